@@ -0,0 +1,88 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AutoExportPolicySpec specifies the Services that an AutoExportPolicy exports.
+type AutoExportPolicySpec struct {
+	// serviceSelector selects the Services, in the namespace of the policy, to export. An empty selector selects
+	// all the Services in the namespace.
+	// +kubebuilder:validation:Required
+	ServiceSelector metav1.LabelSelector `json:"serviceSelector"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,categories={fleet-networking},shortName=autoexport
+// +kubebuilder:printcolumn:JSONPath=`.metadata.creationTimestamp`,name="Age",type=date
+
+// AutoExportPolicy exports the Services in its namespace that match its selector: a ServiceExport is created for
+// every matching Service, and deleted once the Service no longer matches any policy. ServiceExports created by
+// users are left alone.
+type AutoExportPolicy struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +kubebuilder:validation:Required
+	Spec AutoExportPolicySpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// AutoExportPolicyList contains a list of AutoExportPolicy.
+type AutoExportPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	// +listType=set
+	Items []AutoExportPolicy `json:"items"`
+}
+
+// ClusterAutoExportPolicySpec specifies the Services that a ClusterAutoExportPolicy exports.
+type ClusterAutoExportPolicySpec struct {
+	// namespaceSelector selects the namespaces whose Services are exported. An empty selector selects all the
+	// namespaces.
+	// +kubebuilder:validation:Required
+	NamespaceSelector metav1.LabelSelector `json:"namespaceSelector"`
+
+	// serviceSelector selects the Services, in the selected namespaces, to export. An empty selector selects all
+	// the Services in the namespaces.
+	// +kubebuilder:validation:Required
+	ServiceSelector metav1.LabelSelector `json:"serviceSelector"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,categories={fleet-networking},shortName=clusterautoexport
+// +kubebuilder:printcolumn:JSONPath=`.metadata.creationTimestamp`,name="Age",type=date
+
+// ClusterAutoExportPolicy exports the Services that match its selectors across the namespaces of the cluster, in
+// the same way as an AutoExportPolicy does in its namespace.
+type ClusterAutoExportPolicy struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +kubebuilder:validation:Required
+	Spec ClusterAutoExportPolicySpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterAutoExportPolicyList contains a list of ClusterAutoExportPolicy.
+type ClusterAutoExportPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	// +listType=set
+	Items []ClusterAutoExportPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AutoExportPolicy{}, &AutoExportPolicyList{}, &ClusterAutoExportPolicy{}, &ClusterAutoExportPolicyList{})
+}
@@ -0,0 +1,88 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// BackendTrafficPolicyKind is the kind of the BackendTrafficPolicy.
+	BackendTrafficPolicyKind = "BackendTrafficPolicy"
+)
+
+// BackendTrafficPolicySpec specifies how the traffic is distributed among the clusters backing a service.
+type BackendTrafficPolicySpec struct {
+	// Clusters specifies the priority and the weight of the member clusters; the clusters which are not listed use
+	// the default priority and weight.
+	// +optional
+	// +listType=map
+	// +listMapKey=cluster
+	Clusters []ClusterTrafficSettings `json:"clusters,omitempty"`
+
+	// DefaultPriority is the priority of the clusters which are not listed in clusters.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=5
+	// +kubebuilder:default=1
+	DefaultPriority *int32 `json:"defaultPriority,omitempty"`
+
+	// DefaultWeight is the weight of the clusters which are not listed in clusters.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1000
+	// +kubebuilder:default=1000
+	DefaultWeight *int32 `json:"defaultWeight,omitempty"`
+}
+
+// ClusterTrafficSettings are the traffic settings of a member cluster.
+type ClusterTrafficSettings struct {
+	// Cluster is the name of the member cluster.
+	// +required
+	Cluster string `json:"cluster"`
+
+	// Priority of the cluster; the traffic is only routed to the healthy clusters of the lowest value.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=5
+	Priority *int32 `json:"priority,omitempty"`
+
+	// Weight of the cluster, which distributes the traffic among the clusters of the same priority.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1000
+	Weight *int32 `json:"weight,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,categories={fleet-networking},shortName=btp
+// +kubebuilder:printcolumn:JSONPath=`.metadata.creationTimestamp`,name="Age",type=date
+
+// BackendTrafficPolicy specifies the priority and the weight of the member clusters behind the global load
+// balancers, e.g. the origins of the FrontDoorBackend referencing it.
+type BackendTrafficPolicy struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +kubebuilder:validation:Required
+	Spec BackendTrafficPolicySpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// BackendTrafficPolicyList contains a list of BackendTrafficPolicy.
+type BackendTrafficPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	// +listType=set
+	Items []BackendTrafficPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BackendTrafficPolicy{}, &BackendTrafficPolicyList{})
+}
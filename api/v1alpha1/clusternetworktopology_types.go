@@ -0,0 +1,128 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ClusterNetworkTopologyKind is the kind of the ClusterNetworkTopology.
+	ClusterNetworkTopologyKind = "ClusterNetworkTopology"
+
+	// ClusterNetworkTopologyName is the name of the ClusterNetworkTopology of the fleet; the topologies of the
+	// other names are ignored.
+	ClusterNetworkTopologyName = "default"
+)
+
+// ClusterReachability describes how a member cluster reaches the exported Services of another member cluster.
+// +enum
+type ClusterReachability string
+
+const (
+	// ClusterReachabilityDirect means that the Pods of the destination cluster are routable from the source
+	// cluster, so that the endpoints of the exported Services are imported as they are.
+	ClusterReachabilityDirect ClusterReachability = "Direct"
+	// ClusterReachabilityGateway means that only the east-west gateway of the destination cluster is reachable
+	// from the source cluster, so that the Services exported through the gateway are imported with the address of
+	// the gateway.
+	ClusterReachabilityGateway ClusterReachability = "Gateway"
+)
+
+// ClusterNetworkLink describes the reachability from one member cluster to another.
+type ClusterNetworkLink struct {
+	// SourceCluster is the ID of the member cluster the connections originate from, i.e. the importing cluster.
+	// +kubebuilder:validation:Required
+	SourceCluster string `json:"sourceCluster"`
+	// DestinationCluster is the ID of the member cluster the connections are destined to, i.e. the exporting
+	// cluster.
+	// +kubebuilder:validation:Required
+	DestinationCluster string `json:"destinationCluster"`
+	// Reachability is how the source cluster reaches the exported Services of the destination cluster.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=Direct;Gateway
+	Reachability ClusterReachability `json:"reachability"`
+}
+
+// ClusterNetworkTopologySpec specifies the reachability between the member clusters declared by the operators.
+type ClusterNetworkTopologySpec struct {
+	// Links are the reachability between the pairs of member clusters; they take precedence over the links probed
+	// by the member clusters.
+	// +optional
+	// +listType=atomic
+	Links []ClusterNetworkLink `json:"links,omitempty"`
+}
+
+// ProbedClusterNetworkLink is the reachability from one member cluster to another observed by a probe.
+type ProbedClusterNetworkLink struct {
+	ClusterNetworkLink `json:",inline"`
+	// LastTransitionTime is the last time the reachability probed by the source cluster changed.
+	// +kubebuilder:validation:Required
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+}
+
+// ClusterNetworkTopologyStatus is the reachability between the member clusters observed by the probes.
+type ClusterNetworkTopologyStatus struct {
+	// ProbedLinks are the reachability between the pairs of member clusters reported by the reachability probes of
+	// the source clusters.
+	// +optional
+	// +listType=atomic
+	ProbedLinks []ProbedClusterNetworkLink `json:"probedLinks,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,categories={fleet-networking},shortName=cnt
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:JSONPath=`.metadata.creationTimestamp`,name="Age",type=date
+
+// ClusterNetworkTopology describes which member clusters can reach the Pods of each other. The hub distributes the
+// endpoints of the Services exported through an east-west gateway to each importing cluster according to it: the
+// Pod endpoints to the clusters reaching the exporting cluster directly, and the gateway endpoints to the others.
+//
+// Only the topology named default is in effect; it is created by the hub to keep the probed links if it does not
+// exist. A pair of clusters neither declared nor probed is reached through the gateway.
+type ClusterNetworkTopology struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +optional
+	Spec ClusterNetworkTopologySpec `json:"spec,omitempty"`
+
+	// +optional
+	Status ClusterNetworkTopologyStatus `json:"status,omitempty"`
+}
+
+// Reachability returns how the source cluster reaches the destination cluster; the links in the spec take
+// precedence over the probed ones. It returns false if the pair is neither declared nor probed.
+func (in *ClusterNetworkTopology) Reachability(sourceCluster, destinationCluster string) (ClusterReachability, bool) {
+	for _, link := range in.Spec.Links {
+		if link.SourceCluster == sourceCluster && link.DestinationCluster == destinationCluster {
+			return link.Reachability, true
+		}
+	}
+	for _, link := range in.Status.ProbedLinks {
+		if link.SourceCluster == sourceCluster && link.DestinationCluster == destinationCluster {
+			return link.Reachability, true
+		}
+	}
+	return "", false
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterNetworkTopologyList contains a list of ClusterNetworkTopology.
+type ClusterNetworkTopologyList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	// +listType=set
+	Items []ClusterNetworkTopology `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterNetworkTopology{}, &ClusterNetworkTopologyList{})
+}
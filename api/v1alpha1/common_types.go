@@ -86,3 +86,18 @@ type ClusterNamespace string
 type ServiceInUseBy struct {
 	MemberClusters map[ClusterNamespace]ClusterID
 }
+
+// ReachabilityProbeResult describes whether a member cluster reaches the endpoints exported by another member
+// cluster directly. This object is not provided directly as a part of fleet networking API, but provided as a
+// contract for marshaling/unmarshaling EndpointSliceImport annotations, specifically for
+//   - the reachability probe of the importing member cluster to annotate on an EndpointSliceImport whether its
+//     endpoints are reachable; and
+//   - the ClusterNetworkTopology controller to collect the probed links from the annotations.
+type ReachabilityProbeResult struct {
+	// SourceCluster is the ID of the probing member cluster.
+	SourceCluster string `json:"sourceCluster"`
+	// Reachability is Direct if the endpoints are reachable, and Gateway otherwise.
+	Reachability ClusterReachability `json:"reachability"`
+	// ProbeTime is the time of the probe which first observed the reachability.
+	ProbeTime metav1.Time `json:"probeTime"`
+}
@@ -0,0 +1,21 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package v1alpha1
+
+// v1alpha1 is the hub version of the kinds served in both v1alpha1 and v1beta1: the other versions are converted
+// to and from it.
+
+// Hub marks ServiceExport as a conversion hub.
+func (*ServiceExport) Hub() {}
+
+// Hub marks ServiceImport as a conversion hub.
+func (*ServiceImport) Hub() {}
+
+// Hub marks InternalServiceExport as a conversion hub.
+func (*InternalServiceExport) Hub() {}
+
+// Hub marks EndpointSliceExport as a conversion hub.
+func (*EndpointSliceExport) Hub() {}
@@ -20,6 +20,45 @@ type Endpoint struct {
 	// +kubebuilder:validation:MinItems:1
 	// +kubebuilder:validation:MaxItems:100
 	Addresses []string `json:"addresses"`
+	// Zone is the name of the zone the Endpoint exists in, as reported in the source EndpointSlice.
+	// +optional
+	Zone *string `json:"zone,omitempty"`
+	// Region is the name of the region the Endpoint exists in, as labeled on the Node hosting the Endpoint in the
+	// exporting cluster.
+	// +optional
+	Region *string `json:"region,omitempty"`
+	// Hostname is the hostname of the Endpoint, as reported in the source EndpointSlice; it is set for the
+	// endpoints of headless Services backed by Pods with a hostname, e.g. the Pods of a StatefulSet, and allows
+	// importing clusters to address each Pod individually.
+	// +optional
+	Hostname *string `json:"hostname,omitempty"`
+	// Conditions are the conditions of the Endpoint, as reported in the source EndpointSlice; an Endpoint exported
+	// without conditions is ready. A terminating Endpoint which is still serving is exported, so that the importing
+	// clusters may fall back to it during a rolling update of the exporting cluster.
+	// +optional
+	Conditions discoveryv1.EndpointConditions `json:"conditions,omitempty"`
+	// NodeName is the name of the Node hosting the Endpoint in the exporting cluster, as reported in the source
+	// EndpointSlice.
+	// +optional
+	NodeName *string `json:"nodeName,omitempty"`
+	// Hints are the topology hints of the Endpoint, as reported in the source EndpointSlice.
+	// +optional
+	Hints *discoveryv1.EndpointHints `json:"hints,omitempty"`
+}
+
+// IsReady returns true if the Endpoint is ready to receive traffic; a nil ready condition is interpreted as ready,
+// per the EndpointSlice API.
+func (in *Endpoint) IsReady() bool {
+	return in.Conditions.Ready == nil || *in.Conditions.Ready
+}
+
+// IsServing returns true if the Endpoint is serving traffic, which it may do while terminating; a nil serving
+// condition falls back to the ready condition, per the EndpointSlice API.
+func (in *Endpoint) IsServing() bool {
+	if in.Conditions.Serving == nil {
+		return in.IsReady()
+	}
+	return *in.Conditions.Serving
 }
 
 // OwnerServiceReference points to the Service that owns the exported EndpointSlice.
@@ -35,11 +74,36 @@ type OwnerServiceReference struct {
 	NamespacedName string `json:"namespacedName"`
 }
 
+// GatewayEndpoints are the endpoints and the ports of the east-west gateway an EndpointSlice is exported through.
+type GatewayEndpoints struct {
+	// Endpoints are the endpoints of the gateway; it is empty if the gateway has no address of the address type
+	// of the EndpointSliceExport, or the exported EndpointSlice has no serving endpoints.
+	// +optional
+	// +listType=atomic
+	Endpoints []Endpoint `json:"endpoints,omitempty"`
+	// Ports are the ports of the gateway assigned to the exported ports.
+	// +optional
+	// +listType=atomic
+	Ports []discoveryv1.EndpointPort `json:"ports,omitempty"`
+}
+
+// PrivateLinkEndpoints are the Azure Private Link Service an EndpointSlice is exported through and its ports.
+type PrivateLinkEndpoints struct {
+	// ServiceID is the resource ID of the Azure Private Link Service in front of the internal load balancer of the
+	// owner Service.
+	// +kubebuilder:validation:Required
+	ServiceID string `json:"serviceID"`
+	// Ports are the ports of the load balancer, i.e. the ports of the owner Service, of the exported ports.
+	// +optional
+	// +listType=atomic
+	Ports []discoveryv1.EndpointPort `json:"ports,omitempty"`
+}
+
 // EndpointSliceExportSpec specifies the spec of an exported EndpointSlice.
 type EndpointSliceExportSpec struct {
-	// The type of addresses carried by this EndpointSliceExport.
-	// At this stage only IPv4 addresses are supported.
-	// +kubebuilder:validation:Enum:="IPv4"
+	// The type of addresses carried by this EndpointSliceExport; a dual-stack Service is exported with one
+	// EndpointSliceExport for each of its IP families.
+	// +kubebuilder:validation:Enum:="IPv4";"IPv6"
 	// +kubebuilder:default:="IPv4"
 	AddressType discoveryv1.AddressType `json:"addressType"`
 	// A list of unique endpoints in the exported EndpointSlice.
@@ -52,7 +116,18 @@ type EndpointSliceExportSpec struct {
 	// +optional
 	// +listType=atomic
 	Ports []discoveryv1.EndpointPort `json:"ports"`
-	// The reference to the source EndpointSlice.
+	// Gateway, if set, is the east-west gateway of the exporting cluster through which the endpoints are
+	// reachable; the hub distributes the endpoints and the ports of the gateway instead to the member clusters
+	// which do not reach the exporting cluster directly, per the ClusterNetworkTopology of the fleet.
+	// +optional
+	Gateway *GatewayEndpoints `json:"gateway,omitempty"`
+	// PrivateLink, if set, is the Azure Private Link Service of the exporting cluster through which the owner
+	// Service is exposed; the member clusters with Private Endpoints connected to it import the addresses of their
+	// Private Endpoints instead.
+	// +optional
+	PrivateLink *PrivateLinkEndpoints `json:"privateLink,omitempty"`
+	// The reference to the source EndpointSlice, or to the owner Service if the EndpointSliceExport aggregates the
+	// endpoints of all the EndpointSlices of the Service.
 	// +kubebuilder:validation:Required
 	EndpointSliceReference ExportedObjectReference `json:"endpointSliceReference"`
 	// The reference to the owner Service.
@@ -62,6 +137,7 @@ type EndpointSliceExportSpec struct {
 
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:scope=Namespaced,categories={fleet-networking}
+// +kubebuilder:storageversion
 // +kubebuilder:subresource:status
 
 // EndpointSliceExport is a data transport type that member clusters in the fleet use to upload the spec of an
@@ -74,6 +150,12 @@ type EndpointSliceExport struct {
 	Spec EndpointSliceExportSpec `json:"spec"`
 }
 
+// IsAggregated returns true if the EndpointSliceExport aggregates the endpoints of all the EndpointSlices of its
+// owner Service, in which case it references the owner Service instead of a source EndpointSlice.
+func (in *EndpointSliceExport) IsAggregated() bool {
+	return in.Spec.EndpointSliceReference.Kind == "Service"
+}
+
 // +kubebuilder:object:root=true
 
 // EndpointSliceExportList contains a list of EndpointSliceExports.
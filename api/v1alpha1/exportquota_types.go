@@ -0,0 +1,85 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ExportQuotaScope is what the exports are counted per against an ExportQuota.
+// +enum
+type ExportQuotaScope string
+
+const (
+	// ExportQuotaScopeCluster counts the exports per member cluster.
+	ExportQuotaScopeCluster ExportQuotaScope = "Cluster"
+	// ExportQuotaScopeNamespace counts the exports per namespace, across the member clusters.
+	ExportQuotaScopeNamespace ExportQuotaScope = "Namespace"
+)
+
+// ExportQuotaSpec specifies the limits of an ExportQuota.
+type ExportQuotaSpec struct {
+	// scope is what the exports are counted per: each member cluster, or each namespace.
+	// +kubebuilder:validation:Enum=Cluster;Namespace
+	// +kubebuilder:default=Cluster
+	// +optional
+	Scope ExportQuotaScope `json:"scope,omitempty"`
+
+	// targets lists the IDs of the member clusters, or the namespaces, the quota applies to, each of them being
+	// limited on its own; the quota applies to all of them if it is empty.
+	// +optional
+	// +listType=set
+	Targets []string `json:"targets,omitempty"`
+
+	// maxServices is the maximum number of services exported from each target; the number is not limited if it is
+	// not set.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxServices *int32 `json:"maxServices,omitempty"`
+
+	// maxEndpoints is the maximum total number of endpoints exported from each target; the number is not limited if
+	// it is not set.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxEndpoints *int32 `json:"maxEndpoints,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,categories={fleet-networking},shortName=exportquota
+// +kubebuilder:printcolumn:JSONPath=`.spec.scope`,name="Scope",type=string
+// +kubebuilder:printcolumn:JSONPath=`.spec.maxServices`,name="Max-Services",type=integer
+// +kubebuilder:printcolumn:JSONPath=`.spec.maxEndpoints`,name="Max-Endpoints",type=integer
+// +kubebuilder:printcolumn:JSONPath=`.metadata.creationTimestamp`,name="Age",type=date
+
+// ExportQuota limits the services and endpoints exported from each member cluster, or each namespace, so that a
+// single noisy tenant cannot overwhelm a shared hub cluster. It is created in the hub cluster.
+//
+// The exports are admitted in the order they are exported, the oldest first, as long as they fit in the quota; an
+// export which does not fit is reported as over quota and excluded from the ServiceImport until older exports leave
+// room for it. An export is admitted only if it fits in every quota which applies to it.
+type ExportQuota struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +kubebuilder:validation:Required
+	Spec ExportQuotaSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// ExportQuotaList contains a list of ExportQuota.
+type ExportQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	// +listType=set
+	Items []ExportQuota `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ExportQuota{}, &ExportQuotaList{})
+}
@@ -0,0 +1,229 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// FrontDoorBackendKind is the kind of the FrontDoorBackend.
+	FrontDoorBackendKind = "FrontDoorBackend"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,categories={fleet-networking},shortName=fdb
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:JSONPath=`.spec.profile.name`,name="Profile",type=string
+// +kubebuilder:printcolumn:JSONPath=`.spec.backend.name`,name="Backend",type=string
+// +kubebuilder:printcolumn:JSONPath=`.status.conditions[?(@.type=='Accepted')].status`,name="Is-Accepted",type=string
+// +kubebuilder:printcolumn:JSONPath=`.metadata.creationTimestamp`,name="Age",type=date
+
+// FrontDoorBackend is used to manage an origin group of an existing Azure Front Door (Standard or Premium) profile,
+// whose origins are the load balancers of the member clusters exporting the service behind the ServiceImport.
+// The routes of the profile are not managed by the controller; they are expected to reference the origin group, whose
+// name is reported in the status.
+// https://learn.microsoft.com/en-us/azure/frontdoor/origin
+type FrontDoorBackend struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// The desired state of FrontDoorBackend.
+	Spec FrontDoorBackendSpec `json:"spec"`
+
+	// The observed status of FrontDoorBackend.
+	// +optional
+	Status FrontDoorBackendStatus `json:"status,omitempty"`
+}
+
+// FrontDoorBackendSpec is the desired state of FrontDoorBackend.
+type FrontDoorBackendSpec struct {
+	// Which Azure Front Door profile the origin group should be created in.
+	// +required
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="spec.profile is immutable"
+	Profile FrontDoorProfileRef `json:"profile"`
+
+	// The reference to a backend.
+	// +required
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="spec.backend is immutable"
+	Backend TrafficManagerBackendRef `json:"backend"`
+
+	// The health probe settings of the origin group.
+	// +optional
+	HealthProbe *FrontDoorHealthProbe `json:"healthProbe,omitempty"`
+
+	// The port used for HTTP requests to the origins.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +kubebuilder:default=80
+	HTTPPort *int32 `json:"httpPort,omitempty"`
+
+	// The port used for HTTPS requests to the origins.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +kubebuilder:default=443
+	HTTPSPort *int32 `json:"httpsPort,omitempty"`
+
+	// The host header sent to the origins; the host name of the origin is used if not set.
+	// +optional
+	OriginHostHeader *string `json:"originHostHeader,omitempty"`
+
+	// The reference to a BackendTrafficPolicy in the same namespace, which specifies the priority and the weight of
+	// the origins; all the origins have the priority 1 and the weight 1000 if not set.
+	// +optional
+	TrafficPolicyRef *BackendTrafficPolicyRef `json:"trafficPolicyRef,omitempty"`
+}
+
+// FrontDoorProfileRef is a reference to an existing Azure Front Door profile.
+type FrontDoorProfileRef struct {
+	// ResourceGroup is the resource group of the profile.
+	// +required
+	ResourceGroup string `json:"resourceGroup"`
+
+	// Name is the name of the profile.
+	// +required
+	Name string `json:"name"`
+}
+
+// BackendTrafficPolicyRef is a reference to a BackendTrafficPolicy in the same namespace.
+type BackendTrafficPolicyRef struct {
+	// Name is the name of the referenced BackendTrafficPolicy.
+	// +required
+	Name string `json:"name"`
+}
+
+// FrontDoorHealthProbeProtocol is the protocol of the health probes.
+// +enum
+type FrontDoorHealthProbeProtocol string
+
+const (
+	// FrontDoorHealthProbeProtocolHTTP probes the origins using HTTP.
+	FrontDoorHealthProbeProtocolHTTP FrontDoorHealthProbeProtocol = "Http"
+	// FrontDoorHealthProbeProtocolHTTPS probes the origins using HTTPS.
+	FrontDoorHealthProbeProtocolHTTPS FrontDoorHealthProbeProtocol = "Https"
+)
+
+// FrontDoorHealthProbeRequestType is the type of the health probe requests.
+// +enum
+type FrontDoorHealthProbeRequestType string
+
+const (
+	// FrontDoorHealthProbeRequestTypeGET sends GET requests.
+	FrontDoorHealthProbeRequestTypeGET FrontDoorHealthProbeRequestType = "GET"
+	// FrontDoorHealthProbeRequestTypeHEAD sends HEAD requests.
+	FrontDoorHealthProbeRequestTypeHEAD FrontDoorHealthProbeRequestType = "HEAD"
+)
+
+// FrontDoorHealthProbe are the settings of the health probes sent to the origins.
+type FrontDoorHealthProbe struct {
+	// The path relative to the origin that is used to determine the health of the origin.
+	// +optional
+	// +kubebuilder:default="/"
+	Path *string `json:"path,omitempty"`
+
+	// The protocol to use for the health probes.
+	// +optional
+	// +kubebuilder:validation:Enum=Http;Https
+	// +kubebuilder:default=Http
+	Protocol *FrontDoorHealthProbeProtocol `json:"protocol,omitempty"`
+
+	// The type of the health probe requests.
+	// +optional
+	// +kubebuilder:validation:Enum=GET;HEAD
+	// +kubebuilder:default=HEAD
+	RequestType *FrontDoorHealthProbeRequestType `json:"requestType,omitempty"`
+
+	// The number of seconds between the health probes.
+	// +optional
+	// +kubebuilder:validation:Minimum=5
+	// +kubebuilder:validation:Maximum=255
+	// +kubebuilder:default=100
+	IntervalInSeconds *int32 `json:"intervalInSeconds,omitempty"`
+}
+
+// FrontDoorOriginStatus is the status of an Azure Front Door origin which is successfully accepted under the origin
+// group.
+type FrontDoorOriginStatus struct {
+	// Name of the origin.
+	// +required
+	Name string `json:"name"`
+
+	// Cluster is the member cluster the origin is exported from.
+	// +required
+	Cluster string `json:"cluster"`
+
+	// The IP address or the host name of the origin.
+	// +required
+	HostName string `json:"hostName"`
+
+	// The priority of the origin.
+	// +optional
+	Priority *int32 `json:"priority,omitempty"`
+
+	// The weight of the origin.
+	// +optional
+	Weight *int32 `json:"weight,omitempty"`
+}
+
+// FrontDoorBackendStatus is the observed status of FrontDoorBackend.
+type FrontDoorBackendStatus struct {
+	// OriginGroup is the name of the origin group managed by the controller.
+	// +optional
+	OriginGroup string `json:"originGroup,omitempty"`
+
+	// Origins contains a list of accepted origins which are created or updated under the origin group.
+	// +optional
+	Origins []FrontDoorOriginStatus `json:"origins,omitempty"`
+
+	// Current backend status.
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// FrontDoorBackendConditionType is a type of condition associated with a FrontDoorBackendStatus.
+type FrontDoorBackendConditionType string
+
+// FrontDoorBackendConditionReason defines the set of reasons that explain why a particular condition has been raised.
+type FrontDoorBackendConditionReason string
+
+const (
+	// FrontDoorBackendConditionAccepted condition indicates whether the origin group and its origins have been
+	// created or updated in the profile.
+	FrontDoorBackendConditionAccepted FrontDoorBackendConditionType = "Accepted"
+
+	// FrontDoorBackendReasonAccepted is used with the "Accepted" condition when the condition is True.
+	FrontDoorBackendReasonAccepted FrontDoorBackendConditionReason = "Accepted"
+
+	// FrontDoorBackendReasonInvalid is used with the "Accepted" condition when the backend has an invalid
+	// configuration, e.g. the profile does not exist.
+	FrontDoorBackendReasonInvalid FrontDoorBackendConditionReason = "Invalid"
+
+	// FrontDoorBackendReasonPending is used with the "Accepted" condition when the controller hits an internal error
+	// and will keep retrying.
+	FrontDoorBackendReasonPending FrontDoorBackendConditionReason = "Pending"
+)
+
+// +kubebuilder:object:root=true
+
+// FrontDoorBackendList contains a list of FrontDoorBackend.
+type FrontDoorBackendList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	// +listType=set
+	Items []FrontDoorBackend `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&FrontDoorBackend{}, &FrontDoorBackendList{})
+}
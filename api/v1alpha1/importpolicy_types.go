@@ -0,0 +1,79 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ImportPolicySpec specifies the Services that a member cluster imports from the fleet.
+type ImportPolicySpec struct {
+	// allowedNamespaces selects the namespaces whose ServiceImports are imported; all the namespaces are allowed if
+	// it is not set. Namespaces can be selected by name with the kubernetes.io/metadata.name label.
+	// +optional
+	AllowedNamespaces *metav1.LabelSelector `json:"allowedNamespaces,omitempty"`
+
+	// deniedNamespaces selects the namespaces whose ServiceImports are never imported; it takes precedence over
+	// allowedNamespaces.
+	// +optional
+	DeniedNamespaces *metav1.LabelSelector `json:"deniedNamespaces,omitempty"`
+
+	// allowedServices selects, by their labels, the ServiceImports which are imported; all the ServiceImports are
+	// allowed if it is not set. The labels exported with the Services count as well.
+	// +optional
+	AllowedServices *metav1.LabelSelector `json:"allowedServices,omitempty"`
+
+	// deniedServices selects, by their labels, the ServiceImports which are never imported; it takes precedence
+	// over allowedServices.
+	// +optional
+	DeniedServices *metav1.LabelSelector `json:"deniedServices,omitempty"`
+
+	// allowedClusters lists the IDs of the member clusters whose endpoints are imported; the endpoints of all the
+	// clusters are allowed if it is empty.
+	// +optional
+	// +listType=set
+	AllowedClusters []string `json:"allowedClusters,omitempty"`
+
+	// deniedClusters lists the IDs of the member clusters whose endpoints are never imported; it takes precedence
+	// over allowedClusters.
+	// +optional
+	// +listType=set
+	DeniedClusters []string `json:"deniedClusters,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,categories={fleet-networking},shortName=importpolicy
+// +kubebuilder:printcolumn:JSONPath=`.metadata.creationTimestamp`,name="Age",type=date
+
+// ImportPolicy restricts the Services that a member cluster imports from the fleet. Unlike a
+// NamespaceSamenessPolicy, it is local to the member cluster where it is created: a ServiceImport it does not allow
+// is marked as denied and never imported, and the endpoints exported from the clusters it does not allow are never
+// imported either.
+//
+// A Service is imported only if it is allowed by every policy; all the Services are imported if there is no policy.
+type ImportPolicy struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +kubebuilder:validation:Required
+	Spec ImportPolicySpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// ImportPolicyList contains a list of ImportPolicy.
+type ImportPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	// +listType=set
+	Items []ImportPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ImportPolicy{}, &ImportPolicyList{})
+}
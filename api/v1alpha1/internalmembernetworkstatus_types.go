@@ -0,0 +1,160 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,categories={fleet-networking},shortName=imnstatus
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:JSONPath=`.status.clusterID`,name="Cluster",type=string
+// +kubebuilder:printcolumn:JSONPath=`.status.conditions[?(@.type=="AgentHealthy")].status`,name="Healthy",type=string
+// +kubebuilder:printcolumn:JSONPath=`.status.agentVersion`,name="Version",type=string
+// +kubebuilder:printcolumn:JSONPath=`.status.serviceExports`,name="Exports",type=integer
+// +kubebuilder:printcolumn:JSONPath=`.status.serviceImports`,name="Imports",type=integer
+// +kubebuilder:printcolumn:JSONPath=`.status.lastHeartbeatTime`,name="Last-Heartbeat",type=date
+
+// InternalMemberNetworkStatus reports the state of the networking agent of a member cluster to the hub cluster, so
+// that the fleet admins can tell at a glance which clusters' networking agents are healthy.
+//
+// It resides in the namespace reserved for the member cluster in the hub cluster and is named after the member
+// cluster. The networking agent of the member cluster reports its heartbeats, version, exports, imports and errors;
+// the hub networking controller manager evaluates the heartbeats into the AgentHealthy condition. It must not be
+// edited by users.
+type InternalMemberNetworkStatus struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// status is the state of the networking agent of the member cluster.
+	// +optional
+	Status InternalMemberNetworkStatusStatus `json:"status,omitempty"`
+}
+
+// InternalMemberNetworkStatusStatus is the state of the networking agent of a member cluster.
+type InternalMemberNetworkStatusStatus struct {
+	// clusterID is the ID of the member cluster.
+	// +optional
+	ClusterID string `json:"clusterID,omitempty"`
+
+	// agentVersion is the version of the networking agent of the member cluster.
+	// +optional
+	AgentVersion string `json:"agentVersion,omitempty"`
+
+	// lastHeartbeatTime is the last time the networking agent of the member cluster reported its state.
+	// +optional
+	LastHeartbeatTime *metav1.Time `json:"lastHeartbeatTime,omitempty"`
+
+	// serviceExports is the number of ServiceExports in the member cluster.
+	ServiceExports int32 `json:"serviceExports"`
+
+	// serviceImports is the number of ServiceImports in the member cluster.
+	ServiceImports int32 `json:"serviceImports"`
+
+	// endpointSliceExports is the number of EndpointSlices the member cluster exports to the fleet.
+	EndpointSliceExports int32 `json:"endpointSliceExports"`
+
+	// endpointSliceImports is the number of EndpointSlices distributed to the member cluster for import.
+	EndpointSliceImports int32 `json:"endpointSliceImports"`
+
+	// errors summarizes the failed reconciliations of each controller of the networking agent since the previous
+	// heartbeat, sorted by controller.
+	// +optional
+	// +listType=map
+	// +listMapKey=controller
+	Errors []ControllerErrorSummary `json:"errors,omitempty"`
+
+	// tunnel is the tunnel peer of the member cluster, if it runs the tunnel agent; the other member clusters
+	// establish encrypted tunnels to it.
+	// +optional
+	Tunnel *TunnelPeer `json:"tunnel,omitempty"`
+
+	// tunnelPeers are the tunnel peers of the other member clusters running the tunnel agent, as distributed by the
+	// hub cluster, sorted by cluster; they are only set if the member cluster runs the tunnel agent itself.
+	// +optional
+	// +listType=map
+	// +listMapKey=clusterID
+	TunnelPeers []TunnelPeer `json:"tunnelPeers,omitempty"`
+
+	// Current state of the networking agent, as evaluated by the hub cluster.
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// ControllerErrorSummary summarizes the failed reconciliations of a controller.
+type ControllerErrorSummary struct {
+	// controller is the name of the controller.
+	// +kubebuilder:validation:Required
+	Controller string `json:"controller"`
+
+	// count is the number of failed reconciliations.
+	Count int32 `json:"count"`
+
+	// lastError is the error message of the last failed reconciliation.
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+
+	// lastErrorTime is the time of the last failed reconciliation.
+	// +optional
+	LastErrorTime *metav1.Time `json:"lastErrorTime,omitempty"`
+}
+
+// TunnelPeer is the endpoint of the WireGuard tunnel of a member cluster.
+type TunnelPeer struct {
+	// clusterID is the ID of the member cluster.
+	// +kubebuilder:validation:Required
+	ClusterID string `json:"clusterID"`
+
+	// publicKey is the base64-encoded WireGuard public key of the tunnel agent of the member cluster.
+	// +kubebuilder:validation:Required
+	PublicKey string `json:"publicKey"`
+
+	// endpoint is the address and the UDP port the tunnel agent of the member cluster is reachable at from the
+	// other member clusters, e.g. the address of its LoadBalancer Service; it is empty until the address is
+	// assigned.
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// address is the IP address of the tunnel agent of the member cluster within the tunnel network; the endpoints
+	// the member cluster exports through its east-west gateway are imported by the other member clusters at this
+	// address.
+	// +kubebuilder:validation:Required
+	Address string `json:"address"`
+}
+
+// InternalMemberNetworkStatusConditionType is a type of condition associated with an
+// InternalMemberNetworkStatusStatus.
+type InternalMemberNetworkStatusConditionType string
+
+const (
+	// InternalMemberNetworkStatusKind is the kind of the InternalMemberNetworkStatus.
+	InternalMemberNetworkStatusKind = "InternalMemberNetworkStatus"
+
+	// InternalMemberNetworkStatusConditionAgentHealthy condition indicates whether the networking agent of the
+	// member cluster has reported its state within the heartbeat timeout.
+	InternalMemberNetworkStatusConditionAgentHealthy InternalMemberNetworkStatusConditionType = "AgentHealthy"
+)
+
+// +kubebuilder:object:root=true
+
+// InternalMemberNetworkStatusList contains a list of InternalMemberNetworkStatus.
+type InternalMemberNetworkStatusList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	// +listType=set
+	Items []InternalMemberNetworkStatus `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&InternalMemberNetworkStatus{}, &InternalMemberNetworkStatusList{})
+}
@@ -21,6 +21,10 @@ type InternalServiceExportSpec struct {
 	ServiceReference ExportedObjectReference `json:"serviceReference"`
 	// Type is the type of the Service in each cluster.
 	Type corev1.ServiceType `json:"type,omitempty"`
+	// Headless reports whether the exported Service is headless, i.e. its clusterIP is None; the endpoints of a
+	// headless Service are addressed directly rather than through a virtual IP.
+	// +optional
+	Headless bool `json:"headless,omitempty"`
 	// IsDNSLabelConfigured determines if the Service has a DNS label configured.
 	// A valid DNS label should be configured when the public IP address of the Service is configured as an Azure Traffic
 	// Manager endpoint.
@@ -32,10 +36,73 @@ type InternalServiceExportSpec struct {
 	IsInternalLoadBalancer bool `json:"isInternalLoadBalancer,omitempty"`
 	// PublicIPResourceID is the Azure Resource URI of public IP. This is only applicable for Load Balancer type Services.
 	PublicIPResourceID *string `json:"publicIPResourceID,omitempty"`
+	// AllocateLoadBalancerNodePorts mirrors the allocateLoadBalancerNodePorts field of the exported Service.
+	// This is only applicable for Load Balancer type Services; it is left unset for other types of Services, or
+	// when the field is not set on the exported Service.
+	// +optional
+	AllocateLoadBalancerNodePorts *bool `json:"allocateLoadBalancerNodePorts,omitempty"`
+	// LoadBalancerIP mirrors the loadBalancerIP field of the exported Service.
+	// This is only applicable for Load Balancer type Services; it is left empty for other types of Services, or
+	// when the field is not set on the exported Service.
+	// +optional
+	LoadBalancerIP string `json:"loadBalancerIP,omitempty"`
+	// LoadBalancerIngress are the IP addresses or hostnames of the load balancer ingress points of the exported
+	// Service, e.g. for global load balancers to route the traffic to the cluster.
+	// This is only applicable for Load Balancer type Services; it is left empty for other types of Services, or
+	// when the load balancer has not been provisioned yet.
+	// +optional
+	// +listType=atomic
+	LoadBalancerIngress []string `json:"loadBalancerIngress,omitempty"`
+	// IPFamilyPolicy mirrors the ipFamilyPolicy field of the exported Service.
+	// +optional
+	IPFamilyPolicy *corev1.IPFamilyPolicy `json:"ipFamilyPolicy,omitempty"`
+	// IPFamilies mirrors the ipFamilies field of the exported Service.
+	// +optional
+	// +listType=atomic
+	IPFamilies []corev1.IPFamily `json:"ipFamilies,omitempty"`
 	// Weight is the weight of the ServiceExport.
 	// If unspecified, weight defaults to 1.
 	// The value is from serviceExport "networking.fleet.azure.com/weight" annotation and should be in the range [0, 1000].
 	Weight *int64 `json:"weight,omitempty"`
+	// DNSTTLSeconds is the TTL hint, in seconds, for DNS integrations caching the records of the exported Service.
+	// The value is from serviceExport "fleet.azure.com/dns-ttl" annotation; it is left unset when the annotation
+	// is absent or invalid.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	DNSTTLSeconds *int64 `json:"dnsTTLSeconds,omitempty"`
+	// CanaryPercent is the percentage of the fleet traffic to the Service that the exporting cluster should
+	// receive as a canary, regardless of its endpoint count; the other clusters share the rest.
+	// The value is from serviceExport "fleet.azure.com/canary-percent" annotation, clamped to the range [0, 100];
+	// it is left unset when the annotation is absent or invalid, in which case the cluster is not a canary.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	CanaryPercent *int32 `json:"canaryPercent,omitempty"`
+	// SessionAffinity mirrors the sessionAffinity field of the exported Service.
+	// +optional
+	SessionAffinity corev1.ServiceAffinity `json:"sessionAffinity,omitempty"`
+	// SessionAffinityTimeoutSeconds mirrors the client IP based session affinity timeout of the exported Service.
+	// It is only applicable when SessionAffinity is ClientIP, and is left unset when the exported Service does not
+	// specify a timeout, or specifies one out of the range allowed by Kubernetes.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=86400
+	// +optional
+	SessionAffinityTimeoutSeconds *int32 `json:"sessionAffinityTimeoutSeconds,omitempty"`
+	// HealthCheckAnnotations are the annotations of the exported Service which configure the health checks of its
+	// endpoints, e.g. the health-check path and port; only the annotation keys configured on the member cluster are
+	// exported, and the annotations whose keys end with "path" are dropped unless they are well-formed URL paths.
+	// +optional
+	HealthCheckAnnotations map[string]string `json:"healthCheckAnnotations,omitempty"`
+	// ExportedLabels are the valid labels declared by the ServiceExport to merge onto the ServiceImport.
+	// +optional
+	ExportedLabels map[string]string `json:"exportedLabels,omitempty"`
+	// ExportedAnnotations are the valid annotations declared by the ServiceExport to merge onto the ServiceImport.
+	// +optional
+	ExportedAnnotations map[string]string `json:"exportedAnnotations,omitempty"`
+	// AllowedConsumers are the member clusters allowed to import the Service, as declared by the ServiceExport;
+	// all the member clusters are allowed if it is unset.
+	// +optional
+	AllowedConsumers *ConsumerSelector `json:"allowedConsumers,omitempty"`
 }
 
 // InternalServiceExportStatus contains the current status of an InternalServiceExport.
@@ -50,6 +117,7 @@ type InternalServiceExportStatus struct {
 
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:scope=Namespaced,categories={fleet-networking},shortName=internalsvcexport
+// +kubebuilder:storageversion
 // +kubebuilder:subresource:status
 
 // InternalServiceExport is a data transport type that member clusters in the fleet use to upload the spec of
@@ -0,0 +1,227 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// MultiClusterIngressKind is the kind of the MultiClusterIngress.
+	MultiClusterIngressKind = "MultiClusterIngress"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,categories={fleet-networking},shortName=mci
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:JSONPath=`.spec.profile.name`,name="Profile",type=string
+// +kubebuilder:printcolumn:JSONPath=`.status.hostName`,name="Host-Name",type=string
+// +kubebuilder:printcolumn:JSONPath=`.status.conditions[?(@.type=='Accepted')].status`,name="Is-Accepted",type=string
+// +kubebuilder:printcolumn:JSONPath=`.metadata.creationTimestamp`,name="Age",type=date
+
+// MultiClusterIngress is used to program a global L7 entry point, i.e. an endpoint of an existing Azure Front Door
+// (Standard or Premium) profile, which routes the requests by their hosts and paths to the services exported from
+// the member clusters.
+// Each backend of the rules is realized by a FrontDoorBackend owned by the MultiClusterIngress, whose origin group
+// has the load balancers of the member clusters exporting the service as its origins; the routes of the endpoint and
+// the custom domains of the hosts are managed by the controller.
+// https://learn.microsoft.com/en-us/azure/frontdoor/endpoint
+type MultiClusterIngress struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// The desired state of MultiClusterIngress.
+	Spec MultiClusterIngressSpec `json:"spec"`
+
+	// The observed status of MultiClusterIngress.
+	// +optional
+	Status MultiClusterIngressStatus `json:"status,omitempty"`
+}
+
+// MultiClusterIngressSpec is the desired state of MultiClusterIngress.
+type MultiClusterIngressSpec struct {
+	// Which Azure Front Door profile the endpoint should be created in.
+	// +required
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="spec.profile is immutable"
+	Profile FrontDoorProfileRef `json:"profile"`
+
+	// Rules route the requests by their hosts and paths to the backends.
+	// +required
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=25
+	Rules []MultiClusterIngressRule `json:"rules"`
+
+	// TLS configures the certificates of the hosts of the rules; the hosts which are not configured are served with
+	// certificates managed by the Azure Front Door.
+	// +optional
+	TLS []MultiClusterIngressTLS `json:"tls,omitempty"`
+
+	// The health probe settings of the origin groups of the backends.
+	// +optional
+	HealthProbe *FrontDoorHealthProbe `json:"healthProbe,omitempty"`
+}
+
+// MultiClusterIngressRule routes the requests of a host by their paths.
+type MultiClusterIngressRule struct {
+	// Host is the fully qualified domain name of the requests, which is added as a custom domain of the profile; the
+	// rule matches the requests to the default domain of the endpoint if not set.
+	// +optional
+	Host string `json:"host,omitempty"`
+
+	// Paths route the requests by their paths to the backends.
+	// +required
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=25
+	Paths []MultiClusterIngressPath `json:"paths"`
+}
+
+// MultiClusterIngressPath routes the requests matching the path to a backend.
+type MultiClusterIngressPath struct {
+	// Path is the pattern of the paths of the requests, e.g. "/api/*"; a wildcard is only allowed at the end.
+	// +optional
+	// +kubebuilder:default="/*"
+	// +kubebuilder:validation:Pattern=`^/[^*]*\*?$`
+	Path string `json:"path,omitempty"`
+
+	// The backend the requests are routed to.
+	// +required
+	Backend MultiClusterIngressBackend `json:"backend"`
+}
+
+// MultiClusterIngressBackend is a service exported from the member clusters which the requests are routed to.
+type MultiClusterIngressBackend struct {
+	// Name is the name of the ServiceImport in the same namespace; the exported services must be of the LoadBalancer
+	// type with public IP addresses.
+	// +required
+	Name string `json:"name"`
+
+	// The port used for HTTP requests to the exported services.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +kubebuilder:default=80
+	HTTPPort *int32 `json:"httpPort,omitempty"`
+
+	// The port used for HTTPS requests to the exported services.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +kubebuilder:default=443
+	HTTPSPort *int32 `json:"httpsPort,omitempty"`
+}
+
+// MultiClusterIngressTLSVersion is the minimum TLS version of the requests to a host.
+// +enum
+type MultiClusterIngressTLSVersion string
+
+const (
+	// MultiClusterIngressTLSVersion10 accepts TLS 1.0 and above.
+	MultiClusterIngressTLSVersion10 MultiClusterIngressTLSVersion = "TLS10"
+	// MultiClusterIngressTLSVersion12 accepts TLS 1.2 and above.
+	MultiClusterIngressTLSVersion12 MultiClusterIngressTLSVersion = "TLS12"
+)
+
+// MultiClusterIngressTLS configures the certificate of a list of hosts.
+type MultiClusterIngressTLS struct {
+	// Hosts are the hosts of the rules served with the certificate.
+	// +required
+	// +kubebuilder:validation:MinItems=1
+	Hosts []string `json:"hosts"`
+
+	// SecretName is the name of an existing secret of the Azure Front Door profile, which references the customer
+	// certificate in Azure Key Vault; the certificate is managed by the Azure Front Door if not set.
+	// +optional
+	SecretName *string `json:"secretName,omitempty"`
+
+	// The minimum TLS version of the requests to the hosts.
+	// +optional
+	// +kubebuilder:validation:Enum=TLS10;TLS12
+	// +kubebuilder:default=TLS12
+	MinimumTLSVersion *MultiClusterIngressTLSVersion `json:"minimumTLSVersion,omitempty"`
+}
+
+// MultiClusterIngressDomainStatus is the status of a custom domain created for a host of the rules.
+type MultiClusterIngressDomainStatus struct {
+	// Host is the host of the rules.
+	// +required
+	Host string `json:"host"`
+
+	// Name is the name of the custom domain in the profile.
+	// +required
+	Name string `json:"name"`
+
+	// ValidationState is the state of the validation of the ownership of the domain.
+	// +optional
+	ValidationState string `json:"validationState,omitempty"`
+
+	// ValidationToken is the token to be set in the _dnsauth.<host> TXT record to validate the ownership of the
+	// domain.
+	// +optional
+	ValidationToken string `json:"validationToken,omitempty"`
+}
+
+// MultiClusterIngressStatus is the observed status of MultiClusterIngress.
+type MultiClusterIngressStatus struct {
+	// Endpoint is the name of the Azure Front Door endpoint managed by the controller.
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// HostName is the default domain of the endpoint.
+	// +optional
+	HostName string `json:"hostName,omitempty"`
+
+	// CustomDomains are the custom domains created for the hosts of the rules.
+	// +optional
+	CustomDomains []MultiClusterIngressDomainStatus `json:"customDomains,omitempty"`
+
+	// Current ingress status.
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// MultiClusterIngressConditionType is a type of condition associated with a MultiClusterIngressStatus.
+type MultiClusterIngressConditionType string
+
+// MultiClusterIngressConditionReason defines the set of reasons that explain why a particular condition has been
+// raised.
+type MultiClusterIngressConditionReason string
+
+const (
+	// MultiClusterIngressConditionAccepted condition indicates whether the endpoint, its routes and the custom
+	// domains have been created or updated in the profile.
+	MultiClusterIngressConditionAccepted MultiClusterIngressConditionType = "Accepted"
+
+	// MultiClusterIngressReasonAccepted is used with the "Accepted" condition when the condition is True.
+	MultiClusterIngressReasonAccepted MultiClusterIngressConditionReason = "Accepted"
+
+	// MultiClusterIngressReasonInvalid is used with the "Accepted" condition when the ingress has an invalid
+	// configuration, e.g. the profile does not exist.
+	MultiClusterIngressReasonInvalid MultiClusterIngressConditionReason = "Invalid"
+
+	// MultiClusterIngressReasonPending is used with the "Accepted" condition when the origin groups of the backends
+	// are not ready yet, or when the controller hits an internal error and will keep retrying.
+	MultiClusterIngressReasonPending MultiClusterIngressConditionReason = "Pending"
+)
+
+// +kubebuilder:object:root=true
+
+// MultiClusterIngressList contains a list of MultiClusterIngress.
+type MultiClusterIngressList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	// +listType=set
+	Items []MultiClusterIngress `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MultiClusterIngress{}, &MultiClusterIngressList{})
+}
@@ -12,20 +12,54 @@ import (
 
 // MultiClusterServiceSpec defines the desired state of MultiClusterService.
 type MultiClusterServiceSpec struct {
-	// ServiceImport is the reference to the Service with the same name exported in the member clusters.
+	// ServiceImport is the reference to the Service with the same name exported in the member clusters; the
+	// Service with the same name as the MultiClusterService is imported if the name is not set.
+	// +optional
 	ServiceImport ServiceImportRef `json:"serviceImport,omitempty"`
+
+	// DNSLabelName is the DNS label assigned to the public IP address of the load balancer, which makes the
+	// multi-cluster service reachable at <dnsLabelName>.<region>.cloudapp.azure.com; it is ignored for internal
+	// load balancers and headless services.
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^([a-z]([-a-z0-9]*[a-z0-9])?)$`
+	// +optional
+	DNSLabelName string `json:"dnsLabelName,omitempty"`
+
+	// TrafficPolicy configures how the load balancer routes the traffic to the imported endpoints.
+	// +optional
+	TrafficPolicy *MultiClusterServiceTrafficPolicy `json:"trafficPolicy,omitempty"`
+}
+
+// MultiClusterServiceTrafficPolicy configures how the load balancer of a multi-cluster service routes the traffic.
+type MultiClusterServiceTrafficPolicy struct {
+	// ExternalTrafficPolicy is the externalTrafficPolicy of the derived Service; "Local" preserves the client
+	// source IP. Defaults to "Cluster".
+	// +kubebuilder:validation:Enum=Cluster;Local
+	// +optional
+	ExternalTrafficPolicy corev1.ServiceExternalTrafficPolicy `json:"externalTrafficPolicy,omitempty"`
+
+	// LoadBalancerSourceRanges restricts the client IP ranges allowed to reach the load balancer; all the clients
+	// are allowed if it is not set.
+	// +optional
+	LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges,omitempty"`
+
+	// SessionAffinity is the session affinity of the derived Service; "ClientIP" routes the connections from the
+	// same client to the same endpoint. Defaults to the session affinity the exported Services agree on, or "None".
+	// +kubebuilder:validation:Enum=None;ClientIP
+	// +optional
+	SessionAffinity corev1.ServiceAffinity `json:"sessionAffinity,omitempty"`
 }
 
 // ServiceImportRef is the reference to the ServiceImport. To consume multi-cluster service, users are expected to use
 // ServiceImport. When mcs controller sees the MCS definition, the ServiceImport will be created in the importing
 // cluster to represent the multi-cluster service.
 type ServiceImportRef struct {
-	// Name is the name of the referent.
+	// Name is the name of the referent; it defaults to the name of the MultiClusterService.
 	//
 	// +kubebuilder:validation:MaxLength=63
 	// +kubebuilder:validation:Pattern=`^([a-z]([-a-z0-9]*[a-z0-9])?)$`
-	// +required
-	Name string `json:"name"`
+	// +optional
+	Name string `json:"name,omitempty"`
 }
 
 // MultiClusterServiceStatus represents the current status of a multi-cluster service.
@@ -52,6 +86,11 @@ const (
 	// multi-cluster service and its configurations have been recognized as valid by a mcs-controller.
 	// This will be false if the ServiceImport is not found in the hub cluster.
 	MultiClusterServiceValid MultiClusterServiceConditionType = "Valid"
+
+	// MultiClusterServiceIPFamilyMismatch means that one or more clusters export the Service imported by this
+	// multi-cluster service with IP families that the importing cluster does not support; endpoints of the
+	// unsupported IP families are not imported.
+	MultiClusterServiceIPFamilyMismatch MultiClusterServiceConditionType = "IPFamilyMismatch"
 )
 
 // +kubebuilder:object:root=true
@@ -69,11 +108,21 @@ type MultiClusterService struct {
 	// +optional
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec MultiClusterServiceSpec `json:"spec"`
+	// +optional
+	Spec MultiClusterServiceSpec `json:"spec,omitempty"`
 	// +optional
 	Status MultiClusterServiceStatus `json:"status,omitempty"`
 }
 
+// ServiceImportName returns the name of the ServiceImport of the MultiClusterService, which defaults to the name of
+// the MultiClusterService.
+func (in *MultiClusterService) ServiceImportName() string {
+	if in.Spec.ServiceImport.Name != "" {
+		return in.Spec.ServiceImport.Name
+	}
+	return in.Name
+}
+
 //+kubebuilder:object:root=true
 
 // MultiClusterServiceList contains a list of MultiClusterService.
@@ -0,0 +1,58 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NamespaceSamenessPolicySpec specifies the namespaces which participate in multi-cluster networking.
+type NamespaceSamenessPolicySpec struct {
+	// allowedNamespaces selects the namespaces which participate in multi-cluster networking; all the namespaces
+	// are allowed if it is not set. Namespaces can be selected by name with the kubernetes.io/metadata.name label.
+	// +optional
+	AllowedNamespaces *metav1.LabelSelector `json:"allowedNamespaces,omitempty"`
+
+	// deniedNamespaces selects the namespaces which never participate in multi-cluster networking; it takes
+	// precedence over allowedNamespaces.
+	// +optional
+	DeniedNamespaces *metav1.LabelSelector `json:"deniedNamespaces,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,categories={fleet-networking},shortName=nssameness
+// +kubebuilder:printcolumn:JSONPath=`.metadata.creationTimestamp`,name="Age",type=date
+
+// NamespaceSamenessPolicy restricts the namespaces whose Services can be exported to the fleet. It is a fleet-level
+// API: the same policies are expected in the hub cluster, which rejects the exports from the namespaces they do not
+// allow, and in every member cluster, which refuses to export the Services in those namespaces; they are usually
+// propagated from the hub cluster to the member clusters by the fleet.
+//
+// A namespace participates in multi-cluster networking only if it is allowed by every policy; all the namespaces
+// participate if there is no policy.
+type NamespaceSamenessPolicy struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +kubebuilder:validation:Required
+	Spec NamespaceSamenessPolicySpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// NamespaceSamenessPolicyList contains a list of NamespaceSamenessPolicy.
+type NamespaceSamenessPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	// +listType=set
+	Items []NamespaceSamenessPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NamespaceSamenessPolicy{}, &NamespaceSamenessPolicyList{})
+}
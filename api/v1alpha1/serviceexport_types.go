@@ -6,7 +6,12 @@ Licensed under the MIT license.
 package v1alpha1
 
 import (
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // ServiceExportConditionType identifies a specific condition on a ServiceExport.
@@ -21,8 +26,130 @@ const (
 	// field(s) under contention, which cluster won, and why.
 	// Users should not expect detailed per-cluster information in the conflict message.
 	ServiceExportConflict ServiceExportConditionType = "Conflict"
+	// ServiceExportDenied means that the export is vetoed by the denylist managed in the hub cluster; a denied
+	// export is excluded from the ServiceImport regardless of the behavior of the member cluster.
+	ServiceExportDenied ServiceExportConditionType = "Denied"
+	// ServiceExportQuarantined means that the member cluster of the export is quarantined by the fleet operator in
+	// the hub cluster; a quarantined export is excluded from the ServiceImport until the cluster is re-enabled.
+	ServiceExportQuarantined ServiceExportConditionType = "Quarantined"
+	// ServiceExportUnhealthy means that the hub has failed the export over to the other member clusters exporting
+	// the Service, as its member cluster stops reporting or it has no ready endpoints; an unhealthy export is
+	// excluded from the ServiceImport until it recovers.
+	ServiceExportUnhealthy ServiceExportConditionType = "Unhealthy"
+	// ServiceExportStale means that the networking agent of the member cluster of the export has stopped renewing
+	// its heartbeat for longer than the grace period set in the hub cluster; a stale export is excluded from the
+	// ServiceImport until the agent reports again.
+	ServiceExportStale ServiceExportConditionType = "Stale"
+	// ServiceExportPendingApproval means that the hub requires the exports to be approved by a fleet admin and the
+	// export is not approved yet with a ServiceExportApproval; a pending export is excluded from the ServiceImport
+	// until it is approved.
+	ServiceExportPendingApproval ServiceExportConditionType = "PendingApproval"
+	// ServiceExportQuotaExceeded means that the export would exceed an ExportQuota of the hub cluster, limiting the
+	// services or endpoints exported from its member cluster or namespace; an export over quota is excluded from the
+	// ServiceImport until the quota admits it.
+	ServiceExportQuotaExceeded ServiceExportConditionType = "QuotaExceeded"
+	// ServiceExportTenantConflict means that the ServiceImport of the Service is owned by another tenant of the fleet,
+	// as the hub partitions the fleet by tenant; the export is excluded from the ServiceImport until the exports of
+	// the owning tenant leave it.
+	ServiceExportTenantConflict ServiceExportConditionType = "TenantConflict"
+	// ServiceExportImported means that at least one member cluster other than the exporting cluster imports the
+	// Service; it is reported only if the hub tracks the demand for the exports, in which case the member cluster
+	// may hold back the endpoints of the Services no other cluster imports.
+	ServiceExportImported ServiceExportConditionType = "Imported"
+	// ServiceExportPaused means that the reconciliation of the ServiceExport is paused with the
+	// "networking.fleet.azure.com/paused" annotation; the Service stays exported as it is until the annotation is
+	// removed.
+	ServiceExportPaused ServiceExportConditionType = "Paused"
 )
 
+// ServiceExportSpec describes how a Service is exported.
+type ServiceExportSpec struct {
+	// endpointSelector, if set, limits the export to the endpoints backed by Pods whose labels match the
+	// selector, e.g. for canary or partial exposure of a Service. Endpoints that are not backed by Pods are
+	// excluded as well. If unset, all the ready endpoints of the Service are exported.
+	// +optional
+	EndpointSelector *metav1.LabelSelector `json:"endpointSelector,omitempty"`
+
+	// ports, if set, limits the export to the ports of the Service selected by name or by port number, e.g. to
+	// keep the admin or debug ports of a Service from being reachable from the other clusters; a port is
+	// exported if either its name or its port number is listed. If unset, all the ports of the Service are
+	// exported.
+	// +optional
+	// +listType=atomic
+	Ports []intstr.IntOrString `json:"ports,omitempty"`
+
+	// exportedLabels are the labels to merge onto the ServiceImport of the Service in all the member clusters,
+	// e.g. for service mesh or DNS integrations keyed off the ServiceImport metadata. When the exporting clusters
+	// disagree on a label, the value of the oldest export is used, and the ExportedMetadataConflict condition is
+	// set on the ServiceImport in the hub cluster. Keys with the "networking.fleet.azure.com/" prefix are
+	// reserved and are not exported.
+	// +optional
+	ExportedLabels map[string]string `json:"exportedLabels,omitempty"`
+
+	// exportedAnnotations are the annotations to merge onto the ServiceImport of the Service in all the member
+	// clusters; they are handled in the same way as the exportedLabels.
+	// +optional
+	ExportedAnnotations map[string]string `json:"exportedAnnotations,omitempty"`
+
+	// allowedConsumers, if set, restricts the member clusters which can import the Service to the ones it lists or
+	// selects, e.g. to keep a sensitive internal Service from being exposed to the whole fleet; the endpoints of
+	// the Service are not distributed to the other clusters. When several clusters export the Service, a member
+	// cluster can import it only if it is allowed by every export which restricts its consumers. If unset, the
+	// Service can be imported by all the member clusters.
+	// +optional
+	AllowedConsumers *ConsumerSelector `json:"allowedConsumers,omitempty"`
+}
+
+// ConsumerSelector selects the member clusters allowed to import an exported Service; a member cluster is allowed
+// if it is listed by name or selected by its labels.
+type ConsumerSelector struct {
+	// clusters lists the names of the member clusters allowed to import the Service.
+	// +optional
+	// +listType=set
+	Clusters []string `json:"clusters,omitempty"`
+
+	// clusterSelector selects the member clusters allowed to import the Service by the labels of their
+	// MemberClusters in the hub cluster.
+	// +optional
+	ClusterSelector *metav1.LabelSelector `json:"clusterSelector,omitempty"`
+}
+
+// Allows returns true if the member cluster, with the given labels, is allowed to import the Service; it returns an
+// error if the cluster selector is invalid.
+func (in *ConsumerSelector) Allows(cluster string, clusterLabels map[string]string) (bool, error) {
+	if slices.Contains(in.Clusters, cluster) {
+		return true, nil
+	}
+	if in.ClusterSelector == nil {
+		return false, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(in.ClusterSelector)
+	if err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(clusterLabels)), nil
+}
+
+// SelectsPort returns true if the port of the Service is exported.
+func (in *ServiceExportSpec) SelectsPort(port corev1.ServicePort) bool {
+	if len(in.Ports) == 0 {
+		return true
+	}
+	for _, selected := range in.Ports {
+		switch selected.Type {
+		case intstr.Int:
+			if selected.IntVal == port.Port {
+				return true
+			}
+		case intstr.String:
+			if port.Name != "" && selected.StrVal == port.Name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // ServiceExportStatus contains the current status of an export.
 type ServiceExportStatus struct {
 	// +optional
@@ -35,6 +162,7 @@ type ServiceExportStatus struct {
 
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:scope=Namespaced,categories={fleet-networking},shortName=svcexport
+// +kubebuilder:storageversion
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:JSONPath=`.status.conditions[?(@.type=='Valid')].status`,name="Is-Valid",type=string
 // +kubebuilder:printcolumn:JSONPath=`.status.conditions[?(@.type=='Conflict')].status`,name="Is-Conflicted",type=string
@@ -54,6 +182,8 @@ type ServiceExport struct {
 	// +optional
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 	// +optional
+	Spec ServiceExportSpec `json:"spec,omitempty"`
+	// +optional
 	Status ServiceExportStatus `json:"status,omitempty"`
 }
 
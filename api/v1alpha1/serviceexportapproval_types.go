@@ -0,0 +1,52 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ServiceExportApprovalSpec specifies the member clusters whose exports of a Service are approved.
+type ServiceExportApprovalSpec struct {
+	// clusters lists the IDs of the member clusters whose exports of the Service are approved; the exports of all
+	// the member clusters are approved if it is empty.
+	// +optional
+	// +listType=set
+	Clusters []string `json:"clusters,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,categories={fleet-networking},shortName=svcexportapproval
+// +kubebuilder:printcolumn:JSONPath=`.spec.clusters`,name="Clusters",type=string
+// +kubebuilder:printcolumn:JSONPath=`.metadata.creationTimestamp`,name="Age",type=date
+
+// ServiceExportApproval approves the exports of a Service when the hub requires the exports to be reviewed by a
+// fleet admin before their endpoints propagate. It is created in the hub cluster, in the namespace of the Service
+// and with the name of the Service; an export which is not approved is reported as pending approval and excluded
+// from the ServiceImport, and deleting the approval withdraws the exports again.
+type ServiceExportApproval struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +optional
+	Spec ServiceExportApprovalSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ServiceExportApprovalList contains a list of ServiceExportApproval.
+type ServiceExportApprovalList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	// +listType=set
+	Items []ServiceExportApproval `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ServiceExportApproval{}, &ServiceExportApprovalList{})
+}
@@ -0,0 +1,69 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,categories={fleet-networking},shortName=svcexportsummary
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:JSONPath=`.status.contributingClusters`,name="Clusters",type=integer
+// +kubebuilder:printcolumn:JSONPath=`.status.totalEndpoints`,name="Endpoints",type=integer
+// +kubebuilder:printcolumn:JSONPath=`.status.conflictedClusters`,name="Conflicted",type=integer
+// +kubebuilder:printcolumn:JSONPath=`.status.lastSyncTime`,name="Last-Sync",type=date
+
+// ServiceExportSummary summarizes the fleet-wide state of an exported Service, for dashboards and tools which
+// should not have to read every InternalServiceExport of the Service.
+//
+// It is derived from the ServiceImport of the same namespace and name in the hub cluster, which owns it, and is
+// maintained by the hub networking controller manager; it must not be edited by users.
+type ServiceExportSummary struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// status is the summary of the exported Service.
+	// +optional
+	Status ServiceExportSummaryStatus `json:"status,omitempty"`
+}
+
+// ServiceExportSummaryStatus is the summary of an exported Service.
+type ServiceExportSummaryStatus struct {
+	// clusters summarizes the export of the Service from each cluster, sorted by cluster.
+	// +optional
+	// +listType=atomic
+	Clusters []ClusterExportSummary `json:"clusters,omitempty"`
+
+	// contributingClusters is the number of clusters whose exports back the ServiceImport.
+	ContributingClusters int32 `json:"contributingClusters"`
+
+	// totalEndpoints is the number of endpoints exported by the contributing clusters.
+	TotalEndpoints int32 `json:"totalEndpoints"`
+
+	// conflictedClusters is the number of clusters whose exports are in conflict with the ServiceImport.
+	ConflictedClusters int32 `json:"conflictedClusters"`
+
+	// lastSyncTime is the last time the summary changed to reflect the ServiceImport.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ServiceExportSummaryList contains a list of ServiceExportSummary.
+type ServiceExportSummaryList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	// +listType=set
+	Items []ServiceExportSummary `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ServiceExportSummary{}, &ServiceExportSummaryList{})
+}
@@ -13,6 +13,7 @@ import (
 
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:scope=Namespaced,categories={fleet-networking},shortName=svcimport
+// +kubebuilder:storageversion
 // +kubebuilder:subresource:status
 
 // ServiceImport describes a service imported from clusters in a ClusterSet.
@@ -38,6 +39,33 @@ const (
 	Headless ServiceImportType = "Headless"
 )
 
+// ConflictResolutionPolicy decides whose spec a ServiceImport takes when the Services exported from the member
+// clusters are in conflict.
+// +kubebuilder:validation:Enum=OldestExportWins;ClusterPriority;Manual
+type ConflictResolutionPolicy string
+
+const (
+	// OldestExportWins takes the spec of the Service which has been exported for the longest time.
+	OldestExportWins ConflictResolutionPolicy = "OldestExportWins"
+	// ClusterPriority takes the spec of the Service exported from the member cluster with the highest priority,
+	// which is set with the "networking.fleet.azure.com/export-priority" label on its MemberCluster in the hub
+	// cluster; clusters of the same priority fall back to OldestExportWins.
+	ClusterPriority ConflictResolutionPolicy = "ClusterPriority"
+	// ManualConflictResolution takes the spec of the Service exported from the member cluster named by the
+	// "networking.fleet.azure.com/conflict-resolution-winner" annotation on the ServiceImport in the hub cluster;
+	// it falls back to OldestExportWins if the annotation is not set or the cluster does not export the Service.
+	ManualConflictResolution ConflictResolutionPolicy = "Manual"
+)
+
+// ConflictResolutionStatus reports how the spec of a ServiceImport was resolved.
+type ConflictResolutionStatus struct {
+	// policy is the conflict resolution policy used to resolve the spec.
+	Policy ConflictResolutionPolicy `json:"policy"`
+
+	// winningCluster is the name of the cluster whose exported Service spec the ServiceImport takes.
+	WinningCluster string `json:"winningCluster"`
+}
+
 // ServicePort represents the port on which the service is exposed.
 type ServicePort struct {
 	// The name of this port within the service. This must be a DNS_LABEL.
@@ -117,7 +145,136 @@ type ServiceImportStatus struct {
 	// +patchMergeKey=cluster
 	// +listType=map
 	// +listMapKey=cluster
-	Clusters []ClusterStatus `json:"clusters,omitempty"`
+	Clusters []ServiceImportClusterStatus `json:"clusters,omitempty"`
+
+	// endpointDistribution breaks down the healthy endpoints behind this ServiceImport by exporting cluster and
+	// zone, with the share of traffic each group should receive. It is informational and can be used by zone-aware
+	// data planes to distribute traffic across clusters in proportion to the healthy endpoints in each zone.
+	// +optional
+	// +listType=atomic
+	EndpointDistribution []EndpointDistribution `json:"endpointDistribution,omitempty"`
+
+	// dnsTTLSeconds is the effective TTL, in seconds, that DNS integrations should use when caching the records
+	// of this ServiceImport. It is the minimum of the TTL hints set by the exporting clusters via the
+	// "fleet.azure.com/dns-ttl" annotation on their ServiceExports; clusters without a valid hint contribute the
+	// default TTL configured on the hub.
+	// +optional
+	DNSTTLSeconds *int64 `json:"dnsTTLSeconds,omitempty"`
+
+	// healthCheckAnnotations are the health-check annotations of the exported Service, e.g. the health-check path
+	// and port, that dataplanes actively health-checking the imported endpoints should use. When the exporting
+	// clusters disagree on an annotation, the value set by the cluster whose name sorts first is used, and the
+	// HealthCheckConflict condition is set.
+	// +optional
+	HealthCheckAnnotations map[string]string `json:"healthCheckAnnotations,omitempty"`
+
+	// exportedLabels are the labels declared by the ServiceExports of the exporting clusters, which are merged
+	// onto the ServiceImports in the member clusters. When the exporting clusters disagree on a label, the value
+	// of the oldest export is used, and the ExportedMetadataConflict condition is set.
+	// +optional
+	ExportedLabels map[string]string `json:"exportedLabels,omitempty"`
+
+	// exportedAnnotations are the annotations declared by the ServiceExports of the exporting clusters, which are
+	// merged onto the ServiceImports in the member clusters; they are handled in the same way as the
+	// exportedLabels.
+	// +optional
+	ExportedAnnotations map[string]string `json:"exportedAnnotations,omitempty"`
+
+	// allowedConsumers are the distinct consumer restrictions declared by the ServiceExports of the exporting
+	// clusters; a member cluster can import the Service only if it is allowed by each of them. It is empty if no
+	// exporting cluster restricts the consumers. It is only reported in the hub cluster.
+	// +optional
+	// +listType=atomic
+	AllowedConsumers []ConsumerSelector `json:"allowedConsumers,omitempty"`
+
+	// clusterExportSummaries summarizes the exports of every cluster contributing to this ServiceImport,
+	// including the ones in conflict, sorted by cluster name; it gives a single view of the fleet-wide health
+	// of the service.
+	// +optional
+	// +listType=map
+	// +listMapKey=cluster
+	ClusterExportSummaries []ClusterExportSummary `json:"clusterExportSummaries,omitempty"`
+
+	// conflictResolution reports the policy used to resolve the spec of this ServiceImport and the cluster whose
+	// exported Service spec won; the exports in conflict with the winning spec are reported as conflicted.
+	// +optional
+	ConflictResolution *ConflictResolutionStatus `json:"conflictResolution,omitempty"`
+
+	// +optional
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// ServiceImportConditionType identifies a specific condition on a ServiceImport.
+type ServiceImportConditionType string
+
+const (
+	// ServiceImportSessionAffinityConflict means that the exporting clusters disagree on the session affinity
+	// settings of the Service. When "True", the ServiceImport uses the most conservative settings, i.e. client IP
+	// based session affinity with the minimum timeout among the clusters, and the condition message lists the
+	// settings of each cluster.
+	ServiceImportSessionAffinityConflict ServiceImportConditionType = "SessionAffinityConflict"
+	// ServiceImportNamedPortMissing means that some named ports of the ServiceImport are not served by the
+	// endpoints of some contributing clusters. When "True", the condition message lists the missing ports of each
+	// cluster; traffic to such a port is only routed to the clusters that serve it.
+	ServiceImportNamedPortMissing ServiceImportConditionType = "NamedPortMissing"
+	// ServiceImportHealthCheckConflict means that the exporting clusters disagree on the health-check annotations
+	// of the Service. When "True", the condition message lists the annotations of each cluster.
+	ServiceImportHealthCheckConflict ServiceImportConditionType = "HealthCheckConflict"
+	// ServiceImportExportedMetadataConflict means that the exporting clusters disagree on the exported labels or
+	// annotations of the Service. When "True", the condition message lists the keys in conflict; each of them takes
+	// the value of the oldest export.
+	ServiceImportExportedMetadataConflict ServiceImportConditionType = "ExportedMetadataConflict"
+	// ServiceImportPaused means that the reconciliation of the ServiceImport is paused with the
+	// "networking.fleet.azure.com/paused" annotation; the Service stays imported as it is until the annotation is
+	// removed.
+	ServiceImportPaused ServiceImportConditionType = "Paused"
+	// ServiceImportDenied means that the ServiceImport is not allowed by an ImportPolicy of the member cluster, and
+	// the Service is not imported; the condition message tells which policy denies it.
+	ServiceImportDenied ServiceImportConditionType = "Denied"
+	// ServiceImportNotAllowed means that the member cluster is not allowed to import the Service by the consumer
+	// restrictions of the exporting clusters; the Service is not imported, and the condition message tells why.
+	ServiceImportNotAllowed ServiceImportConditionType = "NotAllowed"
+)
+
+// ClusterExportSummary summarizes the export of a Service from a cluster.
+type ClusterExportSummary struct {
+	// cluster is the name of the exporting cluster.
+	Cluster string `json:"cluster"`
+
+	// valid reports whether the export has been accepted by the hub cluster; it is Unknown while the export is
+	// pending processing, and False while the export is being withdrawn.
+	Valid metav1.ConditionStatus `json:"valid"`
+
+	// conflict mirrors the status of the Conflict condition of the export; it is Unknown before the conflict
+	// resolution completes.
+	Conflict metav1.ConditionStatus `json:"conflict"`
+
+	// endpoints is the number of endpoints the cluster exports.
+	Endpoints int32 `json:"endpoints"`
+}
+
+// EndpointDistribution describes the healthy endpoints that a cluster exports in a zone.
+type EndpointDistribution struct {
+	// cluster is the name of the exporting cluster.
+	Cluster string `json:"cluster"`
+
+	// zone is the zone in which the endpoints reside; it is empty for endpoints exported with no zone information.
+	// +optional
+	Zone string `json:"zone,omitempty"`
+
+	// healthyEndpoints is the number of healthy endpoints the cluster exports in the zone.
+	HealthyEndpoints int32 `json:"healthyEndpoints"`
+
+	// weight is the share, in parts per thousand and rounded down, of the traffic to the imported Service that
+	// should go to the endpoints. It is proportional to the number of healthy endpoints, scaled by the weight of
+	// the export from the cluster.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=1000
+	Weight int32 `json:"weight"`
 }
 
 // ClusterStatus contains service configuration mapped to a specific source cluster.
@@ -126,6 +283,45 @@ type ClusterStatus struct {
 	Cluster string `json:"cluster"`
 }
 
+// ServiceImportClusterStatus contains service configuration mapped to a specific source cluster.
+type ServiceImportClusterStatus struct {
+	// cluster is the name of the exporting cluster. Must be a valid RFC-1123 DNS label.
+	Cluster string `json:"cluster"`
+
+	// allocateLoadBalancerNodePorts is the allocateLoadBalancerNodePorts setting of the Service exported from
+	// the cluster. It is informational only and is set only when the exported Service is of the LoadBalancer type.
+	// +optional
+	AllocateLoadBalancerNodePorts *bool `json:"allocateLoadBalancerNodePorts,omitempty"`
+
+	// loadBalancerIP is the loadBalancerIP setting of the Service exported from the cluster. It is informational
+	// only and is set only when the exported Service is of the LoadBalancer type.
+	// +optional
+	LoadBalancerIP string `json:"loadBalancerIP,omitempty"`
+
+	// ipFamilyPolicy is the ipFamilyPolicy setting of the Service exported from the cluster.
+	// +optional
+	IPFamilyPolicy *corev1.IPFamilyPolicy `json:"ipFamilyPolicy,omitempty"`
+
+	// ipFamilies are the IP families of the Service exported from the cluster.
+	// +optional
+	// +listType=atomic
+	IPFamilies []corev1.IPFamily `json:"ipFamilies,omitempty"`
+
+	// readyEndpoints is the number of ready endpoints the cluster exports; the terminating endpoints which are still
+	// serving are exported as well, but are not counted.
+	// +optional
+	ReadyEndpoints int32 `json:"readyEndpoints"`
+
+	// exportGeneration is the generation of the Service when it was last exported from the cluster.
+	// +optional
+	ExportGeneration int64 `json:"exportGeneration,omitempty"`
+
+	// lastPropagationTime is the time when the latest change of the Service or of its endpoints was exported from
+	// the cluster, as observed by the local clock of the cluster.
+	// +optional
+	LastPropagationTime *metav1.Time `json:"lastPropagationTime,omitempty"`
+}
+
 // +kubebuilder:object:root=true
 
 // ServiceImportList contains a list of ServiceImport.
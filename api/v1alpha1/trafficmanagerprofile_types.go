@@ -91,6 +91,47 @@ type MonitorConfig struct {
 	// +kubebuilder:validation:Maximum=9
 	// +kubebuilder:default=3
 	ToleratedNumberOfFailures *int64 `json:"toleratedNumberOfFailures,omitempty"`
+
+	// The custom headers, e.g. the Host header, sent with the HTTP(S) health checks of the endpoints in this profile.
+	// +optional
+	// +kubebuilder:validation:MaxItems=8
+	// +listType=map
+	// +listMapKey=name
+	CustomHeaders []MonitorConfigCustomHeader `json:"customHeaders,omitempty"`
+
+	// The HTTP status code ranges which Traffic Manager considers healthy for the HTTP(S) health checks of the endpoints
+	// in this profile; only 200 is considered healthy if it is not set.
+	// +optional
+	// +kubebuilder:validation:MaxItems=8
+	ExpectedStatusCodeRanges []MonitorConfigStatusCodeRange `json:"expectedStatusCodeRanges,omitempty"`
+}
+
+// MonitorConfigCustomHeader defines a custom header sent with the health checks.
+type MonitorConfigCustomHeader struct {
+	// The name of the header.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// The value of the header.
+	// +required
+	Value string `json:"value"`
+}
+
+// MonitorConfigStatusCodeRange defines an inclusive range of HTTP status codes.
+// +kubebuilder:validation:XValidation:rule="self.min <= self.max",message="min must not be greater than max"
+type MonitorConfigStatusCodeRange struct {
+	// The lowest status code of the range.
+	// +required
+	// +kubebuilder:validation:Minimum=100
+	// +kubebuilder:validation:Maximum=999
+	Min int32 `json:"min"`
+
+	// The highest status code of the range.
+	// +required
+	// +kubebuilder:validation:Minimum=100
+	// +kubebuilder:validation:Maximum=999
+	Max int32 `json:"max"`
 }
 
 // TrafficManagerMonitorProtocol defines the protocol used to probe for endpoint health.
@@ -14,63 +14,103 @@ import (
 	"k8s.io/api/discovery/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterStatus) DeepCopyInto(out *ClusterStatus) {
+func (in *AutoExportPolicy) DeepCopyInto(out *AutoExportPolicy) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterStatus.
-func (in *ClusterStatus) DeepCopy() *ClusterStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoExportPolicy.
+func (in *AutoExportPolicy) DeepCopy() *AutoExportPolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterStatus)
+	out := new(AutoExportPolicy)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AutoExportPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Endpoint) DeepCopyInto(out *Endpoint) {
+func (in *AutoExportPolicyList) DeepCopyInto(out *AutoExportPolicyList) {
 	*out = *in
-	if in.Addresses != nil {
-		in, out := &in.Addresses, &out.Addresses
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]AutoExportPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Endpoint.
-func (in *Endpoint) DeepCopy() *Endpoint {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoExportPolicyList.
+func (in *AutoExportPolicyList) DeepCopy() *AutoExportPolicyList {
 	if in == nil {
 		return nil
 	}
-	out := new(Endpoint)
+	out := new(AutoExportPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AutoExportPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoExportPolicySpec) DeepCopyInto(out *AutoExportPolicySpec) {
+	*out = *in
+	in.ServiceSelector.DeepCopyInto(&out.ServiceSelector)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoExportPolicySpec.
+func (in *AutoExportPolicySpec) DeepCopy() *AutoExportPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoExportPolicySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EndpointSliceExport) DeepCopyInto(out *EndpointSliceExport) {
+func (in *BackendTrafficPolicy) DeepCopyInto(out *BackendTrafficPolicy) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointSliceExport.
-func (in *EndpointSliceExport) DeepCopy() *EndpointSliceExport {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackendTrafficPolicy.
+func (in *BackendTrafficPolicy) DeepCopy() *BackendTrafficPolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(EndpointSliceExport)
+	out := new(BackendTrafficPolicy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *EndpointSliceExport) DeepCopyObject() runtime.Object {
+func (in *BackendTrafficPolicy) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -78,31 +118,31 @@ func (in *EndpointSliceExport) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EndpointSliceExportList) DeepCopyInto(out *EndpointSliceExportList) {
+func (in *BackendTrafficPolicyList) DeepCopyInto(out *BackendTrafficPolicyList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]EndpointSliceExport, len(*in))
+		*out = make([]BackendTrafficPolicy, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointSliceExportList.
-func (in *EndpointSliceExportList) DeepCopy() *EndpointSliceExportList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackendTrafficPolicyList.
+func (in *BackendTrafficPolicyList) DeepCopy() *BackendTrafficPolicyList {
 	if in == nil {
 		return nil
 	}
-	out := new(EndpointSliceExportList)
+	out := new(BackendTrafficPolicyList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *EndpointSliceExportList) DeepCopyObject() runtime.Object {
+func (in *BackendTrafficPolicyList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -110,56 +150,72 @@ func (in *EndpointSliceExportList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EndpointSliceExportSpec) DeepCopyInto(out *EndpointSliceExportSpec) {
+func (in *BackendTrafficPolicyRef) DeepCopyInto(out *BackendTrafficPolicyRef) {
 	*out = *in
-	if in.Endpoints != nil {
-		in, out := &in.Endpoints, &out.Endpoints
-		*out = make([]Endpoint, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackendTrafficPolicyRef.
+func (in *BackendTrafficPolicyRef) DeepCopy() *BackendTrafficPolicyRef {
+	if in == nil {
+		return nil
 	}
-	if in.Ports != nil {
-		in, out := &in.Ports, &out.Ports
-		*out = make([]v1.EndpointPort, len(*in))
+	out := new(BackendTrafficPolicyRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackendTrafficPolicySpec) DeepCopyInto(out *BackendTrafficPolicySpec) {
+	*out = *in
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]ClusterTrafficSettings, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	in.EndpointSliceReference.DeepCopyInto(&out.EndpointSliceReference)
-	out.OwnerServiceReference = in.OwnerServiceReference
+	if in.DefaultPriority != nil {
+		in, out := &in.DefaultPriority, &out.DefaultPriority
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DefaultWeight != nil {
+		in, out := &in.DefaultWeight, &out.DefaultWeight
+		*out = new(int32)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointSliceExportSpec.
-func (in *EndpointSliceExportSpec) DeepCopy() *EndpointSliceExportSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackendTrafficPolicySpec.
+func (in *BackendTrafficPolicySpec) DeepCopy() *BackendTrafficPolicySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(EndpointSliceExportSpec)
+	out := new(BackendTrafficPolicySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EndpointSliceImport) DeepCopyInto(out *EndpointSliceImport) {
+func (in *ClusterAutoExportPolicy) DeepCopyInto(out *ClusterAutoExportPolicy) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointSliceImport.
-func (in *EndpointSliceImport) DeepCopy() *EndpointSliceImport {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterAutoExportPolicy.
+func (in *ClusterAutoExportPolicy) DeepCopy() *ClusterAutoExportPolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(EndpointSliceImport)
+	out := new(ClusterAutoExportPolicy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *EndpointSliceImport) DeepCopyObject() runtime.Object {
+func (in *ClusterAutoExportPolicy) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -167,31 +223,31 @@ func (in *EndpointSliceImport) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EndpointSliceImportList) DeepCopyInto(out *EndpointSliceImportList) {
+func (in *ClusterAutoExportPolicyList) DeepCopyInto(out *ClusterAutoExportPolicyList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]EndpointSliceImport, len(*in))
+		*out = make([]ClusterAutoExportPolicy, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointSliceImportList.
-func (in *EndpointSliceImportList) DeepCopy() *EndpointSliceImportList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterAutoExportPolicyList.
+func (in *ClusterAutoExportPolicyList) DeepCopy() *ClusterAutoExportPolicyList {
 	if in == nil {
 		return nil
 	}
-	out := new(EndpointSliceImportList)
+	out := new(ClusterAutoExportPolicyList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *EndpointSliceImportList) DeepCopyObject() runtime.Object {
+func (in *ClusterAutoExportPolicyList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -199,44 +255,54 @@ func (in *EndpointSliceImportList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ExportedObjectReference) DeepCopyInto(out *ExportedObjectReference) {
+func (in *ClusterAutoExportPolicySpec) DeepCopyInto(out *ClusterAutoExportPolicySpec) {
 	*out = *in
-	in.ExportedSince.DeepCopyInto(&out.ExportedSince)
+	in.NamespaceSelector.DeepCopyInto(&out.NamespaceSelector)
+	in.ServiceSelector.DeepCopyInto(&out.ServiceSelector)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExportedObjectReference.
-func (in *ExportedObjectReference) DeepCopy() *ExportedObjectReference {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterAutoExportPolicySpec.
+func (in *ClusterAutoExportPolicySpec) DeepCopy() *ClusterAutoExportPolicySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ExportedObjectReference)
+	out := new(ClusterAutoExportPolicySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FromCluster) DeepCopyInto(out *FromCluster) {
+func (in *ClusterExportSummary) DeepCopyInto(out *ClusterExportSummary) {
 	*out = *in
-	out.ClusterStatus = in.ClusterStatus
-	if in.Weight != nil {
-		in, out := &in.Weight, &out.Weight
-		*out = new(int64)
-		**out = **in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterExportSummary.
+func (in *ClusterExportSummary) DeepCopy() *ClusterExportSummary {
+	if in == nil {
+		return nil
 	}
+	out := new(ClusterExportSummary)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FromCluster.
-func (in *FromCluster) DeepCopy() *FromCluster {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterNetworkLink) DeepCopyInto(out *ClusterNetworkLink) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterNetworkLink.
+func (in *ClusterNetworkLink) DeepCopy() *ClusterNetworkLink {
 	if in == nil {
 		return nil
 	}
-	out := new(FromCluster)
+	out := new(ClusterNetworkLink)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InternalServiceExport) DeepCopyInto(out *InternalServiceExport) {
+func (in *ClusterNetworkTopology) DeepCopyInto(out *ClusterNetworkTopology) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -244,18 +310,18 @@ func (in *InternalServiceExport) DeepCopyInto(out *InternalServiceExport) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternalServiceExport.
-func (in *InternalServiceExport) DeepCopy() *InternalServiceExport {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterNetworkTopology.
+func (in *ClusterNetworkTopology) DeepCopy() *ClusterNetworkTopology {
 	if in == nil {
 		return nil
 	}
-	out := new(InternalServiceExport)
+	out := new(ClusterNetworkTopology)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *InternalServiceExport) DeepCopyObject() runtime.Object {
+func (in *ClusterNetworkTopology) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -263,31 +329,31 @@ func (in *InternalServiceExport) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InternalServiceExportList) DeepCopyInto(out *InternalServiceExportList) {
+func (in *ClusterNetworkTopologyList) DeepCopyInto(out *ClusterNetworkTopologyList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]InternalServiceExport, len(*in))
+		*out = make([]ClusterNetworkTopology, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternalServiceExportList.
-func (in *InternalServiceExportList) DeepCopy() *InternalServiceExportList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterNetworkTopologyList.
+func (in *ClusterNetworkTopologyList) DeepCopy() *ClusterNetworkTopologyList {
 	if in == nil {
 		return nil
 	}
-	out := new(InternalServiceExportList)
+	out := new(ClusterNetworkTopologyList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *InternalServiceExportList) DeepCopyObject() runtime.Object {
+func (in *ClusterNetworkTopologyList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -295,62 +361,1769 @@ func (in *InternalServiceExportList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InternalServiceExportSpec) DeepCopyInto(out *InternalServiceExportSpec) {
+func (in *ClusterNetworkTopologySpec) DeepCopyInto(out *ClusterNetworkTopologySpec) {
 	*out = *in
-	if in.Ports != nil {
-		in, out := &in.Ports, &out.Ports
-		*out = make([]ServicePort, len(*in))
+	if in.Links != nil {
+		in, out := &in.Links, &out.Links
+		*out = make([]ClusterNetworkLink, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterNetworkTopologySpec.
+func (in *ClusterNetworkTopologySpec) DeepCopy() *ClusterNetworkTopologySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterNetworkTopologySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterNetworkTopologyStatus) DeepCopyInto(out *ClusterNetworkTopologyStatus) {
+	*out = *in
+	if in.ProbedLinks != nil {
+		in, out := &in.ProbedLinks, &out.ProbedLinks
+		*out = make([]ProbedClusterNetworkLink, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	in.ServiceReference.DeepCopyInto(&out.ServiceReference)
-	if in.PublicIPResourceID != nil {
-		in, out := &in.PublicIPResourceID, &out.PublicIPResourceID
-		*out = new(string)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterNetworkTopologyStatus.
+func (in *ClusterNetworkTopologyStatus) DeepCopy() *ClusterNetworkTopologyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterNetworkTopologyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterStatus) DeepCopyInto(out *ClusterStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterStatus.
+func (in *ClusterStatus) DeepCopy() *ClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterTrafficSettings) DeepCopyInto(out *ClusterTrafficSettings) {
+	*out = *in
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int32)
 		**out = **in
 	}
 	if in.Weight != nil {
 		in, out := &in.Weight, &out.Weight
-		*out = new(int64)
+		*out = new(int32)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternalServiceExportSpec.
-func (in *InternalServiceExportSpec) DeepCopy() *InternalServiceExportSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterTrafficSettings.
+func (in *ClusterTrafficSettings) DeepCopy() *ClusterTrafficSettings {
 	if in == nil {
 		return nil
 	}
-	out := new(InternalServiceExportSpec)
+	out := new(ClusterTrafficSettings)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InternalServiceExportStatus) DeepCopyInto(out *InternalServiceExportStatus) {
+func (in *ConflictResolutionStatus) DeepCopyInto(out *ConflictResolutionStatus) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]metav1.Condition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConflictResolutionStatus.
+func (in *ConflictResolutionStatus) DeepCopy() *ConflictResolutionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ConflictResolutionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsumerSelector) DeepCopyInto(out *ConsumerSelector) {
+	*out = *in
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ClusterSelector != nil {
+		in, out := &in.ClusterSelector, &out.ClusterSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsumerSelector.
+func (in *ConsumerSelector) DeepCopy() *ConsumerSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsumerSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControllerErrorSummary) DeepCopyInto(out *ControllerErrorSummary) {
+	*out = *in
+	if in.LastErrorTime != nil {
+		in, out := &in.LastErrorTime, &out.LastErrorTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControllerErrorSummary.
+func (in *ControllerErrorSummary) DeepCopy() *ControllerErrorSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(ControllerErrorSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Endpoint) DeepCopyInto(out *Endpoint) {
+	*out = *in
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Zone != nil {
+		in, out := &in.Zone, &out.Zone
+		*out = new(string)
+		**out = **in
+	}
+	if in.Region != nil {
+		in, out := &in.Region, &out.Region
+		*out = new(string)
+		**out = **in
+	}
+	if in.Hostname != nil {
+		in, out := &in.Hostname, &out.Hostname
+		*out = new(string)
+		**out = **in
 	}
+	in.Conditions.DeepCopyInto(&out.Conditions)
+	if in.NodeName != nil {
+		in, out := &in.NodeName, &out.NodeName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Hints != nil {
+		in, out := &in.Hints, &out.Hints
+		*out = new(v1.EndpointHints)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Endpoint.
+func (in *Endpoint) DeepCopy() *Endpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(Endpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EndpointDistribution) DeepCopyInto(out *EndpointDistribution) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointDistribution.
+func (in *EndpointDistribution) DeepCopy() *EndpointDistribution {
+	if in == nil {
+		return nil
+	}
+	out := new(EndpointDistribution)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EndpointSliceExport) DeepCopyInto(out *EndpointSliceExport) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointSliceExport.
+func (in *EndpointSliceExport) DeepCopy() *EndpointSliceExport {
+	if in == nil {
+		return nil
+	}
+	out := new(EndpointSliceExport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EndpointSliceExport) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EndpointSliceExportList) DeepCopyInto(out *EndpointSliceExportList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]EndpointSliceExport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointSliceExportList.
+func (in *EndpointSliceExportList) DeepCopy() *EndpointSliceExportList {
+	if in == nil {
+		return nil
+	}
+	out := new(EndpointSliceExportList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EndpointSliceExportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EndpointSliceExportSpec) DeepCopyInto(out *EndpointSliceExportSpec) {
+	*out = *in
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = make([]Endpoint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]v1.EndpointPort, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Gateway != nil {
+		in, out := &in.Gateway, &out.Gateway
+		*out = new(GatewayEndpoints)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PrivateLink != nil {
+		in, out := &in.PrivateLink, &out.PrivateLink
+		*out = new(PrivateLinkEndpoints)
+		(*in).DeepCopyInto(*out)
+	}
+	in.EndpointSliceReference.DeepCopyInto(&out.EndpointSliceReference)
+	out.OwnerServiceReference = in.OwnerServiceReference
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointSliceExportSpec.
+func (in *EndpointSliceExportSpec) DeepCopy() *EndpointSliceExportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EndpointSliceExportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EndpointSliceImport) DeepCopyInto(out *EndpointSliceImport) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointSliceImport.
+func (in *EndpointSliceImport) DeepCopy() *EndpointSliceImport {
+	if in == nil {
+		return nil
+	}
+	out := new(EndpointSliceImport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EndpointSliceImport) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EndpointSliceImportList) DeepCopyInto(out *EndpointSliceImportList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]EndpointSliceImport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointSliceImportList.
+func (in *EndpointSliceImportList) DeepCopy() *EndpointSliceImportList {
+	if in == nil {
+		return nil
+	}
+	out := new(EndpointSliceImportList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EndpointSliceImportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExportQuota) DeepCopyInto(out *ExportQuota) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExportQuota.
+func (in *ExportQuota) DeepCopy() *ExportQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(ExportQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ExportQuota) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExportQuotaList) DeepCopyInto(out *ExportQuotaList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ExportQuota, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExportQuotaList.
+func (in *ExportQuotaList) DeepCopy() *ExportQuotaList {
+	if in == nil {
+		return nil
+	}
+	out := new(ExportQuotaList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ExportQuotaList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExportQuotaSpec) DeepCopyInto(out *ExportQuotaSpec) {
+	*out = *in
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxServices != nil {
+		in, out := &in.MaxServices, &out.MaxServices
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxEndpoints != nil {
+		in, out := &in.MaxEndpoints, &out.MaxEndpoints
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExportQuotaSpec.
+func (in *ExportQuotaSpec) DeepCopy() *ExportQuotaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExportQuotaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExportedObjectReference) DeepCopyInto(out *ExportedObjectReference) {
+	*out = *in
+	in.ExportedSince.DeepCopyInto(&out.ExportedSince)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExportedObjectReference.
+func (in *ExportedObjectReference) DeepCopy() *ExportedObjectReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ExportedObjectReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FromCluster) DeepCopyInto(out *FromCluster) {
+	*out = *in
+	out.ClusterStatus = in.ClusterStatus
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FromCluster.
+func (in *FromCluster) DeepCopy() *FromCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(FromCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FrontDoorBackend) DeepCopyInto(out *FrontDoorBackend) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FrontDoorBackend.
+func (in *FrontDoorBackend) DeepCopy() *FrontDoorBackend {
+	if in == nil {
+		return nil
+	}
+	out := new(FrontDoorBackend)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FrontDoorBackend) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FrontDoorBackendList) DeepCopyInto(out *FrontDoorBackendList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]FrontDoorBackend, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FrontDoorBackendList.
+func (in *FrontDoorBackendList) DeepCopy() *FrontDoorBackendList {
+	if in == nil {
+		return nil
+	}
+	out := new(FrontDoorBackendList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FrontDoorBackendList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FrontDoorBackendSpec) DeepCopyInto(out *FrontDoorBackendSpec) {
+	*out = *in
+	out.Profile = in.Profile
+	out.Backend = in.Backend
+	if in.HealthProbe != nil {
+		in, out := &in.HealthProbe, &out.HealthProbe
+		*out = new(FrontDoorHealthProbe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HTTPPort != nil {
+		in, out := &in.HTTPPort, &out.HTTPPort
+		*out = new(int32)
+		**out = **in
+	}
+	if in.HTTPSPort != nil {
+		in, out := &in.HTTPSPort, &out.HTTPSPort
+		*out = new(int32)
+		**out = **in
+	}
+	if in.OriginHostHeader != nil {
+		in, out := &in.OriginHostHeader, &out.OriginHostHeader
+		*out = new(string)
+		**out = **in
+	}
+	if in.TrafficPolicyRef != nil {
+		in, out := &in.TrafficPolicyRef, &out.TrafficPolicyRef
+		*out = new(BackendTrafficPolicyRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FrontDoorBackendSpec.
+func (in *FrontDoorBackendSpec) DeepCopy() *FrontDoorBackendSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FrontDoorBackendSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FrontDoorBackendStatus) DeepCopyInto(out *FrontDoorBackendStatus) {
+	*out = *in
+	if in.Origins != nil {
+		in, out := &in.Origins, &out.Origins
+		*out = make([]FrontDoorOriginStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FrontDoorBackendStatus.
+func (in *FrontDoorBackendStatus) DeepCopy() *FrontDoorBackendStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FrontDoorBackendStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FrontDoorHealthProbe) DeepCopyInto(out *FrontDoorHealthProbe) {
+	*out = *in
+	if in.Path != nil {
+		in, out := &in.Path, &out.Path
+		*out = new(string)
+		**out = **in
+	}
+	if in.Protocol != nil {
+		in, out := &in.Protocol, &out.Protocol
+		*out = new(FrontDoorHealthProbeProtocol)
+		**out = **in
+	}
+	if in.RequestType != nil {
+		in, out := &in.RequestType, &out.RequestType
+		*out = new(FrontDoorHealthProbeRequestType)
+		**out = **in
+	}
+	if in.IntervalInSeconds != nil {
+		in, out := &in.IntervalInSeconds, &out.IntervalInSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FrontDoorHealthProbe.
+func (in *FrontDoorHealthProbe) DeepCopy() *FrontDoorHealthProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(FrontDoorHealthProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FrontDoorOriginStatus) DeepCopyInto(out *FrontDoorOriginStatus) {
+	*out = *in
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FrontDoorOriginStatus.
+func (in *FrontDoorOriginStatus) DeepCopy() *FrontDoorOriginStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FrontDoorOriginStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FrontDoorProfileRef) DeepCopyInto(out *FrontDoorProfileRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FrontDoorProfileRef.
+func (in *FrontDoorProfileRef) DeepCopy() *FrontDoorProfileRef {
+	if in == nil {
+		return nil
+	}
+	out := new(FrontDoorProfileRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayEndpoints) DeepCopyInto(out *GatewayEndpoints) {
+	*out = *in
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = make([]Endpoint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]v1.EndpointPort, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayEndpoints.
+func (in *GatewayEndpoints) DeepCopy() *GatewayEndpoints {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayEndpoints)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImportPolicy) DeepCopyInto(out *ImportPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImportPolicy.
+func (in *ImportPolicy) DeepCopy() *ImportPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ImportPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ImportPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImportPolicyList) DeepCopyInto(out *ImportPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ImportPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImportPolicyList.
+func (in *ImportPolicyList) DeepCopy() *ImportPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(ImportPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ImportPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImportPolicySpec) DeepCopyInto(out *ImportPolicySpec) {
+	*out = *in
+	if in.AllowedNamespaces != nil {
+		in, out := &in.AllowedNamespaces, &out.AllowedNamespaces
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeniedNamespaces != nil {
+		in, out := &in.DeniedNamespaces, &out.DeniedNamespaces
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AllowedServices != nil {
+		in, out := &in.AllowedServices, &out.AllowedServices
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeniedServices != nil {
+		in, out := &in.DeniedServices, &out.DeniedServices
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AllowedClusters != nil {
+		in, out := &in.AllowedClusters, &out.AllowedClusters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeniedClusters != nil {
+		in, out := &in.DeniedClusters, &out.DeniedClusters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImportPolicySpec.
+func (in *ImportPolicySpec) DeepCopy() *ImportPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ImportPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InternalMemberNetworkStatus) DeepCopyInto(out *InternalMemberNetworkStatus) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternalMemberNetworkStatus.
+func (in *InternalMemberNetworkStatus) DeepCopy() *InternalMemberNetworkStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(InternalMemberNetworkStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *InternalMemberNetworkStatus) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InternalMemberNetworkStatusList) DeepCopyInto(out *InternalMemberNetworkStatusList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]InternalMemberNetworkStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternalMemberNetworkStatusList.
+func (in *InternalMemberNetworkStatusList) DeepCopy() *InternalMemberNetworkStatusList {
+	if in == nil {
+		return nil
+	}
+	out := new(InternalMemberNetworkStatusList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *InternalMemberNetworkStatusList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InternalMemberNetworkStatusStatus) DeepCopyInto(out *InternalMemberNetworkStatusStatus) {
+	*out = *in
+	if in.LastHeartbeatTime != nil {
+		in, out := &in.LastHeartbeatTime, &out.LastHeartbeatTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Errors != nil {
+		in, out := &in.Errors, &out.Errors
+		*out = make([]ControllerErrorSummary, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Tunnel != nil {
+		in, out := &in.Tunnel, &out.Tunnel
+		*out = new(TunnelPeer)
+		**out = **in
+	}
+	if in.TunnelPeers != nil {
+		in, out := &in.TunnelPeers, &out.TunnelPeers
+		*out = make([]TunnelPeer, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternalMemberNetworkStatusStatus.
+func (in *InternalMemberNetworkStatusStatus) DeepCopy() *InternalMemberNetworkStatusStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(InternalMemberNetworkStatusStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InternalServiceExport) DeepCopyInto(out *InternalServiceExport) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternalServiceExport.
+func (in *InternalServiceExport) DeepCopy() *InternalServiceExport {
+	if in == nil {
+		return nil
+	}
+	out := new(InternalServiceExport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *InternalServiceExport) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InternalServiceExportList) DeepCopyInto(out *InternalServiceExportList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]InternalServiceExport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternalServiceExportList.
+func (in *InternalServiceExportList) DeepCopy() *InternalServiceExportList {
+	if in == nil {
+		return nil
+	}
+	out := new(InternalServiceExportList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *InternalServiceExportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InternalServiceExportSpec) DeepCopyInto(out *InternalServiceExportSpec) {
+	*out = *in
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]ServicePort, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.ServiceReference.DeepCopyInto(&out.ServiceReference)
+	if in.PublicIPResourceID != nil {
+		in, out := &in.PublicIPResourceID, &out.PublicIPResourceID
+		*out = new(string)
+		**out = **in
+	}
+	if in.AllocateLoadBalancerNodePorts != nil {
+		in, out := &in.AllocateLoadBalancerNodePorts, &out.AllocateLoadBalancerNodePorts
+		*out = new(bool)
+		**out = **in
+	}
+	if in.LoadBalancerIngress != nil {
+		in, out := &in.LoadBalancerIngress, &out.LoadBalancerIngress
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IPFamilyPolicy != nil {
+		in, out := &in.IPFamilyPolicy, &out.IPFamilyPolicy
+		*out = new(corev1.IPFamilyPolicy)
+		**out = **in
+	}
+	if in.IPFamilies != nil {
+		in, out := &in.IPFamilies, &out.IPFamilies
+		*out = make([]corev1.IPFamily, len(*in))
+		copy(*out, *in)
+	}
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int64)
+		**out = **in
+	}
+	if in.DNSTTLSeconds != nil {
+		in, out := &in.DNSTTLSeconds, &out.DNSTTLSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.CanaryPercent != nil {
+		in, out := &in.CanaryPercent, &out.CanaryPercent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SessionAffinityTimeoutSeconds != nil {
+		in, out := &in.SessionAffinityTimeoutSeconds, &out.SessionAffinityTimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.HealthCheckAnnotations != nil {
+		in, out := &in.HealthCheckAnnotations, &out.HealthCheckAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExportedLabels != nil {
+		in, out := &in.ExportedLabels, &out.ExportedLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExportedAnnotations != nil {
+		in, out := &in.ExportedAnnotations, &out.ExportedAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.AllowedConsumers != nil {
+		in, out := &in.AllowedConsumers, &out.AllowedConsumers
+		*out = new(ConsumerSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternalServiceExportSpec.
+func (in *InternalServiceExportSpec) DeepCopy() *InternalServiceExportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(InternalServiceExportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InternalServiceExportStatus) DeepCopyInto(out *InternalServiceExportStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternalServiceExportStatus.
+func (in *InternalServiceExportStatus) DeepCopy() *InternalServiceExportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(InternalServiceExportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InternalServiceImport) DeepCopyInto(out *InternalServiceImport) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternalServiceImport.
+func (in *InternalServiceImport) DeepCopy() *InternalServiceImport {
+	if in == nil {
+		return nil
+	}
+	out := new(InternalServiceImport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *InternalServiceImport) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InternalServiceImportList) DeepCopyInto(out *InternalServiceImportList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]InternalServiceImport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternalServiceImportList.
+func (in *InternalServiceImportList) DeepCopy() *InternalServiceImportList {
+	if in == nil {
+		return nil
+	}
+	out := new(InternalServiceImportList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *InternalServiceImportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InternalServiceImportSpec) DeepCopyInto(out *InternalServiceImportSpec) {
+	*out = *in
+	in.ServiceImportReference.DeepCopyInto(&out.ServiceImportReference)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternalServiceImportSpec.
+func (in *InternalServiceImportSpec) DeepCopy() *InternalServiceImportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(InternalServiceImportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitorConfig) DeepCopyInto(out *MonitorConfig) {
+	*out = *in
+	if in.IntervalInSeconds != nil {
+		in, out := &in.IntervalInSeconds, &out.IntervalInSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Path != nil {
+		in, out := &in.Path, &out.Path
+		*out = new(string)
+		**out = **in
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Protocol != nil {
+		in, out := &in.Protocol, &out.Protocol
+		*out = new(TrafficManagerMonitorProtocol)
+		**out = **in
+	}
+	if in.TimeoutInSeconds != nil {
+		in, out := &in.TimeoutInSeconds, &out.TimeoutInSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ToleratedNumberOfFailures != nil {
+		in, out := &in.ToleratedNumberOfFailures, &out.ToleratedNumberOfFailures
+		*out = new(int64)
+		**out = **in
+	}
+	if in.CustomHeaders != nil {
+		in, out := &in.CustomHeaders, &out.CustomHeaders
+		*out = make([]MonitorConfigCustomHeader, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExpectedStatusCodeRanges != nil {
+		in, out := &in.ExpectedStatusCodeRanges, &out.ExpectedStatusCodeRanges
+		*out = make([]MonitorConfigStatusCodeRange, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitorConfig.
+func (in *MonitorConfig) DeepCopy() *MonitorConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitorConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitorConfigCustomHeader) DeepCopyInto(out *MonitorConfigCustomHeader) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitorConfigCustomHeader.
+func (in *MonitorConfigCustomHeader) DeepCopy() *MonitorConfigCustomHeader {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitorConfigCustomHeader)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitorConfigStatusCodeRange) DeepCopyInto(out *MonitorConfigStatusCodeRange) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitorConfigStatusCodeRange.
+func (in *MonitorConfigStatusCodeRange) DeepCopy() *MonitorConfigStatusCodeRange {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitorConfigStatusCodeRange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultiClusterIngress) DeepCopyInto(out *MultiClusterIngress) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiClusterIngress.
+func (in *MultiClusterIngress) DeepCopy() *MultiClusterIngress {
+	if in == nil {
+		return nil
+	}
+	out := new(MultiClusterIngress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MultiClusterIngress) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultiClusterIngressBackend) DeepCopyInto(out *MultiClusterIngressBackend) {
+	*out = *in
+	if in.HTTPPort != nil {
+		in, out := &in.HTTPPort, &out.HTTPPort
+		*out = new(int32)
+		**out = **in
+	}
+	if in.HTTPSPort != nil {
+		in, out := &in.HTTPSPort, &out.HTTPSPort
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiClusterIngressBackend.
+func (in *MultiClusterIngressBackend) DeepCopy() *MultiClusterIngressBackend {
+	if in == nil {
+		return nil
+	}
+	out := new(MultiClusterIngressBackend)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultiClusterIngressDomainStatus) DeepCopyInto(out *MultiClusterIngressDomainStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiClusterIngressDomainStatus.
+func (in *MultiClusterIngressDomainStatus) DeepCopy() *MultiClusterIngressDomainStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MultiClusterIngressDomainStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultiClusterIngressList) DeepCopyInto(out *MultiClusterIngressList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MultiClusterIngress, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiClusterIngressList.
+func (in *MultiClusterIngressList) DeepCopy() *MultiClusterIngressList {
+	if in == nil {
+		return nil
+	}
+	out := new(MultiClusterIngressList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MultiClusterIngressList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultiClusterIngressPath) DeepCopyInto(out *MultiClusterIngressPath) {
+	*out = *in
+	in.Backend.DeepCopyInto(&out.Backend)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiClusterIngressPath.
+func (in *MultiClusterIngressPath) DeepCopy() *MultiClusterIngressPath {
+	if in == nil {
+		return nil
+	}
+	out := new(MultiClusterIngressPath)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultiClusterIngressRule) DeepCopyInto(out *MultiClusterIngressRule) {
+	*out = *in
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]MultiClusterIngressPath, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiClusterIngressRule.
+func (in *MultiClusterIngressRule) DeepCopy() *MultiClusterIngressRule {
+	if in == nil {
+		return nil
+	}
+	out := new(MultiClusterIngressRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultiClusterIngressSpec) DeepCopyInto(out *MultiClusterIngressSpec) {
+	*out = *in
+	out.Profile = in.Profile
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]MultiClusterIngressRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = make([]MultiClusterIngressTLS, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.HealthProbe != nil {
+		in, out := &in.HealthProbe, &out.HealthProbe
+		*out = new(FrontDoorHealthProbe)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiClusterIngressSpec.
+func (in *MultiClusterIngressSpec) DeepCopy() *MultiClusterIngressSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MultiClusterIngressSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultiClusterIngressStatus) DeepCopyInto(out *MultiClusterIngressStatus) {
+	*out = *in
+	if in.CustomDomains != nil {
+		in, out := &in.CustomDomains, &out.CustomDomains
+		*out = make([]MultiClusterIngressDomainStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiClusterIngressStatus.
+func (in *MultiClusterIngressStatus) DeepCopy() *MultiClusterIngressStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MultiClusterIngressStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultiClusterIngressTLS) DeepCopyInto(out *MultiClusterIngressTLS) {
+	*out = *in
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecretName != nil {
+		in, out := &in.SecretName, &out.SecretName
+		*out = new(string)
+		**out = **in
+	}
+	if in.MinimumTLSVersion != nil {
+		in, out := &in.MinimumTLSVersion, &out.MinimumTLSVersion
+		*out = new(MultiClusterIngressTLSVersion)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiClusterIngressTLS.
+func (in *MultiClusterIngressTLS) DeepCopy() *MultiClusterIngressTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(MultiClusterIngressTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultiClusterService) DeepCopyInto(out *MultiClusterService) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiClusterService.
+func (in *MultiClusterService) DeepCopy() *MultiClusterService {
+	if in == nil {
+		return nil
+	}
+	out := new(MultiClusterService)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MultiClusterService) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultiClusterServiceList) DeepCopyInto(out *MultiClusterServiceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MultiClusterService, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiClusterServiceList.
+func (in *MultiClusterServiceList) DeepCopy() *MultiClusterServiceList {
+	if in == nil {
+		return nil
+	}
+	out := new(MultiClusterServiceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MultiClusterServiceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultiClusterServiceSpec) DeepCopyInto(out *MultiClusterServiceSpec) {
+	*out = *in
+	out.ServiceImport = in.ServiceImport
+	if in.TrafficPolicy != nil {
+		in, out := &in.TrafficPolicy, &out.TrafficPolicy
+		*out = new(MultiClusterServiceTrafficPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiClusterServiceSpec.
+func (in *MultiClusterServiceSpec) DeepCopy() *MultiClusterServiceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MultiClusterServiceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultiClusterServiceStatus) DeepCopyInto(out *MultiClusterServiceStatus) {
+	*out = *in
+	in.LoadBalancer.DeepCopyInto(&out.LoadBalancer)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiClusterServiceStatus.
+func (in *MultiClusterServiceStatus) DeepCopy() *MultiClusterServiceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MultiClusterServiceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultiClusterServiceTrafficPolicy) DeepCopyInto(out *MultiClusterServiceTrafficPolicy) {
+	*out = *in
+	if in.LoadBalancerSourceRanges != nil {
+		in, out := &in.LoadBalancerSourceRanges, &out.LoadBalancerSourceRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiClusterServiceTrafficPolicy.
+func (in *MultiClusterServiceTrafficPolicy) DeepCopy() *MultiClusterServiceTrafficPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(MultiClusterServiceTrafficPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceSamenessPolicy) DeepCopyInto(out *NamespaceSamenessPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceSamenessPolicy.
+func (in *NamespaceSamenessPolicy) DeepCopy() *NamespaceSamenessPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceSamenessPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NamespaceSamenessPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceSamenessPolicyList) DeepCopyInto(out *NamespaceSamenessPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NamespaceSamenessPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceSamenessPolicyList.
+func (in *NamespaceSamenessPolicyList) DeepCopy() *NamespaceSamenessPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceSamenessPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NamespaceSamenessPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceSamenessPolicySpec) DeepCopyInto(out *NamespaceSamenessPolicySpec) {
+	*out = *in
+	if in.AllowedNamespaces != nil {
+		in, out := &in.AllowedNamespaces, &out.AllowedNamespaces
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeniedNamespaces != nil {
+		in, out := &in.DeniedNamespaces, &out.DeniedNamespaces
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceSamenessPolicySpec.
+func (in *NamespaceSamenessPolicySpec) DeepCopy() *NamespaceSamenessPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceSamenessPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OwnerServiceReference) DeepCopyInto(out *OwnerServiceReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OwnerServiceReference.
+func (in *OwnerServiceReference) DeepCopy() *OwnerServiceReference {
+	if in == nil {
+		return nil
+	}
+	out := new(OwnerServiceReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrivateLinkEndpoints) DeepCopyInto(out *PrivateLinkEndpoints) {
+	*out = *in
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]v1.EndpointPort, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrivateLinkEndpoints.
+func (in *PrivateLinkEndpoints) DeepCopy() *PrivateLinkEndpoints {
+	if in == nil {
+		return nil
+	}
+	out := new(PrivateLinkEndpoints)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbedClusterNetworkLink) DeepCopyInto(out *ProbedClusterNetworkLink) {
+	*out = *in
+	out.ClusterNetworkLink = in.ClusterNetworkLink
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbedClusterNetworkLink.
+func (in *ProbedClusterNetworkLink) DeepCopy() *ProbedClusterNetworkLink {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbedClusterNetworkLink)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternalServiceExportStatus.
-func (in *InternalServiceExportStatus) DeepCopy() *InternalServiceExportStatus {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReachabilityProbeResult) DeepCopyInto(out *ReachabilityProbeResult) {
+	*out = *in
+	in.ProbeTime.DeepCopyInto(&out.ProbeTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReachabilityProbeResult.
+func (in *ReachabilityProbeResult) DeepCopy() *ReachabilityProbeResult {
 	if in == nil {
 		return nil
 	}
-	out := new(InternalServiceExportStatus)
+	out := new(ReachabilityProbeResult)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InternalServiceImport) DeepCopyInto(out *InternalServiceImport) {
+func (in *ServiceExport) DeepCopyInto(out *ServiceExport) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -358,18 +2131,18 @@ func (in *InternalServiceImport) DeepCopyInto(out *InternalServiceImport) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternalServiceImport.
-func (in *InternalServiceImport) DeepCopy() *InternalServiceImport {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceExport.
+func (in *ServiceExport) DeepCopy() *ServiceExport {
 	if in == nil {
 		return nil
 	}
-	out := new(InternalServiceImport)
+	out := new(ServiceExport)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *InternalServiceImport) DeepCopyObject() runtime.Object {
+func (in *ServiceExport) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -377,31 +2150,25 @@ func (in *InternalServiceImport) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InternalServiceImportList) DeepCopyInto(out *InternalServiceImportList) {
+func (in *ServiceExportApproval) DeepCopyInto(out *ServiceExportApproval) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]InternalServiceImport, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternalServiceImportList.
-func (in *InternalServiceImportList) DeepCopy() *InternalServiceImportList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceExportApproval.
+func (in *ServiceExportApproval) DeepCopy() *ServiceExportApproval {
 	if in == nil {
 		return nil
 	}
-	out := new(InternalServiceImportList)
+	out := new(ServiceExportApproval)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *InternalServiceImportList) DeepCopyObject() runtime.Object {
+func (in *ServiceExportApproval) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -409,119 +2176,83 @@ func (in *InternalServiceImportList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InternalServiceImportSpec) DeepCopyInto(out *InternalServiceImportSpec) {
+func (in *ServiceExportApprovalList) DeepCopyInto(out *ServiceExportApprovalList) {
 	*out = *in
-	in.ServiceImportReference.DeepCopyInto(&out.ServiceImportReference)
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ServiceExportApproval, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternalServiceImportSpec.
-func (in *InternalServiceImportSpec) DeepCopy() *InternalServiceImportSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceExportApprovalList.
+func (in *ServiceExportApprovalList) DeepCopy() *ServiceExportApprovalList {
 	if in == nil {
 		return nil
 	}
-	out := new(InternalServiceImportSpec)
+	out := new(ServiceExportApprovalList)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MonitorConfig) DeepCopyInto(out *MonitorConfig) {
-	*out = *in
-	if in.IntervalInSeconds != nil {
-		in, out := &in.IntervalInSeconds, &out.IntervalInSeconds
-		*out = new(int64)
-		**out = **in
-	}
-	if in.Path != nil {
-		in, out := &in.Path, &out.Path
-		*out = new(string)
-		**out = **in
-	}
-	if in.Port != nil {
-		in, out := &in.Port, &out.Port
-		*out = new(int64)
-		**out = **in
-	}
-	if in.Protocol != nil {
-		in, out := &in.Protocol, &out.Protocol
-		*out = new(TrafficManagerMonitorProtocol)
-		**out = **in
-	}
-	if in.TimeoutInSeconds != nil {
-		in, out := &in.TimeoutInSeconds, &out.TimeoutInSeconds
-		*out = new(int64)
-		**out = **in
-	}
-	if in.ToleratedNumberOfFailures != nil {
-		in, out := &in.ToleratedNumberOfFailures, &out.ToleratedNumberOfFailures
-		*out = new(int64)
-		**out = **in
-	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitorConfig.
-func (in *MonitorConfig) DeepCopy() *MonitorConfig {
-	if in == nil {
-		return nil
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ServiceExportApprovalList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
 	}
-	out := new(MonitorConfig)
-	in.DeepCopyInto(out)
-	return out
+	return nil
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MultiClusterService) DeepCopyInto(out *MultiClusterService) {
+func (in *ServiceExportApprovalSpec) DeepCopyInto(out *ServiceExportApprovalSpec) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	in.Status.DeepCopyInto(&out.Status)
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiClusterService.
-func (in *MultiClusterService) DeepCopy() *MultiClusterService {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceExportApprovalSpec.
+func (in *ServiceExportApprovalSpec) DeepCopy() *ServiceExportApprovalSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(MultiClusterService)
+	out := new(ServiceExportApprovalSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *MultiClusterService) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MultiClusterServiceList) DeepCopyInto(out *MultiClusterServiceList) {
+func (in *ServiceExportList) DeepCopyInto(out *ServiceExportList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]MultiClusterService, len(*in))
+		*out = make([]ServiceExport, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiClusterServiceList.
-func (in *MultiClusterServiceList) DeepCopy() *MultiClusterServiceList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceExportList.
+func (in *ServiceExportList) DeepCopy() *ServiceExportList {
 	if in == nil {
 		return nil
 	}
-	out := new(MultiClusterServiceList)
+	out := new(ServiceExportList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *MultiClusterServiceList) DeepCopyObject() runtime.Object {
+func (in *ServiceExportList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -529,25 +2260,52 @@ func (in *MultiClusterServiceList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MultiClusterServiceSpec) DeepCopyInto(out *MultiClusterServiceSpec) {
+func (in *ServiceExportSpec) DeepCopyInto(out *ServiceExportSpec) {
 	*out = *in
-	out.ServiceImport = in.ServiceImport
+	if in.EndpointSelector != nil {
+		in, out := &in.EndpointSelector, &out.EndpointSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]intstr.IntOrString, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExportedLabels != nil {
+		in, out := &in.ExportedLabels, &out.ExportedLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExportedAnnotations != nil {
+		in, out := &in.ExportedAnnotations, &out.ExportedAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.AllowedConsumers != nil {
+		in, out := &in.AllowedConsumers, &out.AllowedConsumers
+		*out = new(ConsumerSelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiClusterServiceSpec.
-func (in *MultiClusterServiceSpec) DeepCopy() *MultiClusterServiceSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceExportSpec.
+func (in *ServiceExportSpec) DeepCopy() *ServiceExportSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(MultiClusterServiceSpec)
+	out := new(ServiceExportSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MultiClusterServiceStatus) DeepCopyInto(out *MultiClusterServiceStatus) {
+func (in *ServiceExportStatus) DeepCopyInto(out *ServiceExportStatus) {
 	*out = *in
-	in.LoadBalancer.DeepCopyInto(&out.LoadBalancer)
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]metav1.Condition, len(*in))
@@ -557,51 +2315,36 @@ func (in *MultiClusterServiceStatus) DeepCopyInto(out *MultiClusterServiceStatus
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiClusterServiceStatus.
-func (in *MultiClusterServiceStatus) DeepCopy() *MultiClusterServiceStatus {
-	if in == nil {
-		return nil
-	}
-	out := new(MultiClusterServiceStatus)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OwnerServiceReference) DeepCopyInto(out *OwnerServiceReference) {
-	*out = *in
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OwnerServiceReference.
-func (in *OwnerServiceReference) DeepCopy() *OwnerServiceReference {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceExportStatus.
+func (in *ServiceExportStatus) DeepCopy() *ServiceExportStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(OwnerServiceReference)
+	out := new(ServiceExportStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ServiceExport) DeepCopyInto(out *ServiceExport) {
+func (in *ServiceExportSummary) DeepCopyInto(out *ServiceExportSummary) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceExport.
-func (in *ServiceExport) DeepCopy() *ServiceExport {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceExportSummary.
+func (in *ServiceExportSummary) DeepCopy() *ServiceExportSummary {
 	if in == nil {
 		return nil
 	}
-	out := new(ServiceExport)
+	out := new(ServiceExportSummary)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ServiceExport) DeepCopyObject() runtime.Object {
+func (in *ServiceExportSummary) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -609,31 +2352,31 @@ func (in *ServiceExport) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ServiceExportList) DeepCopyInto(out *ServiceExportList) {
+func (in *ServiceExportSummaryList) DeepCopyInto(out *ServiceExportSummaryList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ServiceExport, len(*in))
+		*out = make([]ServiceExportSummary, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceExportList.
-func (in *ServiceExportList) DeepCopy() *ServiceExportList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceExportSummaryList.
+func (in *ServiceExportSummaryList) DeepCopy() *ServiceExportSummaryList {
 	if in == nil {
 		return nil
 	}
-	out := new(ServiceExportList)
+	out := new(ServiceExportSummaryList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ServiceExportList) DeepCopyObject() runtime.Object {
+func (in *ServiceExportSummaryList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -641,23 +2384,25 @@ func (in *ServiceExportList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ServiceExportStatus) DeepCopyInto(out *ServiceExportStatus) {
+func (in *ServiceExportSummaryStatus) DeepCopyInto(out *ServiceExportSummaryStatus) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]metav1.Condition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]ClusterExportSummary, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceExportStatus.
-func (in *ServiceExportStatus) DeepCopy() *ServiceExportStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceExportSummaryStatus.
+func (in *ServiceExportSummaryStatus) DeepCopy() *ServiceExportSummaryStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ServiceExportStatus)
+	out := new(ServiceExportSummaryStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -688,6 +2433,40 @@ func (in *ServiceImport) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceImportClusterStatus) DeepCopyInto(out *ServiceImportClusterStatus) {
+	*out = *in
+	if in.AllocateLoadBalancerNodePorts != nil {
+		in, out := &in.AllocateLoadBalancerNodePorts, &out.AllocateLoadBalancerNodePorts
+		*out = new(bool)
+		**out = **in
+	}
+	if in.IPFamilyPolicy != nil {
+		in, out := &in.IPFamilyPolicy, &out.IPFamilyPolicy
+		*out = new(corev1.IPFamilyPolicy)
+		**out = **in
+	}
+	if in.IPFamilies != nil {
+		in, out := &in.IPFamilies, &out.IPFamilies
+		*out = make([]corev1.IPFamily, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastPropagationTime != nil {
+		in, out := &in.LastPropagationTime, &out.LastPropagationTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceImportClusterStatus.
+func (in *ServiceImportClusterStatus) DeepCopy() *ServiceImportClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceImportClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceImportList) DeepCopyInto(out *ServiceImportList) {
 	*out = *in
@@ -757,9 +2536,66 @@ func (in *ServiceImportStatus) DeepCopyInto(out *ServiceImportStatus) {
 	}
 	if in.Clusters != nil {
 		in, out := &in.Clusters, &out.Clusters
-		*out = make([]ClusterStatus, len(*in))
+		*out = make([]ServiceImportClusterStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EndpointDistribution != nil {
+		in, out := &in.EndpointDistribution, &out.EndpointDistribution
+		*out = make([]EndpointDistribution, len(*in))
+		copy(*out, *in)
+	}
+	if in.DNSTTLSeconds != nil {
+		in, out := &in.DNSTTLSeconds, &out.DNSTTLSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.HealthCheckAnnotations != nil {
+		in, out := &in.HealthCheckAnnotations, &out.HealthCheckAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExportedLabels != nil {
+		in, out := &in.ExportedLabels, &out.ExportedLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExportedAnnotations != nil {
+		in, out := &in.ExportedAnnotations, &out.ExportedAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.AllowedConsumers != nil {
+		in, out := &in.AllowedConsumers, &out.AllowedConsumers
+		*out = make([]ConsumerSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ClusterExportSummaries != nil {
+		in, out := &in.ClusterExportSummaries, &out.ClusterExportSummaries
+		*out = make([]ClusterExportSummary, len(*in))
 		copy(*out, *in)
 	}
+	if in.ConflictResolution != nil {
+		in, out := &in.ConflictResolution, &out.ConflictResolution
+		*out = new(ConflictResolutionStatus)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceImportStatus.
@@ -1090,3 +2926,18 @@ func (in *TrafficManagerProfileStatus) DeepCopy() *TrafficManagerProfileStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TunnelPeer) DeepCopyInto(out *TunnelPeer) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelPeer.
+func (in *TunnelPeer) DeepCopy() *TunnelPeer {
+	if in == nil {
+		return nil
+	}
+	out := new(TunnelPeer)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -5,9 +5,50 @@ Licensed under the MIT license.
 
 package v1beta1
 
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
 // ClusterStatus contains service configuration mapped to a specific source cluster.
 type ClusterStatus struct {
 	// cluster is the name of the exporting cluster. Must be a valid RFC-1123 DNS
 	// label.
 	Cluster string `json:"cluster"`
 }
+
+// ExportedObjectReference helps operators identify the source of an exported object, e.g. an EndpointSliceExport.
+// +structType=atomic
+type ExportedObjectReference struct {
+	// The ID of the cluster where the object is exported.
+	// +kubebuilder:validation:Required
+	ClusterID string `json:"clusterId"`
+	// The API version of the referred object.
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty"`
+	// The kind of the referred object.
+	// +kubebuilder:validation:Required
+	Kind string `json:"kind"`
+	// The namespace of the referred object.
+	// +kubebuilder:validation:Required
+	Namespace string `json:"namespace"`
+	// The name of the referred object.
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+	// The resource version of the referred object.
+	// +kubebuilder:validation:Required
+	ResourceVersion string `json:"resourceVersion"`
+	// The generation of the referred object.
+	// +kubebuilder:validation:Required
+	Generation int64 `json:"generation"`
+	// The UID of the referred object.
+	// +kubebuilder:validation:Required
+	UID types.UID `json:"uid"`
+	// The namespaced name of the referred object.
+	// +kubebuilder:validation:Required
+	NamespacedName string `json:"namespacedName"`
+	// The timestamp from a local clock when the generation of the object is exported.
+	// This field is marked as optional for backwards compatibility reasons.
+	// +kubebuilder:validation:Optional
+	ExportedSince metav1.Time `json:"exportedSince,omitempty"`
+}
@@ -0,0 +1,76 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+// The v1beta1 kinds below share their schema with their v1alpha1 counterparts, so they are converted field by
+// field through their unstructured form. The conversion is strict: a field set in one version and missing from the
+// other fails the conversion rather than being dropped, so a field added to one version only must be added to the
+// other as well, or handled explicitly here; TestConversionSchemaParity catches the missing ones.
+
+// ConvertTo converts the ServiceExport to the hub version.
+func (in *ServiceExport) ConvertTo(hub conversion.Hub) error {
+	return convert(in, hub, v1alpha1.GroupVersion)
+}
+
+// ConvertFrom converts the ServiceExport from the hub version.
+func (in *ServiceExport) ConvertFrom(hub conversion.Hub) error {
+	return convert(hub, in, GroupVersion)
+}
+
+// ConvertTo converts the ServiceImport to the hub version.
+func (in *ServiceImport) ConvertTo(hub conversion.Hub) error {
+	return convert(in, hub, v1alpha1.GroupVersion)
+}
+
+// ConvertFrom converts the ServiceImport from the hub version.
+func (in *ServiceImport) ConvertFrom(hub conversion.Hub) error {
+	return convert(hub, in, GroupVersion)
+}
+
+// ConvertTo converts the InternalServiceExport to the hub version.
+func (in *InternalServiceExport) ConvertTo(hub conversion.Hub) error {
+	return convert(in, hub, v1alpha1.GroupVersion)
+}
+
+// ConvertFrom converts the InternalServiceExport from the hub version.
+func (in *InternalServiceExport) ConvertFrom(hub conversion.Hub) error {
+	return convert(hub, in, GroupVersion)
+}
+
+// ConvertTo converts the EndpointSliceExport to the hub version.
+func (in *EndpointSliceExport) ConvertTo(hub conversion.Hub) error {
+	return convert(in, hub, v1alpha1.GroupVersion)
+}
+
+// ConvertFrom converts the EndpointSliceExport from the hub version.
+func (in *EndpointSliceExport) ConvertFrom(hub conversion.Hub) error {
+	return convert(hub, in, GroupVersion)
+}
+
+// convert copies src into dst through their unstructured form, and sets the API version of dst to the given
+// group version; it returns an error if src sets a field dst has no counterpart for, which would otherwise be
+// silently dropped.
+func convert(src, dst runtime.Object, gv schema.GroupVersion) error {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(src)
+	if err != nil {
+		return fmt.Errorf("failed to convert %T to unstructured: %w", src, err)
+	}
+	obj["apiVersion"] = gv.String()
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructuredWithValidation(obj, dst, true); err != nil {
+		return fmt.Errorf("failed to convert unstructured to %T: %w", dst, err)
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package v1beta1
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+// TestServiceImportConversion tests that a ServiceImport survives a round trip through v1beta1.
+func TestServiceImportConversion(t *testing.T) {
+	ttl := int64(30)
+	want := &v1alpha1.ServiceImport{
+		TypeMeta: metav1.TypeMeta{APIVersion: v1alpha1.GroupVersion.String(), Kind: "ServiceImport"},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "work",
+			Name:      "app",
+			Labels:    map[string]string{"app": "web"},
+		},
+		Status: v1alpha1.ServiceImportStatus{
+			Type:            v1alpha1.ClusterSetIP,
+			SessionAffinity: corev1.ServiceAffinityNone,
+			Ports:           []v1alpha1.ServicePort{{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80}},
+			Clusters:        []v1alpha1.ServiceImportClusterStatus{{Cluster: "member-1", LoadBalancerIP: "1.2.3.4"}},
+			DNSTTLSeconds:   &ttl,
+			Conditions: []metav1.Condition{
+				{Type: string(v1alpha1.ServiceImportNamedPortMissing), Status: metav1.ConditionFalse, Reason: "AllPortsServed"},
+			},
+		},
+	}
+
+	beta := &ServiceImport{}
+	if err := beta.ConvertFrom(want.DeepCopy()); err != nil {
+		t.Fatalf("ConvertFrom() got error %v, want no error", err)
+	}
+	if beta.APIVersion != GroupVersion.String() {
+		t.Errorf("ConvertFrom() apiVersion = %q, want %q", beta.APIVersion, GroupVersion.String())
+	}
+	if beta.Status.Clusters[0].LoadBalancerIP != "1.2.3.4" {
+		t.Errorf("ConvertFrom() clusters = %+v, want the loadBalancerIP preserved", beta.Status.Clusters)
+	}
+
+	got := &v1alpha1.ServiceImport{}
+	if err := beta.ConvertTo(got); err != nil {
+		t.Fatalf("ConvertTo() got error %v, want no error", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ServiceImport round trip mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+// TestAllowedConsumersConversion tests that the consumer restrictions of the ServiceExports, the
+// InternalServiceExports and the ServiceImports survive a round trip through v1beta1, so that a read-modify-write
+// through v1beta1 does not lift them.
+func TestAllowedConsumersConversion(t *testing.T) {
+	allowedConsumers := v1alpha1.ConsumerSelector{
+		Clusters: []string{"member-1"},
+		ClusterSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"env": "prod"},
+		},
+	}
+	objectMeta := metav1.ObjectMeta{Namespace: "work", Name: "app"}
+
+	t.Run("ServiceExport", func(t *testing.T) {
+		want := &v1alpha1.ServiceExport{
+			TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.GroupVersion.String(), Kind: "ServiceExport"},
+			ObjectMeta: objectMeta,
+			Spec:       v1alpha1.ServiceExportSpec{AllowedConsumers: allowedConsumers.DeepCopy()},
+		}
+		beta := &ServiceExport{}
+		if err := beta.ConvertFrom(want.DeepCopy()); err != nil {
+			t.Fatalf("ConvertFrom() got error %v, want no error", err)
+		}
+		got := &v1alpha1.ServiceExport{}
+		if err := beta.ConvertTo(got); err != nil {
+			t.Fatalf("ConvertTo() got error %v, want no error", err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("ServiceExport round trip mismatch (-want, +got):\n%s", diff)
+		}
+	})
+
+	t.Run("InternalServiceExport", func(t *testing.T) {
+		want := &v1alpha1.InternalServiceExport{
+			TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.GroupVersion.String(), Kind: "InternalServiceExport"},
+			ObjectMeta: objectMeta,
+			Spec:       v1alpha1.InternalServiceExportSpec{AllowedConsumers: allowedConsumers.DeepCopy()},
+		}
+		beta := &InternalServiceExport{}
+		if err := beta.ConvertFrom(want.DeepCopy()); err != nil {
+			t.Fatalf("ConvertFrom() got error %v, want no error", err)
+		}
+		got := &v1alpha1.InternalServiceExport{}
+		if err := beta.ConvertTo(got); err != nil {
+			t.Fatalf("ConvertTo() got error %v, want no error", err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("InternalServiceExport round trip mismatch (-want, +got):\n%s", diff)
+		}
+	})
+
+	t.Run("ServiceImport", func(t *testing.T) {
+		want := &v1alpha1.ServiceImport{
+			TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.GroupVersion.String(), Kind: "ServiceImport"},
+			ObjectMeta: objectMeta,
+			Status:     v1alpha1.ServiceImportStatus{AllowedConsumers: []v1alpha1.ConsumerSelector{*allowedConsumers.DeepCopy()}},
+		}
+		beta := &ServiceImport{}
+		if err := beta.ConvertFrom(want.DeepCopy()); err != nil {
+			t.Fatalf("ConvertFrom() got error %v, want no error", err)
+		}
+		got := &v1alpha1.ServiceImport{}
+		if err := beta.ConvertTo(got); err != nil {
+			t.Fatalf("ConvertTo() got error %v, want no error", err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("ServiceImport round trip mismatch (-want, +got):\n%s", diff)
+		}
+	})
+}
+
+// TestConvertUnknownField tests that a field with no counterpart in the target version fails the conversion
+// rather than being dropped.
+func TestConvertUnknownField(t *testing.T) {
+	src := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": v1alpha1.GroupVersion.String(),
+		"kind":       "ServiceExport",
+		"metadata":   map[string]interface{}{"namespace": "work", "name": "app"},
+		"spec":       map[string]interface{}{"notAField": "value"},
+	}}
+	if err := convert(src, &ServiceExport{}, GroupVersion); err == nil || !strings.Contains(err.Error(), "notAField") {
+		t.Errorf("convert() got error %v, want an error on the unknown field notAField", err)
+	}
+}
+
+// TestConversionSchemaParity tests that every field of the converted kinds has a counterpart under the same JSON
+// name in the other version, so that no field can be added to one version only.
+func TestConversionSchemaParity(t *testing.T) {
+	testCases := []struct {
+		kind  string
+		alpha interface{}
+		beta  interface{}
+	}{
+		{kind: "ServiceExport", alpha: v1alpha1.ServiceExport{}, beta: ServiceExport{}},
+		{kind: "ServiceImport", alpha: v1alpha1.ServiceImport{}, beta: ServiceImport{}},
+		{kind: "InternalServiceExport", alpha: v1alpha1.InternalServiceExport{}, beta: InternalServiceExport{}},
+		{kind: "EndpointSliceExport", alpha: v1alpha1.EndpointSliceExport{}, beta: EndpointSliceExport{}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.kind, func(t *testing.T) {
+			alpha, beta := reflect.TypeOf(tc.alpha), reflect.TypeOf(tc.beta)
+			for _, missing := range missingFields(tc.kind, alpha, beta) {
+				t.Errorf("v1alpha1 field %s has no v1beta1 counterpart", missing)
+			}
+			for _, missing := range missingFields(tc.kind, beta, alpha) {
+				t.Errorf("v1beta1 field %s has no v1alpha1 counterpart", missing)
+			}
+		})
+	}
+}
+
+// missingFields returns the paths of the JSON fields of from, and of the types of the API package it nests, which
+// have no counterpart in to.
+func missingFields(path string, from, to reflect.Type) []string {
+	from, to = elemType(from), elemType(to)
+	// The types of the other packages, e.g. metav1.ObjectMeta, are shared by both versions.
+	if from.Kind() != reflect.Struct || to.Kind() != reflect.Struct || !isAPIType(from) {
+		return nil
+	}
+	toFields := map[string]reflect.Type{}
+	for i := 0; i < to.NumField(); i++ {
+		toFields[jsonName(to.Field(i))] = to.Field(i).Type
+	}
+	var missing []string
+	for i := 0; i < from.NumField(); i++ {
+		name := jsonName(from.Field(i))
+		toType, ok := toFields[name]
+		if !ok {
+			missing = append(missing, path+"."+name)
+			continue
+		}
+		missing = append(missing, missingFields(path+"."+name, from.Field(i).Type, toType)...)
+	}
+	return missing
+}
+
+// elemType returns the type of the elements of the pointers, the slices and the maps, and the type itself otherwise.
+func elemType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	return t
+}
+
+// isAPIType returns true if the type is declared in either version of the API.
+func isAPIType(t reflect.Type) bool {
+	return t.PkgPath() == reflect.TypeOf(ServiceExport{}).PkgPath() || t.PkgPath() == reflect.TypeOf(v1alpha1.ServiceExport{}).PkgPath()
+}
+
+// jsonName returns the JSON name of a struct field.
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	return name
+}
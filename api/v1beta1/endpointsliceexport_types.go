@@ -0,0 +1,149 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package v1beta1
+
+import (
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Endpoint includes all exported addresses from a logical backend.
+type Endpoint struct {
+	// Addresses of the Endpoint.
+	// Addresses should be interpreted per its owner EndpointSliceExport's addressType field. This field contains
+	// at least one address and at maximum 100; for more information about this constraint,
+	// see https://kubernetes.io/docs/reference/generated/kubernetes-api/v1.19/#endpoint-v1beta1-discovery-k8s-io.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems:1
+	// +kubebuilder:validation:MaxItems:100
+	Addresses []string `json:"addresses"`
+	// Zone is the name of the zone the Endpoint exists in, as reported in the source EndpointSlice.
+	// +optional
+	Zone *string `json:"zone,omitempty"`
+	// Region is the name of the region the Endpoint exists in, as labeled on the Node hosting the Endpoint in the
+	// exporting cluster.
+	// +optional
+	Region *string `json:"region,omitempty"`
+	// Hostname is the hostname of the Endpoint, as reported in the source EndpointSlice; it is set for the
+	// endpoints of headless Services backed by Pods with a hostname, e.g. the Pods of a StatefulSet, and allows
+	// importing clusters to address each Pod individually.
+	// +optional
+	Hostname *string `json:"hostname,omitempty"`
+	// Conditions are the conditions of the Endpoint, as reported in the source EndpointSlice; an Endpoint exported
+	// without conditions is ready. A terminating Endpoint which is still serving is exported, so that the importing
+	// clusters may fall back to it during a rolling update of the exporting cluster.
+	// +optional
+	Conditions discoveryv1.EndpointConditions `json:"conditions,omitempty"`
+	// NodeName is the name of the Node hosting the Endpoint in the exporting cluster, as reported in the source
+	// EndpointSlice.
+	// +optional
+	NodeName *string `json:"nodeName,omitempty"`
+	// Hints are the topology hints of the Endpoint, as reported in the source EndpointSlice.
+	// +optional
+	Hints *discoveryv1.EndpointHints `json:"hints,omitempty"`
+}
+
+// OwnerServiceReference points to the Service that owns the exported EndpointSlice.
+type OwnerServiceReference struct {
+	// The namespace of the owner Service.
+	// +kubebuilder:validation:Required
+	Namespace string `json:"namespace"`
+	// The name of the owner Service.
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+	// The namespaced name (key) of the owner Service.
+	// +kubebuilder:validation:Required
+	NamespacedName string `json:"namespacedName"`
+}
+
+// GatewayEndpoints are the endpoints and the ports of the east-west gateway an EndpointSlice is exported through.
+type GatewayEndpoints struct {
+	// Endpoints are the endpoints of the gateway; it is empty if the gateway has no address of the address type
+	// of the EndpointSliceExport, or the exported EndpointSlice has no serving endpoints.
+	// +optional
+	// +listType=atomic
+	Endpoints []Endpoint `json:"endpoints,omitempty"`
+	// Ports are the ports of the gateway assigned to the exported ports.
+	// +optional
+	// +listType=atomic
+	Ports []discoveryv1.EndpointPort `json:"ports,omitempty"`
+}
+
+// PrivateLinkEndpoints are the Azure Private Link Service an EndpointSlice is exported through and its ports.
+type PrivateLinkEndpoints struct {
+	// ServiceID is the resource ID of the Azure Private Link Service in front of the internal load balancer of the
+	// owner Service.
+	// +kubebuilder:validation:Required
+	ServiceID string `json:"serviceID"`
+	// Ports are the ports of the load balancer, i.e. the ports of the owner Service, of the exported ports.
+	// +optional
+	// +listType=atomic
+	Ports []discoveryv1.EndpointPort `json:"ports,omitempty"`
+}
+
+// EndpointSliceExportSpec specifies the spec of an exported EndpointSlice.
+type EndpointSliceExportSpec struct {
+	// The type of addresses carried by this EndpointSliceExport; a dual-stack Service is exported with one
+	// EndpointSliceExport for each of its IP families.
+	// +kubebuilder:validation:Enum:="IPv4";"IPv6"
+	// +kubebuilder:default:="IPv4"
+	AddressType discoveryv1.AddressType `json:"addressType"`
+	// A list of unique endpoints in the exported EndpointSlice.
+	// +kubebuilder:validation:Required
+	// +listType=atomic
+	Endpoints []Endpoint `json:"endpoints"`
+	// The list of ports exported by each endpoint in this EndpointSliceExport. Each port must have a unique name.
+	// When the field is empty, it indicates that there are no defined ports. When a port is defined with a nil
+	// port value, it indicates that all ports are exported. Each slice may include a maximum of 100 ports.
+	// +optional
+	// +listType=atomic
+	Ports []discoveryv1.EndpointPort `json:"ports"`
+	// Gateway, if set, is the east-west gateway of the exporting cluster through which the endpoints are
+	// reachable; the hub distributes the endpoints and the ports of the gateway instead to the member clusters
+	// which do not reach the exporting cluster directly, per the ClusterNetworkTopology of the fleet.
+	// +optional
+	Gateway *GatewayEndpoints `json:"gateway,omitempty"`
+	// PrivateLink, if set, is the Azure Private Link Service of the exporting cluster through which the owner
+	// Service is exposed; the member clusters with Private Endpoints connected to it import the addresses of their
+	// Private Endpoints instead.
+	// +optional
+	PrivateLink *PrivateLinkEndpoints `json:"privateLink,omitempty"`
+	// The reference to the source EndpointSlice.
+	// +kubebuilder:validation:Required
+	EndpointSliceReference ExportedObjectReference `json:"endpointSliceReference"`
+	// The reference to the owner Service.
+	// +kubebuilder:validation:Required
+	OwnerServiceReference OwnerServiceReference `json:"ownerServiceReference"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,categories={fleet-networking}
+// +kubebuilder:subresource:status
+
+// EndpointSliceExport is a data transport type that member clusters in the fleet use to upload the spec of an
+// EndpointSlice to the hub cluster.
+type EndpointSliceExport struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// +kubebuilder:validation:Required
+	Spec EndpointSliceExportSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// EndpointSliceExportList contains a list of EndpointSliceExports.
+type EndpointSliceExportList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	// +listType=set
+	Items []EndpointSliceExport `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&EndpointSliceExport{}, &EndpointSliceExportList{})
+}
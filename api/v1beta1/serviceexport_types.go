@@ -0,0 +1,138 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// ServiceExportConditionType identifies a specific condition on a ServiceExport.
+type ServiceExportConditionType string
+
+const (
+	// ServiceExportValid means that the service referenced by this service export has been recognized as valid.
+	// This will be false if the service is found to be unexportable (e.g. ExternalName, not found).
+	ServiceExportValid ServiceExportConditionType = "Valid"
+	// ServiceExportConflict means that there is a conflict between two exports for the same Service.
+	// When "True", the condition message should contain enough information to diagnose the conflict:
+	// field(s) under contention, which cluster won, and why.
+	// Users should not expect detailed per-cluster information in the conflict message.
+	ServiceExportConflict ServiceExportConditionType = "Conflict"
+	// ServiceExportDenied means that the export is vetoed by the denylist managed in the hub cluster; a denied
+	// export is excluded from the ServiceImport regardless of the behavior of the member cluster.
+	ServiceExportDenied ServiceExportConditionType = "Denied"
+	// ServiceExportQuarantined means that the member cluster of the export is quarantined by the fleet operator in
+	// the hub cluster; a quarantined export is excluded from the ServiceImport until the cluster is re-enabled.
+	ServiceExportQuarantined ServiceExportConditionType = "Quarantined"
+	// ServiceExportUnhealthy means that the hub has failed the export over to the other member clusters exporting
+	// the Service, as its member cluster stops reporting or it has no ready endpoints; an unhealthy export is
+	// excluded from the ServiceImport until it recovers.
+	ServiceExportUnhealthy ServiceExportConditionType = "Unhealthy"
+)
+
+// ServiceExportSpec describes how a Service is exported.
+type ServiceExportSpec struct {
+	// endpointSelector, if set, limits the export to the endpoints backed by Pods whose labels match the
+	// selector, e.g. for canary or partial exposure of a Service. Endpoints that are not backed by Pods are
+	// excluded as well. If unset, all the ready endpoints of the Service are exported.
+	// +optional
+	EndpointSelector *metav1.LabelSelector `json:"endpointSelector,omitempty"`
+
+	// ports, if set, limits the export to the ports of the Service selected by name or by port number, e.g. to
+	// keep the admin or debug ports of a Service from being reachable from the other clusters; a port is
+	// exported if either its name or its port number is listed. If unset, all the ports of the Service are
+	// exported.
+	// +optional
+	// +listType=atomic
+	Ports []intstr.IntOrString `json:"ports,omitempty"`
+
+	// exportedLabels are the labels to merge onto the ServiceImport of the Service in all the member clusters,
+	// e.g. for service mesh or DNS integrations keyed off the ServiceImport metadata. When the exporting clusters
+	// disagree on a label, the value of the oldest export is used, and the ExportedMetadataConflict condition is
+	// set on the ServiceImport in the hub cluster. Keys with the "networking.fleet.azure.com/" prefix are
+	// reserved and are not exported.
+	// +optional
+	ExportedLabels map[string]string `json:"exportedLabels,omitempty"`
+
+	// exportedAnnotations are the annotations to merge onto the ServiceImport of the Service in all the member
+	// clusters; they are handled in the same way as the exportedLabels.
+	// +optional
+	ExportedAnnotations map[string]string `json:"exportedAnnotations,omitempty"`
+
+	// allowedConsumers, if set, restricts the member clusters which can import the Service to the ones it lists or
+	// selects, e.g. to keep a sensitive internal Service from being exposed to the whole fleet; the endpoints of
+	// the Service are not distributed to the other clusters. When several clusters export the Service, a member
+	// cluster can import it only if it is allowed by every export which restricts its consumers. If unset, the
+	// Service can be imported by all the member clusters.
+	// +optional
+	AllowedConsumers *ConsumerSelector `json:"allowedConsumers,omitempty"`
+}
+
+// ConsumerSelector selects the member clusters allowed to import an exported Service; a member cluster is allowed
+// if it is listed by name or selected by its labels.
+type ConsumerSelector struct {
+	// clusters lists the names of the member clusters allowed to import the Service.
+	// +optional
+	// +listType=set
+	Clusters []string `json:"clusters,omitempty"`
+
+	// clusterSelector selects the member clusters allowed to import the Service by the labels of their
+	// MemberClusters in the hub cluster.
+	// +optional
+	ClusterSelector *metav1.LabelSelector `json:"clusterSelector,omitempty"`
+}
+
+// ServiceExportStatus contains the current status of an export.
+type ServiceExportStatus struct {
+	// +optional
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,categories={fleet-networking},shortName=svcexport
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:JSONPath=`.status.conditions[?(@.type=='Valid')].status`,name="Is-Valid",type=string
+// +kubebuilder:printcolumn:JSONPath=`.status.conditions[?(@.type=='Conflict')].status`,name="Is-Conflicted",type=string
+// +kubebuilder:printcolumn:JSONPath=`.metadata.creationTimestamp`,name="Age",type=date
+
+// ServiceExport declares that the associated service should be exported to other clusters.
+// The annotation "networking.fleet.azure.com/weight" specifies the proportion of requests forwarded to the cluster
+// within a serviceImport.
+// The actual value is the ceiling value of a number computed as weight/(sum of all weights in the serviceImport).
+// If weight is set to 0, no traffic should be forwarded for this entry.
+// If unspecified, weight defaults to 1.
+// The value should be in the range [0, 1000].
+// Any invalid value will default to default value.
+// +kubebuilder:validation:XValidation:rule="size(self.metadata.name) < 64",message="metadata.name max length is 63"
+type ServiceExport struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// +optional
+	Spec ServiceExportSpec `json:"spec,omitempty"`
+	// +optional
+	Status ServiceExportStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ServiceExportList contains a list of ServiceExport.
+type ServiceExportList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	// +listType=set
+	Items []ServiceExport `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ServiceExport{}, &ServiceExportList{})
+}
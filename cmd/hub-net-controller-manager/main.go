@@ -8,26 +8,40 @@ Licensed under the MIT license.
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/trafficmanager/armtrafficmanager"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/rand"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/discovery"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/cloud-provider-azure/pkg/azclient"
 	"sigs.k8s.io/cloud-provider-azure/pkg/azclient/policy/ratelimit"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
@@ -38,43 +52,277 @@ import (
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 	fleetnetv1beta1 "go.goms.io/fleet-networking/api/v1beta1"
+	"go.goms.io/fleet-networking/pkg/common/azurefrontdoor"
+	"go.goms.io/fleet-networking/pkg/common/cacheoptions"
+	"go.goms.io/fleet-networking/pkg/common/clusterhealth"
+	"go.goms.io/fleet-networking/pkg/common/componentconfig"
+	"go.goms.io/fleet-networking/pkg/common/conflictnotify"
+	"go.goms.io/fleet-networking/pkg/common/conflictresolution"
+	"go.goms.io/fleet-networking/pkg/common/controlleroptions"
+	"go.goms.io/fleet-networking/pkg/common/diagnostics"
+	"go.goms.io/fleet-networking/pkg/common/dryrun"
+	"go.goms.io/fleet-networking/pkg/common/eventrecorder"
+	"go.goms.io/fleet-networking/pkg/common/explain"
+	"go.goms.io/fleet-networking/pkg/common/exportapproval"
+	"go.goms.io/fleet-networking/pkg/common/features"
+	"go.goms.io/fleet-networking/pkg/common/healthcheck"
+	"go.goms.io/fleet-networking/pkg/common/hubconfig"
+	"go.goms.io/fleet-networking/pkg/common/leaderstatus"
+	"go.goms.io/fleet-networking/pkg/common/logging"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+	"go.goms.io/fleet-networking/pkg/common/metricsauth"
+	"go.goms.io/fleet-networking/pkg/common/quiesce"
+	"go.goms.io/fleet-networking/pkg/common/sharding"
+	"go.goms.io/fleet-networking/pkg/common/tracing"
+	"go.goms.io/fleet-networking/pkg/controllers/hub/clusternetworktopology"
 	"go.goms.io/fleet-networking/pkg/controllers/hub/endpointsliceexport"
+	"go.goms.io/fleet-networking/pkg/controllers/hub/federation"
+	"go.goms.io/fleet-networking/pkg/controllers/hub/frontdoorbackend"
 	"go.goms.io/fleet-networking/pkg/controllers/hub/internalserviceexport"
 	"go.goms.io/fleet-networking/pkg/controllers/hub/internalserviceimport"
 	"go.goms.io/fleet-networking/pkg/controllers/hub/membercluster"
+	"go.goms.io/fleet-networking/pkg/controllers/hub/memberdeparture"
+	"go.goms.io/fleet-networking/pkg/controllers/hub/membernetworkstatus"
+	"go.goms.io/fleet-networking/pkg/controllers/hub/multiclusteringress"
+	"go.goms.io/fleet-networking/pkg/controllers/hub/serviceexportsummary"
 	"go.goms.io/fleet-networking/pkg/controllers/hub/serviceimport"
+	"go.goms.io/fleet-networking/pkg/controllers/hub/staleexport"
 	"go.goms.io/fleet-networking/pkg/controllers/hub/trafficmanagerbackend"
 	"go.goms.io/fleet-networking/pkg/controllers/hub/trafficmanagerprofile"
+	"go.goms.io/fleet-networking/pkg/controllers/storageversionmigration"
+)
+
+const (
+	leaderElectionID = "2bf2b407.hub.networking.fleet.azure.com"
 )
 
 var (
 	scheme = runtime.NewScheme()
 
+	configFile = flag.String("config", "",
+		"The path to the ControllerManagerConfiguration file of the controller manager; the flags set on the command line take precedence over it.")
+
 	metricsAddr = flag.String("metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	probeAddr   = flag.String("health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	pprofAddr   = flag.String("pprof-bind-address", "",
+		"The address the endpoint serving the pprof profiles and the expvar variables binds to, e.g. localhost:6060. If empty, the endpoint is not served.")
+
+	metricsSecureServing = flag.Bool("metrics-secure-serving", false,
+		"If set, the metric endpoint is served over HTTPS, with the tls.crt and tls.key certificate of the metrics-cert-dir directory.")
+	metricsCertDir = flag.String("metrics-cert-dir", "",
+		"The directory of the tls.crt and tls.key certificate of the metric endpoint; if empty or missing the certificate, a self-signed certificate is generated.")
+	metricsAuthnAuthz = flag.Bool("metrics-authn-authz", false,
+		"If set, the scrapers of the metric endpoint are authenticated by TokenReviews and authorized, to get the /metrics non-resource URL, "+
+			"by SubjectAccessReviews; requires metrics-secure-serving.")
 
 	enableLeaderElection = flag.Bool("leader-elect", true,
 		"Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
 	leaderElectionNamespace = flag.String("leader-election-namespace", "fleet-system", "The namespace in which the leader election resource will be created.")
 
-	internalServiceExportRetryInterval = flag.Duration("internalserviceexport-retry-interval", 2*time.Second,
-		"The wait time for the internalserviceexport controller to requeue the request and to wait for the"+
-			"ServiceImport controller to resolve the service Spec")
+	shardCount = flag.Int("shard-count", 1,
+		"The number of shards the work of the controllers is split into by the namespace of the reconciled objects, i.e. by member cluster for the objects "+
+			"of the member clusters, or by ServiceImport for the controllers writing the ServiceImports; each shard elects its own leader among the replicas "+
+			"started with its shard-index, once the shards of a previous shard-count have handed their work over. If 1, the work is not sharded.")
+	shardIndex = flag.Int("shard-index", 0, "The index, in [0, shard-count), of the shard the replica is responsible for.")
+
+	internalServiceExportRetryInterval = flag.Duration("internalserviceexport-retry-interval", time.Minute,
+		"The wait time for the internalserviceexport controller to requeue the request while waiting for the "+
+			"ServiceImport controller to resolve the service spec; the controller watches the resolution, so the requeue is only a safety net.")
+
+	endpointDistributionDebounceWindow = flag.Duration("endpoint-distribution-debounce-window", 5*time.Second,
+		"The wait time for the serviceimport controller to re-compute the endpoint distribution after the exported endpoints change")
+
+	defaultDNSTTLSeconds = flag.Int64("default-dns-ttl-seconds", 30,
+		"The DNS TTL, in seconds, assumed for the exported services without a valid fleet.azure.com/dns-ttl annotation")
 
 	forceDeleteWaitTime = flag.Duration("force-delete-wait-time", 15*time.Minute, "The duration the fleet hub agent waits before trying to force delete a member cluster.")
 
+	enableMemberDepartureCleanup = flag.Bool("enable-member-departure-cleanup", true,
+		"If set, the exports of a member cluster are deleted as soon as its MemberCluster or its reserved namespace is being deleted, "+
+			"rather than left to its networking agent, which may be down.")
+
 	enableV1Beta1APIs = flag.Bool("enable-v1beta1-apis", true, "If set, the agents will watch for the v1beta1 APIs.")
 
 	enableTrafficManagerFeature = flag.Bool("enable-traffic-manager-feature", false, "If set, the traffic manager feature will be enabled.")
 
+	enableFrontDoorFeature = flag.Bool("enable-front-door-feature", false, "If set, the Azure Front Door feature will be enabled.")
+
+	enableMultiClusterIngress = flag.Bool("enable-multi-cluster-ingress", false,
+		"If set, the MultiClusterIngresses program the endpoints, routes and custom domains of the Azure Front Door profiles; "+
+			"it requires the Azure Front Door feature to be enabled.")
+
+	enableClusterNetworkTopology = flag.Bool("enable-cluster-network-topology", false,
+		"If set, the EndpointSlices exported through the east-west gateways of the member clusters are distributed per the ClusterNetworkTopology "+
+			"of the fleet, and the reachability probed by the member clusters is collected into its status; otherwise they are always imported "+
+			"through the gateways. The CRD of the ClusterNetworkTopology must be installed in the hub cluster.")
+
+	quiesced = flag.Bool("quiesce", false,
+		"If set, the controllers start in quiesce mode, where they keep watching resources but skip all the writes to the hub cluster and to Azure, "+
+			"as well as the Events. Sending SIGHUP to the process toggles the mode at runtime; the skipped writes are made once the mode is cleared.")
+	dryRun = flag.Bool("dry-run", false,
+		"If set, the controllers send all their writes to the hub cluster as dry-run requests and skip all the writes to Azure; "+
+			"the intended writes are logged and counted, so that an upgrade or a configuration change can be validated against a production hub.")
+
+	enableClusterFailover = flag.Bool("enable-cluster-failover", false,
+		"If set, the hub fails the exports of a member cluster over to the other member clusters when the cluster stops reporting or an export has no ready endpoints.")
+	clusterHealthCheckInterval = flag.Duration("cluster-health-check-interval", 15*time.Second,
+		"How often the hub evaluates the health of the exports when the cluster failover is enabled.")
+	clusterHeartbeatTimeout = flag.Duration("cluster-heartbeat-timeout", 3*time.Minute,
+		"How long the networking agent of a member cluster may stay silent before its exports are failed over.")
+	clusterUnhealthyThreshold = flag.Duration("cluster-unhealthy-threshold", 30*time.Second,
+		"How long an export must keep failing its health evaluation before it is failed over.")
+	clusterHealthyThreshold = flag.Duration("cluster-healthy-threshold", 2*time.Minute,
+		"How long a failed-over export must keep passing its health evaluation before it rejoins the ServiceImport.")
+	failOverTrafficManagerEndpoints = flag.Bool("fail-over-traffic-manager-endpoints", false,
+		"If set, the Azure Traffic Manager endpoints of the failed-over exports are disabled; otherwise they are left to the health probes of the Traffic Manager.")
+
+	eventRate  = flag.Float64("event-rate", 5, "The average number of Kubernetes Events per second the controllers are allowed to emit.")
+	eventBurst = flag.Int("event-burst", 50, "The maximum number of Kubernetes Events the controllers are allowed to emit in a burst.")
+
+	clientQPS = flag.Float64("client-qps", 50,
+		"The average number of requests per second the controllers are allowed to send to the hub API server. "+
+			"If negative, the requests are not throttled by the client, leaving the flow control to the API Priority and Fairness of the API server.")
+	clientBurst = flag.Int("client-burst", 100, "The maximum number of requests the controllers are allowed to send to the hub API server in a burst.")
+
+	exportDenylistConfigMap = flag.String("export-denylist-configmap", "",
+		"The name of the ConfigMap, in the leader election namespace, holding the patterns of the services which must not be exported to the fleet. "+
+			"If empty, the export denylist is disabled.")
+	rebuildConfigMap = flag.String("rebuild-configmap", "fleet-networking-rebuild",
+		"The name of the ConfigMap, in the leader election namespace, holding the deadline of the rebuild of the hub state from the member clusters, "+
+			"as started by the rebuild command of hub-net-maintenance; the ServiceImports are not resolved until the deadline passes. "+
+			"If empty, the rebuild is disabled.")
+	requireExportApproval = flag.Bool("require-export-approval", false,
+		"If set, the exports of the services are excluded from the ServiceImports, and reported as pending approval, until a fleet admin "+
+			"approves them with a ServiceExportApproval of the same name in the hub cluster listing their member clusters.")
+	exportApprovalExemptNamespaces = flag.String("export-approval-exempt-namespaces", "kube-system",
+		"The comma-separated namespaces whose exports are approved implicitly when --require-export-approval is set.")
+	enforceExportQuotas = flag.Bool("enforce-export-quotas", false,
+		"If set, the exports which do not fit in the ExportQuotas of the hub cluster, limiting the services and endpoints exported from each member cluster "+
+			"or namespace, are excluded from the ServiceImports; the CRD of the quotas must be installed in the hub cluster.")
+	enableTenantIsolation = flag.Bool("enable-tenant-isolation", false,
+		"If set, the fleet is partitioned into tenants by the tenant label on the namespaces of the member clusters in the hub cluster; "+
+			"a ServiceImport is owned by the tenant which exports it first, and the other tenants can neither export nor import it.")
+
+	upstreamHubKubeconfig = flag.String("upstream-hub-kubeconfig", "",
+		"The path to the kubeconfig of the hub cluster of an upstream fleet the fleet joins as a member cluster, so that the selected services "+
+			"are federated with the peer fleets. If empty, the fleet is not federated.")
+	fleetName = flag.String("fleet-name", "",
+		"The name of the fleet as a member cluster of the upstream fleet; it must be unique across the fleets federated by the upstream fleet.")
+	upstreamHubNamespace = flag.String("upstream-hub-namespace", "",
+		"The namespace reserved for the fleet in the upstream hub cluster. Defaults to fleet-member-<fleet-name>.")
+	upstreamClusterID = flag.String("upstream-cluster-id", "upstream",
+		"The ID of the virtual member cluster of the fleet which the services of the peer fleets are imported from.")
+	federationSelector = flag.String("federation-selector", "networking.fleet.azure.com/federated=true",
+		"The label selector, over the labels exported with the services, of the services federated with the upstream fleet.")
+
+	conflictWebhookURL = flag.String("conflict-webhook-url", "",
+		"The URL of the webhook to POST a JSON notification to when a new service export conflict arises in the fleet. "+
+			"If empty, no notifications are sent.")
+
+	conflictResolutionPolicy = flag.String("conflict-resolution-policy", string(fleetnetv1alpha1.OldestExportWins),
+		"The default policy deciding whose spec a ServiceImport takes when the exported services are in conflict: OldestExportWins, ClusterPriority or Manual. "+
+			"It can be overridden for a ServiceImport with the networking.fleet.azure.com/conflict-resolution-policy annotation.")
+
+	reportImportDemand = flag.Bool("report-import-demand", false,
+		"If set, the hub reports on each exported service whether any other member cluster imports it, so that the member agents "+
+			"started with --export-endpoints-on-demand only export the endpoints of the services in demand.")
+
+	serverSideApply = flag.Bool("server-side-apply", true,
+		"If set, the ServiceImport status and the EndpointSliceImports are written with server-side applies under the \"fleet-networking\" "+
+			"field managers, instead of updates, which avoids the conflicts and retries of the writes under heavy endpoint churn; the fields "+
+			"written with updates before are handed over to these field managers. Set it to false to go back to updates.")
+
+	enableConversionWebhook = flag.Bool("enable-conversion-webhook", false,
+		"If set, the webhook server serves the conversion between the v1alpha1 and v1beta1 APIs of the ServiceImport, InternalServiceExport, "+
+			"and EndpointSliceExport CRDs; the CRDs must be configured to use the webhook as their conversion strategy.")
+	enableNamespaceSamenessWebhook = flag.Bool("enable-namespace-sameness-webhook", false,
+		"If set, the webhook server rejects the InternalServiceExports of the services in the namespaces excluded from multi-cluster networking "+
+			"by the NamespaceSamenessPolicies of the hub cluster; the CRD of the policies must be installed in the hub cluster.")
+	enableStorageVersionMigration = flag.Bool("enable-storage-version-migration", false,
+		"If set, the objects of the ServiceImport, InternalServiceExport, and EndpointSliceExport CRDs that are stored in an older API version "+
+			"are rewritten into the current storage version.")
+
+	agentHeartbeatTimeout = flag.Duration("agent-heartbeat-timeout", 2*time.Minute,
+		"How long the networking agent of a member cluster may go without reporting its InternalMemberNetworkStatus before it is considered unhealthy.")
+	enableStaleExportWithdrawal = flag.Bool("enable-stale-export-withdrawal", false,
+		"If set, the exports of a member cluster whose networking agent has not reported for the --agent-heartbeat-timeout are marked as stale, "+
+			"and their endpoints are withdrawn from the ServiceImports once they stay stale for the --stale-export-grace-period.")
+	staleExportGracePeriod = flag.Duration("stale-export-grace-period", 5*time.Minute,
+		"How long the exports of a member cluster may stay stale before their endpoints are withdrawn, when the stale export withdrawal is enabled.")
+
+	loggingFormat = flag.String("logging-format", logging.FormatText,
+		"The format of the logs, either text, the klog text format, or json, a JSON object per log entry.")
+
+	tracingOTLPEndpoint = flag.String("tracing-otlp-endpoint", "",
+		"The OTLP/HTTP endpoint, e.g. http://otel-collector:4318, the traces of the controllers are exported to. "+
+			"If empty, the controllers are not traced.")
+
 	cloudConfigFile = flag.String("cloud-config", "/etc/kubernetes/provider/azure.json", "The path to the cloud config file which will be used to access the Azure resource.")
+
+	// controllerOptions tune the throughput of each controller, e.g. --serviceimport-max-concurrent-reconciles.
+	controllerOptions = controlleroptions.AddFlags(flag.CommandLine,
+		"clusternetworktopology",
+		"endpointsliceexport",
+		"federationexport",
+		"federationimport",
+		"frontdoorbackend",
+		"internalserviceexport",
+		"internalserviceimport",
+		"membercluster",
+		"memberdeparture",
+		"membernetworkstatus",
+		"multiclusteringress",
+		"serviceexportsummary",
+		"serviceimport",
+		"staleexport",
+		"storageversionmigration",
+		"trafficmanagerbackend",
+		"trafficmanagerprofile",
+	)
 )
 
 var (
+	// featureGates are the feature gates of the configuration file and the flags they set.
+	featureGates = map[string]string{
+		"ClusterFailover":          "enable-cluster-failover",
+		"ClusterNetworkTopology":   "enable-cluster-network-topology",
+		"ConversionWebhook":        "enable-conversion-webhook",
+		"FrontDoor":                "enable-front-door-feature",
+		"MemberDepartureCleanup":   "enable-member-departure-cleanup",
+		"MultiClusterIngress":      "enable-multi-cluster-ingress",
+		"NamespaceSamenessWebhook": "enable-namespace-sameness-webhook",
+		"StaleExportWithdrawal":    "enable-stale-export-withdrawal",
+		"StorageVersionMigration":  "enable-storage-version-migration",
+		"TrafficManager":           "enable-traffic-manager-feature",
+		"V1Beta1APIs":              "enable-v1beta1-apis",
+	}
+
 	trafficManagerFeatureRequiredGVKs = []schema.GroupVersionKind{
 		fleetnetv1beta1.GroupVersion.WithKind(fleetnetv1beta1.TrafficManagerProfileKind),
 		fleetnetv1beta1.GroupVersion.WithKind(fleetnetv1beta1.TrafficManagerBackendKind),
 	}
+	frontDoorFeatureRequiredGVKs = []schema.GroupVersionKind{
+		fleetnetv1alpha1.GroupVersion.WithKind(fleetnetv1alpha1.FrontDoorBackendKind),
+		fleetnetv1alpha1.GroupVersion.WithKind(fleetnetv1alpha1.BackendTrafficPolicyKind),
+	}
+	multiClusterIngressRequiredGVKs = []schema.GroupVersionKind{
+		fleetnetv1alpha1.GroupVersion.WithKind(fleetnetv1alpha1.MultiClusterIngressKind),
+	}
+	clusterNetworkTopologyRequiredGVKs = []schema.GroupVersionKind{
+		fleetnetv1alpha1.GroupVersion.WithKind(fleetnetv1alpha1.ClusterNetworkTopologyKind),
+	}
+
+	// multiVersionObjects are the objects in the hub cluster which are served in both the v1alpha1 and v1beta1 APIs.
+	multiVersionObjects = []client.Object{
+		&fleetnetv1beta1.ServiceImport{},
+		&fleetnetv1beta1.InternalServiceExport{},
+		&fleetnetv1beta1.EndpointSliceExport{},
+	}
+	multiVersionCRDNames = []string{
+		"serviceimports.networking.fleet.azure.com",
+		"internalserviceexports.networking.fleet.azure.com",
+		"endpointsliceexports.networking.fleet.azure.com",
+	}
 )
 
 func init() {
@@ -82,7 +330,9 @@ func init() {
 	utilruntime.Must(fleetnetv1alpha1.AddToScheme(scheme))
 	utilruntime.Must(fleetnetv1beta1.AddToScheme(scheme))
 	utilruntime.Must(clusterv1beta1.AddToScheme(scheme))
+	utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
 	klog.InitFlags(nil)
+	features.DefaultGate.AddFlag(flag.CommandLine)
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -101,23 +351,132 @@ func main() {
 
 	defer handleExitFunc()
 
+	// The configuration file sets the flags not set on the command line, so it is applied before any flag is read.
+	var overriddenFlags []string
+	if *configFile != "" {
+		cfg, err := componentconfig.Load(*configFile)
+		if err != nil {
+			klog.ErrorS(err, "Unable to load the configuration file")
+			exitWithErrorFunc()
+		}
+		if overriddenFlags, err = cfg.Apply(flag.CommandLine, featureGates); err != nil {
+			klog.ErrorS(err, "Invalid configuration file", "file", *configFile)
+			exitWithErrorFunc()
+		}
+	}
+
+	if err := logging.Setup(*loggingFormat, os.Stderr); err != nil {
+		klog.ErrorS(err, "Unable to set up logging")
+		exitWithErrorFunc()
+	}
+	if len(overriddenFlags) > 0 {
+		klog.InfoS("The flags set on the command line override the configuration file", "flags", overriddenFlags)
+	}
+
 	flag.VisitAll(func(f *flag.Flag) {
 		klog.InfoS("flag:", "name", f.Name, "value", f.Value)
 	})
 
+	diagnostics.SetRuntimeLimits(diagnostics.CgroupRoot)
+
+	if !conflictresolution.IsValidPolicy(fleetnetv1alpha1.ConflictResolutionPolicy(*conflictResolutionPolicy)) {
+		klog.ErrorS(fmt.Errorf("unsupported conflict resolution policy %q", *conflictResolutionPolicy), "Invalid flag", "flag", "conflict-resolution-policy")
+		exitWithErrorFunc()
+	}
+	if *clientBurst < 1 {
+		klog.ErrorS(fmt.Errorf("the burst must be positive, got %d", *clientBurst), "Invalid flag", "flag", "client-burst")
+		exitWithErrorFunc()
+	}
+	if err := controllerOptions.Validate(); err != nil {
+		klog.ErrorS(err, "Invalid controller options")
+		exitWithErrorFunc()
+	}
+	shard := sharding.Shard{Index: *shardIndex, Count: *shardCount}
+	if err := shard.Validate(); err != nil {
+		klog.ErrorS(err, "Invalid flag", "flag", "shard-index")
+		exitWithErrorFunc()
+	}
+	if *metricsAuthnAuthz && !*metricsSecureServing {
+		// The bearer tokens of the scrapers must not be sent in clear text.
+		klog.ErrorS(fmt.Errorf("the metric endpoint is not served over HTTPS"), "Invalid flag", "flag", "metrics-authn-authz")
+		exitWithErrorFunc()
+	}
+	if *enableStaleExportWithdrawal && *staleExportGracePeriod <= 0 {
+		klog.ErrorS(fmt.Errorf("the grace period must be positive, got %s", *staleExportGracePeriod), "Invalid flag", "flag", "stale-export-grace-period")
+		exitWithErrorFunc()
+	}
+	if *enableMultiClusterIngress && !*enableFrontDoorFeature {
+		klog.ErrorS(fmt.Errorf("the Azure Front Door feature is disabled"), "Invalid flag", "flag", "enable-multi-cluster-ingress")
+		exitWithErrorFunc()
+	}
+
+	var federationLabelSelector labels.Selector
+	if *upstreamHubKubeconfig != "" {
+		if *fleetName == "" {
+			klog.ErrorS(fmt.Errorf("the name of the fleet is required to join the upstream fleet"), "Invalid flag", "flag", "fleet-name")
+			exitWithErrorFunc()
+		}
+		if *upstreamHubNamespace == "" {
+			*upstreamHubNamespace = fmt.Sprintf(hubconfig.HubNamespaceNameFormat, *fleetName)
+		}
+		var err error
+		if federationLabelSelector, err = labels.Parse(*federationSelector); err != nil {
+			klog.ErrorS(err, "Invalid flag", "flag", "federation-selector")
+			exitWithErrorFunc()
+		}
+	}
+
+	denylistConfigMap := types.NamespacedName{Namespace: *leaderElectionNamespace, Name: *exportDenylistConfigMap}
+	rebuildConfigMapKey := types.NamespacedName{Namespace: *leaderElectionNamespace, Name: *rebuildConfigMap}
+	cacheOptions := cacheoptions.Hub()
+	if denylistConfigMap.Name != "" || rebuildConfigMapKey.Name != "" {
+		// Only cache the ConfigMaps in the leader election namespace, where the denylist and the rebuild deadline,
+		// the only ConfigMaps the controllers read, are.
+		cacheOptions.ByObject = map[client.Object]cache.ByObject{
+			&corev1.ConfigMap{}: {
+				Namespaces: map[string]cache.Config{*leaderElectionNamespace: {}},
+			},
+		}
+	}
+
+	metricsOptions := metricsserver.Options{
+		BindAddress:   *metricsAddr,
+		SecureServing: *metricsSecureServing,
+		CertDir:       *metricsCertDir,
+	}
+	if *metricsAuthnAuthz {
+		metricsOptions.FilterProvider = metricsauth.FilterProvider(nil)
+	}
+
+	// Each shard has its own leader election lease; the leader of a shard releases the lease as it shuts down, so that
+	// another replica of the shard takes the work over right away, e.g. during a rollout. When the number of shards
+	// changes, the leaders of the new shards are only elected once the leases of the previous shards are released.
+	shardLeaderElectionID := shard.LeaderElectionID(leaderElectionID)
 	hubConfig := ctrl.GetConfigOrDie()
+	hubConfig.QPS = float32(*clientQPS)
+	hubConfig.Burst = *clientBurst
+	var leaderElectionLock resourcelock.Interface
+	if *enableLeaderElection {
+		var err error
+		if leaderElectionLock, err = shard.NewResourceLock(hubConfig, *leaderElectionNamespace, leaderElectionID); err != nil {
+			klog.ErrorS(err, "Unable to create the leader election lock")
+			exitWithErrorFunc()
+		}
+	}
 	mgr, err := ctrl.NewManager(hubConfig, ctrl.Options{
-		Scheme: scheme,
-		Metrics: metricsserver.Options{
-			BindAddress: *metricsAddr,
-		},
+		Scheme:  scheme,
+		Cache:   cacheOptions,
+		Metrics: metricsOptions,
 		WebhookServer: webhook.NewServer(webhook.Options{
 			Port: 9443,
 		}),
-		HealthProbeBindAddress:  *probeAddr,
-		LeaderElection:          *enableLeaderElection,
-		LeaderElectionNamespace: *leaderElectionNamespace,
-		LeaderElectionID:        "2bf2b407.hub.networking.fleet.azure.com",
+		HealthProbeBindAddress:              *probeAddr,
+		LeaderElection:                      *enableLeaderElection,
+		LeaderElectionNamespace:             *leaderElectionNamespace,
+		LeaderElectionID:                    shardLeaderElectionID,
+		LeaderElectionResourceLockInterface: leaderElectionLock,
+		// The process exits right after the manager stops, which makes releasing the lease on cancel safe.
+		LeaderElectionReleaseOnCancel: shard.IsSharded(),
 	})
 	if err != nil {
 		klog.ErrorS(err, "Unable to start manager")
@@ -126,66 +485,320 @@ func main() {
 
 	//+kubebuilder:scaffold:builder
 
-	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
-		klog.ErrorS(err, "Unable to set up health check")
+	if err := healthcheck.SetupWithManager(mgr, *enableConversionWebhook || *enableNamespaceSamenessWebhook); err != nil {
+		klog.ErrorS(err, "Unable to set up health and ready checks")
+		exitWithErrorFunc()
+	}
+	if err := diagnostics.SetupWithManager(mgr, *pprofAddr); err != nil {
+		klog.ErrorS(err, "Unable to set up diagnostics endpoint")
 		exitWithErrorFunc()
 	}
-	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
-		klog.ErrorS(err, "Unable to set up ready check")
+	if err := leaderstatus.SetupWithManager(mgr, mgr.GetAPIReader(), *leaderElectionNamespace, shardLeaderElectionID); err != nil {
+		klog.ErrorS(err, "Unable to set up leader election status reporter")
 		exitWithErrorFunc()
 	}
 
 	ctx := ctrl.SetupSignalHandler()
 
-	klog.V(1).InfoS("Start to setup EndpointsliceExport controller")
-	if err := (&endpointsliceexport.Reconciler{
-		HubClient: mgr.GetClient(),
-	}).SetupWithManager(ctx, mgr); err != nil {
-		klog.ErrorS(err, "Unable to create EndpointsliceExport controller")
+	shutdownTracing, err := tracing.Setup(ctx, *tracingOTLPEndpoint, "hub-net-controller-manager")
+	if err != nil {
+		klog.ErrorS(err, "Unable to set up tracing")
 		exitWithErrorFunc()
 	}
+	defer func() {
+		// The signal handler context is done by now; the pending spans are flushed without it.
+		if err := shutdownTracing(context.Background()); err != nil {
+			klog.ErrorS(err, "Failed to shut down tracing")
+		}
+	}()
 
-	klog.V(1).InfoS("Start to setup InternalServiceExport controller")
-	if err := (&internalserviceexport.Reconciler{
-		Client:        mgr.GetClient(),
-		RetryInternal: *internalServiceExportRetryInterval,
-	}).SetupWithManager(mgr); err != nil {
-		klog.ErrorS(err, "Unable to create InternalServiceExport controller")
-		exitWithErrorFunc()
+	// All the controllers share one event throttler, which caps the overall rate of Event emission.
+	eventThrottler := eventrecorder.NewThrottler(*eventRate, *eventBurst)
+	quiesceSwitch := quiesce.NewSwitch(*quiesced)
+	quiesceSwitch.ToggleOnSIGHUP(ctx)
+	var hubClient client.Client = mgr.GetClient()
+	if *dryRun {
+		klog.InfoS("Running in dry-run mode; no writes are persisted")
+		hubClient = dryrun.NewClient(hubClient, dryrun.TargetHub)
 	}
+	hubClient = quiesce.NewClient(hubClient, quiesceSwitch)
 
-	klog.V(1).InfoS("Start to setup InternalServiceImport controller")
-	if err := (&internalserviceimport.Reconciler{
-		HubClient: mgr.GetClient(),
-	}).SetupWithManager(ctx, mgr); err != nil {
-		klog.ErrorS(err, "Unable to create InternalServiceImport controller")
-		exitWithErrorFunc()
+	// The services and endpoints exported and imported by each member cluster are counted on each scrape; they are
+	// only counted by the shard of the cluster-scoped objects, so that the fleet is counted once.
+	if shard.Owns("") {
+		ctrlmetrics.Registry.MustRegister(metrics.NewFleetCollector(mgr.GetClient(), hubconfig.HubNamespaceNameFormat))
 	}
 
-	klog.V(1).InfoS("Start to setup ServiceImport controller")
-	if err := (&serviceimport.Reconciler{
-		Client:   mgr.GetClient(),
-		Recorder: mgr.GetEventRecorderFor(serviceimport.ControllerName),
-	}).SetupWithManager(ctx, mgr); err != nil {
-		klog.ErrorS(err, "Unable to create ServiceImport controller")
-		exitWithErrorFunc()
+	// The work queue of each controller only takes the requests for the objects of the shard of the replica, by
+	// default the objects in the namespaces of the shard.
+	controllerOptionsShardedBy := func(name string, shardKey sharding.KeyFunc) controller.Options {
+		opts := controllerOptions.For(name)
+		if shard.IsSharded() {
+			opts.NewQueue = shard.NewQueueFunc(shardKey)
+		}
+		// The requests reconciled in quiesce mode are requeued once it is cleared, so that their writes are made.
+		opts.NewQueue = quiesceSwitch.NewQueue(opts.NewQueue)
+		return opts
+	}
+	controllerOptionsFor := func(name string) controller.Options {
+		return controllerOptionsShardedBy(name, sharding.NamespaceKey)
 	}
 
 	discoverClient := discovery.NewDiscoveryClientForConfigOrDie(hubConfig)
+	memberClusterAPIInstalled := false
 	if *enableV1Beta1APIs {
 		gvk := clusterv1beta1.GroupVersion.WithKind(clusterv1beta1.MemberClusterKind)
-		if utils.CheckCRDInstalled(discoverClient, gvk) == nil {
-			klog.V(1).InfoS("Start to setup MemberCluster controller")
-			if err := (&membercluster.Reconciler{
-				Client:              mgr.GetClient(),
-				Recorder:            mgr.GetEventRecorderFor(membercluster.ControllerName),
-				ForceDeleteWaitTime: *forceDeleteWaitTime,
+		memberClusterAPIInstalled = utils.CheckCRDInstalled(discoverClient, gvk) == nil
+	}
+
+	if *enableClusterNetworkTopology {
+		for _, gvk := range clusterNetworkTopologyRequiredGVKs {
+			if err = utils.CheckCRDInstalled(discoverClient, gvk); err != nil {
+				klog.ErrorS(err, "Unable to find the required CRD", "GVK", gvk)
+				exitWithErrorFunc()
+			}
+		}
+
+		if controllerOptions.Enabled("clusternetworktopology") {
+			klog.V(1).InfoS("Start to setup ClusterNetworkTopology controller")
+			if err := (&clusternetworktopology.Reconciler{
+				Client:            hubClient,
+				ControllerOptions: controllerOptionsFor("clusternetworktopology"),
 			}).SetupWithManager(mgr); err != nil {
-				klog.ErrorS(err, "Unable to create MemberCluster controller")
+				klog.ErrorS(err, "Unable to create ClusterNetworkTopology controller")
+				exitWithErrorFunc()
+			}
+		}
+	}
+
+	if controllerOptions.Enabled("endpointsliceexport") {
+		klog.V(1).InfoS("Start to setup EndpointsliceExport controller")
+		if err := (&endpointsliceexport.Reconciler{
+			HubClient:                    hubClient,
+			EnableClusterQuarantine:      memberClusterAPIInstalled,
+			EnableClusterFailover:        *enableClusterFailover,
+			EnableClusterNetworkTopology: *enableClusterNetworkTopology,
+			EnableStaleExportWithdrawal:  *enableStaleExportWithdrawal,
+			EnableExportApproval:         *requireExportApproval,
+			EnforceExportQuotas:          *enforceExportQuotas,
+			EnableTenantIsolation:        *enableTenantIsolation,
+			ServerSideApply:              *serverSideApply,
+			ControllerOptions:            controllerOptionsFor("endpointsliceexport"),
+		}).SetupWithManager(ctx, mgr); err != nil {
+			klog.ErrorS(err, "Unable to create EndpointsliceExport controller")
+			exitWithErrorFunc()
+		}
+	}
+
+	var conflictNotifier *conflictnotify.Notifier
+	if *conflictWebhookURL != "" {
+		conflictNotifier = conflictnotify.NewNotifier(&conflictnotify.WebhookSink{URL: *conflictWebhookURL})
+	}
+
+	conflictResolver := &conflictresolution.Resolver{
+		DefaultPolicy: fleetnetv1alpha1.ConflictResolutionPolicy(*conflictResolutionPolicy),
+	}
+	if memberClusterAPIInstalled {
+		// The priorities of the clusters are set on their MemberClusters.
+		conflictResolver.Reader = hubClient
+	}
+
+	var healthEvaluator *clusterhealth.Evaluator
+	if *enableClusterFailover {
+		healthEvaluator = &clusterhealth.Evaluator{
+			Interval:           *clusterHealthCheckInterval,
+			HeartbeatTimeout:   *clusterHeartbeatTimeout,
+			UnhealthyThreshold: *clusterUnhealthyThreshold,
+			HealthyThreshold:   *clusterHealthyThreshold,
+		}
+		if !memberClusterAPIInstalled {
+			// The heartbeats of the member clusters are reported on their MemberClusters.
+			klog.InfoS("MemberCluster API is not installed; the heartbeats of the member clusters are not checked")
+			healthEvaluator.HeartbeatTimeout = 0
+		}
+	}
+
+	var exportApproval *exportapproval.Policy
+	if *requireExportApproval {
+		exportApproval = &exportapproval.Policy{ExemptNamespaces: splitAndTrim(*exportApprovalExemptNamespaces)}
+	}
+
+	// The stale marks are ignored if the grace period is not set.
+	var staleGracePeriod time.Duration
+	if *enableStaleExportWithdrawal {
+		staleGracePeriod = *staleExportGracePeriod
+	}
+
+	// The controllers writing the ServiceImports are sharded by ServiceImport instead, so that each ServiceImport is
+	// written by a single shard, whichever member clusters export and import its service.
+	//
+	// The InternalServiceExports are indexed by their exported service by the serviceimport controller, or, if it is
+	// disabled, by the first of the controllers below which looks them up by the index.
+	internalServiceExportIndexed := controllerOptions.Enabled("serviceimport")
+	if controllerOptions.Enabled("internalserviceexport") {
+		klog.V(1).InfoS("Start to setup InternalServiceExport controller")
+		if err := (&internalserviceexport.Reconciler{
+			Client:                  hubClient,
+			RetryInternal:           *internalServiceExportRetryInterval,
+			DenylistConfigMap:       denylistConfigMap,
+			ConflictNotifier:        conflictNotifier,
+			EnableClusterQuarantine: memberClusterAPIInstalled,
+			ConflictResolver:        conflictResolver,
+			HealthEvaluator:         healthEvaluator,
+			Recorder:                quiesce.NewRecorder(eventThrottler.Wrap(mgr.GetEventRecorderFor(internalserviceexport.ControllerName), internalserviceexport.ControllerName), quiesceSwitch),
+			ReportImportDemand:      *reportImportDemand,
+			StaleExportGracePeriod:  staleGracePeriod,
+			ExportApproval:          exportApproval,
+			EnforceExportQuotas:     *enforceExportQuotas,
+			EnableTenantIsolation:   *enableTenantIsolation,
+			ServerSideApply:         *serverSideApply,
+			ControllerOptions:       controllerOptionsShardedBy("internalserviceexport", internalserviceexport.ShardKey(mgr.GetClient())),
+		}).SetupWithManager(ctx, mgr, internalServiceExportIndexed); err != nil {
+			klog.ErrorS(err, "Unable to create InternalServiceExport controller")
+			exitWithErrorFunc()
+		}
+		internalServiceExportIndexed = true
+	}
+
+	if controllerOptions.Enabled("internalserviceimport") {
+		klog.V(1).InfoS("Start to setup InternalServiceImport controller")
+		if err := (&internalserviceimport.Reconciler{
+			HubClient:             hubClient,
+			EnableTenantIsolation: *enableTenantIsolation,
+			ControllerOptions:     controllerOptionsShardedBy("internalserviceimport", internalserviceimport.ShardKey(mgr.GetClient())),
+		}).SetupWithManager(ctx, mgr); err != nil {
+			klog.ErrorS(err, "Unable to create InternalServiceImport controller")
+			exitWithErrorFunc()
+		}
+	}
+
+	serviceImportResults := explain.NewResults()
+	if err := mgr.AddMetricsServerExtraHandler(explain.EndpointPath, &explain.Handler{
+		Reader:               mgr.GetClient(),
+		ServiceImportResults: serviceImportResults,
+	}); err != nil {
+		klog.ErrorS(err, "Unable to set up the explain debug endpoint")
+		exitWithErrorFunc()
+	}
+
+	if controllerOptions.Enabled("serviceimport") {
+		klog.V(1).InfoS("Start to setup ServiceImport controller")
+		if err := (&serviceimport.Reconciler{
+			Client:                             hubClient,
+			Recorder:                           quiesce.NewRecorder(eventThrottler.Wrap(mgr.GetEventRecorderFor(serviceimport.ControllerName), serviceimport.ControllerName), quiesceSwitch),
+			EndpointDistributionDebounceWindow: *endpointDistributionDebounceWindow,
+			DefaultDNSTTLSeconds:               *defaultDNSTTLSeconds,
+			DenylistConfigMap:                  denylistConfigMap,
+			RebuildConfigMap:                   rebuildConfigMapKey,
+			ConflictResolver:                   conflictResolver,
+			ReconcileResults:                   serviceImportResults,
+			ServerSideApply:                    *serverSideApply,
+			ControllerOptions:                  controllerOptionsShardedBy("serviceimport", sharding.ObjectKey),
+			// The endpointsliceexport controller indexes the EndpointSliceExports, unless it is disabled.
+		}).SetupWithManager(ctx, mgr, controllerOptions.Enabled("endpointsliceexport")); err != nil {
+			klog.ErrorS(err, "Unable to create ServiceImport controller")
+			exitWithErrorFunc()
+		}
+	}
+
+	if *upstreamHubKubeconfig != "" {
+		if err := setupFederation(ctx, mgr, hubClient, quiesceSwitch, federationLabelSelector, controllerOptionsFor); err != nil {
+			klog.ErrorS(err, "Unable to set up the federation with the upstream fleet")
+			exitWithErrorFunc()
+		}
+	}
+
+	if controllerOptions.Enabled("serviceexportsummary") {
+		klog.V(1).InfoS("Start to setup ServiceExportSummary controller")
+		if err := (&serviceexportsummary.Reconciler{
+			Client:            hubClient,
+			Scheme:            mgr.GetScheme(),
+			ControllerOptions: controllerOptionsFor("serviceexportsummary"),
+		}).SetupWithManager(mgr); err != nil {
+			klog.ErrorS(err, "Unable to create ServiceExportSummary controller")
+			exitWithErrorFunc()
+		}
+	}
+
+	if controllerOptions.Enabled("membernetworkstatus") {
+		klog.V(1).InfoS("Start to setup InternalMemberNetworkStatus controller")
+		if err := (&membernetworkstatus.Reconciler{
+			Client:            hubClient,
+			HeartbeatTimeout:  *agentHeartbeatTimeout,
+			ControllerOptions: controllerOptionsFor("membernetworkstatus"),
+		}).SetupWithManager(mgr); err != nil {
+			klog.ErrorS(err, "Unable to create InternalMemberNetworkStatus controller")
+			exitWithErrorFunc()
+		}
+	}
+
+	if *enableStaleExportWithdrawal && controllerOptions.Enabled("staleexport") {
+		klog.V(1).InfoS("Start to setup StaleExport controller")
+		if err := (&staleexport.Reconciler{
+			Client:            hubClient,
+			HeartbeatTimeout:  *agentHeartbeatTimeout,
+			ControllerOptions: controllerOptionsFor("staleexport"),
+		}).SetupWithManager(mgr); err != nil {
+			klog.ErrorS(err, "Unable to create StaleExport controller")
+			exitWithErrorFunc()
+		}
+	}
+
+	if memberClusterAPIInstalled && controllerOptions.Enabled("membercluster") {
+		klog.V(1).InfoS("Start to setup MemberCluster controller")
+		if err := (&membercluster.Reconciler{
+			Client:              hubClient,
+			Recorder:            quiesce.NewRecorder(eventThrottler.Wrap(mgr.GetEventRecorderFor(membercluster.ControllerName), membercluster.ControllerName), quiesceSwitch),
+			ForceDeleteWaitTime: *forceDeleteWaitTime,
+			ControllerOptions:   controllerOptionsFor("membercluster"),
+		}).SetupWithManager(mgr); err != nil {
+			klog.ErrorS(err, "Unable to create MemberCluster controller")
+			exitWithErrorFunc()
+		}
+	}
+	if *enableMemberDepartureCleanup && controllerOptions.Enabled("memberdeparture") {
+		klog.V(1).InfoS("Start to setup MemberDeparture controller")
+		if err := (&memberdeparture.Reconciler{
+			Client:              hubClient,
+			WatchMemberClusters: memberClusterAPIInstalled,
+			ControllerOptions:   controllerOptionsFor("memberdeparture"),
+		}).SetupWithManager(mgr); err != nil {
+			klog.ErrorS(err, "Unable to create MemberDeparture controller")
+			exitWithErrorFunc()
+		}
+	}
+	if *enableConversionWebhook {
+		klog.V(1).InfoS("Start to setup the conversion webhook")
+		for _, obj := range multiVersionObjects {
+			if err := ctrl.NewWebhookManagedBy(mgr).For(obj).Complete(); err != nil {
+				klog.ErrorS(err, "Unable to create the conversion webhook", "object", fmt.Sprintf("%T", obj))
 				exitWithErrorFunc()
 			}
 		}
 	}
+
+	if *enableNamespaceSamenessWebhook {
+		klog.V(1).InfoS("Start to setup the namespace sameness webhook")
+		if err := (&internalserviceexport.Validator{
+			Reader: hubClient,
+		}).SetupWebhookWithManager(mgr); err != nil {
+			klog.ErrorS(err, "Unable to create the namespace sameness webhook")
+			exitWithErrorFunc()
+		}
+	}
+
+	if *enableStorageVersionMigration && controllerOptions.Enabled("storageversionmigration") {
+		klog.V(1).InfoS("Start to setup StorageVersionMigration controller")
+		if err := (&storageversionmigration.Reconciler{
+			Client:            hubClient,
+			CRDNames:          multiVersionCRDNames,
+			ControllerOptions: controllerOptionsFor("storageversionmigration"),
+		}).SetupWithManager(mgr); err != nil {
+			klog.ErrorS(err, "Unable to create StorageVersionMigration controller")
+			exitWithErrorFunc()
+		}
+	}
+
 	if *enableTrafficManagerFeature {
 		klog.V(1).InfoS("Traffic manager feature is enabled, checking the required CRDs")
 		for _, gvk := range trafficManagerFeatureRequiredGVKs {
@@ -204,33 +817,95 @@ func main() {
 		cloudConfig.SetUserAgent("fleet-hub-net-controller-manager")
 		klog.V(1).InfoS("Cloud config loaded", "cloudConfig", cloudConfig)
 
-		profilesClient, endpointsClient, err := initAzureTrafficManagerClients(cloudConfig) // profilesClient, endpointsClient, err
+		profilesClient, endpointsClient, err := initAzureTrafficManagerClients(cloudConfig, quiesceSwitch) // profilesClient, endpointsClient, err
 		if err != nil {
 			klog.ErrorS(err, "Unable to create Azure Traffic Manager clients")
 			exitWithErrorFunc()
 		}
-		klog.V(1).InfoS("Start to setup TrafficManagerProfile controller")
-		if err := (&trafficmanagerprofile.Reconciler{
-			Client:            mgr.GetClient(),
-			ProfilesClient:    profilesClient,
-			ResourceGroupName: cloudConfig.ResourceGroup,
-		}).SetupWithManager(mgr); err != nil {
-			klog.ErrorS(err, "Unable to create TrafficManagerProfile controller")
+		if controllerOptions.Enabled("trafficmanagerprofile") {
+			klog.V(1).InfoS("Start to setup TrafficManagerProfile controller")
+			if err := (&trafficmanagerprofile.Reconciler{
+				Client:            hubClient,
+				ProfilesClient:    profilesClient,
+				ResourceGroupName: cloudConfig.ResourceGroup,
+				ControllerOptions: controllerOptionsFor("trafficmanagerprofile"),
+			}).SetupWithManager(mgr); err != nil {
+				klog.ErrorS(err, "Unable to create TrafficManagerProfile controller")
+				exitWithErrorFunc()
+			}
+		}
+
+		if controllerOptions.Enabled("trafficmanagerbackend") {
+			klog.V(1).InfoS("Start to setup TrafficManagerBackend controller")
+			if err := (&trafficmanagerbackend.Reconciler{
+				Client:            hubClient,
+				ProfilesClient:    profilesClient,
+				EndpointsClient:   endpointsClient,
+				ResourceGroupName: cloudConfig.ResourceGroup,
+				// The endpoints are failed over only along with the exports.
+				FailOverUnhealthyClusters: *enableClusterFailover && *failOverTrafficManagerEndpoints,
+				ControllerOptions:         controllerOptionsFor("trafficmanagerbackend"),
+			}).SetupWithManager(ctx, mgr, internalServiceExportIndexed); err != nil {
+				klog.ErrorS(err, "Unable to create TrafficManagerProfile controller")
+				exitWithErrorFunc()
+			}
+			internalServiceExportIndexed = true
+		}
+	}
+
+	if *enableFrontDoorFeature {
+		klog.V(1).InfoS("Azure Front Door feature is enabled, checking the required CRDs")
+		for _, gvk := range frontDoorFeatureRequiredGVKs {
+			if err = utils.CheckCRDInstalled(discoverClient, gvk); err != nil {
+				klog.ErrorS(err, "Unable to find the required CRD", "GVK", gvk)
+				exitWithErrorFunc()
+			}
+		}
+
+		klog.V(1).InfoS("Azure Front Door feature is enabled, loading cloud config and creating azure clients", "cloudConfigFile", *cloudConfigFile)
+		cloudConfig, err := azure.NewCloudConfigFromFile(*cloudConfigFile)
+		if err != nil {
+			klog.ErrorS(err, "Unable to load cloud config", "file name", *cloudConfigFile)
 			exitWithErrorFunc()
 		}
+		cloudConfig.SetUserAgent("fleet-hub-net-controller-manager")
 
-		klog.V(1).InfoS("Start to setup TrafficManagerBackend controller")
-		if err := (&trafficmanagerbackend.Reconciler{
-			Client:            mgr.GetClient(),
-			ProfilesClient:    profilesClient,
-			EndpointsClient:   endpointsClient,
-			ResourceGroupName: cloudConfig.ResourceGroup,
-			// serviceImport controller has already enabled the internalServiceExportIndexer.
-			// Therefore, no need to setup it again.
-		}).SetupWithManager(ctx, mgr, true); err != nil {
-			klog.ErrorS(err, "Unable to create TrafficManagerProfile controller")
+		frontDoorClient, err := initAzureFrontDoorClient(cloudConfig, quiesceSwitch)
+		if err != nil {
+			klog.ErrorS(err, "Unable to create Azure Front Door client")
 			exitWithErrorFunc()
 		}
+
+		if controllerOptions.Enabled("frontdoorbackend") {
+			klog.V(1).InfoS("Start to setup FrontDoorBackend controller")
+			if err := (&frontdoorbackend.Reconciler{
+				Client:            hubClient,
+				FrontDoorClient:   frontDoorClient,
+				ControllerOptions: controllerOptionsFor("frontdoorbackend"),
+			}).SetupWithManager(ctx, mgr, internalServiceExportIndexed); err != nil {
+				klog.ErrorS(err, "Unable to create FrontDoorBackend controller")
+				exitWithErrorFunc()
+			}
+		}
+
+		if *enableMultiClusterIngress && controllerOptions.Enabled("multiclusteringress") {
+			for _, gvk := range multiClusterIngressRequiredGVKs {
+				if err = utils.CheckCRDInstalled(discoverClient, gvk); err != nil {
+					klog.ErrorS(err, "Unable to find the required CRD", "GVK", gvk)
+					exitWithErrorFunc()
+				}
+			}
+
+			klog.V(1).InfoS("Start to setup MultiClusterIngress controller")
+			if err := (&multiclusteringress.Reconciler{
+				Client:            hubClient,
+				FrontDoorClient:   frontDoorClient,
+				ControllerOptions: controllerOptionsFor("multiclusteringress"),
+			}).SetupWithManager(mgr); err != nil {
+				klog.ErrorS(err, "Unable to create MultiClusterIngress controller")
+				exitWithErrorFunc()
+			}
+		}
 	}
 
 	klog.V(1).InfoS("Starting ServiceExportImport controller manager")
@@ -241,7 +916,40 @@ func main() {
 }
 
 // initAzureTrafficManagerClients initializes the Azure Traffic Manager profiles and endpoints clients.
-func initAzureTrafficManagerClients(cloudConfig *azure.CloudConfig) (*armtrafficmanager.ProfilesClient, *armtrafficmanager.EndpointsClient, error) {
+func initAzureTrafficManagerClients(cloudConfig *azure.CloudConfig, quiesceSwitch *quiesce.Switch) (*armtrafficmanager.ProfilesClient, *armtrafficmanager.EndpointsClient, error) {
+	credential, options, err := initAzureClientOptions(cloudConfig, quiesceSwitch)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	profilesClient, err := armtrafficmanager.NewProfilesClient(cloudConfig.SubscriptionID, credential, options)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create Azure trafficManager profiles client: %w", err)
+	}
+
+	endpointsClient, err := armtrafficmanager.NewEndpointsClient(cloudConfig.SubscriptionID, credential, options)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create Azure trafficManager endpoints client: %w", err)
+	}
+	return profilesClient, endpointsClient, nil
+}
+
+// initAzureFrontDoorClient initializes the client of the Azure Front Door origin groups and origins.
+func initAzureFrontDoorClient(cloudConfig *azure.CloudConfig, quiesceSwitch *quiesce.Switch) (*azurefrontdoor.Client, error) {
+	credential, options, err := initAzureClientOptions(cloudConfig, quiesceSwitch)
+	if err != nil {
+		return nil, err
+	}
+
+	frontDoorClient, err := azurefrontdoor.NewClient(cloudConfig.SubscriptionID, credential, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure Front Door client: %w", err)
+	}
+	return frontDoorClient, nil
+}
+
+// initAzureClientOptions initializes the credential and the options shared by the Azure resource clients.
+func initAzureClientOptions(cloudConfig *azure.CloudConfig, quiesceSwitch *quiesce.Switch) (azcore.TokenCredential, *arm.ClientOptions, error) {
 	authProvider, err := azclient.NewAuthProvider(&cloudConfig.ARMClientConfig, &cloudConfig.AzureAuthConfig)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create Azure auth provider: %w", err)
@@ -259,15 +967,85 @@ func initAzureTrafficManagerClients(cloudConfig *azure.CloudConfig) (*armtraffic
 	if rateLimitPolicy := ratelimit.NewRateLimitPolicy(cloudConfig.Config); rateLimitPolicy != nil {
 		options.ClientOptions.PerCallPolicies = append(options.ClientOptions.PerCallPolicies, rateLimitPolicy)
 	}
+	if *dryRun {
+		options.ClientOptions.PerCallPolicies = append(options.ClientOptions.PerCallPolicies, dryrun.NewAzurePolicy())
+	}
+	options.ClientOptions.PerCallPolicies = append(options.ClientOptions.PerCallPolicies, quiesce.NewAzurePolicy(quiesceSwitch))
+	return authProvider.GetAzIdentity(), options, nil
+}
 
-	profilesClient, err := armtrafficmanager.NewProfilesClient(cloudConfig.SubscriptionID, authProvider.GetAzIdentity(), options)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create Azure trafficManager profiles client: %w", err)
+// splitAndTrim splits a comma-separated list, dropping the empty items.
+func splitAndTrim(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
+	return items
+}
 
-	endpointsClient, err := armtrafficmanager.NewEndpointsClient(cloudConfig.SubscriptionID, authProvider.GetAzIdentity(), options)
+// setupFederation joins the fleet to the upstream fleet as a member cluster, watching the upstream hub cluster through
+// a cluster of the manager, and sets up the controllers federating the selected services.
+func setupFederation(ctx context.Context, mgr ctrl.Manager, hubClient client.Client, quiesceSwitch *quiesce.Switch,
+	selector labels.Selector, controllerOptionsFor func(string) controller.Options) error {
+	upstreamConfig, err := clientcmd.BuildConfigFromFlags("", *upstreamHubKubeconfig)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create Azure trafficManager endpoints client: %w", err)
+		return fmt.Errorf("failed to load the kubeconfig of the upstream hub cluster: %w", err)
 	}
-	return profilesClient, endpointsClient, nil
+	upstreamConfig.QPS = float32(*clientQPS)
+	upstreamConfig.Burst = *clientBurst
+	upstreamCluster, err := cluster.New(upstreamConfig, func(o *cluster.Options) {
+		o.Scheme = mgr.GetScheme()
+		// The fleet is only allowed to access its own namespace in the upstream hub cluster, besides the ServiceImports.
+		namespaces := map[string]cache.Config{*upstreamHubNamespace: {}}
+		o.Cache.ByObject = map[client.Object]cache.ByObject{
+			&fleetnetv1alpha1.InternalServiceExport{}: {Namespaces: namespaces},
+			&fleetnetv1alpha1.InternalServiceImport{}: {Namespaces: namespaces},
+			&fleetnetv1alpha1.EndpointSliceExport{}:   {Namespaces: namespaces},
+			&fleetnetv1alpha1.EndpointSliceImport{}:   {Namespaces: namespaces},
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create the client of the upstream hub cluster: %w", err)
+	}
+	if err := mgr.Add(upstreamCluster); err != nil {
+		return err
+	}
+	upstreamClient := upstreamCluster.GetClient()
+	if *dryRun {
+		upstreamClient = dryrun.NewClient(upstreamClient, dryrun.TargetHub)
+	}
+	upstreamClient = quiesce.NewClient(upstreamClient, quiesceSwitch)
+
+	if controllerOptions.Enabled("federationexport") {
+		klog.V(1).InfoS("Start to setup FederationExport controller")
+		if err := (&federation.ExportReconciler{
+			HubClient:         hubClient,
+			UpstreamClient:    upstreamClient,
+			FleetName:         *fleetName,
+			UpstreamNamespace: *upstreamHubNamespace,
+			UpstreamClusterID: *upstreamClusterID,
+			Selector:          selector,
+			ControllerOptions: controllerOptionsFor("federationexport"),
+			// The endpointsliceexport or serviceimport controller indexes the EndpointSliceExports, unless both are disabled.
+		}).SetupWithManager(ctx, mgr, upstreamCluster, controllerOptions.Enabled("endpointsliceexport") || controllerOptions.Enabled("serviceimport")); err != nil {
+			return fmt.Errorf("failed to create FederationExport controller: %w", err)
+		}
+	}
+	if controllerOptions.Enabled("federationimport") {
+		klog.V(1).InfoS("Start to setup FederationImport controller")
+		if err := (&federation.ImportReconciler{
+			HubClient:         hubClient,
+			UpstreamClient:    upstreamClient,
+			FleetName:         *fleetName,
+			UpstreamNamespace: *upstreamHubNamespace,
+			UpstreamClusterID: *upstreamClusterID,
+			Selector:          selector,
+			ControllerOptions: controllerOptionsFor("federationimport"),
+		}).SetupWithManager(mgr, upstreamCluster); err != nil {
+			return fmt.Errorf("failed to create FederationImport controller: %w", err)
+		}
+	}
+	return nil
 }
@@ -0,0 +1,284 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Command hub-net-maintenance runs maintenance tasks against the hub cluster of a fleet.
+//
+// Usage:
+//
+//	hub-net-maintenance prune --hub-cluster=<name> --active-clusters=<id>,<id>,... [--dry-run=false]
+//	hub-net-maintenance rebuild --hub-cluster=<name> [--window=10m]
+//	hub-net-maintenance migrate-export --hub-cluster=<old> --file=<path>
+//	hub-net-maintenance migrate-import --hub-cluster=<new> --file=<path>
+//	hub-net-maintenance migrate-verify --hub-cluster=<old> --new-hub-cluster=<new>
+//	hub-net-maintenance decommission --hub-cluster=<old> --new-hub-cluster=<new> [--dry-run=false]
+//
+// A fleet moves to a new hub cluster by exporting the objects of the old hub cluster and importing them into the new
+// one, having the member agents join the new hub cluster as an additional hub cluster, verifying that the new hub
+// cluster has converged, pointing the member agents at the new hub cluster, and decommissioning the old one.
+//
+// The hub cluster is looked up by the "<name>-admin" context of the kubeconfig file, which is read from the
+// KUBECONFIG environment variable, or from $HOME/.kube/config if the variable is not set.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/klog/v2"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/migration"
+	"go.goms.io/fleet-networking/pkg/common/prune"
+	"go.goms.io/fleet-networking/pkg/common/rebuild"
+	"go.goms.io/fleet-networking/test/e2e/framework"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(fleetnetv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	klog.InitFlags(nil)
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [flags]\n\nCommands:\n", os.Args[0])
+	fmt.Fprintln(os.Stderr, "  prune           delete the hub objects from member clusters which are no longer active")
+	fmt.Fprintln(os.Stderr, "  rebuild         rebuild the hub state from the exports re-published by the member clusters")
+	fmt.Fprintln(os.Stderr, "  migrate-export  export the objects kept by the fleet operator in the old hub cluster to a file")
+	fmt.Fprintln(os.Stderr, "  migrate-import  import the objects exported from the old hub cluster into the new hub cluster")
+	fmt.Fprintln(os.Stderr, "  migrate-verify  verify that the new hub cluster has converged with the old hub cluster")
+	fmt.Fprintln(os.Stderr, "  decommission    delete the hub objects from all the member clusters once they have left the old hub cluster")
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch cmd, args := flag.Arg(0), flag.Args()[1:]; cmd {
+	case "prune":
+		err = runPrune(context.Background(), args)
+	case "rebuild":
+		err = runRebuild(context.Background(), args)
+	case "migrate-export":
+		err = runMigrateExport(context.Background(), args)
+	case "migrate-import":
+		err = runMigrateImport(context.Background(), args)
+	case "migrate-verify":
+		err = runMigrateVerify(context.Background(), args)
+	case "decommission":
+		err = runDecommission(context.Background(), args)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", cmd)
+		usage()
+		os.Exit(2)
+	}
+	klog.Flush()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func runPrune(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("prune", flag.ExitOnError)
+	hubClusterName := fs.String("hub-cluster", "hub", "The name of the hub cluster, whose kubeconfig context is <name>-admin.")
+	activeClusters := fs.String("active-clusters", "", "The comma-separated IDs of the member clusters which are currently active in the fleet.")
+	dryRun := fs.Bool("dry-run", true, "If set, the objects from inactive member clusters are only reported, rather than deleted.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var clusterIDs []string
+	for _, id := range strings.Split(*activeClusters, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			clusterIDs = append(clusterIDs, id)
+		}
+	}
+
+	hubCluster, err := framework.NewCluster(*hubClusterName, scheme)
+	if err != nil {
+		return fmt.Errorf("failed to initialize the client of hub cluster %s: %w", *hubClusterName, err)
+	}
+	summary, err := prune.Run(ctx, hubCluster.Client(), clusterIDs, *dryRun)
+	if summary != nil {
+		if printErr := summary.Print(os.Stdout); printErr != nil {
+			return printErr
+		}
+	}
+	return err
+}
+
+func runRebuild(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("rebuild", flag.ExitOnError)
+	hubClusterName := fs.String("hub-cluster", "hub", "The name of the hub cluster, whose kubeconfig context is <name>-admin.")
+	window := fs.Duration("window", 10*time.Minute,
+		"How long the hub cluster waits for the member clusters to re-publish their exports before it resolves the ServiceImports.")
+	namespace := fs.String("configmap-namespace", "fleet-system", "The namespace of the rebuild ConfigMap, i.e. the leader election namespace of the hub networking controller manager.")
+	name := fs.String("configmap", "fleet-networking-rebuild", "The name of the rebuild ConfigMap, as set by --rebuild-configmap of the hub networking controller manager.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	hubCluster, err := framework.NewCluster(*hubClusterName, scheme)
+	if err != nil {
+		return fmt.Errorf("failed to initialize the client of hub cluster %s: %w", *hubClusterName, err)
+	}
+	now := time.Now()
+	clusters, err := rebuild.Start(ctx, hubCluster.Client(), types.NamespacedName{Namespace: *namespace, Name: *name}, *window, now)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "Rebuild window open until %s\n", now.Add(*window).UTC().Format(time.RFC3339))
+	fmt.Fprintf(os.Stdout, "Resync requested from %d member cluster(s): %s\n", len(clusters), strings.Join(clusters, ","))
+	fmt.Fprintln(os.Stdout, "Member clusters whose status has been lost re-publish their exports as soon as they report again.")
+	return nil
+}
+
+func runMigrateExport(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("migrate-export", flag.ExitOnError)
+	hubClusterName := fs.String("hub-cluster", "hub", "The name of the old hub cluster, whose kubeconfig context is <name>-admin.")
+	file := fs.String("file", "", "The path of the file the objects are exported to.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return fmt.Errorf("the file to export the objects to is not specified")
+	}
+
+	hubCluster, err := framework.NewCluster(*hubClusterName, scheme)
+	if err != nil {
+		return fmt.Errorf("failed to initialize the client of hub cluster %s: %w", *hubClusterName, err)
+	}
+	f, err := os.Create(filepath.Clean(*file))
+	if err != nil {
+		return err
+	}
+	n, err := migration.Export(ctx, hubCluster.Client(), f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "Exported %d object(s) to %s\n", n, *file)
+	return nil
+}
+
+func runMigrateImport(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("migrate-import", flag.ExitOnError)
+	hubClusterName := fs.String("hub-cluster", "hub", "The name of the new hub cluster, whose kubeconfig context is <name>-admin.")
+	file := fs.String("file", "", "The path of the file the objects have been exported to by migrate-export.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return fmt.Errorf("the file to import the objects from is not specified")
+	}
+
+	hubCluster, err := framework.NewCluster(*hubClusterName, scheme)
+	if err != nil {
+		return fmt.Errorf("failed to initialize the client of hub cluster %s: %w", *hubClusterName, err)
+	}
+	f, err := os.Open(filepath.Clean(*file))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	n, err := migration.Import(ctx, hubCluster.Client(), f)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "Imported %d object(s) into hub cluster %s\n", n, *hubClusterName)
+	fmt.Fprintln(os.Stdout, "Have the member agents join the new hub cluster through --additional-hubs-config, then run migrate-verify.")
+	return nil
+}
+
+func runMigrateVerify(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("migrate-verify", flag.ExitOnError)
+	hubClusterName := fs.String("hub-cluster", "hub", "The name of the old hub cluster, whose kubeconfig context is <name>-admin.")
+	newHubClusterName := fs.String("new-hub-cluster", "", "The name of the new hub cluster, whose kubeconfig context is <name>-admin.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	report, err := verifyMigration(ctx, *hubClusterName, *newHubClusterName)
+	if err != nil {
+		return err
+	}
+	if err := report.Print(os.Stdout); err != nil {
+		return err
+	}
+	if !report.Converged() {
+		return fmt.Errorf("hub cluster %s has yet to converge", *newHubClusterName)
+	}
+	return nil
+}
+
+func runDecommission(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("decommission", flag.ExitOnError)
+	hubClusterName := fs.String("hub-cluster", "hub", "The name of the old hub cluster, whose kubeconfig context is <name>-admin.")
+	newHubClusterName := fs.String("new-hub-cluster", "", "The name of the new hub cluster, whose kubeconfig context is <name>-admin.")
+	quietPeriod := fs.Duration("quiet-period", 5*time.Minute,
+		"How long the member clusters must not have reported to the old hub cluster before it is decommissioned.")
+	dryRun := fs.Bool("dry-run", true, "If set, the objects from the member clusters are only reported, rather than deleted.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// The old hub cluster is decommissioned only once the new one has taken over its state.
+	report, err := verifyMigration(ctx, *hubClusterName, *newHubClusterName)
+	if err != nil {
+		return err
+	}
+	if !report.Converged() {
+		if err := report.Print(os.Stdout); err != nil {
+			return err
+		}
+		return fmt.Errorf("hub cluster %s has yet to converge", *newHubClusterName)
+	}
+
+	hubCluster, err := framework.NewCluster(*hubClusterName, scheme)
+	if err != nil {
+		return fmt.Errorf("failed to initialize the client of hub cluster %s: %w", *hubClusterName, err)
+	}
+	summary, err := migration.Decommission(ctx, hubCluster.Client(), *quietPeriod, time.Now(), *dryRun)
+	if summary != nil {
+		if printErr := summary.Print(os.Stdout); printErr != nil {
+			return printErr
+		}
+	}
+	return err
+}
+
+// verifyMigration compares the state of the new hub cluster with the old hub cluster.
+func verifyMigration(ctx context.Context, oldHubClusterName, newHubClusterName string) (*migration.Report, error) {
+	if newHubClusterName == "" {
+		return nil, fmt.Errorf("the new hub cluster is not specified")
+	}
+	oldHubCluster, err := framework.NewCluster(oldHubClusterName, scheme)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize the client of hub cluster %s: %w", oldHubClusterName, err)
+	}
+	newHubCluster, err := framework.NewCluster(newHubClusterName, scheme)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize the client of hub cluster %s: %w", newHubClusterName, err)
+	}
+	return migration.Verify(ctx, oldHubCluster.Client(), newHubCluster.Client())
+}
@@ -15,6 +15,7 @@ import (
 	"syscall"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/rand"
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -22,6 +23,7 @@ import (
 	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -37,7 +39,12 @@ import (
 	fleetv1alpha1 "go.goms.io/fleet/apis/v1alpha1"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/eventrecorder"
+	"go.goms.io/fleet-networking/pkg/common/fairqueue"
 	"go.goms.io/fleet-networking/pkg/common/hubconfig"
+	"go.goms.io/fleet-networking/pkg/common/ipfamily"
+	"go.goms.io/fleet-networking/pkg/common/leaderstatus"
+	"go.goms.io/fleet-networking/pkg/common/quiesce"
 	imcv1alpha1 "go.goms.io/fleet-networking/pkg/controllers/member/internalmembercluster/v1alpha1"
 	imcv1beta1 "go.goms.io/fleet-networking/pkg/controllers/member/internalmembercluster/v1beta1"
 	"go.goms.io/fleet-networking/pkg/controllers/multiclusterservice"
@@ -60,6 +67,22 @@ var (
 
 	isV1Alpha1APIEnabled = flag.Bool("enable-v1alpha1-apis", true, "If set, the agents will watch for the v1alpha1 APIs.")
 	isV1Beta1APIEnabled  = flag.Bool("enable-v1beta1-apis", false, "If set, the agents will watch for the v1beta1 APIs.")
+
+	eventRate  = flag.Float64("event-rate", 5, "The average number of Kubernetes Events per second the controllers are allowed to emit.")
+	eventBurst = flag.Int("event-burst", 50, "The maximum number of Kubernetes Events the controllers are allowed to emit in a burst.")
+
+	fairQueuePerNamespace = flag.Bool("fair-queue-per-namespace", false,
+		"If set, the controllers watching user namespaces share their reconcile throughput fairly among namespaces, so that a storm of events in one namespace cannot starve the others.")
+	fairQueueNamespaceQuantum = flag.Int("fair-queue-namespace-quantum", 1,
+		"The maximum number of reconciles handed out in a row for one namespace when other namespaces have pending reconciles; only applicable when --fair-queue-per-namespace is set.")
+
+	supportedIPFamilies = flag.String("supported-ip-families", "",
+		"A comma-separated list of the IP families (IPv4, IPv6) supported by the member cluster; endpoints of other IP families are not imported. If empty, all IP families are considered supported.")
+
+	quiesced = flag.Bool("quiesce", false,
+		"If set, the controllers start in quiesce mode, where they keep watching resources but skip all the writes to the member and the hub clusters, "+
+			"and thus to the Azure load balancers of the Services, as well as the Events. Sending SIGHUP to the process toggles the mode at runtime; "+
+			"the skipped writes are made once the mode is cleared.")
 )
 
 func init() {
@@ -129,6 +152,16 @@ func main() {
 		exitWithErrorFunc()
 	}
 
+	// Both managers run their leader election in the member cluster.
+	if err := leaderstatus.SetupWithManager(hubMgr, memberMgr.GetAPIReader(), hubOptions.LeaderElectionNamespace, hubOptions.LeaderElectionID); err != nil {
+		klog.ErrorS(err, "Unable to set up leader election status reporter for hub manager")
+		exitWithErrorFunc()
+	}
+	if err := leaderstatus.SetupWithManager(memberMgr, memberMgr.GetAPIReader(), memberOptions.LeaderElectionNamespace, memberOptions.LeaderElectionID); err != nil {
+		klog.ErrorS(err, "Unable to set up leader election status reporter for member manager")
+		exitWithErrorFunc()
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	klog.V(1).InfoS("Setup controllers with controller manager")
@@ -235,17 +268,35 @@ func prepareMemberParameters() (*rest.Config, *ctrl.Options) {
 	return ctrl.GetConfigOrDie(), memberOpts
 }
 
-func setupControllersWithManager(_ context.Context, hubMgr, memberMgr manager.Manager) error {
+func setupControllersWithManager(ctx context.Context, hubMgr, memberMgr manager.Manager) error {
 	klog.V(1).InfoS("Begin to setup controllers with controller manager")
-	memberClient := memberMgr.GetClient()
-	hubClient := hubMgr.GetClient()
+	quiesceSwitch := quiesce.NewSwitch(*quiesced)
+	quiesceSwitch.ToggleOnSIGHUP(ctx)
+	memberClient := quiesce.NewClient(memberMgr.GetClient(), quiesceSwitch)
+	hubClient := quiesce.NewClient(hubMgr.GetClient(), quiesceSwitch)
+	// All the controllers share one event throttler, which caps the overall rate of Event emission.
+	eventThrottler := eventrecorder.NewThrottler(*eventRate, *eventBurst)
+	var newQueue fairqueue.NewQueueFunc
+	if *fairQueuePerNamespace {
+		newQueue = fairqueue.NewPerNamespaceQueue(*fairQueueNamespaceQuantum)
+	}
+	// The requests reconciled in quiesce mode are requeued once it is cleared, so that their writes are made.
+	newQueue = quiesceSwitch.NewQueue(newQueue)
+
+	ipFamilies, err := parseSupportedIPFamilies()
+	if err != nil {
+		klog.ErrorS(err, "Invalid supported IP families", "supportedIPFamilies", *supportedIPFamilies)
+		return err
+	}
 
 	klog.V(1).InfoS("Create multiclusterservice reconciler")
 	if err := (&multiclusterservice.Reconciler{
 		Client:               memberClient,
 		Scheme:               memberMgr.GetScheme(),
 		FleetSystemNamespace: *fleetSystemNamespace,
-		Recorder:             memberMgr.GetEventRecorderFor(multiclusterservice.ControllerName),
+		Recorder:             quiesce.NewRecorder(eventThrottler.Wrap(memberMgr.GetEventRecorderFor(multiclusterservice.ControllerName), multiclusterservice.ControllerName), quiesceSwitch),
+		SupportedIPFamilies:  ipFamilies,
+		NewQueue:             newQueue,
 	}).SetupWithManager(memberMgr); err != nil {
 		klog.ErrorS(err, "Unable to create multiclusterservice reconciler")
 		return err
@@ -257,6 +308,9 @@ func setupControllersWithManager(_ context.Context, hubMgr, memberMgr manager.Ma
 			MemberClient: memberClient,
 			HubClient:    hubClient,
 			AgentType:    fleetv1alpha1.MultiClusterServiceAgent,
+			ControllerOptions: controller.Options{
+				NewQueue: quiesceSwitch.NewQueue(nil),
+			},
 		}).SetupWithManager(hubMgr); err != nil {
 			klog.ErrorS(err, "Unable to create internalmembercluster (v1alpha1 API) reconciler")
 			return err
@@ -269,6 +323,9 @@ func setupControllersWithManager(_ context.Context, hubMgr, memberMgr manager.Ma
 			MemberClient: memberClient,
 			HubClient:    hubClient,
 			AgentType:    clusterv1beta1.MultiClusterServiceAgent,
+			ControllerOptions: controller.Options{
+				NewQueue: quiesceSwitch.NewQueue(nil),
+			},
 		}).SetupWithManager(hubMgr); err != nil {
 			klog.ErrorS(err, "Unable to create internalmembercluster (v1beta1 API) reconciler")
 			return err
@@ -278,3 +335,11 @@ func setupControllersWithManager(_ context.Context, hubMgr, memberMgr manager.Ma
 	klog.V(1).InfoS("Succeeded to setup controllers with controller manager")
 	return nil
 }
+
+// parseSupportedIPFamilies parses the supported IP families of the member cluster from the flag.
+func parseSupportedIPFamilies() ([]corev1.IPFamily, error) {
+	if *supportedIPFamilies == "" {
+		return nil, nil
+	}
+	return ipfamily.Parse(*supportedIPFamilies)
+}
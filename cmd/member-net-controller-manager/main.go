@@ -9,29 +9,41 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"net"
 	"os"
 	"os/signal"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/privatedns/armprivatedns"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/rand"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/cloud-provider-azure/pkg/azclient"
 	"sigs.k8s.io/cloud-provider-azure/pkg/azclient/policy/ratelimit"
 	"sigs.k8s.io/cloud-provider-azure/pkg/azclient/publicipaddressclient"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/cache"
-	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -42,26 +54,76 @@ import (
 	"go.goms.io/fleet/pkg/utils/cloudconfig/azure"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	fleetnetv1beta1 "go.goms.io/fleet-networking/api/v1beta1"
+	"go.goms.io/fleet-networking/pkg/common/cacheoptions"
+	"go.goms.io/fleet-networking/pkg/common/componentconfig"
+	"go.goms.io/fleet-networking/pkg/common/controlleroptions"
+	"go.goms.io/fleet-networking/pkg/common/diagnostics"
+	"go.goms.io/fleet-networking/pkg/common/dryrun"
 	"go.goms.io/fleet-networking/pkg/common/env"
+	"go.goms.io/fleet-networking/pkg/common/eventrecorder"
+	"go.goms.io/fleet-networking/pkg/common/fairqueue"
+	"go.goms.io/fleet-networking/pkg/common/features"
+	"go.goms.io/fleet-networking/pkg/common/healthcheck"
 	"go.goms.io/fleet-networking/pkg/common/hubconfig"
+	"go.goms.io/fleet-networking/pkg/common/ipam"
+	"go.goms.io/fleet-networking/pkg/common/ipfamily"
+	"go.goms.io/fleet-networking/pkg/common/leaderstatus"
+	"go.goms.io/fleet-networking/pkg/common/logging"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+	"go.goms.io/fleet-networking/pkg/common/metricsauth"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/quiesce"
+	"go.goms.io/fleet-networking/pkg/common/resync"
+	"go.goms.io/fleet-networking/pkg/common/tracing"
+	"go.goms.io/fleet-networking/pkg/controllers/member/autoexport"
+	"go.goms.io/fleet-networking/pkg/controllers/member/clusterproperty"
+	"go.goms.io/fleet-networking/pkg/controllers/member/clustersetdns"
+	"go.goms.io/fleet-networking/pkg/controllers/member/clustersetip"
+	"go.goms.io/fleet-networking/pkg/controllers/member/derivedservice"
+	"go.goms.io/fleet-networking/pkg/controllers/member/eastwestgateway"
 	"go.goms.io/fleet-networking/pkg/controllers/member/endpointslice"
 	"go.goms.io/fleet-networking/pkg/controllers/member/endpointsliceexport"
 	"go.goms.io/fleet-networking/pkg/controllers/member/endpointsliceimport"
+	"go.goms.io/fleet-networking/pkg/controllers/member/gatewayapi"
+	"go.goms.io/fleet-networking/pkg/controllers/member/hubmirror"
 	imcv1alpha1 "go.goms.io/fleet-networking/pkg/controllers/member/internalmembercluster/v1alpha1"
 	imcv1beta1 "go.goms.io/fleet-networking/pkg/controllers/member/internalmembercluster/v1beta1"
 	"go.goms.io/fleet-networking/pkg/controllers/member/internalserviceexport"
 	"go.goms.io/fleet-networking/pkg/controllers/member/internalserviceimport"
+	"go.goms.io/fleet-networking/pkg/controllers/member/istio"
+	"go.goms.io/fleet-networking/pkg/controllers/member/loadbalancerexport"
+	"go.goms.io/fleet-networking/pkg/controllers/member/mcsapi"
+	"go.goms.io/fleet-networking/pkg/controllers/member/networkstatus"
+	"go.goms.io/fleet-networking/pkg/controllers/member/privateendpoint"
+	"go.goms.io/fleet-networking/pkg/controllers/member/privatelinkservice"
+	"go.goms.io/fleet-networking/pkg/controllers/member/reachabilityprobe"
 	"go.goms.io/fleet-networking/pkg/controllers/member/serviceexport"
 	"go.goms.io/fleet-networking/pkg/controllers/member/serviceimport"
+	"go.goms.io/fleet-networking/pkg/controllers/member/tunnel"
+	"go.goms.io/fleet-networking/pkg/controllers/storageversionmigration"
 )
 
 var (
 	scheme = runtime.NewScheme()
 
+	configFile = flag.String("config", "",
+		"The path to the ControllerManagerConfiguration file of the member agent; the flags set on the command line take precedence over it.")
+
 	hubMetricsAddr = flag.String("hub-metrics-bind-address", ":8080", "The address of hub controller manager the metric endpoint binds to.")
 	hubProbeAddr   = flag.String("hub-health-probe-bind-address", ":8081", "The address of hub controller manager the probe endpoint binds to.")
 	metricsAddr    = flag.String("member-metrics-bind-address", ":8090", "The address of member controller manager the metric endpoint binds to.")
 	probeAddr      = flag.String("member-health-probe-bind-address", ":8091", "The address of member controller manager the probe endpoint binds to.")
+	pprofAddr      = flag.String("pprof-bind-address", "",
+		"The address the endpoint serving the pprof profiles and the expvar variables binds to, e.g. localhost:6060. If empty, the endpoint is not served.")
+
+	metricsSecureServing = flag.Bool("metrics-secure-serving", false,
+		"If set, the metric endpoints are served over HTTPS, with the tls.crt and tls.key certificate of the metrics-cert-dir directory.")
+	metricsCertDir = flag.String("metrics-cert-dir", "",
+		"The directory of the tls.crt and tls.key certificate of the metric endpoints; if empty or missing the certificate, a self-signed certificate is generated.")
+	metricsAuthnAuthz = flag.Bool("metrics-authn-authz", false,
+		"If set, the scrapers of the metric endpoints are authenticated by TokenReviews and authorized, to get the /metrics non-resource URL, "+
+			"by SubjectAccessReviews of the member cluster; requires metrics-secure-serving.")
 
 	enableLeaderElection    = flag.Bool("leader-elect", true, "Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
 	leaderElectionNamespace = flag.String("leader-election-namespace", "fleet-system", "The namespace in which the leader election resource will be created.")
@@ -74,16 +136,337 @@ var (
 
 	enableTrafficManagerFeature = flag.Bool("enable-traffic-manager-feature", false, "If set, the traffic manager feature will be enabled.")
 
+	ignoreSystemManagedSvcExportUpdates = flag.Bool("ignore-system-managed-serviceexport-updates", true,
+		"If set, the serviceexport controller will ignore the ServiceExport updates that change only the system managed fields, e.g. managedFields, resourceVersion, and condition timestamps.")
+
+	eventRate  = flag.Float64("event-rate", 5, "The average number of Kubernetes Events per second the controllers are allowed to emit.")
+	eventBurst = flag.Int("event-burst", 50, "The maximum number of Kubernetes Events the controllers are allowed to emit in a burst.")
+
+	// The member agent pushes the exports of its cluster to the hub, e.g. hundreds of EndpointSliceExports after a
+	// restart, which the client-go defaults throttle to a trickle.
+	hubClientQPS = flag.Float64("hub-client-qps", 50,
+		"The average number of requests per second the controllers are allowed to send to the hub API server. "+
+			"If negative, the requests are not throttled by the client, leaving the flow control to the API Priority and Fairness of the API server.")
+	hubClientBurst  = flag.Int("hub-client-burst", 100, "The maximum number of requests the controllers are allowed to send to the hub API server in a burst.")
+	memberClientQPS = flag.Float64("member-client-qps", 50,
+		"The average number of requests per second the controllers are allowed to send to the member API server. "+
+			"If negative, the requests are not throttled by the client, leaving the flow control to the API Priority and Fairness of the API server.")
+	memberClientBurst = flag.Int("member-client-burst", 100, "The maximum number of requests the controllers are allowed to send to the member API server in a burst.")
+
+	// The credentials to the hub clusters are mounted from Secrets which are rotated in place, e.g. the token refreshed
+	// by the token-refresh container.
+	hubCredentialReloadInterval = flag.Duration("hub-credential-reload-interval", 30*time.Second,
+		"How often the hub token file and the kubeconfig files of the additional hubs are checked for rotated credentials, which are reloaded without a restart. "+
+			"If zero, the credentials are only loaded at startup.")
+	// Private clusters often reach the hub API server through a corporate egress proxy.
+	hubProxyURL = flag.String("hub-proxy-url", "",
+		"The URL of the HTTP or HTTPS proxy the agent reaches the hub API servers through, tunneling the connections with CONNECT requests; "+
+			"the user info of the URL, if any, authenticates to the proxy. If empty, the proxy of the kubeconfig or of the environment, e.g. HTTPS_PROXY, is used.")
+	hubProxyCAFile = flag.String("hub-proxy-ca-file", "",
+		"The path to the PEM-encoded certificates the certificate of an HTTPS proxy is verified with; if empty, the system roots are used.")
+	hubAuthMode = flag.String("hub-auth-mode", hubconfig.AuthModeToken,
+		"How the agent authenticates to the hub API server: \"token\", with the token file at CONFIG_PATH; \"workload-identity\", with an Azure AD token of the workload identity of the agent; "+
+			"or \"managed-identity\", with an Azure AD token of the managed identity of the member cluster.")
+	hubAADServerAppID = flag.String("hub-aad-server-app-id", hubconfig.DefaultAADServerAppID,
+		"The ID of the Azure AD server application of the hub API server, which the Azure AD tokens are issued for; only applicable to the workload-identity and managed-identity modes.")
+	hubIdentityClientID = flag.String("hub-identity-client-id", "",
+		"The client ID of the Azure identity the agent authenticates to the hub API server with; if empty, AZURE_CLIENT_ID is used for the workload identity, or the system-assigned identity for the managed identity.")
+
+	fairQueuePerNamespace = flag.Bool("fair-queue-per-namespace", false,
+		"If set, the controllers watching user namespaces share their reconcile throughput fairly among namespaces, so that a storm of events in one namespace cannot starve the others.")
+	fairQueueNamespaceQuantum = flag.Int("fair-queue-namespace-quantum", 1,
+		"The maximum number of reconciles handed out in a row for one namespace when other namespaces have pending reconciles; only applicable when --fair-queue-per-namespace is set.")
+
+	supportedIPFamilies = flag.String("supported-ip-families", "",
+		"A comma-separated list of the IP families (IPv4, IPv6) supported by the member cluster; endpoints of other IP families are not imported. If empty, all IP families are considered supported.")
+
+	endpointSliceDebounceWindow = flag.Duration("endpointslice-debounce-window", 0,
+		"The wait time for the endpointslice controller to export the endpoints of an EndpointSlice after it changes, e.g. 500ms to 5s; the changes within the window, "+
+			"such as the ones made by a rolling update or a scale event, are exported to the hub cluster with one write. If 0, the changes are exported right away.")
+
+	aggregateEndpointSliceExports = flag.Bool("aggregate-endpointslice-exports", false,
+		"If set, the endpoints of all the EndpointSlices of an exported Service are exported with as few EndpointSliceExports as possible, i.e. one for each "+
+			"IP family and set of ports with up to 1000 endpoints each, instead of one for each EndpointSlice, which keeps the number of objects in the hub "+
+			"cluster down for the Services with many EndpointSlices.")
+
+	exportEndpointsOnDemand = flag.Bool("export-endpoints-on-demand", false,
+		"If set, the endpoints of an exported Service are only exported to the hub cluster while other member clusters import the Service, which "+
+			"reduces the load on the hub cluster for the Services with few consumers. It requires the hub agent to be started with --report-import-demand, "+
+			"otherwise no endpoints are exported.")
+
+	serverSideApply = flag.Bool("server-side-apply", true,
+		"If set, the InternalServiceExports and EndpointSliceExports are written to the hub cluster with server-side applies under the "+
+			"\"fleet-networking\" field manager, instead of updates, which avoids the conflicts and retries of the writes under heavy endpoint churn; "+
+			"the fields written with updates before are handed over to the field manager. Set it to false to go back to updates.")
+
+	preferSameRegionEndpoints = flag.Bool("prefer-same-region-endpoints", false,
+		"If set, the imported endpoints in the region of the member cluster, as labeled on its nodes, are hinted for all the zones of the member cluster, "+
+			"so that the endpoints in other regions are only used when no endpoints are left in the region; otherwise the imported endpoints are hinted "+
+			"for their own zones only.")
+
+	healthCheckAnnotationKeys = flag.String("health-check-annotation-keys",
+		objectmeta.ServiceAnnotationHealthCheckPath+","+objectmeta.ServiceAnnotationHealthCheckPort,
+		"A comma-separated list of the keys of the Service annotations which configure the health checks of the endpoints; these annotations "+
+			"are exported along with the Service. The values of the annotations whose keys end with \"path\" must be well-formed URL paths.")
+
+	importOnly = flag.Bool("import-only", false,
+		"If set, the member agent runs in import-only mode for a consumer-only cluster: the controllers which export the Services of the member "+
+			"cluster are disabled, and the ServiceExports of the member cluster are left as they are.")
+
+	enforceImportPolicies = flag.Bool("enforce-import-policies", false,
+		"If set, the ServiceImports, and the endpoints of the origin clusters, denied by the ImportPolicies of the member cluster are not imported; "+
+			"the ImportPolicy CRD must be installed in the member cluster.")
+
+	enableMCSAPICompat = flag.Bool("enable-mcs-api-compat", false,
+		"If set, the ServiceExports and ServiceImports of the upstream Multi-Cluster Services API (multicluster.x-k8s.io) are translated into "+
+			"their fleet-networking counterparts; the upstream CRDs must be installed in the member cluster.")
+
+	enableClusterProperty = flag.Bool("enable-cluster-property", false,
+		"If set, the ID of the member cluster, i.e. the name of its MemberCluster, is registered as the cluster.clusterset.k8s.io ClusterProperty "+
+			"of the upstream About API (about.k8s.io); the agent refuses to start if the cluster has been registered with a different ID. "+
+			"The upstream CRD must be installed in the member cluster.")
+	clusterSetName = flag.String("clusterset-name", "",
+		"The name of the ClusterSet the member cluster belongs to, registered as the clusterset.k8s.io ClusterProperty; only applicable when "+
+			"--enable-cluster-property is set. If empty, the property is not registered.")
+
+	enforceNamespaceSameness = flag.Bool("enforce-namespace-sameness", false,
+		"If set, the Services in the namespaces excluded from multi-cluster networking by the NamespaceSamenessPolicies of the member cluster "+
+			"are not exported; the CRD of the policies must be installed in the member cluster.")
+
+	enableGatewayAPIBackends = flag.Bool("enable-gateway-api-backends", false,
+		"If set, the Gateway API routes of the member cluster can reference ServiceImports, of the fleet-networking or the upstream "+
+			"Multi-Cluster Services API group, as backends; the imported endpoints of the referenced ServiceImports are published into "+
+			"their namespaces as EndpointSlices labeled with multicluster.kubernetes.io/service-name.")
+	gatewayAPIRouteKinds = flag.String("gateway-api-route-kinds", "HTTPRoute",
+		"A comma-separated list of the Gateway API route kinds (HTTPRoute, TCPRoute) whose ServiceImport backends are resolved; the CRDs of "+
+			"the kinds must be installed in the member cluster. Only applicable when --enable-gateway-api-backends is set.")
+
+	enableIstioServiceEntries = flag.Bool("enable-istio-service-entries", false,
+		"If set, the ServiceImports of the member cluster are translated into Istio ServiceEntries for their clusterset.local hosts, with a "+
+			"WorkloadEntry per imported endpoint address, so that the meshes spanning the fleet route to the fleet services without Istio's "+
+			"own multi-cluster discovery; the Istio CRDs must be installed in the member cluster.")
+
+	enableAutoExport = flag.Bool("enable-auto-export", false,
+		"If set, ServiceExports are created and deleted automatically for the Services selected by the AutoExportPolicies and "+
+			"ClusterAutoExportPolicies of the member cluster; the CRDs of the policies must be installed in the member cluster.")
+
+	enableConversionWebhook = flag.Bool("enable-conversion-webhook", false,
+		"If set, the webhook server of the member cluster serves the conversion between the v1alpha1 and v1beta1 APIs of the ServiceExport and "+
+			"ServiceImport CRDs; the CRDs must be configured to use the webhook as their conversion strategy.")
+	enableServiceExportWebhook = flag.Bool("enable-serviceexport-webhook", false,
+		"If set, the webhook server of the member cluster validates ServiceExports at admission time, rejecting the exports of ExternalName "+
+			"Services, of Services in the kube-system and fleet system namespaces, and of Services whose namespace and name are too long.")
+	enableStorageVersionMigration = flag.Bool("enable-storage-version-migration", false,
+		"If set, the objects of the ServiceExport and ServiceImport CRDs in the member cluster that are stored in an older API version "+
+			"are rewritten into the current storage version.")
+
+	clusterSetIPCIDR = flag.String("clusterset-ip-cidr", "",
+		"The CIDR from which a stable virtual IP (ClusterSetIP) is allocated to each imported service of the ClusterSetIP type; the derived "+
+			"Service of the import is given the ClusterSetIP as its cluster IP, so the CIDR must be a reserved part of the service CIDR of the member "+
+			"cluster. A dual-stack member cluster may specify a comma-separated pair of CIDRs of different IP families, e.g. "+
+			"10.255.0.0/24,fd00:255::/120; the imported services exported with the IP family of the second CIDR are then allocated a ClusterSetIP "+
+			"from it as well. If empty, or if the ClusterSetIPAllocation feature is disabled, no ClusterSetIP is allocated.")
+
+	enableClusterSetDNS = flag.Bool("enable-clusterset-dns", false,
+		"If set, the DNS records of the imported services in the clusterset.local zone are rendered into a zone file kept in a ConfigMap "+
+			"of the fleet system namespace, which is expected to be mounted into CoreDNS and served with its file plugin.")
+	clusterSetDNSConfigMap = flag.String("clusterset-dns-configmap", "clusterset-dns",
+		"The name of the ConfigMap in the fleet system namespace keeping the zone file of clusterset.local; only applicable when "+
+			"--enable-clusterset-dns is set.")
+	clusterSetDNSTTLSeconds = flag.Int64("clusterset-dns-ttl-seconds", 5,
+		"The TTL of the DNS records of the imported services which have no TTL hint; only applicable when "+
+			"--enable-clusterset-dns or --enable-private-dns-zone is set.")
+
+	enablePrivateDNSZone = flag.Bool("enable-private-dns-zone", false,
+		"If set, the DNS records of the imported services are written into an Azure Private DNS zone, so that the VMs and the other consumers "+
+			"in the virtual networks linked to the zone can resolve them; the ClusterSetIP services are resolved to the load balancer ingress "+
+			"IPs of their derived Services, and the headless services to the IPs of their ready endpoints.")
+	privateDNSZoneName = flag.String("private-dns-zone-name", "",
+		"The name of the Azure Private DNS zone, e.g. clusterset.contoso.internal; required when --enable-private-dns-zone is set.")
+	privateDNSZoneResourceGroup = flag.String("private-dns-zone-resource-group", "",
+		"The resource group of the Azure Private DNS zone, in the subscription of the cloud config; if empty, the resource group of the cloud config is used.")
+
+	enableEastWestGateway = flag.Bool("enable-east-west-gateway", false,
+		"If set, each TCP port of the exported services is assigned a port of the east-west gateway of the member cluster, and the services "+
+			"are exported with the address of the gateway along with the addresses of their pods; the hub distributes the address of the "+
+			"gateway to the member clusters which cannot reach the pod network of the member cluster, per the ClusterNetworkTopology of the fleet.")
+	eastWestGatewayService = flag.String("east-west-gateway-service", "east-west-gateway",
+		"The name of the LoadBalancer Service of the east-west gateway in the fleet system namespace; only applicable when "+
+			"--enable-east-west-gateway is set.")
+	eastWestGatewayConfigMap = flag.String("east-west-gateway-configmap", "east-west-gateway-config",
+		"The name of the ConfigMap in the fleet system namespace keeping the assigned ports and the Envoy configuration of the east-west "+
+			"gateway; only applicable when --enable-east-west-gateway is set.")
+	eastWestGatewayPortRange = flag.String("east-west-gateway-port-range", "15100-15999",
+		"The range of the ports of the east-west gateway assigned to the ports of the exported services, in the format of <min>-<max>; "+
+			"only applicable when --enable-east-west-gateway is set.")
+
+	enableTunnel = flag.Bool("enable-tunnel", false,
+		"If set, the WireGuard tunnel agent running along with the east-west gateway establishes encrypted tunnels to the other member "+
+			"clusters running it, and the services exported through their gateways are imported at their tunnel addresses once routed, so "+
+			"that the traffic between the member clusters is encrypted in transit; requires --enable-east-west-gateway.")
+	tunnelAddress = flag.String("tunnel-address", "",
+		"The IP address of the tunnel agent within the tunnel network, unique in the fleet, e.g. 100.96.0.1; required when --enable-tunnel is set. "+
+			"The services of another member cluster are imported at its tunnel address only once its gateway answers there from the pod "+
+			"network, i.e. once the tunnel network is routed to the east-west gateway within the member cluster; through the gateways until then.")
+	tunnelPort = flag.Int("tunnel-port", tunnel.DefaultPort,
+		"The UDP port of the tunnel agent; only applicable when --enable-tunnel is set.")
+	tunnelSecret = flag.String("tunnel-secret", "east-west-gateway-tunnel",
+		"The name of the Secret in the fleet system namespace keeping the key and the configuration of the tunnel agent; only applicable "+
+			"when --enable-tunnel is set.")
+	tunnelService = flag.String("tunnel-service", "east-west-gateway-tunnel",
+		"The name of the LoadBalancer Service in the fleet system namespace the tunnel agent is reachable at from the other member clusters; "+
+			"only applicable when --enable-tunnel is set.")
+
+	enablePrivateLinkService = flag.Bool("enable-private-link-service", false,
+		"If set, the exported internal LoadBalancer services annotated with fleet.azure.com/private-link-exposure=true are exposed through "+
+			"Azure Private Link Services, so that the member clusters in the virtual networks not peered with the one of the member cluster "+
+			"can reach them through their Private Endpoints.")
+	privateLinkServiceNATSubnetID = flag.String("private-link-service-nat-subnet-id", "",
+		"The resource ID of the subnet the NAT IPs of the Azure Private Link Services are allocated from; required when "+
+			"--enable-private-link-service is set.")
+	privateLinkServiceAllowedSubscriptions = flag.String("private-link-service-allowed-subscriptions", "",
+		"The comma-separated subscriptions of the member clusters allowed to see, and auto-approved to connect to, the Azure Private Link "+
+			"Services; if empty, the subscription of the cloud config is used. Only applicable when --enable-private-link-service is set.")
+	enablePrivateEndpoint = flag.Bool("enable-private-endpoint", false,
+		"If set, the member cluster connects to the Azure Private Link Services the imported services are exposed through with Private "+
+			"Endpoints in its virtual network, and imports the IPs of the Private Endpoints instead of the endpoints of the exporting clusters.")
+	privateEndpointSubnetID = flag.String("private-endpoint-subnet-id", "",
+		"The resource ID of the subnet the IPs of the Azure Private Endpoints are allocated from, which must be reachable from the pods of "+
+			"the member cluster; required when --enable-private-endpoint is set.")
+
+	enableReachabilityProbe = flag.Bool("enable-reachability-probe", false,
+		"If set, the member cluster probes whether the pods of the other member clusters exporting services through their east-west gateways "+
+			"are reachable, and reports the results to the hub cluster for the ClusterNetworkTopology of the fleet.")
+	reachabilityProbeInterval = flag.Duration("reachability-probe-interval", time.Minute,
+		"How often the pods exported through the east-west gateways are probed; only applicable when --enable-reachability-probe is set.")
+	reachabilityProbeTimeout = flag.Duration("reachability-probe-timeout", 3*time.Second,
+		"How long a probe waits for a connection to a pod to be established; only applicable when --enable-reachability-probe is set.")
+
+	quiesced = flag.Bool("quiesce", false,
+		"If set, the controllers start in quiesce mode, where they keep watching resources but skip all the writes to the member and the hub clusters "+
+			"and to Azure, as well as the Events. Sending SIGHUP to the process toggles the mode at runtime; the skipped writes are made once the mode is cleared.")
+	dryRun = flag.Bool("dry-run", false,
+		"If set, the controllers send all their writes to the member and the hub clusters as dry-run requests and skip all the writes to Azure; "+
+			"the intended writes are logged and counted, so that an upgrade or a configuration change can be validated safely.")
+
+	svcExportFinalizer = flag.String("serviceexport-finalizer", objectmeta.ServiceExportCleanupFinalizer,
+		"The finalizer the serviceexport controller adds to ServiceExports to unexport their Services before they are deleted. "+
+			"Objects given the default finalizer before it was changed are still cleaned up.")
+	svcImportFinalizer = flag.String("serviceimport-finalizer", objectmeta.ServiceImportCleanupFinalizer,
+		"The finalizer the serviceimport controller adds to ServiceImports to withdraw their imports before they are deleted. "+
+			"Objects given the default finalizer before it was changed are still cleaned up.")
+
+	additionalHubsConfig = flag.String("additional-hubs-config", "",
+		"The path to the file listing the hub clusters the member cluster joins besides its primary hub cluster, e.g. the hub cluster of the fleet it migrates to. "+
+			"The services exported to the primary hub cluster are exported to them as well; a ServiceImport is imported from the hub cluster named in its "+
+			"networking.fleet.azure.com/hub annotation, or from the primary hub cluster if it has none. If empty, the member cluster joins its primary hub cluster only.")
+
+	networkStatusReportPeriod = flag.Duration("network-status-report-period", 30*time.Second,
+		"How often the networking agent reports its heartbeat, version, exports, imports and errors to the hub cluster in an InternalMemberNetworkStatus.")
+
+	loggingFormat = flag.String("logging-format", logging.FormatText,
+		"The format of the logs, either text, the klog text format, or json, a JSON object per log entry.")
+
+	tracingOTLPEndpoint = flag.String("tracing-otlp-endpoint", "",
+		"The OTLP/HTTP endpoint, e.g. http://otel-collector:4318, the traces of the controllers are exported to. "+
+			"If empty, the controllers are not traced.")
+
 	cloudConfigFile = flag.String("cloud-config", "/etc/kubernetes/provider/azure.json", "The path to the cloud config file which will be used to access the Azure resource.")
+
+	// controllerOptions tune the throughput of each controller, e.g. --endpointslice-max-concurrent-reconciles.
+	controllerOptions = controlleroptions.AddFlags(flag.CommandLine,
+		"autoexport",
+		"clusterproperty",
+		"clustersetdns",
+		"clustersetdns-privatezone",
+		"clustersetip",
+		"derivedservice",
+		"eastwestgateway",
+		"endpointslice",
+		"endpointsliceexport",
+		"endpointsliceimport",
+		"gatewayapi",
+		"hubmirror",
+		"internalmembercluster",
+		"internalserviceexport",
+		"internalserviceimport",
+		"istio",
+		"loadbalancerexport",
+		"mcsapi-serviceexport",
+		"mcsapi-serviceimport",
+		"privateendpoint",
+		"privatelinkservice",
+		"reachabilityprobe",
+		"serviceexport",
+		"serviceimport",
+		"storageversionmigration",
+		"tunnel",
+	)
+)
+
+var (
+	// featureGates are the feature gates of the configuration file and the flags they set.
+	featureGates = map[string]string{
+		"AutoExport":              "enable-auto-export",
+		"ClusterProperty":         "enable-cluster-property",
+		"ClusterSetDNS":           "enable-clusterset-dns",
+		"ConversionWebhook":       "enable-conversion-webhook",
+		"EastWestGateway":         "enable-east-west-gateway",
+		"GatewayAPIBackends":      "enable-gateway-api-backends",
+		"IstioServiceEntries":     "enable-istio-service-entries",
+		"MCSAPICompat":            "enable-mcs-api-compat",
+		"PrivateDNSZone":          "enable-private-dns-zone",
+		"PrivateEndpoint":         "enable-private-endpoint",
+		"PrivateLinkService":      "enable-private-link-service",
+		"ReachabilityProbe":       "enable-reachability-probe",
+		"ServiceExportWebhook":    "enable-serviceexport-webhook",
+		"StorageVersionMigration": "enable-storage-version-migration",
+		"TrafficManager":          "enable-traffic-manager-feature",
+		"Tunnel":                  "enable-tunnel",
+		"V1Alpha1APIs":            "enable-v1alpha1-apis",
+		"V1Beta1APIs":             "enable-v1beta1-apis",
+	}
+
+	// exportControllers are the controllers which export the Services of the member cluster; they are disabled in
+	// import-only mode.
+	exportControllers = []string{
+		"autoexport",
+		"eastwestgateway",
+		"endpointslice",
+		"endpointsliceexport",
+		"hubmirror",
+		"internalserviceexport",
+		"loadbalancerexport",
+		"mcsapi-serviceexport",
+		"privatelinkservice",
+		"serviceexport",
+	}
+
+	// multiVersionObjects are the objects in the member cluster which are served in both the v1alpha1 and v1beta1 APIs.
+	multiVersionObjects = []client.Object{
+		&fleetnetv1beta1.ServiceExport{},
+		&fleetnetv1beta1.ServiceImport{},
+	}
+	multiVersionCRDNames = []string{
+		"serviceexports.networking.fleet.azure.com",
+		"serviceimports.networking.fleet.azure.com",
+	}
 )
 
 func init() {
 	klog.InitFlags(nil)
+	features.DefaultGate.AddFlag(flag.CommandLine)
 
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(fleetnetv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(fleetnetv1beta1.AddToScheme(scheme))
 	utilruntime.Must(fleetv1alpha1.AddToScheme(scheme))
 	utilruntime.Must(clusterv1beta1.AddToScheme(scheme))
+	utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
+	mcsapi.AddToScheme(scheme)
+	gatewayapi.AddToScheme(scheme)
+	istio.AddToScheme(scheme)
+	clusterproperty.AddToScheme(scheme)
 
 	//+kubebuilder:scaffold:scheme
 }
@@ -103,13 +486,79 @@ func main() {
 
 	defer handleExitFunc()
 
+	// The configuration file sets the flags not set on the command line, so it is applied before any flag is read.
+	var overriddenFlags []string
+	if *configFile != "" {
+		cfg, err := componentconfig.Load(*configFile)
+		if err != nil {
+			klog.ErrorS(err, "Unable to load the configuration file")
+			exitWithErrorFunc()
+		}
+		if overriddenFlags, err = cfg.Apply(flag.CommandLine, featureGates); err != nil {
+			klog.ErrorS(err, "Invalid configuration file", "file", *configFile)
+			exitWithErrorFunc()
+		}
+	}
+
+	// The ID of the member cluster is added to the contextual logs, so that the logs of the member agents can be told
+	// apart once aggregated; a missing ID is reported when the controllers are set up.
+	mcName, _ := env.LookupMemberClusterName()
+	if err := logging.Setup(*loggingFormat, os.Stderr, "clusterID", mcName); err != nil {
+		klog.ErrorS(err, "Unable to set up logging")
+		exitWithErrorFunc()
+	}
+	if len(overriddenFlags) > 0 {
+		klog.InfoS("The flags set on the command line override the configuration file", "flags", overriddenFlags)
+	}
+
 	flag.VisitAll(func(f *flag.Flag) {
 		klog.InfoS("flag:", "name", f.Name, "value", f.Value)
 	})
 
+	diagnostics.SetRuntimeLimits(diagnostics.CgroupRoot)
+
+	if *hubClientBurst < 1 {
+		klog.ErrorS(fmt.Errorf("the burst must be positive, got %d", *hubClientBurst), "Invalid flag", "flag", "hub-client-burst")
+		exitWithErrorFunc()
+	}
+	if *memberClientBurst < 1 {
+		klog.ErrorS(fmt.Errorf("the burst must be positive, got %d", *memberClientBurst), "Invalid flag", "flag", "member-client-burst")
+		exitWithErrorFunc()
+	}
+	if err := hubconfig.ValidateAuthMode(*hubAuthMode); err != nil {
+		klog.ErrorS(err, "Invalid flag", "flag", "hub-auth-mode")
+		exitWithErrorFunc()
+	}
+	if *hubProxyCAFile != "" && *hubProxyURL == "" {
+		klog.ErrorS(fmt.Errorf("the proxy CA file is set without a proxy URL"), "Invalid flag", "flag", "hub-proxy-ca-file")
+		exitWithErrorFunc()
+	}
+	if *hubCredentialReloadInterval < 0 {
+		klog.ErrorS(fmt.Errorf("the interval must not be negative, got %v", *hubCredentialReloadInterval), "Invalid flag", "flag", "hub-credential-reload-interval")
+		exitWithErrorFunc()
+	}
+	if *endpointSliceDebounceWindow < 0 {
+		klog.ErrorS(fmt.Errorf("the debounce window must not be negative, got %v", *endpointSliceDebounceWindow), "Invalid flag", "flag", "endpointslice-debounce-window")
+		exitWithErrorFunc()
+	}
+	if *importOnly {
+		for _, name := range exportControllers {
+			controllerOptions[name].Enabled = false
+		}
+	}
+	if err := controllerOptions.Validate(); err != nil {
+		klog.ErrorS(err, "Invalid controller options")
+		exitWithErrorFunc()
+	}
+	if *metricsAuthnAuthz && !*metricsSecureServing {
+		// The bearer tokens of the scrapers must not be sent in clear text.
+		klog.ErrorS(fmt.Errorf("the metric endpoints are not served over HTTPS"), "Invalid flag", "flag", "metrics-authn-authz")
+		exitWithErrorFunc()
+	}
+
 	memberConfig, memberOptions := prepareMemberParameters()
 
-	hubConfig, hubOptions, err := prepareHubParameters(memberConfig)
+	hubConfig, hubOptions, hubReloader, err := prepareHubParameters(memberConfig)
 	if err != nil {
 		exitWithErrorFunc()
 	}
@@ -120,12 +569,14 @@ func main() {
 		klog.ErrorS(err, "Unable to start hub manager")
 		exitWithErrorFunc()
 	}
-	if err := hubMgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
-		klog.ErrorS(err, "Unable to set up health check for hub manager")
-		exitWithErrorFunc()
+	if hubReloader != nil {
+		if err := hubMgr.Add(hubReloader); err != nil {
+			klog.ErrorS(err, "Unable to set up the credential reloader of hub manager")
+			exitWithErrorFunc()
+		}
 	}
-	if err := hubMgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
-		klog.ErrorS(err, "Unable to set up ready check for hub manager")
+	if err := healthcheck.SetupWithManager(hubMgr, false); err != nil {
+		klog.ErrorS(err, "Unable to set up health and ready checks for hub manager")
 		exitWithErrorFunc()
 	}
 
@@ -135,19 +586,47 @@ func main() {
 		klog.ErrorS(err, "Unable to start member manager")
 		exitWithErrorFunc()
 	}
-	if err := memberMgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
-		klog.ErrorS(err, "Unable to set up health check for member manager")
+	if err := healthcheck.SetupWithManager(memberMgr, *enableConversionWebhook || *enableServiceExportWebhook); err != nil {
+		klog.ErrorS(err, "Unable to set up health and ready checks for member manager")
+		exitWithErrorFunc()
+	}
+	// The profiles and variables are the ones of the process, which runs both managers; they are served once.
+	if err := diagnostics.SetupWithManager(memberMgr, *pprofAddr); err != nil {
+		klog.ErrorS(err, "Unable to set up diagnostics endpoint")
+		exitWithErrorFunc()
+	}
+
+	// Both managers run their leader election in the member cluster.
+	if err := leaderstatus.SetupWithManager(hubMgr, memberMgr.GetAPIReader(), hubOptions.LeaderElectionNamespace, hubOptions.LeaderElectionID); err != nil {
+		klog.ErrorS(err, "Unable to set up leader election status reporter for hub manager")
 		exitWithErrorFunc()
 	}
-	if err := memberMgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
-		klog.ErrorS(err, "Unable to set up ready check for member manager")
+	if err := leaderstatus.SetupWithManager(memberMgr, memberMgr.GetAPIReader(), memberOptions.LeaderElectionNamespace, memberOptions.LeaderElectionID); err != nil {
+		klog.ErrorS(err, "Unable to set up leader election status reporter for member manager")
+		exitWithErrorFunc()
+	}
+
+	additionalHubs, err := prepareAdditionalHubs(memberConfig, memberMgr)
+	if err != nil {
 		exitWithErrorFunc()
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 
+	shutdownTracing, err := tracing.Setup(ctx, *tracingOTLPEndpoint, "member-net-controller-manager")
+	if err != nil {
+		klog.ErrorS(err, "Unable to set up tracing")
+		exitWithErrorFunc()
+	}
+	defer func() {
+		// The managers have stopped and the context is canceled by now; the pending spans are flushed without it.
+		if err := shutdownTracing(context.Background()); err != nil {
+			klog.ErrorS(err, "Failed to shut down tracing")
+		}
+	}()
+
 	klog.V(1).InfoS("Setup controllers with controller manager")
-	if err := setupControllersWithManager(ctx, hubMgr, memberMgr); err != nil {
+	if err := setupControllersWithManager(ctx, hubMgr, memberMgr, additionalHubs); err != nil {
 		klog.ErrorS(err, "Unable to setup controllers with manager")
 		exitWithErrorFunc()
 	}
@@ -189,6 +668,21 @@ func main() {
 			startErrors = append(startErrors, err)
 		}
 	}()
+	for _, hub := range additionalHubs {
+		wg.Add(1)
+		go func() {
+			klog.V(1).InfoS("Starting additional hub manager for ServiceExportImport agent", "hub", hub.Name)
+			defer func() {
+				wg.Done()
+				klog.V(1).InfoS("Shutting down additional hub manager", "hub", hub.Name)
+				cancel()
+			}()
+			if err := hub.mgr.Start(ctx); err != nil {
+				klog.ErrorS(err, "Failed to start additional hub manager", "hub", hub.Name)
+				startErrors = append(startErrors, err)
+			}
+		}()
+	}
 
 	wg.Wait()
 
@@ -197,24 +691,24 @@ func main() {
 	}
 }
 
-func prepareHubParameters(memberConfig *rest.Config) (*rest.Config, *ctrl.Options, error) {
-	hubConfig, err := hubconfig.PrepareHubConfig(*tlsClientInsecure)
+func prepareHubParameters(memberConfig *rest.Config) (*rest.Config, *ctrl.Options, *hubconfig.CredentialReloader, error) {
+	hubConfig, reloader, err := prepareHubConfig()
 	if err != nil {
-		klog.ErrorS(err, "Failed to get hub config")
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
+	hubConfig.QPS = float32(*hubClientQPS)
+	hubConfig.Burst = *hubClientBurst
 
 	mcHubNamespace, err := hubconfig.FetchMemberClusterNamespace()
 	if err != nil {
 		klog.ErrorS(err, "Failed to get member cluster hub namespace")
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	hubOptions := &ctrl.Options{
 		Scheme: scheme,
-		Metrics: metricsserver.Options{
-			BindAddress: *hubMetricsAddr,
-		},
+		// The scrapers run in the member cluster, which authenticates them.
+		Metrics: metricsServerOptions(*hubMetricsAddr, memberConfig),
 		WebhookServer: webhook.NewServer(webhook.Options{
 			Port: 9443,
 		}),
@@ -224,21 +718,167 @@ func prepareHubParameters(memberConfig *rest.Config) (*rest.Config, *ctrl.Option
 		LeaderElectionNamespace: *leaderElectionNamespace, // This requires we have access to resource "leases" in API group "coordination.k8s.io" under leaderElectionNamespace.
 		LeaderElectionConfig:    memberConfig,
 		// Restricts the manager's cache to watch objects in the member hub namespace.
-		Cache: cache.Options{
-			DefaultNamespaces: map[string]cache.Config{
-				mcHubNamespace: {},
-			},
-		},
+		Cache: cacheoptions.Hub(mcHubNamespace),
+	}
+	return hubConfig, hubOptions, reloader, nil
+}
+
+// prepareHubConfig returns the config of the clients of the hub cluster, which authenticate per --hub-auth-mode,
+// and the reloader of their credentials, if any.
+func prepareHubConfig() (*rest.Config, *hubconfig.CredentialReloader, error) {
+	if *hubAuthMode != hubconfig.AuthModeToken {
+		cred, err := hubconfig.NewAzureCredential(hubconfig.AzureAuthOptions{
+			Mode:        *hubAuthMode,
+			ServerAppID: *hubAADServerAppID,
+			ClientID:    *hubIdentityClientID,
+		})
+		if err != nil {
+			klog.ErrorS(err, "Failed to get the Azure credential of hub config", "mode", *hubAuthMode)
+			return nil, nil, err
+		}
+		// The Azure AD tokens are acquired and renewed by the credential, with no file to reload.
+		hubConfig, err := hubconfig.PrepareHubConfigWithAzureCredential(*tlsClientInsecure, cred, *hubAADServerAppID)
+		if err == nil {
+			err = setHubProxy(hubConfig)
+		}
+		if err != nil {
+			klog.ErrorS(err, "Failed to get hub config")
+			return nil, nil, err
+		}
+		return hubConfig, nil, nil
+	}
+
+	load := func() (*rest.Config, error) {
+		hubConfig, err := hubconfig.PrepareHubConfig(*tlsClientInsecure)
+		if err != nil {
+			return nil, err
+		}
+		return hubConfig, setHubProxy(hubConfig)
+	}
+	hubConfig, err := load()
+	if err != nil {
+		klog.ErrorS(err, "Failed to get hub config")
+		return nil, nil, err
+	}
+	if *hubCredentialReloadInterval == 0 {
+		return hubConfig, nil, nil
+	}
+	reloader, err := hubconfig.NewCredentialReloader("primary", load, hubConfig.BearerTokenFile)
+	if err != nil {
+		klog.ErrorS(err, "Failed to set up the credential reloader of hub config")
+		return nil, nil, err
 	}
-	return hubConfig, hubOptions, nil
+	reloader.Interval = *hubCredentialReloadInterval
+	return reloader.Config(), reloader, nil
+}
+
+// setHubProxy makes the clients of the hub config reach the hub API server through --hub-proxy-url, if set.
+func setHubProxy(hubConfig *rest.Config) error {
+	if *hubProxyURL == "" {
+		return nil
+	}
+	return hubconfig.SetProxy(hubConfig, hubconfig.ProxyOptions{URL: *hubProxyURL, CAFile: *hubProxyCAFile})
+}
+
+// additionalHub is a hub cluster the member cluster joins besides its primary hub cluster, with the manager of the
+// controllers exporting the services to and importing the services from it.
+type additionalHub struct {
+	hubconfig.AdditionalHub
+	mgr manager.Manager
+}
+
+// prepareAdditionalHubs creates the managers of the additional hub clusters listed in --additional-hubs-config.
+func prepareAdditionalHubs(memberConfig *rest.Config, memberMgr manager.Manager) ([]additionalHub, error) {
+	if *additionalHubsConfig == "" {
+		return nil, nil
+	}
+	mcName, err := env.LookupMemberClusterName()
+	if err != nil {
+		klog.ErrorS(err, "Member cluster name cannot be empty")
+		return nil, err
+	}
+	hubs, err := hubconfig.LoadAdditionalHubs(*additionalHubsConfig, mcName)
+	if err != nil {
+		klog.ErrorS(err, "Failed to load the additional hubs", "file", *additionalHubsConfig)
+		return nil, err
+	}
+
+	additionalHubs := make([]additionalHub, 0, len(hubs))
+	for _, hub := range hubs {
+		load := func() (*rest.Config, error) {
+			hubConfig, err := clientcmd.BuildConfigFromFlags("", hub.Kubeconfig)
+			if err != nil {
+				return nil, err
+			}
+			return hubConfig, setHubProxy(hubConfig)
+		}
+		hubConfig, err := load()
+		if err != nil {
+			klog.ErrorS(err, "Failed to get additional hub config", "hub", hub.Name, "kubeconfig", hub.Kubeconfig)
+			return nil, err
+		}
+		var reloader *hubconfig.CredentialReloader
+		if *hubCredentialReloadInterval > 0 {
+			reloader, err = hubconfig.NewCredentialReloader(hub.Name, load, hub.Kubeconfig)
+			if err != nil {
+				klog.ErrorS(err, "Failed to set up the credential reloader of additional hub config", "hub", hub.Name)
+				return nil, err
+			}
+			reloader.Interval = *hubCredentialReloadInterval
+			hubConfig = reloader.Config()
+		}
+		hubConfig.QPS = float32(*hubClientQPS)
+		hubConfig.Burst = *hubClientBurst
+
+		hubOptions := ctrl.Options{
+			Scheme: scheme,
+			// The metrics of the controllers are served by the managers of the primary hub and the member clusters,
+			// as they share one registry.
+			Metrics:                 metricsserver.Options{BindAddress: "0"},
+			LeaderElection:          *enableLeaderElection,
+			LeaderElectionID:        fmt.Sprintf("2bf2b407.hub-%s.networking.fleet.azure.com", hub.Name),
+			LeaderElectionNamespace: *leaderElectionNamespace,
+			LeaderElectionConfig:    memberConfig,
+			Cache:                   cacheoptions.Hub(hub.Namespace),
+		}
+		mgr, err := ctrl.NewManager(hubConfig, hubOptions)
+		if err != nil {
+			klog.ErrorS(err, "Unable to start additional hub manager", "hub", hub.Name)
+			return nil, err
+		}
+		if reloader != nil {
+			if err := mgr.Add(reloader); err != nil {
+				klog.ErrorS(err, "Unable to set up the credential reloader of additional hub manager", "hub", hub.Name)
+				return nil, err
+			}
+		}
+		if err := leaderstatus.SetupWithManager(mgr, memberMgr.GetAPIReader(), hubOptions.LeaderElectionNamespace, hubOptions.LeaderElectionID); err != nil {
+			klog.ErrorS(err, "Unable to set up leader election status reporter for additional hub manager", "hub", hub.Name)
+			return nil, err
+		}
+		additionalHubs = append(additionalHubs, additionalHub{AdditionalHub: hub, mgr: mgr})
+	}
+	return additionalHubs, nil
+}
+
+// metricsServerOptions returns the options of a metric endpoint bound to the given address, whose scrapers, if
+// authenticated, are authenticated by the API server of the given config, or of the manager if nil.
+func metricsServerOptions(bindAddress string, reviewConfig *rest.Config) metricsserver.Options {
+	opts := metricsserver.Options{
+		BindAddress:   bindAddress,
+		SecureServing: *metricsSecureServing,
+		CertDir:       *metricsCertDir,
+	}
+	if *metricsAuthnAuthz {
+		opts.FilterProvider = metricsauth.FilterProvider(reviewConfig)
+	}
+	return opts
 }
 
 func prepareMemberParameters() (*rest.Config, *ctrl.Options) {
 	memberOpts := &ctrl.Options{
-		Scheme: scheme,
-		Metrics: metricsserver.Options{
-			BindAddress: *metricsAddr,
-		},
+		Scheme:  scheme,
+		Metrics: metricsServerOptions(*metricsAddr, nil),
 		WebhookServer: webhook.NewServer(webhook.Options{
 			Port: 8443,
 		}),
@@ -246,11 +886,16 @@ func prepareMemberParameters() (*rest.Config, *ctrl.Options) {
 		LeaderElection:          *enableLeaderElection,
 		LeaderElectionNamespace: *leaderElectionNamespace,
 		LeaderElectionID:        "2bf2b407.member.networking.fleet.azure.com",
+		// Restricts the manager's cache to the EndpointSlices and the ConfigMaps the controllers read.
+		Cache: cacheoptions.Member(*fleetSystemNamespace),
 	}
-	return ctrl.GetConfigOrDie(), memberOpts
+	memberConfig := ctrl.GetConfigOrDie()
+	memberConfig.QPS = float32(*memberClientQPS)
+	memberConfig.Burst = *memberClientBurst
+	return memberConfig, memberOpts
 }
 
-func setupControllersWithManager(ctx context.Context, hubMgr, memberMgr manager.Manager) error {
+func setupControllersWithManager(ctx context.Context, hubMgr, memberMgr manager.Manager, additionalHubs []additionalHub) error {
 	klog.V(1).InfoS("Begin to setup controllers with controller manager")
 
 	mcName, err := env.LookupMemberClusterName()
@@ -265,73 +910,286 @@ func setupControllersWithManager(ctx context.Context, hubMgr, memberMgr manager.
 		return err
 	}
 
-	memberClient := memberMgr.GetClient()
-	hubClient := hubMgr.GetClient()
+	quiesceSwitch := quiesce.NewSwitch(*quiesced)
+	quiesceSwitch.ToggleOnSIGHUP(ctx)
+	memberClient, hubClient := memberMgr.GetClient(), hubMgr.GetClient()
+	if *dryRun {
+		klog.InfoS("Running in dry-run mode; no writes are persisted")
+		memberClient = dryrun.NewClient(memberClient, dryrun.TargetMember)
+		hubClient = dryrun.NewClient(hubClient, dryrun.TargetHub)
+	}
+	memberClient = quiesce.NewClient(memberClient, quiesceSwitch)
+	hubClient = quiesce.NewClient(hubClient, quiesceSwitch)
+	// The failed writes to the hub cluster are counted, as they keep the exports and imports of the member
+	// cluster from being synced.
+	hubClient = metrics.CountHubWriteFailures(hubClient)
+	// The requests reconciled in quiesce mode are requeued once it is cleared, so that their writes are made.
+	controllerOptionsFor := func(name string) controller.Options {
+		opts := controllerOptions.For(name)
+		opts.NewQueue = quiesceSwitch.NewQueue(nil)
+		return opts
+	}
 
-	klog.V(1).InfoS("Create endpointslice controller")
-	if err := (&endpointslice.Reconciler{
-		MemberClusterID: mcName,
-		MemberClient:    memberClient,
-		HubClient:       hubClient,
-		HubNamespace:    mcHubNamespace,
-	}).SetupWithManager(ctx, memberMgr); err != nil {
-		klog.ErrorS(err, "Unable to create endpointslice controller")
-		return err
+	if *enableClusterProperty {
+		// The cluster properties are registered before any controller starts, so that nothing is exported from a
+		// cluster registered with a different ID.
+		klog.V(1).InfoS("Register the cluster properties", "clusterID", mcName, "clusterSet", *clusterSetName)
+		if err := clusterproperty.Register(ctx, memberMgr.GetAPIReader(), memberClient, mcName, *clusterSetName); err != nil {
+			klog.ErrorS(err, "Unable to register the cluster properties")
+			return err
+		}
+
+		if controllerOptions.Enabled("clusterproperty") {
+			klog.V(1).InfoS("Create clusterproperty controller")
+			if err := (&clusterproperty.Reconciler{
+				Client:            memberClient,
+				ClusterID:         mcName,
+				ClusterSetName:    *clusterSetName,
+				ControllerOptions: controllerOptionsFor("clusterproperty"),
+			}).SetupWithManager(memberMgr); err != nil {
+				klog.ErrorS(err, "Unable to create clusterproperty controller")
+				return err
+			}
+		}
+	}
+	// All the controllers share one event throttler, which caps the overall rate of Event emission.
+	eventThrottler := eventrecorder.NewThrottler(*eventRate, *eventBurst)
+	var newQueue fairqueue.NewQueueFunc
+	if *fairQueuePerNamespace {
+		newQueue = fairqueue.NewPerNamespaceQueue(*fairQueueNamespaceQuantum)
 	}
+	newQueue = quiesceSwitch.NewQueue(newQueue)
 
-	klog.V(1).InfoS("Create endpointsliceexport controller")
-	if err := (&endpointsliceexport.Reconciler{
-		MemberClient: memberClient,
-		HubClient:    hubClient,
-	}).SetupWithManager(hubMgr); err != nil {
-		klog.ErrorS(err, "Unable to create endpointsliceexport controller")
-		return err
+	var eastWestGateway *eastwestgateway.Reader
+	if *enableEastWestGateway {
+		minPort, maxPort, err := eastwestgateway.ParsePortRange(*eastWestGatewayPortRange)
+		if err != nil {
+			klog.ErrorS(err, "Invalid east-west-gateway-port-range", "portRange", *eastWestGatewayPortRange)
+			return err
+		}
+		if controllerOptions.Enabled("eastwestgateway") {
+			klog.V(1).InfoS("Create eastwestgateway reconciler", "configMap", klog.KRef(*fleetSystemNamespace, *eastWestGatewayConfigMap))
+			if err := (&eastwestgateway.Reconciler{
+				Client:               memberClient,
+				FleetSystemNamespace: *fleetSystemNamespace,
+				ServiceName:          *eastWestGatewayService,
+				ConfigMapName:        *eastWestGatewayConfigMap,
+				MinPort:              minPort,
+				MaxPort:              maxPort,
+				ControllerOptions:    controllerOptionsFor("eastwestgateway"),
+			}).SetupWithManager(memberMgr); err != nil {
+				klog.ErrorS(err, "Unable to create eastwestgateway reconciler")
+				return err
+			}
+		}
+		eastWestGateway = &eastwestgateway.Reader{
+			Client:        memberClient,
+			Namespace:     *fleetSystemNamespace,
+			ServiceName:   *eastWestGatewayService,
+			ConfigMapName: *eastWestGatewayConfigMap,
+		}
+	}
+
+	var tunnelReader *tunnel.Reader
+	var tunnelPeer func(ctx context.Context) (*fleetnetv1alpha1.TunnelPeer, error)
+	if *enableTunnel {
+		if !*enableEastWestGateway {
+			err := errors.New("--enable-east-west-gateway is required when --enable-tunnel is set")
+			klog.ErrorS(err, "Invalid tunnel")
+			return err
+		}
+		if net.ParseIP(*tunnelAddress) == nil {
+			err := fmt.Errorf("--tunnel-address %q is not a valid IP address", *tunnelAddress)
+			klog.ErrorS(err, "Invalid tunnel")
+			return err
+		}
+		if *tunnelPort < 1 || *tunnelPort > 65535 {
+			err := fmt.Errorf("--tunnel-port %d is out of range", *tunnelPort)
+			klog.ErrorS(err, "Invalid tunnel")
+			return err
+		}
+		if controllerOptions.Enabled("tunnel") {
+			klog.V(1).InfoS("Create tunnel reconciler", "secret", klog.KRef(*fleetSystemNamespace, *tunnelSecret))
+			if err := (&tunnel.Reconciler{
+				MemberClusterID:      mcName,
+				MemberClient:         memberClient,
+				HubClient:            hubClient,
+				HubNamespace:         mcHubNamespace,
+				FleetSystemNamespace: *fleetSystemNamespace,
+				SecretName:           *tunnelSecret,
+				Address:              *tunnelAddress,
+				Port:                 int32(*tunnelPort),
+				ControllerOptions:    controllerOptionsFor("tunnel"),
+			}).SetupWithManager(hubMgr, memberMgr); err != nil {
+				klog.ErrorS(err, "Unable to create tunnel reconciler")
+				return err
+			}
+		}
+		tunnelReader = &tunnel.Reader{
+			HubClient:       hubClient,
+			HubNamespace:    mcHubNamespace,
+			MemberClusterID: mcName,
+		}
+		tunnelPeer = (&tunnel.Agent{
+			MemberClusterID: mcName,
+			Client:          memberClient,
+			Namespace:       *fleetSystemNamespace,
+			SecretName:      *tunnelSecret,
+			ServiceName:     *tunnelService,
+			Address:         *tunnelAddress,
+			Port:            int32(*tunnelPort),
+		}).Peer
+	}
+
+	if controllerOptions.Enabled("loadbalancerexport") {
+		klog.V(1).InfoS("Create loadbalancerexport controller")
+		if err := (&loadbalancerexport.Reconciler{
+			Client:            memberClient,
+			ControllerOptions: controllerOptionsFor("loadbalancerexport"),
+		}).SetupWithManager(memberMgr); err != nil {
+			klog.ErrorS(err, "Unable to create loadbalancerexport controller")
+			return err
+		}
+	}
+
+	// The exports are re-published at once when the hub state is rebuilt.
+	resyncer := &resync.Resyncer{MemberClient: memberClient}
+
+	if controllerOptions.Enabled("endpointslice") {
+		klog.V(1).InfoS("Create endpointslice controller")
+		if err := (&endpointslice.Reconciler{
+			MemberClusterID:   mcName,
+			MemberClient:      memberClient,
+			HubClient:         hubClient,
+			HubNamespace:      mcHubNamespace,
+			NewQueue:          newQueue,
+			EastWestGateway:   eastWestGateway,
+			Recorder:          quiesce.NewRecorder(eventThrottler.Wrap(memberMgr.GetEventRecorderFor(endpointslice.ControllerName), endpointslice.ControllerName), quiesceSwitch),
+			DebounceWindow:    *endpointSliceDebounceWindow,
+			AggregateExports:  *aggregateEndpointSliceExports,
+			ExportOnDemand:    *exportEndpointsOnDemand,
+			ServerSideApply:   *serverSideApply,
+			ResyncEvents:      resyncer.Subscribe(),
+			ControllerOptions: controllerOptionsFor("endpointslice"),
+		}).SetupWithManager(ctx, memberMgr); err != nil {
+			klog.ErrorS(err, "Unable to create endpointslice controller")
+			return err
+		}
 	}
 
-	klog.V(1).InfoS("Create endpointsliceimport controller")
-	if err := (&endpointsliceimport.Reconciler{
-		MemberClusterID:      mcName,
-		MemberClient:         memberClient,
-		HubClient:            hubClient,
-		FleetSystemNamespace: *fleetSystemNamespace,
-	}).SetupWithManager(ctx, memberMgr, hubMgr); err != nil {
-		klog.ErrorS(err, "Unable to create endpointsliceimport controller")
+	if controllerOptions.Enabled("endpointsliceexport") {
+		klog.V(1).InfoS("Create endpointsliceexport controller")
+		if err := (&endpointsliceexport.Reconciler{
+			MemberClient:                  memberClient,
+			HubClient:                     hubClient,
+			AggregateEndpointSliceExports: *aggregateEndpointSliceExports,
+			ControllerOptions:             controllerOptionsFor("endpointsliceexport"),
+		}).SetupWithManager(hubMgr); err != nil {
+			klog.ErrorS(err, "Unable to create endpointsliceexport controller")
+			return err
+		}
+	}
+
+	ipFamilies, err := parseSupportedIPFamilies()
+	if err != nil {
+		klog.ErrorS(err, "Invalid supported IP families", "supportedIPFamilies", *supportedIPFamilies)
 		return err
 	}
 
-	klog.V(1).InfoS("Create internalserviceexport controller")
-	if err := (&internalserviceexport.Reconciler{
+	if controllerOptions.Enabled("endpointsliceimport") {
+		klog.V(1).InfoS("Create endpointsliceimport controller")
+		if err := (&endpointsliceimport.Reconciler{
+			MemberClusterID:       mcName,
+			MemberClient:          memberClient,
+			HubClient:             hubClient,
+			FleetSystemNamespace:  *fleetSystemNamespace,
+			SupportedIPFamilies:   ipFamilies,
+			PreferSameRegion:      *preferSameRegionEndpoints,
+			EnforceImportPolicies: *enforceImportPolicies,
+			Tunnel:                tunnelReader,
+			ControllerOptions:     controllerOptionsFor("endpointsliceimport"),
+		}).SetupWithManager(ctx, memberMgr, hubMgr); err != nil {
+			klog.ErrorS(err, "Unable to create endpointsliceimport controller")
+			return err
+		}
+	}
+
+	if *enableReachabilityProbe && controllerOptions.Enabled("reachabilityprobe") {
+		klog.V(1).InfoS("Create reachabilityprobe controller")
+		if err := (&reachabilityprobe.Reconciler{
+			MemberClusterID:   mcName,
+			HubClient:         hubClient,
+			Interval:          *reachabilityProbeInterval,
+			Timeout:           *reachabilityProbeTimeout,
+			ControllerOptions: controllerOptionsFor("reachabilityprobe"),
+		}).SetupWithManager(hubMgr); err != nil {
+			klog.ErrorS(err, "Unable to create reachabilityprobe controller")
+			return err
+		}
+	}
+
+	klog.V(1).InfoS("Create networkstatus reporter")
+	if err := (&networkstatus.Reporter{
 		MemberClusterID: mcName,
 		MemberClient:    memberClient,
 		HubClient:       hubClient,
-		Recorder:        memberMgr.GetEventRecorderFor(internalserviceexport.ControllerName),
+		HubNamespace:    mcHubNamespace,
+		Period:          *networkStatusReportPeriod,
+		Resync:          resyncer.Resync,
+		Tunnel:          tunnelPeer,
 	}).SetupWithManager(hubMgr); err != nil {
-		klog.ErrorS(err, "Unable to create internalserviceexport controller")
+		klog.ErrorS(err, "Unable to create networkstatus reporter")
 		return err
 	}
 
-	klog.V(1).InfoS("Create internalserviceimport controller")
-	if err := (&internalserviceimport.Reconciler{
-		MemberClient: memberClient,
-		HubClient:    hubClient,
-	}).SetupWithManager(hubMgr); err != nil {
-		klog.ErrorS(err, "Unable to create internalserviceimport controller")
-		return err
+	if controllerOptions.Enabled("internalserviceexport") {
+		klog.V(1).InfoS("Create internalserviceexport controller")
+		if err := (&internalserviceexport.Reconciler{
+			MemberClusterID:   mcName,
+			MemberClient:      memberClient,
+			HubClient:         hubClient,
+			Recorder:          quiesce.NewRecorder(eventThrottler.Wrap(memberMgr.GetEventRecorderFor(internalserviceexport.ControllerName), internalserviceexport.ControllerName), quiesceSwitch),
+			ControllerOptions: controllerOptionsFor("internalserviceexport"),
+		}).SetupWithManager(hubMgr); err != nil {
+			klog.ErrorS(err, "Unable to create internalserviceexport controller")
+			return err
+		}
 	}
 
-	var azurePublicIPAddressClient publicipaddressclient.Interface
-	var resourceGroupName string
-	if *enableTrafficManagerFeature {
-		klog.V(1).InfoS("Traffic manager feature is enabled, loading cloud config and creating azure clients", "cloudConfigFile", *cloudConfigFile)
-		cloudConfig, err := azure.NewCloudConfigFromFile(*cloudConfigFile)
+	if controllerOptions.Enabled("internalserviceimport") {
+		klog.V(1).InfoS("Create internalserviceimport controller")
+		if err := (&internalserviceimport.Reconciler{
+			MemberClient:      memberClient,
+			HubClient:         hubClient,
+			Recorder:          quiesce.NewRecorder(eventThrottler.Wrap(memberMgr.GetEventRecorderFor(internalserviceimport.ControllerName), internalserviceimport.ControllerName), quiesceSwitch),
+			ControllerOptions: controllerOptionsFor("internalserviceimport"),
+		}).SetupWithManager(hubMgr); err != nil {
+			klog.ErrorS(err, "Unable to create internalserviceimport controller")
+			return err
+		}
+	}
+
+	var cloudConfig *azure.CloudConfig
+	if *enableTrafficManagerFeature || *enablePrivateDNSZone || *enablePrivateLinkService || *enablePrivateEndpoint {
+		klog.V(1).InfoS("Azure features are enabled, loading cloud config", "cloudConfigFile", *cloudConfigFile,
+			"enableTrafficManagerFeature", *enableTrafficManagerFeature, "enablePrivateDNSZone", *enablePrivateDNSZone,
+			"enablePrivateLinkService", *enablePrivateLinkService, "enablePrivateEndpoint", *enablePrivateEndpoint)
+		var err error
+		cloudConfig, err = azure.NewCloudConfigFromFile(*cloudConfigFile)
 		if err != nil {
 			klog.ErrorS(err, "Unable to load cloud config", "file name", *cloudConfigFile)
 			return err
 		}
 		cloudConfig.SetUserAgent("fleet-member-net-controller-manager")
 		klog.V(1).InfoS("Cloud config loaded", "cloudConfig", cloudConfig)
+	}
 
-		azurePublicIPAddressClient, err = initAzureNetworkClients(cloudConfig)
+	var azurePublicIPAddressClient publicipaddressclient.Interface
+	var resourceGroupName string
+	if *enableTrafficManagerFeature {
+		klog.V(1).InfoS("Traffic manager feature is enabled, creating azure clients")
+		var err error
+		azurePublicIPAddressClient, err = initAzureNetworkClients(cloudConfig, quiesceSwitch)
 		if err != nil {
 			klog.ErrorS(err, "Unable to create Azure Traffic Manager clients")
 			return err
@@ -340,65 +1198,481 @@ func setupControllersWithManager(ctx context.Context, hubMgr, memberMgr manager.
 		resourceGroupName = cloudConfig.ResourceGroup
 	}
 
-	klog.V(1).InfoS("Create serviceexport reconciler", "enableTrafficManagerFeature", *enableTrafficManagerFeature)
-	if err := (&serviceexport.Reconciler{
-		MemberClient:                memberClient,
-		HubClient:                   hubClient,
-		MemberClusterID:             mcName,
-		HubNamespace:                mcHubNamespace,
-		Recorder:                    memberMgr.GetEventRecorderFor(serviceexport.ControllerName),
-		EnableTrafficManagerFeature: *enableTrafficManagerFeature,
-		ResourceGroupName:           resourceGroupName,
-		AzurePublicIPAddressClient:  azurePublicIPAddressClient,
-	}).SetupWithManager(memberMgr); err != nil {
-		klog.ErrorS(err, "Unable to create serviceexport reconciler")
-		return err
+	if controllerOptions.Enabled("serviceexport") {
+		klog.V(1).InfoS("Create serviceexport reconciler", "enableTrafficManagerFeature", *enableTrafficManagerFeature)
+		if err := (&serviceexport.Reconciler{
+			MemberClient:                  memberClient,
+			HubClient:                     hubClient,
+			MemberClusterID:               mcName,
+			HubNamespace:                  mcHubNamespace,
+			Recorder:                      quiesce.NewRecorder(eventThrottler.Wrap(memberMgr.GetEventRecorderFor(serviceexport.ControllerName), serviceexport.ControllerName), quiesceSwitch),
+			EnableTrafficManagerFeature:   *enableTrafficManagerFeature,
+			ResourceGroupName:             resourceGroupName,
+			AzurePublicIPAddressClient:    azurePublicIPAddressClient,
+			IgnoreSystemManagedUpdates:    *ignoreSystemManagedSvcExportUpdates,
+			CleanupFinalizer:              *svcExportFinalizer,
+			HealthCheckAnnotationKeys:     splitAndTrim(*healthCheckAnnotationKeys),
+			EnforceNamespaceSameness:      *enforceNamespaceSameness,
+			RejectHeadlessServices:        !features.Enabled(features.HeadlessServiceExport),
+			AggregateEndpointSliceExports: *aggregateEndpointSliceExports,
+			ServerSideApply:               *serverSideApply,
+			NewQueue:                      newQueue,
+			ResyncEvents:                  resyncer.Subscribe(),
+			ControllerOptions:             controllerOptionsFor("serviceexport"),
+		}).SetupWithManager(memberMgr); err != nil {
+			klog.ErrorS(err, "Unable to create serviceexport reconciler")
+			return err
+		}
 	}
 
-	klog.V(1).InfoS("Create serviceimport reconciler")
-	if err := (&serviceimport.Reconciler{
-		MemberClient:    memberClient,
-		HubClient:       hubClient,
-		MemberClusterID: mcName,
-		HubNamespace:    mcHubNamespace,
-	}).SetupWithManager(memberMgr); err != nil {
-		klog.ErrorS(err, "Unable to create serviceimport reconciler")
-		return err
+	if controllerOptions.Enabled("serviceimport") {
+		klog.V(1).InfoS("Create serviceimport reconciler")
+		if err := (&serviceimport.Reconciler{
+			MemberClient:          memberClient,
+			HubClient:             hubClient,
+			MemberClusterID:       mcName,
+			HubNamespace:          mcHubNamespace,
+			Finalizer:             *svcImportFinalizer,
+			EnforceImportPolicies: *enforceImportPolicies,
+			ControllerOptions:     controllerOptionsFor("serviceimport"),
+		}).SetupWithManager(memberMgr); err != nil {
+			klog.ErrorS(err, "Unable to create serviceimport reconciler")
+			return err
+		}
+	}
+
+	if controllerOptions.Enabled("derivedservice") {
+		klog.V(1).InfoS("Create derivedservice reconciler")
+		if err := (&derivedservice.Reconciler{
+			Client:               memberClient,
+			FleetSystemNamespace: *fleetSystemNamespace,
+			ControllerOptions:    controllerOptionsFor("derivedservice"),
+		}).SetupWithManager(memberMgr); err != nil {
+			klog.ErrorS(err, "Unable to create derivedservice reconciler")
+			return err
+		}
 	}
 
-	if *isV1Alpha1APIEnabled {
+	if *clusterSetIPCIDR != "" && features.Enabled(features.ClusterSetIPAllocation) {
+		allocator, secondaryAllocator, err := newClusterSetIPAllocators(*clusterSetIPCIDR)
+		if err != nil {
+			klog.ErrorS(err, "Invalid clusterset-ip-cidr", "clusterSetIPCIDR", *clusterSetIPCIDR)
+			return err
+		}
+		if controllerOptions.Enabled("clustersetip") {
+			klog.V(1).InfoS("Create clustersetip reconciler", "clusterSetIPCIDR", *clusterSetIPCIDR)
+			if err := (&clustersetip.Reconciler{
+				Client:             memberClient,
+				Allocator:          allocator,
+				SecondaryAllocator: secondaryAllocator,
+				ControllerOptions:  controllerOptionsFor("clustersetip"),
+			}).SetupWithManager(memberMgr); err != nil {
+				klog.ErrorS(err, "Unable to create clustersetip reconciler")
+				return err
+			}
+		}
+	}
+
+	if *enableClusterSetDNS && controllerOptions.Enabled("clustersetdns") {
+		klog.V(1).InfoS("Create clustersetdns reconciler", "configMap", klog.KRef(*fleetSystemNamespace, *clusterSetDNSConfigMap))
+		if err := (&clustersetdns.Reconciler{
+			Client:               memberClient,
+			FleetSystemNamespace: *fleetSystemNamespace,
+			ConfigMapName:        *clusterSetDNSConfigMap,
+			DefaultTTLSeconds:    *clusterSetDNSTTLSeconds,
+			ControllerOptions:    controllerOptionsFor("clustersetdns"),
+		}).SetupWithManager(memberMgr); err != nil {
+			klog.ErrorS(err, "Unable to create clustersetdns reconciler")
+			return err
+		}
+	}
+
+	if *enablePrivateDNSZone {
+		if *privateDNSZoneName == "" {
+			err := errors.New("--private-dns-zone-name is required when --enable-private-dns-zone is set")
+			klog.ErrorS(err, "Invalid private DNS zone")
+			return err
+		}
+		zoneResourceGroup := *privateDNSZoneResourceGroup
+		if zoneResourceGroup == "" {
+			zoneResourceGroup = cloudConfig.ResourceGroup
+		}
+		recordSetsClient, err := initAzurePrivateDNSClient(cloudConfig, quiesceSwitch)
+		if err != nil {
+			klog.ErrorS(err, "Unable to create Azure Private DNS client")
+			return err
+		}
+		if controllerOptions.Enabled("clustersetdns-privatezone") {
+			klog.V(1).InfoS("Create clustersetdns private zone reconciler", "privateDNSZone", klog.KRef(zoneResourceGroup, *privateDNSZoneName))
+			if err := (&clustersetdns.PrivateZoneReconciler{
+				Client:               memberClient,
+				FleetSystemNamespace: *fleetSystemNamespace,
+				MemberClusterID:      mcName,
+				RecordSetsClient:     recordSetsClient,
+				ResourceGroupName:    zoneResourceGroup,
+				ZoneName:             *privateDNSZoneName,
+				DefaultTTLSeconds:    *clusterSetDNSTTLSeconds,
+				ControllerOptions:    controllerOptionsFor("clustersetdns-privatezone"),
+			}).SetupWithManager(memberMgr); err != nil {
+				klog.ErrorS(err, "Unable to create clustersetdns private zone reconciler")
+				return err
+			}
+		}
+	}
+
+	if *enablePrivateLinkService {
+		if *privateLinkServiceNATSubnetID == "" {
+			err := errors.New("--private-link-service-nat-subnet-id is required when --enable-private-link-service is set")
+			klog.ErrorS(err, "Invalid private link service")
+			return err
+		}
+		allowedSubscriptions := splitAndTrim(*privateLinkServiceAllowedSubscriptions)
+		if len(allowedSubscriptions) == 0 {
+			allowedSubscriptions = []string{cloudConfig.SubscriptionID}
+		}
+		clientFactory, err := initAzureNetworkClientFactory(cloudConfig, quiesceSwitch)
+		if err != nil {
+			klog.ErrorS(err, "Unable to create Azure network clients")
+			return err
+		}
+		if controllerOptions.Enabled("privatelinkservice") {
+			klog.V(1).InfoS("Create privatelinkservice reconciler", "resourceGroup", cloudConfig.ResourceGroup)
+			if err := (&privatelinkservice.Reconciler{
+				Client:                    memberClient,
+				MemberClusterID:           mcName,
+				PrivateLinkServicesClient: clientFactory.NewPrivateLinkServicesClient(),
+				LoadBalancersClient:       clientFactory.NewLoadBalancersClient(),
+				ResourceGroupName:         cloudConfig.ResourceGroup,
+				Location:                  cloudConfig.Location,
+				NATSubnetID:               *privateLinkServiceNATSubnetID,
+				AllowedSubscriptions:      allowedSubscriptions,
+				ControllerOptions:         controllerOptionsFor("privatelinkservice"),
+			}).SetupWithManager(memberMgr); err != nil {
+				klog.ErrorS(err, "Unable to create privatelinkservice reconciler")
+				return err
+			}
+		}
+	}
+
+	if *enablePrivateEndpoint {
+		if *privateEndpointSubnetID == "" {
+			err := errors.New("--private-endpoint-subnet-id is required when --enable-private-endpoint is set")
+			klog.ErrorS(err, "Invalid private endpoint")
+			return err
+		}
+		clientFactory, err := initAzureNetworkClientFactory(cloudConfig, quiesceSwitch)
+		if err != nil {
+			klog.ErrorS(err, "Unable to create Azure network clients")
+			return err
+		}
+		if controllerOptions.Enabled("privateendpoint") {
+			klog.V(1).InfoS("Create privateendpoint reconciler", "resourceGroup", cloudConfig.ResourceGroup)
+			if err := (&privateendpoint.Reconciler{
+				HubClient:              hubClient,
+				HubNamespace:           mcHubNamespace,
+				MemberClusterID:        mcName,
+				PrivateEndpointsClient: clientFactory.NewPrivateEndpointsClient(),
+				InterfacesClient:       clientFactory.NewInterfacesClient(),
+				ResourceGroupName:      cloudConfig.ResourceGroup,
+				Location:               cloudConfig.Location,
+				SubnetID:               *privateEndpointSubnetID,
+				ControllerOptions:      controllerOptionsFor("privateendpoint"),
+			}).SetupWithManager(hubMgr); err != nil {
+				klog.ErrorS(err, "Unable to create privateendpoint reconciler")
+				return err
+			}
+		}
+	}
+
+	if *enableMCSAPICompat {
+		if controllerOptions.Enabled("mcsapi-serviceexport") {
+			klog.V(1).InfoS("Create upstream MCS API serviceexport reconciler")
+			if err := (&mcsapi.ServiceExportReconciler{
+				Client:            memberClient,
+				Scheme:            memberMgr.GetScheme(),
+				ControllerOptions: controllerOptionsFor("mcsapi-serviceexport"),
+			}).SetupWithManager(memberMgr); err != nil {
+				klog.ErrorS(err, "Unable to create upstream MCS API serviceexport reconciler")
+				return err
+			}
+		}
+
+		if controllerOptions.Enabled("mcsapi-serviceimport") {
+			klog.V(1).InfoS("Create upstream MCS API serviceimport reconciler")
+			if err := (&mcsapi.ServiceImportReconciler{
+				Client:            memberClient,
+				Scheme:            memberMgr.GetScheme(),
+				ControllerOptions: controllerOptionsFor("mcsapi-serviceimport"),
+			}).SetupWithManager(memberMgr); err != nil {
+				klog.ErrorS(err, "Unable to create upstream MCS API serviceimport reconciler")
+				return err
+			}
+		}
+	}
+
+	if *enableGatewayAPIBackends {
+		var routeGVKs []schema.GroupVersionKind
+		for _, kind := range splitAndTrim(*gatewayAPIRouteKinds) {
+			gvk, err := gatewayapi.RouteGVKForKind(kind)
+			if err != nil {
+				klog.ErrorS(err, "Invalid gateway-api-route-kinds", "gatewayAPIRouteKinds", *gatewayAPIRouteKinds)
+				return err
+			}
+			routeGVKs = append(routeGVKs, gvk)
+		}
+		if controllerOptions.Enabled("gatewayapi") {
+			klog.V(1).InfoS("Create gatewayapi reconciler", "routeKinds", *gatewayAPIRouteKinds)
+			if err := (&gatewayapi.Reconciler{
+				Client:               memberClient,
+				Scheme:               memberMgr.GetScheme(),
+				FleetSystemNamespace: *fleetSystemNamespace,
+				RouteGVKs:            routeGVKs,
+				ControllerOptions:    controllerOptionsFor("gatewayapi"),
+			}).SetupWithManager(ctx, memberMgr); err != nil {
+				klog.ErrorS(err, "Unable to create gatewayapi reconciler")
+				return err
+			}
+		}
+	}
+
+	if *enableIstioServiceEntries && controllerOptions.Enabled("istio") {
+		klog.V(1).InfoS("Create istio reconciler")
+		if err := (&istio.Reconciler{
+			Client:               memberClient,
+			Scheme:               memberMgr.GetScheme(),
+			FleetSystemNamespace: *fleetSystemNamespace,
+			ControllerOptions:    controllerOptionsFor("istio"),
+		}).SetupWithManager(memberMgr); err != nil {
+			klog.ErrorS(err, "Unable to create istio reconciler")
+			return err
+		}
+	}
+
+	if *enableAutoExport && controllerOptions.Enabled("autoexport") {
+		klog.V(1).InfoS("Create autoexport reconciler")
+		if err := (&autoexport.Reconciler{
+			Client:             memberClient,
+			ReservedNamespaces: []string{metav1.NamespaceSystem, *fleetSystemNamespace},
+			ControllerOptions:  controllerOptionsFor("autoexport"),
+		}).SetupWithManager(memberMgr); err != nil {
+			klog.ErrorS(err, "Unable to create autoexport reconciler")
+			return err
+		}
+	}
+
+	if *enableConversionWebhook {
+		klog.V(1).InfoS("Create conversion webhook")
+		for _, obj := range multiVersionObjects {
+			if err := ctrl.NewWebhookManagedBy(memberMgr).For(obj).Complete(); err != nil {
+				klog.ErrorS(err, "Unable to create conversion webhook", "object", fmt.Sprintf("%T", obj))
+				return err
+			}
+		}
+	}
+
+	if *enableServiceExportWebhook {
+		klog.V(1).InfoS("Create serviceexport validating webhook")
+		if err := (&serviceexport.Validator{
+			Reader:             memberMgr.GetAPIReader(),
+			ReservedNamespaces: []string{metav1.NamespaceSystem, *fleetSystemNamespace},
+		}).SetupWebhookWithManager(memberMgr); err != nil {
+			klog.ErrorS(err, "Unable to create serviceexport validating webhook")
+			return err
+		}
+	}
+
+	if *enableStorageVersionMigration && controllerOptions.Enabled("storageversionmigration") {
+		klog.V(1).InfoS("Create storageversionmigration reconciler")
+		if err := (&storageversionmigration.Reconciler{
+			Client:            memberClient,
+			CRDNames:          multiVersionCRDNames,
+			ControllerOptions: controllerOptionsFor("storageversionmigration"),
+		}).SetupWithManager(memberMgr); err != nil {
+			klog.ErrorS(err, "Unable to create storageversionmigration reconciler")
+			return err
+		}
+	}
+
+	if *isV1Alpha1APIEnabled && controllerOptions.Enabled("internalmembercluster") {
 		klog.V(1).InfoS("Create internalmembercluster (v1alpha1 API) reconciler")
 		if err := (&imcv1alpha1.Reconciler{
-			MemberClient: memberClient,
-			HubClient:    hubClient,
-			AgentType:    fleetv1alpha1.ServiceExportImportAgent,
+			MemberClient:      memberClient,
+			HubClient:         hubClient,
+			AgentType:         fleetv1alpha1.ServiceExportImportAgent,
+			ControllerOptions: controllerOptionsFor("internalmembercluster"),
 		}).SetupWithManager(hubMgr); err != nil {
 			klog.ErrorS(err, "Unable to create internalmembercluster (v1alpha1 API) reconciler")
 			return err
 		}
 	}
 
-	if *isV1Beta1APIEnabled {
+	if *isV1Beta1APIEnabled && controllerOptions.Enabled("internalmembercluster") {
 		klog.V(1).InfoS("Create internalmembercluster (v1beta1 API) reconciler")
 		if err := (&imcv1beta1.Reconciler{
-			MemberClient: memberClient,
-			HubClient:    hubClient,
-			AgentType:    clusterv1beta1.ServiceExportImportAgent,
+			MemberClient:      memberClient,
+			HubClient:         hubClient,
+			AgentType:         clusterv1beta1.ServiceExportImportAgent,
+			ControllerOptions: controllerOptionsFor("internalmembercluster"),
 		}).SetupWithManager(hubMgr); err != nil {
 			klog.ErrorS(err, "Unable to create internalmembercluster (v1beta1 API) reconciler")
 			return err
 		}
 	}
 
+	for _, hub := range additionalHubs {
+		if err := setupAdditionalHubControllers(ctx, hub, hubMgr, memberMgr, hubClient, memberClient, mcName, mcHubNamespace, ipFamilies, eventThrottler, quiesceSwitch, controllerOptionsFor); err != nil {
+			return err
+		}
+	}
+
 	klog.V(1).InfoS("Succeeded to setup controllers with controller manager")
 	return nil
 }
 
+// setupAdditionalHubControllers sets up the controllers exporting the services of the member cluster to an additional
+// hub cluster, by mirroring the exports of the primary hub cluster, and importing the services from it.
+func setupAdditionalHubControllers(ctx context.Context, hub additionalHub, primaryHubMgr, memberMgr manager.Manager,
+	primaryHubClient, memberClient client.Client, mcName, mcHubNamespace string, ipFamilies []corev1.IPFamily,
+	eventThrottler *eventrecorder.Throttler, quiesceSwitch *quiesce.Switch, controllerOptionsFor func(string) controller.Options) error {
+	hubClient := hub.mgr.GetClient()
+	if *dryRun {
+		hubClient = dryrun.NewClient(hubClient, dryrun.TargetHub)
+	}
+	hubClient = quiesce.NewClient(hubClient, quiesceSwitch)
+	hubClient = metrics.CountHubWriteFailures(hubClient)
+
+	if controllerOptions.Enabled("hubmirror") {
+		klog.V(1).InfoS("Create hubmirror controllers", "hub", hub.Name)
+		mirror := hubmirror.Reconciler{
+			PrimaryHubClient:    primaryHubClient,
+			PrimaryHubNamespace: mcHubNamespace,
+			HubClient:           hubClient,
+			HubNamespace:        hub.Namespace,
+			HubName:             hub.Name,
+			ControllerOptions:   controllerOptionsFor("hubmirror"),
+		}
+		if err := hubmirror.NewInternalServiceExportReconciler(mirror).SetupWithManager(hub.mgr, primaryHubMgr); err != nil {
+			klog.ErrorS(err, "Unable to create hubmirror controller for InternalServiceExports", "hub", hub.Name)
+			return err
+		}
+		if err := hubmirror.NewEndpointSliceExportReconciler(mirror).SetupWithManager(hub.mgr, primaryHubMgr); err != nil {
+			klog.ErrorS(err, "Unable to create hubmirror controller for EndpointSliceExports", "hub", hub.Name)
+			return err
+		}
+	}
+
+	if controllerOptions.Enabled("endpointsliceimport") {
+		klog.V(1).InfoS("Create endpointsliceimport controller", "hub", hub.Name)
+		if err := (&endpointsliceimport.Reconciler{
+			MemberClusterID:       mcName,
+			MemberClient:          memberClient,
+			HubClient:             hubClient,
+			HubName:               hub.Name,
+			FleetSystemNamespace:  *fleetSystemNamespace,
+			SupportedIPFamilies:   ipFamilies,
+			PreferSameRegion:      *preferSameRegionEndpoints,
+			EnforceImportPolicies: *enforceImportPolicies,
+			ControllerOptions:     controllerOptionsFor("endpointsliceimport"),
+		}).SetupWithManager(ctx, memberMgr, hub.mgr); err != nil {
+			klog.ErrorS(err, "Unable to create endpointsliceimport controller", "hub", hub.Name)
+			return err
+		}
+	}
+
+	if controllerOptions.Enabled("internalserviceimport") {
+		klog.V(1).InfoS("Create internalserviceimport controller", "hub", hub.Name)
+		if err := (&internalserviceimport.Reconciler{
+			MemberClient:      memberClient,
+			HubClient:         hubClient,
+			HubName:           hub.Name,
+			Recorder:          quiesce.NewRecorder(eventThrottler.Wrap(memberMgr.GetEventRecorderFor(internalserviceimport.ControllerName), internalserviceimport.ControllerName), quiesceSwitch),
+			ControllerOptions: controllerOptionsFor("internalserviceimport"),
+		}).SetupWithManager(hub.mgr); err != nil {
+			klog.ErrorS(err, "Unable to create internalserviceimport controller", "hub", hub.Name)
+			return err
+		}
+	}
+
+	if controllerOptions.Enabled("serviceimport") {
+		klog.V(1).InfoS("Create serviceimport reconciler", "hub", hub.Name)
+		if err := (&serviceimport.Reconciler{
+			MemberClient:    memberClient,
+			HubClient:       hubClient,
+			MemberClusterID: mcName,
+			HubNamespace:    hub.Namespace,
+			HubName:         hub.Name,
+			// Each hub cluster withdraws the imports from it before a ServiceImport is deleted.
+			Finalizer:             fmt.Sprintf("%s-%s", *svcImportFinalizer, hub.Name),
+			EnforceImportPolicies: *enforceImportPolicies,
+			ControllerOptions:     controllerOptionsFor("serviceimport"),
+		}).SetupWithManager(memberMgr); err != nil {
+			klog.ErrorS(err, "Unable to create serviceimport reconciler", "hub", hub.Name)
+			return err
+		}
+	}
+
+	klog.V(1).InfoS("Create networkstatus reporter", "hub", hub.Name)
+	if err := (&networkstatus.Reporter{
+		MemberClusterID: mcName,
+		MemberClient:    memberClient,
+		HubClient:       hubClient,
+		HubNamespace:    hub.Namespace,
+		Period:          *networkStatusReportPeriod,
+	}).SetupWithManager(hub.mgr); err != nil {
+		klog.ErrorS(err, "Unable to create networkstatus reporter", "hub", hub.Name)
+		return err
+	}
+	return nil
+}
+
 // initAzureNetworkClients initializes the Azure network resource clients, currently only publicIPAddressClient.
-func initAzureNetworkClients(cloudConfig *azure.CloudConfig) (publicipaddressclient.Interface, error) {
+func initAzureNetworkClients(cloudConfig *azure.CloudConfig, quiesceSwitch *quiesce.Switch) (publicipaddressclient.Interface, error) {
+	credential, options, err := initAzureClientOptions(cloudConfig, quiesceSwitch)
+	if err != nil {
+		return nil, err
+	}
+
+	pipClient, err := publicipaddressclient.New(cloudConfig.SubscriptionID, credential, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure PublicIPAddress client: %w", err)
+	}
+
+	return pipClient, nil
+}
+
+// initAzurePrivateDNSClient initializes the client of the record sets of Azure Private DNS zones.
+func initAzurePrivateDNSClient(cloudConfig *azure.CloudConfig, quiesceSwitch *quiesce.Switch) (*armprivatedns.RecordSetsClient, error) {
+	credential, options, err := initAzureClientOptions(cloudConfig, quiesceSwitch)
+	if err != nil {
+		return nil, err
+	}
+
+	recordSetsClient, err := armprivatedns.NewRecordSetsClient(cloudConfig.SubscriptionID, credential, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure Private DNS record sets client: %w", err)
+	}
+	return recordSetsClient, nil
+}
+
+// initAzureNetworkClientFactory initializes the factory of the Azure network resource clients.
+func initAzureNetworkClientFactory(cloudConfig *azure.CloudConfig, quiesceSwitch *quiesce.Switch) (*armnetwork.ClientFactory, error) {
+	credential, options, err := initAzureClientOptions(cloudConfig, quiesceSwitch)
+	if err != nil {
+		return nil, err
+	}
+
+	clientFactory, err := armnetwork.NewClientFactory(cloudConfig.SubscriptionID, credential, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure network client factory: %w", err)
+	}
+	return clientFactory, nil
+}
+
+// initAzureClientOptions initializes the credential and the options shared by the Azure resource clients.
+func initAzureClientOptions(cloudConfig *azure.CloudConfig, quiesceSwitch *quiesce.Switch) (azcore.TokenCredential, *arm.ClientOptions, error) {
 	authProvider, err := azclient.NewAuthProvider(&cloudConfig.ARMClientConfig, &cloudConfig.AzureAuthConfig)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Azure auth provider: %w", err)
+		return nil, nil, fmt.Errorf("failed to create Azure auth provider: %w", err)
 	}
 
 	factoryConfig := &azclient.ClientFactoryConfig{
@@ -407,17 +1681,58 @@ func initAzureNetworkClients(cloudConfig *azure.CloudConfig) (publicipaddresscli
 	}
 	options, err := azclient.GetDefaultResourceClientOption(&cloudConfig.ARMClientConfig, factoryConfig)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get default resource client option: %w", err)
+		return nil, nil, fmt.Errorf("failed to get default resource client option: %w", err)
 	}
 
 	if rateLimitPolicy := ratelimit.NewRateLimitPolicy(cloudConfig.Config); rateLimitPolicy != nil {
 		options.ClientOptions.PerCallPolicies = append(options.ClientOptions.PerCallPolicies, rateLimitPolicy)
 	}
+	if *dryRun {
+		options.ClientOptions.PerCallPolicies = append(options.ClientOptions.PerCallPolicies, dryrun.NewAzurePolicy())
+	}
+	options.ClientOptions.PerCallPolicies = append(options.ClientOptions.PerCallPolicies, quiesce.NewAzurePolicy(quiesceSwitch))
+	return authProvider.GetAzIdentity(), options, nil
+}
+
+// parseSupportedIPFamilies parses the supported IP families of the member cluster from the flag.
+func parseSupportedIPFamilies() ([]corev1.IPFamily, error) {
+	if *supportedIPFamilies == "" {
+		return nil, nil
+	}
+	return ipfamily.Parse(*supportedIPFamilies)
+}
 
-	pipClient, err := publicipaddressclient.New(cloudConfig.SubscriptionID, authProvider.GetAzIdentity(), options)
+// newClusterSetIPAllocators returns the allocators of the ClusterSetIPs from the comma-separated CIDRs; the secondary
+// allocator is nil unless a second CIDR of the other IP family is specified.
+func newClusterSetIPAllocators(cidrs string) (*ipam.Allocator, *ipam.Allocator, error) {
+	items := splitAndTrim(cidrs)
+	if len(items) == 0 || len(items) > 2 {
+		return nil, nil, fmt.Errorf("expected one CIDR or a pair of CIDRs of different IP families, got %q", cidrs)
+	}
+	allocator, err := ipam.NewAllocator(items[0])
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Azure PublicIPAddress client: %w", err)
+		return nil, nil, err
+	}
+	if len(items) == 1 {
+		return allocator, nil, nil
+	}
+	secondaryAllocator, err := ipam.NewAllocator(items[1])
+	if err != nil {
+		return nil, nil, err
 	}
+	if allocator.Family() == secondaryAllocator.Family() {
+		return nil, nil, fmt.Errorf("CIDRs %s and %s are of the same IP family %s", allocator.CIDR(), secondaryAllocator.CIDR(), allocator.Family())
+	}
+	return allocator, secondaryAllocator, nil
+}
 
-	return pipClient, nil
+// splitAndTrim splits a comma-separated list, dropping the empty items.
+func splitAndTrim(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
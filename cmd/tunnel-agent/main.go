@@ -0,0 +1,215 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Command tunnel-agent runs along with the east-west gateway of a member cluster, and brings up the WireGuard
+// interface of the gateway with the configuration the member-net-controller-manager renders into the Secret of the
+// tunnel agent; the peers and their routes are synced as the configuration changes, and the interface is brought down
+// as the agent stops.
+//
+// The agent runs the wg and wg-quick tools of the image with the NET_ADMIN capability only, within the network
+// namespace of the gateway; forwarding must be enabled in the namespace, as the sysctls are read-only to the agent.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+var (
+	configPath   = flag.String("config", "/etc/fleet/tunnel/wg0.conf", "The path of the wg-quick configuration rendered by the member-net-controller-manager.")
+	runDir       = flag.String("run-dir", "/run/wireguard", "The directory the configuration is copied to, readable by the agent only.")
+	syncInterval = flag.Duration("sync-interval", 10*time.Second, "How often the configuration is checked for changes.")
+)
+
+func init() {
+	klog.InitFlags(nil)
+}
+
+func main() {
+	flag.Parse()
+	defer klog.Flush()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+	if err := run(ctx); err != nil {
+		klog.ErrorS(err, "Tunnel agent failed")
+		klog.Flush()
+		os.Exit(1)
+	}
+}
+
+// run brings up the interface once the configuration is written, and syncs it until the context is done.
+func run(ctx context.Context) error {
+	ticker := time.NewTicker(*syncInterval)
+	defer ticker.Stop()
+
+	// The Secret is created by the member-net-controller-manager as soon as it starts.
+	var config []byte
+	for {
+		var err error
+		if config, err = os.ReadFile(*configPath); err == nil {
+			break
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		klog.V(2).InfoS("Waiting for the configuration of the tunnel agent", "path", *configPath)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+
+	if err := checkForwarding(config); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(*runDir, 0o700); err != nil {
+		return err
+	}
+	// wg-quick names the interface after the file.
+	path := filepath.Join(*runDir, filepath.Base(*configPath))
+	iface := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if err := os.WriteFile(path, config, 0o600); err != nil {
+		return err
+	}
+	klog.V(2).InfoS("Bringing up the tunnel interface", "interface", iface)
+	if _, err := command(ctx, "wg-quick", "up", path); err != nil {
+		return err
+	}
+	defer func() {
+		// The context is done by then.
+		klog.V(2).InfoS("Bringing down the tunnel interface", "interface", iface)
+		if _, err := command(context.Background(), "wg-quick", "down", path); err != nil {
+			klog.ErrorS(err, "Failed to bring down the tunnel interface", "interface", iface)
+		}
+	}()
+	routes, err := syncRoutes(ctx, iface, nil)
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		latest, err := os.ReadFile(*configPath)
+		if err != nil {
+			klog.ErrorS(err, "Failed to read the configuration of the tunnel agent", "path", *configPath)
+			continue
+		}
+		if bytes.Equal(latest, config) {
+			continue
+		}
+		klog.V(2).InfoS("Syncing the tunnel interface with the configuration", "interface", iface)
+		if err := os.WriteFile(path, latest, 0o600); err != nil {
+			return err
+		}
+		// The interface keeps its addresses and the hooks of wg-quick; only the key and the peers are synced.
+		stripped, err := command(ctx, "wg-quick", "strip", path)
+		if err != nil {
+			klog.ErrorS(err, "Failed to strip the configuration of the tunnel agent", "interface", iface)
+			continue
+		}
+		syncconf := exec.CommandContext(ctx, "wg", "syncconf", iface, "/dev/stdin")
+		syncconf.Stdin = bytes.NewReader(stripped)
+		if out, err := syncconf.CombinedOutput(); err != nil {
+			klog.ErrorS(err, "Failed to sync the tunnel interface", "interface", iface, "output", string(out))
+			continue
+		}
+		if routes, err = syncRoutes(ctx, iface, routes); err != nil {
+			klog.ErrorS(err, "Failed to sync the routes of the tunnel interface", "interface", iface)
+			continue
+		}
+		config = latest
+	}
+}
+
+// checkForwarding returns an error if forwarding is disabled for the family of the tunnel address, as the connections
+// from the pods of the member cluster are forwarded to the tunnel through the gateway.
+func checkForwarding(config []byte) error {
+	sysctl := "/proc/sys/net/ipv4/ip_forward"
+	scanner := bufio.NewScanner(bytes.NewReader(config))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok || strings.TrimSpace(key) != "Address" {
+			continue
+		}
+		if ip, _, err := net.ParseCIDR(strings.TrimSpace(value)); err == nil && ip.To4() == nil {
+			sysctl = "/proc/sys/net/ipv6/conf/all/forwarding"
+		}
+		break
+	}
+	data, err := os.ReadFile(sysctl)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(string(data)) != "1" {
+		return fmt.Errorf("forwarding is disabled in the network namespace of the gateway (%s), "+
+			"the sysctl must be set through the security context of the pod", sysctl)
+	}
+	return nil
+}
+
+// syncRoutes routes the allowed IPs of the peers to the interface, and removes the routes of the peers removed since
+// the last sync; it returns the routed prefixes.
+func syncRoutes(ctx context.Context, iface string, routed map[string]bool) (map[string]bool, error) {
+	out, err := command(ctx, "wg", "show", iface, "allowed-ips")
+	if err != nil {
+		return routed, err
+	}
+	// Each line is the public key of a peer followed by its allowed IPs.
+	prefixes := map[string]bool{}
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		for _, prefix := range fields[min(1, len(fields)):] {
+			if _, _, err := net.ParseCIDR(prefix); err == nil {
+				prefixes[prefix] = true
+			}
+		}
+	}
+	for prefix := range prefixes {
+		if _, err := command(ctx, "ip", "route", "replace", prefix, "dev", iface); err != nil {
+			return routed, err
+		}
+	}
+	for prefix := range routed {
+		if prefixes[prefix] {
+			continue
+		}
+		if _, err := command(ctx, "ip", "route", "del", prefix, "dev", iface); err != nil {
+			klog.ErrorS(err, "Failed to remove the route of a removed peer", "prefix", prefix)
+		}
+	}
+	return prefixes, nil
+}
+
+// command runs a command and returns its output.
+func command(ctx context.Context, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
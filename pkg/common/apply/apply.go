@@ -0,0 +1,208 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package apply features the server-side apply of the objects fleet networking controllers propagate between the
+// member clusters and the hub cluster, e.g. InternalServiceExports and EndpointSliceExports.
+//
+// An update of an object carries the resource version the controller has read, and is rejected with a 409
+// Conflict whenever the object has changed in the meantime; under heavy endpoint churn the controllers spend much
+// of their time re-reading and retrying. A server-side apply carries no resource version: the API server merges the
+// fields the controller owns into the latest version of the object, so the write never conflicts with the writes
+// of the other fields, e.g. the status set by the hub cluster.
+//
+// The status of a ServiceImport is applied by field manager, one for each exporting cluster and one for the rest of
+// the status, so that the exports from different clusters are added to and removed from the same ServiceImport
+// without dropping each other. The fields written with updates before the switch are handed over to the field
+// managers on the first apply.
+package apply
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+)
+
+// FieldManager is the field manager of all the fields fleet networking controllers apply.
+const FieldManager = "fleet-networking"
+
+// CreateOrUpdateFunc has the signature of controllerutil.CreateOrUpdate, so that the controllers can switch between
+// updates and server-side applies.
+type CreateOrUpdateFunc func(ctx context.Context, c client.Client, obj client.Object, f controllerutil.MutateFn) (controllerutil.OperationResult, error)
+
+// Owned lists the metadata keys a controller owns on the objects it applies; the other labels and annotations of
+// the objects are left to whoever has set them.
+type Owned struct {
+	// Labels are the keys of the labels the controller owns.
+	Labels []string
+	// Annotations are the keys of the annotations the controller owns.
+	Annotations []string
+}
+
+// managerOf returns FieldManager for the fields of the spec and the owned labels and annotations, which are the
+// fields CreateOrApply applies.
+func (o Owned) managerOf(path fieldpath.Path) (string, bool) {
+	if len(path) == 0 || path[0].FieldName == nil {
+		return "", false
+	}
+	switch *path[0].FieldName {
+	case "spec":
+		return FieldManager, true
+	case "metadata":
+		if len(path) < 3 || path[1].FieldName == nil || path[2].FieldName == nil {
+			return "", false
+		}
+		switch key := *path[2].FieldName; *path[1].FieldName {
+		case "labels":
+			return FieldManager, slices.Contains(o.Labels, key)
+		case "annotations":
+			return FieldManager, slices.Contains(o.Annotations, key)
+		}
+	}
+	return "", false
+}
+
+// CreateOrApply returns a CreateOrUpdateFunc which applies the spec and the owned metadata of an object, rather
+// than updating the whole object.
+//
+// As with controllerutil.CreateOrUpdate, the object is read from the cluster and mutated by f, so that f can
+// still check the existing object and keep the fields set on creation; nothing is written if f leaves the object
+// unchanged. The object must have a spec, which the controller owns as a whole.
+//
+// The fields the binary has written with updates before are handed over to FieldManager the first time an
+// existing object is applied, see Upgrade.
+func CreateOrApply(owned Owned) CreateOrUpdateFunc {
+	return func(ctx context.Context, c client.Client, obj client.Object, f controllerutil.MutateFn) (controllerutil.OperationResult, error) {
+		key := client.ObjectKeyFromObject(obj)
+		created := false
+		if err := c.Get(ctx, key, obj); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return controllerutil.OperationResultNone, err
+			}
+			created = true
+		}
+		if !created {
+			if err := Upgrade(ctx, c, obj, "", owned.managerOf); err != nil {
+				return controllerutil.OperationResultNone, err
+			}
+		}
+		existing := obj.DeepCopyObject()
+		if err := f(); err != nil {
+			return controllerutil.OperationResultNone, err
+		}
+		if key != client.ObjectKeyFromObject(obj) {
+			return controllerutil.OperationResultNone, fmt.Errorf("MutateFn cannot mutate object name and/or object namespace")
+		}
+		if !created && equality.Semantic.DeepEqual(existing, obj) {
+			return controllerutil.OperationResultNone, nil
+		}
+
+		// The keys f has dropped are not part of the applied configuration; they are removed by the API server
+		// if they have been applied before, or patched away below otherwise.
+		droppedLabels := droppedKeys(existing.(client.Object).GetLabels(), obj.GetLabels(), owned.Labels)
+		droppedAnnotations := droppedKeys(existing.(client.Object).GetAnnotations(), obj.GetAnnotations(), owned.Annotations)
+		if err := Apply(ctx, c, obj, owned); err != nil {
+			return controllerutil.OperationResultNone, err
+		}
+		if err := removeKeys(ctx, c, obj, droppedLabels, droppedAnnotations); err != nil {
+			return controllerutil.OperationResultNone, err
+		}
+		if created {
+			return controllerutil.OperationResultCreated, nil
+		}
+		return controllerutil.OperationResultUpdated, nil
+	}
+}
+
+// Apply applies the spec and the owned metadata of an object with the fleet networking field manager, forcing the
+// ownership of the fields other managers have set; the object is updated with the one returned by the API server.
+func Apply(ctx context.Context, c client.Client, obj client.Object, owned Owned) error {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return err
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return err
+	}
+
+	applied := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	applied.SetGroupVersionKind(gvk)
+	applied.SetNamespace(obj.GetNamespace())
+	applied.SetName(obj.GetName())
+	if labels := ownedSubset(obj.GetLabels(), owned.Labels); len(labels) > 0 {
+		applied.SetLabels(labels)
+	}
+	if annotations := ownedSubset(obj.GetAnnotations(), owned.Annotations); len(annotations) > 0 {
+		applied.SetAnnotations(annotations)
+	}
+	if spec, ok := content["spec"]; ok {
+		applied.Object["spec"] = spec
+	}
+
+	if err := c.Patch(ctx, applied, client.Apply, client.FieldOwner(FieldManager), client.ForceOwnership); err != nil {
+		return err
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(applied.Object, obj)
+}
+
+// ownedSubset returns the entries of m whose keys are owned.
+func ownedSubset(m map[string]string, owned []string) map[string]string {
+	subset := map[string]string{}
+	for _, key := range owned {
+		if value, ok := m[key]; ok {
+			subset[key] = value
+		}
+	}
+	return subset
+}
+
+// droppedKeys returns the owned keys which are in before but not in after.
+func droppedKeys(before, after map[string]string, owned []string) []string {
+	var dropped []string
+	for _, key := range owned {
+		_, inBefore := before[key]
+		_, inAfter := after[key]
+		if inBefore && !inAfter {
+			dropped = append(dropped, key)
+		}
+	}
+	return dropped
+}
+
+// removeKeys removes the dropped labels and annotations the API server has kept, as they were set by another
+// field manager, e.g. by an update before the controller switched to server-side apply; the merge patch carries no
+// resource version, so that it does not conflict with other writes either.
+func removeKeys(ctx context.Context, c client.Client, obj client.Object, labels, annotations []string) error {
+	base := obj.DeepCopyObject().(client.Object)
+	objLabels, objAnnotations := obj.GetLabels(), obj.GetAnnotations()
+	kept := false
+	for _, key := range labels {
+		if _, ok := objLabels[key]; ok {
+			delete(objLabels, key)
+			kept = true
+		}
+	}
+	for _, key := range annotations {
+		if _, ok := objAnnotations[key]; ok {
+			delete(objAnnotations, key)
+			kept = true
+		}
+	}
+	if !kept {
+		return nil
+	}
+	obj.SetLabels(objLabels)
+	obj.SetAnnotations(objAnnotations)
+	return c.Patch(ctx, obj, client.MergeFrom(base))
+}
@@ -0,0 +1,255 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package apply
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+const (
+	testNamespace = "fleet-member-member-1"
+	testName      = "app-endpointslice"
+
+	ownedLabel      = "networking.fleet.azure.com/owner-service-name"
+	ownedAnnotation = "networking.fleet.azure.com/correlation-id"
+	foreignLabel    = "team"
+)
+
+var owned = Owned{Labels: []string{ownedLabel}, Annotations: []string{ownedAnnotation}}
+
+// applyRecorder emulates server-side applies on top of a fake client, which does not support them, and records
+// the patches sent.
+type applyRecorder struct {
+	applied      []map[string]interface{}
+	mergePatches int
+}
+
+func (r *applyRecorder) patch(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if patch.Type() != types.ApplyPatchType {
+		r.mergePatches++
+		return c.Patch(ctx, obj, patch, opts...)
+	}
+	applied := obj.(*unstructured.Unstructured)
+	r.applied = append(r.applied, applied.DeepCopy().Object)
+
+	desired := &fleetnetv1alpha1.EndpointSliceExport{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(applied.Object, desired); err != nil {
+		return err
+	}
+	current := &fleetnetv1alpha1.EndpointSliceExport{}
+	err := c.Get(ctx, client.ObjectKeyFromObject(obj), current)
+	switch {
+	case apierrors.IsNotFound(err):
+		if err := c.Create(ctx, desired); err != nil {
+			return err
+		}
+		current = desired
+	case err != nil:
+		return err
+	default:
+		for k, v := range desired.Labels {
+			if current.Labels == nil {
+				current.Labels = map[string]string{}
+			}
+			current.Labels[k] = v
+		}
+		for k, v := range desired.Annotations {
+			if current.Annotations == nil {
+				current.Annotations = map[string]string{}
+			}
+			current.Annotations[k] = v
+		}
+		current.Spec = desired.Spec
+		if err := c.Update(ctx, current); err != nil {
+			return err
+		}
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(current)
+	if err != nil {
+		return err
+	}
+	applied.Object = content
+	return nil
+}
+
+func endpointSliceExport(labels, annotations map[string]string, addressType discoveryv1.AddressType) *fleetnetv1alpha1.EndpointSliceExport {
+	return &fleetnetv1alpha1.EndpointSliceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   testNamespace,
+			Name:        testName,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Spec: fleetnetv1alpha1.EndpointSliceExportSpec{
+			AddressType: addressType,
+		},
+	}
+}
+
+// TestCreateOrApply tests the CreateOrApply function.
+func TestCreateOrApply(t *testing.T) {
+	testCases := []struct {
+		name                string
+		existing            *fleetnetv1alpha1.EndpointSliceExport
+		mutate              func(ese *fleetnetv1alpha1.EndpointSliceExport)
+		wantOp              controllerutil.OperationResult
+		wantAppliedMetadata map[string]interface{}
+		wantMergePatches    int
+		wantLabels          map[string]string
+		wantAnnotations     map[string]string
+	}{
+		{
+			name: "create",
+			mutate: func(ese *fleetnetv1alpha1.EndpointSliceExport) {
+				ese.Labels = map[string]string{ownedLabel: "app"}
+				ese.Spec.AddressType = discoveryv1.AddressTypeIPv4
+			},
+			wantOp: controllerutil.OperationResultCreated,
+			wantAppliedMetadata: map[string]interface{}{
+				"namespace": testNamespace,
+				"name":      testName,
+				"labels":    map[string]interface{}{ownedLabel: "app"},
+			},
+			wantLabels: map[string]string{ownedLabel: "app"},
+		},
+		{
+			name:     "unchanged",
+			existing: endpointSliceExport(map[string]string{ownedLabel: "app"}, nil, discoveryv1.AddressTypeIPv4),
+			mutate: func(ese *fleetnetv1alpha1.EndpointSliceExport) {
+				ese.Spec.AddressType = discoveryv1.AddressTypeIPv4
+			},
+			wantOp:     controllerutil.OperationResultNone,
+			wantLabels: map[string]string{ownedLabel: "app"},
+		},
+		{
+			name: "update keeps the metadata of other managers",
+			existing: endpointSliceExport(map[string]string{ownedLabel: "app", foreignLabel: "network"},
+				nil, discoveryv1.AddressTypeIPv4),
+			mutate: func(ese *fleetnetv1alpha1.EndpointSliceExport) {
+				ese.Annotations = map[string]string{ownedAnnotation: "trace"}
+				ese.Spec.AddressType = discoveryv1.AddressTypeIPv6
+			},
+			wantOp: controllerutil.OperationResultUpdated,
+			wantAppliedMetadata: map[string]interface{}{
+				"namespace":   testNamespace,
+				"name":        testName,
+				"labels":      map[string]interface{}{ownedLabel: "app"},
+				"annotations": map[string]interface{}{ownedAnnotation: "trace"},
+			},
+			wantLabels:      map[string]string{ownedLabel: "app", foreignLabel: "network"},
+			wantAnnotations: map[string]string{ownedAnnotation: "trace"},
+		},
+		{
+			name: "dropped owned annotation",
+			existing: endpointSliceExport(map[string]string{ownedLabel: "app"},
+				map[string]string{ownedAnnotation: "trace"}, discoveryv1.AddressTypeIPv4),
+			mutate: func(ese *fleetnetv1alpha1.EndpointSliceExport) {
+				delete(ese.Annotations, ownedAnnotation)
+			},
+			wantOp:           controllerutil.OperationResultUpdated,
+			wantMergePatches: 1,
+			wantLabels:       map[string]string{ownedLabel: "app"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("AddToScheme() = %v", err)
+			}
+			recorder := &applyRecorder{}
+			builder := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{Patch: recorder.patch})
+			if tc.existing != nil {
+				builder = builder.WithObjects(tc.existing)
+			}
+			fakeClient := builder.Build()
+
+			ese := &fleetnetv1alpha1.EndpointSliceExport{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+			}
+			op, err := CreateOrApply(owned)(ctx, fakeClient, ese, func() error {
+				tc.mutate(ese)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("CreateOrApply() = %v, want no error", err)
+			}
+			if op != tc.wantOp {
+				t.Errorf("CreateOrApply() op = %s, want %s", op, tc.wantOp)
+			}
+			if tc.wantAppliedMetadata != nil {
+				if len(recorder.applied) != 1 {
+					t.Fatalf("applied %d configurations, want 1", len(recorder.applied))
+				}
+				applied := recorder.applied[0]
+				if applied["kind"] != "EndpointSliceExport" || applied["apiVersion"] != fleetnetv1alpha1.GroupVersion.String() {
+					t.Errorf("applied type = %v %v, want EndpointSliceExport %s", applied["apiVersion"], applied["kind"], fleetnetv1alpha1.GroupVersion)
+				}
+				if diff := cmp.Diff(tc.wantAppliedMetadata, applied["metadata"]); diff != "" {
+					t.Errorf("applied metadata (-want, +got):\n%s", diff)
+				}
+				if _, ok := applied["status"]; ok {
+					t.Errorf("applied status, want none")
+				}
+			}
+			if tc.wantOp == controllerutil.OperationResultNone && len(recorder.applied) != 0 {
+				t.Errorf("applied %d configurations, want none", len(recorder.applied))
+			}
+			if recorder.mergePatches != tc.wantMergePatches {
+				t.Errorf("sent %d merge patches, want %d", recorder.mergePatches, tc.wantMergePatches)
+			}
+
+			got := &fleetnetv1alpha1.EndpointSliceExport{}
+			if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(ese), got); err != nil {
+				t.Fatalf("Get() = %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.wantLabels, got.Labels); diff != "" {
+				t.Errorf("labels (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantAnnotations, got.Annotations); diff != "" {
+				t.Errorf("annotations (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestCreateOrApply_MutateError tests that nothing is written if the mutation fails.
+func TestCreateOrApply_MutateError(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("AddToScheme() = %v", err)
+	}
+	recorder := &applyRecorder{}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{Patch: recorder.patch}).Build()
+	ese := &fleetnetv1alpha1.EndpointSliceExport{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+	}
+	wantErr := apierrors.NewAlreadyExists(fleetnetv1alpha1.GroupVersion.WithResource("endpointsliceexports").GroupResource(), testName)
+	if _, err := CreateOrApply(owned)(context.Background(), fakeClient, ese, func() error {
+		return wantErr
+	}); err != wantErr {
+		t.Errorf("CreateOrApply() = %v, want %v", err, wantErr)
+	}
+	if len(recorder.applied) != 0 {
+		t.Errorf("applied %d configurations, want none", len(recorder.applied))
+	}
+}
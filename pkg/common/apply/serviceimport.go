@@ -0,0 +1,112 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package apply
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+// ClusterFieldManager returns the field manager of the fields applied on behalf of a member cluster, i.e. the entry
+// of the cluster in the status of a ServiceImport.
+func ClusterFieldManager(clusterID string) string {
+	return FieldManager + "/" + clusterID
+}
+
+// ServiceImportStatus applies the changes of the status of a ServiceImport since old, the status read from the hub
+// cluster.
+//
+// The entry of each exporting cluster is applied with the field manager of the cluster, and the other fields with
+// FieldManager: the controllers adding and removing the clusters of the same ServiceImport concurrently never drop
+// the entries of each other, and an entry is removed as a whole as soon as it is no longer applied. The removed
+// entries go first and the added ones last, so that the ServiceImport never lists its clusters without the spec
+// resolved for them.
+//
+// The serviceImport is left with the desired status, rather than updated with the objects returned by the API
+// server, as each apply carries a part of the status only.
+func ServiceImportStatus(ctx context.Context, c client.Client, serviceImport *fleetnetv1alpha1.ServiceImport, old *fleetnetv1alpha1.ServiceImportStatus) error {
+	if err := Upgrade(ctx, c, serviceImport.DeepCopy(), "status", serviceImportStatusManagerOf); err != nil {
+		return err
+	}
+
+	desired := make(map[string]fleetnetv1alpha1.ServiceImportClusterStatus, len(serviceImport.Status.Clusters))
+	for _, cluster := range serviceImport.Status.Clusters {
+		desired[cluster.Cluster] = cluster
+	}
+	current := make(map[string]fleetnetv1alpha1.ServiceImportClusterStatus, len(old.Clusters))
+	for _, cluster := range old.Clusters {
+		current[cluster.Cluster] = cluster
+		if _, ok := desired[cluster.Cluster]; ok {
+			continue
+		}
+		if err := applyStatus(ctx, c, serviceImport, ClusterFieldManager(cluster.Cluster), &fleetnetv1alpha1.ServiceImportStatus{}); err != nil {
+			return err
+		}
+	}
+
+	oldRest, desiredRest := old.DeepCopy(), serviceImport.Status.DeepCopy()
+	oldRest.Clusters, desiredRest.Clusters = nil, nil
+	if !equality.Semantic.DeepEqual(oldRest, desiredRest) {
+		if err := applyStatus(ctx, c, serviceImport, FieldManager, desiredRest); err != nil {
+			return err
+		}
+	}
+
+	for _, cluster := range serviceImport.Status.Clusters {
+		if existing, ok := current[cluster.Cluster]; ok && equality.Semantic.DeepEqual(existing, cluster) {
+			continue
+		}
+		status := &fleetnetv1alpha1.ServiceImportStatus{Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{cluster}}
+		if err := applyStatus(ctx, c, serviceImport, ClusterFieldManager(cluster.Cluster), status); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// serviceImportStatusManagerOf returns the field manager of the cluster for the fields of its entry in the status
+// of a ServiceImport, and FieldManager for the other fields of the status.
+func serviceImportStatusManagerOf(path fieldpath.Path) (string, bool) {
+	if len(path) == 0 || path[0].FieldName == nil || *path[0].FieldName != "status" {
+		return "", false
+	}
+	if len(path) < 3 || path[1].FieldName == nil || *path[1].FieldName != "clusters" || path[2].Key == nil {
+		return FieldManager, true
+	}
+	for _, field := range *path[2].Key {
+		if field.Name == "cluster" && field.Value.IsString() {
+			return ClusterFieldManager(field.Value.AsString()), true
+		}
+	}
+	return "", false
+}
+
+// applyStatus applies the status of an object with the field manager, forcing the ownership of the fields other
+// managers have set.
+func applyStatus(ctx context.Context, c client.Client, obj client.Object, fieldManager string, status interface{}) error {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return err
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(status)
+	if err != nil {
+		return err
+	}
+
+	applied := &unstructured.Unstructured{Object: map[string]interface{}{"status": content}}
+	applied.SetGroupVersionKind(gvk)
+	applied.SetNamespace(obj.GetNamespace())
+	applied.SetName(obj.GetName())
+	return c.Status().Patch(ctx, applied, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership)
+}
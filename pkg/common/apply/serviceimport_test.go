@@ -0,0 +1,145 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package apply
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+// statusApply is a server-side apply of a status.
+type statusApply struct {
+	Manager string
+	Status  interface{}
+}
+
+// TestServiceImportStatus tests the ServiceImportStatus function.
+func TestServiceImportStatus(t *testing.T) {
+	ports := []fleetnetv1alpha1.ServicePort{{Name: "http", Protocol: "TCP", Port: 80}}
+	resolved := func(clusters ...fleetnetv1alpha1.ServiceImportClusterStatus) fleetnetv1alpha1.ServiceImportStatus {
+		return fleetnetv1alpha1.ServiceImportStatus{Type: fleetnetv1alpha1.ClusterSetIP, Ports: ports, Clusters: clusters}
+	}
+	member1 := fleetnetv1alpha1.ServiceImportClusterStatus{Cluster: "member-1", ReadyEndpoints: 2}
+	member2 := fleetnetv1alpha1.ServiceImportClusterStatus{Cluster: "member-2", ReadyEndpoints: 3}
+	member2Scaled := fleetnetv1alpha1.ServiceImportClusterStatus{Cluster: "member-2", ReadyEndpoints: 5}
+
+	resolvedStatus := map[string]interface{}{
+		"type":  "ClusterSetIP",
+		"ports": []interface{}{map[string]interface{}{"name": "http", "protocol": "TCP", "port": int64(80), "targetPort": int64(0)}},
+	}
+	clusterStatus := func(cluster string, readyEndpoints int64) map[string]interface{} {
+		return map[string]interface{}{
+			"clusters": []interface{}{map[string]interface{}{"cluster": cluster, "readyEndpoints": readyEndpoints}},
+		}
+	}
+
+	testCases := []struct {
+		name        string
+		oldStatus   fleetnetv1alpha1.ServiceImportStatus
+		status      fleetnetv1alpha1.ServiceImportStatus
+		wantApplies []statusApply
+	}{
+		{
+			name:   "resolve the spec",
+			status: resolved(member1, member2),
+			wantApplies: []statusApply{
+				{Manager: FieldManager, Status: resolvedStatus},
+				{Manager: ClusterFieldManager("member-1"), Status: clusterStatus("member-1", 2)},
+				{Manager: ClusterFieldManager("member-2"), Status: clusterStatus("member-2", 3)},
+			},
+		},
+		{
+			name:      "add a cluster",
+			oldStatus: resolved(member1),
+			status:    resolved(member1, member2),
+			wantApplies: []statusApply{
+				{Manager: ClusterFieldManager("member-2"), Status: clusterStatus("member-2", 3)},
+			},
+		},
+		{
+			name:      "refresh a cluster",
+			oldStatus: resolved(member1, member2),
+			status:    resolved(member1, member2Scaled),
+			wantApplies: []statusApply{
+				{Manager: ClusterFieldManager("member-2"), Status: clusterStatus("member-2", 5)},
+			},
+		},
+		{
+			name:      "remove a cluster",
+			oldStatus: resolved(member1, member2),
+			status:    resolved(member1),
+			wantApplies: []statusApply{
+				{Manager: ClusterFieldManager("member-2"), Status: map[string]interface{}{}},
+			},
+		},
+		{
+			name:      "remove the last cluster",
+			oldStatus: resolved(member1),
+			wantApplies: []statusApply{
+				{Manager: ClusterFieldManager("member-1"), Status: map[string]interface{}{}},
+				{Manager: FieldManager, Status: map[string]interface{}{}},
+			},
+		},
+		{
+			name:      "no change",
+			oldStatus: resolved(member1),
+			status:    resolved(member1),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("AddToScheme() = %v", err)
+			}
+			var applies []statusApply
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+				SubResourcePatch: func(_ context.Context, _ client.Client, subResourceName string, obj client.Object, patch client.Patch,
+					opts ...client.SubResourcePatchOption) error {
+					if subResourceName != "status" || patch.Type() != types.ApplyPatchType {
+						t.Fatalf("Patch(%s, %s), want an apply of the status", subResourceName, patch.Type())
+					}
+					applied := obj.(*unstructured.Unstructured)
+					if applied.GetKind() != "ServiceImport" || applied.GetNamespace() != testNamespace || applied.GetName() != testName {
+						t.Fatalf("applied %s %s/%s, want ServiceImport %s/%s", applied.GetKind(), applied.GetNamespace(), applied.GetName(), testNamespace, testName)
+					}
+					patchOpts := &client.SubResourcePatchOptions{}
+					patchOpts.ApplyOptions(opts)
+					if patchOpts.Force == nil || !*patchOpts.Force {
+						t.Errorf("applied the status of %s without forcing the ownership", patchOpts.FieldManager)
+					}
+					applies = append(applies, statusApply{Manager: patchOpts.FieldManager, Status: applied.Object["status"]})
+					return nil
+				},
+			}).Build()
+
+			serviceImport := &fleetnetv1alpha1.ServiceImport{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Status:     tc.status,
+			}
+			if err := ServiceImportStatus(context.Background(), fakeClient, serviceImport, &tc.oldStatus); err != nil {
+				t.Fatalf("ServiceImportStatus() = %v", err)
+			}
+			if diff := cmp.Diff(tc.wantApplies, applies); diff != "" {
+				t.Errorf("ServiceImportStatus() applies mismatch (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.status, serviceImport.Status); diff != "" {
+				t.Errorf("ServiceImportStatus() status mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
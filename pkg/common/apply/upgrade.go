@@ -0,0 +1,160 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package apply
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+)
+
+// LegacyFieldManager is the field manager of the updates the running binary has sent before it switched to
+// server-side applies: the API server records an update carrying no field manager under the user agent of the
+// client, which defaults to the name of the binary.
+var LegacyFieldManager = strings.Split(rest.DefaultKubernetesUserAgent(), "/")[0]
+
+// ManagerOf returns the field manager which applies the field at the path from now on, or false if the field is
+// still written with updates.
+type ManagerOf func(path fieldpath.Path) (manager string, ok bool)
+
+// Upgrade hands the fields of an object, or of its subresource, that the binary has written with updates over to
+// the field managers applying them from now on. Until then the API server keeps these fields owned by the updates,
+// so that an apply dropping one of them, e.g. an optional field of the spec or the entry of a cluster in a status
+// list, would leave it in place.
+//
+// Nothing is written once the object has been upgraded; the upgrade is rejected with a 409 Conflict if the object
+// has changed since it was read, and the object is updated with the one returned by the API server.
+func Upgrade(ctx context.Context, c client.Client, obj client.Object, subresource string, managerOf ManagerOf) error {
+	managedFields, upgraded, err := upgradedManagedFields(obj.GetManagedFields(), subresource, managerOf, metav1.Now())
+	if err != nil || !upgraded {
+		return err
+	}
+	patch, err := json.Marshal([]map[string]interface{}{
+		{"op": "replace", "path": "/metadata/managedFields", "value": managedFields},
+		// Replacing the resource version with the one read, rather than testing it, makes the API server reject
+		// the patch with a 409 Conflict if the managed fields have changed in the meantime.
+		{"op": "replace", "path": "/metadata/resourceVersion", "value": obj.GetResourceVersion()},
+	})
+	if err != nil {
+		return err
+	}
+	return c.Patch(ctx, obj, client.RawPatch(types.JSONPatchType, patch))
+}
+
+// upgradedManagedFields moves the fields of the updates of the binary to the apply entries of the managers
+// returned by managerOf; it returns false if no field is moved.
+//
+// The fields are only moved to an apply entry of the same API version, as the API server tracks a single apply
+// entry per field manager; the ones which cannot be moved are left to the updates.
+func upgradedManagedFields(entries []metav1.ManagedFieldsEntry, subresource string, managerOf ManagerOf,
+	now metav1.Time) ([]metav1.ManagedFieldsEntry, bool, error) {
+	upgraded := make([]metav1.ManagedFieldsEntry, len(entries))
+	copy(upgraded, entries)
+	moved := false
+	for i := 0; i < len(upgraded); i++ {
+		entry := upgraded[i]
+		if entry.Manager != LegacyFieldManager || entry.Operation != metav1.ManagedFieldsOperationUpdate ||
+			entry.Subresource != subresource || entry.FieldsV1 == nil {
+			continue
+		}
+		fields, err := decodeFields(entry)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to decode the fields of manager %s: %w", entry.Manager, err)
+		}
+
+		handed := map[string]*fieldpath.Set{}
+		kept := &fieldpath.Set{}
+		fields.Iterate(func(path fieldpath.Path) {
+			manager, ok := managerOf(path)
+			if ok {
+				target := applyEntryIndex(upgraded, manager, subresource)
+				ok = target < 0 || upgraded[target].APIVersion == entry.APIVersion
+			}
+			if !ok {
+				kept.Insert(path)
+				return
+			}
+			if handed[manager] == nil {
+				handed[manager] = &fieldpath.Set{}
+			}
+			handed[manager].Insert(path)
+		})
+		if len(handed) == 0 {
+			continue
+		}
+		moved = true
+
+		for manager, set := range handed {
+			target := applyEntryIndex(upgraded, manager, subresource)
+			if target < 0 {
+				upgraded = append(upgraded, metav1.ManagedFieldsEntry{
+					Manager:     manager,
+					Operation:   metav1.ManagedFieldsOperationApply,
+					APIVersion:  entry.APIVersion,
+					Time:        &now,
+					FieldsType:  "FieldsV1",
+					Subresource: subresource,
+				})
+				target = len(upgraded) - 1
+			} else if current, err := decodeFields(upgraded[target]); err != nil {
+				return nil, false, fmt.Errorf("failed to decode the fields of manager %s: %w", manager, err)
+			} else {
+				set = set.Union(current)
+			}
+			if err := encodeFields(&upgraded[target], set); err != nil {
+				return nil, false, err
+			}
+		}
+
+		if kept.Empty() {
+			upgraded = append(upgraded[:i], upgraded[i+1:]...)
+			i--
+			continue
+		}
+		if err := encodeFields(&upgraded[i], kept); err != nil {
+			return nil, false, err
+		}
+	}
+	return upgraded, moved, nil
+}
+
+// applyEntryIndex returns the index of the apply entry of the manager, or -1 if the manager has applied nothing.
+func applyEntryIndex(entries []metav1.ManagedFieldsEntry, manager, subresource string) int {
+	for i, entry := range entries {
+		if entry.Manager == manager && entry.Operation == metav1.ManagedFieldsOperationApply && entry.Subresource == subresource {
+			return i
+		}
+	}
+	return -1
+}
+
+func decodeFields(entry metav1.ManagedFieldsEntry) (*fieldpath.Set, error) {
+	set := &fieldpath.Set{}
+	if entry.FieldsV1 == nil {
+		return set, nil
+	}
+	if err := set.FromJSON(bytes.NewReader(entry.FieldsV1.Raw)); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+func encodeFields(entry *metav1.ManagedFieldsEntry, set *fieldpath.Set) error {
+	raw, err := set.ToJSON()
+	if err != nil {
+		return fmt.Errorf("failed to encode the fields of manager %s: %w", entry.Manager, err)
+	}
+	entry.FieldsV1 = &metav1.FieldsV1{Raw: raw}
+	return nil
+}
@@ -0,0 +1,253 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package apply
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+var upgradeTime = metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+// fieldsV1 returns the fields in the serialized format of the API server.
+func fieldsV1(t *testing.T, fields string) *metav1.FieldsV1 {
+	t.Helper()
+	set := &fieldpath.Set{}
+	if err := set.FromJSON(bytes.NewReader([]byte(fields))); err != nil {
+		t.Fatalf("FromJSON(%s) = %v", fields, err)
+	}
+	raw, err := set.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON() = %v", err)
+	}
+	return &metav1.FieldsV1{Raw: raw}
+}
+
+func updateEntry(t *testing.T, manager, subresource, fields string) metav1.ManagedFieldsEntry {
+	return metav1.ManagedFieldsEntry{
+		Manager:     manager,
+		Operation:   metav1.ManagedFieldsOperationUpdate,
+		APIVersion:  fleetnetv1alpha1.GroupVersion.String(),
+		FieldsType:  "FieldsV1",
+		FieldsV1:    fieldsV1(t, fields),
+		Subresource: subresource,
+	}
+}
+
+func applyEntry(t *testing.T, manager, subresource, fields string) metav1.ManagedFieldsEntry {
+	return metav1.ManagedFieldsEntry{
+		Manager:     manager,
+		Operation:   metav1.ManagedFieldsOperationApply,
+		APIVersion:  fleetnetv1alpha1.GroupVersion.String(),
+		Time:        &upgradeTime,
+		FieldsType:  "FieldsV1",
+		FieldsV1:    fieldsV1(t, fields),
+		Subresource: subresource,
+	}
+}
+
+// TestUpgradedManagedFields tests the upgradedManagedFields function.
+func TestUpgradedManagedFields(t *testing.T) {
+	const (
+		specAndMetadata = `{"f:metadata":{"f:labels":{"f:team":{},"f:` + ownedLabel + `":{}},"f:annotations":{"f:` + ownedAnnotation + `":{}}},` +
+			`"f:spec":{"f:addressType":{},"f:endpoints":{}}}`
+		clusterA = `k:{\"cluster\":\"member-1\"}`
+		clusterB = `k:{\"cluster\":\"member-2\"}`
+		status   = `{"f:status":{"f:ports":{},"f:type":{},"f:clusters":{` +
+			`"` + clusterA + `":{".":{},"f:cluster":{},"f:readyEndpoints":{}},` +
+			`"` + clusterB + `":{".":{},"f:cluster":{},"f:readyEndpoints":{}}}}}`
+	)
+
+	testCases := []struct {
+		name         string
+		entries      []metav1.ManagedFieldsEntry
+		subresource  string
+		managerOf    ManagerOf
+		want         []metav1.ManagedFieldsEntry
+		wantUpgraded bool
+	}{
+		{
+			name: "no updates of the binary",
+			entries: []metav1.ManagedFieldsEntry{
+				updateEntry(t, "kubectl", "", specAndMetadata),
+			},
+			managerOf: owned.managerOf,
+			want: []metav1.ManagedFieldsEntry{
+				updateEntry(t, "kubectl", "", specAndMetadata),
+			},
+		},
+		{
+			name: "spec and owned metadata handed over, other metadata left to the updates",
+			entries: []metav1.ManagedFieldsEntry{
+				updateEntry(t, LegacyFieldManager, "", specAndMetadata),
+				updateEntry(t, LegacyFieldManager, "status", `{"f:status":{"f:conditions":{}}}`),
+			},
+			managerOf: owned.managerOf,
+			want: []metav1.ManagedFieldsEntry{
+				updateEntry(t, LegacyFieldManager, "", `{"f:metadata":{"f:labels":{"f:team":{}}}}`),
+				updateEntry(t, LegacyFieldManager, "status", `{"f:status":{"f:conditions":{}}}`),
+				applyEntry(t, FieldManager, "", `{"f:metadata":{"f:labels":{"f:`+ownedLabel+`":{}},"f:annotations":{"f:`+ownedAnnotation+`":{}}},`+
+					`"f:spec":{"f:addressType":{},"f:endpoints":{}}}`),
+			},
+			wantUpgraded: true,
+		},
+		{
+			name: "all fields merged into the existing apply entry",
+			entries: []metav1.ManagedFieldsEntry{
+				applyEntry(t, FieldManager, "", `{"f:spec":{"f:addressType":{}}}`),
+				updateEntry(t, LegacyFieldManager, "", `{"f:spec":{"f:endpoints":{}}}`),
+			},
+			managerOf: owned.managerOf,
+			want: []metav1.ManagedFieldsEntry{
+				applyEntry(t, FieldManager, "", `{"f:spec":{"f:addressType":{},"f:endpoints":{}}}`),
+			},
+			wantUpgraded: true,
+		},
+		{
+			name: "apply entry of another API version",
+			entries: func() []metav1.ManagedFieldsEntry {
+				entry := applyEntry(t, FieldManager, "", `{"f:spec":{"f:addressType":{}}}`)
+				entry.APIVersion = "networking.fleet.azure.com/v1beta1"
+				return []metav1.ManagedFieldsEntry{entry, updateEntry(t, LegacyFieldManager, "", `{"f:spec":{"f:endpoints":{}}}`)}
+			}(),
+			managerOf: owned.managerOf,
+			want: func() []metav1.ManagedFieldsEntry {
+				entry := applyEntry(t, FieldManager, "", `{"f:spec":{"f:addressType":{}}}`)
+				entry.APIVersion = "networking.fleet.azure.com/v1beta1"
+				return []metav1.ManagedFieldsEntry{entry, updateEntry(t, LegacyFieldManager, "", `{"f:spec":{"f:endpoints":{}}}`)}
+			}(),
+		},
+		{
+			name: "serviceImport status split by cluster",
+			entries: []metav1.ManagedFieldsEntry{
+				updateEntry(t, LegacyFieldManager, "", `{"f:metadata":{"f:finalizers":{}}}`),
+				updateEntry(t, LegacyFieldManager, "status", status),
+			},
+			subresource: "status",
+			managerOf:   serviceImportStatusManagerOf,
+			want: []metav1.ManagedFieldsEntry{
+				updateEntry(t, LegacyFieldManager, "", `{"f:metadata":{"f:finalizers":{}}}`),
+				applyEntry(t, FieldManager, "status", `{"f:status":{"f:ports":{},"f:type":{}}}`),
+				applyEntry(t, ClusterFieldManager("member-1"), "status",
+					`{"f:status":{"f:clusters":{"`+clusterA+`":{".":{},"f:cluster":{},"f:readyEndpoints":{}}}}}`),
+				applyEntry(t, ClusterFieldManager("member-2"), "status",
+					`{"f:status":{"f:clusters":{"`+clusterB+`":{".":{},"f:cluster":{},"f:readyEndpoints":{}}}}}`),
+			},
+			wantUpgraded: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, upgraded, err := upgradedManagedFields(tc.entries, tc.subresource, tc.managerOf, upgradeTime)
+			if err != nil {
+				t.Fatalf("upgradedManagedFields() = %v", err)
+			}
+			if upgraded != tc.wantUpgraded {
+				t.Errorf("upgradedManagedFields() upgraded = %t, want %t", upgraded, tc.wantUpgraded)
+			}
+			// The apply entries are appended in no particular order.
+			sortEntries := cmp.Transformer("sort", func(entries []metav1.ManagedFieldsEntry) map[string]metav1.ManagedFieldsEntry {
+				m := make(map[string]metav1.ManagedFieldsEntry, len(entries))
+				for _, entry := range entries {
+					m[entry.Manager+"/"+string(entry.Operation)+"/"+entry.Subresource] = entry
+				}
+				return m
+			})
+			if diff := cmp.Diff(tc.want, got, sortEntries); diff != "" {
+				t.Errorf("upgradedManagedFields() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestUpgrade tests that the upgrade is patched with the resource version read, and only if needed.
+func TestUpgrade(t *testing.T) {
+	testCases := []struct {
+		name          string
+		managedFields []metav1.ManagedFieldsEntry
+		wantPatch     bool
+	}{
+		{
+			name: "upgraded already",
+			managedFields: []metav1.ManagedFieldsEntry{
+				applyEntry(t, FieldManager, "", `{"f:spec":{"f:addressType":{}}}`),
+			},
+		},
+		{
+			name: "written with updates",
+			managedFields: []metav1.ManagedFieldsEntry{
+				updateEntry(t, LegacyFieldManager, "", `{"f:spec":{"f:addressType":{}}}`),
+			},
+			wantPatch: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("AddToScheme() = %v", err)
+			}
+			var patches []client.Patch
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+				Patch: func(_ context.Context, _ client.WithWatch, _ client.Object, patch client.Patch, _ ...client.PatchOption) error {
+					patches = append(patches, patch)
+					return nil
+				},
+			}).Build()
+			ese := endpointSliceExport(nil, nil, "")
+			ese.ResourceVersion = "7"
+			ese.ManagedFields = tc.managedFields
+
+			if err := Upgrade(context.Background(), fakeClient, ese, "", owned.managerOf); err != nil {
+				t.Fatalf("Upgrade() = %v", err)
+			}
+			if !tc.wantPatch {
+				if len(patches) != 0 {
+					t.Errorf("Upgrade() sent %d patches, want none", len(patches))
+				}
+				return
+			}
+			if len(patches) != 1 {
+				t.Fatalf("Upgrade() sent %d patches, want 1", len(patches))
+			}
+			if patches[0].Type() != types.JSONPatchType {
+				t.Errorf("Upgrade() patch type = %s, want %s", patches[0].Type(), types.JSONPatchType)
+			}
+			data, err := patches[0].Data(ese)
+			if err != nil {
+				t.Fatalf("Data() = %v", err)
+			}
+			var ops []map[string]interface{}
+			if err := json.Unmarshal(data, &ops); err != nil {
+				t.Fatalf("Unmarshal() = %v", err)
+			}
+			paths := map[string]interface{}{}
+			for _, op := range ops {
+				paths[op["path"].(string)] = op["value"]
+			}
+			if got := paths["/metadata/resourceVersion"]; got != "7" {
+				t.Errorf("Upgrade() patched resource version %v, want 7", got)
+			}
+			if _, ok := paths["/metadata/managedFields"]; !ok {
+				t.Errorf("Upgrade() patched no managed fields")
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package azurefrontdoor features a client of the origin groups and origins, and of the endpoints, routes and custom
+// domains, of the Azure Front Door (Standard and Premium) profiles, which are the Microsoft.Cdn/profiles resources of
+// the Azure Resource Manager.
+package azurefrontdoor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+const (
+	moduleName    = "go.goms.io/fleet-networking/pkg/common/azurefrontdoor"
+	moduleVersion = "v0.1.0"
+
+	apiVersion = "2024-02-01"
+)
+
+// AFDOriginGroup is an origin group of an Azure Front Door profile, which load balances the traffic among its
+// origins.
+type AFDOriginGroup struct {
+	ID         *string                   `json:"id,omitempty"`
+	Name       *string                   `json:"name,omitempty"`
+	Properties *AFDOriginGroupProperties `json:"properties,omitempty"`
+}
+
+// AFDOriginGroupProperties are the properties of an origin group.
+type AFDOriginGroupProperties struct {
+	HealthProbeSettings   *HealthProbeParameters           `json:"healthProbeSettings,omitempty"`
+	LoadBalancingSettings *LoadBalancingSettingsParameters `json:"loadBalancingSettings,omitempty"`
+	ProvisioningState     *string                          `json:"provisioningState,omitempty"`
+}
+
+// HealthProbeParameters are the settings of the health probes sent to the origins of an origin group.
+type HealthProbeParameters struct {
+	ProbeIntervalInSeconds *int32  `json:"probeIntervalInSeconds,omitempty"`
+	ProbePath              *string `json:"probePath,omitempty"`
+	ProbeProtocol          *string `json:"probeProtocol,omitempty"`
+	ProbeRequestType       *string `json:"probeRequestType,omitempty"`
+}
+
+// LoadBalancingSettingsParameters are the settings of the load balancing among the origins of an origin group.
+type LoadBalancingSettingsParameters struct {
+	AdditionalLatencyInMilliseconds *int32 `json:"additionalLatencyInMilliseconds,omitempty"`
+	SampleSize                      *int32 `json:"sampleSize,omitempty"`
+	SuccessfulSamplesRequired       *int32 `json:"successfulSamplesRequired,omitempty"`
+}
+
+// AFDOrigin is an origin of an origin group, i.e. a backend the traffic is routed to.
+type AFDOrigin struct {
+	ID         *string              `json:"id,omitempty"`
+	Name       *string              `json:"name,omitempty"`
+	Properties *AFDOriginProperties `json:"properties,omitempty"`
+}
+
+// AFDOriginProperties are the properties of an origin.
+type AFDOriginProperties struct {
+	EnabledState                *string `json:"enabledState,omitempty"`
+	EnforceCertificateNameCheck *bool   `json:"enforceCertificateNameCheck,omitempty"`
+	HTTPPort                    *int32  `json:"httpPort,omitempty"`
+	HTTPSPort                   *int32  `json:"httpsPort,omitempty"`
+	HostName                    *string `json:"hostName,omitempty"`
+	OriginHostHeader            *string `json:"originHostHeader,omitempty"`
+	Priority                    *int32  `json:"priority,omitempty"`
+	Weight                      *int32  `json:"weight,omitempty"`
+	ProvisioningState           *string `json:"provisioningState,omitempty"`
+}
+
+// listResult is a page of the resources returned by a list operation.
+type listResult[T any] struct {
+	NextLink *string `json:"nextLink,omitempty"`
+	Value    []*T    `json:"value,omitempty"`
+}
+
+// Interface is the interface of the client of the origin groups and origins of the Azure Front Door profiles.
+type Interface interface {
+	// GetOriginGroup gets an origin group.
+	GetOriginGroup(ctx context.Context, resourceGroupName, profileName, originGroupName string) (AFDOriginGroup, error)
+	// CreateOrUpdateOriginGroup creates or updates an origin group and waits for the operation to complete.
+	CreateOrUpdateOriginGroup(ctx context.Context, resourceGroupName, profileName, originGroupName string, originGroup AFDOriginGroup) (AFDOriginGroup, error)
+	// DeleteOriginGroup deletes an origin group along with its origins and waits for the operation to complete.
+	DeleteOriginGroup(ctx context.Context, resourceGroupName, profileName, originGroupName string) error
+	// ListOrigins lists the origins of an origin group.
+	ListOrigins(ctx context.Context, resourceGroupName, profileName, originGroupName string) ([]*AFDOrigin, error)
+	// CreateOrUpdateOrigin creates or updates an origin and waits for the operation to complete.
+	CreateOrUpdateOrigin(ctx context.Context, resourceGroupName, profileName, originGroupName, originName string, origin AFDOrigin) (AFDOrigin, error)
+	// DeleteOrigin deletes an origin and waits for the operation to complete.
+	DeleteOrigin(ctx context.Context, resourceGroupName, profileName, originGroupName, originName string) error
+}
+
+// Client is the client of the origin groups and origins of the Azure Front Door profiles, which calls the Azure
+// Resource Manager REST API directly.
+type Client struct {
+	subscriptionID string
+	internal       *arm.Client
+}
+
+var _ Interface = &Client{}
+
+// NewClient creates a client of the origin groups and origins of the Azure Front Door profiles in the subscription.
+func NewClient(subscriptionID string, credential azcore.TokenCredential, options *arm.ClientOptions) (*Client, error) {
+	if subscriptionID == "" {
+		return nil, errors.New("subscriptionID cannot be empty")
+	}
+	cl, err := arm.NewClient(moduleName, moduleVersion, credential, options)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{subscriptionID: subscriptionID, internal: cl}, nil
+}
+
+// GetOriginGroup implements Interface.
+func (c *Client) GetOriginGroup(ctx context.Context, resourceGroupName, profileName, originGroupName string) (AFDOriginGroup, error) {
+	resp, err := c.do(ctx, http.MethodGet, c.originGroupPath(resourceGroupName, profileName, originGroupName), nil, http.StatusOK)
+	if err != nil {
+		return AFDOriginGroup{}, err
+	}
+	originGroup := AFDOriginGroup{}
+	if err := runtime.UnmarshalAsJSON(resp, &originGroup); err != nil {
+		return AFDOriginGroup{}, err
+	}
+	return originGroup, nil
+}
+
+// CreateOrUpdateOriginGroup implements Interface.
+func (c *Client) CreateOrUpdateOriginGroup(ctx context.Context, resourceGroupName, profileName, originGroupName string, originGroup AFDOriginGroup) (AFDOriginGroup, error) {
+	resp, err := c.do(ctx, http.MethodPut, c.originGroupPath(resourceGroupName, profileName, originGroupName), originGroup,
+		http.StatusOK, http.StatusCreated, http.StatusAccepted)
+	if err != nil {
+		return AFDOriginGroup{}, err
+	}
+	return pollUntilDone[AFDOriginGroup](ctx, resp, c.internal.Pipeline())
+}
+
+// DeleteOriginGroup implements Interface.
+func (c *Client) DeleteOriginGroup(ctx context.Context, resourceGroupName, profileName, originGroupName string) error {
+	resp, err := c.do(ctx, http.MethodDelete, c.originGroupPath(resourceGroupName, profileName, originGroupName), nil,
+		http.StatusOK, http.StatusAccepted, http.StatusNoContent)
+	if err != nil {
+		return err
+	}
+	_, err = pollUntilDone[struct{}](ctx, resp, c.internal.Pipeline())
+	return err
+}
+
+// ListOrigins implements Interface.
+func (c *Client) ListOrigins(ctx context.Context, resourceGroupName, profileName, originGroupName string) ([]*AFDOrigin, error) {
+	return listAll[AFDOrigin](ctx, c, runtime.JoinPaths(c.originGroupPath(resourceGroupName, profileName, originGroupName), "origins"))
+}
+
+// CreateOrUpdateOrigin implements Interface.
+func (c *Client) CreateOrUpdateOrigin(ctx context.Context, resourceGroupName, profileName, originGroupName, originName string, origin AFDOrigin) (AFDOrigin, error) {
+	path := runtime.JoinPaths(c.originGroupPath(resourceGroupName, profileName, originGroupName), "origins", url.PathEscape(originName))
+	resp, err := c.do(ctx, http.MethodPut, path, origin, http.StatusOK, http.StatusCreated, http.StatusAccepted)
+	if err != nil {
+		return AFDOrigin{}, err
+	}
+	return pollUntilDone[AFDOrigin](ctx, resp, c.internal.Pipeline())
+}
+
+// DeleteOrigin implements Interface.
+func (c *Client) DeleteOrigin(ctx context.Context, resourceGroupName, profileName, originGroupName, originName string) error {
+	path := runtime.JoinPaths(c.originGroupPath(resourceGroupName, profileName, originGroupName), "origins", url.PathEscape(originName))
+	resp, err := c.do(ctx, http.MethodDelete, path, nil, http.StatusOK, http.StatusAccepted, http.StatusNoContent)
+	if err != nil {
+		return err
+	}
+	_, err = pollUntilDone[struct{}](ctx, resp, c.internal.Pipeline())
+	return err
+}
+
+func (c *Client) originGroupPath(resourceGroupName, profileName, originGroupName string) string {
+	return runtime.JoinPaths(c.profilePath(resourceGroupName, profileName), "originGroups", url.PathEscape(originGroupName))
+}
+
+func (c *Client) profilePath(resourceGroupName, profileName string) string {
+	return runtime.JoinPaths("/subscriptions", url.PathEscape(c.subscriptionID),
+		"resourceGroups", url.PathEscape(resourceGroupName),
+		"providers/Microsoft.Cdn/profiles", url.PathEscape(profileName))
+}
+
+// listAll lists all the resources under the path, following the next links of the pages.
+func listAll[T any](ctx context.Context, c *Client, path string) ([]*T, error) {
+	var items []*T
+	endpoint := runtime.JoinPaths(c.internal.Endpoint(), path)
+	for {
+		req, err := runtime.NewRequest(ctx, http.MethodGet, endpoint)
+		if err != nil {
+			return nil, err
+		}
+		if !isNextLink(endpoint) {
+			setAPIVersion(req)
+		}
+		req.Raw().Header["Accept"] = []string{"application/json"}
+		resp, err := c.internal.Pipeline().Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if !runtime.HasStatusCode(resp, http.StatusOK) {
+			return nil, runtime.NewResponseError(resp)
+		}
+		page := listResult[T]{}
+		if err := runtime.UnmarshalAsJSON(resp, &page); err != nil {
+			return nil, err
+		}
+		items = append(items, page.Value...)
+		if page.NextLink == nil || *page.NextLink == "" {
+			return items, nil
+		}
+		endpoint = *page.NextLink
+	}
+}
+
+// do sends the request and returns the response if its status code is one of the expected ones.
+func (c *Client) do(ctx context.Context, method, path string, body any, statusCodes ...int) (*http.Response, error) {
+	req, err := runtime.NewRequest(ctx, method, runtime.JoinPaths(c.internal.Endpoint(), path))
+	if err != nil {
+		return nil, err
+	}
+	setAPIVersion(req)
+	req.Raw().Header["Accept"] = []string{"application/json"}
+	if body != nil {
+		if err := runtime.MarshalAsJSON(req, body); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := c.internal.Pipeline().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if !runtime.HasStatusCode(resp, statusCodes...) {
+		return nil, runtime.NewResponseError(resp)
+	}
+	return resp, nil
+}
+
+func setAPIVersion(req *policy.Request) {
+	query := req.Raw().URL.Query()
+	query.Set("api-version", apiVersion)
+	req.Raw().URL.RawQuery = query.Encode()
+}
+
+// isNextLink returns true if the endpoint is a next link returned by the server, which carries the api-version.
+func isNextLink(endpoint string) bool {
+	u, err := url.Parse(endpoint)
+	return err == nil && u.Query().Has("api-version")
+}
+
+// pollUntilDone waits for the long-running operation started by the response to complete, and returns its result.
+func pollUntilDone[T any](ctx context.Context, resp *http.Response, pl runtime.Pipeline) (T, error) {
+	poller, err := runtime.NewPoller[T](resp, pl, &runtime.NewPollerOptions[T]{
+		FinalStateVia: runtime.FinalStateViaAzureAsyncOp,
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return poller.PollUntilDone(ctx, nil)
+}
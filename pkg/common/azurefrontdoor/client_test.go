@@ -0,0 +1,154 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package azurefrontdoor
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/utils/ptr"
+
+	"go.goms.io/fleet-networking/pkg/common/azureerrors"
+)
+
+const (
+	testSubscriptionID = "sub"
+	originGroupPath    = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Cdn/profiles/afd/originGroups/group"
+)
+
+type fakeCredential struct{}
+
+func (fakeCredential) GetToken(_ context.Context, _ policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+type fakeResponse struct {
+	statusCode int
+	body       string
+}
+
+// fakeTransport responds to the requests by the method and the path, recording the requests it has received.
+type fakeTransport struct {
+	responses map[string]fakeResponse
+	requests  []string
+}
+
+func (f *fakeTransport) Do(req *http.Request) (*http.Response, error) {
+	key := req.Method + " " + req.URL.Path
+	if req.URL.Query().Has("page") {
+		key += "?page=" + req.URL.Query().Get("page")
+	}
+	f.requests = append(f.requests, key+" api-version="+req.URL.Query().Get("api-version"))
+	resp, ok := f.responses[key]
+	if !ok {
+		resp = fakeResponse{statusCode: http.StatusNotFound, body: `{"error":{"code":"NotFound"}}`}
+	}
+	return &http.Response{
+		StatusCode: resp.statusCode,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(resp.body)),
+		Request:    req,
+	}, nil
+}
+
+func newTestClient(t *testing.T, transport *fakeTransport) *Client {
+	c, err := NewClient(testSubscriptionID, fakeCredential{}, &arm.ClientOptions{
+		ClientOptions: policy.ClientOptions{Transport: transport, Retry: policy.RetryOptions{MaxRetries: -1}},
+	})
+	if err != nil {
+		t.Fatalf("NewClient() = %v, want no error", err)
+	}
+	return c
+}
+
+// TestCreateOrUpdateOrigin tests the Client.CreateOrUpdateOrigin method.
+func TestCreateOrUpdateOrigin(t *testing.T) {
+	transport := &fakeTransport{responses: map[string]fakeResponse{
+		"PUT " + originGroupPath + "/origins/member-1": {
+			statusCode: http.StatusOK,
+			body:       `{"name":"member-1","properties":{"hostName":"20.0.0.1","priority":1,"weight":500,"provisioningState":"Succeeded"}}`,
+		},
+	}}
+	c := newTestClient(t, transport)
+
+	got, err := c.CreateOrUpdateOrigin(context.Background(), "rg", "afd", "group", "member-1", AFDOrigin{
+		Properties: &AFDOriginProperties{HostName: ptr.To("20.0.0.1"), Priority: ptr.To[int32](1), Weight: ptr.To[int32](500)},
+	})
+	if err != nil {
+		t.Fatalf("CreateOrUpdateOrigin() = %v, want no error", err)
+	}
+	want := AFDOrigin{
+		Name: ptr.To("member-1"),
+		Properties: &AFDOriginProperties{
+			HostName:          ptr.To("20.0.0.1"),
+			Priority:          ptr.To[int32](1),
+			Weight:            ptr.To[int32](500),
+			ProvisioningState: ptr.To("Succeeded"),
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("CreateOrUpdateOrigin() mismatch (-want, +got):\n%s", diff)
+	}
+	wantRequests := []string{"PUT " + originGroupPath + "/origins/member-1 api-version=" + apiVersion}
+	if diff := cmp.Diff(wantRequests, transport.requests); diff != "" {
+		t.Errorf("requests mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+// TestListOrigins tests the Client.ListOrigins method.
+func TestListOrigins(t *testing.T) {
+	transport := &fakeTransport{responses: map[string]fakeResponse{
+		"GET " + originGroupPath + "/origins": {
+			statusCode: http.StatusOK,
+			body: `{"value":[{"name":"member-1"}],"nextLink":"https://management.azure.com` + originGroupPath +
+				`/origins?api-version=` + apiVersion + `&page=2"}`,
+		},
+		"GET " + originGroupPath + "/origins?page=2": {
+			statusCode: http.StatusOK,
+			body:       `{"value":[{"name":"member-2"}]}`,
+		},
+	}}
+	c := newTestClient(t, transport)
+
+	got, err := c.ListOrigins(context.Background(), "rg", "afd", "group")
+	if err != nil {
+		t.Fatalf("ListOrigins() = %v, want no error", err)
+	}
+	want := []*AFDOrigin{{Name: ptr.To("member-1")}, {Name: ptr.To("member-2")}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ListOrigins() mismatch (-want, +got):\n%s", diff)
+	}
+	wantRequests := []string{
+		"GET " + originGroupPath + "/origins api-version=" + apiVersion,
+		"GET " + originGroupPath + "/origins?page=2 api-version=" + apiVersion,
+	}
+	if diff := cmp.Diff(wantRequests, transport.requests); diff != "" {
+		t.Errorf("requests mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+// TestDeleteOrigin tests the Client.DeleteOrigin method.
+func TestDeleteOrigin(t *testing.T) {
+	transport := &fakeTransport{responses: map[string]fakeResponse{
+		"DELETE " + originGroupPath + "/origins/member-1": {statusCode: http.StatusNoContent},
+	}}
+	c := newTestClient(t, transport)
+
+	if err := c.DeleteOrigin(context.Background(), "rg", "afd", "group", "member-1"); err != nil {
+		t.Errorf("DeleteOrigin(member-1) = %v, want no error", err)
+	}
+	if err := c.DeleteOrigin(context.Background(), "rg", "afd", "group", "member-2"); !azureerrors.IsNotFound(err) {
+		t.Errorf("DeleteOrigin(member-2) = %v, want not found error", err)
+	}
+}
@@ -0,0 +1,226 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package azurefrontdoor
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+const (
+	// EndpointLocation is the location of the endpoints, which are global resources.
+	EndpointLocation = "Global"
+)
+
+// AFDEndpoint is an endpoint of an Azure Front Door profile, i.e. the entry point of the traffic with a default
+// domain, e.g. <name>-<hash>.z01.azurefd.net.
+type AFDEndpoint struct {
+	ID         *string                `json:"id,omitempty"`
+	Name       *string                `json:"name,omitempty"`
+	Location   *string                `json:"location,omitempty"`
+	Properties *AFDEndpointProperties `json:"properties,omitempty"`
+}
+
+// AFDEndpointProperties are the properties of an endpoint.
+type AFDEndpointProperties struct {
+	EnabledState      *string `json:"enabledState,omitempty"`
+	HostName          *string `json:"hostName,omitempty"`
+	ProvisioningState *string `json:"provisioningState,omitempty"`
+}
+
+// ResourceReference is a reference to another resource by its ID.
+type ResourceReference struct {
+	ID *string `json:"id,omitempty"`
+}
+
+// Route is a route of an endpoint, which routes the requests of its domains matching its patterns to an origin
+// group.
+type Route struct {
+	ID         *string          `json:"id,omitempty"`
+	Name       *string          `json:"name,omitempty"`
+	Properties *RouteProperties `json:"properties,omitempty"`
+}
+
+// RouteProperties are the properties of a route.
+type RouteProperties struct {
+	CustomDomains       []*ResourceReference `json:"customDomains,omitempty"`
+	EnabledState        *string              `json:"enabledState,omitempty"`
+	ForwardingProtocol  *string              `json:"forwardingProtocol,omitempty"`
+	HTTPSRedirect       *string              `json:"httpsRedirect,omitempty"`
+	LinkToDefaultDomain *string              `json:"linkToDefaultDomain,omitempty"`
+	OriginGroup         *ResourceReference   `json:"originGroup,omitempty"`
+	PatternsToMatch     []*string            `json:"patternsToMatch,omitempty"`
+	SupportedProtocols  []*string            `json:"supportedProtocols,omitempty"`
+	ProvisioningState   *string              `json:"provisioningState,omitempty"`
+}
+
+// AFDDomain is a custom domain of an Azure Front Door profile, which is served by the routes referencing it.
+type AFDDomain struct {
+	ID         *string              `json:"id,omitempty"`
+	Name       *string              `json:"name,omitempty"`
+	Properties *AFDDomainProperties `json:"properties,omitempty"`
+}
+
+// AFDDomainProperties are the properties of a custom domain.
+type AFDDomainProperties struct {
+	HostName              *string                     `json:"hostName,omitempty"`
+	TLSSettings           *AFDDomainHTTPSParameters   `json:"tlsSettings,omitempty"`
+	DomainValidationState *string                     `json:"domainValidationState,omitempty"`
+	ValidationProperties  *DomainValidationProperties `json:"validationProperties,omitempty"`
+	ProvisioningState     *string                     `json:"provisioningState,omitempty"`
+}
+
+// AFDDomainHTTPSParameters are the TLS settings of a custom domain.
+type AFDDomainHTTPSParameters struct {
+	CertificateType   *string            `json:"certificateType,omitempty"`
+	MinimumTLSVersion *string            `json:"minimumTlsVersion,omitempty"`
+	Secret            *ResourceReference `json:"secret,omitempty"`
+}
+
+// DomainValidationProperties are the properties used to validate the ownership of a custom domain, i.e. the token to
+// be set in the _dnsauth TXT record of the domain.
+type DomainValidationProperties struct {
+	ExpirationDate  *string `json:"expirationDate,omitempty"`
+	ValidationToken *string `json:"validationToken,omitempty"`
+}
+
+// RoutingInterface is the interface of the client of the endpoints, routes and custom domains of the Azure Front Door
+// profiles.
+type RoutingInterface interface {
+	// ProfileID returns the resource ID of a profile, which prefixes the IDs of its origin groups, custom domains and
+	// secrets.
+	ProfileID(resourceGroupName, profileName string) string
+	// GetEndpoint gets an endpoint.
+	GetEndpoint(ctx context.Context, resourceGroupName, profileName, endpointName string) (AFDEndpoint, error)
+	// CreateOrUpdateEndpoint creates or updates an endpoint and waits for the operation to complete.
+	CreateOrUpdateEndpoint(ctx context.Context, resourceGroupName, profileName, endpointName string, endpoint AFDEndpoint) (AFDEndpoint, error)
+	// DeleteEndpoint deletes an endpoint along with its routes and waits for the operation to complete.
+	DeleteEndpoint(ctx context.Context, resourceGroupName, profileName, endpointName string) error
+	// ListRoutes lists the routes of an endpoint.
+	ListRoutes(ctx context.Context, resourceGroupName, profileName, endpointName string) ([]*Route, error)
+	// CreateOrUpdateRoute creates or updates a route and waits for the operation to complete.
+	CreateOrUpdateRoute(ctx context.Context, resourceGroupName, profileName, endpointName, routeName string, route Route) (Route, error)
+	// DeleteRoute deletes a route and waits for the operation to complete.
+	DeleteRoute(ctx context.Context, resourceGroupName, profileName, endpointName, routeName string) error
+	// GetCustomDomain gets a custom domain.
+	GetCustomDomain(ctx context.Context, resourceGroupName, profileName, domainName string) (AFDDomain, error)
+	// CreateOrUpdateCustomDomain creates or updates a custom domain and waits for the operation to complete.
+	CreateOrUpdateCustomDomain(ctx context.Context, resourceGroupName, profileName, domainName string, domain AFDDomain) (AFDDomain, error)
+	// DeleteCustomDomain deletes a custom domain and waits for the operation to complete.
+	DeleteCustomDomain(ctx context.Context, resourceGroupName, profileName, domainName string) error
+}
+
+var _ RoutingInterface = &Client{}
+
+// ProfileID implements RoutingInterface.
+func (c *Client) ProfileID(resourceGroupName, profileName string) string {
+	return c.profilePath(resourceGroupName, profileName)
+}
+
+// GetEndpoint implements RoutingInterface.
+func (c *Client) GetEndpoint(ctx context.Context, resourceGroupName, profileName, endpointName string) (AFDEndpoint, error) {
+	resp, err := c.do(ctx, http.MethodGet, c.endpointPath(resourceGroupName, profileName, endpointName), nil, http.StatusOK)
+	if err != nil {
+		return AFDEndpoint{}, err
+	}
+	endpoint := AFDEndpoint{}
+	if err := runtime.UnmarshalAsJSON(resp, &endpoint); err != nil {
+		return AFDEndpoint{}, err
+	}
+	return endpoint, nil
+}
+
+// CreateOrUpdateEndpoint implements RoutingInterface.
+func (c *Client) CreateOrUpdateEndpoint(ctx context.Context, resourceGroupName, profileName, endpointName string, endpoint AFDEndpoint) (AFDEndpoint, error) {
+	resp, err := c.do(ctx, http.MethodPut, c.endpointPath(resourceGroupName, profileName, endpointName), endpoint,
+		http.StatusOK, http.StatusCreated, http.StatusAccepted)
+	if err != nil {
+		return AFDEndpoint{}, err
+	}
+	return pollUntilDone[AFDEndpoint](ctx, resp, c.internal.Pipeline())
+}
+
+// DeleteEndpoint implements RoutingInterface.
+func (c *Client) DeleteEndpoint(ctx context.Context, resourceGroupName, profileName, endpointName string) error {
+	resp, err := c.do(ctx, http.MethodDelete, c.endpointPath(resourceGroupName, profileName, endpointName), nil,
+		http.StatusOK, http.StatusAccepted, http.StatusNoContent)
+	if err != nil {
+		return err
+	}
+	_, err = pollUntilDone[struct{}](ctx, resp, c.internal.Pipeline())
+	return err
+}
+
+// ListRoutes implements RoutingInterface.
+func (c *Client) ListRoutes(ctx context.Context, resourceGroupName, profileName, endpointName string) ([]*Route, error) {
+	return listAll[Route](ctx, c, runtime.JoinPaths(c.endpointPath(resourceGroupName, profileName, endpointName), "routes"))
+}
+
+// CreateOrUpdateRoute implements RoutingInterface.
+func (c *Client) CreateOrUpdateRoute(ctx context.Context, resourceGroupName, profileName, endpointName, routeName string, route Route) (Route, error) {
+	path := runtime.JoinPaths(c.endpointPath(resourceGroupName, profileName, endpointName), "routes", url.PathEscape(routeName))
+	resp, err := c.do(ctx, http.MethodPut, path, route, http.StatusOK, http.StatusCreated, http.StatusAccepted)
+	if err != nil {
+		return Route{}, err
+	}
+	return pollUntilDone[Route](ctx, resp, c.internal.Pipeline())
+}
+
+// DeleteRoute implements RoutingInterface.
+func (c *Client) DeleteRoute(ctx context.Context, resourceGroupName, profileName, endpointName, routeName string) error {
+	path := runtime.JoinPaths(c.endpointPath(resourceGroupName, profileName, endpointName), "routes", url.PathEscape(routeName))
+	resp, err := c.do(ctx, http.MethodDelete, path, nil, http.StatusOK, http.StatusAccepted, http.StatusNoContent)
+	if err != nil {
+		return err
+	}
+	_, err = pollUntilDone[struct{}](ctx, resp, c.internal.Pipeline())
+	return err
+}
+
+// GetCustomDomain implements RoutingInterface.
+func (c *Client) GetCustomDomain(ctx context.Context, resourceGroupName, profileName, domainName string) (AFDDomain, error) {
+	resp, err := c.do(ctx, http.MethodGet, c.customDomainPath(resourceGroupName, profileName, domainName), nil, http.StatusOK)
+	if err != nil {
+		return AFDDomain{}, err
+	}
+	domain := AFDDomain{}
+	if err := runtime.UnmarshalAsJSON(resp, &domain); err != nil {
+		return AFDDomain{}, err
+	}
+	return domain, nil
+}
+
+// CreateOrUpdateCustomDomain implements RoutingInterface.
+func (c *Client) CreateOrUpdateCustomDomain(ctx context.Context, resourceGroupName, profileName, domainName string, domain AFDDomain) (AFDDomain, error) {
+	resp, err := c.do(ctx, http.MethodPut, c.customDomainPath(resourceGroupName, profileName, domainName), domain,
+		http.StatusOK, http.StatusCreated, http.StatusAccepted)
+	if err != nil {
+		return AFDDomain{}, err
+	}
+	return pollUntilDone[AFDDomain](ctx, resp, c.internal.Pipeline())
+}
+
+// DeleteCustomDomain implements RoutingInterface.
+func (c *Client) DeleteCustomDomain(ctx context.Context, resourceGroupName, profileName, domainName string) error {
+	resp, err := c.do(ctx, http.MethodDelete, c.customDomainPath(resourceGroupName, profileName, domainName), nil,
+		http.StatusOK, http.StatusAccepted, http.StatusNoContent)
+	if err != nil {
+		return err
+	}
+	_, err = pollUntilDone[struct{}](ctx, resp, c.internal.Pipeline())
+	return err
+}
+
+func (c *Client) endpointPath(resourceGroupName, profileName, endpointName string) string {
+	return runtime.JoinPaths(c.profilePath(resourceGroupName, profileName), "afdEndpoints", url.PathEscape(endpointName))
+}
+
+func (c *Client) customDomainPath(resourceGroupName, profileName, domainName string) string {
+	return runtime.JoinPaths(c.profilePath(resourceGroupName, profileName), "customDomains", url.PathEscape(domainName))
+}
@@ -0,0 +1,171 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package azurefrontdoor
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/utils/ptr"
+
+	"go.goms.io/fleet-networking/pkg/common/azureerrors"
+)
+
+const (
+	profilePath  = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Cdn/profiles/afd"
+	endpointPath = profilePath + "/afdEndpoints/endpoint"
+)
+
+// TestProfileID tests the Client.ProfileID method.
+func TestProfileID(t *testing.T) {
+	c := newTestClient(t, &fakeTransport{})
+	if got := c.ProfileID("rg", "afd"); got != profilePath {
+		t.Errorf("ProfileID() = %q, want %q", got, profilePath)
+	}
+}
+
+// TestCreateOrUpdateEndpoint tests the Client.CreateOrUpdateEndpoint and Client.GetEndpoint methods.
+func TestCreateOrUpdateEndpoint(t *testing.T) {
+	body := `{"name":"endpoint","location":"Global","properties":{"enabledState":"Enabled","hostName":"endpoint-abc.z01.azurefd.net"}}`
+	transport := &fakeTransport{responses: map[string]fakeResponse{
+		"PUT " + endpointPath: {statusCode: http.StatusOK, body: body},
+		"GET " + endpointPath: {statusCode: http.StatusOK, body: body},
+	}}
+	c := newTestClient(t, transport)
+
+	got, err := c.CreateOrUpdateEndpoint(context.Background(), "rg", "afd", "endpoint", AFDEndpoint{
+		Location:   ptr.To(EndpointLocation),
+		Properties: &AFDEndpointProperties{EnabledState: ptr.To("Enabled")},
+	})
+	if err != nil {
+		t.Fatalf("CreateOrUpdateEndpoint() = %v, want no error", err)
+	}
+	want := AFDEndpoint{
+		Name:     ptr.To("endpoint"),
+		Location: ptr.To(EndpointLocation),
+		Properties: &AFDEndpointProperties{
+			EnabledState: ptr.To("Enabled"),
+			HostName:     ptr.To("endpoint-abc.z01.azurefd.net"),
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("CreateOrUpdateEndpoint() mismatch (-want, +got):\n%s", diff)
+	}
+
+	got, err = c.GetEndpoint(context.Background(), "rg", "afd", "endpoint")
+	if err != nil {
+		t.Fatalf("GetEndpoint() = %v, want no error", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetEndpoint() mismatch (-want, +got):\n%s", diff)
+	}
+	if _, err := c.GetEndpoint(context.Background(), "rg", "afd", "other"); !azureerrors.IsNotFound(err) {
+		t.Errorf("GetEndpoint(other) = %v, want not found error", err)
+	}
+}
+
+// TestRoutes tests the Client.ListRoutes, Client.CreateOrUpdateRoute and Client.DeleteRoute methods.
+func TestRoutes(t *testing.T) {
+	transport := &fakeTransport{responses: map[string]fakeResponse{
+		"GET " + endpointPath + "/routes": {
+			statusCode: http.StatusOK,
+			body:       `{"value":[{"name":"rule-0-path-0","properties":{"patternsToMatch":["/*"]}}]}`,
+		},
+		"PUT " + endpointPath + "/routes/rule-0-path-1": {
+			statusCode: http.StatusOK,
+			body:       `{"name":"rule-0-path-1","properties":{"patternsToMatch":["/api/*"],"originGroup":{"id":"group-id"}}}`,
+		},
+		"DELETE " + endpointPath + "/routes/rule-0-path-0": {statusCode: http.StatusOK},
+	}}
+	c := newTestClient(t, transport)
+
+	routes, err := c.ListRoutes(context.Background(), "rg", "afd", "endpoint")
+	if err != nil {
+		t.Fatalf("ListRoutes() = %v, want no error", err)
+	}
+	wantRoutes := []*Route{{Name: ptr.To("rule-0-path-0"), Properties: &RouteProperties{PatternsToMatch: []*string{ptr.To("/*")}}}}
+	if diff := cmp.Diff(wantRoutes, routes); diff != "" {
+		t.Errorf("ListRoutes() mismatch (-want, +got):\n%s", diff)
+	}
+
+	route := Route{Properties: &RouteProperties{
+		PatternsToMatch: []*string{ptr.To("/api/*")},
+		OriginGroup:     &ResourceReference{ID: ptr.To("group-id")},
+	}}
+	got, err := c.CreateOrUpdateRoute(context.Background(), "rg", "afd", "endpoint", "rule-0-path-1", route)
+	if err != nil {
+		t.Fatalf("CreateOrUpdateRoute() = %v, want no error", err)
+	}
+	route.Name = ptr.To("rule-0-path-1")
+	if diff := cmp.Diff(route, got); diff != "" {
+		t.Errorf("CreateOrUpdateRoute() mismatch (-want, +got):\n%s", diff)
+	}
+
+	if err := c.DeleteRoute(context.Background(), "rg", "afd", "endpoint", "rule-0-path-0"); err != nil {
+		t.Errorf("DeleteRoute() = %v, want no error", err)
+	}
+	wantRequests := []string{
+		"GET " + endpointPath + "/routes api-version=" + apiVersion,
+		"PUT " + endpointPath + "/routes/rule-0-path-1 api-version=" + apiVersion,
+		"DELETE " + endpointPath + "/routes/rule-0-path-0 api-version=" + apiVersion,
+	}
+	if diff := cmp.Diff(wantRequests, transport.requests); diff != "" {
+		t.Errorf("requests mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+// TestCustomDomains tests the Client.GetCustomDomain, Client.CreateOrUpdateCustomDomain and
+// Client.DeleteCustomDomain methods.
+func TestCustomDomains(t *testing.T) {
+	domainPath := profilePath + "/customDomains/domain"
+	body := `{"name":"domain","properties":{"hostName":"www.contoso.com","tlsSettings":{"certificateType":"ManagedCertificate","minimumTlsVersion":"TLS12"},` +
+		`"domainValidationState":"Pending","validationProperties":{"validationToken":"token"}}}`
+	transport := &fakeTransport{responses: map[string]fakeResponse{
+		"PUT " + domainPath:    {statusCode: http.StatusOK, body: body},
+		"GET " + domainPath:    {statusCode: http.StatusOK, body: body},
+		"DELETE " + domainPath: {statusCode: http.StatusNoContent},
+	}}
+	c := newTestClient(t, transport)
+
+	got, err := c.CreateOrUpdateCustomDomain(context.Background(), "rg", "afd", "domain", AFDDomain{
+		Properties: &AFDDomainProperties{
+			HostName:    ptr.To("www.contoso.com"),
+			TLSSettings: &AFDDomainHTTPSParameters{CertificateType: ptr.To("ManagedCertificate"), MinimumTLSVersion: ptr.To("TLS12")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateOrUpdateCustomDomain() = %v, want no error", err)
+	}
+	want := AFDDomain{
+		Name: ptr.To("domain"),
+		Properties: &AFDDomainProperties{
+			HostName:              ptr.To("www.contoso.com"),
+			TLSSettings:           &AFDDomainHTTPSParameters{CertificateType: ptr.To("ManagedCertificate"), MinimumTLSVersion: ptr.To("TLS12")},
+			DomainValidationState: ptr.To("Pending"),
+			ValidationProperties:  &DomainValidationProperties{ValidationToken: ptr.To("token")},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("CreateOrUpdateCustomDomain() mismatch (-want, +got):\n%s", diff)
+	}
+
+	got, err = c.GetCustomDomain(context.Background(), "rg", "afd", "domain")
+	if err != nil {
+		t.Fatalf("GetCustomDomain() = %v, want no error", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetCustomDomain() mismatch (-want, +got):\n%s", diff)
+	}
+
+	if err := c.DeleteCustomDomain(context.Background(), "rg", "afd", "domain"); err != nil {
+		t.Errorf("DeleteCustomDomain(domain) = %v, want no error", err)
+	}
+	if err := c.DeleteCustomDomain(context.Background(), "rg", "afd", "other"); !azureerrors.IsNotFound(err) {
+		t.Errorf("DeleteCustomDomain(other) = %v, want not found error", err)
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package cacheoptions features the options of the informer caches of the controller managers, which keep only
+// the objects, and the parts of the objects, the controllers read; in clusters with tens of thousands of
+// EndpointSlices, the cached objects account for most of the memory used by the agents.
+package cacheoptions
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Hub returns the options of a cache of the hub cluster, restricted to the given namespaces if any.
+func Hub(namespaces ...string) cache.Options {
+	opts := cache.Options{
+		// No controller reads the managed fields; the writes from a cache without them keep the ones on the server.
+		DefaultTransform: cache.TransformStripManagedFields(),
+	}
+	if len(namespaces) > 0 {
+		opts.DefaultNamespaces = make(map[string]cache.Config, len(namespaces))
+		for _, namespace := range namespaces {
+			opts.DefaultNamespaces[namespace] = cache.Config{}
+		}
+	}
+	return opts
+}
+
+// Member returns the options of the cache of a member cluster, where the agent runs in the fleet system namespace.
+//
+// Only the EndpointSlices of Services are cached, as the controllers look the EndpointSlices up by their Service,
+// whether they are exported or imported; an EndpointSlice which loses its Service label is seen as deleted, and its
+// export is withdrawn as such. Only the ConfigMaps in the fleet system namespace are cached, as the controllers only
+// keep their own configurations there. The Services are cached without the configuration last applied by kubectl,
+// as the controllers only patch the Services of the users.
+func Member(fleetSystemNamespace string) cache.Options {
+	opts := Hub()
+	opts.ByObject = map[client.Object]cache.ByObject{
+		&discoveryv1.EndpointSlice{}: {
+			Label: EndpointSliceSelector(),
+		},
+		&corev1.Service{}: {
+			Transform: TransformStripLastApplied(opts.DefaultTransform),
+		},
+		&corev1.ConfigMap{}: {
+			Namespaces: map[string]cache.Config{fleetSystemNamespace: {}},
+		},
+		&corev1.Secret{}: {
+			Namespaces: map[string]cache.Config{fleetSystemNamespace: {}},
+		},
+	}
+	return opts
+}
+
+// EndpointSliceSelector selects the EndpointSlices which belong to a Service.
+func EndpointSliceSelector() labels.Selector {
+	// The requirement is built from a valid label key, which never fails.
+	requirement, _ := labels.NewRequirement(discoveryv1.LabelServiceName, selection.Exists, nil)
+	return labels.NewSelector().Add(*requirement)
+}
+
+// TransformStripLastApplied strips the last configuration applied by kubectl, which can be as large as the object
+// itself, before the object is committed to the cache, after the given transform if any.
+//
+// It is only safe for the objects the controllers never update as a whole, as an update from the cache would remove
+// the annotation, and the next kubectl apply would no longer tell the fields it has removed.
+func TransformStripLastApplied(transform toolscache.TransformFunc) toolscache.TransformFunc {
+	return func(in interface{}) (interface{}, error) {
+		if transform != nil {
+			var err error
+			if in, err = transform(in); err != nil {
+				return nil, err
+			}
+		}
+		if obj, err := meta.Accessor(in); err == nil {
+			if annotations := obj.GetAnnotations(); annotations != nil {
+				if _, ok := annotations[corev1.LastAppliedConfigAnnotation]; ok {
+					delete(annotations, corev1.LastAppliedConfigAnnotation)
+					obj.SetAnnotations(annotations)
+				}
+			}
+		}
+		return in, nil
+	}
+}
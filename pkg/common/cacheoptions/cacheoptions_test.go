@@ -0,0 +1,103 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package cacheoptions
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+// TestEndpointSliceSelector tests the EndpointSliceSelector function.
+func TestEndpointSliceSelector(t *testing.T) {
+	testCases := []struct {
+		name   string
+		labels map[string]string
+		want   bool
+	}{
+		{
+			name:   "endpointslice of a service",
+			labels: map[string]string{discoveryv1.LabelServiceName: "app"},
+			want:   true,
+		},
+		{
+			name:   "endpointslice of no service",
+			labels: map[string]string{discoveryv1.LabelManagedBy: "custom"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := EndpointSliceSelector().Matches(labels.Set(tc.labels)); got != tc.want {
+				t.Errorf("EndpointSliceSelector().Matches() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestTransformStripLastApplied tests that the managed fields and the last applied configuration are stripped, and
+// that the other annotations are kept.
+func TestTransformStripLastApplied(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "work",
+			Name:      "app",
+			Annotations: map[string]string{
+				corev1.LastAppliedConfigAnnotation: `{"apiVersion":"v1","kind":"Service"}`,
+				"fleet.azure.com/dns-ttl":          "30",
+			},
+			ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+		},
+	}
+	got, err := TransformStripLastApplied(cache.TransformStripManagedFields())(svc)
+	if err != nil {
+		t.Fatalf("TransformStripLastApplied() = %v, want no error", err)
+	}
+	want := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "work",
+			Name:        "app",
+			Annotations: map[string]string{"fleet.azure.com/dns-ttl": "30"},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("TransformStripLastApplied() (-want, +got):\n%s", diff)
+	}
+}
+
+// TestMember tests that the member cluster cache only keeps the ConfigMaps and the Secrets of the fleet system
+// namespace.
+func TestMember(t *testing.T) {
+	opts := Member("fleet-system")
+	if opts.DefaultTransform == nil {
+		t.Errorf("Member().DefaultTransform = nil, want the managed fields stripped")
+	}
+	found := map[string]bool{}
+	for obj, byObject := range opts.ByObject {
+		var kind string
+		switch obj.(type) {
+		case *corev1.ConfigMap:
+			kind = "ConfigMap"
+		case *corev1.Secret:
+			kind = "Secret"
+		default:
+			continue
+		}
+		found[kind] = true
+		if diff := cmp.Diff(map[string]cache.Config{"fleet-system": {}}, byObject.Namespaces); diff != "" {
+			t.Errorf("Member() %s namespaces (-want, +got):\n%s", kind, diff)
+		}
+	}
+	for _, kind := range []string{"ConfigMap", "Secret"} {
+		if !found[kind] {
+			t.Errorf("Member() has no options for %ss, want some", kind)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package clusterhealth features the hub-side health evaluation of the exports of member clusters, which lets the
+// hub fail the traffic of a Service over from a member cluster that stops reporting, or that has no ready endpoints
+// left, to the other member clusters exporting the Service.
+package clusterhealth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1beta1 "go.goms.io/fleet/apis/cluster/v1beta1"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/fieldindex"
+	"go.goms.io/fleet-networking/pkg/common/uniquename"
+)
+
+const (
+	// ReasonClusterNotReporting is the reason of the unhealthy condition of the exports of a member cluster whose
+	// networking agent stops reporting, so that its EndpointSliceExports can no longer be trusted to be fresh.
+	ReasonClusterNotReporting = "ClusterNotReporting"
+	// ReasonNoReadyEndpoints is the reason of the unhealthy condition of an export with no ready endpoints.
+	ReasonNoReadyEndpoints = "NoReadyEndpoints"
+
+	// endpointSliceExportOwnerSvcNamespacedNameFieldKey is the field index of the EndpointSliceExports by the
+	// namespaced name of their Service, which the EndpointSliceExport controller sets up.
+	endpointSliceExportOwnerSvcNamespacedNameFieldKey = fieldindex.EndpointSliceExportOwnerServiceNamespacedName
+)
+
+// Evaluator evaluates the health of the exports of member clusters.
+//
+// An export is failing if the networking agent of its member cluster, which exports the EndpointSlices, has not
+// reported for the HeartbeatTimeout, or if it exports no ready endpoints. To avoid flapping, an export is reported as
+// unhealthy only after it keeps failing for the UnhealthyThreshold, and as healthy again only after it keeps passing
+// for the HealthyThreshold. An export is never failed over if no other member cluster exporting the same Service is
+// reporting and has ready endpoints, as there is nowhere to fail the traffic over to.
+type Evaluator struct {
+	// Interval is how often the health of an export is evaluated.
+	Interval time.Duration
+	// HeartbeatTimeout is how long the networking agent of a member cluster may stay silent before its exports
+	// are considered stale; the heartbeats are not checked if it is zero, e.g. when the MemberCluster API is missing.
+	HeartbeatTimeout time.Duration
+	// UnhealthyThreshold is how long a healthy export must keep failing before it is reported as unhealthy.
+	UnhealthyThreshold time.Duration
+	// HealthyThreshold is how long an unhealthy export must keep passing before it is reported as healthy again.
+	HealthyThreshold time.Duration
+
+	mu sync.Mutex
+	// pendingSince keeps, for each export whose health differs from the reported one, when the difference was
+	// first observed; it is kept in memory only, so that a restart of the hub agent restarts the thresholds.
+	pendingSince map[types.NamespacedName]time.Time
+}
+
+// Result is the health of an export as reported by the Evaluator.
+type Result struct {
+	Unhealthy bool
+	// Reason and Message describe why an export is unhealthy.
+	Reason  string
+	Message string
+}
+
+// IsUnhealthyExport returns true if the internalServiceExport is reported as unhealthy in its status.
+func IsUnhealthyExport(internalServiceExport *fleetnetv1alpha1.InternalServiceExport) bool {
+	return meta.IsStatusConditionTrue(internalServiceExport.Status.Conditions, string(fleetnetv1alpha1.ServiceExportUnhealthy))
+}
+
+// IsServiceUnhealthy returns true if the internalServiceExport of the Service exported from the member cluster, whose
+// namespace in the hub cluster is given, is reported as unhealthy; a Service which is not exported is not unhealthy.
+func IsServiceUnhealthy(ctx context.Context, reader client.Reader, clusterNamespace, svcNamespace, svcName string) (bool, error) {
+	internalServiceExport := &fleetnetv1alpha1.InternalServiceExport{}
+	key := types.NamespacedName{Namespace: clusterNamespace, Name: uniquename.ClusterScopedDeterministicName(svcNamespace, svcName)}
+	if err := reader.Get(ctx, key, internalServiceExport); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	return IsUnhealthyExport(internalServiceExport), nil
+}
+
+// Evaluate evaluates the health of the internalServiceExport at the given time, and returns the health to report
+// after the hysteresis is applied. The reader must index the EndpointSliceExports by the namespaced name of their
+// Service.
+func (e *Evaluator) Evaluate(ctx context.Context, reader client.Reader, internalServiceExport *fleetnetv1alpha1.InternalServiceExport, now time.Time) (Result, error) {
+	probed, err := e.probe(ctx, reader, internalServiceExport, now)
+	if err != nil {
+		return Result{}, err
+	}
+	current := Result{}
+	if IsUnhealthyExport(internalServiceExport) {
+		cond := meta.FindStatusCondition(internalServiceExport.Status.Conditions, string(fleetnetv1alpha1.ServiceExportUnhealthy))
+		current = Result{Unhealthy: true, Reason: cond.Reason, Message: cond.Message}
+	}
+
+	key := types.NamespacedName{Namespace: internalServiceExport.Namespace, Name: internalServiceExport.Name}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if probed.Unhealthy == current.Unhealthy {
+		delete(e.pendingSince, key)
+		if probed.Unhealthy {
+			// Refresh the reason, which may change while the export stays unhealthy.
+			return probed, nil
+		}
+		return current, nil
+	}
+	if e.pendingSince == nil {
+		e.pendingSince = make(map[types.NamespacedName]time.Time)
+	}
+	since, ok := e.pendingSince[key]
+	if !ok {
+		since = now
+		e.pendingSince[key] = since
+	}
+	threshold := e.UnhealthyThreshold
+	if current.Unhealthy {
+		threshold = e.HealthyThreshold
+	}
+	if now.Sub(since) < threshold {
+		return current, nil
+	}
+	delete(e.pendingSince, key)
+	return probed, nil
+}
+
+// Forget drops the pending health change of the internalServiceExport, e.g. when it is deleted.
+func (e *Evaluator) Forget(key types.NamespacedName) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.pendingSince, key)
+}
+
+// probe returns the health of the internalServiceExport as observed at the given time, without hysteresis.
+func (e *Evaluator) probe(ctx context.Context, reader client.Reader, internalServiceExport *fleetnetv1alpha1.InternalServiceExport, now time.Time) (Result, error) {
+	svcRef := internalServiceExport.Spec.ServiceReference
+	readyEndpoints, err := countReadyEndpoints(ctx, reader, svcRef.Namespace, svcRef.Name)
+	if err != nil {
+		return Result{}, err
+	}
+	reporting, err := e.isReporting(ctx, reader, svcRef.ClusterID, now)
+	if err != nil {
+		return Result{}, err
+	}
+
+	var result Result
+	switch {
+	case !reporting:
+		result = Result{
+			Unhealthy: true,
+			Reason:    ReasonClusterNotReporting,
+			Message:   fmt.Sprintf("member cluster %s has not reported for %s and its exports are failed over", svcRef.ClusterID, e.HeartbeatTimeout),
+		}
+	case readyEndpoints[svcRef.ClusterID] == 0:
+		result = Result{
+			Unhealthy: true,
+			Reason:    ReasonNoReadyEndpoints,
+			Message:   fmt.Sprintf("service %s exported from member cluster %s has no ready endpoints and is failed over", svcRef.NamespacedName, svcRef.ClusterID),
+		}
+	default:
+		return Result{}, nil
+	}
+
+	// Fail over only if another member cluster can take the traffic.
+	for cluster, count := range readyEndpoints {
+		if cluster == svcRef.ClusterID || count == 0 {
+			continue
+		}
+		reporting, err := e.isReporting(ctx, reader, cluster, now)
+		if err != nil {
+			return Result{}, err
+		}
+		if reporting {
+			return result, nil
+		}
+	}
+	return Result{}, nil
+}
+
+// isReporting returns true if the networking agent exporting the services of the member cluster has reported within
+// the heartbeat timeout; a member cluster without any reported heartbeat is assumed to be reporting.
+func (e *Evaluator) isReporting(ctx context.Context, reader client.Reader, clusterID string, now time.Time) (bool, error) {
+	if e.HeartbeatTimeout == 0 {
+		return true, nil
+	}
+	mc := &clusterv1beta1.MemberCluster{}
+	if err := reader.Get(ctx, types.NamespacedName{Name: clusterID}, mc); err != nil {
+		return true, client.IgnoreNotFound(err)
+	}
+	for _, agentStatus := range mc.Status.AgentStatus {
+		if agentStatus.Type != clusterv1beta1.ServiceExportImportAgent || agentStatus.LastReceivedHeartbeat.IsZero() {
+			continue
+		}
+		return now.Sub(agentStatus.LastReceivedHeartbeat.Time) < e.HeartbeatTimeout, nil
+	}
+	return true, nil
+}
+
+// countReadyEndpoints returns the number of ready endpoints of the Service exported from each member cluster.
+func countReadyEndpoints(ctx context.Context, reader client.Reader, svcNamespace, svcName string) (map[string]int32, error) {
+	endpointSliceExportList := &fleetnetv1alpha1.EndpointSliceExportList{}
+	listOpts := client.MatchingFields{
+		endpointSliceExportOwnerSvcNamespacedNameFieldKey: types.NamespacedName{Namespace: svcNamespace, Name: svcName}.String(),
+	}
+	if err := reader.List(ctx, endpointSliceExportList, listOpts); err != nil {
+		return nil, err
+	}
+	readyEndpoints := make(map[string]int32)
+	for i := range endpointSliceExportList.Items {
+		endpointSliceExport := &endpointSliceExportList.Items[i]
+		if endpointSliceExport.DeletionTimestamp != nil {
+			continue
+		}
+		for j := range endpointSliceExport.Spec.Endpoints {
+			if endpointSliceExport.Spec.Endpoints[j].IsReady() {
+				readyEndpoints[endpointSliceExport.Spec.EndpointSliceReference.ClusterID]++
+			}
+		}
+	}
+	return readyEndpoints, nil
+}
@@ -0,0 +1,297 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package clusterhealth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1beta1 "go.goms.io/fleet/apis/cluster/v1beta1"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/uniquename"
+)
+
+const (
+	testNamespace   = "my-ns"
+	testServiceName = "my-svc"
+	testClusterID   = "member-1"
+	otherClusterID  = "member-2"
+)
+
+func testScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	if err := clusterv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	return scheme
+}
+
+func internalServiceExport(unhealthy bool) *fleetnetv1alpha1.InternalServiceExport {
+	export := &fleetnetv1alpha1.InternalServiceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testClusterID + "-ns",
+			Name:      uniquename.ClusterScopedDeterministicName(testNamespace, testServiceName),
+		},
+		Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+			ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+				ClusterID:      testClusterID,
+				Kind:           "Service",
+				Namespace:      testNamespace,
+				Name:           testServiceName,
+				NamespacedName: testNamespace + "/" + testServiceName,
+			},
+		},
+	}
+	if unhealthy {
+		export.Status.Conditions = []metav1.Condition{
+			{
+				Type:   string(fleetnetv1alpha1.ServiceExportUnhealthy),
+				Status: metav1.ConditionTrue,
+				Reason: ReasonNoReadyEndpoints,
+			},
+		}
+	}
+	return export
+}
+
+func endpointSliceExport(clusterID string, ready bool) *fleetnetv1alpha1.EndpointSliceExport {
+	return &fleetnetv1alpha1.EndpointSliceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: clusterID + "-ns",
+			Name:      "my-svc-slice",
+			Labels: map[string]string{
+				objectmeta.EndpointSliceExportLabelOwnerServiceNamespace: testNamespace,
+				objectmeta.EndpointSliceExportLabelOwnerServiceName:      testServiceName,
+			},
+		},
+		Spec: fleetnetv1alpha1.EndpointSliceExportSpec{
+			Endpoints: []fleetnetv1alpha1.Endpoint{
+				{
+					Addresses:  []string{"10.0.0.1"},
+					Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(ready)},
+				},
+			},
+			EndpointSliceReference: fleetnetv1alpha1.ExportedObjectReference{ClusterID: clusterID},
+			OwnerServiceReference: fleetnetv1alpha1.OwnerServiceReference{
+				Namespace:      testNamespace,
+				Name:           testServiceName,
+				NamespacedName: testNamespace + "/" + testServiceName,
+			},
+		},
+	}
+}
+
+// newFakeClient returns a fake client indexing the EndpointSliceExports as the hub controller manager does.
+func newFakeClient(t *testing.T, objs ...client.Object) client.Client {
+	return fake.NewClientBuilder().
+		WithScheme(testScheme(t)).
+		WithObjects(objs...).
+		WithIndex(&fleetnetv1alpha1.EndpointSliceExport{}, endpointSliceExportOwnerSvcNamespacedNameFieldKey, func(o client.Object) []string {
+			return []string{o.(*fleetnetv1alpha1.EndpointSliceExport).Spec.OwnerServiceReference.NamespacedName}
+		}).
+		Build()
+}
+
+func memberCluster(clusterID string, heartbeat time.Time) *clusterv1beta1.MemberCluster {
+	return &clusterv1beta1.MemberCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: clusterID},
+		Status: clusterv1beta1.MemberClusterStatus{
+			AgentStatus: []clusterv1beta1.AgentStatus{
+				{
+					Type:                  clusterv1beta1.ServiceExportImportAgent,
+					LastReceivedHeartbeat: metav1.NewTime(heartbeat),
+				},
+			},
+		},
+	}
+}
+
+// TestEvaluate tests the Evaluator.Evaluate method.
+func TestEvaluate(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name      string
+		objs      []client.Object
+		unhealthy bool
+		want      Result
+	}{
+		{
+			name: "healthy export",
+			objs: []client.Object{endpointSliceExport(testClusterID, true), endpointSliceExport(otherClusterID, true)},
+		},
+		{
+			name: "export without ready endpoints",
+			objs: []client.Object{endpointSliceExport(testClusterID, false), endpointSliceExport(otherClusterID, true)},
+			want: Result{Unhealthy: true, Reason: ReasonNoReadyEndpoints},
+		},
+		{
+			name: "member cluster not reporting",
+			objs: []client.Object{
+				endpointSliceExport(testClusterID, true),
+				endpointSliceExport(otherClusterID, true),
+				memberCluster(testClusterID, now.Add(-time.Hour)),
+				memberCluster(otherClusterID, now),
+			},
+			want: Result{Unhealthy: true, Reason: ReasonClusterNotReporting},
+		},
+		{
+			name: "no other member cluster has ready endpoints",
+			objs: []client.Object{endpointSliceExport(testClusterID, false), endpointSliceExport(otherClusterID, false)},
+		},
+		{
+			name: "no other member cluster is reporting",
+			objs: []client.Object{
+				endpointSliceExport(testClusterID, false),
+				endpointSliceExport(otherClusterID, true),
+				memberCluster(otherClusterID, now.Add(-time.Hour)),
+			},
+		},
+		{
+			name:      "unhealthy export recovers",
+			objs:      []client.Object{endpointSliceExport(testClusterID, true), endpointSliceExport(otherClusterID, true)},
+			unhealthy: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := newFakeClient(t, tc.objs...)
+			e := &Evaluator{HeartbeatTimeout: time.Minute}
+			got, err := e.Evaluate(context.Background(), fakeClient, internalServiceExport(tc.unhealthy), now)
+			if err != nil {
+				t.Fatalf("Evaluate() = %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.want, got, cmpopts.IgnoreFields(Result{}, "Message")); diff != "" {
+				t.Errorf("Evaluate() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestEvaluate_Hysteresis tests that the Evaluator.Evaluate method reports a health change only after it persists
+// for the threshold.
+func TestEvaluate_Hysteresis(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fakeClient := newFakeClient(t, endpointSliceExport(testClusterID, false), endpointSliceExport(otherClusterID, true))
+	e := &Evaluator{UnhealthyThreshold: 30 * time.Second, HealthyThreshold: 2 * time.Minute}
+
+	testCases := []struct {
+		name      string
+		at        time.Duration
+		ready     bool
+		unhealthy bool
+		want      bool
+	}{
+		{
+			name: "failing export is not reported before the unhealthy threshold",
+			at:   0,
+		},
+		{
+			name: "failing export is still not reported",
+			at:   20 * time.Second,
+		},
+		{
+			name: "failing export is reported after the unhealthy threshold",
+			at:   30 * time.Second,
+			want: true,
+		},
+		{
+			name:      "passing export is not reported before the healthy threshold",
+			at:        time.Minute,
+			ready:     true,
+			unhealthy: true,
+			want:      true,
+		},
+		{
+			name:      "failing again resets the healthy threshold",
+			at:        2 * time.Minute,
+			unhealthy: true,
+			want:      true,
+		},
+		{
+			name:      "passing export is still not reported after the reset",
+			at:        3 * time.Minute,
+			ready:     true,
+			unhealthy: true,
+			want:      true,
+		},
+		{
+			name:      "passing export is reported after the healthy threshold",
+			at:        5 * time.Minute,
+			ready:     true,
+			unhealthy: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			endpointSliceExport := endpointSliceExport(testClusterID, tc.ready)
+			if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(endpointSliceExport), endpointSliceExport); err != nil {
+				t.Fatalf("EndpointSliceExport Get() got error %v, want no error", err)
+			}
+			endpointSliceExport.Spec.Endpoints[0].Conditions.Ready = ptr.To(tc.ready)
+			if err := fakeClient.Update(ctx, endpointSliceExport); err != nil {
+				t.Fatalf("EndpointSliceExport Update() got error %v, want no error", err)
+			}
+
+			got, err := e.Evaluate(ctx, fakeClient, internalServiceExport(tc.unhealthy), now.Add(tc.at))
+			if err != nil {
+				t.Fatalf("Evaluate() = %v, want no error", err)
+			}
+			if got.Unhealthy != tc.want {
+				t.Errorf("Evaluate().Unhealthy = %t, want %t", got.Unhealthy, tc.want)
+			}
+		})
+	}
+}
+
+// TestIsServiceUnhealthy tests the IsServiceUnhealthy function.
+func TestIsServiceUnhealthy(t *testing.T) {
+	testCases := []struct {
+		name string
+		objs []client.Object
+		want bool
+	}{
+		{
+			name: "unhealthy export",
+			objs: []client.Object{internalServiceExport(true)},
+			want: true,
+		},
+		{
+			name: "healthy export",
+			objs: []client.Object{internalServiceExport(false)},
+		},
+		{
+			name: "service not exported",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := newFakeClient(t, tc.objs...)
+			got, err := IsServiceUnhealthy(context.Background(), fakeClient, testClusterID+"-ns", testNamespace, testServiceName)
+			if err != nil {
+				t.Fatalf("IsServiceUnhealthy() = %v, want no error", err)
+			}
+			if got != tc.want {
+				t.Errorf("IsServiceUnhealthy() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
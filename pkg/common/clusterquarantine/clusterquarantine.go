@@ -0,0 +1,41 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package clusterquarantine features the hub-side quarantine of member clusters, which lets the fleet operator
+// exclude all the exports of a misbehaving member cluster from the fleet without touching the cluster itself.
+package clusterquarantine
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1beta1 "go.goms.io/fleet/apis/cluster/v1beta1"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+// IsQuarantined returns true if the MemberCluster with the given cluster ID is labeled as quarantined; a
+// MemberCluster which does not exist is not quarantined.
+func IsQuarantined(ctx context.Context, reader client.Reader, clusterID string) (bool, error) {
+	mc := &clusterv1beta1.MemberCluster{}
+	if err := reader.Get(ctx, types.NamespacedName{Name: clusterID}, mc); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	return HasQuarantineLabel(mc), nil
+}
+
+// HasQuarantineLabel returns true if the object carries the quarantine label with the value "true".
+func HasQuarantineLabel(obj client.Object) bool {
+	return obj.GetLabels()[objectmeta.MemberClusterLabelExportsQuarantined] == "true"
+}
+
+// IsQuarantinedExport returns true if the internalServiceExport is reported as quarantined in its status.
+func IsQuarantinedExport(internalServiceExport *fleetnetv1alpha1.InternalServiceExport) bool {
+	return meta.IsStatusConditionTrue(internalServiceExport.Status.Conditions, string(fleetnetv1alpha1.ServiceExportQuarantined))
+}
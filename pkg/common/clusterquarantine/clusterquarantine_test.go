@@ -0,0 +1,79 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package clusterquarantine
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1beta1 "go.goms.io/fleet/apis/cluster/v1beta1"
+
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+const clusterID = "member-1"
+
+// TestIsQuarantined tests the IsQuarantined function.
+func TestIsQuarantined(t *testing.T) {
+	testCases := []struct {
+		name          string
+		memberCluster *clusterv1beta1.MemberCluster
+		want          bool
+	}{
+		{
+			name: "cluster is quarantined",
+			memberCluster: &clusterv1beta1.MemberCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   clusterID,
+					Labels: map[string]string{objectmeta.MemberClusterLabelExportsQuarantined: "true"},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "quarantine label is not true",
+			memberCluster: &clusterv1beta1.MemberCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   clusterID,
+					Labels: map[string]string{objectmeta.MemberClusterLabelExportsQuarantined: "false"},
+				},
+			},
+		},
+		{
+			name: "cluster is not labeled",
+			memberCluster: &clusterv1beta1.MemberCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: clusterID},
+			},
+		},
+		{
+			name: "cluster not found",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := clusterv1beta1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			builder := fake.NewClientBuilder().WithScheme(scheme)
+			if tc.memberCluster != nil {
+				builder = builder.WithObjects(tc.memberCluster)
+			}
+			got, err := IsQuarantined(context.Background(), builder.Build(), clusterID)
+			if err != nil {
+				t.Fatalf("IsQuarantined() got error %v, want no error", err)
+			}
+			if got != tc.want {
+				t.Errorf("IsQuarantined() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package componentconfig features the configuration file of the controller managers, a versioned API which gathers
+// their feature gates, the settings of each controller and the logging settings, as an alternative to the growing
+// set of flags.
+//
+// The configuration file is applied to the flags: each of its settings sets the flag it stands for, unless the flag
+// is set on the command line, which always takes precedence, so that the existing deployments keep working as they
+// are and a setting can still be overridden by a flag.
+package componentconfig
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// featureGatesFlag is the flag of the feature gates without a flag of their own.
+const featureGatesFlag = "feature-gates"
+
+// The API version and kind of the configuration file.
+const (
+	GroupName  = "config.networking.fleet.azure.com"
+	Version    = "v1alpha1"
+	APIVersion = GroupName + "/" + Version
+	Kind       = "ControllerManagerConfiguration"
+)
+
+// ControllerManagerConfiguration is the configuration of a controller manager.
+type ControllerManagerConfiguration struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// FeatureGates enable or disable the features of the controller manager, keyed by the name of the feature, e.g.
+	// ClusterFailover for the --enable-cluster-failover flag; the features without a flag of their own are set with
+	// the --feature-gates flag.
+	FeatureGates map[string]bool `json:"featureGates,omitempty"`
+
+	// Controllers configure the controllers of the controller manager, keyed by the name of the controller, e.g.
+	// serviceexport for the --serviceexport-* flags.
+	Controllers map[string]ControllerConfiguration `json:"controllers,omitempty"`
+
+	// Logging configures the logs of the controller manager.
+	Logging LoggingConfiguration `json:"logging,omitempty"`
+
+	// Flags set the other flags of the controller manager, keyed by the name of the flag, e.g. hub-client-qps.
+	Flags map[string]string `json:"flags,omitempty"`
+}
+
+// ControllerConfiguration configures a controller.
+type ControllerConfiguration struct {
+	// Enabled is false if the controller is not set up at all.
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// MaxConcurrentReconciles is the maximum number of concurrent reconciles of the controller.
+	MaxConcurrentReconciles *int `json:"maxConcurrentReconciles,omitempty"`
+
+	// RateLimiter configures the rate limiter of the work queue of the controller.
+	RateLimiter RateLimiterConfiguration `json:"rateLimiter,omitempty"`
+}
+
+// RateLimiterConfiguration configures the rate limiter of the work queue of a controller.
+type RateLimiterConfiguration struct {
+	// BaseDelay and MaxDelay bound the exponential backoff of the requests which fail to reconcile.
+	BaseDelay *metav1.Duration `json:"baseDelay,omitempty"`
+	MaxDelay  *metav1.Duration `json:"maxDelay,omitempty"`
+
+	// QPS and BucketSize configure the token bucket which limits the overall rate of the requests.
+	QPS        *float64 `json:"qps,omitempty"`
+	BucketSize *int     `json:"bucketSize,omitempty"`
+}
+
+// LoggingConfiguration configures the logs of a controller manager.
+type LoggingConfiguration struct {
+	// Format is the format of the logs, either text or json.
+	Format string `json:"format,omitempty"`
+
+	// Verbosity is the verbosity of the logs, as set by the klog -v flag.
+	Verbosity *int `json:"verbosity,omitempty"`
+
+	// VModule is the per-file verbosity of the logs, as set by the klog -vmodule flag.
+	VModule string `json:"vmodule,omitempty"`
+}
+
+// Load reads the configuration file at the given path; unknown fields are rejected, so that a misspelled setting is
+// not silently ignored.
+func Load(path string) (*ControllerManagerConfiguration, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the configuration file: %w", err)
+	}
+	cfg := &ControllerManagerConfiguration{}
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse the configuration file %s: %w", path, err)
+	}
+	if cfg.APIVersion != APIVersion || cfg.Kind != Kind {
+		return nil, fmt.Errorf("unsupported configuration %s %s in %s, want %s %s", cfg.APIVersion, cfg.Kind, path, APIVersion, Kind)
+	}
+	return cfg, nil
+}
+
+// Apply sets the flags of the flag set per the configuration, except for the flags set on the command line; the
+// feature gates are mapped to the flags by the given names of the features, and the other feature gates are set with
+// the --feature-gates flag. It returns the names of the flags overridden by the command line.
+func (c *ControllerManagerConfiguration) Apply(fs *flag.FlagSet, featureGates map[string]string) ([]string, error) {
+	values, err := c.flagValues(featureGates, fs.Lookup(featureGatesFlag) != nil)
+	if err != nil {
+		return nil, err
+	}
+
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var overridden []string
+	for _, name := range names {
+		if fs.Lookup(name) == nil {
+			return nil, fmt.Errorf("unknown flag %q", name)
+		}
+		if set[name] {
+			overridden = append(overridden, name)
+			continue
+		}
+		if err := fs.Set(name, values[name]); err != nil {
+			return nil, fmt.Errorf("invalid value %q of flag %q: %w", values[name], name, err)
+		}
+	}
+	return overridden, nil
+}
+
+// flagValues returns the values of the flags the configuration stands for, keyed by the name of the flag.
+func (c *ControllerManagerConfiguration) flagValues(featureGates map[string]string, hasFeatureGatesFlag bool) (map[string]string, error) {
+	values := make(map[string]string, len(c.Flags))
+	for name, value := range c.Flags {
+		values[name] = value
+	}
+
+	var gates []string
+	for feature, enabled := range c.FeatureGates {
+		name, ok := featureGates[feature]
+		switch {
+		case ok:
+			values[name] = strconv.FormatBool(enabled)
+		case hasFeatureGatesFlag:
+			gates = append(gates, fmt.Sprintf("%s=%t", feature, enabled))
+		default:
+			return nil, fmt.Errorf("unknown feature gate %q", feature)
+		}
+	}
+	if len(gates) > 0 {
+		sort.Strings(gates)
+		values[featureGatesFlag] = strings.Join(gates, ",")
+	}
+
+	for controller, cc := range c.Controllers {
+		if cc.Enabled != nil {
+			values[controller+"-enabled"] = strconv.FormatBool(*cc.Enabled)
+		}
+		if cc.MaxConcurrentReconciles != nil {
+			values[controller+"-max-concurrent-reconciles"] = strconv.Itoa(*cc.MaxConcurrentReconciles)
+		}
+		if cc.RateLimiter.BaseDelay != nil {
+			values[controller+"-rate-limiter-base-delay"] = cc.RateLimiter.BaseDelay.Duration.String()
+		}
+		if cc.RateLimiter.MaxDelay != nil {
+			values[controller+"-rate-limiter-max-delay"] = cc.RateLimiter.MaxDelay.Duration.String()
+		}
+		if cc.RateLimiter.QPS != nil {
+			values[controller+"-rate-limiter-qps"] = strconv.FormatFloat(*cc.RateLimiter.QPS, 'g', -1, 64)
+		}
+		if cc.RateLimiter.BucketSize != nil {
+			values[controller+"-rate-limiter-bucket-size"] = strconv.Itoa(*cc.RateLimiter.BucketSize)
+		}
+	}
+
+	if c.Logging.Format != "" {
+		values["logging-format"] = c.Logging.Format
+	}
+	if c.Logging.Verbosity != nil {
+		values["v"] = strconv.Itoa(*c.Logging.Verbosity)
+	}
+	if c.Logging.VModule != "" {
+		values["vmodule"] = c.Logging.VModule
+	}
+	return values, nil
+}
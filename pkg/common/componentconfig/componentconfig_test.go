@@ -0,0 +1,207 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package componentconfig
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"go.goms.io/fleet-networking/pkg/common/features"
+)
+
+var testFeatureGates = map[string]string{
+	"ClusterFailover": "enable-cluster-failover",
+}
+
+const testConfig = `
+apiVersion: config.networking.fleet.azure.com/v1alpha1
+kind: ControllerManagerConfiguration
+featureGates:
+  ClusterFailover: true
+  HeadlessServiceExport: false
+controllers:
+  serviceimport:
+    enabled: false
+    maxConcurrentReconciles: 4
+    rateLimiter:
+      baseDelay: 10ms
+      maxDelay: 5m
+      qps: 2.5
+      bucketSize: 50
+logging:
+  format: json
+  verbosity: 2
+flags:
+  client-qps: "100"
+`
+
+// newTestFlagSet returns a flag set with the flags set by testConfig.
+func newTestFlagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Bool("enable-cluster-failover", false, "")
+	features.NewGate(map[features.Feature]features.Spec{
+		features.HeadlessServiceExport: {Stage: features.Beta, Default: true},
+	}).AddFlag(fs)
+	fs.Bool("serviceimport-enabled", true, "")
+	fs.Int("serviceimport-max-concurrent-reconciles", 1, "")
+	fs.Duration("serviceimport-rate-limiter-base-delay", 5*time.Millisecond, "")
+	fs.Duration("serviceimport-rate-limiter-max-delay", 1000*time.Second, "")
+	fs.Float64("serviceimport-rate-limiter-qps", 10, "")
+	fs.Int("serviceimport-rate-limiter-bucket-size", 100, "")
+	fs.String("logging-format", "text", "")
+	fs.Int("v", 0, "")
+	fs.String("vmodule", "", "")
+	fs.Float64("client-qps", 20, "")
+	return fs
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile() = %v", err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{
+			name:    "valid",
+			content: testConfig,
+		},
+		{
+			name:    "unsupported version",
+			content: "apiVersion: config.networking.fleet.azure.com/v1\nkind: ControllerManagerConfiguration\n",
+			wantErr: true,
+		},
+		{
+			name:    "unknown field",
+			content: "apiVersion: config.networking.fleet.azure.com/v1alpha1\nkind: ControllerManagerConfiguration\nfeatures: {}\n",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, tc.content))
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Load() got error %v, want error %t", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	testCases := []struct {
+		name           string
+		args           []string
+		want           map[string]string
+		wantOverridden []string
+	}{
+		{
+			name: "no flags on the command line",
+			want: map[string]string{
+				"enable-cluster-failover":                 "true",
+				"feature-gates":                           "HeadlessServiceExport=false",
+				"serviceimport-enabled":                   "false",
+				"serviceimport-max-concurrent-reconciles": "4",
+				"serviceimport-rate-limiter-base-delay":   "10ms",
+				"serviceimport-rate-limiter-max-delay":    "5m0s",
+				"serviceimport-rate-limiter-qps":          "2.5",
+				"serviceimport-rate-limiter-bucket-size":  "50",
+				"logging-format":                          "json",
+				"v":                                       "2",
+				"vmodule":                                 "",
+				"client-qps":                              "100",
+			},
+		},
+		{
+			name: "flags on the command line take precedence",
+			args: []string{"--enable-cluster-failover=false", "--v=4"},
+			want: map[string]string{
+				"enable-cluster-failover":                 "false",
+				"feature-gates":                           "HeadlessServiceExport=false",
+				"serviceimport-enabled":                   "false",
+				"serviceimport-max-concurrent-reconciles": "4",
+				"serviceimport-rate-limiter-base-delay":   "10ms",
+				"serviceimport-rate-limiter-max-delay":    "5m0s",
+				"serviceimport-rate-limiter-qps":          "2.5",
+				"serviceimport-rate-limiter-bucket-size":  "50",
+				"logging-format":                          "json",
+				"v":                                       "4",
+				"vmodule":                                 "",
+				"client-qps":                              "100",
+			},
+			wantOverridden: []string{"enable-cluster-failover", "v"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := Load(writeConfig(t, testConfig))
+			if err != nil {
+				t.Fatalf("Load() = %v", err)
+			}
+			fs := newTestFlagSet()
+			if err := fs.Parse(tc.args); err != nil {
+				t.Fatalf("Parse() = %v", err)
+			}
+			overridden, err := cfg.Apply(fs, testFeatureGates)
+			if err != nil {
+				t.Fatalf("Apply() = %v", err)
+			}
+			if diff := cmp.Diff(tc.wantOverridden, overridden); diff != "" {
+				t.Errorf("Apply() overridden flags mismatch (-want, +got):\n%s", diff)
+			}
+			got := map[string]string{}
+			fs.VisitAll(func(f *flag.Flag) {
+				got[f.Name] = f.Value.String()
+			})
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Apply() flags mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestApply_Invalid(t *testing.T) {
+	testCases := []struct {
+		name string
+		cfg  ControllerManagerConfiguration
+	}{
+		{
+			name: "unknown feature gate",
+			cfg:  ControllerManagerConfiguration{FeatureGates: map[string]bool{"Teleport": true}},
+		},
+		{
+			name: "unknown controller",
+			cfg:  ControllerManagerConfiguration{Controllers: map[string]ControllerConfiguration{"teleport": {MaxConcurrentReconciles: new(int)}}},
+		},
+		{
+			name: "unknown flag",
+			cfg:  ControllerManagerConfiguration{Flags: map[string]string{"teleport": "true"}},
+		},
+		{
+			name: "invalid value",
+			cfg:  ControllerManagerConfiguration{Flags: map[string]string{"client-qps": "fast"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := tc.cfg.Apply(newTestFlagSet(), testFeatureGates); err == nil {
+				t.Errorf("Apply() = nil, want error")
+			}
+		})
+	}
+}
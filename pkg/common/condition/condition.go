@@ -65,17 +65,23 @@ func UnconflictedServiceExportConflictCondition(internalServiceExport fleetnetv1
 	}
 }
 
-// ConflictedServiceExportConflictCondition returns the desired conflicted condition.
-func ConflictedServiceExportConflictCondition(internalServiceExport fleetnetv1alpha1.InternalServiceExport) metav1.Condition {
+// ConflictedServiceExportConflictCondition returns the desired conflicted condition; the message names the winning
+// cluster and the conflict resolution policy if the resolution is reported.
+func ConflictedServiceExportConflictCondition(internalServiceExport fleetnetv1alpha1.InternalServiceExport,
+	resolution *fleetnetv1alpha1.ConflictResolutionStatus) metav1.Condition {
 	svcName := types.NamespacedName{
 		Namespace: internalServiceExport.Spec.ServiceReference.Namespace,
 		Name:      internalServiceExport.Spec.ServiceReference.Name,
 	}
+	message := fmt.Sprintf("service %s is in conflict with other exported services", svcName)
+	if resolution != nil {
+		message = fmt.Sprintf("%s; the spec exported from cluster %s wins by the %s policy", message, resolution.WinningCluster, resolution.Policy)
+	}
 	return metav1.Condition{
 		Type:               string(fleetnetv1alpha1.ServiceExportConflict),
 		Status:             metav1.ConditionTrue,
 		Reason:             conditionReasonConflictFound,
 		ObservedGeneration: internalServiceExport.Spec.ServiceReference.Generation, // use the generation of the original object
-		Message:            fmt.Sprintf("service %s is in conflict with other exported services", svcName),
+		Message:            message,
 	}
 }
@@ -305,15 +305,42 @@ func TestConflictedServiceExportConflictCondition(t *testing.T) {
 			},
 		},
 	}
-	want := metav1.Condition{
-		Type:               string(fleetnetv1alpha1.ServiceExportConflict),
-		Status:             metav1.ConditionTrue,
-		Reason:             conditionReasonConflictFound,
-		ObservedGeneration: 123,
-		Message:            "service test-ns/test-svc is in conflict with other exported services",
+	testCases := []struct {
+		name       string
+		resolution *fleetnetv1alpha1.ConflictResolutionStatus
+		want       metav1.Condition
+	}{
+		{
+			name: "resolution is not reported",
+			want: metav1.Condition{
+				Type:               string(fleetnetv1alpha1.ServiceExportConflict),
+				Status:             metav1.ConditionTrue,
+				Reason:             conditionReasonConflictFound,
+				ObservedGeneration: 123,
+				Message:            "service test-ns/test-svc is in conflict with other exported services",
+			},
+		},
+		{
+			name: "resolution is reported",
+			resolution: &fleetnetv1alpha1.ConflictResolutionStatus{
+				Policy:         fleetnetv1alpha1.ClusterPriority,
+				WinningCluster: "member-2",
+			},
+			want: metav1.Condition{
+				Type:               string(fleetnetv1alpha1.ServiceExportConflict),
+				Status:             metav1.ConditionTrue,
+				Reason:             conditionReasonConflictFound,
+				ObservedGeneration: 123,
+				Message:            "service test-ns/test-svc is in conflict with other exported services; the spec exported from cluster member-2 wins by the ClusterPriority policy",
+			},
+		},
 	}
-	got := ConflictedServiceExportConflictCondition(input)
-	if diff := cmp.Diff(want, got); diff != "" {
-		t.Errorf("ConflictedServiceExportConflictCondition() mismatch (-want, +got):\n%s", diff)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := ConflictedServiceExportConflictCondition(input, tc.resolution)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("ConflictedServiceExportConflictCondition() mismatch (-want, +got):\n%s", diff)
+			}
+		})
 	}
 }
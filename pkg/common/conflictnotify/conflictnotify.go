@@ -0,0 +1,152 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package conflictnotify features the notification of new service export conflicts in the fleet, so that platform
+// teams learn about them proactively instead of polling the conditions of the exports.
+package conflictnotify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// notifyTimeout is the time allowed for delivering one notification to a sink.
+	notifyTimeout = 10 * time.Second
+)
+
+// Conflict describes an export conflict of a service.
+type Conflict struct {
+	// Namespace is the namespace of the exported service.
+	Namespace string `json:"namespace"`
+	// Service is the name of the exported service.
+	Service string `json:"service"`
+	// Cluster is the ID of the member cluster whose export is in conflict.
+	Cluster string `json:"cluster"`
+	// ConflictingClusters are the IDs of the member clusters whose exports of the service are in use, and which
+	// the export of Cluster conflicts with.
+	ConflictingClusters []string `json:"conflictingClusters"`
+}
+
+// Sink delivers the notifications of export conflicts, e.g. to a webhook.
+type Sink interface {
+	// Notify delivers the notification of a new export conflict.
+	Notify(ctx context.Context, conflict Conflict) error
+}
+
+// Notifier notifies a sink of new export conflicts; an unchanged conflict is only notified once, until it is
+// resolved.
+//
+// Notifications are delivered in the background, so that a slow or failing sink never blocks the caller; a
+// failure is logged and the notification is not retried. A nil Notifier notifies nothing.
+type Notifier struct {
+	sink Sink
+
+	mu sync.Mutex
+	// notified are the keys of the conflicts which have been notified and are not resolved yet.
+	notified map[string]bool
+	// wg tracks the notifications in flight.
+	wg sync.WaitGroup
+}
+
+// NewNotifier returns a Notifier which delivers the notifications to a sink.
+func NewNotifier(sink Sink) *Notifier {
+	return &Notifier{
+		sink:     sink,
+		notified: map[string]bool{},
+	}
+}
+
+func conflictKey(namespace, service, cluster string) string {
+	return fmt.Sprintf("%s/%s/%s", namespace, service, cluster)
+}
+
+// Conflicted notifies the sink of a conflict, unless the same conflict has been notified and not resolved since.
+func (n *Notifier) Conflicted(conflict Conflict) {
+	if n == nil {
+		return
+	}
+	key := conflictKey(conflict.Namespace, conflict.Service, conflict.Cluster)
+	n.mu.Lock()
+	if n.notified[key] {
+		n.mu.Unlock()
+		return
+	}
+	n.notified[key] = true
+	n.mu.Unlock()
+
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		defer cancel()
+		if err := n.sink.Notify(ctx, conflict); err != nil {
+			klog.ErrorS(err, "Failed to notify the export conflict", "service", klog.KRef(conflict.Namespace, conflict.Service), "cluster", conflict.Cluster)
+			return
+		}
+		klog.V(2).InfoS("Notified the export conflict", "service", klog.KRef(conflict.Namespace, conflict.Service), "cluster", conflict.Cluster)
+	}()
+}
+
+// Resolved forgets the conflict of the export of a service from a member cluster, so that the conflict is
+// notified again should it arise again.
+func (n *Notifier) Resolved(namespace, service, cluster string) {
+	if n == nil {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.notified, conflictKey(namespace, service, cluster))
+}
+
+// Wait waits for the notifications in flight to complete.
+func (n *Notifier) Wait() {
+	if n == nil {
+		return
+	}
+	n.wg.Wait()
+}
+
+// WebhookSink delivers the notifications by POSTing them as JSON to a webhook URL, e.g. an incoming webhook of
+// a chat service.
+type WebhookSink struct {
+	// URL is the URL of the webhook.
+	URL string
+	// Client is the HTTP client for calling the webhook; http.DefaultClient is used if nil.
+	Client *http.Client
+}
+
+// Notify POSTs the conflict as JSON to the webhook; any response status other than 2xx is an error.
+func (s *WebhookSink) Notify(ctx context.Context, conflict Conflict) error {
+	body, err := json.Marshal(conflict)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the conflict: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build the webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call the webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("the webhook responded with status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package conflictnotify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var testConflict = Conflict{
+	Namespace:           "work",
+	Service:             "app",
+	Cluster:             "member-2",
+	ConflictingClusters: []string{"member-1", "member-3"},
+}
+
+// fakeSink records the conflicts it is notified of.
+type fakeSink struct {
+	mu        sync.Mutex
+	conflicts []Conflict
+}
+
+func (s *fakeSink) Notify(_ context.Context, conflict Conflict) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.conflicts = append(s.conflicts, conflict)
+	return nil
+}
+
+// TestWebhookSink_Notify tests that the webhook sink POSTs the conflict as JSON.
+func TestWebhookSink_Notify(t *testing.T) {
+	testCases := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{
+			name:   "webhook accepts the notification",
+			status: http.StatusOK,
+		},
+		{
+			name:    "webhook fails",
+			status:  http.StatusInternalServerError,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotMethod, gotContentType string
+			var gotBody []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotMethod = r.Method
+				gotContentType = r.Header.Get("Content-Type")
+				gotBody, _ = io.ReadAll(r.Body)
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+
+			sink := &WebhookSink{URL: server.URL}
+			if err := sink.Notify(context.Background(), testConflict); (err != nil) != tc.wantErr {
+				t.Fatalf("Notify() got error %v, want error %t", err, tc.wantErr)
+			}
+			if gotMethod != http.MethodPost {
+				t.Errorf("webhook request method = %s, want %s", gotMethod, http.MethodPost)
+			}
+			if gotContentType != "application/json" {
+				t.Errorf("webhook request content type = %s, want application/json", gotContentType)
+			}
+			var got map[string]interface{}
+			if err := json.Unmarshal(gotBody, &got); err != nil {
+				t.Fatalf("failed to unmarshal the webhook payload %q: %v", gotBody, err)
+			}
+			want := map[string]interface{}{
+				"namespace":           "work",
+				"service":             "app",
+				"cluster":             "member-2",
+				"conflictingClusters": []interface{}{"member-1", "member-3"},
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("webhook payload mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestNotifier_Dedup tests that an unchanged conflict is notified only once until it is resolved.
+func TestNotifier_Dedup(t *testing.T) {
+	sink := &fakeSink{}
+	n := NewNotifier(sink)
+
+	n.Conflicted(testConflict)
+	n.Wait()
+	// The same conflict is detected again, e.g. with more clusters in use.
+	again := testConflict
+	again.ConflictingClusters = []string{"member-1", "member-3", "member-4"}
+	n.Conflicted(again)
+	n.Wait()
+	// Another cluster runs into a conflict of the same service.
+	other := testConflict
+	other.Cluster = "member-5"
+	n.Conflicted(other)
+	n.Wait()
+	// The conflict is resolved and then arises again.
+	n.Resolved(testConflict.Namespace, testConflict.Service, testConflict.Cluster)
+	n.Conflicted(again)
+	n.Wait()
+
+	want := []Conflict{testConflict, other, again}
+	if diff := cmp.Diff(want, sink.conflicts); diff != "" {
+		t.Errorf("notified conflicts mismatch (-want, +got):\n%s", diff)
+	}
+
+	var nilNotifier *Notifier
+	nilNotifier.Conflicted(testConflict)
+	nilNotifier.Resolved(testConflict.Namespace, testConflict.Service, testConflict.Cluster)
+	nilNotifier.Wait()
+}
@@ -0,0 +1,132 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package conflictresolution features the conflict resolution policies of the hub, which decide whose spec a
+// ServiceImport takes when the Services exported from the member clusters are in conflict.
+package conflictresolution
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1beta1 "go.goms.io/fleet/apis/cluster/v1beta1"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+// Resolver ranks the exports of a Service by the conflict resolution policy of its ServiceImport.
+type Resolver struct {
+	// Reader reads the MemberClusters for the priorities of the clusters; the ClusterPriority policy treats all
+	// the clusters as the same priority if it is not set, e.g. when the MemberCluster API is not installed.
+	Reader client.Reader
+	// DefaultPolicy is the policy of the ServiceImports which do not override it; OldestExportWins is used if it
+	// is not set.
+	DefaultPolicy fleetnetv1alpha1.ConflictResolutionPolicy
+}
+
+// IsValidPolicy returns true if the policy is one of the supported conflict resolution policies.
+func IsValidPolicy(policy fleetnetv1alpha1.ConflictResolutionPolicy) bool {
+	switch policy {
+	case fleetnetv1alpha1.OldestExportWins, fleetnetv1alpha1.ClusterPriority, fleetnetv1alpha1.ManualConflictResolution:
+		return true
+	}
+	return false
+}
+
+// PolicyOf returns the conflict resolution policy of the ServiceImport; an invalid policy set on the ServiceImport
+// is ignored.
+func (r *Resolver) PolicyOf(serviceImport *fleetnetv1alpha1.ServiceImport) fleetnetv1alpha1.ConflictResolutionPolicy {
+	if policy := fleetnetv1alpha1.ConflictResolutionPolicy(serviceImport.Annotations[objectmeta.ServiceImportAnnotationConflictResolutionPolicy]); IsValidPolicy(policy) {
+		return policy
+	}
+	if r != nil && IsValidPolicy(r.DefaultPolicy) {
+		return r.DefaultPolicy
+	}
+	return fleetnetv1alpha1.OldestExportWins
+}
+
+// Sort sorts the internalServiceExports of the ServiceImport so that the export whose spec wins comes first, and
+// returns the policy used; exports which rank the same keep their order.
+func (r *Resolver) Sort(ctx context.Context, serviceImport *fleetnetv1alpha1.ServiceImport,
+	internalServiceExports []*fleetnetv1alpha1.InternalServiceExport) (fleetnetv1alpha1.ConflictResolutionPolicy, error) {
+	policy := r.PolicyOf(serviceImport)
+	priorities := make(map[string]int64)
+	if policy == fleetnetv1alpha1.ClusterPriority {
+		for _, export := range internalServiceExports {
+			clusterID := export.Spec.ServiceReference.ClusterID
+			if _, ok := priorities[clusterID]; ok {
+				continue
+			}
+			priority, err := r.priorityOf(ctx, clusterID)
+			if err != nil {
+				return "", err
+			}
+			priorities[clusterID] = priority
+		}
+	}
+	winner := serviceImport.Annotations[objectmeta.ServiceImportAnnotationConflictResolutionWinner]
+	sort.SliceStable(internalServiceExports, func(i, j int) bool {
+		a, b := internalServiceExports[i].Spec.ServiceReference, internalServiceExports[j].Spec.ServiceReference
+		switch policy {
+		case fleetnetv1alpha1.ClusterPriority:
+			if priorities[a.ClusterID] != priorities[b.ClusterID] {
+				return priorities[a.ClusterID] > priorities[b.ClusterID]
+			}
+		case fleetnetv1alpha1.ManualConflictResolution:
+			if (a.ClusterID == winner) != (b.ClusterID == winner) {
+				return a.ClusterID == winner
+			}
+		}
+		return a.ExportedSince.Before(&b.ExportedSince)
+	})
+	return policy, nil
+}
+
+// Preempts returns true if the export from the challenger cluster outranks the winning export of the
+// ServiceImport, in which case the spec of the ServiceImport must be resolved again. Under the OldestExportWins
+// policy an export never preempts the winning one, which was the oldest export when the spec was resolved.
+func (r *Resolver) Preempts(ctx context.Context, serviceImport *fleetnetv1alpha1.ServiceImport, challenger string) (bool, error) {
+	resolution := serviceImport.Status.ConflictResolution
+	if resolution == nil || resolution.WinningCluster == challenger {
+		return false, nil
+	}
+	switch r.PolicyOf(serviceImport) {
+	case fleetnetv1alpha1.ClusterPriority:
+		challengerPriority, err := r.priorityOf(ctx, challenger)
+		if err != nil {
+			return false, err
+		}
+		winnerPriority, err := r.priorityOf(ctx, resolution.WinningCluster)
+		if err != nil {
+			return false, err
+		}
+		return challengerPriority > winnerPriority, nil
+	case fleetnetv1alpha1.ManualConflictResolution:
+		return serviceImport.Annotations[objectmeta.ServiceImportAnnotationConflictResolutionWinner] == challenger, nil
+	}
+	return false, nil
+}
+
+// priorityOf returns the export priority of the member cluster; a MemberCluster which does not exist or has no
+// valid priority has the priority 0.
+func (r *Resolver) priorityOf(ctx context.Context, clusterID string) (int64, error) {
+	if r == nil || r.Reader == nil {
+		return 0, nil
+	}
+	mc := &clusterv1beta1.MemberCluster{}
+	if err := r.Reader.Get(ctx, types.NamespacedName{Name: clusterID}, mc); err != nil {
+		return 0, client.IgnoreNotFound(err)
+	}
+	priority, err := strconv.ParseInt(mc.Labels[objectmeta.MemberClusterLabelExportPriority], 10, 64)
+	if err != nil {
+		return 0, nil
+	}
+	return priority, nil
+}
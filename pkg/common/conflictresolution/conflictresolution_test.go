@@ -0,0 +1,139 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package conflictresolution
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1beta1 "go.goms.io/fleet/apis/cluster/v1beta1"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+// TestPolicyOf tests the Resolver.PolicyOf method.
+func TestPolicyOf(t *testing.T) {
+	testCases := []struct {
+		name     string
+		resolver *Resolver
+		policy   string
+		want     fleetnetv1alpha1.ConflictResolutionPolicy
+	}{
+		{
+			name: "no resolver",
+			want: fleetnetv1alpha1.OldestExportWins,
+		},
+		{
+			name:     "default policy of the resolver",
+			resolver: &Resolver{DefaultPolicy: fleetnetv1alpha1.ClusterPriority},
+			want:     fleetnetv1alpha1.ClusterPriority,
+		},
+		{
+			name:     "policy of the serviceImport overrides the default policy",
+			resolver: &Resolver{DefaultPolicy: fleetnetv1alpha1.ClusterPriority},
+			policy:   string(fleetnetv1alpha1.ManualConflictResolution),
+			want:     fleetnetv1alpha1.ManualConflictResolution,
+		},
+		{
+			name:     "invalid policy of the serviceImport is ignored",
+			resolver: &Resolver{DefaultPolicy: fleetnetv1alpha1.ClusterPriority},
+			policy:   "NewestExportWins",
+			want:     fleetnetv1alpha1.ClusterPriority,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			serviceImport := &fleetnetv1alpha1.ServiceImport{}
+			if tc.policy != "" {
+				serviceImport.Annotations = map[string]string{objectmeta.ServiceImportAnnotationConflictResolutionPolicy: tc.policy}
+			}
+			if got := tc.resolver.PolicyOf(serviceImport); got != tc.want {
+				t.Errorf("PolicyOf() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestSort tests the Resolver.Sort method.
+func TestSort(t *testing.T) {
+	now := time.Now()
+	export := func(cluster string, exportedSince time.Time) *fleetnetv1alpha1.InternalServiceExport {
+		return &fleetnetv1alpha1.InternalServiceExport{
+			Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+				ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+					ClusterID:     cluster,
+					ExportedSince: metav1.NewTime(exportedSince),
+				},
+			},
+		}
+	}
+	memberCluster := func(name, priority string) *clusterv1beta1.MemberCluster {
+		return &clusterv1beta1.MemberCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{objectmeta.MemberClusterLabelExportPriority: priority},
+			},
+		}
+	}
+	testCases := []struct {
+		name           string
+		annotations    map[string]string
+		memberClusters []client.Object
+		want           []string
+	}{
+		{
+			name: "oldest export first",
+			want: []string{"member-3", "member-1", "member-2"},
+		},
+		{
+			name:           "highest priority first",
+			annotations:    map[string]string{objectmeta.ServiceImportAnnotationConflictResolutionPolicy: string(fleetnetv1alpha1.ClusterPriority)},
+			memberClusters: []client.Object{memberCluster("member-2", "10"), memberCluster("member-3", "-1")},
+			want:           []string{"member-2", "member-1", "member-3"},
+		},
+		{
+			name: "cluster named by the operator first",
+			annotations: map[string]string{
+				objectmeta.ServiceImportAnnotationConflictResolutionPolicy: string(fleetnetv1alpha1.ManualConflictResolution),
+				objectmeta.ServiceImportAnnotationConflictResolutionWinner: "member-2",
+			},
+			want: []string{"member-2", "member-3", "member-1"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := clusterv1beta1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			r := &Resolver{Reader: fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.memberClusters...).Build()}
+			exports := []*fleetnetv1alpha1.InternalServiceExport{
+				export("member-1", now.Add(-time.Hour)),
+				export("member-2", now),
+				export("member-3", now.Add(-2*time.Hour)),
+			}
+			serviceImport := &fleetnetv1alpha1.ServiceImport{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+			if _, err := r.Sort(context.Background(), serviceImport, exports); err != nil {
+				t.Fatalf("Sort() = %v, want no error", err)
+			}
+			got := make([]string, 0, len(exports))
+			for _, e := range exports {
+				got = append(got, e.Spec.ServiceReference.ClusterID)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Sort() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package controlleroptions features the flags which tune the throughput of each controller of a controller manager,
+// i.e. the number of concurrent reconciles and the rate limiter of its work queue, so that large fleets can be served
+// without changing the code; each controller can also be disabled.
+package controlleroptions
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// The defaults are the ones of workqueue.DefaultTypedControllerRateLimiter.
+const (
+	defaultMaxConcurrentReconciles = 1
+	defaultBaseDelay               = 5 * time.Millisecond
+	defaultMaxDelay                = 1000 * time.Second
+	defaultQPS                     = 10
+	defaultBucketSize              = 100
+)
+
+// Options tune the throughput of a controller.
+type Options struct {
+	// Enabled is false if the controller is not set up at all.
+	Enabled bool
+	// MaxConcurrentReconciles is the maximum number of concurrent reconciles.
+	MaxConcurrentReconciles int
+	// BaseDelay and MaxDelay bound the exponential backoff of the requests which fail to reconcile.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// QPS and BucketSize configure the token bucket which limits the overall rate of the requests.
+	QPS        float64
+	BucketSize int
+}
+
+// Set is the options of the controllers of a controller manager, keyed by the controller name.
+type Set map[string]*Options
+
+// AddFlags registers the flags of the options of each of the named controllers on the flag set, e.g.
+//
+//	--serviceexport-enabled
+//	--serviceexport-max-concurrent-reconciles
+//	--serviceexport-rate-limiter-base-delay
+//	--serviceexport-rate-limiter-max-delay
+//	--serviceexport-rate-limiter-qps
+//	--serviceexport-rate-limiter-bucket-size
+//
+// as well as the --controllers flag, which enables or disables the controllers by name, and returns the options the
+// flags are parsed into.
+func AddFlags(fs *flag.FlagSet, names ...string) Set {
+	set := make(Set, len(names))
+	for _, name := range names {
+		o := &Options{}
+		fs.BoolVar(&o.Enabled, name+"-enabled", true,
+			fmt.Sprintf("If set to false, the %s controller is not set up.", name))
+		fs.IntVar(&o.MaxConcurrentReconciles, name+"-max-concurrent-reconciles", defaultMaxConcurrentReconciles,
+			fmt.Sprintf("The maximum number of concurrent reconciles of the %s controller.", name))
+		fs.DurationVar(&o.BaseDelay, name+"-rate-limiter-base-delay", defaultBaseDelay,
+			fmt.Sprintf("The base delay of the exponential backoff of the failed requests of the %s controller.", name))
+		fs.DurationVar(&o.MaxDelay, name+"-rate-limiter-max-delay", defaultMaxDelay,
+			fmt.Sprintf("The maximum delay of the exponential backoff of the failed requests of the %s controller.", name))
+		fs.Float64Var(&o.QPS, name+"-rate-limiter-qps", defaultQPS,
+			fmt.Sprintf("The average number of requests per second the %s controller is allowed to reconcile.", name))
+		fs.IntVar(&o.BucketSize, name+"-rate-limiter-bucket-size", defaultBucketSize,
+			fmt.Sprintf("The maximum number of requests the %s controller is allowed to reconcile in a burst.", name))
+		set[name] = o
+	}
+	fs.Func("controllers", fmt.Sprintf("A comma-separated list of the controllers to enable or disable: '*' enables all the controllers, "+
+		"'foo' enables the controller named foo, and '-foo' disables it, e.g. '*,-serviceexport'. All the controllers are enabled by default; "+
+		"the controllers are %s.", strings.Join(names, ", ")), set.setEnabled)
+	return set
+}
+
+// setEnabled enables or disables the controllers per the comma-separated list of their names.
+func (s Set) setEnabled(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		name, disabled := strings.CutPrefix(item, "-")
+		switch {
+		case item == "":
+		case item == "*":
+			for _, o := range s {
+				o.Enabled = true
+			}
+		case s[name] == nil:
+			return fmt.Errorf("unknown controller %q", name)
+		default:
+			s[name].Enabled = !disabled
+		}
+	}
+	return nil
+}
+
+// Validate returns an error if the options of any controller are invalid.
+func (s Set) Validate() error {
+	for name, o := range s {
+		switch {
+		case o.MaxConcurrentReconciles < 1:
+			return fmt.Errorf("the maximum number of concurrent reconciles of the %s controller must be positive, got %d", name, o.MaxConcurrentReconciles)
+		case o.BaseDelay <= 0 || o.MaxDelay < o.BaseDelay:
+			return fmt.Errorf("the backoff delays of the %s controller must satisfy 0 < base delay <= max delay, got %v and %v", name, o.BaseDelay, o.MaxDelay)
+		case o.QPS <= 0 || o.BucketSize < 1:
+			return fmt.Errorf("the QPS and the bucket size of the %s controller must be positive, got %v and %d", name, o.QPS, o.BucketSize)
+		}
+	}
+	return nil
+}
+
+// Enabled returns false if the named controller is disabled; the controllers without options are always enabled.
+func (s Set) Enabled(name string) bool {
+	o, ok := s[name]
+	return !ok || o.Enabled
+}
+
+// For returns the controller options of the named controller; the defaults of controller-runtime apply to the
+// controllers without options.
+func (s Set) For(name string) controller.Options {
+	o, ok := s[name]
+	if !ok {
+		return controller.Options{}
+	}
+	return controller.Options{
+		MaxConcurrentReconciles: o.MaxConcurrentReconciles,
+		RateLimiter: workqueue.NewTypedMaxOfRateLimiter(
+			workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](o.BaseDelay, o.MaxDelay),
+			&workqueue.TypedBucketRateLimiter[reconcile.Request]{Limiter: rate.NewLimiter(rate.Limit(o.QPS), o.BucketSize)},
+		),
+	}
+}
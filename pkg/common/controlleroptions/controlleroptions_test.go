@@ -0,0 +1,137 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package controlleroptions
+
+import (
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestAddFlags(t *testing.T) {
+	testCases := []struct {
+		name    string
+		args    []string
+		want    Set
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			want: Set{
+				"serviceexport": {Enabled: true, MaxConcurrentReconciles: 1, BaseDelay: 5 * time.Millisecond, MaxDelay: 1000 * time.Second, QPS: 10, BucketSize: 100},
+				"serviceimport": {Enabled: true, MaxConcurrentReconciles: 1, BaseDelay: 5 * time.Millisecond, MaxDelay: 1000 * time.Second, QPS: 10, BucketSize: 100},
+			},
+		},
+		{
+			name: "one controller tuned",
+			args: []string{
+				"--serviceexport-max-concurrent-reconciles=8",
+				"--serviceexport-rate-limiter-base-delay=10ms",
+				"--serviceexport-rate-limiter-max-delay=5m",
+				"--serviceexport-rate-limiter-qps=50",
+				"--serviceexport-rate-limiter-bucket-size=500",
+			},
+			want: Set{
+				"serviceexport": {Enabled: true, MaxConcurrentReconciles: 8, BaseDelay: 10 * time.Millisecond, MaxDelay: 5 * time.Minute, QPS: 50, BucketSize: 500},
+				"serviceimport": {Enabled: true, MaxConcurrentReconciles: 1, BaseDelay: 5 * time.Millisecond, MaxDelay: 1000 * time.Second, QPS: 10, BucketSize: 100},
+			},
+		},
+		{
+			name: "one controller disabled",
+			args: []string{"--serviceimport-enabled=false"},
+			want: Set{
+				"serviceexport": {Enabled: true, MaxConcurrentReconciles: 1, BaseDelay: 5 * time.Millisecond, MaxDelay: 1000 * time.Second, QPS: 10, BucketSize: 100},
+				"serviceimport": {MaxConcurrentReconciles: 1, BaseDelay: 5 * time.Millisecond, MaxDelay: 1000 * time.Second, QPS: 10, BucketSize: 100},
+			},
+		},
+		{
+			name: "controllers list",
+			args: []string{"--serviceexport-enabled=false", "--controllers=*,-serviceimport"},
+			want: Set{
+				"serviceexport": {Enabled: true, MaxConcurrentReconciles: 1, BaseDelay: 5 * time.Millisecond, MaxDelay: 1000 * time.Second, QPS: 10, BucketSize: 100},
+				"serviceimport": {MaxConcurrentReconciles: 1, BaseDelay: 5 * time.Millisecond, MaxDelay: 1000 * time.Second, QPS: 10, BucketSize: 100},
+			},
+		},
+		{
+			name:    "no concurrency",
+			args:    []string{"--serviceimport-max-concurrent-reconciles=0"},
+			wantErr: true,
+		},
+		{
+			name:    "max delay below base delay",
+			args:    []string{"--serviceimport-rate-limiter-base-delay=1s", "--serviceimport-rate-limiter-max-delay=10ms"},
+			wantErr: true,
+		},
+		{
+			name:    "empty bucket",
+			args:    []string{"--serviceimport-rate-limiter-bucket-size=0"},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fs := flag.NewFlagSet(tc.name, flag.ContinueOnError)
+			got := AddFlags(fs, "serviceexport", "serviceimport")
+			if err := fs.Parse(tc.args); err != nil {
+				t.Fatalf("Parse() = %v, want nil", err)
+			}
+			err := got.Validate()
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Validate() got error %v, want error %t", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("AddFlags() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAddFlags_UnknownController(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	AddFlags(fs, "serviceexport")
+	if err := fs.Parse([]string{"--controllers=-teleport"}); err == nil {
+		t.Errorf("Parse() = nil, want error")
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	set := Set{
+		"serviceexport": {Enabled: true},
+		"serviceimport": {Enabled: false},
+	}
+	for name, want := range map[string]bool{"serviceexport": true, "serviceimport": false, "endpointslice": true} {
+		if got := set.Enabled(name); got != want {
+			t.Errorf("Enabled(%q) = %t, want %t", name, got, want)
+		}
+	}
+}
+
+func TestFor(t *testing.T) {
+	set := Set{
+		"serviceexport": {MaxConcurrentReconciles: 4, BaseDelay: time.Second, MaxDelay: time.Minute, QPS: 10, BucketSize: 100},
+	}
+
+	got := set.For("serviceexport")
+	if got.MaxConcurrentReconciles != 4 {
+		t.Errorf("For().MaxConcurrentReconciles = %d, want 4", got.MaxConcurrentReconciles)
+	}
+	req := reconcile.Request{}
+	for i, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		if delay := got.RateLimiter.When(req); delay != want {
+			t.Errorf("For().RateLimiter.When() #%d = %v, want %v", i, delay, want)
+		}
+	}
+
+	if got := set.For("serviceimport"); got.MaxConcurrentReconciles != 0 || got.RateLimiter != nil {
+		t.Errorf("For() of a controller without options = %+v, want the zero value", got)
+	}
+}
@@ -0,0 +1,43 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package defaulter
+
+import (
+	"k8s.io/utils/ptr"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+// SetDefaultsFrontDoorBackend sets the default values for FrontDoorBackend.
+func SetDefaultsFrontDoorBackend(obj *fleetnetv1alpha1.FrontDoorBackend) {
+	if obj.Spec.HealthProbe == nil {
+		obj.Spec.HealthProbe = &fleetnetv1alpha1.FrontDoorHealthProbe{}
+	}
+
+	if obj.Spec.HealthProbe.Path == nil {
+		obj.Spec.HealthProbe.Path = ptr.To("/")
+	}
+
+	if obj.Spec.HealthProbe.Protocol == nil {
+		obj.Spec.HealthProbe.Protocol = ptr.To(fleetnetv1alpha1.FrontDoorHealthProbeProtocolHTTP)
+	}
+
+	if obj.Spec.HealthProbe.RequestType == nil {
+		obj.Spec.HealthProbe.RequestType = ptr.To(fleetnetv1alpha1.FrontDoorHealthProbeRequestTypeHEAD)
+	}
+
+	if obj.Spec.HealthProbe.IntervalInSeconds == nil {
+		obj.Spec.HealthProbe.IntervalInSeconds = ptr.To(int32(100))
+	}
+
+	if obj.Spec.HTTPPort == nil {
+		obj.Spec.HTTPPort = ptr.To(int32(80))
+	}
+
+	if obj.Spec.HTTPSPort == nil {
+		obj.Spec.HTTPSPort = ptr.To(int32(443))
+	}
+}
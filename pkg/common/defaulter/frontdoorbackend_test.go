@@ -0,0 +1,78 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package defaulter
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/utils/ptr"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+func TestSetDefaultsFrontDoorBackend(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  *fleetnetv1alpha1.FrontDoorBackend
+		want *fleetnetv1alpha1.FrontDoorBackend
+	}{
+		{
+			name: "FrontDoorBackend with nil health probe and ports",
+			obj: &fleetnetv1alpha1.FrontDoorBackend{
+				Spec: fleetnetv1alpha1.FrontDoorBackendSpec{},
+			},
+			want: &fleetnetv1alpha1.FrontDoorBackend{
+				Spec: fleetnetv1alpha1.FrontDoorBackendSpec{
+					HealthProbe: &fleetnetv1alpha1.FrontDoorHealthProbe{
+						Path:              ptr.To("/"),
+						Protocol:          ptr.To(fleetnetv1alpha1.FrontDoorHealthProbeProtocolHTTP),
+						RequestType:       ptr.To(fleetnetv1alpha1.FrontDoorHealthProbeRequestTypeHEAD),
+						IntervalInSeconds: ptr.To(int32(100)),
+					},
+					HTTPPort:  ptr.To(int32(80)),
+					HTTPSPort: ptr.To(int32(443)),
+				},
+			},
+		},
+		{
+			name: "FrontDoorBackend with values",
+			obj: &fleetnetv1alpha1.FrontDoorBackend{
+				Spec: fleetnetv1alpha1.FrontDoorBackendSpec{
+					HealthProbe: &fleetnetv1alpha1.FrontDoorHealthProbe{
+						Path:              ptr.To("/healthz"),
+						Protocol:          ptr.To(fleetnetv1alpha1.FrontDoorHealthProbeProtocolHTTPS),
+						RequestType:       ptr.To(fleetnetv1alpha1.FrontDoorHealthProbeRequestTypeGET),
+						IntervalInSeconds: ptr.To(int32(30)),
+					},
+					HTTPPort:  ptr.To(int32(8080)),
+					HTTPSPort: ptr.To(int32(8443)),
+				},
+			},
+			want: &fleetnetv1alpha1.FrontDoorBackend{
+				Spec: fleetnetv1alpha1.FrontDoorBackendSpec{
+					HealthProbe: &fleetnetv1alpha1.FrontDoorHealthProbe{
+						Path:              ptr.To("/healthz"),
+						Protocol:          ptr.To(fleetnetv1alpha1.FrontDoorHealthProbeProtocolHTTPS),
+						RequestType:       ptr.To(fleetnetv1alpha1.FrontDoorHealthProbeRequestTypeGET),
+						IntervalInSeconds: ptr.To(int32(30)),
+					},
+					HTTPPort:  ptr.To(int32(8080)),
+					HTTPSPort: ptr.To(int32(8443)),
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			SetDefaultsFrontDoorBackend(tc.obj)
+			if diff := cmp.Diff(tc.want, tc.obj); diff != "" {
+				t.Errorf("SetDefaultsFrontDoorBackend() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
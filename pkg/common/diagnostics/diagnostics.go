@@ -0,0 +1,204 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package diagnostics features the runtime diagnostics of a controller manager: an optional endpoint serving the
+// pprof profiles and the expvar variables of the process, and the alignment of the Go runtime with the CPU and memory
+// limits of the container, so that the memory growth of the controllers in large fleets can be diagnosed, and
+// contained, in production.
+package diagnostics
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	// CgroupRoot is where the cgroup file system is mounted in a container.
+	CgroupRoot = "/sys/fs/cgroup"
+
+	// memoryLimitRatio is the share of the memory limit of the container the Go runtime aims to stay within; the rest
+	// is left for the memory not managed by the Go runtime, so that the garbage collector works harder before the
+	// container is OOM killed.
+	memoryLimitRatio = 0.9
+
+	// cgroupV1UnlimitedMemory is the smallest memory limit a cgroup v1 reports when the memory is not limited, i.e.
+	// the largest page-aligned int64.
+	cgroupV1UnlimitedMemory = math.MaxInt64 &^ (1<<12 - 1)
+
+	serverReadHeaderTimeout = 10 * time.Second
+	serverShutdownTimeout   = 10 * time.Second
+)
+
+// SetupWithManager serves the pprof profiles under /debug/pprof/ and the expvar variables under /debug/vars on the
+// given address as part of a controller manager; nothing is served if the address is empty.
+//
+// The endpoint is served by all the replicas, whether they lead or not, and must not be exposed outside of the pod,
+// as the profiles reveal the internals of the process.
+func SetupWithManager(mgr ctrl.Manager, bindAddress string) error {
+	if bindAddress == "" {
+		return nil
+	}
+	shutdownTimeout := serverShutdownTimeout
+	return mgr.Add(&manager.Server{
+		Name: "diagnostics",
+		Server: &http.Server{
+			Addr:              bindAddress,
+			Handler:           newMux(),
+			ReadHeaderTimeout: serverReadHeaderTimeout,
+		},
+		ShutdownTimeout: &shutdownTimeout,
+	})
+}
+
+// newMux returns the handler of the diagnostics endpoint.
+func newMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	return mux
+}
+
+func init() {
+	// expvar publishes the command line and the memory statistics; the limits the Go runtime works with complete them.
+	expvar.Publish("gomaxprocs", expvar.Func(func() interface{} { return runtime.GOMAXPROCS(0) }))
+	expvar.Publish("gomemlimit", expvar.Func(func() interface{} { return debug.SetMemoryLimit(-1) }))
+}
+
+// SetRuntimeLimits aligns GOMAXPROCS with the CPU quota and GOMEMLIMIT with the memory limit of the container, as
+// read from the cgroup file system mounted at the given root, unless they are set by the GOMAXPROCS and GOMEMLIMIT
+// environment variables.
+//
+// Otherwise the Go runtime schedules as many threads as the node has CPUs, which the CPU quota throttles, and lets
+// the heap grow up to twice its live size, which the memory limit may not accommodate.
+func SetRuntimeLimits(cgroupRoot string) {
+	if _, ok := os.LookupEnv("GOMAXPROCS"); !ok {
+		procs, err := cpuQuota(cgroupRoot)
+		switch {
+		case err != nil:
+			klog.ErrorS(err, "Failed to read the CPU quota of the container; GOMAXPROCS is left unchanged")
+		case procs > 0 && procs < runtime.NumCPU():
+			runtime.GOMAXPROCS(procs)
+		}
+	}
+	if _, ok := os.LookupEnv("GOMEMLIMIT"); !ok {
+		limit, err := memoryLimit(cgroupRoot)
+		switch {
+		case err != nil:
+			klog.ErrorS(err, "Failed to read the memory limit of the container; GOMEMLIMIT is left unchanged")
+		case limit > 0:
+			debug.SetMemoryLimit(int64(float64(limit) * memoryLimitRatio))
+		}
+	}
+	klog.InfoS("Set the runtime limits", "GOMAXPROCS", runtime.GOMAXPROCS(0), "GOMEMLIMIT", debug.SetMemoryLimit(-1))
+}
+
+// cpuQuota returns the CPU quota of the container rounded up to whole CPUs, or 0 if the CPU is not limited.
+func cpuQuota(cgroupRoot string) (int, error) {
+	// cgroup v2 reports the quota and the period in one file, e.g. "max 100000" or "150000 100000".
+	content, err := os.ReadFile(filepath.Join(cgroupRoot, "cpu.max"))
+	switch {
+	case err == nil:
+		fields := strings.Fields(string(content))
+		if len(fields) != 2 {
+			return 0, fmt.Errorf("invalid cpu.max %q", strings.TrimSpace(string(content)))
+		}
+		if fields[0] == "max" {
+			return 0, nil
+		}
+		return procsOf(fields[0], fields[1])
+	case !errors.Is(err, os.ErrNotExist):
+		return 0, err
+	}
+
+	// cgroup v1 reports the quota, which is -1 if the CPU is not limited, and the period in two files.
+	quota, err := readCgroupV1File(cgroupRoot, "cpu", "cpu.cfs_quota_us")
+	if err != nil || quota == "" || quota == "-1" {
+		return 0, err
+	}
+	period, err := readCgroupV1File(cgroupRoot, "cpu", "cpu.cfs_period_us")
+	if err != nil || period == "" {
+		return 0, err
+	}
+	return procsOf(quota, period)
+}
+
+// procsOf returns the number of CPUs a CPU quota and period amount to, rounded up.
+func procsOf(quota, period string) (int, error) {
+	q, err := strconv.ParseInt(quota, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid CPU quota %q: %w", quota, err)
+	}
+	p, err := strconv.ParseInt(period, 10, 64)
+	if err != nil || p <= 0 {
+		return 0, fmt.Errorf("invalid CPU period %q", period)
+	}
+	return int((q + p - 1) / p), nil
+}
+
+// memoryLimit returns the memory limit of the container in bytes, or 0 if the memory is not limited.
+func memoryLimit(cgroupRoot string) (int64, error) {
+	// cgroup v2 reports the limit, which is "max" if the memory is not limited.
+	content, err := os.ReadFile(filepath.Join(cgroupRoot, "memory.max"))
+	switch {
+	case err == nil:
+		limit := strings.TrimSpace(string(content))
+		if limit == "max" {
+			return 0, nil
+		}
+		return parseBytes(limit)
+	case !errors.Is(err, os.ErrNotExist):
+		return 0, err
+	}
+
+	limit, err := readCgroupV1File(cgroupRoot, "memory", "memory.limit_in_bytes")
+	if err != nil || limit == "" {
+		return 0, err
+	}
+	bytes, err := parseBytes(limit)
+	if err != nil || bytes >= cgroupV1UnlimitedMemory {
+		return 0, err
+	}
+	return bytes, nil
+}
+
+func parseBytes(limit string) (int64, error) {
+	bytes, err := strconv.ParseInt(limit, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory limit %q: %w", limit, err)
+	}
+	return bytes, nil
+}
+
+// readCgroupV1File returns the trimmed content of a file of a cgroup v1 controller, or an empty string if the
+// controller is not mounted, i.e. the resource is not limited.
+func readCgroupV1File(cgroupRoot, controller, name string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(cgroupRoot, controller, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
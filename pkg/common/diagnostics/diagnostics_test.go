@@ -0,0 +1,174 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package diagnostics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeCgroupFiles(t *testing.T, files map[string]string) string {
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("MkdirAll() = %v, want nil", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("WriteFile() = %v, want nil", err)
+		}
+	}
+	return root
+}
+
+func TestCPUQuota(t *testing.T) {
+	testCases := []struct {
+		name    string
+		files   map[string]string
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "cgroup v2, unlimited",
+			files: map[string]string{
+				"cpu.max": "max 100000\n",
+			},
+		},
+		{
+			name: "cgroup v2, fractional quota",
+			files: map[string]string{
+				"cpu.max": "150000 100000\n",
+			},
+			want: 2,
+		},
+		{
+			name: "cgroup v2, invalid",
+			files: map[string]string{
+				"cpu.max": "150000\n",
+			},
+			wantErr: true,
+		},
+		{
+			name: "cgroup v1, unlimited",
+			files: map[string]string{
+				"cpu/cpu.cfs_quota_us":  "-1\n",
+				"cpu/cpu.cfs_period_us": "100000\n",
+			},
+		},
+		{
+			name: "cgroup v1, limited",
+			files: map[string]string{
+				"cpu/cpu.cfs_quota_us":  "400000\n",
+				"cpu/cpu.cfs_period_us": "100000\n",
+			},
+			want: 4,
+		},
+		{
+			name: "no cgroup",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := cpuQuota(writeCgroupFiles(t, tc.files))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("cpuQuota() got error %v, want error %t", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("cpuQuota() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMemoryLimit(t *testing.T) {
+	testCases := []struct {
+		name    string
+		files   map[string]string
+		want    int64
+		wantErr bool
+	}{
+		{
+			name: "cgroup v2, unlimited",
+			files: map[string]string{
+				"memory.max": "max\n",
+			},
+		},
+		{
+			name: "cgroup v2, limited",
+			files: map[string]string{
+				"memory.max": "536870912\n",
+			},
+			want: 536870912,
+		},
+		{
+			name: "cgroup v2, invalid",
+			files: map[string]string{
+				"memory.max": "512Mi\n",
+			},
+			wantErr: true,
+		},
+		{
+			name: "cgroup v1, unlimited",
+			files: map[string]string{
+				"memory/memory.limit_in_bytes": "9223372036854771712\n",
+			},
+		},
+		{
+			name: "cgroup v1, limited",
+			files: map[string]string{
+				"memory/memory.limit_in_bytes": "1073741824\n",
+			},
+			want: 1073741824,
+		},
+		{
+			name: "no cgroup",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := memoryLimit(writeCgroupFiles(t, tc.files))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("memoryLimit() got error %v, want error %t", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("memoryLimit() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMux(t *testing.T) {
+	server := httptest.NewServer(newMux())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/debug/vars")
+	if err != nil {
+		t.Fatalf("Get(/debug/vars) = %v, want nil", err)
+	}
+	defer resp.Body.Close()
+	vars := map[string]json.RawMessage{}
+	if err := json.NewDecoder(resp.Body).Decode(&vars); err != nil {
+		t.Fatalf("Decode() = %v, want nil", err)
+	}
+	for _, name := range []string{"memstats", "gomaxprocs", "gomemlimit"} {
+		if _, ok := vars[name]; !ok {
+			t.Errorf("/debug/vars misses %q", name)
+		}
+	}
+
+	resp, err = http.Get(server.URL + "/debug/pprof/heap")
+	if err != nil {
+		t.Fatalf("Get(/debug/pprof/heap) = %v, want nil", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Get(/debug/pprof/heap) status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
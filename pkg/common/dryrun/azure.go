@@ -0,0 +1,51 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package dryrun
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"k8s.io/klog/v2"
+
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+)
+
+// ErrAzureWriteSkipped is returned for the writes to Azure in dry-run mode; the controllers retry them with backoff
+// as with any other failed request.
+var ErrAzureWriteSkipped = errors.New("the write to Azure is skipped in dry-run mode")
+
+// NewAzurePolicy returns the policy of the Azure resource clients which skips all the requests but the reads; the
+// intended writes are logged and counted.
+func NewAzurePolicy() policy.Policy {
+	return azurePolicy{}
+}
+
+type azurePolicy struct{}
+
+// Do implements policy.Policy.
+func (azurePolicy) Do(req *policy.Request) (*http.Response, error) {
+	raw := req.Raw()
+	if raw.Method == http.MethodGet || raw.Method == http.MethodHead {
+		return req.Next()
+	}
+	kind := azureResourceType(raw.URL.Path)
+	klog.V(2).InfoS("Dry-run write", "target", TargetAzure, "verb", raw.Method, "kind", kind, "path", raw.URL.Path)
+	metrics.DryRunWrite(TargetAzure, strings.ToLower(raw.Method), kind, nil)
+	return nil, ErrAzureWriteSkipped
+}
+
+// azureResourceType returns the type of the Azure resource of the given request path, i.e. the segment before the
+// resource name, e.g. "trafficmanagerprofiles".
+func azureResourceType(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) < 2 {
+		return ""
+	}
+	return segments[len(segments)-2]
+}
@@ -0,0 +1,97 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package dryrun
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+const testResourceURL = "https://management.azure.com/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/trafficmanagerprofiles/profile"
+
+type fakeTransporter struct {
+	requests int
+}
+
+func (t *fakeTransporter) Do(req *http.Request) (*http.Response, error) {
+	t.requests++
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+}
+
+func TestAzurePolicy(t *testing.T) {
+	testCases := []struct {
+		name         string
+		method       string
+		wantErr      error
+		wantRequests int
+	}{
+		{
+			name:         "get",
+			method:       http.MethodGet,
+			wantRequests: 1,
+		},
+		{
+			name:    "put",
+			method:  http.MethodPut,
+			wantErr: ErrAzureWriteSkipped,
+		},
+		{
+			name:    "delete",
+			method:  http.MethodDelete,
+			wantErr: ErrAzureWriteSkipped,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			transporter := &fakeTransporter{}
+			pipeline := runtime.NewPipeline("dryrun", "v0.0.0", runtime.PipelineOptions{}, &policy.ClientOptions{
+				Transport:       transporter,
+				PerCallPolicies: []policy.Policy{NewAzurePolicy()},
+				Retry:           policy.RetryOptions{MaxRetries: -1},
+			})
+			req, err := runtime.NewRequest(context.Background(), tc.method, testResourceURL)
+			if err != nil {
+				t.Fatalf("NewRequest() = %v", err)
+			}
+			if _, err := pipeline.Do(req); !errors.Is(err, tc.wantErr) {
+				t.Errorf("Do() = %v, want %v", err, tc.wantErr)
+			}
+			if transporter.requests != tc.wantRequests {
+				t.Errorf("Do() sent %d requests, want %d", transporter.requests, tc.wantRequests)
+			}
+		})
+	}
+}
+
+func TestAzureResourceType(t *testing.T) {
+	testCases := []struct {
+		name string
+		path string
+		want string
+	}{
+		{
+			name: "resource",
+			path: "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/trafficmanagerprofiles/profile",
+			want: "trafficmanagerprofiles",
+		},
+		{
+			name: "empty path",
+			path: "/",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := azureResourceType(tc.path); got != tc.want {
+				t.Errorf("azureResourceType() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
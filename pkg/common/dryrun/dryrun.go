@@ -0,0 +1,106 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package dryrun features the dry-run mode of the networking controllers, in which the controllers run as usual, but
+// send all their writes to the API server as dry-run requests; the API server validates and admits the writes
+// without persisting them. The intended writes, and the errors the API server would return for them, are logged and
+// counted, so that an upgrade or a configuration change can be validated safely against a production fleet.
+//
+// Unlike the quiesce mode, which skips the writes altogether, the dry-run mode exercises the validation and the
+// admission of the writes; the dry-run mode cannot be toggled at runtime. Azure Resource Manager has no dry-run
+// requests, and the writes to Azure are skipped instead.
+package dryrun
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+)
+
+// The targets of the writes, as recorded in the logs and the metrics.
+const (
+	TargetHub    = "hub"
+	TargetMember = "member"
+	TargetAzure  = "azure"
+)
+
+// NewClient wraps a client of the given target cluster so that its writes, including the writes to subresources, are
+// sent as dry-run requests; the intended writes are logged and counted. Reads are always passed through.
+func NewClient(c client.Client, target string) client.Client {
+	return &dryRunClient{Client: client.NewDryRunClient(c), target: target}
+}
+
+type dryRunClient struct {
+	client.Client
+	target string
+}
+
+func (c *dryRunClient) record(verb string, obj client.Object, err error) error {
+	kind := c.objectKind(obj)
+	if err != nil {
+		klog.InfoS("Dry-run write would fail", "target", c.target, "verb", verb, "kind", kind, "object", klog.KObj(obj), "err", err)
+	} else {
+		klog.V(2).InfoS("Dry-run write", "target", c.target, "verb", verb, "kind", kind, "object", klog.KObj(obj))
+	}
+	metrics.DryRunWrite(c.target, verb, kind, err)
+	return err
+}
+
+func (c *dryRunClient) objectKind(obj client.Object) string {
+	if gvk, err := c.Client.GroupVersionKindFor(obj); err == nil {
+		return gvk.Kind
+	}
+	return fmt.Sprintf("%T", obj)
+}
+
+func (c *dryRunClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	return c.record("create", obj, c.Client.Create(ctx, obj, opts...))
+}
+
+func (c *dryRunClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	return c.record("update", obj, c.Client.Update(ctx, obj, opts...))
+}
+
+func (c *dryRunClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return c.record("patch", obj, c.Client.Patch(ctx, obj, patch, opts...))
+}
+
+func (c *dryRunClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	return c.record("delete", obj, c.Client.Delete(ctx, obj, opts...))
+}
+
+func (c *dryRunClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	return c.record("deleteallof", obj, c.Client.DeleteAllOf(ctx, obj, opts...))
+}
+
+func (c *dryRunClient) Status() client.SubResourceWriter {
+	return c.SubResource("status")
+}
+
+func (c *dryRunClient) SubResource(subResource string) client.SubResourceClient {
+	return &dryRunSubResourceClient{SubResourceClient: c.Client.SubResource(subResource), parent: c, subResource: subResource}
+}
+
+type dryRunSubResourceClient struct {
+	client.SubResourceClient
+	parent      *dryRunClient
+	subResource string
+}
+
+func (c *dryRunSubResourceClient) Create(ctx context.Context, obj client.Object, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+	return c.parent.record("create "+c.subResource, obj, c.SubResourceClient.Create(ctx, obj, subResource, opts...))
+}
+
+func (c *dryRunSubResourceClient) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	return c.parent.record("update "+c.subResource, obj, c.SubResourceClient.Update(ctx, obj, opts...))
+}
+
+func (c *dryRunSubResourceClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	return c.parent.record("patch "+c.subResource, obj, c.SubResourceClient.Patch(ctx, obj, patch, opts...))
+}
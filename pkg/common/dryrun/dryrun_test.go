@@ -0,0 +1,91 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package dryrun
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+const (
+	testNamespace = "work"
+	testName      = "app"
+)
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	key := types.NamespacedName{Namespace: testNamespace, Name: testName}
+	existing := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(existing).
+		WithStatusSubresource(existing).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Patch: func(context.Context, client.WithWatch, client.Object, client.Patch, ...client.PatchOption) error {
+				return apierrors.NewConflict(schema.GroupResource{Resource: "services"}, testName, fmt.Errorf("test conflict"))
+			},
+		}).
+		Build()
+	c := NewClient(fakeClient, TargetHub)
+
+	// Creates are validated but not persisted.
+	created := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "other"},
+	}
+	if err := c.Create(ctx, created); err != nil {
+		t.Fatalf("Create() in dry-run mode = %v, want no error", err)
+	}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: testNamespace, Name: "other"}, &corev1.Service{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("Get() after a dry-run Create() = %v, want not found error", err)
+	}
+
+	// The errors the writes would fail with are returned as they are.
+	if err := c.Patch(ctx, created, client.MergeFrom(created.DeepCopy())); !apierrors.IsConflict(err) {
+		t.Fatalf("Patch() in dry-run mode = %v, want conflict error", err)
+	}
+
+	// Updates, including the status updates, are not persisted, while reads still go through.
+	got := &corev1.Service{}
+	if err := c.Get(ctx, key, got); err != nil {
+		t.Fatalf("Get() in dry-run mode = %v, want no error", err)
+	}
+	got.Labels = map[string]string{"app": testName}
+	if err := c.Update(ctx, got); err != nil {
+		t.Fatalf("Update() in dry-run mode = %v, want no error", err)
+	}
+	got.Status.Conditions = []metav1.Condition{{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready"}}
+	if err := c.Status().Update(ctx, got); err != nil {
+		t.Fatalf("Status().Update() in dry-run mode = %v, want no error", err)
+	}
+	stored := &corev1.Service{}
+	if err := fakeClient.Get(ctx, key, stored); err != nil {
+		t.Fatalf("Get() = %v, want no error", err)
+	}
+	if len(stored.Labels) != 0 || len(stored.Status.Conditions) != 0 {
+		t.Errorf("service = %+v, want no labels or status conditions after dry-run updates", stored)
+	}
+
+	// Deletes are not persisted.
+	if err := c.Delete(ctx, stored); err != nil {
+		t.Fatalf("Delete() in dry-run mode = %v, want no error", err)
+	}
+	if err := fakeClient.Get(ctx, key, &corev1.Service{}); err != nil {
+		t.Errorf("Get() after a dry-run Delete() = %v, want no error", err)
+	}
+}
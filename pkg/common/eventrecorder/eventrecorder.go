@@ -0,0 +1,92 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package eventrecorder features an event recorder that rate limits the emission of Kubernetes Events, so that
+// objects flapping between states cannot flood the Events API.
+package eventrecorder
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+)
+
+var (
+	// droppedEvents counts the Events dropped by the rate limiter, partitioned by the emitting controller.
+	droppedEvents = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metrics.MetricsNamespace,
+			Subsystem: metrics.MetricsSubsystem,
+			Name:      "dropped_events_total",
+			Help:      "The number of Kubernetes Events dropped because of rate limiting",
+		},
+		[]string{
+			// The name of the controller which emits the Event.
+			"controller",
+		},
+	)
+)
+
+func init() {
+	// Register droppedEvents (fleet_networking_dropped_events_total) metric with the controller runtime global
+	// metrics registry.
+	ctrlmetrics.Registry.MustRegister(droppedEvents)
+}
+
+// Throttler holds a token bucket shared by all the event recorders it wraps, which caps the overall rate at
+// which a process emits Events.
+type Throttler struct {
+	limiter *rate.Limiter
+}
+
+// NewThrottler returns a Throttler which allows eventRate Events per second on average, with bursts of up to
+// burst Events.
+func NewThrottler(eventRate float64, burst int) *Throttler {
+	return &Throttler{limiter: rate.NewLimiter(rate.Limit(eventRate), burst)}
+}
+
+// Wrap returns an event recorder which emits Events through the given recorder when the token bucket allows,
+// and drops them otherwise; the controller name is used to label the dropped events metric.
+func (t *Throttler) Wrap(recorder record.EventRecorder, controller string) record.EventRecorder {
+	return &rateLimitedRecorder{recorder: recorder, limiter: t.limiter, controller: controller}
+}
+
+type rateLimitedRecorder struct {
+	recorder   record.EventRecorder
+	limiter    *rate.Limiter
+	controller string
+}
+
+func (r *rateLimitedRecorder) allow(eventType, reason string) bool {
+	if r.limiter.Allow() {
+		return true
+	}
+	droppedEvents.WithLabelValues(r.controller).Inc()
+	klog.V(4).InfoS("Dropped an event because of rate limiting", "controller", r.controller, "type", eventType, "reason", reason)
+	return false
+}
+
+func (r *rateLimitedRecorder) Event(object runtime.Object, eventType, reason, message string) {
+	if r.allow(eventType, reason) {
+		r.recorder.Event(object, eventType, reason, message)
+	}
+}
+
+func (r *rateLimitedRecorder) Eventf(object runtime.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	if r.allow(eventType, reason) {
+		r.recorder.Eventf(object, eventType, reason, messageFmt, args...)
+	}
+}
+
+func (r *rateLimitedRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventType, reason, messageFmt string, args ...interface{}) {
+	if r.allow(eventType, reason) {
+		r.recorder.AnnotatedEventf(object, annotations, eventType, reason, messageFmt, args...)
+	}
+}
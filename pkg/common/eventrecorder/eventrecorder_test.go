@@ -0,0 +1,88 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package eventrecorder
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestRateLimitedRecorder(t *testing.T) {
+	const controller = "test-controller"
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "work", Name: "app"}}
+
+	testCases := []struct {
+		name        string
+		burst       int
+		emit        int
+		wantEmitted int
+	}{
+		{
+			name:        "events within the burst are emitted",
+			burst:       5,
+			emit:        5,
+			wantEmitted: 5,
+		},
+		{
+			name:        "events beyond the burst are dropped",
+			burst:       3,
+			emit:        10,
+			wantEmitted: 3,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			droppedBefore := testutil.ToFloat64(droppedEvents.WithLabelValues(controller))
+			fakeRecorder := record.NewFakeRecorder(tc.emit)
+			// A zero rate never refills the bucket, which keeps the test deterministic.
+			recorder := NewThrottler(0, tc.burst).Wrap(fakeRecorder, controller)
+
+			// Simulate an object flapping between states.
+			for i := 0; i < tc.emit; i++ {
+				switch i % 3 {
+				case 0:
+					recorder.Event(svc, corev1.EventTypeNormal, "Valid", "service is valid")
+				case 1:
+					recorder.Eventf(svc, corev1.EventTypeWarning, "Invalid", "service %s is invalid", svc.Name)
+				default:
+					recorder.AnnotatedEventf(svc, map[string]string{"foo": "bar"}, corev1.EventTypeNormal, "Valid", "service %s is valid", svc.Name)
+				}
+			}
+
+			if got := len(fakeRecorder.Events); got != tc.wantEmitted {
+				t.Errorf("emitted %d events, want %d", got, tc.wantEmitted)
+			}
+			droppedAfter := testutil.ToFloat64(droppedEvents.WithLabelValues(controller))
+			if got, want := int(droppedAfter-droppedBefore), tc.emit-tc.wantEmitted; got != want {
+				t.Errorf("dropped events metric increased by %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestThrottlerSharedAcrossRecorders(t *testing.T) {
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "work", Name: "app"}}
+	throttler := NewThrottler(0, 2)
+	fakeRecorderA := record.NewFakeRecorder(10)
+	fakeRecorderB := record.NewFakeRecorder(10)
+	recorderA := throttler.Wrap(fakeRecorderA, "controller-a")
+	recorderB := throttler.Wrap(fakeRecorderB, "controller-b")
+
+	recorderA.Event(svc, corev1.EventTypeNormal, "Valid", "service is valid")
+	recorderA.Event(svc, corev1.EventTypeNormal, "Valid", "service is valid")
+	recorderB.Event(svc, corev1.EventTypeNormal, "Valid", "service is valid")
+
+	if got := len(fakeRecorderA.Events); got != 2 {
+		t.Errorf("recorder A emitted %d events, want 2", got)
+	}
+	if got := len(fakeRecorderB.Events); got != 0 {
+		t.Errorf("recorder B emitted %d events, want 0 as the shared bucket is exhausted", got)
+	}
+}
@@ -0,0 +1,257 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package explain features a debug endpoint which describes, in plain words, why an object is in its current
+// state, e.g. which exports contribute to a ServiceImport and why the others are excluded; it turns the diagnosis
+// of a ServiceImport into a single call rather than a trawl through the logs.
+package explain
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/fieldindex"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+// EndpointPath is the path of the debug endpoint, served by the metrics server of a controller manager, e.g.
+// "/explain?kind=ServiceImport&ns=work&name=app".
+const EndpointPath = "/explain"
+
+// kindServiceImport is the only kind of object which can be explained at this moment.
+const kindServiceImport = "ServiceImport"
+
+// Result is the outcome of the last reconciliation of an object.
+type Result struct {
+	// Time is when the reconciliation ended.
+	Time time.Time
+	// Result is the result returned by the reconciliation.
+	Result ctrl.Result
+	// Err is the error returned by the reconciliation, if any.
+	Err error
+}
+
+// Results keeps the outcome of the last reconciliation of each object handled by a controller, until the controller
+// forgets the object as it is gone; a nil Results records nothing.
+type Results struct {
+	mu      sync.Mutex
+	results map[types.NamespacedName]Result
+}
+
+// NewResults returns an empty Results.
+func NewResults() *Results {
+	return &Results{results: make(map[types.NamespacedName]Result)}
+}
+
+// Record records the outcome of a reconciliation of the object with the given key.
+func (r *Results) Record(key types.NamespacedName, result ctrl.Result, err error) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results[key] = Result{Time: time.Now(), Result: result, Err: err}
+}
+
+// Forget drops the outcome recorded for the object with the given key, as the object is gone.
+func (r *Results) Forget(key types.NamespacedName) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.results, key)
+}
+
+// Get returns the outcome of the last reconciliation of the object with the given key.
+func (r *Results) Get(key types.NamespacedName) (Result, bool) {
+	if r == nil {
+		return Result{}, false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res, ok := r.results[key]
+	return res, ok
+}
+
+// Handler serves the debug endpoint; it only reads the objects, and reflects the state of the cache it reads
+// from.
+type Handler struct {
+	// Reader reads the objects, usually the cache-backed client of the controller manager; it must index the
+	// InternalServiceExports and the EndpointSliceExports by the namespaced name of their service, as the hub
+	// controllers do.
+	Reader client.Reader
+	// ServiceImportResults are the outcomes recorded by the ServiceImport controller.
+	ServiceImportResults *Results
+}
+
+// ServeHTTP implements http.Handler; it returns the explanation of the requested object as plain text.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	kind, namespace, name := query.Get("kind"), query.Get("ns"), query.Get("name")
+	if kind != kindServiceImport {
+		http.Error(w, fmt.Sprintf("unsupported kind %q, want %s", kind, kindServiceImport), http.StatusBadRequest)
+		return
+	}
+	if namespace == "" || name == "" {
+		http.Error(w, "both ns and name must be specified", http.StatusBadRequest)
+		return
+	}
+	explanation, err := h.ExplainServiceImport(req.Context(), types.NamespacedName{Namespace: namespace, Name: name})
+	if err != nil {
+		klog.ErrorS(err, "Failed to explain the serviceImport", "serviceImport", klog.KRef(namespace, name))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if _, err := w.Write([]byte(explanation)); err != nil {
+		klog.ErrorS(err, "Failed to write the explanation", "serviceImport", klog.KRef(namespace, name))
+	}
+}
+
+// ExplainServiceImport describes the ServiceImport with the given key: which InternalServiceExports contribute
+// to it, why the others are excluded, and the outcome of its last reconciliation.
+func (h *Handler) ExplainServiceImport(ctx context.Context, key types.NamespacedName) (string, error) {
+	var b strings.Builder
+	serviceImport := &fleetnetv1alpha1.ServiceImport{}
+	found := true
+	if err := h.Reader.Get(ctx, key, serviceImport); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return "", fmt.Errorf("failed to get serviceImport %s: %w", key, err)
+		}
+		found = false
+	}
+	internalServiceExportList := &fleetnetv1alpha1.InternalServiceExportList{}
+	if err := h.Reader.List(ctx, internalServiceExportList, client.MatchingFields{fieldindex.InternalServiceExportServiceNamespacedName: key.String()}); err != nil {
+		return "", fmt.Errorf("failed to list internalServiceExports: %w", err)
+	}
+	exports := make([]*fleetnetv1alpha1.InternalServiceExport, 0, len(internalServiceExportList.Items))
+	for i := range internalServiceExportList.Items {
+		exports = append(exports, &internalServiceExportList.Items[i])
+	}
+	sort.Slice(exports, func(i, j int) bool {
+		return exports[i].Spec.ServiceReference.ClusterID < exports[j].Spec.ServiceReference.ClusterID
+	})
+
+	switch {
+	case !found:
+		fmt.Fprintf(&b, "ServiceImport %s does not exist; it is created when a member cluster exports the service.\n", key)
+	case serviceImport.DeletionTimestamp != nil:
+		fmt.Fprintf(&b, "ServiceImport %s is being deleted.\n", key)
+	case len(serviceImport.Status.Clusters) == 0:
+		fmt.Fprintf(&b, "ServiceImport %s has no contributing clusters; its spec is not resolved yet, or all the exports are excluded.\n", key)
+	default:
+		fmt.Fprintf(&b, "ServiceImport %s has %d contributing cluster(s) and exposes %d port(s).\n", key, len(serviceImport.Status.Clusters), len(serviceImport.Status.Ports))
+	}
+	if cond := meta.FindStatusCondition(serviceImport.Status.Conditions, string(fleetnetv1alpha1.ServiceImportSessionAffinityConflict)); found && cond != nil && cond.Status == metav1.ConditionTrue {
+		fmt.Fprintf(&b, "Session affinity conflict: %s.\n", cond.Message)
+	}
+
+	if len(exports) == 0 {
+		b.WriteString("No member cluster exports the service.\n")
+	} else {
+		endpoints, err := h.countEndpoints(ctx, key)
+		if err != nil {
+			return "", err
+		}
+		contributing := make(map[string]bool, len(serviceImport.Status.Clusters))
+		for _, c := range serviceImport.Status.Clusters {
+			contributing[c.Cluster] = true
+		}
+		fmt.Fprintf(&b, "InternalServiceExports (%d):\n", len(exports))
+		for _, export := range exports {
+			cluster := export.Spec.ServiceReference.ClusterID
+			fmt.Fprintf(&b, "- %s from cluster %s: %s\n", klog.KObj(export), cluster, explainExport(export, contributing[cluster], endpoints[cluster]))
+		}
+	}
+
+	if res, ok := h.ServiceImportResults.Get(key); ok {
+		outcome := "succeeded"
+		switch {
+		case res.Err != nil:
+			outcome = fmt.Sprintf("failed with error: %v", res.Err)
+		case res.Result.RequeueAfter > 0:
+			outcome = fmt.Sprintf("succeeded and requeued after %s", res.Result.RequeueAfter)
+		case res.Result.Requeue:
+			outcome = "succeeded and requeued"
+		}
+		fmt.Fprintf(&b, "Last reconcile at %s %s.\n", res.Time.UTC().Format(time.RFC3339), outcome)
+	} else {
+		b.WriteString("No reconcile has been recorded since the controller started.\n")
+	}
+	return b.String(), nil
+}
+
+// countEndpoints returns the number of endpoints each cluster exports for the service with the given key.
+func (h *Handler) countEndpoints(ctx context.Context, key types.NamespacedName) (map[string]int, error) {
+	endpointSliceExportList := &fleetnetv1alpha1.EndpointSliceExportList{}
+	listOpts := client.MatchingFields{
+		fieldindex.EndpointSliceExportOwnerServiceNamespacedName: key.String(),
+	}
+	if err := h.Reader.List(ctx, endpointSliceExportList, listOpts); err != nil {
+		return nil, fmt.Errorf("failed to list endpointSliceExports: %w", err)
+	}
+	endpoints := make(map[string]int)
+	for i := range endpointSliceExportList.Items {
+		endpointSliceExport := &endpointSliceExportList.Items[i]
+		endpoints[endpointSliceExport.Spec.EndpointSliceReference.ClusterID] += len(endpointSliceExport.Spec.Endpoints)
+	}
+	return endpoints, nil
+}
+
+// explainExport describes why an InternalServiceExport contributes to its ServiceImport, or why it does not.
+func explainExport(export *fleetnetv1alpha1.InternalServiceExport, contributing bool, endpoints int) string {
+	conds := export.Status.Conditions
+	switch {
+	case export.DeletionTimestamp != nil:
+		return "excluded, as the export is being withdrawn"
+	case !controllerutil.ContainsFinalizer(export, objectmeta.InternalServiceExportFinalizer):
+		return "excluded, as the export is pending processing by the hub"
+	case meta.IsStatusConditionTrue(conds, string(fleetnetv1alpha1.ServiceExportDenied)):
+		return "excluded, as the service is denied by the export denylist" + conditionMessage(conds, fleetnetv1alpha1.ServiceExportDenied)
+	case meta.IsStatusConditionTrue(conds, string(fleetnetv1alpha1.ServiceExportQuarantined)):
+		return "excluded, as the member cluster is quarantined" + conditionMessage(conds, fleetnetv1alpha1.ServiceExportQuarantined)
+	case meta.IsStatusConditionTrue(conds, string(fleetnetv1alpha1.ServiceExportTenantConflict)):
+		return "excluded, as the service is owned by another tenant" + conditionMessage(conds, fleetnetv1alpha1.ServiceExportTenantConflict)
+	case meta.IsStatusConditionTrue(conds, string(fleetnetv1alpha1.ServiceExportPendingApproval)):
+		return "excluded, as the export is pending approval" + conditionMessage(conds, fleetnetv1alpha1.ServiceExportPendingApproval)
+	case meta.IsStatusConditionTrue(conds, string(fleetnetv1alpha1.ServiceExportQuotaExceeded)):
+		return "excluded, as the export exceeds an export quota" + conditionMessage(conds, fleetnetv1alpha1.ServiceExportQuotaExceeded)
+	case meta.IsStatusConditionTrue(conds, string(fleetnetv1alpha1.ServiceExportStale)):
+		return "excluded, as the member cluster has stopped reporting" + conditionMessage(conds, fleetnetv1alpha1.ServiceExportStale)
+	case meta.IsStatusConditionTrue(conds, string(fleetnetv1alpha1.ServiceExportConflict)):
+		return "excluded, as the export is in conflict with the ServiceImport" + conditionMessage(conds, fleetnetv1alpha1.ServiceExportConflict)
+	case !contributing:
+		return "not contributing yet, as the conflict resolution is pending"
+	case endpoints == 0:
+		return "contributing, but unroutable as the cluster exports no endpoints"
+	default:
+		return fmt.Sprintf("contributing with %d endpoint(s)", endpoints)
+	}
+}
+
+// conditionMessage returns the message of the condition of the given type, formatted to be appended to an
+// explanation.
+func conditionMessage(conds []metav1.Condition, condType fleetnetv1alpha1.ServiceExportConditionType) string {
+	cond := meta.FindStatusCondition(conds, string(condType))
+	if cond == nil || cond.Message == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", cond.Message)
+}
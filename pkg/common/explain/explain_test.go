@@ -0,0 +1,267 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package explain
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/fieldindex"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+const (
+	svcNamespace = "work"
+	svcName      = "app"
+)
+
+var serviceImportKey = types.NamespacedName{Namespace: svcNamespace, Name: svcName}
+
+func internalServiceExport(cluster string, conds ...metav1.Condition) *fleetnetv1alpha1.InternalServiceExport {
+	return &fleetnetv1alpha1.InternalServiceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  cluster + "-ns",
+			Name:       svcNamespace + "-" + svcName,
+			Finalizers: []string{objectmeta.InternalServiceExportFinalizer},
+		},
+		Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+			ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+				ClusterID:      cluster,
+				Namespace:      svcNamespace,
+				Name:           svcName,
+				NamespacedName: serviceImportKey.String(),
+			},
+		},
+		Status: fleetnetv1alpha1.InternalServiceExportStatus{Conditions: conds},
+	}
+}
+
+func endpointSliceExport(cluster string, endpoints int) *fleetnetv1alpha1.EndpointSliceExport {
+	endpointSliceExport := &fleetnetv1alpha1.EndpointSliceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: cluster + "-ns",
+			Name:      svcNamespace + "-" + svcName + "-abcde",
+			Labels: map[string]string{
+				objectmeta.EndpointSliceExportLabelOwnerServiceNamespace: svcNamespace,
+				objectmeta.EndpointSliceExportLabelOwnerServiceName:      svcName,
+			},
+		},
+		Spec: fleetnetv1alpha1.EndpointSliceExportSpec{
+			EndpointSliceReference: fleetnetv1alpha1.ExportedObjectReference{ClusterID: cluster},
+			OwnerServiceReference: fleetnetv1alpha1.OwnerServiceReference{
+				Namespace:      svcNamespace,
+				Name:           svcName,
+				NamespacedName: serviceImportKey.String(),
+			},
+		},
+	}
+	for i := 0; i < endpoints; i++ {
+		endpointSliceExport.Spec.Endpoints = append(endpointSliceExport.Spec.Endpoints, fleetnetv1alpha1.Endpoint{Addresses: []string{"1.2.3.4"}})
+	}
+	return endpointSliceExport
+}
+
+func handler(t *testing.T, results *Results, objects ...client.Object) *Handler {
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	return &Handler{
+		Reader: fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(objects...).
+			WithIndex(&fleetnetv1alpha1.InternalServiceExport{}, fieldindex.InternalServiceExportServiceNamespacedName, func(o client.Object) []string {
+				return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.NamespacedName}
+			}).
+			WithIndex(&fleetnetv1alpha1.EndpointSliceExport{}, fieldindex.EndpointSliceExportOwnerServiceNamespacedName, func(o client.Object) []string {
+				return []string{o.(*fleetnetv1alpha1.EndpointSliceExport).Spec.OwnerServiceReference.NamespacedName}
+			}).
+			Build(),
+		ServiceImportResults: results,
+	}
+}
+
+// TestExplainServiceImport tests the ExplainServiceImport method.
+func TestExplainServiceImport(t *testing.T) {
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{Namespace: svcNamespace, Name: svcName},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Ports:    []fleetnetv1alpha1.ServicePort{{Port: 80}},
+			Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-1"}, {Cluster: "member-5"}},
+			Conditions: []metav1.Condition{{
+				Type:    string(fleetnetv1alpha1.ServiceImportSessionAffinityConflict),
+				Status:  metav1.ConditionTrue,
+				Reason:  "SessionAffinityMismatch",
+				Message: "exporting clusters disagree on the session affinity settings (member-1: None, member-5: ClientIP with a timeout of 600s); the most conservative settings are used",
+			}},
+		},
+	}
+	conflicted := metav1.Condition{
+		Type:    string(fleetnetv1alpha1.ServiceExportConflict),
+		Status:  metav1.ConditionTrue,
+		Reason:  "ConflictFound",
+		Message: "service work/app is in conflict with other exported services",
+	}
+	quarantined := metav1.Condition{
+		Type:   string(fleetnetv1alpha1.ServiceExportQuarantined),
+		Status: metav1.ConditionTrue,
+		Reason: "ClusterQuarantined",
+	}
+	pending := internalServiceExport("member-4")
+	pending.Finalizers = nil
+	otherService := internalServiceExport("member-1")
+	otherService.Name = "work-other"
+	otherService.Spec.ServiceReference.Name = "other"
+	otherService.Spec.ServiceReference.NamespacedName = svcNamespace + "/other"
+	reconciledAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name    string
+		objects []client.Object
+		result  *Result
+		want    string
+	}{
+		{
+			name: "conflicted export is excluded",
+			objects: []client.Object{
+				serviceImport,
+				otherService,
+				internalServiceExport("member-1"),
+				internalServiceExport("member-2", conflicted),
+				internalServiceExport("member-3", quarantined),
+				pending,
+				internalServiceExport("member-5"),
+				endpointSliceExport("member-1", 2),
+				endpointSliceExport("member-2", 3),
+			},
+			result: &Result{Time: reconciledAt, Err: errors.New("conflict")},
+			want: `ServiceImport work/app has 2 contributing cluster(s) and exposes 1 port(s).
+Session affinity conflict: exporting clusters disagree on the session affinity settings (member-1: None, member-5: ClientIP with a timeout of 600s); the most conservative settings are used.
+InternalServiceExports (5):
+- member-1-ns/work-app from cluster member-1: contributing with 2 endpoint(s)
+- member-2-ns/work-app from cluster member-2: excluded, as the export is in conflict with the ServiceImport (service work/app is in conflict with other exported services)
+- member-3-ns/work-app from cluster member-3: excluded, as the member cluster is quarantined
+- member-4-ns/work-app from cluster member-4: excluded, as the export is pending processing by the hub
+- member-5-ns/work-app from cluster member-5: contributing, but unroutable as the cluster exports no endpoints
+Last reconcile at 2024-01-01T00:00:00Z failed with error: conflict.
+`,
+		},
+		{
+			name:    "serviceImport not found",
+			objects: []client.Object{internalServiceExport("member-1")},
+			result:  &Result{Time: reconciledAt, Result: ctrl.Result{RequeueAfter: 5 * time.Second}},
+			want: `ServiceImport work/app does not exist; it is created when a member cluster exports the service.
+InternalServiceExports (1):
+- member-1-ns/work-app from cluster member-1: not contributing yet, as the conflict resolution is pending
+Last reconcile at 2024-01-01T00:00:00Z succeeded and requeued after 5s.
+`,
+		},
+		{
+			name: "nothing exported",
+			want: `ServiceImport work/app does not exist; it is created when a member cluster exports the service.
+No member cluster exports the service.
+No reconcile has been recorded since the controller started.
+`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			results := NewResults()
+			if tc.result != nil {
+				results.results[serviceImportKey] = *tc.result
+			}
+			got, err := handler(t, results, tc.objects...).ExplainServiceImport(context.Background(), serviceImportKey)
+			if err != nil {
+				t.Fatalf("ExplainServiceImport() got error %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("ExplainServiceImport() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestHandler_ServeHTTP tests the debug endpoint.
+func TestHandler_ServeHTTP(t *testing.T) {
+	testCases := []struct {
+		name         string
+		query        string
+		wantCode     int
+		wantContains string
+	}{
+		{
+			name:         "serviceImport is explained",
+			query:        "kind=ServiceImport&ns=work&name=app",
+			wantCode:     http.StatusOK,
+			wantContains: "ServiceImport work/app does not exist",
+		},
+		{
+			name:         "unsupported kind",
+			query:        "kind=ServiceExport&ns=work&name=app",
+			wantCode:     http.StatusBadRequest,
+			wantContains: "unsupported kind",
+		},
+		{
+			name:         "missing name",
+			query:        "kind=ServiceImport&ns=work",
+			wantCode:     http.StatusBadRequest,
+			wantContains: "both ns and name must be specified",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, EndpointPath+"?"+tc.query, nil)
+			rec := httptest.NewRecorder()
+			handler(t, nil).ServeHTTP(rec, req)
+			if rec.Code != tc.wantCode {
+				t.Errorf("ServeHTTP() status code = %d, want %d", rec.Code, tc.wantCode)
+			}
+			if !strings.Contains(rec.Body.String(), tc.wantContains) {
+				t.Errorf("ServeHTTP() body = %q, want it to contain %q", rec.Body.String(), tc.wantContains)
+			}
+		})
+	}
+}
+
+// TestResults tests that the Results keep the outcome of the last reconciliation until the object is forgotten,
+// and that a nil Results records nothing.
+func TestResults(t *testing.T) {
+	results := NewResults()
+	results.Record(serviceImportKey, ctrl.Result{}, errors.New("failed"))
+	results.Record(serviceImportKey, ctrl.Result{Requeue: true}, nil)
+	got, ok := results.Get(serviceImportKey)
+	if !ok {
+		t.Fatalf("Get() = _, false, want true")
+	}
+	if !got.Result.Requeue || got.Err != nil {
+		t.Errorf("Get() = %+v, want the outcome of the last reconciliation", got)
+	}
+	results.Forget(serviceImportKey)
+	if _, ok := results.Get(serviceImportKey); ok {
+		t.Errorf("Get() of a forgotten object = _, true, want false")
+	}
+
+	var nilResults *Results
+	nilResults.Record(serviceImportKey, ctrl.Result{}, nil)
+	nilResults.Forget(serviceImportKey)
+	if _, ok := nilResults.Get(serviceImportKey); ok {
+		t.Errorf("Get() of a nil Results = _, true, want false")
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package exportapproval features the approval workflow of the exports, which lets the fleet admins of the
+// organizations with strict exposure-review processes review every Service exported from the user namespaces before
+// its endpoints propagate across the fleet.
+//
+// When the hub requires the exports to be approved, an export is excluded from its ServiceImport, and reported as
+// pending approval, until a fleet admin creates a ServiceExportApproval of its Service listing its member cluster.
+package exportapproval
+
+import (
+	"context"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/uniquename"
+)
+
+// ReasonAwaitingApproval is the reason of the pending approval condition of the exports which are not approved yet.
+const ReasonAwaitingApproval = "AwaitingApproval"
+
+// Policy decides which exports require the approval of a fleet admin.
+type Policy struct {
+	// ExemptNamespaces lists the namespaces, e.g. of the system Services, whose exports are approved implicitly.
+	ExemptNamespaces []string
+}
+
+// RequiresApproval returns true if the exports of the Services in the namespace require an approval.
+func (p *Policy) RequiresApproval(namespace string) bool {
+	return !slices.Contains(p.ExemptNamespaces, namespace)
+}
+
+// IsApproved returns true if the export of the Service from the member cluster does not require an approval, or is
+// approved by the ServiceExportApproval of the Service.
+func (p *Policy) IsApproved(ctx context.Context, reader client.Reader, clusterID, svcNamespace, svcName string) (bool, error) {
+	if !p.RequiresApproval(svcNamespace) {
+		return true, nil
+	}
+	approval := &fleetnetv1alpha1.ServiceExportApproval{}
+	if err := reader.Get(ctx, types.NamespacedName{Namespace: svcNamespace, Name: svcName}, approval); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	return Approves(approval, clusterID), nil
+}
+
+// Approves returns true if the ServiceExportApproval approves the export from the member cluster.
+func Approves(approval *fleetnetv1alpha1.ServiceExportApproval, clusterID string) bool {
+	return len(approval.Spec.Clusters) == 0 || slices.Contains(approval.Spec.Clusters, clusterID)
+}
+
+// IsPendingExport returns true if the internalServiceExport is reported as pending approval in its status.
+func IsPendingExport(internalServiceExport *fleetnetv1alpha1.InternalServiceExport) bool {
+	return meta.IsStatusConditionTrue(internalServiceExport.Status.Conditions, string(fleetnetv1alpha1.ServiceExportPendingApproval))
+}
+
+// IsServicePending returns true if the internalServiceExport of the Service exported from the member cluster, whose
+// namespace in the hub cluster is given, is reported as pending approval; a Service which is not exported is not
+// pending.
+func IsServicePending(ctx context.Context, reader client.Reader, clusterNamespace, svcNamespace, svcName string) (bool, error) {
+	internalServiceExport := &fleetnetv1alpha1.InternalServiceExport{}
+	key := types.NamespacedName{Namespace: clusterNamespace, Name: uniquename.ClusterScopedDeterministicName(svcNamespace, svcName)}
+	if err := reader.Get(ctx, key, internalServiceExport); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	return IsPendingExport(internalServiceExport), nil
+}
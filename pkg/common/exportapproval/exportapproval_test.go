@@ -0,0 +1,142 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package exportapproval
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/uniquename"
+)
+
+const (
+	testClusterID        = "member-1"
+	testClusterNamespace = "fleet-member-member-1"
+	testNamespace        = "work"
+	testServiceName      = "app"
+)
+
+func newScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	return scheme
+}
+
+// TestIsApproved tests the IsApproved function.
+func TestIsApproved(t *testing.T) {
+	approval := func(clusters ...string) *fleetnetv1alpha1.ServiceExportApproval {
+		return &fleetnetv1alpha1.ServiceExportApproval{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testServiceName},
+			Spec:       fleetnetv1alpha1.ServiceExportApprovalSpec{Clusters: clusters},
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		exempt   []string
+		approval *fleetnetv1alpha1.ServiceExportApproval
+		want     bool
+	}{
+		{
+			name: "no approval",
+		},
+		{
+			name:   "namespace is exempt",
+			exempt: []string{"kube-system", testNamespace},
+			want:   true,
+		},
+		{
+			name:     "all the clusters are approved",
+			approval: approval(),
+			want:     true,
+		},
+		{
+			name:     "cluster is approved",
+			approval: approval("member-2", testClusterID),
+			want:     true,
+		},
+		{
+			name:     "cluster is not approved",
+			approval: approval("member-2"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithScheme(newScheme(t))
+			if tc.approval != nil {
+				builder = builder.WithObjects(tc.approval)
+			}
+			policy := &Policy{ExemptNamespaces: tc.exempt}
+			got, err := policy.IsApproved(context.Background(), builder.Build(), testClusterID, testNamespace, testServiceName)
+			if err != nil {
+				t.Fatalf("IsApproved() got error %v, want no error", err)
+			}
+			if got != tc.want {
+				t.Errorf("IsApproved() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestIsServicePending tests the IsServicePending function.
+func TestIsServicePending(t *testing.T) {
+	internalServiceExport := func(status metav1.ConditionStatus) *fleetnetv1alpha1.InternalServiceExport {
+		return &fleetnetv1alpha1.InternalServiceExport{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: testClusterNamespace,
+				Name:      uniquename.ClusterScopedDeterministicName(testNamespace, testServiceName),
+			},
+			Status: fleetnetv1alpha1.InternalServiceExportStatus{
+				Conditions: []metav1.Condition{
+					{
+						Type:   string(fleetnetv1alpha1.ServiceExportPendingApproval),
+						Status: status,
+						Reason: ReasonAwaitingApproval,
+					},
+				},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name    string
+		objects []client.Object
+		want    bool
+	}{
+		{
+			name: "service is not exported",
+		},
+		{
+			name:    "export is pending approval",
+			objects: []client.Object{internalServiceExport(metav1.ConditionTrue)},
+			want:    true,
+		},
+		{
+			name:    "export is not pending approval",
+			objects: []client.Object{internalServiceExport(metav1.ConditionFalse)},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().WithScheme(newScheme(t)).WithObjects(tc.objects...).Build()
+			got, err := IsServicePending(context.Background(), fakeClient, testClusterNamespace, testNamespace, testServiceName)
+			if err != nil {
+				t.Fatalf("IsServicePending() got error %v, want no error", err)
+			}
+			if got != tc.want {
+				t.Errorf("IsServicePending() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
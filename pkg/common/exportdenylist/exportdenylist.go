@@ -0,0 +1,102 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package exportdenylist features the hub-managed denylist of exported services, which gives the fleet operator
+// a central veto over the services exported from member clusters.
+package exportdenylist
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PatternsKey is the key in the data of the denylist ConfigMap that holds the denied patterns.
+//
+// Each non-empty line which does not start with "#" is a pattern in the form of "<namespace>/<name>", matched
+// against the namespaced name of an exported Service with the syntax of path.Match, e.g. "kube-system/*" denies
+// all the services in the kube-system namespace and "*/secrets" denies the services named secrets in any
+// namespace.
+const PatternsKey = "patterns"
+
+// Denylist is a set of patterns of denied exported services; the zero value, as well as a nil Denylist, denies
+// nothing.
+type Denylist struct {
+	patterns []string
+}
+
+// Parse parses the patterns of a denylist ConfigMap; invalid patterns are skipped and reported in the error.
+func Parse(configMap *corev1.ConfigMap) (*Denylist, error) {
+	d := &Denylist{}
+	var invalid []string
+	scanner := bufio.NewScanner(strings.NewReader(configMap.Data[PatternsKey]))
+	for scanner.Scan() {
+		pattern := strings.TrimSpace(scanner.Text())
+		if pattern == "" || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+		if strings.Count(pattern, "/") != 1 {
+			invalid = append(invalid, pattern)
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			invalid = append(invalid, pattern)
+			continue
+		}
+		d.patterns = append(d.patterns, pattern)
+	}
+	if len(invalid) > 0 {
+		return d, fmt.Errorf("invalid denylist patterns %q, want <namespace>/<name>", invalid)
+	}
+	return d, nil
+}
+
+// Get reads and parses the denylist ConfigMap; a ConfigMap which does not exist denies nothing, and neither does
+// an empty key, which disables the denylist.
+func Get(ctx context.Context, reader client.Reader, key types.NamespacedName) (*Denylist, error) {
+	if key.Name == "" {
+		return &Denylist{}, nil
+	}
+	configMap := &corev1.ConfigMap{}
+	if err := reader.Get(ctx, key, configMap); err != nil {
+		if client.IgnoreNotFound(err) == nil {
+			return &Denylist{}, nil
+		}
+		return nil, err
+	}
+	d, err := Parse(configMap)
+	if err != nil {
+		// The valid patterns still apply; one typo should not lift the veto on everything else.
+		klog.ErrorS(err, "Skipped invalid patterns in the export denylist", "configMap", key)
+	}
+	return d, nil
+}
+
+// Denies returns true if the exported service with the given namespace and name matches any denied pattern.
+func (d *Denylist) Denies(namespace, name string) bool {
+	if d == nil {
+		return false
+	}
+	svc := namespace + "/" + name
+	for _, pattern := range d.patterns {
+		if matched, _ := path.Match(pattern, svc); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDenylist returns true if the object is the denylist ConfigMap with the given key; it can be used to filter
+// the ConfigMap events watched by controllers.
+func IsDenylist(obj client.Object, key types.NamespacedName) bool {
+	return key.Name != "" && obj.GetNamespace() == key.Namespace && obj.GetName() == key.Name
+}
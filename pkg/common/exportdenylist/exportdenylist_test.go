@@ -0,0 +1,127 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package exportdenylist
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	denylistNamespace = "fleet-system"
+	denylistName      = "export-denylist"
+)
+
+func denylistConfigMap(patterns string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: denylistNamespace,
+			Name:      denylistName,
+		},
+		Data: map[string]string{
+			PatternsKey: patterns,
+		},
+	}
+}
+
+// TestDenies tests the Denies function with the patterns parsed from a ConfigMap.
+func TestDenies(t *testing.T) {
+	patterns := `
+# system services are never exported
+kube-system/*
+*/secrets
+  work/db-?
+`
+	d, err := Parse(denylistConfigMap(patterns))
+	if err != nil {
+		t.Fatalf("Parse() = %v, want no error", err)
+	}
+
+	testCases := []struct {
+		namespace string
+		name      string
+		want      bool
+	}{
+		{namespace: "kube-system", name: "kube-dns", want: true},
+		{namespace: "app", name: "secrets", want: true},
+		{namespace: "work", name: "db-1", want: true},
+		{namespace: "work", name: "db-10", want: false},
+		{namespace: "work", name: "app", want: false},
+		{namespace: "kube-public", name: "app", want: false},
+	}
+	for _, tc := range testCases {
+		if got := d.Denies(tc.namespace, tc.name); got != tc.want {
+			t.Errorf("Denies(%s, %s) = %t, want %t", tc.namespace, tc.name, got, tc.want)
+		}
+	}
+
+	var nilDenylist *Denylist
+	if nilDenylist.Denies("kube-system", "kube-dns") {
+		t.Errorf("Denies() of a nil denylist = true, want false")
+	}
+}
+
+// TestParse_InvalidPatterns tests that the invalid patterns are skipped while the valid ones still apply.
+func TestParse_InvalidPatterns(t *testing.T) {
+	d, err := Parse(denylistConfigMap("kube-system\nwork/[\nkube-system/*"))
+	if err == nil {
+		t.Fatalf("Parse() = nil, want error")
+	}
+	if !d.Denies("kube-system", "kube-dns") {
+		t.Errorf("Denies(kube-system, kube-dns) = false, want true")
+	}
+	if d.Denies("work", "[") {
+		t.Errorf("Denies(work, [) = true, want false")
+	}
+}
+
+// TestGet tests the Get function.
+func TestGet(t *testing.T) {
+	key := types.NamespacedName{Namespace: denylistNamespace, Name: denylistName}
+	testCases := []struct {
+		name      string
+		configMap *corev1.ConfigMap
+		key       types.NamespacedName
+		want      bool
+	}{
+		{
+			name:      "denylist applies",
+			configMap: denylistConfigMap("work/*"),
+			key:       key,
+			want:      true,
+		},
+		{
+			name: "denylist not found",
+			key:  key,
+		},
+		{
+			name:      "denylist disabled",
+			configMap: denylistConfigMap("work/*"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithScheme(scheme.Scheme)
+			if tc.configMap != nil {
+				builder = builder.WithObjects(tc.configMap)
+			}
+			d, err := Get(context.Background(), builder.Build(), tc.key)
+			if err != nil {
+				t.Fatalf("Get() = %v, want no error", err)
+			}
+			if got := d.Denies("work", "app"); got != tc.want {
+				t.Errorf("Denies(work, app) = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
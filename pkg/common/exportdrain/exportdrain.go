@@ -0,0 +1,66 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package exportdrain features the draining of the endpoints of an exported Service as its ServiceExport is deleted.
+//
+// Withdrawing the endpoints right away cuts the in-flight connections from the importing clusters abruptly. A
+// ServiceExport annotated with a drain period keeps its Service exported for the period after it is deleted, with
+// all of its endpoints exported as terminating, i.e. not ready, so that the importing clusters stop opening new
+// connections to them while the existing ones complete; the Service is unexported once the period ends.
+package exportdrain
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/utils/ptr"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+// Period extracts the drain period from the annotation of a ServiceExport; it returns 0 if the annotation is absent,
+// and an error if the value is not a positive duration.
+func Period(svcExport *fleetnetv1alpha1.ServiceExport) (time.Duration, error) {
+	val, ok := svcExport.Annotations[objectmeta.ServiceExportAnnotationDrainPeriod]
+	if !ok {
+		return 0, nil
+	}
+	period, err := time.ParseDuration(val)
+	if err != nil || period <= 0 {
+		return 0, fmt.Errorf("the value of annotation %s must be a positive duration, got %q", objectmeta.ServiceExportAnnotationDrainPeriod, val)
+	}
+	return period, nil
+}
+
+// Remaining returns how long the Service of a ServiceExport being deleted is still drained at the given time; it
+// returns 0 if the ServiceExport is not being deleted, has no valid drain period, or has been drained. The drain
+// starts as the ServiceExport is marked for deletion.
+func Remaining(svcExport *fleetnetv1alpha1.ServiceExport, now time.Time) time.Duration {
+	if svcExport.DeletionTimestamp == nil {
+		return 0
+	}
+	// An invalid drain period is ignored, and the Service is unexported right away, as if no drain period were
+	// specified.
+	period, _ := Period(svcExport)
+	if remaining := svcExport.DeletionTimestamp.Add(period).Sub(now); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// Drain returns the endpoints marked as terminating, i.e. not ready; the endpoints keep their serving condition, so
+// that the importing clusters may still fall back to them if no other endpoints are ready.
+func Drain(endpoints []fleetnetv1alpha1.Endpoint) []fleetnetv1alpha1.Endpoint {
+	drained := make([]fleetnetv1alpha1.Endpoint, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		serving := endpoint.IsServing()
+		endpoint.Conditions.Ready = ptr.To(false)
+		endpoint.Conditions.Serving = ptr.To(serving)
+		endpoint.Conditions.Terminating = ptr.To(true)
+		drained = append(drained, endpoint)
+	}
+	return drained
+}
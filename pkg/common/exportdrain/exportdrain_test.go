@@ -0,0 +1,145 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package exportdrain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+func serviceExport(drainPeriod string, deletedAt *time.Time) *fleetnetv1alpha1.ServiceExport {
+	svcExport := &fleetnetv1alpha1.ServiceExport{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "work", Name: "app"},
+	}
+	if drainPeriod != "" {
+		svcExport.Annotations = map[string]string{objectmeta.ServiceExportAnnotationDrainPeriod: drainPeriod}
+	}
+	if deletedAt != nil {
+		svcExport.DeletionTimestamp = &metav1.Time{Time: *deletedAt}
+	}
+	return svcExport
+}
+
+// TestPeriod tests the Period function.
+func TestPeriod(t *testing.T) {
+	testCases := []struct {
+		name        string
+		drainPeriod string
+		want        time.Duration
+		wantErr     bool
+	}{
+		{
+			name: "no annotation",
+		},
+		{
+			name:        "valid drain period",
+			drainPeriod: "30s",
+			want:        time.Second * 30,
+		},
+		{
+			name:        "not a duration",
+			drainPeriod: "thirty",
+			wantErr:     true,
+		},
+		{
+			name:        "negative duration",
+			drainPeriod: "-30s",
+			wantErr:     true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Period(serviceExport(tc.drainPeriod, nil))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Period() got error %v, want error %t", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("Period() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestRemaining tests the Remaining function.
+func TestRemaining(t *testing.T) {
+	now := time.Now()
+	testCases := []struct {
+		name        string
+		drainPeriod string
+		deletedAt   *time.Time
+		want        time.Duration
+	}{
+		{
+			name:        "not deleted",
+			drainPeriod: "1m",
+		},
+		{
+			name:      "deleted with no drain period",
+			deletedAt: ptr.To(now.Add(-time.Second)),
+		},
+		{
+			name:        "deleted with an invalid drain period",
+			drainPeriod: "thirty",
+			deletedAt:   ptr.To(now.Add(-time.Second)),
+		},
+		{
+			name:        "draining",
+			drainPeriod: "1m",
+			deletedAt:   ptr.To(now.Add(-time.Second * 20)),
+			want:        time.Second * 40,
+		},
+		{
+			name:        "drained",
+			drainPeriod: "1m",
+			deletedAt:   ptr.To(now.Add(-time.Minute)),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Remaining(serviceExport(tc.drainPeriod, tc.deletedAt), now); got != tc.want {
+				t.Errorf("Remaining() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestDrain tests the Drain function.
+func TestDrain(t *testing.T) {
+	endpoints := []fleetnetv1alpha1.Endpoint{
+		{
+			Addresses: []string{"1.2.3.4"},
+		},
+		{
+			Addresses:  []string{"2.3.4.5"},
+			Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(false), Serving: ptr.To(true), Terminating: ptr.To(true)},
+		},
+	}
+	want := []fleetnetv1alpha1.Endpoint{
+		{
+			Addresses:  []string{"1.2.3.4"},
+			Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(false), Serving: ptr.To(true), Terminating: ptr.To(true)},
+		},
+		{
+			Addresses:  []string{"2.3.4.5"},
+			Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(false), Serving: ptr.To(true), Terminating: ptr.To(true)},
+		},
+	}
+	if diff := cmp.Diff(want, Drain(endpoints)); diff != "" {
+		t.Errorf("Drain() mismatch (-want, +got):\n%s", diff)
+	}
+	// The endpoints passed in are left as is.
+	if endpoints[0].Conditions.Ready != nil {
+		t.Errorf("Drain() changed the endpoints passed in")
+	}
+}
@@ -0,0 +1,187 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package exportquota features the evaluation of the ExportQuotas, which limit the services and endpoints exported
+// from each member cluster, or each namespace, so that a single noisy tenant cannot overwhelm a shared hub cluster.
+//
+// The exports in the scope of a quota are admitted in the order they are exported, the oldest first, as long as they
+// fit in the quota; an export which does not fit is rejected and takes no room, so that the exports admitted stay
+// admitted as newer exports come and go.
+package exportquota
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/uniquename"
+)
+
+// ReasonQuotaExceeded is the reason of the condition of the exports rejected by a quota.
+const ReasonQuotaExceeded = "QuotaExceeded"
+
+// export is an export counted against the quotas.
+type export struct {
+	ref       *fleetnetv1alpha1.ExportedObjectReference
+	endpoints int
+}
+
+// Check returns the reason why the internalServiceExport is rejected by the ExportQuotas, or an empty string if it
+// fits in all of them.
+func Check(ctx context.Context, reader client.Reader, internalServiceExport *fleetnetv1alpha1.InternalServiceExport) (string, error) {
+	quotaList := &fleetnetv1alpha1.ExportQuotaList{}
+	if err := reader.List(ctx, quotaList); err != nil {
+		return "", err
+	}
+	target := &internalServiceExport.Spec.ServiceReference
+	var quotas []fleetnetv1alpha1.ExportQuota
+	countEndpoints := false
+	for i := range quotaList.Items {
+		if appliesTo(&quotaList.Items[i], target) {
+			quotas = append(quotas, quotaList.Items[i])
+			countEndpoints = countEndpoints || quotaList.Items[i].Spec.MaxEndpoints != nil
+		}
+	}
+	if len(quotas) == 0 {
+		return "", nil
+	}
+
+	internalServiceExportList := &fleetnetv1alpha1.InternalServiceExportList{}
+	if err := reader.List(ctx, internalServiceExportList); err != nil {
+		return "", err
+	}
+	var endpoints map[string]int
+	if countEndpoints {
+		var err error
+		if endpoints, err = countExportedEndpoints(ctx, reader); err != nil {
+			return "", err
+		}
+	}
+	exports := make([]export, 0, len(internalServiceExportList.Items))
+	for i := range internalServiceExportList.Items {
+		item := &internalServiceExportList.Items[i]
+		if item.DeletionTimestamp != nil {
+			continue
+		}
+		ref := &item.Spec.ServiceReference
+		exports = append(exports, export{ref: ref, endpoints: endpoints[exportKey(ref.ClusterID, ref.Namespace, ref.Name)]})
+	}
+	return check(quotas, exports, target), nil
+}
+
+// appliesTo returns true if the quota applies to the export.
+func appliesTo(quota *fleetnetv1alpha1.ExportQuota, ref *fleetnetv1alpha1.ExportedObjectReference) bool {
+	return len(quota.Spec.Targets) == 0 || slices.Contains(quota.Spec.Targets, scopeOf(quota, ref))
+}
+
+// scopeOf returns the member cluster or the namespace the export is counted per against the quota.
+func scopeOf(quota *fleetnetv1alpha1.ExportQuota, ref *fleetnetv1alpha1.ExportedObjectReference) string {
+	if quota.Spec.Scope == fleetnetv1alpha1.ExportQuotaScopeNamespace {
+		return ref.Namespace
+	}
+	return ref.ClusterID
+}
+
+// check returns the reason why the first quota rejecting the target export does so; the exports must include the
+// target.
+func check(quotas []fleetnetv1alpha1.ExportQuota, exports []export, target *fleetnetv1alpha1.ExportedObjectReference) string {
+	slices.SortFunc(exports, func(a, b export) int {
+		if c := a.ref.ExportedSince.Compare(b.ref.ExportedSince.Time); c != 0 {
+			return c
+		}
+		return strings.Compare(exportKey(a.ref.ClusterID, a.ref.Namespace, a.ref.Name), exportKey(b.ref.ClusterID, b.ref.Namespace, b.ref.Name))
+	})
+	for i := range quotas {
+		if reason := checkQuota(&quotas[i], exports, target); reason != "" {
+			return reason
+		}
+	}
+	return ""
+}
+
+// checkQuota admits the exports in the scope of the target, in order, as long as they fit in the quota, and returns
+// the reason why the target is rejected, if it is.
+func checkQuota(quota *fleetnetv1alpha1.ExportQuota, exports []export, target *fleetnetv1alpha1.ExportedObjectReference) string {
+	scope := scopeOf(quota, target)
+	var services, endpoints int
+	for i := range exports {
+		if scopeOf(quota, exports[i].ref) != scope {
+			continue
+		}
+		isTarget := exports[i].ref.ClusterID == target.ClusterID && exports[i].ref.Namespace == target.Namespace && exports[i].ref.Name == target.Name
+		if quota.Spec.MaxServices != nil && services+1 > int(*quota.Spec.MaxServices) {
+			if isTarget {
+				return fmt.Sprintf("%s exceeds the quota %s of %d exported services", scopeName(quota, scope), quota.Name, *quota.Spec.MaxServices)
+			}
+			continue
+		}
+		if quota.Spec.MaxEndpoints != nil && endpoints+exports[i].endpoints > int(*quota.Spec.MaxEndpoints) {
+			if isTarget {
+				return fmt.Sprintf("%s exceeds the quota %s of %d exported endpoints with the %d endpoints of service %s/%s",
+					scopeName(quota, scope), quota.Name, *quota.Spec.MaxEndpoints, exports[i].endpoints, target.Namespace, target.Name)
+			}
+			continue
+		}
+		if isTarget {
+			return ""
+		}
+		services++
+		endpoints += exports[i].endpoints
+	}
+	return ""
+}
+
+// scopeName returns the name of the member cluster or the namespace, as reported in the condition messages.
+func scopeName(quota *fleetnetv1alpha1.ExportQuota, scope string) string {
+	if quota.Spec.Scope == fleetnetv1alpha1.ExportQuotaScopeNamespace {
+		return "namespace " + scope
+	}
+	return "member cluster " + scope
+}
+
+// countExportedEndpoints returns the number of endpoints exported with each service from each member cluster.
+func countExportedEndpoints(ctx context.Context, reader client.Reader) (map[string]int, error) {
+	endpointSliceExportList := &fleetnetv1alpha1.EndpointSliceExportList{}
+	if err := reader.List(ctx, endpointSliceExportList); err != nil {
+		return nil, err
+	}
+	endpoints := make(map[string]int)
+	for i := range endpointSliceExportList.Items {
+		endpointSliceExport := &endpointSliceExportList.Items[i]
+		if endpointSliceExport.DeletionTimestamp != nil {
+			continue
+		}
+		owner := &endpointSliceExport.Spec.OwnerServiceReference
+		endpoints[exportKey(endpointSliceExport.Spec.EndpointSliceReference.ClusterID, owner.Namespace, owner.Name)] += len(endpointSliceExport.Spec.Endpoints)
+	}
+	return endpoints, nil
+}
+
+func exportKey(clusterID, namespace, name string) string {
+	return clusterID + "/" + namespace + "/" + name
+}
+
+// IsOverQuotaExport returns true if the internalServiceExport is reported as over quota in its status.
+func IsOverQuotaExport(internalServiceExport *fleetnetv1alpha1.InternalServiceExport) bool {
+	return meta.IsStatusConditionTrue(internalServiceExport.Status.Conditions, string(fleetnetv1alpha1.ServiceExportQuotaExceeded))
+}
+
+// IsServiceOverQuota returns true if the internalServiceExport of the Service exported from the member cluster, whose
+// namespace in the hub cluster is given, is reported as over quota; a Service which is not exported is not over
+// quota.
+func IsServiceOverQuota(ctx context.Context, reader client.Reader, clusterNamespace, svcNamespace, svcName string) (bool, error) {
+	internalServiceExport := &fleetnetv1alpha1.InternalServiceExport{}
+	key := types.NamespacedName{Namespace: clusterNamespace, Name: uniquename.ClusterScopedDeterministicName(svcNamespace, svcName)}
+	if err := reader.Get(ctx, key, internalServiceExport); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	return IsOverQuotaExport(internalServiceExport), nil
+}
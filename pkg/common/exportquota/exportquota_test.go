@@ -0,0 +1,199 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package exportquota
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/uniquename"
+)
+
+var exportTime = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+func reference(clusterID, namespace, name string, age time.Duration) *fleetnetv1alpha1.ExportedObjectReference {
+	return &fleetnetv1alpha1.ExportedObjectReference{
+		ClusterID:      clusterID,
+		Namespace:      namespace,
+		Name:           name,
+		NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}.String(),
+		ExportedSince:  metav1.NewTime(exportTime.Add(-age)),
+	}
+}
+
+func quota(name string, spec fleetnetv1alpha1.ExportQuotaSpec) fleetnetv1alpha1.ExportQuota {
+	return fleetnetv1alpha1.ExportQuota{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: spec}
+}
+
+// TestCheck tests the check function.
+func TestCheck(t *testing.T) {
+	oldest := export{ref: reference("member-1", "work", "oldest", 3*time.Hour), endpoints: 5}
+	older := export{ref: reference("member-1", "work", "older", 2*time.Hour), endpoints: 20}
+	target := export{ref: reference("member-1", "work", "app", time.Hour), endpoints: 3}
+	newer := export{ref: reference("member-1", "work", "newer", 0), endpoints: 1}
+	otherCluster := export{ref: reference("member-2", "work", "other", 4*time.Hour), endpoints: 1}
+	otherNamespace := export{ref: reference("member-1", "search", "other", 4*time.Hour), endpoints: 1}
+
+	testCases := []struct {
+		name    string
+		quotas  []fleetnetv1alpha1.ExportQuota
+		exports []export
+		want    string
+	}{
+		{
+			name:    "no quota",
+			exports: []export{oldest, target},
+		},
+		{
+			name: "fits in the service quota",
+			quotas: []fleetnetv1alpha1.ExportQuota{
+				quota("small", fleetnetv1alpha1.ExportQuotaSpec{MaxServices: ptr.To[int32](2)}),
+			},
+			exports: []export{newer, target, oldest, otherCluster},
+		},
+		{
+			name: "exceeds the service quota of its cluster",
+			quotas: []fleetnetv1alpha1.ExportQuota{
+				quota("small", fleetnetv1alpha1.ExportQuotaSpec{MaxServices: ptr.To[int32](2)}),
+			},
+			exports: []export{newer, target, older, oldest, otherCluster},
+			want:    "member cluster member-1 exceeds the quota small of 2 exported services",
+		},
+		{
+			name: "exceeds the service quota of its namespace",
+			quotas: []fleetnetv1alpha1.ExportQuota{
+				quota("per-namespace", fleetnetv1alpha1.ExportQuotaSpec{
+					Scope:       fleetnetv1alpha1.ExportQuotaScopeNamespace,
+					MaxServices: ptr.To[int32](1),
+				}),
+			},
+			exports: []export{target, otherCluster, otherNamespace},
+			want:    "namespace work exceeds the quota per-namespace of 1 exported services",
+		},
+		{
+			name: "export rejected by the endpoint quota takes no room",
+			quotas: []fleetnetv1alpha1.ExportQuota{
+				quota("endpoints", fleetnetv1alpha1.ExportQuotaSpec{MaxEndpoints: ptr.To[int32](10)}),
+			},
+			exports: []export{oldest, older, target, newer},
+		},
+		{
+			name: "exceeds the endpoint quota",
+			quotas: []fleetnetv1alpha1.ExportQuota{
+				quota("endpoints", fleetnetv1alpha1.ExportQuotaSpec{MaxEndpoints: ptr.To[int32](7)}),
+			},
+			exports: []export{oldest, target},
+			want:    "member cluster member-1 exceeds the quota endpoints of 7 exported endpoints with the 3 endpoints of service work/app",
+		},
+		{
+			name: "quota does not target the cluster",
+			quotas: []fleetnetv1alpha1.ExportQuota{
+				quota("member-2", fleetnetv1alpha1.ExportQuotaSpec{Targets: []string{"member-2"}, MaxServices: ptr.To[int32](0)}),
+			},
+			exports: []export{target},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var quotas []fleetnetv1alpha1.ExportQuota
+			for i := range tc.quotas {
+				if appliesTo(&tc.quotas[i], target.ref) {
+					quotas = append(quotas, tc.quotas[i])
+				}
+			}
+			if got := check(quotas, tc.exports, target.ref); got != tc.want {
+				t.Errorf("check() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestCheck_Objects tests the Check function against the objects of the hub cluster.
+func TestCheck_Objects(t *testing.T) {
+	internalServiceExport := func(clusterID, name string, age time.Duration, deleting bool) *fleetnetv1alpha1.InternalServiceExport {
+		ise := &fleetnetv1alpha1.InternalServiceExport{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "fleet-member-" + clusterID,
+				Name:      uniquename.ClusterScopedDeterministicName("work", name),
+			},
+			Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+				ServiceReference: *reference(clusterID, "work", name, age),
+			},
+		}
+		if deleting {
+			ise.DeletionTimestamp = &metav1.Time{Time: exportTime}
+			ise.Finalizers = []string{"test"}
+		}
+		return ise
+	}
+	endpointSliceExport := func(clusterID, svcName string, endpoints int) *fleetnetv1alpha1.EndpointSliceExport {
+		return &fleetnetv1alpha1.EndpointSliceExport{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "fleet-member-" + clusterID, Name: svcName + "-slice"},
+			Spec: fleetnetv1alpha1.EndpointSliceExportSpec{
+				Endpoints:              make([]fleetnetv1alpha1.Endpoint, endpoints),
+				EndpointSliceReference: *reference(clusterID, "work", svcName+"-slice", 0),
+				OwnerServiceReference:  fleetnetv1alpha1.OwnerServiceReference{Namespace: "work", Name: svcName},
+			},
+		}
+	}
+	maxServices := quota("services", fleetnetv1alpha1.ExportQuotaSpec{MaxServices: ptr.To[int32](1)})
+	maxEndpoints := quota("endpoints", fleetnetv1alpha1.ExportQuotaSpec{MaxEndpoints: ptr.To[int32](4)})
+	target := internalServiceExport("member-1", "app", time.Hour, false)
+
+	testCases := []struct {
+		name    string
+		objects []client.Object
+		want    string
+	}{
+		{
+			name:    "no quota",
+			objects: []client.Object{target, internalServiceExport("member-1", "older", 2*time.Hour, false)},
+		},
+		{
+			name:    "older export takes the room",
+			objects: []client.Object{&maxServices, target, internalServiceExport("member-1", "older", 2*time.Hour, false)},
+			want:    "member cluster member-1 exceeds the quota services of 1 exported services",
+		},
+		{
+			name:    "deleting export leaves the room",
+			objects: []client.Object{&maxServices, target, internalServiceExport("member-1", "older", 2*time.Hour, true)},
+		},
+		{
+			name: "endpoints are counted",
+			objects: []client.Object{
+				&maxEndpoints, target, internalServiceExport("member-1", "older", 2*time.Hour, false),
+				endpointSliceExport("member-1", "older", 2), endpointSliceExport("member-1", "app", 3),
+			},
+			want: "member cluster member-1 exceeds the quota endpoints of 4 exported endpoints with the 3 endpoints of service work/app",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.objects...).Build()
+
+			got, err := Check(context.Background(), fakeClient, target)
+			if err != nil {
+				t.Fatalf("Check() = %v, want no error", err)
+			}
+			if got != tc.want {
+				t.Errorf("Check() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
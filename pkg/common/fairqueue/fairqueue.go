@@ -0,0 +1,106 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package fairqueue features a controller work queue which shares the reconcile throughput fairly among
+// namespaces, so that a storm of events in one namespace cannot starve reconciles in other namespaces.
+package fairqueue
+
+import (
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// NewQueueFunc constructs the work queue of a controller; see controller.Options.NewQueue.
+type NewQueueFunc func(controllerName string, rateLimiter workqueue.TypedRateLimiter[reconcile.Request]) workqueue.TypedRateLimitingInterface[reconcile.Request]
+
+// NewPerNamespaceQueue returns a NewQueueFunc which builds rate limiting work queues that round-robin across
+// namespaces; at most quantum requests from one namespace are handed out in a row when requests from other
+// namespaces are pending. A quantum less than 1 is treated as 1.
+func NewPerNamespaceQueue(quantum int) NewQueueFunc {
+	return func(controllerName string, rateLimiter workqueue.TypedRateLimiter[reconcile.Request]) workqueue.TypedRateLimitingInterface[reconcile.Request] {
+		queue := workqueue.NewTypedWithConfig(workqueue.TypedQueueConfig[reconcile.Request]{
+			Name:  controllerName,
+			Queue: newNamespaceQueue(quantum),
+		})
+		delayingQueue := workqueue.NewTypedDelayingQueueWithConfig(workqueue.TypedDelayingQueueConfig[reconcile.Request]{
+			Name:  controllerName,
+			Queue: queue,
+		})
+		return workqueue.NewTypedRateLimitingQueueWithConfig(rateLimiter, workqueue.TypedRateLimitingQueueConfig[reconcile.Request]{
+			Name:          controllerName,
+			DelayingQueue: delayingQueue,
+		})
+	}
+}
+
+// namespaceQueue is the underlying storage of a work queue, which keeps one FIFO queue per namespace and
+// serves the namespaces in a round-robin manner.
+//
+// Note that the work queue always calls the methods of its underlying storage with its lock held, and
+// de-duplicates the requests before pushing them.
+type namespaceQueue struct {
+	quantum int
+	queues  map[string][]reconcile.Request
+	// namespaces are the namespaces with pending requests, in the order they are served.
+	namespaces []string
+	// served is the number of requests served in a row from the namespace at the head of namespaces.
+	served int
+	length int
+}
+
+var _ workqueue.Queue[reconcile.Request] = &namespaceQueue{}
+
+func newNamespaceQueue(quantum int) *namespaceQueue {
+	if quantum < 1 {
+		quantum = 1
+	}
+	return &namespaceQueue{
+		quantum: quantum,
+		queues:  map[string][]reconcile.Request{},
+	}
+}
+
+// Touch implements workqueue.Queue; re-adding a pending request does not change its position.
+func (q *namespaceQueue) Touch(_ reconcile.Request) {}
+
+// Push implements workqueue.Queue.
+func (q *namespaceQueue) Push(item reconcile.Request) {
+	if _, ok := q.queues[item.Namespace]; !ok {
+		q.namespaces = append(q.namespaces, item.Namespace)
+	}
+	q.queues[item.Namespace] = append(q.queues[item.Namespace], item)
+	q.length++
+}
+
+// Len implements workqueue.Queue.
+func (q *namespaceQueue) Len() int {
+	return q.length
+}
+
+// Pop implements workqueue.Queue; it must only be called when the queue is not empty.
+func (q *namespaceQueue) Pop() reconcile.Request {
+	ns := q.namespaces[0]
+	pending := q.queues[ns]
+	item := pending[0]
+	pending[0] = reconcile.Request{}
+	pending = pending[1:]
+	q.length--
+	q.served++
+
+	switch {
+	case len(pending) == 0:
+		delete(q.queues, ns)
+		q.namespaces = q.namespaces[1:]
+		q.served = 0
+	case q.served >= q.quantum:
+		// Move the namespace to the back of the line.
+		q.queues[ns] = pending
+		q.namespaces = append(q.namespaces[1:], ns)
+		q.served = 0
+	default:
+		q.queues[ns] = pending
+	}
+	return item
+}
@@ -0,0 +1,113 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package fairqueue
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func request(namespace, name string) reconcile.Request {
+	return reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}}
+}
+
+// TestNamespaceQueue tests the order in which the namespaceQueue serves requests.
+func TestNamespaceQueue(t *testing.T) {
+	testCases := []struct {
+		name    string
+		quantum int
+		pushed  []reconcile.Request
+		want    []reconcile.Request
+	}{
+		{
+			name:    "single namespace is served in FIFO order",
+			quantum: 1,
+			pushed:  []reconcile.Request{request("ns1", "a"), request("ns1", "b"), request("ns1", "c")},
+			want:    []reconcile.Request{request("ns1", "a"), request("ns1", "b"), request("ns1", "c")},
+		},
+		{
+			name:    "namespaces are served in a round-robin manner",
+			quantum: 1,
+			pushed: []reconcile.Request{
+				request("ns1", "a"), request("ns1", "b"), request("ns1", "c"),
+				request("ns2", "a"), request("", "a"),
+			},
+			want: []reconcile.Request{
+				request("ns1", "a"), request("ns2", "a"), request("", "a"),
+				request("ns1", "b"), request("ns1", "c"),
+			},
+		},
+		{
+			name:    "namespaces are served quantum requests at a time",
+			quantum: 2,
+			pushed: []reconcile.Request{
+				request("ns1", "a"), request("ns1", "b"), request("ns1", "c"),
+				request("ns2", "a"), request("ns2", "b"), request("ns2", "c"),
+			},
+			want: []reconcile.Request{
+				request("ns1", "a"), request("ns1", "b"), request("ns2", "a"), request("ns2", "b"),
+				request("ns1", "c"), request("ns2", "c"),
+			},
+		},
+		{
+			name:    "invalid quantum is treated as 1",
+			quantum: 0,
+			pushed:  []reconcile.Request{request("ns1", "a"), request("ns1", "b"), request("ns2", "a")},
+			want:    []reconcile.Request{request("ns1", "a"), request("ns2", "a"), request("ns1", "b")},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := newNamespaceQueue(tc.quantum)
+			for _, item := range tc.pushed {
+				q.Push(item)
+			}
+			if got := q.Len(); got != len(tc.pushed) {
+				t.Fatalf("Len() = %d, want %d", got, len(tc.pushed))
+			}
+			var got []reconcile.Request
+			for q.Len() > 0 {
+				got = append(got, q.Pop())
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Pop() order mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestNewPerNamespaceQueue_Flood tests that a flood of requests in one namespace does not delay the requests
+// in another namespace.
+func TestNewPerNamespaceQueue_Flood(t *testing.T) {
+	q := NewPerNamespaceQueue(1)("fair-queue-test", workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+	defer q.ShutDown()
+
+	for i := 0; i < 1000; i++ {
+		q.Add(request("noisy", fmt.Sprintf("app-%d", i)))
+	}
+	quiet := request("quiet", "app")
+	q.Add(quiet)
+
+	// The first request is taken from the flooded namespace; the request from the quiet namespace must be
+	// handed out right after it, even though the flooded namespace still has plenty of pending requests.
+	for i := 0; i < 2; i++ {
+		item, shutdown := q.Get()
+		if shutdown {
+			t.Fatalf("Get() reports that the queue has shut down")
+		}
+		q.Done(item)
+		if item == quiet {
+			return
+		}
+	}
+	t.Errorf("the request from the quiet namespace is not handed out after a flood in another namespace; %d requests remain", q.Len())
+}
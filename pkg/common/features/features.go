@@ -0,0 +1,144 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package features features the feature gates of the networking controllers, which let new capabilities be shipped
+// disabled and enabled per environment with the --feature-gates flag, e.g.
+// --feature-gates=HeadlessServiceExport=false,ClusterSetIPAllocation=true.
+//
+// Each feature goes through the alpha, beta and GA stages: the alpha features are disabled by default, the beta
+// features are enabled by default, and the GA features are always enabled, so that their gates can be removed in a
+// later release.
+package features
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Feature is the name of a feature.
+type Feature string
+
+// Stage is the maturity of a feature.
+type Stage string
+
+// The stages of the features.
+const (
+	Alpha Stage = "ALPHA"
+	Beta  Stage = "BETA"
+	GA    Stage = "GA"
+)
+
+// Spec is the specification of a feature.
+type Spec struct {
+	// Stage is the maturity of the feature.
+	Stage Stage
+	// Default tells if the feature is enabled unless set otherwise.
+	Default bool
+}
+
+// The features of the networking controllers.
+const (
+	// HeadlessServiceExport exports the headless Services; the ServiceExports of the headless Services are marked as
+	// invalid otherwise.
+	HeadlessServiceExport Feature = "HeadlessServiceExport"
+
+	// ClusterSetIPAllocation allocates the ClusterSetIPs of the ServiceImports from the --clusterset-ip-cidr.
+	ClusterSetIPAllocation Feature = "ClusterSetIPAllocation"
+)
+
+var defaultFeatures = map[Feature]Spec{
+	HeadlessServiceExport:  {Stage: Beta, Default: true},
+	ClusterSetIPAllocation: {Stage: Beta, Default: true},
+}
+
+// DefaultGate is the feature gate of the controller manager, set by its --feature-gates flag.
+var DefaultGate = NewGate(defaultFeatures)
+
+// Enabled tells if the feature is enabled by the default gate.
+func Enabled(f Feature) bool {
+	return DefaultGate.Enabled(f)
+}
+
+// Gate tells which of the known features are enabled; it is set up once, before the controllers start, and is
+// read-only afterwards.
+type Gate struct {
+	known   map[Feature]Spec
+	enabled map[Feature]bool
+}
+
+// NewGate returns a gate of the given known features, all of which are set to their defaults.
+func NewGate(known map[Feature]Spec) *Gate {
+	return &Gate{known: known, enabled: map[Feature]bool{}}
+}
+
+// AddFlag registers the --feature-gates flag of the gate on the flag set.
+func (g *Gate) AddFlag(fs *flag.FlagSet) {
+	fs.Var(g, "feature-gates", "A set of key=value pairs that enable or disable the features. Options are:\n"+
+		strings.Join(g.KnownFeatures(), "\n"))
+}
+
+// Enabled tells if the feature is enabled; the unknown features are never enabled.
+func (g *Gate) Enabled(f Feature) bool {
+	if enabled, ok := g.enabled[f]; ok {
+		return enabled
+	}
+	return g.known[f].Default
+}
+
+// Set enables or disables the features per the comma-separated key=value pairs, e.g. "A=true,B=false". It
+// implements flag.Value.
+func (g *Gate) Set(value string) error {
+	enabled := map[Feature]bool{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("missing bool value for feature %q", k)
+		}
+		f := Feature(strings.TrimSpace(k))
+		spec, ok := g.known[f]
+		if !ok {
+			return fmt.Errorf("unknown feature %q", f)
+		}
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			return fmt.Errorf("invalid value %q of feature %q: %w", v, f, err)
+		}
+		if spec.Stage == GA && !b {
+			return fmt.Errorf("feature %q is GA and cannot be disabled", f)
+		}
+		enabled[f] = b
+	}
+	for f, b := range enabled {
+		g.enabled[f] = b
+	}
+	return nil
+}
+
+// String returns the features set on the gate as comma-separated key=value pairs. It implements flag.Value.
+func (g *Gate) String() string {
+	pairs := make([]string, 0, len(g.enabled))
+	for f, b := range g.enabled {
+		pairs = append(pairs, fmt.Sprintf("%s=%t", f, b))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// KnownFeatures returns the descriptions of the known features, sorted by name.
+func (g *Gate) KnownFeatures() []string {
+	descriptions := make([]string, 0, len(g.known))
+	for f, spec := range g.known {
+		descriptions = append(descriptions, fmt.Sprintf("%s=true|false (%s - default=%t)", f, spec.Stage, spec.Default))
+	}
+	sort.Strings(descriptions)
+	return descriptions
+}
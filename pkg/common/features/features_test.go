@@ -0,0 +1,111 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package features
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const (
+	alphaFeature Feature = "AlphaFeature"
+	betaFeature  Feature = "BetaFeature"
+	gaFeature    Feature = "GAFeature"
+)
+
+var testFeatures = map[Feature]Spec{
+	alphaFeature: {Stage: Alpha},
+	betaFeature:  {Stage: Beta, Default: true},
+	gaFeature:    {Stage: GA, Default: true},
+}
+
+func TestGate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		args    []string
+		want    map[Feature]bool
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			want: map[Feature]bool{alphaFeature: false, betaFeature: true, gaFeature: true},
+		},
+		{
+			name: "alpha enabled and beta disabled",
+			args: []string{"--feature-gates=AlphaFeature=true, BetaFeature=false"},
+			want: map[Feature]bool{alphaFeature: true, betaFeature: false, gaFeature: true},
+		},
+		{
+			name: "repeated flags",
+			args: []string{"--feature-gates=AlphaFeature=true", "--feature-gates=BetaFeature=false"},
+			want: map[Feature]bool{alphaFeature: true, betaFeature: false, gaFeature: true},
+		},
+		{
+			name:    "GA disabled",
+			args:    []string{"--feature-gates=GAFeature=false"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown feature",
+			args:    []string{"--feature-gates=Teleport=true"},
+			wantErr: true,
+		},
+		{
+			name:    "missing value",
+			args:    []string{"--feature-gates=AlphaFeature"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid value",
+			args:    []string{"--feature-gates=AlphaFeature=maybe"},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGate(testFeatures)
+			fs := flag.NewFlagSet(tc.name, flag.ContinueOnError)
+			g.AddFlag(fs)
+			err := fs.Parse(tc.args)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Parse() got error %v, want error %t", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			got := map[Feature]bool{}
+			for f := range testFeatures {
+				got[f] = g.Enabled(f)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Enabled() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGate_String(t *testing.T) {
+	g := NewGate(testFeatures)
+	if err := g.Set("BetaFeature=false,AlphaFeature=true"); err != nil {
+		t.Fatalf("Set() = %v, want nil", err)
+	}
+	if got, want := g.String(), "AlphaFeature=true,BetaFeature=false"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestGate_KnownFeatures(t *testing.T) {
+	want := []string{
+		"AlphaFeature=true|false (ALPHA - default=false)",
+		"BetaFeature=true|false (BETA - default=true)",
+		"GAFeature=true|false (GA - default=true)",
+	}
+	if diff := cmp.Diff(want, NewGate(testFeatures).KnownFeatures()); diff != "" {
+		t.Errorf("KnownFeatures() mismatch (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,17 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package fieldindex defines the field indexes which the hub controllers set up on the cache of the controller
+// manager, and which they share with each other and with the debug endpoints.
+package fieldindex
+
+const (
+	// InternalServiceExportServiceNamespacedName is the field index of the InternalServiceExports by the namespaced
+	// name of their service, i.e. of their ServiceImport.
+	InternalServiceExportServiceNamespacedName = ".spec.serviceReference.namespacedName"
+	// EndpointSliceExportOwnerServiceNamespacedName is the field index of the EndpointSliceExports by the namespaced
+	// name of their owner service.
+	EndpointSliceExportOwnerServiceNamespacedName = ".spec.ownerServiceReference.namespacedName"
+)
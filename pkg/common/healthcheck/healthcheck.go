@@ -0,0 +1,197 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package healthcheck features the readiness checks of a controller manager, which report it ready only when it can
+// actually work: its API server is reachable, its informer caches are synced, and, once it leads, its controllers
+// have finished the initial reconciliation of the existing objects.
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// checkTimeout is the time allowed for a check of a dependency of the controller manager.
+	checkTimeout = 5 * time.Second
+
+	// sweepPollInterval is how often the work queues are inspected for the end of the initial sweep.
+	sweepPollInterval = time.Second
+
+	// The names of the work queue metrics of the controller runtime.
+	workQueueDepthMetric          = "workqueue_depth"
+	workQueueUnfinishedWorkMetric = "workqueue_unfinished_work_seconds"
+)
+
+// SetupWithManager sets up the health and readiness checks of a controller manager. The controller manager is ready
+// once its API server is reachable, its informer caches are synced, its initial reconciliation sweep is finished
+// and, if it serves webhooks, its webhook server is started.
+//
+// The controller manager stays live as long as it serves the probes; a lost dependency makes it unready rather than
+// restarted, as a restart does not bring the dependency back.
+func SetupWithManager(mgr ctrl.Manager, serveWebhooks bool) error {
+	if err := mgr.AddHealthzCheck("ping", healthz.Ping); err != nil {
+		return err
+	}
+	apiServerCheck, err := APIServer(mgr.GetConfig())
+	if err != nil {
+		return err
+	}
+	if err := mgr.AddReadyzCheck("apiserver", apiServerCheck); err != nil {
+		return err
+	}
+	if err := mgr.AddReadyzCheck("informer-cache", CacheSync(mgr)); err != nil {
+		return err
+	}
+	sweepCheck, err := SetupSweepWithManager(mgr)
+	if err != nil {
+		return err
+	}
+	if err := mgr.AddReadyzCheck("initial-sweep", sweepCheck); err != nil {
+		return err
+	}
+	if serveWebhooks {
+		// The webhook server is only started by the manager when a webhook is registered.
+		return mgr.AddReadyzCheck("webhook", mgr.GetWebhookServer().StartedChecker())
+	}
+	return nil
+}
+
+// APIServer returns a check which verifies that the API server of the given config is reachable and healthy.
+func APIServer(cfg *rest.Config) (healthz.Checker, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	restClient := discoveryClient.RESTClient()
+	return func(req *http.Request) error {
+		ctx, cancel := context.WithTimeout(req.Context(), checkTimeout)
+		defer cancel()
+		if err := restClient.Get().AbsPath("/readyz").Do(ctx).Error(); err != nil {
+			return fmt.Errorf("API server %s is not ready: %w", cfg.Host, err)
+		}
+		return nil
+	}, nil
+}
+
+// CacheSync returns a check which verifies that the informer caches of a controller manager have synced.
+func CacheSync(mgr ctrl.Manager) healthz.Checker {
+	return func(req *http.Request) error {
+		ctx, cancel := context.WithTimeout(req.Context(), checkTimeout)
+		defer cancel()
+		if !mgr.GetCache().WaitForCacheSync(ctx) {
+			return fmt.Errorf("informer caches are not synced")
+		}
+		return nil
+	}
+}
+
+// Sweep tracks the initial reconciliation sweep of a controller manager, i.e. the reconciliation of all the objects
+// its controllers enqueue when they start leading.
+type Sweep struct {
+	elected          <-chan struct{}
+	waitForCacheSync func(ctx context.Context) bool
+	gatherer         prometheus.Gatherer
+	done             atomic.Bool
+}
+
+// NewSweep returns a Sweep which starts when the elected channel is closed, e.g. the one returned by
+// manager.Elected(), and ends when the caches are synced and the work queues reported by the gatherer are idle.
+func NewSweep(elected <-chan struct{}, waitForCacheSync func(ctx context.Context) bool, gatherer prometheus.Gatherer) *Sweep {
+	return &Sweep{
+		elected:          elected,
+		waitForCacheSync: waitForCacheSync,
+		gatherer:         gatherer,
+	}
+}
+
+// SetupSweepWithManager sets up a Sweep with a controller manager and returns its check.
+//
+// The work queues of all the controllers of the process are inspected, as the controller runtime registers their
+// metrics globally.
+func SetupSweepWithManager(mgr ctrl.Manager) (healthz.Checker, error) {
+	s := NewSweep(mgr.Elected(), mgr.GetCache().WaitForCacheSync, ctrlmetrics.Registry)
+	if err := mgr.Add(s); err != nil {
+		return nil, err
+	}
+	return s.Check, nil
+}
+
+// Start implements manager.Runnable; it waits for the caches to sync, then for the work queues to be idle in two
+// consecutive inspections, so that the objects enqueued by the informers right after the sync are accounted for.
+func (s *Sweep) Start(ctx context.Context) error {
+	if !s.waitForCacheSync(ctx) {
+		return nil
+	}
+	idle := false
+	ticker := time.NewTicker(sweepPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		busy, err := s.workQueuesBusy()
+		if err != nil {
+			klog.ErrorS(err, "Failed to inspect the work queues for the initial sweep")
+			continue
+		}
+		if !busy && idle {
+			klog.V(2).InfoS("Finished the initial reconciliation sweep")
+			s.done.Store(true)
+			return nil
+		}
+		idle = !busy
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable; the controllers only sweep once they lead.
+func (s *Sweep) NeedLeaderElection() bool {
+	return true
+}
+
+// Check implements healthz.Checker; the standby replicas, whose controllers do not run, are always ready.
+func (s *Sweep) Check(_ *http.Request) error {
+	select {
+	case <-s.elected:
+	default:
+		return nil
+	}
+	if !s.done.Load() {
+		return fmt.Errorf("the initial reconciliation sweep is in progress")
+	}
+	return nil
+}
+
+// workQueuesBusy reports whether any work queue has items waiting or being processed.
+func (s *Sweep) workQueuesBusy() (bool, error) {
+	families, err := s.gatherer.Gather()
+	if err != nil {
+		return false, err
+	}
+	for _, family := range families {
+		if family.GetName() != workQueueDepthMetric && family.GetName() != workQueueUnfinishedWorkMetric {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			if m.GetGauge().GetValue() > 0 {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
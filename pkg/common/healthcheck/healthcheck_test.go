@@ -0,0 +1,96 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/rest"
+)
+
+// TestSweep tests that a leading controller manager is ready only after its work queues are drained.
+func TestSweep(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	depth := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: workQueueDepthMetric}, []string{"name"})
+	registry.MustRegister(depth)
+	depth.WithLabelValues("serviceexport").Set(3)
+
+	elected := make(chan struct{})
+	s := NewSweep(elected, func(context.Context) bool { return true }, registry)
+	if err := s.Check(nil); err != nil {
+		t.Fatalf("Check() before the election = %v, want no error", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := s.Start(ctx); err != nil {
+			t.Errorf("Start() = %v, want no error", err)
+		}
+	}()
+	close(elected)
+	time.Sleep(2 * sweepPollInterval)
+	if err := s.Check(nil); err == nil {
+		t.Fatalf("Check() with items in the work queues = nil, want error")
+	}
+
+	depth.WithLabelValues("serviceexport").Set(0)
+	select {
+	case <-done:
+	case <-time.After(5 * sweepPollInterval):
+		t.Fatalf("Start() did not return after the work queues were drained")
+	}
+	if err := s.Check(nil); err != nil {
+		t.Errorf("Check() after the sweep = %v, want no error", err)
+	}
+}
+
+// TestAPIServer tests the API server check.
+func TestAPIServer(t *testing.T) {
+	testCases := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{
+			name:   "ready",
+			status: http.StatusOK,
+		},
+		{
+			name:    "not ready",
+			status:  http.StatusInternalServerError,
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/readyz" {
+					http.NotFound(w, r)
+					return
+				}
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+
+			check, err := APIServer(&rest.Config{Host: server.URL})
+			if err != nil {
+				t.Fatalf("APIServer() = %v, want no error", err)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+			if err := check(req); (err != nil) != tc.wantErr {
+				t.Errorf("check() = %v, want error %t", err, tc.wantErr)
+			}
+		})
+	}
+}
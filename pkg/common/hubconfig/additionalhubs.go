@@ -0,0 +1,74 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package hubconfig
+
+import (
+	"fmt"
+	"os"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+// AdditionalHub is a hub cluster the member cluster joins besides its primary hub cluster, e.g. the hub cluster of
+// the fleet it migrates to, or of another fleet it participates in.
+type AdditionalHub struct {
+	// Name identifies the hub cluster in the member cluster; the ServiceImports annotated with it are imported from
+	// the hub cluster.
+	Name string `json:"name"`
+
+	// Kubeconfig is the path to the kubeconfig of the hub cluster.
+	Kubeconfig string `json:"kubeconfig"`
+
+	// Namespace is the namespace reserved for the member cluster in the hub cluster; it defaults to the namespace
+	// named after the member cluster, as in the primary hub cluster.
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// additionalHubsFile is the format of the file listing the additional hub clusters.
+type additionalHubsFile struct {
+	Hubs []AdditionalHub `json:"hubs"`
+}
+
+// LoadAdditionalHubs reads the additional hub clusters of the member cluster from the file at the given path.
+func LoadAdditionalHubs(path, memberClusterName string) ([]AdditionalHub, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the additional hubs file: %w", err)
+	}
+	file := &additionalHubsFile{}
+	if err := yaml.UnmarshalStrict(data, file); err != nil {
+		return nil, fmt.Errorf("failed to parse the additional hubs file %s: %w", path, err)
+	}
+
+	names := make(map[string]bool, len(file.Hubs))
+	for i := range file.Hubs {
+		hub := &file.Hubs[i]
+		if errs := validation.IsDNS1123Label(hub.Name); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid name %q of additional hub %d: %v", hub.Name, i, errs)
+		}
+		if names[hub.Name] {
+			return nil, fmt.Errorf("duplicate additional hub %q", hub.Name)
+		}
+		names[hub.Name] = true
+		if hub.Kubeconfig == "" {
+			return nil, fmt.Errorf("the kubeconfig of additional hub %q is not set", hub.Name)
+		}
+		if hub.Namespace == "" {
+			hub.Namespace = fmt.Sprintf(HubNamespaceNameFormat, memberClusterName)
+		}
+	}
+	return file.Hubs, nil
+}
+
+// ImportingHub returns the name of the additional hub cluster the ServiceImport is imported from, or an empty string
+// if it is imported from the primary hub cluster.
+func ImportingHub(svcImport metav1.Object) string {
+	return svcImport.GetAnnotations()[objectmeta.ServiceImportAnnotationHub]
+}
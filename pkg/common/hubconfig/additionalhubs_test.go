@@ -0,0 +1,101 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package hubconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoadAdditionalHubs(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		want    []AdditionalHub
+		wantErr bool
+	}{
+		{
+			name: "hubs with the default and a custom namespace",
+			content: `
+hubs:
+- name: west
+  kubeconfig: /etc/fleet/hubs/west/kubeconfig
+- name: east
+  kubeconfig: /etc/fleet/hubs/east/kubeconfig
+  namespace: fleet-member-east-1
+`,
+			want: []AdditionalHub{
+				{Name: "west", Kubeconfig: "/etc/fleet/hubs/west/kubeconfig", Namespace: "fleet-member-member-1"},
+				{Name: "east", Kubeconfig: "/etc/fleet/hubs/east/kubeconfig", Namespace: "fleet-member-east-1"},
+			},
+		},
+		{
+			name:    "no hubs",
+			content: "hubs: []\n",
+			want:    []AdditionalHub{},
+		},
+		{
+			name: "invalid name",
+			content: `
+hubs:
+- name: West_Hub
+  kubeconfig: /etc/fleet/hubs/west/kubeconfig
+`,
+			wantErr: true,
+		},
+		{
+			name: "duplicate name",
+			content: `
+hubs:
+- name: west
+  kubeconfig: /etc/fleet/hubs/west/kubeconfig
+- name: west
+  kubeconfig: /etc/fleet/hubs/west-2/kubeconfig
+`,
+			wantErr: true,
+		},
+		{
+			name: "missing kubeconfig",
+			content: `
+hubs:
+- name: west
+`,
+			wantErr: true,
+		},
+		{
+			name: "unknown field",
+			content: `
+hubs:
+- name: west
+  kubeconfig: /etc/fleet/hubs/west/kubeconfig
+  server: https://west
+`,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "hubs.yaml")
+			if err := os.WriteFile(path, []byte(tc.content), 0600); err != nil {
+				t.Fatalf("WriteFile() = %v, want no error", err)
+			}
+			got, err := LoadAdditionalHubs(path, "member-1")
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("LoadAdditionalHubs() got err %v, want err %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("LoadAdditionalHubs() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package hubconfig
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// AuthModeToken authenticates to the hub cluster with the token in the file at CONFIG_PATH, which is kept
+	// current by the refresh-token container.
+	AuthModeToken = "token"
+	// AuthModeWorkloadIdentity authenticates to the hub cluster with an Azure AD token of the workload identity of
+	// the agent, federated with its service account; the workload identity webhook sets the AZURE_CLIENT_ID,
+	// AZURE_TENANT_ID and AZURE_FEDERATED_TOKEN_FILE environment variables it is read from.
+	AuthModeWorkloadIdentity = "workload-identity"
+	// AuthModeManagedIdentity authenticates to the hub cluster with an Azure AD token of the managed identity of the
+	// member cluster nodes, e.g. the kubelet identity of an AKS cluster.
+	AuthModeManagedIdentity = "managed-identity"
+
+	// DefaultAADServerAppID is the ID of the Azure AD server application of the AKS clusters with the AKS-managed
+	// Azure AD integration, which the tokens to their API servers are issued for.
+	DefaultAADServerAppID = "6dae42f8-4368-4678-94ff-3960e28e3630"
+
+	// tokenRefreshMargin is how long before its expiry an Azure AD token is refreshed.
+	tokenRefreshMargin = 5 * time.Minute
+)
+
+// AzureAuthOptions are the options of the authentication to the hub cluster with an Azure AD token.
+type AzureAuthOptions struct {
+	// Mode is AuthModeWorkloadIdentity or AuthModeManagedIdentity.
+	Mode string
+	// ServerAppID is the ID of the Azure AD server application the tokens are issued for.
+	ServerAppID string
+	// ClientID is the client ID of the identity; for a workload identity, it overrides AZURE_CLIENT_ID, and for a
+	// managed identity, it picks a user-assigned identity rather than the system-assigned one.
+	ClientID string
+}
+
+// ValidateAuthMode returns an error if the mode of the authentication to the hub cluster is not supported.
+func ValidateAuthMode(mode string) error {
+	switch mode {
+	case AuthModeToken, AuthModeWorkloadIdentity, AuthModeManagedIdentity:
+		return nil
+	default:
+		return fmt.Errorf("unsupported hub authentication mode %q, want one of %q, %q or %q",
+			mode, AuthModeToken, AuthModeWorkloadIdentity, AuthModeManagedIdentity)
+	}
+}
+
+// NewAzureCredential returns the credential of the identity the agent authenticates to the hub cluster with.
+func NewAzureCredential(opts AzureAuthOptions) (azcore.TokenCredential, error) {
+	switch opts.Mode {
+	case AuthModeWorkloadIdentity:
+		return azidentity.NewWorkloadIdentityCredential(&azidentity.WorkloadIdentityCredentialOptions{ClientID: opts.ClientID})
+	case AuthModeManagedIdentity:
+		var id azidentity.ManagedIDKind
+		if opts.ClientID != "" {
+			id = azidentity.ClientID(opts.ClientID)
+		}
+		return azidentity.NewManagedIdentityCredential(&azidentity.ManagedIdentityCredentialOptions{ID: id})
+	default:
+		return nil, fmt.Errorf("hub authentication mode %q does not use an Azure identity", opts.Mode)
+	}
+}
+
+// PrepareHubConfigWithAzureCredential returns the config for a Kubernetes client to request the hub cluster with
+// the Azure AD tokens of the given credential, issued for the given server application, rather than with a token
+// file; no long-lived secret of the hub cluster is then distributed to the member cluster.
+func PrepareHubConfigWithAzureCredential(tlsClientInsecure bool, cred azcore.TokenCredential, serverAppID string) (*rest.Config, error) {
+	hubConfig, err := prepareHubConfig(tlsClientInsecure, "")
+	if err != nil {
+		return nil, err
+	}
+	hubConfig.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return newAzureTokenRoundTripper(cred, serverAppID, rt)
+	})
+	return hubConfig, nil
+}
+
+// azureTokenRoundTripper sets the Azure AD token of a credential as the bearer token of the requests.
+type azureTokenRoundTripper struct {
+	cred  azcore.TokenCredential
+	scope string
+	rt    http.RoundTripper
+
+	mu    sync.Mutex
+	token azcore.AccessToken
+}
+
+func newAzureTokenRoundTripper(cred azcore.TokenCredential, serverAppID string, rt http.RoundTripper) *azureTokenRoundTripper {
+	return &azureTokenRoundTripper{
+		cred:  cred,
+		scope: serverAppID + "/.default",
+		rt:    rt,
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (rt *azureTokenRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if len(req.Header.Get("Authorization")) != 0 {
+		return rt.rt.RoundTrip(req)
+	}
+	token, err := rt.accessToken(req)
+	if err != nil {
+		return nil, err
+	}
+	req = utilnet.CloneRequest(req)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := rt.rt.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized {
+		// The token may have been revoked, e.g. on a change of the role assignments; a new one is acquired for the
+		// next request.
+		rt.mu.Lock()
+		if rt.token.Token == token {
+			rt.token = azcore.AccessToken{}
+		}
+		rt.mu.Unlock()
+	}
+	return resp, err
+}
+
+// accessToken returns the cached token, or acquires a new one if it expires soon.
+func (rt *azureTokenRoundTripper) accessToken(req *http.Request) (string, error) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if rt.token.Token != "" && time.Until(rt.token.ExpiresOn) > tokenRefreshMargin {
+		return rt.token.Token, nil
+	}
+	token, err := rt.cred.GetToken(req.Context(), policy.TokenRequestOptions{Scopes: []string{rt.scope}})
+	if err != nil {
+		klog.ErrorS(err, "Failed to acquire the Azure AD token of the hub cluster", "scope", rt.scope)
+		return "", fmt.Errorf("failed to acquire the Azure AD token of the hub cluster: %w", err)
+	}
+	klog.V(4).InfoS("Acquired the Azure AD token of the hub cluster", "scope", rt.scope, "expiresOn", token.ExpiresOn)
+	rt.token = token
+	return token.Token, nil
+}
+
+// WrappedRoundTripper implements utilnet.RoundTripperWrapper.
+func (rt *azureTokenRoundTripper) WrappedRoundTripper() http.RoundTripper {
+	return rt.rt
+}
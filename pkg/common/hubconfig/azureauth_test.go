@@ -0,0 +1,110 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package hubconfig
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// fakeCredential issues the tokens "token-1", "token-2", ..., which expire after the given lifetime.
+type fakeCredential struct {
+	lifetime time.Duration
+	scopes   [][]string
+}
+
+func (c *fakeCredential) GetToken(_ context.Context, opts policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	c.scopes = append(c.scopes, opts.Scopes)
+	return azcore.AccessToken{
+		Token:     fmt.Sprintf("token-%d", len(c.scopes)),
+		ExpiresOn: time.Now().Add(c.lifetime),
+	}, nil
+}
+
+// TestAzureTokenRoundTripper tests that the requests carry the Azure AD token, which is cached until it expires
+// soon or is rejected.
+func TestAzureTokenRoundTripper(t *testing.T) {
+	testCases := []struct {
+		name       string
+		lifetime   time.Duration
+		rejected   bool
+		wantTokens []string
+	}{
+		{
+			name:       "token cached",
+			lifetime:   time.Hour,
+			wantTokens: []string{"token-1", "token-1"},
+		},
+		{
+			name:       "token expiring soon",
+			lifetime:   time.Minute,
+			wantTokens: []string{"token-1", "token-2"},
+		},
+		{
+			name:       "token rejected",
+			lifetime:   time.Hour,
+			rejected:   true,
+			wantTokens: []string{"token-1", "token-2"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotTokens []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				gotTokens = append(gotTokens, req.Header.Get("Authorization")[len("Bearer "):])
+				if tc.rejected {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			cred := &fakeCredential{lifetime: tc.lifetime}
+			httpClient := &http.Client{Transport: newAzureTokenRoundTripper(cred, DefaultAADServerAppID, http.DefaultTransport)}
+			for range 2 {
+				resp, err := httpClient.Get(server.URL)
+				if err != nil {
+					t.Fatalf("Get() = %v, want no error", err)
+				}
+				resp.Body.Close()
+			}
+
+			if len(gotTokens) != len(tc.wantTokens) {
+				t.Fatalf("got tokens %v, want %v", gotTokens, tc.wantTokens)
+			}
+			for i := range gotTokens {
+				if gotTokens[i] != tc.wantTokens[i] {
+					t.Errorf("request %d token = %q, want %q", i, gotTokens[i], tc.wantTokens[i])
+				}
+			}
+			for _, scopes := range cred.scopes {
+				if len(scopes) != 1 || scopes[0] != DefaultAADServerAppID+"/.default" {
+					t.Errorf("GetToken() scopes = %v, want [%s/.default]", scopes, DefaultAADServerAppID)
+				}
+			}
+		})
+	}
+}
+
+// TestValidateAuthMode tests the validation of the hub authentication modes.
+func TestValidateAuthMode(t *testing.T) {
+	for _, mode := range []string{AuthModeToken, AuthModeWorkloadIdentity, AuthModeManagedIdentity} {
+		if err := ValidateAuthMode(mode); err != nil {
+			t.Errorf("ValidateAuthMode(%q) = %v, want no error", mode, err)
+		}
+	}
+	if err := ValidateAuthMode("kubeconfig"); err == nil {
+		t.Errorf("ValidateAuthMode(%q) = nil, want error", "kubeconfig")
+	}
+}
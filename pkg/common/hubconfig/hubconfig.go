@@ -40,12 +40,6 @@ const (
 // PrepareHubConfig return the config holding attributes for a Kubernetes client to request hub cluster.
 // Called must make sure all required environment variables are well set.
 func PrepareHubConfig(tlsClientInsecure bool) (*rest.Config, error) {
-	hubURL, err := env.Lookup(hubServerURLEnvKey)
-	if err != nil {
-		klog.ErrorS(err, "Hub cluster endpoint URL cannot be empty")
-		return nil, err
-	}
-
 	tokenFilePath, err := env.Lookup(tokenConfigPathEnvKey)
 	if err != nil {
 		klog.ErrorS(err, "Hub token file path cannot be empty")
@@ -63,6 +57,18 @@ func PrepareHubConfig(tlsClientInsecure bool) (*rest.Config, error) {
 		klog.ErrorS(err, "Cannot retrieve token file from the path %s", tokenFilePath)
 		return nil, err
 	}
+	return prepareHubConfig(tlsClientInsecure, tokenFilePath)
+}
+
+// prepareHubConfig returns the config to request the hub cluster with the token in the given file, or with no
+// credentials if the path is empty.
+func prepareHubConfig(tlsClientInsecure bool, tokenFilePath string) (*rest.Config, error) {
+	hubURL, err := env.Lookup(hubServerURLEnvKey)
+	if err != nil {
+		klog.ErrorS(err, "Hub cluster endpoint URL cannot be empty")
+		return nil, err
+	}
+
 	var hubConfig *rest.Config
 	if tlsClientInsecure {
 		hubConfig = &rest.Config{
@@ -0,0 +1,213 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package hubconfig
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+)
+
+// The reasons of the failures of the connections through the egress proxy.
+const (
+	// ProxyFailureDial is the failure to open a TCP connection to the proxy.
+	ProxyFailureDial = "dial"
+	// ProxyFailureTLS is the failure of the TLS handshake with an HTTPS proxy.
+	ProxyFailureTLS = "tls"
+	// ProxyFailureConnect is the failure of the CONNECT request, e.g. when the proxy denies the hub API server.
+	ProxyFailureConnect = "connect"
+)
+
+var (
+	// proxyConnectionFailures counts the failures of the connections to the hub API server through the egress proxy.
+	proxyConnectionFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metrics.MetricsNamespace,
+			Subsystem: metrics.MetricsSubsystem,
+			Name:      "hub_proxy_connection_failures_total",
+			Help:      "Total number of failures of the connections to the hub API server through the egress proxy",
+		},
+		[]string{
+			// The host of the proxy.
+			"proxy",
+			// Why the connection failed, i.e. dial, tls or connect.
+			"reason",
+		},
+	)
+)
+
+func init() {
+	// Register proxyConnectionFailures (fleet_networking_hub_proxy_connection_failures_total) metric with the
+	// controller runtime global metrics registry.
+	ctrlmetrics.Registry.MustRegister(proxyConnectionFailures)
+}
+
+// ProxyOptions are the options of the egress proxy the agent reaches the hub API server through.
+type ProxyOptions struct {
+	// URL is the URL of the proxy, with the http or https scheme; the user info of the URL, if any, authenticates to
+	// the proxy with the basic authentication scheme.
+	URL string
+	// CAFile is the path to the PEM-encoded certificates the certificate of an HTTPS proxy is verified with; the
+	// certificate is verified with the system roots if empty.
+	CAFile string
+}
+
+// proxyDialer opens the connections to the hub API server through an HTTP CONNECT tunnel of the egress proxy.
+type proxyDialer struct {
+	proxyURL  *url.URL
+	proxyAddr string
+	auth      string
+	tlsConfig *tls.Config
+	dialer    net.Dialer
+}
+
+func newProxyDialer(opts ProxyOptions) (*proxyDialer, error) {
+	proxyURL, err := url.Parse(opts.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	d := &proxyDialer{
+		proxyURL: proxyURL,
+		dialer:   net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second},
+	}
+	port := proxyURL.Port()
+	switch proxyURL.Scheme {
+	case "http":
+		if port == "" {
+			port = "80"
+		}
+		if opts.CAFile != "" {
+			return nil, fmt.Errorf("the CA file is only applicable to an HTTPS proxy, got %s", proxyURL.Redacted())
+		}
+	case "https":
+		if port == "" {
+			port = "443"
+		}
+		d.tlsConfig = &tls.Config{
+			ServerName: proxyURL.Hostname(),
+			MinVersion: tls.VersionTLS12,
+		}
+		if opts.CAFile != "" {
+			data, err := os.ReadFile(opts.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read the proxy CA file: %w", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(data) {
+				return nil, fmt.Errorf("no certificate found in the proxy CA file %s", opts.CAFile)
+			}
+			d.tlsConfig.RootCAs = pool
+		}
+	default:
+		return nil, fmt.Errorf("unsupported scheme of the proxy URL %s, want http or https", proxyURL.Redacted())
+	}
+	if proxyURL.Hostname() == "" {
+		return nil, fmt.Errorf("the proxy URL %s has no host", proxyURL.Redacted())
+	}
+	d.proxyAddr = net.JoinHostPort(proxyURL.Hostname(), port)
+	if user := proxyURL.User; user != nil {
+		password, _ := user.Password()
+		d.auth = "Basic " + base64.StdEncoding.EncodeToString([]byte(user.Username()+":"+password))
+	}
+	return d, nil
+}
+
+// DialContext opens a connection to the given address through the proxy.
+func (d *proxyDialer) DialContext(ctx context.Context, _, addr string) (net.Conn, error) {
+	conn, err := d.dialer.DialContext(ctx, "tcp", d.proxyAddr)
+	if err != nil {
+		return nil, d.fail(ProxyFailureDial, addr, err)
+	}
+	if d.tlsConfig != nil {
+		tlsConn := tls.Client(conn, d.tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, d.fail(ProxyFailureTLS, addr, err)
+		}
+		conn = tlsConn
+	}
+
+	// The CONNECT request is bound to the deadline of the dial, rather than of the requests sent over the tunnel.
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+		defer func() { _ = conn.SetDeadline(time.Time{}) }()
+	}
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: http.Header{},
+	}
+	if d.auth != "" {
+		req.Header.Set("Proxy-Authorization", d.auth)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, d.fail(ProxyFailureConnect, addr, err)
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, d.fail(ProxyFailureConnect, addr, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, d.fail(ProxyFailureConnect, addr, fmt.Errorf("the proxy responded %s", resp.Status))
+	}
+	if br.Buffered() > 0 {
+		// The bytes the server sent through the tunnel along with the response are not lost.
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
+
+func (d *proxyDialer) fail(reason, addr string, err error) error {
+	proxyConnectionFailures.WithLabelValues(d.proxyURL.Host, reason).Inc()
+	klog.V(2).InfoS("Failed to connect to the hub API server through the proxy", "proxy", d.proxyURL.Redacted(), "address", addr, "reason", reason, "err", err)
+	return fmt.Errorf("failed to connect to %s through proxy %s: %w", addr, d.proxyURL.Redacted(), err)
+}
+
+// bufferedConn is a connection whose first bytes have been read into a buffer.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+// SetProxy makes the clients of the config reach the hub API server through the egress proxy, in place of the
+// proxy of the kubeconfig or the environment, e.g. HTTPS_PROXY.
+func SetProxy(config *rest.Config, opts ProxyOptions) error {
+	d, err := newProxyDialer(opts)
+	if err != nil {
+		return err
+	}
+	config.Dial = d.DialContext
+	// The connections are tunneled by the dialer; no other proxy is stacked on top of it.
+	config.Proxy = func(*http.Request) (*url.URL, error) {
+		return nil, nil
+	}
+	return nil
+}
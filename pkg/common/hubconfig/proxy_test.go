@@ -0,0 +1,187 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package hubconfig
+
+import (
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/client-go/rest"
+)
+
+// connectProxy is a handler of a fake egress proxy, which tunnels the CONNECT requests with the expected
+// Proxy-Authorization header.
+type connectProxy struct {
+	auth string
+
+	mu        sync.Mutex
+	connected []string
+}
+
+func (p *connectProxy) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodConnect {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if req.Header.Get("Proxy-Authorization") != p.auth {
+		w.WriteHeader(http.StatusProxyAuthRequired)
+		return
+	}
+	target, err := net.Dial("tcp", req.Host)
+	if err != nil {
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+	p.mu.Lock()
+	p.connected = append(p.connected, req.Host)
+	p.mu.Unlock()
+	conn, _, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		target.Close()
+		return
+	}
+	if _, err := io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n"); err != nil {
+		target.Close()
+		conn.Close()
+		return
+	}
+	go func() {
+		defer target.Close()
+		_, _ = io.Copy(target, conn)
+	}()
+	go func() {
+		defer conn.Close()
+		_, _ = io.Copy(conn, target)
+	}()
+}
+
+// TestSetProxy tests that the clients reach the hub API server through the egress proxy.
+func TestSetProxy(t *testing.T) {
+	hub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer hub.Close()
+	hubURL, _ := url.Parse(hub.URL)
+
+	testCases := []struct {
+		name        string
+		tls         bool
+		auth        string
+		userInfo    *url.Userinfo
+		wantErr     bool
+		wantFailure string
+	}{
+		{
+			name: "http proxy",
+		},
+		{
+			name:     "https proxy with basic authentication",
+			tls:      true,
+			auth:     "Basic dXNlcjpwYXNz",
+			userInfo: url.UserPassword("user", "pass"),
+		},
+		{
+			name:        "proxy authentication required",
+			auth:        "Basic dXNlcjpwYXNz",
+			wantErr:     true,
+			wantFailure: ProxyFailureConnect,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := &connectProxy{auth: tc.auth}
+			opts := ProxyOptions{}
+			var proxy *httptest.Server
+			if tc.tls {
+				proxy = httptest.NewTLSServer(handler)
+				caFile := filepath.Join(t.TempDir(), "ca.crt")
+				data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: proxy.Certificate().Raw})
+				if err := os.WriteFile(caFile, data, 0600); err != nil {
+					t.Fatalf("failed to write the CA file: %v", err)
+				}
+				opts.CAFile = caFile
+			} else {
+				proxy = httptest.NewServer(handler)
+			}
+			defer proxy.Close()
+			proxyURL, _ := url.Parse(proxy.URL)
+			proxyURL.User = tc.userInfo
+			opts.URL = proxyURL.String()
+
+			config := &rest.Config{Host: hub.URL}
+			if err := SetProxy(config, opts); err != nil {
+				t.Fatalf("SetProxy() = %v, want no error", err)
+			}
+			httpClient, err := rest.HTTPClientFor(config)
+			if err != nil {
+				t.Fatalf("HTTPClientFor() = %v, want no error", err)
+			}
+			failures := func() float64 {
+				return testutil.ToFloat64(proxyConnectionFailures.WithLabelValues(proxyURL.Host, tc.wantFailure))
+			}
+			before := failures()
+
+			resp, err := httpClient.Get(hub.URL)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("Get() = %v, want error %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				if got := failures() - before; got != 1 {
+					t.Errorf("proxy connection failures (%s) = %v, want 1", tc.wantFailure, got)
+				}
+				return
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("Get() status = %d, want %d", resp.StatusCode, http.StatusOK)
+			}
+			if len(handler.connected) != 1 || handler.connected[0] != hubURL.Host {
+				t.Errorf("proxy tunneled %v, want [%s]", handler.connected, hubURL.Host)
+			}
+		})
+	}
+}
+
+// TestSetProxy_InvalidOptions tests the validation of the proxy options.
+func TestSetProxy_InvalidOptions(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts ProxyOptions
+	}{
+		{
+			name: "unsupported scheme",
+			opts: ProxyOptions{URL: "socks5://proxy.example.com:1080"},
+		},
+		{
+			name: "no host",
+			opts: ProxyOptions{URL: "http://"},
+		},
+		{
+			name: "CA file of an http proxy",
+			opts: ProxyOptions{URL: "http://proxy.example.com:3128", CAFile: "ca.crt"},
+		},
+		{
+			name: "missing CA file",
+			opts: ProxyOptions{URL: "https://proxy.example.com:3128", CAFile: filepath.Join(t.TempDir(), "ca.crt")},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := SetProxy(&rest.Config{}, tc.opts); err == nil {
+				t.Errorf("SetProxy() = nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package hubconfig
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+)
+
+var (
+	// credentialReloads counts the reloads of the credentials of a hub cluster after their files have changed.
+	credentialReloads = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metrics.MetricsNamespace,
+			Subsystem: metrics.MetricsSubsystem,
+			Name:      "hub_credential_reloads_total",
+			Help:      "Total number of reloads of the credentials of a hub cluster",
+		},
+		[]string{
+			// The name of the hub cluster, "primary" for the primary hub cluster.
+			"hub",
+			// Whether the reload succeeded, "success", or failed, "error".
+			"result",
+		},
+	)
+)
+
+func init() {
+	// Register credentialReloads (fleet_networking_hub_credential_reloads_total) metric with the controller runtime
+	// global metrics registry.
+	ctrlmetrics.Registry.MustRegister(credentialReloads)
+}
+
+// CredentialReloader keeps the credentials used to reach a hub cluster current without restarting the agent: it
+// watches the files the credentials come from, e.g. the token file or the kubeconfig file mounted from a Secret,
+// and rebuilds the transport to the hub cluster whenever they change.
+//
+// The clients built from Config send their requests through the current transport, so the caches and the watches
+// of a controller manager carry on over a rotation; the requests in flight complete with the previous credentials.
+type CredentialReloader struct {
+	hub   string
+	load  func() (*rest.Config, error)
+	files []string
+
+	// Interval is how often the files are checked for changes.
+	Interval time.Duration
+	// Backoff is how long to wait before reloading the credentials again after a failure, e.g. while a rotated file
+	// is only partially written; the wait grows up to Interval.
+	Backoff wait.Backoff
+
+	host      string
+	mu        sync.RWMutex
+	transport http.RoundTripper
+	digest    [sha256.Size]byte
+	// unauthorized wakes the reloader up when the hub cluster rejects the current credentials.
+	unauthorized chan struct{}
+}
+
+// NewCredentialReloader loads the config of the named hub cluster with load, and returns a CredentialReloader which
+// loads it again whenever any of the given files changes.
+func NewCredentialReloader(hub string, load func() (*rest.Config, error), files ...string) (*CredentialReloader, error) {
+	r := &CredentialReloader{
+		hub:      hub,
+		load:     load,
+		files:    files,
+		Interval: 30 * time.Second,
+		Backoff: wait.Backoff{
+			Duration: time.Second,
+			Factor:   2,
+			Jitter:   0.1,
+			Steps:    math.MaxInt32,
+		},
+		unauthorized: make(chan struct{}, 1),
+	}
+	digest, err := r.fileDigest()
+	if err != nil {
+		return nil, err
+	}
+	config, err := load()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.swap(config, digest); err != nil {
+		return nil, err
+	}
+	r.host = config.Host
+	return r, nil
+}
+
+// Config returns the config of the clients of the hub cluster, which reach it through the current transport; the
+// callers may set its rate limits and timeout, but not its credentials, which come from the loaded config.
+func (r *CredentialReloader) Config() *rest.Config {
+	return &rest.Config{
+		Host: r.host,
+		WrapTransport: func(http.RoundTripper) http.RoundTripper {
+			return r
+		},
+	}
+}
+
+// RoundTrip implements http.RoundTripper; it sends the request through the current transport.
+func (r *CredentialReloader) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.mu.RLock()
+	transport := r.transport
+	r.mu.RUnlock()
+	resp, err := transport.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized {
+		// The credentials may have been rotated since the files were last checked.
+		select {
+		case r.unauthorized <- struct{}{}:
+		default:
+		}
+	}
+	return resp, err
+}
+
+// Start implements manager.Runnable; it checks the files every interval, or as soon as the hub cluster rejects
+// the credentials, and reloads the credentials when the files have changed, backing off while the reload fails.
+func (r *CredentialReloader) Start(ctx context.Context) error {
+	backoff := r.Backoff
+	backoff.Cap = r.Interval
+	delay := r.Interval
+	for {
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		case <-r.unauthorized:
+			timer.Stop()
+		}
+
+		if err := r.reload(); err != nil {
+			credentialReloads.WithLabelValues(r.hub, "error").Inc()
+			delay = backoff.Step()
+			klog.ErrorS(err, "Failed to reload the credentials of the hub cluster", "hub", r.hub, "retryAfter", delay)
+			continue
+		}
+		backoff = r.Backoff
+		backoff.Cap = r.Interval
+		delay = r.Interval
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable; the clients of all the replicas reach the hub
+// cluster.
+func (r *CredentialReloader) NeedLeaderElection() bool {
+	return false
+}
+
+// reload loads the config again and swaps the transport if the files have changed since the last load.
+func (r *CredentialReloader) reload() error {
+	digest, err := r.fileDigest()
+	if err != nil {
+		return err
+	}
+	r.mu.RLock()
+	unchanged := digest == r.digest
+	r.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	config, err := r.load()
+	if err != nil {
+		return fmt.Errorf("failed to load the config: %w", err)
+	}
+	if config.Host != r.host {
+		// The clients keep sending the requests to the host they have been built with.
+		return fmt.Errorf("the host of the hub cluster has changed from %s to %s, which requires a restart", r.host, config.Host)
+	}
+	if err := r.swap(config, digest); err != nil {
+		return err
+	}
+	credentialReloads.WithLabelValues(r.hub, "success").Inc()
+	klog.InfoS("Reloaded the credentials of the hub cluster", "hub", r.hub, "files", r.files)
+	return nil
+}
+
+// swap replaces the current transport with one built from the config, and closes the idle connections of the
+// previous one.
+func (r *CredentialReloader) swap(config *rest.Config, digest [sha256.Size]byte) error {
+	transport, err := rest.TransportFor(config)
+	if err != nil {
+		return fmt.Errorf("failed to build the transport to the hub cluster: %w", err)
+	}
+	r.mu.Lock()
+	previous := r.transport
+	r.transport = transport
+	r.digest = digest
+	r.mu.Unlock()
+	if previous != nil {
+		utilnet.CloseIdleConnectionsFor(previous)
+	}
+	return nil
+}
+
+// fileDigest returns the digest of the contents of the watched files.
+func (r *CredentialReloader) fileDigest() ([sha256.Size]byte, error) {
+	h := sha256.New()
+	for _, f := range r.files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return [sha256.Size]byte{}, fmt.Errorf("failed to read the credentials of the hub cluster: %w", err)
+		}
+		h.Write([]byte(f))
+		h.Write(data)
+	}
+	var digest [sha256.Size]byte
+	copy(digest[:], h.Sum(nil))
+	return digest, nil
+}
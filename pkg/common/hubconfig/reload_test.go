@@ -0,0 +1,131 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package hubconfig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+// TestCredentialReloader tests that the clients reach the hub cluster with the rotated token once it is reloaded.
+func TestCredentialReloader(t *testing.T) {
+	var validToken atomic.Value
+	validToken.Store("token-1")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer "+validToken.Load().(string) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	writeToken := func(token string) {
+		if err := os.WriteFile(tokenFile, []byte(token), 0600); err != nil {
+			t.Fatalf("failed to write the token file: %v", err)
+		}
+	}
+	writeToken("token-1")
+	loads := 0
+	load := func() (*rest.Config, error) {
+		loads++
+		return &rest.Config{Host: server.URL, BearerTokenFile: tokenFile}, nil
+	}
+	r, err := NewCredentialReloader("primary", load, tokenFile)
+	if err != nil {
+		t.Fatalf("NewCredentialReloader() = %v, want no error", err)
+	}
+	httpClient, err := rest.HTTPClientFor(r.Config())
+	if err != nil {
+		t.Fatalf("HTTPClientFor() = %v, want no error", err)
+	}
+	get := func() int {
+		resp, err := httpClient.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Get() = %v, want no error", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if got := get(); got != http.StatusOK {
+		t.Fatalf("Get() with the initial token = %d, want %d", got, http.StatusOK)
+	}
+
+	// The files have not changed.
+	if err := r.reload(); err != nil {
+		t.Fatalf("reload() = %v, want no error", err)
+	}
+	if loads != 1 {
+		t.Errorf("load() called %d times, want 1", loads)
+	}
+
+	// The token is rotated; the hub cluster rejects the previous token before the reload.
+	validToken.Store("token-2")
+	writeToken("token-2")
+	if got := get(); got != http.StatusUnauthorized {
+		t.Fatalf("Get() before the reload = %d, want %d", got, http.StatusUnauthorized)
+	}
+	select {
+	case <-r.unauthorized:
+	default:
+		t.Errorf("the rejected request did not wake up the reloader")
+	}
+	if err := r.reload(); err != nil {
+		t.Fatalf("reload() = %v, want no error", err)
+	}
+	if loads != 2 {
+		t.Errorf("load() called %d times, want 2", loads)
+	}
+	if got := get(); got != http.StatusOK {
+		t.Errorf("Get() after the reload = %d, want %d", got, http.StatusOK)
+	}
+
+	// The token file is missing while it is rotated; the current transport is kept.
+	if err := os.Remove(tokenFile); err != nil {
+		t.Fatalf("failed to remove the token file: %v", err)
+	}
+	if err := r.reload(); err == nil {
+		t.Errorf("reload() with the token file missing = nil, want error")
+	}
+	if got := get(); got != http.StatusOK {
+		t.Errorf("Get() after the failed reload = %d, want %d", got, http.StatusOK)
+	}
+}
+
+// TestCredentialReloader_HostChanged tests that a change of the host of the hub cluster is not reloaded.
+func TestCredentialReloader_HostChanged(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := os.WriteFile(kubeconfig, []byte("a"), 0600); err != nil {
+		t.Fatalf("failed to write the kubeconfig file: %v", err)
+	}
+	host := "https://hub-1.example.com"
+	load := func() (*rest.Config, error) {
+		return &rest.Config{Host: host}, nil
+	}
+	r, err := NewCredentialReloader("hub-1", load, kubeconfig)
+	if err != nil {
+		t.Fatalf("NewCredentialReloader() = %v, want no error", err)
+	}
+
+	host = "https://hub-2.example.com"
+	if err := os.WriteFile(kubeconfig, []byte("b"), 0600); err != nil {
+		t.Fatalf("failed to write the kubeconfig file: %v", err)
+	}
+	if err := r.reload(); err == nil {
+		t.Errorf("reload() with a new host = nil, want error")
+	}
+	if got := r.Config().Host; got != "https://hub-1.example.com" {
+		t.Errorf("Config().Host = %q, want %q", got, "https://hub-1.example.com")
+	}
+}
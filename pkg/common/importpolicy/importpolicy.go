@@ -0,0 +1,107 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package importpolicy features the evaluation of the ImportPolicies, which restrict the Services that a member
+// cluster imports from the fleet.
+package importpolicy
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+// Check returns the reason why the ServiceImport is denied by the ImportPolicies, or an empty string if it is allowed
+// by all of them.
+func Check(ctx context.Context, reader client.Reader, svcImport *fleetnetv1alpha1.ServiceImport) (string, error) {
+	policyList := &fleetnetv1alpha1.ImportPolicyList{}
+	if err := reader.List(ctx, policyList); err != nil {
+		return "", err
+	}
+	if len(policyList.Items) == 0 {
+		return "", nil
+	}
+	nsLabels := map[string]string{corev1.LabelMetadataName: svcImport.Namespace}
+	ns := &corev1.Namespace{}
+	if err := reader.Get(ctx, types.NamespacedName{Name: svcImport.Namespace}, ns); client.IgnoreNotFound(err) != nil {
+		return "", err
+	}
+	for k, v := range ns.Labels {
+		nsLabels[k] = v
+	}
+	return checkServiceImport(policyList.Items, svcImport, nsLabels), nil
+}
+
+// CheckCluster returns the reason why the endpoints exported from the cluster are denied by the ImportPolicies, or an
+// empty string if they are allowed by all of them.
+func CheckCluster(ctx context.Context, reader client.Reader, clusterID string) (string, error) {
+	policyList := &fleetnetv1alpha1.ImportPolicyList{}
+	if err := reader.List(ctx, policyList); err != nil {
+		return "", err
+	}
+	return checkCluster(policyList.Items, clusterID), nil
+}
+
+// checkServiceImport returns the reason why the first policy denying the ServiceImport, in a namespace with the given
+// labels, does so; a policy with an invalid selector denies all the ServiceImports, so that a typo never widens the
+// imports.
+func checkServiceImport(policies []fleetnetv1alpha1.ImportPolicy, svcImport *fleetnetv1alpha1.ServiceImport, nsLabels map[string]string) string {
+	for i := range policies {
+		spec := &policies[i].Spec
+		if reason := checkSelectors(policies[i].Name, "namespace", svcImport.Namespace, "Namespaces", spec.AllowedNamespaces, spec.DeniedNamespaces, nsLabels); reason != "" {
+			return reason
+		}
+		if reason := checkSelectors(policies[i].Name, "service", svcImport.Name, "Services", spec.AllowedServices, spec.DeniedServices, svcImport.Labels); reason != "" {
+			return reason
+		}
+	}
+	return ""
+}
+
+// checkSelectors returns the reason why the object with the given labels is denied by the selectors of a policy, or
+// an empty string if it is allowed.
+func checkSelectors(policy, kind, name, field string, allowed, denied *metav1.LabelSelector, objLabels map[string]string) string {
+	if denied != nil {
+		selector, err := metav1.LabelSelectorAsSelector(denied)
+		if err != nil {
+			return fmt.Sprintf("import policy %s has an invalid denied%s selector: %v", policy, field, err)
+		}
+		if selector.Matches(labels.Set(objLabels)) {
+			return fmt.Sprintf("%s %s is denied by import policy %s", kind, name, policy)
+		}
+	}
+	if allowed != nil {
+		selector, err := metav1.LabelSelectorAsSelector(allowed)
+		if err != nil {
+			return fmt.Sprintf("import policy %s has an invalid allowed%s selector: %v", policy, field, err)
+		}
+		if !selector.Matches(labels.Set(objLabels)) {
+			return fmt.Sprintf("%s %s is not allowed by import policy %s", kind, name, policy)
+		}
+	}
+	return ""
+}
+
+// checkCluster returns the reason why the first policy denying the cluster does so.
+func checkCluster(policies []fleetnetv1alpha1.ImportPolicy, clusterID string) string {
+	for i := range policies {
+		spec := &policies[i].Spec
+		if slices.Contains(spec.DeniedClusters, clusterID) {
+			return fmt.Sprintf("cluster %s is denied by import policy %s", clusterID, policies[i].Name)
+		}
+		if len(spec.AllowedClusters) > 0 && !slices.Contains(spec.AllowedClusters, clusterID) {
+			return fmt.Sprintf("cluster %s is not allowed by import policy %s", clusterID, policies[i].Name)
+		}
+	}
+	return ""
+}
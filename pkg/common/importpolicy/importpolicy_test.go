@@ -0,0 +1,214 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package importpolicy
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+const (
+	testNamespace = "work"
+	testName      = "app"
+)
+
+func policy(name string, spec fleetnetv1alpha1.ImportPolicySpec) fleetnetv1alpha1.ImportPolicy {
+	return fleetnetv1alpha1.ImportPolicy{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: spec}
+}
+
+func selector(matchLabels map[string]string) *metav1.LabelSelector {
+	return &metav1.LabelSelector{MatchLabels: matchLabels}
+}
+
+func serviceImport() *fleetnetv1alpha1.ServiceImport {
+	return &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testName,
+			Labels:    map[string]string{"tier": "backend"},
+		},
+	}
+}
+
+// TestCheckServiceImport tests the checkServiceImport function.
+func TestCheckServiceImport(t *testing.T) {
+	nsLabels := map[string]string{corev1.LabelMetadataName: testNamespace, "team": "payments"}
+	invalidSelector := &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tier", Operator: "Unknown"}},
+	}
+
+	testCases := []struct {
+		name     string
+		policies []fleetnetv1alpha1.ImportPolicy
+		want     string
+	}{
+		{
+			name: "no policy",
+		},
+		{
+			name: "allowed by namespace and service labels",
+			policies: []fleetnetv1alpha1.ImportPolicy{
+				policy("payments", fleetnetv1alpha1.ImportPolicySpec{
+					AllowedNamespaces: selector(map[string]string{"team": "payments"}),
+					AllowedServices:   selector(map[string]string{"tier": "backend"}),
+				}),
+			},
+		},
+		{
+			name: "namespace not allowed",
+			policies: []fleetnetv1alpha1.ImportPolicy{
+				policy("search", fleetnetv1alpha1.ImportPolicySpec{AllowedNamespaces: selector(map[string]string{"team": "search"})}),
+			},
+			want: "namespace work is not allowed by import policy search",
+		},
+		{
+			name: "namespace denied by name, which takes precedence over allowed",
+			policies: []fleetnetv1alpha1.ImportPolicy{
+				policy("payments", fleetnetv1alpha1.ImportPolicySpec{
+					AllowedNamespaces: selector(map[string]string{"team": "payments"}),
+					DeniedNamespaces:  selector(map[string]string{corev1.LabelMetadataName: testNamespace}),
+				}),
+			},
+			want: "namespace work is denied by import policy payments",
+		},
+		{
+			name: "service denied by label",
+			policies: []fleetnetv1alpha1.ImportPolicy{
+				policy("no-backends", fleetnetv1alpha1.ImportPolicySpec{DeniedServices: selector(map[string]string{"tier": "backend"})}),
+			},
+			want: "service app is denied by import policy no-backends",
+		},
+		{
+			name: "service allowed by one policy but not by another",
+			policies: []fleetnetv1alpha1.ImportPolicy{
+				policy("backends", fleetnetv1alpha1.ImportPolicySpec{AllowedServices: selector(map[string]string{"tier": "backend"})}),
+				policy("frontends", fleetnetv1alpha1.ImportPolicySpec{AllowedServices: selector(map[string]string{"tier": "frontend"})}),
+			},
+			want: "service app is not allowed by import policy frontends",
+		},
+		{
+			name: "invalid selector",
+			policies: []fleetnetv1alpha1.ImportPolicy{
+				policy("typo", fleetnetv1alpha1.ImportPolicySpec{AllowedServices: invalidSelector}),
+			},
+			want: "import policy typo has an invalid allowedServices selector: \"Unknown\" is not a valid label selector operator",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := checkServiceImport(tc.policies, serviceImport(), nsLabels); got != tc.want {
+				t.Errorf("checkServiceImport() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestCheckCluster tests the checkCluster function.
+func TestCheckCluster(t *testing.T) {
+	testCases := []struct {
+		name     string
+		policies []fleetnetv1alpha1.ImportPolicy
+		want     string
+	}{
+		{
+			name: "no policy",
+		},
+		{
+			name: "allowed",
+			policies: []fleetnetv1alpha1.ImportPolicy{
+				policy("eu", fleetnetv1alpha1.ImportPolicySpec{AllowedClusters: []string{"member-1", "member-2"}}),
+			},
+		},
+		{
+			name: "not allowed",
+			policies: []fleetnetv1alpha1.ImportPolicy{
+				policy("us", fleetnetv1alpha1.ImportPolicySpec{AllowedClusters: []string{"member-3"}}),
+			},
+			want: "cluster member-1 is not allowed by import policy us",
+		},
+		{
+			name: "denied, which takes precedence over allowed",
+			policies: []fleetnetv1alpha1.ImportPolicy{
+				policy("eu", fleetnetv1alpha1.ImportPolicySpec{AllowedClusters: []string{"member-1"}, DeniedClusters: []string{"member-1"}}),
+			},
+			want: "cluster member-1 is denied by import policy eu",
+		},
+		{
+			name: "policy without clusters",
+			policies: []fleetnetv1alpha1.ImportPolicy{
+				policy("backends", fleetnetv1alpha1.ImportPolicySpec{AllowedServices: selector(map[string]string{"tier": "backend"})}),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := checkCluster(tc.policies, "member-1"); got != tc.want {
+				t.Errorf("checkCluster() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestCheck tests the Check function.
+func TestCheck(t *testing.T) {
+	allowPayments := policy("payments", fleetnetv1alpha1.ImportPolicySpec{AllowedNamespaces: selector(map[string]string{"team": "payments"})})
+	allowByName := policy("by-name", fleetnetv1alpha1.ImportPolicySpec{AllowedNamespaces: selector(map[string]string{corev1.LabelMetadataName: testNamespace})})
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: testNamespace, Labels: map[string]string{"team": "payments"}},
+	}
+
+	testCases := []struct {
+		name    string
+		objects []client.Object
+		want    string
+	}{
+		{
+			name:    "no policy",
+			objects: []client.Object{namespace},
+		},
+		{
+			name:    "namespace allowed by its labels",
+			objects: []client.Object{namespace, &allowPayments},
+		},
+		{
+			name:    "namespace not found is evaluated with its name label",
+			objects: []client.Object{&allowByName},
+		},
+		{
+			name:    "namespace not found is not allowed by label",
+			objects: []client.Object{&allowPayments},
+			want:    "namespace work is not allowed by import policy payments",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			if err := corev1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.objects...).Build()
+
+			got, err := Check(context.Background(), fakeClient, serviceImport())
+			if err != nil {
+				t.Fatalf("Check() = %v, want no error", err)
+			}
+			if got != tc.want {
+				t.Errorf("Check() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
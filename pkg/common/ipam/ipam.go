@@ -0,0 +1,118 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package ipam features a simple in-memory IP address allocator, which hands out the virtual IPs (ClusterSetIPs)
+// of imported services from a configured CIDR.
+package ipam
+
+import (
+	"fmt"
+	"net/netip"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Allocator allocates IP addresses from a CIDR to the objects identified by their namespaced names; each object
+// holds at most one address. It keeps its state in memory only, and is expected to be rebuilt from the addresses
+// recorded in the objects, using Reserve, when the process restarts.
+type Allocator struct {
+	prefix netip.Prefix
+
+	mu sync.Mutex
+	// owners maps each allocated address to the object holding it.
+	owners map[netip.Addr]types.NamespacedName
+	// addrs maps each object to the address it holds.
+	addrs map[types.NamespacedName]netip.Addr
+}
+
+// NewAllocator returns an Allocator which allocates addresses from the given CIDR, e.g. "10.255.0.0/16".
+func NewAllocator(cidr string) (*Allocator, error) {
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CIDR %q: %w", cidr, err)
+	}
+	prefix = prefix.Masked()
+	if prefix.Addr().Is4() && prefix.Bits() > 30 {
+		return nil, fmt.Errorf("CIDR %q is too small; the prefix length must be at most 30 bits", cidr)
+	}
+	if prefix.Addr().Is6() && prefix.Bits() > 126 {
+		return nil, fmt.Errorf("CIDR %q is too small; the prefix length must be at most 126 bits", cidr)
+	}
+	return &Allocator{
+		prefix: prefix,
+		owners: make(map[netip.Addr]types.NamespacedName),
+		addrs:  make(map[types.NamespacedName]netip.Addr),
+	}, nil
+}
+
+// CIDR returns the CIDR the addresses are allocated from.
+func (a *Allocator) CIDR() string {
+	return a.prefix.String()
+}
+
+// Family returns the IP family of the addresses allocated.
+func (a *Allocator) Family() corev1.IPFamily {
+	if a.prefix.Addr().Is4() {
+		return corev1.IPv4Protocol
+	}
+	return corev1.IPv6Protocol
+}
+
+// Allocate returns the address held by the object with the given key, allocating a free one if the object holds
+// none.
+func (a *Allocator) Allocate(key types.NamespacedName) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if addr, ok := a.addrs[key]; ok {
+		return addr.String(), nil
+	}
+	for addr := a.prefix.Addr().Next(); a.isUsable(addr); addr = addr.Next() {
+		if _, ok := a.owners[addr]; !ok {
+			a.owners[addr] = key
+			a.addrs[key] = addr
+			return addr.String(), nil
+		}
+	}
+	return "", fmt.Errorf("no free address is left in CIDR %s", a.prefix)
+}
+
+// Reserve records that the object with the given key holds the given address, e.g. an address allocated before the
+// process restarted. It returns false if the address is not in the CIDR, or is held by another object; an object
+// which holds another address releases it.
+func (a *Allocator) Reserve(key types.NamespacedName, address string) bool {
+	addr, err := netip.ParseAddr(address)
+	if err != nil || !a.isUsable(addr) || addr == a.prefix.Addr() {
+		return false
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if owner, ok := a.owners[addr]; ok {
+		return owner == key
+	}
+	if old, ok := a.addrs[key]; ok {
+		delete(a.owners, old)
+	}
+	a.owners[addr] = key
+	a.addrs[key] = addr
+	return true
+}
+
+// Release releases the address held by the object with the given key, if any.
+func (a *Allocator) Release(key types.NamespacedName) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if addr, ok := a.addrs[key]; ok {
+		delete(a.owners, addr)
+		delete(a.addrs, key)
+	}
+}
+
+// isUsable returns true if the address is in the CIDR and is not its last address, which is the broadcast address
+// of an IPv4 network; the first address, i.e. the network address, is skipped by the callers.
+func (a *Allocator) isUsable(addr netip.Addr) bool {
+	return addr.IsValid() && a.prefix.Contains(addr) && a.prefix.Contains(addr.Next())
+}
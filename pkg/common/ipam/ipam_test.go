@@ -0,0 +1,120 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package ipam
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var (
+	keyA = types.NamespacedName{Namespace: "work", Name: "a"}
+	keyB = types.NamespacedName{Namespace: "work", Name: "b"}
+	keyC = types.NamespacedName{Namespace: "work", Name: "c"}
+)
+
+// TestNewAllocator tests the NewAllocator function.
+func TestNewAllocator(t *testing.T) {
+	testCases := []struct {
+		name       string
+		cidr       string
+		wantCIDR   string
+		wantFamily corev1.IPFamily
+		wantErr    bool
+	}{
+		{
+			name:       "IPv4 CIDR",
+			cidr:       "10.255.0.0/16",
+			wantCIDR:   "10.255.0.0/16",
+			wantFamily: corev1.IPv4Protocol,
+		},
+		{
+			name:       "IPv4 CIDR with host bits",
+			cidr:       "10.255.1.1/16",
+			wantCIDR:   "10.255.0.0/16",
+			wantFamily: corev1.IPv4Protocol,
+		},
+		{
+			name:       "IPv6 CIDR",
+			cidr:       "fd00:10::/112",
+			wantCIDR:   "fd00:10::/112",
+			wantFamily: corev1.IPv6Protocol,
+		},
+		{
+			name:    "invalid CIDR",
+			cidr:    "10.255.0.0",
+			wantErr: true,
+		},
+		{
+			name:    "CIDR too small",
+			cidr:    "10.255.0.0/31",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := NewAllocator(tc.cidr)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("NewAllocator() got error %v, want error %t", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.CIDR() != tc.wantCIDR {
+				t.Errorf("NewAllocator().CIDR() = %q, want %q", got.CIDR(), tc.wantCIDR)
+			}
+			if got.Family() != tc.wantFamily {
+				t.Errorf("NewAllocator().Family() = %q, want %q", got.Family(), tc.wantFamily)
+			}
+		})
+	}
+}
+
+// TestAllocator tests that addresses are allocated, reserved and released, and that an object keeps its address.
+func TestAllocator(t *testing.T) {
+	a, err := NewAllocator("10.0.0.0/30")
+	if err != nil {
+		t.Fatalf("NewAllocator() got error %v, want no error", err)
+	}
+
+	// The network and the broadcast addresses are never handed out.
+	if !a.Reserve(keyA, "10.0.0.2") {
+		t.Errorf("Reserve(%v, 10.0.0.2) = false, want true", keyA)
+	}
+	for _, addr := range []string{"10.0.0.0", "10.0.0.3", "10.0.1.1", "not-an-ip"} {
+		if a.Reserve(keyB, addr) {
+			t.Errorf("Reserve(%v, %s) = true, want false", keyB, addr)
+		}
+	}
+	if a.Reserve(keyB, "10.0.0.2") {
+		t.Errorf("Reserve(%v, 10.0.0.2) = true, want false as the address is held by %v", keyB, keyA)
+	}
+	got, err := a.Allocate(keyB)
+	if err != nil || got != "10.0.0.1" {
+		t.Errorf("Allocate(%v) = %q, %v, want 10.0.0.1, no error", keyB, got, err)
+	}
+	if got, err := a.Allocate(keyA); err != nil || got != "10.0.0.2" {
+		t.Errorf("Allocate(%v) = %q, %v, want the reserved address 10.0.0.2, no error", keyA, got, err)
+	}
+	if _, err := a.Allocate(keyC); err == nil {
+		t.Errorf("Allocate(%v) got no error, want an error as the CIDR is exhausted", keyC)
+	}
+
+	a.Release(keyA)
+	if got, err := a.Allocate(keyC); err != nil || got != "10.0.0.2" {
+		t.Errorf("Allocate(%v) = %q, %v, want the released address 10.0.0.2, no error", keyC, got, err)
+	}
+	// Reserving another address releases the one held before.
+	a.Release(keyC)
+	if !a.Reserve(keyB, "10.0.0.2") {
+		t.Errorf("Reserve(%v, 10.0.0.2) = false, want true", keyB)
+	}
+	if got, err := a.Allocate(keyC); err != nil || got != "10.0.0.1" {
+		t.Errorf("Allocate(%v) = %q, %v, want 10.0.0.1, no error", keyC, got, err)
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package ipfamily features utility functions that help member clusters reason about the IP families of
+// exported Services and imported endpoints.
+package ipfamily
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+)
+
+// Parse parses a comma-separated list of IP families (e.g. "IPv4,IPv6").
+func Parse(s string) ([]corev1.IPFamily, error) {
+	families := []corev1.IPFamily{}
+	for _, f := range strings.Split(s, ",") {
+		family := corev1.IPFamily(strings.TrimSpace(f))
+		switch family {
+		case corev1.IPv4Protocol, corev1.IPv6Protocol:
+		case "":
+			continue
+		default:
+			return nil, fmt.Errorf("unsupported IP family %q; must be one of %s, %s", family, corev1.IPv4Protocol, corev1.IPv6Protocol)
+		}
+		if !Contains(families, family) {
+			families = append(families, family)
+		}
+	}
+	if len(families) == 0 {
+		return nil, fmt.Errorf("at least one IP family must be specified")
+	}
+	return families, nil
+}
+
+// OfAddress returns the IP family of an IP address; it returns false if the address is not a valid IP address.
+func OfAddress(address string) (corev1.IPFamily, bool) {
+	ip := net.ParseIP(address)
+	switch {
+	case ip == nil:
+		return "", false
+	case ip.To4() != nil:
+		return corev1.IPv4Protocol, true
+	default:
+		return corev1.IPv6Protocol, true
+	}
+}
+
+// OfAddressType returns the IP family of the addresses of an EndpointSlice address type; it returns false if the
+// address type is not an IP family, i.e. FQDN.
+func OfAddressType(addressType discoveryv1.AddressType) (corev1.IPFamily, bool) {
+	switch addressType {
+	case discoveryv1.AddressTypeIPv4:
+		return corev1.IPv4Protocol, true
+	case discoveryv1.AddressTypeIPv6:
+		return corev1.IPv6Protocol, true
+	default:
+		return "", false
+	}
+}
+
+// Contains returns if an IP family is present in a list of IP families.
+func Contains(families []corev1.IPFamily, family corev1.IPFamily) bool {
+	for _, f := range families {
+		if f == family {
+			return true
+		}
+	}
+	return false
+}
+
+// Unsupported returns the IP families in the given list that are not present in the list of supported IP families.
+func Unsupported(families, supported []corev1.IPFamily) []corev1.IPFamily {
+	var unsupported []corev1.IPFamily
+	for _, f := range families {
+		if !Contains(supported, f) {
+			unsupported = append(unsupported, f)
+		}
+	}
+	return unsupported
+}
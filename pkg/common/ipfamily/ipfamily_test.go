@@ -0,0 +1,142 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package ipfamily
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+)
+
+// TestParse tests the Parse function.
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name    string
+		s       string
+		want    []corev1.IPFamily
+		wantErr bool
+	}{
+		{
+			name: "single family",
+			s:    "IPv4",
+			want: []corev1.IPFamily{corev1.IPv4Protocol},
+		},
+		{
+			name: "dual-stack with spaces and duplicates",
+			s:    "IPv6, IPv4,IPv6",
+			want: []corev1.IPFamily{corev1.IPv6Protocol, corev1.IPv4Protocol},
+		},
+		{
+			name:    "unknown family",
+			s:       "IPv5",
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			s:       " , ",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Parse(tc.s)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Parse(%q) error = %v, want error %t", tc.s, err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Parse(%q) mismatch (-want, +got):\n%s", tc.s, diff)
+			}
+		})
+	}
+}
+
+// TestOfAddress tests the OfAddress function.
+func TestOfAddress(t *testing.T) {
+	testCases := []struct {
+		name       string
+		address    string
+		wantFamily corev1.IPFamily
+		wantOK     bool
+	}{
+		{
+			name:       "IPv4 address",
+			address:    "10.0.0.1",
+			wantFamily: corev1.IPv4Protocol,
+			wantOK:     true,
+		},
+		{
+			name:       "IPv6 address",
+			address:    "fd00::1",
+			wantFamily: corev1.IPv6Protocol,
+			wantOK:     true,
+		},
+		{
+			name:    "not an IP address",
+			address: "example.com",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			family, ok := OfAddress(tc.address)
+			if family != tc.wantFamily || ok != tc.wantOK {
+				t.Errorf("OfAddress(%q) = (%s, %t), want (%s, %t)", tc.address, family, ok, tc.wantFamily, tc.wantOK)
+			}
+		})
+	}
+}
+
+// TestOfAddressType tests the OfAddressType function.
+func TestOfAddressType(t *testing.T) {
+	testCases := []struct {
+		name        string
+		addressType discoveryv1.AddressType
+		wantFamily  corev1.IPFamily
+		wantOK      bool
+	}{
+		{
+			name:        "IPv4 address type",
+			addressType: discoveryv1.AddressTypeIPv4,
+			wantFamily:  corev1.IPv4Protocol,
+			wantOK:      true,
+		},
+		{
+			name:        "IPv6 address type",
+			addressType: discoveryv1.AddressTypeIPv6,
+			wantFamily:  corev1.IPv6Protocol,
+			wantOK:      true,
+		},
+		{
+			name:        "FQDN address type",
+			addressType: discoveryv1.AddressTypeFQDN,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			family, ok := OfAddressType(tc.addressType)
+			if family != tc.wantFamily || ok != tc.wantOK {
+				t.Errorf("OfAddressType(%q) = (%s, %t), want (%s, %t)", tc.addressType, family, ok, tc.wantFamily, tc.wantOK)
+			}
+		})
+	}
+}
+
+// TestUnsupported tests the Unsupported function.
+func TestUnsupported(t *testing.T) {
+	dualStack := []corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv6Protocol}
+	ipv4Only := []corev1.IPFamily{corev1.IPv4Protocol}
+
+	if got := Unsupported(dualStack, ipv4Only); cmp.Diff([]corev1.IPFamily{corev1.IPv6Protocol}, got) != "" {
+		t.Errorf("Unsupported(%v, %v) = %v, want [IPv6]", dualStack, ipv4Only, got)
+	}
+	if got := Unsupported(ipv4Only, dualStack); len(got) != 0 {
+		t.Errorf("Unsupported(%v, %v) = %v, want none", ipv4Only, dualStack, got)
+	}
+}
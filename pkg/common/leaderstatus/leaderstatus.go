@@ -0,0 +1,149 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package leaderstatus features a reporter which exposes the leader election state of a controller manager,
+// both as a metric and as a debug endpoint, to help diagnose split-brain or flapping leadership during rollouts.
+package leaderstatus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+)
+
+// EndpointPath is the path of the debug endpoint, served by the metrics server of a controller manager.
+const EndpointPath = "/leader"
+
+var (
+	// leaderElectionStatus reports whether the controller manager currently holds the leadership of a lease.
+	leaderElectionStatus = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metrics.MetricsNamespace,
+			Subsystem: metrics.MetricsSubsystem,
+			Name:      "leader_election_status",
+			Help:      "Whether the controller manager currently holds the leadership (1) or not (0)",
+		},
+		[]string{
+			// The name of the leader election lease.
+			"lease",
+		},
+	)
+)
+
+func init() {
+	// Register leaderElectionStatus (fleet_networking_leader_election_status) metric with the controller runtime
+	// global metrics registry.
+	ctrlmetrics.Registry.MustRegister(leaderElectionStatus)
+}
+
+// Status is the leader election state returned by the debug endpoint.
+type Status struct {
+	// Lease is the namespaced name of the leader election lease.
+	Lease string `json:"lease"`
+	// IsLeader reports whether this controller manager currently holds the leadership.
+	IsLeader bool `json:"isLeader"`
+	// HolderIdentity is the identity of the current leader, as recorded in the lease.
+	HolderIdentity string `json:"holderIdentity,omitempty"`
+	// RenewTime is the last time the current leader renewed the lease.
+	RenewTime *time.Time `json:"renewTime,omitempty"`
+}
+
+// Reporter keeps track of the leader election state of a controller manager.
+type Reporter struct {
+	reader   client.Reader
+	lease    types.NamespacedName
+	elected  <-chan struct{}
+	isLeader atomic.Bool
+}
+
+// NewReporter returns a Reporter for the given lease; the elected channel must be closed once the controller
+// manager acquires the leadership, e.g. the one returned by manager.Elected().
+func NewReporter(reader client.Reader, leaseNamespace, leaseName string, elected <-chan struct{}) *Reporter {
+	leaderElectionStatus.WithLabelValues(leaseName).Set(0)
+	return &Reporter{
+		reader:  reader,
+		lease:   types.NamespacedName{Namespace: leaseNamespace, Name: leaseName},
+		elected: elected,
+	}
+}
+
+// SetupWithManager sets up a Reporter with a controller manager, which serves the debug endpoint via the
+// metrics server of the manager. The lease is read with leaseReader, as a manager may run its leader election
+// in a cluster other than the one it manages.
+func SetupWithManager(mgr ctrl.Manager, leaseReader client.Reader, leaseNamespace, leaseName string) error {
+	r := NewReporter(leaseReader, leaseNamespace, leaseName, mgr.Elected())
+	if err := mgr.Add(r); err != nil {
+		return err
+	}
+	return mgr.AddMetricsServerExtraHandler(EndpointPath, r)
+}
+
+// Start implements manager.Runnable; it flips the reported state when the leadership is acquired, and again
+// when the controller manager steps down, which always comes with the cancellation of the context.
+func (r *Reporter) Start(ctx context.Context) error {
+	select {
+	case <-r.elected:
+		klog.V(2).InfoS("Acquired the leadership", "lease", r.lease)
+		r.setLeader(true)
+	case <-ctx.Done():
+		return nil
+	}
+	<-ctx.Done()
+	klog.V(2).InfoS("Stepped down from the leadership", "lease", r.lease)
+	r.setLeader(false)
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable; the reporter runs on all the replicas.
+func (r *Reporter) NeedLeaderElection() bool {
+	return false
+}
+
+func (r *Reporter) setLeader(isLeader bool) {
+	r.isLeader.Store(isLeader)
+	val := 0.0
+	if isLeader {
+		val = 1
+	}
+	leaderElectionStatus.WithLabelValues(r.lease.Name).Set(val)
+}
+
+// ServeHTTP implements http.Handler; it returns the leader election state read from the lease as JSON.
+func (r *Reporter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	status := Status{
+		Lease:    r.lease.String(),
+		IsLeader: r.isLeader.Load(),
+	}
+	lease := &coordinationv1.Lease{}
+	if err := r.reader.Get(req.Context(), r.lease, lease); client.IgnoreNotFound(err) != nil {
+		klog.ErrorS(err, "Failed to get the leader election lease", "lease", r.lease)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if lease.Spec.HolderIdentity != nil {
+		status.HolderIdentity = *lease.Spec.HolderIdentity
+	}
+	if lease.Spec.RenewTime != nil {
+		renewTime := lease.Spec.RenewTime.Time
+		status.RenewTime = &renewTime
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		klog.ErrorS(err, "Failed to write the leader election status", "lease", r.lease)
+	}
+}
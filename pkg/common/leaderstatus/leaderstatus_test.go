@@ -0,0 +1,146 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package leaderstatus
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	leaseNamespace = "fleet-system"
+	leaseName      = "2bf2b407.hub.networking.fleet.azure.com"
+	holderIdentity = "hub-net-controller-manager-0_1234"
+)
+
+var renewTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// TestReporter_Start tests that the reported state follows the acquisition and the loss of the leadership.
+func TestReporter_Start(t *testing.T) {
+	elected := make(chan struct{})
+	r := NewReporter(fake.NewClientBuilder().WithScheme(scheme.Scheme).Build(), leaseNamespace, leaseName, elected)
+	gauge := leaderElectionStatus.WithLabelValues(leaseName)
+	if got := testutil.ToFloat64(gauge); got != 0 {
+		t.Fatalf("leader election status before the election = %v, want 0", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := r.Start(ctx); err != nil {
+			t.Errorf("Start() = %v, want no error", err)
+		}
+	}()
+
+	close(elected)
+	if err := waitFor(func() bool { return testutil.ToFloat64(gauge) == 1 && r.isLeader.Load() }); err != nil {
+		t.Fatalf("leader election status is not set after the election: %v", err)
+	}
+
+	cancel()
+	<-done
+	if got := testutil.ToFloat64(gauge); got != 0 || r.isLeader.Load() {
+		t.Fatalf("leader election status after stepping down = %v (isLeader %t), want 0", got, r.isLeader.Load())
+	}
+}
+
+// TestReporter_ServeHTTP tests the debug endpoint of the Reporter.
+func TestReporter_ServeHTTP(t *testing.T) {
+	lease := &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: leaseNamespace,
+			Name:      leaseName,
+		},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity: ptr.To(holderIdentity),
+			RenewTime:      &metav1.MicroTime{Time: renewTime},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		lease    *coordinationv1.Lease
+		isLeader bool
+		want     Status
+	}{
+		{
+			name:     "lease held by this manager",
+			lease:    lease,
+			isLeader: true,
+			want: Status{
+				Lease:          leaseNamespace + "/" + leaseName,
+				IsLeader:       true,
+				HolderIdentity: holderIdentity,
+				RenewTime:      &renewTime,
+			},
+		},
+		{
+			name:  "lease held by another manager",
+			lease: lease,
+			want: Status{
+				Lease:          leaseNamespace + "/" + leaseName,
+				HolderIdentity: holderIdentity,
+				RenewTime:      &renewTime,
+			},
+		},
+		{
+			name: "lease not found",
+			want: Status{
+				Lease: leaseNamespace + "/" + leaseName,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithScheme(scheme.Scheme)
+			if tc.lease != nil {
+				builder = builder.WithObjects(tc.lease)
+			}
+			r := NewReporter(builder.Build(), leaseNamespace, leaseName, make(chan struct{}))
+			r.isLeader.Store(tc.isLeader)
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, EndpointPath, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("ServeHTTP() status code = %d, want %d", rec.Code, http.StatusOK)
+			}
+			var got Status
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("failed to decode the response: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("ServeHTTP() status mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func waitFor(cond func() bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for !cond() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	return nil
+}
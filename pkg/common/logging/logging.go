@@ -0,0 +1,65 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package logging features the set up of the loggers of a controller manager, in either the klog text format or
+// JSON, so that the logs of many member agents can be aggregated and filtered by a log backend.
+package logging
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"go.uber.org/zap/zapcore"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+// The supported logging formats.
+const (
+	// FormatText is the klog text format.
+	FormatText = "text"
+	// FormatJSON writes a JSON object per log entry.
+	FormatJSON = "json"
+)
+
+// Setup sets up the global klog logger and the controller runtime logger with the given format, writing to out.
+//
+// The contextual loggers the controller runtime hands to the reconcilers, which carry the controller, namespace and
+// name of the object reconciled, additionally carry the given key/value pairs, e.g. the ID of the member cluster.
+// The verbosity of both loggers is the one set by the klog -v flag.
+func Setup(format string, out io.Writer, keysAndValues ...interface{}) error {
+	var logger logr.Logger
+	switch format {
+	case FormatText:
+		// klog writes the text format itself.
+		logger = klog.NewKlogr()
+	case FormatJSON:
+		// The errors are logged without stack traces, as klog does.
+		logger = zap.New(zap.JSONEncoder(), zap.WriteTo(out), zap.Level(zapcore.Level(-verbosity())),
+			zap.StacktraceLevel(zapcore.DPanicLevel))
+		klog.SetLogger(logger)
+	default:
+		return fmt.Errorf("unsupported logging format %q, want %q or %q", format, FormatText, FormatJSON)
+	}
+	ctrl.SetLogger(logger.WithValues(keysAndValues...))
+	return nil
+}
+
+// verbosity returns the value of the klog -v flag, as registered by klog.InitFlags.
+func verbosity() int {
+	f := flag.Lookup("v")
+	if f == nil {
+		return 0
+	}
+	v, err := strconv.Atoi(f.Value.String())
+	if err != nil {
+		return 0
+	}
+	return v
+}
@@ -0,0 +1,48 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/klog/v2"
+)
+
+// TestSetup_JSON tests that the klog logs are written as JSON objects once set up with the JSON format.
+func TestSetup_JSON(t *testing.T) {
+	out := &bytes.Buffer{}
+	if err := Setup(FormatJSON, out); err != nil {
+		t.Fatalf("Setup() = %v, want no error", err)
+	}
+	defer klog.ClearLogger()
+
+	klog.InfoS("Reconciliation starts", "service", klog.KRef("work", "app"))
+	klog.Flush()
+
+	got := map[string]interface{}{}
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("json.Unmarshal(%q) = %v, want no error", out.String(), err)
+	}
+	want := map[string]interface{}{
+		"msg":     "Reconciliation starts",
+		"service": map[string]interface{}{"namespace": "work", "name": "app"},
+	}
+	for key, wantValue := range want {
+		if diff := cmp.Diff(wantValue, got[key]); diff != "" {
+			t.Errorf("log entry %s mismatch (-want, +got):\n%s", key, diff)
+		}
+	}
+}
+
+// TestSetup_UnsupportedFormat tests that an unsupported format is rejected.
+func TestSetup_UnsupportedFormat(t *testing.T) {
+	if err := Setup("yaml", &bytes.Buffer{}); err == nil {
+		t.Errorf("Setup(yaml) = nil, want error")
+	}
+}
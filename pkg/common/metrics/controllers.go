@@ -0,0 +1,291 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package metrics
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/tracing"
+)
+
+// The values of the result label of the reconcile duration metric.
+const (
+	ReconcileResultSuccess = "success"
+	ReconcileResultRequeue = "requeue"
+	ReconcileResultError   = "error"
+)
+
+var (
+	// reconcileDuration measures how long the reconciliations of each controller take, partitioned by their
+	// results.
+	reconcileDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: MetricsNamespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "reconcile_duration_seconds",
+			Help:      "The duration of a reconciliation of a fleet networking controller",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{
+			// The name of the controller.
+			"controller",
+			// The result of the reconciliation, i.e. success, requeue or error.
+			"result",
+		},
+	)
+
+	// conflictsDetected counts the service exports which run into a conflict with their ServiceImports.
+	conflictsDetected = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "export_conflicts_detected_total",
+			Help:      "The number of service export conflicts detected",
+		},
+		[]string{
+			// The ID of the member cluster whose export is in conflict.
+			"cluster_id",
+		},
+	)
+
+	// conflictsResolved counts the service exports which are no longer in conflict with their ServiceImports.
+	conflictsResolved = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "export_conflicts_resolved_total",
+			Help:      "The number of service export conflicts resolved",
+		},
+		[]string{
+			// The ID of the member cluster whose export was in conflict.
+			"cluster_id",
+		},
+	)
+
+	// hubWriteFailures counts the writes of a member cluster to the hub cluster which fail.
+	hubWriteFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "hub_write_failures_total",
+			Help:      "The number of failed writes to the hub cluster",
+		},
+		[]string{
+			// The verb of the write, e.g. create or update.
+			"verb",
+			// The reason of the failure, as returned by the API server, e.g. Conflict.
+			"reason",
+		},
+	)
+
+	// dryRunWrites counts the writes which the controllers intend to make in dry-run mode.
+	dryRunWrites = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "dry_run_writes_total",
+			Help:      "The number of writes made in dry-run mode",
+		},
+		[]string{
+			// The target of the write, i.e. hub, member or azure.
+			"target",
+			// The verb of the write, e.g. create or update.
+			"verb",
+			// The kind of the object written.
+			"kind",
+			// The reason the write fails with, e.g. Conflict, or empty if it would succeed.
+			"reason",
+		},
+	)
+)
+
+var (
+	// reconcileErrorsMu guards reconcileErrors.
+	reconcileErrorsMu sync.Mutex
+	// reconcileErrors summarizes the failed reconciliations of each controller since they were last taken.
+	reconcileErrors = map[string]*fleetnetv1alpha1.ControllerErrorSummary{}
+)
+
+func init() {
+	// Register the controller metrics with the controller runtime global metrics registry.
+	ctrlmetrics.Registry.MustRegister(reconcileDuration, conflictsDetected, conflictsResolved, hubWriteFailures, dryRunWrites)
+}
+
+// ObserveReconcile records the duration of a reconciliation of the given controller which started at startTime.
+func ObserveReconcile(controller string, startTime time.Time, res ctrl.Result, err error) {
+	result := ReconcileResultSuccess
+	switch {
+	case err != nil:
+		result = ReconcileResultError
+	case res.Requeue || res.RequeueAfter > 0:
+		result = ReconcileResultRequeue
+	}
+	reconcileDuration.WithLabelValues(controller, result).Observe(time.Since(startTime).Seconds())
+	if err != nil {
+		recordReconcileError(controller, err)
+	}
+}
+
+func recordReconcileError(controller string, err error) {
+	reconcileErrorsMu.Lock()
+	defer reconcileErrorsMu.Unlock()
+	summary, ok := reconcileErrors[controller]
+	if !ok {
+		summary = &fleetnetv1alpha1.ControllerErrorSummary{Controller: controller}
+		reconcileErrors[controller] = summary
+	}
+	now := metav1.Now()
+	summary.Count++
+	summary.LastError = err.Error()
+	summary.LastErrorTime = &now
+}
+
+// TakeReconcileErrors returns the summaries of the failed reconciliations of each controller, sorted by controller,
+// since the last time they were taken, and resets them.
+func TakeReconcileErrors() []fleetnetv1alpha1.ControllerErrorSummary {
+	reconcileErrorsMu.Lock()
+	defer reconcileErrorsMu.Unlock()
+	if len(reconcileErrors) == 0 {
+		return nil
+	}
+	summaries := make([]fleetnetv1alpha1.ControllerErrorSummary, 0, len(reconcileErrors))
+	for _, summary := range reconcileErrors {
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Controller < summaries[j].Controller
+	})
+	reconcileErrors = map[string]*fleetnetv1alpha1.ControllerErrorSummary{}
+	return summaries
+}
+
+// InstrumentReconciler returns a reconciler which records the duration of each reconciliation of the given one
+// under the controller name, and traces it in a span.
+func InstrumentReconciler(controller string, r reconcile.Reconciler) reconcile.Reconciler {
+	return reconcile.Func(func(ctx context.Context, req reconcile.Request) (ctrl.Result, error) {
+		startTime := time.Now()
+		ctx, span := tracing.StartReconcile(ctx, controller, req)
+		res, err := r.Reconcile(ctx, req)
+		tracing.EndSpan(span, err)
+		ObserveReconcile(controller, startTime, res, err)
+		return res, err
+	})
+}
+
+// ConflictDetected records that the export of a Service from the given member cluster runs into a conflict.
+func ConflictDetected(clusterID string) {
+	conflictsDetected.WithLabelValues(clusterID).Inc()
+}
+
+// ConflictResolved records that the export of a Service from the given member cluster is no longer in conflict.
+func ConflictResolved(clusterID string) {
+	conflictsResolved.WithLabelValues(clusterID).Inc()
+}
+
+// DryRunWrite records a write to the given target made in dry-run mode, and the error it would fail with, if any.
+func DryRunWrite(target, verb, kind string, err error) {
+	var reason string
+	if err != nil {
+		reason = string(errors.ReasonForError(err))
+		if reason == "" {
+			reason = "Unknown"
+		}
+	}
+	dryRunWrites.WithLabelValues(target, verb, kind, reason).Inc()
+}
+
+// hubWriteFailureCountingClient is a client which counts its failed writes as hub write failures.
+type hubWriteFailureCountingClient struct {
+	client.Client
+}
+
+// CountHubWriteFailures returns a client which counts the failed writes of the given hub client.
+func CountHubWriteFailures(c client.Client) client.Client {
+	return &hubWriteFailureCountingClient{Client: c}
+}
+
+func countHubWriteFailure(verb string, err error) error {
+	if err != nil {
+		hubWriteFailures.WithLabelValues(verb, string(errors.ReasonForError(err))).Inc()
+	}
+	return err
+}
+
+func (c *hubWriteFailureCountingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	return countHubWriteFailure("create", c.Client.Create(ctx, obj, opts...))
+}
+
+func (c *hubWriteFailureCountingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	return countHubWriteFailure("update", c.Client.Update(ctx, obj, opts...))
+}
+
+func (c *hubWriteFailureCountingClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return countHubWriteFailure("patch", c.Client.Patch(ctx, obj, patch, opts...))
+}
+
+func (c *hubWriteFailureCountingClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	return countHubWriteFailure("delete", c.Client.Delete(ctx, obj, opts...))
+}
+
+func (c *hubWriteFailureCountingClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	return countHubWriteFailure("deleteallof", c.Client.DeleteAllOf(ctx, obj, opts...))
+}
+
+func (c *hubWriteFailureCountingClient) Status() client.SubResourceWriter {
+	return &hubWriteFailureCountingSubResourceWriter{SubResourceWriter: c.Client.Status()}
+}
+
+func (c *hubWriteFailureCountingClient) SubResource(subResource string) client.SubResourceClient {
+	return &hubWriteFailureCountingSubResourceClient{SubResourceClient: c.Client.SubResource(subResource)}
+}
+
+// hubWriteFailureCountingSubResourceWriter is a subresource writer which counts its failed writes as hub write
+// failures.
+type hubWriteFailureCountingSubResourceWriter struct {
+	client.SubResourceWriter
+}
+
+func (w *hubWriteFailureCountingSubResourceWriter) Create(ctx context.Context, obj client.Object, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+	return countHubWriteFailure("create", w.SubResourceWriter.Create(ctx, obj, subResource, opts...))
+}
+
+func (w *hubWriteFailureCountingSubResourceWriter) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	return countHubWriteFailure("update", w.SubResourceWriter.Update(ctx, obj, opts...))
+}
+
+func (w *hubWriteFailureCountingSubResourceWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	return countHubWriteFailure("patch", w.SubResourceWriter.Patch(ctx, obj, patch, opts...))
+}
+
+// hubWriteFailureCountingSubResourceClient is a subresource client which counts its failed writes as hub write
+// failures.
+type hubWriteFailureCountingSubResourceClient struct {
+	client.SubResourceClient
+}
+
+func (c *hubWriteFailureCountingSubResourceClient) Create(ctx context.Context, obj client.Object, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+	return countHubWriteFailure("create", c.SubResourceClient.Create(ctx, obj, subResource, opts...))
+}
+
+func (c *hubWriteFailureCountingSubResourceClient) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	return countHubWriteFailure("update", c.SubResourceClient.Update(ctx, obj, opts...))
+}
+
+func (c *hubWriteFailureCountingSubResourceClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	return countHubWriteFailure("patch", c.SubResourceClient.Patch(ctx, obj, patch, opts...))
+}
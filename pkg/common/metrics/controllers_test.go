@@ -0,0 +1,187 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+// TestInstrumentReconciler tests that the reconciliations are observed under their results.
+func TestInstrumentReconciler(t *testing.T) {
+	testCases := []struct {
+		name       string
+		res        ctrl.Result
+		err        error
+		wantResult string
+	}{
+		{
+			name:       "success",
+			wantResult: ReconcileResultSuccess,
+		},
+		{
+			name:       "requeue",
+			res:        ctrl.Result{RequeueAfter: time.Second},
+			wantResult: ReconcileResultRequeue,
+		},
+		{
+			name:       "error",
+			err:        fmt.Errorf("test error"),
+			wantResult: ReconcileResultError,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			controller := "test-" + tc.name
+			r := InstrumentReconciler(controller, reconcile.Func(func(context.Context, reconcile.Request) (ctrl.Result, error) {
+				return tc.res, tc.err
+			}))
+			res, err := r.Reconcile(context.Background(), reconcile.Request{})
+			if res != tc.res || err != tc.err {
+				t.Errorf("Reconcile() = %v, %v, want %v, %v", res, err, tc.res, tc.err)
+			}
+			m := &dto.Metric{}
+			if err := reconcileDuration.WithLabelValues(controller, tc.wantResult).(prometheus.Histogram).Write(m); err != nil {
+				t.Fatalf("Write() = %v, want no error", err)
+			}
+			if got := m.GetHistogram().GetSampleCount(); got != 1 {
+				t.Errorf("reconcile duration observations = %d, want 1", got)
+			}
+		})
+	}
+}
+
+// TestConflicts tests the conflict counters.
+func TestConflicts(t *testing.T) {
+	clusterID := "member-conflicts"
+	ConflictDetected(clusterID)
+	ConflictDetected(clusterID)
+	ConflictResolved(clusterID)
+	if got := testutil.ToFloat64(conflictsDetected.WithLabelValues(clusterID)); got != 2 {
+		t.Errorf("conflicts detected = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(conflictsResolved.WithLabelValues(clusterID)); got != 1 {
+		t.Errorf("conflicts resolved = %v, want 1", got)
+	}
+}
+
+// TestCountHubWriteFailures tests that the failed writes to the hub cluster are counted by their verbs and reasons.
+func TestCountHubWriteFailures(t *testing.T) {
+	conflictErr := apierrors.NewConflict(schema.GroupResource{Resource: "services"}, "app", fmt.Errorf("test conflict"))
+	fakeClient := fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+		Update: func(context.Context, client.WithWatch, client.Object, ...client.UpdateOption) error {
+			return conflictErr
+		},
+		SubResourcePatch: func(context.Context, client.Client, string, client.Object, client.Patch, ...client.SubResourcePatchOption) error {
+			return conflictErr
+		},
+	}).Build()
+	c := CountHubWriteFailures(fakeClient)
+	ctx := context.Background()
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "work", Name: "app"}}
+
+	if err := c.Create(ctx, svc); err != nil {
+		t.Fatalf("Create() = %v, want no error", err)
+	}
+	dup := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "work", Name: "app"}}
+	if err := c.Create(ctx, dup); !apierrors.IsAlreadyExists(err) {
+		t.Fatalf("Create() = %v, want already exists", err)
+	}
+	if err := c.Update(ctx, svc); !apierrors.IsConflict(err) {
+		t.Fatalf("Update() = %v, want conflict", err)
+	}
+	if err := c.Status().Patch(ctx, svc, client.MergeFrom(svc.DeepCopy())); !apierrors.IsConflict(err) {
+		t.Fatalf("Status().Patch() = %v, want conflict", err)
+	}
+
+	testCases := []struct {
+		verb   string
+		reason metav1.StatusReason
+		want   float64
+	}{
+		{verb: "create", reason: metav1.StatusReasonAlreadyExists, want: 1},
+		{verb: "update", reason: metav1.StatusReasonConflict, want: 1},
+		{verb: "patch", reason: metav1.StatusReasonConflict, want: 1},
+	}
+	for _, tc := range testCases {
+		if got := testutil.ToFloat64(hubWriteFailures.WithLabelValues(tc.verb, string(tc.reason))); got != tc.want {
+			t.Errorf("hub write failures (%s, %s) = %v, want %v", tc.verb, tc.reason, got, tc.want)
+		}
+	}
+}
+
+// TestDryRunWrite tests that the dry-run writes are counted by their targets, verbs, kinds and failure reasons.
+func TestDryRunWrite(t *testing.T) {
+	conflictErr := apierrors.NewConflict(schema.GroupResource{Resource: "services"}, "app", fmt.Errorf("test conflict"))
+	DryRunWrite("hub", "create", "ServiceImport", nil)
+	DryRunWrite("hub", "create", "ServiceImport", nil)
+	DryRunWrite("member", "update status", "ServiceExport", conflictErr)
+	DryRunWrite("member", "delete", "EndpointSlice", fmt.Errorf("test error"))
+
+	testCases := []struct {
+		target string
+		verb   string
+		kind   string
+		reason string
+		want   float64
+	}{
+		{target: "hub", verb: "create", kind: "ServiceImport", want: 2},
+		{target: "member", verb: "update status", kind: "ServiceExport", reason: string(metav1.StatusReasonConflict), want: 1},
+		{target: "member", verb: "delete", kind: "EndpointSlice", reason: "Unknown", want: 1},
+	}
+	for _, tc := range testCases {
+		if got := testutil.ToFloat64(dryRunWrites.WithLabelValues(tc.target, tc.verb, tc.kind, tc.reason)); got != tc.want {
+			t.Errorf("dry-run writes (%s, %s, %s, %s) = %v, want %v", tc.target, tc.verb, tc.kind, tc.reason, got, tc.want)
+		}
+	}
+}
+
+// TestTakeReconcileErrors tests that the failed reconciliations are summarized per controller until taken.
+func TestTakeReconcileErrors(t *testing.T) {
+	// Drop the errors recorded by the other tests.
+	TakeReconcileErrors()
+
+	ObserveReconcile("serviceexport-controller", time.Now(), ctrl.Result{}, fmt.Errorf("first error"))
+	ObserveReconcile("serviceexport-controller", time.Now(), ctrl.Result{}, fmt.Errorf("second error"))
+	ObserveReconcile("endpointslice-controller", time.Now(), ctrl.Result{}, fmt.Errorf("test error"))
+	ObserveReconcile("endpointslice-controller", time.Now(), ctrl.Result{}, nil)
+
+	want := []fleetnetv1alpha1.ControllerErrorSummary{
+		{Controller: "endpointslice-controller", Count: 1, LastError: "test error"},
+		{Controller: "serviceexport-controller", Count: 2, LastError: "second error"},
+	}
+	got := TakeReconcileErrors()
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(fleetnetv1alpha1.ControllerErrorSummary{}, "LastErrorTime")); diff != "" {
+		t.Errorf("TakeReconcileErrors() mismatch (-want, +got):\n%s", diff)
+	}
+	for _, summary := range got {
+		if summary.LastErrorTime == nil {
+			t.Errorf("TakeReconcileErrors() controller %s got no last error time, want one", summary.Controller)
+		}
+	}
+	if got := TakeReconcileErrors(); got != nil {
+		t.Errorf("TakeReconcileErrors() = %v, want nil after the errors are taken", got)
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package metrics
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+// fleetCollectTimeout is the time allowed for listing the objects of the hub cluster on a scrape.
+const fleetCollectTimeout = 10 * time.Second
+
+var (
+	exportedServicesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, MetricsSubsystem, "exported_services"),
+		"The number of services exported by a member cluster",
+		[]string{"cluster_id"}, nil,
+	)
+	exportedEndpointsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, MetricsSubsystem, "exported_endpoints"),
+		"The number of endpoints exported by a member cluster",
+		[]string{"cluster_id"}, nil,
+	)
+	importedEndpointsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, MetricsSubsystem, "imported_endpoints"),
+		"The number of endpoints distributed to a member cluster for import",
+		[]string{"cluster_id"}, nil,
+	)
+)
+
+// FleetCollector reports the services and the endpoints exported and imported by each member cluster, as recorded
+// in the hub cluster; the objects are counted on each scrape, from the cache of the hub controller manager.
+type FleetCollector struct {
+	reader client.Reader
+	// memberNamespacePrefix is the prefix of the namespaces reserved for the member clusters in the hub cluster.
+	memberNamespacePrefix string
+}
+
+// NewFleetCollector returns a FleetCollector which reads the objects of the hub cluster with the given reader; the
+// namespaces reserved for the member clusters are named after the given format, e.g. "fleet-member-%s".
+func NewFleetCollector(reader client.Reader, memberNamespaceFormat string) *FleetCollector {
+	return &FleetCollector{reader: reader, memberNamespacePrefix: strings.TrimSuffix(memberNamespaceFormat, "%s")}
+}
+
+// Describe implements prometheus.Collector.
+func (c *FleetCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- exportedServicesDesc
+	ch <- exportedEndpointsDesc
+	ch <- importedEndpointsDesc
+}
+
+// Collect implements prometheus.Collector; a metric whose objects cannot be listed is not reported.
+func (c *FleetCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), fleetCollectTimeout)
+	defer cancel()
+
+	internalServiceExportList := &fleetnetv1alpha1.InternalServiceExportList{}
+	if err := c.reader.List(ctx, internalServiceExportList); err != nil {
+		klog.ErrorS(err, "Failed to list internalServiceExports for metrics")
+	} else {
+		services := map[string]int{}
+		for i := range internalServiceExportList.Items {
+			services[internalServiceExportList.Items[i].Spec.ServiceReference.ClusterID]++
+		}
+		collectGauges(ch, exportedServicesDesc, services)
+	}
+
+	endpointSliceExportList := &fleetnetv1alpha1.EndpointSliceExportList{}
+	if err := c.reader.List(ctx, endpointSliceExportList); err != nil {
+		klog.ErrorS(err, "Failed to list endpointSliceExports for metrics")
+	} else {
+		endpoints := map[string]int{}
+		for i := range endpointSliceExportList.Items {
+			endpointSliceExport := &endpointSliceExportList.Items[i]
+			endpoints[endpointSliceExport.Spec.EndpointSliceReference.ClusterID] += len(endpointSliceExport.Spec.Endpoints)
+		}
+		collectGauges(ch, exportedEndpointsDesc, endpoints)
+	}
+
+	endpointSliceImportList := &fleetnetv1alpha1.EndpointSliceImportList{}
+	if err := c.reader.List(ctx, endpointSliceImportList); err != nil {
+		klog.ErrorS(err, "Failed to list endpointSliceImports for metrics")
+	} else {
+		// The EndpointSliceImports are distributed to the reserved namespaces of the importing member clusters.
+		endpoints := map[string]int{}
+		for i := range endpointSliceImportList.Items {
+			endpointSliceImport := &endpointSliceImportList.Items[i]
+			clusterID := strings.TrimPrefix(endpointSliceImport.Namespace, c.memberNamespacePrefix)
+			endpoints[clusterID] += len(endpointSliceImport.Spec.Endpoints)
+		}
+		collectGauges(ch, importedEndpointsDesc, endpoints)
+	}
+}
+
+func collectGauges(ch chan<- prometheus.Metric, desc *prometheus.Desc, values map[string]int) {
+	for clusterID, value := range values {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(value), clusterID)
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+func internalServiceExportForTest(name, clusterID string) *fleetnetv1alpha1.InternalServiceExport {
+	return &fleetnetv1alpha1.InternalServiceExport{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "fleet-member-" + clusterID, Name: name},
+		Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+			ServiceReference: fleetnetv1alpha1.ExportedObjectReference{ClusterID: clusterID},
+		},
+	}
+}
+
+func endpoints(n int) []fleetnetv1alpha1.Endpoint {
+	endpoints := make([]fleetnetv1alpha1.Endpoint, n)
+	for i := range endpoints {
+		endpoints[i] = fleetnetv1alpha1.Endpoint{Addresses: []string{"10.0.0.1"}}
+	}
+	return endpoints
+}
+
+// TestFleetCollector tests that the exported services and the exported and imported endpoints are counted per
+// member cluster.
+func TestFleetCollector(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	objs := []client.Object{
+		internalServiceExportForTest("work-app", "member-1"),
+		internalServiceExportForTest("work-db", "member-1"),
+		internalServiceExportForTest("work-app", "member-2"),
+		&fleetnetv1alpha1.EndpointSliceExport{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "fleet-member-member-1", Name: "app-1"},
+			Spec: fleetnetv1alpha1.EndpointSliceExportSpec{
+				AddressType:            discoveryv1.AddressTypeIPv4,
+				Endpoints:              endpoints(3),
+				EndpointSliceReference: fleetnetv1alpha1.ExportedObjectReference{ClusterID: "member-1"},
+			},
+		},
+		&fleetnetv1alpha1.EndpointSliceImport{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "fleet-member-member-2", Name: "app-1"},
+			Spec: fleetnetv1alpha1.EndpointSliceExportSpec{
+				AddressType:            discoveryv1.AddressTypeIPv4,
+				Endpoints:              endpoints(3),
+				EndpointSliceReference: fleetnetv1alpha1.ExportedObjectReference{ClusterID: "member-1"},
+			},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+
+	want := `
+# HELP fleet_networking_exported_endpoints The number of endpoints exported by a member cluster
+# TYPE fleet_networking_exported_endpoints gauge
+fleet_networking_exported_endpoints{cluster_id="member-1"} 3
+# HELP fleet_networking_exported_services The number of services exported by a member cluster
+# TYPE fleet_networking_exported_services gauge
+fleet_networking_exported_services{cluster_id="member-1"} 2
+fleet_networking_exported_services{cluster_id="member-2"} 1
+# HELP fleet_networking_imported_endpoints The number of endpoints distributed to a member cluster for import
+# TYPE fleet_networking_imported_endpoints gauge
+fleet_networking_imported_endpoints{cluster_id="member-2"} 3
+`
+	if err := testutil.CollectAndCompare(NewFleetCollector(fakeClient, "fleet-member-%s"), strings.NewReader(want)); err != nil {
+		t.Errorf("FleetCollector metrics mismatch: %v", err)
+	}
+}
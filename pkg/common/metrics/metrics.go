@@ -3,7 +3,8 @@ Copyright (c) Microsoft Corporation.
 Licensed under the MIT license.
 */
 
-// Package metrics features some consts and variables used for exposing metrics.
+// Package metrics features some consts and variables used for exposing metrics, and the metrics shared by the
+// fleet networking controllers.
 package metrics
 
 import (
@@ -37,6 +38,11 @@ const (
 	// a specific generation of an object; this annotation is reserved for the purpose of metric collection,
 	// specifically tracking when a generation of an object is exported.
 	MetricsAnnotationLastSeenTimestamp = "networking.fleet.azure.com/last-seen-timestamp"
+
+	// MetricsAnnotationDistributedTimestamp is an annotation that marks when the hub cluster distributes a specific
+	// generation of an exported object to a member cluster; this annotation is reserved for the purpose of metric
+	// collection, specifically tracking how long it takes for a distributed object to be imported.
+	MetricsAnnotationDistributedTimestamp = "networking.fleet.azure.com/distributed-timestamp"
 )
 
 // Metrics related values.
@@ -44,7 +50,7 @@ const (
 	MetricsNamespace = "fleet"
 	MetricsSubsystem = "networking"
 
-	// The format to use with MetricsAnnotationLastSeenTimestamp.
+	// The format to use with MetricsAnnotationLastSeenTimestamp and MetricsAnnotationDistributedTimestamp.
 	//
 	// Why use RFC 3339
 	//
@@ -68,3 +74,17 @@ var (
 	// The right bound of export durations; any data point beyond this limit will be capped.
 	ExportDurationRightBound = ExportDurationMillisecondsBuckets[len(ExportDurationMillisecondsBuckets)-1] * 2
 )
+
+// PropagationDurationMilliseconds returns the duration, in milliseconds, it takes for an object to propagate
+// between clusters from the start time to the end time. As clocks may drift across clusters, a non-positive duration
+// is reported as 1 second; to avoid outliers skewing the stats, a duration beyond ExportDurationRightBound is capped.
+func PropagationDurationMilliseconds(start, end time.Time) int64 {
+	timeSpent := end.Sub(start).Milliseconds()
+	if timeSpent <= 0 {
+		return time.Second.Milliseconds()
+	}
+	if timeSpent > int64(ExportDurationRightBound) {
+		return int64(ExportDurationRightBound)
+	}
+	return timeSpent
+}
@@ -0,0 +1,46 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+// TestPropagationDurationMilliseconds tests the PropagationDurationMilliseconds function.
+func TestPropagationDurationMilliseconds(t *testing.T) {
+	start := time.Now().Round(time.Second)
+
+	testCases := []struct {
+		name string
+		end  time.Time
+		want int64
+	}{
+		{
+			name: "in range",
+			end:  start.Add(time.Second * 3),
+			want: 3000,
+		},
+		{
+			name: "negative duration",
+			end:  start.Add(-time.Second * 2),
+			want: 1000,
+		},
+		{
+			name: "large outlier",
+			end:  start.Add(time.Minute * 5),
+			want: int64(ExportDurationRightBound),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := PropagationDurationMilliseconds(start, tc.end); got != tc.want {
+				t.Errorf("PropagationDurationMilliseconds(), got %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
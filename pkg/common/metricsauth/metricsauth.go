@@ -0,0 +1,184 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package metricsauth features the authentication and authorization of the scrapers of the metrics endpoint of a
+// controller manager, as the metrics of the controllers reveal the topology of the fleet.
+//
+// A scraper presents the token of its service account as a bearer token; the token is authenticated with a
+// TokenReview and the scraper is authorized with a SubjectAccessReview of a get of the non-resource URL /metrics, e.g.
+//
+//	rules:
+//	- nonResourceURLs: ["/metrics"]
+//	  verbs: ["get"]
+//
+// so that no proxy, e.g. kube-rbac-proxy, is needed in front of the endpoint.
+package metricsauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/client-go/kubernetes"
+	authenticationv1client "k8s.io/client-go/kubernetes/typed/authentication/v1"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	"k8s.io/client-go/rest"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+)
+
+const (
+	// reviewTimeout is the time allowed for a review by the API server.
+	reviewTimeout = 10 * time.Second
+
+	// The results of the reviews are cached, so that each scrape does not cost the API server two reviews.
+	reviewCacheSize = 1024
+	allowedCacheTTL = 2 * time.Minute
+	deniedCacheTTL  = 30 * time.Second
+
+	bearerTokenPrefix = "Bearer "
+)
+
+//+kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+//+kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// FilterProvider returns a filter provider for the metrics server of a controller manager, which authenticates and
+// authorizes the scrapers with the API server of the given config; if the config is nil, the API server of the
+// controller manager is used.
+//
+// A config is given when the controller manager watches another cluster than the one its scrapers run in, e.g. the
+// hub controller manager of a member agent, whose scrapers are authenticated by the member cluster.
+func FilterProvider(cfg *rest.Config) func(*rest.Config, *http.Client) (metricsserver.Filter, error) {
+	return func(mgrCfg *rest.Config, httpClient *http.Client) (metricsserver.Filter, error) {
+		var clientset *kubernetes.Clientset
+		var err error
+		if cfg != nil {
+			clientset, err = kubernetes.NewForConfig(cfg)
+		} else {
+			clientset, err = kubernetes.NewForConfigAndClient(mgrCfg, httpClient)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the client of the metrics authentication: %w", err)
+		}
+		return NewFilter(clientset.AuthenticationV1().TokenReviews(), clientset.AuthorizationV1().SubjectAccessReviews()), nil
+	}
+}
+
+// NewFilter returns a metrics server filter which authenticates the scrapers with the given TokenReview client and
+// authorizes them with the given SubjectAccessReview client.
+func NewFilter(tokenReviews authenticationv1client.TokenReviewInterface, subjectAccessReviews authorizationv1client.SubjectAccessReviewInterface) metricsserver.Filter {
+	a := &authorizer{
+		tokenReviews:         tokenReviews,
+		subjectAccessReviews: subjectAccessReviews,
+		users:                cache.NewLRUExpireCache(reviewCacheSize),
+		decisions:            cache.NewLRUExpireCache(reviewCacheSize),
+	}
+	return func(log logr.Logger, handler http.Handler) (http.Handler, error) {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			ctx, cancel := context.WithTimeout(req.Context(), reviewTimeout)
+			defer cancel()
+
+			token, ok := strings.CutPrefix(req.Header.Get("Authorization"), bearerTokenPrefix)
+			if !ok || token == "" {
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+			user, err := a.authenticate(ctx, token)
+			if err != nil {
+				log.Error(err, "Failed to authenticate the metrics scraper")
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+			if user == nil {
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+			allowed, err := a.authorize(ctx, user, strings.ToLower(req.Method), req.URL.Path)
+			if err != nil {
+				log.Error(err, "Failed to authorize the metrics scraper", "user", user.Username)
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				return
+			}
+			if !allowed {
+				log.V(4).Info("Denied the metrics scraper", "user", user.Username, "path", req.URL.Path)
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				return
+			}
+			handler.ServeHTTP(w, req)
+		}), nil
+	}
+}
+
+// authorizer reviews the scrapers, caching the results of the reviews.
+type authorizer struct {
+	tokenReviews         authenticationv1client.TokenReviewInterface
+	subjectAccessReviews authorizationv1client.SubjectAccessReviewInterface
+	// users maps the hash of a token to its user, or nil if the token is not authenticated.
+	users *cache.LRUExpireCache
+	// decisions maps a user, a verb and a path to whether the user is allowed to request the path.
+	decisions *cache.LRUExpireCache
+}
+
+// authenticate returns the user of a token, or nil if the token is not authenticated.
+func (a *authorizer) authenticate(ctx context.Context, token string) (*authenticationv1.UserInfo, error) {
+	// Only the hashes of the tokens are kept in memory.
+	key := sha256.Sum256([]byte(token))
+	if user, ok := a.users.Get(key); ok {
+		return user.(*authenticationv1.UserInfo), nil
+	}
+	review, err := a.tokenReviews.Create(ctx, &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if !review.Status.Authenticated {
+		a.users.Add(key, (*authenticationv1.UserInfo)(nil), deniedCacheTTL)
+		return nil, nil
+	}
+	user := &review.Status.User
+	a.users.Add(key, user, allowedCacheTTL)
+	return user, nil
+}
+
+// authorize returns whether a user is allowed to request a path with a verb.
+func (a *authorizer) authorize(ctx context.Context, user *authenticationv1.UserInfo, verb, path string) (bool, error) {
+	key := fmt.Sprintf("%s/%s/%s/%s", user.UID, user.Username, verb, path)
+	if allowed, ok := a.decisions.Get(key); ok {
+		return allowed.(bool), nil
+	}
+	extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+	for k, v := range user.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+	review, err := a.subjectAccessReviews.Create(ctx, &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   user.Username,
+			UID:    user.UID,
+			Groups: user.Groups,
+			Extra:  extra,
+			NonResourceAttributes: &authorizationv1.NonResourceAttributes{
+				Path: path,
+				Verb: verb,
+			},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return false, err
+	}
+	ttl := deniedCacheTTL
+	if review.Status.Allowed {
+		ttl = allowedCacheTTL
+	}
+	a.decisions.Add(key, review.Status.Allowed, ttl)
+	return review.Status.Allowed, nil
+}
@@ -0,0 +1,142 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package metricsauth
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-logr/logr"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const (
+	scraperToken    = "scraper-token"
+	intruderToken   = "intruder-token"
+	failingToken    = "failing-token"
+	scraperUser     = "system:serviceaccount:monitoring:prometheus"
+	intruderUser    = "system:serviceaccount:default:intruder"
+	metricsPath     = "/metrics"
+	unreviewedToken = "unreviewed-token"
+)
+
+func newFakeClientset(reviews *int) *fake.Clientset {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		*reviews++
+		review := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		switch review.Spec.Token {
+		case scraperToken:
+			review.Status = authenticationv1.TokenReviewStatus{Authenticated: true, User: authenticationv1.UserInfo{Username: scraperUser}}
+		case intruderToken:
+			review.Status = authenticationv1.TokenReviewStatus{Authenticated: true, User: authenticationv1.UserInfo{Username: intruderUser}}
+		case failingToken:
+			return true, nil, fmt.Errorf("the API server is unavailable")
+		}
+		return true, review, nil
+	})
+	clientset.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		*reviews++
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		attrs := review.Spec.NonResourceAttributes
+		review.Status.Allowed = review.Spec.User == scraperUser && attrs != nil && attrs.Path == metricsPath && attrs.Verb == "get"
+		return true, review, nil
+	})
+	return clientset
+}
+
+func TestFilter(t *testing.T) {
+	testCases := []struct {
+		name           string
+		header         string
+		wantStatusCode int
+	}{
+		{
+			name:           "no token",
+			wantStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:           "not a bearer token",
+			header:         "Basic " + scraperToken,
+			wantStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:           "token not authenticated",
+			header:         bearerTokenPrefix + unreviewedToken,
+			wantStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:           "token review failed",
+			header:         bearerTokenPrefix + failingToken,
+			wantStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:           "user not authorized",
+			header:         bearerTokenPrefix + intruderToken,
+			wantStatusCode: http.StatusForbidden,
+		},
+		{
+			name:           "user authorized",
+			header:         bearerTokenPrefix + scraperToken,
+			wantStatusCode: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reviews := 0
+			clientset := newFakeClientset(&reviews)
+			filter := NewFilter(clientset.AuthenticationV1().TokenReviews(), clientset.AuthorizationV1().SubjectAccessReviews())
+			handler, err := filter(logr.Discard(), http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			if err != nil {
+				t.Fatalf("filter() = %v, want nil", err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, metricsPath, nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tc.wantStatusCode {
+				t.Errorf("ServeHTTP() status = %d, want %d", rec.Code, tc.wantStatusCode)
+			}
+		})
+	}
+}
+
+func TestFilter_CachesReviews(t *testing.T) {
+	reviews := 0
+	clientset := newFakeClientset(&reviews)
+	filter := NewFilter(clientset.AuthenticationV1().TokenReviews(), clientset.AuthorizationV1().SubjectAccessReviews())
+	handler, err := filter(logr.Discard(), http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	if err != nil {
+		t.Fatalf("filter() = %v, want nil", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodGet, metricsPath, nil)
+		req.Header.Set("Authorization", bearerTokenPrefix+scraperToken)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("ServeHTTP() status = %d, want %d", rec.Code, http.StatusOK)
+		}
+	}
+	// One TokenReview and one SubjectAccessReview.
+	if want := 2; reviews != want {
+		t.Errorf("reviews = %d, want %d", reviews, want)
+	}
+}
@@ -0,0 +1,333 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package migration features the migration of a fleet to a new hub cluster without dropping the cross-cluster
+// traffic, which goes as follows:
+//
+//  1. the objects the fleet operator keeps in the old hub cluster, e.g. the ServiceExportApprovals, are exported to
+//     a file, and imported into the new hub cluster, where a rebuild window is opened (see package rebuild);
+//  2. the member agents join the new hub cluster as an additional hub cluster (--additional-hubs-config), so that
+//     their exports reach both hub clusters, while their ServiceImports are still imported from the old one;
+//  3. the migration is verified: the new hub cluster has converged when it has resolved every ServiceImport of the
+//     old hub cluster the same way, from the same EndpointSliceExports;
+//  4. the member agents are pointed at the new hub cluster as their primary hub cluster, and, once none of them
+//     reports to the old hub cluster any longer, the old hub cluster is decommissioned.
+package migration
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/yaml"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/prune"
+)
+
+// Kinds are the kinds of the objects the fleet operator keeps in the hub cluster, which are migrated to the new
+// hub cluster; the other networking objects are re-published by the member clusters, and derived from them.
+var Kinds = []schema.GroupVersionKind{
+	fleetnetv1alpha1.GroupVersion.WithKind("ServiceExportApproval"),
+	fleetnetv1alpha1.GroupVersion.WithKind("ExportQuota"),
+	fleetnetv1alpha1.GroupVersion.WithKind("ClusterNetworkTopology"),
+	fleetnetv1alpha1.GroupVersion.WithKind("NamespaceSamenessPolicy"),
+	fleetnetv1alpha1.GroupVersion.WithKind("BackendTrafficPolicy"),
+	fleetnetv1alpha1.GroupVersion.WithKind("TrafficManagerProfile"),
+	fleetnetv1alpha1.GroupVersion.WithKind("TrafficManagerBackend"),
+	fleetnetv1alpha1.GroupVersion.WithKind("FrontDoorBackend"),
+	fleetnetv1alpha1.GroupVersion.WithKind("MultiClusterIngress"),
+}
+
+// Export writes the objects of the Kinds in the hub cluster to w as a YAML List, without their status and the
+// metadata set by the API server; it returns the number of the objects exported. The kinds whose CRDs are not
+// installed in the hub cluster are skipped.
+func Export(ctx context.Context, hubClient client.Reader, w io.Writer) (int, error) {
+	items := []map[string]interface{}{}
+	for _, gvk := range Kinds {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := hubClient.List(ctx, list); err != nil {
+			if meta.IsNoMatchError(err) {
+				klog.V(2).InfoS("Skipped the kind whose CRD is not installed", "kind", gvk.Kind)
+				continue
+			}
+			return 0, fmt.Errorf("failed to list %s: %w", gvk.Kind, err)
+		}
+		sort.Slice(list.Items, func(i, j int) bool {
+			return client.ObjectKeyFromObject(&list.Items[i]).String() < client.ObjectKeyFromObject(&list.Items[j]).String()
+		})
+		for i := range list.Items {
+			items = append(items, portable(&list.Items[i], gvk))
+		}
+	}
+
+	data, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"items":      items,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal the exported objects: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return 0, fmt.Errorf("failed to write the exported objects: %w", err)
+	}
+	return len(items), nil
+}
+
+// portable returns the content of the object which can be applied to another cluster.
+func portable(obj *unstructured.Unstructured, gvk schema.GroupVersionKind) map[string]interface{} {
+	content := make(map[string]interface{}, len(obj.Object))
+	for k, v := range obj.Object {
+		if k == "metadata" || k == "status" {
+			continue
+		}
+		content[k] = v
+	}
+	content["apiVersion"] = gvk.GroupVersion().String()
+	content["kind"] = gvk.Kind
+	metadata := map[string]interface{}{"name": obj.GetName()}
+	if obj.GetNamespace() != "" {
+		metadata["namespace"] = obj.GetNamespace()
+	}
+	if labels := obj.GetLabels(); len(labels) > 0 {
+		metadata["labels"] = labels
+	}
+	if annotations := obj.GetAnnotations(); len(annotations) > 0 {
+		metadata["annotations"] = annotations
+	}
+	content["metadata"] = metadata
+	return content
+}
+
+// Import creates or updates the objects in the YAML List read from r, as written by Export, in the hub cluster;
+// the namespaces of the objects are created if they do not exist. It returns the number of the objects imported.
+func Import(ctx context.Context, hubClient client.Client, r io.Reader) (int, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the exported objects: %w", err)
+	}
+	data, err = yaml.YAMLToJSON(data)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse the exported objects: %w", err)
+	}
+	list := &unstructured.UnstructuredList{}
+	if err := list.UnmarshalJSON(data); err != nil {
+		return 0, fmt.Errorf("failed to parse the exported objects: %w", err)
+	}
+
+	namespaces := map[string]bool{}
+	for i := range list.Items {
+		item := &list.Items[i]
+		if ns := item.GetNamespace(); ns != "" && !namespaces[ns] {
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns}}
+			if err := hubClient.Create(ctx, namespace); client.IgnoreAlreadyExists(err) != nil {
+				return i, fmt.Errorf("failed to create namespace %s: %w", ns, err)
+			}
+			namespaces[ns] = true
+		}
+
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(item.GroupVersionKind())
+		obj.SetNamespace(item.GetNamespace())
+		obj.SetName(item.GetName())
+		op, err := controllerutil.CreateOrUpdate(ctx, hubClient, obj, func() error {
+			for k, v := range item.Object {
+				if k == "metadata" || k == "status" {
+					continue
+				}
+				obj.Object[k] = v
+			}
+			obj.SetLabels(item.GetLabels())
+			obj.SetAnnotations(item.GetAnnotations())
+			return nil
+		})
+		if err != nil {
+			return i, fmt.Errorf("failed to import %s %s: %w", item.GetKind(), klog.KObj(item), err)
+		}
+		klog.V(2).InfoS("Imported object", "kind", item.GetKind(), "object", klog.KObj(item), "op", op)
+	}
+	return len(list.Items), nil
+}
+
+// Difference is a way in which the new hub cluster has yet to converge with the old hub cluster.
+type Difference struct {
+	// Kind is the kind of the object which differs.
+	Kind string
+	// Namespace is the namespace of the object.
+	Namespace string
+	// Name is the name of the object, or the ID of the member cluster for the EndpointSliceExports.
+	Name string
+	// Reason tells how the object differs.
+	Reason string
+}
+
+// Report reports whether the new hub cluster has converged with the old hub cluster.
+type Report struct {
+	// ServiceImports is the number of the ServiceImports resolved in the old hub cluster.
+	ServiceImports int
+	// Differences are the ways in which the new hub cluster has yet to converge, sorted by kind, namespace and name.
+	Differences []Difference
+}
+
+// Converged returns true if the new hub cluster has converged with the old hub cluster.
+func (r *Report) Converged() bool {
+	return len(r.Differences) == 0
+}
+
+// Verify compares the state of the new hub cluster with the old hub cluster: the new hub cluster has converged when
+// it has resolved every ServiceImport resolved in the old hub cluster with the same type, ports and clusters, and
+// every member cluster has exported as many EndpointSlices to it.
+func Verify(ctx context.Context, oldHubClient, newHubClient client.Reader) (*Report, error) {
+	report := &Report{}
+
+	oldImports, err := resolvedServiceImports(ctx, oldHubClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the serviceImports of the old hub cluster: %w", err)
+	}
+	newImports, err := resolvedServiceImports(ctx, newHubClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the serviceImports of the new hub cluster: %w", err)
+	}
+	report.ServiceImports = len(oldImports)
+	for key, old := range oldImports {
+		diff := Difference{Kind: "ServiceImport", Namespace: key.Namespace, Name: key.Name}
+		cur, ok := newImports[key]
+		switch {
+		case !ok:
+			diff.Reason = "not resolved"
+		case old.Status.Type != cur.Status.Type:
+			diff.Reason = fmt.Sprintf("type %s, want %s", cur.Status.Type, old.Status.Type)
+		case !equality.Semantic.DeepEqual(old.Status.Ports, cur.Status.Ports):
+			diff.Reason = "ports differ"
+		case !equality.Semantic.DeepEqual(clusterNames(old), clusterNames(cur)):
+			diff.Reason = fmt.Sprintf("clusters %v, want %v", clusterNames(cur), clusterNames(old))
+		default:
+			continue
+		}
+		report.Differences = append(report.Differences, diff)
+	}
+
+	oldCounts, err := endpointSliceExportCounts(ctx, oldHubClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the endpointSliceExports of the old hub cluster: %w", err)
+	}
+	newCounts, err := endpointSliceExportCounts(ctx, newHubClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the endpointSliceExports of the new hub cluster: %w", err)
+	}
+	for cluster, count := range oldCounts {
+		if newCounts[cluster] < count {
+			report.Differences = append(report.Differences, Difference{
+				Kind:   "EndpointSliceExport",
+				Name:   cluster,
+				Reason: fmt.Sprintf("%d of %d exported", newCounts[cluster], count),
+			})
+		}
+	}
+
+	sort.Slice(report.Differences, func(i, j int) bool {
+		a, b := report.Differences[i], report.Differences[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return report, nil
+}
+
+// resolvedServiceImports returns the ServiceImports of the hub cluster whose spec has been resolved.
+func resolvedServiceImports(ctx context.Context, hubClient client.Reader) (map[client.ObjectKey]*fleetnetv1alpha1.ServiceImport, error) {
+	list := &fleetnetv1alpha1.ServiceImportList{}
+	if err := hubClient.List(ctx, list); err != nil {
+		return nil, err
+	}
+	imports := make(map[client.ObjectKey]*fleetnetv1alpha1.ServiceImport, len(list.Items))
+	for i := range list.Items {
+		if len(list.Items[i].Status.Clusters) > 0 {
+			imports[client.ObjectKeyFromObject(&list.Items[i])] = &list.Items[i]
+		}
+	}
+	return imports, nil
+}
+
+// clusterNames returns the sorted names of the clusters of a ServiceImport.
+func clusterNames(svcImport *fleetnetv1alpha1.ServiceImport) []string {
+	names := make([]string, 0, len(svcImport.Status.Clusters))
+	for _, c := range svcImport.Status.Clusters {
+		names = append(names, c.Cluster)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// endpointSliceExportCounts counts the EndpointSliceExports of the hub cluster by member cluster.
+func endpointSliceExportCounts(ctx context.Context, hubClient client.Reader) (map[string]int, error) {
+	list := &fleetnetv1alpha1.EndpointSliceExportList{}
+	if err := hubClient.List(ctx, list); err != nil {
+		return nil, err
+	}
+	counts := map[string]int{}
+	for i := range list.Items {
+		counts[list.Items[i].Spec.EndpointSliceReference.ClusterID]++
+	}
+	return counts, nil
+}
+
+// Print writes a human-readable report of the convergence.
+func (r *Report) Print(w io.Writer) error {
+	if r.Converged() {
+		_, err := fmt.Fprintf(w, "The new hub cluster has converged: %d ServiceImport(s) resolved the same way.\n", r.ServiceImports)
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tNAMESPACE\tNAME\tREASON")
+	for _, d := range r.Differences {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", d.Kind, d.Namespace, d.Name, d.Reason)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "The new hub cluster has yet to converge: %d difference(s).\n", len(r.Differences))
+	return err
+}
+
+// Decommission deletes the objects exported and imported by the member clusters from the old hub cluster, unless
+// dryRun is set; it refuses to if any member cluster has reported to the old hub cluster within the given period,
+// as its agent still treats the old hub cluster as a hub cluster of its own.
+func Decommission(ctx context.Context, oldHubClient client.Client, quietPeriod time.Duration, now time.Time, dryRun bool) (*prune.Summary, error) {
+	statusList := &fleetnetv1alpha1.InternalMemberNetworkStatusList{}
+	if err := oldHubClient.List(ctx, statusList); err != nil {
+		return nil, fmt.Errorf("failed to list internalMemberNetworkStatuses: %w", err)
+	}
+	var reporting []string
+	for i := range statusList.Items {
+		heartbeat := statusList.Items[i].Status.LastHeartbeatTime
+		if heartbeat != nil && now.Sub(heartbeat.Time) < quietPeriod {
+			reporting = append(reporting, statusList.Items[i].Status.ClusterID)
+		}
+	}
+	if len(reporting) > 0 {
+		sort.Strings(reporting)
+		return nil, fmt.Errorf("member clusters %v still report to the hub cluster; point their agents at the new hub cluster first", reporting)
+	}
+	return prune.RunAll(ctx, oldHubClient, dryRun)
+}
@@ -0,0 +1,244 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package migration
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+func testScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	return scheme
+}
+
+// TestExportImport tests that the objects exported from the old hub cluster are imported into the new one.
+func TestExportImport(t *testing.T) {
+	ctx := context.Background()
+	scheme := testScheme(t)
+	oldHubClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&fleetnetv1alpha1.ServiceExportApproval{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       "work",
+				Name:            "app",
+				Labels:          map[string]string{"team": "a"},
+				ResourceVersion: "10",
+			},
+			Spec: fleetnetv1alpha1.ServiceExportApprovalSpec{Clusters: []string{"member-1"}},
+		},
+		&fleetnetv1alpha1.ExportQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			Spec:       fleetnetv1alpha1.ExportQuotaSpec{Scope: fleetnetv1alpha1.ExportQuotaScopeCluster},
+		},
+		// Re-published by the member clusters rather than migrated.
+		&fleetnetv1alpha1.InternalServiceExport{ObjectMeta: metav1.ObjectMeta{Namespace: "fleet-member-member-1", Name: "work-app"}},
+	).Build()
+
+	var buf bytes.Buffer
+	exported, err := Export(ctx, oldHubClient, &buf)
+	if err != nil {
+		t.Fatalf("Export() = %v, want no error", err)
+	}
+	if exported != 2 {
+		t.Errorf("Export() = %d, want 2", exported)
+	}
+
+	newHubClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		// A stale approval left in the new hub cluster is overwritten.
+		&fleetnetv1alpha1.ServiceExportApproval{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "work", Name: "app"},
+			Spec:       fleetnetv1alpha1.ServiceExportApprovalSpec{Clusters: []string{"member-2"}},
+		},
+	).Build()
+	imported, err := Import(ctx, newHubClient, &buf)
+	if err != nil {
+		t.Fatalf("Import() = %v, want no error", err)
+	}
+	if imported != 2 {
+		t.Errorf("Import() = %d, want 2", imported)
+	}
+
+	approval := &fleetnetv1alpha1.ServiceExportApproval{}
+	if err := newHubClient.Get(ctx, client.ObjectKey{Namespace: "work", Name: "app"}, approval); err != nil {
+		t.Fatalf("ServiceExportApproval Get() = %v, want no error", err)
+	}
+	if diff := cmp.Diff([]string{"member-1"}, approval.Spec.Clusters); diff != "" {
+		t.Errorf("ServiceExportApproval clusters mismatch (-want, +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]string{"team": "a"}, approval.Labels); diff != "" {
+		t.Errorf("ServiceExportApproval labels mismatch (-want, +got):\n%s", diff)
+	}
+	quota := &fleetnetv1alpha1.ExportQuota{}
+	if err := newHubClient.Get(ctx, client.ObjectKey{Name: "default"}, quota); err != nil {
+		t.Fatalf("ExportQuota Get() = %v, want no error", err)
+	}
+	if quota.Spec.Scope != fleetnetv1alpha1.ExportQuotaScopeCluster {
+		t.Errorf("ExportQuota scope = %q, want %q", quota.Spec.Scope, fleetnetv1alpha1.ExportQuotaScopeCluster)
+	}
+	if err := newHubClient.Get(ctx, client.ObjectKey{Name: "work"}, &corev1.Namespace{}); err != nil {
+		t.Errorf("Namespace Get() = %v, want the namespace created", err)
+	}
+	internalSvcExportList := &fleetnetv1alpha1.InternalServiceExportList{}
+	if err := newHubClient.List(ctx, internalSvcExportList); err != nil {
+		t.Fatalf("InternalServiceExport List() = %v, want no error", err)
+	}
+	if len(internalSvcExportList.Items) != 0 {
+		t.Errorf("InternalServiceExport List() = %d items, want none migrated", len(internalSvcExportList.Items))
+	}
+}
+
+// TestVerify tests the convergence of the new hub cluster with the old one.
+func TestVerify(t *testing.T) {
+	serviceImport := func(clusters ...string) *fleetnetv1alpha1.ServiceImport {
+		svcImport := &fleetnetv1alpha1.ServiceImport{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "work", Name: "app"},
+			Status: fleetnetv1alpha1.ServiceImportStatus{
+				Type:  fleetnetv1alpha1.ClusterSetIP,
+				Ports: []fleetnetv1alpha1.ServicePort{{Port: 80, Protocol: corev1.ProtocolTCP}},
+			},
+		}
+		for _, c := range clusters {
+			svcImport.Status.Clusters = append(svcImport.Status.Clusters, fleetnetv1alpha1.ServiceImportClusterStatus{Cluster: c})
+		}
+		return svcImport
+	}
+	endpointSliceExport := func(name, cluster string) *fleetnetv1alpha1.EndpointSliceExport {
+		return &fleetnetv1alpha1.EndpointSliceExport{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "fleet-member-" + cluster, Name: name},
+			Spec: fleetnetv1alpha1.EndpointSliceExportSpec{
+				EndpointSliceReference: fleetnetv1alpha1.ExportedObjectReference{ClusterID: cluster},
+			},
+		}
+	}
+	oldHubObjects := []client.Object{
+		serviceImport("member-1", "member-2"),
+		endpointSliceExport("work-app-1", "member-1"),
+		endpointSliceExport("work-app-2", "member-2"),
+	}
+
+	testCases := []struct {
+		name          string
+		newHubObjects []client.Object
+		want          []Difference
+	}{
+		{
+			name: "converged",
+			newHubObjects: []client.Object{
+				serviceImport("member-2", "member-1"),
+				endpointSliceExport("work-app-1", "member-1"),
+				endpointSliceExport("work-app-2", "member-2"),
+			},
+		},
+		{
+			name: "nothing re-published yet",
+			want: []Difference{
+				{Kind: "EndpointSliceExport", Name: "member-1", Reason: "0 of 1 exported"},
+				{Kind: "EndpointSliceExport", Name: "member-2", Reason: "0 of 1 exported"},
+				{Kind: "ServiceImport", Namespace: "work", Name: "app", Reason: "not resolved"},
+			},
+		},
+		{
+			name: "member cluster missing",
+			newHubObjects: []client.Object{
+				serviceImport("member-1"),
+				endpointSliceExport("work-app-1", "member-1"),
+			},
+			want: []Difference{
+				{Kind: "EndpointSliceExport", Name: "member-2", Reason: "0 of 1 exported"},
+				{Kind: "ServiceImport", Namespace: "work", Name: "app", Reason: "clusters [member-1], want [member-1 member-2]"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := testScheme(t)
+			oldHubClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(oldHubObjects...).Build()
+			newHubClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.newHubObjects...).Build()
+			report, err := Verify(context.Background(), oldHubClient, newHubClient)
+			if err != nil {
+				t.Fatalf("Verify() = %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.want, report.Differences); diff != "" {
+				t.Errorf("Verify() differences mismatch (-want, +got):\n%s", diff)
+			}
+			if got, want := report.Converged(), len(tc.want) == 0; got != want {
+				t.Errorf("Converged() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+// TestDecommission tests that the old hub cluster is decommissioned only once no member cluster reports to it.
+func TestDecommission(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	status := func(heartbeat time.Time) *fleetnetv1alpha1.InternalMemberNetworkStatus {
+		return &fleetnetv1alpha1.InternalMemberNetworkStatus{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "fleet-member-member-1", Name: "member-1"},
+			Status: fleetnetv1alpha1.InternalMemberNetworkStatusStatus{
+				ClusterID:         "member-1",
+				LastHeartbeatTime: &metav1.Time{Time: heartbeat},
+			},
+		}
+	}
+	testCases := []struct {
+		name       string
+		status     *fleetnetv1alpha1.InternalMemberNetworkStatus
+		wantErr    bool
+		wantPruned int
+	}{
+		{
+			name:    "member cluster still reporting",
+			status:  status(now.Add(-time.Minute)),
+			wantErr: true,
+		},
+		{
+			name:       "member cluster gone quiet",
+			status:     status(now.Add(-time.Hour)),
+			wantPruned: 1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			oldHubClient := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(
+				tc.status,
+				&fleetnetv1alpha1.InternalServiceExport{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "fleet-member-member-1", Name: "work-app"},
+					Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+						ServiceReference: fleetnetv1alpha1.ExportedObjectReference{ClusterID: "member-1"},
+					},
+				},
+			).Build()
+			summary, err := Decommission(ctx, oldHubClient, 5*time.Minute, now, false)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("Decommission() = %v, want error %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if len(summary.Objects) != tc.wantPruned {
+				t.Errorf("Decommission() pruned %d objects, want %d", len(summary.Objects), tc.wantPruned)
+			}
+		})
+	}
+}
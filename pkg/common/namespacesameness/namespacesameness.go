@@ -0,0 +1,72 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package namespacesameness features the evaluation of the NamespaceSamenessPolicies, which restrict the namespaces
+// participating in multi-cluster networking.
+package namespacesameness
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+// Check returns the reason why the namespace is excluded from multi-cluster networking by the
+// NamespaceSamenessPolicies, or an empty string if it is allowed by all of them. A namespace which does not exist,
+// e.g. a namespace of a member cluster which has not been created in the hub cluster, is evaluated with its name
+// label only.
+func Check(ctx context.Context, reader client.Reader, namespace string) (string, error) {
+	policyList := &fleetnetv1alpha1.NamespaceSamenessPolicyList{}
+	if err := reader.List(ctx, policyList); err != nil {
+		return "", err
+	}
+	if len(policyList.Items) == 0 {
+		return "", nil
+	}
+	nsLabels := map[string]string{corev1.LabelMetadataName: namespace}
+	ns := &corev1.Namespace{}
+	if err := reader.Get(ctx, types.NamespacedName{Name: namespace}, ns); client.IgnoreNotFound(err) != nil {
+		return "", err
+	}
+	for k, v := range ns.Labels {
+		nsLabels[k] = v
+	}
+	return CheckLabels(policyList.Items, namespace, nsLabels), nil
+}
+
+// CheckLabels returns the reason why the first policy excluding the namespace with the given labels does so, or an
+// empty string if all the policies allow the namespace; a policy with an invalid selector excludes all the
+// namespaces, so that a typo never widens the exposure.
+func CheckLabels(policies []fleetnetv1alpha1.NamespaceSamenessPolicy, namespace string, nsLabels map[string]string) string {
+	for i := range policies {
+		spec := &policies[i].Spec
+		if spec.DeniedNamespaces != nil {
+			selector, err := metav1.LabelSelectorAsSelector(spec.DeniedNamespaces)
+			if err != nil {
+				return fmt.Sprintf("namespace sameness policy %s has an invalid deniedNamespaces selector: %v", policies[i].Name, err)
+			}
+			if selector.Matches(labels.Set(nsLabels)) {
+				return fmt.Sprintf("namespace %s is denied by namespace sameness policy %s", namespace, policies[i].Name)
+			}
+		}
+		if spec.AllowedNamespaces != nil {
+			selector, err := metav1.LabelSelectorAsSelector(spec.AllowedNamespaces)
+			if err != nil {
+				return fmt.Sprintf("namespace sameness policy %s has an invalid allowedNamespaces selector: %v", policies[i].Name, err)
+			}
+			if !selector.Matches(labels.Set(nsLabels)) {
+				return fmt.Sprintf("namespace %s is not allowed by namespace sameness policy %s", namespace, policies[i].Name)
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,145 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package namespacesameness
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+const testNamespace = "work"
+
+func policy(name string, spec fleetnetv1alpha1.NamespaceSamenessPolicySpec) fleetnetv1alpha1.NamespaceSamenessPolicy {
+	return fleetnetv1alpha1.NamespaceSamenessPolicy{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: spec}
+}
+
+func selector(matchLabels map[string]string) *metav1.LabelSelector {
+	return &metav1.LabelSelector{MatchLabels: matchLabels}
+}
+
+// TestCheckLabels tests the CheckLabels function.
+func TestCheckLabels(t *testing.T) {
+	nsLabels := map[string]string{corev1.LabelMetadataName: testNamespace, "exposure": "fleet"}
+	invalidSelector := &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "exposure", Operator: "Unknown"}},
+	}
+
+	testCases := []struct {
+		name     string
+		policies []fleetnetv1alpha1.NamespaceSamenessPolicy
+		want     string
+	}{
+		{
+			name: "no policy",
+		},
+		{
+			name: "allowed by label",
+			policies: []fleetnetv1alpha1.NamespaceSamenessPolicy{
+				policy("fleet", fleetnetv1alpha1.NamespaceSamenessPolicySpec{AllowedNamespaces: selector(map[string]string{"exposure": "fleet"})}),
+			},
+		},
+		{
+			name: "not allowed by label",
+			policies: []fleetnetv1alpha1.NamespaceSamenessPolicy{
+				policy("private", fleetnetv1alpha1.NamespaceSamenessPolicySpec{AllowedNamespaces: selector(map[string]string{"exposure": "private"})}),
+			},
+			want: "namespace work is not allowed by namespace sameness policy private",
+		},
+		{
+			name: "denied by name, which takes precedence over allowed",
+			policies: []fleetnetv1alpha1.NamespaceSamenessPolicy{
+				policy("fleet", fleetnetv1alpha1.NamespaceSamenessPolicySpec{
+					AllowedNamespaces: selector(map[string]string{"exposure": "fleet"}),
+					DeniedNamespaces:  selector(map[string]string{corev1.LabelMetadataName: testNamespace}),
+				}),
+			},
+			want: "namespace work is denied by namespace sameness policy fleet",
+		},
+		{
+			name: "allowed by one policy but not by another",
+			policies: []fleetnetv1alpha1.NamespaceSamenessPolicy{
+				policy("fleet", fleetnetv1alpha1.NamespaceSamenessPolicySpec{AllowedNamespaces: selector(map[string]string{"exposure": "fleet"})}),
+				policy("team", fleetnetv1alpha1.NamespaceSamenessPolicySpec{AllowedNamespaces: selector(map[string]string{"team": "payments"})}),
+			},
+			want: "namespace work is not allowed by namespace sameness policy team",
+		},
+		{
+			name: "invalid selector",
+			policies: []fleetnetv1alpha1.NamespaceSamenessPolicy{
+				policy("typo", fleetnetv1alpha1.NamespaceSamenessPolicySpec{DeniedNamespaces: invalidSelector}),
+			},
+			want: "namespace sameness policy typo has an invalid deniedNamespaces selector: \"Unknown\" is not a valid label selector operator",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := CheckLabels(tc.policies, testNamespace, nsLabels); got != tc.want {
+				t.Errorf("CheckLabels() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestCheck tests the Check function.
+func TestCheck(t *testing.T) {
+	allowFleet := policy("fleet", fleetnetv1alpha1.NamespaceSamenessPolicySpec{AllowedNamespaces: selector(map[string]string{"exposure": "fleet"})})
+	allowByName := policy("by-name", fleetnetv1alpha1.NamespaceSamenessPolicySpec{AllowedNamespaces: selector(map[string]string{corev1.LabelMetadataName: testNamespace})})
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: testNamespace, Labels: map[string]string{"exposure": "fleet"}},
+	}
+
+	testCases := []struct {
+		name    string
+		objects []client.Object
+		want    string
+	}{
+		{
+			name:    "no policy",
+			objects: []client.Object{namespace},
+		},
+		{
+			name:    "namespace allowed by its labels",
+			objects: []client.Object{namespace, &allowFleet},
+		},
+		{
+			name:    "namespace not found is evaluated with its name label",
+			objects: []client.Object{&allowByName},
+		},
+		{
+			name:    "namespace not found is not allowed by label",
+			objects: []client.Object{&allowFleet},
+			want:    "namespace work is not allowed by namespace sameness policy fleet",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			if err := corev1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.objects...).Build()
+
+			got, err := Check(context.Background(), fakeClient, testNamespace)
+			if err != nil {
+				t.Fatalf("Check() = %v, want no error", err)
+			}
+			if got != tc.want {
+				t.Errorf("Check() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -26,6 +26,25 @@ const (
 	// TrafficManagerBackendFinalizer a finalizer added by the TrafficManagerBackend controller to all trafficManagerBackends,
 	// to make sure that the controller can react to backend deletions if necessary.
 	TrafficManagerBackendFinalizer = fleetNetworkingPrefix + "traffic-manager-backend-cleanup"
+
+	// FrontDoorBackendFinalizer a finalizer added by the FrontDoorBackend controller to all frontDoorBackends,
+	// to make sure that the controller can delete the Azure Front Door origin group before the backend is deleted.
+	FrontDoorBackendFinalizer = fleetNetworkingPrefix + "front-door-backend-cleanup"
+
+	// MultiClusterIngressFinalizer a finalizer added by the MultiClusterIngress controller to all multiClusterIngresses,
+	// to make sure that the controller can delete the Azure Front Door endpoint and custom domains before the ingress
+	// is deleted.
+	MultiClusterIngressFinalizer = fleetNetworkingPrefix + "multi-cluster-ingress-cleanup"
+
+	// ServiceExportCleanupFinalizer is the default finalizer the ServiceExport controller adds to mark that a
+	// ServiceExport can only be deleted after its corresponding Service has been unexported from the hub cluster.
+	// The member agent can be configured to use a different name.
+	ServiceExportCleanupFinalizer = fleetNetworkingPrefix + "svc-export-cleanup"
+
+	// ServiceImportCleanupFinalizer is the default finalizer the ServiceImport controller adds to mark that a
+	// ServiceImport can only be deleted after its corresponding InternalServiceImport has been deleted from the hub
+	// cluster. The member agent can be configured to use a different name.
+	ServiceImportCleanupFinalizer = fleetNetworkingPrefix + "serviceimport-cleanup"
 )
 
 // Labels
@@ -33,6 +52,84 @@ const (
 	// MultiClusterServiceLabelDerivedService is the label added by the MCS controller, which marks the
 	// derived Service behind a MCS.
 	MultiClusterServiceLabelDerivedService = fleetNetworkingPrefix + "derived-service"
+
+	// ServiceImportLabelDerivedService is the label added by the derivedservice controller to ServiceImports which
+	// are not imported by a MCS, which marks the derived Service behind the ServiceImport.
+	ServiceImportLabelDerivedService = fleetNetworkingPrefix + "derived-service"
+
+	// ServiceLabelServiceImportNamespace and ServiceLabelServiceImportName are the labels added by the
+	// derivedservice controller to derived Services, which mark the ServiceImport behind the Service.
+	ServiceLabelServiceImportNamespace = fleetNetworkingPrefix + "service-import-namespace"
+	ServiceLabelServiceImportName      = fleetNetworkingPrefix + "service-import-name"
+
+	// ServiceLabelLoadBalancerFor is the label added by the loadbalancerexport controller to the internal
+	// LoadBalancer Services it creates, which marks the name of the exported Service the load balancer fronts.
+	ServiceLabelLoadBalancerFor = fleetNetworkingPrefix + "load-balancer-for"
+
+	// EndpointSliceExportLabelOwnerServiceNamespace is the label added by the EndpointSlice controller to
+	// EndpointSliceExports, which marks the namespace of the Service that owns the exported EndpointSlice.
+	EndpointSliceExportLabelOwnerServiceNamespace = fleetNetworkingPrefix + "owner-service-namespace"
+
+	// EndpointSliceExportLabelOwnerServiceName is the label added by the EndpointSlice controller to
+	// EndpointSliceExports, which marks the name of the Service that owns the exported EndpointSlice.
+	EndpointSliceExportLabelOwnerServiceName = fleetNetworkingPrefix + "owner-service-name"
+
+	// InternalServiceExportLabelServiceNamespace is the label added by the ServiceExport controller to
+	// InternalServiceExports, which marks the namespace of the exported Service.
+	InternalServiceExportLabelServiceNamespace = fleetNetworkingPrefix + "service-namespace"
+
+	// InternalServiceExportLabelServiceName is the label added by the ServiceExport controller to
+	// InternalServiceExports, which marks the name of the exported Service.
+	InternalServiceExportLabelServiceName = fleetNetworkingPrefix + "service-name"
+
+	// ServiceExportLabelAutoExported is the label added by the autoexport controller to the ServiceExports it creates
+	// for the Services selected by the auto export policies, with the value "true"; ServiceExports without the label
+	// are never deleted by the controller.
+	ServiceExportLabelAutoExported = fleetNetworkingPrefix + "auto-exported"
+
+	// MemberClusterLabelExportsQuarantined is the label added by the fleet operator to a MemberCluster in the hub
+	// cluster to quarantine the exports of the member cluster; with the value "true", the exports of the cluster
+	// are excluded from all the ServiceImports until the label is removed.
+	MemberClusterLabelExportsQuarantined = fleetNetworkingPrefix + "exports-quarantined"
+
+	// MemberClusterLabelExportPriority is the label added by the fleet operator to a MemberCluster in the hub
+	// cluster to set the priority, an integer, of the exports of the member cluster under the ClusterPriority
+	// conflict resolution policy; higher wins, and clusters without a valid priority have the priority 0.
+	MemberClusterLabelExportPriority = fleetNetworkingPrefix + "export-priority"
+
+	// MemberNamespaceLabelTenant is the label added by the fleet operator to the namespace of a member cluster in the
+	// hub cluster to assign the member cluster to a tenant of the fleet; the member clusters whose namespaces are not
+	// labeled belong to the default tenant.
+	MemberNamespaceLabelTenant = fleetNetworkingPrefix + "tenant"
+
+	// ServiceImportLabelTenant is the label added by the hub to a ServiceImport to record the tenant of the fleet
+	// owning it, i.e. the only tenant whose member clusters export and import the Service.
+	ServiceImportLabelTenant = fleetNetworkingPrefix + "tenant"
+
+	// FrontDoorBackendLabelMultiClusterIngress is the label added by the MultiClusterIngress controller to the
+	// FrontDoorBackends it creates for the backends of an ingress, whose value is the name of the ingress.
+	FrontDoorBackendLabelMultiClusterIngress = fleetNetworkingPrefix + "multi-cluster-ingress"
+
+	// EndpointSliceLabelSourceCluster is the well-known label of the Multi-Cluster Services API, which the
+	// EndpointSliceImport controller adds to the imported EndpointSlices to mark the cluster they are exported from.
+	EndpointSliceLabelSourceCluster = "multicluster.kubernetes.io/source-cluster"
+
+	// EndpointSliceLabelServiceImportName is the well-known label of the Multi-Cluster Services API, which marks the
+	// ServiceImport, in the same namespace, an EndpointSlice belongs to; Gateway API implementations resolve the
+	// ServiceImport backends of their routes to the EndpointSlices with the label.
+	EndpointSliceLabelServiceImportName = "multicluster.kubernetes.io/service-name"
+
+	// LabelManagedBy is the well-known label which marks the tool that manages an object; the hub networking
+	// controller manager adds it to the objects it derives, e.g. ServiceExportSummaries, with the value
+	// HubNetControllerManagerName, and the member networking controller manager to the objects it reports to the
+	// hub cluster, e.g. InternalMemberNetworkStatuses, with the value MemberNetControllerManagerName.
+	LabelManagedBy = "app.kubernetes.io/managed-by"
+
+	// HubNetControllerManagerName is the name of the hub networking controller manager.
+	HubNetControllerManagerName = "hub-net-controller-manager"
+
+	// MemberNetControllerManagerName is the name of the member networking controller manager.
+	MemberNetControllerManagerName = "member-net-controller-manager"
 )
 
 // Annotations
@@ -41,13 +138,122 @@ const (
 	// of member clusters importing an exported Service.
 	ServiceImportAnnotationServiceInUseBy = fleetNetworkingPrefix + "service-in-use-by"
 
+	// ServiceImportAnnotationConflictResolutionPolicy is an annotation added by the fleet operator to a
+	// ServiceImport in the hub cluster to override the default conflict resolution policy of the hub.
+	ServiceImportAnnotationConflictResolutionPolicy = fleetNetworkingPrefix + "conflict-resolution-policy"
+
+	// ServiceImportAnnotationConflictResolutionWinner is an annotation added by the fleet operator to a
+	// ServiceImport in the hub cluster to name the cluster whose exported Service spec wins under the Manual
+	// conflict resolution policy.
+	ServiceImportAnnotationConflictResolutionWinner = fleetNetworkingPrefix + "conflict-resolution-winner"
+
+	// ServiceImportAnnotationHub is an annotation added by the user to a ServiceImport in a member cluster joining
+	// more than one hub cluster, naming the additional hub cluster the service is imported from; the service is
+	// imported from the primary hub cluster if it is absent.
+	ServiceImportAnnotationHub = fleetNetworkingPrefix + "hub"
+
+	// EndpointSliceImportAnnotationReachability is the key of the annotation which marks how the importing member
+	// cluster reaches the exporting member cluster of an EndpointSliceImport exported through an east-west gateway,
+	// as decided by the hub per the ClusterNetworkTopology; the endpoints of the gateway are imported unless it is
+	// Direct.
+	EndpointSliceImportAnnotationReachability = fleetNetworkingPrefix + "reachability"
+
+	// EndpointSliceImportAnnotationReachabilityProbe is the key of the ReachabilityProbeResult annotation, which
+	// marks whether the importing member cluster reaches the endpoints of an EndpointSliceImport directly.
+	EndpointSliceImportAnnotationReachabilityProbe = fleetNetworkingPrefix + "reachability-probe"
+
+	// EndpointSliceImportAnnotationPrivateEndpointIP is the key of the annotation which marks the IP of the Azure
+	// Private Endpoint of the importing member cluster connected to the Private Link Service of an EndpointSliceImport;
+	// the IP is imported instead of the endpoints of the exporting cluster.
+	EndpointSliceImportAnnotationPrivateEndpointIP = fleetNetworkingPrefix + "private-endpoint-ip"
+
+	// ServiceExportAnnotationPrivateLinkServiceID is the key of the annotation which marks the resource ID of the
+	// Azure Private Link Service created by the exporting member cluster for the exported Service.
+	ServiceExportAnnotationPrivateLinkServiceID = fleetNetworkingPrefix + "private-link-service-id"
+
 	// ExportedObjectAnnotationUniqueName is an annotation that marks the fleet-scoped unique name assigned to
 	// an exported object.
 	ExportedObjectAnnotationUniqueName = fleetNetworkingPrefix + "fleet-unique-name"
 
+	// ExportedObjectAnnotationCorrelationID is an annotation that marks the W3C trace context of the span which
+	// exports or distributes the current version of an exported object, so that its journey across the fleet can
+	// be traced end to end.
+	ExportedObjectAnnotationCorrelationID = fleetNetworkingPrefix + "correlation-id"
+
+	// ExportedObjectAnnotationStaleSince is an annotation the hub cluster adds to the InternalServiceExports and
+	// EndpointSliceExports of a member cluster whose networking agent has stopped renewing its heartbeat; it marks
+	// the time the heartbeat expired, in RFC 3339.
+	ExportedObjectAnnotationStaleSince = fleetNetworkingPrefix + "stale-since"
+
+	// ExportedObjectAnnotationOrigin is an annotation that marks the origin of the endpoints of an EndpointSliceExport
+	// federated across fleets, and of the EndpointSliceImports they are distributed with; it is the path of the
+	// fleets the endpoints have traversed, the nearest first, followed by the member cluster they are exported
+	// from, e.g. "fleet-west/fleet-west-2/member-1".
+	ExportedObjectAnnotationOrigin = fleetNetworkingPrefix + "origin"
+
+	// NetworkStatusAnnotationResyncRequested is an annotation the fleet operator adds to the
+	// InternalMemberNetworkStatus of a member cluster to have its networking agent re-publish all of its exports to
+	// the hub cluster, e.g. after the hub cluster has been restored; the value is an opaque token, usually the time
+	// of the request in RFC 3339.
+	NetworkStatusAnnotationResyncRequested = fleetNetworkingPrefix + "resync-requested"
+
+	// NetworkStatusAnnotationResynced is an annotation the networking agent of a member cluster adds to its
+	// InternalMemberNetworkStatus once it has re-published its exports as requested; the value is the token of the
+	// request handled last.
+	NetworkStatusAnnotationResynced = fleetNetworkingPrefix + "resynced"
+
+	// ObjectAnnotationPaused is an annotation added by the fleet operator to a ServiceExport or a ServiceImport to
+	// pause its reconciliation; with the value "true", the controllers leave the object, and whatever has been
+	// propagated from or to it, as it is until the annotation is removed.
+	ObjectAnnotationPaused = fleetNetworkingPrefix + "paused"
+
 	// ServiceExportAnnotationWeight is an annotation that marks the weight of the ServiceExport.
 	ServiceExportAnnotationWeight = fleetNetworkingPrefix + "weight"
 
+	// ServiceExportAnnotationDNSTTL is an annotation that marks the TTL hint, in seconds, for DNS integrations
+	// caching the records of the exported Service; the value must be a positive integer.
+	ServiceExportAnnotationDNSTTL = "fleet.azure.com/dns-ttl"
+
+	// ServiceExportAnnotationEndpointWarmup is an annotation that marks the warmup period of the endpoints of the
+	// exported Service, i.e. how long an endpoint must stay ready before it is advertised to the fleet; the value
+	// must be a positive duration, e.g. "30s".
+	ServiceExportAnnotationEndpointWarmup = "fleet.azure.com/endpoint-warmup"
+
+	// ServiceExportAnnotationDrainPeriod is an annotation that marks the drain period of the exported Service, i.e.
+	// how long the endpoints of the Service stay exported as terminating after its ServiceExport is deleted, so that
+	// the in-flight connections from the importing clusters complete; the value must be a positive duration, e.g.
+	// "30s".
+	ServiceExportAnnotationDrainPeriod = "fleet.azure.com/drain-period"
+
+	// ServiceExportAnnotationCanaryPercent is an annotation that marks the exporting cluster as a canary which
+	// receives the given percentage of the fleet traffic to the Service; the value must be an integer, and is
+	// clamped to the range [0, 100].
+	ServiceExportAnnotationCanaryPercent = "fleet.azure.com/canary-percent"
+
+	// ServiceExportAnnotationConnectivityMode is an annotation that marks how the other member clusters connect to
+	// the exported Service; the value "LoadBalancer" exports the IP of the internal load balancer of the Service,
+	// which is created if the Service is not an internal LoadBalancer Service itself, instead of the pod IPs, e.g.
+	// when the pod networks of the member clusters are not peered.
+	ServiceExportAnnotationConnectivityMode = "fleet.azure.com/connectivity-mode"
+
+	// ServiceImportAnnotationClusterWeights is an annotation that assigns weights to the clusters backing the
+	// ServiceImport in a member cluster, e.g. "member-1=90,member-2=10", so that the traffic of the member cluster is
+	// split across the clusters in the ratio of their weights; the clusters not listed have a weight of 0.
+	ServiceImportAnnotationClusterWeights = "fleet.azure.com/cluster-weights"
+
+	// ServiceAnnotationHealthCheckPath is an annotation that marks the path, e.g. "/healthz", that dataplanes
+	// health-checking the endpoints of the exported Service should probe; the value must be a well-formed URL path.
+	ServiceAnnotationHealthCheckPath = "fleet.azure.com/health-check-path"
+
+	// ServiceAnnotationHealthCheckPort is an annotation that marks the port that dataplanes health-checking the
+	// endpoints of the exported Service should probe.
+	ServiceAnnotationHealthCheckPort = "fleet.azure.com/health-check-port"
+
+	// ServiceAnnotationPrivateLinkExposure is an annotation that marks the exported internal LoadBalancer Service to
+	// be exposed to the other member clusters through an Azure Private Link Service, e.g. when their virtual networks
+	// are not peered; the value must be "true".
+	ServiceAnnotationPrivateLinkExposure = "fleet.azure.com/private-link-exposure"
+
 	// ServiceAnnotationAzureLoadBalancerInternal is an annotation that marks the Service as an internal load balancer by cloud-provider-azure.
 	ServiceAnnotationAzureLoadBalancerInternal = "service.beta.kubernetes.io/azure-load-balancer-internal"
 
@@ -68,3 +274,8 @@ var (
 	// Note: The tag name cannot have reserved characters '<,>,%,&,\\,?,/' or control characters.
 	AzureTrafficManagerProfileTagKey = strings.ReplaceAll(fleetNetworkingPrefix, "/", ".") + "trafficManagerProfile"
 )
+
+// IsReservedKey returns true if the label or annotation key is reserved for the fleet networking controllers.
+func IsReservedKey(key string) bool {
+	return strings.HasPrefix(key, fleetNetworkingPrefix)
+}
@@ -0,0 +1,45 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package pause features the pausing of the reconciliation of ServiceExports and ServiceImports, which lets the
+// fleet operator freeze the propagation of a Service across the fleet, e.g. during incident response or migrations.
+//
+// An object annotated with "networking.fleet.azure.com/paused: true" is left as it is by the controllers, which
+// only record the Paused condition in its status; whatever has been propagated from or to the object stays as it
+// is until the annotation is removed, at which point the controllers remove the condition and catch up.
+package pause
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+// ReasonReconciliationPaused is the reason of the Paused condition of an object whose reconciliation is paused.
+const ReasonReconciliationPaused = "ReconciliationPaused"
+
+// IsPaused returns true if the reconciliation of the object is paused.
+func IsPaused(obj client.Object) bool {
+	return obj.GetAnnotations()[objectmeta.ObjectAnnotationPaused] == "true"
+}
+
+// SetCondition adds the Paused condition of the given type to the conditions if paused, or removes it otherwise,
+// and returns true if the conditions are changed.
+func SetCondition(conditions *[]metav1.Condition, conditionType string, paused bool, observedGeneration int64) bool {
+	if !paused {
+		return meta.RemoveStatusCondition(conditions, conditionType)
+	}
+	return meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: observedGeneration,
+		Reason:             ReasonReconciliationPaused,
+		Message:            fmt.Sprintf("reconciliation is paused by annotation %s", objectmeta.ObjectAnnotationPaused),
+	})
+}
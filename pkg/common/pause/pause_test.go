@@ -0,0 +1,109 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package pause
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+// TestIsPaused tests the IsPaused function.
+func TestIsPaused(t *testing.T) {
+	testCases := []struct {
+		name        string
+		annotations map[string]string
+		want        bool
+	}{
+		{
+			name: "no annotation",
+		},
+		{
+			name:        "paused",
+			annotations: map[string]string{objectmeta.ObjectAnnotationPaused: "true"},
+			want:        true,
+		},
+		{
+			name:        "not paused",
+			annotations: map[string]string{objectmeta.ObjectAnnotationPaused: "false"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svcExport := &fleetnetv1alpha1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "work", Name: "app", Annotations: tc.annotations},
+			}
+			if got := IsPaused(svcExport); got != tc.want {
+				t.Errorf("IsPaused() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestSetCondition tests the SetCondition function.
+func TestSetCondition(t *testing.T) {
+	conditionType := string(fleetnetv1alpha1.ServiceExportPaused)
+	pausedCond := metav1.Condition{
+		Type:               conditionType,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: 2,
+		Reason:             ReasonReconciliationPaused,
+		Message:            "reconciliation is paused by annotation networking.fleet.azure.com/paused",
+	}
+	validCond := metav1.Condition{
+		Type:   string(fleetnetv1alpha1.ServiceExportValid),
+		Status: metav1.ConditionTrue,
+		Reason: "ServiceIsValid",
+	}
+	testCases := []struct {
+		name           string
+		conditions     []metav1.Condition
+		paused         bool
+		wantConditions []metav1.Condition
+		wantChanged    bool
+	}{
+		{
+			name:           "paused",
+			conditions:     []metav1.Condition{validCond},
+			paused:         true,
+			wantConditions: []metav1.Condition{validCond, pausedCond},
+			wantChanged:    true,
+		},
+		{
+			name:           "still paused",
+			conditions:     []metav1.Condition{validCond, pausedCond},
+			paused:         true,
+			wantConditions: []metav1.Condition{validCond, pausedCond},
+		},
+		{
+			name:           "resumed",
+			conditions:     []metav1.Condition{validCond, pausedCond},
+			wantConditions: []metav1.Condition{validCond},
+			wantChanged:    true,
+		},
+		{
+			name:           "never paused",
+			conditions:     []metav1.Condition{validCond},
+			wantConditions: []metav1.Condition{validCond},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conditions := append([]metav1.Condition{}, tc.conditions...)
+			if got := SetCondition(&conditions, conditionType, tc.paused, 2); got != tc.wantChanged {
+				t.Errorf("SetCondition() = %t, want %t", got, tc.wantChanged)
+			}
+			if diff := cmp.Diff(tc.wantConditions, conditions, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime")); diff != "" {
+				t.Errorf("SetCondition() conditions mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package prune features the pruning of orphaned fleet networking objects in the hub cluster, i.e. the objects
+// exported or imported by member clusters which are no longer part of the fleet.
+package prune
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+// Object is a hub object which comes from an inactive member cluster.
+type Object struct {
+	// Kind is the kind of the object.
+	Kind string
+	// Namespace is the namespace of the object.
+	Namespace string
+	// Name is the name of the object.
+	Name string
+	// Cluster is the ID of the member cluster from which the object comes.
+	Cluster string
+}
+
+// Summary reports the objects pruned, or to be pruned in the dry-run mode.
+type Summary struct {
+	// DryRun reports whether the objects are only reported, rather than deleted.
+	DryRun bool
+	// Objects are the objects from inactive member clusters, sorted by kind, namespace and name.
+	Objects []Object
+}
+
+// Run finds all the InternalServiceExports, InternalServiceImports and EndpointSliceExports in the hub cluster
+// whose source member cluster is not in the active set, and deletes them unless dryRun is set.
+//
+// The source of an object is the cluster ID recorded in its exported object reference, which the member cluster
+// sets when the object is created.
+func Run(ctx context.Context, hubClient client.Client, activeClusters []string, dryRun bool) (*Summary, error) {
+	if len(activeClusters) == 0 {
+		// Refuse to prune every object in the hub, which is almost certainly a mistake.
+		return nil, fmt.Errorf("no active member clusters are specified")
+	}
+	active := make(map[string]bool, len(activeClusters))
+	for _, cluster := range activeClusters {
+		active[cluster] = true
+	}
+	return run(ctx, hubClient, func(cluster string) bool { return active[cluster] }, dryRun)
+}
+
+// RunAll deletes all the InternalServiceExports, InternalServiceImports and EndpointSliceExports in the hub cluster
+// unless dryRun is set, as if no member cluster were active; it is meant for the decommission of a hub cluster the
+// fleet has moved away from.
+func RunAll(ctx context.Context, hubClient client.Client, dryRun bool) (*Summary, error) {
+	return run(ctx, hubClient, func(string) bool { return false }, dryRun)
+}
+
+// run prunes the objects whose source member cluster is not active per the isActive predicate.
+func run(ctx context.Context, hubClient client.Client, isActive func(cluster string) bool, dryRun bool) (*Summary, error) {
+	internalSvcExportList := &fleetnetv1alpha1.InternalServiceExportList{}
+	if err := hubClient.List(ctx, internalSvcExportList); err != nil {
+		return nil, fmt.Errorf("failed to list internalServiceExports: %w", err)
+	}
+	internalSvcImportList := &fleetnetv1alpha1.InternalServiceImportList{}
+	if err := hubClient.List(ctx, internalSvcImportList); err != nil {
+		return nil, fmt.Errorf("failed to list internalServiceImports: %w", err)
+	}
+	endpointSliceExportList := &fleetnetv1alpha1.EndpointSliceExportList{}
+	if err := hubClient.List(ctx, endpointSliceExportList); err != nil {
+		return nil, fmt.Errorf("failed to list endpointSliceExports: %w", err)
+	}
+
+	type staleObject struct {
+		Object
+		obj client.Object
+	}
+	var stale []staleObject
+	for i := range internalSvcExportList.Items {
+		obj := &internalSvcExportList.Items[i]
+		if cluster := obj.Spec.ServiceReference.ClusterID; !isActive(cluster) {
+			stale = append(stale, staleObject{Object{Kind: "InternalServiceExport", Namespace: obj.Namespace, Name: obj.Name, Cluster: cluster}, obj})
+		}
+	}
+	for i := range internalSvcImportList.Items {
+		obj := &internalSvcImportList.Items[i]
+		if cluster := obj.Spec.ServiceImportReference.ClusterID; !isActive(cluster) {
+			stale = append(stale, staleObject{Object{Kind: "InternalServiceImport", Namespace: obj.Namespace, Name: obj.Name, Cluster: cluster}, obj})
+		}
+	}
+	for i := range endpointSliceExportList.Items {
+		obj := &endpointSliceExportList.Items[i]
+		if cluster := obj.Spec.EndpointSliceReference.ClusterID; !isActive(cluster) {
+			stale = append(stale, staleObject{Object{Kind: "EndpointSliceExport", Namespace: obj.Namespace, Name: obj.Name, Cluster: cluster}, obj})
+		}
+	}
+	sort.Slice(stale, func(i, j int) bool {
+		if stale[i].Kind != stale[j].Kind {
+			return stale[i].Kind < stale[j].Kind
+		}
+		if stale[i].Namespace != stale[j].Namespace {
+			return stale[i].Namespace < stale[j].Namespace
+		}
+		return stale[i].Name < stale[j].Name
+	})
+
+	summary := &Summary{DryRun: dryRun}
+	for _, s := range stale {
+		if !dryRun {
+			klog.V(2).InfoS("Deleting object from an inactive member cluster", "kind", s.Kind, "object", klog.KObj(s.obj), "cluster", s.Cluster)
+			// The hub controllers clean up the objects derived from the deleted ones, e.g. the ServiceImports.
+			if err := hubClient.Delete(ctx, s.obj); client.IgnoreNotFound(err) != nil {
+				return summary, fmt.Errorf("failed to delete %s %s: %w", s.Kind, klog.KObj(s.obj), err)
+			}
+		}
+		summary.Objects = append(summary.Objects, s.Object)
+	}
+	return summary, nil
+}
+
+// Print writes a human-readable report of the summary.
+func (s *Summary) Print(w io.Writer) error {
+	verb := "Deleted"
+	if s.DryRun {
+		verb = "Would delete"
+	}
+	if len(s.Objects) == 0 {
+		_, err := fmt.Fprintln(w, "No objects from inactive member clusters found.")
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tNAMESPACE\tNAME\tCLUSTER")
+	for _, o := range s.Objects {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", o.Kind, o.Namespace, o.Name, o.Cluster)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "%s %d object(s) from inactive member clusters.\n", verb, len(s.Objects))
+	return err
+}
@@ -0,0 +1,225 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package prune
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+const (
+	activeCluster = "member-1"
+	staleCluster  = "member-2"
+)
+
+func hubObjects() []client.Object {
+	return []client.Object{
+		&fleetnetv1alpha1.InternalServiceExport{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "member-1-ns", Name: "work-app"},
+			Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+				ServiceReference: fleetnetv1alpha1.ExportedObjectReference{ClusterID: activeCluster},
+			},
+		},
+		&fleetnetv1alpha1.InternalServiceExport{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "member-2-ns", Name: "work-app"},
+			Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+				ServiceReference: fleetnetv1alpha1.ExportedObjectReference{ClusterID: staleCluster},
+			},
+		},
+		&fleetnetv1alpha1.InternalServiceImport{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "member-1-ns", Name: "work-app"},
+			Spec: fleetnetv1alpha1.InternalServiceImportSpec{
+				ServiceImportReference: fleetnetv1alpha1.ExportedObjectReference{ClusterID: activeCluster},
+			},
+		},
+		&fleetnetv1alpha1.InternalServiceImport{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "member-2-ns", Name: "work-app"},
+			Spec: fleetnetv1alpha1.InternalServiceImportSpec{
+				ServiceImportReference: fleetnetv1alpha1.ExportedObjectReference{ClusterID: staleCluster},
+			},
+		},
+		&fleetnetv1alpha1.EndpointSliceExport{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "member-1-ns", Name: "work-app-abcde"},
+			Spec: fleetnetv1alpha1.EndpointSliceExportSpec{
+				EndpointSliceReference: fleetnetv1alpha1.ExportedObjectReference{ClusterID: activeCluster},
+			},
+		},
+		&fleetnetv1alpha1.EndpointSliceExport{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "member-2-ns", Name: "work-app-fghij"},
+			Spec: fleetnetv1alpha1.EndpointSliceExportSpec{
+				EndpointSliceReference: fleetnetv1alpha1.ExportedObjectReference{ClusterID: staleCluster},
+			},
+		},
+	}
+}
+
+func hubClient(t *testing.T) client.Client {
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(hubObjects()...).Build()
+}
+
+// countObjects returns the number of objects of each kind left in the hub cluster.
+func countObjects(ctx context.Context, t *testing.T, c client.Client) map[string]int {
+	internalSvcExportList := &fleetnetv1alpha1.InternalServiceExportList{}
+	internalSvcImportList := &fleetnetv1alpha1.InternalServiceImportList{}
+	endpointSliceExportList := &fleetnetv1alpha1.EndpointSliceExportList{}
+	for _, list := range []client.ObjectList{internalSvcExportList, internalSvcImportList, endpointSliceExportList} {
+		if err := c.List(ctx, list); err != nil {
+			t.Fatalf("List() got error %v, want no error", err)
+		}
+	}
+	return map[string]int{
+		"InternalServiceExport": len(internalSvcExportList.Items),
+		"InternalServiceImport": len(internalSvcImportList.Items),
+		"EndpointSliceExport":   len(endpointSliceExportList.Items),
+	}
+}
+
+// TestRun tests the Run function over a hub cluster with objects from both active and inactive member clusters.
+func TestRun(t *testing.T) {
+	wantObjects := []Object{
+		{Kind: "EndpointSliceExport", Namespace: "member-2-ns", Name: "work-app-fghij", Cluster: staleCluster},
+		{Kind: "InternalServiceExport", Namespace: "member-2-ns", Name: "work-app", Cluster: staleCluster},
+		{Kind: "InternalServiceImport", Namespace: "member-2-ns", Name: "work-app", Cluster: staleCluster},
+	}
+
+	testCases := []struct {
+		name       string
+		dryRun     bool
+		wantCounts map[string]int
+	}{
+		{
+			name:   "dry run only reports the stale objects",
+			dryRun: true,
+			wantCounts: map[string]int{
+				"InternalServiceExport": 2,
+				"InternalServiceImport": 2,
+				"EndpointSliceExport":   2,
+			},
+		},
+		{
+			name: "stale objects are deleted",
+			wantCounts: map[string]int{
+				"InternalServiceExport": 1,
+				"InternalServiceImport": 1,
+				"EndpointSliceExport":   1,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := hubClient(t)
+			summary, err := Run(ctx, c, []string{activeCluster}, tc.dryRun)
+			if err != nil {
+				t.Fatalf("Run() got error %v, want no error", err)
+			}
+			want := &Summary{DryRun: tc.dryRun, Objects: wantObjects}
+			if diff := cmp.Diff(want, summary); diff != "" {
+				t.Errorf("Run() summary mismatch (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantCounts, countObjects(ctx, t, c)); diff != "" {
+				t.Errorf("objects left in the hub mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestRun_NoActiveClusters tests that Run refuses to prune when no active member cluster is specified.
+func TestRun_NoActiveClusters(t *testing.T) {
+	ctx := context.Background()
+	c := hubClient(t)
+	if _, err := Run(ctx, c, nil, false); err == nil {
+		t.Fatalf("Run() = nil, want error")
+	}
+	want := map[string]int{
+		"InternalServiceExport": 2,
+		"InternalServiceImport": 2,
+		"EndpointSliceExport":   2,
+	}
+	if diff := cmp.Diff(want, countObjects(ctx, t, c)); diff != "" {
+		t.Errorf("objects left in the hub mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+// TestRunAll tests that RunAll deletes the objects of every member cluster.
+func TestRunAll(t *testing.T) {
+	ctx := context.Background()
+	c := hubClient(t)
+	summary, err := RunAll(ctx, c, false)
+	if err != nil {
+		t.Fatalf("RunAll() got error %v, want no error", err)
+	}
+	if got, want := len(summary.Objects), 6; got != want {
+		t.Errorf("RunAll() pruned %d objects, want %d", got, want)
+	}
+	want := map[string]int{
+		"InternalServiceExport": 0,
+		"InternalServiceImport": 0,
+		"EndpointSliceExport":   0,
+	}
+	if diff := cmp.Diff(want, countObjects(ctx, t, c)); diff != "" {
+		t.Errorf("objects left in the hub mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+// TestSummary_Print tests the Print function.
+func TestSummary_Print(t *testing.T) {
+	testCases := []struct {
+		name    string
+		summary *Summary
+		want    []string
+	}{
+		{
+			name: "dry run",
+			summary: &Summary{
+				DryRun:  true,
+				Objects: []Object{{Kind: "InternalServiceExport", Namespace: "member-2-ns", Name: "work-app", Cluster: staleCluster}},
+			},
+			want: []string{"InternalServiceExport", "member-2-ns", "work-app", staleCluster, "Would delete 1 object(s)"},
+		},
+		{
+			name: "deleted",
+			summary: &Summary{
+				Objects: []Object{{Kind: "InternalServiceExport", Namespace: "member-2-ns", Name: "work-app", Cluster: staleCluster}},
+			},
+			want: []string{"Deleted 1 object(s)"},
+		},
+		{
+			name:    "nothing to prune",
+			summary: &Summary{DryRun: true},
+			want:    []string{"No objects from inactive member clusters found."},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tc.summary.Print(&buf); err != nil {
+				t.Fatalf("Print() got error %v, want no error", err)
+			}
+			for _, s := range tc.want {
+				if !strings.Contains(buf.String(), s) {
+					t.Errorf("Print() = %q, want it to contain %q", buf.String(), s)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package quiesce
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"k8s.io/klog/v2"
+)
+
+// ErrAzureWriteSkipped is returned for the writes to Azure while the quiesce mode is on; the controllers retry them
+// with backoff as with any other failed request, and their requests are requeued once the quiesce mode is cleared.
+var ErrAzureWriteSkipped = errors.New("the write to Azure is skipped in quiesce mode")
+
+// NewAzurePolicy returns the policy of the Azure resource clients which skips all the requests but the reads while
+// the quiesce mode is on; the intended writes are logged instead.
+func NewAzurePolicy(s *Switch) policy.Policy {
+	return azurePolicy{sw: s}
+}
+
+type azurePolicy struct {
+	sw *Switch
+}
+
+// Do implements policy.Policy.
+func (p azurePolicy) Do(req *policy.Request) (*http.Response, error) {
+	raw := req.Raw()
+	if !p.sw.Quiesced() || raw.Method == http.MethodGet || raw.Method == http.MethodHead {
+		return req.Next()
+	}
+	klog.V(2).InfoS("Skipping the write to Azure in quiesce mode", "verb", raw.Method, "path", raw.URL.Path)
+	return nil, ErrAzureWriteSkipped
+}
@@ -0,0 +1,102 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package quiesce
+
+import (
+	"sync"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// NewQueue returns the constructor of the work queues of the controllers, see controller.Options.NewQueue, which
+// builds the queues with the given constructor, or the default one if it is nil, and requeues the requests
+// reconciled while quiesced once the quiesce mode is cleared.
+func (s *Switch) NewQueue(newQueue func(string, workqueue.TypedRateLimiter[reconcile.Request]) workqueue.TypedRateLimitingInterface[reconcile.Request],
+) func(string, workqueue.TypedRateLimiter[reconcile.Request]) workqueue.TypedRateLimitingInterface[reconcile.Request] {
+	return func(controllerName string, rateLimiter workqueue.TypedRateLimiter[reconcile.Request]) workqueue.TypedRateLimitingInterface[reconcile.Request] {
+		var q workqueue.TypedRateLimitingInterface[reconcile.Request]
+		if newQueue != nil {
+			q = newQueue(controllerName, rateLimiter)
+		} else {
+			q = workqueue.NewTypedRateLimitingQueueWithConfig(rateLimiter, workqueue.TypedRateLimitingQueueConfig[reconcile.Request]{
+				Name: controllerName,
+			})
+		}
+		rq := &requeuingQueue{
+			TypedRateLimitingInterface: q,
+			sw:                         s,
+			processing:                 map[reconcile.Request]processingState{},
+			quiesced:                   map[reconcile.Request]struct{}{},
+		}
+		s.OnClear(rq.requeueQuiesced)
+		return rq
+	}
+}
+
+// processingState is the state of the quiesce mode when the processing of a request started.
+type processingState struct {
+	quiesced bool
+	changes  uint64
+}
+
+// requeuingQueue is a work queue which keeps the requests whose reconciliation ran while quiesced, as their writes
+// may have been skipped, and adds them again once the quiesce mode is cleared.
+type requeuingQueue struct {
+	workqueue.TypedRateLimitingInterface[reconcile.Request]
+	sw *Switch
+
+	mu sync.Mutex
+	// processing are the requests being reconciled.
+	processing map[reconcile.Request]processingState
+	// quiesced are the requests reconciled while quiesced, which are requeued once the quiesce mode is cleared.
+	quiesced map[reconcile.Request]struct{}
+}
+
+var _ workqueue.TypedRateLimitingInterface[reconcile.Request] = &requeuingQueue{}
+
+// Get implements workqueue.TypedInterface.
+func (q *requeuingQueue) Get() (reconcile.Request, bool) {
+	item, shutdown := q.TypedRateLimitingInterface.Get()
+	if shutdown {
+		return item, shutdown
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.processing[item] = processingState{quiesced: q.sw.Quiesced(), changes: q.sw.changes.Load()}
+	return item, shutdown
+}
+
+// Done implements workqueue.TypedInterface.
+func (q *requeuingQueue) Done(item reconcile.Request) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	state, ok := q.processing[item]
+	delete(q.processing, item)
+	q.TypedRateLimitingInterface.Done(item)
+	// The reconciliation may have skipped writes if the quiesce mode was on at any time while it ran.
+	quiesced := q.sw.Quiesced()
+	if !ok || (!state.quiesced && !quiesced && state.changes == q.sw.changes.Load()) {
+		return
+	}
+	if quiesced {
+		q.quiesced[item] = struct{}{}
+		return
+	}
+	// The quiesce mode has been cleared while the request was reconciled, possibly after the requests reconciled
+	// while quiesced were requeued.
+	q.TypedRateLimitingInterface.Add(item)
+}
+
+// requeueQuiesced adds the requests reconciled while quiesced to the queue again.
+func (q *requeuingQueue) requeueQuiesced() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for item := range q.quiesced {
+		q.TypedRateLimitingInterface.Add(item)
+	}
+	clear(q.quiesced)
+}
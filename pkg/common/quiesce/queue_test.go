@@ -0,0 +1,128 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package quiesce
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// reconcileNext takes the next request from the queue and marks it done, running the given function in between as the
+// reconciliation.
+func reconcileNext(t *testing.T, q workqueue.TypedRateLimitingInterface[reconcile.Request], during func()) reconcile.Request {
+	t.Helper()
+	item, shutdown := q.Get()
+	if shutdown {
+		t.Fatalf("Get() = shutdown, want a request")
+	}
+	if during != nil {
+		during()
+	}
+	q.Done(item)
+	return item
+}
+
+// TestQueue tests that the requests reconciled while quiesced are requeued once the quiesce mode is cleared, and
+// only then.
+func TestQueue(t *testing.T) {
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: testName}}
+	testCases := []struct {
+		name string
+		// quiescedAtStart is the mode when the request is taken from the queue.
+		quiescedAtStart bool
+		// during changes the mode while the request is reconciled.
+		during func(sw *Switch)
+		// clear clears the quiesce mode after the reconciliation, if it is still on.
+		clear       bool
+		wantLenLeft int
+	}{
+		{
+			name:        "not quiesced",
+			wantLenLeft: 0,
+		},
+		{
+			name:            "reconciled while quiesced",
+			quiescedAtStart: true,
+			clear:           true,
+			wantLenLeft:     1,
+		},
+		{
+			name:            "reconciled while quiesced, still quiesced",
+			quiescedAtStart: true,
+			wantLenLeft:     0,
+		},
+		{
+			name:        "quiesced while reconciled",
+			during:      func(sw *Switch) { sw.Set(true) },
+			clear:       true,
+			wantLenLeft: 1,
+		},
+		{
+			name:            "cleared while reconciled",
+			quiescedAtStart: true,
+			during:          func(sw *Switch) { sw.Set(false) },
+			wantLenLeft:     1,
+		},
+		{
+			name:        "quiesced and cleared while reconciled",
+			during:      func(sw *Switch) { sw.Set(true); sw.Set(false) },
+			wantLenLeft: 1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sw := NewSwitch(tc.quiescedAtStart)
+			q := sw.NewQueue(nil)("test", workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+			defer q.ShutDown()
+
+			q.Add(req)
+			reconcileNext(t, q, func() {
+				if tc.during != nil {
+					tc.during(sw)
+				}
+			})
+			if tc.clear {
+				if got := q.Len(); got != 0 {
+					t.Fatalf("Len() while quiesced = %d, want 0", got)
+				}
+				sw.Set(false)
+			}
+			if got := q.Len(); got != tc.wantLenLeft {
+				t.Fatalf("Len() = %d, want %d", got, tc.wantLenLeft)
+			}
+			if tc.wantLenLeft == 0 {
+				return
+			}
+
+			// The requeued request is reconciled again, and is not requeued any more.
+			if got := reconcileNext(t, q, nil); got != req {
+				t.Errorf("Get() = %v, want %v", got, req)
+			}
+			sw.Set(true)
+			sw.Set(false)
+			if got := q.Len(); got != 0 {
+				t.Errorf("Len() after the requeued request is reconciled = %d, want 0", got)
+			}
+		})
+	}
+}
+
+// TestQueue_Constructor tests that the queue is built with the given constructor.
+func TestQueue_Constructor(t *testing.T) {
+	var gotName string
+	newQueue := func(name string, rateLimiter workqueue.TypedRateLimiter[reconcile.Request]) workqueue.TypedRateLimitingInterface[reconcile.Request] {
+		gotName = name
+		return workqueue.NewTypedRateLimitingQueue(rateLimiter)
+	}
+	q := NewSwitch(false).NewQueue(newQueue)("test", workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+	defer q.ShutDown()
+	if gotName != "test" {
+		t.Errorf("constructor called with name %q, want %q", gotName, "test")
+	}
+}
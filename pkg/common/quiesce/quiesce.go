@@ -0,0 +1,191 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package quiesce features the quiesce mode of the networking controllers, in which the controllers keep watching
+// resources and computing the desired state, but skip all the writes, i.e. to the API servers, to Azure and the
+// Events; it is used during maintenance windows so that the controllers can converge fast once the maintenance is
+// over.
+//
+// The writes to the API servers are skipped as if they succeeded, so the reconcilers do not retry them; instead, the
+// work queues built by the Switch requeue the requests reconciled while quiesced once the quiesce mode is cleared,
+// so that the skipped writes are made then.
+package quiesce
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Switch turns the quiesce mode on and off at runtime; it is safe for concurrent use.
+type Switch struct {
+	quiesced atomic.Bool
+	// changes counts the changes of the mode, so that a reconciliation can tell if the mode has changed while it
+	// ran.
+	changes atomic.Uint64
+
+	mu sync.Mutex
+	// onClear are called when the quiesce mode is cleared.
+	onClear []func()
+}
+
+// NewSwitch returns a Switch with the given initial state.
+func NewSwitch(quiesced bool) *Switch {
+	s := &Switch{}
+	s.quiesced.Store(quiesced)
+	return s
+}
+
+// Quiesced returns if the quiesce mode is on.
+func (s *Switch) Quiesced() bool {
+	return s.quiesced.Load()
+}
+
+// Set turns the quiesce mode on or off; the requests reconciled while quiesced are requeued when it is cleared.
+func (s *Switch) Set(quiesced bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.quiesced.Swap(quiesced) == quiesced {
+		return
+	}
+	s.changes.Add(1)
+	klog.InfoS("Quiesce mode changed", "quiesced", quiesced)
+	if !quiesced {
+		for _, f := range s.onClear {
+			f()
+		}
+	}
+}
+
+// OnClear registers a function to call whenever the quiesce mode is cleared.
+func (s *Switch) OnClear(f func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onClear = append(s.onClear, f)
+}
+
+// ToggleOnSIGHUP flips the quiesce mode every time the process receives a SIGHUP, until the context is done.
+func (s *Switch) ToggleOnSIGHUP(ctx context.Context) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(sigCh)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sigCh:
+				s.Set(!s.Quiesced())
+			}
+		}
+	}()
+}
+
+// NewClient wraps a client so that its writes, including the writes to subresources, are skipped while the
+// quiesce mode is on; the intended writes are logged instead, and reported as successful. Reads are always passed
+// through.
+//
+// The controllers writing through the client are to build their work queues with Switch.NewQueue, so that the
+// skipped writes are made once the quiesce mode is cleared.
+func NewClient(c client.Client, s *Switch) client.Client {
+	return &quiescedClient{Client: c, sw: s}
+}
+
+type quiescedClient struct {
+	client.Client
+	sw *Switch
+}
+
+func (c *quiescedClient) skip(action string, obj client.Object) bool {
+	if !c.sw.Quiesced() {
+		return false
+	}
+	klog.V(2).InfoS("Skipping the write in quiesce mode", "action", action, "object", klog.KObj(obj), "type", objectType(obj))
+	return true
+}
+
+func objectType(obj client.Object) string {
+	if gvk := obj.GetObjectKind().GroupVersionKind(); !gvk.Empty() {
+		return gvk.String()
+	}
+	return fmt.Sprintf("%T", obj)
+}
+
+func (c *quiescedClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if c.skip("create", obj) {
+		return nil
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *quiescedClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if c.skip("update", obj) {
+		return nil
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *quiescedClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if c.skip("patch", obj) {
+		return nil
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (c *quiescedClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	if c.skip("delete", obj) {
+		return nil
+	}
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func (c *quiescedClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	if c.skip("deleteAllOf", obj) {
+		return nil
+	}
+	return c.Client.DeleteAllOf(ctx, obj, opts...)
+}
+
+func (c *quiescedClient) Status() client.SubResourceWriter {
+	return c.SubResource("status")
+}
+
+func (c *quiescedClient) SubResource(subResource string) client.SubResourceClient {
+	return &quiescedSubResourceClient{SubResourceClient: c.Client.SubResource(subResource), parent: c, subResource: subResource}
+}
+
+type quiescedSubResourceClient struct {
+	client.SubResourceClient
+	parent      *quiescedClient
+	subResource string
+}
+
+func (c *quiescedSubResourceClient) Create(ctx context.Context, obj client.Object, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+	if c.parent.skip("create "+c.subResource, obj) {
+		return nil
+	}
+	return c.SubResourceClient.Create(ctx, obj, subResource, opts...)
+}
+
+func (c *quiescedSubResourceClient) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	if c.parent.skip("update "+c.subResource, obj) {
+		return nil
+	}
+	return c.SubResourceClient.Update(ctx, obj, opts...)
+}
+
+func (c *quiescedSubResourceClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	if c.parent.skip("patch "+c.subResource, obj) {
+		return nil
+	}
+	return c.SubResourceClient.Patch(ctx, obj, patch, opts...)
+}
@@ -0,0 +1,131 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package quiesce
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	testNamespace = "work"
+	testName      = "app"
+)
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	key := types.NamespacedName{Namespace: testNamespace, Name: testName}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithStatusSubresource(&corev1.Service{}).
+		Build()
+	sw := NewSwitch(true)
+	c := NewClient(fakeClient, sw)
+
+	// Writes are suppressed while quiesced.
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+	}
+	if err := c.Create(ctx, svc); err != nil {
+		t.Fatalf("Create() in quiesce mode = %v, want no error", err)
+	}
+	if err := fakeClient.Get(ctx, key, &corev1.Service{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("Get() after a quiesced Create() = %v, want not found error", err)
+	}
+
+	// Writes resume once the quiesce mode is cleared.
+	sw.Set(false)
+	if err := c.Create(ctx, svc); err != nil {
+		t.Fatalf("Create() = %v, want no error", err)
+	}
+	if err := c.Get(ctx, key, &corev1.Service{}); err != nil {
+		t.Fatalf("Get() after Create() = %v, want no error", err)
+	}
+
+	// Status writes are suppressed while quiesced too, but reads still go through.
+	sw.Set(true)
+	got := &corev1.Service{}
+	if err := c.Get(ctx, key, got); err != nil {
+		t.Fatalf("Get() in quiesce mode = %v, want no error", err)
+	}
+	got.Status.Conditions = []metav1.Condition{{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Ready"}}
+	if err := c.Status().Update(ctx, got); err != nil {
+		t.Fatalf("Status().Update() in quiesce mode = %v, want no error", err)
+	}
+	if err := c.Delete(ctx, svc); err != nil {
+		t.Fatalf("Delete() in quiesce mode = %v, want no error", err)
+	}
+	stored := &corev1.Service{}
+	if err := fakeClient.Get(ctx, key, stored); err != nil {
+		t.Fatalf("Get() after a quiesced Delete() = %v, want no error", err)
+	}
+	if len(stored.Status.Conditions) != 0 {
+		t.Errorf("service status conditions = %v, want no conditions after a quiesced status update", stored.Status.Conditions)
+	}
+
+	// Status writes resume once the quiesce mode is cleared.
+	sw.Set(false)
+	stored.Status.Conditions = got.Status.Conditions
+	if err := c.Status().Update(ctx, stored); err != nil {
+		t.Fatalf("Status().Update() = %v, want no error", err)
+	}
+	if err := fakeClient.Get(ctx, key, stored); err != nil {
+		t.Fatalf("Get() = %v, want no error", err)
+	}
+	if len(stored.Status.Conditions) != 1 {
+		t.Errorf("service status conditions = %v, want 1 condition", stored.Status.Conditions)
+	}
+	if err := c.Delete(ctx, svc); err != nil {
+		t.Fatalf("Delete() = %v, want no error", err)
+	}
+	if err := fakeClient.Get(ctx, key, stored); !apierrors.IsNotFound(err) {
+		t.Errorf("Get() after Delete() = %v, want not found error", err)
+	}
+}
+
+func TestSwitch(t *testing.T) {
+	sw := NewSwitch(false)
+	if sw.Quiesced() {
+		t.Fatalf("Quiesced() = true, want false")
+	}
+	sw.Set(true)
+	if !sw.Quiesced() {
+		t.Fatalf("Quiesced() after Set(true) = false, want true")
+	}
+	sw.Set(false)
+	if sw.Quiesced() {
+		t.Fatalf("Quiesced() after Set(false) = true, want false")
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	fakeRecorder := record.NewFakeRecorder(10)
+	sw := NewSwitch(true)
+	recorder := NewRecorder(fakeRecorder, sw)
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName}}
+
+	recorder.Eventf(svc, corev1.EventTypeNormal, "Quiesced", "event %d", 1)
+	if got := len(fakeRecorder.Events); got != 0 {
+		t.Fatalf("number of events recorded in quiesce mode = %d, want 0", got)
+	}
+
+	sw.Set(false)
+	recorder.Eventf(svc, corev1.EventTypeNormal, "Resumed", "event %d", 2)
+	if got := len(fakeRecorder.Events); got != 1 {
+		t.Fatalf("number of events recorded = %d, want 1", got)
+	}
+	if got, want := <-fakeRecorder.Events, "Normal Resumed event 2"; got != want {
+		t.Errorf("event = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,48 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package quiesce
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+)
+
+// NewRecorder wraps an event recorder so that its Events are dropped while the quiesce mode is on.
+func NewRecorder(recorder record.EventRecorder, s *Switch) record.EventRecorder {
+	return &quiescedRecorder{recorder: recorder, sw: s}
+}
+
+type quiescedRecorder struct {
+	recorder record.EventRecorder
+	sw       *Switch
+}
+
+func (r *quiescedRecorder) skip(eventType, reason string) bool {
+	if !r.sw.Quiesced() {
+		return false
+	}
+	klog.V(4).InfoS("Skipping the event in quiesce mode", "type", eventType, "reason", reason)
+	return true
+}
+
+func (r *quiescedRecorder) Event(object runtime.Object, eventType, reason, message string) {
+	if !r.skip(eventType, reason) {
+		r.recorder.Event(object, eventType, reason, message)
+	}
+}
+
+func (r *quiescedRecorder) Eventf(object runtime.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	if !r.skip(eventType, reason) {
+		r.recorder.Eventf(object, eventType, reason, messageFmt, args...)
+	}
+}
+
+func (r *quiescedRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventType, reason, messageFmt string, args ...interface{}) {
+	if !r.skip(eventType, reason) {
+		r.recorder.AnnotatedEventf(object, annotations, eventType, reason, messageFmt, args...)
+	}
+}
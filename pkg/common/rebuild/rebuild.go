@@ -0,0 +1,134 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package rebuild features the rebuild of the state of a hub cluster from its member clusters, after the hub
+// cluster has lost its data or the fleet has moved to a new hub cluster.
+//
+// A rebuild opens a window, recorded in a ConfigMap of the hub cluster, during which the member clusters re-publish
+// all of their exports and the hub cluster holds back the resolution of the ServiceImports; once the window closes,
+// every ServiceImport is resolved from the complete set of its exports, so that the oldest export wins no matter in
+// which order the member clusters have re-published them.
+package rebuild
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+// DeadlineKey is the key in the data of the rebuild ConfigMap that holds the time, in RFC 3339, the rebuild window
+// closes at.
+const DeadlineKey = "deadline"
+
+// Deadline reads the time the rebuild window closes at from the rebuild ConfigMap; the zero time is returned if
+// no rebuild has been started, or if the key is empty, which disables the rebuild.
+func Deadline(ctx context.Context, reader client.Reader, key types.NamespacedName) (time.Time, error) {
+	if key.Name == "" {
+		return time.Time{}, nil
+	}
+	configMap := &corev1.ConfigMap{}
+	if err := reader.Get(ctx, key, configMap); err != nil {
+		if client.IgnoreNotFound(err) == nil {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+	data, ok := configMap.Data[DeadlineKey]
+	if !ok {
+		return time.Time{}, nil
+	}
+	deadline, err := time.Parse(time.RFC3339, data)
+	if err != nil {
+		// A malformed deadline must not hold back the ServiceImports forever.
+		klog.ErrorS(err, "Ignored the invalid deadline of the rebuild", "configMap", key)
+		return time.Time{}, nil
+	}
+	return deadline, nil
+}
+
+// Remaining returns how long the rebuild window stays open, or zero if it is closed.
+func Remaining(ctx context.Context, reader client.Reader, key types.NamespacedName, now time.Time) (time.Duration, error) {
+	deadline, err := Deadline(ctx, reader, key)
+	if err != nil {
+		return 0, err
+	}
+	if remaining := deadline.Sub(now); remaining > 0 {
+		return remaining, nil
+	}
+	return 0, nil
+}
+
+// IsRebuildConfigMap returns true if the object is the rebuild ConfigMap with the given key; it can be used to
+// filter the ConfigMap events watched by controllers.
+func IsRebuildConfigMap(obj client.Object, key types.NamespacedName) bool {
+	return key.Name != "" && obj.GetNamespace() == key.Namespace && obj.GetName() == key.Name
+}
+
+// Start opens a rebuild window of the given length, and asks the networking agents of all the member clusters
+// which have reported their status to the hub cluster to re-publish their exports; it returns the IDs of those
+// member clusters, sorted.
+//
+// The member clusters whose status has been lost with the rest of the hub state re-publish their exports on their
+// own as soon as they find their status gone.
+func Start(ctx context.Context, hubClient client.Client, key types.NamespacedName, window time.Duration, now time.Time) ([]string, error) {
+	if key.Name == "" {
+		return nil, fmt.Errorf("the rebuild ConfigMap is not specified")
+	}
+	if window <= 0 {
+		return nil, fmt.Errorf("the rebuild window must be positive, got %v", window)
+	}
+
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}}
+	if _, err := controllerutil.CreateOrUpdate(ctx, hubClient, configMap, func() error {
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		configMap.Data[DeadlineKey] = now.Add(window).UTC().Format(time.RFC3339)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to open the rebuild window in configMap %s: %w", key, err)
+	}
+
+	statusList := &fleetnetv1alpha1.InternalMemberNetworkStatusList{}
+	if err := hubClient.List(ctx, statusList); err != nil {
+		return nil, fmt.Errorf("failed to list internalMemberNetworkStatuses: %w", err)
+	}
+	token := now.UTC().Format(time.RFC3339)
+	clusters := make([]string, 0, len(statusList.Items))
+	for i := range statusList.Items {
+		status := &statusList.Items[i]
+		if status.Annotations == nil {
+			status.Annotations = map[string]string{}
+		}
+		status.Annotations[objectmeta.NetworkStatusAnnotationResyncRequested] = token
+		if err := hubClient.Update(ctx, status); err != nil {
+			return nil, fmt.Errorf("failed to request the resync of internalMemberNetworkStatus %s: %w", klog.KObj(status), err)
+		}
+		clusters = append(clusters, status.Name)
+	}
+	sort.Strings(clusters)
+	return clusters, nil
+}
+
+// ResyncRequest returns the token of the resync requested in the InternalMemberNetworkStatus, and whether it has
+// yet to be handled by the networking agent.
+func ResyncRequest(status *fleetnetv1alpha1.InternalMemberNetworkStatus) (string, bool) {
+	requested, ok := status.Annotations[objectmeta.NetworkStatusAnnotationResyncRequested]
+	if !ok {
+		return "", false
+	}
+	return requested, status.Annotations[objectmeta.NetworkStatusAnnotationResynced] != requested
+}
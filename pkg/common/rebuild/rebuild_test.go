@@ -0,0 +1,176 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package rebuild
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+var configMapKey = types.NamespacedName{Namespace: "fleet-system", Name: "fleet-networking-rebuild"}
+
+func testScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	return scheme
+}
+
+// TestRemaining tests how long the rebuild window stays open per the rebuild ConfigMap.
+func TestRemaining(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	configMap := func(deadline string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: configMapKey.Namespace, Name: configMapKey.Name},
+			Data:       map[string]string{DeadlineKey: deadline},
+		}
+	}
+	testCases := []struct {
+		name      string
+		key       types.NamespacedName
+		configMap *corev1.ConfigMap
+		want      time.Duration
+	}{
+		{
+			name:      "rebuild disabled",
+			configMap: configMap("2024-01-01T00:10:00Z"),
+		},
+		{
+			name: "no rebuild started",
+			key:  configMapKey,
+		},
+		{
+			name:      "window open",
+			key:       configMapKey,
+			configMap: configMap("2024-01-01T00:10:00Z"),
+			want:      10 * time.Minute,
+		},
+		{
+			name:      "window closed",
+			key:       configMapKey,
+			configMap: configMap("2023-12-31T23:50:00Z"),
+		},
+		{
+			name:      "invalid deadline",
+			key:       configMapKey,
+			configMap: configMap("in ten minutes"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithScheme(testScheme(t))
+			if tc.configMap != nil {
+				builder = builder.WithObjects(tc.configMap)
+			}
+			got, err := Remaining(context.Background(), builder.Build(), tc.key, now)
+			if err != nil {
+				t.Fatalf("Remaining() = %v, want no error", err)
+			}
+			if got != tc.want {
+				t.Errorf("Remaining() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestStart tests that a rebuild opens the window and requests the resync of every member cluster.
+func TestStart(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fakeClient := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(
+		&fleetnetv1alpha1.InternalMemberNetworkStatus{ObjectMeta: metav1.ObjectMeta{Namespace: "fleet-member-member-2", Name: "member-2"}},
+		&fleetnetv1alpha1.InternalMemberNetworkStatus{ObjectMeta: metav1.ObjectMeta{Namespace: "fleet-member-member-1", Name: "member-1"}},
+	).Build()
+
+	clusters, err := Start(ctx, fakeClient, configMapKey, 10*time.Minute, now)
+	if err != nil {
+		t.Fatalf("Start() = %v, want no error", err)
+	}
+	if diff := cmp.Diff([]string{"member-1", "member-2"}, clusters); diff != "" {
+		t.Errorf("Start() clusters mismatch (-want, +got):\n%s", diff)
+	}
+
+	remaining, err := Remaining(ctx, fakeClient, configMapKey, now)
+	if err != nil {
+		t.Fatalf("Remaining() = %v, want no error", err)
+	}
+	if remaining != 10*time.Minute {
+		t.Errorf("Remaining() = %v, want %v", remaining, 10*time.Minute)
+	}
+	for _, key := range []client.ObjectKey{
+		{Namespace: "fleet-member-member-1", Name: "member-1"},
+		{Namespace: "fleet-member-member-2", Name: "member-2"},
+	} {
+		status := &fleetnetv1alpha1.InternalMemberNetworkStatus{}
+		if err := fakeClient.Get(ctx, key, status); err != nil {
+			t.Fatalf("InternalMemberNetworkStatus Get() = %v, want no error", err)
+		}
+		token, pending := ResyncRequest(status)
+		if token != "2024-01-01T00:00:00Z" || !pending {
+			t.Errorf("ResyncRequest(%s) = (%q, %v), want (%q, true)", key, token, pending, "2024-01-01T00:00:00Z")
+		}
+	}
+
+	if _, err := Start(ctx, fakeClient, configMapKey, 0, now); err == nil {
+		t.Errorf("Start() with no window = nil, want error")
+	}
+}
+
+// TestResyncRequest tests whether the resync requested in an InternalMemberNetworkStatus has yet to be handled.
+func TestResyncRequest(t *testing.T) {
+	testCases := []struct {
+		name        string
+		annotations map[string]string
+		wantToken   string
+		wantPending bool
+	}{
+		{
+			name: "no request",
+		},
+		{
+			name: "request pending",
+			annotations: map[string]string{
+				objectmeta.NetworkStatusAnnotationResyncRequested: "b",
+				objectmeta.NetworkStatusAnnotationResynced:        "a",
+			},
+			wantToken:   "b",
+			wantPending: true,
+		},
+		{
+			name: "request handled",
+			annotations: map[string]string{
+				objectmeta.NetworkStatusAnnotationResyncRequested: "b",
+				objectmeta.NetworkStatusAnnotationResynced:        "b",
+			},
+			wantToken: "b",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			status := &fleetnetv1alpha1.InternalMemberNetworkStatus{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+			token, pending := ResyncRequest(status)
+			if token != tc.wantToken || pending != tc.wantPending {
+				t.Errorf("ResyncRequest() = (%q, %v), want (%q, %v)", token, pending, tc.wantToken, tc.wantPending)
+			}
+		})
+	}
+}
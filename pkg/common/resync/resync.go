@@ -0,0 +1,67 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package resync features the on-demand resync of the exports of a member cluster, which has the controllers
+// exporting the services re-publish all of them to the hub cluster at once, e.g. after the hub cluster has lost its
+// state, rather than at their next periodic resync.
+package resync
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+// Resyncer enqueues all the ServiceExports of the member cluster to the controllers subscribed to it.
+type Resyncer struct {
+	// MemberClient lists the ServiceExports of the member cluster.
+	MemberClient client.Reader
+
+	mu          sync.Mutex
+	subscribers []chan event.GenericEvent
+}
+
+// Subscribe returns the channel the ServiceExports are sent to on each resync; a controller watches it through a
+// channel source, and maps the ServiceExports to the objects it reconciles.
+//
+// The controllers subscribe as they are set up; the channel is to be watched as soon as the controller starts, or
+// the resync blocks.
+func (r *Resyncer) Subscribe() <-chan event.GenericEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ch := make(chan event.GenericEvent)
+	r.subscribers = append(r.subscribers, ch)
+	return ch
+}
+
+// Resync sends all the ServiceExports of the member cluster to every subscribed controller.
+func (r *Resyncer) Resync(ctx context.Context) error {
+	svcExportList := &fleetnetv1alpha1.ServiceExportList{}
+	if err := r.MemberClient.List(ctx, svcExportList); err != nil {
+		return fmt.Errorf("failed to list serviceExports: %w", err)
+	}
+
+	r.mu.Lock()
+	subscribers := r.subscribers
+	r.mu.Unlock()
+	klog.V(2).InfoS("Resyncing the exports", "serviceExports", len(svcExportList.Items), "controllers", len(subscribers))
+	for i := range svcExportList.Items {
+		e := event.GenericEvent{Object: &svcExportList.Items[i]}
+		for _, ch := range subscribers {
+			select {
+			case ch <- e:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+	return nil
+}
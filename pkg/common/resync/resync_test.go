@@ -0,0 +1,74 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package resync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+// TestResync tests that every subscriber receives all the ServiceExports.
+func TestResync(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&fleetnetv1alpha1.ServiceExport{ObjectMeta: metav1.ObjectMeta{Namespace: "work", Name: "app"}},
+		&fleetnetv1alpha1.ServiceExport{ObjectMeta: metav1.ObjectMeta{Namespace: "work", Name: "db"}},
+	).Build()
+	r := &Resyncer{MemberClient: fakeClient}
+	subscribers := []<-chan event.GenericEvent{r.Subscribe(), r.Subscribe()}
+
+	got := make([][]string, len(subscribers))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 2 {
+			for i, ch := range subscribers {
+				e := <-ch
+				got[i] = append(got[i], client.ObjectKeyFromObject(e.Object).String())
+			}
+		}
+	}()
+	if err := r.Resync(context.Background()); err != nil {
+		t.Fatalf("Resync() = %v, want no error", err)
+	}
+	<-done
+
+	want := [][]string{{"work/app", "work/db"}, {"work/app", "work/db"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("resynced serviceExports mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+// TestResync_Canceled tests that the resync stops when the context is canceled.
+func TestResync_Canceled(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&fleetnetv1alpha1.ServiceExport{ObjectMeta: metav1.ObjectMeta{Namespace: "work", Name: "app"}},
+	).Build()
+	r := &Resyncer{MemberClient: fakeClient}
+	_ = r.Subscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := r.Resync(ctx); err == nil {
+		t.Errorf("Resync() = nil, want context canceled")
+	}
+}
@@ -0,0 +1,257 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package sharding features the sharding of the work of the controllers across the replicas of a controller manager
+// by the namespace of the reconciled objects, so that the reconcile throughput of the hub cluster scales
+// horizontally for very large fleets, as the objects of each member cluster live in its own namespace. The
+// controllers which write objects shared by the member clusters, e.g. the ServiceImports, shard their work by the
+// key of the shared object instead, so that each shared object is written by a single shard.
+//
+// Each shard elects its own leader among the replicas started for it, and the replicas of a shard hand the work
+// over to each other through the leader election, as a single-sharded controller manager does. When the number of
+// shards changes, the shards of the new count only take the work over once the leases of the shards of the other
+// counts are released or expired, so that no work is done by two shards at once.
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	coordinationv1client "k8s.io/client-go/kubernetes/typed/coordination/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Shard is the part of the work of the controllers a replica of a controller manager is responsible for.
+type Shard struct {
+	// Index is the index of the shard, in [0, Count).
+	Index int
+	// Count is the number of shards; the work is not sharded if it is 1.
+	Count int
+}
+
+// Validate returns an error if the shard is invalid.
+func (s Shard) Validate() error {
+	if s.Count < 1 {
+		return fmt.Errorf("the number of shards must be positive, got %d", s.Count)
+	}
+	if s.Index < 0 || s.Index >= s.Count {
+		return fmt.Errorf("the shard index must be in [0, %d), got %d", s.Count, s.Index)
+	}
+	return nil
+}
+
+// IsSharded returns true if the work is split across more than one shard.
+func (s Shard) IsSharded() bool {
+	return s.Count > 1
+}
+
+// Owns returns true if the shard is responsible for the objects of the shard key, by default their namespace; the
+// cluster-scoped objects, whose namespace is empty, are all owned by the same shard.
+func (s Shard) Owns(key string) bool {
+	if !s.IsSharded() {
+		return true
+	}
+	h := fnv.New32a()
+	// Writing to a hash never returns an error.
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum32()%uint32(s.Count)) == s.Index
+}
+
+// LeaderElectionID returns the ID of the leader election lease of the shard; the leases of the shards are derived
+// from the one of the single-sharded controller manager, which is kept as is if the work is not sharded. The ID
+// includes the number of shards, so that the shards of different counts never share a lease.
+func (s Shard) LeaderElectionID(id string) string {
+	if !s.IsSharded() {
+		return id
+	}
+	return fmt.Sprintf("shard-%d-of-%d.%s", s.Index, s.Count, id)
+}
+
+// shardCount returns the number of shards of the shard whose leader election lease is named name, or false if the
+// lease is not the one of a shard of the controller manager whose leader election ID is id.
+func shardCount(name, id string) (int, bool) {
+	if name == id {
+		return 1, true
+	}
+	prefix, ok := strings.CutSuffix(name, "."+id)
+	if !ok {
+		return 0, false
+	}
+	var s Shard
+	if _, err := fmt.Sscanf(prefix, "shard-%d-of-%d", &s.Index, &s.Count); err != nil {
+		return 0, false
+	}
+	if s.Validate() != nil || s.LeaderElectionID(id) != name {
+		return 0, false
+	}
+	return s.Count, true
+}
+
+// KeyFunc returns the shard key of a request, which decides the shard responsible for it; a request whose key is
+// unknown, e.g. as the reconciled object is gone, is accepted by every shard.
+type KeyFunc func(req reconcile.Request) (key string, ok bool)
+
+// NamespaceKey shards the requests by the namespace of the reconciled objects.
+func NamespaceKey(req reconcile.Request) (string, bool) {
+	return req.Namespace, true
+}
+
+// ObjectKey shards the requests by the namespace and name of the reconciled objects, for the objects shared by the
+// member clusters, e.g. the ServiceImports.
+func ObjectKey(req reconcile.Request) (string, bool) {
+	return req.String(), true
+}
+
+// NewQueue builds the work queue of a controller, which drops the requests for the namespaces the shard is not
+// responsible for; see controller.Options.NewQueue.
+func (s Shard) NewQueue(controllerName string, rateLimiter workqueue.TypedRateLimiter[reconcile.Request]) workqueue.TypedRateLimitingInterface[reconcile.Request] {
+	return s.NewQueueFunc(NamespaceKey)(controllerName, rateLimiter)
+}
+
+// NewQueueFunc returns the constructor of the work queues of a controller which shards the requests by the given
+// key instead of by namespace; see controller.Options.NewQueue.
+func (s Shard) NewQueueFunc(key KeyFunc) func(string, workqueue.TypedRateLimiter[reconcile.Request]) workqueue.TypedRateLimitingInterface[reconcile.Request] {
+	return func(controllerName string, rateLimiter workqueue.TypedRateLimiter[reconcile.Request]) workqueue.TypedRateLimitingInterface[reconcile.Request] {
+		return &shardedQueue{
+			TypedRateLimitingInterface: workqueue.NewTypedRateLimitingQueueWithConfig(rateLimiter, workqueue.TypedRateLimitingQueueConfig[reconcile.Request]{
+				Name: controllerName,
+			}),
+			shard: s,
+			key:   key,
+		}
+	}
+}
+
+// shardedQueue is a work queue which only accepts the requests for the objects its shard is responsible for; the
+// requests are filtered as they are added, so that the requests mapped from watches of other objects, e.g. from a
+// ServiceImport to the InternalServiceExports of all the member clusters, are filtered as well.
+type shardedQueue struct {
+	workqueue.TypedRateLimitingInterface[reconcile.Request]
+	shard Shard
+	key   KeyFunc
+}
+
+var _ workqueue.TypedRateLimitingInterface[reconcile.Request] = &shardedQueue{}
+
+// owns returns true if the shard of the queue is responsible for the request.
+func (q *shardedQueue) owns(item reconcile.Request) bool {
+	key, ok := q.key(item)
+	return !ok || q.shard.Owns(key)
+}
+
+// Add implements workqueue.TypedInterface.
+func (q *shardedQueue) Add(item reconcile.Request) {
+	if q.owns(item) {
+		q.TypedRateLimitingInterface.Add(item)
+	}
+}
+
+// AddAfter implements workqueue.TypedDelayingInterface.
+func (q *shardedQueue) AddAfter(item reconcile.Request, duration time.Duration) {
+	if q.owns(item) {
+		q.TypedRateLimitingInterface.AddAfter(item, duration)
+	}
+}
+
+// AddRateLimited implements workqueue.TypedRateLimitingInterface.
+func (q *shardedQueue) AddRateLimited(item reconcile.Request) {
+	if q.owns(item) {
+		q.TypedRateLimitingInterface.AddRateLimited(item)
+	}
+}
+
+// NewResourceLock builds the leader election lock of the shard, the lease named LeaderElectionID(id) in the
+// namespace, which is only acquired once the leases of the shards of the other shard counts are released or
+// expired; see manager.Options.LeaderElectionResourceLockInterface.
+func (s Shard) NewResourceLock(config *rest.Config, namespace, id string) (resourcelock.Interface, error) {
+	// The identity of the leader is unique, as the one of the leaders elected by controller-runtime.
+	identity, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+	identity = identity + "_" + string(uuid.NewUUID())
+	coordinationClient, err := coordinationv1client.NewForConfig(rest.AddUserAgent(rest.CopyConfig(config), "leader-election"))
+	if err != nil {
+		return nil, err
+	}
+	lock, err := resourcelock.New(resourcelock.LeasesResourceLock, namespace, s.LeaderElectionID(id), nil, coordinationClient,
+		resourcelock.ResourceLockConfig{Identity: identity})
+	if err != nil {
+		return nil, err
+	}
+	return &handoffLock{
+		Interface: lock,
+		leases:    coordinationClient.Leases(namespace),
+		shard:     s,
+		id:        id,
+		now:       time.Now,
+	}, nil
+}
+
+// handoffLock is a leader election lock which fails to be acquired while a lease of a shard of another shard count
+// is held, so that the work is handed over from the shards of the previous count to the ones of the new count, e.g.
+// during the rollout changing the number of shards, without being done by two shards at once.
+type handoffLock struct {
+	resourcelock.Interface
+	leases coordinationv1client.LeaseInterface
+	shard  Shard
+	id     string
+	now    func() time.Time
+}
+
+var _ resourcelock.Interface = &handoffLock{}
+
+// Create implements resourcelock.Interface; the lease is created as it is first acquired.
+func (l *handoffLock) Create(ctx context.Context, ler resourcelock.LeaderElectionRecord) error {
+	if err := l.checkHandoff(ctx); err != nil {
+		return err
+	}
+	return l.Interface.Create(ctx, ler)
+}
+
+// Update implements resourcelock.Interface.
+func (l *handoffLock) Update(ctx context.Context, ler resourcelock.LeaderElectionRecord) error {
+	// The leader elector sets the acquire time to the renew time only as it acquires the lease; the renewals keep
+	// the acquire time, and the release clears the holder.
+	if ler.HolderIdentity != "" && ler.AcquireTime.Equal(&ler.RenewTime) {
+		if err := l.checkHandoff(ctx); err != nil {
+			return err
+		}
+	}
+	return l.Interface.Update(ctx, ler)
+}
+
+// checkHandoff returns an error if a lease of a shard of another shard count is held.
+func (l *handoffLock) checkHandoff(ctx context.Context) error {
+	leases, err := l.leases.List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list the leases of the shards: %w", err)
+	}
+	now := l.now()
+	for i := range leases.Items {
+		lease := &leases.Items[i]
+		count, ok := shardCount(lease.Name, l.id)
+		if !ok || count == l.shard.Count {
+			continue
+		}
+		spec := lease.Spec
+		if spec.HolderIdentity == nil || *spec.HolderIdentity == "" || spec.RenewTime == nil || spec.LeaseDurationSeconds == nil {
+			continue
+		}
+		if spec.RenewTime.Add(time.Duration(*spec.LeaseDurationSeconds) * time.Second).After(now) {
+			return fmt.Errorf("the lease %s of a shard of %d shards is held by %s; waiting for its handoff", lease.Name, count, *spec.HolderIdentity)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,367 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func request(namespace, name string) reconcile.Request {
+	return reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}}
+}
+
+// TestValidate tests the Shard.Validate method.
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		shard   Shard
+		wantErr bool
+	}{
+		{
+			name:  "not sharded",
+			shard: Shard{Index: 0, Count: 1},
+		},
+		{
+			name:  "last shard",
+			shard: Shard{Index: 2, Count: 3},
+		},
+		{
+			name:    "no shards",
+			shard:   Shard{Index: 0, Count: 0},
+			wantErr: true,
+		},
+		{
+			name:    "index out of range",
+			shard:   Shard{Index: 3, Count: 3},
+			wantErr: true,
+		},
+		{
+			name:    "negative index",
+			shard:   Shard{Index: -1, Count: 3},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.shard.Validate(); (err != nil) != tc.wantErr {
+				t.Errorf("Validate() = %v, want error %t", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+// TestOwns tests that each namespace is owned by exactly one shard, and that the work is spread across the shards.
+func TestOwns(t *testing.T) {
+	const count = 4
+	owned := make([]int, count)
+	for i := 0; i < 1000; i++ {
+		namespace := fmt.Sprintf("fleet-member-cluster-%d", i)
+		owners := 0
+		for index := 0; index < count; index++ {
+			if (Shard{Index: index, Count: count}).Owns(namespace) {
+				owners++
+				owned[index]++
+			}
+		}
+		if owners != 1 {
+			t.Fatalf("namespace %s is owned by %d shards, want 1", namespace, owners)
+		}
+	}
+	for index, n := range owned {
+		if n == 0 {
+			t.Errorf("shard %d owns no namespace, want some", index)
+		}
+	}
+
+	if !(Shard{Index: 0, Count: 1}).Owns("any") {
+		t.Errorf("Owns() = false for the only shard, want true")
+	}
+}
+
+// TestLeaderElectionID tests the Shard.LeaderElectionID method.
+func TestLeaderElectionID(t *testing.T) {
+	const id = "2bf2b407.hub.networking.fleet.azure.com"
+	if got := (Shard{Index: 0, Count: 1}).LeaderElectionID(id); got != id {
+		t.Errorf("LeaderElectionID() = %s, want %s", got, id)
+	}
+	if got, want := (Shard{Index: 1, Count: 3}).LeaderElectionID(id), "shard-1-of-3."+id; got != want {
+		t.Errorf("LeaderElectionID() = %s, want %s", got, want)
+	}
+}
+
+// TestShardCount tests the shardCount function.
+func TestShardCount(t *testing.T) {
+	const id = "2bf2b407.hub.networking.fleet.azure.com"
+	testCases := []struct {
+		name      string
+		leaseName string
+		wantCount int
+		wantOK    bool
+	}{
+		{
+			name:      "not sharded",
+			leaseName: id,
+			wantCount: 1,
+			wantOK:    true,
+		},
+		{
+			name:      "sharded",
+			leaseName: "shard-1-of-3." + id,
+			wantCount: 3,
+			wantOK:    true,
+		},
+		{
+			name:      "other controller manager",
+			leaseName: "shard-1-of-3.a2c4a8f5.member.networking.fleet.azure.com",
+		},
+		{
+			name:      "malformed",
+			leaseName: "shard-1." + id,
+		},
+		{
+			name:      "index out of range",
+			leaseName: "shard-3-of-3." + id,
+		},
+		{
+			name:      "trailing characters",
+			leaseName: "shard-1-of-3x." + id,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			count, ok := shardCount(tc.leaseName, id)
+			if count != tc.wantCount || ok != tc.wantOK {
+				t.Errorf("shardCount() = (%d, %t), want (%d, %t)", count, ok, tc.wantCount, tc.wantOK)
+			}
+		})
+	}
+}
+
+// TestNewQueue tests that the work queue of a shard drops the requests for the namespaces of other shards.
+func TestNewQueue(t *testing.T) {
+	shard := Shard{Index: 0, Count: 2}
+	var owned, notOwned string
+	for i := 0; owned == "" || notOwned == ""; i++ {
+		namespace := fmt.Sprintf("fleet-member-cluster-%d", i)
+		if shard.Owns(namespace) {
+			owned = namespace
+		} else {
+			notOwned = namespace
+		}
+	}
+
+	q := shard.NewQueue("test", workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+	defer q.ShutDown()
+	q.Add(request(notOwned, "a"))
+	q.AddRateLimited(request(notOwned, "b"))
+	q.AddAfter(request(notOwned, "c"), 0)
+	q.Add(request(owned, "a"))
+	if got := q.Len(); got != 1 {
+		t.Fatalf("Len() = %d, want 1", got)
+	}
+	if got, _ := q.Get(); got != request(owned, "a") {
+		t.Errorf("Get() = %v, want %v", got, request(owned, "a"))
+	}
+}
+
+// TestNewQueueFunc tests that the work queue of a shard sharding by a key drops the requests whose key the shard is
+// not responsible for, and accepts the requests whose key is unknown.
+func TestNewQueueFunc(t *testing.T) {
+	shard := Shard{Index: 0, Count: 2}
+	var owned, notOwned string
+	for i := 0; owned == "" || notOwned == ""; i++ {
+		name := fmt.Sprintf("app-%d", i)
+		if key, _ := ObjectKey(request("work", name)); shard.Owns(key) {
+			owned = name
+		} else {
+			notOwned = name
+		}
+	}
+
+	key := func(req reconcile.Request) (string, bool) {
+		if req.Name == "unknown" {
+			return "", false
+		}
+		return ObjectKey(req)
+	}
+	q := shard.NewQueueFunc(key)("test", workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+	defer q.ShutDown()
+	q.Add(request("work", notOwned))
+	q.Add(request("work", owned))
+	q.Add(request("work", "unknown"))
+	if got := q.Len(); got != 2 {
+		t.Fatalf("Len() = %d, want 2", got)
+	}
+	for _, want := range []reconcile.Request{request("work", owned), request("work", "unknown")} {
+		if got, _ := q.Get(); got != want {
+			t.Errorf("Get() = %v, want %v", got, want)
+		}
+	}
+}
+
+// TestHandoffLock tests that the lease of a shard is only acquired once the leases of the shards of the other shard
+// counts are released or expired, and that it is renewed and released regardless.
+func TestHandoffLock(t *testing.T) {
+	const (
+		namespace = "fleet-system"
+		id        = "2bf2b407.hub.networking.fleet.azure.com"
+	)
+	now := time.Now()
+	lease := func(name, holder string, renewTime time.Time) *coordinationv1.Lease {
+		return &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       ptr.To(holder),
+				LeaseDurationSeconds: ptr.To[int32](15),
+				RenewTime:            &metav1.MicroTime{Time: renewTime},
+			},
+		}
+	}
+	acquire := resourcelock.LeaderElectionRecord{
+		HolderIdentity:       "new-leader",
+		LeaseDurationSeconds: 15,
+		AcquireTime:          metav1.NewTime(now),
+		RenewTime:            metav1.NewTime(now),
+	}
+	renew := acquire
+	renew.AcquireTime = metav1.NewTime(now.Add(-time.Minute))
+	release := resourcelock.LeaderElectionRecord{
+		LeaseDurationSeconds: 1,
+		AcquireTime:          metav1.NewTime(now),
+		RenewTime:            metav1.NewTime(now),
+	}
+
+	testCases := []struct {
+		name       string
+		leases     []*coordinationv1.Lease
+		record     resourcelock.LeaderElectionRecord
+		wantCreate bool
+		wantErr    bool
+	}{
+		{
+			name:       "first shards",
+			record:     acquire,
+			wantCreate: true,
+		},
+		{
+			name: "create while a shard of the previous count is held",
+			leases: []*coordinationv1.Lease{
+				lease("shard-0-of-2."+id, "old-leader", now),
+			},
+			record:     acquire,
+			wantCreate: true,
+			wantErr:    true,
+		},
+		{
+			name: "acquire while the unsharded lease is held",
+			leases: []*coordinationv1.Lease{
+				lease("shard-1-of-3."+id, "", now.Add(-time.Minute)),
+				lease(id, "old-leader", now),
+			},
+			record:  acquire,
+			wantErr: true,
+		},
+		{
+			name: "acquire once the shards of the previous count are released or expired",
+			leases: []*coordinationv1.Lease{
+				lease("shard-1-of-3."+id, "", now.Add(-time.Minute)),
+				lease("shard-0-of-2."+id, "", now),
+				lease("shard-1-of-2."+id, "old-leader", now.Add(-time.Minute)),
+			},
+			record: acquire,
+		},
+		{
+			name: "acquire while another shard of the same count is held",
+			leases: []*coordinationv1.Lease{
+				lease("shard-1-of-3."+id, "", now.Add(-time.Minute)),
+				lease("shard-0-of-3."+id, "other-leader", now),
+			},
+			record: acquire,
+		},
+		{
+			name: "acquire while the lease of another controller manager is held",
+			leases: []*coordinationv1.Lease{
+				lease("shard-1-of-3."+id, "", now.Add(-time.Minute)),
+				lease("a2c4a8f5.member.networking.fleet.azure.com", "other-leader", now),
+			},
+			record: acquire,
+		},
+		{
+			name: "renew while a shard of another count is held",
+			leases: []*coordinationv1.Lease{
+				lease("shard-1-of-3."+id, "new-leader", now.Add(-time.Second)),
+				lease("shard-0-of-4."+id, "newer-leader", now),
+			},
+			record: renew,
+		},
+		{
+			name: "release while a shard of another count is held",
+			leases: []*coordinationv1.Lease{
+				lease("shard-1-of-3."+id, "new-leader", now.Add(-time.Second)),
+				lease("shard-0-of-4."+id, "newer-leader", now),
+			},
+			record: release,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			objs := make([]runtime.Object, 0, len(tc.leases))
+			for _, lease := range tc.leases {
+				objs = append(objs, lease)
+			}
+			clientset := fake.NewSimpleClientset(objs...)
+			shard := Shard{Index: 1, Count: 3}
+			lock, err := resourcelock.New(resourcelock.LeasesResourceLock, namespace, shard.LeaderElectionID(id), nil, clientset.CoordinationV1(),
+				resourcelock.ResourceLockConfig{Identity: "new-leader"})
+			if err != nil {
+				t.Fatalf("resourcelock.New() = %v", err)
+			}
+			l := &handoffLock{
+				Interface: lock,
+				leases:    clientset.CoordinationV1().Leases(namespace),
+				shard:     shard,
+				id:        id,
+				now:       func() time.Time { return now },
+			}
+			ctx := context.Background()
+			if tc.wantCreate {
+				err = l.Create(ctx, tc.record)
+			} else {
+				// The lease is observed before it is updated.
+				if _, _, err := l.Get(ctx); err != nil {
+					t.Fatalf("Get() = %v", err)
+				}
+				err = l.Update(ctx, tc.record)
+			}
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("acquiring the lock = %v, want error %t", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			got, _, err := l.Get(ctx)
+			if err != nil {
+				t.Fatalf("Get() = %v", err)
+			}
+			if got.HolderIdentity != tc.record.HolderIdentity {
+				t.Errorf("Get() holder = %q, want %q", got.HolderIdentity, tc.record.HolderIdentity)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package staleexport features the hub-side detection of the stale exports, i.e. the exports of the member clusters
+// whose networking agents have stopped renewing their heartbeats, which lets the hub withdraw the endpoints of a dead
+// member cluster from the fleet after a grace period, so that the cluster does not blackhole the traffic fleet-wide.
+//
+// The heartbeat of a networking agent is the last heartbeat time of the InternalMemberNetworkStatus of its member
+// cluster, which the agent renews periodically as a lease; the exports of the cluster are marked as stale, with the
+// time the lease expired, as soon as it expires, and are excluded from their ServiceImports once they stay stale for
+// the grace period.
+package staleexport
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/uniquename"
+)
+
+// ReasonAgentNotRenewing is the reason of the stale condition of the exports of a member cluster whose networking
+// agent has stopped renewing its heartbeat.
+const ReasonAgentNotRenewing = "AgentNotRenewing"
+
+// StaleSince returns when the object was marked as stale, if it is.
+func StaleSince(obj client.Object) (time.Time, bool) {
+	value, ok := obj.GetAnnotations()[objectmeta.ExportedObjectAnnotationStaleSince]
+	if !ok {
+		return time.Time{}, false
+	}
+	since, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		// A corrupt mark is replaced by the hub with the actual expiry as long as the agent stays silent.
+		return time.Time{}, false
+	}
+	return since, true
+}
+
+// Mark marks the object as stale since the given time, and returns true if the object is changed; an object
+// already marked keeps its mark, so that the grace period is not restarted.
+func Mark(obj client.Object, since time.Time) bool {
+	if _, ok := StaleSince(obj); ok {
+		return false
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[objectmeta.ExportedObjectAnnotationStaleSince] = since.UTC().Format(time.RFC3339)
+	obj.SetAnnotations(annotations)
+	return true
+}
+
+// Unmark removes the stale mark of the object, and returns true if the object is changed.
+func Unmark(obj client.Object) bool {
+	annotations := obj.GetAnnotations()
+	if _, ok := annotations[objectmeta.ExportedObjectAnnotationStaleSince]; !ok {
+		return false
+	}
+	delete(annotations, objectmeta.ExportedObjectAnnotationStaleSince)
+	obj.SetAnnotations(annotations)
+	return true
+}
+
+// Withdrawal returns true if the object has been stale for the grace period at the given time, so that its
+// endpoints should be withdrawn from the fleet; otherwise it returns how long until they should be, if ever.
+func Withdrawal(obj client.Object, gracePeriod time.Duration, now time.Time) (bool, time.Duration) {
+	since, ok := StaleSince(obj)
+	if !ok {
+		return false, 0
+	}
+	if remaining := since.Add(gracePeriod).Sub(now); remaining > 0 {
+		return false, remaining
+	}
+	return true, 0
+}
+
+// IsStaleExport returns true if the internalServiceExport is reported as stale in its status.
+func IsStaleExport(internalServiceExport *fleetnetv1alpha1.InternalServiceExport) bool {
+	return meta.IsStatusConditionTrue(internalServiceExport.Status.Conditions, string(fleetnetv1alpha1.ServiceExportStale))
+}
+
+// IsServiceStale returns true if the internalServiceExport of the Service exported from the member cluster, whose
+// namespace in the hub cluster is given, is reported as stale; a Service which is not exported is not stale.
+func IsServiceStale(ctx context.Context, reader client.Reader, clusterNamespace, svcNamespace, svcName string) (bool, error) {
+	internalServiceExport := &fleetnetv1alpha1.InternalServiceExport{}
+	key := types.NamespacedName{Namespace: clusterNamespace, Name: uniquename.ClusterScopedDeterministicName(svcNamespace, svcName)}
+	if err := reader.Get(ctx, key, internalServiceExport); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	return IsStaleExport(internalServiceExport), nil
+}
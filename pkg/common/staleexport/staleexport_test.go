@@ -0,0 +1,170 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package staleexport
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/uniquename"
+)
+
+const (
+	testNamespace        = "my-ns"
+	testServiceName      = "my-svc"
+	testClusterNamespace = "fleet-member-member-1"
+)
+
+func internalServiceExport(stale bool) *fleetnetv1alpha1.InternalServiceExport {
+	export := &fleetnetv1alpha1.InternalServiceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testClusterNamespace,
+			Name:      uniquename.ClusterScopedDeterministicName(testNamespace, testServiceName),
+		},
+	}
+	if stale {
+		export.Status.Conditions = []metav1.Condition{
+			{
+				Type:   string(fleetnetv1alpha1.ServiceExportStale),
+				Status: metav1.ConditionTrue,
+				Reason: ReasonAgentNotRenewing,
+			},
+		}
+	}
+	return export
+}
+
+// TestMarkUnmark tests the Mark, Unmark and StaleSince functions.
+func TestMarkUnmark(t *testing.T) {
+	export := internalServiceExport(false)
+	if _, ok := StaleSince(export); ok {
+		t.Fatalf("StaleSince() of an unmarked export = true, want false")
+	}
+	if Unmark(export) {
+		t.Errorf("Unmark() of an unmarked export = true, want false")
+	}
+
+	since := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	if !Mark(export, since) {
+		t.Fatalf("Mark() = false, want true")
+	}
+	if got, ok := StaleSince(export); !ok || !got.Equal(since) {
+		t.Errorf("StaleSince() = (%v, %t), want (%v, true)", got, ok, since)
+	}
+	if Mark(export, since.Add(time.Hour)) {
+		t.Errorf("Mark() of a marked export = true, want false")
+	}
+	if got, _ := StaleSince(export); !got.Equal(since) {
+		t.Errorf("StaleSince() after another Mark() = %v, want %v", got, since)
+	}
+
+	if !Unmark(export) {
+		t.Errorf("Unmark() = false, want true")
+	}
+	if _, ok := StaleSince(export); ok {
+		t.Errorf("StaleSince() after Unmark() = true, want false")
+	}
+}
+
+// TestStaleSince_Corrupt tests that a corrupt mark is ignored and replaced.
+func TestStaleSince_Corrupt(t *testing.T) {
+	export := internalServiceExport(false)
+	export.Annotations = map[string]string{objectmeta.ExportedObjectAnnotationStaleSince: "yesterday"}
+	if _, ok := StaleSince(export); ok {
+		t.Errorf("StaleSince() of a corrupt mark = true, want false")
+	}
+	if !Mark(export, time.Now()) {
+		t.Errorf("Mark() of a corruptly marked export = false, want true")
+	}
+}
+
+// TestWithdrawal tests the Withdrawal function.
+func TestWithdrawal(t *testing.T) {
+	now := time.Now()
+	gracePeriod := 5 * time.Minute
+	testCases := []struct {
+		name          string
+		staleSince    *time.Time
+		want          bool
+		wantRemaining time.Duration
+	}{
+		{
+			name: "not stale",
+		},
+		{
+			name:          "stale within the grace period",
+			staleSince:    ptr.To(now.Add(-time.Minute)),
+			wantRemaining: 4 * time.Minute,
+		},
+		{
+			name:       "stale for the grace period",
+			staleSince: ptr.To(now.Add(-gracePeriod)),
+			want:       true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			export := internalServiceExport(false)
+			if tc.staleSince != nil {
+				Mark(export, *tc.staleSince)
+			}
+			got, gotRemaining := Withdrawal(export, gracePeriod, now)
+			// The mark is kept with a precision of a second.
+			if got != tc.want || (gotRemaining-tc.wantRemaining).Abs() > time.Second {
+				t.Errorf("Withdrawal() = (%t, %v), want (%t, %v)", got, gotRemaining, tc.want, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+// TestIsServiceStale tests the IsServiceStale function.
+func TestIsServiceStale(t *testing.T) {
+	testCases := []struct {
+		name    string
+		objects []*fleetnetv1alpha1.InternalServiceExport
+		want    bool
+	}{
+		{
+			name: "service is not exported",
+		},
+		{
+			name:    "export is not stale",
+			objects: []*fleetnetv1alpha1.InternalServiceExport{internalServiceExport(false)},
+		},
+		{
+			name:    "export is stale",
+			objects: []*fleetnetv1alpha1.InternalServiceExport{internalServiceExport(true)},
+			want:    true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			builder := fake.NewClientBuilder().WithScheme(scheme)
+			for _, obj := range tc.objects {
+				builder = builder.WithObjects(obj)
+			}
+			got, err := IsServiceStale(context.Background(), builder.Build(), testClusterNamespace, testNamespace, testServiceName)
+			if err != nil {
+				t.Fatalf("IsServiceStale() got error %v, want no error", err)
+			}
+			if got != tc.want {
+				t.Errorf("IsServiceStale() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
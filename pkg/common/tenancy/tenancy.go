@@ -0,0 +1,89 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package tenancy features the partitioning of a fleet into tenants, which lets a single hub cluster serve multiple
+// isolated business units.
+//
+// A member cluster belongs to the tenant set with the tenant label on its namespace in the hub cluster, or to the
+// default tenant if the namespace is not labeled. A ServiceImport is owned by the tenant of the member cluster whose
+// export first claims it: only the exports of the owning tenant are aggregated into the ServiceImport, and resolve
+// their conflicts among themselves, and only the member clusters of the owning tenant import it. The ServiceImport
+// is released once the exports of the owning tenant leave it.
+package tenancy
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/uniquename"
+)
+
+const (
+	// ReasonOwnedByOtherTenant is the reason of the tenant conflict condition of the exports, and of the NotAllowed
+	// condition of the imports, of a ServiceImport owned by another tenant.
+	ReasonOwnedByOtherTenant = "OwnedByOtherTenant"
+)
+
+// TenantOf returns the tenant of the member cluster whose namespace in the hub cluster is given; a namespace which
+// does not exist, e.g. of a member cluster which has left the fleet, belongs to the default tenant.
+func TenantOf(ctx context.Context, reader client.Reader, clusterNamespace string) (string, error) {
+	ns := &corev1.Namespace{}
+	if err := reader.Get(ctx, types.NamespacedName{Name: clusterNamespace}, ns); err != nil {
+		return "", client.IgnoreNotFound(err)
+	}
+	return ns.Labels[objectmeta.MemberNamespaceLabelTenant], nil
+}
+
+// Owner returns the tenant owning the ServiceImport, if any.
+func Owner(serviceImport client.Object) (string, bool) {
+	tenant, ok := serviceImport.GetLabels()[objectmeta.ServiceImportLabelTenant]
+	return tenant, ok
+}
+
+// Claim records the tenant as the owner of the ServiceImport, and returns true if the object is changed; a
+// ServiceImport owned by another tenant is left as it is.
+func Claim(serviceImport client.Object, tenant string) bool {
+	if _, ok := Owner(serviceImport); ok {
+		return false
+	}
+	labels := serviceImport.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[objectmeta.ServiceImportLabelTenant] = tenant
+	serviceImport.SetLabels(labels)
+	return true
+}
+
+// IsTenantLabelChanged returns true if the tenant label of the namespace is changed.
+func IsTenantLabelChanged(oldNamespace, newNamespace client.Object) bool {
+	oldTenant, oldOK := oldNamespace.GetLabels()[objectmeta.MemberNamespaceLabelTenant]
+	newTenant, newOK := newNamespace.GetLabels()[objectmeta.MemberNamespaceLabelTenant]
+	return oldOK != newOK || oldTenant != newTenant
+}
+
+// IsConflictedExport returns true if the internalServiceExport is reported in conflict with another tenant in its
+// status.
+func IsConflictedExport(internalServiceExport *fleetnetv1alpha1.InternalServiceExport) bool {
+	return meta.IsStatusConditionTrue(internalServiceExport.Status.Conditions, string(fleetnetv1alpha1.ServiceExportTenantConflict))
+}
+
+// IsServiceConflicted returns true if the internalServiceExport of the Service exported from the member cluster,
+// whose namespace in the hub cluster is given, is reported in conflict with another tenant; a Service which is not
+// exported is not in conflict.
+func IsServiceConflicted(ctx context.Context, reader client.Reader, clusterNamespace, svcNamespace, svcName string) (bool, error) {
+	internalServiceExport := &fleetnetv1alpha1.InternalServiceExport{}
+	key := types.NamespacedName{Namespace: clusterNamespace, Name: uniquename.ClusterScopedDeterministicName(svcNamespace, svcName)}
+	if err := reader.Get(ctx, key, internalServiceExport); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	return IsConflictedExport(internalServiceExport), nil
+}
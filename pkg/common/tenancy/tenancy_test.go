@@ -0,0 +1,107 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package tenancy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+const testClusterNamespace = "fleet-member-member-1"
+
+// TestTenantOf tests the TenantOf function.
+func TestTenantOf(t *testing.T) {
+	testCases := []struct {
+		name    string
+		objects []client.Object
+		want    string
+	}{
+		{
+			name: "namespace not found",
+		},
+		{
+			name: "namespace not labeled",
+			objects: []client.Object{
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: testClusterNamespace}},
+			},
+		},
+		{
+			name: "namespace labeled",
+			objects: []client.Object{
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+					Name:   testClusterNamespace,
+					Labels: map[string]string{objectmeta.MemberNamespaceLabelTenant: "payments"},
+				}},
+			},
+			want: "payments",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := corev1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.objects...).Build()
+
+			got, err := TenantOf(context.Background(), fakeClient, testClusterNamespace)
+			if err != nil {
+				t.Fatalf("TenantOf() got error %v, want no error", err)
+			}
+			if got != tc.want {
+				t.Errorf("TenantOf() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestClaim tests the Claim function.
+func TestClaim(t *testing.T) {
+	testCases := []struct {
+		name       string
+		labels     map[string]string
+		wantChange bool
+		wantLabels map[string]string
+	}{
+		{
+			name:       "not owned",
+			labels:     map[string]string{"app": "web"},
+			wantChange: true,
+			wantLabels: map[string]string{"app": "web", objectmeta.ServiceImportLabelTenant: "payments"},
+		},
+		{
+			name:       "owned by the default tenant",
+			labels:     map[string]string{objectmeta.ServiceImportLabelTenant: ""},
+			wantLabels: map[string]string{objectmeta.ServiceImportLabelTenant: ""},
+		},
+		{
+			name:       "owned by another tenant",
+			labels:     map[string]string{objectmeta.ServiceImportLabelTenant: "search"},
+			wantLabels: map[string]string{objectmeta.ServiceImportLabelTenant: "search"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			serviceImport := &fleetnetv1alpha1.ServiceImport{ObjectMeta: metav1.ObjectMeta{Labels: tc.labels}}
+			if got := Claim(serviceImport, "payments"); got != tc.wantChange {
+				t.Errorf("Claim() = %t, want %t", got, tc.wantChange)
+			}
+			if diff := cmp.Diff(tc.wantLabels, serviceImport.Labels); diff != "" {
+				t.Errorf("Claim() labels mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
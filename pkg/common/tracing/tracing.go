@@ -0,0 +1,114 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package tracing features the OpenTelemetry tracing of the fleet networking controllers. The journey of an export
+// across the fleet is traced end to end by carrying a correlation ID, i.e. the W3C trace context of the span which
+// exports a change, as an annotation on the exported objects; the controllers handling the exported objects in the
+// hub cluster and the importing member clusters continue the trace from the annotation.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+const (
+	tracerName = "go.goms.io/fleet-networking"
+
+	// traceParentKey is the key of the W3C trace context carrying the correlation ID.
+	traceParentKey = "traceparent"
+)
+
+// traceContext formats and parses correlation IDs.
+var traceContext = propagation.TraceContext{}
+
+// Setup exports the traces of the controllers to the OTLP/HTTP endpoint, e.g. http://otel-collector:4318, under the
+// given service name; the controllers are not traced if the endpoint is empty. The returned function flushes the
+// pending spans and shuts down the exporter.
+func Setup(ctx context.Context, endpoint, serviceName string) (func(context.Context) error, error) {
+	if endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the OTLP trace exporter: %w", err)
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the trace resource: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// StartReconcile starts the span of a reconciliation of the given controller.
+func StartReconcile(ctx context.Context, controller string, req reconcile.Request) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, controller+".Reconcile", trace.WithAttributes(
+		attribute.String("controller", controller),
+		attribute.String("namespace", req.Namespace),
+		attribute.String("name", req.Name),
+	))
+}
+
+// Start starts a span handling an exported object; the span continues the trace of the export if the object carries
+// a correlation ID, and is linked to the span in the context, i.e. the span of the reconciliation.
+func Start(ctx context.Context, spanName string, annotations map[string]string) (context.Context, trace.Span) {
+	remoteCtx := traceContext.Extract(ctx, propagation.MapCarrier{traceParentKey: annotations[objectmeta.ExportedObjectAnnotationCorrelationID]})
+	remote := trace.SpanContextFromContext(remoteCtx)
+	if !remote.IsValid() || !remote.IsRemote() {
+		return otel.Tracer(tracerName).Start(ctx, spanName)
+	}
+	return otel.Tracer(tracerName).Start(trace.ContextWithRemoteSpanContext(ctx, remote), spanName,
+		trace.WithLinks(trace.LinkFromContext(ctx)))
+}
+
+// CorrelationID returns the correlation ID of the span in the context, for the exported objects to carry; it returns
+// an empty string if the span is not sampled, e.g. when the controllers are not traced.
+func CorrelationID(ctx context.Context) string {
+	if !trace.SpanContextFromContext(ctx).IsSampled() {
+		return ""
+	}
+	carrier := propagation.MapCarrier{}
+	traceContext.Inject(ctx, carrier)
+	return carrier[traceParentKey]
+}
+
+// SetCorrelationID annotates an exported object with the correlation ID of the span in the context; an existing
+// correlation ID is removed if the span is not sampled, so that a stale trace is not continued.
+func SetCorrelationID(ctx context.Context, annotations map[string]string) map[string]string {
+	correlationID := CorrelationID(ctx)
+	if correlationID == "" {
+		delete(annotations, objectmeta.ExportedObjectAnnotationCorrelationID)
+		return annotations
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[objectmeta.ExportedObjectAnnotationCorrelationID] = correlationID
+	return annotations
+}
+
+// EndSpan records the error, if any, on the span and ends it.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,81 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+// TestSetCorrelationID_NotTraced tests the SetCorrelationID function when the controllers are not traced.
+func TestSetCorrelationID_NotTraced(t *testing.T) {
+	testCases := []struct {
+		name        string
+		annotations map[string]string
+		want        map[string]string
+	}{
+		{
+			name: "no annotations",
+		},
+		{
+			name: "stale correlation ID",
+			annotations: map[string]string{
+				"key": "value",
+				objectmeta.ExportedObjectAnnotationCorrelationID: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+			},
+			want: map[string]string{"key": "value"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := SetCorrelationID(context.Background(), tc.annotations)
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("SetCorrelationID() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestStart_ContinuesTrace tests that the span handling an exported object continues the trace of its export.
+func TestStart_ContinuesTrace(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	oldProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(oldProvider)
+
+	exportCtx, exportSpan := StartReconcile(context.Background(), "serviceexport", reconcile.Request{})
+	annotations := SetCorrelationID(exportCtx, nil)
+	exportSpan.End()
+	if annotations[objectmeta.ExportedObjectAnnotationCorrelationID] == "" {
+		t.Fatalf("SetCorrelationID(), got no correlation ID, want one")
+	}
+
+	reconcileCtx, reconcileSpan := StartReconcile(context.Background(), "endpointsliceimport", reconcile.Request{})
+	_, importSpan := Start(reconcileCtx, "endpointsliceimport.Import", annotations)
+	importSpan.End()
+	reconcileSpan.End()
+
+	exportTraceID := exportSpan.SpanContext().TraceID()
+	if got := importSpan.SpanContext().TraceID(); got != exportTraceID {
+		t.Errorf("Start(), got trace ID %s, want %s", got, exportTraceID)
+	}
+	links := recorder.Ended()[1].Links()
+	if len(links) != 1 || links[0].SpanContext.SpanID() != trace.SpanContextFromContext(reconcileCtx).SpanID() {
+		t.Errorf("Start(), got links %v, want a link to the reconciliation span", links)
+	}
+}
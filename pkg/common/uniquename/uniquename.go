@@ -8,6 +8,8 @@ Licensed under the MIT license.
 package uniquename
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"strings"
 	"unicode"
@@ -28,6 +30,7 @@ const (
 	DNS1035Label Format = 3
 
 	uuidLength = 5
+	hashLength = 10
 )
 
 // minInt returns the smaller one of two integers.
@@ -118,6 +121,29 @@ func ClusterScopedUniqueName(format Format, namespace, name string) (string, err
 	return "", fmt.Errorf("not a valid name format: %d", format)
 }
 
+// ClusterScopedDeterministicName returns a stable name for an object within a cluster, i.e. the same namespace
+// and name always yield the same result. The name is formatted as [NAMESPACE]-[NAME], e.g. an object `app` from
+// the namespace `work` will be assigned the name `work-app`; if the result exceeds the maximum length of an
+// RFC 1123 DNS subdomain, it is truncated and suffixed with a 10 character long hash of the full namespace and name,
+// in the format of [TRUNCATED NAMESPACE-NAME]-[HASH], so that long names which share the same prefix after
+// truncation remain distinct.
+// Note: this function assumes that
+//   - the input object namespace is a valid RFC 1123 DNS label; and
+//   - the input object name is a valid RFC 1123 DNS subdomain.
+func ClusterScopedDeterministicName(namespace, name string) string {
+	deterministicName := fmt.Sprintf("%s-%s", namespace, name)
+	if len(deterministicName) <= validation.DNS1123SubdomainMaxLength {
+		return deterministicName
+	}
+
+	// Slashes are not allowed in either namespaces or names, which keeps the hash input unambiguous.
+	hash := sha256.Sum256([]byte(namespace + "/" + name))
+	availableSlots := validation.DNS1123SubdomainMaxLength - 1 - hashLength // 1 dash + 10 character hash
+	// An RFC 1123 DNS subdomain must end with an alphanumeric character.
+	prefix := strings.TrimRight(deterministicName[:availableSlots], "-.")
+	return fmt.Sprintf("%s-%s", prefix, hex.EncodeToString(hash[:])[:hashLength])
+}
+
 // FleetScopedUniqueName returns a name that is guaranteed to be unique within a cluster.
 // The name is formatted using an object's origin cluster, an object's namespace, its name, and a 5 character
 // long UUID suffix; the format is [CLUSTER ID]-[NAMESPACE]-[NAME]-[SUFFIX], e.g. an object `app` from the namespace
@@ -193,6 +219,26 @@ func FleetScopedUniqueName(format Format, clusterID, namespace, name string) (st
 	return "", fmt.Errorf("not a valid name format: %d", format)
 }
 
+// FleetScopedDeterministicName returns a stable name for an object within a fleet, i.e. the same cluster ID,
+// namespace, and name always yield the same result. The name is formatted as [CLUSTER ID]-[NAMESPACE]-[NAME], e.g.
+// an object `app` from the namespace `work` in cluster `bravelion` will be assigned the name `bravelion-work-app`;
+// long names are truncated and hashed the same way as with ClusterScopedDeterministicName.
+// Note: this function assumes that
+//   - the input cluster ID is a valid RFC 1123 DNS subdomain; and
+//   - the input object namespace is a valid RFC 1123 DNS label; and
+//   - the input object name is a valid RFC 1123 DNS subdomain.
+func FleetScopedDeterministicName(clusterID, namespace, name string) string {
+	deterministicName := fmt.Sprintf("%s-%s-%s", clusterID, namespace, name)
+	if len(deterministicName) <= validation.DNS1123SubdomainMaxLength {
+		return deterministicName
+	}
+
+	hash := sha256.Sum256([]byte(clusterID + "/" + namespace + "/" + name))
+	availableSlots := validation.DNS1123SubdomainMaxLength - 1 - hashLength // 1 dash + 10 character hash
+	prefix := strings.TrimRight(deterministicName[:availableSlots], "-.")
+	return fmt.Sprintf("%s-%s", prefix, hex.EncodeToString(hash[:])[:hashLength])
+}
+
 // RandomLowerCaseAlphabeticString returns a string of lower case alphabetic characters only. This function
 // is best used for fallback cases where one cannot format a unique name as expected, as a lower case
 // alphabetic string of proper length is always a valid Kubernetes object name, regardless of the required name
@@ -9,6 +9,8 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 const (
@@ -133,6 +135,70 @@ func TestClusterScopedUniqueName(t *testing.T) {
 	}
 }
 
+// TestClusterScopedDeterministicName tests the ClusterScopedDeterministicName function.
+func TestClusterScopedDeterministicName(t *testing.T) {
+	nameWithDashAtCut := strings.Repeat("a", 180) + "-" + strings.Repeat("b", 50)
+
+	testCases := []struct {
+		name       string
+		objectNS   string
+		objectName string
+		wantPrefix string
+		wantLength int
+	}{
+		{
+			name:       "should format name as is",
+			objectNS:   objectNS,
+			objectName: objectName,
+			wantPrefix: "work-app",
+			wantLength: 8,
+		},
+		{
+			name:       "should truncate and hash pathologically long name",
+			objectNS:   longObjectNS,
+			objectName: longObjectName,
+			wantPrefix: longObjectNS + "-" + longObjectName[:181] + "-",
+			wantLength: 253,
+		},
+		{
+			name:       "should trim trailing dash before appending hash",
+			objectNS:   longObjectNS,
+			objectName: nameWithDashAtCut,
+			wantPrefix: longObjectNS + "-" + strings.Repeat("a", 180) + "-",
+			wantLength: 252,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			name := ClusterScopedDeterministicName(tc.objectNS, tc.objectName)
+			if !strings.HasPrefix(name, tc.wantPrefix) {
+				t.Errorf("ClusterScopedDeterministicName(%s, %s)=%s, want prefix %s", tc.objectNS, tc.objectName, name, tc.wantPrefix)
+			}
+			if len(name) != tc.wantLength {
+				t.Errorf("ClusterScopedDeterministicName(%s, %s)=%s, got length %d, want length %d",
+					tc.objectNS, tc.objectName, name, len(name), tc.wantLength)
+			}
+			if errs := validation.IsDNS1123Subdomain(name); len(errs) != 0 {
+				t.Errorf("ClusterScopedDeterministicName(%s, %s)=%s, not a valid RFC 1123 DNS subdomain: %v", tc.objectNS, tc.objectName, name, errs)
+			}
+			if again := ClusterScopedDeterministicName(tc.objectNS, tc.objectName); again != name {
+				t.Errorf("ClusterScopedDeterministicName(%s, %s) is not stable, got %s and %s", tc.objectNS, tc.objectName, name, again)
+			}
+		})
+	}
+}
+
+// TestClusterScopedDeterministicName_NoCollision tests that the ClusterScopedDeterministicName function assigns
+// different names to long names that share the same prefix after truncation.
+func TestClusterScopedDeterministicName_NoCollision(t *testing.T) {
+	name1 := ClusterScopedDeterministicName(longObjectNS, longObjectName+"x")
+	name2 := ClusterScopedDeterministicName(longObjectNS, longObjectName+"y")
+	if name1 == name2 {
+		t.Errorf("ClusterScopedDeterministicName() = %s for two different names, want distinct names", name1)
+	}
+}
+
 // TestFleetScopedUniqueName tests the FleetScopedUniqueName function.
 func TestFleetScopedUniqueName(t *testing.T) {
 	testCases := []struct {
@@ -257,6 +323,54 @@ func TestFleetScopedUniqueName(t *testing.T) {
 	}
 }
 
+// TestFleetScopedDeterministicName tests the FleetScopedDeterministicName function.
+func TestFleetScopedDeterministicName(t *testing.T) {
+	testCases := []struct {
+		name       string
+		clusterID  string
+		objectNS   string
+		objectName string
+		wantPrefix string
+		wantLength int
+	}{
+		{
+			name:       "should format name as is",
+			clusterID:  clusterID,
+			objectNS:   objectNS,
+			objectName: objectName,
+			wantPrefix: "bravelion-work-app",
+			wantLength: 18,
+		},
+		{
+			name:       "should truncate and hash pathologically long name",
+			clusterID:  longClusterID,
+			objectNS:   longObjectNS,
+			objectName: longObjectName,
+			wantPrefix: (longClusterID + "-" + longObjectNS + "-" + longObjectName)[:242] + "-",
+			wantLength: 253,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			name := FleetScopedDeterministicName(tc.clusterID, tc.objectNS, tc.objectName)
+			if !strings.HasPrefix(name, tc.wantPrefix) {
+				t.Errorf("FleetScopedDeterministicName(%s, %s, %s)=%s, want prefix %s", tc.clusterID, tc.objectNS, tc.objectName, name, tc.wantPrefix)
+			}
+			if len(name) != tc.wantLength {
+				t.Errorf("FleetScopedDeterministicName(%s, %s, %s)=%s, got length %d, want length %d",
+					tc.clusterID, tc.objectNS, tc.objectName, name, len(name), tc.wantLength)
+			}
+			if errs := validation.IsDNS1123Subdomain(name); len(errs) != 0 {
+				t.Errorf("FleetScopedDeterministicName(%s, %s, %s)=%s, not a valid RFC 1123 DNS subdomain: %v", tc.clusterID, tc.objectNS, tc.objectName, name, errs)
+			}
+			if again := FleetScopedDeterministicName(tc.clusterID, tc.objectNS, tc.objectName); again != name {
+				t.Errorf("FleetScopedDeterministicName(%s, %s, %s) is not stable, got %s and %s", tc.clusterID, tc.objectNS, tc.objectName, name, again)
+			}
+		})
+	}
+}
+
 // TestRandomLowerCaseAlphabeticString tests the RandomLowerCaseAlphabeticString function.
 func TestRandomLowerCaseAlphabeticString(t *testing.T) {
 	testCases := []struct {
@@ -0,0 +1,31 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package unstructuredkind features the helpers of the controllers which handle the kinds of other projects, e.g. the
+// Gateway API routes or the Istio ServiceEntries, as unstructured objects, so that their Go types are not required.
+package unstructuredkind
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// AddToScheme registers the kinds, and their lists, as unstructured objects in the scheme.
+func AddToScheme(scheme *runtime.Scheme, gvks ...schema.GroupVersionKind) {
+	for _, gvk := range gvks {
+		scheme.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+		scheme.AddKnownTypeWithName(gvk.GroupVersion().WithKind(gvk.Kind+"List"), &unstructured.UnstructuredList{})
+		metav1.AddToGroupVersion(scheme, gvk.GroupVersion())
+	}
+}
+
+// New returns an empty unstructured object of the given kind.
+func New(gvk schema.GroupVersionKind) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	return obj
+}
@@ -0,0 +1,49 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package unstructuredkind
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// TestAddToScheme tests that the kinds and their lists are registered as unstructured objects.
+func TestAddToScheme(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "networking.istio.io", Version: "v1beta1", Kind: "ServiceEntry"}
+	scheme := runtime.NewScheme()
+	AddToScheme(scheme, gvk)
+
+	obj, err := scheme.New(gvk)
+	if err != nil {
+		t.Fatalf("New(%s) = %v", gvk, err)
+	}
+	if _, ok := obj.(*unstructured.Unstructured); !ok {
+		t.Errorf("New(%s) = %T, want *unstructured.Unstructured", gvk, obj)
+	}
+	listGVK := gvk.GroupVersion().WithKind("ServiceEntryList")
+	list, err := scheme.New(listGVK)
+	if err != nil {
+		t.Fatalf("New(%s) = %v", listGVK, err)
+	}
+	if _, ok := list.(*unstructured.UnstructuredList); !ok {
+		t.Errorf("New(%s) = %T, want *unstructured.UnstructuredList", listGVK, list)
+	}
+}
+
+// TestNew tests that the new object is empty and of the given kind.
+func TestNew(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "gateway.networking.k8s.io", Version: "v1", Kind: "HTTPRoute"}
+	obj := New(gvk)
+	if got := obj.GroupVersionKind(); got != gvk {
+		t.Errorf("GroupVersionKind() = %s, want %s", got, gvk)
+	}
+	if obj.GetName() != "" || obj.GetNamespace() != "" {
+		t.Errorf("New() = %s/%s, want an empty object", obj.GetNamespace(), obj.GetName())
+	}
+}
@@ -0,0 +1,160 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package clusternetworktopology features the ClusterNetworkTopology controller running on the hub cluster, which
+// collects the reachability probed by the member clusters into the status of the ClusterNetworkTopology of the fleet.
+package clusternetworktopology
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+// Reconciler reconciles the status of the ClusterNetworkTopology of the fleet.
+type Reconciler struct {
+	Client client.Client
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
+}
+
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=clusternetworktopologies,verbs=get;list;watch;create
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=clusternetworktopologies/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=endpointsliceimports,verbs=get;list;watch
+
+// Reconcile collects the reachability probe results annotated on the EndpointSliceImports into the probed links of
+// the ClusterNetworkTopology; the topology is created if it does not exist and any link has been probed.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	topologyRef := klog.KRef("", req.Name)
+	startTime := time.Now()
+	klog.V(2).InfoS("Reconciliation starts", "clusterNetworkTopology", topologyRef)
+	defer func() {
+		latency := time.Since(startTime).Milliseconds()
+		klog.V(2).InfoS("Reconciliation ends", "clusterNetworkTopology", topologyRef, "latency", latency)
+	}()
+
+	if req.Name != fleetnetv1alpha1.ClusterNetworkTopologyName {
+		klog.V(4).InfoS("Ignoring clusterNetworkTopology not in effect", "clusterNetworkTopology", topologyRef)
+		return ctrl.Result{}, nil
+	}
+
+	endpointSliceImportList := &fleetnetv1alpha1.EndpointSliceImportList{}
+	if err := r.Client.List(ctx, endpointSliceImportList); err != nil {
+		klog.ErrorS(err, "Failed to list endpointSliceImports")
+		return ctrl.Result{}, err
+	}
+	probedLinks := collectProbedLinks(endpointSliceImportList.Items)
+
+	topology := &fleetnetv1alpha1.ClusterNetworkTopology{}
+	if err := r.Client.Get(ctx, req.NamespacedName, topology); err != nil {
+		if !errors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to get clusterNetworkTopology", "clusterNetworkTopology", topologyRef)
+			return ctrl.Result{}, err
+		}
+		if len(probedLinks) == 0 {
+			return ctrl.Result{}, nil
+		}
+		topology = &fleetnetv1alpha1.ClusterNetworkTopology{
+			ObjectMeta: metav1.ObjectMeta{Name: req.Name},
+		}
+		klog.V(2).InfoS("Creating clusterNetworkTopology to keep the probed links", "clusterNetworkTopology", topologyRef)
+		if err := r.Client.Create(ctx, topology); err != nil {
+			klog.ErrorS(err, "Failed to create clusterNetworkTopology", "clusterNetworkTopology", topologyRef)
+			return ctrl.Result{}, err
+		}
+	}
+
+	if equality.Semantic.DeepEqual(topology.Status.ProbedLinks, probedLinks) {
+		klog.V(4).InfoS("Probed links are not changed", "clusterNetworkTopology", topologyRef)
+		return ctrl.Result{}, nil
+	}
+	topology.Status.ProbedLinks = probedLinks
+	klog.V(2).InfoS("Updating probed links", "clusterNetworkTopology", topologyRef, "numberOfLinks", len(probedLinks))
+	if err := r.Client.Status().Update(ctx, topology); err != nil {
+		klog.ErrorS(err, "Failed to update the status of clusterNetworkTopology", "clusterNetworkTopology", topologyRef)
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// collectProbedLinks returns the probed links of the EndpointSliceImports sorted by the source and the destination
+// clusters; the most recent result wins if a pair of clusters is probed through multiple EndpointSliceImports.
+func collectProbedLinks(endpointSliceImports []fleetnetv1alpha1.EndpointSliceImport) []fleetnetv1alpha1.ProbedClusterNetworkLink {
+	type pair struct {
+		source, destination string
+	}
+	links := map[pair]fleetnetv1alpha1.ProbedClusterNetworkLink{}
+	for i := range endpointSliceImports {
+		data, ok := endpointSliceImports[i].Annotations[objectmeta.EndpointSliceImportAnnotationReachabilityProbe]
+		if !ok {
+			continue
+		}
+		result := fleetnetv1alpha1.ReachabilityProbeResult{}
+		if err := json.Unmarshal([]byte(data), &result); err != nil || result.SourceCluster == "" {
+			klog.V(2).InfoS("Ignoring invalid reachability probe result", "endpointSliceImport", klog.KObj(&endpointSliceImports[i]))
+			continue
+		}
+		if result.Reachability != fleetnetv1alpha1.ClusterReachabilityDirect && result.Reachability != fleetnetv1alpha1.ClusterReachabilityGateway {
+			continue
+		}
+		key := pair{source: result.SourceCluster, destination: endpointSliceImports[i].Spec.EndpointSliceReference.ClusterID}
+		if link, ok := links[key]; ok && !link.LastTransitionTime.Before(&result.ProbeTime) {
+			continue
+		}
+		links[key] = fleetnetv1alpha1.ProbedClusterNetworkLink{
+			ClusterNetworkLink: fleetnetv1alpha1.ClusterNetworkLink{
+				SourceCluster:      key.source,
+				DestinationCluster: key.destination,
+				Reachability:       result.Reachability,
+			},
+			LastTransitionTime: result.ProbeTime,
+		}
+	}
+
+	res := make([]fleetnetv1alpha1.ProbedClusterNetworkLink, 0, len(links))
+	for _, link := range links {
+		res = append(res, link)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].SourceCluster != res[j].SourceCluster {
+			return res[i].SourceCluster < res[j].SourceCluster
+		}
+		return res[i].DestinationCluster < res[j].DestinationCluster
+	})
+	if len(res) == 0 {
+		return nil
+	}
+	return res
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	key := types.NamespacedName{Name: fleetnetv1alpha1.ClusterNetworkTopologyName}
+	enqueueTopology := handler.EnqueueRequestsFromMapFunc(func(_ context.Context, _ client.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: key}}
+	})
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&fleetnetv1alpha1.ClusterNetworkTopology{}).
+		Watches(&fleetnetv1alpha1.EndpointSliceImport{}, enqueueTopology).
+		WithOptions(r.ControllerOptions).
+		Complete(metrics.InstrumentReconciler("clusternetworktopology", r))
+}
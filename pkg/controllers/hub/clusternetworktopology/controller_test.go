@@ -0,0 +1,235 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package clusternetworktopology
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+const (
+	hubNSForMemberA     = "bravelion"
+	clusterIDForMemberA = "0"
+	hubNSForMemberB     = "highflyingcat"
+	clusterIDForMemberB = "1"
+	clusterIDForMemberC = "2"
+)
+
+var (
+	probeTime     = metav1.NewTime(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	lastProbeTime = metav1.NewTime(probeTime.Add(time.Minute))
+	topologyKey   = types.NamespacedName{Name: fleetnetv1alpha1.ClusterNetworkTopologyName}
+)
+
+func TestMain(m *testing.M) {
+	// Add custom APIs to the runtime scheme
+	if err := fleetnetv1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		log.Fatalf("failed to add custom APIs to the runtime scheme: %v", err)
+	}
+	os.Exit(m.Run())
+}
+
+// probedEndpointSliceImport returns an EndpointSliceImport in the hub namespace of a member cluster, exported by
+// another cluster and annotated with the given probe result.
+func probedEndpointSliceImport(namespace, name, exportingClusterID string, result *fleetnetv1alpha1.ReachabilityProbeResult) *fleetnetv1alpha1.EndpointSliceImport {
+	res := &fleetnetv1alpha1.EndpointSliceImport{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec: fleetnetv1alpha1.EndpointSliceExportSpec{
+			EndpointSliceReference: fleetnetv1alpha1.ExportedObjectReference{ClusterID: exportingClusterID},
+		},
+	}
+	if result != nil {
+		data, _ := json.Marshal(result)
+		res.Annotations = map[string]string{objectmeta.EndpointSliceImportAnnotationReachabilityProbe: string(data)}
+	}
+	return res
+}
+
+func probedLink(source, destination string, reachability fleetnetv1alpha1.ClusterReachability, t metav1.Time) fleetnetv1alpha1.ProbedClusterNetworkLink {
+	return fleetnetv1alpha1.ProbedClusterNetworkLink{
+		ClusterNetworkLink: fleetnetv1alpha1.ClusterNetworkLink{
+			SourceCluster:      source,
+			DestinationCluster: destination,
+			Reachability:       reachability,
+		},
+		LastTransitionTime: t,
+	}
+}
+
+// TestCollectProbedLinks tests the collectProbedLinks function.
+func TestCollectProbedLinks(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		endpointSliceImports []fleetnetv1alpha1.EndpointSliceImport
+		want                 []fleetnetv1alpha1.ProbedClusterNetworkLink
+	}{
+		{
+			name: "no probe results",
+			endpointSliceImports: []fleetnetv1alpha1.EndpointSliceImport{
+				*probedEndpointSliceImport(hubNSForMemberA, "app", clusterIDForMemberB, nil),
+			},
+		},
+		{
+			name: "probe results of multiple pairs",
+			endpointSliceImports: []fleetnetv1alpha1.EndpointSliceImport{
+				*probedEndpointSliceImport(hubNSForMemberB, "app", clusterIDForMemberA, &fleetnetv1alpha1.ReachabilityProbeResult{
+					SourceCluster: clusterIDForMemberB, Reachability: fleetnetv1alpha1.ClusterReachabilityGateway, ProbeTime: probeTime,
+				}),
+				*probedEndpointSliceImport(hubNSForMemberA, "app", clusterIDForMemberC, &fleetnetv1alpha1.ReachabilityProbeResult{
+					SourceCluster: clusterIDForMemberA, Reachability: fleetnetv1alpha1.ClusterReachabilityDirect, ProbeTime: probeTime,
+				}),
+				*probedEndpointSliceImport(hubNSForMemberA, "db", clusterIDForMemberB, &fleetnetv1alpha1.ReachabilityProbeResult{
+					SourceCluster: clusterIDForMemberA, Reachability: fleetnetv1alpha1.ClusterReachabilityDirect, ProbeTime: probeTime,
+				}),
+			},
+			want: []fleetnetv1alpha1.ProbedClusterNetworkLink{
+				probedLink(clusterIDForMemberA, clusterIDForMemberB, fleetnetv1alpha1.ClusterReachabilityDirect, probeTime),
+				probedLink(clusterIDForMemberA, clusterIDForMemberC, fleetnetv1alpha1.ClusterReachabilityDirect, probeTime),
+				probedLink(clusterIDForMemberB, clusterIDForMemberA, fleetnetv1alpha1.ClusterReachabilityGateway, probeTime),
+			},
+		},
+		{
+			name: "most recent result wins",
+			endpointSliceImports: []fleetnetv1alpha1.EndpointSliceImport{
+				*probedEndpointSliceImport(hubNSForMemberA, "app", clusterIDForMemberB, &fleetnetv1alpha1.ReachabilityProbeResult{
+					SourceCluster: clusterIDForMemberA, Reachability: fleetnetv1alpha1.ClusterReachabilityGateway, ProbeTime: lastProbeTime,
+				}),
+				*probedEndpointSliceImport(hubNSForMemberA, "db", clusterIDForMemberB, &fleetnetv1alpha1.ReachabilityProbeResult{
+					SourceCluster: clusterIDForMemberA, Reachability: fleetnetv1alpha1.ClusterReachabilityDirect, ProbeTime: probeTime,
+				}),
+			},
+			want: []fleetnetv1alpha1.ProbedClusterNetworkLink{
+				probedLink(clusterIDForMemberA, clusterIDForMemberB, fleetnetv1alpha1.ClusterReachabilityGateway, lastProbeTime),
+			},
+		},
+		{
+			name: "invalid probe results",
+			endpointSliceImports: []fleetnetv1alpha1.EndpointSliceImport{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace:   hubNSForMemberA,
+						Name:        "app",
+						Annotations: map[string]string{objectmeta.EndpointSliceImportAnnotationReachabilityProbe: "invalid"},
+					},
+				},
+				*probedEndpointSliceImport(hubNSForMemberA, "db", clusterIDForMemberB, &fleetnetv1alpha1.ReachabilityProbeResult{
+					SourceCluster: clusterIDForMemberA, Reachability: "Unknown", ProbeTime: probeTime,
+				}),
+				*probedEndpointSliceImport(hubNSForMemberA, "web", clusterIDForMemberB, &fleetnetv1alpha1.ReachabilityProbeResult{
+					Reachability: fleetnetv1alpha1.ClusterReachabilityDirect, ProbeTime: probeTime,
+				}),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := collectProbedLinks(tc.endpointSliceImports)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("collectProbedLinks() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestReconcile tests the Reconcile method.
+func TestReconcile(t *testing.T) {
+	probed := probedEndpointSliceImport(hubNSForMemberA, "app", clusterIDForMemberB, &fleetnetv1alpha1.ReachabilityProbeResult{
+		SourceCluster: clusterIDForMemberA, Reachability: fleetnetv1alpha1.ClusterReachabilityDirect, ProbeTime: probeTime,
+	})
+	wantLinks := []fleetnetv1alpha1.ProbedClusterNetworkLink{
+		probedLink(clusterIDForMemberA, clusterIDForMemberB, fleetnetv1alpha1.ClusterReachabilityDirect, probeTime),
+	}
+	declaredLinks := []fleetnetv1alpha1.ClusterNetworkLink{
+		{SourceCluster: clusterIDForMemberB, DestinationCluster: clusterIDForMemberA, Reachability: fleetnetv1alpha1.ClusterReachabilityGateway},
+	}
+
+	testCases := []struct {
+		name            string
+		objs            []client.Object
+		wantTopology    bool
+		wantLinks       []fleetnetv1alpha1.ClusterNetworkLink
+		wantProbedLinks []fleetnetv1alpha1.ProbedClusterNetworkLink
+	}{
+		{
+			name: "no topology and no probe results",
+			objs: []client.Object{probedEndpointSliceImport(hubNSForMemberA, "app", clusterIDForMemberB, nil)},
+		},
+		{
+			name:            "topology created to keep the probed links",
+			objs:            []client.Object{probed},
+			wantTopology:    true,
+			wantProbedLinks: wantLinks,
+		},
+		{
+			name: "probed links of existing topology updated",
+			objs: []client.Object{
+				probed,
+				&fleetnetv1alpha1.ClusterNetworkTopology{
+					ObjectMeta: metav1.ObjectMeta{Name: fleetnetv1alpha1.ClusterNetworkTopologyName},
+					Spec:       fleetnetv1alpha1.ClusterNetworkTopologySpec{Links: declaredLinks},
+					Status: fleetnetv1alpha1.ClusterNetworkTopologyStatus{
+						ProbedLinks: []fleetnetv1alpha1.ProbedClusterNetworkLink{
+							probedLink(clusterIDForMemberA, clusterIDForMemberC, fleetnetv1alpha1.ClusterReachabilityGateway, probeTime),
+						},
+					},
+				},
+			},
+			wantTopology:    true,
+			wantLinks:       declaredLinks,
+			wantProbedLinks: wantLinks,
+		},
+		{
+			name: "probed links of existing topology cleared",
+			objs: []client.Object{
+				&fleetnetv1alpha1.ClusterNetworkTopology{
+					ObjectMeta: metav1.ObjectMeta{Name: fleetnetv1alpha1.ClusterNetworkTopologyName},
+					Status:     fleetnetv1alpha1.ClusterNetworkTopologyStatus{ProbedLinks: wantLinks},
+				},
+			},
+			wantTopology: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(tc.objs...).
+				WithStatusSubresource(&fleetnetv1alpha1.ClusterNetworkTopology{}).
+				Build()
+			r := &Reconciler{Client: fakeClient}
+			if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: topologyKey}); err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+
+			topology := &fleetnetv1alpha1.ClusterNetworkTopology{}
+			err := fakeClient.Get(context.Background(), topologyKey, topology)
+			if gotTopology := err == nil; gotTopology != tc.wantTopology {
+				t.Fatalf("clusterNetworkTopology Get() = %v, want topology %t", err, tc.wantTopology)
+			}
+			if diff := cmp.Diff(tc.wantLinks, topology.Spec.Links); diff != "" {
+				t.Errorf("clusterNetworkTopology links mismatch (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantProbedLinks, topology.Status.ProbedLinks); diff != "" {
+				t.Errorf("clusterNetworkTopology probed links mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -11,28 +11,44 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 	"go.goms.io/fleet-networking/pkg/common/apiretry"
+	"go.goms.io/fleet-networking/pkg/common/apply"
+	"go.goms.io/fleet-networking/pkg/common/clusterhealth"
+	"go.goms.io/fleet-networking/pkg/common/clusterquarantine"
+	"go.goms.io/fleet-networking/pkg/common/exportapproval"
+	"go.goms.io/fleet-networking/pkg/common/exportquota"
+	"go.goms.io/fleet-networking/pkg/common/fieldindex"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
 	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/pause"
+	"go.goms.io/fleet-networking/pkg/common/staleexport"
+	"go.goms.io/fleet-networking/pkg/common/tenancy"
+	"go.goms.io/fleet-networking/pkg/common/tracing"
 )
 
 const (
 	endpointSliceExportCleanupFinalizer = "networking.fleet.azure.com/endpointsliceexport-cleanup"
 
 	endpointSliceImportNameFieldKey                   = ".metadata.name"
-	endpointSliceExportOwnerSvcNamespacedNameFieldKey = ".spec.ownerServiceReference.namespacedName"
+	endpointSliceExportOwnerSvcNamespacedNameFieldKey = fieldindex.EndpointSliceExportOwnerServiceNamespacedName
 
 	endpointSliceExportRetryInterval = time.Second * 5
 )
@@ -55,25 +71,82 @@ var (
 	}
 )
 
+var (
+	// endpointSliceExportHubDuration is a Prometheus histogram metric bundle that measures the time it takes for
+	// an EndpointSlice exported from a member cluster to reach the hub cluster. The stopwatch starts when an
+	// EndpointSlice is ready for export, and stops when the hub cluster picks up its EndpointSliceExport for
+	// distribution.
+	endpointSliceExportHubDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metrics.MetricsNamespace,
+			Subsystem: metrics.MetricsSubsystem,
+			Name:      "endpointslice_export_hub_duration_milliseconds",
+			Help:      "The duration of an endpointslice export reaching the hub cluster",
+			Buckets:   metrics.ExportDurationMillisecondsBuckets,
+		},
+		[]string{
+			// The ID of the origin cluster, which exports the Service and the EndpointSlice.
+			"origin_cluster_id",
+		},
+	)
+)
+
+func init() {
+	// Register endpointSliceExportHubDuration (endpointslice_export_hub_duration_milliseconds) metric
+	// with the controller runtime global metrics registry.
+	ctrlmetrics.Registry.MustRegister(endpointSliceExportHubDuration)
+}
+
 // Reconciler reconciles the distribution of EndpointSlices across the fleet.
 type Reconciler struct {
 	HubClient client.Client
+	// EnableClusterQuarantine enables the quarantine of member clusters by labeling their MemberClusters in the
+	// hub cluster; the EndpointSlices exported from a quarantined cluster are withdrawn from the fleet.
+	EnableClusterQuarantine bool
+	// EnableClusterFailover enables the failover of the unhealthy exports by the hub; the EndpointSlices exported
+	// along with an unhealthy export are withdrawn from the fleet.
+	EnableClusterFailover bool
+	// EnableClusterNetworkTopology enables the distribution of the EndpointSlices exported through an east-west
+	// gateway per the ClusterNetworkTopology of the fleet; otherwise they are always imported through the gateway.
+	EnableClusterNetworkTopology bool
+	// EnableStaleExportWithdrawal enables the withdrawal of the EndpointSlices exported along with a stale export,
+	// i.e. from a member cluster whose networking agent has stopped renewing its heartbeat.
+	EnableStaleExportWithdrawal bool
+	// EnableExportApproval enables the approval workflow of the exports; the EndpointSlices exported along with an
+	// export pending approval are withdrawn from the fleet.
+	EnableExportApproval bool
+	// EnforceExportQuotas enforces the ExportQuotas of the hub cluster; the EndpointSlices exported along with an
+	// export over quota are withdrawn from the fleet.
+	EnforceExportQuotas bool
+	// EnableTenantIsolation partitions the fleet by the tenants of the member clusters; the EndpointSlices exported
+	// along with an export in conflict with another tenant are withdrawn from the fleet.
+	EnableTenantIsolation bool
+	// ServerSideApply, if set, writes the EndpointSliceImports with server-side applies, so that the writes never
+	// conflict with the other writes of the EndpointSliceImports.
+	ServerSideApply bool
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
 }
 
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=endpointsliceexports,verbs=get;list;watch;create;update;patch
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=endpointsliceimports,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalserviceexports,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=clusternetworktopologies,verbs=get;list;watch
 //+kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;create;update;patch;delete;list;watch
 
 // Reconcile distributes an exported EndpointSlice (in the form of EndpointSliceExports) to whichever member
 // cluster that has imported the EndpointSlice's owner Service.
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	endpointSliceExportRef := klog.KRef(req.Namespace, req.Name)
+	logger := klog.FromContext(ctx).WithValues("endpointSliceExport", endpointSliceExportRef)
+	ctx = klog.NewContext(ctx, logger)
 	startTime := time.Now()
-	klog.V(2).InfoS("Reconciliation starts", "endpointSliceExport", endpointSliceExportRef)
+	logger.V(2).Info("Reconciliation starts")
 	defer func() {
 		latency := time.Since(startTime).Milliseconds()
-		klog.V(2).InfoS("Reconciliation ends", "endpointSliceExport", endpointSliceExportRef, "latency", latency)
+		logger.V(2).Info("Reconciliation ends", "latency", latency)
 	}()
 
 	// Retrieve the EndpointSliceExport object.
@@ -84,20 +157,24 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		// chance to reconcile it. The absence of the finalizer guarantees that the EndpointSlice has never been
 		// distributed across the fleet, thus no action is needed on this controller's side.
 		if errors.IsNotFound(err) {
-			klog.V(4).InfoS("Ignoring NotFound endpointSliceExport", "endpointSliceExport", endpointSliceExportRef)
+			logger.V(4).Info("Ignoring NotFound endpointSliceExport")
 			return ctrl.Result{}, nil
 		}
-		klog.ErrorS(err, "Failed to get endpointSliceExport", "endpointSliceExport", endpointSliceExportRef)
+		logger.Error(err, "Failed to get endpointSliceExport")
 		return ctrl.Result{}, err
 	}
 
+	// Continue the trace of the export, so that its distribution across the fleet is part of its journey.
+	ctx, span := tracing.Start(ctx, "endpointsliceexport.Distribute", endpointSliceExport.Annotations)
+	defer span.End()
+
 	// Check if the EndpointSliceExport has been marked for deletion; withdraw EndpointSliceImports across
 	// the fleet if the EndpointSlice has been distributed.
 	if endpointSliceExport.DeletionTimestamp != nil {
 		if controllerutil.ContainsFinalizer(endpointSliceExport, endpointSliceExportCleanupFinalizer) {
 			// The presence of the EndpointSliceExport cleanup finalizer guarantees that an attempt has been made
 			// to distribute the EndpointSlice.
-			klog.V(2).InfoS("EndpointSliceExport deleted; withdraw distributed EndpointSlices", "endpointSliceExport", endpointSliceExportRef)
+			logger.V(2).Info("EndpointSliceExport deleted; withdraw distributed EndpointSlices")
 			if err := r.withdrawAllEndpointSliceImports(ctx, endpointSliceExport); err != nil {
 				return ctrl.Result{}, err
 			}
@@ -106,16 +183,120 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return ctrl.Result{}, nil
 	}
 
-	// Inquire the corresponding ServiceImport to find out which member clusters the EndpointSlice should be
-	// distributed to.
+	if err := r.observeMetrics(ctx, endpointSliceExport, startTime); err != nil {
+		logger.Error(err, "Failed to observe metrics")
+		return ctrl.Result{}, err
+	}
+
+	if r.EnableClusterQuarantine {
+		clusterID := endpointSliceExport.Spec.EndpointSliceReference.ClusterID
+		quarantined, err := clusterquarantine.IsQuarantined(ctx, r.HubClient, clusterID)
+		if err != nil {
+			logger.Error(err, "Failed to check whether the member cluster is quarantined", "clusterID", clusterID)
+			return ctrl.Result{}, err
+		}
+		if quarantined {
+			// The EndpointSliceExport will be re-processed when the cluster is re-enabled, as its exports rejoin
+			// the ServiceImport.
+			logger.V(2).Info("Member cluster is quarantined; withdraw distributed EndpointSlices", "clusterID", clusterID)
+			if err := r.withdrawAllEndpointSliceImports(ctx, endpointSliceExport); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{}, nil
+		}
+	}
+
 	ownerSvcNS := endpointSliceExport.Spec.OwnerServiceReference.Namespace
 	ownerSvc := endpointSliceExport.Spec.OwnerServiceReference.Name
+	logger = logger.WithValues("service", klog.KRef(ownerSvcNS, ownerSvc))
+	ctx = klog.NewContext(ctx, logger)
+	if r.EnableStaleExportWithdrawal {
+		stale, err := staleexport.IsServiceStale(ctx, r.HubClient, endpointSliceExport.Namespace, ownerSvcNS, ownerSvc)
+		if err != nil {
+			logger.Error(err, "Failed to check whether the exported service is stale")
+			return ctrl.Result{}, err
+		}
+		if stale {
+			// The EndpointSliceExport will be re-processed when the member cluster reports again, as its exports
+			// rejoin the ServiceImport.
+			logger.V(2).Info("Exported service is stale; withdraw distributed EndpointSlices")
+			if err := r.withdrawAllEndpointSliceImports(ctx, endpointSliceExport); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{}, nil
+		}
+	}
+	if r.EnableTenantIsolation {
+		conflicted, err := tenancy.IsServiceConflicted(ctx, r.HubClient, endpointSliceExport.Namespace, ownerSvcNS, ownerSvc)
+		if err != nil {
+			logger.Error(err, "Failed to check whether the exported service is in conflict with another tenant")
+			return ctrl.Result{}, err
+		}
+		if conflicted {
+			// The EndpointSliceExport will be re-processed when the ServiceImport is released by the other tenant, as
+			// the export joins it.
+			logger.V(2).Info("Exported service is owned by another tenant; withdraw distributed EndpointSlices")
+			if err := r.withdrawAllEndpointSliceImports(ctx, endpointSliceExport); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{}, nil
+		}
+	}
+	if r.EnableExportApproval {
+		pending, err := exportapproval.IsServicePending(ctx, r.HubClient, endpointSliceExport.Namespace, ownerSvcNS, ownerSvc)
+		if err != nil {
+			logger.Error(err, "Failed to check whether the exported service is pending approval")
+			return ctrl.Result{}, err
+		}
+		if pending {
+			// The EndpointSliceExport will be re-processed when the export is approved, as it joins the
+			// ServiceImport.
+			logger.V(2).Info("Exported service is pending approval; withdraw distributed EndpointSlices")
+			if err := r.withdrawAllEndpointSliceImports(ctx, endpointSliceExport); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{}, nil
+		}
+	}
+	if r.EnforceExportQuotas {
+		overQuota, err := exportquota.IsServiceOverQuota(ctx, r.HubClient, endpointSliceExport.Namespace, ownerSvcNS, ownerSvc)
+		if err != nil {
+			logger.Error(err, "Failed to check whether the exported service is over quota")
+			return ctrl.Result{}, err
+		}
+		if overQuota {
+			// The EndpointSliceExport will be re-processed when the quota admits the export, as it joins the
+			// ServiceImport.
+			logger.V(2).Info("Exported service is over quota; withdraw distributed EndpointSlices")
+			if err := r.withdrawAllEndpointSliceImports(ctx, endpointSliceExport); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{}, nil
+		}
+	}
+	if r.EnableClusterFailover {
+		unhealthy, err := clusterhealth.IsServiceUnhealthy(ctx, r.HubClient, endpointSliceExport.Namespace, ownerSvcNS, ownerSvc)
+		if err != nil {
+			logger.Error(err, "Failed to check whether the exported service is unhealthy")
+			return ctrl.Result{}, err
+		}
+		if unhealthy {
+			// The EndpointSliceExport will be re-processed when the export recovers, as it rejoins the ServiceImport.
+			logger.V(2).Info("Exported service is failed over; withdraw distributed EndpointSlices")
+			if err := r.withdrawAllEndpointSliceImports(ctx, endpointSliceExport); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{}, nil
+		}
+	}
+
+	// Inquire the corresponding ServiceImport to find out which member clusters the EndpointSlice should be
+	// distributed to.
 	svcImportKey := types.NamespacedName{Namespace: ownerSvcNS, Name: ownerSvc}
 	svcImport := &fleetnetv1alpha1.ServiceImport{}
 	svcImportRef := klog.KRef(ownerSvcNS, ownerSvc)
-	klog.V(2).InfoS("Inquire ServceImport to find out which member clusters have requested the EndpointSlice",
-		"serviceImport", svcImportRef,
-		"endpointSliceExport", endpointSliceExportRef)
+	logger.V(2).Info("Inquire ServceImport to find out which member clusters have requested the EndpointSlice",
+		"serviceImport", svcImportRef)
 	err := r.HubClient.Get(ctx, svcImportKey, svcImport)
 	switch {
 	case err != nil && errors.IsNotFound(err):
@@ -124,19 +305,23 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		// observes some in-between state, such as a Service is deleted right after being exported successfully,
 		// and the system does not get to withdraw exported EndpointSlices from the Service yet. The controller
 		// will requeue the EndpointSliceExport and wait until the state stablizes.
-		klog.V(2).InfoS("ServiceImport does not exist", "serviceImport", svcImportRef, "endpointSliceExport", endpointSliceExportRef)
+		logger.V(2).Info("ServiceImport does not exist", "serviceImport", svcImportRef)
 		return ctrl.Result{RequeueAfter: endpointSliceExportRetryInterval}, nil
 	case err != nil:
 		// An unexpected error occurs.
-		klog.ErrorS(err, "Failed to get ServiceImport", "serviceImport", svcImportRef, "endpointSliceExport", endpointSliceExportRef)
+		logger.Error(err, "Failed to get ServiceImport", "serviceImport", svcImportRef)
 		return ctrl.Result{}, err
+	case pause.IsPaused(svcImport):
+		// The EndpointSlices distributed before are left as they are; the EndpointSliceExport will be re-processed
+		// when the reconciliation of the ServiceImport resumes.
+		logger.V(2).Info("Reconciliation of the ServiceImport is paused", "serviceImport", svcImportRef)
+		return ctrl.Result{}, nil
 	case len(svcImport.Status.Clusters) == 0:
 		// The corresponding ServiceImport exists but it is still being processed. This is also a case that
 		// should not happen in normal situations. The controller could be, once again, observing some in-between
 		// state. The EndpointSliceExport will be requeued and re-processed when the state stablizes.
-		klog.V(2).InfoS("ServiceImport is being processed (no accepted exports yet)",
-			"serviceImport", svcImportRef,
-			"endpointSliceExport", endpointSliceExportRef)
+		logger.V(2).Info("ServiceImport is being processed (no accepted exports yet)",
+			"serviceImport", svcImportRef)
 		return ctrl.Result{RequeueAfter: endpointSliceExportRetryInterval}, nil
 	}
 
@@ -145,9 +330,8 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		// No cluster has requested to import the EndpointSlice's owner service.
 		// If the exported EndpointSlice has been distributed across the fleet before; withdraw the
 		// EndpointSliceImports.
-		klog.V(2).InfoS("No cluster has requested to import the Service; withdraw distributed EndpointSlices",
-			"serviceImport", svcImportRef,
-			"endpointSliceExport", endpointSliceExportRef)
+		logger.V(2).Info("No cluster has requested to import the Service; withdraw distributed EndpointSlices",
+			"serviceImport", svcImportRef)
 		if err := r.withdrawAllEndpointSliceImports(ctx, endpointSliceExport); err != nil {
 			return ctrl.Result{}, err
 		}
@@ -158,9 +342,8 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 
 	svcInUseBy := &fleetnetv1alpha1.ServiceInUseBy{}
 	if err := json.Unmarshal([]byte(data), svcInUseBy); err != nil {
-		klog.ErrorS(err, "Failed to unmarshal data for in-use Services from ServiceImport annotations",
+		logger.Error(err, "Failed to unmarshal data for in-use Services from ServiceImport annotations",
 			"serviceImport", svcImportRef,
-			"endpointSliceExport", endpointSliceExportRef,
 			"data", data)
 		// This error cannot be recovered by retrying; a reconciliation will be triggered when the ServiceInUseBy
 		// data is overwritten.
@@ -172,21 +355,32 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	// Add cleanup finalizer to the EndpointSliceExport; this must happen before EndpointSlice is distributed.
 	if !controllerutil.ContainsFinalizer(endpointSliceExport, endpointSliceExportCleanupFinalizer) {
 		if err := r.addEndpointSliceExportCleanupFinalizer(ctx, endpointSliceExport); err != nil {
-			klog.ErrorS(err, "Failed to add cleanup finalizer to EndpointSliceExport", "endpointSliceExport", endpointSliceExportRef)
+			logger.Error(err, "Failed to add cleanup finalizer to EndpointSliceExport")
 			return ctrl.Result{}, err
 		}
 	}
 
+	// Collect the IDs of the member clusters that have requested the EndpointSlice before the scan consumes them.
+	importingClusterIDs := make(map[fleetnetv1alpha1.ClusterNamespace]fleetnetv1alpha1.ClusterID, len(svcInUseBy.MemberClusters))
+	for ns, clusterID := range svcInUseBy.MemberClusters {
+		importingClusterIDs[ns] = clusterID
+	}
+	topology, err := r.getClusterNetworkTopology(ctx, endpointSliceExport)
+	if err != nil {
+		logger.Error(err, "Failed to get the cluster network topology")
+		return ctrl.Result{}, err
+	}
+
 	// Scan for EndpointSlices to withdraw and EndpointSlices to create or update.
-	klog.V(2).InfoS("Scan for EndpointSliceImports to withdraw and to create/update",
+	logger.V(2).Info("Scan for EndpointSliceImports to withdraw and to create/update",
 		"serviceInUseBy", svcInUseBy,
 		"endpointSliceExport", endpointSliceExport)
 	endpointSliceImportsToWithdraw, endpointSlicesImportsToCreateOrUpdate, err := r.scanForEndpointSliceImports(ctx, endpointSliceExport, svcInUseBy)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
-	klog.V(4).InfoS("EndpointSliceImports to withdraw", "count", len(endpointSliceImportsToWithdraw))
-	klog.V(4).InfoS("EndpointSliceImports to create or update", "count", len(endpointSlicesImportsToCreateOrUpdate))
+	logger.V(4).Info("EndpointSliceImports to withdraw", "count", len(endpointSliceImportsToWithdraw))
+	logger.V(4).Info("EndpointSliceImports to create or update", "count", len(endpointSlicesImportsToCreateOrUpdate))
 
 	// Delete distributed EndpointSlices that are no longer needed.
 	//
@@ -200,15 +394,13 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		if endpointSliceImport.DeletionTimestamp != nil {
 			continue
 		}
-		klog.V(4).InfoS("Withdraw endpointSlice",
-			"endpointSliceImport", klog.KObj(endpointSliceImport),
-			"endpointSliceExport", endpointSliceExportRef)
+		logger.V(4).Info("Withdraw endpointSlice",
+			"endpointSliceImport", klog.KObj(endpointSliceImport))
 		if err := apiretry.Do(func() error {
 			return r.HubClient.Delete(ctx, endpointSliceImport)
 		}); err != nil && !errors.IsNotFound(err) {
-			klog.ErrorS(err, "Failed to withdraw EndpointSlice",
-				"endpointSliceImport", klog.KObj(endpointSliceImport),
-				"endpointSliceExport", endpointSliceExportRef)
+			logger.Error(err, "Failed to withdraw EndpointSlice",
+				"endpointSliceImport", klog.KObj(endpointSliceImport))
 			return ctrl.Result{}, err
 		}
 	}
@@ -221,22 +413,30 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	// imported to multiple clusters.
 	for idx := range endpointSlicesImportsToCreateOrUpdate {
 		endpointSliceImport := endpointSlicesImportsToCreateOrUpdate[idx]
-		klog.V(4).InfoS("Create/update endpointSliceImport",
+		clusterID := importingClusterIDs[fleetnetv1alpha1.ClusterNamespace(endpointSliceImport.Namespace)]
+		reachability := importReachability(endpointSliceExport, topology, string(clusterID))
+		logger.V(4).Info("Create/update endpointSliceImport",
 			"endpointSliceImport", klog.KObj(endpointSliceImport),
-			"endpointSliceExport", endpointSliceExportRef)
+			"reachability", reachability)
 
 		var op controllerutil.OperationResult
 		if err := apiretry.Do(func() error {
 			var createOrUpdateErr error
-			op, createOrUpdateErr = controllerutil.CreateOrUpdate(ctx, r.HubClient, endpointSliceImport, func() error {
+			op, createOrUpdateErr = r.createOrUpdateFunc()(ctx, r.HubClient, endpointSliceImport, func() error {
+				stampDistributedTimestamp(endpointSliceImport, endpointSliceExport, startTime)
+				// Only a new version of the import carries the correlation ID of the distribution.
+				if !equality.Semantic.DeepEqual(endpointSliceImport.Spec, endpointSliceExport.Spec) {
+					endpointSliceImport.Annotations = tracing.SetCorrelationID(ctx, endpointSliceImport.Annotations)
+				}
 				endpointSliceImport.Spec = *endpointSliceExport.Spec.DeepCopy()
+				setReachabilityAnnotation(endpointSliceImport, reachability)
+				setOriginAnnotation(endpointSliceImport, endpointSliceExport)
 				return nil
 			})
 			return createOrUpdateErr
 		}); err != nil {
-			klog.ErrorS(err, "Failed to create or update EndpointSliceImport",
+			logger.Error(err, "Failed to create or update EndpointSliceImport",
 				"endpointSliceImport", klog.KObj(endpointSliceImport),
-				"endpointSliceExport", endpointSliceExportRef,
 				"op", op)
 			return ctrl.Result{}, err
 		}
@@ -245,6 +445,21 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	return ctrl.Result{}, nil
 }
 
+// createOrUpdateFunc returns the function which writes the EndpointSliceImports to the hub cluster.
+func (r *Reconciler) createOrUpdateFunc() apply.CreateOrUpdateFunc {
+	if !r.ServerSideApply {
+		return controllerutil.CreateOrUpdate
+	}
+	return apply.CreateOrApply(apply.Owned{
+		Annotations: []string{
+			metrics.MetricsAnnotationDistributedTimestamp,
+			objectmeta.ExportedObjectAnnotationCorrelationID,
+			objectmeta.EndpointSliceImportAnnotationReachability,
+			objectmeta.ExportedObjectAnnotationOrigin,
+		},
+	})
+}
+
 // SetupWithManager sets up the EndpointSliceExport controller with a controller manager.
 func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
 	// Set up an index for efficient EndpointSliceImport lookup.
@@ -268,7 +483,7 @@ func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) err
 	}
 
 	// Enqueue EndpointSliceExports for processing when a ServiceImport changes.
-	eventHandlers := handler.EnqueueRequestsFromMapFunc(func(_ context.Context, o client.Object) []reconcile.Request {
+	eventHandlers := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, o client.Object) []reconcile.Request {
 		svcImport, ok := o.(*fleetnetv1alpha1.ServiceImport)
 		if !ok {
 			return []reconcile.Request{}
@@ -279,7 +494,7 @@ func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) err
 			endpointSliceExportOwnerSvcNamespacedNameFieldKey: fmt.Sprintf("%s/%s", svcImport.Namespace, svcImport.Name),
 		}
 		if err := r.HubClient.List(ctx, endpointSliceExportList, fieldMatcher); err != nil {
-			klog.ErrorS(err,
+			klog.FromContext(ctx).Error(err,
 				"Failed to list EndpointSliceExports for an imported Service",
 				"serviceImport", klog.KObj(svcImport))
 			return []reconcile.Request{}
@@ -297,23 +512,111 @@ func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) err
 		return reqs
 	})
 
-	return ctrl.NewControllerManagedBy(mgr).
+	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&fleetnetv1alpha1.EndpointSliceExport{}).
-		Watches(&fleetnetv1alpha1.ServiceImport{}, eventHandlers).
-		Complete(r)
+		Watches(&fleetnetv1alpha1.ServiceImport{}, eventHandlers)
+	if r.EnableClusterNetworkTopology {
+		// Enqueue the EndpointSliceExports exported through an east-west gateway when the topology changes.
+		builder = builder.Watches(&fleetnetv1alpha1.ClusterNetworkTopology{}, handler.EnqueueRequestsFromMapFunc(
+			func(ctx context.Context, o client.Object) []reconcile.Request {
+				if o.GetName() != fleetnetv1alpha1.ClusterNetworkTopologyName {
+					return []reconcile.Request{}
+				}
+				endpointSliceExportList := &fleetnetv1alpha1.EndpointSliceExportList{}
+				if err := r.HubClient.List(ctx, endpointSliceExportList); err != nil {
+					klog.FromContext(ctx).Error(err, "Failed to list EndpointSliceExports for the cluster network topology")
+					return []reconcile.Request{}
+				}
+				reqs := []reconcile.Request{}
+				for _, endpointSliceExport := range endpointSliceExportList.Items {
+					if endpointSliceExport.Spec.Gateway == nil {
+						continue
+					}
+					reqs = append(reqs, reconcile.Request{
+						NamespacedName: types.NamespacedName{
+							Namespace: endpointSliceExport.Namespace,
+							Name:      endpointSliceExport.Name,
+						},
+					})
+				}
+				return reqs
+			}))
+	}
+	return builder.WithOptions(r.ControllerOptions).Complete(metrics.InstrumentReconciler("endpointsliceexport", r))
+}
+
+// getClusterNetworkTopology returns the ClusterNetworkTopology of the fleet if the EndpointSliceExport is exported
+// through an east-west gateway; it returns nil if the topology is disabled or does not exist.
+func (r *Reconciler) getClusterNetworkTopology(ctx context.Context, endpointSliceExport *fleetnetv1alpha1.EndpointSliceExport) (*fleetnetv1alpha1.ClusterNetworkTopology, error) {
+	if !r.EnableClusterNetworkTopology || endpointSliceExport.Spec.Gateway == nil {
+		return nil, nil
+	}
+	topology := &fleetnetv1alpha1.ClusterNetworkTopology{}
+	if err := r.HubClient.Get(ctx, types.NamespacedName{Name: fleetnetv1alpha1.ClusterNetworkTopologyName}, topology); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return topology, nil
+}
+
+// importReachability returns how a member cluster reaches the EndpointSlice exported through an east-west gateway;
+// it is Gateway unless the topology declares or has probed the pair of clusters as Direct. It returns an empty
+// string if the EndpointSlice is not exported through a gateway.
+func importReachability(endpointSliceExport *fleetnetv1alpha1.EndpointSliceExport,
+	topology *fleetnetv1alpha1.ClusterNetworkTopology, importingClusterID string) fleetnetv1alpha1.ClusterReachability {
+	if endpointSliceExport.Spec.Gateway == nil {
+		return ""
+	}
+	if topology != nil {
+		reachability, ok := topology.Reachability(importingClusterID, endpointSliceExport.Spec.EndpointSliceReference.ClusterID)
+		if ok && reachability == fleetnetv1alpha1.ClusterReachabilityDirect {
+			return fleetnetv1alpha1.ClusterReachabilityDirect
+		}
+	}
+	return fleetnetv1alpha1.ClusterReachabilityGateway
+}
+
+// setReachabilityAnnotation sets the reachability annotation of an EndpointSliceImport, or removes it if the
+// reachability is empty.
+func setReachabilityAnnotation(endpointSliceImport *fleetnetv1alpha1.EndpointSliceImport, reachability fleetnetv1alpha1.ClusterReachability) {
+	if reachability == "" {
+		delete(endpointSliceImport.Annotations, objectmeta.EndpointSliceImportAnnotationReachability)
+		return
+	}
+	if endpointSliceImport.Annotations == nil {
+		endpointSliceImport.Annotations = map[string]string{}
+	}
+	endpointSliceImport.Annotations[objectmeta.EndpointSliceImportAnnotationReachability] = string(reachability)
+}
+
+// setOriginAnnotation copies the origin annotation of an EndpointSliceExport federated from another fleet to the
+// EndpointSliceImport it is distributed with, or removes it if the endpoints are exported from the fleet itself.
+func setOriginAnnotation(endpointSliceImport *fleetnetv1alpha1.EndpointSliceImport, endpointSliceExport *fleetnetv1alpha1.EndpointSliceExport) {
+	origin, ok := endpointSliceExport.Annotations[objectmeta.ExportedObjectAnnotationOrigin]
+	if !ok {
+		delete(endpointSliceImport.Annotations, objectmeta.ExportedObjectAnnotationOrigin)
+		return
+	}
+	if endpointSliceImport.Annotations == nil {
+		endpointSliceImport.Annotations = map[string]string{}
+	}
+	endpointSliceImport.Annotations[objectmeta.ExportedObjectAnnotationOrigin] = origin
 }
 
 // withdrawEndpointSliceImports withdraws EndpointSliceImports distributed across the fleet.
 func (r *Reconciler) withdrawAllEndpointSliceImports(ctx context.Context, endpointSliceExport *fleetnetv1alpha1.EndpointSliceExport) error {
+	// The logger of the reconciliation carries the endpointSliceExport.
+	logger := klog.FromContext(ctx)
 	// List all EndpointSlices distributed as EndpointSliceImports.
 	endpointSliceImportList := &fleetnetv1alpha1.EndpointSliceImportList{}
 	listOpts := client.MatchingFields{
 		endpointSliceImportNameFieldKey: endpointSliceExport.Name,
 	}
 	if err := r.HubClient.List(ctx, endpointSliceImportList, listOpts); err != nil {
-		klog.ErrorS(err, "Failed to list EndpointSliceImports by a specific name",
-			"endpointSliceImportName", endpointSliceExport.Name,
-			"endpointSliceExport", klog.KObj(endpointSliceExport))
+		logger.Error(err, "Failed to list EndpointSliceImports by a specific name",
+			"endpointSliceImportName", endpointSliceExport.Name)
 		return err
 	}
 
@@ -323,16 +626,15 @@ func (r *Reconciler) withdrawAllEndpointSliceImports(ctx context.Context, endpoi
 		if err := apiretry.Do(func() error {
 			return r.HubClient.Delete(ctx, &endpointSliceImport)
 		}); err != nil && !errors.IsNotFound(err) {
-			klog.ErrorS(err, "Failed to withdraw EndpointSliceImport",
-				"endpointSliceImport", klog.KObj(&endpointSliceImport),
-				"endpointSliceExport", klog.KObj(endpointSliceExport))
+			logger.Error(err, "Failed to withdraw EndpointSliceImport",
+				"endpointSliceImport", klog.KObj(&endpointSliceImport))
 			return err
 		}
 	}
 
 	// Remove the EndpointSliceExport cleanup finalizer.
 	if err := r.removeEndpointSliceExportCleanupFinalizer(ctx, endpointSliceExport); err != nil {
-		klog.ErrorS(err, "Failed to remove EndpointSliceImport cleanup finalizer", "endpointSliceExport", klog.KObj(endpointSliceExport))
+		logger.Error(err, "Failed to remove EndpointSliceImport cleanup finalizer")
 		return err
 	}
 	return nil
@@ -350,6 +652,54 @@ func (r *Reconciler) addEndpointSliceExportCleanupFinalizer(ctx context.Context,
 	return r.HubClient.Update(ctx, endpointSliceExport)
 }
 
+// observeMetrics observes a data point for the export to hub duration metric, once per generation of the exported
+// EndpointSlice.
+func (r *Reconciler) observeMetrics(ctx context.Context, endpointSliceExport *fleetnetv1alpha1.EndpointSliceExport, startTime time.Time) error {
+	endpointSliceRef := endpointSliceExport.Spec.EndpointSliceReference
+	currentGenerationStr := strconv.FormatInt(endpointSliceRef.Generation, 10)
+	if endpointSliceExport.Annotations[metrics.MetricsAnnotationLastObservedGeneration] == currentGenerationStr {
+		// A data point has been observed for this generation; skip the observation.
+		return nil
+	}
+
+	// Annotate the object to track the last observed generation; this must happen before the actual observation.
+	if endpointSliceExport.Annotations == nil {
+		endpointSliceExport.Annotations = map[string]string{}
+	}
+	endpointSliceExport.Annotations[metrics.MetricsAnnotationLastObservedGeneration] = currentGenerationStr
+	if err := r.HubClient.Update(ctx, endpointSliceExport); err != nil {
+		return err
+	}
+
+	// The logger of the reconciliation carries the endpointSliceExport.
+	logger := klog.FromContext(ctx)
+	if endpointSliceRef.ExportedSince.IsZero() {
+		logger.V(4).Info("exportedSince timestamp is absent; endpointSlice export to hub duration data point is not collected")
+		return nil
+	}
+	timeSpent := metrics.PropagationDurationMilliseconds(endpointSliceRef.ExportedSince.Time, startTime)
+	endpointSliceExportHubDuration.WithLabelValues(endpointSliceRef.ClusterID).Observe(float64(timeSpent))
+	logger.V(2).Info("endpointSliceExportHubDurationMilliseconds",
+		"value", timeSpent,
+		"originClusterID", endpointSliceRef.ClusterID)
+	return nil
+}
+
+// stampDistributedTimestamp annotates an EndpointSliceImport with the time the hub cluster distributes the current
+// generation of the exported EndpointSlice, so that the importing member cluster can measure how long the import
+// takes; the timestamp is kept when the same generation is distributed again.
+func stampDistributedTimestamp(endpointSliceImport *fleetnetv1alpha1.EndpointSliceImport,
+	endpointSliceExport *fleetnetv1alpha1.EndpointSliceExport, distributedTime time.Time) {
+	_, stamped := endpointSliceImport.Annotations[metrics.MetricsAnnotationDistributedTimestamp]
+	if stamped && endpointSliceImport.Spec.EndpointSliceReference.Generation == endpointSliceExport.Spec.EndpointSliceReference.Generation {
+		return
+	}
+	if endpointSliceImport.Annotations == nil {
+		endpointSliceImport.Annotations = map[string]string{}
+	}
+	endpointSliceImport.Annotations[metrics.MetricsAnnotationDistributedTimestamp] = distributedTime.Format(metrics.MetricsLastSeenTimestampFormat)
+}
+
 // scanForEndpointSliceImports lists all EndpointSliceImports across the fleet created from a specific
 // EndpointSliceExport, and matches them with the set of member clusters that have requested the EndpointSlice;
 // it returns
@@ -371,9 +721,8 @@ func (r *Reconciler) scanForEndpointSliceImports(
 		endpointSliceImportNameFieldKey: endpointSliceExport.Name,
 	}
 	if err := r.HubClient.List(ctx, endpointSliceImportList, listOpts); err != nil {
-		klog.ErrorS(err, "Failed to list EndpointSliceImports by a specific name",
-			"endpointSliceImportName", endpointSliceExport.Name,
-			"endpointSliceExport", klog.KObj(endpointSliceExport))
+		klog.FromContext(ctx).Error(err, "Failed to list EndpointSliceImports by a specific name",
+			"endpointSliceImportName", endpointSliceExport.Name)
 		return endpointSliceImportsToWithdraw, endpointSliceImportsToCreateOrUpdate, err
 	}
 
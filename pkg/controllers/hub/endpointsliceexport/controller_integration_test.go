@@ -80,7 +80,7 @@ func fulfillSvcImport(svcImport *fleetnetv1alpha1.ServiceImport) {
 				Port:        tcpPort,
 			},
 		},
-		Clusters: []fleetnetv1alpha1.ClusterStatus{
+		Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 			{
 				Cluster: clusterIDForMemberA,
 			},
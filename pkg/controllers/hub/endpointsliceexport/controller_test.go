@@ -15,6 +15,8 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	corev1 "k8s.io/api/core/v1"
 	discoveryv1 "k8s.io/api/discovery/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -23,6 +25,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
 )
 
 const (
@@ -437,3 +441,247 @@ func TestScanForEndpointSliceImports(t *testing.T) {
 		})
 	}
 }
+
+// TestImportReachability tests the importReachability function.
+func TestImportReachability(t *testing.T) {
+	gatewayEndpointSliceExport := ipv4EndpointSliceExport()
+	gatewayEndpointSliceExport.Spec.Gateway = &fleetnetv1alpha1.GatewayEndpoints{
+		Endpoints: []fleetnetv1alpha1.Endpoint{{Addresses: []string{altIPAddr}}},
+	}
+	exportingClusterID := gatewayEndpointSliceExport.Spec.EndpointSliceReference.ClusterID
+	topology := &fleetnetv1alpha1.ClusterNetworkTopology{
+		Spec: fleetnetv1alpha1.ClusterNetworkTopologySpec{
+			Links: []fleetnetv1alpha1.ClusterNetworkLink{
+				{SourceCluster: clusterIDForMemberB, DestinationCluster: exportingClusterID, Reachability: fleetnetv1alpha1.ClusterReachabilityGateway},
+			},
+		},
+		Status: fleetnetv1alpha1.ClusterNetworkTopologyStatus{
+			ProbedLinks: []fleetnetv1alpha1.ProbedClusterNetworkLink{
+				{ClusterNetworkLink: fleetnetv1alpha1.ClusterNetworkLink{
+					SourceCluster: clusterIDForMemberB, DestinationCluster: exportingClusterID, Reachability: fleetnetv1alpha1.ClusterReachabilityDirect,
+				}},
+				{ClusterNetworkLink: fleetnetv1alpha1.ClusterNetworkLink{
+					SourceCluster: clusterIDForMemberC, DestinationCluster: exportingClusterID, Reachability: fleetnetv1alpha1.ClusterReachabilityDirect,
+				}},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name                string
+		endpointSliceExport *fleetnetv1alpha1.EndpointSliceExport
+		topology            *fleetnetv1alpha1.ClusterNetworkTopology
+		importingClusterID  string
+		want                fleetnetv1alpha1.ClusterReachability
+	}{
+		{
+			name:                "not exported through a gateway",
+			endpointSliceExport: ipv4EndpointSliceExport(),
+			topology:            topology,
+			importingClusterID:  clusterIDForMemberC,
+			want:                "",
+		},
+		{
+			name:                "no topology",
+			endpointSliceExport: gatewayEndpointSliceExport,
+			importingClusterID:  clusterIDForMemberC,
+			want:                fleetnetv1alpha1.ClusterReachabilityGateway,
+		},
+		{
+			name:                "declared link takes precedence over the probed one",
+			endpointSliceExport: gatewayEndpointSliceExport,
+			topology:            topology,
+			importingClusterID:  clusterIDForMemberB,
+			want:                fleetnetv1alpha1.ClusterReachabilityGateway,
+		},
+		{
+			name:                "probed direct link",
+			endpointSliceExport: gatewayEndpointSliceExport,
+			topology:            topology,
+			importingClusterID:  clusterIDForMemberC,
+			want:                fleetnetv1alpha1.ClusterReachabilityDirect,
+		},
+		{
+			name:                "pair not in the topology",
+			endpointSliceExport: gatewayEndpointSliceExport,
+			topology:            topology,
+			importingClusterID:  "unknown",
+			want:                fleetnetv1alpha1.ClusterReachabilityGateway,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := importReachability(tc.endpointSliceExport, tc.topology, tc.importingClusterID); got != tc.want {
+				t.Errorf("importReachability() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestSetOriginAnnotation tests the setOriginAnnotation function.
+func TestSetOriginAnnotation(t *testing.T) {
+	testCases := []struct {
+		name              string
+		exportAnnotations map[string]string
+		importAnnotations map[string]string
+		want              map[string]string
+	}{
+		{
+			name:              "exported from the fleet itself",
+			exportAnnotations: map[string]string{"app": "web"},
+			importAnnotations: map[string]string{"app": "web"},
+			want:              map[string]string{"app": "web"},
+		},
+		{
+			name:              "federated from another fleet",
+			exportAnnotations: map[string]string{objectmeta.ExportedObjectAnnotationOrigin: "fleet-west/member-1"},
+			want:              map[string]string{objectmeta.ExportedObjectAnnotationOrigin: "fleet-west/member-1"},
+		},
+		{
+			name:              "no longer federated",
+			importAnnotations: map[string]string{objectmeta.ExportedObjectAnnotationOrigin: "fleet-west/member-1"},
+			want:              map[string]string{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			endpointSliceExport := ipv4EndpointSliceExport()
+			endpointSliceExport.Annotations = tc.exportAnnotations
+			endpointSliceImport := &fleetnetv1alpha1.EndpointSliceImport{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tc.importAnnotations},
+			}
+			setOriginAnnotation(endpointSliceImport, endpointSliceExport)
+			if diff := cmp.Diff(tc.want, endpointSliceImport.Annotations, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("setOriginAnnotation() annotations mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestObserveMetrics tests the Reconciler.observeMetrics method.
+func TestObserveMetrics(t *testing.T) {
+	startTime := time.Now().Round(time.Second)
+
+	testCases := []struct {
+		name                string
+		annotations         map[string]string
+		exportedSince       time.Time
+		wantObservations    uint64
+		wantObservedSumDiff float64
+	}{
+		{
+			name: "should not observe data point (no exportedSince field)",
+		},
+		{
+			name:          "should not observe data point (the object generation has been observed before)",
+			annotations:   map[string]string{metrics.MetricsAnnotationLastObservedGeneration: "1"},
+			exportedSince: startTime.Add(-time.Second * 3),
+		},
+		{
+			name:                "should observe a data point",
+			exportedSince:       startTime.Add(-time.Second * 3),
+			wantObservations:    1,
+			wantObservedSumDiff: 3000,
+		},
+		{
+			name:                "should observe a data point (negative export duration)",
+			annotations:         map[string]string{metrics.MetricsAnnotationLastObservedGeneration: "0"},
+			exportedSince:       startTime.Add(time.Second * 2),
+			wantObservations:    1,
+			wantObservedSumDiff: 1000,
+		},
+	}
+
+	ctx := context.Background()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			endpointSliceExport := ipv4EndpointSliceExport()
+			endpointSliceExport.Annotations = tc.annotations
+			endpointSliceExport.Spec.EndpointSliceReference.ExportedSince = metav1.NewTime(tc.exportedSince)
+			if tc.exportedSince.IsZero() {
+				endpointSliceExport.Spec.EndpointSliceReference.ExportedSince = metav1.Time{}
+			}
+			fakeHubClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(endpointSliceExport).
+				Build()
+			reconciler := Reconciler{
+				HubClient: fakeHubClient,
+			}
+
+			countBefore, sumBefore := observedHistogram(t)
+			if err := reconciler.observeMetrics(ctx, endpointSliceExport, startTime); err != nil {
+				t.Fatalf("observeMetrics(%+v), got %v, want no error", endpointSliceExport, err)
+			}
+
+			got := &fleetnetv1alpha1.EndpointSliceExport{}
+			if err := fakeHubClient.Get(ctx, endpointSliceExportKey, got); err != nil {
+				t.Fatalf("endpointSliceExport Get(%+v), got %v, want no error", endpointSliceExportKey, err)
+			}
+			if lastObservedGeneration := got.Annotations[metrics.MetricsAnnotationLastObservedGeneration]; lastObservedGeneration != "1" {
+				t.Fatalf("lastObservedGeneration, got %s, want 1", lastObservedGeneration)
+			}
+			count, sum := observedHistogram(t)
+			if got := count - countBefore; got != tc.wantObservations {
+				t.Errorf("observations, got %d, want %d", got, tc.wantObservations)
+			}
+			if got := sum - sumBefore; got != tc.wantObservedSumDiff {
+				t.Errorf("observed duration, got %v, want %v", got, tc.wantObservedSumDiff)
+			}
+		})
+	}
+}
+
+// observedHistogram returns the count and the sum of the observed export to hub durations of member cluster A.
+func observedHistogram(t *testing.T) (uint64, float64) {
+	m := &dto.Metric{}
+	if err := endpointSliceExportHubDuration.WithLabelValues(hubNSForMemberA).(prometheus.Histogram).Write(m); err != nil {
+		t.Fatalf("Write(), got %v, want no error", err)
+	}
+	return m.GetHistogram().GetSampleCount(), m.GetHistogram().GetSampleSum()
+}
+
+// TestStampDistributedTimestamp tests the stampDistributedTimestamp function.
+func TestStampDistributedTimestamp(t *testing.T) {
+	distributedTime := time.Now().Round(time.Second)
+	earlierTimestamp := distributedTime.Add(-time.Minute).Format(metrics.MetricsLastSeenTimestampFormat)
+
+	testCases := []struct {
+		name             string
+		annotations      map[string]string
+		importGeneration int64
+		want             string
+	}{
+		{
+			name: "new import",
+			want: distributedTime.Format(metrics.MetricsLastSeenTimestampFormat),
+		},
+		{
+			name:             "same generation distributed again",
+			annotations:      map[string]string{metrics.MetricsAnnotationDistributedTimestamp: earlierTimestamp},
+			importGeneration: 1,
+			want:             earlierTimestamp,
+		},
+		{
+			name:             "new generation",
+			annotations:      map[string]string{metrics.MetricsAnnotationDistributedTimestamp: earlierTimestamp},
+			importGeneration: 0,
+			want:             distributedTime.Format(metrics.MetricsLastSeenTimestampFormat),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			endpointSliceImport := &fleetnetv1alpha1.EndpointSliceImport{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations},
+				Spec: fleetnetv1alpha1.EndpointSliceExportSpec{
+					EndpointSliceReference: fleetnetv1alpha1.ExportedObjectReference{Generation: tc.importGeneration},
+				},
+			}
+			stampDistributedTimestamp(endpointSliceImport, ipv4EndpointSliceExport(), distributedTime)
+			if got := endpointSliceImport.Annotations[metrics.MetricsAnnotationDistributedTimestamp]; got != tc.want {
+				t.Errorf("distributed timestamp, got %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
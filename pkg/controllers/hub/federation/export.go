@@ -0,0 +1,281 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package federation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/hubconfig"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/uniquename"
+)
+
+// ExportReconciler re-exports the selected ServiceImports of the fleet, and the endpoints of the member clusters
+// backing them, to the upstream hub cluster.
+type ExportReconciler struct {
+	// HubClient is the client of the hub cluster of the fleet.
+	HubClient client.Client
+	// UpstreamClient is the client of the upstream hub cluster.
+	UpstreamClient client.Client
+	// FleetName is the ID of the fleet as a member cluster of the upstream fleet.
+	FleetName string
+	// UpstreamNamespace is the namespace reserved for the fleet in the upstream hub cluster.
+	UpstreamNamespace string
+	// UpstreamClusterID is the ID of the virtual member cluster of the fleet which the services of the upstream fleet
+	// are imported from; its exports are never re-exported.
+	UpstreamClusterID string
+	// Selector selects the ServiceImports to re-export by the labels exported with them.
+	Selector labels.Selector
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
+}
+
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=endpointsliceexports,verbs=get;list;watch
+
+// Reconcile re-exports a ServiceImport of the fleet to the upstream hub cluster, or withdraws it if it is no longer
+// re-exported.
+func (r *ExportReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	svcImportRef := klog.KRef(req.Namespace, req.Name)
+	startTime := time.Now()
+	klog.V(2).InfoS("Reconciliation starts", "serviceImport", svcImportRef)
+	defer func() {
+		latency := time.Since(startTime).Milliseconds()
+		klog.V(2).InfoS("Reconciliation ends", "serviceImport", svcImportRef, "latency", latency)
+	}()
+
+	svcImport := &fleetnetv1alpha1.ServiceImport{}
+	if err := r.HubClient.Get(ctx, req.NamespacedName, svcImport); err != nil {
+		if !apierrors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to get serviceImport", "serviceImport", svcImportRef)
+			return ctrl.Result{}, err
+		}
+		klog.V(2).InfoS("ServiceImport is not found; withdraw it from the upstream fleet", "serviceImport", svcImportRef)
+		return ctrl.Result{}, r.withdraw(ctx, req.NamespacedName)
+	}
+	if reason := r.skipReason(svcImport); reason != "" {
+		klog.V(2).InfoS("ServiceImport is not re-exported; withdraw it from the upstream fleet", "serviceImport", svcImportRef, "reason", reason)
+		return ctrl.Result{}, r.withdraw(ctx, req.NamespacedName)
+	}
+
+	if err := r.exportService(ctx, svcImport); err != nil {
+		klog.ErrorS(err, "Failed to re-export the service to the upstream fleet", "serviceImport", svcImportRef)
+		return ctrl.Result{}, err
+	}
+	if err := r.exportEndpointSlices(ctx, svcImport); err != nil {
+		klog.ErrorS(err, "Failed to re-export the endpoint slices to the upstream fleet", "serviceImport", svcImportRef)
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// skipReason returns why the ServiceImport is not re-exported to the upstream fleet, or an empty string if it is.
+func (r *ExportReconciler) skipReason(svcImport *fleetnetv1alpha1.ServiceImport) string {
+	switch {
+	case svcImport.DeletionTimestamp != nil:
+		return "the serviceImport is being deleted"
+	case !r.Selector.Matches(labels.Set(svcImport.Status.ExportedLabels)):
+		return "the serviceImport is not selected"
+	case len(svcImport.Status.Ports) == 0:
+		return "the serviceImport is not resolved yet"
+	case len(svcImport.Status.AllowedConsumers) > 0:
+		// The restrictions select the member clusters of the fleet, which have no meaning in the upstream fleet.
+		return "the exporting clusters restrict the consumers of the service"
+	case len(r.contributingClusters(svcImport)) == 0:
+		return "the service is only imported from the upstream fleet"
+	}
+	return ""
+}
+
+// contributingClusters returns the member clusters of the fleet contributing to the ServiceImport, leaving the
+// upstream fleet out.
+func (r *ExportReconciler) contributingClusters(svcImport *fleetnetv1alpha1.ServiceImport) map[string]bool {
+	clusters := make(map[string]bool, len(svcImport.Status.Clusters))
+	for _, cluster := range svcImport.Status.Clusters {
+		if cluster.Cluster != r.UpstreamClusterID {
+			clusters[cluster.Cluster] = true
+		}
+	}
+	return clusters
+}
+
+// exportService creates or updates the InternalServiceExport of the ServiceImport in the upstream hub cluster.
+func (r *ExportReconciler) exportService(ctx context.Context, svcImport *fleetnetv1alpha1.ServiceImport) error {
+	internalSvcExport := &fleetnetv1alpha1.InternalServiceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: r.UpstreamNamespace,
+			Name:      uniquename.ClusterScopedDeterministicName(svcImport.Namespace, svcImport.Name),
+		},
+	}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.UpstreamClient, internalSvcExport, func() error {
+		spec := federatedServiceSpec(&svcImport.Status)
+		spec.ServiceReference = internalSvcExport.Spec.ServiceReference
+		switch {
+		case internalSvcExport.CreationTimestamp.IsZero():
+			spec.ServiceReference = fleetnetv1alpha1.FromMetaObjects(r.FleetName, serviceImportTypeMeta, svcImport.ObjectMeta, metav1.Now())
+		case !equality.Semantic.DeepEqual(&internalSvcExport.Spec, &spec):
+			// The reference only moves along with the exported spec; the status of the ServiceImport changes far
+			// more often, e.g. as its endpoints are distributed, which must not ripple across the fleets.
+			spec.ServiceReference.UpdateFromMetaObject(svcImport.ObjectMeta, metav1.Now())
+		}
+		internalSvcExport.Spec = spec
+
+		if internalSvcExport.Labels == nil {
+			internalSvcExport.Labels = map[string]string{}
+		}
+		internalSvcExport.Labels[objectmeta.InternalServiceExportLabelServiceNamespace] = svcImport.Namespace
+		internalSvcExport.Labels[objectmeta.InternalServiceExportLabelServiceName] = svcImport.Name
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	klog.V(2).InfoS("Re-exported the service to the upstream fleet", "internalServiceExport", klog.KObj(internalSvcExport), "op", op)
+	return nil
+}
+
+// exportEndpointSlices mirrors the EndpointSliceExports of the member clusters contributing to the ServiceImport to
+// the upstream hub cluster, and deletes the mirrors of the others.
+func (r *ExportReconciler) exportEndpointSlices(ctx context.Context, svcImport *fleetnetv1alpha1.ServiceImport) error {
+	endpointSliceExportList := &fleetnetv1alpha1.EndpointSliceExportList{}
+	svcKey := types.NamespacedName{Namespace: svcImport.Namespace, Name: svcImport.Name}.String()
+	if err := r.HubClient.List(ctx, endpointSliceExportList, client.MatchingFields{endpointSliceExportOwnerSvcNamespacedNameFieldKey: svcKey}); err != nil {
+		return err
+	}
+
+	clusters := r.contributingClusters(svcImport)
+	upstreamClusterNamespace := fmt.Sprintf(hubconfig.HubNamespaceNameFormat, r.UpstreamClusterID)
+	exported := make(map[string]bool, len(endpointSliceExportList.Items))
+	for i := range endpointSliceExportList.Items {
+		endpointSliceExport := &endpointSliceExportList.Items[i]
+		origin := originOf(endpointSliceExport, &endpointSliceExport.Spec)
+		switch {
+		case endpointSliceExport.DeletionTimestamp != nil:
+			continue
+		case endpointSliceExport.Namespace == upstreamClusterNamespace || hasTraversed(origin, r.FleetName):
+			// The endpoints imported from the upstream fleet never go back.
+			continue
+		case !clusters[endpointSliceExport.Spec.EndpointSliceReference.ClusterID]:
+			// The endpoints of the exports excluded from the ServiceImport, e.g. in conflict, are not re-exported.
+			continue
+		}
+
+		mirror := &fleetnetv1alpha1.EndpointSliceExport{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: r.UpstreamNamespace,
+				Name:      endpointSliceExport.Name,
+			},
+		}
+		op, err := controllerutil.CreateOrUpdate(ctx, r.UpstreamClient, mirror, func() error {
+			mirror.Spec = federatedEndpointSliceSpec(&endpointSliceExport.Spec, r.FleetName)
+			setOwnerServiceLabels(mirror, svcImport.Namespace, svcImport.Name)
+			setOrigin(mirror, r.FleetName+originSeparator+origin)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		klog.V(4).InfoS("Re-exported the endpoint slice to the upstream fleet",
+			"endpointSliceExport", klog.KObj(endpointSliceExport), "mirror", klog.KObj(mirror), "op", op)
+		exported[mirror.Name] = true
+	}
+	return r.deleteEndpointSliceMirrors(ctx, svcImport.Namespace, svcImport.Name, exported)
+}
+
+// withdraw deletes the InternalServiceExport and EndpointSliceExports of the Service from the upstream hub cluster.
+func (r *ExportReconciler) withdraw(ctx context.Context, svcKey types.NamespacedName) error {
+	internalSvcExport := &fleetnetv1alpha1.InternalServiceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: r.UpstreamNamespace,
+			Name:      uniquename.ClusterScopedDeterministicName(svcKey.Namespace, svcKey.Name),
+		},
+	}
+	if err := r.UpstreamClient.Delete(ctx, internalSvcExport); err != nil && !apierrors.IsNotFound(err) {
+		klog.ErrorS(err, "Failed to withdraw the service from the upstream fleet", "internalServiceExport", klog.KObj(internalSvcExport))
+		return err
+	}
+	return r.deleteEndpointSliceMirrors(ctx, svcKey.Namespace, svcKey.Name, nil)
+}
+
+// deleteEndpointSliceMirrors deletes the EndpointSliceExports of the Service in the upstream hub cluster, except
+// for the ones to keep.
+func (r *ExportReconciler) deleteEndpointSliceMirrors(ctx context.Context, svcNamespace, svcName string, keep map[string]bool) error {
+	mirrorList := &fleetnetv1alpha1.EndpointSliceExportList{}
+	if err := r.UpstreamClient.List(ctx, mirrorList, client.InNamespace(r.UpstreamNamespace), ownerServiceLabelSelector(svcNamespace, svcName)); err != nil {
+		return err
+	}
+	for i := range mirrorList.Items {
+		mirror := &mirrorList.Items[i]
+		if keep[mirror.Name] {
+			continue
+		}
+		klog.V(4).InfoS("Withdraw the endpoint slice from the upstream fleet", "endpointSliceExport", klog.KObj(mirror))
+		if err := r.UpstreamClient.Delete(ctx, mirror); err != nil && !apierrors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to withdraw the endpoint slice from the upstream fleet", "endpointSliceExport", klog.KObj(mirror))
+			return err
+		}
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager; the objects of the upstream hub cluster are watched
+// through the given cluster. The index of the EndpointSliceExports by service is shared with the other hub
+// controllers; it is only created here if endpointSliceExportIndexed is false.
+func (r *ExportReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, upstream cluster.Cluster, endpointSliceExportIndexed bool) error {
+	if !endpointSliceExportIndexed {
+		extractFunc := func(o client.Object) []string {
+			return []string{o.(*fleetnetv1alpha1.EndpointSliceExport).Spec.OwnerServiceReference.NamespacedName}
+		}
+		if err := mgr.GetFieldIndexer().IndexField(ctx, &fleetnetv1alpha1.EndpointSliceExport{}, endpointSliceExportOwnerSvcNamespacedNameFieldKey, extractFunc); err != nil {
+			klog.ErrorS(err, "Failed to create index", "field", endpointSliceExportOwnerSvcNamespacedNameFieldKey)
+			return err
+		}
+	}
+
+	upstreamClusterNamespace := fmt.Sprintf(hubconfig.HubNamespaceNameFormat, r.UpstreamClusterID)
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(ExportControllerName).
+		For(&fleetnetv1alpha1.ServiceImport{}).
+		// Re-export the endpoints of the member clusters as they change.
+		Watches(&fleetnetv1alpha1.EndpointSliceExport{}, handler.EnqueueRequestsFromMapFunc(func(_ context.Context, o client.Object) []reconcile.Request {
+			endpointSliceExport := o.(*fleetnetv1alpha1.EndpointSliceExport)
+			if endpointSliceExport.Namespace == upstreamClusterNamespace {
+				return nil
+			}
+			return ownerServiceRequest(&endpointSliceExport.Spec)
+		})).
+		// Restore the exports deleted from the upstream hub cluster out-of-band.
+		WatchesRawSource(source.Kind(upstream.GetCache(), &fleetnetv1alpha1.InternalServiceExport{},
+			handler.TypedEnqueueRequestsFromMapFunc(func(_ context.Context, o *fleetnetv1alpha1.InternalServiceExport) []reconcile.Request {
+				return labeledServiceRequest(o, objectmeta.InternalServiceExportLabelServiceNamespace, objectmeta.InternalServiceExportLabelServiceName)
+			}))).
+		WatchesRawSource(source.Kind(upstream.GetCache(), &fleetnetv1alpha1.EndpointSliceExport{},
+			handler.TypedEnqueueRequestsFromMapFunc(func(_ context.Context, o *fleetnetv1alpha1.EndpointSliceExport) []reconcile.Request {
+				return labeledServiceRequest(o, objectmeta.EndpointSliceExportLabelOwnerServiceNamespace, objectmeta.EndpointSliceExportLabelOwnerServiceName)
+			}))).
+		WithOptions(r.ControllerOptions).
+		Complete(metrics.InstrumentReconciler("federationexport", r))
+}
@@ -0,0 +1,144 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package federation features hierarchical fleets, in which the hub cluster of a fleet joins the hub cluster of an
+// upstream fleet as one of its member clusters, so that very large organizations can federate their regional fleets.
+//
+// The export controller re-exports the ServiceImports of the fleet selected by the labels exported with them to the
+// upstream hub cluster, as if they were Services exported from a member cluster named after the fleet, along with
+// the EndpointSliceExports of the member clusters backing them. The import controller imports the ServiceImports of
+// the upstream hub cluster selected the same way, i.e. the services of the peer fleets, as if they were exported
+// from a virtual member cluster of the fleet representing the upstream fleet.
+//
+// The services are prevented from looping across the fleets in two ways: the exports of the virtual member cluster
+// are never re-exported to the upstream fleet, and the endpoints which have traversed the fleet, as tracked by their
+// origin annotation, are never imported back; the names of the fleets must be unique across the hierarchy.
+package federation
+
+import (
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/fieldindex"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+const (
+	// ExportControllerName is the name of the ExportReconciler.
+	ExportControllerName = "federationexport-controller"
+	// ImportControllerName is the name of the ImportReconciler.
+	ImportControllerName = "federationimport-controller"
+
+	// endpointSliceExportOwnerSvcNamespacedNameFieldKey is the index of the EndpointSliceExports by their owner
+	// Service, shared with the other hub controllers.
+	endpointSliceExportOwnerSvcNamespacedNameFieldKey = fieldindex.EndpointSliceExportOwnerServiceNamespacedName
+
+	originSeparator = "/"
+)
+
+// serviceImportTypeMeta is the type of the ServiceImports referenced by the federated exports.
+var serviceImportTypeMeta = metav1.TypeMeta{
+	APIVersion: fleetnetv1alpha1.GroupVersion.String(),
+	Kind:       "ServiceImport",
+}
+
+// originOf returns the origin of the endpoints of an EndpointSliceExport or EndpointSliceImport, which is the
+// member cluster exporting them unless they are federated from another fleet.
+func originOf(obj client.Object, spec *fleetnetv1alpha1.EndpointSliceExportSpec) string {
+	if origin, ok := obj.GetAnnotations()[objectmeta.ExportedObjectAnnotationOrigin]; ok && origin != "" {
+		return origin
+	}
+	return spec.EndpointSliceReference.ClusterID
+}
+
+// hasTraversed returns true if the endpoints of the origin have traversed the fleet, or originate from it.
+func hasTraversed(origin, fleetName string) bool {
+	return slices.Contains(strings.Split(origin, originSeparator), fleetName)
+}
+
+// federatedServiceSpec returns the spec of the export of a ServiceImport of the given status to another fleet; the
+// reference to the ServiceImport is left to the caller.
+func federatedServiceSpec(status *fleetnetv1alpha1.ServiceImportStatus) fleetnetv1alpha1.InternalServiceExportSpec {
+	spec := fleetnetv1alpha1.InternalServiceExportSpec{
+		Ports:                  status.Ports,
+		Type:                   corev1.ServiceTypeClusterIP,
+		Headless:               status.Type == fleetnetv1alpha1.Headless,
+		SessionAffinity:        status.SessionAffinity,
+		DNSTTLSeconds:          status.DNSTTLSeconds,
+		HealthCheckAnnotations: status.HealthCheckAnnotations,
+		ExportedLabels:         status.ExportedLabels,
+		ExportedAnnotations:    status.ExportedAnnotations,
+	}
+	if config := status.SessionAffinityConfig; config != nil && config.ClientIP != nil {
+		spec.SessionAffinityTimeoutSeconds = config.ClientIP.TimeoutSeconds
+	}
+	// The spec must not share the slices and maps of the status.
+	return *spec.DeepCopy()
+}
+
+// federatedEndpointSliceSpec returns the spec of the EndpointSliceExport federating the endpoints of the given spec to
+// another fleet, as exported from the given cluster; the Azure Private Link Services are not federated, as the
+// Private Endpoints connected to them are only created in the member clusters of the fleet.
+func federatedEndpointSliceSpec(spec *fleetnetv1alpha1.EndpointSliceExportSpec, clusterID string) fleetnetv1alpha1.EndpointSliceExportSpec {
+	federated := spec.DeepCopy()
+	federated.PrivateLink = nil
+	federated.EndpointSliceReference.ClusterID = clusterID
+	return *federated
+}
+
+// setOwnerServiceLabels labels a federated EndpointSliceExport with its owner Service, so that the EndpointSliceExports
+// of a Service are found without an index.
+func setOwnerServiceLabels(obj client.Object, svcNamespace, svcName string) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[objectmeta.EndpointSliceExportLabelOwnerServiceNamespace] = svcNamespace
+	labels[objectmeta.EndpointSliceExportLabelOwnerServiceName] = svcName
+	obj.SetLabels(labels)
+}
+
+// setOrigin sets the origin annotation of a federated EndpointSliceExport.
+func setOrigin(obj client.Object, origin string) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[objectmeta.ExportedObjectAnnotationOrigin] = origin
+	obj.SetAnnotations(annotations)
+}
+
+// ownerServiceLabelSelector selects the federated EndpointSliceExports of a Service.
+func ownerServiceLabelSelector(svcNamespace, svcName string) client.MatchingLabels {
+	return client.MatchingLabels{
+		objectmeta.EndpointSliceExportLabelOwnerServiceNamespace: svcNamespace,
+		objectmeta.EndpointSliceExportLabelOwnerServiceName:      svcName,
+	}
+}
+
+// ownerServiceRequest returns the request of the Service owning an EndpointSliceExport or EndpointSliceImport.
+func ownerServiceRequest(spec *fleetnetv1alpha1.EndpointSliceExportSpec) []reconcile.Request {
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{
+		Namespace: spec.OwnerServiceReference.Namespace,
+		Name:      spec.OwnerServiceReference.Name,
+	}}}
+}
+
+// labeledServiceRequest returns the request of the Service a federated object is labeled with.
+func labeledServiceRequest(obj client.Object, namespaceKey, nameKey string) []reconcile.Request {
+	labels := obj.GetLabels()
+	namespace, name := labels[namespaceKey], labels[nameKey]
+	if namespace == "" || name == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}}}
+}
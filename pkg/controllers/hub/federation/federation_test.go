@@ -0,0 +1,413 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package federation
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/hubconfig"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/uniquename"
+)
+
+const (
+	testFleetName         = "fleet-west"
+	testPeerFleetName     = "fleet-east"
+	testUpstreamClusterID = "upstream"
+	testUpstreamNamespace = "fleet-member-fleet-west"
+	testMemberClusterID   = "member-1"
+	testSvcNamespace      = "work"
+	testSvcName           = "app"
+	testFederatedLabel    = "networking.fleet.azure.com/federated"
+)
+
+var (
+	testSvcKey               = types.NamespacedName{Namespace: testSvcNamespace, Name: testSvcName}
+	testMemberNamespace      = fmt.Sprintf(hubconfig.HubNamespaceNameFormat, testMemberClusterID)
+	testUpstreamClusterNS    = fmt.Sprintf(hubconfig.HubNamespaceNameFormat, testUpstreamClusterID)
+	testInternalSvcObjName   = uniquename.ClusterScopedDeterministicName(testSvcNamespace, testSvcName)
+	testFederatedLabels      = map[string]string{testFederatedLabel: "true"}
+	testSelector             = labels.SelectorFromSet(testFederatedLabels)
+	testServicePorts         = []fleetnetv1alpha1.ServicePort{{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80}}
+	testEndpointSliceAddress = []string{"10.0.0.1"}
+)
+
+func testScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	return scheme
+}
+
+func endpointSliceExportIndexerFunc(o client.Object) []string {
+	return []string{o.(*fleetnetv1alpha1.EndpointSliceExport).Spec.OwnerServiceReference.NamespacedName}
+}
+
+func serviceImport(exportedLabels map[string]string, clusters ...string) *fleetnetv1alpha1.ServiceImport {
+	svcImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testSvcNamespace,
+			Name:      testSvcName,
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Type:           fleetnetv1alpha1.ClusterSetIP,
+			Ports:          testServicePorts,
+			ExportedLabels: exportedLabels,
+		},
+	}
+	for _, cluster := range clusters {
+		svcImport.Status.Clusters = append(svcImport.Status.Clusters, fleetnetv1alpha1.ServiceImportClusterStatus{Cluster: cluster})
+	}
+	return svcImport
+}
+
+func endpointSliceSpec(clusterID string) fleetnetv1alpha1.EndpointSliceExportSpec {
+	return fleetnetv1alpha1.EndpointSliceExportSpec{
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints:   []fleetnetv1alpha1.Endpoint{{Addresses: testEndpointSliceAddress}},
+		EndpointSliceReference: fleetnetv1alpha1.ExportedObjectReference{
+			ClusterID: clusterID,
+			Kind:      "EndpointSlice",
+			Namespace: testSvcNamespace,
+			Name:      "app-abcde",
+		},
+		OwnerServiceReference: fleetnetv1alpha1.OwnerServiceReference{
+			Namespace:      testSvcNamespace,
+			Name:           testSvcName,
+			NamespacedName: testSvcKey.String(),
+		},
+	}
+}
+
+func endpointSliceExport(namespace, name, clusterID, origin string) *fleetnetv1alpha1.EndpointSliceExport {
+	endpointSliceExport := &fleetnetv1alpha1.EndpointSliceExport{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       endpointSliceSpec(clusterID),
+	}
+	if origin != "" {
+		setOrigin(endpointSliceExport, origin)
+	}
+	return endpointSliceExport
+}
+
+func endpointSliceImport(name, clusterID, origin string) *fleetnetv1alpha1.EndpointSliceImport {
+	endpointSliceImport := &fleetnetv1alpha1.EndpointSliceImport{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testUpstreamNamespace, Name: name},
+		Spec:       endpointSliceSpec(clusterID),
+	}
+	if origin != "" {
+		setOrigin(endpointSliceImport, origin)
+	}
+	return endpointSliceImport
+}
+
+// mirrors returns the spec and origin of the EndpointSliceExports of the test Service in the namespace by name.
+func mirrors(ctx context.Context, t *testing.T, c client.Client, namespace string) map[string]string {
+	list := &fleetnetv1alpha1.EndpointSliceExportList{}
+	if err := c.List(ctx, list, client.InNamespace(namespace), ownerServiceLabelSelector(testSvcNamespace, testSvcName)); err != nil {
+		t.Fatalf("endpointSliceExport List(), got %v, want no error", err)
+	}
+	got := make(map[string]string, len(list.Items))
+	for _, item := range list.Items {
+		got[item.Name] = item.Spec.EndpointSliceReference.ClusterID + " " + item.Annotations[objectmeta.ExportedObjectAnnotationOrigin]
+	}
+	return got
+}
+
+func TestHasTraversed(t *testing.T) {
+	testCases := []struct {
+		name   string
+		origin string
+		want   bool
+	}{
+		{
+			name:   "member cluster of another fleet",
+			origin: testMemberClusterID,
+		},
+		{
+			name:   "originates from the fleet",
+			origin: testFleetName,
+			want:   true,
+		},
+		{
+			name:   "traversed the fleet",
+			origin: "fleet-east/" + testFleetName + "/member-1",
+			want:   true,
+		},
+		{
+			name:   "fleet name as a prefix of another fleet",
+			origin: testFleetName + "-2/member-1",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := hasTraversed(tc.origin, testFleetName); got != tc.want {
+				t.Errorf("hasTraversed(%q, %q) = %v, want %v", tc.origin, testFleetName, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFederatedEndpointSliceSpec(t *testing.T) {
+	spec := endpointSliceSpec(testMemberClusterID)
+	spec.PrivateLink = &fleetnetv1alpha1.PrivateLinkEndpoints{ServiceID: "pls"}
+
+	got := federatedEndpointSliceSpec(&spec, testFleetName)
+	want := endpointSliceSpec(testFleetName)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("federatedEndpointSliceSpec() mismatch (-want, +got):\n%s", diff)
+	}
+	if spec.PrivateLink == nil || spec.EndpointSliceReference.ClusterID != testMemberClusterID {
+		t.Errorf("federatedEndpointSliceSpec() modified the source spec: %+v", spec)
+	}
+}
+
+func TestExportReconcile(t *testing.T) {
+	ctx := context.Background()
+	testCases := []struct {
+		name         string
+		svcImport    *fleetnetv1alpha1.ServiceImport
+		hubObjs      []client.Object
+		upstreamObjs []client.Object
+		wantExported bool
+		wantMirrors  map[string]string
+	}{
+		{
+			name:      "should re-export the service and the endpoints of the member clusters",
+			svcImport: serviceImport(testFederatedLabels, testMemberClusterID, testUpstreamClusterID),
+			hubObjs: []client.Object{
+				endpointSliceExport(testMemberNamespace, "member-slice", testMemberClusterID, ""),
+				endpointSliceExport(testUpstreamClusterNS, "upstream-slice", testUpstreamClusterID, testUpstreamClusterID+"/fleet-east/member-2"),
+			},
+			upstreamObjs: []client.Object{
+				// A stale mirror of an endpoint slice which is no longer exported.
+				func() client.Object {
+					stale := endpointSliceExport(testUpstreamNamespace, "stale-slice", testFleetName, testFleetName+"/member-2")
+					setOwnerServiceLabels(stale, testSvcNamespace, testSvcName)
+					return stale
+				}(),
+			},
+			wantExported: true,
+			wantMirrors: map[string]string{
+				"member-slice": testFleetName + " " + testFleetName + "/" + testMemberClusterID,
+			},
+		},
+		{
+			name:      "should not re-export the endpoints of the excluded clusters",
+			svcImport: serviceImport(testFederatedLabels, "member-2"),
+			hubObjs: []client.Object{
+				endpointSliceExport(testMemberNamespace, "member-slice", testMemberClusterID, ""),
+			},
+			wantExported: true,
+			wantMirrors:  map[string]string{},
+		},
+		{
+			name:      "should withdraw the service which is not selected",
+			svcImport: serviceImport(nil, testMemberClusterID),
+			upstreamObjs: []client.Object{
+				&fleetnetv1alpha1.InternalServiceExport{
+					ObjectMeta: metav1.ObjectMeta{Namespace: testUpstreamNamespace, Name: testInternalSvcObjName},
+				},
+				func() client.Object {
+					mirror := endpointSliceExport(testUpstreamNamespace, "member-slice", testFleetName, testFleetName+"/"+testMemberClusterID)
+					setOwnerServiceLabels(mirror, testSvcNamespace, testSvcName)
+					return mirror
+				}(),
+			},
+			wantMirrors: map[string]string{},
+		},
+		{
+			name:      "should not re-export the service only imported from the upstream fleet",
+			svcImport: serviceImport(testFederatedLabels, testUpstreamClusterID),
+			hubObjs: []client.Object{
+				endpointSliceExport(testUpstreamClusterNS, "upstream-slice", testUpstreamClusterID, testUpstreamClusterID+"/fleet-east/member-2"),
+			},
+			wantMirrors: map[string]string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := testScheme(t)
+			hubClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithIndex(&fleetnetv1alpha1.EndpointSliceExport{}, endpointSliceExportOwnerSvcNamespacedNameFieldKey, endpointSliceExportIndexerFunc).
+				WithObjects(append(tc.hubObjs, tc.svcImport)...).
+				Build()
+			upstreamClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(tc.upstreamObjs...).
+				Build()
+			r := &ExportReconciler{
+				HubClient:         hubClient,
+				UpstreamClient:    upstreamClient,
+				FleetName:         testFleetName,
+				UpstreamNamespace: testUpstreamNamespace,
+				UpstreamClusterID: testUpstreamClusterID,
+				Selector:          testSelector,
+			}
+
+			if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: testSvcKey}); err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+
+			internalSvcExport := &fleetnetv1alpha1.InternalServiceExport{}
+			err := upstreamClient.Get(ctx, types.NamespacedName{Namespace: testUpstreamNamespace, Name: testInternalSvcObjName}, internalSvcExport)
+			if !tc.wantExported {
+				if !apierrors.IsNotFound(err) {
+					t.Errorf("internalServiceExport Get() = %v, want not found", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("internalServiceExport Get() = %v, want no error", err)
+				}
+				if diff := cmp.Diff(testServicePorts, internalSvcExport.Spec.Ports); diff != "" {
+					t.Errorf("internalServiceExport ports mismatch (-want, +got):\n%s", diff)
+				}
+				ref := internalSvcExport.Spec.ServiceReference
+				if ref.ClusterID != testFleetName || ref.NamespacedName != testSvcKey.String() {
+					t.Errorf("internalServiceExport serviceReference = %+v, want cluster %q and service %q", ref, testFleetName, testSvcKey)
+				}
+			}
+
+			if diff := cmp.Diff(tc.wantMirrors, mirrors(ctx, t, upstreamClient, testUpstreamNamespace)); diff != "" {
+				t.Errorf("endpointSliceExports of the upstream hub mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestImportReconcile(t *testing.T) {
+	ctx := context.Background()
+	fulfilled := &fleetnetv1alpha1.InternalServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testUpstreamNamespace,
+			Name:      internalServiceImportName(testSvcNamespace, testSvcName),
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Type:           fleetnetv1alpha1.ClusterSetIP,
+			Ports:          testServicePorts,
+			ExportedLabels: testFederatedLabels,
+		},
+	}
+	testCases := []struct {
+		name          string
+		svcImport     *fleetnetv1alpha1.ServiceImport
+		hubObjs       []client.Object
+		upstreamObjs  []client.Object
+		wantRequested bool
+		wantImported  bool
+		wantMirrors   map[string]string
+	}{
+		{
+			name:      "should import the service and the endpoints of the peer fleets",
+			svcImport: serviceImport(testFederatedLabels, testFleetName, testPeerFleetName),
+			upstreamObjs: []client.Object{
+				fulfilled,
+				endpointSliceImport("own-slice", testFleetName, testFleetName+"/"+testMemberClusterID),
+				endpointSliceImport("peer-slice", testPeerFleetName, testPeerFleetName+"/member-2"),
+			},
+			wantRequested: true,
+			wantImported:  true,
+			wantMirrors: map[string]string{
+				"peer-slice": testUpstreamClusterID + " " + testUpstreamClusterID + "/" + testPeerFleetName + "/member-2",
+			},
+		},
+		{
+			name:          "should request the import and wait for it to be fulfilled",
+			svcImport:     serviceImport(testFederatedLabels, testPeerFleetName),
+			wantRequested: true,
+			wantMirrors:   map[string]string{},
+		},
+		{
+			name:      "should not import the service only exported from the fleet itself",
+			svcImport: serviceImport(testFederatedLabels, testFleetName),
+			hubObjs: []client.Object{
+				&fleetnetv1alpha1.InternalServiceExport{
+					ObjectMeta: metav1.ObjectMeta{Namespace: testUpstreamClusterNS, Name: testInternalSvcObjName},
+				},
+			},
+			upstreamObjs: []client.Object{fulfilled},
+			wantMirrors:  map[string]string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := testScheme(t)
+			hubClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(tc.hubObjs...).
+				Build()
+			upstreamClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(append(tc.upstreamObjs, tc.svcImport)...).
+				Build()
+			r := &ImportReconciler{
+				HubClient:         hubClient,
+				UpstreamClient:    upstreamClient,
+				FleetName:         testFleetName,
+				UpstreamNamespace: testUpstreamNamespace,
+				UpstreamClusterID: testUpstreamClusterID,
+				Selector:          testSelector,
+			}
+
+			if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: testSvcKey}); err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+
+			internalSvcImport := &fleetnetv1alpha1.InternalServiceImport{}
+			err := upstreamClient.Get(ctx, types.NamespacedName{Namespace: testUpstreamNamespace, Name: internalServiceImportName(testSvcNamespace, testSvcName)}, internalSvcImport)
+			if got := err == nil; got != tc.wantRequested {
+				t.Errorf("internalServiceImport Get() = %v, want requested %v", err, tc.wantRequested)
+			}
+
+			internalSvcExport := &fleetnetv1alpha1.InternalServiceExport{}
+			err = hubClient.Get(ctx, types.NamespacedName{Namespace: testUpstreamClusterNS, Name: testInternalSvcObjName}, internalSvcExport)
+			if !tc.wantImported {
+				if !apierrors.IsNotFound(err) {
+					t.Errorf("internalServiceExport Get() = %v, want not found", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("internalServiceExport Get() = %v, want no error", err)
+				}
+				if diff := cmp.Diff(testServicePorts, internalSvcExport.Spec.Ports); diff != "" {
+					t.Errorf("internalServiceExport ports mismatch (-want, +got):\n%s", diff)
+				}
+				if got := internalSvcExport.Spec.ServiceReference.ClusterID; got != testUpstreamClusterID {
+					t.Errorf("internalServiceExport cluster = %q, want %q", got, testUpstreamClusterID)
+				}
+				if err := hubClient.Get(ctx, types.NamespacedName{Name: testUpstreamClusterNS}, &corev1.Namespace{}); err != nil {
+					t.Errorf("namespace Get(%q) = %v, want no error", testUpstreamClusterNS, err)
+				}
+			}
+
+			if diff := cmp.Diff(tc.wantMirrors, mirrors(ctx, t, hubClient, testUpstreamClusterNS)); diff != "" {
+				t.Errorf("endpointSliceExports of the upstream fleet mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,355 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package federation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/hubconfig"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/uniquename"
+)
+
+// ImportReconciler imports the selected ServiceImports of the upstream hub cluster, and the endpoints of the peer
+// fleets backing them, into the fleet, as the exports of the virtual member cluster representing the upstream fleet.
+type ImportReconciler struct {
+	// HubClient is the client of the hub cluster of the fleet.
+	HubClient client.Client
+	// UpstreamClient is the client of the upstream hub cluster.
+	UpstreamClient client.Client
+	// FleetName is the ID of the fleet as a member cluster of the upstream fleet.
+	FleetName string
+	// UpstreamNamespace is the namespace reserved for the fleet in the upstream hub cluster.
+	UpstreamNamespace string
+	// UpstreamClusterID is the ID of the virtual member cluster of the fleet which the services of the upstream fleet
+	// are imported from.
+	UpstreamClusterID string
+	// Selector selects the ServiceImports of the upstream hub cluster to import by the labels exported with them.
+	Selector labels.Selector
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
+}
+
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch;create
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalserviceexports,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=endpointsliceexports,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile imports a ServiceImport of the upstream hub cluster into the fleet, or withdraws it if it is no longer
+// imported.
+func (r *ImportReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	svcImportRef := klog.KRef(req.Namespace, req.Name)
+	startTime := time.Now()
+	klog.V(2).InfoS("Reconciliation starts", "upstreamServiceImport", svcImportRef)
+	defer func() {
+		latency := time.Since(startTime).Milliseconds()
+		klog.V(2).InfoS("Reconciliation ends", "upstreamServiceImport", svcImportRef, "latency", latency)
+	}()
+
+	svcImport := &fleetnetv1alpha1.ServiceImport{}
+	if err := r.UpstreamClient.Get(ctx, req.NamespacedName, svcImport); err != nil {
+		if !apierrors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to get the upstream serviceImport", "upstreamServiceImport", svcImportRef)
+			return ctrl.Result{}, err
+		}
+		klog.V(2).InfoS("Upstream serviceImport is not found; withdraw it from the fleet", "upstreamServiceImport", svcImportRef)
+		return ctrl.Result{}, r.withdraw(ctx, req.NamespacedName)
+	}
+	if reason := r.skipReason(svcImport); reason != "" {
+		klog.V(2).InfoS("Upstream serviceImport is not imported; withdraw it from the fleet", "upstreamServiceImport", svcImportRef, "reason", reason)
+		return ctrl.Result{}, r.withdraw(ctx, req.NamespacedName)
+	}
+
+	// Import the service into the fleet as a member cluster of the upstream fleet, so that the upstream hub cluster
+	// decides whether the fleet is allowed to import it, and distributes its endpoints to the fleet.
+	internalSvcImport, err := r.requestImport(ctx, svcImport)
+	if err != nil {
+		klog.ErrorS(err, "Failed to import the service from the upstream fleet", "upstreamServiceImport", svcImportRef)
+		return ctrl.Result{}, err
+	}
+	if reason := importPendingReason(internalSvcImport); reason != "" {
+		// The import is fulfilled by the upstream hub cluster asynchronously; the controller is notified as the
+		// status of the InternalServiceImport changes.
+		klog.V(2).InfoS("The import from the upstream fleet is not fulfilled; withdraw it from the fleet",
+			"upstreamServiceImport", svcImportRef, "internalServiceImport", klog.KObj(internalSvcImport), "reason", reason)
+		return ctrl.Result{}, r.withdrawFromFleet(ctx, req.NamespacedName)
+	}
+
+	if err := r.ensureClusterNamespace(ctx); err != nil {
+		klog.ErrorS(err, "Failed to create the namespace of the upstream fleet")
+		return ctrl.Result{}, err
+	}
+	if err := r.importService(ctx, svcImport, internalSvcImport); err != nil {
+		klog.ErrorS(err, "Failed to import the service into the fleet", "upstreamServiceImport", svcImportRef)
+		return ctrl.Result{}, err
+	}
+	if err := r.importEndpointSlices(ctx, svcImport); err != nil {
+		klog.ErrorS(err, "Failed to import the endpoint slices into the fleet", "upstreamServiceImport", svcImportRef)
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// skipReason returns why the upstream ServiceImport is not imported into the fleet, or an empty string if it is.
+func (r *ImportReconciler) skipReason(svcImport *fleetnetv1alpha1.ServiceImport) string {
+	switch {
+	case svcImport.DeletionTimestamp != nil:
+		return "the serviceImport is being deleted"
+	case !r.Selector.Matches(labels.Set(svcImport.Status.ExportedLabels)):
+		return "the serviceImport is not selected"
+	}
+	for _, cluster := range svcImport.Status.Clusters {
+		if cluster.Cluster != r.FleetName {
+			return ""
+		}
+	}
+	return "the service is only exported from the fleet itself"
+}
+
+// importPendingReason returns why the import of the fleet from the upstream hub cluster is not fulfilled, or an
+// empty string if it is.
+func importPendingReason(internalSvcImport *fleetnetv1alpha1.InternalServiceImport) string {
+	if cond := meta.FindStatusCondition(internalSvcImport.Status.Conditions, string(fleetnetv1alpha1.ServiceImportNotAllowed)); cond != nil && cond.Status == metav1.ConditionTrue {
+		return cond.Message
+	}
+	if len(internalSvcImport.Status.Ports) == 0 {
+		return "the import is not fulfilled yet"
+	}
+	return ""
+}
+
+// requestImport creates the InternalServiceImport of the fleet in the upstream hub cluster, and returns it.
+func (r *ImportReconciler) requestImport(ctx context.Context, svcImport *fleetnetv1alpha1.ServiceImport) (*fleetnetv1alpha1.InternalServiceImport, error) {
+	internalSvcImport := &fleetnetv1alpha1.InternalServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: r.UpstreamNamespace,
+			Name:      internalServiceImportName(svcImport.Namespace, svcImport.Name),
+		},
+	}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.UpstreamClient, internalSvcImport, func() error {
+		if internalSvcImport.CreationTimestamp.IsZero() {
+			internalSvcImport.Spec.ServiceImportReference = fleetnetv1alpha1.FromMetaObjects(r.FleetName,
+				serviceImportTypeMeta, svcImport.ObjectMeta, svcImport.CreationTimestamp)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	klog.V(4).InfoS("Requested the import from the upstream fleet", "internalServiceImport", klog.KObj(internalSvcImport), "op", op)
+	return internalSvcImport, nil
+}
+
+// ensureClusterNamespace creates the namespace of the virtual member cluster representing the upstream fleet.
+func (r *ImportReconciler) ensureClusterNamespace(ctx context.Context) error {
+	ns := &corev1.Namespace{}
+	nsKey := types.NamespacedName{Name: fmt.Sprintf(hubconfig.HubNamespaceNameFormat, r.UpstreamClusterID)}
+	err := r.HubClient.Get(ctx, nsKey, ns)
+	if !apierrors.IsNotFound(err) {
+		return err
+	}
+	ns.Name = nsKey.Name
+	if err := r.HubClient.Create(ctx, ns); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// importService creates or updates the InternalServiceExport of the virtual member cluster representing the
+// upstream fleet, with the spec of the service as fulfilled by the upstream hub cluster.
+func (r *ImportReconciler) importService(ctx context.Context, svcImport *fleetnetv1alpha1.ServiceImport, internalSvcImport *fleetnetv1alpha1.InternalServiceImport) error {
+	internalSvcExport := &fleetnetv1alpha1.InternalServiceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: fmt.Sprintf(hubconfig.HubNamespaceNameFormat, r.UpstreamClusterID),
+			Name:      uniquename.ClusterScopedDeterministicName(svcImport.Namespace, svcImport.Name),
+		},
+	}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.HubClient, internalSvcExport, func() error {
+		spec := federatedServiceSpec(&internalSvcImport.Status)
+		spec.ServiceReference = internalSvcExport.Spec.ServiceReference
+		switch {
+		case internalSvcExport.CreationTimestamp.IsZero():
+			spec.ServiceReference = fleetnetv1alpha1.FromMetaObjects(r.UpstreamClusterID, serviceImportTypeMeta, svcImport.ObjectMeta, metav1.Now())
+		case !equality.Semantic.DeepEqual(&internalSvcExport.Spec, &spec):
+			// The reference only moves along with the imported spec, for the same reason as the re-exports.
+			spec.ServiceReference.UpdateFromMetaObject(svcImport.ObjectMeta, metav1.Now())
+		}
+		internalSvcExport.Spec = spec
+
+		if internalSvcExport.Labels == nil {
+			internalSvcExport.Labels = map[string]string{}
+		}
+		internalSvcExport.Labels[objectmeta.InternalServiceExportLabelServiceNamespace] = svcImport.Namespace
+		internalSvcExport.Labels[objectmeta.InternalServiceExportLabelServiceName] = svcImport.Name
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	klog.V(2).InfoS("Imported the service from the upstream fleet", "internalServiceExport", klog.KObj(internalSvcExport), "op", op)
+	return nil
+}
+
+// importEndpointSlices mirrors the EndpointSliceImports distributed to the fleet by the upstream hub cluster as the
+// EndpointSliceExports of the virtual member cluster, except for the endpoints which have traversed the fleet, and
+// deletes the mirrors of the others.
+func (r *ImportReconciler) importEndpointSlices(ctx context.Context, svcImport *fleetnetv1alpha1.ServiceImport) error {
+	endpointSliceImportList := &fleetnetv1alpha1.EndpointSliceImportList{}
+	if err := r.UpstreamClient.List(ctx, endpointSliceImportList, client.InNamespace(r.UpstreamNamespace)); err != nil {
+		return err
+	}
+
+	clusterNamespace := fmt.Sprintf(hubconfig.HubNamespaceNameFormat, r.UpstreamClusterID)
+	imported := make(map[string]bool, len(endpointSliceImportList.Items))
+	for i := range endpointSliceImportList.Items {
+		endpointSliceImport := &endpointSliceImportList.Items[i]
+		owner := &endpointSliceImport.Spec.OwnerServiceReference
+		if owner.Namespace != svcImport.Namespace || owner.Name != svcImport.Name {
+			continue
+		}
+		origin := originOf(endpointSliceImport, &endpointSliceImport.Spec)
+		if hasTraversed(origin, r.FleetName) {
+			// The endpoints exported from the fleet itself never come back.
+			continue
+		}
+
+		mirror := &fleetnetv1alpha1.EndpointSliceExport{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: clusterNamespace,
+				Name:      endpointSliceImport.Name,
+			},
+		}
+		op, err := controllerutil.CreateOrUpdate(ctx, r.HubClient, mirror, func() error {
+			mirror.Spec = federatedEndpointSliceSpec(&endpointSliceImport.Spec, r.UpstreamClusterID)
+			setOwnerServiceLabels(mirror, svcImport.Namespace, svcImport.Name)
+			setOrigin(mirror, r.UpstreamClusterID+originSeparator+origin)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		klog.V(4).InfoS("Imported the endpoint slice from the upstream fleet",
+			"endpointSliceImport", klog.KObj(endpointSliceImport), "mirror", klog.KObj(mirror), "op", op)
+		imported[mirror.Name] = true
+	}
+	return r.deleteEndpointSliceMirrors(ctx, svcImport.Namespace, svcImport.Name, imported)
+}
+
+// withdraw deletes the InternalServiceImport of the fleet from the upstream hub cluster, and withdraws the imported
+// service from the fleet.
+func (r *ImportReconciler) withdraw(ctx context.Context, svcKey types.NamespacedName) error {
+	internalSvcImport := &fleetnetv1alpha1.InternalServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: r.UpstreamNamespace,
+			Name:      internalServiceImportName(svcKey.Namespace, svcKey.Name),
+		},
+	}
+	if err := r.UpstreamClient.Delete(ctx, internalSvcImport); err != nil && !apierrors.IsNotFound(err) {
+		klog.ErrorS(err, "Failed to withdraw the import from the upstream fleet", "internalServiceImport", klog.KObj(internalSvcImport))
+		return err
+	}
+	return r.withdrawFromFleet(ctx, svcKey)
+}
+
+// withdrawFromFleet deletes the InternalServiceExport and EndpointSliceExports of the virtual member cluster
+// representing the upstream fleet for the Service.
+func (r *ImportReconciler) withdrawFromFleet(ctx context.Context, svcKey types.NamespacedName) error {
+	internalSvcExport := &fleetnetv1alpha1.InternalServiceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: fmt.Sprintf(hubconfig.HubNamespaceNameFormat, r.UpstreamClusterID),
+			Name:      uniquename.ClusterScopedDeterministicName(svcKey.Namespace, svcKey.Name),
+		},
+	}
+	if err := r.HubClient.Delete(ctx, internalSvcExport); err != nil && !apierrors.IsNotFound(err) {
+		klog.ErrorS(err, "Failed to withdraw the imported service from the fleet", "internalServiceExport", klog.KObj(internalSvcExport))
+		return err
+	}
+	return r.deleteEndpointSliceMirrors(ctx, svcKey.Namespace, svcKey.Name, nil)
+}
+
+// deleteEndpointSliceMirrors deletes the EndpointSliceExports of the virtual member cluster for the Service, except
+// for the ones to keep.
+func (r *ImportReconciler) deleteEndpointSliceMirrors(ctx context.Context, svcNamespace, svcName string, keep map[string]bool) error {
+	mirrorList := &fleetnetv1alpha1.EndpointSliceExportList{}
+	clusterNamespace := fmt.Sprintf(hubconfig.HubNamespaceNameFormat, r.UpstreamClusterID)
+	if err := r.HubClient.List(ctx, mirrorList, client.InNamespace(clusterNamespace), ownerServiceLabelSelector(svcNamespace, svcName)); err != nil {
+		return err
+	}
+	for i := range mirrorList.Items {
+		mirror := &mirrorList.Items[i]
+		if keep[mirror.Name] {
+			continue
+		}
+		klog.V(4).InfoS("Withdraw the imported endpoint slice from the fleet", "endpointSliceExport", klog.KObj(mirror))
+		if err := r.HubClient.Delete(ctx, mirror); err != nil && !apierrors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to withdraw the imported endpoint slice from the fleet", "endpointSliceExport", klog.KObj(mirror))
+			return err
+		}
+	}
+	return nil
+}
+
+// internalServiceImportName returns the name of the InternalServiceImport of the fleet for a Service, in the same
+// format as the member clusters use.
+func internalServiceImportName(svcNamespace, svcName string) string {
+	return fmt.Sprintf("%s-%s", svcNamespace, svcName)
+}
+
+// SetupWithManager sets up the controller with the Manager; the objects of the upstream hub cluster are watched
+// through the given cluster, whose requests are keyed by the upstream ServiceImports.
+func (r *ImportReconciler) SetupWithManager(mgr ctrl.Manager, upstream cluster.Cluster) error {
+	clusterNamespace := fmt.Sprintf(hubconfig.HubNamespaceNameFormat, r.UpstreamClusterID)
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(ImportControllerName).
+		WatchesRawSource(source.Kind(upstream.GetCache(), &fleetnetv1alpha1.ServiceImport{},
+			&handler.TypedEnqueueRequestForObject[*fleetnetv1alpha1.ServiceImport]{})).
+		// Follow the fulfillment of the imports, and the endpoints distributed to the fleet.
+		WatchesRawSource(source.Kind(upstream.GetCache(), &fleetnetv1alpha1.InternalServiceImport{},
+			handler.TypedEnqueueRequestsFromMapFunc(func(_ context.Context, o *fleetnetv1alpha1.InternalServiceImport) []reconcile.Request {
+				ref := &o.Spec.ServiceImportReference
+				return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}}}
+			}))).
+		WatchesRawSource(source.Kind(upstream.GetCache(), &fleetnetv1alpha1.EndpointSliceImport{},
+			handler.TypedEnqueueRequestsFromMapFunc(func(_ context.Context, o *fleetnetv1alpha1.EndpointSliceImport) []reconcile.Request {
+				return ownerServiceRequest(&o.Spec)
+			}))).
+		// Restore the imports deleted from the hub cluster out-of-band.
+		Watches(&fleetnetv1alpha1.InternalServiceExport{}, handler.EnqueueRequestsFromMapFunc(func(_ context.Context, o client.Object) []reconcile.Request {
+			if o.GetNamespace() != clusterNamespace {
+				return nil
+			}
+			return labeledServiceRequest(o, objectmeta.InternalServiceExportLabelServiceNamespace, objectmeta.InternalServiceExportLabelServiceName)
+		})).
+		Watches(&fleetnetv1alpha1.EndpointSliceExport{}, handler.EnqueueRequestsFromMapFunc(func(_ context.Context, o client.Object) []reconcile.Request {
+			if o.GetNamespace() != clusterNamespace {
+				return nil
+			}
+			return labeledServiceRequest(o, objectmeta.EndpointSliceExportLabelOwnerServiceNamespace, objectmeta.EndpointSliceExportLabelOwnerServiceName)
+		})).
+		WithOptions(r.ControllerOptions).
+		Complete(metrics.InstrumentReconciler("federationimport", r))
+}
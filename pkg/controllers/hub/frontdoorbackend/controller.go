@@ -0,0 +1,682 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package frontdoorbackend features the FrontDoorBackend controller to reconcile FrontDoorBackend CRs.
+package frontdoorbackend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"go.goms.io/fleet/pkg/utils/controller"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/azureerrors"
+	"go.goms.io/fleet-networking/pkg/common/azurefrontdoor"
+	"go.goms.io/fleet-networking/pkg/common/defaulter"
+	"go.goms.io/fleet-networking/pkg/common/fieldindex"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+const (
+	frontDoorBackendBackendFieldKey       = ".spec.backend.name"
+	frontDoorBackendTrafficPolicyFieldKey = ".spec.trafficPolicyRef.name"
+	// fields name used to filter resources
+	exportedServiceFieldNamespacedName = fieldindex.InternalServiceExportServiceNamespacedName
+
+	// AzureResourceOriginGroupNameFormat is the name format of the Azure Front Door origin group created by the fleet
+	// controller, which is fleet-{FrontDoorBackendUUID}.
+	// The origin group name must be 1-90 characters long and contain only letters, numbers and hyphens.
+	AzureResourceOriginGroupNameFormat = "fleet-%s"
+
+	// maxAzureResourceOriginNameLength is the max length of the Azure Front Door origin name.
+	maxAzureResourceOriginNameLength = 90
+
+	// The load balancing settings of the origin groups, which are the defaults of the Azure Front Door.
+	defaultSampleSize                      = 4
+	defaultSuccessfulSamplesRequired       = 3
+	defaultAdditionalLatencyInMilliseconds = 50
+
+	// The priority and weight of the origins when the backend does not reference any traffic policy.
+	defaultOriginPriority = 1
+	defaultOriginWeight   = 1000
+)
+
+var (
+	invalidOriginNameCharacters = regexp.MustCompile("[^a-zA-Z0-9-]")
+)
+
+// Reconciler reconciles a frontDoorBackend object.
+type Reconciler struct {
+	client.Client
+
+	FrontDoorClient azurefrontdoor.Interface
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions ctrlcontroller.Options
+}
+
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=frontdoorbackends,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=frontdoorbackends/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=frontdoorbackends/finalizers,verbs=get;update
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=backendtrafficpolicies,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalserviceexports,verbs=get;list;watch
+
+// Reconcile triggers a single reconcile round.
+func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	name := req.NamespacedName
+	backendKRef := klog.KRef(name.Namespace, name.Name)
+
+	startTime := time.Now()
+	klog.V(2).InfoS("Reconciliation starts", "frontDoorBackend", backendKRef)
+	defer func() {
+		latency := time.Since(startTime).Milliseconds()
+		klog.V(2).InfoS("Reconciliation ends", "frontDoorBackend", backendKRef, "latency", latency)
+	}()
+
+	backend := &fleetnetv1alpha1.FrontDoorBackend{}
+	if err := r.Client.Get(ctx, name, backend); err != nil {
+		if apierrors.IsNotFound(err) {
+			klog.V(4).InfoS("Ignoring NotFound frontDoorBackend", "frontDoorBackend", backendKRef)
+			return ctrl.Result{}, nil
+		}
+		klog.ErrorS(err, "Failed to get frontDoorBackend", "frontDoorBackend", backendKRef)
+		return ctrl.Result{}, controller.NewAPIServerError(true, err)
+	}
+
+	if !backend.ObjectMeta.DeletionTimestamp.IsZero() {
+		return r.handleDelete(ctx, backend)
+	}
+
+	// register finalizer
+	if !controllerutil.ContainsFinalizer(backend, objectmeta.FrontDoorBackendFinalizer) {
+		controllerutil.AddFinalizer(backend, objectmeta.FrontDoorBackendFinalizer)
+		if err := r.Update(ctx, backend); err != nil {
+			klog.ErrorS(err, "Failed to add finalizer to frontDoorBackend", "frontDoorBackend", backendKRef)
+			return ctrl.Result{}, controller.NewUpdateIgnoreConflictError(err)
+		}
+	}
+	defaulter.SetDefaultsFrontDoorBackend(backend)
+	return r.handleUpdate(ctx, backend)
+}
+
+func (r *Reconciler) handleDelete(ctx context.Context, backend *fleetnetv1alpha1.FrontDoorBackend) (ctrl.Result, error) {
+	backendKObj := klog.KObj(backend)
+	// The backend is being deleted
+	if !controllerutil.ContainsFinalizer(backend, objectmeta.FrontDoorBackendFinalizer) {
+		klog.V(4).InfoS("FrontDoorBackend is being deleted", "frontDoorBackend", backendKObj)
+		return ctrl.Result{}, nil
+	}
+
+	originGroupName := generateAzureOriginGroupName(backend)
+	if err := r.FrontDoorClient.DeleteOriginGroup(ctx, backend.Spec.Profile.ResourceGroup, backend.Spec.Profile.Name, originGroupName); err != nil {
+		if !azureerrors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to delete Azure Front Door origin group", "frontDoorBackend", backendKObj, "originGroup", originGroupName)
+			return ctrl.Result{}, err
+		}
+		klog.V(2).InfoS("Ignoring NotFound Azure Front Door origin group", "frontDoorBackend", backendKObj, "originGroup", originGroupName)
+	}
+
+	controllerutil.RemoveFinalizer(backend, objectmeta.FrontDoorBackendFinalizer)
+	if err := r.Client.Update(ctx, backend); err != nil {
+		klog.ErrorS(err, "Failed to remove frontDoorBackend finalizer", "frontDoorBackend", backendKObj)
+		return ctrl.Result{}, controller.NewUpdateIgnoreConflictError(err)
+	}
+	klog.V(2).InfoS("Removed frontDoorBackend finalizer", "frontDoorBackend", backendKObj)
+	return ctrl.Result{}, nil
+}
+
+func (r *Reconciler) handleUpdate(ctx context.Context, backend *fleetnetv1alpha1.FrontDoorBackend) (ctrl.Result, error) {
+	backendKObj := klog.KObj(backend)
+	originGroupName, err := r.createOrUpdateOriginGroup(ctx, backend)
+	if err != nil || originGroupName == "" {
+		// We don't need to requeue the invalid profile (err == nil and originGroupName == "") as the backend has to
+		// be updated to fix it.
+		// The controller will retry when err is not nil.
+		return ctrl.Result{}, err
+	}
+	klog.V(2).InfoS("Created or updated the Azure Front Door origin group", "frontDoorBackend", backendKObj, "originGroup", originGroupName)
+
+	policy, err := r.validateBackendTrafficPolicy(ctx, backend)
+	if err != nil || policy == nil {
+		// We don't need to requeue the invalid traffic policy (err == nil and policy == nil) as when the policy is
+		// created, the controller will be re-triggered again.
+		// The controller will retry when err is not nil.
+		return ctrl.Result{}, err
+	}
+
+	serviceImport := &fleetnetv1alpha1.ServiceImport{}
+	serviceImportName := types.NamespacedName{Namespace: backend.Namespace, Name: backend.Spec.Backend.Name}
+	if err := r.Client.Get(ctx, serviceImportName, serviceImport); err != nil {
+		if !apierrors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to get serviceImport", "frontDoorBackend", backendKObj, "serviceImport", serviceImportName)
+			return ctrl.Result{}, controller.NewAPIServerError(true, err)
+		}
+		klog.V(2).InfoS("NotFound serviceImport and deleting any stale origins", "frontDoorBackend", backendKObj, "serviceImport", serviceImportName)
+		if _, _, err := r.updateOrigins(ctx, backend, originGroupName, map[string]desiredOrigin{}); err != nil {
+			return ctrl.Result{}, err
+		}
+		setFalseCondition(backend, nil, fmt.Sprintf("ServiceImport %q is not found", backend.Spec.Backend.Name))
+		backend.Status.OriginGroup = originGroupName
+		return ctrl.Result{}, r.updateFrontDoorBackendStatus(ctx, backend)
+	}
+
+	desiredOrigins, invalidServices, err := r.buildDesiredOrigins(ctx, backend, policy, serviceImport)
+	if err != nil || (desiredOrigins == nil && invalidServices == nil) {
+		// We don't need to requeue when the services are still being exported (err == nil and desiredOrigins == nil &&
+		// invalidServices == nil) as when the serviceImport is updated, the controller will be re-triggered again.
+		// The controller will retry when err is not nil.
+		return ctrl.Result{}, err
+	}
+	klog.V(2).InfoS("Found the exported services behind the serviceImport", "frontDoorBackend", backendKObj, "numberOfDesiredOrigins", len(desiredOrigins), "numberOfInvalidServices", len(invalidServices))
+
+	acceptedOrigins, badOriginsErr, err := r.updateOrigins(ctx, backend, originGroupName, desiredOrigins)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(invalidServices) == 0 && len(badOriginsErr) == 0 {
+		setTrueCondition(backend, acceptedOrigins)
+	} else {
+		var message string
+		if len(badOriginsErr) > 0 {
+			message = fmt.Sprintf("%v origin(s) failed to be created/updated in the Azure Front Door, for example, %v; ", len(badOriginsErr), badOriginsErr[0])
+		}
+		if len(invalidServices) > 0 {
+			clusters := make([]string, 0, len(invalidServices))
+			for cluster := range invalidServices {
+				clusters = append(clusters, cluster)
+			}
+			sort.Strings(clusters)
+			message += fmt.Sprintf("%v service(s) exported from clusters cannot be exposed as the Azure Front Door origins, for example, service exported from %v is invalid: %v", len(invalidServices), clusters[0], invalidServices[clusters[0]])
+		}
+		setFalseCondition(backend, acceptedOrigins, message)
+	}
+	backend.Status.OriginGroup = originGroupName
+	klog.V(2).InfoS("Updated Azure Front Door origins for the serviceImport and updating the condition", "frontDoorBackend", backendKObj, "status", backend.Status)
+	return ctrl.Result{}, r.updateFrontDoorBackendStatus(ctx, backend)
+}
+
+// createOrUpdateOriginGroup returns the name of the origin group when it has been created or updated.
+func (r *Reconciler) createOrUpdateOriginGroup(ctx context.Context, backend *fleetnetv1alpha1.FrontDoorBackend) (string, error) {
+	backendKObj := klog.KObj(backend)
+	profile := backend.Spec.Profile
+	originGroupName := generateAzureOriginGroupName(backend)
+	desired := generateAzureOriginGroup(backend)
+
+	current, getErr := r.FrontDoorClient.GetOriginGroup(ctx, profile.ResourceGroup, profile.Name, originGroupName)
+	if getErr == nil && equalAzureOriginGroup(current, desired) {
+		klog.V(2).InfoS("Skipping updating the existing Azure Front Door origin group", "frontDoorBackend", backendKObj, "originGroup", originGroupName)
+		return originGroupName, nil
+	}
+	if getErr != nil && !azureerrors.IsNotFound(getErr) {
+		klog.ErrorS(getErr, "Failed to get the Azure Front Door origin group", "frontDoorBackend", backendKObj, "originGroup", originGroupName)
+		setUnknownCondition(backend, fmt.Sprintf("Failed to get the origin group %q of the Azure Front Door profile %q: %v", originGroupName, profile.Name, getErr))
+		if err := r.updateFrontDoorBackendStatus(ctx, backend); err != nil {
+			return "", err
+		}
+		return "", getErr
+	}
+
+	if _, updateErr := r.FrontDoorClient.CreateOrUpdateOriginGroup(ctx, profile.ResourceGroup, profile.Name, originGroupName, desired); updateErr != nil {
+		klog.ErrorS(updateErr, "Failed to create or update the Azure Front Door origin group", "frontDoorBackend", backendKObj, "originGroup", originGroupName)
+		if azureerrors.IsClientError(updateErr) && !azureerrors.IsThrottled(updateErr) {
+			// It may happen when the profile does not exist or the settings are rejected by the Azure Front Door.
+			// Retry won't help and the backend or the profile has to be updated to fix it.
+			setFalseCondition(backend, nil, fmt.Sprintf("Invalid origin group %q of the Azure Front Door profile %q under %q: %v", originGroupName, profile.Name, profile.ResourceGroup, updateErr))
+			return "", r.updateFrontDoorBackendStatus(ctx, backend)
+		}
+		setUnknownCondition(backend, fmt.Sprintf("Failed to create or update the origin group %q of the Azure Front Door profile %q: %v", originGroupName, profile.Name, updateErr))
+		if err := r.updateFrontDoorBackendStatus(ctx, backend); err != nil {
+			return "", err
+		}
+		return "", updateErr
+	}
+	return originGroupName, nil
+}
+
+// validateBackendTrafficPolicy returns not nil policy when the referenced policy is found or not set.
+func (r *Reconciler) validateBackendTrafficPolicy(ctx context.Context, backend *fleetnetv1alpha1.FrontDoorBackend) (*fleetnetv1alpha1.BackendTrafficPolicy, error) {
+	backendKObj := klog.KObj(backend)
+	policy := &fleetnetv1alpha1.BackendTrafficPolicy{}
+	if backend.Spec.TrafficPolicyRef == nil {
+		return policy, nil
+	}
+	policyName := backend.Spec.TrafficPolicyRef.Name
+	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: backend.Namespace, Name: policyName}, policy); err != nil {
+		if apierrors.IsNotFound(err) {
+			klog.V(2).InfoS("NotFound backendTrafficPolicy", "frontDoorBackend", backendKObj, "backendTrafficPolicy", policyName)
+			setFalseCondition(backend, backend.Status.Origins, fmt.Sprintf("BackendTrafficPolicy %q is not found", policyName))
+			return nil, r.updateFrontDoorBackendStatus(ctx, backend)
+		}
+		klog.ErrorS(err, "Failed to get backendTrafficPolicy", "frontDoorBackend", backendKObj, "backendTrafficPolicy", policyName)
+		return nil, controller.NewAPIServerError(true, err)
+	}
+	return policy, nil
+}
+
+type desiredOrigin struct {
+	Origin  azurefrontdoor.AFDOrigin
+	Cluster string
+}
+
+// buildDesiredOrigins returns the desired origins keyed by the origin name and the invalid exported services keyed
+// by the cluster name; both are nil when the services are still being exported.
+func (r *Reconciler) buildDesiredOrigins(ctx context.Context, backend *fleetnetv1alpha1.FrontDoorBackend, policy *fleetnetv1alpha1.BackendTrafficPolicy, serviceImport *fleetnetv1alpha1.ServiceImport) (map[string]desiredOrigin, map[string]error, error) {
+	backendKObj := klog.KObj(backend)
+	serviceImportKObj := klog.KObj(serviceImport)
+	serviceImportName := types.NamespacedName{Namespace: serviceImport.Namespace, Name: serviceImport.Name}
+	if len(serviceImport.Status.Clusters) == 0 {
+		klog.V(2).InfoS("No clusters found in the serviceImport", "frontDoorBackend", backendKObj, "serviceImport", serviceImportKObj)
+		setUnknownCondition(backend, "In the process of exporting the services")
+		return nil, nil, r.updateFrontDoorBackendStatus(ctx, backend)
+	}
+
+	internalServiceExportList := &fleetnetv1alpha1.InternalServiceExportList{}
+	listOpts := client.MatchingFields{
+		exportedServiceFieldNamespacedName: serviceImportName.String(),
+	}
+	if err := r.Client.List(ctx, internalServiceExportList, &listOpts); err != nil {
+		klog.ErrorS(err, "Failed to list internalServiceExports used by the serviceImport", "frontDoorBackend", backendKObj, "serviceImport", serviceImportKObj)
+		return nil, nil, controller.NewAPIServerError(true, err)
+	}
+	internalServiceExportMap := make(map[string]*fleetnetv1alpha1.InternalServiceExport, len(internalServiceExportList.Items))
+	for i, export := range internalServiceExportList.Items {
+		internalServiceExportMap[export.Spec.ServiceReference.ClusterID] = &internalServiceExportList.Items[i]
+	}
+
+	desiredOrigins := make(map[string]desiredOrigin, len(serviceImport.Status.Clusters)) // key is the origin name
+	invalidServices := make(map[string]error, len(serviceImport.Status.Clusters))        // key is cluster name
+	for _, clusterStatus := range serviceImport.Status.Clusters {
+		internalServiceExport, ok := internalServiceExportMap[clusterStatus.Cluster]
+		if !ok {
+			klog.V(2).InfoS("InternalServiceExport not found for the cluster", "frontDoorBackend", backendKObj, "serviceImport", serviceImportKObj, "clusterID", clusterStatus.Cluster)
+			setUnknownCondition(backend, fmt.Sprintf("Failed to find the exported service %q for %q", serviceImportName, clusterStatus.Cluster))
+			return nil, nil, r.updateFrontDoorBackendStatus(ctx, backend)
+		}
+		if err := isValidFrontDoorOrigin(internalServiceExport); err != nil {
+			invalidServices[clusterStatus.Cluster] = err
+			klog.V(2).InfoS("Invalid service for Azure Front Door origin", "frontDoorBackend", backendKObj, "serviceImport", serviceImportKObj, "clusterID", clusterStatus.Cluster, "error", err)
+			continue
+		}
+		origin := generateAzureOrigin(backend, policy, internalServiceExport)
+		desiredOrigins[*origin.Name] = desiredOrigin{Origin: origin, Cluster: clusterStatus.Cluster}
+	}
+	return desiredOrigins, invalidServices, nil
+}
+
+func isValidFrontDoorOrigin(export *fleetnetv1alpha1.InternalServiceExport) error {
+	if export.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		return fmt.Errorf("unsupported service type %q", export.Spec.Type)
+	}
+	if export.Spec.IsInternalLoadBalancer {
+		return fmt.Errorf("internal load balancer is not supported")
+	}
+	if len(export.Spec.LoadBalancerIngress) == 0 || export.Spec.LoadBalancerIngress[0] == "" {
+		return fmt.Errorf("load balancer ingress is not assigned")
+	}
+	return nil
+}
+
+func generateAzureOriginGroupName(backend *fleetnetv1alpha1.FrontDoorBackend) string {
+	return fmt.Sprintf(AzureResourceOriginGroupNameFormat, backend.UID)
+}
+
+// generateAzureOriginName returns the name of the origin for the cluster; as the origin names only contain letters,
+// numbers and hyphens, the other characters of the cluster name are replaced with hyphens.
+func generateAzureOriginName(cluster string) string {
+	name := invalidOriginNameCharacters.ReplaceAllString(cluster, "-")
+	if len(name) > maxAzureResourceOriginNameLength {
+		name = name[:maxAzureResourceOriginNameLength]
+	}
+	return strings.ToLower(name)
+}
+
+func generateAzureOriginGroup(backend *fleetnetv1alpha1.FrontDoorBackend) azurefrontdoor.AFDOriginGroup {
+	probe := backend.Spec.HealthProbe
+	return azurefrontdoor.AFDOriginGroup{
+		Properties: &azurefrontdoor.AFDOriginGroupProperties{
+			HealthProbeSettings: &azurefrontdoor.HealthProbeParameters{
+				ProbeIntervalInSeconds: probe.IntervalInSeconds,
+				ProbePath:              probe.Path,
+				ProbeProtocol:          ptr.To(string(*probe.Protocol)),
+				ProbeRequestType:       ptr.To(string(*probe.RequestType)),
+			},
+			LoadBalancingSettings: &azurefrontdoor.LoadBalancingSettingsParameters{
+				AdditionalLatencyInMilliseconds: ptr.To(int32(defaultAdditionalLatencyInMilliseconds)),
+				SampleSize:                      ptr.To(int32(defaultSampleSize)),
+				SuccessfulSamplesRequired:       ptr.To(int32(defaultSuccessfulSamplesRequired)),
+			},
+		},
+	}
+}
+
+func generateAzureOrigin(backend *fleetnetv1alpha1.FrontDoorBackend, policy *fleetnetv1alpha1.BackendTrafficPolicy, export *fleetnetv1alpha1.InternalServiceExport) azurefrontdoor.AFDOrigin {
+	cluster := export.Spec.ServiceReference.ClusterID
+	priority, weight := clusterTrafficSettings(policy, cluster)
+	return azurefrontdoor.AFDOrigin{
+		Name: ptr.To(generateAzureOriginName(cluster)),
+		Properties: &azurefrontdoor.AFDOriginProperties{
+			EnabledState:     ptr.To("Enabled"),
+			HostName:         ptr.To(export.Spec.LoadBalancerIngress[0]),
+			HTTPPort:         backend.Spec.HTTPPort,
+			HTTPSPort:        backend.Spec.HTTPSPort,
+			OriginHostHeader: backend.Spec.OriginHostHeader,
+			Priority:         ptr.To(priority),
+			Weight:           ptr.To(weight),
+		},
+	}
+}
+
+// clusterTrafficSettings returns the priority and the weight of the cluster specified by the policy.
+func clusterTrafficSettings(policy *fleetnetv1alpha1.BackendTrafficPolicy, cluster string) (int32, int32) {
+	priority := ptr.Deref(policy.Spec.DefaultPriority, defaultOriginPriority)
+	weight := ptr.Deref(policy.Spec.DefaultWeight, defaultOriginWeight)
+	for _, settings := range policy.Spec.Clusters {
+		if settings.Cluster != cluster {
+			continue
+		}
+		if settings.Priority != nil {
+			priority = *settings.Priority
+		}
+		if settings.Weight != nil {
+			weight = *settings.Weight
+		}
+		break
+	}
+	return priority, weight
+}
+
+func equalAzureOriginGroup(current, desired azurefrontdoor.AFDOriginGroup) bool {
+	if current.Properties == nil || current.Properties.HealthProbeSettings == nil || current.Properties.LoadBalancingSettings == nil {
+		return false
+	}
+	currentProbe, desiredProbe := current.Properties.HealthProbeSettings, desired.Properties.HealthProbeSettings
+	currentLB, desiredLB := current.Properties.LoadBalancingSettings, desired.Properties.LoadBalancingSettings
+	return ptr.Equal(currentProbe.ProbeIntervalInSeconds, desiredProbe.ProbeIntervalInSeconds) &&
+		ptr.Equal(currentProbe.ProbePath, desiredProbe.ProbePath) &&
+		ptr.Equal(currentProbe.ProbeProtocol, desiredProbe.ProbeProtocol) &&
+		ptr.Equal(currentProbe.ProbeRequestType, desiredProbe.ProbeRequestType) &&
+		ptr.Equal(currentLB.AdditionalLatencyInMilliseconds, desiredLB.AdditionalLatencyInMilliseconds) &&
+		ptr.Equal(currentLB.SampleSize, desiredLB.SampleSize) &&
+		ptr.Equal(currentLB.SuccessfulSamplesRequired, desiredLB.SuccessfulSamplesRequired)
+}
+
+func equalAzureOrigin(current, desired azurefrontdoor.AFDOrigin) bool {
+	if current.Properties == nil {
+		return false
+	}
+	c, d := current.Properties, desired.Properties
+	return ptr.Equal(c.EnabledState, d.EnabledState) &&
+		ptr.Equal(c.HostName, d.HostName) &&
+		ptr.Equal(c.HTTPPort, d.HTTPPort) &&
+		ptr.Equal(c.HTTPSPort, d.HTTPSPort) &&
+		ptr.Equal(c.OriginHostHeader, d.OriginHostHeader) &&
+		ptr.Equal(c.Priority, d.Priority) &&
+		ptr.Equal(c.Weight, d.Weight)
+}
+
+func buildAcceptedOriginStatus(origin *azurefrontdoor.AFDOrigin, cluster string) fleetnetv1alpha1.FrontDoorOriginStatus {
+	return fleetnetv1alpha1.FrontDoorOriginStatus{
+		Name:     *origin.Name,
+		Cluster:  cluster,
+		HostName: ptr.Deref(origin.Properties.HostName, ""),
+		Priority: origin.Properties.Priority,
+		Weight:   origin.Properties.Weight,
+	}
+}
+
+// updateOrigins deletes the stale origins of the origin group and creates or updates the desired ones, and returns
+// the accepted origins and the errors of the origins rejected by the Azure Front Door.
+func (r *Reconciler) updateOrigins(ctx context.Context, backend *fleetnetv1alpha1.FrontDoorBackend, originGroupName string, desiredOrigins map[string]desiredOrigin) ([]fleetnetv1alpha1.FrontDoorOriginStatus, []error, error) {
+	backendKObj := klog.KObj(backend)
+	profile := backend.Spec.Profile
+	origins, listErr := r.FrontDoorClient.ListOrigins(ctx, profile.ResourceGroup, profile.Name, originGroupName)
+	if listErr != nil {
+		klog.ErrorS(listErr, "Failed to list the Azure Front Door origins", "frontDoorBackend", backendKObj, "originGroup", originGroupName)
+		setUnknownCondition(backend, fmt.Sprintf("Failed to list the origins of %q: %v", originGroupName, listErr))
+		if err := r.updateFrontDoorBackendStatus(ctx, backend); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, listErr
+	}
+
+	acceptedOrigins := make([]fleetnetv1alpha1.FrontDoorOriginStatus, 0, len(desiredOrigins))
+	for _, origin := range origins {
+		if origin.Name == nil {
+			err := controller.NewUnexpectedBehaviorError(errors.New("azure Front Door origin name is nil"))
+			klog.ErrorS(err, "Invalid Azure Front Door origin", "afdOrigin", origin)
+			continue
+		}
+		originName := strings.ToLower(*origin.Name) // resource names are case-insensitive
+		desired, ok := desiredOrigins[originName]
+		if !ok {
+			// The origin group is owned by the backend, so are all the origins.
+			klog.V(2).InfoS("Deleting the Azure Front Door origin", "frontDoorBackend", backendKObj, "originGroup", originGroupName, "afdOrigin", originName)
+			if deleteErr := r.FrontDoorClient.DeleteOrigin(ctx, profile.ResourceGroup, profile.Name, originGroupName, *origin.Name); deleteErr != nil {
+				if azureerrors.IsNotFound(deleteErr) {
+					klog.V(2).InfoS("Ignoring NotFound Azure Front Door origin", "frontDoorBackend", backendKObj, "originGroup", originGroupName, "afdOrigin", originName)
+					continue
+				}
+				klog.ErrorS(deleteErr, "Failed to delete the Azure Front Door origin", "frontDoorBackend", backendKObj, "originGroup", originGroupName, "afdOrigin", originName)
+				setUnknownCondition(backend, fmt.Sprintf("Failed to cleanup the existing %q for %q: %v", originName, originGroupName, deleteErr))
+				if err := r.updateFrontDoorBackendStatus(ctx, backend); err != nil {
+					return nil, nil, err
+				}
+				return nil, nil, deleteErr
+			}
+			klog.V(2).InfoS("Deleted the Azure Front Door origin", "frontDoorBackend", backendKObj, "originGroup", originGroupName, "afdOrigin", originName)
+			continue
+		}
+		if equalAzureOrigin(*origin, desired.Origin) {
+			klog.V(2).InfoS("Skipping updating the existing Azure Front Door origin", "frontDoorBackend", backendKObj, "originGroup", originGroupName, "afdOrigin", originName)
+			delete(desiredOrigins, originName) // no need to update the existing origin
+			acceptedOrigins = append(acceptedOrigins, buildAcceptedOriginStatus(&desired.Origin, desired.Cluster))
+		}
+	}
+
+	badOriginsErr := make([]error, 0, len(desiredOrigins))
+	for originName, desired := range desiredOrigins {
+		klog.V(2).InfoS("Creating or updating Azure Front Door origin", "frontDoorBackend", backendKObj, "originGroup", originGroupName, "afdOrigin", originName)
+		res, updateErr := r.FrontDoorClient.CreateOrUpdateOrigin(ctx, profile.ResourceGroup, profile.Name, originGroupName, originName, desired.Origin)
+		if updateErr != nil {
+			klog.ErrorS(updateErr, "Failed to create or update the Azure Front Door origin", "frontDoorBackend", backendKObj, "originGroup", originGroupName, "afdOrigin", originName)
+			if azureerrors.IsClientError(updateErr) && !azureerrors.IsThrottled(updateErr) {
+				badOriginsErr = append(badOriginsErr, updateErr)
+				continue
+			}
+			setUnknownCondition(backend, fmt.Sprintf("Failed to create or update %q for %q: %v", originName, originGroupName, updateErr))
+			if err := r.updateFrontDoorBackendStatus(ctx, backend); err != nil {
+				return nil, nil, err
+			}
+			return nil, nil, updateErr
+		}
+		if res.Name == nil || res.Properties == nil {
+			res = desired.Origin
+		}
+		klog.V(2).InfoS("Created or updated Azure Front Door origin", "frontDoorBackend", backendKObj, "originGroup", originGroupName, "afdOrigin", originName)
+		acceptedOrigins = append(acceptedOrigins, buildAcceptedOriginStatus(&res, desired.Cluster))
+	}
+	sort.Slice(acceptedOrigins, func(i, j int) bool {
+		return acceptedOrigins[i].Cluster < acceptedOrigins[j].Cluster
+	})
+	klog.V(2).InfoS("Successfully updated the Azure Front Door origins", "frontDoorBackend", backendKObj, "originGroup", originGroupName, "numberOfAcceptedOrigins", len(acceptedOrigins), "numberOfBadOrigins", len(badOriginsErr))
+	return acceptedOrigins, badOriginsErr, nil
+}
+
+func setFalseCondition(backend *fleetnetv1alpha1.FrontDoorBackend, acceptedOrigins []fleetnetv1alpha1.FrontDoorOriginStatus, message string) {
+	cond := metav1.Condition{
+		Type:               string(fleetnetv1alpha1.FrontDoorBackendConditionAccepted),
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: backend.Generation,
+		Reason:             string(fleetnetv1alpha1.FrontDoorBackendReasonInvalid),
+		Message:            message,
+	}
+	if len(acceptedOrigins) == 0 {
+		backend.Status.Origins = []fleetnetv1alpha1.FrontDoorOriginStatus{}
+	} else {
+		backend.Status.Origins = acceptedOrigins
+	}
+	meta.SetStatusCondition(&backend.Status.Conditions, cond)
+}
+
+func setUnknownCondition(backend *fleetnetv1alpha1.FrontDoorBackend, message string) {
+	cond := metav1.Condition{
+		Type:               string(fleetnetv1alpha1.FrontDoorBackendConditionAccepted),
+		Status:             metav1.ConditionUnknown,
+		ObservedGeneration: backend.Generation,
+		Reason:             string(fleetnetv1alpha1.FrontDoorBackendReasonPending),
+		Message:            message,
+	}
+	meta.SetStatusCondition(&backend.Status.Conditions, cond)
+}
+
+func setTrueCondition(backend *fleetnetv1alpha1.FrontDoorBackend, acceptedOrigins []fleetnetv1alpha1.FrontDoorOriginStatus) {
+	cond := metav1.Condition{
+		Type:               string(fleetnetv1alpha1.FrontDoorBackendConditionAccepted),
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: backend.Generation,
+		Reason:             string(fleetnetv1alpha1.FrontDoorBackendReasonAccepted),
+		Message:            fmt.Sprintf("%v service(s) exported from clusters have been accepted as Azure Front Door origins", len(acceptedOrigins)),
+	}
+	backend.Status.Origins = acceptedOrigins
+	meta.SetStatusCondition(&backend.Status.Conditions, cond)
+}
+
+func (r *Reconciler) updateFrontDoorBackendStatus(ctx context.Context, backend *fleetnetv1alpha1.FrontDoorBackend) error {
+	backendKObj := klog.KObj(backend)
+	if err := r.Client.Status().Update(ctx, backend); err != nil {
+		klog.ErrorS(err, "Failed to update frontDoorBackend status", "frontDoorBackend", backendKObj)
+		return controller.NewUpdateIgnoreConflictError(err)
+	}
+	klog.V(2).InfoS("Updated frontDoorBackend status", "frontDoorBackend", backendKObj, "status", backend.Status)
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// The internalServiceExport indexer is expected to be set up by the serviceImport controller; it can be registered
+// here by setting disableInternalServiceExportIndexer to false.
+func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, disableInternalServiceExportIndexer bool) error {
+	backendIndexerFunc := func(o client.Object) []string {
+		fdb, ok := o.(*fleetnetv1alpha1.FrontDoorBackend)
+		if !ok {
+			return []string{}
+		}
+		return []string{fdb.Spec.Backend.Name}
+	}
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &fleetnetv1alpha1.FrontDoorBackend{}, frontDoorBackendBackendFieldKey, backendIndexerFunc); err != nil {
+		klog.ErrorS(err, "Failed to setup backend field indexer for FrontDoorBackend")
+		return err
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &fleetnetv1alpha1.FrontDoorBackend{}, frontDoorBackendTrafficPolicyFieldKey, trafficPolicyIndexerFunc); err != nil {
+		klog.ErrorS(err, "Failed to setup traffic policy field indexer for FrontDoorBackend")
+		return err
+	}
+
+	if !disableInternalServiceExportIndexer {
+		internalServiceExportIndexerFunc := func(o client.Object) []string {
+			name, ok := o.(*fleetnetv1alpha1.InternalServiceExport)
+			if !ok {
+				return []string{}
+			}
+			return []string{name.Spec.ServiceReference.NamespacedName}
+		}
+		if err := mgr.GetFieldIndexer().IndexField(ctx, &fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldNamespacedName, internalServiceExportIndexerFunc); err != nil {
+			klog.ErrorS(err, "Failed to create index", "field", exportedServiceFieldNamespacedName)
+			return err
+		}
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&fleetnetv1alpha1.FrontDoorBackend{}).
+		Watches(
+			&fleetnetv1alpha1.ServiceImport{},
+			handler.EnqueueRequestsFromMapFunc(r.serviceImportEventHandler()),
+		).
+		Watches(
+			&fleetnetv1alpha1.InternalServiceExport{},
+			handler.EnqueueRequestsFromMapFunc(r.internalServiceExportEventHandler()),
+		).
+		Watches(
+			&fleetnetv1alpha1.BackendTrafficPolicy{},
+			handler.EnqueueRequestsFromMapFunc(r.backendTrafficPolicyEventHandler()),
+		).
+		WithOptions(r.ControllerOptions).
+		Complete(metrics.InstrumentReconciler("frontdoorbackend", r))
+}
+
+func trafficPolicyIndexerFunc(o client.Object) []string {
+	fdb, ok := o.(*fleetnetv1alpha1.FrontDoorBackend)
+	if !ok || fdb.Spec.TrafficPolicyRef == nil {
+		return []string{}
+	}
+	return []string{fdb.Spec.TrafficPolicyRef.Name}
+}
+
+func (r *Reconciler) serviceImportEventHandler() handler.MapFunc {
+	return func(ctx context.Context, object client.Object) []reconcile.Request {
+		return r.enqueueFrontDoorBackends(ctx, object.GetNamespace(), frontDoorBackendBackendFieldKey, object.GetName())
+	}
+}
+
+func (r *Reconciler) internalServiceExportEventHandler() handler.MapFunc {
+	return func(ctx context.Context, object client.Object) []reconcile.Request {
+		internalServiceExport, ok := object.(*fleetnetv1alpha1.InternalServiceExport)
+		if !ok {
+			return []reconcile.Request{}
+		}
+		serviceReference := internalServiceExport.Spec.ServiceReference
+		return r.enqueueFrontDoorBackends(ctx, serviceReference.Namespace, frontDoorBackendBackendFieldKey, serviceReference.Name)
+	}
+}
+
+func (r *Reconciler) backendTrafficPolicyEventHandler() handler.MapFunc {
+	return func(ctx context.Context, object client.Object) []reconcile.Request {
+		return r.enqueueFrontDoorBackends(ctx, object.GetNamespace(), frontDoorBackendTrafficPolicyFieldKey, object.GetName())
+	}
+}
+
+func (r *Reconciler) enqueueFrontDoorBackends(ctx context.Context, namespace, fieldKey, name string) []reconcile.Request {
+	frontDoorBackendList := &fleetnetv1alpha1.FrontDoorBackendList{}
+	fieldMatcher := client.MatchingFields{
+		fieldKey: name,
+	}
+	if err := r.Client.List(ctx, frontDoorBackendList, client.InNamespace(namespace), fieldMatcher); err != nil {
+		klog.ErrorS(err, "Failed to list frontDoorBackends", "namespace", namespace, "field", fieldKey, "name", name)
+		return []reconcile.Request{}
+	}
+
+	res := make([]reconcile.Request, 0, len(frontDoorBackendList.Items))
+	for _, backend := range frontDoorBackendList.Items {
+		res = append(res, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: backend.Namespace,
+				Name:      backend.Name,
+			},
+		})
+	}
+	return res
+}
@@ -0,0 +1,532 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package frontdoorbackend
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/azurefrontdoor"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+const (
+	testNamespace     = "work"
+	testBackendName   = "app-fdb"
+	testServiceName   = "app"
+	testPolicyName    = "app-policy"
+	testResourceGroup = "rg"
+	testProfileName   = "afd"
+	testBackendUID    = "00000000-0000-0000-0000-000000000001"
+	testOriginGroup   = "fleet-" + testBackendUID
+)
+
+// fakeFrontDoorClient is an in-memory implementation of azurefrontdoor.Interface, which only manages the origin group
+// of the test backend.
+type fakeFrontDoorClient struct {
+	originGroup       *azurefrontdoor.AFDOriginGroup
+	origins           map[string]azurefrontdoor.AFDOrigin
+	originGroupErr    error
+	originGroupWrites int
+}
+
+var _ azurefrontdoor.Interface = &fakeFrontDoorClient{}
+
+func notFoundError() error {
+	return &azcore.ResponseError{StatusCode: http.StatusNotFound}
+}
+
+func (f *fakeFrontDoorClient) GetOriginGroup(_ context.Context, _, _, _ string) (azurefrontdoor.AFDOriginGroup, error) {
+	if f.originGroup == nil {
+		return azurefrontdoor.AFDOriginGroup{}, notFoundError()
+	}
+	return *f.originGroup, nil
+}
+
+func (f *fakeFrontDoorClient) CreateOrUpdateOriginGroup(_ context.Context, _, _, name string, originGroup azurefrontdoor.AFDOriginGroup) (azurefrontdoor.AFDOriginGroup, error) {
+	if f.originGroupErr != nil {
+		return azurefrontdoor.AFDOriginGroup{}, f.originGroupErr
+	}
+	f.originGroupWrites++
+	originGroup.Name = ptr.To(name)
+	f.originGroup = &originGroup
+	return originGroup, nil
+}
+
+func (f *fakeFrontDoorClient) DeleteOriginGroup(_ context.Context, _, _, _ string) error {
+	if f.originGroup == nil {
+		return notFoundError()
+	}
+	f.originGroup = nil
+	f.origins = nil
+	return nil
+}
+
+func (f *fakeFrontDoorClient) ListOrigins(_ context.Context, _, _, _ string) ([]*azurefrontdoor.AFDOrigin, error) {
+	if f.originGroup == nil {
+		return nil, notFoundError()
+	}
+	res := make([]*azurefrontdoor.AFDOrigin, 0, len(f.origins))
+	for name := range f.origins {
+		origin := f.origins[name]
+		origin.Name = ptr.To(name)
+		res = append(res, &origin)
+	}
+	return res, nil
+}
+
+func (f *fakeFrontDoorClient) CreateOrUpdateOrigin(_ context.Context, _, _, _, name string, origin azurefrontdoor.AFDOrigin) (azurefrontdoor.AFDOrigin, error) {
+	if f.origins == nil {
+		f.origins = map[string]azurefrontdoor.AFDOrigin{}
+	}
+	origin.Name = ptr.To(name)
+	f.origins[name] = origin
+	return origin, nil
+}
+
+func (f *fakeFrontDoorClient) DeleteOrigin(_ context.Context, _, _, _, name string) error {
+	if _, ok := f.origins[name]; !ok {
+		return notFoundError()
+	}
+	delete(f.origins, name)
+	return nil
+}
+
+func frontDoorBackendForTest() *fleetnetv1alpha1.FrontDoorBackend {
+	return &fleetnetv1alpha1.FrontDoorBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  testNamespace,
+			Name:       testBackendName,
+			UID:        testBackendUID,
+			Finalizers: []string{objectmeta.FrontDoorBackendFinalizer},
+		},
+		Spec: fleetnetv1alpha1.FrontDoorBackendSpec{
+			Profile:          fleetnetv1alpha1.FrontDoorProfileRef{ResourceGroup: testResourceGroup, Name: testProfileName},
+			Backend:          fleetnetv1alpha1.TrafficManagerBackendRef{Name: testServiceName},
+			TrafficPolicyRef: &fleetnetv1alpha1.BackendTrafficPolicyRef{Name: testPolicyName},
+		},
+	}
+}
+
+func serviceImportForTest(clusters ...string) *fleetnetv1alpha1.ServiceImport {
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testServiceName},
+	}
+	for _, cluster := range clusters {
+		serviceImport.Status.Clusters = append(serviceImport.Status.Clusters, fleetnetv1alpha1.ServiceImportClusterStatus{Cluster: cluster})
+	}
+	return serviceImport
+}
+
+func internalServiceExportForTest(cluster string, svcType corev1.ServiceType, ingress ...string) *fleetnetv1alpha1.InternalServiceExport {
+	return &fleetnetv1alpha1.InternalServiceExport{
+		ObjectMeta: metav1.ObjectMeta{Namespace: cluster, Name: testNamespace + "-" + testServiceName},
+		Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+			Type:                svcType,
+			LoadBalancerIngress: ingress,
+			ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+				ClusterID:      cluster,
+				Namespace:      testNamespace,
+				Name:           testServiceName,
+				NamespacedName: testNamespace + "/" + testServiceName,
+			},
+		},
+	}
+}
+
+func backendTrafficPolicyForTest() *fleetnetv1alpha1.BackendTrafficPolicy {
+	return &fleetnetv1alpha1.BackendTrafficPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testPolicyName},
+		Spec: fleetnetv1alpha1.BackendTrafficPolicySpec{
+			Clusters: []fleetnetv1alpha1.ClusterTrafficSettings{
+				{Cluster: "member-2", Priority: ptr.To(int32(2)), Weight: ptr.To(int32(500))},
+			},
+		},
+	}
+}
+
+func desiredOriginForTest(hostName string, priority, weight int32) azurefrontdoor.AFDOrigin {
+	return azurefrontdoor.AFDOrigin{
+		Properties: &azurefrontdoor.AFDOriginProperties{
+			EnabledState: ptr.To("Enabled"),
+			HostName:     ptr.To(hostName),
+			HTTPPort:     ptr.To(int32(80)),
+			HTTPSPort:    ptr.To(int32(443)),
+			Priority:     ptr.To(priority),
+			Weight:       ptr.To(weight),
+		},
+	}
+}
+
+func TestIsValidFrontDoorOrigin(t *testing.T) {
+	tests := []struct {
+		name    string
+		export  *fleetnetv1alpha1.InternalServiceExport
+		wantErr bool
+	}{
+		{
+			name:   "valid origin",
+			export: internalServiceExportForTest("member-1", corev1.ServiceTypeLoadBalancer, "20.0.0.1"),
+		},
+		{
+			name:    "wrong service type",
+			export:  internalServiceExportForTest("member-1", corev1.ServiceTypeClusterIP),
+			wantErr: true,
+		},
+		{
+			name: "internal load balancer",
+			export: func() *fleetnetv1alpha1.InternalServiceExport {
+				export := internalServiceExportForTest("member-1", corev1.ServiceTypeLoadBalancer, "10.0.0.1")
+				export.Spec.IsInternalLoadBalancer = true
+				return export
+			}(),
+			wantErr: true,
+		},
+		{
+			name:    "load balancer ingress not assigned",
+			export:  internalServiceExportForTest("member-1", corev1.ServiceTypeLoadBalancer),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := isValidFrontDoorOrigin(tt.export)
+			if got := err != nil; got != tt.wantErr {
+				t.Errorf("isValidFrontDoorOrigin() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateAzureOriginName(t *testing.T) {
+	tests := []struct {
+		name    string
+		cluster string
+		want    string
+	}{
+		{
+			name:    "valid name",
+			cluster: "member-1",
+			want:    "member-1",
+		},
+		{
+			name:    "name with dots and upper case letters",
+			cluster: "Member.East.1",
+			want:    "member-east-1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := generateAzureOriginName(tt.cluster); got != tt.want {
+				t.Errorf("generateAzureOriginName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClusterTrafficSettings(t *testing.T) {
+	tests := []struct {
+		name         string
+		policy       *fleetnetv1alpha1.BackendTrafficPolicy
+		cluster      string
+		wantPriority int32
+		wantWeight   int32
+	}{
+		{
+			name:         "empty policy",
+			policy:       &fleetnetv1alpha1.BackendTrafficPolicy{},
+			cluster:      "member-1",
+			wantPriority: 1,
+			wantWeight:   1000,
+		},
+		{
+			name:         "cluster listed in the policy",
+			policy:       backendTrafficPolicyForTest(),
+			cluster:      "member-2",
+			wantPriority: 2,
+			wantWeight:   500,
+		},
+		{
+			name: "cluster not listed in the policy",
+			policy: func() *fleetnetv1alpha1.BackendTrafficPolicy {
+				policy := backendTrafficPolicyForTest()
+				policy.Spec.DefaultPriority = ptr.To(int32(3))
+				policy.Spec.DefaultWeight = ptr.To(int32(10))
+				return policy
+			}(),
+			cluster:      "member-1",
+			wantPriority: 3,
+			wantWeight:   10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotPriority, gotWeight := clusterTrafficSettings(tt.policy, tt.cluster)
+			if gotPriority != tt.wantPriority || gotWeight != tt.wantWeight {
+				t.Errorf("clusterTrafficSettings() = (%v, %v), want (%v, %v)", gotPriority, gotWeight, tt.wantPriority, tt.wantWeight)
+			}
+		})
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	deletingBackend := frontDoorBackendForTest()
+	deletingBackend.DeletionTimestamp = ptr.To(metav1.Now())
+
+	tests := []struct {
+		name            string
+		backend         *fleetnetv1alpha1.FrontDoorBackend
+		objects         []client.Object
+		afd             *fakeFrontDoorClient
+		wantErr         bool
+		wantOrigins     map[string]azurefrontdoor.AFDOrigin
+		wantOriginGroup bool
+		wantStatus      fleetnetv1alpha1.FrontDoorBackendStatus
+		wantDeleted     bool
+	}{
+		{
+			name:    "origins are created for the exported services",
+			backend: frontDoorBackendForTest(),
+			objects: []client.Object{
+				serviceImportForTest("member-1", "member-2"),
+				internalServiceExportForTest("member-1", corev1.ServiceTypeLoadBalancer, "20.0.0.1"),
+				internalServiceExportForTest("member-2", corev1.ServiceTypeLoadBalancer, "app.westus.cloudapp.azure.com"),
+				backendTrafficPolicyForTest(),
+			},
+			afd: &fakeFrontDoorClient{},
+			wantOrigins: map[string]azurefrontdoor.AFDOrigin{
+				"member-1": desiredOriginForTest("20.0.0.1", 1, 1000),
+				"member-2": desiredOriginForTest("app.westus.cloudapp.azure.com", 2, 500),
+			},
+			wantOriginGroup: true,
+			wantStatus: fleetnetv1alpha1.FrontDoorBackendStatus{
+				OriginGroup: testOriginGroup,
+				Origins: []fleetnetv1alpha1.FrontDoorOriginStatus{
+					{Name: "member-1", Cluster: "member-1", HostName: "20.0.0.1", Priority: ptr.To(int32(1)), Weight: ptr.To(int32(1000))},
+					{Name: "member-2", Cluster: "member-2", HostName: "app.westus.cloudapp.azure.com", Priority: ptr.To(int32(2)), Weight: ptr.To(int32(500))},
+				},
+				Conditions: []metav1.Condition{
+					{
+						Type:    string(fleetnetv1alpha1.FrontDoorBackendConditionAccepted),
+						Status:  metav1.ConditionTrue,
+						Reason:  string(fleetnetv1alpha1.FrontDoorBackendReasonAccepted),
+						Message: "2 service(s) exported from clusters have been accepted as Azure Front Door origins",
+					},
+				},
+			},
+		},
+		{
+			name:    "stale origins are deleted and invalid services are reported",
+			backend: frontDoorBackendForTest(),
+			objects: []client.Object{
+				serviceImportForTest("member-1", "member-3"),
+				internalServiceExportForTest("member-1", corev1.ServiceTypeLoadBalancer, "20.0.0.2"),
+				internalServiceExportForTest("member-3", corev1.ServiceTypeClusterIP),
+				backendTrafficPolicyForTest(),
+			},
+			afd: &fakeFrontDoorClient{
+				originGroup: &azurefrontdoor.AFDOriginGroup{},
+				origins: map[string]azurefrontdoor.AFDOrigin{
+					"member-1": desiredOriginForTest("20.0.0.1", 1, 1000),
+					"member-2": desiredOriginForTest("20.0.1.1", 2, 500),
+				},
+			},
+			wantOrigins: map[string]azurefrontdoor.AFDOrigin{
+				"member-1": desiredOriginForTest("20.0.0.2", 1, 1000),
+			},
+			wantOriginGroup: true,
+			wantStatus: fleetnetv1alpha1.FrontDoorBackendStatus{
+				OriginGroup: testOriginGroup,
+				Origins: []fleetnetv1alpha1.FrontDoorOriginStatus{
+					{Name: "member-1", Cluster: "member-1", HostName: "20.0.0.2", Priority: ptr.To(int32(1)), Weight: ptr.To(int32(1000))},
+				},
+				Conditions: []metav1.Condition{
+					{
+						Type:    string(fleetnetv1alpha1.FrontDoorBackendConditionAccepted),
+						Status:  metav1.ConditionFalse,
+						Reason:  string(fleetnetv1alpha1.FrontDoorBackendReasonInvalid),
+						Message: "1 service(s) exported from clusters cannot be exposed as the Azure Front Door origins, for example, service exported from member-3 is invalid: unsupported service type \"ClusterIP\"",
+					},
+				},
+			},
+		},
+		{
+			name:    "origins are deleted when the serviceImport is not found",
+			backend: frontDoorBackendForTest(),
+			objects: []client.Object{backendTrafficPolicyForTest()},
+			afd: &fakeFrontDoorClient{
+				originGroup: &azurefrontdoor.AFDOriginGroup{},
+				origins: map[string]azurefrontdoor.AFDOrigin{
+					"member-1": desiredOriginForTest("20.0.0.1", 1, 1000),
+				},
+			},
+			wantOrigins:     map[string]azurefrontdoor.AFDOrigin{},
+			wantOriginGroup: true,
+			wantStatus: fleetnetv1alpha1.FrontDoorBackendStatus{
+				OriginGroup: testOriginGroup,
+				Conditions: []metav1.Condition{
+					{
+						Type:    string(fleetnetv1alpha1.FrontDoorBackendConditionAccepted),
+						Status:  metav1.ConditionFalse,
+						Reason:  string(fleetnetv1alpha1.FrontDoorBackendReasonInvalid),
+						Message: "ServiceImport \"app\" is not found",
+					},
+				},
+			},
+		},
+		{
+			name:    "traffic policy is not found",
+			backend: frontDoorBackendForTest(),
+			objects: []client.Object{
+				serviceImportForTest("member-1"),
+				internalServiceExportForTest("member-1", corev1.ServiceTypeLoadBalancer, "20.0.0.1"),
+			},
+			afd:             &fakeFrontDoorClient{},
+			wantOriginGroup: true,
+			wantStatus: fleetnetv1alpha1.FrontDoorBackendStatus{
+				Conditions: []metav1.Condition{
+					{
+						Type:    string(fleetnetv1alpha1.FrontDoorBackendConditionAccepted),
+						Status:  metav1.ConditionFalse,
+						Reason:  string(fleetnetv1alpha1.FrontDoorBackendReasonInvalid),
+						Message: "BackendTrafficPolicy \"app-policy\" is not found",
+					},
+				},
+			},
+		},
+		{
+			name:    "profile is not found",
+			backend: frontDoorBackendForTest(),
+			afd:     &fakeFrontDoorClient{originGroupErr: &azcore.ResponseError{StatusCode: http.StatusNotFound, ErrorCode: "ParentResourceNotFound"}},
+			wantStatus: fleetnetv1alpha1.FrontDoorBackendStatus{
+				Conditions: []metav1.Condition{
+					{
+						Type:   string(fleetnetv1alpha1.FrontDoorBackendConditionAccepted),
+						Status: metav1.ConditionFalse,
+						Reason: string(fleetnetv1alpha1.FrontDoorBackendReasonInvalid),
+					},
+				},
+			},
+		},
+		{
+			name:    "failing to create the origin group",
+			backend: frontDoorBackendForTest(),
+			afd:     &fakeFrontDoorClient{originGroupErr: &azcore.ResponseError{StatusCode: http.StatusInternalServerError}},
+			wantErr: true,
+			wantStatus: fleetnetv1alpha1.FrontDoorBackendStatus{
+				Conditions: []metav1.Condition{
+					{
+						Type:   string(fleetnetv1alpha1.FrontDoorBackendConditionAccepted),
+						Status: metav1.ConditionUnknown,
+						Reason: string(fleetnetv1alpha1.FrontDoorBackendReasonPending),
+					},
+				},
+			},
+		},
+		{
+			name:    "origin group is deleted with the backend",
+			backend: deletingBackend,
+			afd: &fakeFrontDoorClient{
+				originGroup: &azurefrontdoor.AFDOriginGroup{},
+				origins: map[string]azurefrontdoor.AFDOrigin{
+					"member-1": desiredOriginForTest("20.0.0.1", 1, 1000),
+				},
+			},
+			wantDeleted: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			objects := append([]client.Object{tt.backend}, tt.objects...)
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(objects...).
+				WithStatusSubresource(&fleetnetv1alpha1.FrontDoorBackend{}).
+				WithIndex(&fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldNamespacedName, func(o client.Object) []string {
+					return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.NamespacedName}
+				}).
+				Build()
+			r := &Reconciler{Client: fakeClient, FrontDoorClient: tt.afd}
+
+			name := types.NamespacedName{Namespace: testNamespace, Name: testBackendName}
+			_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: name})
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Fatalf("Reconcile() = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			got := &fleetnetv1alpha1.FrontDoorBackend{}
+			getErr := fakeClient.Get(context.Background(), name, got)
+			if tt.wantDeleted {
+				if getErr == nil || tt.afd.originGroup != nil {
+					t.Errorf("Reconcile() got backend error %v and origin group %v, want both deleted", getErr, tt.afd.originGroup)
+				}
+				return
+			}
+			if getErr != nil {
+				t.Fatalf("failed to get frontDoorBackend: %v", getErr)
+			}
+			if gotOriginGroup := tt.afd.originGroup != nil; gotOriginGroup != tt.wantOriginGroup {
+				t.Errorf("Reconcile() origin group exists = %v, want %v", gotOriginGroup, tt.wantOriginGroup)
+			}
+			if tt.wantOrigins != nil {
+				if diff := cmp.Diff(tt.wantOrigins, tt.afd.origins, cmpopts.IgnoreFields(azurefrontdoor.AFDOrigin{}, "Name"), cmpopts.EquateEmpty()); diff != "" {
+					t.Errorf("Reconcile() origins mismatch (-want, +got):\n%s", diff)
+				}
+			}
+			ignoreOptions := []cmp.Option{
+				cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime"),
+			}
+			if tt.wantStatus.Conditions != nil && tt.wantStatus.Conditions[0].Message == "" {
+				ignoreOptions = append(ignoreOptions, cmpopts.IgnoreFields(metav1.Condition{}, "Message"))
+			}
+			if diff := cmp.Diff(tt.wantStatus, got.Status, ignoreOptions...); diff != "" {
+				t.Errorf("Reconcile() status mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReconcile_SkipUpdatingOriginGroup(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(frontDoorBackendForTest(), backendTrafficPolicyForTest(), serviceImportForTest()).
+		WithStatusSubresource(&fleetnetv1alpha1.FrontDoorBackend{}).
+		Build()
+	afd := &fakeFrontDoorClient{}
+	r := &Reconciler{Client: fakeClient, FrontDoorClient: afd}
+
+	name := types.NamespacedName{Namespace: testNamespace, Name: testBackendName}
+	for i := 0; i < 2; i++ {
+		if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: name}); err != nil {
+			t.Fatalf("Reconcile() = %v, want no error", err)
+		}
+	}
+	if afd.originGroupWrites != 1 {
+		t.Errorf("CreateOrUpdateOriginGroup() called %v times, want 1", afd.originGroupWrites)
+	}
+}
@@ -9,29 +9,126 @@ package internalserviceexport
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	clusterv1beta1 "go.goms.io/fleet/apis/cluster/v1beta1"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/apply"
+	"go.goms.io/fleet-networking/pkg/common/clusterhealth"
+	"go.goms.io/fleet-networking/pkg/common/clusterquarantine"
 	"go.goms.io/fleet-networking/pkg/common/condition"
+	"go.goms.io/fleet-networking/pkg/common/conflictnotify"
+	"go.goms.io/fleet-networking/pkg/common/conflictresolution"
+	"go.goms.io/fleet-networking/pkg/common/exportapproval"
+	"go.goms.io/fleet-networking/pkg/common/exportdenylist"
+	"go.goms.io/fleet-networking/pkg/common/exportquota"
+	"go.goms.io/fleet-networking/pkg/common/fieldindex"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
 	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/sharding"
+	"go.goms.io/fleet-networking/pkg/common/staleexport"
+	"go.goms.io/fleet-networking/pkg/common/tenancy"
+	"go.goms.io/fleet-networking/pkg/common/tracing"
 )
 
 // Reconciler reconciles a InternalServiceExport object.
 type Reconciler struct {
 	client.Client
-	// RetryInternal is the wait time for the controller to requeue the request and to wait for the
-	// ServiceImport controller to resolve the service Spec.
+	// RetryInternal is the wait time for the controller to requeue the request while waiting for the ServiceImport
+	// controller to resolve the service spec. The controller is notified of the resolution by watching the
+	// serviceImports; the requeue is only a safety net against a missed event, and can be long.
 	RetryInternal time.Duration
+	// DenylistConfigMap is the ConfigMap holding the patterns of the services which must not be exported to the
+	// fleet; the denylist is disabled if the name is empty.
+	DenylistConfigMap types.NamespacedName
+	// ConflictNotifier, if set, is notified when an internalServiceExport runs into a new conflict.
+	ConflictNotifier *conflictnotify.Notifier
+	// EnableClusterQuarantine enables the quarantine of member clusters by labeling their MemberClusters in the
+	// hub cluster; it requires the MemberCluster API.
+	EnableClusterQuarantine bool
+	// ConflictResolver decides whether an export in conflict with the spec of its serviceImport outranks the
+	// winning export, in which case the spec is resolved again; the oldest export wins if it is not set.
+	ConflictResolver *conflictresolution.Resolver
+	// HealthEvaluator, if set, evaluates the health of the exports periodically, and fails the unhealthy exports
+	// over to the other member clusters by excluding them from their serviceImports.
+	HealthEvaluator *clusterhealth.Evaluator
+	// Recorder records the Events telling the users which exports are accepted into, are in conflict with, and are
+	// withdrawn from the serviceImports.
+	Recorder record.EventRecorder
+	// ReportImportDemand, if set, reports with the Imported condition whether any member cluster other than the
+	// exporting cluster imports the Service of each accepted internalServiceExport, so that the member clusters
+	// may only export the endpoints of the Services actually in demand.
+	ReportImportDemand bool
+	// StaleExportGracePeriod, if positive, is how long an internalServiceExport may stay marked as stale, as the
+	// networking agent of its member cluster has stopped renewing its heartbeat, before it is excluded from its
+	// serviceImport; the stale marks are ignored otherwise.
+	StaleExportGracePeriod time.Duration
+	// ExportApproval, if set, requires the exports of the Services in the namespaces it does not exempt to be
+	// approved by a fleet admin with a ServiceExportApproval before they join their serviceImports.
+	ExportApproval *exportapproval.Policy
+	// EnforceExportQuotas enforces the ExportQuotas of the hub cluster, excluding the exports which do not fit in
+	// them from their serviceImports.
+	EnforceExportQuotas bool
+	// EnableTenantIsolation partitions the fleet by the tenants of the member clusters, set with the tenant label on
+	// their namespaces; a serviceImport aggregates only the exports of the tenant owning it.
+	EnableTenantIsolation bool
+	// ServerSideApply, if set, writes the serviceImport status with server-side applies, the entry of each exporting
+	// cluster with the field manager of the cluster, so that the exports of the same service from different
+	// clusters never conflict with each other.
+	ServerSideApply bool
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
+}
+
+const (
+	// ControllerName is the name of the Reconciler.
+	ControllerName = "internalserviceexport-controller"
+
+	// exportedServiceFieldNamespacedName is the field index of the internalServiceExports by the namespaced name of
+	// their service, i.e. of their serviceImport.
+	exportedServiceFieldNamespacedName = fieldindex.InternalServiceExportServiceNamespacedName
+	// exportedServiceFieldClusterID is the field index of the internalServiceExports by the member cluster they are
+	// exported from.
+	exportedServiceFieldClusterID = ".spec.serviceReference.clusterID"
+
+	conditionReasonDeniedByHub        = "DeniedByHub"
+	conditionReasonClusterQuarantined = "ClusterQuarantined"
+	conditionReasonImported           = "ImportedByOtherClusters"
+	conditionReasonNotImported        = "NotImportedByOtherClusters"
+)
+
+// exclusionConditionTypes are the types of the conditions reporting that an internalServiceExport is excluded from
+// its serviceImport by the hub.
+var exclusionConditionTypes = []string{
+	string(fleetnetv1alpha1.ServiceExportDenied),
+	string(fleetnetv1alpha1.ServiceExportQuarantined),
+	string(fleetnetv1alpha1.ServiceExportTenantConflict),
+	string(fleetnetv1alpha1.ServiceExportPendingApproval),
+	string(fleetnetv1alpha1.ServiceExportQuotaExceeded),
+	string(fleetnetv1alpha1.ServiceExportStale),
+	string(fleetnetv1alpha1.ServiceExportUnhealthy),
 }
 
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalserviceexports,verbs=get;list;watch;create;update;patch;delete
@@ -39,6 +136,13 @@ type Reconciler struct {
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalserviceexports/finalizers,verbs=update
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports,verbs=get;list;watch;create;update;patch
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=endpointsliceexports,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceexportapprovals,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=exportquotas,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+//+kubebuilder:rbac:groups=cluster.kubernetes-fleet.io,resources=memberclusters,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile creates/updates ServiceImport by watching internalServiceExport objects.
 // To simplify the design and implementation in the first phase, the serviceExport will be marked as conflicted if its
@@ -49,20 +153,22 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	name := req.NamespacedName
 	internalServiceExport := fleetnetv1alpha1.InternalServiceExport{}
 	internalServiceExportKRef := klog.KRef(name.Namespace, name.Name)
+	logger := klog.FromContext(ctx).WithValues("internalServiceExport", internalServiceExportKRef)
+	ctx = klog.NewContext(ctx, logger)
 
 	startTime := time.Now()
-	klog.V(2).InfoS("Reconciliation starts", "internalServiceExport", internalServiceExportKRef)
+	logger.V(2).Info("Reconciliation starts")
 	defer func() {
 		latency := time.Since(startTime).Milliseconds()
-		klog.V(2).InfoS("Reconciliation ends", "internalServiceExport", internalServiceExportKRef, "latency", latency)
+		logger.V(2).Info("Reconciliation ends", "latency", latency)
 	}()
 
 	if err := r.Client.Get(ctx, name, &internalServiceExport); err != nil {
 		if errors.IsNotFound(err) {
-			klog.V(4).InfoS("Ignoring NotFound internalServiceExport", "internalServiceExport", internalServiceExportKRef)
+			logger.V(4).Info("Ignoring NotFound internalServiceExport")
 			return ctrl.Result{}, nil
 		}
-		klog.ErrorS(err, "Failed to get internalServiceExport", "internalServiceExport", internalServiceExportKRef)
+		logger.Error(err, "Failed to get internalServiceExport")
 		return ctrl.Result{}, err
 	}
 
@@ -74,53 +180,373 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	if !controllerutil.ContainsFinalizer(&internalServiceExport, objectmeta.InternalServiceExportFinalizer) {
 		controllerutil.AddFinalizer(&internalServiceExport, objectmeta.InternalServiceExportFinalizer)
 		if err := r.Update(ctx, &internalServiceExport); err != nil {
-			klog.ErrorS(err, "Failed to add internalServiceExport finalizer", "internalServiceExport", internalServiceExportKRef)
+			logger.Error(err, "Failed to add internalServiceExport finalizer")
+			return ctrl.Result{}, err
+		}
+	}
+
+	denylist, err := exportdenylist.Get(ctx, r.Client, r.DenylistConfigMap)
+	if err != nil {
+		logger.Error(err, "Failed to get the export denylist", "configMap", r.DenylistConfigMap)
+		return ctrl.Result{}, err
+	}
+	svcRef := internalServiceExport.Spec.ServiceReference
+	logger = logger.WithValues("clusterID", svcRef.ClusterID, "service", klog.KRef(svcRef.Namespace, svcRef.Name))
+	ctx = klog.NewContext(ctx, logger)
+	if denylist.Denies(svcRef.Namespace, svcRef.Name) {
+		logger.V(2).Info("Excluding the internalServiceExport denied by the hub")
+		return ctrl.Result{}, r.handleExcluded(ctx, &internalServiceExport, deniedCondition(&internalServiceExport))
+	}
+	if r.EnableClusterQuarantine {
+		quarantined, err := clusterquarantine.IsQuarantined(ctx, r.Client, svcRef.ClusterID)
+		if err != nil {
+			logger.Error(err, "Failed to check whether the member cluster is quarantined")
+			return ctrl.Result{}, err
+		}
+		if quarantined {
+			logger.V(2).Info("Excluding the internalServiceExport from a quarantined member cluster")
+			return ctrl.Result{}, r.handleExcluded(ctx, &internalServiceExport, quarantinedCondition(&internalServiceExport))
+		}
+	}
+	if clusterquarantine.IsQuarantinedExport(&internalServiceExport) {
+		// Lift the quarantine before rejoining the serviceImport, so that the serviceImport controller takes the
+		// export into account when resolving the service spec.
+		if err := r.liftExclusion(ctx, &internalServiceExport, string(fleetnetv1alpha1.ServiceExportQuarantined)); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if r.EnableTenantIsolation {
+		owner, owned, err := r.checkTenant(ctx, &internalServiceExport)
+		if err != nil {
+			logger.Error(err, "Failed to check the tenant owning the serviceImport")
+			return ctrl.Result{}, err
+		}
+		if !owned {
+			logger.V(2).Info("Excluding the internalServiceExport of a tenant not owning the serviceImport", "owner", owner)
+			return ctrl.Result{}, r.handleExcluded(ctx, &internalServiceExport, tenantConflictCondition(&internalServiceExport, owner))
+		}
+	}
+	if tenancy.IsConflictedExport(&internalServiceExport) {
+		logger.V(2).Info("ServiceImport is owned by the tenant of the internalServiceExport and the export is joining it")
+		if err := r.liftExclusion(ctx, &internalServiceExport, string(fleetnetv1alpha1.ServiceExportTenantConflict)); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if r.ExportApproval != nil {
+		approved, err := r.ExportApproval.IsApproved(ctx, r.Client, svcRef.ClusterID, svcRef.Namespace, svcRef.Name)
+		if err != nil {
+			logger.Error(err, "Failed to check whether the internalServiceExport is approved")
+			return ctrl.Result{}, err
+		}
+		if !approved {
+			logger.V(2).Info("Excluding the internalServiceExport pending approval")
+			return ctrl.Result{}, r.handleExcluded(ctx, &internalServiceExport, pendingApprovalCondition(&internalServiceExport))
+		}
+	}
+	if exportapproval.IsPendingExport(&internalServiceExport) {
+		logger.V(2).Info("InternalServiceExport is approved and joining serviceImport")
+		if err := r.liftExclusion(ctx, &internalServiceExport, string(fleetnetv1alpha1.ServiceExportPendingApproval)); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if r.EnforceExportQuotas {
+		reason, err := exportquota.Check(ctx, r.Client, &internalServiceExport)
+		if err != nil {
+			logger.Error(err, "Failed to check the export quotas")
+			return ctrl.Result{}, err
+		}
+		if reason != "" {
+			logger.V(2).Info("Excluding the internalServiceExport over quota", "reason", reason)
+			return ctrl.Result{}, r.handleExcluded(ctx, &internalServiceExport, quotaExceededCondition(&internalServiceExport, reason))
+		}
+	}
+	if exportquota.IsOverQuotaExport(&internalServiceExport) {
+		logger.V(2).Info("InternalServiceExport fits in the export quotas and is joining serviceImport")
+		if err := r.liftExclusion(ctx, &internalServiceExport, string(fleetnetv1alpha1.ServiceExportQuotaExceeded)); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	// The stale export is requeued to be excluded when its grace period ends, unless the mark is lifted before.
+	staleResult := ctrl.Result{}
+	if r.StaleExportGracePeriod > 0 {
+		withdraw, remaining := staleexport.Withdrawal(&internalServiceExport, r.StaleExportGracePeriod, startTime)
+		if withdraw {
+			logger.V(2).Info("Excluding the stale internalServiceExport")
+			return ctrl.Result{}, r.handleExcluded(ctx, &internalServiceExport, staleCondition(&internalServiceExport))
+		}
+		staleResult.RequeueAfter = remaining
+	}
+	if staleexport.IsStaleExport(&internalServiceExport) {
+		logger.V(2).Info("Member cluster reports again and the internalServiceExport is rejoining serviceImport")
+		if err := r.liftExclusion(ctx, &internalServiceExport, string(fleetnetv1alpha1.ServiceExportStale)); err != nil {
 			return ctrl.Result{}, err
 		}
 	}
-	// handle update
-	return r.handleUpdate(ctx, &internalServiceExport)
+	if r.HealthEvaluator == nil {
+		// handle update
+		result, err := r.handleUpdate(ctx, &internalServiceExport)
+		return soonerResult(result, staleResult), err
+	}
+
+	// The health of the export is evaluated periodically, as the heartbeats of the member clusters are not watched.
+	healthCheckResult := ctrl.Result{RequeueAfter: r.HealthEvaluator.Interval}
+	health, err := r.HealthEvaluator.Evaluate(ctx, r.Client, &internalServiceExport, startTime)
+	if err != nil {
+		logger.Error(err, "Failed to evaluate the health of internalServiceExport")
+		return ctrl.Result{}, err
+	}
+	if health.Unhealthy {
+		logger.V(2).Info("Failing over the unhealthy internalServiceExport", "reason", health.Reason)
+		return healthCheckResult, r.handleExcluded(ctx, &internalServiceExport, unhealthyCondition(&internalServiceExport, health))
+	}
+	if clusterhealth.IsUnhealthyExport(&internalServiceExport) {
+		logger.V(2).Info("InternalServiceExport has recovered and rejoining serviceImport")
+		if err := r.liftExclusion(ctx, &internalServiceExport, string(fleetnetv1alpha1.ServiceExportUnhealthy)); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	result, err := r.handleUpdate(ctx, &internalServiceExport)
+	if err != nil || result.RequeueAfter != 0 {
+		return soonerResult(result, staleResult), err
+	}
+	return soonerResult(healthCheckResult, staleResult), nil
+}
+
+// soonerResult returns the result which requeues the request sooner; a result which does not requeue the request
+// never wins.
+func soonerResult(a, b ctrl.Result) ctrl.Result {
+	if a.RequeueAfter == 0 || (b.RequeueAfter != 0 && b.RequeueAfter < a.RequeueAfter) {
+		return b
+	}
+	return a
+}
+
+// handleExcluded excludes the internalServiceExport vetoed by the hub from the serviceImport, regardless of its
+// spec, and reports the veto with the desired condition in its status.
+func (r *Reconciler) handleExcluded(ctx context.Context, internalServiceExport *fleetnetv1alpha1.InternalServiceExport, desiredCond metav1.Condition) error {
+	logger := klog.FromContext(ctx)
+	r.resolveConflict(internalServiceExport)
+
+	serviceImport := &fleetnetv1alpha1.ServiceImport{}
+	serviceImportName := types.NamespacedName{Namespace: internalServiceExport.Spec.ServiceReference.Namespace, Name: internalServiceExport.Spec.ServiceReference.Name}
+	if err := r.Client.Get(ctx, serviceImportName, serviceImport); err != nil {
+		if !errors.IsNotFound(err) {
+			logger.Error(err, "Failed to get serviceImport", "serviceImport", klog.KRef(serviceImportName.Namespace, serviceImportName.Name))
+			return err
+		}
+	} else {
+		oldStatus := serviceImport.Status.DeepCopy()
+		removeClusterFromServiceImportStatus(serviceImport, internalServiceExport.Spec.ServiceReference.ClusterID)
+		if err := r.updateServiceImportStatus(ctx, serviceImport, oldStatus); err != nil {
+			return err
+		}
+	}
+
+	currentCond := meta.FindStatusCondition(internalServiceExport.Status.Conditions, desiredCond.Type)
+	otherConds := []string{string(fleetnetv1alpha1.ServiceExportConflict)}
+	for _, condType := range exclusionConditionTypes {
+		if condType != desiredCond.Type {
+			otherConds = append(otherConds, condType)
+		}
+	}
+	if condition.EqualCondition(currentCond, &desiredCond) && !hasAnyCondition(internalServiceExport, otherConds) {
+		return nil
+	}
+	oldStatus := internalServiceExport.Status.DeepCopy()
+	meta.SetStatusCondition(&internalServiceExport.Status.Conditions, desiredCond)
+	// An excluded export takes no part in the conflict resolution, and is reported with one reason only.
+	for _, condType := range otherConds {
+		meta.RemoveStatusCondition(&internalServiceExport.Status.Conditions, condType)
+	}
+
+	logger.V(2).Info("Updating internalServiceExport status", "status", internalServiceExport.Status, "oldStatus", oldStatus)
+	if err := r.Status().Update(ctx, internalServiceExport); err != nil {
+		logger.Error(err, "Failed to update internalServiceExport status", "status", internalServiceExport.Status, "oldStatus", oldStatus)
+		return err
+	}
+	return nil
+}
+
+func deniedCondition(internalServiceExport *fleetnetv1alpha1.InternalServiceExport) metav1.Condition {
+	svcName := types.NamespacedName{
+		Namespace: internalServiceExport.Spec.ServiceReference.Namespace,
+		Name:      internalServiceExport.Spec.ServiceReference.Name,
+	}
+	return metav1.Condition{
+		Type:               string(fleetnetv1alpha1.ServiceExportDenied),
+		Status:             metav1.ConditionTrue,
+		Reason:             conditionReasonDeniedByHub,
+		ObservedGeneration: internalServiceExport.Spec.ServiceReference.Generation, // use the generation of the original object
+		Message:            fmt.Sprintf("service %s matches the export denylist of the fleet and is not exported", svcName),
+	}
+}
+
+func quarantinedCondition(internalServiceExport *fleetnetv1alpha1.InternalServiceExport) metav1.Condition {
+	return metav1.Condition{
+		Type:               string(fleetnetv1alpha1.ServiceExportQuarantined),
+		Status:             metav1.ConditionTrue,
+		Reason:             conditionReasonClusterQuarantined,
+		ObservedGeneration: internalServiceExport.Spec.ServiceReference.Generation, // use the generation of the original object
+		Message:            fmt.Sprintf("member cluster %s is quarantined by the fleet and its exports are excluded", internalServiceExport.Spec.ServiceReference.ClusterID),
+	}
+}
+
+// checkTenant returns the tenant owning the serviceImport of the internalServiceExport, and whether it is the tenant
+// of the export; a serviceImport not owned yet is claimed for the tenant of the export.
+func (r *Reconciler) checkTenant(ctx context.Context, internalServiceExport *fleetnetv1alpha1.InternalServiceExport) (string, bool, error) {
+	tenant, err := tenancy.TenantOf(ctx, r.Client, internalServiceExport.Namespace)
+	if err != nil {
+		return "", false, err
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{}
+	serviceImportName := types.NamespacedName{Namespace: internalServiceExport.Spec.ServiceReference.Namespace, Name: internalServiceExport.Spec.ServiceReference.Name}
+	if err := r.Client.Get(ctx, serviceImportName, serviceImport); err != nil {
+		if !errors.IsNotFound(err) {
+			return "", false, err
+		}
+		// The serviceImport is claimed when the export creates it.
+		return tenant, true, nil
+	}
+	if tenancy.Claim(serviceImport, tenant) {
+		klog.FromContext(ctx).V(2).Info("Claiming serviceImport for the tenant", "serviceImport", klog.KObj(serviceImport), "tenant", tenant)
+		if err := r.Client.Update(ctx, serviceImport); err != nil {
+			return "", false, err
+		}
+	}
+	owner, _ := tenancy.Owner(serviceImport)
+	return owner, owner == tenant, nil
+}
+
+func tenantConflictCondition(internalServiceExport *fleetnetv1alpha1.InternalServiceExport, owner string) metav1.Condition {
+	return metav1.Condition{
+		Type:               string(fleetnetv1alpha1.ServiceExportTenantConflict),
+		Status:             metav1.ConditionTrue,
+		Reason:             tenancy.ReasonOwnedByOtherTenant,
+		ObservedGeneration: internalServiceExport.Spec.ServiceReference.Generation, // use the generation of the original object
+		Message: fmt.Sprintf("service %s is exported by tenant %q of the fleet, and member cluster %s of another tenant is excluded",
+			internalServiceExport.Spec.ServiceReference.NamespacedName, owner, internalServiceExport.Spec.ServiceReference.ClusterID),
+	}
+}
+
+func pendingApprovalCondition(internalServiceExport *fleetnetv1alpha1.InternalServiceExport) metav1.Condition {
+	svcName := types.NamespacedName{
+		Namespace: internalServiceExport.Spec.ServiceReference.Namespace,
+		Name:      internalServiceExport.Spec.ServiceReference.Name,
+	}
+	return metav1.Condition{
+		Type:               string(fleetnetv1alpha1.ServiceExportPendingApproval),
+		Status:             metav1.ConditionTrue,
+		Reason:             exportapproval.ReasonAwaitingApproval,
+		ObservedGeneration: internalServiceExport.Spec.ServiceReference.Generation, // use the generation of the original object
+		Message: fmt.Sprintf("export of service %s is pending approval; a fleet admin approves it with the ServiceExportApproval %s listing member cluster %s",
+			svcName, svcName, internalServiceExport.Spec.ServiceReference.ClusterID),
+	}
+}
+
+func quotaExceededCondition(internalServiceExport *fleetnetv1alpha1.InternalServiceExport, reason string) metav1.Condition {
+	return metav1.Condition{
+		Type:               string(fleetnetv1alpha1.ServiceExportQuotaExceeded),
+		Status:             metav1.ConditionTrue,
+		Reason:             exportquota.ReasonQuotaExceeded,
+		ObservedGeneration: internalServiceExport.Spec.ServiceReference.Generation, // use the generation of the original object
+		Message:            reason + "; the export is excluded until the quota admits it",
+	}
+}
+
+func staleCondition(internalServiceExport *fleetnetv1alpha1.InternalServiceExport) metav1.Condition {
+	since, _ := staleexport.StaleSince(internalServiceExport)
+	return metav1.Condition{
+		Type:               string(fleetnetv1alpha1.ServiceExportStale),
+		Status:             metav1.ConditionTrue,
+		Reason:             staleexport.ReasonAgentNotRenewing,
+		ObservedGeneration: internalServiceExport.Spec.ServiceReference.Generation, // use the generation of the original object
+		Message: fmt.Sprintf("member cluster %s has not renewed its heartbeat since %s and its exports are withdrawn",
+			internalServiceExport.Spec.ServiceReference.ClusterID, since.UTC().Format(time.RFC3339)),
+	}
+}
+
+func unhealthyCondition(internalServiceExport *fleetnetv1alpha1.InternalServiceExport, health clusterhealth.Result) metav1.Condition {
+	return metav1.Condition{
+		Type:               string(fleetnetv1alpha1.ServiceExportUnhealthy),
+		Status:             metav1.ConditionTrue,
+		Reason:             health.Reason,
+		ObservedGeneration: internalServiceExport.Spec.ServiceReference.Generation, // use the generation of the original object
+		Message:            health.Message,
+	}
+}
+
+// hasAnyCondition returns true if the internalServiceExport has a condition of any of the given types.
+func hasAnyCondition(internalServiceExport *fleetnetv1alpha1.InternalServiceExport, condTypes []string) bool {
+	for _, condType := range condTypes {
+		if meta.FindStatusCondition(internalServiceExport.Status.Conditions, condType) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// liftExclusion removes the exclusion condition of the given type from the internalServiceExport which is no longer
+// excluded, e.g. whose member cluster is re-enabled or has recovered.
+func (r *Reconciler) liftExclusion(ctx context.Context, internalServiceExport *fleetnetv1alpha1.InternalServiceExport, condType string) error {
+	logger := klog.FromContext(ctx)
+	oldStatus := internalServiceExport.Status.DeepCopy()
+	meta.RemoveStatusCondition(&internalServiceExport.Status.Conditions, condType)
+	logger.V(2).Info("Lifting the exclusion of internalServiceExport", "conditionType", condType, "status", internalServiceExport.Status, "oldStatus", oldStatus)
+	if err := r.Status().Update(ctx, internalServiceExport); err != nil {
+		logger.Error(err, "Failed to update internalServiceExport status", "status", internalServiceExport.Status, "oldStatus", oldStatus)
+		return err
+	}
+	return nil
 }
 
 func (r *Reconciler) handleDelete(ctx context.Context, internalServiceExport *fleetnetv1alpha1.InternalServiceExport) (ctrl.Result, error) {
+	logger := klog.FromContext(ctx)
 	// the internalServiceExport is being deleted
 	if !controllerutil.ContainsFinalizer(internalServiceExport, objectmeta.InternalServiceExportFinalizer) {
 		return ctrl.Result{}, nil
 	}
 
-	internalServiceExportKObj := klog.KObj(internalServiceExport)
-	klog.V(2).InfoS("Removing internalServiceExport", "internalServiceExport", internalServiceExportKObj)
+	logger.V(2).Info("Removing internalServiceExport")
+	r.resolveConflict(internalServiceExport)
+	if r.HealthEvaluator != nil {
+		r.HealthEvaluator.Forget(client.ObjectKeyFromObject(internalServiceExport))
+	}
 
 	// get serviceImport
 	serviceImport := &fleetnetv1alpha1.ServiceImport{}
 	serviceImportName := types.NamespacedName{Namespace: internalServiceExport.Spec.ServiceReference.Namespace, Name: internalServiceExport.Spec.ServiceReference.Name}
 	serviceImportKRef := klog.KRef(serviceImportName.Namespace, serviceImportName.Name)
 	if err := r.Client.Get(ctx, serviceImportName, serviceImport); err != nil {
-		klog.ErrorS(err, "Failed to get serviceImport", "serviceImport", serviceImportKRef, "internalServiceExport", internalServiceExportKObj)
+		logger.Error(err, "Failed to get serviceImport", "serviceImport", serviceImportKRef)
 		if !errors.IsNotFound(err) {
 			return ctrl.Result{}, err
 		}
 		return r.removeFinalizer(ctx, internalServiceExport)
 	}
 	// check serviceImport spec
-	if len(serviceImport.Status.Ports) == 0 {
+	if !isServiceImportResolved(serviceImport) {
 		// Requeue the request and waiting for the ServiceImport controller to resolve the spec.
 		// In case serviceImport picks the same spec as the deleting one at the same time and controller misses removing
 		// the clusterID from the serviceImport.
-		klog.V(2).InfoS("Waiting for serviceImport controller to resolve the spec", "serviceImport", serviceImportKRef, "internalServiceExport", internalServiceExportKObj)
+		logger.V(2).Info("Waiting for serviceImport controller to resolve the spec", "serviceImport", serviceImportKRef)
 		return ctrl.Result{RequeueAfter: r.RetryInternal}, nil
 	}
 
 	oldStatus := serviceImport.Status.DeepCopy()
-	removeClusterFromServiceImportStatus(serviceImport, internalServiceExport.Spec.ServiceReference.ClusterID)
+	clusterID := internalServiceExport.Spec.ServiceReference.ClusterID
+	wasAccepted := hasCluster(serviceImport, clusterID)
+	removeClusterFromServiceImportStatus(serviceImport, clusterID)
 	if err := r.updateServiceImportStatus(ctx, serviceImport, oldStatus); err != nil {
 		return ctrl.Result{}, err
 	}
+	if wasAccepted {
+		r.Recorder.Eventf(serviceImport, corev1.EventTypeNormal, "ExportWithdrawn", "Service exported from cluster %s is withdrawn", clusterID)
+	}
 	return r.removeFinalizer(ctx, internalServiceExport)
 }
 
 func removeClusterFromServiceImportStatus(serviceImport *fleetnetv1alpha1.ServiceImport, clusterID string) {
-	var updatedClusters []fleetnetv1alpha1.ClusterStatus
+	var updatedClusters []fleetnetv1alpha1.ServiceImportClusterStatus
 	for _, c := range serviceImport.Status.Clusters {
 		if c.Cluster != clusterID {
 			updatedClusters = append(updatedClusters, c)
@@ -133,62 +559,112 @@ func removeClusterFromServiceImportStatus(serviceImport *fleetnetv1alpha1.Servic
 	}
 }
 
-func addClusterToServiceImportStatus(serviceImport *fleetnetv1alpha1.ServiceImport, clusterID string) {
+// isServiceImportResolved returns true if the ServiceImport controller has resolved the spec of the ServiceImport;
+// a headless Service may expose no ports at all.
+func isServiceImportResolved(serviceImport *fleetnetv1alpha1.ServiceImport) bool {
+	return len(serviceImport.Status.Ports) != 0 || serviceImport.Status.Type == fleetnetv1alpha1.Headless
+}
+
+// hasCluster returns true if the export from the cluster is in use by the ServiceImport.
+func hasCluster(serviceImport *fleetnetv1alpha1.ServiceImport, clusterID string) bool {
 	for _, c := range serviceImport.Status.Clusters {
 		if c.Cluster == clusterID {
+			return true
+		}
+	}
+	return false
+}
+
+// addClusterToServiceImportStatus adds the cluster from which the Service is exported to the ServiceImport status,
+// or refreshes the cluster status if the cluster has already been added.
+func addClusterToServiceImportStatus(serviceImport *fleetnetv1alpha1.ServiceImport, internalServiceExport *fleetnetv1alpha1.InternalServiceExport) {
+	clusterStatus := fleetnetv1alpha1.ServiceImportClusterStatus{
+		Cluster:                       internalServiceExport.Spec.ServiceReference.ClusterID,
+		AllocateLoadBalancerNodePorts: internalServiceExport.Spec.AllocateLoadBalancerNodePorts,
+		LoadBalancerIP:                internalServiceExport.Spec.LoadBalancerIP,
+		IPFamilyPolicy:                internalServiceExport.Spec.IPFamilyPolicy,
+		IPFamilies:                    internalServiceExport.Spec.IPFamilies,
+	}
+	for i := range serviceImport.Status.Clusters {
+		if serviceImport.Status.Clusters[i].Cluster == clusterStatus.Cluster {
+			serviceImport.Status.Clusters[i] = clusterStatus
 			return
 		}
 	}
-	serviceImport.Status.Clusters = append(serviceImport.Status.Clusters, fleetnetv1alpha1.ClusterStatus{Cluster: clusterID})
+	serviceImport.Status.Clusters = append(serviceImport.Status.Clusters, clusterStatus)
 }
 
 func (r *Reconciler) updateServiceImportStatus(ctx context.Context, serviceImport *fleetnetv1alpha1.ServiceImport, oldStatus *fleetnetv1alpha1.ServiceImportStatus) error {
+	logger := klog.FromContext(ctx)
 	if equality.Semantic.DeepEqual(&serviceImport.Status, oldStatus) { // no change
 		return nil
 	}
 	serviceImportKObj := klog.KObj(serviceImport)
-	klog.V(2).InfoS("Updating the serviceImport status", "serviceImport", serviceImportKObj, "oldStatus", oldStatus, "status", serviceImport.Status)
+	logger.V(2).Info("Updating the serviceImport status", "serviceImport", serviceImportKObj, "oldStatus", oldStatus, "status", serviceImport.Status)
 
-	if err := r.Client.Status().Update(ctx, serviceImport); err != nil {
-		klog.ErrorS(err, "Failed to update the serviceImport status", "serviceImport", serviceImportKObj, "oldStatus", oldStatus, "status", serviceImport.Status)
+	var err error
+	if r.ServerSideApply {
+		err = apply.ServiceImportStatus(ctx, r.Client, serviceImport, oldStatus)
+	} else {
+		err = r.Client.Status().Update(ctx, serviceImport)
+	}
+	if err != nil {
+		logger.Error(err, "Failed to update the serviceImport status", "serviceImport", serviceImportKObj, "oldStatus", oldStatus, "status", serviceImport.Status)
 		return err
 	}
 	return nil
 }
 
 func (r *Reconciler) removeFinalizer(ctx context.Context, internalServiceExport *fleetnetv1alpha1.InternalServiceExport) (ctrl.Result, error) {
+	logger := klog.FromContext(ctx)
 	// remove the finalizer
 	controllerutil.RemoveFinalizer(internalServiceExport, objectmeta.InternalServiceExportFinalizer)
 	if err := r.Client.Update(ctx, internalServiceExport); err != nil {
-		klog.ErrorS(err, "Failed to remove internalServiceExport finalizer", "internalServiceExport", klog.KObj(internalServiceExport))
+		logger.Error(err, "Failed to remove internalServiceExport finalizer")
 		return ctrl.Result{}, err
 	}
 	return ctrl.Result{}, nil
 }
 
-func (r *Reconciler) updateInternalServiceExportStatus(ctx context.Context, internalServiceExport *fleetnetv1alpha1.InternalServiceExport, conflict bool) error {
+func (r *Reconciler) updateInternalServiceExportStatus(ctx context.Context, internalServiceExport *fleetnetv1alpha1.InternalServiceExport,
+	conflict bool, resolution *fleetnetv1alpha1.ConflictResolutionStatus) error {
+	logger := klog.FromContext(ctx)
 	desiredCond := condition.UnconflictedServiceExportConflictCondition(*internalServiceExport)
 	if conflict {
-		desiredCond = condition.ConflictedServiceExportConflictCondition(*internalServiceExport)
+		desiredCond = condition.ConflictedServiceExportConflictCondition(*internalServiceExport, resolution)
 	}
 	currentCond := meta.FindStatusCondition(internalServiceExport.Status.Conditions, string(fleetnetv1alpha1.ServiceExportConflict))
-	if condition.EqualCondition(currentCond, &desiredCond) {
+	if condition.EqualCondition(currentCond, &desiredCond) && !hasAnyCondition(internalServiceExport, exclusionConditionTypes) {
 		return nil
 	}
-	exportKObj := klog.KObj(internalServiceExport)
 	oldStatus := internalServiceExport.Status.DeepCopy()
+	// The current condition is updated in place; keep whether the export was in conflict for the metrics.
+	wasConflict := currentCond != nil && currentCond.Status == metav1.ConditionTrue
 	meta.SetStatusCondition(&internalServiceExport.Status.Conditions, desiredCond)
+	// The export is no longer excluded once it takes part in the conflict resolution again.
+	for _, condType := range exclusionConditionTypes {
+		meta.RemoveStatusCondition(&internalServiceExport.Status.Conditions, condType)
+	}
 
-	klog.V(2).InfoS("Updating internalServiceExport status", "internalServiceExport", exportKObj, "status", internalServiceExport.Status, "oldStatus", oldStatus)
+	logger.V(2).Info("Updating internalServiceExport status", "status", internalServiceExport.Status, "oldStatus", oldStatus)
 	if err := r.Status().Update(ctx, internalServiceExport); err != nil {
-		klog.ErrorS(err, "Failed to update internalServiceExport status", "internalServiceExport", exportKObj, "status", internalServiceExport.Status, "oldStatus", oldStatus)
+		logger.Error(err, "Failed to update internalServiceExport status", "status", internalServiceExport.Status, "oldStatus", oldStatus)
 		return err
 	}
+	switch clusterID := internalServiceExport.Spec.ServiceReference.ClusterID; {
+	case conflict && !wasConflict:
+		metrics.ConflictDetected(clusterID)
+	case !conflict && wasConflict:
+		metrics.ConflictResolved(clusterID)
+	}
 	return nil
 }
 
 func (r *Reconciler) handleUpdate(ctx context.Context, internalServiceExport *fleetnetv1alpha1.InternalServiceExport) (ctrl.Result, error) {
-	internalServiceExportKObj := klog.KObj(internalServiceExport)
+	logger := klog.FromContext(ctx)
+	// Continue the trace of the export, so that its merge into the serviceImport is part of its journey.
+	ctx, span := tracing.Start(ctx, "internalserviceexport.Merge", internalServiceExport.Annotations)
+	defer span.End()
 	// get serviceImport
 	serviceImport := &fleetnetv1alpha1.ServiceImport{}
 	serviceImportName := types.NamespacedName{Namespace: internalServiceExport.Spec.ServiceReference.Namespace, Name: internalServiceExport.Spec.ServiceReference.Name}
@@ -196,7 +672,7 @@ func (r *Reconciler) handleUpdate(ctx context.Context, internalServiceExport *fl
 
 	if err := r.Client.Get(ctx, serviceImportName, serviceImport); err != nil {
 		if !errors.IsNotFound(err) {
-			klog.ErrorS(err, "Failed to get serviceImport", "serviceImport", serviceImportKRef, "internalServiceExport", internalServiceExportKObj)
+			logger.Error(err, "Failed to get serviceImport", "serviceImport", serviceImportKRef)
 			return ctrl.Result{}, err
 		}
 		serviceImport = &fleetnetv1alpha1.ServiceImport{
@@ -205,16 +681,24 @@ func (r *Reconciler) handleUpdate(ctx context.Context, internalServiceExport *fl
 				Name:      serviceImportName.Name,
 			},
 		}
-		klog.V(2).InfoS("Creating serviceImport", "serviceImport", serviceImportKRef, "internalServiceExport", internalServiceExportKObj)
+		if r.EnableTenantIsolation {
+			tenant, err := tenancy.TenantOf(ctx, r.Client, internalServiceExport.Namespace)
+			if err != nil {
+				logger.Error(err, "Failed to get the tenant of the member cluster")
+				return ctrl.Result{}, err
+			}
+			tenancy.Claim(serviceImport, tenant)
+		}
+		logger.V(2).Info("Creating serviceImport", "serviceImport", serviceImportKRef)
 		if err := r.Client.Create(ctx, serviceImport); err != nil {
-			klog.ErrorS(err, "Failed to create or update service import", "serviceImport", serviceImportKRef, "internalServiceExport", internalServiceExportKObj)
+			logger.Error(err, "Failed to create or update service import", "serviceImport", serviceImportKRef)
 			return ctrl.Result{}, err
 		}
 	}
 
-	if len(serviceImport.Status.Ports) == 0 {
+	if !isServiceImportResolved(serviceImport) {
 		// Requeue the request and waiting for the ServiceImport controller to resolve the spec.
-		klog.V(3).InfoS("Waiting for serviceImport controller to resolve the spec", "serviceImport", serviceImportKRef, "internalServiceExport", internalServiceExportKObj)
+		logger.V(3).Info("Waiting for serviceImport controller to resolve the spec", "serviceImport", serviceImportKRef)
 		return ctrl.Result{RequeueAfter: r.RetryInternal}, nil
 	}
 
@@ -223,32 +707,397 @@ func (r *Reconciler) handleUpdate(ctx context.Context, internalServiceExport *fl
 
 	// To simplify the implementation, we compare the whole ports structure.
 	// TODO, change to compare the ports by ignoring the order and protocol and port are the map keys.
-	if !equality.Semantic.DeepEqual(serviceImport.Status.Ports, internalServiceExport.Spec.Ports) {
+	// A headless Service cannot be merged with a Service with a cluster IP.
+	isHeadlessImport := serviceImport.Status.Type == fleetnetv1alpha1.Headless
+	if !equality.Semantic.DeepEqual(serviceImport.Status.Ports, internalServiceExport.Spec.Ports) || isHeadlessImport != internalServiceExport.Spec.Headless {
 		removeClusterFromServiceImportStatus(serviceImport, clusterID)
 		if err := r.updateServiceImportStatus(ctx, serviceImport, oldStatus); err != nil {
 			return ctrl.Result{}, err
 		}
 		// It's possible, eg, there is only one serviceExport and its spec has been changed.
 		// ServiceImport stores the old spec of this ServiceExport and later the serviceExport changes its spec.
-		if len(serviceImport.Status.Ports) == 0 {
-			klog.V(3).InfoS("Removed the cluster and waiting for serviceImport controller to resolve the spec", "serviceImport", serviceImportKRef, "internalServiceExport", internalServiceExportKObj)
+		if !isServiceImportResolved(serviceImport) {
+			logger.V(3).Info("Removed the cluster and waiting for serviceImport controller to resolve the spec", "serviceImport", serviceImportKRef)
 			// Requeue the request and waiting for the ServiceImport controller to resolve the spec.
 			return ctrl.Result{RequeueAfter: r.RetryInternal}, nil
 		}
-		return ctrl.Result{}, r.updateInternalServiceExportStatus(ctx, internalServiceExport, true)
+		preempts, err := r.ConflictResolver.Preempts(ctx, serviceImport, clusterID)
+		if err != nil {
+			logger.Error(err, "Failed to apply the conflict resolution policy", "serviceImport", serviceImportKRef)
+			return ctrl.Result{}, err
+		}
+		if preempts {
+			// Reset the resolved spec so that the serviceImport controller resolves it again with this export.
+			logger.V(2).Info("Export outranks the winning export and resetting the serviceImport spec", "serviceImport", serviceImportKRef, "winningCluster", serviceImport.Status.ConflictResolution.WinningCluster)
+			oldStatus = serviceImport.Status.DeepCopy()
+			serviceImport.Status = fleetnetv1alpha1.ServiceImportStatus{}
+			if err := r.updateServiceImportStatus(ctx, serviceImport, oldStatus); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: r.RetryInternal}, nil
+		}
+		conflictCond := meta.FindStatusCondition(internalServiceExport.Status.Conditions, string(fleetnetv1alpha1.ServiceExportConflict))
+		isNewConflict := conflictCond == nil || conflictCond.Status != metav1.ConditionTrue
+		if err := r.updateInternalServiceExportStatus(ctx, internalServiceExport, true, serviceImport.Status.ConflictResolution); err != nil {
+			return ctrl.Result{}, err
+		}
+		if isNewConflict {
+			conflict := newConflict(internalServiceExport, serviceImport)
+			r.ConflictNotifier.Conflicted(conflict)
+			r.Recorder.Eventf(serviceImport, corev1.EventTypeWarning, "ExportConflict",
+				"Service exported from cluster %s is in conflict with the exports from clusters %v", clusterID, conflict.ConflictingClusters)
+		}
+		return ctrl.Result{}, nil
 	}
 
-	addClusterToServiceImportStatus(serviceImport, clusterID)
+	isNewlyAccepted := !hasCluster(serviceImport, clusterID)
+	addClusterToServiceImportStatus(serviceImport, internalServiceExport)
 	if err := r.updateServiceImportStatus(ctx, serviceImport, oldStatus); err != nil {
 		return ctrl.Result{}, err
 	}
+	if isNewlyAccepted {
+		r.Recorder.Eventf(serviceImport, corev1.EventTypeNormal, "ExportAccepted", "Service exported from cluster %s is accepted", clusterID)
+	}
+
+	if err := r.updateInternalServiceExportStatus(ctx, internalServiceExport, false, serviceImport.Status.ConflictResolution); err != nil {
+		return ctrl.Result{}, err
+	}
+	r.resolveConflict(internalServiceExport)
+	if r.ReportImportDemand {
+		if err := r.updateImportedCondition(ctx, internalServiceExport, serviceImport); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// updateImportedCondition reports whether any member cluster other than the exporting cluster imports the service
+// of the internalServiceExport.
+func (r *Reconciler) updateImportedCondition(ctx context.Context, internalServiceExport *fleetnetv1alpha1.InternalServiceExport,
+	serviceImport *fleetnetv1alpha1.ServiceImport) error {
+	logger := klog.FromContext(ctx)
+	desiredCond := importedCondition(internalServiceExport, isImportedByOtherClusters(serviceImport, internalServiceExport.Spec.ServiceReference.ClusterID))
+	currentCond := meta.FindStatusCondition(internalServiceExport.Status.Conditions, string(fleetnetv1alpha1.ServiceExportImported))
+	if condition.EqualCondition(currentCond, &desiredCond) {
+		return nil
+	}
+	oldStatus := internalServiceExport.Status.DeepCopy()
+	meta.SetStatusCondition(&internalServiceExport.Status.Conditions, desiredCond)
+	logger.V(2).Info("Updating internalServiceExport import demand", "status", internalServiceExport.Status, "oldStatus", oldStatus)
+	if err := r.Status().Update(ctx, internalServiceExport); err != nil {
+		logger.Error(err, "Failed to update internalServiceExport status", "status", internalServiceExport.Status, "oldStatus", oldStatus)
+		return err
+	}
+	return nil
+}
+
+func importedCondition(internalServiceExport *fleetnetv1alpha1.InternalServiceExport, imported bool) metav1.Condition {
+	svcName := types.NamespacedName{
+		Namespace: internalServiceExport.Spec.ServiceReference.Namespace,
+		Name:      internalServiceExport.Spec.ServiceReference.Name,
+	}
+	if !imported {
+		return metav1.Condition{
+			Type:               string(fleetnetv1alpha1.ServiceExportImported),
+			Status:             metav1.ConditionFalse,
+			Reason:             conditionReasonNotImported,
+			ObservedGeneration: internalServiceExport.Spec.ServiceReference.Generation, // use the generation of the original object
+			Message:            fmt.Sprintf("service %s is not imported by any other member cluster", svcName),
+		}
+	}
+	return metav1.Condition{
+		Type:               string(fleetnetv1alpha1.ServiceExportImported),
+		Status:             metav1.ConditionTrue,
+		Reason:             conditionReasonImported,
+		ObservedGeneration: internalServiceExport.Spec.ServiceReference.Generation, // use the generation of the original object
+		Message:            fmt.Sprintf("service %s is imported by other member clusters", svcName),
+	}
+}
+
+// isImportedByOtherClusters returns true if any member cluster other than the given one imports the service of the
+// serviceImport, as annotated by the InternalServiceImport controller; the service is deemed imported if the
+// annotation cannot be read, so that its endpoints are never held back by mistake.
+func isImportedByOtherClusters(serviceImport *fleetnetv1alpha1.ServiceImport, clusterID string) bool {
+	data, ok := serviceImport.Annotations[objectmeta.ServiceImportAnnotationServiceInUseBy]
+	if !ok {
+		return false
+	}
+	svcInUseBy := &fleetnetv1alpha1.ServiceInUseBy{}
+	if err := json.Unmarshal([]byte(data), svcInUseBy); err != nil {
+		klog.ErrorS(err, "Failed to unmarshal ServiceInUseBy data", "serviceImport", klog.KObj(serviceImport), "data", data)
+		return true
+	}
+	for _, importingClusterID := range svcInUseBy.MemberClusters {
+		if string(importingClusterID) != clusterID {
+			return true
+		}
+	}
+	return false
+}
 
-	return ctrl.Result{}, r.updateInternalServiceExportStatus(ctx, internalServiceExport, false)
+// newConflict describes the conflict of an internalServiceExport with the exports in use by its serviceImport.
+func newConflict(internalServiceExport *fleetnetv1alpha1.InternalServiceExport, serviceImport *fleetnetv1alpha1.ServiceImport) conflictnotify.Conflict {
+	clusters := make([]string, 0, len(serviceImport.Status.Clusters))
+	for _, c := range serviceImport.Status.Clusters {
+		clusters = append(clusters, c.Cluster)
+	}
+	return conflictnotify.Conflict{
+		Namespace:           internalServiceExport.Spec.ServiceReference.Namespace,
+		Service:             internalServiceExport.Spec.ServiceReference.Name,
+		Cluster:             internalServiceExport.Spec.ServiceReference.ClusterID,
+		ConflictingClusters: clusters,
+	}
+}
+
+// resolveConflict lets the conflict notifier know that the internalServiceExport is no longer in conflict.
+func (r *Reconciler) resolveConflict(internalServiceExport *fleetnetv1alpha1.InternalServiceExport) {
+	svcRef := internalServiceExport.Spec.ServiceReference
+	r.ConflictNotifier.Resolved(svcRef.Namespace, svcRef.Name, svcRef.ClusterID)
+}
+
+// ShardKey returns the shard key of the requests of the controller, the namespaced name of the serviceImport of the
+// exported service, as the controller writes the serviceImport and the internalServiceExports of all the member
+// clusters exporting the service; the exports of a service are thus reconciled by the shard of its serviceImport.
+func ShardKey(reader client.Reader) sharding.KeyFunc {
+	return func(req reconcile.Request) (string, bool) {
+		var internalServiceExport fleetnetv1alpha1.InternalServiceExport
+		if err := reader.Get(context.Background(), req.NamespacedName, &internalServiceExport); err != nil {
+			return "", false
+		}
+		svcRef := internalServiceExport.Spec.ServiceReference
+		return types.NamespacedName{Namespace: svcRef.Namespace, Name: svcRef.Name}.String(), true
+	}
 }
 
 // SetupWithManager sets up the controller with the Manager.
-func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+// The index of the internalServiceExports by service is shared with the ServiceImport controller; it is only created
+// here if disableInternalServiceExportIndexer is false.
+func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, disableInternalServiceExportIndexer bool) error {
+	// add index to quickly query internalServiceExport list by service
+	if !disableInternalServiceExportIndexer {
+		extractFunc := func(o client.Object) []string {
+			return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.NamespacedName}
+		}
+		if err := mgr.GetFieldIndexer().IndexField(ctx, &fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldNamespacedName, extractFunc); err != nil {
+			klog.ErrorS(err, "Failed to create index", "field", exportedServiceFieldNamespacedName)
+			return err
+		}
+	}
+
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&fleetnetv1alpha1.InternalServiceExport{}).
-		Complete(r)
+		// Re-evaluate the exports of a service as soon as the ServiceImport controller resolves the spec of its
+		// serviceImport, instead of polling the serviceImport until it is resolved.
+		Watches(&fleetnetv1alpha1.ServiceImport{},
+			handler.EnqueueRequestsFromMapFunc(r.enqueueServiceInternalServiceExports),
+			builder.WithPredicates(predicate.Funcs{
+				CreateFunc: func(_ event.CreateEvent) bool {
+					return false
+				},
+				UpdateFunc: func(e event.UpdateEvent) bool {
+					if r.ReportImportDemand && isServiceImportDemandChanged(e.ObjectOld, e.ObjectNew) {
+						return true
+					}
+					return isServiceImportResolutionChanged(e.ObjectOld.(*fleetnetv1alpha1.ServiceImport), e.ObjectNew.(*fleetnetv1alpha1.ServiceImport))
+				},
+				// The exports of the other tenants may claim a released serviceImport.
+				DeleteFunc: func(_ event.DeleteEvent) bool {
+					return r.EnableTenantIsolation
+				},
+				GenericFunc: func(_ event.GenericEvent) bool {
+					return false
+				},
+			}))
+	if r.DenylistConfigMap.Name != "" {
+		// Re-evaluate all the exports whenever the denylist changes.
+		b = b.Watches(&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.enqueueAllInternalServiceExports),
+			builder.WithPredicates(predicate.NewPredicateFuncs(func(o client.Object) bool {
+				return exportdenylist.IsDenylist(o, r.DenylistConfigMap)
+			})))
+	}
+	if r.ExportApproval != nil {
+		// Re-evaluate the exports of a service whenever its approval changes.
+		b = b.Watches(&fleetnetv1alpha1.ServiceExportApproval{},
+			handler.EnqueueRequestsFromMapFunc(r.enqueueServiceInternalServiceExports))
+	}
+	if r.EnableTenantIsolation {
+		// Re-evaluate the exports of a member cluster whenever it moves to another tenant.
+		b = b.Watches(&corev1.Namespace{},
+			handler.EnqueueRequestsFromMapFunc(r.enqueueNamespaceInternalServiceExports),
+			builder.WithPredicates(predicate.Funcs{
+				CreateFunc: func(_ event.CreateEvent) bool {
+					return false
+				},
+				UpdateFunc: func(e event.UpdateEvent) bool {
+					return tenancy.IsTenantLabelChanged(e.ObjectOld, e.ObjectNew)
+				},
+				DeleteFunc: func(_ event.DeleteEvent) bool {
+					return false
+				},
+				GenericFunc: func(_ event.GenericEvent) bool {
+					return false
+				},
+			}))
+	}
+	if r.EnforceExportQuotas {
+		// Re-evaluate all the exports whenever the quotas change, or the exports taking room in them come and go,
+		// as the exports are admitted in order.
+		b = b.Watches(&fleetnetv1alpha1.ExportQuota{},
+			handler.EnqueueRequestsFromMapFunc(r.enqueueAllInternalServiceExports)).
+			Watches(&fleetnetv1alpha1.InternalServiceExport{},
+				handler.EnqueueRequestsFromMapFunc(r.enqueueQuotaInternalServiceExports(false)),
+				builder.WithPredicates(predicate.Funcs{
+					CreateFunc: func(_ event.CreateEvent) bool {
+						return false
+					},
+					UpdateFunc: func(e event.UpdateEvent) bool {
+						return e.ObjectOld.GetDeletionTimestamp() == nil && e.ObjectNew.GetDeletionTimestamp() != nil
+					},
+					DeleteFunc: func(_ event.DeleteEvent) bool {
+						return true
+					},
+					GenericFunc: func(_ event.GenericEvent) bool {
+						return false
+					},
+				})).
+			Watches(&fleetnetv1alpha1.EndpointSliceExport{},
+				handler.EnqueueRequestsFromMapFunc(r.enqueueQuotaInternalServiceExports(true)),
+				builder.WithPredicates(predicate.Funcs{
+					UpdateFunc: func(e event.UpdateEvent) bool {
+						return len(e.ObjectOld.(*fleetnetv1alpha1.EndpointSliceExport).Spec.Endpoints) !=
+							len(e.ObjectNew.(*fleetnetv1alpha1.EndpointSliceExport).Spec.Endpoints)
+					},
+					GenericFunc: func(_ event.GenericEvent) bool {
+						return false
+					},
+				}))
+	}
+	if r.EnableClusterQuarantine {
+		extractFunc := func(o client.Object) []string {
+			return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.ClusterID}
+		}
+		if err := mgr.GetFieldIndexer().IndexField(ctx, &fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldClusterID, extractFunc); err != nil {
+			klog.ErrorS(err, "Failed to create index", "field", exportedServiceFieldClusterID)
+			return err
+		}
+		// Re-evaluate the exports of a member cluster whenever it is quarantined or re-enabled.
+		b = b.Watches(&clusterv1beta1.MemberCluster{},
+			handler.EnqueueRequestsFromMapFunc(r.enqueueClusterInternalServiceExports),
+			builder.WithPredicates(predicate.Funcs{
+				CreateFunc: func(e event.CreateEvent) bool {
+					return clusterquarantine.HasQuarantineLabel(e.Object)
+				},
+				UpdateFunc: func(e event.UpdateEvent) bool {
+					return clusterquarantine.HasQuarantineLabel(e.ObjectOld) != clusterquarantine.HasQuarantineLabel(e.ObjectNew)
+				},
+				DeleteFunc: func(e event.DeleteEvent) bool {
+					return clusterquarantine.HasQuarantineLabel(e.Object)
+				},
+				GenericFunc: func(_ event.GenericEvent) bool {
+					return false
+				},
+			}))
+	}
+	return b.WithOptions(r.ControllerOptions).Complete(metrics.InstrumentReconciler("internalserviceexport", r))
+}
+
+// isServiceImportResolutionChanged returns true if the spec of the serviceImport is newly resolved, or resolved to
+// another spec, so that the exports waiting for the resolution, or in conflict with the former spec, are re-evaluated.
+func isServiceImportResolutionChanged(oldServiceImport, newServiceImport *fleetnetv1alpha1.ServiceImport) bool {
+	if !isServiceImportResolved(newServiceImport) {
+		return false
+	}
+	if !isServiceImportResolved(oldServiceImport) {
+		return true
+	}
+	return oldServiceImport.Status.Type != newServiceImport.Status.Type ||
+		!equality.Semantic.DeepEqual(oldServiceImport.Status.Ports, newServiceImport.Status.Ports)
+}
+
+// isServiceImportDemandChanged returns true if the member clusters importing the service of the serviceImport change,
+// so that the import demand reported on the exports is refreshed.
+func isServiceImportDemandChanged(oldServiceImport, newServiceImport client.Object) bool {
+	return oldServiceImport.GetAnnotations()[objectmeta.ServiceImportAnnotationServiceInUseBy] !=
+		newServiceImport.GetAnnotations()[objectmeta.ServiceImportAnnotationServiceInUseBy]
+}
+
+// enqueueServiceInternalServiceExports enqueues the internalServiceExports of the service of the serviceImport, or of the
+// serviceExportApproval.
+func (r *Reconciler) enqueueServiceInternalServiceExports(ctx context.Context, obj client.Object) []reconcile.Request {
+	internalServiceExportList := &fleetnetv1alpha1.InternalServiceExportList{}
+	listOpts := client.MatchingFields{
+		exportedServiceFieldNamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}.String(),
+	}
+	if err := r.Client.List(ctx, internalServiceExportList, &listOpts); err != nil {
+		klog.ErrorS(err, "Failed to list internalServiceExports of the service", "service", klog.KObj(obj))
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(internalServiceExportList.Items))
+	for i := range internalServiceExportList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&internalServiceExportList.Items[i])})
+	}
+	return requests
+}
+
+// enqueueClusterInternalServiceExports enqueues the internalServiceExports exported from the member cluster.
+func (r *Reconciler) enqueueClusterInternalServiceExports(ctx context.Context, mc client.Object) []reconcile.Request {
+	internalServiceExportList := &fleetnetv1alpha1.InternalServiceExportList{}
+	listOpts := client.MatchingFields{
+		exportedServiceFieldClusterID: mc.GetName(),
+	}
+	if err := r.Client.List(ctx, internalServiceExportList, &listOpts); err != nil {
+		klog.ErrorS(err, "Failed to list internalServiceExports to apply the cluster quarantine", "memberCluster", klog.KObj(mc))
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(internalServiceExportList.Items))
+	for i := range internalServiceExportList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&internalServiceExportList.Items[i])})
+	}
+	return requests
+}
+
+// enqueueNamespaceInternalServiceExports enqueues the internalServiceExports in the namespace of a member cluster.
+func (r *Reconciler) enqueueNamespaceInternalServiceExports(ctx context.Context, ns client.Object) []reconcile.Request {
+	internalServiceExportList := &fleetnetv1alpha1.InternalServiceExportList{}
+	if err := r.Client.List(ctx, internalServiceExportList, client.InNamespace(ns.GetName())); err != nil {
+		klog.ErrorS(err, "Failed to list internalServiceExports to apply the tenant change", "namespace", klog.KObj(ns))
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(internalServiceExportList.Items))
+	for i := range internalServiceExportList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&internalServiceExportList.Items[i])})
+	}
+	return requests
+}
+
+// enqueueQuotaInternalServiceExports returns a map function enqueueing all the internalServiceExports if any export
+// quota is set, or, if endpointsOnly is set, if any quota limits the exported endpoints.
+func (r *Reconciler) enqueueQuotaInternalServiceExports(endpointsOnly bool) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		quotaList := &fleetnetv1alpha1.ExportQuotaList{}
+		if err := r.Client.List(ctx, quotaList); err != nil {
+			klog.ErrorS(err, "Failed to list export quotas", "object", klog.KObj(obj))
+			return nil
+		}
+		for i := range quotaList.Items {
+			if !endpointsOnly || quotaList.Items[i].Spec.MaxEndpoints != nil {
+				return r.enqueueAllInternalServiceExports(ctx, obj)
+			}
+		}
+		return nil
+	}
+}
+
+func (r *Reconciler) enqueueAllInternalServiceExports(ctx context.Context, obj client.Object) []reconcile.Request {
+	internalServiceExportList := &fleetnetv1alpha1.InternalServiceExportList{}
+	if err := r.Client.List(ctx, internalServiceExportList); err != nil {
+		klog.ErrorS(err, "Failed to list internalServiceExports to re-evaluate", "object", klog.KObj(obj))
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(internalServiceExportList.Items))
+	for i := range internalServiceExportList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&internalServiceExportList.Items[i])})
+	}
+	return requests
 }
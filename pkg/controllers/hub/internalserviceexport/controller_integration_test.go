@@ -142,7 +142,7 @@ var _ = Describe("Test InternalServiceExport Controller", func() {
 			By("Updating serviceImport status (the resolved spec is the same as internalServiceImport)")
 			serviceImport.Status = fleetnetv1alpha1.ServiceImportStatus{
 				Ports: importServicePorts,
-				Clusters: []fleetnetv1alpha1.ClusterStatus{
+				Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 					{
 						Cluster: testClusterID,
 					},
@@ -186,7 +186,7 @@ var _ = Describe("Test InternalServiceExport Controller", func() {
 			Eventually(func() string {
 				want := fleetnetv1alpha1.ServiceImportStatus{
 					Ports: importServicePorts,
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 						{
 							Cluster: testClusterID,
 						},
@@ -215,7 +215,7 @@ var _ = Describe("Test InternalServiceExport Controller", func() {
 			Eventually(func() string {
 				want := fleetnetv1alpha1.ServiceImportStatus{
 					Ports: importServicePorts,
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 						{
 							Cluster: "member-2",
 						},
@@ -272,7 +272,7 @@ var _ = Describe("Test InternalServiceExport Controller", func() {
 			By("Updating serviceImport status")
 			serviceImport.Status = fleetnetv1alpha1.ServiceImportStatus{
 				Ports: importServicePorts,
-				Clusters: []fleetnetv1alpha1.ClusterStatus{
+				Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 					{
 						Cluster: "other-cluster",
 					},
@@ -295,7 +295,7 @@ var _ = Describe("Test InternalServiceExport Controller", func() {
 			Eventually(func() string {
 				want := fleetnetv1alpha1.ServiceImportStatus{
 					Ports: importServicePorts,
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 						{
 							Cluster: "other-cluster",
 						},
@@ -338,7 +338,7 @@ var _ = Describe("Test InternalServiceExport Controller", func() {
 			Eventually(func() string {
 				want := fleetnetv1alpha1.ServiceImportStatus{
 					Ports: importServicePorts,
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 						{
 							Cluster: "other-cluster",
 						},
@@ -364,7 +364,7 @@ var _ = Describe("Test InternalServiceExport Controller", func() {
 						TargetPort:  intstr.IntOrString{IntVal: 8080},
 					},
 				},
-				Clusters: []fleetnetv1alpha1.ClusterStatus{
+				Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 					{
 						Cluster: "other-cluster",
 					},
@@ -472,7 +472,7 @@ var _ = Describe("Test InternalServiceExport Controller", func() {
 			By("Updating serviceImport status")
 			serviceImport.Status = fleetnetv1alpha1.ServiceImportStatus{
 				Ports: importServicePorts,
-				Clusters: []fleetnetv1alpha1.ClusterStatus{
+				Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 					{
 						Cluster: "other-cluster",
 					},
@@ -7,23 +7,41 @@ package internalserviceexport
 import (
 	"context"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	clusterv1beta1 "go.goms.io/fleet/apis/cluster/v1beta1"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/clusterhealth"
+	"go.goms.io/fleet-networking/pkg/common/conflictnotify"
+	"go.goms.io/fleet-networking/pkg/common/conflictresolution"
+	"go.goms.io/fleet-networking/pkg/common/exportapproval"
+	"go.goms.io/fleet-networking/pkg/common/exportdenylist"
+	"go.goms.io/fleet-networking/pkg/common/exportquota"
 	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/sharding"
+	"go.goms.io/fleet-networking/pkg/common/staleexport"
+	"go.goms.io/fleet-networking/pkg/common/tenancy"
 )
 
 const (
@@ -89,6 +107,7 @@ func internalServiceExportReconciler(client client.Client) *Reconciler {
 	return &Reconciler{
 		Client:        client,
 		RetryInternal: internalserviceexportRetryInterval,
+		Recorder:      record.NewFakeRecorder(10),
 	}
 }
 
@@ -169,7 +188,7 @@ func TestHandleDelete(t *testing.T) {
 				},
 				Status: fleetnetv1alpha1.ServiceImportStatus{
 					Ports: importServicePorts,
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 						{
 							Cluster: testClusterID,
 						},
@@ -193,7 +212,7 @@ func TestHandleDelete(t *testing.T) {
 				},
 				Status: fleetnetv1alpha1.ServiceImportStatus{
 					Ports: importServicePorts,
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 						{
 							Cluster: testClusterID,
 						},
@@ -211,7 +230,7 @@ func TestHandleDelete(t *testing.T) {
 				},
 				Status: fleetnetv1alpha1.ServiceImportStatus{
 					Ports: importServicePorts,
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 						{
 							Cluster: "member-2",
 						},
@@ -236,7 +255,7 @@ func TestHandleDelete(t *testing.T) {
 							AppProtocol: &appProtocol,
 						},
 					},
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 						{
 							Cluster: "member-2",
 						},
@@ -258,7 +277,7 @@ func TestHandleDelete(t *testing.T) {
 							AppProtocol: &appProtocol,
 						},
 					},
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 						{
 							Cluster: "member-2",
 						},
@@ -494,7 +513,7 @@ func TestHandleUpdate(t *testing.T) {
 				},
 				Status: fleetnetv1alpha1.ServiceImportStatus{
 					Ports: importServicePorts,
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 						{
 							Cluster: "member-2",
 						},
@@ -533,7 +552,7 @@ func TestHandleUpdate(t *testing.T) {
 				},
 				Status: fleetnetv1alpha1.ServiceImportStatus{
 					Ports: importServicePorts,
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 						{
 							Cluster: "member-2",
 						},
@@ -580,7 +599,7 @@ func TestHandleUpdate(t *testing.T) {
 				},
 				Status: fleetnetv1alpha1.ServiceImportStatus{
 					Ports: importServicePorts,
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 						{
 							Cluster: "member-2",
 						},
@@ -627,7 +646,84 @@ func TestHandleUpdate(t *testing.T) {
 				},
 				Status: fleetnetv1alpha1.ServiceImportStatus{
 					Ports: importServicePorts,
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
+						{
+							Cluster: "member-2",
+						},
+					},
+					Type: fleetnetv1alpha1.ClusterSetIP,
+				},
+			},
+		},
+		{
+			name: "headless serviceExport just created and serviceImport has a cluster IP",
+			internalSvcExport: &fleetnetv1alpha1.InternalServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testName,
+					Namespace: testMemberNamespace,
+				},
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Ports:    importServicePorts,
+					Headless: true,
+					ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+						ClusterID:       testClusterID,
+						Kind:            "Service",
+						Namespace:       testNamespace,
+						Name:            testServiceName,
+						ResourceVersion: "0",
+						Generation:      0,
+						UID:             "0",
+					},
+				},
+			},
+			serviceImport: &fleetnetv1alpha1.ServiceImport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testServiceName,
+					Namespace: testNamespace,
+				},
+				Status: fleetnetv1alpha1.ServiceImportStatus{
+					Ports: importServicePorts,
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
+						{
+							Cluster: "member-2",
+						},
+					},
+					Type: fleetnetv1alpha1.ClusterSetIP,
+				},
+			},
+			want: ctrl.Result{},
+			wantInternalSvcExport: &fleetnetv1alpha1.InternalServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testName,
+					Namespace: testMemberNamespace,
+				},
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Ports:    importServicePorts,
+					Headless: true,
+					ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+						ClusterID:       testClusterID,
+						Kind:            "Service",
+						Namespace:       testNamespace,
+						Name:            testServiceName,
+						ResourceVersion: "0",
+						Generation:      0,
+						UID:             "0",
+					},
+				},
+				Status: fleetnetv1alpha1.InternalServiceExportStatus{
+					Conditions: []metav1.Condition{
+						conflictedServiceExportConflictCondition(testNamespace, testServiceName),
+					},
+				},
+			},
+			wantServiceImport: &fleetnetv1alpha1.ServiceImport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testServiceName,
+					Namespace: testNamespace,
+				},
+				Status: fleetnetv1alpha1.ServiceImportStatus{
+					Ports: importServicePorts,
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 						{
 							Cluster: "member-2",
 						},
@@ -636,6 +732,82 @@ func TestHandleUpdate(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "headless serviceExport without ports and serviceImport is headless",
+			internalSvcExport: &fleetnetv1alpha1.InternalServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testName,
+					Namespace: testMemberNamespace,
+				},
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Headless: true,
+					ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+						ClusterID:       testClusterID,
+						Kind:            "Service",
+						Namespace:       testNamespace,
+						Name:            testServiceName,
+						ResourceVersion: "0",
+						Generation:      0,
+						UID:             "0",
+					},
+				},
+			},
+			serviceImport: &fleetnetv1alpha1.ServiceImport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testServiceName,
+					Namespace: testNamespace,
+				},
+				Status: fleetnetv1alpha1.ServiceImportStatus{
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
+						{
+							Cluster: "member-2",
+						},
+					},
+					Type: fleetnetv1alpha1.Headless,
+				},
+			},
+			want: ctrl.Result{},
+			wantInternalSvcExport: &fleetnetv1alpha1.InternalServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testName,
+					Namespace: testMemberNamespace,
+				},
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Headless: true,
+					ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+						ClusterID:       testClusterID,
+						Kind:            "Service",
+						Namespace:       testNamespace,
+						Name:            testServiceName,
+						ResourceVersion: "0",
+						Generation:      0,
+						UID:             "0",
+					},
+				},
+				Status: fleetnetv1alpha1.InternalServiceExportStatus{
+					Conditions: []metav1.Condition{
+						unconflictedServiceExportConflictCondition(testNamespace, testServiceName),
+					},
+				},
+			},
+			wantServiceImport: &fleetnetv1alpha1.ServiceImport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testServiceName,
+					Namespace: testNamespace,
+				},
+				Status: fleetnetv1alpha1.ServiceImportStatus{
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
+						{
+							Cluster: "member-2",
+						},
+						{
+							Cluster: testClusterID,
+						},
+					},
+					Type: fleetnetv1alpha1.Headless,
+				},
+			},
+		},
 		{
 			name: "update serviceExport and old serviceExport has the same spec as serviceImport",
 			internalSvcExport: &fleetnetv1alpha1.InternalServiceExport{
@@ -676,7 +848,7 @@ func TestHandleUpdate(t *testing.T) {
 				},
 				Status: fleetnetv1alpha1.ServiceImportStatus{
 					Ports: importServicePorts,
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 						{
 							Cluster: "member-2",
 						},
@@ -726,7 +898,7 @@ func TestHandleUpdate(t *testing.T) {
 				},
 				Status: fleetnetv1alpha1.ServiceImportStatus{
 					Ports: importServicePorts,
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 						{
 							Cluster: "member-2",
 						},
@@ -767,7 +939,7 @@ func TestHandleUpdate(t *testing.T) {
 				},
 				Status: fleetnetv1alpha1.ServiceImportStatus{
 					Ports: importServicePorts,
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 						{
 							Cluster: "member-2",
 						},
@@ -806,7 +978,54 @@ func TestHandleUpdate(t *testing.T) {
 				},
 				Status: fleetnetv1alpha1.ServiceImportStatus{
 					Ports: importServicePorts,
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
+						{
+							Cluster: "member-2",
+						},
+						{
+							Cluster: testClusterID,
+						},
+					},
+					Type: fleetnetv1alpha1.ClusterSetIP,
+				},
+			},
+		},
+		{
+			name: "serviceExport of the load balancer type has its load balancer metadata carried to serviceImport",
+			internalSvcExport: &fleetnetv1alpha1.InternalServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testName,
+					Namespace: testMemberNamespace,
+				},
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Ports: importServicePorts,
+					ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+						ClusterID:       testClusterID,
+						Kind:            "Service",
+						Namespace:       testNamespace,
+						Name:            testServiceName,
+						ResourceVersion: "0",
+						Generation:      0,
+						UID:             "0",
+					},
+					Type:                          corev1.ServiceTypeLoadBalancer,
+					AllocateLoadBalancerNodePorts: ptr.To(false),
+					LoadBalancerIP:                "10.0.0.1",
+				},
+				Status: fleetnetv1alpha1.InternalServiceExportStatus{
+					Conditions: []metav1.Condition{
+						unconflictedServiceExportConflictCondition(testNamespace, testServiceName),
+					},
+				},
+			},
+			serviceImport: &fleetnetv1alpha1.ServiceImport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testServiceName,
+					Namespace: testNamespace,
+				},
+				Status: fleetnetv1alpha1.ServiceImportStatus{
+					Ports: importServicePorts,
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 						{
 							Cluster: "member-2",
 						},
@@ -817,6 +1036,53 @@ func TestHandleUpdate(t *testing.T) {
 					Type: fleetnetv1alpha1.ClusterSetIP,
 				},
 			},
+			want: ctrl.Result{},
+			wantInternalSvcExport: &fleetnetv1alpha1.InternalServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testName,
+					Namespace: testMemberNamespace,
+				},
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Ports: importServicePorts,
+					ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+						ClusterID:       testClusterID,
+						Kind:            "Service",
+						Namespace:       testNamespace,
+						Name:            testServiceName,
+						ResourceVersion: "0",
+						Generation:      0,
+						UID:             "0",
+					},
+					Type:                          corev1.ServiceTypeLoadBalancer,
+					AllocateLoadBalancerNodePorts: ptr.To(false),
+					LoadBalancerIP:                "10.0.0.1",
+				},
+				Status: fleetnetv1alpha1.InternalServiceExportStatus{
+					Conditions: []metav1.Condition{
+						unconflictedServiceExportConflictCondition(testNamespace, testServiceName),
+					},
+				},
+			},
+			wantServiceImport: &fleetnetv1alpha1.ServiceImport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testServiceName,
+					Namespace: testNamespace,
+				},
+				Status: fleetnetv1alpha1.ServiceImportStatus{
+					Ports: importServicePorts,
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
+						{
+							Cluster: "member-2",
+						},
+						{
+							Cluster:                       testClusterID,
+							AllocateLoadBalancerNodePorts: ptr.To(false),
+							LoadBalancerIP:                "10.0.0.1",
+						},
+					},
+					Type: fleetnetv1alpha1.ClusterSetIP,
+				},
+			},
 		},
 		{
 			name: "there is only one serviceExport and port spec has been changed",
@@ -858,7 +1124,7 @@ func TestHandleUpdate(t *testing.T) {
 				},
 				Status: fleetnetv1alpha1.ServiceImportStatus{
 					Ports: importServicePorts,
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 						{
 							Cluster: testClusterID,
 						},
@@ -956,3 +1222,1273 @@ func TestHandleUpdate(t *testing.T) {
 		})
 	}
 }
+
+// TestReconcile_Denied tests that an export denied by the hub is excluded from the serviceImport and marked as denied.
+func TestReconcile_Denied(t *testing.T) {
+	ctx := context.Background()
+	denylistKey := types.NamespacedName{Namespace: "fleet-system", Name: "export-denylist"}
+	denylist := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: denylistKey.Namespace,
+			Name:      denylistKey.Name,
+		},
+		Data: map[string]string{
+			exportdenylist.PatternsKey: testNamespace + "/*",
+		},
+	}
+	internalSvcExport := internalServiceExportForTest()
+	internalSvcExport.Finalizers = []string{objectmeta.InternalServiceExportFinalizer}
+	internalSvcExport.Status.Conditions = []metav1.Condition{
+		unconflictedServiceExportConflictCondition(testNamespace, testServiceName),
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testServiceName,
+			Namespace: testNamespace,
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Ports: internalSvcExport.Spec.Ports,
+			Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
+				{Cluster: testClusterID},
+				{Cluster: "member-2"},
+			},
+			Type: fleetnetv1alpha1.ClusterSetIP,
+		},
+	}
+
+	scheme := internalServiceExportScheme(t)
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	objects := []client.Object{internalSvcExport, serviceImport}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(append(objects, denylist)...).
+		WithStatusSubresource(objects...).
+		Build()
+	r := internalServiceExportReconciler(fakeClient)
+	r.DenylistConfigMap = denylistKey
+
+	name := types.NamespacedName{Namespace: testMemberNamespace, Name: testName}
+	got, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: name})
+	if err != nil {
+		t.Fatalf("Reconcile() = %v, want no error", err)
+	}
+	if want := (ctrl.Result{}); !cmp.Equal(got, want) {
+		t.Errorf("Reconcile() = %+v, want %+v", got, want)
+	}
+
+	options := []cmp.Option{
+		cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime", "Message"),
+	}
+	gotInternalSvcExport := fleetnetv1alpha1.InternalServiceExport{}
+	if err := fakeClient.Get(ctx, name, &gotInternalSvcExport); err != nil {
+		t.Fatalf("InternalServiceExport Get() got error %v, want no error", err)
+	}
+	wantConditions := []metav1.Condition{
+		{
+			Type:   string(fleetnetv1alpha1.ServiceExportDenied),
+			Status: metav1.ConditionTrue,
+			Reason: conditionReasonDeniedByHub,
+		},
+	}
+	if diff := cmp.Diff(wantConditions, gotInternalSvcExport.Status.Conditions, options...); diff != "" {
+		t.Errorf("InternalServiceExport conditions mismatch (-want, +got):\n%s", diff)
+	}
+
+	gotServiceImport := fleetnetv1alpha1.ServiceImport{}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: testNamespace, Name: testServiceName}, &gotServiceImport); err != nil {
+		t.Fatalf("ServiceImport Get() got error %v, want no error", err)
+	}
+	wantClusters := []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-2"}}
+	if diff := cmp.Diff(wantClusters, gotServiceImport.Status.Clusters); diff != "" {
+		t.Errorf("ServiceImport clusters mismatch (-want, +got):\n%s", diff)
+	}
+
+	// The export is allowed again once the denylist is lifted.
+	if err := fakeClient.Delete(ctx, denylist); err != nil {
+		t.Fatalf("ConfigMap Delete() got error %v, want no error", err)
+	}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: name}); err != nil {
+		t.Fatalf("Reconcile() = %v, want no error", err)
+	}
+	if err := fakeClient.Get(ctx, name, &gotInternalSvcExport); err != nil {
+		t.Fatalf("InternalServiceExport Get() got error %v, want no error", err)
+	}
+	wantConditions = []metav1.Condition{
+		unconflictedServiceExportConflictCondition(testNamespace, testServiceName),
+	}
+	if diff := cmp.Diff(wantConditions, gotInternalSvcExport.Status.Conditions, options...); diff != "" {
+		t.Errorf("InternalServiceExport conditions after lifting the denylist mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+// TestReconcile_Quarantined tests that the exports of a quarantined member cluster leave the serviceImport and
+// rejoin it once the cluster is re-enabled.
+func TestReconcile_Quarantined(t *testing.T) {
+	ctx := context.Background()
+	memberCluster := &clusterv1beta1.MemberCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: testClusterID,
+			Labels: map[string]string{
+				objectmeta.MemberClusterLabelExportsQuarantined: "true",
+			},
+		},
+	}
+	internalSvcExport := internalServiceExportForTest()
+	internalSvcExport.Finalizers = []string{objectmeta.InternalServiceExportFinalizer}
+	internalSvcExport.Status.Conditions = []metav1.Condition{
+		unconflictedServiceExportConflictCondition(testNamespace, testServiceName),
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testServiceName,
+			Namespace: testNamespace,
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Ports: internalSvcExport.Spec.Ports,
+			Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
+				{Cluster: testClusterID},
+				{Cluster: "member-2"},
+			},
+			Type: fleetnetv1alpha1.ClusterSetIP,
+		},
+	}
+
+	scheme := internalServiceExportScheme(t)
+	if err := clusterv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	objects := []client.Object{internalSvcExport, serviceImport}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(append(objects, memberCluster)...).
+		WithStatusSubresource(objects...).
+		Build()
+	r := internalServiceExportReconciler(fakeClient)
+	r.EnableClusterQuarantine = true
+
+	name := types.NamespacedName{Namespace: testMemberNamespace, Name: testName}
+	serviceImportName := types.NamespacedName{Namespace: testNamespace, Name: testServiceName}
+	options := []cmp.Option{
+		cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime", "Message"),
+	}
+
+	testCases := []struct {
+		name           string
+		quarantined    bool
+		wantConditions []metav1.Condition
+		wantClusters   []fleetnetv1alpha1.ServiceImportClusterStatus
+	}{
+		{
+			name:        "cluster is quarantined",
+			quarantined: true,
+			wantConditions: []metav1.Condition{
+				{
+					Type:   string(fleetnetv1alpha1.ServiceExportQuarantined),
+					Status: metav1.ConditionTrue,
+					Reason: conditionReasonClusterQuarantined,
+				},
+			},
+			wantClusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-2"}},
+		},
+		{
+			name: "cluster is re-enabled",
+			wantConditions: []metav1.Condition{
+				unconflictedServiceExportConflictCondition(testNamespace, testServiceName),
+			},
+			wantClusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-2"}, {Cluster: testClusterID}},
+		},
+		{
+			name:        "cluster is quarantined again",
+			quarantined: true,
+			wantConditions: []metav1.Condition{
+				{
+					Type:   string(fleetnetv1alpha1.ServiceExportQuarantined),
+					Status: metav1.ConditionTrue,
+					Reason: conditionReasonClusterQuarantined,
+				},
+			},
+			wantClusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-2"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotMemberCluster := &clusterv1beta1.MemberCluster{}
+			if err := fakeClient.Get(ctx, types.NamespacedName{Name: testClusterID}, gotMemberCluster); err != nil {
+				t.Fatalf("MemberCluster Get() got error %v, want no error", err)
+			}
+			gotMemberCluster.Labels = nil
+			if tc.quarantined {
+				gotMemberCluster.Labels = map[string]string{objectmeta.MemberClusterLabelExportsQuarantined: "true"}
+			}
+			if err := fakeClient.Update(ctx, gotMemberCluster); err != nil {
+				t.Fatalf("MemberCluster Update() got error %v, want no error", err)
+			}
+
+			got, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: name})
+			if err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+			if want := (ctrl.Result{}); !cmp.Equal(got, want) {
+				t.Errorf("Reconcile() = %+v, want %+v", got, want)
+			}
+
+			gotInternalSvcExport := fleetnetv1alpha1.InternalServiceExport{}
+			if err := fakeClient.Get(ctx, name, &gotInternalSvcExport); err != nil {
+				t.Fatalf("InternalServiceExport Get() got error %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.wantConditions, gotInternalSvcExport.Status.Conditions, options...); diff != "" {
+				t.Errorf("InternalServiceExport conditions mismatch (-want, +got):\n%s", diff)
+			}
+			gotServiceImport := fleetnetv1alpha1.ServiceImport{}
+			if err := fakeClient.Get(ctx, serviceImportName, &gotServiceImport); err != nil {
+				t.Fatalf("ServiceImport Get() got error %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.wantClusters, gotServiceImport.Status.Clusters); diff != "" {
+				t.Errorf("ServiceImport clusters mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestReconcile_TenantConflict tests that an export is excluded from a serviceImport owned by another tenant, and
+// joins it once its member cluster moves to the owning tenant.
+func TestReconcile_TenantConflict(t *testing.T) {
+	ctx := context.Background()
+	internalSvcExport := internalServiceExportForTest()
+	internalSvcExport.Finalizers = []string{objectmeta.InternalServiceExportFinalizer}
+	internalSvcExport.Status.Conditions = []metav1.Condition{
+		unconflictedServiceExportConflictCondition(testNamespace, testServiceName),
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testServiceName,
+			Namespace: testNamespace,
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Ports: internalSvcExport.Spec.Ports,
+			Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
+				{Cluster: testClusterID},
+				{Cluster: "member-2"},
+			},
+			Type: fleetnetv1alpha1.ClusterSetIP,
+		},
+	}
+	memberNamespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: testMemberNamespace}}
+
+	scheme := internalServiceExportScheme(t)
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	objects := []client.Object{internalSvcExport, serviceImport}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(append(objects, memberNamespace)...).
+		WithStatusSubresource(objects...).
+		Build()
+	r := internalServiceExportReconciler(fakeClient)
+	r.EnableTenantIsolation = true
+
+	name := types.NamespacedName{Namespace: testMemberNamespace, Name: testName}
+	serviceImportName := types.NamespacedName{Namespace: testNamespace, Name: testServiceName}
+	options := []cmp.Option{
+		cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime", "Message"),
+	}
+
+	testCases := []struct {
+		name           string
+		owner          *string // nil leaves the serviceImport as it is, and an empty owner leaves it not owned
+		tenant         string
+		wantConditions []metav1.Condition
+		wantOwner      string
+		wantClusters   []fleetnetv1alpha1.ServiceImportClusterStatus
+	}{
+		{
+			name:   "serviceImport is owned by another tenant",
+			owner:  ptr.To("search"),
+			tenant: "payments",
+			wantConditions: []metav1.Condition{
+				{
+					Type:   string(fleetnetv1alpha1.ServiceExportTenantConflict),
+					Status: metav1.ConditionTrue,
+					Reason: tenancy.ReasonOwnedByOtherTenant,
+				},
+			},
+			wantOwner:    "search",
+			wantClusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-2"}},
+		},
+		{
+			name:   "member cluster moves to the owning tenant",
+			tenant: "search",
+			wantConditions: []metav1.Condition{
+				unconflictedServiceExportConflictCondition(testNamespace, testServiceName),
+			},
+			wantOwner:    "search",
+			wantClusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-2"}, {Cluster: testClusterID}},
+		},
+		{
+			name:   "serviceImport not owned is claimed",
+			owner:  ptr.To(""),
+			tenant: "payments",
+			wantConditions: []metav1.Condition{
+				unconflictedServiceExportConflictCondition(testNamespace, testServiceName),
+			},
+			wantOwner:    "payments",
+			wantClusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-2"}, {Cluster: testClusterID}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotNamespace := &corev1.Namespace{}
+			if err := fakeClient.Get(ctx, types.NamespacedName{Name: testMemberNamespace}, gotNamespace); err != nil {
+				t.Fatalf("Namespace Get() got error %v, want no error", err)
+			}
+			gotNamespace.Labels = map[string]string{objectmeta.MemberNamespaceLabelTenant: tc.tenant}
+			if err := fakeClient.Update(ctx, gotNamespace); err != nil {
+				t.Fatalf("Namespace Update() got error %v, want no error", err)
+			}
+			if tc.owner != nil {
+				gotServiceImport := &fleetnetv1alpha1.ServiceImport{}
+				if err := fakeClient.Get(ctx, serviceImportName, gotServiceImport); err != nil {
+					t.Fatalf("ServiceImport Get() got error %v, want no error", err)
+				}
+				gotServiceImport.Labels = nil
+				if *tc.owner != "" {
+					gotServiceImport.Labels = map[string]string{objectmeta.ServiceImportLabelTenant: *tc.owner}
+				}
+				if err := fakeClient.Update(ctx, gotServiceImport); err != nil {
+					t.Fatalf("ServiceImport Update() got error %v, want no error", err)
+				}
+			}
+
+			got, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: name})
+			if err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+			if want := (ctrl.Result{}); !cmp.Equal(got, want) {
+				t.Errorf("Reconcile() = %+v, want %+v", got, want)
+			}
+
+			gotInternalSvcExport := fleetnetv1alpha1.InternalServiceExport{}
+			if err := fakeClient.Get(ctx, name, &gotInternalSvcExport); err != nil {
+				t.Fatalf("InternalServiceExport Get() got error %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.wantConditions, gotInternalSvcExport.Status.Conditions, options...); diff != "" {
+				t.Errorf("InternalServiceExport conditions mismatch (-want, +got):\n%s", diff)
+			}
+			gotServiceImport := fleetnetv1alpha1.ServiceImport{}
+			if err := fakeClient.Get(ctx, serviceImportName, &gotServiceImport); err != nil {
+				t.Fatalf("ServiceImport Get() got error %v, want no error", err)
+			}
+			if got := gotServiceImport.Labels[objectmeta.ServiceImportLabelTenant]; got != tc.wantOwner {
+				t.Errorf("ServiceImport owner = %q, want %q", got, tc.wantOwner)
+			}
+			if diff := cmp.Diff(tc.wantClusters, gotServiceImport.Status.Clusters); diff != "" {
+				t.Errorf("ServiceImport clusters mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestReconcile_PendingApproval tests that an export is excluded from its serviceImport until it is approved, and is
+// withdrawn again once its approval is revoked.
+func TestReconcile_PendingApproval(t *testing.T) {
+	ctx := context.Background()
+	internalSvcExport := internalServiceExportForTest()
+	internalSvcExport.Finalizers = []string{objectmeta.InternalServiceExportFinalizer}
+	internalSvcExport.Status.Conditions = []metav1.Condition{
+		unconflictedServiceExportConflictCondition(testNamespace, testServiceName),
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testServiceName,
+			Namespace: testNamespace,
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Ports: internalSvcExport.Spec.Ports,
+			Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
+				{Cluster: testClusterID},
+				{Cluster: "member-2"},
+			},
+			Type: fleetnetv1alpha1.ClusterSetIP,
+		},
+	}
+
+	objects := []client.Object{internalSvcExport, serviceImport}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(internalServiceExportScheme(t)).
+		WithObjects(objects...).
+		WithStatusSubresource(objects...).
+		Build()
+	r := internalServiceExportReconciler(fakeClient)
+	r.ExportApproval = &exportapproval.Policy{ExemptNamespaces: []string{"kube-system"}}
+
+	name := types.NamespacedName{Namespace: testMemberNamespace, Name: testName}
+	serviceImportName := types.NamespacedName{Namespace: testNamespace, Name: testServiceName}
+	options := []cmp.Option{
+		cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime", "Message"),
+	}
+	pendingCondition := metav1.Condition{
+		Type:   string(fleetnetv1alpha1.ServiceExportPendingApproval),
+		Status: metav1.ConditionTrue,
+		Reason: exportapproval.ReasonAwaitingApproval,
+	}
+
+	testCases := []struct {
+		name             string
+		approvedClusters []string // nil means no approval
+		wantConditions   []metav1.Condition
+		wantClusters     []fleetnetv1alpha1.ServiceImportClusterStatus
+	}{
+		{
+			name:           "export is not approved",
+			wantConditions: []metav1.Condition{pendingCondition},
+			wantClusters:   []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-2"}},
+		},
+		{
+			name:             "another cluster is approved",
+			approvedClusters: []string{"member-2"},
+			wantConditions:   []metav1.Condition{pendingCondition},
+			wantClusters:     []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-2"}},
+		},
+		{
+			name:             "export is approved",
+			approvedClusters: []string{"member-2", testClusterID},
+			wantConditions: []metav1.Condition{
+				unconflictedServiceExportConflictCondition(testNamespace, testServiceName),
+			},
+			wantClusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-2"}, {Cluster: testClusterID}},
+		},
+		{
+			name:           "approval is revoked",
+			wantConditions: []metav1.Condition{pendingCondition},
+			wantClusters:   []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-2"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			approval := &fleetnetv1alpha1.ServiceExportApproval{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testServiceName},
+			}
+			if err := fakeClient.Delete(ctx, approval); client.IgnoreNotFound(err) != nil {
+				t.Fatalf("ServiceExportApproval Delete() got error %v, want no error", err)
+			}
+			if tc.approvedClusters != nil {
+				approval.Spec.Clusters = tc.approvedClusters
+				if err := fakeClient.Create(ctx, approval); err != nil {
+					t.Fatalf("ServiceExportApproval Create() got error %v, want no error", err)
+				}
+			}
+
+			got, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: name})
+			if err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+			if want := (ctrl.Result{}); !cmp.Equal(got, want) {
+				t.Errorf("Reconcile() = %+v, want %+v", got, want)
+			}
+
+			gotInternalSvcExport := fleetnetv1alpha1.InternalServiceExport{}
+			if err := fakeClient.Get(ctx, name, &gotInternalSvcExport); err != nil {
+				t.Fatalf("InternalServiceExport Get() got error %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.wantConditions, gotInternalSvcExport.Status.Conditions, options...); diff != "" {
+				t.Errorf("InternalServiceExport conditions mismatch (-want, +got):\n%s", diff)
+			}
+			gotServiceImport := fleetnetv1alpha1.ServiceImport{}
+			if err := fakeClient.Get(ctx, serviceImportName, &gotServiceImport); err != nil {
+				t.Fatalf("ServiceImport Get() got error %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.wantClusters, gotServiceImport.Status.Clusters); diff != "" {
+				t.Errorf("ServiceImport clusters mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestReconcile_QuotaExceeded tests that an export which does not fit in an export quota is excluded from its
+// serviceImport, and rejoins it once the quota admits it.
+func TestReconcile_QuotaExceeded(t *testing.T) {
+	ctx := context.Background()
+	internalSvcExport := internalServiceExportForTest()
+	internalSvcExport.Finalizers = []string{objectmeta.InternalServiceExportFinalizer}
+	internalSvcExport.Spec.ServiceReference.ExportedSince = metav1.NewTime(time.Date(2024, time.January, 1, 1, 0, 0, 0, time.UTC))
+	internalSvcExport.Status.Conditions = []metav1.Condition{
+		unconflictedServiceExportConflictCondition(testNamespace, testServiceName),
+	}
+	olderSvcExport := internalServiceExportForTest()
+	olderSvcExport.Name = "my-ns-older-svc"
+	olderSvcExport.Spec.ServiceReference.Name = "older-svc"
+	olderSvcExport.Spec.ServiceReference.ExportedSince = metav1.NewTime(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC))
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testServiceName,
+			Namespace: testNamespace,
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Ports: internalSvcExport.Spec.Ports,
+			Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
+				{Cluster: testClusterID},
+				{Cluster: "member-2"},
+			},
+			Type: fleetnetv1alpha1.ClusterSetIP,
+		},
+	}
+	quota := &fleetnetv1alpha1.ExportQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "per-cluster"},
+		Spec:       fleetnetv1alpha1.ExportQuotaSpec{Scope: fleetnetv1alpha1.ExportQuotaScopeCluster},
+	}
+
+	objects := []client.Object{internalSvcExport, serviceImport}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(internalServiceExportScheme(t)).
+		WithObjects(append(objects, olderSvcExport, quota)...).
+		WithStatusSubresource(objects...).
+		Build()
+	r := internalServiceExportReconciler(fakeClient)
+	r.EnforceExportQuotas = true
+
+	name := types.NamespacedName{Namespace: testMemberNamespace, Name: testName}
+	serviceImportName := types.NamespacedName{Namespace: testNamespace, Name: testServiceName}
+	options := []cmp.Option{
+		cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime"),
+	}
+
+	testCases := []struct {
+		name           string
+		maxServices    int32
+		wantConditions []metav1.Condition
+		wantClusters   []fleetnetv1alpha1.ServiceImportClusterStatus
+	}{
+		{
+			name:        "older export takes the room",
+			maxServices: 1,
+			wantConditions: []metav1.Condition{
+				{
+					Type:    string(fleetnetv1alpha1.ServiceExportQuotaExceeded),
+					Status:  metav1.ConditionTrue,
+					Reason:  exportquota.ReasonQuotaExceeded,
+					Message: "member cluster member-1 exceeds the quota per-cluster of 1 exported services; the export is excluded until the quota admits it",
+				},
+			},
+			wantClusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-2"}},
+		},
+		{
+			name:        "quota is raised",
+			maxServices: 2,
+			wantConditions: []metav1.Condition{
+				unconflictedServiceExportConflictCondition(testNamespace, testServiceName),
+			},
+			wantClusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-2"}, {Cluster: testClusterID}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			quota.Spec.MaxServices = &tc.maxServices
+			if err := fakeClient.Update(ctx, quota); err != nil {
+				t.Fatalf("ExportQuota Update() got error %v, want no error", err)
+			}
+
+			got, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: name})
+			if err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+			if want := (ctrl.Result{}); !cmp.Equal(got, want) {
+				t.Errorf("Reconcile() = %+v, want %+v", got, want)
+			}
+
+			gotInternalSvcExport := fleetnetv1alpha1.InternalServiceExport{}
+			if err := fakeClient.Get(ctx, name, &gotInternalSvcExport); err != nil {
+				t.Fatalf("InternalServiceExport Get() got error %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.wantConditions, gotInternalSvcExport.Status.Conditions, options...); diff != "" {
+				t.Errorf("InternalServiceExport conditions mismatch (-want, +got):\n%s", diff)
+			}
+			gotServiceImport := fleetnetv1alpha1.ServiceImport{}
+			if err := fakeClient.Get(ctx, serviceImportName, &gotServiceImport); err != nil {
+				t.Fatalf("ServiceImport Get() got error %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.wantClusters, gotServiceImport.Status.Clusters); diff != "" {
+				t.Errorf("ServiceImport clusters mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestReconcile_Unhealthy tests that an export with no ready endpoints is failed over to the other member clusters
+// and rejoins the serviceImport once it recovers.
+func TestReconcile_Unhealthy(t *testing.T) {
+	ctx := context.Background()
+	internalSvcExport := internalServiceExportForTest()
+	internalSvcExport.Finalizers = []string{objectmeta.InternalServiceExportFinalizer}
+	internalSvcExport.Status.Conditions = []metav1.Condition{
+		unconflictedServiceExportConflictCondition(testNamespace, testServiceName),
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testServiceName,
+			Namespace: testNamespace,
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Ports: internalSvcExport.Spec.Ports,
+			Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
+				{Cluster: testClusterID},
+				{Cluster: "member-2"},
+			},
+			Type: fleetnetv1alpha1.ClusterSetIP,
+		},
+	}
+	endpointSliceExport := func(clusterID string, ready bool) *fleetnetv1alpha1.EndpointSliceExport {
+		return &fleetnetv1alpha1.EndpointSliceExport{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: clusterID + "-ns",
+				Name:      "my-svc-slice",
+				Labels: map[string]string{
+					objectmeta.EndpointSliceExportLabelOwnerServiceNamespace: testNamespace,
+					objectmeta.EndpointSliceExportLabelOwnerServiceName:      testServiceName,
+				},
+			},
+			Spec: fleetnetv1alpha1.EndpointSliceExportSpec{
+				Endpoints: []fleetnetv1alpha1.Endpoint{
+					{
+						Addresses:  []string{"10.0.0.1"},
+						Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(ready)},
+					},
+				},
+				EndpointSliceReference: fleetnetv1alpha1.ExportedObjectReference{ClusterID: clusterID},
+				OwnerServiceReference: fleetnetv1alpha1.OwnerServiceReference{
+					Namespace:      testNamespace,
+					Name:           testServiceName,
+					NamespacedName: testNamespace + "/" + testServiceName,
+				},
+			},
+		}
+	}
+
+	objects := []client.Object{internalSvcExport, serviceImport}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(internalServiceExportScheme(t)).
+		WithObjects(append(objects, endpointSliceExport(testClusterID, false), endpointSliceExport("member-2", true))...).
+		WithStatusSubresource(objects...).
+		WithIndex(&fleetnetv1alpha1.EndpointSliceExport{}, ".spec.ownerServiceReference.namespacedName", func(o client.Object) []string {
+			return []string{o.(*fleetnetv1alpha1.EndpointSliceExport).Spec.OwnerServiceReference.NamespacedName}
+		}).
+		Build()
+	r := internalServiceExportReconciler(fakeClient)
+	r.HealthEvaluator = &clusterhealth.Evaluator{Interval: time.Minute}
+
+	name := types.NamespacedName{Namespace: testMemberNamespace, Name: testName}
+	serviceImportName := types.NamespacedName{Namespace: testNamespace, Name: testServiceName}
+	options := []cmp.Option{
+		cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime", "Message"),
+	}
+
+	testCases := []struct {
+		name           string
+		ready          bool
+		wantConditions []metav1.Condition
+		wantClusters   []fleetnetv1alpha1.ServiceImportClusterStatus
+	}{
+		{
+			name: "export has no ready endpoints",
+			wantConditions: []metav1.Condition{
+				{
+					Type:   string(fleetnetv1alpha1.ServiceExportUnhealthy),
+					Status: metav1.ConditionTrue,
+					Reason: clusterhealth.ReasonNoReadyEndpoints,
+				},
+			},
+			wantClusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-2"}},
+		},
+		{
+			name:  "export has recovered",
+			ready: true,
+			wantConditions: []metav1.Condition{
+				unconflictedServiceExportConflictCondition(testNamespace, testServiceName),
+			},
+			wantClusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-2"}, {Cluster: testClusterID}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotEndpointSliceExport := &fleetnetv1alpha1.EndpointSliceExport{}
+			if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: testMemberNamespace, Name: "my-svc-slice"}, gotEndpointSliceExport); err != nil {
+				t.Fatalf("EndpointSliceExport Get() got error %v, want no error", err)
+			}
+			gotEndpointSliceExport.Spec.Endpoints[0].Conditions.Ready = ptr.To(tc.ready)
+			if err := fakeClient.Update(ctx, gotEndpointSliceExport); err != nil {
+				t.Fatalf("EndpointSliceExport Update() got error %v, want no error", err)
+			}
+
+			got, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: name})
+			if err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+			if want := (ctrl.Result{RequeueAfter: time.Minute}); !cmp.Equal(got, want) {
+				t.Errorf("Reconcile() = %+v, want %+v", got, want)
+			}
+
+			gotInternalSvcExport := fleetnetv1alpha1.InternalServiceExport{}
+			if err := fakeClient.Get(ctx, name, &gotInternalSvcExport); err != nil {
+				t.Fatalf("InternalServiceExport Get() got error %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.wantConditions, gotInternalSvcExport.Status.Conditions, options...); diff != "" {
+				t.Errorf("InternalServiceExport conditions mismatch (-want, +got):\n%s", diff)
+			}
+			gotServiceImport := fleetnetv1alpha1.ServiceImport{}
+			if err := fakeClient.Get(ctx, serviceImportName, &gotServiceImport); err != nil {
+				t.Fatalf("ServiceImport Get() got error %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.wantClusters, gotServiceImport.Status.Clusters); diff != "" {
+				t.Errorf("ServiceImport clusters mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestReconcile_Stale tests that an export marked as stale is excluded from its serviceImport once its grace period
+// ends, and rejoins the serviceImport once the mark is lifted.
+func TestReconcile_Stale(t *testing.T) {
+	ctx := context.Background()
+	internalSvcExport := internalServiceExportForTest()
+	internalSvcExport.Finalizers = []string{objectmeta.InternalServiceExportFinalizer}
+	internalSvcExport.Status.Conditions = []metav1.Condition{
+		unconflictedServiceExportConflictCondition(testNamespace, testServiceName),
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testServiceName,
+			Namespace: testNamespace,
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Ports: internalSvcExport.Spec.Ports,
+			Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
+				{Cluster: testClusterID},
+				{Cluster: "member-2"},
+			},
+			Type: fleetnetv1alpha1.ClusterSetIP,
+		},
+	}
+
+	objects := []client.Object{internalSvcExport, serviceImport}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(internalServiceExportScheme(t)).
+		WithObjects(objects...).
+		WithStatusSubresource(objects...).
+		Build()
+	const gracePeriod = 5 * time.Minute
+	r := internalServiceExportReconciler(fakeClient)
+	r.StaleExportGracePeriod = gracePeriod
+
+	name := types.NamespacedName{Namespace: testMemberNamespace, Name: testName}
+	serviceImportName := types.NamespacedName{Namespace: testNamespace, Name: testServiceName}
+	options := []cmp.Option{
+		cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime", "Message"),
+	}
+
+	testCases := []struct {
+		name           string
+		staleFor       time.Duration
+		wantRequeue    bool
+		wantConditions []metav1.Condition
+		wantClusters   []fleetnetv1alpha1.ServiceImportClusterStatus
+	}{
+		{
+			name:        "export is stale within the grace period",
+			staleFor:    time.Minute,
+			wantRequeue: true,
+			wantConditions: []metav1.Condition{
+				unconflictedServiceExportConflictCondition(testNamespace, testServiceName),
+			},
+			wantClusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: testClusterID}, {Cluster: "member-2"}},
+		},
+		{
+			name:     "export is stale for the grace period",
+			staleFor: 10 * time.Minute,
+			wantConditions: []metav1.Condition{
+				{
+					Type:   string(fleetnetv1alpha1.ServiceExportStale),
+					Status: metav1.ConditionTrue,
+					Reason: staleexport.ReasonAgentNotRenewing,
+				},
+			},
+			wantClusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-2"}},
+		},
+		{
+			name: "member cluster reports again",
+			wantConditions: []metav1.Condition{
+				unconflictedServiceExportConflictCondition(testNamespace, testServiceName),
+			},
+			wantClusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-2"}, {Cluster: testClusterID}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotInternalSvcExport := &fleetnetv1alpha1.InternalServiceExport{}
+			if err := fakeClient.Get(ctx, name, gotInternalSvcExport); err != nil {
+				t.Fatalf("InternalServiceExport Get() got error %v, want no error", err)
+			}
+			staleexport.Unmark(gotInternalSvcExport)
+			if tc.staleFor > 0 {
+				staleexport.Mark(gotInternalSvcExport, time.Now().Add(-tc.staleFor))
+			}
+			if err := fakeClient.Update(ctx, gotInternalSvcExport); err != nil {
+				t.Fatalf("InternalServiceExport Update() got error %v, want no error", err)
+			}
+
+			got, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: name})
+			if err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+			if gotRequeue := got.RequeueAfter > 0 && got.RequeueAfter <= gracePeriod; gotRequeue != tc.wantRequeue {
+				t.Errorf("Reconcile() = %+v, want a requeue within the grace period %t", got, tc.wantRequeue)
+			}
+
+			if err := fakeClient.Get(ctx, name, gotInternalSvcExport); err != nil {
+				t.Fatalf("InternalServiceExport Get() got error %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.wantConditions, gotInternalSvcExport.Status.Conditions, options...); diff != "" {
+				t.Errorf("InternalServiceExport conditions mismatch (-want, +got):\n%s", diff)
+			}
+			gotServiceImport := fleetnetv1alpha1.ServiceImport{}
+			if err := fakeClient.Get(ctx, serviceImportName, &gotServiceImport); err != nil {
+				t.Fatalf("ServiceImport Get() got error %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.wantClusters, gotServiceImport.Status.Clusters); diff != "" {
+				t.Errorf("ServiceImport clusters mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// fakeConflictSink records the conflicts it is notified of.
+type fakeConflictSink struct {
+	mu        sync.Mutex
+	conflicts []conflictnotify.Conflict
+}
+
+func (s *fakeConflictSink) Notify(_ context.Context, conflict conflictnotify.Conflict) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.conflicts = append(s.conflicts, conflict)
+	return nil
+}
+
+func TestReconcile_ConflictNotification(t *testing.T) {
+	ctx := context.Background()
+	internalSvcExport := internalServiceExportForTest()
+	internalSvcExport.Finalizers = []string{objectmeta.InternalServiceExportFinalizer}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testServiceName,
+			Namespace: testNamespace,
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Ports: []fleetnetv1alpha1.ServicePort{
+				{
+					Name:       "portA",
+					Protocol:   "TCP",
+					Port:       7070,
+					TargetPort: intstr.IntOrString{IntVal: 7070},
+				},
+			},
+			Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
+				{Cluster: "member-2"},
+				{Cluster: "member-3"},
+			},
+			Type: fleetnetv1alpha1.ClusterSetIP,
+		},
+	}
+	objects := []client.Object{internalSvcExport, serviceImport}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(internalServiceExportScheme(t)).
+		WithObjects(objects...).
+		WithStatusSubresource(objects...).
+		Build()
+	sink := &fakeConflictSink{}
+	r := internalServiceExportReconciler(fakeClient)
+	r.ConflictNotifier = conflictnotify.NewNotifier(sink)
+
+	name := types.NamespacedName{Namespace: testMemberNamespace, Name: testName}
+	reconcile := func() {
+		if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: name}); err != nil {
+			t.Fatalf("Reconcile() = %v, want no error", err)
+		}
+		r.ConflictNotifier.Wait()
+	}
+	setServiceImportPorts := func(ports []fleetnetv1alpha1.ServicePort) {
+		si := &fleetnetv1alpha1.ServiceImport{}
+		if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: testNamespace, Name: testServiceName}, si); err != nil {
+			t.Fatalf("ServiceImport Get() got error %v, want no error", err)
+		}
+		si.Status.Ports = ports
+		if err := fakeClient.Status().Update(ctx, si); err != nil {
+			t.Fatalf("ServiceImport Update() got error %v, want no error", err)
+		}
+	}
+
+	// The conflict is notified once, however many times the export is reconciled.
+	reconcile()
+	reconcile()
+	wantConflict := conflictnotify.Conflict{
+		Namespace:           testNamespace,
+		Service:             testServiceName,
+		Cluster:             testClusterID,
+		ConflictingClusters: []string{"member-2", "member-3"},
+	}
+	if diff := cmp.Diff([]conflictnotify.Conflict{wantConflict}, sink.conflicts); diff != "" {
+		t.Errorf("notified conflicts mismatch (-want, +got):\n%s", diff)
+	}
+
+	// The conflict is resolved and then arises again, which is a new conflict.
+	ports := serviceImport.Status.Ports
+	setServiceImportPorts(internalSvcExport.Spec.Ports)
+	reconcile()
+	setServiceImportPorts(ports)
+	reconcile()
+	if diff := cmp.Diff([]conflictnotify.Conflict{wantConflict, wantConflict}, sink.conflicts); diff != "" {
+		t.Errorf("notified conflicts after the conflict arises again mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+// TestReconcile_ConflictResolutionPreemption tests that an export in conflict with its serviceImport resets the
+// resolved spec if it outranks the winning export by the conflict resolution policy.
+func TestReconcile_ConflictResolutionPreemption(t *testing.T) {
+	memberCluster := func(name, priority string) *clusterv1beta1.MemberCluster {
+		return &clusterv1beta1.MemberCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{objectmeta.MemberClusterLabelExportPriority: priority},
+			},
+		}
+	}
+	testCases := []struct {
+		name           string
+		policy         fleetnetv1alpha1.ConflictResolutionPolicy
+		annotations    map[string]string
+		memberClusters []client.Object
+		wantPreempted  bool
+	}{
+		{
+			name:   "newer export does not preempt the oldest export",
+			policy: fleetnetv1alpha1.OldestExportWins,
+		},
+		{
+			name:           "export from a cluster with a higher priority preempts the winning export",
+			policy:         fleetnetv1alpha1.ClusterPriority,
+			memberClusters: []client.Object{memberCluster(testClusterID, "5")},
+			wantPreempted:  true,
+		},
+		{
+			name:           "export from a cluster with a lower priority does not preempt the winning export",
+			policy:         fleetnetv1alpha1.ClusterPriority,
+			memberClusters: []client.Object{memberCluster("member-2", "5")},
+		},
+		{
+			name:          "export from the cluster named by the operator preempts the winning export",
+			policy:        fleetnetv1alpha1.ManualConflictResolution,
+			annotations:   map[string]string{objectmeta.ServiceImportAnnotationConflictResolutionWinner: testClusterID},
+			wantPreempted: true,
+		},
+		{
+			name:        "export from a cluster not named by the operator does not preempt the winning export",
+			policy:      fleetnetv1alpha1.ManualConflictResolution,
+			annotations: map[string]string{objectmeta.ServiceImportAnnotationConflictResolutionWinner: "member-3"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			internalSvcExport := internalServiceExportForTest()
+			internalSvcExport.Finalizers = []string{objectmeta.InternalServiceExportFinalizer}
+			resolution := &fleetnetv1alpha1.ConflictResolutionStatus{Policy: tc.policy, WinningCluster: "member-2"}
+			serviceImport := &fleetnetv1alpha1.ServiceImport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        testServiceName,
+					Namespace:   testNamespace,
+					Annotations: tc.annotations,
+				},
+				Status: fleetnetv1alpha1.ServiceImportStatus{
+					Ports: []fleetnetv1alpha1.ServicePort{
+						{
+							Name:       "portA",
+							Protocol:   "TCP",
+							Port:       7070,
+							TargetPort: intstr.IntOrString{IntVal: 7070},
+						},
+					},
+					Clusters:           []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-2"}},
+					Type:               fleetnetv1alpha1.ClusterSetIP,
+					ConflictResolution: resolution,
+				},
+			}
+			scheme := internalServiceExportScheme(t)
+			if err := clusterv1beta1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			objects := []client.Object{internalSvcExport, serviceImport}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(append(objects, tc.memberClusters...)...).
+				WithStatusSubresource(objects...).
+				Build()
+			r := internalServiceExportReconciler(fakeClient)
+			r.ConflictResolver = &conflictresolution.Resolver{Reader: fakeClient, DefaultPolicy: tc.policy}
+
+			name := types.NamespacedName{Namespace: testMemberNamespace, Name: testName}
+			if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: name}); err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+			gotServiceImport := &fleetnetv1alpha1.ServiceImport{}
+			if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: testNamespace, Name: testServiceName}, gotServiceImport); err != nil {
+				t.Fatalf("ServiceImport Get() = %v, want no error", err)
+			}
+			gotInternalSvcExport := &fleetnetv1alpha1.InternalServiceExport{}
+			if err := fakeClient.Get(ctx, name, gotInternalSvcExport); err != nil {
+				t.Fatalf("InternalServiceExport Get() = %v, want no error", err)
+			}
+			cond := meta.FindStatusCondition(gotInternalSvcExport.Status.Conditions, string(fleetnetv1alpha1.ServiceExportConflict))
+			if tc.wantPreempted {
+				if diff := cmp.Diff(fleetnetv1alpha1.ServiceImportStatus{}, gotServiceImport.Status); diff != "" {
+					t.Errorf("ServiceImport status mismatch (-want, +got):\n%s", diff)
+				}
+				if cond != nil {
+					t.Errorf("InternalServiceExport conflict condition = %v, want none before the spec is resolved again", cond)
+				}
+				return
+			}
+			if diff := cmp.Diff(resolution, gotServiceImport.Status.ConflictResolution); diff != "" {
+				t.Errorf("ServiceImport conflictResolution mismatch (-want, +got):\n%s", diff)
+			}
+			wantMessage := fmt.Sprintf("service %s/%s is in conflict with other exported services; the spec exported from cluster member-2 wins by the %s policy",
+				testNamespace, testServiceName, tc.policy)
+			if cond == nil || cond.Status != metav1.ConditionTrue || cond.Message != wantMessage {
+				t.Errorf("InternalServiceExport conflict condition = %v, want true with message %q", cond, wantMessage)
+			}
+		})
+	}
+}
+
+func TestIsServiceImportResolutionChanged(t *testing.T) {
+	resolvedStatus := fleetnetv1alpha1.ServiceImportStatus{
+		Type:     fleetnetv1alpha1.ClusterSetIP,
+		Ports:    []fleetnetv1alpha1.ServicePort{{Name: "portA", Protocol: "TCP", Port: 8080}},
+		Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: testClusterID}},
+	}
+	otherPortsStatus := *resolvedStatus.DeepCopy()
+	otherPortsStatus.Ports[0].Port = 9090
+	otherClustersStatus := *resolvedStatus.DeepCopy()
+	otherClustersStatus.Clusters = append(otherClustersStatus.Clusters, fleetnetv1alpha1.ServiceImportClusterStatus{Cluster: "member-2"})
+
+	testCases := []struct {
+		name      string
+		oldStatus fleetnetv1alpha1.ServiceImportStatus
+		newStatus fleetnetv1alpha1.ServiceImportStatus
+		want      bool
+	}{
+		{
+			name: "still unresolved",
+		},
+		{
+			name:      "newly resolved",
+			newStatus: resolvedStatus,
+			want:      true,
+		},
+		{
+			name:      "reset",
+			oldStatus: resolvedStatus,
+		},
+		{
+			name:      "resolved to another spec",
+			oldStatus: resolvedStatus,
+			newStatus: otherPortsStatus,
+			want:      true,
+		},
+		{
+			name:      "another cluster accepted",
+			oldStatus: resolvedStatus,
+			newStatus: otherClustersStatus,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			oldServiceImport := &fleetnetv1alpha1.ServiceImport{Status: tc.oldStatus}
+			newServiceImport := &fleetnetv1alpha1.ServiceImport{Status: tc.newStatus}
+			if got := isServiceImportResolutionChanged(oldServiceImport, newServiceImport); got != tc.want {
+				t.Errorf("isServiceImportResolutionChanged() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestIsImportedByOtherClusters(t *testing.T) {
+	testCases := []struct {
+		name        string
+		annotations map[string]string
+		want        bool
+	}{
+		{
+			name: "not imported",
+		},
+		{
+			name: "imported by the exporting cluster only",
+			annotations: map[string]string{
+				objectmeta.ServiceImportAnnotationServiceInUseBy: `{"MemberClusters":{"fleet-member-member-1":"` + testClusterID + `"}}`,
+			},
+		},
+		{
+			name: "imported by another cluster",
+			annotations: map[string]string{
+				objectmeta.ServiceImportAnnotationServiceInUseBy: `{"MemberClusters":{"fleet-member-member-1":"` + testClusterID + `","fleet-member-member-2":"member-2"}}`,
+			},
+			want: true,
+		},
+		{
+			name: "corrupted annotation",
+			annotations: map[string]string{
+				objectmeta.ServiceImportAnnotationServiceInUseBy: "{",
+			},
+			want: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			serviceImport := &fleetnetv1alpha1.ServiceImport{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+			if got := isImportedByOtherClusters(serviceImport, testClusterID); got != tc.want {
+				t.Errorf("isImportedByOtherClusters() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEnqueueServiceInternalServiceExports(t *testing.T) {
+	otherServiceExport := internalServiceExportForTest()
+	otherServiceExport.Name = "my-ns-other-svc"
+	otherServiceExport.Spec.ServiceReference.Name = "other-svc"
+	otherServiceExport.Spec.ServiceReference.NamespacedName = testNamespace + "/other-svc"
+	otherClusterExport := internalServiceExportForTest()
+	otherClusterExport.Namespace = "member-2-ns"
+	otherClusterExport.Spec.ServiceReference.ClusterID = "member-2"
+	internalServiceExport := internalServiceExportForTest()
+	for _, export := range []*fleetnetv1alpha1.InternalServiceExport{internalServiceExport, otherClusterExport} {
+		export.Spec.ServiceReference.NamespacedName = testNamespace + "/" + testServiceName
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(internalServiceExportScheme(t)).
+		WithObjects(internalServiceExport, otherServiceExport, otherClusterExport).
+		WithIndex(&fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldNamespacedName, func(o client.Object) []string {
+			return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.NamespacedName}
+		}).
+		Build()
+	r := internalServiceExportReconciler(fakeClient)
+
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testServiceName,
+		},
+	}
+	got := r.enqueueServiceInternalServiceExports(context.Background(), serviceImport)
+	want := []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: "member-2-ns", Name: testName}},
+		{NamespacedName: types.NamespacedName{Namespace: testMemberNamespace, Name: testName}},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.SortSlices(func(a, b reconcile.Request) bool {
+		return a.String() < b.String()
+	})); diff != "" {
+		t.Errorf("enqueueServiceInternalServiceExports() mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+// TestShardKey tests that two shards reconciling the exports of the same service from member clusters of different
+// shards leave them all to the shard of its serviceImport, so that the serviceImport has a single writer.
+func TestShardKey(t *testing.T) {
+	shards := []sharding.Shard{{Index: 0, Count: 2}, {Index: 1, Count: 2}}
+	// The member namespaces of the exports are owned by different shards.
+	memberNamespaces := make([]string, 2)
+	for i := 0; memberNamespaces[0] == "" || memberNamespaces[1] == ""; i++ {
+		namespace := fmt.Sprintf("member-%d-ns", i)
+		for index, shard := range shards {
+			if shard.Owns(namespace) {
+				memberNamespaces[index] = namespace
+			}
+		}
+	}
+	var exports []client.Object
+	var requests []reconcile.Request
+	for i, namespace := range memberNamespaces {
+		export := internalServiceExportForTest()
+		export.Namespace = namespace
+		export.Spec.ServiceReference.ClusterID = fmt.Sprintf("member-%d", i)
+		exports = append(exports, export)
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: testName}})
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(internalServiceExportScheme(t)).
+		WithObjects(exports...).
+		Build()
+	serviceImportRequest := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: testServiceName}}
+	// The export which is gone is reconciled by every shard.
+	goneRequest := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: memberNamespaces[0], Name: "gone"}}
+
+	owners := 0
+	for _, shard := range shards {
+		q := shard.NewQueueFunc(ShardKey(fakeClient))("internalserviceexport", workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+		for _, req := range requests {
+			q.Add(req)
+		}
+		q.Add(goneRequest)
+		serviceImportQueue := shard.NewQueueFunc(sharding.ObjectKey)("serviceimport", workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+		serviceImportQueue.Add(serviceImportRequest)
+
+		want := 1
+		if serviceImportQueue.Len() == 1 {
+			owners++
+			want += len(requests)
+		}
+		if got := q.Len(); got != want {
+			t.Errorf("shard %d: Len() = %d, want %d", shard.Index, got, want)
+		}
+		q.ShutDown()
+		serviceImportQueue.ShutDown()
+	}
+	if owners != 1 {
+		t.Errorf("serviceImport is owned by %d shards, want 1", owners)
+	}
+}
+
+func TestEnqueueClusterInternalServiceExports(t *testing.T) {
+	otherClusterExport := internalServiceExportForTest()
+	otherClusterExport.Namespace = "member-2-ns"
+	otherClusterExport.Spec.ServiceReference.ClusterID = "member-2"
+	otherServiceExport := internalServiceExportForTest()
+	otherServiceExport.Name = "my-ns-other-svc"
+	otherServiceExport.Spec.ServiceReference.Name = "other-svc"
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(internalServiceExportScheme(t)).
+		WithObjects(internalServiceExportForTest(), otherClusterExport, otherServiceExport).
+		WithIndex(&fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldClusterID, func(o client.Object) []string {
+			return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.ClusterID}
+		}).
+		Build()
+	r := internalServiceExportReconciler(fakeClient)
+
+	mc := &clusterv1beta1.MemberCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: testClusterID,
+		},
+	}
+	got := r.enqueueClusterInternalServiceExports(context.Background(), mc)
+	want := []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: testMemberNamespace, Name: "my-ns-other-svc"}},
+		{NamespacedName: types.NamespacedName{Namespace: testMemberNamespace, Name: testName}},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.SortSlices(func(a, b reconcile.Request) bool {
+		return a.String() < b.String()
+	})); diff != "" {
+		t.Errorf("enqueueClusterInternalServiceExports() mismatch (-want, +got):\n%s", diff)
+	}
+}
@@ -113,7 +113,8 @@ var _ = BeforeSuite(func() {
 	err = (&Reconciler{
 		Client:        mgr.GetClient(),
 		RetryInternal: 10 * time.Millisecond,
-	}).SetupWithManager(mgr)
+		Recorder:      mgr.GetEventRecorderFor(ControllerName),
+	}).SetupWithManager(ctx, mgr, false)
 	Expect(err).ToNot(HaveOccurred())
 
 	ctx, cancel = context.WithCancel(context.TODO())
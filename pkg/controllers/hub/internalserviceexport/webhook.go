@@ -0,0 +1,70 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package internalserviceexport
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/namespacesameness"
+)
+
+// Validator validates InternalServiceExports at admission time, rejecting the exports of the Services in the
+// namespaces excluded from multi-cluster networking by the NamespaceSamenessPolicies of the hub cluster.
+type Validator struct {
+	// Reader reads the NamespaceSamenessPolicies and the namespaces of the hub cluster.
+	Reader client.Reader
+}
+
+//+kubebuilder:webhook:path=/validate-networking-fleet-azure-com-v1alpha1-internalserviceexport,mutating=false,failurePolicy=fail,sideEffects=None,groups=networking.fleet.azure.com,resources=internalserviceexports,verbs=create,versions=v1alpha1,name=vinternalserviceexport.networking.fleet.azure.com,admissionReviewVersions=v1
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=namespacesamenesspolicies,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+var _ admission.CustomValidator = &Validator{}
+
+// ValidateCreate implements admission.CustomValidator.
+func (v *Validator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	internalSvcExport, ok := obj.(*fleetnetv1alpha1.InternalServiceExport)
+	if !ok {
+		return nil, fmt.Errorf("expected an InternalServiceExport, got %T", obj)
+	}
+	namespace := internalSvcExport.Spec.ServiceReference.Namespace
+	reason, err := namespacesameness.Check(ctx, v.Reader, namespace)
+	if err != nil {
+		klog.ErrorS(err, "Failed to evaluate the namespace sameness policies", "internalServiceExport", klog.KObj(internalSvcExport))
+		return nil, fmt.Errorf("failed to evaluate the namespace sameness policies: %w", err)
+	}
+	if reason != "" {
+		return nil, fmt.Errorf("service %s/%s cannot be exported: %s", namespace, internalSvcExport.Spec.ServiceReference.Name, reason)
+	}
+	return nil, nil
+}
+
+// ValidateUpdate implements admission.CustomValidator; the exports admitted before a policy changes are withdrawn by
+// the member clusters, and must remain updatable until then, e.g. to have their finalizers removed.
+func (v *Validator) ValidateUpdate(_ context.Context, _, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// ValidateDelete implements admission.CustomValidator; InternalServiceExports can always be deleted.
+func (v *Validator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// SetupWebhookWithManager registers the validating webhook of InternalServiceExports with the manager.
+func (v *Validator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&fleetnetv1alpha1.InternalServiceExport{}).
+		WithValidator(v).
+		Complete()
+}
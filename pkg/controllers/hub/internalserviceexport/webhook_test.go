@@ -0,0 +1,79 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package internalserviceexport
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+// TestValidator_ValidateCreate tests the Validator.ValidateCreate method.
+func TestValidator_ValidateCreate(t *testing.T) {
+	policy := func(spec fleetnetv1alpha1.NamespaceSamenessPolicySpec) *fleetnetv1alpha1.NamespaceSamenessPolicy {
+		return &fleetnetv1alpha1.NamespaceSamenessPolicy{ObjectMeta: metav1.ObjectMeta{Name: "sameness"}, Spec: spec}
+	}
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: testNamespace, Labels: map[string]string{"exposure": "fleet"}},
+	}
+
+	testCases := []struct {
+		name    string
+		objects []client.Object
+		wantErr string
+	}{
+		{
+			name: "no policy",
+		},
+		{
+			name: "namespace allowed by the policy",
+			objects: []client.Object{namespace, policy(fleetnetv1alpha1.NamespaceSamenessPolicySpec{
+				AllowedNamespaces: &metav1.LabelSelector{MatchLabels: map[string]string{"exposure": "fleet"}},
+			})},
+		},
+		{
+			name: "namespace not allowed by the policy",
+			objects: []client.Object{policy(fleetnetv1alpha1.NamespaceSamenessPolicySpec{
+				AllowedNamespaces: &metav1.LabelSelector{MatchLabels: map[string]string{"exposure": "fleet"}},
+			})},
+			wantErr: "service my-ns/my-svc cannot be exported: namespace my-ns is not allowed by namespace sameness policy sameness",
+		},
+		{
+			name: "namespace denied by name",
+			objects: []client.Object{namespace, policy(fleetnetv1alpha1.NamespaceSamenessPolicySpec{
+				DeniedNamespaces: &metav1.LabelSelector{MatchLabels: map[string]string{corev1.LabelMetadataName: testNamespace}},
+			})},
+			wantErr: "namespace my-ns is denied by namespace sameness policy sameness",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := internalServiceExportScheme(t)
+			if err := corev1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			v := &Validator{Reader: fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.objects...).Build()}
+
+			_, err := v.ValidateCreate(context.Background(), internalServiceExportForTest())
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateCreate() = %v, want no error", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("ValidateCreate() = %v, want error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
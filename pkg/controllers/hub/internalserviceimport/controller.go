@@ -14,17 +14,30 @@ import (
 	"reflect"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	clusterv1beta1 "go.goms.io/fleet/apis/cluster/v1beta1"
+
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
 	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/pause"
+	"go.goms.io/fleet-networking/pkg/common/sharding"
+	"go.goms.io/fleet-networking/pkg/common/tenancy"
 )
 
 const (
@@ -34,16 +47,28 @@ const (
 	internalSvcImportSvcRefNamespacedNameFieldKey = ".spec.serviceImportReference.namespacedName"
 
 	internalSvcImportRetryInterval = time.Second * 2
+
+	// consumerNotAllowedReason is the reason of the NotAllowed condition of the imports rejected by the consumer
+	// restrictions of the exporting clusters.
+	consumerNotAllowedReason = "ConsumerNotAllowed"
 )
 
 // Reconciler reconciles an InternalServiceImport object.
 type Reconciler struct {
 	HubClient client.Client
+	// EnableTenantIsolation partitions the fleet by the tenants of the member clusters, set with the tenant label on
+	// their namespaces; only the member clusters of the tenant owning a ServiceImport import it.
+	EnableTenantIsolation bool
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
 }
 
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalserviceimports,verbs=get;list;watch
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports,verbs=get;list
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=cluster.kubernetes-fleet.io,resources=memberclusters,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
 
 // Reconcile checks if a member cluster can import a Service from the hub cluster and fulfills the import.
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -89,6 +114,13 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		// An unexpected error occurred.
 		klog.ErrorS(err, "Failed to get ServiceImport", "serviceImport", svcImportRef, "internalServiceImport", internalSvcImportRef)
 		return ctrl.Result{}, err
+	case svcImport.DeletionTimestamp == nil && pause.IsPaused(svcImport):
+		// The Service import request is left as it is; the InternalServiceImport will be re-processed when the
+		// reconciliation of the ServiceImport resumes.
+		klog.V(2).InfoS("Reconciliation of the ServiceImport is paused",
+			"serviceImport", svcImportRef,
+			"internalServiceImport", internalSvcImportRef)
+		return ctrl.Result{}, nil
 	case svcImport.DeletionTimestamp == nil && len(svcImport.Status.Clusters) == 0:
 		// The ServiceImport is being processed; requeue the InternalServiceImport for later processing.
 		klog.V(2).InfoS("ServiceImport is being processed; requeue for later processing",
@@ -114,6 +146,46 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	clusterNamespace := fleetnetv1alpha1.ClusterNamespace(internalSvcImport.Namespace)
 	clusterID := fleetnetv1alpha1.ClusterID(internalSvcImport.Spec.ServiceImportReference.ClusterID)
 
+	// Reject the import if the member cluster belongs to a tenant other than the one owning the ServiceImport; a
+	// ServiceImport not owned by any tenant yet is imported by all of them.
+	if r.EnableTenantIsolation {
+		if owner, owned := tenancy.Owner(svcImport); owned {
+			tenant, err := tenancy.TenantOf(ctx, r.HubClient, internalSvcImport.Namespace)
+			if err != nil {
+				klog.ErrorS(err, "Failed to get the tenant of the member cluster", "internalServiceImport", internalSvcImportRef)
+				return ctrl.Result{}, err
+			}
+			if tenant != owner {
+				klog.V(2).InfoS("The member cluster belongs to a tenant not owning the Service",
+					"serviceImport", svcImportRef,
+					"internalServiceImport", internalSvcImportRef,
+					"tenant", tenant,
+					"owner", owner)
+				return r.rejectServiceImport(ctx, svcImport, internalSvcImport, tenancy.ReasonOwnedByOtherTenant,
+					fmt.Sprintf("service is owned by tenant %q of the fleet and member cluster %s of tenant %q is not allowed to import it", owner, clusterID, tenant))
+			}
+		}
+	}
+
+	// Reject the import if the member cluster is not allowed by the consumer restrictions of the exporting
+	// clusters; an import fulfilled before the restrictions changed is withdrawn, which stops the distribution of
+	// the endpoints to the member cluster. The restrictions selecting the clusters by their labels are re-evaluated
+	// on the periodic resyncs when the labels change.
+	notAllowedReason, err := r.checkAllowedConsumers(ctx, svcImport, string(clusterID))
+	if err != nil {
+		klog.ErrorS(err, "Failed to check the consumer restrictions of the Service",
+			"serviceImport", svcImportRef,
+			"internalServiceImport", internalSvcImportRef)
+		return ctrl.Result{}, err
+	}
+	if notAllowedReason != "" {
+		klog.V(2).InfoS("The member cluster is not allowed to import the Service",
+			"serviceImport", svcImportRef,
+			"internalServiceImport", internalSvcImportRef,
+			"reason", notAllowedReason)
+		return r.rejectServiceImport(ctx, svcImport, internalSvcImport, consumerNotAllowedReason, notAllowedReason)
+	}
+
 	// Find out which member clusters have imported the Service.
 	svcInUseBy := extractServiceInUseByInfoFromServiceImport(svcImport)
 	if len(svcInUseBy.MemberClusters) > 0 {
@@ -169,6 +241,20 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	return ctrl.Result{}, nil
 }
 
+// ShardKey returns the shard key of the requests of the controller, the namespaced name of the imported
+// serviceImport, as the controller writes the serviceImport; the imports of a service are thus reconciled by the
+// shard of its serviceImport.
+func ShardKey(reader client.Reader) sharding.KeyFunc {
+	return func(req reconcile.Request) (string, bool) {
+		var internalSvcImport fleetnetv1alpha1.InternalServiceImport
+		if err := reader.Get(context.Background(), req.NamespacedName, &internalSvcImport); err != nil {
+			return "", false
+		}
+		svcImportRef := internalSvcImport.Spec.ServiceImportReference
+		return types.NamespacedName{Namespace: svcImportRef.Namespace, Name: svcImportRef.Name}.String(), true
+	}
+}
+
 // SetupWithManager sets up the InternalServiceImport controller with a controller manager.
 func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
 	// Set up an index for efficient InternalServiceImport lookup.
@@ -216,10 +302,44 @@ func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) err
 		return reqs
 	})
 
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&fleetnetv1alpha1.InternalServiceImport{}).
-		Watches(&fleetnetv1alpha1.ServiceImport{}, eventHandlers).
-		Complete(r)
+		Watches(&fleetnetv1alpha1.ServiceImport{}, eventHandlers)
+	if r.EnableTenantIsolation {
+		// Re-evaluate the imports of a member cluster whenever it moves to another tenant.
+		b = b.Watches(&corev1.Namespace{},
+			handler.EnqueueRequestsFromMapFunc(r.enqueueNamespaceInternalServiceImports),
+			builder.WithPredicates(predicate.Funcs{
+				CreateFunc: func(_ event.CreateEvent) bool {
+					return false
+				},
+				UpdateFunc: func(e event.UpdateEvent) bool {
+					return tenancy.IsTenantLabelChanged(e.ObjectOld, e.ObjectNew)
+				},
+				DeleteFunc: func(_ event.DeleteEvent) bool {
+					return false
+				},
+				GenericFunc: func(_ event.GenericEvent) bool {
+					return false
+				},
+			}))
+	}
+	return b.WithOptions(r.ControllerOptions).
+		Complete(metrics.InstrumentReconciler("internalserviceimport", r))
+}
+
+// enqueueNamespaceInternalServiceImports enqueues the InternalServiceImports in the namespace of a member cluster.
+func (r *Reconciler) enqueueNamespaceInternalServiceImports(ctx context.Context, ns client.Object) []reconcile.Request {
+	internalSvcImportList := &fleetnetv1alpha1.InternalServiceImportList{}
+	if err := r.HubClient.List(ctx, internalSvcImportList, client.InNamespace(ns.GetName())); err != nil {
+		klog.ErrorS(err, "Failed to list InternalServiceImports to apply the tenant change", "namespace", klog.KObj(ns))
+		return []reconcile.Request{}
+	}
+	reqs := make([]reconcile.Request, 0, len(internalSvcImportList.Items))
+	for i := range internalSvcImportList.Items {
+		reqs = append(reqs, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&internalSvcImportList.Items[i])})
+	}
+	return reqs
 }
 
 // withdrawServiceImport withdraws the request to import a Service to a member cluster.
@@ -268,6 +388,70 @@ func (r *Reconciler) withdrawServiceImport(ctx context.Context,
 	return ctrl.Result{}, nil
 }
 
+// checkAllowedConsumers returns the reason why the member cluster is not allowed to import the Service by the
+// consumer restrictions of the exporting clusters, or an empty string if it is allowed by all of them; a restriction
+// with an invalid cluster selector allows no cluster, so that a typo never widens the exposure.
+func (r *Reconciler) checkAllowedConsumers(ctx context.Context, svcImport *fleetnetv1alpha1.ServiceImport, cluster string) (string, error) {
+	var clusterLabels map[string]string
+	for i := range svcImport.Status.AllowedConsumers {
+		allowed := &svcImport.Status.AllowedConsumers[i]
+		if allowed.ClusterSelector != nil && clusterLabels == nil {
+			// The labels of the MemberCluster are only retrieved if a restriction selects the clusters by labels;
+			// a MemberCluster which does not exist, e.g. one which has left the fleet, has no labels.
+			mc := &clusterv1beta1.MemberCluster{}
+			if err := r.HubClient.Get(ctx, types.NamespacedName{Name: cluster}, mc); client.IgnoreNotFound(err) != nil {
+				return "", err
+			}
+			clusterLabels = mc.Labels
+			if clusterLabels == nil {
+				clusterLabels = map[string]string{}
+			}
+		}
+		ok, err := allowed.Allows(cluster, clusterLabels)
+		if err != nil {
+			return fmt.Sprintf("an exporting cluster restricts the consumers with an invalid cluster selector: %v", err), nil
+		}
+		if !ok {
+			return fmt.Sprintf("member cluster %s is not allowed to import the Service by the exporting clusters", cluster), nil
+		}
+	}
+	return "", nil
+}
+
+// rejectServiceImport rejects the request to import a Service to a member cluster which is not allowed to import
+// it: an import fulfilled earlier is withdrawn, and the status of the InternalServiceImport is cleared but for a
+// NotAllowed condition telling why.
+func (r *Reconciler) rejectServiceImport(ctx context.Context,
+	svcImport *fleetnetv1alpha1.ServiceImport,
+	internalSvcImport *fleetnetv1alpha1.InternalServiceImport,
+	reason, message string) (ctrl.Result, error) {
+	if res, err := r.withdrawServiceImport(ctx, svcImport, internalSvcImport); err != nil {
+		return res, err
+	}
+
+	rejectedStatus := fleetnetv1alpha1.ServiceImportStatus{}
+	if cond := meta.FindStatusCondition(internalSvcImport.Status.Conditions, string(fleetnetv1alpha1.ServiceImportNotAllowed)); cond != nil {
+		rejectedStatus.Conditions = []metav1.Condition{*cond}
+	}
+	meta.SetStatusCondition(&rejectedStatus.Conditions, metav1.Condition{
+		Type:               string(fleetnetv1alpha1.ServiceImportNotAllowed),
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: internalSvcImport.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+	if reflect.DeepEqual(internalSvcImport.Status, rejectedStatus) {
+		// The state has stablized; skip the rejection.
+		return ctrl.Result{}, nil
+	}
+	internalSvcImport.Status = rejectedStatus
+	if err := r.HubClient.Status().Update(ctx, internalSvcImport); err != nil {
+		klog.ErrorS(err, "Failed to reject InternalServiceImport", "internalServiceImport", klog.KObj(internalSvcImport))
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
 // clearInternalServiceImportStatus clears the status (Service spec) from an InternalServiceImport; if the
 // InternalServiceImport has a cleanup finalizer added, it will be removed as well.
 func (r *Reconciler) clearInternalServiceImportStatus(ctx context.Context, internalSvcImport *fleetnetv1alpha1.InternalServiceImport) (ctrl.Result, error) {
@@ -341,6 +525,8 @@ func (r *Reconciler) fulfillInternalServiceImport(ctx context.Context,
 	svcImport *fleetnetv1alpha1.ServiceImport,
 	internalSvcImport *fleetnetv1alpha1.InternalServiceImport) error {
 	updatedInternalSvcImportStatus := svcImport.Status.DeepCopy()
+	// The consumer restrictions of the exporting clusters are only reported in the hub cluster.
+	updatedInternalSvcImportStatus.AllowedConsumers = nil
 	if reflect.DeepEqual(internalSvcImport.Status, updatedInternalSvcImportStatus) {
 		// The state has stablized; skip the fulfillment.
 		return nil
@@ -65,7 +65,7 @@ func fulfillInternalServiceImport(internalSvcImport *fleetnetv1alpha1.InternalSe
 				Port:        tcpPort,
 			},
 		},
-		Clusters: []fleetnetv1alpha1.ClusterStatus{
+		Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 			{
 				Cluster: clusterIDForMemberA,
 			},
@@ -111,7 +111,7 @@ func fulfillServiceImport(svcImport *fleetnetv1alpha1.ServiceImport) {
 				Port:        tcpPort,
 			},
 		},
-		Clusters: []fleetnetv1alpha1.ClusterStatus{
+		Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 			{
 				Cluster: clusterIDForMemberA,
 			},
@@ -931,7 +931,7 @@ var _ = Describe("internalserviceimport controller", Ordered, func() {
 						TargetPort: newTargetPort,
 					},
 				},
-				Clusters: []fleetnetv1alpha1.ClusterStatus{
+				Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 					{
 						Cluster: clusterIDForMemberC,
 					},
@@ -950,7 +950,7 @@ var _ = Describe("internalserviceimport controller", Ordered, func() {
 						TargetPort: newTargetPort,
 					},
 				},
-				Clusters: []fleetnetv1alpha1.ClusterStatus{
+				Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 					{
 						Cluster: clusterIDForMemberC,
 					},
@@ -14,11 +14,16 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	clusterv1beta1 "go.goms.io/fleet/apis/cluster/v1beta1"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 	"go.goms.io/fleet-networking/pkg/common/objectmeta"
@@ -94,7 +99,7 @@ func fulfilledServiceImport() *fleetnetv1alpha1.ServiceImport {
 					Port:        tcpPort,
 				},
 			},
-			Clusters: []fleetnetv1alpha1.ClusterStatus{
+			Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 				{
 					Cluster: clusterIDForMemberA,
 				},
@@ -115,6 +120,9 @@ func TestMain(m *testing.M) {
 	if err := fleetnetv1alpha1.AddToScheme(scheme.Scheme); err != nil {
 		log.Fatalf("failed to add custom APIs to the runtime scheme: %v", err)
 	}
+	if err := clusterv1beta1.AddToScheme(scheme.Scheme); err != nil {
+		log.Fatalf("failed to add cluster APIs to the runtime scheme: %v", err)
+	}
 
 	os.Exit(m.Run())
 }
@@ -687,3 +695,239 @@ func TestClearServiceInUseByInfoFromServiceImport(t *testing.T) {
 		})
 	}
 }
+
+// TestReconcile_AllowedConsumers tests the Reconciler.Reconcile method with a Service whose exporting clusters
+// restrict its consumers.
+func TestReconcile_AllowedConsumers(t *testing.T) {
+	memberClusterA := &clusterv1beta1.MemberCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   clusterIDForMemberA,
+			Labels: map[string]string{"env": "prod"},
+		},
+	}
+
+	testCases := []struct {
+		name             string
+		allowedConsumers []fleetnetv1alpha1.ConsumerSelector
+		wantAllowed      bool
+		wantReason       string
+	}{
+		{
+			name:        "no restrictions",
+			wantAllowed: true,
+		},
+		{
+			name: "allowed by name and by labels",
+			allowedConsumers: []fleetnetv1alpha1.ConsumerSelector{
+				{Clusters: []string{clusterIDForMemberA}},
+				{ClusterSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}}},
+			},
+			wantAllowed: true,
+		},
+		{
+			name: "not allowed by one of the restrictions",
+			allowedConsumers: []fleetnetv1alpha1.ConsumerSelector{
+				{Clusters: []string{clusterIDForMemberA}},
+				{Clusters: []string{clusterIDForMemberB}},
+			},
+			wantReason: "member cluster 0 is not allowed to import the Service by the exporting clusters",
+		},
+		{
+			name: "invalid cluster selector",
+			allowedConsumers: []fleetnetv1alpha1.ConsumerSelector{
+				{ClusterSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "env", Operator: "Unknown"}},
+				}},
+			},
+			wantReason: "an exporting cluster restricts the consumers with an invalid cluster selector: \"Unknown\" is not a valid label selector operator",
+		},
+	}
+
+	ctx := context.Background()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// The Service has been imported by member cluster A.
+			svcImport := fulfilledServiceImport()
+			svcImport.Status.AllowedConsumers = tc.allowedConsumers
+			internalSvcImport := &fleetnetv1alpha1.InternalServiceImport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:  hubNSForMemberA,
+					Name:       internalSvcImportName,
+					Finalizers: []string{internalSvcImportCleanupFinalizer},
+				},
+				Spec: fleetnetv1alpha1.InternalServiceImportSpec{
+					ServiceImportReference: fleetnetv1alpha1.ExportedObjectReference{
+						ClusterID: clusterIDForMemberA,
+						Namespace: memberUserNS,
+						Name:      svcName,
+					},
+				},
+			}
+			fakeHubClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(svcImport, internalSvcImport, memberClusterA).
+				WithStatusSubresource(internalSvcImport).
+				Build()
+			reconciler := Reconciler{
+				HubClient: fakeHubClient,
+			}
+
+			if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: internalSvcImportAKey}); err != nil {
+				t.Fatalf("Reconcile(%v) = %v, want no error", internalSvcImportAKey, err)
+			}
+
+			gotSvcImport := &fleetnetv1alpha1.ServiceImport{}
+			if err := fakeHubClient.Get(ctx, svcImportKey, gotSvcImport); err != nil {
+				t.Fatalf("serviceImport Get(%+v), got %v, want no error", svcImportKey, err)
+			}
+			_, inUse := gotSvcImport.Annotations[objectmeta.ServiceImportAnnotationServiceInUseBy]
+			if inUse != tc.wantAllowed {
+				t.Errorf("serviceInUseBy annotation present = %t, want %t", inUse, tc.wantAllowed)
+			}
+
+			gotInternalSvcImport := &fleetnetv1alpha1.InternalServiceImport{}
+			if err := fakeHubClient.Get(ctx, internalSvcImportAKey, gotInternalSvcImport); err != nil {
+				t.Fatalf("internalServiceImport Get(%+v), got %v, want no error", internalSvcImportAKey, err)
+			}
+			if gotInternalSvcImport.Status.AllowedConsumers != nil {
+				t.Errorf("internalServiceImport allowedConsumers = %+v, want nil", gotInternalSvcImport.Status.AllowedConsumers)
+			}
+			cond := meta.FindStatusCondition(gotInternalSvcImport.Status.Conditions, string(fleetnetv1alpha1.ServiceImportNotAllowed))
+			if tc.wantAllowed {
+				if cond != nil || len(gotInternalSvcImport.Status.Clusters) == 0 {
+					t.Errorf("internalServiceImport status = %+v, want fulfilled", gotInternalSvcImport.Status)
+				}
+				return
+			}
+			if cond == nil || cond.Status != metav1.ConditionTrue || cond.Message != tc.wantReason {
+				t.Errorf("internalServiceImport not allowed condition = %+v, want true with message %q", cond, tc.wantReason)
+			}
+			if len(gotInternalSvcImport.Status.Clusters) != 0 || len(gotInternalSvcImport.Finalizers) != 0 {
+				t.Errorf("internalServiceImport = %+v, want no clusters and no finalizers", gotInternalSvcImport)
+			}
+		})
+	}
+}
+
+// TestReconcile_TenantIsolation tests the Reconciler.Reconcile method with a Service owned by a tenant.
+func TestReconcile_TenantIsolation(t *testing.T) {
+	testCases := []struct {
+		name          string
+		owner         *string
+		clusterTenant string
+		wantAllowed   bool
+		wantReason    string
+	}{
+		{
+			name:          "not owned",
+			clusterTenant: "payments",
+			wantAllowed:   true,
+		},
+		{
+			name:          "owned by the tenant of the cluster",
+			owner:         ptr.To("payments"),
+			clusterTenant: "payments",
+			wantAllowed:   true,
+		},
+		{
+			name:          "owned by another tenant",
+			owner:         ptr.To("search"),
+			clusterTenant: "payments",
+			wantReason:    "service is owned by tenant \"search\" of the fleet and member cluster 0 of tenant \"payments\" is not allowed to import it",
+		},
+		{
+			name:       "owned by another tenant than the default one",
+			owner:      ptr.To("search"),
+			wantReason: "service is owned by tenant \"search\" of the fleet and member cluster 0 of tenant \"\" is not allowed to import it",
+		},
+	}
+
+	ctx := context.Background()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// The Service has been imported by member cluster A.
+			svcImport := fulfilledServiceImport()
+			if tc.owner != nil {
+				svcImport.Labels = map[string]string{objectmeta.ServiceImportLabelTenant: *tc.owner}
+			}
+			internalSvcImport := &fleetnetv1alpha1.InternalServiceImport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:  hubNSForMemberA,
+					Name:       internalSvcImportName,
+					Finalizers: []string{internalSvcImportCleanupFinalizer},
+				},
+				Spec: fleetnetv1alpha1.InternalServiceImportSpec{
+					ServiceImportReference: fleetnetv1alpha1.ExportedObjectReference{
+						ClusterID: clusterIDForMemberA,
+						Namespace: memberUserNS,
+						Name:      svcName,
+					},
+				},
+			}
+			clusterNS := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: hubNSForMemberA}}
+			if tc.clusterTenant != "" {
+				clusterNS.Labels = map[string]string{objectmeta.MemberNamespaceLabelTenant: tc.clusterTenant}
+			}
+			fakeHubClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(svcImport, internalSvcImport, clusterNS).
+				WithStatusSubresource(internalSvcImport).
+				Build()
+			reconciler := Reconciler{
+				HubClient:             fakeHubClient,
+				EnableTenantIsolation: true,
+			}
+
+			if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: internalSvcImportAKey}); err != nil {
+				t.Fatalf("Reconcile(%v) = %v, want no error", internalSvcImportAKey, err)
+			}
+
+			gotInternalSvcImport := &fleetnetv1alpha1.InternalServiceImport{}
+			if err := fakeHubClient.Get(ctx, internalSvcImportAKey, gotInternalSvcImport); err != nil {
+				t.Fatalf("internalServiceImport Get(%+v), got %v, want no error", internalSvcImportAKey, err)
+			}
+			cond := meta.FindStatusCondition(gotInternalSvcImport.Status.Conditions, string(fleetnetv1alpha1.ServiceImportNotAllowed))
+			if tc.wantAllowed {
+				if cond != nil || len(gotInternalSvcImport.Status.Clusters) == 0 {
+					t.Errorf("internalServiceImport status = %+v, want fulfilled", gotInternalSvcImport.Status)
+				}
+				return
+			}
+			if cond == nil || cond.Status != metav1.ConditionTrue || cond.Reason != "OwnedByOtherTenant" || cond.Message != tc.wantReason {
+				t.Errorf("internalServiceImport not allowed condition = %+v, want true with message %q", cond, tc.wantReason)
+			}
+			if len(gotInternalSvcImport.Status.Clusters) != 0 || len(gotInternalSvcImport.Finalizers) != 0 {
+				t.Errorf("internalServiceImport = %+v, want no clusters and no finalizers", gotInternalSvcImport)
+			}
+		})
+	}
+}
+
+// TestShardKey tests the ShardKey function.
+func TestShardKey(t *testing.T) {
+	internalSvcImport := &fleetnetv1alpha1.InternalServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: hubNSForMemberA,
+			Name:      internalSvcImportName,
+		},
+		Spec: fleetnetv1alpha1.InternalServiceImportSpec{
+			ServiceImportReference: fleetnetv1alpha1.ExportedObjectReference{
+				ClusterID: clusterIDForMemberA,
+				Namespace: memberUserNS,
+				Name:      svcName,
+			},
+		},
+	}
+	fakeHubClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(internalSvcImport).Build()
+	shardKey := ShardKey(fakeHubClient)
+
+	// The imports are sharded by their serviceImport, whichever member cluster imports it.
+	key, ok := shardKey(reconcile.Request{NamespacedName: internalSvcImportAKey})
+	if want := svcImportKey.String(); key != want || !ok {
+		t.Errorf("ShardKey() = (%q, %t), want (%q, true)", key, ok, want)
+	}
+	// The key of an import which is gone is unknown.
+	if key, ok := shardKey(reconcile.Request{NamespacedName: internalSvcImportBKey}); ok {
+		t.Errorf("ShardKey() = (%q, true), want false", key)
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
@@ -26,6 +27,7 @@ import (
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 	"go.goms.io/fleet-networking/pkg/common/hubconfig"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
 )
 
 const (
@@ -38,6 +40,9 @@ type Reconciler struct {
 	Recorder record.EventRecorder
 	// the wait time in minutes before we need to force delete a member cluster.
 	ForceDeleteWaitTime time.Duration
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
 }
 
 // Reconcile watches the deletion of the member cluster and removes finalizers on fleet networking resources in the
@@ -128,5 +133,6 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&clusterv1beta1.MemberCluster{}).
 		WithEventFilter(customPredicate).
-		Complete(r)
+		WithOptions(r.ControllerOptions).
+		Complete(metrics.InstrumentReconciler("membercluster", r))
 }
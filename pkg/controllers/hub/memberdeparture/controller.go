@@ -0,0 +1,167 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package memberdeparture features the member departure controller, which deletes the InternalServiceExports and
+// EndpointSliceExports of a member cluster as soon as the cluster departs from the fleet, i.e. its MemberCluster or
+// its reserved namespace in the hub cluster is being deleted.
+//
+// A member cluster which leaves gracefully withdraws its exports itself; the exports of a cluster which is removed
+// while its networking agent is down would otherwise keep being served until the namespace is finally deleted. The
+// finalizers of the deleted exports let the other hub controllers re-resolve the affected ServiceImports and
+// withdraw the EndpointSliceImports distributed from the cluster.
+package memberdeparture
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	clusterv1beta1 "go.goms.io/fleet/apis/cluster/v1beta1"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/hubconfig"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+)
+
+// Reconciler deletes the exports of the departed member clusters; the requests are keyed by the reserved namespace
+// and the name of a member cluster, so that they belong to the same shard as the exports of the cluster.
+type Reconciler struct {
+	client.Client
+	// WatchMemberClusters is true if the MemberCluster API is installed, so that a member cluster departs as soon as
+	// its MemberCluster is being deleted; otherwise it departs as its reserved namespace is being deleted.
+	WatchMemberClusters bool
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
+}
+
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+//+kubebuilder:rbac:groups=cluster.kubernetes-fleet.io,resources=memberclusters,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalserviceexports,verbs=get;list;watch;delete
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=endpointsliceexports,verbs=get;list;watch;delete
+
+// Reconcile deletes the exports in the reserved namespace of the member cluster if the cluster has departed.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	memberClusterRef := klog.KRef("", req.Name)
+	startTime := time.Now()
+	klog.V(2).InfoS("Reconciliation starts", "memberCluster", memberClusterRef)
+	defer func() {
+		latency := time.Since(startTime).Milliseconds()
+		klog.V(2).InfoS("Reconciliation ends", "memberCluster", memberClusterRef, "latency", latency)
+	}()
+
+	departed, err := r.hasDeparted(ctx, req)
+	if err != nil {
+		klog.ErrorS(err, "Failed to check whether the member cluster has departed", "memberCluster", memberClusterRef)
+		return ctrl.Result{}, err
+	}
+	if !departed {
+		klog.V(4).InfoS("The member cluster has not departed", "memberCluster", memberClusterRef)
+		return ctrl.Result{}, nil
+	}
+
+	internalServiceExportList := &fleetnetv1alpha1.InternalServiceExportList{}
+	if err := r.Client.List(ctx, internalServiceExportList, client.InNamespace(req.Namespace)); err != nil {
+		klog.ErrorS(err, "Failed to list internalServiceExports", "memberCluster", memberClusterRef)
+		return ctrl.Result{}, err
+	}
+	endpointSliceExportList := &fleetnetv1alpha1.EndpointSliceExportList{}
+	if err := r.Client.List(ctx, endpointSliceExportList, client.InNamespace(req.Namespace)); err != nil {
+		klog.ErrorS(err, "Failed to list endpointSliceExports", "memberCluster", memberClusterRef)
+		return ctrl.Result{}, err
+	}
+	exports := make([]client.Object, 0, len(internalServiceExportList.Items)+len(endpointSliceExportList.Items))
+	for i := range internalServiceExportList.Items {
+		exports = append(exports, &internalServiceExportList.Items[i])
+	}
+	for i := range endpointSliceExportList.Items {
+		exports = append(exports, &endpointSliceExportList.Items[i])
+	}
+
+	deleted := 0
+	for _, export := range exports {
+		if export.GetDeletionTimestamp() != nil {
+			continue
+		}
+		if err := r.Client.Delete(ctx, export); err != nil && !errors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to delete the export of the departed member cluster", "memberCluster", memberClusterRef, "export", klog.KObj(export))
+			return ctrl.Result{}, err
+		}
+		deleted++
+	}
+	klog.V(2).InfoS("Deleted the exports of the departed member cluster", "memberCluster", memberClusterRef, "objectCounter", deleted)
+	return ctrl.Result{}, nil
+}
+
+// hasDeparted returns true if the reserved namespace, or the MemberCluster if watched, of the member cluster is
+// being deleted; a member cluster whose namespace is already gone has no exports left.
+func (r *Reconciler) hasDeparted(ctx context.Context, req ctrl.Request) (bool, error) {
+	namespace := &corev1.Namespace{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: req.Namespace}, namespace); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	if namespace.DeletionTimestamp != nil {
+		return true, nil
+	}
+	if !r.WatchMemberClusters {
+		return false, nil
+	}
+	memberCluster := &clusterv1beta1.MemberCluster{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: req.Name}, memberCluster); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	return memberCluster.DeletionTimestamp != nil, nil
+}
+
+// requestForMemberCluster returns the request of the member cluster of the given name.
+func requestForMemberCluster(name string) reconcile.Request {
+	return reconcile.Request{NamespacedName: types.NamespacedName{
+		Namespace: fmt.Sprintf(hubconfig.HubNamespaceNameFormat, name),
+		Name:      name,
+	}}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	deleting := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetDeletionTimestamp() != nil
+	})
+	namespacePrefix := strings.TrimSuffix(hubconfig.HubNamespaceNameFormat, "%s")
+	b := ctrl.NewControllerManagedBy(mgr).
+		Named("memberdeparture").
+		Watches(&corev1.Namespace{},
+			handler.EnqueueRequestsFromMapFunc(func(_ context.Context, obj client.Object) []reconcile.Request {
+				name, ok := strings.CutPrefix(obj.GetName(), namespacePrefix)
+				if !ok {
+					return nil
+				}
+				return []reconcile.Request{requestForMemberCluster(name)}
+			}),
+			builder.WithPredicates(deleting),
+		)
+	if r.WatchMemberClusters {
+		b = b.Watches(&clusterv1beta1.MemberCluster{},
+			handler.EnqueueRequestsFromMapFunc(func(_ context.Context, obj client.Object) []reconcile.Request {
+				return []reconcile.Request{requestForMemberCluster(obj.GetName())}
+			}),
+			builder.WithPredicates(deleting),
+		)
+	}
+	return b.WithOptions(r.ControllerOptions).
+		Complete(metrics.InstrumentReconciler("memberdeparture", r))
+}
@@ -0,0 +1,127 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package memberdeparture
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1beta1 "go.goms.io/fleet/apis/cluster/v1beta1"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+const (
+	testMemberClusterName = "member-1"
+	testHubNamespace      = "fleet-member-member-1"
+	testFinalizer         = "test-finalizer"
+)
+
+func testScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	if err := clusterv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	return scheme
+}
+
+func objectMeta(namespace, name string, deleting bool) metav1.ObjectMeta {
+	meta := metav1.ObjectMeta{Namespace: namespace, Name: name}
+	if deleting {
+		now := metav1.Now()
+		meta.DeletionTimestamp = &now
+		meta.Finalizers = []string{testFinalizer}
+	}
+	return meta
+}
+
+// TestReconcile tests that the exports of a member cluster are deleted only once the cluster has departed.
+func TestReconcile(t *testing.T) {
+	testCases := []struct {
+		name                string
+		namespaceDeleting   bool
+		memberClusterExists bool
+		memberClusterLeaves bool
+		watchMemberClusters bool
+		wantDeleted         bool
+	}{
+		{
+			name:                "member cluster is in the fleet",
+			memberClusterExists: true,
+			watchMemberClusters: true,
+		},
+		{
+			name:                "member cluster is being deleted",
+			memberClusterExists: true,
+			memberClusterLeaves: true,
+			watchMemberClusters: true,
+			wantDeleted:         true,
+		},
+		{
+			name:                "member cluster is being deleted but not watched",
+			memberClusterExists: true,
+			memberClusterLeaves: true,
+		},
+		{
+			name:              "reserved namespace is being deleted",
+			namespaceDeleting: true,
+			wantDeleted:       true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			objects := []client.Object{
+				&corev1.Namespace{ObjectMeta: objectMeta("", testHubNamespace, tc.namespaceDeleting)},
+				&fleetnetv1alpha1.InternalServiceExport{ObjectMeta: objectMeta(testHubNamespace, "work-app", false)},
+				&fleetnetv1alpha1.EndpointSliceExport{ObjectMeta: objectMeta(testHubNamespace, "app-slice", false)},
+				&fleetnetv1alpha1.InternalServiceExport{ObjectMeta: objectMeta("fleet-member-member-2", "work-app", false)},
+			}
+			if tc.memberClusterExists {
+				objects = append(objects, &clusterv1beta1.MemberCluster{ObjectMeta: objectMeta("", testMemberClusterName, tc.memberClusterLeaves)})
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(objects...).Build()
+			r := &Reconciler{Client: fakeClient, WatchMemberClusters: tc.watchMemberClusters}
+
+			if _, err := r.Reconcile(ctx, requestForMemberCluster(testMemberClusterName)); err != nil {
+				t.Fatalf("Reconcile() got error %v, want no error", err)
+			}
+
+			for _, export := range []struct {
+				key types.NamespacedName
+				obj client.Object
+			}{
+				{key: types.NamespacedName{Namespace: testHubNamespace, Name: "work-app"}, obj: &fleetnetv1alpha1.InternalServiceExport{}},
+				{key: types.NamespacedName{Namespace: testHubNamespace, Name: "app-slice"}, obj: &fleetnetv1alpha1.EndpointSliceExport{}},
+			} {
+				err := fakeClient.Get(ctx, export.key, export.obj)
+				if gotDeleted := errors.IsNotFound(err); gotDeleted != tc.wantDeleted {
+					t.Errorf("Get(%v) = %v, want deleted %t", export.key, err, tc.wantDeleted)
+				}
+			}
+			// The exports of the other member clusters are left alone.
+			otherKey := types.NamespacedName{Namespace: "fleet-member-member-2", Name: "work-app"}
+			if err := fakeClient.Get(ctx, otherKey, &fleetnetv1alpha1.InternalServiceExport{}); err != nil {
+				t.Errorf("Get(%v) = %v, want no error", otherKey, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package membernetworkstatus features the InternalMemberNetworkStatus controller, which evaluates the heartbeats
+// reported by the networking agents of the member clusters into their AgentHealthy conditions, and distributes the
+// tunnel peers of the member clusters running the tunnel agent to each other.
+package membernetworkstatus
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/hubconfig"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+)
+
+const (
+	conditionReasonHeartbeatReceived = "HeartbeatReceived"
+	conditionReasonHeartbeatTimeout  = "HeartbeatTimeout"
+	conditionReasonHeartbeatUnknown  = "HeartbeatUnknown"
+)
+
+// Reconciler reconciles the AgentHealthy condition of an InternalMemberNetworkStatus.
+type Reconciler struct {
+	client.Client
+	// HeartbeatTimeout is how long the networking agent of a member cluster may go without reporting its state
+	// before it is considered unhealthy.
+	HeartbeatTimeout time.Duration
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
+}
+
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalmembernetworkstatuses,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalmembernetworkstatuses/status,verbs=get;update;patch
+
+// Reconcile evaluates the last heartbeat of an InternalMemberNetworkStatus into its AgentHealthy condition, sets the
+// tunnel peers of the other member clusters, and requeues the InternalMemberNetworkStatus to be evaluated again when
+// the heartbeat would time out.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	networkStatusRef := klog.KRef(req.Namespace, req.Name)
+	startTime := time.Now()
+	klog.V(2).InfoS("Reconciliation starts", "internalMemberNetworkStatus", networkStatusRef)
+	defer func() {
+		latency := time.Since(startTime).Milliseconds()
+		klog.V(2).InfoS("Reconciliation ends", "internalMemberNetworkStatus", networkStatusRef, "latency", latency)
+	}()
+
+	networkStatus := &fleetnetv1alpha1.InternalMemberNetworkStatus{}
+	if err := r.Client.Get(ctx, req.NamespacedName, networkStatus); err != nil {
+		if errors.IsNotFound(err) {
+			klog.V(4).InfoS("Ignoring NotFound internalMemberNetworkStatus", "internalMemberNetworkStatus", networkStatusRef)
+			return ctrl.Result{}, nil
+		}
+		klog.ErrorS(err, "Failed to get internalMemberNetworkStatus", "internalMemberNetworkStatus", networkStatusRef)
+		return ctrl.Result{}, err
+	}
+	if networkStatus.DeletionTimestamp != nil {
+		return ctrl.Result{}, nil
+	}
+
+	peers, err := r.tunnelPeers(ctx, networkStatus)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	peersChanged := !equality.Semantic.DeepEqual(networkStatus.Status.TunnelPeers, peers)
+	networkStatus.Status.TunnelPeers = peers
+
+	condition, requeueAfter := r.healthyCondition(networkStatus, time.Now())
+	if meta.SetStatusCondition(&networkStatus.Status.Conditions, condition) || peersChanged {
+		klog.V(2).InfoS("Updating internalMemberNetworkStatus status", "internalMemberNetworkStatus", networkStatusRef, "condition", condition, "numberOfTunnelPeers", len(peers))
+		if err := r.Client.Status().Update(ctx, networkStatus); err != nil {
+			if errors.IsConflict(err) {
+				// The networking agent has just reported again, which triggers another reconciliation.
+				klog.V(4).InfoS("Ignoring the conflict updating internalMemberNetworkStatus status", "internalMemberNetworkStatus", networkStatusRef)
+				return ctrl.Result{}, nil
+			}
+			klog.ErrorS(err, "Failed to update internalMemberNetworkStatus status", "internalMemberNetworkStatus", networkStatusRef)
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// tunnelPeers returns the tunnel peers of the member clusters other than the one of an InternalMemberNetworkStatus,
+// sorted by cluster; it returns nil if the member cluster does not run the tunnel agent.
+func (r *Reconciler) tunnelPeers(ctx context.Context, networkStatus *fleetnetv1alpha1.InternalMemberNetworkStatus) ([]fleetnetv1alpha1.TunnelPeer, error) {
+	if networkStatus.Status.Tunnel == nil {
+		return nil, nil
+	}
+	networkStatusList := &fleetnetv1alpha1.InternalMemberNetworkStatusList{}
+	if err := r.Client.List(ctx, networkStatusList); err != nil {
+		klog.ErrorS(err, "Failed to list internalMemberNetworkStatuses")
+		return nil, err
+	}
+	// The ID of the member cluster of each peer is the one of its reserved namespace rather than the one it reports,
+	// so that a member cluster cannot pass itself off as another.
+	namespacePrefix := strings.TrimSuffix(hubconfig.HubNamespaceNameFormat, "%s")
+	var peers []fleetnetv1alpha1.TunnelPeer
+	for i := range networkStatusList.Items {
+		other := &networkStatusList.Items[i]
+		if other.Status.Tunnel == nil || other.DeletionTimestamp != nil || other.Namespace == networkStatus.Namespace {
+			continue
+		}
+		clusterID, ok := strings.CutPrefix(other.Namespace, namespacePrefix)
+		if !ok || clusterID == "" {
+			klog.V(2).InfoS("Skipping the tunnel peer outside of the member cluster namespaces", "internalMemberNetworkStatus", klog.KObj(other))
+			continue
+		}
+		peer := *other.Status.Tunnel
+		peer.ClusterID = clusterID
+		peers = append(peers, peer)
+	}
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].ClusterID < peers[j].ClusterID
+	})
+	return peers, nil
+}
+
+// healthyCondition returns the AgentHealthy condition of an InternalMemberNetworkStatus at the given time, and how
+// long until the condition is due to change, if ever.
+func (r *Reconciler) healthyCondition(networkStatus *fleetnetv1alpha1.InternalMemberNetworkStatus, now time.Time) (metav1.Condition, time.Duration) {
+	condition := metav1.Condition{
+		Type:               string(fleetnetv1alpha1.InternalMemberNetworkStatusConditionAgentHealthy),
+		ObservedGeneration: networkStatus.Generation,
+	}
+	heartbeatTime := networkStatus.Status.LastHeartbeatTime
+	if heartbeatTime == nil {
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = conditionReasonHeartbeatUnknown
+		condition.Message = "The networking agent has not reported its state yet"
+		return condition, 0
+	}
+	if age := now.Sub(heartbeatTime.Time); age >= r.HeartbeatTimeout {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = conditionReasonHeartbeatTimeout
+		condition.Message = fmt.Sprintf("The networking agent has not reported its state since %s", heartbeatTime.UTC().Format(time.RFC3339))
+		return condition, 0
+	}
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = conditionReasonHeartbeatReceived
+	condition.Message = fmt.Sprintf("The networking agent reported its state within %s", r.HeartbeatTimeout)
+	return condition, heartbeatTime.Add(r.HeartbeatTimeout).Sub(now)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// The tunnel peers of all the member clusters running the tunnel agent are updated when the tunnel peer of any
+	// member cluster changes.
+	tunnelChanged := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldStatus, ok := e.ObjectOld.(*fleetnetv1alpha1.InternalMemberNetworkStatus)
+			if !ok {
+				return false
+			}
+			newStatus, ok := e.ObjectNew.(*fleetnetv1alpha1.InternalMemberNetworkStatus)
+			if !ok {
+				return false
+			}
+			return !equality.Semantic.DeepEqual(oldStatus.Status.Tunnel, newStatus.Status.Tunnel)
+		},
+	}
+	enqueueTunnelPeers := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, _ client.Object) []reconcile.Request {
+		networkStatusList := &fleetnetv1alpha1.InternalMemberNetworkStatusList{}
+		if err := r.Client.List(ctx, networkStatusList); err != nil {
+			klog.ErrorS(err, "Failed to list internalMemberNetworkStatuses")
+			return []reconcile.Request{}
+		}
+		requests := []reconcile.Request{}
+		for i := range networkStatusList.Items {
+			if networkStatusList.Items[i].Status.Tunnel != nil {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&networkStatusList.Items[i])})
+			}
+		}
+		return requests
+	})
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&fleetnetv1alpha1.InternalMemberNetworkStatus{}).
+		Watches(&fleetnetv1alpha1.InternalMemberNetworkStatus{}, enqueueTunnelPeers, builder.WithPredicates(tunnelChanged)).
+		WithOptions(r.ControllerOptions).
+		Complete(metrics.InstrumentReconciler("membernetworkstatus", r))
+}
@@ -0,0 +1,209 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package membernetworkstatus
+
+import (
+	"context"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+const (
+	memberClusterID  = "bravelion"
+	hubNSForMember   = "fleet-member-bravelion"
+	heartbeatTimeout = 2 * time.Minute
+)
+
+var (
+	networkStatusKey = types.NamespacedName{Namespace: hubNSForMember, Name: memberClusterID}
+)
+
+func TestMain(m *testing.M) {
+	// Add custom APIs to the runtime scheme
+	if err := fleetnetv1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		log.Fatalf("failed to add custom APIs to the runtime scheme: %v", err)
+	}
+	os.Exit(m.Run())
+}
+
+func networkStatusForTest(heartbeatTime *metav1.Time) *fleetnetv1alpha1.InternalMemberNetworkStatus {
+	return &fleetnetv1alpha1.InternalMemberNetworkStatus{
+		ObjectMeta: metav1.ObjectMeta{Namespace: hubNSForMember, Name: memberClusterID},
+		Status: fleetnetv1alpha1.InternalMemberNetworkStatusStatus{
+			ClusterID:         memberClusterID,
+			LastHeartbeatTime: heartbeatTime,
+		},
+	}
+}
+
+// TestHealthyCondition tests the healthyCondition method.
+func TestHealthyCondition(t *testing.T) {
+	now := time.Now()
+	testCases := []struct {
+		name             string
+		heartbeatTime    *metav1.Time
+		wantStatus       metav1.ConditionStatus
+		wantReason       string
+		wantRequeueAfter time.Duration
+	}{
+		{
+			name:       "no heartbeat",
+			wantStatus: metav1.ConditionUnknown,
+			wantReason: conditionReasonHeartbeatUnknown,
+		},
+		{
+			name:             "recent heartbeat",
+			heartbeatTime:    &metav1.Time{Time: now.Add(-30 * time.Second)},
+			wantStatus:       metav1.ConditionTrue,
+			wantReason:       conditionReasonHeartbeatReceived,
+			wantRequeueAfter: 90 * time.Second,
+		},
+		{
+			name:          "heartbeat timed out",
+			heartbeatTime: &metav1.Time{Time: now.Add(-heartbeatTimeout)},
+			wantStatus:    metav1.ConditionFalse,
+			wantReason:    conditionReasonHeartbeatTimeout,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &Reconciler{HeartbeatTimeout: heartbeatTimeout}
+			got, gotRequeueAfter := r.healthyCondition(networkStatusForTest(tc.heartbeatTime), now)
+			if got.Status != tc.wantStatus || got.Reason != tc.wantReason {
+				t.Errorf("healthyCondition() = %v, %v, want %v, %v", got.Status, got.Reason, tc.wantStatus, tc.wantReason)
+			}
+			if gotRequeueAfter != tc.wantRequeueAfter {
+				t.Errorf("healthyCondition() requeueAfter = %v, want %v", gotRequeueAfter, tc.wantRequeueAfter)
+			}
+		})
+	}
+}
+
+// TestReconcile tests that the AgentHealthy condition follows the heartbeats.
+func TestReconcile(t *testing.T) {
+	heartbeatTime := metav1.NewTime(time.Now().Add(-3 * time.Minute))
+	fakeClient := fake.NewClientBuilder().
+		WithObjects(networkStatusForTest(&heartbeatTime)).
+		WithStatusSubresource(&fleetnetv1alpha1.InternalMemberNetworkStatus{}).
+		Build()
+	r := &Reconciler{Client: fakeClient, HeartbeatTimeout: heartbeatTimeout}
+	ctx := context.Background()
+
+	got, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: networkStatusKey})
+	if err != nil || got != (ctrl.Result{}) {
+		t.Fatalf("Reconcile() = %v, %v, want %v, nil", got, err, ctrl.Result{})
+	}
+	networkStatus := &fleetnetv1alpha1.InternalMemberNetworkStatus{}
+	if err := fakeClient.Get(ctx, networkStatusKey, networkStatus); err != nil {
+		t.Fatalf("InternalMemberNetworkStatus Get() = %v, want no error", err)
+	}
+	want := []metav1.Condition{
+		{
+			Type:   string(fleetnetv1alpha1.InternalMemberNetworkStatusConditionAgentHealthy),
+			Status: metav1.ConditionFalse,
+			Reason: conditionReasonHeartbeatTimeout,
+		},
+	}
+	options := cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime", "Message")
+	if diff := cmp.Diff(want, networkStatus.Status.Conditions, options); diff != "" {
+		t.Errorf("InternalMemberNetworkStatus conditions mismatch (-want, +got):\n%s", diff)
+	}
+
+	// The networking agent reports again.
+	networkStatus.Status.LastHeartbeatTime = &metav1.Time{Time: time.Now()}
+	if err := fakeClient.Status().Update(ctx, networkStatus); err != nil {
+		t.Fatalf("InternalMemberNetworkStatus status Update() = %v, want no error", err)
+	}
+	got, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: networkStatusKey})
+	if err != nil || got.RequeueAfter <= 0 || got.RequeueAfter > heartbeatTimeout {
+		t.Fatalf("Reconcile() = %v, %v, want a requeue within %v, nil", got, err, heartbeatTimeout)
+	}
+	if err := fakeClient.Get(ctx, networkStatusKey, networkStatus); err != nil {
+		t.Fatalf("InternalMemberNetworkStatus Get() = %v, want no error", err)
+	}
+	want[0].Status = metav1.ConditionTrue
+	want[0].Reason = conditionReasonHeartbeatReceived
+	if diff := cmp.Diff(want, networkStatus.Status.Conditions, options); diff != "" {
+		t.Errorf("InternalMemberNetworkStatus conditions mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+// TestReconcile_TunnelPeers tests that the tunnel peers of the other member clusters are distributed to the member
+// clusters running the tunnel agent only, under the IDs of their reserved namespaces.
+func TestReconcile_TunnelPeers(t *testing.T) {
+	tunnelPeer := func(clusterID, address string) *fleetnetv1alpha1.TunnelPeer {
+		return &fleetnetv1alpha1.TunnelPeer{ClusterID: clusterID, PublicKey: "key-" + clusterID, Address: address}
+	}
+	networkStatusWithTunnel := func(clusterID string, tunnel *fleetnetv1alpha1.TunnelPeer) *fleetnetv1alpha1.InternalMemberNetworkStatus {
+		return &fleetnetv1alpha1.InternalMemberNetworkStatus{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "fleet-member-" + clusterID, Name: clusterID},
+			Status: fleetnetv1alpha1.InternalMemberNetworkStatusStatus{
+				ClusterID: clusterID,
+				Tunnel:    tunnel,
+			},
+		}
+	}
+
+	testCases := []struct {
+		name   string
+		tunnel *fleetnetv1alpha1.TunnelPeer
+		want   []fleetnetv1alpha1.TunnelPeer
+	}{
+		{
+			name:   "member cluster running the tunnel agent",
+			tunnel: tunnelPeer(memberClusterID, "100.96.0.1"),
+			want: []fleetnetv1alpha1.TunnelPeer{
+				*tunnelPeer("highflyingcat", "100.96.0.3"),
+				*tunnelPeer("smartfish", "100.96.0.2"),
+				{ClusterID: "sneakyrat", PublicKey: "key-" + memberClusterID, Address: "100.96.0.4"},
+			},
+		},
+		{
+			name: "member cluster not running the tunnel agent",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().
+				WithObjects(
+					networkStatusWithTunnel(memberClusterID, tc.tunnel),
+					networkStatusWithTunnel("smartfish", tunnelPeer("smartfish", "100.96.0.2")),
+					networkStatusWithTunnel("highflyingcat", tunnelPeer("highflyingcat", "100.96.0.3")),
+					// A member cluster not running the tunnel agent.
+					networkStatusWithTunnel("quickfox", nil),
+					// A member cluster reporting the cluster ID of another; its peer takes the ID of its namespace.
+					networkStatusWithTunnel("sneakyrat", tunnelPeer(memberClusterID, "100.96.0.4")),
+				).
+				WithStatusSubresource(&fleetnetv1alpha1.InternalMemberNetworkStatus{}).
+				Build()
+			r := &Reconciler{Client: fakeClient, HeartbeatTimeout: heartbeatTimeout}
+			ctx := context.Background()
+
+			if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: networkStatusKey}); err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+			networkStatus := &fleetnetv1alpha1.InternalMemberNetworkStatus{}
+			if err := fakeClient.Get(ctx, networkStatusKey, networkStatus); err != nil {
+				t.Fatalf("InternalMemberNetworkStatus Get() = %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.want, networkStatus.Status.TunnelPeers); diff != "" {
+				t.Errorf("InternalMemberNetworkStatus tunnelPeers mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
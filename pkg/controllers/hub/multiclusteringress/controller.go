@@ -0,0 +1,674 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package multiclusteringress features the MultiClusterIngress controller to reconcile MultiClusterIngress CRs.
+package multiclusteringress
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"go.goms.io/fleet/pkg/utils/controller"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/azureerrors"
+	"go.goms.io/fleet-networking/pkg/common/azurefrontdoor"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/uniquename"
+)
+
+const (
+	// AzureResourceEndpointNameFormat is the name format of the Azure Front Door endpoint created by the fleet
+	// controller, which is fleet-{MultiClusterIngressUUID}.
+	// The endpoint name must be 1-46 characters long and contain only letters, numbers and hyphens.
+	AzureResourceEndpointNameFormat = "fleet-%s"
+
+	// routeNameFormat is the name format of the routes of the endpoint, which is rule-{ruleIndex}-path-{pathIndex}.
+	routeNameFormat = "rule-%d-path-%d"
+
+	// maxAzureResourceCustomDomainNameLength is the max length of the Azure Front Door custom domain name.
+	maxAzureResourceCustomDomainNameLength = 260
+
+	defaultPath              = "/*"
+	defaultHTTPPort          = 80
+	defaultHTTPSPort         = 443
+	defaultMinimumTLSVersion = fleetnetv1alpha1.MultiClusterIngressTLSVersion12
+
+	enabledState                = "Enabled"
+	disabledState               = "Disabled"
+	forwardingProtocolMatch     = "MatchRequest"
+	certificateTypeManaged      = "ManagedCertificate"
+	certificateTypeCustomer     = "CustomerCertificate"
+	supportedProtocolHTTP       = "Http"
+	supportedProtocolHTTPS      = "Https"
+	originGroupsResourceType    = "originGroups"
+	customDomainsResourceType   = "customDomains"
+	secretsResourceType         = "secrets"
+	waitingForOriginGroupFormat = "Waiting for the origin group of the FrontDoorBackend %q of the backend %q"
+)
+
+var (
+	invalidCustomDomainNameCharacters = regexp.MustCompile("[^a-zA-Z0-9-]")
+)
+
+// Reconciler reconciles a multiClusterIngress object.
+type Reconciler struct {
+	client.Client
+
+	FrontDoorClient azurefrontdoor.RoutingInterface
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions ctrlcontroller.Options
+}
+
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=multiclusteringresses,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=multiclusteringresses/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=multiclusteringresses/finalizers,verbs=get;update
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=frontdoorbackends,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile triggers a single reconcile round.
+func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	name := req.NamespacedName
+	ingressKRef := klog.KRef(name.Namespace, name.Name)
+
+	startTime := time.Now()
+	klog.V(2).InfoS("Reconciliation starts", "multiClusterIngress", ingressKRef)
+	defer func() {
+		latency := time.Since(startTime).Milliseconds()
+		klog.V(2).InfoS("Reconciliation ends", "multiClusterIngress", ingressKRef, "latency", latency)
+	}()
+
+	ingress := &fleetnetv1alpha1.MultiClusterIngress{}
+	if err := r.Client.Get(ctx, name, ingress); err != nil {
+		if apierrors.IsNotFound(err) {
+			klog.V(4).InfoS("Ignoring NotFound multiClusterIngress", "multiClusterIngress", ingressKRef)
+			return ctrl.Result{}, nil
+		}
+		klog.ErrorS(err, "Failed to get multiClusterIngress", "multiClusterIngress", ingressKRef)
+		return ctrl.Result{}, controller.NewAPIServerError(true, err)
+	}
+
+	if !ingress.ObjectMeta.DeletionTimestamp.IsZero() {
+		return r.handleDelete(ctx, ingress)
+	}
+
+	// register finalizer
+	if !controllerutil.ContainsFinalizer(ingress, objectmeta.MultiClusterIngressFinalizer) {
+		controllerutil.AddFinalizer(ingress, objectmeta.MultiClusterIngressFinalizer)
+		if err := r.Update(ctx, ingress); err != nil {
+			klog.ErrorS(err, "Failed to add finalizer to multiClusterIngress", "multiClusterIngress", ingressKRef)
+			return ctrl.Result{}, controller.NewUpdateIgnoreConflictError(err)
+		}
+	}
+	return r.handleUpdate(ctx, ingress)
+}
+
+func (r *Reconciler) handleDelete(ctx context.Context, ingress *fleetnetv1alpha1.MultiClusterIngress) (ctrl.Result, error) {
+	ingressKObj := klog.KObj(ingress)
+	// The ingress is being deleted
+	if !controllerutil.ContainsFinalizer(ingress, objectmeta.MultiClusterIngressFinalizer) {
+		klog.V(4).InfoS("MultiClusterIngress is being deleted", "multiClusterIngress", ingressKObj)
+		return ctrl.Result{}, nil
+	}
+
+	profile := ingress.Spec.Profile
+	// The routes are deleted along with the endpoint, which releases the custom domains.
+	endpointName := generateAzureEndpointName(ingress)
+	if err := r.FrontDoorClient.DeleteEndpoint(ctx, profile.ResourceGroup, profile.Name, endpointName); err != nil {
+		if !azureerrors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to delete Azure Front Door endpoint", "multiClusterIngress", ingressKObj, "endpoint", endpointName)
+			return ctrl.Result{}, err
+		}
+		klog.V(2).InfoS("Ignoring NotFound Azure Front Door endpoint", "multiClusterIngress", ingressKObj, "endpoint", endpointName)
+	}
+
+	domainNames := make(map[string]bool, len(ingress.Status.CustomDomains))
+	for _, domain := range ingress.Status.CustomDomains {
+		domainNames[domain.Name] = true
+	}
+	for _, host := range ruleHosts(ingress) {
+		domainNames[generateAzureCustomDomainName(ingress, host)] = true
+	}
+	for domainName := range domainNames {
+		if err := r.deleteCustomDomain(ctx, ingress, domainName); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// The FrontDoorBackends are garbage collected as they are owned by the ingress.
+	controllerutil.RemoveFinalizer(ingress, objectmeta.MultiClusterIngressFinalizer)
+	if err := r.Client.Update(ctx, ingress); err != nil {
+		klog.ErrorS(err, "Failed to remove multiClusterIngress finalizer", "multiClusterIngress", ingressKObj)
+		return ctrl.Result{}, controller.NewUpdateIgnoreConflictError(err)
+	}
+	klog.V(2).InfoS("Removed multiClusterIngress finalizer", "multiClusterIngress", ingressKObj)
+	return ctrl.Result{}, nil
+}
+
+func (r *Reconciler) handleUpdate(ctx context.Context, ingress *fleetnetv1alpha1.MultiClusterIngress) (ctrl.Result, error) {
+	ingressKObj := klog.KObj(ingress)
+	originGroups, err := r.ensureFrontDoorBackends(ctx, ingress)
+	if err != nil || originGroups == nil {
+		// We don't need to requeue when the origin groups are not ready yet (err == nil and originGroups == nil) as
+		// when the FrontDoorBackends are updated, the controller will be re-triggered again.
+		// The controller will retry when err is not nil.
+		return ctrl.Result{}, err
+	}
+	klog.V(2).InfoS("Found the origin groups of the backends", "multiClusterIngress", ingressKObj, "numberOfOriginGroups", len(originGroups))
+
+	if err := r.programFrontDoor(ctx, ingress, originGroups); err != nil {
+		profile := ingress.Spec.Profile
+		if azureerrors.IsClientError(err) && !azureerrors.IsThrottled(err) {
+			// It may happen when the profile does not exist or the settings are rejected by the Azure Front Door.
+			// Retry won't help and the ingress or the profile has to be updated to fix it.
+			setFalseCondition(ingress, fmt.Sprintf("Invalid configuration of the Azure Front Door profile %q under %q: %v", profile.Name, profile.ResourceGroup, err))
+			return ctrl.Result{}, r.updateMultiClusterIngressStatus(ctx, ingress)
+		}
+		setUnknownCondition(ingress, fmt.Sprintf("Failed to configure the Azure Front Door profile %q: %v", profile.Name, err))
+		if statusErr := r.updateMultiClusterIngressStatus(ctx, ingress); statusErr != nil {
+			return ctrl.Result{}, statusErr
+		}
+		return ctrl.Result{}, err
+	}
+
+	setTrueCondition(ingress)
+	klog.V(2).InfoS("Configured the Azure Front Door endpoint and updating the condition", "multiClusterIngress", ingressKObj, "status", ingress.Status)
+	return ctrl.Result{}, r.updateMultiClusterIngressStatus(ctx, ingress)
+}
+
+// backendKey identifies a backend of the rules, i.e. a serviceImport exposed with the ports.
+type backendKey struct {
+	Name      string
+	HTTPPort  int32
+	HTTPSPort int32
+}
+
+func toBackendKey(backend fleetnetv1alpha1.MultiClusterIngressBackend) backendKey {
+	return backendKey{
+		Name:      backend.Name,
+		HTTPPort:  ptr.Deref(backend.HTTPPort, defaultHTTPPort),
+		HTTPSPort: ptr.Deref(backend.HTTPSPort, defaultHTTPSPort),
+	}
+}
+
+// ensureFrontDoorBackends creates or updates the FrontDoorBackends of the backends of the rules, deletes the stale
+// ones, and returns the names of their origin groups keyed by the backend; it returns nil when any of the origin
+// groups is not ready yet.
+func (r *Reconciler) ensureFrontDoorBackends(ctx context.Context, ingress *fleetnetv1alpha1.MultiClusterIngress) (map[backendKey]string, error) {
+	ingressKObj := klog.KObj(ingress)
+	desired := make(map[string]*fleetnetv1alpha1.FrontDoorBackend)
+	keys := make(map[string]backendKey)
+	for _, rule := range ingress.Spec.Rules {
+		for _, path := range rule.Paths {
+			key := toBackendKey(path.Backend)
+			backend := generateFrontDoorBackend(ingress, key)
+			desired[backend.Name] = backend
+			keys[backend.Name] = key
+		}
+	}
+
+	backendList := &fleetnetv1alpha1.FrontDoorBackendList{}
+	if err := r.Client.List(ctx, backendList, client.InNamespace(ingress.Namespace), client.MatchingLabels{objectmeta.FrontDoorBackendLabelMultiClusterIngress: ingress.Name}); err != nil {
+		klog.ErrorS(err, "Failed to list frontDoorBackends of the multiClusterIngress", "multiClusterIngress", ingressKObj)
+		return nil, controller.NewAPIServerError(true, err)
+	}
+	current := make(map[string]*fleetnetv1alpha1.FrontDoorBackend, len(backendList.Items))
+	for i := range backendList.Items {
+		backend := &backendList.Items[i]
+		if !metav1.IsControlledBy(backend, ingress) {
+			continue
+		}
+		if _, ok := desired[backend.Name]; ok {
+			current[backend.Name] = backend
+			continue
+		}
+		if err := r.Client.Delete(ctx, backend); err != nil && !apierrors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to delete stale frontDoorBackend", "multiClusterIngress", ingressKObj, "frontDoorBackend", klog.KObj(backend))
+			return nil, controller.NewAPIServerError(false, err)
+		}
+		klog.V(2).InfoS("Deleted stale frontDoorBackend", "multiClusterIngress", ingressKObj, "frontDoorBackend", klog.KObj(backend))
+	}
+
+	originGroups := make(map[backendKey]string, len(desired))
+	var pending string
+	names := make([]string, 0, len(desired))
+	for name := range desired {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		want := desired[name]
+		backend, ok := current[name]
+		switch {
+		case !ok:
+			if err := controllerutil.SetControllerReference(ingress, want, r.Client.Scheme()); err != nil {
+				klog.ErrorS(err, "Failed to set the controller reference of frontDoorBackend", "multiClusterIngress", ingressKObj, "frontDoorBackend", klog.KObj(want))
+				return nil, controller.NewUnexpectedBehaviorError(err)
+			}
+			if err := r.Client.Create(ctx, want); err != nil {
+				klog.ErrorS(err, "Failed to create frontDoorBackend", "multiClusterIngress", ingressKObj, "frontDoorBackend", klog.KObj(want))
+				return nil, controller.NewCreateIgnoreAlreadyExistError(err)
+			}
+			klog.V(2).InfoS("Created frontDoorBackend", "multiClusterIngress", ingressKObj, "frontDoorBackend", klog.KObj(want))
+			backend = want
+		case !equality.Semantic.DeepEqual(backend.Spec, want.Spec):
+			backend.Spec = want.Spec
+			if err := r.Client.Update(ctx, backend); err != nil {
+				klog.ErrorS(err, "Failed to update frontDoorBackend", "multiClusterIngress", ingressKObj, "frontDoorBackend", klog.KObj(backend))
+				return nil, controller.NewUpdateIgnoreConflictError(err)
+			}
+			klog.V(2).InfoS("Updated frontDoorBackend", "multiClusterIngress", ingressKObj, "frontDoorBackend", klog.KObj(backend))
+		}
+		if backend.Status.OriginGroup == "" {
+			if pending == "" {
+				pending = name
+			}
+			continue
+		}
+		originGroups[keys[name]] = backend.Status.OriginGroup
+	}
+	if pending != "" {
+		klog.V(2).InfoS("Origin group of the frontDoorBackend is not ready", "multiClusterIngress", ingressKObj, "frontDoorBackend", pending)
+		setUnknownCondition(ingress, fmt.Sprintf(waitingForOriginGroupFormat, pending, keys[pending].Name))
+		return nil, r.updateMultiClusterIngressStatus(ctx, ingress)
+	}
+	return originGroups, nil
+}
+
+// programFrontDoor creates or updates the endpoint, the custom domains and the routes of the ingress, and deletes
+// the stale routes and custom domains.
+func (r *Reconciler) programFrontDoor(ctx context.Context, ingress *fleetnetv1alpha1.MultiClusterIngress, originGroups map[backendKey]string) error {
+	ingressKObj := klog.KObj(ingress)
+	profile := ingress.Spec.Profile
+	profileID := r.FrontDoorClient.ProfileID(profile.ResourceGroup, profile.Name)
+
+	endpointName := generateAzureEndpointName(ingress)
+	endpoint, err := r.FrontDoorClient.GetEndpoint(ctx, profile.ResourceGroup, profile.Name, endpointName)
+	if err != nil && !azureerrors.IsNotFound(err) {
+		klog.ErrorS(err, "Failed to get the Azure Front Door endpoint", "multiClusterIngress", ingressKObj, "endpoint", endpointName)
+		return fmt.Errorf("failed to get the endpoint %q: %w", endpointName, err)
+	}
+	if err != nil || endpoint.Properties == nil || !strings.EqualFold(ptr.Deref(endpoint.Properties.EnabledState, ""), enabledState) {
+		desired := azurefrontdoor.AFDEndpoint{
+			Location:   ptr.To(azurefrontdoor.EndpointLocation),
+			Properties: &azurefrontdoor.AFDEndpointProperties{EnabledState: ptr.To(enabledState)},
+		}
+		if endpoint, err = r.FrontDoorClient.CreateOrUpdateEndpoint(ctx, profile.ResourceGroup, profile.Name, endpointName, desired); err != nil {
+			klog.ErrorS(err, "Failed to create or update the Azure Front Door endpoint", "multiClusterIngress", ingressKObj, "endpoint", endpointName)
+			return fmt.Errorf("failed to create or update the endpoint %q: %w", endpointName, err)
+		}
+		klog.V(2).InfoS("Created or updated the Azure Front Door endpoint", "multiClusterIngress", ingressKObj, "endpoint", endpointName)
+	}
+	ingress.Status.Endpoint = endpointName
+	if endpoint.Properties != nil {
+		ingress.Status.HostName = ptr.Deref(endpoint.Properties.HostName, "")
+	}
+
+	hosts := ruleHosts(ingress)
+	domainStatuses := make([]fleetnetv1alpha1.MultiClusterIngressDomainStatus, 0, len(hosts))
+	for _, host := range hosts {
+		status, err := r.createOrUpdateCustomDomain(ctx, ingress, profileID, host)
+		if err != nil {
+			return err
+		}
+		domainStatuses = append(domainStatuses, status)
+	}
+	staleDomains := ingress.Status.CustomDomains
+	ingress.Status.CustomDomains = domainStatuses
+
+	if err := r.updateRoutes(ctx, ingress, profileID, endpointName, originGroups); err != nil {
+		return err
+	}
+
+	// The stale custom domains are deleted after the routes so that they are no longer referenced.
+	desiredDomains := make(map[string]bool, len(domainStatuses))
+	for _, status := range domainStatuses {
+		desiredDomains[status.Name] = true
+	}
+	for _, status := range staleDomains {
+		if desiredDomains[status.Name] {
+			continue
+		}
+		if err := r.deleteCustomDomain(ctx, ingress, status.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Reconciler) createOrUpdateCustomDomain(ctx context.Context, ingress *fleetnetv1alpha1.MultiClusterIngress, profileID, host string) (fleetnetv1alpha1.MultiClusterIngressDomainStatus, error) {
+	ingressKObj := klog.KObj(ingress)
+	profile := ingress.Spec.Profile
+	domainName := generateAzureCustomDomainName(ingress, host)
+	desired := generateAzureCustomDomain(ingress, profileID, host)
+
+	domain, err := r.FrontDoorClient.GetCustomDomain(ctx, profile.ResourceGroup, profile.Name, domainName)
+	if err != nil && !azureerrors.IsNotFound(err) {
+		klog.ErrorS(err, "Failed to get the Azure Front Door custom domain", "multiClusterIngress", ingressKObj, "customDomain", domainName)
+		return fleetnetv1alpha1.MultiClusterIngressDomainStatus{}, fmt.Errorf("failed to get the custom domain %q: %w", domainName, err)
+	}
+	if err != nil || !equalAzureCustomDomain(domain, desired) {
+		if domain, err = r.FrontDoorClient.CreateOrUpdateCustomDomain(ctx, profile.ResourceGroup, profile.Name, domainName, desired); err != nil {
+			klog.ErrorS(err, "Failed to create or update the Azure Front Door custom domain", "multiClusterIngress", ingressKObj, "customDomain", domainName)
+			return fleetnetv1alpha1.MultiClusterIngressDomainStatus{}, fmt.Errorf("failed to create or update the custom domain %q of %q: %w", domainName, host, err)
+		}
+		klog.V(2).InfoS("Created or updated the Azure Front Door custom domain", "multiClusterIngress", ingressKObj, "customDomain", domainName)
+	}
+
+	status := fleetnetv1alpha1.MultiClusterIngressDomainStatus{Host: host, Name: domainName}
+	if domain.Properties != nil {
+		status.ValidationState = ptr.Deref(domain.Properties.DomainValidationState, "")
+		if domain.Properties.ValidationProperties != nil {
+			status.ValidationToken = ptr.Deref(domain.Properties.ValidationProperties.ValidationToken, "")
+		}
+	}
+	return status, nil
+}
+
+func (r *Reconciler) deleteCustomDomain(ctx context.Context, ingress *fleetnetv1alpha1.MultiClusterIngress, domainName string) error {
+	ingressKObj := klog.KObj(ingress)
+	profile := ingress.Spec.Profile
+	if err := r.FrontDoorClient.DeleteCustomDomain(ctx, profile.ResourceGroup, profile.Name, domainName); err != nil {
+		if azureerrors.IsNotFound(err) {
+			klog.V(2).InfoS("Ignoring NotFound Azure Front Door custom domain", "multiClusterIngress", ingressKObj, "customDomain", domainName)
+			return nil
+		}
+		klog.ErrorS(err, "Failed to delete the Azure Front Door custom domain", "multiClusterIngress", ingressKObj, "customDomain", domainName)
+		return fmt.Errorf("failed to delete the custom domain %q: %w", domainName, err)
+	}
+	klog.V(2).InfoS("Deleted the Azure Front Door custom domain", "multiClusterIngress", ingressKObj, "customDomain", domainName)
+	return nil
+}
+
+// updateRoutes deletes the stale routes of the endpoint and creates or updates the desired ones.
+func (r *Reconciler) updateRoutes(ctx context.Context, ingress *fleetnetv1alpha1.MultiClusterIngress, profileID, endpointName string, originGroups map[backendKey]string) error {
+	ingressKObj := klog.KObj(ingress)
+	profile := ingress.Spec.Profile
+	desiredRoutes := make(map[string]azurefrontdoor.Route)
+	for i, rule := range ingress.Spec.Rules {
+		for j, path := range rule.Paths {
+			originGroupID := joinResourceID(profileID, originGroupsResourceType, originGroups[toBackendKey(path.Backend)])
+			desiredRoutes[fmt.Sprintf(routeNameFormat, i, j)] = generateAzureRoute(ingress, profileID, originGroupID, rule.Host, path.Path)
+		}
+	}
+
+	routes, err := r.FrontDoorClient.ListRoutes(ctx, profile.ResourceGroup, profile.Name, endpointName)
+	if err != nil {
+		klog.ErrorS(err, "Failed to list the Azure Front Door routes", "multiClusterIngress", ingressKObj, "endpoint", endpointName)
+		return fmt.Errorf("failed to list the routes of %q: %w", endpointName, err)
+	}
+	for _, route := range routes {
+		if route.Name == nil {
+			continue
+		}
+		routeName := strings.ToLower(*route.Name) // resource names are case-insensitive
+		desired, ok := desiredRoutes[routeName]
+		if !ok {
+			// The endpoint is owned by the ingress, so are all the routes.
+			if err := r.FrontDoorClient.DeleteRoute(ctx, profile.ResourceGroup, profile.Name, endpointName, *route.Name); err != nil && !azureerrors.IsNotFound(err) {
+				klog.ErrorS(err, "Failed to delete the Azure Front Door route", "multiClusterIngress", ingressKObj, "endpoint", endpointName, "route", routeName)
+				return fmt.Errorf("failed to delete the route %q of %q: %w", routeName, endpointName, err)
+			}
+			klog.V(2).InfoS("Deleted the Azure Front Door route", "multiClusterIngress", ingressKObj, "endpoint", endpointName, "route", routeName)
+			continue
+		}
+		if equalAzureRoute(*route, desired) {
+			klog.V(2).InfoS("Skipping updating the existing Azure Front Door route", "multiClusterIngress", ingressKObj, "endpoint", endpointName, "route", routeName)
+			delete(desiredRoutes, routeName)
+		}
+	}
+
+	routeNames := make([]string, 0, len(desiredRoutes))
+	for routeName := range desiredRoutes {
+		routeNames = append(routeNames, routeName)
+	}
+	sort.Strings(routeNames)
+	for _, routeName := range routeNames {
+		if _, err := r.FrontDoorClient.CreateOrUpdateRoute(ctx, profile.ResourceGroup, profile.Name, endpointName, routeName, desiredRoutes[routeName]); err != nil {
+			klog.ErrorS(err, "Failed to create or update the Azure Front Door route", "multiClusterIngress", ingressKObj, "endpoint", endpointName, "route", routeName)
+			return fmt.Errorf("failed to create or update the route %q of %q: %w", routeName, endpointName, err)
+		}
+		klog.V(2).InfoS("Created or updated the Azure Front Door route", "multiClusterIngress", ingressKObj, "endpoint", endpointName, "route", routeName)
+	}
+	return nil
+}
+
+func generateAzureEndpointName(ingress *fleetnetv1alpha1.MultiClusterIngress) string {
+	return fmt.Sprintf(AzureResourceEndpointNameFormat, ingress.UID)
+}
+
+// generateAzureCustomDomainName returns the name of the custom domain of the host, which is unique in the profile
+// as the host can only be added once; the dots of the host are replaced with hyphens.
+func generateAzureCustomDomainName(ingress *fleetnetv1alpha1.MultiClusterIngress, host string) string {
+	name := generateAzureEndpointName(ingress) + "-" + invalidCustomDomainNameCharacters.ReplaceAllString(host, "-")
+	if len(name) > maxAzureResourceCustomDomainNameLength {
+		name = name[:maxAzureResourceCustomDomainNameLength]
+	}
+	return strings.ToLower(name)
+}
+
+// generateFrontDoorBackend returns the FrontDoorBackend of the backend without the owner reference.
+func generateFrontDoorBackend(ingress *fleetnetv1alpha1.MultiClusterIngress, key backendKey) *fleetnetv1alpha1.FrontDoorBackend {
+	return &fleetnetv1alpha1.FrontDoorBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: ingress.Namespace,
+			Name:      uniquename.ClusterScopedDeterministicName(ingress.Name, fmt.Sprintf("%s-%d-%d", key.Name, key.HTTPPort, key.HTTPSPort)),
+			Labels:    map[string]string{objectmeta.FrontDoorBackendLabelMultiClusterIngress: ingress.Name},
+		},
+		Spec: fleetnetv1alpha1.FrontDoorBackendSpec{
+			Profile:     ingress.Spec.Profile,
+			Backend:     fleetnetv1alpha1.TrafficManagerBackendRef{Name: key.Name},
+			HealthProbe: ingress.Spec.HealthProbe.DeepCopy(),
+			HTTPPort:    ptr.To(key.HTTPPort),
+			HTTPSPort:   ptr.To(key.HTTPSPort),
+		},
+	}
+}
+
+// ruleHosts returns the sorted distinct hosts of the rules.
+func ruleHosts(ingress *fleetnetv1alpha1.MultiClusterIngress) []string {
+	seen := make(map[string]bool, len(ingress.Spec.Rules))
+	hosts := make([]string, 0, len(ingress.Spec.Rules))
+	for _, rule := range ingress.Spec.Rules {
+		host := strings.ToLower(rule.Host)
+		if host == "" || seen[host] {
+			continue
+		}
+		seen[host] = true
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// hostTLS returns the first TLS configuration of the host, or nil if the host is not configured.
+func hostTLS(ingress *fleetnetv1alpha1.MultiClusterIngress, host string) *fleetnetv1alpha1.MultiClusterIngressTLS {
+	for i := range ingress.Spec.TLS {
+		for _, h := range ingress.Spec.TLS[i].Hosts {
+			if strings.EqualFold(h, host) {
+				return &ingress.Spec.TLS[i]
+			}
+		}
+	}
+	return nil
+}
+
+func joinResourceID(profileID, resourceType, name string) string {
+	return profileID + "/" + resourceType + "/" + name
+}
+
+func generateAzureCustomDomain(ingress *fleetnetv1alpha1.MultiClusterIngress, profileID, host string) azurefrontdoor.AFDDomain {
+	tlsSettings := &azurefrontdoor.AFDDomainHTTPSParameters{
+		CertificateType:   ptr.To(certificateTypeManaged),
+		MinimumTLSVersion: ptr.To(string(defaultMinimumTLSVersion)),
+	}
+	if tls := hostTLS(ingress, host); tls != nil {
+		if tls.MinimumTLSVersion != nil {
+			tlsSettings.MinimumTLSVersion = ptr.To(string(*tls.MinimumTLSVersion))
+		}
+		if tls.SecretName != nil {
+			tlsSettings.CertificateType = ptr.To(certificateTypeCustomer)
+			tlsSettings.Secret = &azurefrontdoor.ResourceReference{ID: ptr.To(joinResourceID(profileID, secretsResourceType, *tls.SecretName))}
+		}
+	}
+	return azurefrontdoor.AFDDomain{
+		Properties: &azurefrontdoor.AFDDomainProperties{
+			HostName:    ptr.To(host),
+			TLSSettings: tlsSettings,
+		},
+	}
+}
+
+func generateAzureRoute(ingress *fleetnetv1alpha1.MultiClusterIngress, profileID, originGroupID, host, path string) azurefrontdoor.Route {
+	if path == "" {
+		path = defaultPath
+	}
+	route := azurefrontdoor.Route{
+		Properties: &azurefrontdoor.RouteProperties{
+			EnabledState:        ptr.To(enabledState),
+			ForwardingProtocol:  ptr.To(forwardingProtocolMatch),
+			HTTPSRedirect:       ptr.To(enabledState),
+			LinkToDefaultDomain: ptr.To(enabledState),
+			OriginGroup:         &azurefrontdoor.ResourceReference{ID: ptr.To(originGroupID)},
+			PatternsToMatch:     []*string{ptr.To(path)},
+			SupportedProtocols:  []*string{ptr.To(supportedProtocolHTTP), ptr.To(supportedProtocolHTTPS)},
+		},
+	}
+	if host != "" {
+		domainID := joinResourceID(profileID, customDomainsResourceType, generateAzureCustomDomainName(ingress, strings.ToLower(host)))
+		route.Properties.CustomDomains = []*azurefrontdoor.ResourceReference{{ID: ptr.To(domainID)}}
+		route.Properties.LinkToDefaultDomain = ptr.To(disabledState)
+	}
+	return route
+}
+
+// equalFoldPtr compares the values case-insensitively, as the Azure resource IDs and enum values are.
+func equalFoldPtr(current, desired *string) bool {
+	if current == nil || desired == nil {
+		return current == desired
+	}
+	return strings.EqualFold(*current, *desired)
+}
+
+func equalResourceReference(current, desired *azurefrontdoor.ResourceReference) bool {
+	if current == nil || desired == nil {
+		return current == desired
+	}
+	return equalFoldPtr(current.ID, desired.ID)
+}
+
+func equalFoldPtrs(current, desired []*string) bool {
+	if len(current) != len(desired) {
+		return false
+	}
+	for i := range current {
+		if !equalFoldPtr(current[i], desired[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalAzureCustomDomain(current, desired azurefrontdoor.AFDDomain) bool {
+	if current.Properties == nil || current.Properties.TLSSettings == nil {
+		return false
+	}
+	c, d := current.Properties.TLSSettings, desired.Properties.TLSSettings
+	return equalFoldPtr(current.Properties.HostName, desired.Properties.HostName) &&
+		equalFoldPtr(c.CertificateType, d.CertificateType) &&
+		equalFoldPtr(c.MinimumTLSVersion, d.MinimumTLSVersion) &&
+		equalResourceReference(c.Secret, d.Secret)
+}
+
+func equalAzureRoute(current, desired azurefrontdoor.Route) bool {
+	if current.Properties == nil {
+		return false
+	}
+	c, d := current.Properties, desired.Properties
+	if len(c.CustomDomains) != len(d.CustomDomains) {
+		return false
+	}
+	for i := range c.CustomDomains {
+		if !equalResourceReference(c.CustomDomains[i], d.CustomDomains[i]) {
+			return false
+		}
+	}
+	return equalFoldPtr(c.EnabledState, d.EnabledState) &&
+		equalFoldPtr(c.ForwardingProtocol, d.ForwardingProtocol) &&
+		equalFoldPtr(c.HTTPSRedirect, d.HTTPSRedirect) &&
+		equalFoldPtr(c.LinkToDefaultDomain, d.LinkToDefaultDomain) &&
+		equalResourceReference(c.OriginGroup, d.OriginGroup) &&
+		equalFoldPtrs(c.PatternsToMatch, d.PatternsToMatch) &&
+		equalFoldPtrs(c.SupportedProtocols, d.SupportedProtocols)
+}
+
+func setFalseCondition(ingress *fleetnetv1alpha1.MultiClusterIngress, message string) {
+	cond := metav1.Condition{
+		Type:               string(fleetnetv1alpha1.MultiClusterIngressConditionAccepted),
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: ingress.Generation,
+		Reason:             string(fleetnetv1alpha1.MultiClusterIngressReasonInvalid),
+		Message:            message,
+	}
+	meta.SetStatusCondition(&ingress.Status.Conditions, cond)
+}
+
+func setUnknownCondition(ingress *fleetnetv1alpha1.MultiClusterIngress, message string) {
+	cond := metav1.Condition{
+		Type:               string(fleetnetv1alpha1.MultiClusterIngressConditionAccepted),
+		Status:             metav1.ConditionUnknown,
+		ObservedGeneration: ingress.Generation,
+		Reason:             string(fleetnetv1alpha1.MultiClusterIngressReasonPending),
+		Message:            message,
+	}
+	meta.SetStatusCondition(&ingress.Status.Conditions, cond)
+}
+
+func setTrueCondition(ingress *fleetnetv1alpha1.MultiClusterIngress) {
+	cond := metav1.Condition{
+		Type:               string(fleetnetv1alpha1.MultiClusterIngressConditionAccepted),
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: ingress.Generation,
+		Reason:             string(fleetnetv1alpha1.MultiClusterIngressReasonAccepted),
+		Message:            fmt.Sprintf("The Azure Front Door endpoint %q has been configured with %v custom domain(s)", ingress.Status.Endpoint, len(ingress.Status.CustomDomains)),
+	}
+	meta.SetStatusCondition(&ingress.Status.Conditions, cond)
+}
+
+func (r *Reconciler) updateMultiClusterIngressStatus(ctx context.Context, ingress *fleetnetv1alpha1.MultiClusterIngress) error {
+	ingressKObj := klog.KObj(ingress)
+	if err := r.Client.Status().Update(ctx, ingress); err != nil {
+		klog.ErrorS(err, "Failed to update multiClusterIngress status", "multiClusterIngress", ingressKObj)
+		return controller.NewUpdateIgnoreConflictError(err)
+	}
+	klog.V(2).InfoS("Updated multiClusterIngress status", "multiClusterIngress", ingressKObj, "status", ingress.Status)
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&fleetnetv1alpha1.MultiClusterIngress{}).
+		Owns(&fleetnetv1alpha1.FrontDoorBackend{}).
+		WithOptions(r.ControllerOptions).
+		Complete(metrics.InstrumentReconciler("multiclusteringress", r))
+}
@@ -0,0 +1,436 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package multiclusteringress
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/azurefrontdoor"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+const (
+	testNamespace     = "work"
+	testIngressName   = "web"
+	testIngressUID    = "00000000-0000-0000-0000-000000000001"
+	testResourceGroup = "rg"
+	testProfileName   = "afd"
+	testProfileID     = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Cdn/profiles/afd"
+	testEndpoint      = "fleet-" + testIngressUID
+	testHostName      = "fleet-abc.z01.azurefd.net"
+	testHost          = "www.contoso.com"
+	testDomain        = testEndpoint + "-www-contoso-com"
+	testBackendName   = "web-app-80-443"
+	testOriginGroup   = "fleet-00000000-0000-0000-0000-000000000002"
+)
+
+// fakeFrontDoorClient is an in-memory implementation of azurefrontdoor.RoutingInterface, which only manages the
+// endpoint of the test ingress.
+type fakeFrontDoorClient struct {
+	endpoint      *azurefrontdoor.AFDEndpoint
+	routes        map[string]azurefrontdoor.Route
+	domains       map[string]azurefrontdoor.AFDDomain
+	endpointErr   error
+	routeWrites   int
+	domainWrites  int
+	deletedDomain []string
+}
+
+var _ azurefrontdoor.RoutingInterface = &fakeFrontDoorClient{}
+
+func notFoundError() error {
+	return &azcore.ResponseError{StatusCode: http.StatusNotFound}
+}
+
+func (f *fakeFrontDoorClient) ProfileID(_, _ string) string {
+	return testProfileID
+}
+
+func (f *fakeFrontDoorClient) GetEndpoint(_ context.Context, _, _, _ string) (azurefrontdoor.AFDEndpoint, error) {
+	if f.endpoint == nil {
+		return azurefrontdoor.AFDEndpoint{}, notFoundError()
+	}
+	return *f.endpoint, nil
+}
+
+func (f *fakeFrontDoorClient) CreateOrUpdateEndpoint(_ context.Context, _, _, name string, endpoint azurefrontdoor.AFDEndpoint) (azurefrontdoor.AFDEndpoint, error) {
+	if f.endpointErr != nil {
+		return azurefrontdoor.AFDEndpoint{}, f.endpointErr
+	}
+	endpoint.Name = ptr.To(name)
+	endpoint.Properties.HostName = ptr.To(testHostName)
+	f.endpoint = &endpoint
+	return endpoint, nil
+}
+
+func (f *fakeFrontDoorClient) DeleteEndpoint(_ context.Context, _, _, _ string) error {
+	if f.endpoint == nil {
+		return notFoundError()
+	}
+	f.endpoint = nil
+	f.routes = nil
+	return nil
+}
+
+func (f *fakeFrontDoorClient) ListRoutes(_ context.Context, _, _, _ string) ([]*azurefrontdoor.Route, error) {
+	if f.endpoint == nil {
+		return nil, notFoundError()
+	}
+	res := make([]*azurefrontdoor.Route, 0, len(f.routes))
+	for name := range f.routes {
+		route := f.routes[name]
+		route.Name = ptr.To(name)
+		res = append(res, &route)
+	}
+	return res, nil
+}
+
+func (f *fakeFrontDoorClient) CreateOrUpdateRoute(_ context.Context, _, _, _, name string, route azurefrontdoor.Route) (azurefrontdoor.Route, error) {
+	if f.routes == nil {
+		f.routes = map[string]azurefrontdoor.Route{}
+	}
+	f.routeWrites++
+	route.Name = ptr.To(name)
+	f.routes[name] = route
+	return route, nil
+}
+
+func (f *fakeFrontDoorClient) DeleteRoute(_ context.Context, _, _, _, name string) error {
+	if _, ok := f.routes[name]; !ok {
+		return notFoundError()
+	}
+	delete(f.routes, name)
+	return nil
+}
+
+func (f *fakeFrontDoorClient) GetCustomDomain(_ context.Context, _, _, name string) (azurefrontdoor.AFDDomain, error) {
+	domain, ok := f.domains[name]
+	if !ok {
+		return azurefrontdoor.AFDDomain{}, notFoundError()
+	}
+	return domain, nil
+}
+
+func (f *fakeFrontDoorClient) CreateOrUpdateCustomDomain(_ context.Context, _, _, name string, domain azurefrontdoor.AFDDomain) (azurefrontdoor.AFDDomain, error) {
+	if f.domains == nil {
+		f.domains = map[string]azurefrontdoor.AFDDomain{}
+	}
+	f.domainWrites++
+	domain.Name = ptr.To(name)
+	domain.Properties.DomainValidationState = ptr.To("Pending")
+	domain.Properties.ValidationProperties = &azurefrontdoor.DomainValidationProperties{ValidationToken: ptr.To("token")}
+	f.domains[name] = domain
+	return domain, nil
+}
+
+func (f *fakeFrontDoorClient) DeleteCustomDomain(_ context.Context, _, _, name string) error {
+	if _, ok := f.domains[name]; !ok {
+		return notFoundError()
+	}
+	delete(f.domains, name)
+	f.deletedDomain = append(f.deletedDomain, name)
+	return nil
+}
+
+func ingressForTest() *fleetnetv1alpha1.MultiClusterIngress {
+	return &fleetnetv1alpha1.MultiClusterIngress{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  testNamespace,
+			Name:       testIngressName,
+			UID:        testIngressUID,
+			Finalizers: []string{objectmeta.MultiClusterIngressFinalizer},
+		},
+		Spec: fleetnetv1alpha1.MultiClusterIngressSpec{
+			Profile: fleetnetv1alpha1.FrontDoorProfileRef{ResourceGroup: testResourceGroup, Name: testProfileName},
+			Rules: []fleetnetv1alpha1.MultiClusterIngressRule{
+				{
+					Host: testHost,
+					Paths: []fleetnetv1alpha1.MultiClusterIngressPath{
+						{Path: "/api/*", Backend: fleetnetv1alpha1.MultiClusterIngressBackend{Name: "app"}},
+						{Path: "/*", Backend: fleetnetv1alpha1.MultiClusterIngressBackend{Name: "app"}},
+					},
+				},
+				{
+					Paths: []fleetnetv1alpha1.MultiClusterIngressPath{
+						{Backend: fleetnetv1alpha1.MultiClusterIngressBackend{Name: "app"}},
+					},
+				},
+			},
+			TLS: []fleetnetv1alpha1.MultiClusterIngressTLS{
+				{Hosts: []string{testHost}, SecretName: ptr.To("contoso-cert")},
+			},
+		},
+	}
+}
+
+func frontDoorBackendForTest(originGroup string) *fleetnetv1alpha1.FrontDoorBackend {
+	return &fleetnetv1alpha1.FrontDoorBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testBackendName,
+			Labels:    map[string]string{objectmeta.FrontDoorBackendLabelMultiClusterIngress: testIngressName},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion:         fleetnetv1alpha1.GroupVersion.String(),
+					Kind:               fleetnetv1alpha1.MultiClusterIngressKind,
+					Name:               testIngressName,
+					UID:                testIngressUID,
+					Controller:         ptr.To(true),
+					BlockOwnerDeletion: ptr.To(true),
+				},
+			},
+		},
+		Spec: fleetnetv1alpha1.FrontDoorBackendSpec{
+			Profile:   fleetnetv1alpha1.FrontDoorProfileRef{ResourceGroup: testResourceGroup, Name: testProfileName},
+			Backend:   fleetnetv1alpha1.TrafficManagerBackendRef{Name: "app"},
+			HTTPPort:  ptr.To[int32](80),
+			HTTPSPort: ptr.To[int32](443),
+		},
+		Status: fleetnetv1alpha1.FrontDoorBackendStatus{OriginGroup: originGroup},
+	}
+}
+
+func wantRoute(pattern string, customDomain bool) azurefrontdoor.Route {
+	route := azurefrontdoor.Route{
+		Properties: &azurefrontdoor.RouteProperties{
+			EnabledState:        ptr.To("Enabled"),
+			ForwardingProtocol:  ptr.To("MatchRequest"),
+			HTTPSRedirect:       ptr.To("Enabled"),
+			LinkToDefaultDomain: ptr.To("Enabled"),
+			OriginGroup:         &azurefrontdoor.ResourceReference{ID: ptr.To(testProfileID + "/originGroups/" + testOriginGroup)},
+			PatternsToMatch:     []*string{ptr.To(pattern)},
+			SupportedProtocols:  []*string{ptr.To("Http"), ptr.To("Https")},
+		},
+	}
+	if customDomain {
+		route.Properties.CustomDomains = []*azurefrontdoor.ResourceReference{{ID: ptr.To(testProfileID + "/customDomains/" + testDomain)}}
+		route.Properties.LinkToDefaultDomain = ptr.To("Disabled")
+	}
+	return route
+}
+
+func newFakeClient(t *testing.T, objs ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	return fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objs...).
+		WithStatusSubresource(&fleetnetv1alpha1.MultiClusterIngress{}).
+		Build()
+}
+
+// TestReconcile tests the Reconciler.Reconcile method.
+func TestReconcile(t *testing.T) {
+	staleBackend := frontDoorBackendForTest("fleet-stale")
+	staleBackend.Name = "web-old-80-443"
+
+	testCases := []struct {
+		name          string
+		objs          []client.Object
+		frontDoor     *fakeFrontDoorClient
+		wantBackends  []string
+		wantRoutes    map[string]azurefrontdoor.Route
+		wantDomains   []string
+		wantDeleted   []string
+		wantStatus    fleetnetv1alpha1.MultiClusterIngressStatus
+		wantCondition metav1.Condition
+	}{
+		{
+			name:         "create frontDoorBackends and wait for their origin groups",
+			objs:         []client.Object{ingressForTest(), staleBackend},
+			frontDoor:    &fakeFrontDoorClient{},
+			wantBackends: []string{testBackendName},
+			wantCondition: metav1.Condition{
+				Type:   string(fleetnetv1alpha1.MultiClusterIngressConditionAccepted),
+				Status: metav1.ConditionUnknown,
+				Reason: string(fleetnetv1alpha1.MultiClusterIngressReasonPending),
+			},
+		},
+		{
+			name: "program endpoint, custom domains and routes",
+			objs: []client.Object{
+				func() client.Object {
+					ingress := ingressForTest()
+					ingress.Status.CustomDomains = []fleetnetv1alpha1.MultiClusterIngressDomainStatus{{Host: "old.contoso.com", Name: testEndpoint + "-old-contoso-com"}}
+					return ingress
+				}(),
+				frontDoorBackendForTest(testOriginGroup),
+			},
+			frontDoor: &fakeFrontDoorClient{
+				endpoint: &azurefrontdoor.AFDEndpoint{Properties: &azurefrontdoor.AFDEndpointProperties{EnabledState: ptr.To("Enabled"), HostName: ptr.To(testHostName)}},
+				routes: map[string]azurefrontdoor.Route{
+					"rule-0-path-0": wantRoute("/api/*", true),
+					"rule-3-path-0": wantRoute("/old/*", false),
+				},
+				domains: map[string]azurefrontdoor.AFDDomain{
+					testEndpoint + "-old-contoso-com": {},
+				},
+			},
+			wantBackends: []string{testBackendName},
+			wantRoutes: map[string]azurefrontdoor.Route{
+				"rule-0-path-0": wantRoute("/api/*", true),
+				"rule-0-path-1": wantRoute("/*", true),
+				"rule-1-path-0": wantRoute("/*", false),
+			},
+			wantDomains: []string{testDomain},
+			wantDeleted: []string{testEndpoint + "-old-contoso-com"},
+			wantStatus: fleetnetv1alpha1.MultiClusterIngressStatus{
+				Endpoint: testEndpoint,
+				HostName: testHostName,
+				CustomDomains: []fleetnetv1alpha1.MultiClusterIngressDomainStatus{
+					{Host: testHost, Name: testDomain, ValidationState: "Pending", ValidationToken: "token"},
+				},
+			},
+			wantCondition: metav1.Condition{
+				Type:   string(fleetnetv1alpha1.MultiClusterIngressConditionAccepted),
+				Status: metav1.ConditionTrue,
+				Reason: string(fleetnetv1alpha1.MultiClusterIngressReasonAccepted),
+			},
+		},
+		{
+			name: "invalid profile",
+			objs: []client.Object{ingressForTest(), frontDoorBackendForTest(testOriginGroup)},
+			frontDoor: &fakeFrontDoorClient{
+				endpointErr: &azcore.ResponseError{StatusCode: http.StatusBadRequest},
+			},
+			wantBackends: []string{testBackendName},
+			wantCondition: metav1.Condition{
+				Type:   string(fleetnetv1alpha1.MultiClusterIngressConditionAccepted),
+				Status: metav1.ConditionFalse,
+				Reason: string(fleetnetv1alpha1.MultiClusterIngressReasonInvalid),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			fakeClient := newFakeClient(t, tc.objs...)
+			r := &Reconciler{Client: fakeClient, FrontDoorClient: tc.frontDoor}
+			key := types.NamespacedName{Namespace: testNamespace, Name: testIngressName}
+			if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+
+			backendList := &fleetnetv1alpha1.FrontDoorBackendList{}
+			if err := fakeClient.List(ctx, backendList, client.InNamespace(testNamespace)); err != nil {
+				t.Fatalf("FrontDoorBackend List() = %v, want no error", err)
+			}
+			gotBackends := make([]string, 0, len(backendList.Items))
+			for _, backend := range backendList.Items {
+				gotBackends = append(gotBackends, backend.Name)
+				if !metav1.IsControlledBy(&backend, &metav1.ObjectMeta{UID: testIngressUID}) {
+					t.Errorf("FrontDoorBackend %q is not controlled by the ingress", backend.Name)
+				}
+			}
+			if diff := cmp.Diff(tc.wantBackends, gotBackends); diff != "" {
+				t.Errorf("frontDoorBackends mismatch (-want, +got):\n%s", diff)
+			}
+
+			routes := make(map[string]azurefrontdoor.Route, len(tc.frontDoor.routes))
+			for name, route := range tc.frontDoor.routes {
+				route.Name = nil
+				routes[name] = route
+			}
+			if diff := cmp.Diff(tc.wantRoutes, routes, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("routes mismatch (-want, +got):\n%s", diff)
+			}
+			gotDomains := make([]string, 0, len(tc.frontDoor.domains))
+			for name := range tc.frontDoor.domains {
+				gotDomains = append(gotDomains, name)
+			}
+			if diff := cmp.Diff(tc.wantDomains, gotDomains, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("custom domains mismatch (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantDeleted, tc.frontDoor.deletedDomain); diff != "" {
+				t.Errorf("deleted custom domains mismatch (-want, +got):\n%s", diff)
+			}
+
+			got := &fleetnetv1alpha1.MultiClusterIngress{}
+			if err := fakeClient.Get(ctx, key, got); err != nil {
+				t.Fatalf("MultiClusterIngress Get() = %v, want no error", err)
+			}
+			options := []cmp.Option{
+				cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime", "Message"),
+				cmpopts.EquateEmpty(),
+			}
+			wantStatus := tc.wantStatus
+			wantStatus.Conditions = []metav1.Condition{tc.wantCondition}
+			if diff := cmp.Diff(wantStatus, got.Status, options...); diff != "" {
+				t.Errorf("MultiClusterIngress status mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestReconcile_CustomerCertificate tests the TLS settings of the custom domains.
+func TestReconcile_CustomerCertificate(t *testing.T) {
+	ctx := context.Background()
+	frontDoor := &fakeFrontDoorClient{}
+	fakeClient := newFakeClient(t, ingressForTest(), frontDoorBackendForTest(testOriginGroup))
+	r := &Reconciler{Client: fakeClient, FrontDoorClient: frontDoor}
+	key := types.NamespacedName{Namespace: testNamespace, Name: testIngressName}
+	for i := 0; i < 2; i++ {
+		if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+			t.Fatalf("Reconcile() = %v, want no error", err)
+		}
+	}
+
+	want := &azurefrontdoor.AFDDomainHTTPSParameters{
+		CertificateType:   ptr.To("CustomerCertificate"),
+		MinimumTLSVersion: ptr.To("TLS12"),
+		Secret:            &azurefrontdoor.ResourceReference{ID: ptr.To(testProfileID + "/secrets/contoso-cert")},
+	}
+	if diff := cmp.Diff(want, frontDoor.domains[testDomain].Properties.TLSSettings); diff != "" {
+		t.Errorf("TLS settings mismatch (-want, +got):\n%s", diff)
+	}
+	// The second reconciliation should not update the unchanged custom domains and routes.
+	if frontDoor.domainWrites != 1 || frontDoor.routeWrites != 3 {
+		t.Errorf("domain and route writes = %d and %d, want 1 and 3", frontDoor.domainWrites, frontDoor.routeWrites)
+	}
+}
+
+// TestReconcile_Delete tests the Reconciler.Reconcile method when the ingress is being deleted.
+func TestReconcile_Delete(t *testing.T) {
+	ctx := context.Background()
+	ingress := ingressForTest()
+	ingress.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	frontDoor := &fakeFrontDoorClient{
+		endpoint: &azurefrontdoor.AFDEndpoint{},
+		routes:   map[string]azurefrontdoor.Route{"rule-0-path-0": wantRoute("/*", true)},
+		domains:  map[string]azurefrontdoor.AFDDomain{testDomain: {}},
+	}
+	fakeClient := newFakeClient(t, ingress)
+	r := &Reconciler{Client: fakeClient, FrontDoorClient: frontDoor}
+	key := types.NamespacedName{Namespace: testNamespace, Name: testIngressName}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile() = %v, want no error", err)
+	}
+
+	if frontDoor.endpoint != nil || len(frontDoor.domains) != 0 {
+		t.Errorf("endpoint = %v and custom domains = %v, want all deleted", frontDoor.endpoint, frontDoor.domains)
+	}
+	if err := fakeClient.Get(ctx, key, &fleetnetv1alpha1.MultiClusterIngress{}); !apierrors.IsNotFound(err) {
+		t.Errorf("MultiClusterIngress Get() = %v, want not found error", err)
+	}
+}
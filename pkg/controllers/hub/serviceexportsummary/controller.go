@@ -0,0 +1,147 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package serviceexportsummary features the ServiceExportSummary controller, which maintains a lightweight summary
+// of each exported Service, derived from its ServiceImport in the hub cluster.
+package serviceexportsummary
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+const (
+	// ControllerName is the name of the Reconciler; it must be set explicitly as the ServiceImport controller
+	// watches ServiceImports as well.
+	ControllerName = "serviceexportsummary-controller"
+)
+
+// Reconciler reconciles the ServiceExportSummary of a ServiceImport.
+type Reconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
+}
+
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceexportsummaries,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceexportsummaries/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports,verbs=get;list;watch
+
+// Reconcile creates, updates or deletes the ServiceExportSummary of a ServiceImport, which shares its namespace and
+// name, so that the summary exists if and only if the ServiceImport does.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	summaryRef := klog.KRef(req.Namespace, req.Name)
+	startTime := time.Now()
+	klog.V(2).InfoS("Reconciliation starts", "serviceExportSummary", summaryRef)
+	defer func() {
+		latency := time.Since(startTime).Milliseconds()
+		klog.V(2).InfoS("Reconciliation ends", "serviceExportSummary", summaryRef, "latency", latency)
+	}()
+
+	serviceImport := &fleetnetv1alpha1.ServiceImport{}
+	if err := r.Client.Get(ctx, req.NamespacedName, serviceImport); err != nil {
+		if !errors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to get serviceImport", "serviceImport", summaryRef)
+			return ctrl.Result{}, err
+		}
+		serviceImport = nil
+	}
+	if serviceImport == nil || serviceImport.DeletionTimestamp != nil {
+		// The last export of the Service has been removed; the garbage collector deletes the summary owned by
+		// the ServiceImport as well, which this merely speeds up.
+		summary := &fleetnetv1alpha1.ServiceExportSummary{
+			ObjectMeta: metav1.ObjectMeta{Namespace: req.Namespace, Name: req.Name},
+		}
+		klog.V(2).InfoS("Deleting the serviceExportSummary of the removed serviceImport", "serviceExportSummary", summaryRef)
+		if err := r.Client.Delete(ctx, summary); client.IgnoreNotFound(err) != nil {
+			klog.ErrorS(err, "Failed to delete serviceExportSummary", "serviceExportSummary", summaryRef)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	summary := &fleetnetv1alpha1.ServiceExportSummary{
+		ObjectMeta: metav1.ObjectMeta{Namespace: req.Namespace, Name: req.Name},
+	}
+	if op, err := controllerutil.CreateOrUpdate(ctx, r.Client, summary, func() error {
+		if summary.Labels == nil {
+			summary.Labels = map[string]string{}
+		}
+		summary.Labels[objectmeta.LabelManagedBy] = objectmeta.HubNetControllerManagerName
+		return controllerutil.SetControllerReference(serviceImport, summary, r.Scheme)
+	}); err != nil {
+		klog.ErrorS(err, "Failed to create or update serviceExportSummary", "serviceExportSummary", summaryRef, "op", op)
+		return ctrl.Result{}, err
+	}
+
+	desiredStatus := buildSummaryStatus(serviceImport)
+	// The sync time only moves when the summary changes, so that the summary is not rewritten on every
+	// reconciliation.
+	desiredStatus.LastSyncTime = summary.Status.LastSyncTime
+	if equality.Semantic.DeepEqual(desiredStatus, summary.Status) {
+		return ctrl.Result{}, nil
+	}
+	now := metav1.Now()
+	desiredStatus.LastSyncTime = &now
+	summary.Status = desiredStatus
+	klog.V(2).InfoS("Updating serviceExportSummary status", "serviceExportSummary", summaryRef, "status", summary.Status)
+	if err := r.Client.Status().Update(ctx, summary); err != nil {
+		klog.ErrorS(err, "Failed to update serviceExportSummary status", "serviceExportSummary", summaryRef)
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// buildSummaryStatus summarizes the status of a ServiceImport, less the sync time.
+func buildSummaryStatus(serviceImport *fleetnetv1alpha1.ServiceImport) fleetnetv1alpha1.ServiceExportSummaryStatus {
+	contributing := make(map[string]bool, len(serviceImport.Status.Clusters))
+	for _, c := range serviceImport.Status.Clusters {
+		contributing[c.Cluster] = true
+	}
+	status := fleetnetv1alpha1.ServiceExportSummaryStatus{
+		ContributingClusters: int32(len(contributing)),
+	}
+	if len(serviceImport.Status.ClusterExportSummaries) > 0 {
+		// The cluster export summaries of a ServiceImport are already sorted by cluster.
+		status.Clusters = append([]fleetnetv1alpha1.ClusterExportSummary{}, serviceImport.Status.ClusterExportSummaries...)
+	}
+	for _, c := range serviceImport.Status.ClusterExportSummaries {
+		if contributing[c.Cluster] {
+			status.TotalEndpoints += c.Endpoints
+		}
+		if c.Conflict == metav1.ConditionTrue {
+			status.ConflictedClusters++
+		}
+	}
+	return status
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// A ServiceExportSummary shares its namespace and name with its ServiceImport, so the requests of both map to
+	// the same key.
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(ControllerName).
+		For(&fleetnetv1alpha1.ServiceImport{}).
+		Owns(&fleetnetv1alpha1.ServiceExportSummary{}).
+		WithOptions(r.ControllerOptions).
+		Complete(metrics.InstrumentReconciler("serviceexportsummary", r))
+}
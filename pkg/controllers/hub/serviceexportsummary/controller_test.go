@@ -0,0 +1,122 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package serviceexportsummary
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+const (
+	testNamespace   = "work"
+	testServiceName = "app"
+)
+
+// TestReconcile tests that the summary reflects a two-cluster export, and is cleaned up when the last export is
+// removed along with the ServiceImport.
+func TestReconcile(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	clusterExportSummaries := []fleetnetv1alpha1.ClusterExportSummary{
+		{Cluster: "member-1", Valid: metav1.ConditionTrue, Conflict: metav1.ConditionFalse, Endpoints: 3},
+		{Cluster: "member-2", Valid: metav1.ConditionTrue, Conflict: metav1.ConditionFalse, Endpoints: 2},
+		{Cluster: "member-3", Valid: metav1.ConditionTrue, Conflict: metav1.ConditionTrue, Endpoints: 4},
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testServiceName,
+			UID:       "svc-import-uid",
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Clusters:               []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-1"}, {Cluster: "member-2"}},
+			ClusterExportSummaries: clusterExportSummaries,
+		},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(serviceImport).
+		WithStatusSubresource(serviceImport, &fleetnetv1alpha1.ServiceExportSummary{}).
+		Build()
+	r := &Reconciler{Client: fakeClient, Scheme: scheme}
+	key := types.NamespacedName{Namespace: testNamespace, Name: testServiceName}
+
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile() = %v, want no error", err)
+	}
+	got := &fleetnetv1alpha1.ServiceExportSummary{}
+	if err := fakeClient.Get(ctx, key, got); err != nil {
+		t.Fatalf("ServiceExportSummary Get() = %v, want no error", err)
+	}
+	wantLabels := map[string]string{objectmeta.LabelManagedBy: objectmeta.HubNetControllerManagerName}
+	if diff := cmp.Diff(wantLabels, got.Labels); diff != "" {
+		t.Errorf("ServiceExportSummary labels mismatch (-want, +got):\n%s", diff)
+	}
+	wantOwners := []metav1.OwnerReference{
+		{
+			APIVersion:         fleetnetv1alpha1.GroupVersion.String(),
+			Kind:               "ServiceImport",
+			Name:               testServiceName,
+			UID:                "svc-import-uid",
+			Controller:         ptr.To(true),
+			BlockOwnerDeletion: ptr.To(true),
+		},
+	}
+	if diff := cmp.Diff(wantOwners, got.OwnerReferences); diff != "" {
+		t.Errorf("ServiceExportSummary owner references mismatch (-want, +got):\n%s", diff)
+	}
+	wantStatus := fleetnetv1alpha1.ServiceExportSummaryStatus{
+		Clusters:             clusterExportSummaries,
+		ContributingClusters: 2,
+		TotalEndpoints:       5,
+		ConflictedClusters:   1,
+	}
+	if diff := cmp.Diff(wantStatus, got.Status, cmpopts.IgnoreFields(fleetnetv1alpha1.ServiceExportSummaryStatus{}, "LastSyncTime")); diff != "" {
+		t.Errorf("ServiceExportSummary status mismatch (-want, +got):\n%s", diff)
+	}
+	if got.Status.LastSyncTime == nil {
+		t.Fatalf("ServiceExportSummary lastSyncTime = nil, want set")
+	}
+
+	// An unchanged summary is not rewritten.
+	resourceVersion := got.ResourceVersion
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile() = %v, want no error", err)
+	}
+	if err := fakeClient.Get(ctx, key, got); err != nil {
+		t.Fatalf("ServiceExportSummary Get() = %v, want no error", err)
+	}
+	if got.ResourceVersion != resourceVersion {
+		t.Errorf("ServiceExportSummary resourceVersion = %s, want %s", got.ResourceVersion, resourceVersion)
+	}
+
+	// The last export is removed, and the ServiceImport with it.
+	if err := fakeClient.Delete(ctx, serviceImport); err != nil {
+		t.Fatalf("ServiceImport Delete() = %v, want no error", err)
+	}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile() = %v, want no error", err)
+	}
+	if err := fakeClient.Get(ctx, key, got); !errors.IsNotFound(err) {
+		t.Errorf("ServiceExportSummary Get() = %v, want NotFound", err)
+	}
+}
@@ -9,37 +9,105 @@ package serviceimport
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 	"go.goms.io/fleet-networking/pkg/common/apiretry"
+	"go.goms.io/fleet-networking/pkg/common/apply"
+	"go.goms.io/fleet-networking/pkg/common/clusterhealth"
+	"go.goms.io/fleet-networking/pkg/common/clusterquarantine"
 	"go.goms.io/fleet-networking/pkg/common/condition"
+	"go.goms.io/fleet-networking/pkg/common/conflictresolution"
+	"go.goms.io/fleet-networking/pkg/common/explain"
+	"go.goms.io/fleet-networking/pkg/common/exportapproval"
+	"go.goms.io/fleet-networking/pkg/common/exportdenylist"
+	"go.goms.io/fleet-networking/pkg/common/exportquota"
+	"go.goms.io/fleet-networking/pkg/common/fieldindex"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
 	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/pause"
+	"go.goms.io/fleet-networking/pkg/common/rebuild"
+	"go.goms.io/fleet-networking/pkg/common/staleexport"
+	"go.goms.io/fleet-networking/pkg/common/tenancy"
 )
 
 const (
 	// fields name used to filter resources
-	exportedServiceFieldNamespacedName = ".spec.serviceReference.namespacedName"
+	exportedServiceFieldNamespacedName = fieldindex.InternalServiceExportServiceNamespacedName
+	// endpointSliceExportOwnerSvcNamespacedNameFieldKey is the field index of the endpointSliceExports by the
+	// namespaced name of their service.
+	endpointSliceExportOwnerSvcNamespacedNameFieldKey = fieldindex.EndpointSliceExportOwnerServiceNamespacedName
 
 	// ControllerName is the name of the Reconciler.
 	ControllerName = "serviceimport-controller"
+
+	// defaultEndpointDistributionDebounceWindow is used when the debounce window is not configured.
+	defaultEndpointDistributionDebounceWindow = 5 * time.Second
+
+	// defaultDNSTTLSeconds is used when the default DNS TTL is not configured.
+	defaultDNSTTLSeconds = 30
+
+	conditionReasonSessionAffinityMismatch    = "SessionAffinityMismatch"
+	conditionReasonSessionAffinityConsistent  = "SessionAffinityConsistent"
+	conditionReasonNamedPortMissing           = "NamedPortMissing"
+	conditionReasonNamedPortsServed           = "NamedPortsServed"
+	conditionReasonHealthCheckMismatch        = "HealthCheckMismatch"
+	conditionReasonHealthCheckConsistent      = "HealthCheckConsistent"
+	conditionReasonExportedMetadataMismatch   = "ExportedMetadataMismatch"
+	conditionReasonExportedMetadataConsistent = "ExportedMetadataConsistent"
 )
 
 // Reconciler reconciles a ServiceImport object.
 type Reconciler struct {
 	client.Client
 	Recorder record.EventRecorder
+	// EndpointDistributionDebounceWindow is the wait time for the controller to re-compute the endpoint distribution
+	// of a ServiceImport after its exported endpoints change; changes within the window are handled in one go.
+	EndpointDistributionDebounceWindow time.Duration
+	// DefaultDNSTTLSeconds is the DNS TTL, in seconds, assumed for the exporting clusters that set no valid TTL hint.
+	DefaultDNSTTLSeconds int64
+	// DenylistConfigMap is the ConfigMap holding the patterns of the services which must not be exported to the
+	// fleet; the denylist is disabled if the name is empty.
+	DenylistConfigMap types.NamespacedName
+	// RebuildConfigMap is the ConfigMap holding the deadline of the rebuild of the hub state from the member
+	// clusters; the ServiceImports are not resolved until the deadline passes, so that they are resolved from all
+	// the re-published exports. The rebuild is disabled if the name is empty.
+	RebuildConfigMap types.NamespacedName
+	// ConflictResolver decides whose spec the serviceImport takes when the exported services are in conflict;
+	// the oldest export wins if it is not set.
+	ConflictResolver *conflictresolution.Resolver
+	// ReconcileResults, if set, records the outcome of the last reconciliation of each serviceImport, which is
+	// reported by the explain debug endpoint.
+	ReconcileResults *explain.Results
+	// ServerSideApply, if set, writes the serviceImport status with server-side applies, the entry of each exporting
+	// cluster with the field manager of the cluster, so that the writes never conflict with the ones of the
+	// internalServiceExport controller.
+	ServerSideApply bool
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
 }
 
 // statusChange stores the internalServiceExports list whose status needs to be updated.
@@ -53,73 +121,173 @@ type statusChange struct {
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports/finalizers,verbs=update
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalserviceexports,verbs=get;watch;list
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalserviceexports/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=endpointsliceexports,verbs=get;watch;list
 //+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+//+kubebuilder:rbac:groups=cluster.kubernetes-fleet.io,resources=memberclusters,verbs=get;list;watch
 
 // Reconcile resolves the service spec when the serviceImport status is empty and updates the status of internalServiceExports.
-func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
 	serviceImportKRef := klog.KRef(req.Namespace, req.Name)
+	logger := klog.FromContext(ctx).WithValues("serviceImport", serviceImportKRef)
+	ctx = klog.NewContext(ctx, logger)
 	startTime := time.Now()
-	klog.V(2).InfoS("Reconciliation starts", "serviceImport", serviceImportKRef)
+	logger.V(2).Info("Reconciliation starts")
+	gone := false
 	defer func() {
 		latency := time.Since(startTime).Milliseconds()
-		klog.V(2).InfoS("Reconciliation ends", "serviceImport", serviceImportKRef, "latency", latency)
+		logger.V(2).Info("Reconciliation ends", "latency", latency)
+		if gone {
+			r.ReconcileResults.Forget(req.NamespacedName)
+			return
+		}
+		r.ReconcileResults.Record(req.NamespacedName, result, err)
 	}()
 	serviceImport := fleetnetv1alpha1.ServiceImport{}
 	if err := r.Client.Get(ctx, req.NamespacedName, &serviceImport); err != nil {
 		if errors.IsNotFound(err) {
-			klog.V(4).InfoS("Ignoring NotFound serviceImport", "serviceImport", serviceImportKRef)
+			logger.V(4).Info("Ignoring NotFound serviceImport")
+			gone = true
 			return ctrl.Result{}, nil
 		}
-		klog.ErrorS(err, "Failed to get serviceImport", "serviceImport", serviceImportKRef)
+		logger.Error(err, "Failed to get serviceImport")
 		return ctrl.Result{}, err
 	}
-	// If the spec has already present, no need to resolve the service spec.
-	if len(serviceImport.Status.Clusters) != 0 {
-		klog.V(4).InfoS("Already resolved the service spec and skipping", "serviceImport", serviceImportKRef)
+	// Leave the serviceImport, and the Service as it has been imported across the fleet, as it is while its
+	// reconciliation is paused; the Paused condition is removed as soon as the reconciliation resumes.
+	paused := pause.IsPaused(&serviceImport)
+	oldStatus := serviceImport.Status.DeepCopy()
+	if pause.SetCondition(&serviceImport.Status.Conditions, string(fleetnetv1alpha1.ServiceImportPaused), paused, serviceImport.Generation) {
+		if err := r.updateStatus(ctx, &serviceImport, oldStatus); err != nil {
+			logger.Error(err, "Failed to update the paused condition of the serviceImport")
+			return ctrl.Result{}, err
+		}
+	}
+	if paused {
+		logger.V(2).Info("Reconciliation of the serviceImport is paused")
+		return ctrl.Result{}, nil
+	}
+	denylist, err := exportdenylist.Get(ctx, r.Client, r.DenylistConfigMap)
+	if err != nil {
+		logger.Error(err, "Failed to get the export denylist", "configMap", r.DenylistConfigMap)
+		return ctrl.Result{}, err
+	}
+	if denylist.Denies(serviceImport.Namespace, serviceImport.Name) {
+		// The exports are vetoed by the hub; the internalServiceExport controller reports the veto on each of them.
+		logger.V(2).Info("Exported service is denied by the hub and deleting serviceImport")
+		r.Recorder.Eventf(&serviceImport, corev1.EventTypeWarning, "ExportDenied", "Service is denied by the export denylist and deleting serviceImport %s", serviceImport.Name)
+		if err := r.Client.Delete(ctx, &serviceImport); err != nil {
+			logger.Error(err, "Failed to delete serviceImport")
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
 		return ctrl.Result{}, nil
 	}
-
 	internalServiceExportList := &fleetnetv1alpha1.InternalServiceExportList{}
 	namespaceName := types.NamespacedName{Namespace: serviceImport.Namespace, Name: serviceImport.Name}
 	listOpts := client.MatchingFields{
 		exportedServiceFieldNamespacedName: namespaceName.String(),
 	}
 	if err := r.Client.List(ctx, internalServiceExportList, &listOpts); err != nil {
-		klog.ErrorS(err, "Failed to list internalServiceExports used by the serviceImport", "serviceImport", serviceImportKRef)
+		logger.Error(err, "Failed to list internalServiceExports used by the serviceImport")
 		return ctrl.Result{}, err
 	}
+	exporting := exportingClusters(internalServiceExportList.Items)
+	// The exports excluded by the hub, e.g. from quarantined member clusters, take no part in the serviceImport.
+	internalServiceExportList.Items = excludeOutOfServiceExports(internalServiceExportList.Items)
+
+	// If the spec has already present, no need to resolve the service spec; only the status fields derived from
+	// the exports need to be kept up to date.
+	if len(serviceImport.Status.Clusters) != 0 {
+		logger.V(4).Info("Already resolved the service spec; refreshing the derived status")
+		oldStatus = serviceImport.Status.DeepCopy()
+		if r.ServerSideApply {
+			// The applies carry no resource version: the entry of a cluster applied from a stale read may outlive
+			// the withdrawal of the cluster, and is removed here once its internalServiceExport is gone.
+			pruneWithdrawnClusters(&serviceImport, exporting)
+		}
+		if len(serviceImport.Status.Clusters) == 0 {
+			logger.V(2).Info("Removing the withdrawn clusters from the serviceImport status")
+			if err := r.updateStatus(ctx, &serviceImport, oldStatus); err != nil {
+				logger.Error(err, "Failed to remove the withdrawn clusters from the serviceImport status")
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{Requeue: true}, nil
+		}
+		if err := r.setDerivedStatus(ctx, &serviceImport, internalServiceExportList.Items); err != nil {
+			logger.Error(err, "Failed to compute the derived status")
+			return ctrl.Result{}, err
+		}
+		if equality.Semantic.DeepEqual(oldStatus, &serviceImport.Status) {
+			return ctrl.Result{}, nil
+		}
+		logger.V(2).Info("Updating the serviceImport derived status")
+		if err := r.updateStatus(ctx, &serviceImport, oldStatus); err != nil {
+			logger.Error(err, "Failed to update the serviceImport derived status")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
 	if len(internalServiceExportList.Items) == 0 {
-		klog.V(2).InfoS("No internalServiceExport found and deleting serviceImport", "serviceImport", serviceImportKRef)
+		logger.V(2).Info("No internalServiceExport found and deleting serviceImport")
 		return r.deleteServiceImport(ctx, &serviceImport)
 	}
+	// While the hub state is being rebuilt, the exports are still being re-published by the member clusters in no
+	// particular order; the spec is resolved once all of them are in, so that the same export wins as before.
+	remaining, err := rebuild.Remaining(ctx, r.Client, r.RebuildConfigMap, time.Now())
+	if err != nil {
+		logger.Error(err, "Failed to get the rebuild deadline", "configMap", r.RebuildConfigMap)
+		return ctrl.Result{}, err
+	}
+	if remaining > 0 {
+		logger.V(2).Info("The hub state is being rebuilt; deferring the resolution of the service spec", "remaining", remaining)
+		return ctrl.Result{RequeueAfter: remaining}, nil
+	}
 	change := statusChange{
 		conflict:   []*fleetnetv1alpha1.InternalServiceExport{},
 		noConflict: []*fleetnetv1alpha1.InternalServiceExport{},
 	}
 
-	var resolvedPortsSpec *[]fleetnetv1alpha1.ServicePort
+	candidates := make([]*fleetnetv1alpha1.InternalServiceExport, 0, len(internalServiceExportList.Items))
 	for i := range internalServiceExportList.Items {
-		v := internalServiceExportList.Items[i]
+		// point to the list items so that the status updates below are visible when deriving the status
+		v := &internalServiceExportList.Items[i]
 		if v.DeletionTimestamp != nil { // skip if the resource is in the deleting state
-			klog.V(4).InfoS("Skipping the internalServiceExport which is in the deleting state", serviceImport, serviceImportKRef, "internalServiceExport", klog.KObj(&v))
+			logger.V(4).Info("Skipping the internalServiceExport which is in the deleting state", "internalServiceExport", klog.KObj(v))
 			continue
 		}
 		// skip if the resource is just added which has not been handled by the internalServiceExport controller yet
-		if !controllerutil.ContainsFinalizer(&v, objectmeta.InternalServiceExportFinalizer) {
-			klog.V(3).InfoS("Skipping the internalServiceExport because of missing finalizer", "serviceImport", serviceImportKRef, "internalServiceExport", klog.KObj(&v))
+		if !controllerutil.ContainsFinalizer(v, objectmeta.InternalServiceExportFinalizer) {
+			logger.V(3).Info("Skipping the internalServiceExport because of missing finalizer", "internalServiceExport", klog.KObj(v))
 			continue
 		}
+		candidates = append(candidates, v)
+	}
+	// The spec of the export ranked first by the conflict resolution policy wins.
+	policy, err := r.ConflictResolver.Sort(ctx, &serviceImport, candidates)
+	if err != nil {
+		logger.Error(err, "Failed to rank the internalServiceExports by the conflict resolution policy")
+		return ctrl.Result{}, err
+	}
 
+	var resolvedPortsSpec *[]fleetnetv1alpha1.ServicePort
+	var resolvedHeadless bool
+	var resolution *fleetnetv1alpha1.ConflictResolutionStatus
+	for _, v := range candidates {
 		if resolvedPortsSpec == nil {
-			// pick the first internalServiceExport spec
 			resolvedPortsSpec = &v.Spec.Ports
+			resolvedHeadless = v.Spec.Headless
+			resolution = &fleetnetv1alpha1.ConflictResolutionStatus{
+				Policy:         policy,
+				WinningCluster: v.Spec.ServiceReference.ClusterID,
+			}
 		}
 		// TODO: ideally we should ignore the order when comparing the serviceImports; port and protocol are the key.
-		if !equality.Semantic.DeepEqual(*resolvedPortsSpec, v.Spec.Ports) {
-			change.conflict = append(change.conflict, &v)
+		// A headless Service cannot be imported along with a Service with a cluster IP.
+		if !equality.Semantic.DeepEqual(*resolvedPortsSpec, v.Spec.Ports) || resolvedHeadless != v.Spec.Headless {
+			change.conflict = append(change.conflict, v)
 			continue
 		}
-		change.noConflict = append(change.noConflict, &v)
+		change.noConflict = append(change.noConflict, v)
 	}
 
 	if resolvedPortsSpec == nil {
@@ -127,55 +295,124 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		// We could safely delete the serviceImport if exists.
 		// When the internalserviceexport controller starts processing the object, it will create the serviceImport at
 		// that time.
-		klog.V(2).InfoS("No valid internalServiceExport found and deleting serviceImport", "serviceImport", serviceImportKRef)
+		logger.V(2).Info("No valid internalServiceExport found and deleting serviceImport")
 		return r.deleteServiceImport(ctx, &serviceImport)
 	}
 
 	// To reduce reconcile failure, we'll keep retry until it succeeds.
-	clusters := make([]fleetnetv1alpha1.ClusterStatus, 0, len(change.noConflict))
+	clusters := make([]fleetnetv1alpha1.ServiceImportClusterStatus, 0, len(change.noConflict))
 	for _, v := range change.noConflict {
-		klog.V(3).InfoS("Marking internalServiceExport status as nonConflict", "serviceImport", serviceImportKRef, "internalServiceExport", klog.KObj(v))
-		if err := r.updateInternalServiceExportWithRetry(ctx, v, false); err != nil {
+		logger.V(3).Info("Marking internalServiceExport status as nonConflict", "internalServiceExport", klog.KObj(v))
+		if err := r.updateInternalServiceExportWithRetry(ctx, v, false, resolution); err != nil {
 			if errors.IsNotFound(err) { // ignore deleted internalServiceExport
 				continue
 			}
 			return ctrl.Result{}, err
 		}
-		clusters = append(clusters, fleetnetv1alpha1.ClusterStatus{Cluster: v.Spec.ServiceReference.ClusterID})
+		clusters = append(clusters, fleetnetv1alpha1.ServiceImportClusterStatus{
+			Cluster:                       v.Spec.ServiceReference.ClusterID,
+			AllocateLoadBalancerNodePorts: v.Spec.AllocateLoadBalancerNodePorts,
+			LoadBalancerIP:                v.Spec.LoadBalancerIP,
+			IPFamilyPolicy:                v.Spec.IPFamilyPolicy,
+			IPFamilies:                    v.Spec.IPFamilies,
+		})
 	}
 	if len(clusters) == 0 {
 		// At that time, all of internalServiceExports has been deleted.
 		// need to redo the Reconcile to pick new ports spec
-		klog.V(2).InfoS("Requeue the request to resolve the spec", "serviceImport", serviceImportKRef)
+		logger.V(2).Info("Requeue the request to resolve the spec")
 		return ctrl.Result{Requeue: true}, nil
 	}
 	for _, v := range change.conflict {
-		klog.V(3).InfoS("Marking internalServiceExport status as Conflict", "serviceImport", serviceImportKRef, "internalServiceExport", klog.KObj(v))
-		if err := r.updateInternalServiceExportWithRetry(ctx, v, true); err != nil {
+		logger.V(3).Info("Marking internalServiceExport status as Conflict", "internalServiceExport", klog.KObj(v))
+		if err := r.updateInternalServiceExportWithRetry(ctx, v, true, resolution); err != nil {
 			return ctrl.Result{}, client.IgnoreNotFound(err)
 		}
 	}
+	serviceImportType := fleetnetv1alpha1.ClusterSetIP
+	if resolvedHeadless {
+		serviceImportType = fleetnetv1alpha1.Headless
+	}
+	oldStatus = serviceImport.Status.DeepCopy()
 	serviceImport.Status = fleetnetv1alpha1.ServiceImportStatus{
-		Ports:    *resolvedPortsSpec,
-		Clusters: clusters,
-		Type:     fleetnetv1alpha1.ClusterSetIP, // may support headless in the future
+		Ports:              *resolvedPortsSpec,
+		Clusters:           clusters,
+		Type:               serviceImportType,
+		ConflictResolution: resolution,
+	}
+	if err := r.setDerivedStatus(ctx, &serviceImport, internalServiceExportList.Items); err != nil {
+		logger.Error(err, "Failed to compute the derived status")
+		return ctrl.Result{}, err
 	}
 	updateFunc := func() error {
-		return r.Status().Update(ctx, &serviceImport)
+		return r.updateStatus(ctx, &serviceImport, oldStatus)
 	}
-	klog.V(2).InfoS("Updating the serviceImport status", "serviceImport", serviceImportKRef)
+	logger.V(2).Info("Updating the serviceImport status")
 	if err := apiretry.Do(updateFunc); err != nil {
-		klog.ErrorS(err, "Failed to update serviceImport status with retry", "serviceImport", serviceImportKRef)
+		logger.Error(err, "Failed to update serviceImport status with retry")
 		return ctrl.Result{}, err
 	}
 	r.Recorder.Eventf(&serviceImport, corev1.EventTypeNormal, "SuccessfulUpdateStatus", "Resolved exported service properties and updated %s status", serviceImport.Name)
 	return ctrl.Result{}, nil
 }
 
-func (r *Reconciler) updateInternalServiceExportWithRetry(ctx context.Context, internalServiceExport *fleetnetv1alpha1.InternalServiceExport, conflict bool) error {
+// excludeOutOfServiceExports returns the internalServiceExports which are not reported as quarantined, in conflict
+// with another tenant, pending approval, over quota, stale or unhealthy.
+func excludeOutOfServiceExports(internalServiceExports []fleetnetv1alpha1.InternalServiceExport) []fleetnetv1alpha1.InternalServiceExport {
+	included := internalServiceExports[:0]
+	for i := range internalServiceExports {
+		if !clusterquarantine.IsQuarantinedExport(&internalServiceExports[i]) && !tenancy.IsConflictedExport(&internalServiceExports[i]) &&
+			!exportapproval.IsPendingExport(&internalServiceExports[i]) &&
+			!exportquota.IsOverQuotaExport(&internalServiceExports[i]) && !staleexport.IsStaleExport(&internalServiceExports[i]) &&
+			!clusterhealth.IsUnhealthyExport(&internalServiceExports[i]) {
+			included = append(included, internalServiceExports[i])
+		}
+	}
+	return included
+}
+
+// exportingClusters returns the clusters which export the service, including the ones whose exports are excluded.
+func exportingClusters(internalServiceExports []fleetnetv1alpha1.InternalServiceExport) map[string]bool {
+	clusters := make(map[string]bool, len(internalServiceExports))
+	for i := range internalServiceExports {
+		clusters[internalServiceExports[i].Spec.ServiceReference.ClusterID] = true
+	}
+	return clusters
+}
+
+// pruneWithdrawnClusters removes the clusters which no longer export the service from the serviceImport status;
+// the status is reset once no cluster is left, so that the spec is resolved again, as the internalServiceExport
+// controller does when it removes the last cluster.
+func pruneWithdrawnClusters(serviceImport *fleetnetv1alpha1.ServiceImport, exportingClusters map[string]bool) {
+	var clusters []fleetnetv1alpha1.ServiceImportClusterStatus
+	for _, c := range serviceImport.Status.Clusters {
+		if exportingClusters[c.Cluster] {
+			clusters = append(clusters, c)
+		}
+	}
+	if len(clusters) == len(serviceImport.Status.Clusters) {
+		return
+	}
+	if len(clusters) == 0 {
+		serviceImport.Status = fleetnetv1alpha1.ServiceImportStatus{}
+		return
+	}
+	serviceImport.Status.Clusters = clusters
+}
+
+// updateStatus writes the serviceImport status, which was oldStatus when the serviceImport was read.
+func (r *Reconciler) updateStatus(ctx context.Context, serviceImport *fleetnetv1alpha1.ServiceImport, oldStatus *fleetnetv1alpha1.ServiceImportStatus) error {
+	if r.ServerSideApply {
+		return apply.ServiceImportStatus(ctx, r.Client, serviceImport, oldStatus)
+	}
+	return r.Status().Update(ctx, serviceImport)
+}
+
+func (r *Reconciler) updateInternalServiceExportWithRetry(ctx context.Context, internalServiceExport *fleetnetv1alpha1.InternalServiceExport,
+	conflict bool, resolution *fleetnetv1alpha1.ConflictResolutionStatus) error {
 	desiredCond := condition.UnconflictedServiceExportConflictCondition(*internalServiceExport)
 	if conflict {
-		desiredCond = condition.ConflictedServiceExportConflictCondition(*internalServiceExport)
+		desiredCond = condition.ConflictedServiceExportConflictCondition(*internalServiceExport, resolution)
 	}
 	currentCond := meta.FindStatusCondition(internalServiceExport.Status.Conditions, string(fleetnetv1alpha1.ServiceExportConflict))
 	if condition.EqualCondition(currentCond, &desiredCond) {
@@ -188,7 +425,7 @@ func (r *Reconciler) updateInternalServiceExportWithRetry(ctx context.Context, i
 		return r.Client.Status().Update(ctx, internalServiceExport)
 	}
 	if err := apiretry.Do(updateFunc); err != nil {
-		klog.ErrorS(err, "Failed to update internalServiceExport status with retry", "internalServiceExport", exportKObj)
+		klog.FromContext(ctx).Error(err, "Failed to update internalServiceExport status with retry", "internalServiceExport", exportKObj)
 		return err
 	}
 	return nil
@@ -197,17 +434,651 @@ func (r *Reconciler) updateInternalServiceExportWithRetry(ctx context.Context, i
 func (r *Reconciler) deleteServiceImport(ctx context.Context, serviceImport *fleetnetv1alpha1.ServiceImport) (ctrl.Result, error) {
 	r.Recorder.Eventf(serviceImport, corev1.EventTypeNormal, "NoExportedService", "No exported service and deleting serviceImport %s", serviceImport.Name)
 
-	serviceImportKObj := klog.KObj(serviceImport)
+	// The logger of the reconciliation carries the serviceImport.
+	logger := klog.FromContext(ctx)
 	if err := r.Client.Delete(ctx, serviceImport); err != nil {
-		klog.ErrorS(err, "Failed to delete serviceImport", "serviceImport", serviceImportKObj)
+		logger.Error(err, "Failed to delete serviceImport")
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
-	klog.V(2).InfoS("There are no internalServiceExports and serviceImport has been deleted", "serviceImport", serviceImportKObj)
+	logger.V(2).Info("There are no internalServiceExports and serviceImport has been deleted")
 	return ctrl.Result{}, nil
 }
 
+// setDerivedStatus sets the status fields of a ServiceImport that are derived from its exports, i.e. the endpoint
+// distribution, the DNS TTL, the cluster export summaries, and the per-cluster export status; the resolved
+// clusters must have been set.
+func (r *Reconciler) setDerivedStatus(ctx context.Context, serviceImport *fleetnetv1alpha1.ServiceImport,
+	internalServiceExports []fleetnetv1alpha1.InternalServiceExport) error {
+	endpointSliceExportList := &fleetnetv1alpha1.EndpointSliceExportList{}
+	listOpts := client.MatchingFields{
+		endpointSliceExportOwnerSvcNamespacedNameFieldKey: types.NamespacedName{Namespace: serviceImport.Namespace, Name: serviceImport.Name}.String(),
+	}
+	if err := r.Client.List(ctx, endpointSliceExportList, listOpts); err != nil {
+		return err
+	}
+
+	clusterWeights := make(map[string]int64, len(internalServiceExports))
+	canaryPercents := make(map[string]int32)
+	for i := range internalServiceExports {
+		weight := int64(1)
+		if internalServiceExports[i].Spec.Weight != nil {
+			weight = *internalServiceExports[i].Spec.Weight
+		}
+		clusterWeights[internalServiceExports[i].Spec.ServiceReference.ClusterID] = weight
+		if internalServiceExports[i].Spec.CanaryPercent != nil {
+			canaryPercents[internalServiceExports[i].Spec.ServiceReference.ClusterID] = *internalServiceExports[i].Spec.CanaryPercent
+		}
+	}
+	serviceImport.Status.EndpointDistribution = buildEndpointDistribution(serviceImport.Status.Clusters, clusterWeights, endpointSliceExportList.Items)
+	applyCanaryPercents(serviceImport.Status.EndpointDistribution, canaryPercents)
+	serviceImport.Status.DNSTTLSeconds = aggregateDNSTTL(serviceImport.Status.Clusters, internalServiceExports, r.DefaultDNSTTLSeconds)
+	serviceImport.Status.ClusterExportSummaries = buildClusterExportSummaries(internalServiceExports, endpointSliceExportList.Items)
+	setClusterExportStatus(serviceImport.Status.Clusters, internalServiceExports, endpointSliceExportList.Items)
+
+	affinity, affinityConfig, affinityCond := aggregateSessionAffinity(serviceImport.Status.Clusters, internalServiceExports, serviceImport.Generation)
+	serviceImport.Status.SessionAffinity = affinity
+	serviceImport.Status.SessionAffinityConfig = affinityConfig
+	if affinityCond != nil {
+		meta.SetStatusCondition(&serviceImport.Status.Conditions, *affinityCond)
+	} else {
+		meta.RemoveStatusCondition(&serviceImport.Status.Conditions, string(fleetnetv1alpha1.ServiceImportSessionAffinityConflict))
+	}
+
+	if portCond := namedPortMissingCondition(serviceImport.Status.Ports, serviceImport.Status.Clusters, endpointSliceExportList.Items, serviceImport.Generation); portCond != nil {
+		meta.SetStatusCondition(&serviceImport.Status.Conditions, *portCond)
+	} else {
+		meta.RemoveStatusCondition(&serviceImport.Status.Conditions, string(fleetnetv1alpha1.ServiceImportNamedPortMissing))
+	}
+
+	healthCheckAnnotations, healthCheckCond := aggregateHealthCheckAnnotations(serviceImport.Status.Clusters, internalServiceExports, serviceImport.Generation)
+	serviceImport.Status.HealthCheckAnnotations = healthCheckAnnotations
+	if healthCheckCond != nil {
+		meta.SetStatusCondition(&serviceImport.Status.Conditions, *healthCheckCond)
+	} else {
+		meta.RemoveStatusCondition(&serviceImport.Status.Conditions, string(fleetnetv1alpha1.ServiceImportHealthCheckConflict))
+	}
+
+	exportedLabels, exportedAnnotations, metadataCond := aggregateExportedMetadata(serviceImport.Status.Clusters, internalServiceExports, serviceImport.Generation)
+	serviceImport.Status.ExportedLabels = exportedLabels
+	serviceImport.Status.ExportedAnnotations = exportedAnnotations
+	if metadataCond != nil {
+		meta.SetStatusCondition(&serviceImport.Status.Conditions, *metadataCond)
+	} else {
+		meta.RemoveStatusCondition(&serviceImport.Status.Conditions, string(fleetnetv1alpha1.ServiceImportExportedMetadataConflict))
+	}
+	serviceImport.Status.AllowedConsumers = aggregateAllowedConsumers(serviceImport.Status.Clusters, internalServiceExports)
+	return nil
+}
+
+// aggregateAllowedConsumers returns the distinct consumer restrictions of the clusters contributing to a
+// ServiceImport, ordered by cluster name; a member cluster can import the Service only if it is allowed by each of
+// them, so that no exporting cluster has its Service exposed beyond the consumers it allows.
+func aggregateAllowedConsumers(clusters []fleetnetv1alpha1.ServiceImportClusterStatus, internalServiceExports []fleetnetv1alpha1.InternalServiceExport) []fleetnetv1alpha1.ConsumerSelector {
+	contributing := make(map[string]bool, len(clusters))
+	for _, c := range clusters {
+		contributing[c.Cluster] = true
+	}
+	exports := make([]*fleetnetv1alpha1.InternalServiceExport, 0, len(clusters))
+	for i := range internalServiceExports {
+		if contributing[internalServiceExports[i].Spec.ServiceReference.ClusterID] && internalServiceExports[i].Spec.AllowedConsumers != nil {
+			exports = append(exports, &internalServiceExports[i])
+		}
+	}
+	sort.Slice(exports, func(i, j int) bool {
+		return exports[i].Spec.ServiceReference.ClusterID < exports[j].Spec.ServiceReference.ClusterID
+	})
+
+	var allowed []fleetnetv1alpha1.ConsumerSelector
+	for _, export := range exports {
+		duplicate := false
+		for i := range allowed {
+			if equality.Semantic.DeepEqual(allowed[i], *export.Spec.AllowedConsumers) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			allowed = append(allowed, *export.Spec.AllowedConsumers.DeepCopy())
+		}
+	}
+	return allowed
+}
+
+// aggregateHealthCheckAnnotations aggregates the health-check annotations of the clusters contributing to a
+// ServiceImport. Each annotation takes the value set by the cluster whose name sorts first among the clusters
+// setting it, so that the result does not depend on the order of the clusters. No annotations or condition are
+// returned if none of the clusters sets any health-check annotation.
+func aggregateHealthCheckAnnotations(clusters []fleetnetv1alpha1.ServiceImportClusterStatus, internalServiceExports []fleetnetv1alpha1.InternalServiceExport,
+	generation int64) (map[string]string, *metav1.Condition) {
+	exports := make(map[string]*fleetnetv1alpha1.InternalServiceExport, len(internalServiceExports))
+	for i := range internalServiceExports {
+		exports[internalServiceExports[i].Spec.ServiceReference.ClusterID] = &internalServiceExports[i]
+	}
+	clusterNames := make([]string, 0, len(clusters))
+	for _, c := range clusters {
+		clusterNames = append(clusterNames, c.Cluster)
+	}
+	sort.Strings(clusterNames)
+
+	var annotations map[string]string
+	settings := make([]string, 0, len(clusterNames))
+	distinct := make(map[string]bool)
+	for _, cluster := range clusterNames {
+		var clusterAnnotations map[string]string
+		if export, ok := exports[cluster]; ok {
+			clusterAnnotations = export.Spec.HealthCheckAnnotations
+		}
+		keys := make([]string, 0, len(clusterAnnotations))
+		for key, val := range clusterAnnotations {
+			if _, ok := annotations[key]; !ok {
+				if annotations == nil {
+					annotations = make(map[string]string)
+				}
+				annotations[key] = val
+			}
+			keys = append(keys, fmt.Sprintf("%s=%q", key, val))
+		}
+		sort.Strings(keys)
+		setting := "none"
+		if len(keys) > 0 {
+			setting = strings.Join(keys, " ")
+		}
+		distinct[setting] = true
+		settings = append(settings, fmt.Sprintf("%s: %s", cluster, setting))
+	}
+
+	if annotations == nil {
+		return nil, nil
+	}
+	if len(distinct) == 1 {
+		return annotations, &metav1.Condition{
+			Type:               string(fleetnetv1alpha1.ServiceImportHealthCheckConflict),
+			Status:             metav1.ConditionFalse,
+			Reason:             conditionReasonHealthCheckConsistent,
+			ObservedGeneration: generation,
+			Message:            "all exporting clusters agree on the health-check annotations",
+		}
+	}
+	return annotations, &metav1.Condition{
+		Type:               string(fleetnetv1alpha1.ServiceImportHealthCheckConflict),
+		Status:             metav1.ConditionTrue,
+		Reason:             conditionReasonHealthCheckMismatch,
+		ObservedGeneration: generation,
+		Message: fmt.Sprintf("exporting clusters disagree on the health-check annotations (%s); the values of the cluster whose name sorts first are used",
+			strings.Join(settings, ", ")),
+	}
+}
+
+// aggregateExportedMetadata aggregates the labels and annotations exported by the clusters contributing to a
+// ServiceImport. Each key takes the value set by the oldest export among the clusters setting it, ties broken by
+// cluster name; the keys on which the clusters disagree are listed in the returned condition, and no condition is
+// returned if none of the clusters exports any labels or annotations.
+func aggregateExportedMetadata(clusters []fleetnetv1alpha1.ServiceImportClusterStatus, internalServiceExports []fleetnetv1alpha1.InternalServiceExport,
+	generation int64) (exportedLabels, exportedAnnotations map[string]string, cond *metav1.Condition) {
+	contributing := make(map[string]bool, len(clusters))
+	for _, c := range clusters {
+		contributing[c.Cluster] = true
+	}
+	exports := make([]*fleetnetv1alpha1.InternalServiceExport, 0, len(clusters))
+	for i := range internalServiceExports {
+		if contributing[internalServiceExports[i].Spec.ServiceReference.ClusterID] {
+			exports = append(exports, &internalServiceExports[i])
+		}
+	}
+	sort.SliceStable(exports, func(i, j int) bool {
+		a, b := exports[i].Spec.ServiceReference, exports[j].Spec.ServiceReference
+		if !a.ExportedSince.Equal(&b.ExportedSince) {
+			return a.ExportedSince.Before(&b.ExportedSince)
+		}
+		return a.ClusterID < b.ClusterID
+	})
+
+	var conflicts []string
+	merge := func(kind string, merged map[string]string, exported map[string]string) map[string]string {
+		keys := make([]string, 0, len(exported))
+		for key := range exported {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			val, ok := merged[key]
+			switch {
+			case !ok:
+				if merged == nil {
+					merged = make(map[string]string)
+				}
+				merged[key] = exported[key]
+			case val != exported[key]:
+				conflicts = append(conflicts, fmt.Sprintf("%s %s", kind, key))
+			}
+		}
+		return merged
+	}
+	for _, export := range exports {
+		exportedLabels = merge("label", exportedLabels, export.Spec.ExportedLabels)
+		exportedAnnotations = merge("annotation", exportedAnnotations, export.Spec.ExportedAnnotations)
+	}
+
+	if exportedLabels == nil && exportedAnnotations == nil {
+		return nil, nil, nil
+	}
+	if len(conflicts) == 0 {
+		return exportedLabels, exportedAnnotations, &metav1.Condition{
+			Type:               string(fleetnetv1alpha1.ServiceImportExportedMetadataConflict),
+			Status:             metav1.ConditionFalse,
+			Reason:             conditionReasonExportedMetadataConsistent,
+			ObservedGeneration: generation,
+			Message:            "all exporting clusters agree on the exported labels and annotations",
+		}
+	}
+	conflicts = sortedUnique(conflicts)
+	return exportedLabels, exportedAnnotations, &metav1.Condition{
+		Type:               string(fleetnetv1alpha1.ServiceImportExportedMetadataConflict),
+		Status:             metav1.ConditionTrue,
+		Reason:             conditionReasonExportedMetadataMismatch,
+		ObservedGeneration: generation,
+		Message: fmt.Sprintf("exporting clusters disagree on the exported %s; the values of the oldest export are used",
+			strings.Join(conflicts, ", ")),
+	}
+}
+
+// sortedUnique sorts the strings and removes the duplicates.
+func sortedUnique(strs []string) []string {
+	sort.Strings(strs)
+	unique := strs[:0]
+	for i, str := range strs {
+		if i == 0 || str != strs[i-1] {
+			unique = append(unique, str)
+		}
+	}
+	return unique
+}
+
+// namedPortMissingCondition checks that every named port of the ServiceImport is served by the endpoints of each
+// contributing cluster; the endpoints are matched to the published ports by name, as the same named port may be
+// served on different numbers in different clusters. Clusters which export no endpoints are not checked, and no
+// condition is returned if the ServiceImport has no named ports.
+func namedPortMissingCondition(ports []fleetnetv1alpha1.ServicePort, clusters []fleetnetv1alpha1.ServiceImportClusterStatus,
+	endpointSliceExports []fleetnetv1alpha1.EndpointSliceExport, generation int64) *metav1.Condition {
+	var namedPorts []string
+	for _, port := range ports {
+		if port.Name != "" {
+			namedPorts = append(namedPorts, port.Name)
+		}
+	}
+	if len(namedPorts) == 0 || len(clusters) == 0 {
+		return nil
+	}
+
+	servedPorts := make(map[string]map[string]bool, len(clusters))
+	for _, c := range clusters {
+		servedPorts[c.Cluster] = nil
+	}
+	for i := range endpointSliceExports {
+		endpointSliceExport := &endpointSliceExports[i]
+		cluster := endpointSliceExport.Spec.EndpointSliceReference.ClusterID
+		served, ok := servedPorts[cluster]
+		if !ok || endpointSliceExport.DeletionTimestamp != nil || len(endpointSliceExport.Spec.Endpoints) == 0 {
+			continue
+		}
+		if served == nil {
+			served = make(map[string]bool)
+			servedPorts[cluster] = served
+		}
+		for _, port := range endpointSliceExport.Spec.Ports {
+			if port.Name != nil {
+				served[*port.Name] = true
+			}
+		}
+	}
+
+	var missing []string
+	for cluster, served := range servedPorts {
+		if served == nil {
+			continue
+		}
+		var missingPorts []string
+		for _, name := range namedPorts {
+			if !served[name] {
+				missingPorts = append(missingPorts, name)
+			}
+		}
+		if len(missingPorts) > 0 {
+			missing = append(missing, fmt.Sprintf("%s: %s", cluster, strings.Join(missingPorts, ", ")))
+		}
+	}
+
+	if len(missing) == 0 {
+		return &metav1.Condition{
+			Type:               string(fleetnetv1alpha1.ServiceImportNamedPortMissing),
+			Status:             metav1.ConditionFalse,
+			Reason:             conditionReasonNamedPortsServed,
+			ObservedGeneration: generation,
+			Message:            "all contributing clusters serve every named port",
+		}
+	}
+	sort.Strings(missing)
+	return &metav1.Condition{
+		Type:               string(fleetnetv1alpha1.ServiceImportNamedPortMissing),
+		Status:             metav1.ConditionTrue,
+		Reason:             conditionReasonNamedPortMissing,
+		ObservedGeneration: generation,
+		Message:            fmt.Sprintf("some contributing clusters do not serve all the named ports (%s)", strings.Join(missing, "; ")),
+	}
+}
+
+// buildClusterExportSummaries summarizes the exports from every contributing cluster, sorted by cluster name.
+func buildClusterExportSummaries(internalServiceExports []fleetnetv1alpha1.InternalServiceExport,
+	endpointSliceExports []fleetnetv1alpha1.EndpointSliceExport) []fleetnetv1alpha1.ClusterExportSummary {
+	if len(internalServiceExports) == 0 {
+		return nil
+	}
+	endpointCounts := make(map[string]int32)
+	for i := range endpointSliceExports {
+		if endpointSliceExports[i].DeletionTimestamp != nil {
+			continue
+		}
+		cluster := endpointSliceExports[i].Spec.EndpointSliceReference.ClusterID
+		endpointCounts[cluster] += int32(len(endpointSliceExports[i].Spec.Endpoints))
+	}
+
+	summaries := make([]fleetnetv1alpha1.ClusterExportSummary, 0, len(internalServiceExports))
+	for i := range internalServiceExports {
+		internalServiceExport := &internalServiceExports[i]
+		cluster := internalServiceExport.Spec.ServiceReference.ClusterID
+		valid := metav1.ConditionTrue
+		switch {
+		case internalServiceExport.DeletionTimestamp != nil:
+			valid = metav1.ConditionFalse
+		case !controllerutil.ContainsFinalizer(internalServiceExport, objectmeta.InternalServiceExportFinalizer):
+			valid = metav1.ConditionUnknown
+		}
+		conflict := metav1.ConditionUnknown
+		if cond := meta.FindStatusCondition(internalServiceExport.Status.Conditions, string(fleetnetv1alpha1.ServiceExportConflict)); cond != nil {
+			conflict = cond.Status
+		}
+		summaries = append(summaries, fleetnetv1alpha1.ClusterExportSummary{
+			Cluster:   cluster,
+			Valid:     valid,
+			Conflict:  conflict,
+			Endpoints: endpointCounts[cluster],
+		})
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Cluster < summaries[j].Cluster
+	})
+	return summaries
+}
+
+// setClusterExportStatus sets, for every cluster backing a ServiceImport, the number of ready endpoints it exports,
+// the generation of its exported Service, and the last time it propagated a change of the Service or its endpoints.
+func setClusterExportStatus(clusters []fleetnetv1alpha1.ServiceImportClusterStatus, internalServiceExports []fleetnetv1alpha1.InternalServiceExport,
+	endpointSliceExports []fleetnetv1alpha1.EndpointSliceExport) {
+	readyEndpoints := make(map[string]int32)
+	lastPropagationTimes := make(map[string]metav1.Time)
+	updateLastPropagationTime := func(cluster string, t metav1.Time) {
+		if last, ok := lastPropagationTimes[cluster]; t.IsZero() || (ok && !last.Before(&t)) {
+			return
+		}
+		lastPropagationTimes[cluster] = t
+	}
+	for i := range endpointSliceExports {
+		if endpointSliceExports[i].DeletionTimestamp != nil {
+			continue
+		}
+		ref := endpointSliceExports[i].Spec.EndpointSliceReference
+		readyEndpoints[ref.ClusterID] += countReadyEndpoints(endpointSliceExports[i].Spec.Endpoints)
+		updateLastPropagationTime(ref.ClusterID, ref.ExportedSince)
+	}
+	exportGenerations := make(map[string]int64, len(internalServiceExports))
+	for i := range internalServiceExports {
+		ref := internalServiceExports[i].Spec.ServiceReference
+		exportGenerations[ref.ClusterID] = ref.Generation
+		updateLastPropagationTime(ref.ClusterID, ref.ExportedSince)
+	}
+
+	for i := range clusters {
+		cluster := clusters[i].Cluster
+		clusters[i].ReadyEndpoints = readyEndpoints[cluster]
+		clusters[i].ExportGeneration = exportGenerations[cluster]
+		clusters[i].LastPropagationTime = nil
+		if t, ok := lastPropagationTimes[cluster]; ok {
+			clusters[i].LastPropagationTime = &t
+		}
+	}
+}
+
+// countReadyEndpoints returns the number of ready endpoints among the exported ones.
+func countReadyEndpoints(endpoints []fleetnetv1alpha1.Endpoint) int32 {
+	var count int32
+	for i := range endpoints {
+		if endpoints[i].IsReady() {
+			count++
+		}
+	}
+	return count
+}
+
+// buildEndpointDistribution groups the endpoints exported by the given clusters by cluster and zone; each group
+// gets a share of the traffic, in parts per thousand, that is proportional to its healthy endpoint count scaled
+// by the weight of its cluster. Clusters without a weight have the default weight of 1.
+//
+// Only ready endpoints count as healthy ones; the terminating endpoints which are still serving are not.
+func buildEndpointDistribution(clusters []fleetnetv1alpha1.ServiceImportClusterStatus, clusterWeights map[string]int64,
+	endpointSliceExports []fleetnetv1alpha1.EndpointSliceExport) []fleetnetv1alpha1.EndpointDistribution {
+	type clusterZone struct {
+		cluster string
+		zone    string
+	}
+	included := make(map[string]bool, len(clusters))
+	for _, c := range clusters {
+		included[c.Cluster] = true
+	}
+
+	counts := make(map[clusterZone]int32)
+	for i := range endpointSliceExports {
+		endpointSliceExport := &endpointSliceExports[i]
+		cluster := endpointSliceExport.Spec.EndpointSliceReference.ClusterID
+		// Skip endpoints from the clusters that are not (or no longer) backing the ServiceImport, e.g.
+		// clusters whose exports are in conflict.
+		if !included[cluster] || endpointSliceExport.DeletionTimestamp != nil {
+			continue
+		}
+		for _, endpoint := range endpointSliceExport.Spec.Endpoints {
+			if !endpoint.IsReady() {
+				continue
+			}
+			key := clusterZone{cluster: cluster}
+			if endpoint.Zone != nil {
+				key.zone = *endpoint.Zone
+			}
+			counts[key]++
+		}
+	}
+	if len(counts) == 0 {
+		return nil
+	}
+
+	var total int64
+	for key, count := range counts {
+		total += int64(count) * clusterWeight(clusterWeights, key.cluster)
+	}
+	distribution := make([]fleetnetv1alpha1.EndpointDistribution, 0, len(counts))
+	for key, count := range counts {
+		var weight int32
+		if total > 0 {
+			weight = int32(int64(count) * clusterWeight(clusterWeights, key.cluster) * 1000 / total)
+		}
+		distribution = append(distribution, fleetnetv1alpha1.EndpointDistribution{
+			Cluster:          key.cluster,
+			Zone:             key.zone,
+			HealthyEndpoints: count,
+			Weight:           weight,
+		})
+	}
+	sort.Slice(distribution, func(i, j int) bool {
+		if distribution[i].Cluster != distribution[j].Cluster {
+			return distribution[i].Cluster < distribution[j].Cluster
+		}
+		return distribution[i].Zone < distribution[j].Zone
+	})
+	return distribution
+}
+
+// aggregateDNSTTL returns the most conservative, i.e. the minimum, DNS TTL among the given clusters; a cluster
+// that sets no TTL hint contributes the default TTL. It returns nil if there is no cluster.
+func aggregateDNSTTL(clusters []fleetnetv1alpha1.ServiceImportClusterStatus, internalServiceExports []fleetnetv1alpha1.InternalServiceExport,
+	defaultTTL int64) *int64 {
+	if len(clusters) == 0 {
+		return nil
+	}
+	ttls := make(map[string]int64, len(internalServiceExports))
+	for i := range internalServiceExports {
+		if ttl := internalServiceExports[i].Spec.DNSTTLSeconds; ttl != nil && *ttl > 0 {
+			ttls[internalServiceExports[i].Spec.ServiceReference.ClusterID] = *ttl
+		}
+	}
+
+	effective := int64(0)
+	for _, c := range clusters {
+		ttl, ok := ttls[c.Cluster]
+		if !ok {
+			ttl = defaultTTL
+		}
+		if effective == 0 || ttl < effective {
+			effective = ttl
+		}
+	}
+	return &effective
+}
+
+// aggregateSessionAffinity returns the session affinity settings of a ServiceImport, along with the desired
+// SessionAffinityConflict condition, based on the settings exported from the given clusters. When the clusters
+// disagree, the most conservative settings win: client IP based session affinity is used as long as one cluster
+// asks for it, with the minimum timeout among such clusters; a cluster that sets no timeout contributes the
+// Kubernetes default. It returns nil settings and condition if there is no cluster.
+func aggregateSessionAffinity(clusters []fleetnetv1alpha1.ServiceImportClusterStatus, internalServiceExports []fleetnetv1alpha1.InternalServiceExport,
+	generation int64) (corev1.ServiceAffinity, *corev1.SessionAffinityConfig, *metav1.Condition) {
+	if len(clusters) == 0 {
+		return "", nil, nil
+	}
+	exports := make(map[string]*fleetnetv1alpha1.InternalServiceExport, len(internalServiceExports))
+	for i := range internalServiceExports {
+		exports[internalServiceExports[i].Spec.ServiceReference.ClusterID] = &internalServiceExports[i]
+	}
+
+	settings := make([]string, 0, len(clusters))
+	distinct := make(map[string]bool)
+	timeout := int32(0)
+	for _, c := range clusters {
+		setting := string(corev1.ServiceAffinityNone)
+		if export, ok := exports[c.Cluster]; ok && export.Spec.SessionAffinity == corev1.ServiceAffinityClientIP {
+			clusterTimeout := corev1.DefaultClientIPServiceAffinitySeconds
+			if export.Spec.SessionAffinityTimeoutSeconds != nil {
+				clusterTimeout = *export.Spec.SessionAffinityTimeoutSeconds
+			}
+			if timeout == 0 || clusterTimeout < timeout {
+				timeout = clusterTimeout
+			}
+			setting = fmt.Sprintf("%s with a timeout of %ds", corev1.ServiceAffinityClientIP, clusterTimeout)
+		}
+		distinct[setting] = true
+		settings = append(settings, fmt.Sprintf("%s: %s", c.Cluster, setting))
+	}
+
+	affinity := corev1.ServiceAffinityNone
+	var affinityConfig *corev1.SessionAffinityConfig
+	if timeout != 0 {
+		affinity = corev1.ServiceAffinityClientIP
+		affinityConfig = &corev1.SessionAffinityConfig{
+			ClientIP: &corev1.ClientIPConfig{TimeoutSeconds: &timeout},
+		}
+	}
+
+	if len(distinct) == 1 {
+		return affinity, affinityConfig, &metav1.Condition{
+			Type:               string(fleetnetv1alpha1.ServiceImportSessionAffinityConflict),
+			Status:             metav1.ConditionFalse,
+			Reason:             conditionReasonSessionAffinityConsistent,
+			ObservedGeneration: generation,
+			Message:            "all exporting clusters agree on the session affinity settings",
+		}
+	}
+	sort.Strings(settings)
+	return affinity, affinityConfig, &metav1.Condition{
+		Type:               string(fleetnetv1alpha1.ServiceImportSessionAffinityConflict),
+		Status:             metav1.ConditionTrue,
+		Reason:             conditionReasonSessionAffinityMismatch,
+		ObservedGeneration: generation,
+		Message: fmt.Sprintf("exporting clusters disagree on the session affinity settings (%s); the most conservative settings are used",
+			strings.Join(settings, ", ")),
+	}
+}
+
+// applyCanaryPercents rebalances an endpoint distribution so that each canary cluster receives its canary
+// percentage of the traffic, split among its zones by healthy endpoint count, while the other clusters share the
+// rest in the proportions the distribution already gives them. Canary percentages adding up to more than 100 are
+// scaled down to add up to 100.
+//
+// The distribution is left as is unless it has endpoints in both canary and non-canary clusters, as a canary
+// percentage is only meaningful relative to a stable version of the Service.
+func applyCanaryPercents(distribution []fleetnetv1alpha1.EndpointDistribution, canaryPercents map[string]int32) {
+	canaryEndpoints := make(map[string]int64)
+	var stableWeights int64
+	hasStable := false
+	for _, d := range distribution {
+		if _, ok := canaryPercents[d.Cluster]; ok {
+			canaryEndpoints[d.Cluster] += int64(d.HealthyEndpoints)
+			continue
+		}
+		hasStable = true
+		stableWeights += int64(d.Weight)
+	}
+	if len(canaryEndpoints) == 0 || !hasStable {
+		return
+	}
+
+	var totalPercent int64
+	for cluster := range canaryEndpoints {
+		totalPercent += int64(canaryPercents[cluster])
+	}
+	canaryShares := make(map[string]int64, len(canaryEndpoints))
+	var canaryTotal int64
+	for cluster := range canaryEndpoints {
+		share := int64(canaryPercents[cluster]) * 10
+		if totalPercent > 100 {
+			share = int64(canaryPercents[cluster]) * 1000 / totalPercent
+		}
+		canaryShares[cluster] = share
+		canaryTotal += share
+	}
+	stableShare := 1000 - canaryTotal
+
+	for i := range distribution {
+		d := &distribution[i]
+		if share, ok := canaryShares[d.Cluster]; ok {
+			d.Weight = int32(share * int64(d.HealthyEndpoints) / canaryEndpoints[d.Cluster])
+			continue
+		}
+		var weight int32
+		if stableWeights > 0 {
+			weight = int32(stableShare * int64(d.Weight) / stableWeights)
+		}
+		d.Weight = weight
+	}
+}
+
+func clusterWeight(clusterWeights map[string]int64, cluster string) int64 {
+	if weight, ok := clusterWeights[cluster]; ok {
+		return weight
+	}
+	return 1
+}
+
 // SetupWithManager sets up the controller with the Manager.
-func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
+// The index of the endpointSliceExports by service is shared with the EndpointSliceExport controller; it is only
+// created here if disableEndpointSliceExportIndexer is false.
+func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, disableEndpointSliceExportIndexer bool) error {
 	// add index to quickly query internalServiceExport list by service
 	extractFunc := func(o client.Object) []string {
 		name := o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.NamespacedName
@@ -217,8 +1088,80 @@ func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) err
 		klog.ErrorS(err, "Failed to create index", "field", exportedServiceFieldNamespacedName)
 		return err
 	}
+	// add index to quickly query endpointSliceExport list by service
+	if !disableEndpointSliceExportIndexer {
+		extractFunc := func(o client.Object) []string {
+			return []string{o.(*fleetnetv1alpha1.EndpointSliceExport).Spec.OwnerServiceReference.NamespacedName}
+		}
+		if err := mgr.GetFieldIndexer().IndexField(ctx, &fleetnetv1alpha1.EndpointSliceExport{}, endpointSliceExportOwnerSvcNamespacedNameFieldKey, extractFunc); err != nil {
+			klog.ErrorS(err, "Failed to create index", "field", endpointSliceExportOwnerSvcNamespacedNameFieldKey)
+			return err
+		}
+	}
+
+	if r.EndpointDistributionDebounceWindow <= 0 {
+		r.EndpointDistributionDebounceWindow = defaultEndpointDistributionDebounceWindow
+	}
+	if r.DefaultDNSTTLSeconds <= 0 {
+		r.DefaultDNSTTLSeconds = defaultDNSTTLSeconds
+	}
+
+	// Enqueue the ServiceImport after the debounce window when its exported endpoints or exports change; the work
+	// queue de-duplicates the requests added during the window, so that a burst of changes is handled only once.
+	enqueueAfterDebounceWindow := func(q workqueue.TypedRateLimitingInterface[reconcile.Request], o client.Object) {
+		var key types.NamespacedName
+		switch obj := o.(type) {
+		case *fleetnetv1alpha1.EndpointSliceExport:
+			key = types.NamespacedName{
+				Namespace: obj.Spec.OwnerServiceReference.Namespace,
+				Name:      obj.Spec.OwnerServiceReference.Name,
+			}
+		case *fleetnetv1alpha1.InternalServiceExport:
+			key = types.NamespacedName{
+				Namespace: obj.Spec.ServiceReference.Namespace,
+				Name:      obj.Spec.ServiceReference.Name,
+			}
+		default:
+			return
+		}
+		q.AddAfter(reconcile.Request{NamespacedName: key}, r.EndpointDistributionDebounceWindow)
+	}
+	eventHandler := handler.Funcs{
+		CreateFunc: func(_ context.Context, e event.CreateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueueAfterDebounceWindow(q, e.Object)
+		},
+		UpdateFunc: func(_ context.Context, e event.UpdateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueueAfterDebounceWindow(q, e.ObjectNew)
+		},
+		DeleteFunc: func(_ context.Context, e event.DeleteEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueueAfterDebounceWindow(q, e.Object)
+		},
+	}
 
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&fleetnetv1alpha1.ServiceImport{}).
-		Complete(r)
+		Watches(&fleetnetv1alpha1.EndpointSliceExport{}, eventHandler).
+		Watches(&fleetnetv1alpha1.InternalServiceExport{}, eventHandler)
+	if r.DenylistConfigMap.Name != "" || r.RebuildConfigMap.Name != "" {
+		// Re-evaluate all the serviceImports whenever the denylist changes, or the rebuild window is moved.
+		b = b.Watches(&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.enqueueAllServiceImports),
+			builder.WithPredicates(predicate.NewPredicateFuncs(func(o client.Object) bool {
+				return exportdenylist.IsDenylist(o, r.DenylistConfigMap) || rebuild.IsRebuildConfigMap(o, r.RebuildConfigMap)
+			})))
+	}
+	return b.WithOptions(r.ControllerOptions).Complete(metrics.InstrumentReconciler("serviceimport", r))
+}
+
+func (r *Reconciler) enqueueAllServiceImports(ctx context.Context, _ client.Object) []reconcile.Request {
+	serviceImportList := &fleetnetv1alpha1.ServiceImportList{}
+	if err := r.Client.List(ctx, serviceImportList); err != nil {
+		klog.FromContext(ctx).Error(err, "Failed to list serviceImports to apply the changed configMap")
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(serviceImportList.Items))
+	for i := range serviceImportList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&serviceImportList.Items[i])})
+	}
+	return requests
 }
@@ -13,10 +13,12 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
@@ -108,6 +110,11 @@ var _ = Describe("Test ServiceImport Controller", func() {
 			cmpopts.IgnoreFields(metav1.ObjectMeta{}, "ResourceVersion"),
 			cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime"),
 			cmpopts.IgnoreFields(metav1.ObjectMeta{}, "ManagedFields"),
+			// The cluster export summaries depend on the order in which the exports are processed; they are
+			// covered by the unit tests.
+			cmpopts.IgnoreFields(fleetnetv1alpha1.ServiceImportStatus{}, "ClusterExportSummaries"),
+			// The session affinity conditions are covered by the unit tests.
+			cmpopts.IgnoreFields(fleetnetv1alpha1.ServiceImportStatus{}, "Conditions"),
 		}
 	)
 
@@ -271,13 +278,15 @@ var _ = Describe("Test ServiceImport Controller", func() {
 					return err.Error()
 				}
 				want := fleetnetv1alpha1.ServiceImportStatus{
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 						{
 							Cluster: testClusterID,
 						},
 					},
-					Type:  fleetnetv1alpha1.ClusterSetIP,
-					Ports: internalServiceExportA.Spec.Ports,
+					Type:            fleetnetv1alpha1.ClusterSetIP,
+					Ports:           internalServiceExportA.Spec.Ports,
+					DNSTTLSeconds:   ptr.To(int64(defaultDNSTTLSeconds)),
+					SessionAffinity: corev1.ServiceAffinityNone,
 				}
 				if len(serviceImport.Status.Clusters) != 1 {
 					return fmt.Sprintf("got %v cluster, want 1", len(serviceImport.Status.Clusters))
@@ -285,13 +294,15 @@ var _ = Describe("Test ServiceImport Controller", func() {
 				resolvedClusterID = serviceImport.Status.Clusters[0].Cluster
 				if resolvedClusterID != testClusterID {
 					want = fleetnetv1alpha1.ServiceImportStatus{
-						Clusters: []fleetnetv1alpha1.ClusterStatus{
+						Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 							{
 								Cluster: "member-cluster-b",
 							},
 						},
-						Type:  fleetnetv1alpha1.ClusterSetIP,
-						Ports: internalServiceExportB.Spec.Ports,
+						Type:            fleetnetv1alpha1.ClusterSetIP,
+						Ports:           internalServiceExportB.Spec.Ports,
+						DNSTTLSeconds:   ptr.To(int64(defaultDNSTTLSeconds)),
+						SessionAffinity: corev1.ServiceAffinityNone,
 					}
 				}
 				return cmp.Diff(want, serviceImport.Status, options...)
@@ -426,12 +437,14 @@ var _ = Describe("Test ServiceImport Controller", func() {
 				}
 				want := fleetnetv1alpha1.ServiceImportStatus{
 					Ports: internalServiceExportA.Spec.Ports,
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 						{
 							Cluster: testClusterID,
 						},
 					},
-					Type: fleetnetv1alpha1.ClusterSetIP,
+					Type:            fleetnetv1alpha1.ClusterSetIP,
+					DNSTTLSeconds:   ptr.To(int64(defaultDNSTTLSeconds)),
+					SessionAffinity: corev1.ServiceAffinityNone,
 				}
 				return cmp.Diff(want, serviceImport.Status, options...)
 			}, timeout, interval).Should(BeEmpty())
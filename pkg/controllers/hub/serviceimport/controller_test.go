@@ -0,0 +1,1607 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package serviceimport
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1beta1 "go.goms.io/fleet/apis/cluster/v1beta1"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/conflictresolution"
+	"go.goms.io/fleet-networking/pkg/common/explain"
+	"go.goms.io/fleet-networking/pkg/common/exportdenylist"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/rebuild"
+)
+
+func endpointSliceExport(cluster string, zones ...*string) fleetnetv1alpha1.EndpointSliceExport {
+	endpoints := make([]fleetnetv1alpha1.Endpoint, 0, len(zones))
+	for _, zone := range zones {
+		endpoints = append(endpoints, fleetnetv1alpha1.Endpoint{
+			Addresses: []string{"1.2.3.4"},
+			Zone:      zone,
+		})
+	}
+	return fleetnetv1alpha1.EndpointSliceExport{
+		Spec: fleetnetv1alpha1.EndpointSliceExportSpec{
+			Endpoints: endpoints,
+			EndpointSliceReference: fleetnetv1alpha1.ExportedObjectReference{
+				ClusterID: cluster,
+			},
+		},
+	}
+}
+
+// terminatingEndpointSliceExport returns an EndpointSliceExport of terminating endpoints which are still serving.
+func terminatingEndpointSliceExport(cluster string, zones ...*string) fleetnetv1alpha1.EndpointSliceExport {
+	export := endpointSliceExport(cluster, zones...)
+	for i := range export.Spec.Endpoints {
+		export.Spec.Endpoints[i].Conditions = discoveryv1.EndpointConditions{
+			Ready:       ptr.To(false),
+			Serving:     ptr.To(true),
+			Terminating: ptr.To(true),
+		}
+	}
+	return export
+}
+
+func TestApplyCanaryPercents(t *testing.T) {
+	zone1 := ptr.To("zone-1")
+	zone2 := ptr.To("zone-2")
+	twoClusters := []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "stable"}, {Cluster: "canary"}}
+	twoClusterExports := []fleetnetv1alpha1.EndpointSliceExport{
+		endpointSliceExport("stable", zone1, zone2),
+		endpointSliceExport("canary", zone1, zone2),
+	}
+	twoClusterDistribution := func(stableWeight, canaryWeight int32) []fleetnetv1alpha1.EndpointDistribution {
+		return []fleetnetv1alpha1.EndpointDistribution{
+			{Cluster: "canary", Zone: "zone-1", HealthyEndpoints: 1, Weight: canaryWeight},
+			{Cluster: "canary", Zone: "zone-2", HealthyEndpoints: 1, Weight: canaryWeight},
+			{Cluster: "stable", Zone: "zone-1", HealthyEndpoints: 1, Weight: stableWeight},
+			{Cluster: "stable", Zone: "zone-2", HealthyEndpoints: 1, Weight: stableWeight},
+		}
+	}
+
+	testCases := []struct {
+		name                 string
+		clusters             []fleetnetv1alpha1.ServiceImportClusterStatus
+		clusterWeights       map[string]int64
+		canaryPercents       map[string]int32
+		endpointSliceExports []fleetnetv1alpha1.EndpointSliceExport
+		want                 []fleetnetv1alpha1.EndpointDistribution
+	}{
+		{
+			name:                 "no canary",
+			clusters:             twoClusters,
+			endpointSliceExports: twoClusterExports,
+			want:                 twoClusterDistribution(250, 250),
+		},
+		{
+			name:                 "canary at 0 percent",
+			clusters:             twoClusters,
+			canaryPercents:       map[string]int32{"canary": 0},
+			endpointSliceExports: twoClusterExports,
+			want:                 twoClusterDistribution(500, 0),
+		},
+		{
+			name:                 "canary at 10 percent",
+			clusters:             twoClusters,
+			canaryPercents:       map[string]int32{"canary": 10},
+			endpointSliceExports: twoClusterExports,
+			want:                 twoClusterDistribution(450, 50),
+		},
+		{
+			name:                 "canary at 50 percent",
+			clusters:             twoClusters,
+			canaryPercents:       map[string]int32{"canary": 50},
+			endpointSliceExports: twoClusterExports,
+			want:                 twoClusterDistribution(250, 250),
+		},
+		{
+			name:                 "canary at 80 percent",
+			clusters:             twoClusters,
+			canaryPercents:       map[string]int32{"canary": 80},
+			endpointSliceExports: twoClusterExports,
+			want:                 twoClusterDistribution(100, 400),
+		},
+		{
+			name:                 "canary at 100 percent",
+			clusters:             twoClusters,
+			canaryPercents:       map[string]int32{"canary": 100},
+			endpointSliceExports: twoClusterExports,
+			want:                 twoClusterDistribution(0, 500),
+		},
+		{
+			name:           "canary share is independent of the endpoint count",
+			clusters:       twoClusters,
+			canaryPercents: map[string]int32{"canary": 20},
+			endpointSliceExports: []fleetnetv1alpha1.EndpointSliceExport{
+				endpointSliceExport("stable", zone1),
+				endpointSliceExport("canary", zone1, zone1, zone1, zone2),
+			},
+			want: []fleetnetv1alpha1.EndpointDistribution{
+				{Cluster: "canary", Zone: "zone-1", HealthyEndpoints: 3, Weight: 150},
+				{Cluster: "canary", Zone: "zone-2", HealthyEndpoints: 1, Weight: 50},
+				{Cluster: "stable", Zone: "zone-1", HealthyEndpoints: 1, Weight: 800},
+			},
+		},
+		{
+			name:           "stable clusters keep their weighted proportions",
+			clusters:       []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "stable-1"}, {Cluster: "stable-2"}, {Cluster: "canary"}},
+			clusterWeights: map[string]int64{"stable-1": 1, "stable-2": 3},
+			canaryPercents: map[string]int32{"canary": 20},
+			endpointSliceExports: []fleetnetv1alpha1.EndpointSliceExport{
+				endpointSliceExport("stable-1", zone1),
+				endpointSliceExport("stable-2", zone1),
+				endpointSliceExport("canary", zone1),
+			},
+			want: []fleetnetv1alpha1.EndpointDistribution{
+				{Cluster: "canary", Zone: "zone-1", HealthyEndpoints: 1, Weight: 200},
+				{Cluster: "stable-1", Zone: "zone-1", HealthyEndpoints: 1, Weight: 200},
+				{Cluster: "stable-2", Zone: "zone-1", HealthyEndpoints: 1, Weight: 600},
+			},
+		},
+		{
+			name:           "canary percentages over 100 in total are scaled down",
+			clusters:       []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "stable"}, {Cluster: "canary-1"}, {Cluster: "canary-2"}},
+			canaryPercents: map[string]int32{"canary-1": 60, "canary-2": 90},
+			endpointSliceExports: []fleetnetv1alpha1.EndpointSliceExport{
+				endpointSliceExport("stable", zone1),
+				endpointSliceExport("canary-1", zone1),
+				endpointSliceExport("canary-2", zone1),
+			},
+			want: []fleetnetv1alpha1.EndpointDistribution{
+				{Cluster: "canary-1", Zone: "zone-1", HealthyEndpoints: 1, Weight: 400},
+				{Cluster: "canary-2", Zone: "zone-1", HealthyEndpoints: 1, Weight: 600},
+				{Cluster: "stable", Zone: "zone-1", HealthyEndpoints: 1, Weight: 0},
+			},
+		},
+		{
+			name:           "canary without stable clusters",
+			clusters:       []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "canary"}},
+			canaryPercents: map[string]int32{"canary": 10},
+			endpointSliceExports: []fleetnetv1alpha1.EndpointSliceExport{
+				endpointSliceExport("canary", zone1),
+			},
+			want: []fleetnetv1alpha1.EndpointDistribution{
+				{Cluster: "canary", Zone: "zone-1", HealthyEndpoints: 1, Weight: 1000},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := buildEndpointDistribution(tc.clusters, tc.clusterWeights, tc.endpointSliceExports)
+			applyCanaryPercents(got, tc.canaryPercents)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("applyCanaryPercents() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBuildEndpointDistribution(t *testing.T) {
+	zone1 := ptr.To("zone-1")
+	zone2 := ptr.To("zone-2")
+	testCases := []struct {
+		name                 string
+		clusters             []fleetnetv1alpha1.ServiceImportClusterStatus
+		clusterWeights       map[string]int64
+		endpointSliceExports []fleetnetv1alpha1.EndpointSliceExport
+		want                 []fleetnetv1alpha1.EndpointDistribution
+	}{
+		{
+			name:     "no endpoints",
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-1"}},
+		},
+		{
+			name:     "multiple zones in multiple clusters with default weights",
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-1"}, {Cluster: "member-2"}},
+			endpointSliceExports: []fleetnetv1alpha1.EndpointSliceExport{
+				endpointSliceExport("member-2", zone1),
+				endpointSliceExport("member-1", zone1, zone2, zone2),
+				endpointSliceExport("member-1", zone2),
+			},
+			want: []fleetnetv1alpha1.EndpointDistribution{
+				{Cluster: "member-1", Zone: "zone-1", HealthyEndpoints: 1, Weight: 200},
+				{Cluster: "member-1", Zone: "zone-2", HealthyEndpoints: 3, Weight: 600},
+				{Cluster: "member-2", Zone: "zone-1", HealthyEndpoints: 1, Weight: 200},
+			},
+		},
+		{
+			name:           "cluster weights scale the shares",
+			clusters:       []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-1"}, {Cluster: "member-2"}},
+			clusterWeights: map[string]int64{"member-1": 1, "member-2": 3},
+			endpointSliceExports: []fleetnetv1alpha1.EndpointSliceExport{
+				endpointSliceExport("member-1", zone1, zone2),
+				endpointSliceExport("member-2", nil, zone1),
+			},
+			want: []fleetnetv1alpha1.EndpointDistribution{
+				{Cluster: "member-1", Zone: "zone-1", HealthyEndpoints: 1, Weight: 125},
+				{Cluster: "member-1", Zone: "zone-2", HealthyEndpoints: 1, Weight: 125},
+				{Cluster: "member-2", HealthyEndpoints: 1, Weight: 375},
+				{Cluster: "member-2", Zone: "zone-1", HealthyEndpoints: 1, Weight: 375},
+			},
+		},
+		{
+			name:           "zero weighted clusters",
+			clusters:       []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-1"}},
+			clusterWeights: map[string]int64{"member-1": 0},
+			endpointSliceExports: []fleetnetv1alpha1.EndpointSliceExport{
+				endpointSliceExport("member-1", zone1),
+			},
+			want: []fleetnetv1alpha1.EndpointDistribution{
+				{Cluster: "member-1", Zone: "zone-1", HealthyEndpoints: 1, Weight: 0},
+			},
+		},
+		{
+			name:     "endpoints from the clusters not backing the service are ignored",
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-1"}},
+			endpointSliceExports: []fleetnetv1alpha1.EndpointSliceExport{
+				endpointSliceExport("member-1", zone1, zone1),
+				endpointSliceExport("member-3", zone1),
+			},
+			want: []fleetnetv1alpha1.EndpointDistribution{
+				{Cluster: "member-1", Zone: "zone-1", HealthyEndpoints: 2, Weight: 1000},
+			},
+		},
+		{
+			name:     "terminating endpoints are not healthy",
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-1"}},
+			endpointSliceExports: []fleetnetv1alpha1.EndpointSliceExport{
+				terminatingEndpointSliceExport("member-1", zone1),
+				endpointSliceExport("member-1", zone1),
+			},
+			want: []fleetnetv1alpha1.EndpointDistribution{
+				{Cluster: "member-1", Zone: "zone-1", HealthyEndpoints: 1, Weight: 1000},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := buildEndpointDistribution(tc.clusters, tc.clusterWeights, tc.endpointSliceExports)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("buildEndpointDistribution() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func internalServiceExport(cluster string, ttl *int64) fleetnetv1alpha1.InternalServiceExport {
+	return fleetnetv1alpha1.InternalServiceExport{
+		Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+			ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+				ClusterID: cluster,
+			},
+			DNSTTLSeconds: ttl,
+		},
+	}
+}
+
+func TestAggregateDNSTTL(t *testing.T) {
+	testCases := []struct {
+		name                   string
+		clusters               []fleetnetv1alpha1.ServiceImportClusterStatus
+		internalServiceExports []fleetnetv1alpha1.InternalServiceExport
+		want                   *int64
+	}{
+		{
+			name: "no clusters",
+			internalServiceExports: []fleetnetv1alpha1.InternalServiceExport{
+				internalServiceExport("member-1", ptr.To(int64(10))),
+			},
+		},
+		{
+			name:     "no ttl hints",
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-1"}, {Cluster: "member-2"}},
+			internalServiceExports: []fleetnetv1alpha1.InternalServiceExport{
+				internalServiceExport("member-1", nil),
+				internalServiceExport("member-2", nil),
+			},
+			want: ptr.To(int64(30)),
+		},
+		{
+			name:     "members disagree",
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-1"}, {Cluster: "member-2"}},
+			internalServiceExports: []fleetnetv1alpha1.InternalServiceExport{
+				internalServiceExport("member-1", ptr.To(int64(20))),
+				internalServiceExport("member-2", ptr.To(int64(10))),
+			},
+			want: ptr.To(int64(10)),
+		},
+		{
+			name:     "members without hints contribute the default",
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-1"}, {Cluster: "member-2"}},
+			internalServiceExports: []fleetnetv1alpha1.InternalServiceExport{
+				internalServiceExport("member-1", ptr.To(int64(120))),
+				internalServiceExport("member-2", nil),
+			},
+			want: ptr.To(int64(30)),
+		},
+		{
+			name:     "hints from the clusters not backing the service are ignored",
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-1"}},
+			internalServiceExports: []fleetnetv1alpha1.InternalServiceExport{
+				internalServiceExport("member-1", ptr.To(int64(15))),
+				internalServiceExport("member-3", ptr.To(int64(5))),
+			},
+			want: ptr.To(int64(15)),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := aggregateDNSTTL(tc.clusters, tc.internalServiceExports, 30)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("aggregateDNSTTL() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func sessionAffinityExport(cluster string, affinity corev1.ServiceAffinity, timeout *int32) fleetnetv1alpha1.InternalServiceExport {
+	export := internalServiceExport(cluster, nil)
+	export.Spec.SessionAffinity = affinity
+	export.Spec.SessionAffinityTimeoutSeconds = timeout
+	return export
+}
+
+func TestAggregateSessionAffinity(t *testing.T) {
+	clientIPConfig := func(timeout int32) *corev1.SessionAffinityConfig {
+		return &corev1.SessionAffinityConfig{ClientIP: &corev1.ClientIPConfig{TimeoutSeconds: ptr.To(timeout)}}
+	}
+	consistentCond := &metav1.Condition{
+		Type:               string(fleetnetv1alpha1.ServiceImportSessionAffinityConflict),
+		Status:             metav1.ConditionFalse,
+		Reason:             conditionReasonSessionAffinityConsistent,
+		ObservedGeneration: 3,
+		Message:            "all exporting clusters agree on the session affinity settings",
+	}
+	conflictCond := func(message string) *metav1.Condition {
+		return &metav1.Condition{
+			Type:               string(fleetnetv1alpha1.ServiceImportSessionAffinityConflict),
+			Status:             metav1.ConditionTrue,
+			Reason:             conditionReasonSessionAffinityMismatch,
+			ObservedGeneration: 3,
+			Message:            message,
+		}
+	}
+
+	testCases := []struct {
+		name                   string
+		clusters               []fleetnetv1alpha1.ServiceImportClusterStatus
+		internalServiceExports []fleetnetv1alpha1.InternalServiceExport
+		wantAffinity           corev1.ServiceAffinity
+		wantConfig             *corev1.SessionAffinityConfig
+		wantCond               *metav1.Condition
+	}{
+		{
+			name: "no clusters",
+			internalServiceExports: []fleetnetv1alpha1.InternalServiceExport{
+				sessionAffinityExport("member-1", corev1.ServiceAffinityClientIP, ptr.To(int32(60))),
+			},
+		},
+		{
+			name:     "members agree on no session affinity",
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-1"}, {Cluster: "member-2"}},
+			internalServiceExports: []fleetnetv1alpha1.InternalServiceExport{
+				sessionAffinityExport("member-1", corev1.ServiceAffinityNone, nil),
+				sessionAffinityExport("member-2", "", nil),
+			},
+			wantAffinity: corev1.ServiceAffinityNone,
+			wantCond:     consistentCond,
+		},
+		{
+			name:     "members agree on the timeout",
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-1"}, {Cluster: "member-2"}},
+			internalServiceExports: []fleetnetv1alpha1.InternalServiceExport{
+				sessionAffinityExport("member-1", corev1.ServiceAffinityClientIP, ptr.To(int32(60))),
+				sessionAffinityExport("member-2", corev1.ServiceAffinityClientIP, ptr.To(int32(60))),
+			},
+			wantAffinity: corev1.ServiceAffinityClientIP,
+			wantConfig:   clientIPConfig(60),
+			wantCond:     consistentCond,
+		},
+		{
+			name:     "members without a timeout contribute the kubernetes default",
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-1"}, {Cluster: "member-2"}},
+			internalServiceExports: []fleetnetv1alpha1.InternalServiceExport{
+				sessionAffinityExport("member-1", corev1.ServiceAffinityClientIP, ptr.To(corev1.DefaultClientIPServiceAffinitySeconds)),
+				sessionAffinityExport("member-2", corev1.ServiceAffinityClientIP, nil),
+			},
+			wantAffinity: corev1.ServiceAffinityClientIP,
+			wantConfig:   clientIPConfig(corev1.DefaultClientIPServiceAffinitySeconds),
+			wantCond:     consistentCond,
+		},
+		{
+			name:     "members disagree on the timeout",
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-2"}, {Cluster: "member-1"}},
+			internalServiceExports: []fleetnetv1alpha1.InternalServiceExport{
+				sessionAffinityExport("member-1", corev1.ServiceAffinityClientIP, ptr.To(int32(300))),
+				sessionAffinityExport("member-2", corev1.ServiceAffinityClientIP, ptr.To(int32(60))),
+			},
+			wantAffinity: corev1.ServiceAffinityClientIP,
+			wantConfig:   clientIPConfig(60),
+			wantCond: conflictCond("exporting clusters disagree on the session affinity settings " +
+				"(member-1: ClientIP with a timeout of 300s, member-2: ClientIP with a timeout of 60s); the most conservative settings are used"),
+		},
+		{
+			name:     "members disagree on the session affinity",
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-1"}, {Cluster: "member-2"}},
+			internalServiceExports: []fleetnetv1alpha1.InternalServiceExport{
+				sessionAffinityExport("member-1", corev1.ServiceAffinityNone, nil),
+				sessionAffinityExport("member-2", corev1.ServiceAffinityClientIP, ptr.To(int32(600))),
+			},
+			wantAffinity: corev1.ServiceAffinityClientIP,
+			wantConfig:   clientIPConfig(600),
+			wantCond: conflictCond("exporting clusters disagree on the session affinity settings " +
+				"(member-1: None, member-2: ClientIP with a timeout of 600s); the most conservative settings are used"),
+		},
+		{
+			name:     "settings from the clusters not backing the service are ignored",
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-1"}},
+			internalServiceExports: []fleetnetv1alpha1.InternalServiceExport{
+				sessionAffinityExport("member-1", corev1.ServiceAffinityClientIP, ptr.To(int32(120))),
+				sessionAffinityExport("member-3", corev1.ServiceAffinityClientIP, ptr.To(int32(5))),
+			},
+			wantAffinity: corev1.ServiceAffinityClientIP,
+			wantConfig:   clientIPConfig(120),
+			wantCond:     consistentCond,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotAffinity, gotConfig, gotCond := aggregateSessionAffinity(tc.clusters, tc.internalServiceExports, 3)
+			if gotAffinity != tc.wantAffinity {
+				t.Errorf("aggregateSessionAffinity() affinity = %q, want %q", gotAffinity, tc.wantAffinity)
+			}
+			if diff := cmp.Diff(tc.wantConfig, gotConfig); diff != "" {
+				t.Errorf("aggregateSessionAffinity() config mismatch (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantCond, gotCond); diff != "" {
+				t.Errorf("aggregateSessionAffinity() condition mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBuildClusterExportSummaries(t *testing.T) {
+	deletionTimestamp := metav1.Now()
+	conflictCondition := func(status metav1.ConditionStatus) []metav1.Condition {
+		return []metav1.Condition{
+			{
+				Type:   string(fleetnetv1alpha1.ServiceExportConflict),
+				Status: status,
+			},
+		}
+	}
+	internalServiceExports := []fleetnetv1alpha1.InternalServiceExport{
+		{
+			// An export in conflict.
+			ObjectMeta: metav1.ObjectMeta{
+				Finalizers: []string{objectmeta.InternalServiceExportFinalizer},
+			},
+			Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+				ServiceReference: fleetnetv1alpha1.ExportedObjectReference{ClusterID: "member-3"},
+			},
+			Status: fleetnetv1alpha1.InternalServiceExportStatus{
+				Conditions: conflictCondition(metav1.ConditionTrue),
+			},
+		},
+		{
+			// A healthy export.
+			ObjectMeta: metav1.ObjectMeta{
+				Finalizers: []string{objectmeta.InternalServiceExportFinalizer},
+			},
+			Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+				ServiceReference: fleetnetv1alpha1.ExportedObjectReference{ClusterID: "member-1"},
+			},
+			Status: fleetnetv1alpha1.InternalServiceExportStatus{
+				Conditions: conflictCondition(metav1.ConditionFalse),
+			},
+		},
+		{
+			// An export pending processing.
+			Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+				ServiceReference: fleetnetv1alpha1.ExportedObjectReference{ClusterID: "member-2"},
+			},
+		},
+		{
+			// An export being withdrawn.
+			ObjectMeta: metav1.ObjectMeta{
+				DeletionTimestamp: &deletionTimestamp,
+				Finalizers:        []string{objectmeta.InternalServiceExportFinalizer},
+			},
+			Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+				ServiceReference: fleetnetv1alpha1.ExportedObjectReference{ClusterID: "member-4"},
+			},
+			Status: fleetnetv1alpha1.InternalServiceExportStatus{
+				Conditions: conflictCondition(metav1.ConditionFalse),
+			},
+		},
+	}
+	deletedEndpointSliceExport := endpointSliceExport("member-1", nil)
+	deletedEndpointSliceExport.DeletionTimestamp = &deletionTimestamp
+	endpointSliceExports := []fleetnetv1alpha1.EndpointSliceExport{
+		endpointSliceExport("member-1", nil, nil),
+		endpointSliceExport("member-1", nil),
+		deletedEndpointSliceExport,
+		endpointSliceExport("member-4", nil),
+	}
+
+	want := []fleetnetv1alpha1.ClusterExportSummary{
+		{Cluster: "member-1", Valid: metav1.ConditionTrue, Conflict: metav1.ConditionFalse, Endpoints: 3},
+		{Cluster: "member-2", Valid: metav1.ConditionUnknown, Conflict: metav1.ConditionUnknown},
+		{Cluster: "member-3", Valid: metav1.ConditionTrue, Conflict: metav1.ConditionTrue},
+		{Cluster: "member-4", Valid: metav1.ConditionFalse, Conflict: metav1.ConditionFalse, Endpoints: 1},
+	}
+	got := buildClusterExportSummaries(internalServiceExports, endpointSliceExports)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("buildClusterExportSummaries() mismatch (-want, +got):\n%s", diff)
+	}
+
+	if got := buildClusterExportSummaries(nil, endpointSliceExports); got != nil {
+		t.Errorf("buildClusterExportSummaries() with no exports = %v, want nil", got)
+	}
+}
+
+// TestSetClusterExportStatus tests the setClusterExportStatus function.
+func TestSetClusterExportStatus(t *testing.T) {
+	exportTime := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	endpointsTime := metav1.NewTime(exportTime.Add(time.Minute))
+	deletionTimestamp := metav1.Now()
+	internalServiceExport := func(cluster string, generation int64) fleetnetv1alpha1.InternalServiceExport {
+		return fleetnetv1alpha1.InternalServiceExport{
+			Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+				ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+					ClusterID:     cluster,
+					Generation:    generation,
+					ExportedSince: exportTime,
+				},
+			},
+		}
+	}
+	internalServiceExports := []fleetnetv1alpha1.InternalServiceExport{
+		internalServiceExport("member-1", 3),
+		internalServiceExport("member-2", 1),
+		internalServiceExport("member-3", 2),
+	}
+	updatedEndpointSliceExport := endpointSliceExport("member-1", nil, nil)
+	updatedEndpointSliceExport.Spec.EndpointSliceReference.ExportedSince = endpointsTime
+	deletedEndpointSliceExport := endpointSliceExport("member-2", nil)
+	deletedEndpointSliceExport.Spec.EndpointSliceReference.ExportedSince = endpointsTime
+	deletedEndpointSliceExport.DeletionTimestamp = &deletionTimestamp
+	endpointSliceExports := []fleetnetv1alpha1.EndpointSliceExport{
+		updatedEndpointSliceExport,
+		endpointSliceExport("member-1", nil),
+		deletedEndpointSliceExport,
+		endpointSliceExport("member-3", nil),
+		terminatingEndpointSliceExport("member-1", nil),
+	}
+
+	// member-3 is not backing the ServiceImport; member-4 has no export left.
+	clusters := []fleetnetv1alpha1.ServiceImportClusterStatus{
+		{Cluster: "member-1"},
+		{Cluster: "member-2"},
+		{Cluster: "member-4", ReadyEndpoints: 2, ExportGeneration: 1, LastPropagationTime: &exportTime},
+	}
+	setClusterExportStatus(clusters, internalServiceExports, endpointSliceExports)
+	want := []fleetnetv1alpha1.ServiceImportClusterStatus{
+		{Cluster: "member-1", ReadyEndpoints: 3, ExportGeneration: 3, LastPropagationTime: &endpointsTime},
+		{Cluster: "member-2", ExportGeneration: 1, LastPropagationTime: &exportTime},
+		{Cluster: "member-4"},
+	}
+	if diff := cmp.Diff(want, clusters); diff != "" {
+		t.Errorf("setClusterExportStatus() mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+// TestReconcile_Denied tests that the serviceImport of a service denied by the hub is deleted, even though the
+// exports of the service are still present.
+func TestReconcile_Denied(t *testing.T) {
+	ctx := context.Background()
+	denylistKey := types.NamespacedName{Namespace: "fleet-system", Name: "export-denylist"}
+	denylist := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: denylistKey.Namespace,
+			Name:      denylistKey.Name,
+		},
+		Data: map[string]string{
+			exportdenylist.PatternsKey: "work/secrets",
+		},
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "work",
+			Name:      "secrets",
+		},
+	}
+	internalSvcExport := &fleetnetv1alpha1.InternalServiceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  "member-1-ns",
+			Name:       "work-secrets",
+			Finalizers: []string{objectmeta.InternalServiceExportFinalizer},
+		},
+		Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+			Ports: []fleetnetv1alpha1.ServicePort{{Protocol: corev1.ProtocolTCP, Port: 443}},
+			ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+				ClusterID:      "member-1",
+				Kind:           "Service",
+				Namespace:      "work",
+				Name:           "secrets",
+				NamespacedName: "work/secrets",
+			},
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(denylist, serviceImport, internalSvcExport).
+		Build()
+	r := &Reconciler{
+		Client:            fakeClient,
+		Recorder:          record.NewFakeRecorder(10),
+		DenylistConfigMap: denylistKey,
+	}
+
+	name := types.NamespacedName{Namespace: "work", Name: "secrets"}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: name}); err != nil {
+		t.Fatalf("Reconcile() = %v, want no error", err)
+	}
+	if err := fakeClient.Get(ctx, name, &fleetnetv1alpha1.ServiceImport{}); !errors.IsNotFound(err) {
+		t.Errorf("ServiceImport Get() = %v, want NotFound", err)
+	}
+	if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(internalSvcExport), &fleetnetv1alpha1.InternalServiceExport{}); err != nil {
+		t.Errorf("InternalServiceExport Get() = %v, want no error", err)
+	}
+}
+
+// TestReconcile_Paused tests that a serviceImport is left as it is, instead of being deleted as it has no exports,
+// while its reconciliation is paused.
+func TestReconcile_Paused(t *testing.T) {
+	ctx := context.Background()
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "work",
+			Name:        "app",
+			Annotations: map[string]string{objectmeta.ObjectAnnotationPaused: "true"},
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(serviceImport).
+		WithStatusSubresource(serviceImport).
+		Build()
+	r := &Reconciler{
+		Client:   fakeClient,
+		Recorder: record.NewFakeRecorder(10),
+	}
+
+	name := types.NamespacedName{Namespace: "work", Name: "app"}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: name}); err != nil {
+		t.Fatalf("Reconcile() = %v, want no error", err)
+	}
+	got := &fleetnetv1alpha1.ServiceImport{}
+	if err := fakeClient.Get(ctx, name, got); err != nil {
+		t.Fatalf("ServiceImport Get() = %v, want no error", err)
+	}
+	if !meta.IsStatusConditionTrue(got.Status.Conditions, string(fleetnetv1alpha1.ServiceImportPaused)) {
+		t.Errorf("ServiceImport conditions = %+v, want the paused condition", got.Status.Conditions)
+	}
+}
+
+// TestReconcile_Results tests that the outcome of the reconciliation of a serviceImport is recorded, and forgotten
+// once the serviceImport is gone.
+func TestReconcile_Results(t *testing.T) {
+	ctx := context.Background()
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "work",
+			Name:        "app",
+			Annotations: map[string]string{objectmeta.ObjectAnnotationPaused: "true"},
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(serviceImport).
+		WithStatusSubresource(serviceImport).
+		Build()
+	r := &Reconciler{
+		Client:           fakeClient,
+		Recorder:         record.NewFakeRecorder(10),
+		ReconcileResults: explain.NewResults(),
+	}
+
+	name := types.NamespacedName{Namespace: "work", Name: "app"}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: name}); err != nil {
+		t.Fatalf("Reconcile() = %v, want no error", err)
+	}
+	if _, ok := r.ReconcileResults.Get(name); !ok {
+		t.Fatalf("ReconcileResults.Get() = _, false, want true")
+	}
+
+	if err := fakeClient.Delete(ctx, serviceImport); err != nil {
+		t.Fatalf("ServiceImport Delete() = %v, want no error", err)
+	}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: name}); err != nil {
+		t.Fatalf("Reconcile() = %v, want no error", err)
+	}
+	if _, ok := r.ReconcileResults.Get(name); ok {
+		t.Errorf("ReconcileResults.Get() of a deleted serviceImport = _, true, want false")
+	}
+}
+
+// TestReconcile_Headless tests that the type of the serviceImport follows the exported Services, and that a
+// headless Service is in conflict with a Service with a cluster IP.
+func TestReconcile_Headless(t *testing.T) {
+	internalSvcExport := func(cluster string, headless bool) *fleetnetv1alpha1.InternalServiceExport {
+		return &fleetnetv1alpha1.InternalServiceExport{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:  cluster + "-ns",
+				Name:       "work-web",
+				Finalizers: []string{objectmeta.InternalServiceExportFinalizer},
+			},
+			Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+				Ports:    []fleetnetv1alpha1.ServicePort{{Protocol: corev1.ProtocolTCP, Port: 80}},
+				Headless: headless,
+				ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+					ClusterID:      cluster,
+					Kind:           "Service",
+					Namespace:      "work",
+					Name:           "web",
+					NamespacedName: "work/web",
+				},
+			},
+		}
+	}
+	testCases := []struct {
+		name           string
+		exports        []*fleetnetv1alpha1.InternalServiceExport
+		wantType       fleetnetv1alpha1.ServiceImportType
+		wantClusters   []string
+		wantConflicted []string
+	}{
+		{
+			name:         "headless services",
+			exports:      []*fleetnetv1alpha1.InternalServiceExport{internalSvcExport("member-1", true), internalSvcExport("member-2", true)},
+			wantType:     fleetnetv1alpha1.Headless,
+			wantClusters: []string{"member-1", "member-2"},
+		},
+		{
+			name:         "services with cluster IPs",
+			exports:      []*fleetnetv1alpha1.InternalServiceExport{internalSvcExport("member-1", false), internalSvcExport("member-2", false)},
+			wantType:     fleetnetv1alpha1.ClusterSetIP,
+			wantClusters: []string{"member-1", "member-2"},
+		},
+		{
+			name:           "headless service is in conflict with service with a cluster IP",
+			exports:        []*fleetnetv1alpha1.InternalServiceExport{internalSvcExport("member-1", true), internalSvcExport("member-2", false)},
+			wantType:       fleetnetv1alpha1.Headless,
+			wantClusters:   []string{"member-1"},
+			wantConflicted: []string{"member-2"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			if err := corev1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			objects := []client.Object{&fleetnetv1alpha1.ServiceImport{ObjectMeta: metav1.ObjectMeta{Namespace: "work", Name: "web"}}}
+			for _, export := range tc.exports {
+				objects = append(objects, export)
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(objects...).
+				WithStatusSubresource(&fleetnetv1alpha1.ServiceImport{}, &fleetnetv1alpha1.InternalServiceExport{}).
+				WithIndex(&fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldNamespacedName, func(o client.Object) []string {
+					return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.NamespacedName}
+				}).
+				WithIndex(&fleetnetv1alpha1.EndpointSliceExport{}, endpointSliceExportOwnerSvcNamespacedNameFieldKey, func(o client.Object) []string {
+					return []string{o.(*fleetnetv1alpha1.EndpointSliceExport).Spec.OwnerServiceReference.NamespacedName}
+				}).
+				Build()
+			r := &Reconciler{
+				Client:   fakeClient,
+				Recorder: record.NewFakeRecorder(10),
+			}
+
+			name := types.NamespacedName{Namespace: "work", Name: "web"}
+			if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: name}); err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+			serviceImport := &fleetnetv1alpha1.ServiceImport{}
+			if err := fakeClient.Get(ctx, name, serviceImport); err != nil {
+				t.Fatalf("ServiceImport Get() = %v, want no error", err)
+			}
+			if serviceImport.Status.Type != tc.wantType {
+				t.Errorf("ServiceImport type = %q, want %q", serviceImport.Status.Type, tc.wantType)
+			}
+			var gotClusters []string
+			for _, c := range serviceImport.Status.Clusters {
+				gotClusters = append(gotClusters, c.Cluster)
+			}
+			if diff := cmp.Diff(tc.wantClusters, gotClusters); diff != "" {
+				t.Errorf("ServiceImport clusters mismatch (-want, +got):\n%s", diff)
+			}
+			var gotConflicted []string
+			for _, export := range tc.exports {
+				got := &fleetnetv1alpha1.InternalServiceExport{}
+				if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(export), got); err != nil {
+					t.Fatalf("InternalServiceExport Get() = %v, want no error", err)
+				}
+				if meta.IsStatusConditionTrue(got.Status.Conditions, string(fleetnetv1alpha1.ServiceExportConflict)) {
+					gotConflicted = append(gotConflicted, got.Spec.ServiceReference.ClusterID)
+				}
+			}
+			if diff := cmp.Diff(tc.wantConflicted, gotConflicted); diff != "" {
+				t.Errorf("conflicted clusters mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestReconcile_Rebuild tests that the spec of the serviceImport is not resolved while the hub state is being
+// rebuilt.
+func TestReconcile_Rebuild(t *testing.T) {
+	rebuildConfigMap := types.NamespacedName{Namespace: "fleet-system", Name: "fleet-networking-rebuild"}
+	export := &fleetnetv1alpha1.InternalServiceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  "member-1-ns",
+			Name:       "work-web",
+			Finalizers: []string{objectmeta.InternalServiceExportFinalizer},
+		},
+		Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+			Ports: []fleetnetv1alpha1.ServicePort{{Protocol: corev1.ProtocolTCP, Port: 80}},
+			ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+				ClusterID:      "member-1",
+				Kind:           "Service",
+				Namespace:      "work",
+				Name:           "web",
+				NamespacedName: "work/web",
+			},
+		},
+	}
+	testCases := []struct {
+		name         string
+		deadline     time.Time
+		wantRequeue  bool
+		wantClusters []string
+	}{
+		{
+			name:        "rebuild in progress",
+			deadline:    time.Now().Add(time.Hour),
+			wantRequeue: true,
+		},
+		{
+			name:         "rebuild done",
+			deadline:     time.Now().Add(-time.Hour),
+			wantClusters: []string{"member-1"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			if err := corev1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			configMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: rebuildConfigMap.Namespace, Name: rebuildConfigMap.Name},
+				Data:       map[string]string{rebuild.DeadlineKey: tc.deadline.UTC().Format(time.RFC3339)},
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(&fleetnetv1alpha1.ServiceImport{ObjectMeta: metav1.ObjectMeta{Namespace: "work", Name: "web"}}, export.DeepCopy(), configMap).
+				WithStatusSubresource(&fleetnetv1alpha1.ServiceImport{}, &fleetnetv1alpha1.InternalServiceExport{}).
+				WithIndex(&fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldNamespacedName, func(o client.Object) []string {
+					return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.NamespacedName}
+				}).
+				WithIndex(&fleetnetv1alpha1.EndpointSliceExport{}, endpointSliceExportOwnerSvcNamespacedNameFieldKey, func(o client.Object) []string {
+					return []string{o.(*fleetnetv1alpha1.EndpointSliceExport).Spec.OwnerServiceReference.NamespacedName}
+				}).
+				Build()
+			r := &Reconciler{
+				Client:           fakeClient,
+				Recorder:         record.NewFakeRecorder(10),
+				RebuildConfigMap: rebuildConfigMap,
+			}
+
+			name := types.NamespacedName{Namespace: "work", Name: "web"}
+			got, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: name})
+			if err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+			if gotRequeue := got.RequeueAfter > 0; gotRequeue != tc.wantRequeue {
+				t.Errorf("Reconcile() requeueAfter = %v, want requeue %v", got.RequeueAfter, tc.wantRequeue)
+			}
+			serviceImport := &fleetnetv1alpha1.ServiceImport{}
+			if err := fakeClient.Get(ctx, name, serviceImport); err != nil {
+				t.Fatalf("ServiceImport Get() = %v, want no error", err)
+			}
+			var gotClusters []string
+			for _, c := range serviceImport.Status.Clusters {
+				gotClusters = append(gotClusters, c.Cluster)
+			}
+			if diff := cmp.Diff(tc.wantClusters, gotClusters); diff != "" {
+				t.Errorf("ServiceImport clusters mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestReconcile_ConflictResolution tests that the spec of the serviceImport is taken from the export ranked first
+// by the conflict resolution policy, and that the resolution is reported.
+func TestReconcile_ConflictResolution(t *testing.T) {
+	now := time.Now()
+	internalSvcExport := func(cluster string, port int32, exportedSince time.Time) *fleetnetv1alpha1.InternalServiceExport {
+		return &fleetnetv1alpha1.InternalServiceExport{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:  cluster + "-ns",
+				Name:       "work-web",
+				Finalizers: []string{objectmeta.InternalServiceExportFinalizer},
+			},
+			Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+				Ports: []fleetnetv1alpha1.ServicePort{{Protocol: corev1.ProtocolTCP, Port: port}},
+				ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+					ClusterID:      cluster,
+					Kind:           "Service",
+					Namespace:      "work",
+					Name:           "web",
+					NamespacedName: "work/web",
+					ExportedSince:  metav1.NewTime(exportedSince),
+				},
+			},
+		}
+	}
+	memberCluster := func(name, priority string) *clusterv1beta1.MemberCluster {
+		return &clusterv1beta1.MemberCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{objectmeta.MemberClusterLabelExportPriority: priority},
+			},
+		}
+	}
+	testCases := []struct {
+		name           string
+		annotations    map[string]string
+		memberClusters []client.Object
+		wantResolution *fleetnetv1alpha1.ConflictResolutionStatus
+		wantConflicted string
+	}{
+		{
+			name:           "oldest export wins",
+			wantResolution: &fleetnetv1alpha1.ConflictResolutionStatus{Policy: fleetnetv1alpha1.OldestExportWins, WinningCluster: "member-2"},
+			wantConflicted: "member-1",
+		},
+		{
+			name:           "export from the cluster with the highest priority wins",
+			annotations:    map[string]string{objectmeta.ServiceImportAnnotationConflictResolutionPolicy: string(fleetnetv1alpha1.ClusterPriority)},
+			memberClusters: []client.Object{memberCluster("member-1", "10"), memberCluster("member-2", "invalid")},
+			wantResolution: &fleetnetv1alpha1.ConflictResolutionStatus{Policy: fleetnetv1alpha1.ClusterPriority, WinningCluster: "member-1"},
+			wantConflicted: "member-2",
+		},
+		{
+			name:           "clusters of the same priority fall back to the oldest export",
+			annotations:    map[string]string{objectmeta.ServiceImportAnnotationConflictResolutionPolicy: string(fleetnetv1alpha1.ClusterPriority)},
+			wantResolution: &fleetnetv1alpha1.ConflictResolutionStatus{Policy: fleetnetv1alpha1.ClusterPriority, WinningCluster: "member-2"},
+			wantConflicted: "member-1",
+		},
+		{
+			name: "export from the cluster named by the operator wins",
+			annotations: map[string]string{
+				objectmeta.ServiceImportAnnotationConflictResolutionPolicy: string(fleetnetv1alpha1.ManualConflictResolution),
+				objectmeta.ServiceImportAnnotationConflictResolutionWinner: "member-1",
+			},
+			wantResolution: &fleetnetv1alpha1.ConflictResolutionStatus{Policy: fleetnetv1alpha1.ManualConflictResolution, WinningCluster: "member-1"},
+			wantConflicted: "member-2",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			if err := clusterv1beta1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			exports := []*fleetnetv1alpha1.InternalServiceExport{
+				internalSvcExport("member-1", 80, now),
+				internalSvcExport("member-2", 8080, now.Add(-time.Hour)),
+			}
+			objects := []client.Object{
+				&fleetnetv1alpha1.ServiceImport{ObjectMeta: metav1.ObjectMeta{Namespace: "work", Name: "web", Annotations: tc.annotations}},
+				exports[0],
+				exports[1],
+			}
+			objects = append(objects, tc.memberClusters...)
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(objects...).
+				WithStatusSubresource(&fleetnetv1alpha1.ServiceImport{}, &fleetnetv1alpha1.InternalServiceExport{}).
+				WithIndex(&fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldNamespacedName, func(o client.Object) []string {
+					return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.NamespacedName}
+				}).
+				WithIndex(&fleetnetv1alpha1.EndpointSliceExport{}, endpointSliceExportOwnerSvcNamespacedNameFieldKey, func(o client.Object) []string {
+					return []string{o.(*fleetnetv1alpha1.EndpointSliceExport).Spec.OwnerServiceReference.NamespacedName}
+				}).
+				Build()
+			r := &Reconciler{
+				Client:           fakeClient,
+				Recorder:         record.NewFakeRecorder(10),
+				ConflictResolver: &conflictresolution.Resolver{Reader: fakeClient},
+			}
+
+			name := types.NamespacedName{Namespace: "work", Name: "web"}
+			if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: name}); err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+			serviceImport := &fleetnetv1alpha1.ServiceImport{}
+			if err := fakeClient.Get(ctx, name, serviceImport); err != nil {
+				t.Fatalf("ServiceImport Get() = %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.wantResolution, serviceImport.Status.ConflictResolution); diff != "" {
+				t.Errorf("ServiceImport conflictResolution mismatch (-want, +got):\n%s", diff)
+			}
+			if len(serviceImport.Status.Clusters) != 1 || serviceImport.Status.Clusters[0].Cluster != tc.wantResolution.WinningCluster {
+				t.Errorf("ServiceImport clusters = %v, want only the winning cluster %s", serviceImport.Status.Clusters, tc.wantResolution.WinningCluster)
+			}
+
+			conflicted := &fleetnetv1alpha1.InternalServiceExport{}
+			if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: tc.wantConflicted + "-ns", Name: "work-web"}, conflicted); err != nil {
+				t.Fatalf("InternalServiceExport Get() = %v, want no error", err)
+			}
+			cond := meta.FindStatusCondition(conflicted.Status.Conditions, string(fleetnetv1alpha1.ServiceExportConflict))
+			wantMessage := fmt.Sprintf("service work/web is in conflict with other exported services; the spec exported from cluster %s wins by the %s policy",
+				tc.wantResolution.WinningCluster, tc.wantResolution.Policy)
+			if cond == nil || cond.Status != metav1.ConditionTrue || cond.Message != wantMessage {
+				t.Errorf("InternalServiceExport conflict condition = %v, want true with message %q", cond, wantMessage)
+			}
+		})
+	}
+}
+
+func endpointSliceExportWithPorts(cluster string, endpoints int, ports ...discoveryv1.EndpointPort) fleetnetv1alpha1.EndpointSliceExport {
+	export := fleetnetv1alpha1.EndpointSliceExport{
+		Spec: fleetnetv1alpha1.EndpointSliceExportSpec{
+			Ports: ports,
+			EndpointSliceReference: fleetnetv1alpha1.ExportedObjectReference{
+				ClusterID: cluster,
+			},
+		},
+	}
+	for i := 0; i < endpoints; i++ {
+		export.Spec.Endpoints = append(export.Spec.Endpoints, fleetnetv1alpha1.Endpoint{Addresses: []string{fmt.Sprintf("10.0.0.%d", i+1)}})
+	}
+	return export
+}
+
+// TestExcludeOutOfServiceExports tests the excludeOutOfServiceExports function.
+func TestExcludeOutOfServiceExports(t *testing.T) {
+	export := func(cluster string, conds ...metav1.Condition) fleetnetv1alpha1.InternalServiceExport {
+		return fleetnetv1alpha1.InternalServiceExport{
+			ObjectMeta: metav1.ObjectMeta{Namespace: cluster + "-ns", Name: "work-app"},
+			Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+				ServiceReference: fleetnetv1alpha1.ExportedObjectReference{ClusterID: cluster},
+			},
+			Status: fleetnetv1alpha1.InternalServiceExportStatus{Conditions: conds},
+		}
+	}
+	quarantined := metav1.Condition{
+		Type:   string(fleetnetv1alpha1.ServiceExportQuarantined),
+		Status: metav1.ConditionTrue,
+		Reason: "ClusterQuarantined",
+	}
+	unhealthy := metav1.Condition{
+		Type:   string(fleetnetv1alpha1.ServiceExportUnhealthy),
+		Status: metav1.ConditionTrue,
+		Reason: "NoReadyEndpoints",
+	}
+	stale := metav1.Condition{
+		Type:   string(fleetnetv1alpha1.ServiceExportStale),
+		Status: metav1.ConditionTrue,
+		Reason: "AgentNotRenewing",
+	}
+	pendingApproval := metav1.Condition{
+		Type:   string(fleetnetv1alpha1.ServiceExportPendingApproval),
+		Status: metav1.ConditionTrue,
+		Reason: "AwaitingApproval",
+	}
+	tenantConflict := metav1.Condition{
+		Type:   string(fleetnetv1alpha1.ServiceExportTenantConflict),
+		Status: metav1.ConditionTrue,
+		Reason: "OwnedByOtherTenant",
+	}
+	overQuota := metav1.Condition{
+		Type:   string(fleetnetv1alpha1.ServiceExportQuotaExceeded),
+		Status: metav1.ConditionTrue,
+		Reason: "QuotaExceeded",
+	}
+	noConflict := metav1.Condition{
+		Type:   string(fleetnetv1alpha1.ServiceExportConflict),
+		Status: metav1.ConditionFalse,
+		Reason: "NoConflictFound",
+	}
+
+	testCases := []struct {
+		name    string
+		exports []fleetnetv1alpha1.InternalServiceExport
+		want    []fleetnetv1alpha1.InternalServiceExport
+	}{
+		{
+			name:    "no exports are quarantined",
+			exports: []fleetnetv1alpha1.InternalServiceExport{export("member-1", noConflict), export("member-2")},
+			want:    []fleetnetv1alpha1.InternalServiceExport{export("member-1", noConflict), export("member-2")},
+		},
+		{
+			name:    "quarantined exports are excluded",
+			exports: []fleetnetv1alpha1.InternalServiceExport{export("member-1", quarantined), export("member-2", noConflict)},
+			want:    []fleetnetv1alpha1.InternalServiceExport{export("member-2", noConflict)},
+		},
+		{
+			name:    "unhealthy exports are excluded",
+			exports: []fleetnetv1alpha1.InternalServiceExport{export("member-1", noConflict), export("member-2", unhealthy)},
+			want:    []fleetnetv1alpha1.InternalServiceExport{export("member-1", noConflict)},
+		},
+		{
+			name:    "stale exports are excluded",
+			exports: []fleetnetv1alpha1.InternalServiceExport{export("member-1", stale), export("member-2", noConflict)},
+			want:    []fleetnetv1alpha1.InternalServiceExport{export("member-2", noConflict)},
+		},
+		{
+			name:    "exports pending approval are excluded",
+			exports: []fleetnetv1alpha1.InternalServiceExport{export("member-1", noConflict), export("member-2", pendingApproval)},
+			want:    []fleetnetv1alpha1.InternalServiceExport{export("member-1", noConflict)},
+		},
+		{
+			name:    "exports of another tenant are excluded",
+			exports: []fleetnetv1alpha1.InternalServiceExport{export("member-1", tenantConflict), export("member-2", noConflict)},
+			want:    []fleetnetv1alpha1.InternalServiceExport{export("member-2", noConflict)},
+		},
+		{
+			name:    "exports over quota are excluded",
+			exports: []fleetnetv1alpha1.InternalServiceExport{export("member-1", overQuota), export("member-2", noConflict)},
+			want:    []fleetnetv1alpha1.InternalServiceExport{export("member-2", noConflict)},
+		},
+		{
+			name:    "all exports are quarantined",
+			exports: []fleetnetv1alpha1.InternalServiceExport{export("member-1", quarantined)},
+			want:    []fleetnetv1alpha1.InternalServiceExport{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := excludeOutOfServiceExports(tc.exports)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("excludeOutOfServiceExports() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestPruneWithdrawnClusters tests the pruneWithdrawnClusters function.
+func TestPruneWithdrawnClusters(t *testing.T) {
+	ports := []fleetnetv1alpha1.ServicePort{{Name: "http", Protocol: "TCP", Port: 80}}
+	status := func(clusters ...string) fleetnetv1alpha1.ServiceImportStatus {
+		s := fleetnetv1alpha1.ServiceImportStatus{Type: fleetnetv1alpha1.ClusterSetIP, Ports: ports}
+		for _, c := range clusters {
+			s.Clusters = append(s.Clusters, fleetnetv1alpha1.ServiceImportClusterStatus{Cluster: c})
+		}
+		return s
+	}
+
+	testCases := []struct {
+		name      string
+		status    fleetnetv1alpha1.ServiceImportStatus
+		exporting map[string]bool
+		want      fleetnetv1alpha1.ServiceImportStatus
+	}{
+		{
+			name:      "all clusters export the service",
+			status:    status("member-1", "member-2"),
+			exporting: map[string]bool{"member-1": true, "member-2": true},
+			want:      status("member-1", "member-2"),
+		},
+		{
+			name:      "withdrawn cluster",
+			status:    status("member-1", "member-2"),
+			exporting: map[string]bool{"member-2": true, "member-3": true},
+			want:      status("member-2"),
+		},
+		{
+			name:      "all clusters withdrawn",
+			status:    status("member-1"),
+			exporting: map[string]bool{},
+			want:      fleetnetv1alpha1.ServiceImportStatus{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			serviceImport := &fleetnetv1alpha1.ServiceImport{Status: tc.status}
+			pruneWithdrawnClusters(serviceImport, tc.exporting)
+			if diff := cmp.Diff(tc.want, serviceImport.Status); diff != "" {
+				t.Errorf("pruneWithdrawnClusters() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestAggregateHealthCheckAnnotations tests the aggregateHealthCheckAnnotations function.
+func TestAggregateHealthCheckAnnotations(t *testing.T) {
+	healthCheckExport := func(cluster string, annotations map[string]string) fleetnetv1alpha1.InternalServiceExport {
+		return fleetnetv1alpha1.InternalServiceExport{
+			Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+				ServiceReference:       fleetnetv1alpha1.ExportedObjectReference{ClusterID: cluster},
+				HealthCheckAnnotations: annotations,
+			},
+		}
+	}
+	pathKey := objectmeta.ServiceAnnotationHealthCheckPath
+	portKey := objectmeta.ServiceAnnotationHealthCheckPort
+	consistentCond := &metav1.Condition{
+		Type:               string(fleetnetv1alpha1.ServiceImportHealthCheckConflict),
+		Status:             metav1.ConditionFalse,
+		Reason:             conditionReasonHealthCheckConsistent,
+		ObservedGeneration: 3,
+		Message:            "all exporting clusters agree on the health-check annotations",
+	}
+	conflictCond := func(message string) *metav1.Condition {
+		return &metav1.Condition{
+			Type:               string(fleetnetv1alpha1.ServiceImportHealthCheckConflict),
+			Status:             metav1.ConditionTrue,
+			Reason:             conditionReasonHealthCheckMismatch,
+			ObservedGeneration: 3,
+			Message:            message,
+		}
+	}
+
+	testCases := []struct {
+		name                   string
+		clusters               []fleetnetv1alpha1.ServiceImportClusterStatus
+		internalServiceExports []fleetnetv1alpha1.InternalServiceExport
+		want                   map[string]string
+		wantCond               *metav1.Condition
+	}{
+		{
+			name:     "no health-check annotations",
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-1"}, {Cluster: "member-2"}},
+			internalServiceExports: []fleetnetv1alpha1.InternalServiceExport{
+				healthCheckExport("member-1", nil),
+				healthCheckExport("member-2", nil),
+			},
+		},
+		{
+			name:     "members agree on the health checks",
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-1"}, {Cluster: "member-2"}},
+			internalServiceExports: []fleetnetv1alpha1.InternalServiceExport{
+				healthCheckExport("member-1", map[string]string{pathKey: "/healthz", portKey: "8080"}),
+				healthCheckExport("member-2", map[string]string{pathKey: "/healthz", portKey: "8080"}),
+			},
+			want:     map[string]string{pathKey: "/healthz", portKey: "8080"},
+			wantCond: consistentCond,
+		},
+		{
+			name:     "members disagree on the health-check path",
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-2"}, {Cluster: "member-1"}},
+			internalServiceExports: []fleetnetv1alpha1.InternalServiceExport{
+				healthCheckExport("member-2", map[string]string{pathKey: "/ready"}),
+				healthCheckExport("member-1", map[string]string{pathKey: "/healthz"}),
+			},
+			want: map[string]string{pathKey: "/healthz"},
+			wantCond: conflictCond(`exporting clusters disagree on the health-check annotations (member-1: fleet.azure.com/health-check-path="/healthz", ` +
+				`member-2: fleet.azure.com/health-check-path="/ready"); the values of the cluster whose name sorts first are used`),
+		},
+		{
+			name:     "annotations missing in some members are taken from others",
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-1"}, {Cluster: "member-2"}},
+			internalServiceExports: []fleetnetv1alpha1.InternalServiceExport{
+				healthCheckExport("member-1", nil),
+				healthCheckExport("member-2", map[string]string{portKey: "8080"}),
+			},
+			want: map[string]string{portKey: "8080"},
+			wantCond: conflictCond(`exporting clusters disagree on the health-check annotations (member-1: none, ` +
+				`member-2: fleet.azure.com/health-check-port="8080"); the values of the cluster whose name sorts first are used`),
+		},
+		{
+			name:     "exports of clusters not in use are ignored",
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-1"}},
+			internalServiceExports: []fleetnetv1alpha1.InternalServiceExport{
+				healthCheckExport("member-1", map[string]string{pathKey: "/healthz"}),
+				healthCheckExport("member-2", map[string]string{pathKey: "/ready"}),
+			},
+			want:     map[string]string{pathKey: "/healthz"},
+			wantCond: consistentCond,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, gotCond := aggregateHealthCheckAnnotations(tc.clusters, tc.internalServiceExports, 3)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("aggregateHealthCheckAnnotations() annotations mismatch (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantCond, gotCond); diff != "" {
+				t.Errorf("aggregateHealthCheckAnnotations() condition mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestNamedPortMissingCondition tests the namedPortMissingCondition function.
+func TestNamedPortMissingCondition(t *testing.T) {
+	ports := []fleetnetv1alpha1.ServicePort{
+		{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80, TargetPort: intstr.FromString("web")},
+		{Name: "metrics", Protocol: corev1.ProtocolTCP, Port: 9090},
+	}
+	clusters := []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-1"}, {Cluster: "member-2"}, {Cluster: "member-3"}}
+	// The clusters serve the named ports on different numbers.
+	httpOn8080 := discoveryv1.EndpointPort{Name: ptr.To("http"), Port: ptr.To(int32(8080))}
+	httpOn8081 := discoveryv1.EndpointPort{Name: ptr.To("http"), Port: ptr.To(int32(8081))}
+	metrics := discoveryv1.EndpointPort{Name: ptr.To("metrics"), Port: ptr.To(int32(9090))}
+
+	testCases := []struct {
+		name                 string
+		ports                []fleetnetv1alpha1.ServicePort
+		endpointSliceExports []fleetnetv1alpha1.EndpointSliceExport
+		want                 *metav1.Condition
+	}{
+		{
+			name:  "all clusters serve the named ports on different numbers",
+			ports: ports,
+			endpointSliceExports: []fleetnetv1alpha1.EndpointSliceExport{
+				endpointSliceExportWithPorts("member-1", 2, httpOn8080, metrics),
+				endpointSliceExportWithPorts("member-2", 1, httpOn8081),
+				endpointSliceExportWithPorts("member-2", 1, metrics),
+				// A cluster which exports no endpoints is not checked.
+				endpointSliceExportWithPorts("member-3", 0),
+			},
+			want: &metav1.Condition{
+				Type:               string(fleetnetv1alpha1.ServiceImportNamedPortMissing),
+				Status:             metav1.ConditionFalse,
+				Reason:             conditionReasonNamedPortsServed,
+				ObservedGeneration: 3,
+			},
+		},
+		{
+			name:  "named ports missing in some clusters",
+			ports: ports,
+			endpointSliceExports: []fleetnetv1alpha1.EndpointSliceExport{
+				endpointSliceExportWithPorts("member-1", 2, httpOn8080, metrics),
+				endpointSliceExportWithPorts("member-2", 1, httpOn8081),
+				endpointSliceExportWithPorts("member-3", 1),
+				// Endpoints from a cluster which does not back the ServiceImport are ignored.
+				endpointSliceExportWithPorts("member-4", 1),
+			},
+			want: &metav1.Condition{
+				Type:               string(fleetnetv1alpha1.ServiceImportNamedPortMissing),
+				Status:             metav1.ConditionTrue,
+				Reason:             conditionReasonNamedPortMissing,
+				ObservedGeneration: 3,
+				Message:            "some contributing clusters do not serve all the named ports (member-2: metrics; member-3: http, metrics)",
+			},
+		},
+		{
+			name:  "no named ports",
+			ports: []fleetnetv1alpha1.ServicePort{{Protocol: corev1.ProtocolTCP, Port: 80}},
+			endpointSliceExports: []fleetnetv1alpha1.EndpointSliceExport{
+				endpointSliceExportWithPorts("member-1", 1, discoveryv1.EndpointPort{Port: ptr.To(int32(8080))}),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := namedPortMissingCondition(tc.ports, clusters, tc.endpointSliceExports, 3)
+			if diff := cmp.Diff(tc.want, got, cmpopts.IgnoreFields(metav1.Condition{}, "Message")); diff != "" {
+				t.Errorf("namedPortMissingCondition() mismatch (-want, +got):\n%s", diff)
+			}
+			if tc.want != nil && tc.want.Message != "" && got.Message != tc.want.Message {
+				t.Errorf("namedPortMissingCondition() message = %q, want %q", got.Message, tc.want.Message)
+			}
+		})
+	}
+}
+
+// TestAggregateExportedMetadata tests the aggregateExportedMetadata function.
+func TestAggregateExportedMetadata(t *testing.T) {
+	now := time.Now()
+	metadataExport := func(cluster string, exportedSince time.Time, labels, annotations map[string]string) fleetnetv1alpha1.InternalServiceExport {
+		return fleetnetv1alpha1.InternalServiceExport{
+			Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+				ServiceReference:    fleetnetv1alpha1.ExportedObjectReference{ClusterID: cluster, ExportedSince: metav1.NewTime(exportedSince)},
+				ExportedLabels:      labels,
+				ExportedAnnotations: annotations,
+			},
+		}
+	}
+	consistentCond := &metav1.Condition{
+		Type:               string(fleetnetv1alpha1.ServiceImportExportedMetadataConflict),
+		Status:             metav1.ConditionFalse,
+		Reason:             conditionReasonExportedMetadataConsistent,
+		ObservedGeneration: 3,
+		Message:            "all exporting clusters agree on the exported labels and annotations",
+	}
+
+	testCases := []struct {
+		name                   string
+		clusters               []fleetnetv1alpha1.ServiceImportClusterStatus
+		internalServiceExports []fleetnetv1alpha1.InternalServiceExport
+		wantLabels             map[string]string
+		wantAnnotations        map[string]string
+		wantCond               *metav1.Condition
+	}{
+		{
+			name:     "no exported metadata",
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-1"}, {Cluster: "member-2"}},
+			internalServiceExports: []fleetnetv1alpha1.InternalServiceExport{
+				metadataExport("member-1", now, nil, nil),
+				metadataExport("member-2", now, nil, nil),
+			},
+		},
+		{
+			name:     "metadata of the members are merged",
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-1"}, {Cluster: "member-2"}},
+			internalServiceExports: []fleetnetv1alpha1.InternalServiceExport{
+				metadataExport("member-1", now, map[string]string{"team": "payments"}, nil),
+				metadataExport("member-2", now, map[string]string{"team": "payments", "tier": "backend"}, map[string]string{"example.com/owner": "alice"}),
+			},
+			wantLabels:      map[string]string{"team": "payments", "tier": "backend"},
+			wantAnnotations: map[string]string{"example.com/owner": "alice"},
+			wantCond:        consistentCond,
+		},
+		{
+			name:     "oldest export wins on conflicts",
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-1"}, {Cluster: "member-2"}},
+			internalServiceExports: []fleetnetv1alpha1.InternalServiceExport{
+				metadataExport("member-1", now, map[string]string{"team": "billing"}, map[string]string{"example.com/owner": "bob"}),
+				metadataExport("member-2", now.Add(-time.Hour), map[string]string{"team": "payments"}, map[string]string{"example.com/owner": "alice"}),
+			},
+			wantLabels:      map[string]string{"team": "payments"},
+			wantAnnotations: map[string]string{"example.com/owner": "alice"},
+			wantCond: &metav1.Condition{
+				Type:               string(fleetnetv1alpha1.ServiceImportExportedMetadataConflict),
+				Status:             metav1.ConditionTrue,
+				Reason:             conditionReasonExportedMetadataMismatch,
+				ObservedGeneration: 3,
+				Message:            "exporting clusters disagree on the exported annotation example.com/owner, label team; the values of the oldest export are used",
+			},
+		},
+		{
+			name:     "exports of clusters not in use are ignored",
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-1"}},
+			internalServiceExports: []fleetnetv1alpha1.InternalServiceExport{
+				metadataExport("member-1", now, map[string]string{"team": "billing"}, nil),
+				metadataExport("member-2", now.Add(-time.Hour), map[string]string{"team": "payments"}, nil),
+			},
+			wantLabels: map[string]string{"team": "billing"},
+			wantCond:   consistentCond,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotLabels, gotAnnotations, gotCond := aggregateExportedMetadata(tc.clusters, tc.internalServiceExports, 3)
+			if diff := cmp.Diff(tc.wantLabels, gotLabels); diff != "" {
+				t.Errorf("aggregateExportedMetadata() labels mismatch (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantAnnotations, gotAnnotations); diff != "" {
+				t.Errorf("aggregateExportedMetadata() annotations mismatch (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantCond, gotCond); diff != "" {
+				t.Errorf("aggregateExportedMetadata() condition mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestAggregateAllowedConsumers tests the aggregateAllowedConsumers function.
+func TestAggregateAllowedConsumers(t *testing.T) {
+	consumersExport := func(cluster string, allowed *fleetnetv1alpha1.ConsumerSelector) fleetnetv1alpha1.InternalServiceExport {
+		return fleetnetv1alpha1.InternalServiceExport{
+			Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+				ServiceReference: fleetnetv1alpha1.ExportedObjectReference{ClusterID: cluster},
+				AllowedConsumers: allowed,
+			},
+		}
+	}
+	onlyMember3 := &fleetnetv1alpha1.ConsumerSelector{Clusters: []string{"member-3"}}
+	prodClusters := &fleetnetv1alpha1.ConsumerSelector{
+		ClusterSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+	}
+
+	testCases := []struct {
+		name                   string
+		clusters               []fleetnetv1alpha1.ServiceImportClusterStatus
+		internalServiceExports []fleetnetv1alpha1.InternalServiceExport
+		want                   []fleetnetv1alpha1.ConsumerSelector
+	}{
+		{
+			name:     "no restrictions",
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-1"}, {Cluster: "member-2"}},
+			internalServiceExports: []fleetnetv1alpha1.InternalServiceExport{
+				consumersExport("member-1", nil),
+				consumersExport("member-2", nil),
+			},
+		},
+		{
+			name:     "restrictions ordered by cluster name",
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-1"}, {Cluster: "member-2"}},
+			internalServiceExports: []fleetnetv1alpha1.InternalServiceExport{
+				consumersExport("member-2", prodClusters),
+				consumersExport("member-1", onlyMember3),
+			},
+			want: []fleetnetv1alpha1.ConsumerSelector{*onlyMember3, *prodClusters},
+		},
+		{
+			name:     "same restrictions are merged",
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-1"}, {Cluster: "member-2"}},
+			internalServiceExports: []fleetnetv1alpha1.InternalServiceExport{
+				consumersExport("member-1", onlyMember3),
+				consumersExport("member-2", onlyMember3.DeepCopy()),
+			},
+			want: []fleetnetv1alpha1.ConsumerSelector{*onlyMember3},
+		},
+		{
+			name:     "exports of clusters not in use are ignored",
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-1"}},
+			internalServiceExports: []fleetnetv1alpha1.InternalServiceExport{
+				consumersExport("member-1", nil),
+				consumersExport("member-2", onlyMember3),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := aggregateAllowedConsumers(tc.clusters, tc.internalServiceExports)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("aggregateAllowedConsumers() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -124,7 +124,7 @@ var _ = BeforeSuite(func() {
 	err = (&Reconciler{
 		Client:   mgr.GetClient(),
 		Recorder: mgr.GetEventRecorderFor(ControllerName),
-	}).SetupWithManager(ctx, mgr)
+	}).SetupWithManager(ctx, mgr, false)
 	Expect(err).ToNot(HaveOccurred())
 
 	ctx, cancel = context.WithCancel(context.TODO())
@@ -0,0 +1,136 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package staleexport features the stale export controller, which marks the InternalServiceExports and
+// EndpointSliceExports of a member cluster as stale when the networking agent of the cluster stops renewing its
+// heartbeat, and unmarks them when the agent reports again.
+package staleexport
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+	"go.goms.io/fleet-networking/pkg/common/staleexport"
+)
+
+// Reconciler reconciles the stale marks of the exports of the member cluster of an InternalMemberNetworkStatus.
+type Reconciler struct {
+	client.Client
+	// HeartbeatTimeout is how long the heartbeat of a networking agent lasts before the exports of its member
+	// cluster are marked as stale.
+	HeartbeatTimeout time.Duration
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
+}
+
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalmembernetworkstatuses,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalserviceexports,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=endpointsliceexports,verbs=get;list;watch;update;patch
+
+// Reconcile marks the exports of the member cluster as stale if the heartbeat of its networking agent has expired,
+// or unmarks them otherwise, and requeues the InternalMemberNetworkStatus to be evaluated again when the heartbeat
+// would expire.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	networkStatusRef := klog.KRef(req.Namespace, req.Name)
+	startTime := time.Now()
+	klog.V(2).InfoS("Reconciliation starts", "internalMemberNetworkStatus", networkStatusRef)
+	defer func() {
+		latency := time.Since(startTime).Milliseconds()
+		klog.V(2).InfoS("Reconciliation ends", "internalMemberNetworkStatus", networkStatusRef, "latency", latency)
+	}()
+
+	networkStatus := &fleetnetv1alpha1.InternalMemberNetworkStatus{}
+	if err := r.Client.Get(ctx, req.NamespacedName, networkStatus); err != nil {
+		if errors.IsNotFound(err) {
+			// The exports of a member cluster which never reports are never marked as stale.
+			klog.V(4).InfoS("Ignoring NotFound internalMemberNetworkStatus", "internalMemberNetworkStatus", networkStatusRef)
+			return ctrl.Result{}, nil
+		}
+		klog.ErrorS(err, "Failed to get internalMemberNetworkStatus", "internalMemberNetworkStatus", networkStatusRef)
+		return ctrl.Result{}, err
+	}
+	if networkStatus.DeletionTimestamp != nil {
+		return ctrl.Result{}, nil
+	}
+
+	exports, err := r.listExports(ctx, networkStatus.Namespace)
+	if err != nil {
+		klog.ErrorS(err, "Failed to list the exports of the member cluster", "internalMemberNetworkStatus", networkStatusRef)
+		return ctrl.Result{}, err
+	}
+
+	expiry, stale := r.heartbeatExpiry(networkStatus, startTime)
+	for _, export := range exports {
+		patch := client.MergeFrom(export.DeepCopyObject().(client.Object))
+		var changed bool
+		if stale {
+			changed = staleexport.Mark(export, expiry)
+		} else {
+			changed = staleexport.Unmark(export)
+		}
+		if !changed {
+			continue
+		}
+		klog.V(2).InfoS("Updating the stale mark of the export", "export", klog.KObj(export), "stale", stale, "expiry", expiry)
+		if err := r.Client.Patch(ctx, export, patch); err != nil && !errors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to update the stale mark of the export", "export", klog.KObj(export))
+			return ctrl.Result{}, err
+		}
+	}
+	if stale || expiry.IsZero() {
+		// The networking agent triggers another reconciliation when it reports again.
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{RequeueAfter: expiry.Sub(startTime)}, nil
+}
+
+// heartbeatExpiry returns when the heartbeat of the networking agent expires, and whether it has expired at the
+// given time; a networking agent which has not reported yet is not stale.
+func (r *Reconciler) heartbeatExpiry(networkStatus *fleetnetv1alpha1.InternalMemberNetworkStatus, now time.Time) (time.Time, bool) {
+	heartbeatTime := networkStatus.Status.LastHeartbeatTime
+	if heartbeatTime == nil {
+		return time.Time{}, false
+	}
+	expiry := heartbeatTime.Add(r.HeartbeatTimeout)
+	return expiry, !now.Before(expiry)
+}
+
+// listExports lists the InternalServiceExports and EndpointSliceExports in the namespace of a member cluster.
+func (r *Reconciler) listExports(ctx context.Context, namespace string) ([]client.Object, error) {
+	internalServiceExportList := &fleetnetv1alpha1.InternalServiceExportList{}
+	if err := r.Client.List(ctx, internalServiceExportList, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	endpointSliceExportList := &fleetnetv1alpha1.EndpointSliceExportList{}
+	if err := r.Client.List(ctx, endpointSliceExportList, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	exports := make([]client.Object, 0, len(internalServiceExportList.Items)+len(endpointSliceExportList.Items))
+	for i := range internalServiceExportList.Items {
+		exports = append(exports, &internalServiceExportList.Items[i])
+	}
+	for i := range endpointSliceExportList.Items {
+		exports = append(exports, &endpointSliceExportList.Items[i])
+	}
+	return exports, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("staleexport").
+		For(&fleetnetv1alpha1.InternalMemberNetworkStatus{}).
+		WithOptions(r.ControllerOptions).
+		Complete(metrics.InstrumentReconciler("staleexport", r))
+}
@@ -0,0 +1,140 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package staleexport
+
+import (
+	"context"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/staleexport"
+)
+
+const (
+	memberClusterID  = "bravelion"
+	hubNSForMember   = "fleet-member-bravelion"
+	heartbeatTimeout = 2 * time.Minute
+)
+
+var (
+	networkStatusKey         = types.NamespacedName{Namespace: hubNSForMember, Name: memberClusterID}
+	internalServiceExportKey = types.NamespacedName{Namespace: hubNSForMember, Name: "work-app"}
+	endpointSliceExportKey   = types.NamespacedName{Namespace: hubNSForMember, Name: "app-slice"}
+)
+
+func TestMain(m *testing.M) {
+	// Add custom APIs to the runtime scheme
+	if err := fleetnetv1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		log.Fatalf("failed to add custom APIs to the runtime scheme: %v", err)
+	}
+	os.Exit(m.Run())
+}
+
+// TestReconcile tests that the exports of a member cluster are marked as stale once its heartbeat expires, and
+// unmarked once it reports again.
+func TestReconcile(t *testing.T) {
+	ctx := context.Background()
+	networkStatus := &fleetnetv1alpha1.InternalMemberNetworkStatus{
+		ObjectMeta: metav1.ObjectMeta{Namespace: hubNSForMember, Name: memberClusterID},
+		Status: fleetnetv1alpha1.InternalMemberNetworkStatusStatus{
+			ClusterID:         memberClusterID,
+			LastHeartbeatTime: &metav1.Time{Time: time.Now().Add(-2 * heartbeatTimeout)},
+		},
+	}
+	internalSvcExport := &fleetnetv1alpha1.InternalServiceExport{
+		ObjectMeta: metav1.ObjectMeta{Namespace: internalServiceExportKey.Namespace, Name: internalServiceExportKey.Name},
+	}
+	endpointSliceExport := &fleetnetv1alpha1.EndpointSliceExport{
+		ObjectMeta: metav1.ObjectMeta{Namespace: endpointSliceExportKey.Namespace, Name: endpointSliceExportKey.Name},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(networkStatus, internalSvcExport, endpointSliceExport).
+		WithStatusSubresource(networkStatus).
+		Build()
+	r := &Reconciler{Client: fakeClient, HeartbeatTimeout: heartbeatTimeout}
+
+	assertStale := func(t *testing.T, want bool) {
+		t.Helper()
+		for _, export := range []struct {
+			key types.NamespacedName
+			obj client.Object
+		}{
+			{key: internalServiceExportKey, obj: &fleetnetv1alpha1.InternalServiceExport{}},
+			{key: endpointSliceExportKey, obj: &fleetnetv1alpha1.EndpointSliceExport{}},
+		} {
+			if err := fakeClient.Get(ctx, export.key, export.obj); err != nil {
+				t.Fatalf("Get(%v) got error %v, want no error", export.key, err)
+			}
+			if _, got := staleexport.StaleSince(export.obj); got != want {
+				t.Errorf("StaleSince(%v) = %t, want %t", export.key, got, want)
+			}
+		}
+	}
+
+	got, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: networkStatusKey})
+	if err != nil {
+		t.Fatalf("Reconcile() got error %v, want no error", err)
+	}
+	if got.RequeueAfter != 0 {
+		t.Errorf("Reconcile() = %+v, want no requeue", got)
+	}
+	assertStale(t, true)
+
+	networkStatus.Status.LastHeartbeatTime = &metav1.Time{Time: time.Now()}
+	if err := fakeClient.Status().Update(ctx, networkStatus); err != nil {
+		t.Fatalf("InternalMemberNetworkStatus Update() got error %v, want no error", err)
+	}
+	got, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: networkStatusKey})
+	if err != nil {
+		t.Fatalf("Reconcile() got error %v, want no error", err)
+	}
+	if got.RequeueAfter <= 0 || got.RequeueAfter > heartbeatTimeout {
+		t.Errorf("Reconcile() = %+v, want a requeue within %v", got, heartbeatTimeout)
+	}
+	assertStale(t, false)
+}
+
+// TestReconcile_NoHeartbeat tests that the exports of a member cluster which has not reported yet are not marked.
+func TestReconcile_NoHeartbeat(t *testing.T) {
+	ctx := context.Background()
+	networkStatus := &fleetnetv1alpha1.InternalMemberNetworkStatus{
+		ObjectMeta: metav1.ObjectMeta{Namespace: hubNSForMember, Name: memberClusterID},
+	}
+	internalSvcExport := &fleetnetv1alpha1.InternalServiceExport{
+		ObjectMeta: metav1.ObjectMeta{Namespace: internalServiceExportKey.Namespace, Name: internalServiceExportKey.Name},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(networkStatus, internalSvcExport).
+		Build()
+	r := &Reconciler{Client: fakeClient, HeartbeatTimeout: heartbeatTimeout}
+
+	got, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: networkStatusKey})
+	if err != nil {
+		t.Fatalf("Reconcile() got error %v, want no error", err)
+	}
+	if got != (ctrl.Result{}) {
+		t.Errorf("Reconcile() = %+v, want no requeue", got)
+	}
+	gotInternalSvcExport := &fleetnetv1alpha1.InternalServiceExport{}
+	if err := fakeClient.Get(ctx, internalServiceExportKey, gotInternalSvcExport); err != nil {
+		t.Fatalf("InternalServiceExport Get() got error %v, want no error", err)
+	}
+	if _, stale := staleexport.StaleSince(gotInternalSvcExport); stale {
+		t.Errorf("StaleSince() = true, want false")
+	}
+}
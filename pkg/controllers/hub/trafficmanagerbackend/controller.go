@@ -26,6 +26,7 @@ import (
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -36,7 +37,10 @@ import (
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 	fleetnetv1beta1 "go.goms.io/fleet-networking/api/v1beta1"
 	"go.goms.io/fleet-networking/pkg/common/azureerrors"
+	"go.goms.io/fleet-networking/pkg/common/clusterhealth"
 	"go.goms.io/fleet-networking/pkg/common/defaulter"
+	"go.goms.io/fleet-networking/pkg/common/fieldindex"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
 	"go.goms.io/fleet-networking/pkg/common/objectmeta"
 	"go.goms.io/fleet-networking/pkg/controllers/hub/trafficmanagerprofile"
 )
@@ -45,7 +49,7 @@ const (
 	trafficManagerBackendProfileFieldKey = ".spec.profile.name"
 	trafficManagerBackendBackendFieldKey = ".spec.backend.name"
 	// fields name used to filter resources
-	exportedServiceFieldNamespacedName = ".spec.serviceReference.namespacedName"
+	exportedServiceFieldNamespacedName = fieldindex.InternalServiceExportServiceNamespacedName
 
 	// AzureResourceEndpointNamePrefix is the prefix format of the Azure Traffic Manager Endpoint created by the fleet controller.
 	// The naming convention of a Traffic Manager Endpoint is fleet-{TrafficManagerBackendUUID}#.
@@ -79,6 +83,12 @@ type Reconciler struct {
 	ProfilesClient    *armtrafficmanager.ProfilesClient
 	EndpointsClient   *armtrafficmanager.EndpointsClient
 	ResourceGroupName string // default resource group name to create azure traffic manager resources
+	// FailOverUnhealthyClusters, if set, disables the endpoints of the exports failed over by the hub; otherwise they
+	// are kept enabled and left to the health probes of the Azure Traffic Manager.
+	FailOverUnhealthyClusters bool
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions ctrlcontroller.Options
 }
 
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=trafficmanagerbackends,verbs=get;list;watch;create;update;patch;delete
@@ -497,7 +507,40 @@ func (r *Reconciler) validateExportedServiceForServiceImport(ctx context.Context
 			},
 		}
 	}
-	desiredWeight := int(math.Ceil(float64(*backend.Spec.Weight) / float64(len(desiredEndpoints))))
+	// The exports failed over by the hub are excluded from the serviceImport, while their endpoints are kept in the
+	// Azure Traffic Manager; they are disabled rather than deleted when failed over, so that they are enabled again
+	// as soon as the exports recover.
+	for i := range internalServiceExportList.Items {
+		internalServiceExport := &internalServiceExportList.Items[i]
+		if !clusterhealth.IsUnhealthyExport(internalServiceExport) || isValidTrafficManagerEndpoint(internalServiceExport) != nil {
+			continue
+		}
+		endpoint := generateAzureTrafficManagerEndpoint(backend, internalServiceExport)
+		if _, ok := desiredEndpoints[*endpoint.Name]; ok {
+			continue
+		}
+		if r.FailOverUnhealthyClusters {
+			endpoint.Properties.EndpointStatus = ptr.To(armtrafficmanager.EndpointStatusDisabled)
+		}
+		klog.V(2).InfoS("Found the unhealthy service", "trafficManagerBackend", backendKObj, "serviceImport", serviceImportKObj, "clusterID", internalServiceExport.Spec.ServiceReference.ClusterID, "endpointStatus", *endpoint.Properties.EndpointStatus)
+		desiredEndpoints[*endpoint.Name] = desiredEndpoint{
+			Endpoint: endpoint,
+			Cluster: fleetnetv1beta1.ClusterStatus{
+				Cluster: internalServiceExport.Spec.ServiceReference.ClusterID,
+			},
+		}
+	}
+	// The weight of the backend is split across the enabled endpoints only.
+	enabledEndpoints := 0
+	for _, dp := range desiredEndpoints {
+		if *dp.Endpoint.Properties.EndpointStatus == armtrafficmanager.EndpointStatusEnabled {
+			enabledEndpoints++
+		}
+	}
+	if enabledEndpoints == 0 {
+		enabledEndpoints = len(desiredEndpoints)
+	}
+	desiredWeight := int(math.Ceil(float64(*backend.Spec.Weight) / float64(enabledEndpoints)))
 	for _, dp := range desiredEndpoints {
 		dp.Endpoint.Properties.Weight = ptr.To(int64(desiredWeight))
 	}
@@ -686,7 +729,8 @@ func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, dis
 			&fleetnetv1alpha1.InternalServiceExport{},
 			handler.EnqueueRequestsFromMapFunc(r.internalServiceExportEventHandler()),
 		).
-		Complete(r)
+		WithOptions(r.ControllerOptions).
+		Complete(metrics.InstrumentReconciler("trafficmanagerbackend", r))
 }
 
 func (r *Reconciler) trafficManagerProfileEventHandler() handler.MapFunc {
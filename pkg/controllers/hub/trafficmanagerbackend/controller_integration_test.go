@@ -623,7 +623,7 @@ var _ = Describe("Test TrafficManagerBackend Controller", func() {
 
 		It("Updating the ServiceImport status", func() {
 			serviceImport.Status = fleetnetv1alpha1.ServiceImportStatus{
-				Clusters: []fleetnetv1alpha1.ClusterStatus{
+				Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 					{
 						Cluster: memberClusterNames[2], // not found internalServiceExport
 					},
@@ -740,7 +740,7 @@ var _ = Describe("Test TrafficManagerBackend Controller", func() {
 
 		It("Updating the ServiceImport status", func() {
 			serviceImport.Status = fleetnetv1alpha1.ServiceImportStatus{
-				Clusters: []fleetnetv1alpha1.ClusterStatus{
+				Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 					{
 						Cluster: memberClusterNames[0], // valid endpoint
 					},
@@ -782,7 +782,7 @@ var _ = Describe("Test TrafficManagerBackend Controller", func() {
 
 		It("Updating the ServiceImport status", func() {
 			serviceImport.Status = fleetnetv1alpha1.ServiceImportStatus{
-				Clusters: []fleetnetv1alpha1.ClusterStatus{
+				Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 					{
 						Cluster: memberClusterNames[0], // valid endpoint
 					},
@@ -834,7 +834,7 @@ var _ = Describe("Test TrafficManagerBackend Controller", func() {
 
 		It("Updating the ServiceImport status", func() {
 			serviceImport.Status = fleetnetv1alpha1.ServiceImportStatus{
-				Clusters: []fleetnetv1alpha1.ClusterStatus{
+				Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 					{
 						Cluster: memberClusterNames[4], // would fail to create atm endpoint
 					},
@@ -876,7 +876,7 @@ var _ = Describe("Test TrafficManagerBackend Controller", func() {
 
 		It("Updating the ServiceImport status", func() {
 			serviceImport.Status = fleetnetv1alpha1.ServiceImportStatus{
-				Clusters: []fleetnetv1alpha1.ClusterStatus{
+				Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 					{
 						Cluster: memberClusterNames[5], // would fail to create atm endpoint
 					},
@@ -906,7 +906,7 @@ var _ = Describe("Test TrafficManagerBackend Controller", func() {
 
 		It("Updating the ServiceImport status", func() {
 			serviceImport.Status = fleetnetv1alpha1.ServiceImportStatus{
-				Clusters: []fleetnetv1alpha1.ClusterStatus{
+				Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 					{
 						Cluster: memberClusterNames[0], // valid endpoint
 					},
@@ -22,6 +22,7 @@ import (
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	"go.goms.io/fleet/pkg/utils/controller"
@@ -29,6 +30,7 @@ import (
 	fleetnetv1beta1 "go.goms.io/fleet-networking/api/v1beta1"
 	"go.goms.io/fleet-networking/pkg/common/azureerrors"
 	"go.goms.io/fleet-networking/pkg/common/defaulter"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
 	"go.goms.io/fleet-networking/pkg/common/objectmeta"
 )
 
@@ -62,6 +64,9 @@ type Reconciler struct {
 
 	ProfilesClient    *armtrafficmanager.ProfilesClient
 	ResourceGroupName string // default resource group name to create azure traffic manager profiles
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions ctrlcontroller.Options
 }
 
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=trafficmanagerprofiles,verbs=get;list;watch;create;update;patch;delete
@@ -195,6 +200,11 @@ func EqualAzureTrafficManagerProfile(current, desired armtrafficmanager.Profile)
 		return false
 	}
 
+	if !equalCustomHeaders(current.Properties.MonitorConfig.CustomHeaders, desired.Properties.MonitorConfig.CustomHeaders) ||
+		!equalExpectedStatusCodeRanges(current.Properties.MonitorConfig.ExpectedStatusCodeRanges, desired.Properties.MonitorConfig.ExpectedStatusCodeRanges) {
+		return false
+	}
+
 	if *current.Properties.ProfileStatus != *desired.Properties.ProfileStatus || *current.Properties.TrafficRoutingMethod != *desired.Properties.TrafficRoutingMethod {
 		return false
 	}
@@ -216,6 +226,35 @@ func EqualAzureTrafficManagerProfile(current, desired armtrafficmanager.Profile)
 	return true
 }
 
+// equalCustomHeaders compares the custom headers of the monitor configs in order; nil and empty lists are equal.
+func equalCustomHeaders(current, desired []*armtrafficmanager.MonitorConfigCustomHeadersItem) bool {
+	if len(current) != len(desired) {
+		return false
+	}
+	for i := range desired {
+		if current[i] == nil || current[i].Name == nil || current[i].Value == nil ||
+			*current[i].Name != *desired[i].Name || *current[i].Value != *desired[i].Value {
+			return false
+		}
+	}
+	return true
+}
+
+// equalExpectedStatusCodeRanges compares the expected status code ranges of the monitor configs in order; nil and empty
+// lists are equal.
+func equalExpectedStatusCodeRanges(current, desired []*armtrafficmanager.MonitorConfigExpectedStatusCodeRangesItem) bool {
+	if len(current) != len(desired) {
+		return false
+	}
+	for i := range desired {
+		if current[i] == nil || current[i].Min == nil || current[i].Max == nil ||
+			*current[i].Min != *desired[i].Min || *current[i].Max != *desired[i].Max {
+			return false
+		}
+	}
+	return true
+}
+
 func (r *Reconciler) updateProfileStatus(ctx context.Context, profile *fleetnetv1beta1.TrafficManagerProfile, atmProfile armtrafficmanager.Profile, updateErr error) (ctrl.Result, error) {
 	profileKObj := klog.KObj(profile)
 	if updateErr == nil {
@@ -289,6 +328,8 @@ func generateAzureTrafficManagerProfile(profile *fleetnetv1beta1.TrafficManagerP
 				Protocol:                  ptr.To(armtrafficmanager.MonitorProtocol(*mc.Protocol)),
 				TimeoutInSeconds:          mc.TimeoutInSeconds,
 				ToleratedNumberOfFailures: mc.ToleratedNumberOfFailures,
+				CustomHeaders:             generateAzureMonitorCustomHeaders(mc.CustomHeaders),
+				ExpectedStatusCodeRanges:  generateAzureMonitorExpectedStatusCodeRanges(mc.ExpectedStatusCodeRanges),
 			},
 			ProfileStatus: ptr.To(armtrafficmanager.ProfileStatusEnabled),
 			// By default, the routing method is set to Weighted.
@@ -300,9 +341,38 @@ func generateAzureTrafficManagerProfile(profile *fleetnetv1beta1.TrafficManagerP
 	}
 }
 
+func generateAzureMonitorCustomHeaders(headers []fleetnetv1beta1.MonitorConfigCustomHeader) []*armtrafficmanager.MonitorConfigCustomHeadersItem {
+	if len(headers) == 0 {
+		return nil
+	}
+	res := make([]*armtrafficmanager.MonitorConfigCustomHeadersItem, len(headers))
+	for i := range headers {
+		res[i] = &armtrafficmanager.MonitorConfigCustomHeadersItem{
+			Name:  ptr.To(headers[i].Name),
+			Value: ptr.To(headers[i].Value),
+		}
+	}
+	return res
+}
+
+func generateAzureMonitorExpectedStatusCodeRanges(ranges []fleetnetv1beta1.MonitorConfigStatusCodeRange) []*armtrafficmanager.MonitorConfigExpectedStatusCodeRangesItem {
+	if len(ranges) == 0 {
+		return nil
+	}
+	res := make([]*armtrafficmanager.MonitorConfigExpectedStatusCodeRangesItem, len(ranges))
+	for i := range ranges {
+		res[i] = &armtrafficmanager.MonitorConfigExpectedStatusCodeRangesItem{
+			Min: ptr.To(ranges[i].Min),
+			Max: ptr.To(ranges[i].Max),
+		}
+	}
+	return res
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&fleetnetv1beta1.TrafficManagerProfile{}).
-		Complete(r)
+		WithOptions(r.ControllerOptions).
+		Complete(metrics.InstrumentReconciler("trafficmanagerprofile", r))
 }
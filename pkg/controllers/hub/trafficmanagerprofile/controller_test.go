@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/trafficmanager/armtrafficmanager"
+	"github.com/google/go-cmp/cmp"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
 
@@ -209,6 +210,36 @@ func TestEqualAzureTrafficManagerProfile(t *testing.T) {
 				return res
 			},
 		},
+		{
+			name: "MonitorConfig.CustomHeaders and ExpectedStatusCodeRanges are empty",
+			buildCurrentFunc: func() armtrafficmanager.Profile {
+				res := buildDesiredProfile()
+				res.Properties.MonitorConfig.CustomHeaders = []*armtrafficmanager.MonitorConfigCustomHeadersItem{}
+				res.Properties.MonitorConfig.ExpectedStatusCodeRanges = []*armtrafficmanager.MonitorConfigExpectedStatusCodeRangesItem{}
+				return res
+			},
+			want: true,
+		},
+		{
+			name: "MonitorConfig.CustomHeaders is different",
+			buildCurrentFunc: func() armtrafficmanager.Profile {
+				res := buildDesiredProfile()
+				res.Properties.MonitorConfig.CustomHeaders = []*armtrafficmanager.MonitorConfigCustomHeadersItem{
+					{Name: ptr.To("Host"), Value: ptr.To("example.com")},
+				}
+				return res
+			},
+		},
+		{
+			name: "MonitorConfig.ExpectedStatusCodeRanges is different",
+			buildCurrentFunc: func() armtrafficmanager.Profile {
+				res := buildDesiredProfile()
+				res.Properties.MonitorConfig.ExpectedStatusCodeRanges = []*armtrafficmanager.MonitorConfigExpectedStatusCodeRangesItem{
+					{Min: ptr.To[int32](200), Max: ptr.To[int32](299)},
+				}
+				return res
+			},
+		},
 		{
 			name: "ProfileStatus is different",
 			buildCurrentFunc: func() armtrafficmanager.Profile {
@@ -278,3 +309,51 @@ func TestEqualAzureTrafficManagerProfile(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateAzureTrafficManagerProfile_MonitorConfig(t *testing.T) {
+	profile := &fleetnetv1beta1.TrafficManagerProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "name",
+			Namespace: "namespace",
+		},
+		Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+			MonitorConfig: &fleetnetv1beta1.MonitorConfig{
+				IntervalInSeconds:         ptr.To[int64](30),
+				Path:                      ptr.To("/healthz"),
+				Port:                      ptr.To[int64](443),
+				Protocol:                  ptr.To(fleetnetv1beta1.TrafficManagerMonitorProtocolHTTPS),
+				TimeoutInSeconds:          ptr.To[int64](10),
+				ToleratedNumberOfFailures: ptr.To[int64](3),
+				CustomHeaders: []fleetnetv1beta1.MonitorConfigCustomHeader{
+					{Name: "Host", Value: "example.com"},
+				},
+				ExpectedStatusCodeRanges: []fleetnetv1beta1.MonitorConfigStatusCodeRange{
+					{Min: 200, Max: 299},
+					{Min: 301, Max: 302},
+				},
+			},
+		},
+	}
+	want := &armtrafficmanager.MonitorConfig{
+		IntervalInSeconds:         ptr.To[int64](30),
+		Path:                      ptr.To("/healthz"),
+		Port:                      ptr.To[int64](443),
+		Protocol:                  ptr.To(armtrafficmanager.MonitorProtocolHTTPS),
+		TimeoutInSeconds:          ptr.To[int64](10),
+		ToleratedNumberOfFailures: ptr.To[int64](3),
+		CustomHeaders: []*armtrafficmanager.MonitorConfigCustomHeadersItem{
+			{Name: ptr.To("Host"), Value: ptr.To("example.com")},
+		},
+		ExpectedStatusCodeRanges: []*armtrafficmanager.MonitorConfigExpectedStatusCodeRangesItem{
+			{Min: ptr.To[int32](200), Max: ptr.To[int32](299)},
+			{Min: ptr.To[int32](301), Max: ptr.To[int32](302)},
+		},
+	}
+	got := generateAzureTrafficManagerProfile(profile)
+	if diff := cmp.Diff(want, got.Properties.MonitorConfig); diff != "" {
+		t.Errorf("generateAzureTrafficManagerProfile() monitorConfig mismatch (-want, +got):\n%s", diff)
+	}
+	if !EqualAzureTrafficManagerProfile(got, got) {
+		t.Errorf("EqualAzureTrafficManagerProfile() = false, want true for the same profile")
+	}
+}
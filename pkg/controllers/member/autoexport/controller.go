@@ -0,0 +1,197 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package autoexport features the autoexport controller, which creates and deletes ServiceExports for the Services
+// selected by the AutoExportPolicies and ClusterAutoExportPolicies of a member cluster.
+package autoexport
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+// Reconciler reconciles the ServiceExport of a Service with the auto export policies.
+type Reconciler struct {
+	Client client.Client
+	// ReservedNamespaces are the namespaces whose Services are never exported automatically, e.g. kube-system.
+	ReservedNamespaces []string
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
+}
+
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=autoexportpolicies,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=clusterautoexportpolicies,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceexports,verbs=get;list;watch;create;delete
+//+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+// Reconcile creates a ServiceExport for the Service if it is selected by an auto export policy, and deletes the
+// ServiceExport created before once the Service is no longer selected.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	svcRef := klog.KRef(req.Namespace, req.Name)
+	startTime := time.Now()
+	klog.V(2).InfoS("Reconciliation starts", "service", svcRef)
+	defer func() {
+		latency := time.Since(startTime).Milliseconds()
+		klog.V(2).InfoS("Reconciliation ends", "service", svcRef, "latency", latency)
+	}()
+
+	for _, ns := range r.ReservedNamespaces {
+		if req.Namespace == ns {
+			klog.V(4).InfoS("Ignoring the service in a reserved namespace", "service", svcRef)
+			return ctrl.Result{}, nil
+		}
+	}
+
+	selected := false
+	svc := &corev1.Service{}
+	switch err := r.Client.Get(ctx, req.NamespacedName, svc); {
+	case errors.IsNotFound(err):
+		klog.V(4).InfoS("Service is not found", "service", svcRef)
+	case err != nil:
+		klog.ErrorS(err, "Failed to get service", "service", svcRef)
+		return ctrl.Result{}, err
+	case svc.DeletionTimestamp == nil && svc.Spec.Type != corev1.ServiceTypeExternalName:
+		if selected, err = r.isSelected(ctx, svc); err != nil {
+			klog.ErrorS(err, "Failed to evaluate the auto export policies", "service", svcRef)
+			return ctrl.Result{}, err
+		}
+	}
+
+	svcExport := &fleetnetv1alpha1.ServiceExport{}
+	switch err := r.Client.Get(ctx, req.NamespacedName, svcExport); {
+	case errors.IsNotFound(err):
+		if !selected {
+			return ctrl.Result{}, nil
+		}
+		svcExport = &fleetnetv1alpha1.ServiceExport{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: req.Namespace,
+				Name:      req.Name,
+				Labels:    map[string]string{objectmeta.ServiceExportLabelAutoExported: "true"},
+			},
+		}
+		klog.V(2).InfoS("Creating the serviceExport for the service selected by an auto export policy", "service", svcRef)
+		if err := r.Client.Create(ctx, svcExport); err != nil && !errors.IsAlreadyExists(err) {
+			klog.ErrorS(err, "Failed to create serviceExport", "service", svcRef)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	case err != nil:
+		klog.ErrorS(err, "Failed to get serviceExport", "service", svcRef)
+		return ctrl.Result{}, err
+	}
+
+	if selected || svcExport.Labels[objectmeta.ServiceExportLabelAutoExported] != "true" || svcExport.DeletionTimestamp != nil {
+		// The serviceExport is still wanted, has been created by the user, or is being deleted.
+		return ctrl.Result{}, nil
+	}
+	klog.V(2).InfoS("Deleting the serviceExport as the service is no longer selected by any auto export policy", "service", svcRef)
+	if err := r.Client.Delete(ctx, svcExport); err != nil {
+		klog.ErrorS(err, "Failed to delete serviceExport", "service", svcRef)
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	return ctrl.Result{}, nil
+}
+
+// isSelected returns true if the Service is selected by an AutoExportPolicy in its namespace or by a
+// ClusterAutoExportPolicy; policies with invalid selectors select nothing.
+func (r *Reconciler) isSelected(ctx context.Context, svc *corev1.Service) (bool, error) {
+	policyList := &fleetnetv1alpha1.AutoExportPolicyList{}
+	if err := r.Client.List(ctx, policyList, client.InNamespace(svc.Namespace)); err != nil {
+		return false, err
+	}
+	for i := range policyList.Items {
+		if matches(&policyList.Items[i].Spec.ServiceSelector, svc.Labels) {
+			return true, nil
+		}
+	}
+
+	clusterPolicyList := &fleetnetv1alpha1.ClusterAutoExportPolicyList{}
+	if err := r.Client.List(ctx, clusterPolicyList); err != nil {
+		return false, err
+	}
+	if len(clusterPolicyList.Items) == 0 {
+		return false, nil
+	}
+	ns := &corev1.Namespace{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: svc.Namespace}, ns); err != nil {
+		return false, err
+	}
+	for i := range clusterPolicyList.Items {
+		spec := &clusterPolicyList.Items[i].Spec
+		if matches(&spec.NamespaceSelector, ns.Labels) && matches(&spec.ServiceSelector, svc.Labels) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// matches returns true if the labels match the label selector; an invalid selector matches nothing.
+func matches(labelSelector *metav1.LabelSelector, objLabels map[string]string) bool {
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		klog.V(2).InfoS("Ignoring the invalid label selector of an auto export policy", "selector", labelSelector, "error", err)
+		return false
+	}
+	return selector.Matches(labels.Set(objLabels))
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("autoexport").
+		For(&corev1.Service{}).
+		Watches(&fleetnetv1alpha1.ServiceExport{}, &handler.EnqueueRequestForObject{}).
+		Watches(&fleetnetv1alpha1.AutoExportPolicy{}, handler.EnqueueRequestsFromMapFunc(r.servicesInNamespace)).
+		Watches(&fleetnetv1alpha1.ClusterAutoExportPolicy{}, handler.EnqueueRequestsFromMapFunc(r.servicesInNamespace)).
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.servicesOfNamespace),
+			builder.WithPredicates(predicate.LabelChangedPredicate{})).
+		WithOptions(r.ControllerOptions).
+		Complete(metrics.InstrumentReconciler("autoexport", r))
+}
+
+// servicesInNamespace enqueues all the Services in the namespace of the policy, or in all the namespaces for a
+// cluster-scoped policy.
+func (r *Reconciler) servicesInNamespace(ctx context.Context, policy client.Object) []reconcile.Request {
+	return r.listServices(ctx, policy.GetNamespace())
+}
+
+// servicesOfNamespace enqueues all the Services in the namespace.
+func (r *Reconciler) servicesOfNamespace(ctx context.Context, ns client.Object) []reconcile.Request {
+	return r.listServices(ctx, ns.GetName())
+}
+
+func (r *Reconciler) listServices(ctx context.Context, namespace string) []reconcile.Request {
+	svcList := &corev1.ServiceList{}
+	if err := r.Client.List(ctx, svcList, client.InNamespace(namespace)); err != nil {
+		klog.ErrorS(err, "Failed to list services", "namespace", namespace)
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(svcList.Items))
+	for i := range svcList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: svcList.Items[i].Namespace, Name: svcList.Items[i].Name}})
+	}
+	return requests
+}
@@ -0,0 +1,150 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package autoexport
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+const (
+	testNamespace = "work"
+	testName      = "app"
+)
+
+var svcKey = types.NamespacedName{Namespace: testNamespace, Name: testName}
+
+func service(svcLabels map[string]string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Labels: svcLabels},
+		Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP},
+	}
+}
+
+func serviceExport(svcExportLabels map[string]string) *fleetnetv1alpha1.ServiceExport {
+	return &fleetnetv1alpha1.ServiceExport{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Labels: svcExportLabels},
+	}
+}
+
+func autoExportPolicy(matchLabels map[string]string) *fleetnetv1alpha1.AutoExportPolicy {
+	return &fleetnetv1alpha1.AutoExportPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "export-web"},
+		Spec: fleetnetv1alpha1.AutoExportPolicySpec{
+			ServiceSelector: metav1.LabelSelector{MatchLabels: matchLabels},
+		},
+	}
+}
+
+func clusterAutoExportPolicy(nsMatchLabels map[string]string) *fleetnetv1alpha1.ClusterAutoExportPolicy {
+	return &fleetnetv1alpha1.ClusterAutoExportPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "export-all"},
+		Spec: fleetnetv1alpha1.ClusterAutoExportPolicySpec{
+			NamespaceSelector: metav1.LabelSelector{MatchLabels: nsMatchLabels},
+		},
+	}
+}
+
+var autoExported = map[string]string{objectmeta.ServiceExportLabelAutoExported: "true"}
+
+// TestReconcile tests that ServiceExports are created and deleted by the auto export policies.
+func TestReconcile(t *testing.T) {
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: testNamespace, Labels: map[string]string{"team": "payments"}},
+	}
+	externalName := service(map[string]string{"tier": "web"})
+	externalName.Spec.Type = corev1.ServiceTypeExternalName
+
+	testCases := []struct {
+		name               string
+		objects            []client.Object
+		reservedNamespaces []string
+		// wantSvcExportLabels are the labels of the serviceExport; nil means that there should be no serviceExport.
+		wantSvcExportLabels map[string]string
+	}{
+		{
+			name:                "service selected by a policy in its namespace is exported",
+			objects:             []client.Object{service(map[string]string{"tier": "web"}), autoExportPolicy(map[string]string{"tier": "web"})},
+			wantSvcExportLabels: autoExported,
+		},
+		{
+			name:                "service selected by a cluster policy is exported",
+			objects:             []client.Object{namespace, service(nil), clusterAutoExportPolicy(map[string]string{"team": "payments"})},
+			wantSvcExportLabels: autoExported,
+		},
+		{
+			name:    "service in a namespace not selected by the cluster policy is not exported",
+			objects: []client.Object{namespace, service(nil), clusterAutoExportPolicy(map[string]string{"team": "billing"})},
+		},
+		{
+			name:    "service of the ExternalName type is not exported",
+			objects: []client.Object{externalName, autoExportPolicy(map[string]string{"tier": "web"})},
+		},
+		{
+			name:               "service in a reserved namespace is not exported",
+			objects:            []client.Object{service(map[string]string{"tier": "web"}), autoExportPolicy(map[string]string{"tier": "web"})},
+			reservedNamespaces: []string{testNamespace},
+		},
+		{
+			name:    "serviceExport is deleted once the service is no longer selected",
+			objects: []client.Object{service(map[string]string{"tier": "db"}), autoExportPolicy(map[string]string{"tier": "web"}), serviceExport(autoExported)},
+		},
+		{
+			name:    "serviceExport is deleted once the service is deleted",
+			objects: []client.Object{autoExportPolicy(map[string]string{"tier": "web"}), serviceExport(autoExported)},
+		},
+		{
+			name:                "serviceExport created by the user is left alone",
+			objects:             []client.Object{service(nil), serviceExport(map[string]string{"owner": "user"})},
+			wantSvcExportLabels: map[string]string{"owner": "user"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			if err := corev1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.objects...).Build()
+			r := &Reconciler{Client: fakeClient, ReservedNamespaces: tc.reservedNamespaces}
+
+			if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: svcKey}); err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+			got := &fleetnetv1alpha1.ServiceExport{}
+			err := fakeClient.Get(ctx, svcKey, got)
+			if tc.wantSvcExportLabels == nil {
+				if !errors.IsNotFound(err) {
+					t.Errorf("ServiceExport Get() = %v, want not found", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ServiceExport Get() = %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.wantSvcExportLabels, got.Labels); diff != "" {
+				t.Errorf("ServiceExport labels mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package clusterproperty features the ClusterProperty controller, which registers the well-known ClusterProperties
+// of the upstream About API (KEP-2149, API group about.k8s.io) in a member cluster: the ID of the cluster, and the
+// name of the ClusterSet, i.e. the fleet, it belongs to. Workloads and tools built for the upstream Multi-Cluster
+// Services API read the properties to find out which cluster they run in.
+//
+// The ID of a member cluster is the name of its MemberCluster, by which the hub cluster identifies the cluster in the
+// exports and in the status of the ServiceImports; it must stay the same as long as the cluster is in the fleet.
+// The properties are handled as unstructured objects, so that the controller does not depend on the upstream module.
+package clusterproperty
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+)
+
+// GroupVersion is the group version of the upstream About API.
+var GroupVersion = schema.GroupVersion{Group: "about.k8s.io", Version: "v1alpha1"}
+
+// ClusterPropertyGVK is the group version kind of the upstream ClusterProperty.
+var ClusterPropertyGVK = GroupVersion.WithKind("ClusterProperty")
+
+const (
+	// ClusterIDPropertyName is the name of the ClusterProperty which holds the ID of the cluster.
+	ClusterIDPropertyName = "cluster.clusterset.k8s.io"
+	// ClusterSetPropertyName is the name of the ClusterProperty which holds the name of the ClusterSet the cluster
+	// belongs to.
+	ClusterSetPropertyName = "clusterset.k8s.io"
+)
+
+// AddToScheme registers the ClusterProperty kind, and its list, as unstructured objects in the scheme.
+func AddToScheme(scheme *runtime.Scheme) {
+	scheme.AddKnownTypeWithName(ClusterPropertyGVK, &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(GroupVersion.WithKind("ClusterPropertyList"), &unstructured.UnstructuredList{})
+	metav1.AddToGroupVersion(scheme, GroupVersion)
+}
+
+// ValidateClusterID returns an error if the cluster ID is not a valid RFC-1123 DNS label, which the status of the
+// ServiceImports requires.
+func ValidateClusterID(clusterID string) error {
+	if errs := validation.IsDNS1123Label(clusterID); len(errs) != 0 {
+		return fmt.Errorf("invalid cluster ID %q: %v", clusterID, errs)
+	}
+	return nil
+}
+
+// Register validates the ClusterProperties already present in the member cluster against the ID of the cluster,
+// and creates the missing ones; it is meant to run before the controllers start, so that the cluster never exports
+// anything under an ID different from the one registered. It returns an error if the cluster has been registered
+// with a different ID, e.g. when it is still registered in another fleet.
+func Register(ctx context.Context, reader client.Reader, writer client.Writer, clusterID, clusterSetName string) error {
+	if err := ValidateClusterID(clusterID); err != nil {
+		return err
+	}
+	property := newClusterProperty(ClusterIDPropertyName)
+	err := reader.Get(ctx, types.NamespacedName{Name: ClusterIDPropertyName}, property)
+	switch {
+	case errors.IsNotFound(err):
+	case err != nil:
+		return fmt.Errorf("failed to get the cluster property %s: %w", ClusterIDPropertyName, err)
+	default:
+		if value := propertyValue(property); value != clusterID {
+			return fmt.Errorf("the cluster has been registered with the cluster ID %q rather than %q; delete the cluster property %s if the cluster has left its previous cluster set",
+				value, clusterID, ClusterIDPropertyName)
+		}
+	}
+
+	for name, value := range desiredValues(clusterID, clusterSetName) {
+		if err := ensure(ctx, reader, writer, name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reconciler reconciles the well-known ClusterProperties of a member cluster.
+type Reconciler struct {
+	Client client.Client
+	// ClusterID is the ID of the member cluster.
+	ClusterID string
+	// ClusterSetName is the name of the ClusterSet the member cluster belongs to; the ClusterSet property is not
+	// registered if it is empty.
+	ClusterSetName string
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
+}
+
+//+kubebuilder:rbac:groups=about.k8s.io,resources=clusterproperties,verbs=get;list;watch;create;update
+
+// Reconcile recreates the well-known ClusterProperty if it has been deleted, and resets its value if it has been
+// changed.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	propertyKRef := klog.KRef("", req.Name)
+	startTime := time.Now()
+	klog.V(2).InfoS("Reconciliation starts", "clusterProperty", propertyKRef)
+	defer func() {
+		latency := time.Since(startTime).Milliseconds()
+		klog.V(2).InfoS("Reconciliation ends", "clusterProperty", propertyKRef, "latency", latency)
+	}()
+
+	desired, ok := desiredValues(r.ClusterID, r.ClusterSetName)[req.Name]
+	if !ok {
+		klog.V(4).InfoS("Ignoring the cluster property which is not managed by fleet networking", "clusterProperty", propertyKRef)
+		return ctrl.Result{}, nil
+	}
+
+	if err := ensure(ctx, r.Client, r.Client, req.Name, desired); err != nil {
+		klog.ErrorS(err, "Failed to reconcile the cluster property", "clusterProperty", propertyKRef)
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// ensure creates the ClusterProperty with the desired value if it does not exist, and resets its value if it has
+// been changed; the properties must stay the same as long as the cluster is in the fleet.
+func ensure(ctx context.Context, reader client.Reader, writer client.Writer, name, desired string) error {
+	propertyKRef := klog.KRef("", name)
+	property := newClusterProperty(name)
+	if err := reader.Get(ctx, types.NamespacedName{Name: name}, property); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get the cluster property %s: %w", name, err)
+		}
+		property = newClusterProperty(name)
+		if err := unstructured.SetNestedField(property.Object, desired, "spec", "value"); err != nil {
+			return err
+		}
+		klog.V(2).InfoS("Creating the cluster property", "clusterProperty", propertyKRef, "value", desired)
+		if err := writer.Create(ctx, property); err != nil {
+			return fmt.Errorf("failed to create the cluster property %s: %w", name, err)
+		}
+		return nil
+	}
+
+	value := propertyValue(property)
+	if value == desired {
+		return nil
+	}
+	klog.V(2).InfoS("Resetting the value of the cluster property", "clusterProperty", propertyKRef, "value", desired, "oldValue", value)
+	if err := unstructured.SetNestedField(property.Object, desired, "spec", "value"); err != nil {
+		return err
+	}
+	if err := writer.Update(ctx, property); err != nil {
+		return fmt.Errorf("failed to reset the value of the cluster property %s: %w", name, err)
+	}
+	return nil
+}
+
+// desiredValues returns the values of the ClusterProperties managed by fleet networking, keyed by their names.
+func desiredValues(clusterID, clusterSetName string) map[string]string {
+	values := map[string]string{ClusterIDPropertyName: clusterID}
+	if clusterSetName != "" {
+		values[ClusterSetPropertyName] = clusterSetName
+	}
+	return values
+}
+
+// newClusterProperty returns an empty ClusterProperty of the given name.
+func newClusterProperty(name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(ClusterPropertyGVK)
+	obj.SetName(name)
+	return obj
+}
+
+// propertyValue returns the value of the ClusterProperty.
+func propertyValue(property *unstructured.Unstructured) string {
+	value, _, _ := unstructured.NestedString(property.Object, "spec", "value")
+	return value
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	managed := desiredValues(r.ClusterID, r.ClusterSetName)
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(ClusterPropertyGVK)
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("clusterproperty").
+		For(obj, builder.WithPredicates(predicate.NewPredicateFuncs(func(o client.Object) bool {
+			_, ok := managed[o.GetName()]
+			return ok
+		}))).
+		WithOptions(r.ControllerOptions).
+		Complete(metrics.InstrumentReconciler("clusterproperty", r))
+}
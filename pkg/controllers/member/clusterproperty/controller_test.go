@@ -0,0 +1,146 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package clusterproperty
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func clusterProperty(name, value string) *unstructured.Unstructured {
+	property := newClusterProperty(name)
+	property.Object["spec"] = map[string]interface{}{"value": value}
+	return property
+}
+
+func fakeClient(objects ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	AddToScheme(scheme)
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+}
+
+// propertyValues returns the values of the ClusterProperties in the cluster, keyed by their names.
+func propertyValues(t *testing.T, c client.Client) map[string]string {
+	values := make(map[string]string)
+	for _, name := range []string{ClusterIDPropertyName, ClusterSetPropertyName} {
+		property := newClusterProperty(name)
+		if err := c.Get(context.Background(), types.NamespacedName{Name: name}, property); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				t.Fatalf("ClusterProperty Get(%s) = %v, want no error", name, err)
+			}
+			continue
+		}
+		values[name] = propertyValue(property)
+	}
+	return values
+}
+
+// TestRegister tests the Register function.
+func TestRegister(t *testing.T) {
+	testCases := []struct {
+		name           string
+		clusterID      string
+		clusterSetName string
+		properties     []client.Object
+		want           map[string]string
+		wantErr        string
+	}{
+		{
+			name:      "cluster ID is registered",
+			clusterID: "member-1",
+			want:      map[string]string{ClusterIDPropertyName: "member-1"},
+		},
+		{
+			name:           "cluster ID and cluster set are registered",
+			clusterID:      "member-1",
+			clusterSetName: "fleet",
+			properties:     []client.Object{clusterProperty(ClusterSetPropertyName, "other-fleet")},
+			want:           map[string]string{ClusterIDPropertyName: "member-1", ClusterSetPropertyName: "fleet"},
+		},
+		{
+			name:       "cluster ID has been registered",
+			clusterID:  "member-1",
+			properties: []client.Object{clusterProperty(ClusterIDPropertyName, "member-1")},
+			want:       map[string]string{ClusterIDPropertyName: "member-1"},
+		},
+		{
+			name:       "cluster has been registered with a different ID",
+			clusterID:  "member-1",
+			properties: []client.Object{clusterProperty(ClusterIDPropertyName, "member-2")},
+			want:       map[string]string{ClusterIDPropertyName: "member-2"},
+			wantErr:    `registered with the cluster ID "member-2" rather than "member-1"`,
+		},
+		{
+			name:      "invalid cluster ID",
+			clusterID: "Member_1",
+			want:      map[string]string{},
+			wantErr:   `invalid cluster ID "Member_1"`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := fakeClient(tc.properties...)
+			err := Register(context.Background(), c, c, tc.clusterID, tc.clusterSetName)
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("Register() got error %v, want no error", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Fatalf("Register() got error %v, want error containing %q", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, propertyValues(t, c)); diff != "" {
+				t.Errorf("ClusterProperties mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestReconcile tests that the ClusterProperties are recreated and reset by the controller.
+func TestReconcile(t *testing.T) {
+	testCases := []struct {
+		name         string
+		propertyName string
+		properties   []client.Object
+		want         map[string]string
+	}{
+		{
+			name:         "deleted cluster ID is recreated",
+			propertyName: ClusterIDPropertyName,
+			want:         map[string]string{ClusterIDPropertyName: "member-1"},
+		},
+		{
+			name:         "changed cluster set is reset",
+			propertyName: ClusterSetPropertyName,
+			properties:   []client.Object{clusterProperty(ClusterSetPropertyName, "other-fleet")},
+			want:         map[string]string{ClusterSetPropertyName: "fleet"},
+		},
+		{
+			name:         "unmanaged property is ignored",
+			propertyName: "region.example.com",
+			want:         map[string]string{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := fakeClient(tc.properties...)
+			r := &Reconciler{Client: c, ClusterID: "member-1", ClusterSetName: "fleet"}
+			if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: tc.propertyName}}); err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.want, propertyValues(t, c)); diff != "" {
+				t.Errorf("ClusterProperties mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
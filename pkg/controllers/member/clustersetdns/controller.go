@@ -0,0 +1,165 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package clustersetdns features the clustersetdns controller deployed in member cluster to program the records of
+// the clusterset.local zone, so that <service>.<namespace>.svc.clusterset.local resolves to the ClusterSetIP of a
+// ServiceImport, or to the addresses of its endpoints if it is headless, following the DNS specification of the
+// Multi-Cluster Services API (KEP-1645).
+//
+// The records are rendered into a zone file kept in a ConfigMap, which is expected to be mounted into CoreDNS and
+// served with its file plugin, e.g.
+//
+//	clusterset.local:53 {
+//	    file /etc/coredns/clusterset/db.clusterset.local clusterset.local {
+//	        reload 5s
+//	    }
+//	}
+package clustersetdns
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+)
+
+const (
+	// ZoneFileKey is the key of the zone file in the ConfigMap.
+	ZoneFileKey = "db." + Zone
+
+	// zoneSerialAnnotation is the annotation of the ConfigMap which keeps the serial of the zone, which is increased
+	// whenever the records change so that CoreDNS reloads the zone.
+	zoneSerialAnnotation = "networking.fleet.azure.com/zone-serial"
+
+	multiClusterServiceKind = "MultiClusterService"
+)
+
+// Reconciler reconciles the ConfigMap of the clusterset.local zone; all the events are mapped to the ConfigMap, as
+// the zone is rendered from all the ServiceImports of the member cluster.
+type Reconciler struct {
+	Client client.Client
+	// The derived Services and the imported EndpointSlices are in the fleet system namespace, and so is the ConfigMap.
+	FleetSystemNamespace string
+	// ConfigMapName is the name of the ConfigMap keeping the zone file.
+	ConfigMapName string
+	// DefaultTTLSeconds is the TTL of the records of the ServiceImports which have no TTL hint in their status.
+	DefaultTTLSeconds int64
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
+}
+
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=multiclusterservices,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch
+//+kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch
+
+// Reconcile renders the records of all the ServiceImports into the zone file, and updates the ConfigMap if the
+// records have changed.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	configMapKRef := klog.KRef(req.Namespace, req.Name)
+	startTime := time.Now()
+	klog.V(2).InfoS("Reconciliation starts", "configMap", configMapKRef)
+	defer func() {
+		latency := time.Since(startTime).Milliseconds()
+		klog.V(2).InfoS("Reconciliation ends", "configMap", configMapKRef, "latency", latency)
+	}()
+
+	records, err := r.recordBuilder().build(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := r.Client.Get(ctx, req.NamespacedName, configMap); err != nil {
+		if !errors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to get configMap", "configMap", configMapKRef)
+			return ctrl.Result{}, err
+		}
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: req.Namespace, Name: req.Name},
+		}
+		serial := nextSerial(0, startTime)
+		setZone(configMap, renderZone(records, serial, r.DefaultTTLSeconds), serial)
+		klog.V(2).InfoS("Creating configMap of the clusterset zone", "configMap", configMapKRef, "numberOfRecords", len(records))
+		if err := r.Client.Create(ctx, configMap); err != nil {
+			klog.ErrorS(err, "Failed to create configMap", "configMap", configMapKRef)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// An invalid or missing serial is treated as 0, so that the zone is rendered with a new serial.
+	currentSerial, _ := strconv.ParseUint(configMap.Annotations[zoneSerialAnnotation], 10, 32)
+	if configMap.Data[ZoneFileKey] == renderZone(records, uint32(currentSerial), r.DefaultTTLSeconds) {
+		klog.V(4).InfoS("Records of the clusterset zone are not changed", "configMap", configMapKRef)
+		return ctrl.Result{}, nil
+	}
+	serial := nextSerial(uint32(currentSerial), startTime)
+	setZone(configMap, renderZone(records, serial, r.DefaultTTLSeconds), serial)
+	klog.V(2).InfoS("Updating configMap of the clusterset zone", "configMap", configMapKRef, "numberOfRecords", len(records), "serial", serial)
+	if err := r.Client.Update(ctx, configMap); err != nil {
+		klog.ErrorS(err, "Failed to update configMap", "configMap", configMapKRef)
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// nextSerial returns the serial of the zone after the current one; it is the current time in seconds, as long as it
+// keeps increasing the serial.
+func nextSerial(current uint32, now time.Time) uint32 {
+	if serial := uint32(now.Unix()); serial > current {
+		return serial
+	}
+	return current + 1
+}
+
+func setZone(configMap *corev1.ConfigMap, zone string, serial uint32) {
+	if configMap.Annotations == nil {
+		configMap.Annotations = map[string]string{}
+	}
+	configMap.Annotations[zoneSerialAnnotation] = strconv.FormatUint(uint64(serial), 10)
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+	configMap.Data[ZoneFileKey] = zone
+}
+
+func (r *Reconciler) recordBuilder() *recordBuilder {
+	return &recordBuilder{
+		client:               r.Client,
+		fleetSystemNamespace: r.FleetSystemNamespace,
+		defaultTTLSeconds:    r.DefaultTTLSeconds,
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	key := types.NamespacedName{Namespace: r.FleetSystemNamespace, Name: r.ConfigMapName}
+	enqueueConfigMap := handler.EnqueueRequestsFromMapFunc(func(_ context.Context, object client.Object) []reconcile.Request {
+		if object.GetNamespace() != key.Namespace || object.GetName() != key.Name {
+			return []reconcile.Request{}
+		}
+		return []reconcile.Request{{NamespacedName: key}}
+	})
+	return watchRecordSources(ctrl.NewControllerManagedBy(mgr).Named("clustersetdns"), r.FleetSystemNamespace, key).
+		// The ConfigMap is watched so that the zone file is restored if it is modified or deleted.
+		Watches(&corev1.ConfigMap{}, enqueueConfigMap).
+		WithOptions(r.ControllerOptions).
+		Complete(metrics.InstrumentReconciler("clustersetdns", r))
+}
@@ -0,0 +1,331 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package clustersetdns
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+const (
+	testNamespace        = "work"
+	fleetSystemNamespace = "fleet-system"
+	configMapName        = "clusterset-dns"
+)
+
+func TestMain(m *testing.M) {
+	// Add custom APIs to the runtime scheme
+	if err := fleetnetv1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		log.Fatalf("failed to add custom APIs to the runtime scheme: %v", err)
+	}
+	os.Exit(m.Run())
+}
+
+func clusterSetIPServiceImport() *fleetnetv1alpha1.ServiceImport {
+	return &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      "app",
+			Labels:    map[string]string{objectmeta.ServiceImportLabelDerivedService: "work-app"},
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Type: fleetnetv1alpha1.ClusterSetIP,
+			Ports: []fleetnetv1alpha1.ServicePort{
+				{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80},
+				{Protocol: corev1.ProtocolTCP, Port: 8080},
+			},
+			Clusters:      []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-1"}},
+			DNSTTLSeconds: ptr.To[int64](30),
+		},
+	}
+}
+
+func headlessServiceImport() *fleetnetv1alpha1.ServiceImport {
+	return &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      "db",
+			Labels:    map[string]string{objectmeta.ServiceImportLabelDerivedService: "work-db"},
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Type:     fleetnetv1alpha1.Headless,
+			Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-1"}},
+		},
+	}
+}
+
+func derivedService(name, clusterIP string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: fleetSystemNamespace, Name: name},
+		Spec:       corev1.ServiceSpec{ClusterIP: clusterIP},
+	}
+}
+
+func importedEndpointSlice() *discoveryv1.EndpointSlice {
+	return &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: fleetSystemNamespace,
+			Name:      "work-db-abcde",
+			Labels: map[string]string{
+				discoveryv1.LabelServiceName:               "work-db",
+				objectmeta.EndpointSliceLabelSourceCluster: "member-1",
+			},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints: []discoveryv1.Endpoint{
+			{
+				Addresses: []string{"10.1.0.1"},
+				Hostname:  ptr.To("db-0-member-1"),
+			},
+			{
+				Addresses:  []string{"10.1.0.2"},
+				Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(false)},
+			},
+			{
+				Addresses: []string{"10.1.0.3"},
+			},
+		},
+		Ports: []discoveryv1.EndpointPort{
+			{Name: ptr.To("sql"), Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To[int32](5432)},
+		},
+	}
+}
+
+// TestReconcile tests the Reconcile method.
+func TestReconcile(t *testing.T) {
+	now := time.Now()
+	testCases := []struct {
+		name        string
+		objs        []client.Object
+		wantZone    string
+		wantUpdated bool
+	}{
+		{
+			name: "no serviceImports",
+			objs: []client.Object{},
+			wantZone: `$ORIGIN clusterset.local.
+$TTL 5
+@ IN SOA ns.dns.clusterset.local. hostmaster.clusterset.local. SERIAL 7200 1800 86400 5
+`,
+			wantUpdated: true,
+		},
+		{
+			name: "clusterSetIP serviceImport",
+			objs: []client.Object{
+				clusterSetIPServiceImport(),
+				derivedService("work-app", "10.0.0.10"),
+			},
+			wantZone: `$ORIGIN clusterset.local.
+$TTL 5
+@ IN SOA ns.dns.clusterset.local. hostmaster.clusterset.local. SERIAL 7200 1800 86400 5
+_http._tcp.app.work.svc 30 IN SRV 0 100 80 app.work.svc.clusterset.local.
+app.work.svc 30 IN A 10.0.0.10
+`,
+			wantUpdated: true,
+		},
+		{
+			name: "dual-stack clusterSetIP serviceImport",
+			objs: []client.Object{
+				func() *fleetnetv1alpha1.ServiceImport {
+					serviceImport := clusterSetIPServiceImport()
+					serviceImport.Status.IPs = []string{"10.255.0.1", "fd00:255::1"}
+					return serviceImport
+				}(),
+				derivedService("work-app", "10.255.0.1"),
+			},
+			wantZone: `$ORIGIN clusterset.local.
+$TTL 5
+@ IN SOA ns.dns.clusterset.local. hostmaster.clusterset.local. SERIAL 7200 1800 86400 5
+_http._tcp.app.work.svc 30 IN SRV 0 100 80 app.work.svc.clusterset.local.
+app.work.svc 30 IN A 10.255.0.1
+app.work.svc 30 IN AAAA fd00:255::1
+`,
+			wantUpdated: true,
+		},
+		{
+			name: "clusterSetIP serviceImport without derived service",
+			objs: []client.Object{
+				clusterSetIPServiceImport(),
+			},
+			wantZone: `$ORIGIN clusterset.local.
+$TTL 5
+@ IN SOA ns.dns.clusterset.local. hostmaster.clusterset.local. SERIAL 7200 1800 86400 5
+`,
+			wantUpdated: true,
+		},
+		{
+			name: "headless serviceImport",
+			objs: []client.Object{
+				headlessServiceImport(),
+				derivedService("work-db", corev1.ClusterIPNone),
+				importedEndpointSlice(),
+			},
+			wantZone: `$ORIGIN clusterset.local.
+$TTL 5
+@ IN SOA ns.dns.clusterset.local. hostmaster.clusterset.local. SERIAL 7200 1800 86400 5
+_sql._tcp.db.work.svc 5 IN SRV 0 100 5432 db-0.member-1.db.work.svc.clusterset.local.
+db-0.member-1.db.work.svc 5 IN A 10.1.0.1
+db.work.svc 5 IN A 10.1.0.1
+db.work.svc 5 IN A 10.1.0.3
+`,
+			wantUpdated: true,
+		},
+		{
+			name: "serviceImport without clusters",
+			objs: []client.Object{
+				&fleetnetv1alpha1.ServiceImport{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: testNamespace,
+						Name:      "app",
+						Labels:    map[string]string{objectmeta.ServiceImportLabelDerivedService: "work-app"},
+					},
+				},
+				derivedService("work-app", "10.0.0.10"),
+			},
+			wantZone: `$ORIGIN clusterset.local.
+$TTL 5
+@ IN SOA ns.dns.clusterset.local. hostmaster.clusterset.local. SERIAL 7200 1800 86400 5
+`,
+			wantUpdated: true,
+		},
+		{
+			name: "unchanged records",
+			objs: []client.Object{
+				clusterSetIPServiceImport(),
+				derivedService("work-app", "10.0.0.10"),
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace:   fleetSystemNamespace,
+						Name:        configMapName,
+						Annotations: map[string]string{zoneSerialAnnotation: "7"},
+					},
+					Data: map[string]string{
+						ZoneFileKey: `$ORIGIN clusterset.local.
+$TTL 5
+@ IN SOA ns.dns.clusterset.local. hostmaster.clusterset.local. 7 7200 1800 86400 5
+_http._tcp.app.work.svc 30 IN SRV 0 100 80 app.work.svc.clusterset.local.
+app.work.svc 30 IN A 10.0.0.10
+`,
+					},
+				},
+			},
+			wantZone: `$ORIGIN clusterset.local.
+$TTL 5
+@ IN SOA ns.dns.clusterset.local. hostmaster.clusterset.local. 7 7200 1800 86400 5
+_http._tcp.app.work.svc 30 IN SRV 0 100 80 app.work.svc.clusterset.local.
+app.work.svc 30 IN A 10.0.0.10
+`,
+		},
+		{
+			name: "changed records",
+			objs: []client.Object{
+				clusterSetIPServiceImport(),
+				derivedService("work-app", "10.0.0.10"),
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace:   fleetSystemNamespace,
+						Name:        configMapName,
+						Annotations: map[string]string{zoneSerialAnnotation: "7"},
+					},
+					Data: map[string]string{ZoneFileKey: "stale"},
+				},
+			},
+			wantZone: `$ORIGIN clusterset.local.
+$TTL 5
+@ IN SOA ns.dns.clusterset.local. hostmaster.clusterset.local. SERIAL 7200 1800 86400 5
+_http._tcp.app.work.svc 30 IN SRV 0 100 80 app.work.svc.clusterset.local.
+app.work.svc 30 IN A 10.0.0.10
+`,
+			wantUpdated: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(tc.objs...).
+				Build()
+			r := &Reconciler{
+				Client:               fakeClient,
+				FleetSystemNamespace: fleetSystemNamespace,
+				ConfigMapName:        configMapName,
+				DefaultTTLSeconds:    5,
+			}
+			key := types.NamespacedName{Namespace: fleetSystemNamespace, Name: configMapName}
+			if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+
+			got := &corev1.ConfigMap{}
+			if err := fakeClient.Get(context.Background(), key, got); err != nil {
+				t.Fatalf("configMap Get() = %v, want no error", err)
+			}
+			wantZone := tc.wantZone
+			if tc.wantUpdated {
+				serial := got.Annotations[zoneSerialAnnotation]
+				if s, err := strconv.ParseInt(serial, 10, 64); err != nil || s < now.Unix() {
+					t.Errorf("configMap serial = %q, want a serial no less than %d", serial, now.Unix())
+				}
+				wantZone = strings.Replace(wantZone, "SERIAL", serial, 1)
+			}
+			if diff := cmp.Diff(wantZone, got.Data[ZoneFileKey]); diff != "" {
+				t.Errorf("configMap zone mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestNextSerial tests the nextSerial function.
+func TestNextSerial(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	testCases := []struct {
+		name    string
+		current uint32
+		want    uint32
+	}{
+		{
+			name:    "older serial",
+			current: 7,
+			want:    1700000000,
+		},
+		{
+			name:    "serial of the current time",
+			current: 1700000000,
+			want:    1700000001,
+		},
+		{
+			name:    "newer serial",
+			current: 1700000005,
+			want:    1700000006,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := nextSerial(tc.current, now); got != tc.want {
+				t.Errorf("nextSerial(%d) = %d, want %d", tc.current, got, tc.want)
+			}
+		})
+	}
+}
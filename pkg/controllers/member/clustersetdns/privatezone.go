@@ -0,0 +1,200 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package clustersetdns
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/privatedns/armprivatedns"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	"go.goms.io/fleet-networking/pkg/common/azureerrors"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+)
+
+const (
+	// privateZoneMetadataCluster is the metadata of the record sets written by the PrivateZoneReconciler, whose value
+	// is the ID of the member cluster; the metadata keys of Azure Private DNS only allow alphanumerics and underscores.
+	privateZoneMetadataCluster = "fleet_networking_member_cluster"
+)
+
+// recordSetKey identifies a record set of the Azure Private DNS zone.
+type recordSetKey struct {
+	recordType armprivatedns.RecordType
+	name       string
+}
+
+// PrivateZoneReconciler reconciles the records of the imported services in an Azure Private DNS zone linked to the
+// virtual networks of the member clusters, so that the VMs and the other consumers outside Kubernetes in the same
+// virtual networks can resolve the services by <service>.<namespace>.svc.<zone>.
+//
+// As the ClusterSetIPs are only reachable in the member cluster, the ClusterSetIP services are resolved to the load
+// balancer ingress IPs of their derived Services, e.g. the ones of the MultiClusterServices, and the headless services
+// are resolved to the IPs of their ready endpoints.
+type PrivateZoneReconciler struct {
+	Client client.Client
+	// The derived Services and the imported EndpointSlices are in the fleet system namespace.
+	FleetSystemNamespace string
+	// MemberClusterID is the ID of the member cluster; the record sets written by the member cluster are marked with
+	// it, so that the member clusters sharing the same zone do not overwrite the record sets of each other.
+	MemberClusterID string
+
+	RecordSetsClient *armprivatedns.RecordSetsClient
+	// ResourceGroupName and ZoneName locate the Azure Private DNS zone.
+	ResourceGroupName string
+	ZoneName          string
+	// DefaultTTLSeconds is the TTL of the records of the ServiceImports which have no TTL hint in their status.
+	DefaultTTLSeconds int64
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
+}
+
+// Reconcile writes the record sets of all the ServiceImports into the Azure Private DNS zone, and deletes the stale
+// record sets written by the member cluster.
+func (r *PrivateZoneReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	zoneKRef := klog.KRef(r.ResourceGroupName, r.ZoneName)
+	startTime := time.Now()
+	klog.V(2).InfoS("Reconciliation starts", "privateDNSZone", zoneKRef)
+	defer func() {
+		latency := time.Since(startTime).Milliseconds()
+		klog.V(2).InfoS("Reconciliation ends", "privateDNSZone", zoneKRef, "latency", latency)
+	}()
+
+	builder := &recordBuilder{
+		client:                 r.Client,
+		fleetSystemNamespace:   r.FleetSystemNamespace,
+		defaultTTLSeconds:      r.DefaultTTLSeconds,
+		useLoadBalancerIngress: true,
+	}
+	records, err := builder.build(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	desired := r.recordSetsOf(records)
+
+	owned := make(map[recordSetKey]*armprivatedns.RecordSet)
+	others := make(map[recordSetKey]bool)
+	pager := r.RecordSetsClient.NewListPager(r.ResourceGroupName, r.ZoneName, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			klog.ErrorS(err, "Failed to list record sets of private DNS zone", "privateDNSZone", zoneKRef)
+			return ctrl.Result{}, err
+		}
+		for _, recordSet := range page.Value {
+			if recordSet == nil || recordSet.Name == nil || recordSet.Type == nil {
+				continue
+			}
+			// The type of the record set is in the form of Microsoft.Network/privateDnsZones/<record type>.
+			key := recordSetKey{recordType: armprivatedns.RecordType(path.Base(*recordSet.Type)), name: *recordSet.Name}
+			if recordSet.Properties != nil && ptr.Deref(recordSet.Properties.Metadata[privateZoneMetadataCluster], "") == r.MemberClusterID {
+				owned[key] = recordSet
+				continue
+			}
+			others[key] = true
+		}
+	}
+
+	for key, recordSet := range desired {
+		if others[key] {
+			klog.V(2).InfoS("Skipping record set which is not written by the member cluster", "privateDNSZone", zoneKRef, "recordType", key.recordType, "name", key.name)
+			continue
+		}
+		if current, ok := owned[key]; ok && equalRecordSets(current.Properties, recordSet.Properties) {
+			continue
+		}
+		klog.V(2).InfoS("Writing record set of private DNS zone", "privateDNSZone", zoneKRef, "recordType", key.recordType, "name", key.name)
+		if _, err := r.RecordSetsClient.CreateOrUpdate(ctx, r.ResourceGroupName, r.ZoneName, key.recordType, key.name, recordSet, nil); err != nil {
+			klog.ErrorS(err, "Failed to write record set of private DNS zone", "privateDNSZone", zoneKRef, "recordType", key.recordType, "name", key.name)
+			return ctrl.Result{}, err
+		}
+	}
+	for key := range owned {
+		if _, ok := desired[key]; ok {
+			continue
+		}
+		klog.V(2).InfoS("Deleting stale record set of private DNS zone", "privateDNSZone", zoneKRef, "recordType", key.recordType, "name", key.name)
+		if _, err := r.RecordSetsClient.Delete(ctx, r.ResourceGroupName, r.ZoneName, key.recordType, key.name, nil); err != nil && !azureerrors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to delete record set of private DNS zone", "privateDNSZone", zoneKRef, "recordType", key.recordType, "name", key.name)
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// recordSetsOf groups the records into the record sets of the Azure Private DNS zone.
+func (r *PrivateZoneReconciler) recordSetsOf(records []record) map[recordSetKey]armprivatedns.RecordSet {
+	recordSets := make(map[recordSetKey]armprivatedns.RecordSet)
+	for _, rec := range sortRecords(records) {
+		key := recordSetKey{recordType: armprivatedns.RecordType(rec.rrType), name: rec.name}
+		recordSet, ok := recordSets[key]
+		if !ok {
+			recordSet = armprivatedns.RecordSet{
+				Properties: &armprivatedns.RecordSetProperties{
+					TTL:      ptr.To(rec.ttl),
+					Metadata: map[string]*string{privateZoneMetadataCluster: ptr.To(r.MemberClusterID)},
+				},
+			}
+		}
+		switch key.recordType {
+		case armprivatedns.RecordTypeA:
+			recordSet.Properties.ARecords = append(recordSet.Properties.ARecords, &armprivatedns.ARecord{IPv4Address: ptr.To(rec.data)})
+		case armprivatedns.RecordTypeAAAA:
+			recordSet.Properties.AaaaRecords = append(recordSet.Properties.AaaaRecords, &armprivatedns.AaaaRecord{IPv6Address: ptr.To(rec.data)})
+		case armprivatedns.RecordTypeSRV:
+			recordSet.Properties.SrvRecords = append(recordSet.Properties.SrvRecords, &armprivatedns.SrvRecord{
+				Priority: ptr.To[int32](0),
+				Weight:   ptr.To[int32](100),
+				Port:     ptr.To(rec.port),
+				Target:   ptr.To(fmt.Sprintf("%s.%s", rec.target, r.ZoneName)),
+			})
+		}
+		recordSets[key] = recordSet
+	}
+	return recordSets
+}
+
+// equalRecordSets returns true if the record sets have the same TTL and records, regardless of the order of the
+// records.
+func equalRecordSets(current, desired *armprivatedns.RecordSetProperties) bool {
+	if current == nil || desired == nil {
+		return current == desired
+	}
+	return ptr.Deref(current.TTL, 0) == ptr.Deref(desired.TTL, 0) && slices.Equal(recordDataOf(current), recordDataOf(desired))
+}
+
+func recordDataOf(properties *armprivatedns.RecordSetProperties) []string {
+	var data []string
+	for _, a := range properties.ARecords {
+		data = append(data, "A "+ptr.Deref(a.IPv4Address, ""))
+	}
+	for _, aaaa := range properties.AaaaRecords {
+		data = append(data, "AAAA "+ptr.Deref(aaaa.IPv6Address, ""))
+	}
+	for _, srv := range properties.SrvRecords {
+		data = append(data, fmt.Sprintf("SRV %d %d %d %s", ptr.Deref(srv.Priority, 0), ptr.Deref(srv.Weight, 0), ptr.Deref(srv.Port, 0), ptr.Deref(srv.Target, "")))
+	}
+	slices.Sort(data)
+	return data
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PrivateZoneReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	key := types.NamespacedName{Name: r.ZoneName}
+	return watchRecordSources(ctrl.NewControllerManagedBy(mgr).Named("clustersetdns-privatezone"), r.FleetSystemNamespace, key).
+		WithOptions(r.ControllerOptions).
+		Complete(metrics.InstrumentReconciler("clustersetdns-privatezone", r))
+}
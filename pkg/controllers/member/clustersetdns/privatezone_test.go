@@ -0,0 +1,242 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package clustersetdns
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azcorefake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/privatedns/armprivatedns"
+	privatednsfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/privatedns/armprivatedns/fake"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	testResourceGroup   = "rg"
+	testZoneName        = "clusterset.contoso.internal"
+	testMemberClusterID = "member-1"
+)
+
+// fakeRecordSets is an in-memory Azure Private DNS zone, which records the record sets written and deleted.
+type fakeRecordSets struct {
+	recordSets map[recordSetKey]armprivatedns.RecordSet
+	written    []recordSetKey
+	deleted    []recordSetKey
+}
+
+func (f *fakeRecordSets) newClient(t *testing.T) *armprivatedns.RecordSetsClient {
+	server := privatednsfake.RecordSetsServer{
+		CreateOrUpdate: func(_ context.Context, _ string, _ string, recordType armprivatedns.RecordType, name string, parameters armprivatedns.RecordSet, _ *armprivatedns.RecordSetsClientCreateOrUpdateOptions) (resp azcorefake.Responder[armprivatedns.RecordSetsClientCreateOrUpdateResponse], errResp azcorefake.ErrorResponder) {
+			key := recordSetKey{recordType: recordType, name: name}
+			parameters.Name = ptr.To(name)
+			parameters.Type = ptr.To("Microsoft.Network/privateDnsZones/" + string(recordType))
+			f.recordSets[key] = parameters
+			f.written = append(f.written, key)
+			resp.SetResponse(http.StatusOK, armprivatedns.RecordSetsClientCreateOrUpdateResponse{RecordSet: parameters}, nil)
+			return resp, errResp
+		},
+		Delete: func(_ context.Context, _ string, _ string, recordType armprivatedns.RecordType, name string, _ *armprivatedns.RecordSetsClientDeleteOptions) (resp azcorefake.Responder[armprivatedns.RecordSetsClientDeleteResponse], errResp azcorefake.ErrorResponder) {
+			key := recordSetKey{recordType: recordType, name: name}
+			delete(f.recordSets, key)
+			f.deleted = append(f.deleted, key)
+			resp.SetResponse(http.StatusOK, armprivatedns.RecordSetsClientDeleteResponse{}, nil)
+			return resp, errResp
+		},
+		NewListPager: func(_ string, _ string, _ *armprivatedns.RecordSetsClientListOptions) (resp azcorefake.PagerResponder[armprivatedns.RecordSetsClientListResponse]) {
+			page := armprivatedns.RecordSetsClientListResponse{}
+			for key, recordSet := range f.recordSets {
+				recordSet := recordSet
+				recordSet.Name = ptr.To(key.name)
+				recordSet.Type = ptr.To("Microsoft.Network/privateDnsZones/" + string(key.recordType))
+				page.Value = append(page.Value, &recordSet)
+			}
+			resp.AddPage(http.StatusOK, page, nil)
+			return resp
+		},
+	}
+	clientFactory, err := armprivatedns.NewClientFactory("sub", &azcorefake.TokenCredential{}, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: privatednsfake.NewRecordSetsServerTransport(&server),
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewClientFactory() = %v, want no error", err)
+	}
+	return clientFactory.NewRecordSetsClient()
+}
+
+func ownedRecordSet(ttl int64, properties armprivatedns.RecordSetProperties) armprivatedns.RecordSet {
+	properties.TTL = ptr.To(ttl)
+	properties.Metadata = map[string]*string{privateZoneMetadataCluster: ptr.To(testMemberClusterID)}
+	return armprivatedns.RecordSet{Properties: &properties}
+}
+
+func loadBalancerDerivedService() *corev1.Service {
+	service := derivedService("work-app", "10.0.0.10")
+	service.Spec.Type = corev1.ServiceTypeLoadBalancer
+	service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "10.224.0.7"}}
+	return service
+}
+
+// TestPrivateZoneReconcile tests the PrivateZoneReconciler.Reconcile method.
+func TestPrivateZoneReconcile(t *testing.T) {
+	appA := recordSetKey{recordType: armprivatedns.RecordTypeA, name: "app.work.svc"}
+	appSRV := recordSetKey{recordType: armprivatedns.RecordTypeSRV, name: "_http._tcp.app.work.svc"}
+	dbA := recordSetKey{recordType: armprivatedns.RecordTypeA, name: "db.work.svc"}
+	podA := recordSetKey{recordType: armprivatedns.RecordTypeA, name: "db-0.member-1.db.work.svc"}
+	dbSRV := recordSetKey{recordType: armprivatedns.RecordTypeSRV, name: "_sql._tcp.db.work.svc"}
+	wantAppA := ownedRecordSet(30, armprivatedns.RecordSetProperties{
+		ARecords: []*armprivatedns.ARecord{{IPv4Address: ptr.To("10.224.0.7")}},
+	})
+	wantAppSRV := ownedRecordSet(30, armprivatedns.RecordSetProperties{
+		SrvRecords: []*armprivatedns.SrvRecord{
+			{Priority: ptr.To[int32](0), Weight: ptr.To[int32](100), Port: ptr.To[int32](80), Target: ptr.To("app.work.svc." + testZoneName)},
+		},
+	})
+	wantDBA := ownedRecordSet(5, armprivatedns.RecordSetProperties{
+		ARecords: []*armprivatedns.ARecord{{IPv4Address: ptr.To("10.1.0.1")}, {IPv4Address: ptr.To("10.1.0.3")}},
+	})
+	wantPodA := ownedRecordSet(5, armprivatedns.RecordSetProperties{
+		ARecords: []*armprivatedns.ARecord{{IPv4Address: ptr.To("10.1.0.1")}},
+	})
+	wantDBSRV := ownedRecordSet(5, armprivatedns.RecordSetProperties{
+		SrvRecords: []*armprivatedns.SrvRecord{
+			{Priority: ptr.To[int32](0), Weight: ptr.To[int32](100), Port: ptr.To[int32](5432), Target: ptr.To("db-0.member-1.db.work.svc." + testZoneName)},
+		},
+	})
+	otherRecordSet := armprivatedns.RecordSet{
+		Properties: &armprivatedns.RecordSetProperties{
+			TTL:      ptr.To[int64](60),
+			ARecords: []*armprivatedns.ARecord{{IPv4Address: ptr.To("10.9.9.9")}},
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		objs           []client.Object
+		recordSets     map[recordSetKey]armprivatedns.RecordSet
+		wantRecordSets map[recordSetKey]armprivatedns.RecordSet
+		wantWritten    []recordSetKey
+		wantDeleted    []recordSetKey
+	}{
+		{
+			name: "write record sets",
+			objs: []client.Object{
+				clusterSetIPServiceImport(),
+				loadBalancerDerivedService(),
+				headlessServiceImport(),
+				derivedService("work-db", corev1.ClusterIPNone),
+				importedEndpointSlice(),
+			},
+			recordSets: map[recordSetKey]armprivatedns.RecordSet{},
+			wantRecordSets: map[recordSetKey]armprivatedns.RecordSet{
+				appA:   wantAppA,
+				appSRV: wantAppSRV,
+				dbA:    wantDBA,
+				podA:   wantPodA,
+				dbSRV:  wantDBSRV,
+			},
+			wantWritten: []recordSetKey{appSRV, dbSRV, podA, appA, dbA},
+		},
+		{
+			name: "clusterSetIP service without load balancer ingress",
+			objs: []client.Object{
+				clusterSetIPServiceImport(),
+				derivedService("work-app", "10.0.0.10"),
+			},
+			recordSets:     map[recordSetKey]armprivatedns.RecordSet{},
+			wantRecordSets: map[recordSetKey]armprivatedns.RecordSet{},
+		},
+		{
+			name: "unchanged record sets",
+			objs: []client.Object{
+				clusterSetIPServiceImport(),
+				loadBalancerDerivedService(),
+			},
+			recordSets: map[recordSetKey]armprivatedns.RecordSet{
+				appA:   wantAppA,
+				appSRV: wantAppSRV,
+			},
+			wantRecordSets: map[recordSetKey]armprivatedns.RecordSet{
+				appA:   wantAppA,
+				appSRV: wantAppSRV,
+			},
+		},
+		{
+			name: "delete stale record sets and skip the ones of others",
+			objs: []client.Object{
+				clusterSetIPServiceImport(),
+				loadBalancerDerivedService(),
+			},
+			recordSets: map[recordSetKey]armprivatedns.RecordSet{
+				appA:  otherRecordSet,
+				dbA:   wantDBA,
+				dbSRV: otherRecordSet,
+			},
+			wantRecordSets: map[recordSetKey]armprivatedns.RecordSet{
+				appA:   otherRecordSet,
+				appSRV: wantAppSRV,
+				dbSRV:  otherRecordSet,
+			},
+			wantWritten: []recordSetKey{appSRV},
+			wantDeleted: []recordSetKey{dbA},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(tc.objs...).
+				Build()
+			recordSets := &fakeRecordSets{recordSets: tc.recordSets}
+			r := &PrivateZoneReconciler{
+				Client:               fakeClient,
+				FleetSystemNamespace: fleetSystemNamespace,
+				MemberClusterID:      testMemberClusterID,
+				RecordSetsClient:     recordSets.newClient(t),
+				ResourceGroupName:    testResourceGroup,
+				ZoneName:             testZoneName,
+				DefaultTTLSeconds:    5,
+			}
+			if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: testZoneName}}); err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+
+			for key, recordSet := range recordSets.recordSets {
+				recordSet.Name = nil
+				recordSet.Type = nil
+				recordSets.recordSets[key] = recordSet
+			}
+			if diff := cmp.Diff(tc.wantRecordSets, recordSets.recordSets, cmp.AllowUnexported(recordSetKey{})); diff != "" {
+				t.Errorf("record sets mismatch (-want, +got):\n%s", diff)
+			}
+			sortKeys := cmp.Transformer("sortKeys", func(keys []recordSetKey) map[recordSetKey]bool {
+				set := make(map[recordSetKey]bool, len(keys))
+				for _, key := range keys {
+					set[key] = true
+				}
+				return set
+			})
+			if diff := cmp.Diff(tc.wantWritten, recordSets.written, cmp.AllowUnexported(recordSetKey{}), sortKeys); diff != "" {
+				t.Errorf("written record sets mismatch (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantDeleted, recordSets.deleted, cmp.AllowUnexported(recordSetKey{}), sortKeys); diff != "" {
+				t.Errorf("deleted record sets mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
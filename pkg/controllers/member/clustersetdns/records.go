@@ -0,0 +1,225 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package clustersetdns
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+// recordBuilder builds the records of the ServiceImports of the member cluster.
+type recordBuilder struct {
+	client               client.Client
+	fleetSystemNamespace string
+	defaultTTLSeconds    int64
+	// useLoadBalancerIngress is set if the records are resolved outside the member cluster, where the ClusterSetIPs
+	// are not reachable; the ClusterSetIP services are then resolved to the load balancer ingress IPs of their derived
+	// Services instead.
+	useLoadBalancerIngress bool
+}
+
+// build returns the records of all the ServiceImports which have contributing clusters.
+func (b *recordBuilder) build(ctx context.Context) ([]record, error) {
+	serviceImportList := &fleetnetv1alpha1.ServiceImportList{}
+	if err := b.client.List(ctx, serviceImportList); err != nil {
+		klog.ErrorS(err, "Failed to list serviceImports")
+		return nil, err
+	}
+	endpointSliceList := &discoveryv1.EndpointSliceList{}
+	if err := b.client.List(ctx, endpointSliceList, client.InNamespace(b.fleetSystemNamespace)); err != nil {
+		klog.ErrorS(err, "Failed to list endpointSlices", "namespace", b.fleetSystemNamespace)
+		return nil, err
+	}
+	endpointSlicesByService := make(map[string][]*discoveryv1.EndpointSlice)
+	for i := range endpointSliceList.Items {
+		endpointSlice := &endpointSliceList.Items[i]
+		serviceName := endpointSlice.Labels[discoveryv1.LabelServiceName]
+		endpointSlicesByService[serviceName] = append(endpointSlicesByService[serviceName], endpointSlice)
+	}
+
+	var records []record
+	for i := range serviceImportList.Items {
+		serviceImport := &serviceImportList.Items[i]
+		if serviceImport.DeletionTimestamp != nil || len(serviceImport.Status.Clusters) == 0 {
+			continue
+		}
+		derivedService, err := b.getDerivedService(ctx, serviceImport)
+		if err != nil {
+			return nil, err
+		}
+		if derivedService == nil {
+			klog.V(4).InfoS("ServiceImport has no derived service yet", "serviceImport", klog.KObj(serviceImport))
+			continue
+		}
+		if serviceImport.Status.Type == fleetnetv1alpha1.Headless {
+			records = append(records, b.headlessRecords(serviceImport, endpointSlicesByService[derivedService.Name])...)
+			continue
+		}
+		records = append(records, b.clusterSetIPRecords(serviceImport, derivedService)...)
+	}
+	return records, nil
+}
+
+// getDerivedService returns the derived Service of the ServiceImport, which is created by the derivedservice
+// controller, or by the multiclusterservice controller if the ServiceImport is imported by a MultiClusterService;
+// it returns nil if the derived Service is not found.
+func (b *recordBuilder) getDerivedService(ctx context.Context, serviceImport *fleetnetv1alpha1.ServiceImport) (*corev1.Service, error) {
+	serviceName := serviceImport.Labels[objectmeta.ServiceImportLabelDerivedService]
+	if owner := metav1.GetControllerOf(serviceImport); owner != nil && owner.Kind == multiClusterServiceKind {
+		mcs := &fleetnetv1alpha1.MultiClusterService{}
+		if err := b.client.Get(ctx, types.NamespacedName{Namespace: serviceImport.Namespace, Name: owner.Name}, mcs); err != nil {
+			if errors.IsNotFound(err) {
+				return nil, nil
+			}
+			klog.ErrorS(err, "Failed to get multiClusterService", "multiClusterService", klog.KRef(serviceImport.Namespace, owner.Name))
+			return nil, err
+		}
+		serviceName = mcs.Labels[objectmeta.MultiClusterServiceLabelDerivedService]
+	}
+	if serviceName == "" {
+		return nil, nil
+	}
+	service := &corev1.Service{}
+	if err := b.client.Get(ctx, types.NamespacedName{Namespace: b.fleetSystemNamespace, Name: serviceName}, service); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		klog.ErrorS(err, "Failed to get derived service", "service", klog.KRef(b.fleetSystemNamespace, serviceName))
+		return nil, err
+	}
+	return service, nil
+}
+
+func serviceName(serviceImport *fleetnetv1alpha1.ServiceImport) string {
+	return fmt.Sprintf("%s.%s.svc", serviceImport.Name, serviceImport.Namespace)
+}
+
+func (b *recordBuilder) ttlOf(serviceImport *fleetnetv1alpha1.ServiceImport) int64 {
+	return ptr.Deref(serviceImport.Status.DNSTTLSeconds, b.defaultTTLSeconds)
+}
+
+// clusterSetIPRecords returns the address records of the ServiceImport, i.e. of the ClusterSetIPs or the cluster IP of
+// its derived Service, or of the load balancer ingress IPs of its derived Service, and the SRV records of its named
+// ports.
+func (b *recordBuilder) clusterSetIPRecords(serviceImport *fleetnetv1alpha1.ServiceImport, derivedService *corev1.Service) []record {
+	var ips []string
+	switch {
+	case b.useLoadBalancerIngress:
+		for _, ingress := range derivedService.Status.LoadBalancer.Ingress {
+			ips = append(ips, ingress.IP)
+		}
+	case serviceImport.Status.Type == fleetnetv1alpha1.ClusterSetIP && len(serviceImport.Status.IPs) > 0:
+		// A dual-stack ServiceImport has a ClusterSetIP of each IP family, i.e. both A and AAAA records.
+		ips = serviceImport.Status.IPs
+	default:
+		ips = []string{derivedService.Spec.ClusterIP}
+	}
+	name := serviceName(serviceImport)
+	ttl := b.ttlOf(serviceImport)
+	var records []record
+	for _, ip := range ips {
+		if address, ok := addressRecord(name, ttl, ip); ok {
+			records = append(records, address)
+		}
+	}
+	if len(records) == 0 {
+		klog.V(4).InfoS("ServiceImport has no valid address yet", "serviceImport", klog.KObj(serviceImport), "addresses", ips)
+		return nil
+	}
+	for _, port := range serviceImport.Status.Ports {
+		if port.Name == "" {
+			continue
+		}
+		records = append(records, srvRecord(port.Name, protocolOf(port.Protocol), name, ttl, port.Port, name))
+	}
+	return records
+}
+
+// headlessRecords returns the address records of the ready endpoints imported for the headless ServiceImport; an
+// endpoint with a hostname is also addressable as <hostname>.<cluster>.<service>.<namespace>.svc.clusterset.local,
+// which is the target of the SRV records of its named ports.
+func (b *recordBuilder) headlessRecords(serviceImport *fleetnetv1alpha1.ServiceImport, endpointSlices []*discoveryv1.EndpointSlice) []record {
+	name := serviceName(serviceImport)
+	ttl := b.ttlOf(serviceImport)
+	var records []record
+	for _, endpointSlice := range endpointSlices {
+		cluster := endpointSlice.Labels[objectmeta.EndpointSliceLabelSourceCluster]
+		for _, endpoint := range endpointSlice.Endpoints {
+			if endpoint.Conditions.Ready != nil && !*endpoint.Conditions.Ready {
+				continue
+			}
+			var podName string
+			if endpoint.Hostname != nil && cluster != "" {
+				// The imported hostname is suffixed with the cluster ID, as the hostnames are only unique in a cluster.
+				hostname := strings.TrimSuffix(*endpoint.Hostname, "-"+cluster)
+				podName = fmt.Sprintf("%s.%s.%s", hostname, cluster, name)
+			}
+			for _, ip := range endpoint.Addresses {
+				if address, ok := addressRecord(name, ttl, ip); ok {
+					records = append(records, address)
+				}
+				if podName == "" {
+					continue
+				}
+				if address, ok := addressRecord(podName, ttl, ip); ok {
+					records = append(records, address)
+				}
+			}
+			if podName == "" {
+				continue
+			}
+			for _, port := range endpointSlice.Ports {
+				if port.Name == nil || *port.Name == "" || port.Port == nil {
+					continue
+				}
+				records = append(records, srvRecord(*port.Name, protocolOf(ptr.Deref(port.Protocol, corev1.ProtocolTCP)), name, ttl, *port.Port, podName))
+			}
+		}
+	}
+	return records
+}
+
+func protocolOf(protocol corev1.Protocol) string {
+	if protocol == "" {
+		return string(corev1.ProtocolTCP)
+	}
+	return string(protocol)
+}
+
+// watchRecordSources sets up the watches of the objects which the records are built from; as the records of all the
+// ServiceImports are reconciled together, all the events are mapped to the same key.
+func watchRecordSources(b *builder.Builder, fleetSystemNamespace string, key types.NamespacedName) *builder.Builder {
+	enqueueKey := handler.EnqueueRequestsFromMapFunc(func(_ context.Context, _ client.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: key}}
+	})
+	enqueueKeyInFleetSystemNamespace := handler.EnqueueRequestsFromMapFunc(func(_ context.Context, object client.Object) []reconcile.Request {
+		if object.GetNamespace() != fleetSystemNamespace {
+			return []reconcile.Request{}
+		}
+		return []reconcile.Request{{NamespacedName: key}}
+	})
+	return b.
+		Watches(&fleetnetv1alpha1.ServiceImport{}, enqueueKey).
+		Watches(&fleetnetv1alpha1.MultiClusterService{}, enqueueKey).
+		Watches(&corev1.Service{}, enqueueKeyInFleetSystemNamespace).
+		Watches(&discoveryv1.EndpointSlice{}, enqueueKeyInFleetSystemNamespace)
+}
@@ -0,0 +1,107 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package clustersetdns
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+const (
+	// Zone is the DNS zone of the multi-cluster services, as defined by the Multi-Cluster Services API (KEP-1645).
+	Zone = "clusterset.local"
+
+	// The SOA record of the zone; the zone is served by the CoreDNS instances of the member cluster only, so that
+	// the values other than the serial are informational.
+	soaPrimaryNameServer = "ns.dns." + Zone + "."
+	soaResponsiblePerson = "hostmaster." + Zone + "."
+	soaRefreshSeconds    = 7200
+	soaRetrySeconds      = 1800
+	soaExpireSeconds     = 86400
+)
+
+// record is a resource record of the zone.
+type record struct {
+	// name is the owner name relative to the zone, e.g. app.work.svc.
+	name string
+	ttl  int64
+	// rrType is the type of the record, i.e. A, AAAA or SRV.
+	rrType string
+	// data is the address of an A or AAAA record.
+	data string
+	// port and target, which is a name relative to the zone, are the data of an SRV record.
+	port   int32
+	target string
+}
+
+// rdata returns the data of the record in the zone file format, where the target of an SRV record is fully qualified
+// in the zone.
+func (r record) rdata(zone string) string {
+	if r.rrType == "SRV" {
+		return fmt.Sprintf("0 100 %d %s.%s.", r.port, r.target, zone)
+	}
+	return r.data
+}
+
+// addressRecord returns the A or AAAA record of the IP address; it returns false if the address is not a valid IP.
+func addressRecord(name string, ttl int64, address string) (record, bool) {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return record{}, false
+	}
+	rrType := "AAAA"
+	if ip.To4() != nil {
+		rrType = "A"
+	}
+	return record{name: name, ttl: ttl, rrType: rrType, data: ip.String()}, true
+}
+
+// srvRecord returns the SRV record of a named port, whose target is a name in the zone.
+func srvRecord(portName, protocol, name string, ttl int64, port int32, target string) record {
+	return record{
+		name:   fmt.Sprintf("_%s._%s.%s", portName, strings.ToLower(protocol), name),
+		ttl:    ttl,
+		rrType: "SRV",
+		port:   port,
+		target: target,
+	}
+}
+
+// sortRecords sorts and deduplicates the records, so that the same records are always rendered in the same way.
+func sortRecords(records []record) []record {
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].name != records[j].name {
+			return records[i].name < records[j].name
+		}
+		if records[i].rrType != records[j].rrType {
+			return records[i].rrType < records[j].rrType
+		}
+		return records[i].rdata(Zone) < records[j].rdata(Zone)
+	})
+	unique := records[:0]
+	for _, r := range records {
+		if len(unique) > 0 && r == unique[len(unique)-1] {
+			continue
+		}
+		unique = append(unique, r)
+	}
+	return unique
+}
+
+// renderZone renders the records into an RFC 1035 zone file, which can be served by the file plugin of CoreDNS.
+func renderZone(records []record, serial uint32, defaultTTL int64) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "$ORIGIN %s.\n", Zone)
+	fmt.Fprintf(&b, "$TTL %d\n", defaultTTL)
+	fmt.Fprintf(&b, "@ IN SOA %s %s %d %d %d %d %d\n",
+		soaPrimaryNameServer, soaResponsiblePerson, serial, soaRefreshSeconds, soaRetrySeconds, soaExpireSeconds, defaultTTL)
+	for _, r := range sortRecords(records) {
+		fmt.Fprintf(&b, "%s %d IN %s %s\n", r.name, r.ttl, r.rrType, r.rdata(Zone))
+	}
+	return b.String()
+}
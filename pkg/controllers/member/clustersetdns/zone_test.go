@@ -0,0 +1,73 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package clustersetdns
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestAddressRecord tests the addressRecord function.
+func TestAddressRecord(t *testing.T) {
+	testCases := []struct {
+		name    string
+		address string
+		want    record
+		wantOK  bool
+	}{
+		{
+			name:    "ipv4",
+			address: "10.0.0.1",
+			want:    record{name: "app.work.svc", ttl: 5, rrType: "A", data: "10.0.0.1"},
+			wantOK:  true,
+		},
+		{
+			name:    "ipv6",
+			address: "fd00::1",
+			want:    record{name: "app.work.svc", ttl: 5, rrType: "AAAA", data: "fd00::1"},
+			wantOK:  true,
+		},
+		{
+			name:    "none",
+			address: "None",
+		},
+		{
+			name: "empty",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, gotOK := addressRecord("app.work.svc", 5, tc.address)
+			if gotOK != tc.wantOK {
+				t.Fatalf("addressRecord(%q) ok = %v, want %v", tc.address, gotOK, tc.wantOK)
+			}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(record{})); diff != "" {
+				t.Errorf("addressRecord(%q) mismatch (-want, +got):\n%s", tc.address, diff)
+			}
+		})
+	}
+}
+
+// TestRenderZone tests the renderZone function.
+func TestRenderZone(t *testing.T) {
+	records := []record{
+		srvRecord("http", "TCP", "app.work.svc", 30, 80, "app.work.svc"),
+		{name: "app.work.svc", ttl: 30, rrType: "A", data: "10.0.0.1"},
+		{name: "app.work.svc", ttl: 30, rrType: "A", data: "10.0.0.1"},
+		{name: "app.dev.svc", ttl: 5, rrType: "AAAA", data: "fd00::1"},
+	}
+	want := `$ORIGIN clusterset.local.
+$TTL 5
+@ IN SOA ns.dns.clusterset.local. hostmaster.clusterset.local. 12 7200 1800 86400 5
+_http._tcp.app.work.svc 30 IN SRV 0 100 80 app.work.svc.clusterset.local.
+app.dev.svc 5 IN AAAA fd00::1
+app.work.svc 30 IN A 10.0.0.1
+`
+	if diff := cmp.Diff(want, renderZone(records, 12, 5)); diff != "" {
+		t.Errorf("renderZone() mismatch (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,192 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package clustersetip features the clustersetip controller deployed in member cluster to allocate a stable
+// virtual IP (ClusterSetIP) to each ServiceImport of the ClusterSetIP type; the derived Service of the
+// ServiceImport is then given the ClusterSetIP as its cluster IP. In a dual-stack member cluster, a ServiceImport
+// exported as dual-stack is allocated a ClusterSetIP of each IP family.
+package clustersetip
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/ipam"
+	"go.goms.io/fleet-networking/pkg/common/ipfamily"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+)
+
+// Reconciler reconciles a ServiceImport object.
+type Reconciler struct {
+	Client client.Client
+	// Allocator allocates the ClusterSetIPs from the configured CIDR.
+	Allocator *ipam.Allocator
+	// SecondaryAllocator, if set, allocates the ClusterSetIPs of the other IP family from the configured secondary
+	// CIDR, to the ServiceImports exported by at least one cluster with the IP family.
+	SecondaryAllocator *ipam.Allocator
+
+	// synced is true once the Allocator has been rebuilt from the ClusterSetIPs recorded in the ServiceImports;
+	// the reconciler is not run concurrently, so the field needs no lock.
+	synced bool
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
+}
+
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports/status,verbs=get;update;patch
+
+// Reconcile allocates a ClusterSetIP to the ServiceImport if it is of the ClusterSetIP type and records it in its
+// status, or releases the ClusterSetIP if the ServiceImport is gone or is headless.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	serviceImportKRef := klog.KRef(req.Namespace, req.Name)
+	startTime := time.Now()
+	klog.V(2).InfoS("Reconciliation starts", "serviceImport", serviceImportKRef)
+	defer func() {
+		latency := time.Since(startTime).Milliseconds()
+		klog.V(2).InfoS("Reconciliation ends", "serviceImport", serviceImportKRef, "latency", latency)
+	}()
+
+	if err := r.syncAllocator(ctx); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	serviceImport := &fleetnetv1alpha1.ServiceImport{}
+	if err := r.Client.Get(ctx, req.NamespacedName, serviceImport); err != nil {
+		if errors.IsNotFound(err) {
+			klog.V(4).InfoS("Releasing the clusterSetIP of NotFound serviceImport", "serviceImport", serviceImportKRef)
+			r.release(req.NamespacedName)
+			return ctrl.Result{}, nil
+		}
+		klog.ErrorS(err, "Failed to get serviceImport", "serviceImport", serviceImportKRef)
+		return ctrl.Result{}, err
+	}
+	if serviceImport.DeletionTimestamp != nil {
+		klog.V(4).InfoS("Releasing the clusterSetIP of deleting serviceImport", "serviceImport", serviceImportKRef)
+		r.release(req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
+	var ips []string
+	switch serviceImport.Status.Type {
+	case fleetnetv1alpha1.ClusterSetIP:
+		ip, err := allocate(r.Allocator, req.NamespacedName, serviceImport.Status.IPs)
+		if err != nil {
+			klog.ErrorS(err, "Failed to allocate clusterSetIP", "serviceImport", serviceImportKRef, "cidr", r.Allocator.CIDR())
+			return ctrl.Result{}, err
+		}
+		ips = []string{ip}
+		if r.SecondaryAllocator == nil {
+			break
+		}
+		if !isExportedWithIPFamily(serviceImport, r.SecondaryAllocator.Family()) {
+			r.SecondaryAllocator.Release(req.NamespacedName)
+			break
+		}
+		secondaryIP, err := allocate(r.SecondaryAllocator, req.NamespacedName, serviceImport.Status.IPs)
+		if err != nil {
+			klog.ErrorS(err, "Failed to allocate secondary clusterSetIP", "serviceImport", serviceImportKRef, "cidr", r.SecondaryAllocator.CIDR())
+			return ctrl.Result{}, err
+		}
+		ips = append(ips, secondaryIP)
+	default:
+		// A headless serviceImport has no virtual IP, and the type of a serviceImport is left empty until its spec
+		// is resolved.
+		r.release(req.NamespacedName)
+	}
+	if equality.Semantic.DeepEqual(ips, serviceImport.Status.IPs) {
+		return ctrl.Result{}, nil
+	}
+
+	klog.V(2).InfoS("Updating the clusterSetIP of serviceImport", "serviceImport", serviceImportKRef, "ips", ips, "oldIPs", serviceImport.Status.IPs)
+	serviceImport.Status.IPs = ips
+	if err := r.Client.Status().Update(ctx, serviceImport); err != nil {
+		klog.ErrorS(err, "Failed to update the clusterSetIP of serviceImport", "serviceImport", serviceImportKRef, "ips", ips)
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// allocate returns the ClusterSetIP of the ServiceImport with the given key from the allocator; the address of the
+// allocator already recorded in the ServiceImport is kept if it is still valid, so that the ClusterSetIP stays stable.
+func allocate(allocator *ipam.Allocator, key types.NamespacedName, recorded []string) (string, error) {
+	for _, ip := range recorded {
+		if allocator.Reserve(key, ip) {
+			return ip, nil
+		}
+	}
+	return allocator.Allocate(key)
+}
+
+// release releases the ClusterSetIPs of the ServiceImport with the given key.
+func (r *Reconciler) release(key types.NamespacedName) {
+	r.Allocator.Release(key)
+	if r.SecondaryAllocator != nil {
+		r.SecondaryAllocator.Release(key)
+	}
+}
+
+// isExportedWithIPFamily returns true if at least one cluster exports the Service of the ServiceImport with the IP
+// family.
+func isExportedWithIPFamily(serviceImport *fleetnetv1alpha1.ServiceImport, family corev1.IPFamily) bool {
+	for _, cluster := range serviceImport.Status.Clusters {
+		if ipfamily.Contains(cluster.IPFamilies, family) {
+			return true
+		}
+	}
+	return false
+}
+
+// syncAllocator rebuilds the Allocator from the ClusterSetIPs recorded in the ServiceImports, before any new
+// ClusterSetIP is allocated, so that no address is handed out twice after the controller restarts.
+func (r *Reconciler) syncAllocator(ctx context.Context) error {
+	if r.synced {
+		return nil
+	}
+	serviceImportList := &fleetnetv1alpha1.ServiceImportList{}
+	if err := r.Client.List(ctx, serviceImportList); err != nil {
+		klog.ErrorS(err, "Failed to list serviceImports to rebuild the clusterSetIP allocator")
+		return fmt.Errorf("failed to list serviceImports: %w", err)
+	}
+	for i := range serviceImportList.Items {
+		serviceImport := &serviceImportList.Items[i]
+		if serviceImport.Status.Type != fleetnetv1alpha1.ClusterSetIP {
+			continue
+		}
+		for _, ip := range serviceImport.Status.IPs {
+			allocator := r.Allocator
+			if family, ok := ipfamily.OfAddress(ip); ok && family != allocator.Family() && r.SecondaryAllocator != nil {
+				allocator = r.SecondaryAllocator
+			}
+			if !allocator.Reserve(client.ObjectKeyFromObject(serviceImport), ip) {
+				// The address is outside the CIDR, or held by another serviceImport; a new one is allocated when the
+				// serviceImport is reconciled.
+				klog.V(2).InfoS("Discarding the invalid clusterSetIP of serviceImport", "serviceImport", klog.KObj(serviceImport), "ip", ip, "cidr", allocator.CIDR())
+			}
+		}
+	}
+	r.synced = true
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("clustersetip").
+		For(&fleetnetv1alpha1.ServiceImport{}).
+		WithOptions(r.ControllerOptions).
+		Complete(metrics.InstrumentReconciler("clustersetip", r))
+}
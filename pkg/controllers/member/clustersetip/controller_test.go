@@ -0,0 +1,247 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package clustersetip
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/ipam"
+)
+
+const (
+	testNamespace = "work"
+	testName      = "app"
+	testCIDR      = "10.255.0.0/24"
+	testIPv6CIDR  = "fd00:255::/120"
+)
+
+func serviceImport(name string, importType fleetnetv1alpha1.ServiceImportType, ips ...string) *fleetnetv1alpha1.ServiceImport {
+	return &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: name},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			IPs:  ips,
+			Type: importType,
+		},
+	}
+}
+
+// TestReconcile tests that a ClusterSetIP is allocated to, kept by, and released from a ServiceImport.
+func TestReconcile(t *testing.T) {
+	deleting := serviceImport(testName, fleetnetv1alpha1.ClusterSetIP, "10.255.0.1")
+	deleting.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	deleting.Finalizers = []string{"example.com/test"}
+
+	testCases := []struct {
+		name          string
+		serviceImport *fleetnetv1alpha1.ServiceImport
+		others        []client.Object
+		wantIPs       []string
+		// wantFreeIP is the address the allocator hands out next, which tells if the address of the ServiceImport
+		// has been released.
+		wantFreeIP string
+	}{
+		{
+			name:          "clusterSetIP is allocated",
+			serviceImport: serviceImport(testName, fleetnetv1alpha1.ClusterSetIP),
+			wantIPs:       []string{"10.255.0.1"},
+			wantFreeIP:    "10.255.0.2",
+		},
+		{
+			name:          "clusterSetIP is allocated around the addresses held by other serviceImports",
+			serviceImport: serviceImport(testName, fleetnetv1alpha1.ClusterSetIP),
+			others:        []client.Object{serviceImport("other", fleetnetv1alpha1.ClusterSetIP, "10.255.0.1")},
+			wantIPs:       []string{"10.255.0.2"},
+			wantFreeIP:    "10.255.0.3",
+		},
+		{
+			name:          "recorded clusterSetIP is kept",
+			serviceImport: serviceImport(testName, fleetnetv1alpha1.ClusterSetIP, "10.255.0.8"),
+			wantIPs:       []string{"10.255.0.8"},
+			wantFreeIP:    "10.255.0.1",
+		},
+		{
+			name:          "recorded clusterSetIP outside the CIDR is replaced",
+			serviceImport: serviceImport(testName, fleetnetv1alpha1.ClusterSetIP, "10.0.0.8"),
+			wantIPs:       []string{"10.255.0.1"},
+			wantFreeIP:    "10.255.0.2",
+		},
+		{
+			name:          "headless serviceImport has its clusterSetIP released",
+			serviceImport: serviceImport(testName, fleetnetv1alpha1.Headless, "10.255.0.1"),
+			wantFreeIP:    "10.255.0.1",
+		},
+		{
+			name:          "unresolved serviceImport has no clusterSetIP",
+			serviceImport: serviceImport(testName, ""),
+			wantFreeIP:    "10.255.0.1",
+		},
+		{
+			name:          "deleting serviceImport has its clusterSetIP released",
+			serviceImport: deleting,
+			wantIPs:       []string{"10.255.0.1"},
+			wantFreeIP:    "10.255.0.1",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			objects := append([]client.Object{tc.serviceImport}, tc.others...)
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(objects...).
+				WithStatusSubresource(objects...).
+				Build()
+			allocator, err := ipam.NewAllocator(testCIDR)
+			if err != nil {
+				t.Fatalf("NewAllocator() = %v, want no error", err)
+			}
+			r := &Reconciler{Client: fakeClient, Allocator: allocator}
+
+			key := types.NamespacedName{Namespace: testNamespace, Name: testName}
+			if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+			got := &fleetnetv1alpha1.ServiceImport{}
+			if err := fakeClient.Get(ctx, key, got); err != nil {
+				t.Fatalf("ServiceImport Get() = %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.wantIPs, got.Status.IPs); diff != "" {
+				t.Errorf("ServiceImport IPs mismatch (-want, +got):\n%s", diff)
+			}
+			gotFreeIP, err := allocator.Allocate(types.NamespacedName{Namespace: testNamespace, Name: "probe"})
+			if err != nil {
+				t.Fatalf("Allocate() = %v, want no error", err)
+			}
+			if gotFreeIP != tc.wantFreeIP {
+				t.Errorf("Allocate() = %q, want %q", gotFreeIP, tc.wantFreeIP)
+			}
+		})
+	}
+}
+
+// TestReconcile_NotFound tests that the clusterSetIP of a ServiceImport which is gone is released.
+func TestReconcile_NotFound(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	allocator, err := ipam.NewAllocator(testCIDR)
+	if err != nil {
+		t.Fatalf("NewAllocator() = %v, want no error", err)
+	}
+	key := types.NamespacedName{Namespace: testNamespace, Name: testName}
+	if !allocator.Reserve(key, "10.255.0.1") {
+		t.Fatalf("Reserve() = false, want true")
+	}
+	r := &Reconciler{Client: fake.NewClientBuilder().WithScheme(scheme).Build(), Allocator: allocator}
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile() = %v, want no error", err)
+	}
+	if got, err := allocator.Allocate(types.NamespacedName{Namespace: testNamespace, Name: "probe"}); err != nil || got != "10.255.0.1" {
+		t.Errorf("Allocate() = %q, %v, want the released address 10.255.0.1, no error", got, err)
+	}
+}
+
+// TestReconcile_DualStack tests that a ClusterSetIP of the secondary IP family is allocated to a ServiceImport only if
+// the Service is exported with the IP family.
+func TestReconcile_DualStack(t *testing.T) {
+	dualStack := serviceImport(testName, fleetnetv1alpha1.ClusterSetIP)
+	dualStack.Status.Clusters = []fleetnetv1alpha1.ServiceImportClusterStatus{
+		{Cluster: "member-1", IPFamilies: []corev1.IPFamily{corev1.IPv4Protocol}},
+		{Cluster: "member-2", IPFamilies: []corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv6Protocol}},
+	}
+	recorded := dualStack.DeepCopy()
+	recorded.Status.IPs = []string{"10.255.0.8", "fd00:255::8"}
+	singleStack := serviceImport(testName, fleetnetv1alpha1.ClusterSetIP, "10.255.0.8", "fd00:255::8")
+	singleStack.Status.Clusters = []fleetnetv1alpha1.ServiceImportClusterStatus{
+		{Cluster: "member-1", IPFamilies: []corev1.IPFamily{corev1.IPv4Protocol}},
+	}
+
+	testCases := []struct {
+		name          string
+		serviceImport *fleetnetv1alpha1.ServiceImport
+		wantIPs       []string
+		// wantFreeIPv6 is the address the secondary allocator hands out next.
+		wantFreeIPv6 string
+	}{
+		{
+			name:          "clusterSetIPs of both IP families are allocated",
+			serviceImport: dualStack,
+			wantIPs:       []string{"10.255.0.1", "fd00:255::1"},
+			wantFreeIPv6:  "fd00:255::2",
+		},
+		{
+			name:          "recorded clusterSetIPs are kept",
+			serviceImport: recorded,
+			wantIPs:       []string{"10.255.0.8", "fd00:255::8"},
+			wantFreeIPv6:  "fd00:255::1",
+		},
+		{
+			name:          "secondary clusterSetIP is released when no cluster exports the IP family",
+			serviceImport: singleStack,
+			wantIPs:       []string{"10.255.0.8"},
+			wantFreeIPv6:  "fd00:255::1",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(tc.serviceImport).
+				WithStatusSubresource(tc.serviceImport).
+				Build()
+			allocator, err := ipam.NewAllocator(testCIDR)
+			if err != nil {
+				t.Fatalf("NewAllocator() = %v, want no error", err)
+			}
+			secondaryAllocator, err := ipam.NewAllocator(testIPv6CIDR)
+			if err != nil {
+				t.Fatalf("NewAllocator() = %v, want no error", err)
+			}
+			r := &Reconciler{Client: fakeClient, Allocator: allocator, SecondaryAllocator: secondaryAllocator}
+
+			key := types.NamespacedName{Namespace: testNamespace, Name: testName}
+			if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+			got := &fleetnetv1alpha1.ServiceImport{}
+			if err := fakeClient.Get(ctx, key, got); err != nil {
+				t.Fatalf("ServiceImport Get() = %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.wantIPs, got.Status.IPs); diff != "" {
+				t.Errorf("ServiceImport IPs mismatch (-want, +got):\n%s", diff)
+			}
+			gotFreeIP, err := secondaryAllocator.Allocate(types.NamespacedName{Namespace: testNamespace, Name: "probe"})
+			if err != nil {
+				t.Fatalf("Allocate() = %v, want no error", err)
+			}
+			if gotFreeIP != tc.wantFreeIPv6 {
+				t.Errorf("Allocate() = %q, want %q", gotFreeIP, tc.wantFreeIPv6)
+			}
+		})
+	}
+}
@@ -0,0 +1,275 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package derivedservice features the derivedservice controller deployed in member cluster to create a derived
+// Service for each ServiceImport which is not imported by a MultiClusterService, so that the imported
+// EndpointSlices have a local Service to associate with, and kube-proxy load-balances the traffic to the
+// endpoints in the other member clusters natively.
+package derivedservice
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/ipfamily"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/uniquename"
+)
+
+const (
+	multiClusterServiceKind = "MultiClusterService"
+
+	// topologyModeAuto enables topology aware routing of a Service.
+	topologyModeAuto = "Auto"
+)
+
+// Reconciler reconciles a ServiceImport object.
+type Reconciler struct {
+	Client client.Client
+	// The derived Services are created in the fleet system namespace, along with the imported EndpointSlices.
+	FleetSystemNamespace string
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
+}
+
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile creates or updates the derived Service of the ServiceImport, or deletes it if the ServiceImport is gone,
+// is imported by a MultiClusterService, or has no contributing clusters.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	serviceImportKRef := klog.KRef(req.Namespace, req.Name)
+	startTime := time.Now()
+	klog.V(2).InfoS("Reconciliation starts", "serviceImport", serviceImportKRef)
+	defer func() {
+		latency := time.Since(startTime).Milliseconds()
+		klog.V(2).InfoS("Reconciliation ends", "serviceImport", serviceImportKRef, "latency", latency)
+	}()
+
+	serviceImport := &fleetnetv1alpha1.ServiceImport{}
+	if err := r.Client.Get(ctx, req.NamespacedName, serviceImport); err != nil {
+		if errors.IsNotFound(err) {
+			klog.V(4).InfoS("ServiceImport is not found; deleting its derived services", "serviceImport", serviceImportKRef)
+			return ctrl.Result{}, r.deleteDerivedServices(ctx, req.NamespacedName)
+		}
+		klog.ErrorS(err, "Failed to get serviceImport", "serviceImport", serviceImportKRef)
+		return ctrl.Result{}, err
+	}
+	if serviceImport.DeletionTimestamp != nil || isImportedByMultiClusterService(serviceImport) || len(serviceImport.Status.Clusters) == 0 {
+		// The derived Service of a ServiceImport imported by a MultiClusterService is managed by the
+		// multiclusterservice controller instead.
+		klog.V(4).InfoS("ServiceImport needs no derived service", "serviceImport", serviceImportKRef)
+		return ctrl.Result{}, r.deleteDerivedServices(ctx, req.NamespacedName)
+	}
+
+	serviceName := serviceImport.Labels[objectmeta.ServiceImportLabelDerivedService]
+	if serviceName == "" {
+		name, err := uniquename.ClusterScopedUniqueName(uniquename.DNS1035Label, serviceImport.Namespace, serviceImport.Name)
+		if err != nil {
+			klog.ErrorS(err, "Failed to generate derived service name", "serviceImport", serviceImportKRef)
+			return ctrl.Result{}, err
+		}
+		// Label the serviceImport first, so that the name stays the same if the controller aborts before the service
+		// is created.
+		if serviceImport.Labels == nil {
+			serviceImport.Labels = map[string]string{}
+		}
+		serviceImport.Labels[objectmeta.ServiceImportLabelDerivedService] = name
+		if err := r.Client.Update(ctx, serviceImport); err != nil {
+			klog.ErrorS(err, "Failed to add derived service label to serviceImport", "serviceImport", serviceImportKRef, "service", name)
+			return ctrl.Result{}, err
+		}
+		serviceName = name
+	}
+
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: r.FleetSystemNamespace,
+			Name:      serviceName,
+		},
+	}
+	serviceKObj := klog.KObj(service)
+	if err := r.deleteStaleService(ctx, serviceImport, service); err != nil {
+		return ctrl.Result{}, err
+	}
+	if op, err := controllerutil.CreateOrUpdate(ctx, r.Client, service, func() error {
+		formatDerivedService(serviceImport, service)
+		return nil
+	}); err != nil {
+		klog.ErrorS(err, "Failed to create or update derived service", "serviceImport", serviceImportKRef, "service", serviceKObj, "op", op)
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// isImportedByMultiClusterService returns true if the ServiceImport is controlled by a MultiClusterService.
+func isImportedByMultiClusterService(serviceImport *fleetnetv1alpha1.ServiceImport) bool {
+	owner := metav1.GetControllerOf(serviceImport)
+	return owner != nil && owner.Kind == multiClusterServiceKind
+}
+
+// clusterSetIPsOf returns the ClusterSetIPs allocated to the serviceImport, which are one of each IP family if it is
+// dual-stack, or nil if none is allocated.
+func clusterSetIPsOf(serviceImport *fleetnetv1alpha1.ServiceImport) []string {
+	if serviceImport.Status.Type != fleetnetv1alpha1.ClusterSetIP {
+		return nil
+	}
+	return serviceImport.Status.IPs
+}
+
+// isExportedAsDualStack returns true if the Service is exported with both IP families, by the same cluster or not.
+func isExportedAsDualStack(serviceImport *fleetnetv1alpha1.ServiceImport) bool {
+	var families []corev1.IPFamily
+	for _, cluster := range serviceImport.Status.Clusters {
+		for _, family := range cluster.IPFamilies {
+			if !ipfamily.Contains(families, family) {
+				families = append(families, family)
+			}
+		}
+	}
+	return len(families) > 1
+}
+
+// formatDerivedService formats the derived Service of the ServiceImport; the Service has no selector, as its
+// EndpointSlices are imported from the other member clusters.
+func formatDerivedService(serviceImport *fleetnetv1alpha1.ServiceImport, service *corev1.Service) {
+	ports := make([]corev1.ServicePort, len(serviceImport.Status.Ports))
+	for i := range serviceImport.Status.Ports {
+		ports[i] = serviceImport.Status.Ports[i].ToServicePort()
+	}
+	service.Spec.Ports = ports
+	service.Spec.Type = corev1.ServiceTypeClusterIP
+	clusterSetIPs := clusterSetIPsOf(serviceImport)
+	switch {
+	case serviceImport.Status.Type == fleetnetv1alpha1.Headless:
+		service.Spec.ClusterIP = corev1.ClusterIPNone
+	case len(clusterSetIPs) > 0:
+		service.Spec.ClusterIP = clusterSetIPs[0]
+		service.Spec.ClusterIPs = clusterSetIPs
+	}
+	if len(clusterSetIPs) > 1 || isExportedAsDualStack(serviceImport) {
+		// The derived Service is given the addresses of both IP families, so that the imported EndpointSlices of
+		// either family are load-balanced; the policy falls back to single-stack in a single-stack member cluster.
+		service.Spec.IPFamilyPolicy = ptr.To(corev1.IPFamilyPolicyPreferDualStack)
+	}
+	if serviceImport.Status.SessionAffinity != "" {
+		service.Spec.SessionAffinity = serviceImport.Status.SessionAffinity
+		service.Spec.SessionAffinityConfig = serviceImport.Status.SessionAffinityConfig
+	}
+	if service.Annotations == nil {
+		service.Annotations = map[string]string{}
+	}
+	// The imported EndpointSlices are hinted for the zones of the member cluster; kube-proxy honors the hints of a
+	// Service only if topology aware routing is enabled for it.
+	service.Annotations[corev1.AnnotationTopologyMode] = topologyModeAuto
+	if service.Labels == nil {
+		service.Labels = map[string]string{}
+	}
+	service.Labels[objectmeta.ServiceLabelServiceImportNamespace] = serviceImport.Namespace
+	service.Labels[objectmeta.ServiceLabelServiceImportName] = serviceImport.Name
+}
+
+// deleteStaleService deletes the derived Service if it is headless while the ServiceImport is not, or vice versa,
+// or if its cluster IPs are not the ClusterSetIPs allocated to the ServiceImport; the cluster IPs of a Service are
+// immutable, so the derived Service has to be recreated.
+func (r *Reconciler) deleteStaleService(ctx context.Context, serviceImport *fleetnetv1alpha1.ServiceImport, service *corev1.Service) error {
+	existing := &corev1.Service{}
+	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(service), existing); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		klog.ErrorS(err, "Failed to get derived service", "service", klog.KObj(service))
+		return err
+	}
+	isHeadlessService := existing.Spec.ClusterIP == corev1.ClusterIPNone
+	clusterSetIPs := clusterSetIPsOf(serviceImport)
+	if isHeadlessService == (serviceImport.Status.Type == fleetnetv1alpha1.Headless) &&
+		(len(clusterSetIPs) == 0 || hasClusterIPs(existing, clusterSetIPs)) {
+		return nil
+	}
+	klog.V(2).InfoS("Deleting derived service to recreate it as the serviceImport type or clusterSetIP has changed", "service", klog.KObj(existing), "serviceImport", klog.KObj(serviceImport), "clusterIPs", existing.Spec.ClusterIPs, "clusterSetIPs", clusterSetIPs)
+	if err := r.Client.Delete(ctx, existing); err != nil && !errors.IsNotFound(err) {
+		klog.ErrorS(err, "Failed to delete derived service", "service", klog.KObj(existing))
+		return err
+	}
+	return nil
+}
+
+// hasClusterIPs returns true if the Service has the ClusterSetIPs as its cluster IPs; a Service given a single
+// ClusterSetIP may have a secondary cluster IP allocated by the member cluster, which is kept.
+func hasClusterIPs(service *corev1.Service, clusterSetIPs []string) bool {
+	if service.Spec.ClusterIP != clusterSetIPs[0] {
+		return false
+	}
+	return len(clusterSetIPs) == 1 || slices.Equal(service.Spec.ClusterIPs, clusterSetIPs)
+}
+
+// deleteDerivedServices deletes all the derived Services of the ServiceImport with the given key.
+func (r *Reconciler) deleteDerivedServices(ctx context.Context, serviceImportKey types.NamespacedName) error {
+	serviceList := &corev1.ServiceList{}
+	if err := r.Client.List(ctx, serviceList, client.InNamespace(r.FleetSystemNamespace), client.MatchingLabels{
+		objectmeta.ServiceLabelServiceImportNamespace: serviceImportKey.Namespace,
+		objectmeta.ServiceLabelServiceImportName:      serviceImportKey.Name,
+	}); err != nil {
+		klog.ErrorS(err, "Failed to list derived services", "serviceImport", serviceImportKey)
+		return err
+	}
+	for i := range serviceList.Items {
+		service := &serviceList.Items[i]
+		klog.V(2).InfoS("Deleting derived service", "service", klog.KObj(service), "serviceImport", serviceImportKey)
+		if err := r.Client.Delete(ctx, service); err != nil && !errors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to delete derived service", "service", klog.KObj(service), "serviceImport", serviceImportKey)
+			return err
+		}
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("derivedservice").
+		For(&fleetnetv1alpha1.ServiceImport{}).
+		// Cross-namespace owner references are not allowed; the derived services are mapped back to their
+		// serviceImports with their labels instead.
+		Watches(
+			&corev1.Service{},
+			handler.EnqueueRequestsFromMapFunc(r.serviceEventHandler()),
+		).
+		WithOptions(r.ControllerOptions).
+		Complete(metrics.InstrumentReconciler("derivedservice", r))
+}
+
+func (r *Reconciler) serviceEventHandler() handler.MapFunc {
+	return func(_ context.Context, object client.Object) []reconcile.Request {
+		namespace := object.GetLabels()[objectmeta.ServiceLabelServiceImportNamespace]
+		name := object.GetLabels()[objectmeta.ServiceLabelServiceImportName]
+		if object.GetNamespace() != r.FleetSystemNamespace || namespace == "" || name == "" {
+			return []reconcile.Request{}
+		}
+		return []reconcile.Request{
+			{
+				NamespacedName: types.NamespacedName{Namespace: namespace, Name: name},
+			},
+		}
+	}
+}
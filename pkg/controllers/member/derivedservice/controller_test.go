@@ -0,0 +1,290 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package derivedservice
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+const (
+	testNamespace      = "work"
+	testName           = "app"
+	fleetSystemNS      = "fleet-system"
+	testDerivedSvcName = "work-app-1x2yz"
+)
+
+var serviceImportKey = types.NamespacedName{Namespace: testNamespace, Name: testName}
+
+func serviceImportForTest(importType fleetnetv1alpha1.ServiceImportType, ips ...string) *fleetnetv1alpha1.ServiceImport {
+	return &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testName,
+			Labels:    map[string]string{objectmeta.ServiceImportLabelDerivedService: testDerivedSvcName},
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			IPs:      ips,
+			Type:     importType,
+			Ports:    []fleetnetv1alpha1.ServicePort{{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80}},
+			Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-2"}},
+		},
+	}
+}
+
+func derivedServiceForTest(clusterIP string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: fleetSystemNS,
+			Name:      testDerivedSvcName,
+			Labels: map[string]string{
+				objectmeta.ServiceLabelServiceImportNamespace: testNamespace,
+				objectmeta.ServiceLabelServiceImportName:      testName,
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Type:      corev1.ServiceTypeClusterIP,
+			ClusterIP: clusterIP,
+		},
+	}
+}
+
+func scheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	return scheme
+}
+
+// TestReconcile tests that the derived Service follows the ServiceImport.
+func TestReconcile(t *testing.T) {
+	ownedByMCS := serviceImportForTest(fleetnetv1alpha1.ClusterSetIP)
+	ownedByMCS.OwnerReferences = []metav1.OwnerReference{
+		{APIVersion: "networking.fleet.azure.com/v1alpha1", Kind: multiClusterServiceKind, Name: testName, Controller: ptr.To(true)},
+	}
+	noClusters := serviceImportForTest(fleetnetv1alpha1.ClusterSetIP)
+	noClusters.Status.Clusters = nil
+
+	testCases := []struct {
+		name          string
+		serviceImport *fleetnetv1alpha1.ServiceImport
+		service       *corev1.Service
+		// wantClusterIP is the cluster IP of the derived Service; nil means that there should be no derived Service.
+		wantClusterIP *string
+	}{
+		{
+			name:          "derived service is created",
+			serviceImport: serviceImportForTest(fleetnetv1alpha1.ClusterSetIP),
+			wantClusterIP: ptr.To(""),
+		},
+		{
+			name:          "derived service is created with the clusterSetIP",
+			serviceImport: serviceImportForTest(fleetnetv1alpha1.ClusterSetIP, "10.255.0.1"),
+			wantClusterIP: ptr.To("10.255.0.1"),
+		},
+		{
+			name:          "headless derived service is created",
+			serviceImport: serviceImportForTest(fleetnetv1alpha1.Headless),
+			wantClusterIP: ptr.To(corev1.ClusterIPNone),
+		},
+		{
+			name:          "derived service is recreated as the serviceImport becomes headless",
+			serviceImport: serviceImportForTest(fleetnetv1alpha1.Headless),
+			service:       derivedServiceForTest("10.0.0.1"),
+			wantClusterIP: ptr.To(corev1.ClusterIPNone),
+		},
+		{
+			name:          "derived service is recreated with the clusterSetIP",
+			serviceImport: serviceImportForTest(fleetnetv1alpha1.ClusterSetIP, "10.255.0.1"),
+			service:       derivedServiceForTest("10.0.0.1"),
+			wantClusterIP: ptr.To("10.255.0.1"),
+		},
+		{
+			name:          "derived service keeps its cluster IP",
+			serviceImport: serviceImportForTest(fleetnetv1alpha1.ClusterSetIP),
+			service:       derivedServiceForTest("10.0.0.1"),
+			wantClusterIP: ptr.To("10.0.0.1"),
+		},
+		{
+			name:          "derived service is deleted as the serviceImport is imported by a MCS",
+			serviceImport: ownedByMCS,
+			service:       derivedServiceForTest("10.0.0.1"),
+		},
+		{
+			name:          "derived service is deleted as the serviceImport has no clusters",
+			serviceImport: noClusters,
+			service:       derivedServiceForTest("10.0.0.1"),
+		},
+		{
+			name:    "derived service is deleted as the serviceImport is not found",
+			service: derivedServiceForTest("10.0.0.1"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			var objects []client.Object
+			if tc.serviceImport != nil {
+				objects = append(objects, tc.serviceImport)
+			}
+			if tc.service != nil {
+				objects = append(objects, tc.service)
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme(t)).WithObjects(objects...).Build()
+			r := &Reconciler{Client: fakeClient, FleetSystemNamespace: fleetSystemNS}
+
+			if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: serviceImportKey}); err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+			serviceList := &corev1.ServiceList{}
+			if err := fakeClient.List(ctx, serviceList, client.InNamespace(fleetSystemNS)); err != nil {
+				t.Fatalf("Service List() = %v, want no error", err)
+			}
+			if tc.wantClusterIP == nil {
+				if len(serviceList.Items) != 0 {
+					t.Errorf("Service List() = %v, want no derived service", serviceList.Items)
+				}
+				return
+			}
+			if len(serviceList.Items) != 1 {
+				t.Fatalf("Service List() got %d services, want 1", len(serviceList.Items))
+			}
+			got := serviceList.Items[0]
+			if got.Name != testDerivedSvcName {
+				t.Errorf("derived service name = %q, want %q", got.Name, testDerivedSvcName)
+			}
+			if got.Spec.ClusterIP != *tc.wantClusterIP {
+				t.Errorf("derived service cluster IP = %q, want %q", got.Spec.ClusterIP, *tc.wantClusterIP)
+			}
+			if got.Annotations[corev1.AnnotationTopologyMode] != topologyModeAuto {
+				t.Errorf("derived service topology mode = %q, want %q", got.Annotations[corev1.AnnotationTopologyMode], topologyModeAuto)
+			}
+			wantPorts := []corev1.ServicePort{{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80}}
+			if diff := cmp.Diff(wantPorts, got.Spec.Ports); diff != "" {
+				t.Errorf("derived service ports mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestReconcile_DualStack tests that the derived Service of a ServiceImport exported as dual-stack prefers dual-stack.
+func TestReconcile_DualStack(t *testing.T) {
+	dualStackClusters := []fleetnetv1alpha1.ServiceImportClusterStatus{
+		{Cluster: "member-1", IPFamilies: []corev1.IPFamily{corev1.IPv4Protocol}},
+		{Cluster: "member-2", IPFamilies: []corev1.IPFamily{corev1.IPv6Protocol}},
+	}
+	dualStack := serviceImportForTest(fleetnetv1alpha1.ClusterSetIP, "10.255.0.1", "fd00:255::1")
+	dualStack.Status.Clusters = dualStackClusters
+	withoutClusterSetIPs := serviceImportForTest(fleetnetv1alpha1.ClusterSetIP)
+	withoutClusterSetIPs.Status.Clusters = dualStackClusters
+	singleStackService := derivedServiceForTest("10.255.0.1")
+	singleStackService.Spec.ClusterIPs = []string{"10.255.0.1"}
+
+	testCases := []struct {
+		name               string
+		serviceImport      *fleetnetv1alpha1.ServiceImport
+		service            *corev1.Service
+		wantClusterIPs     []string
+		wantIPFamilyPolicy *corev1.IPFamilyPolicy
+	}{
+		{
+			name:               "derived service is created with the clusterSetIPs of both IP families",
+			serviceImport:      dualStack,
+			wantClusterIPs:     []string{"10.255.0.1", "fd00:255::1"},
+			wantIPFamilyPolicy: ptr.To(corev1.IPFamilyPolicyPreferDualStack),
+		},
+		{
+			name:               "single-stack derived service is recreated with the clusterSetIPs of both IP families",
+			serviceImport:      dualStack,
+			service:            singleStackService,
+			wantClusterIPs:     []string{"10.255.0.1", "fd00:255::1"},
+			wantIPFamilyPolicy: ptr.To(corev1.IPFamilyPolicyPreferDualStack),
+		},
+		{
+			name:               "derived service prefers dual-stack without clusterSetIPs",
+			serviceImport:      withoutClusterSetIPs,
+			wantIPFamilyPolicy: ptr.To(corev1.IPFamilyPolicyPreferDualStack),
+		},
+		{
+			name:           "single-stack serviceImport",
+			serviceImport:  serviceImportForTest(fleetnetv1alpha1.ClusterSetIP, "10.255.0.1"),
+			wantClusterIPs: []string{"10.255.0.1"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			objects := []client.Object{tc.serviceImport}
+			if tc.service != nil {
+				objects = append(objects, tc.service)
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme(t)).WithObjects(objects...).Build()
+			r := &Reconciler{Client: fakeClient, FleetSystemNamespace: fleetSystemNS}
+
+			if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: serviceImportKey}); err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+			got := &corev1.Service{}
+			if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: fleetSystemNS, Name: testDerivedSvcName}, got); err != nil {
+				t.Fatalf("Service Get() = %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.wantClusterIPs, got.Spec.ClusterIPs); diff != "" {
+				t.Errorf("derived service cluster IPs mismatch (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantIPFamilyPolicy, got.Spec.IPFamilyPolicy); diff != "" {
+				t.Errorf("derived service IP family policy mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestReconcile_DerivedServiceName tests that the name of the derived Service is generated once and recorded in
+// the ServiceImport.
+func TestReconcile_DerivedServiceName(t *testing.T) {
+	ctx := context.Background()
+	serviceImport := serviceImportForTest(fleetnetv1alpha1.ClusterSetIP)
+	serviceImport.Labels = nil
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme(t)).WithObjects(serviceImport).Build()
+	r := &Reconciler{Client: fakeClient, FleetSystemNamespace: fleetSystemNS}
+
+	for i := 0; i < 2; i++ {
+		if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: serviceImportKey}); err != nil {
+			t.Fatalf("Reconcile() = %v, want no error", err)
+		}
+	}
+	got := &fleetnetv1alpha1.ServiceImport{}
+	if err := fakeClient.Get(ctx, serviceImportKey, got); err != nil {
+		t.Fatalf("ServiceImport Get() = %v, want no error", err)
+	}
+	name := got.Labels[objectmeta.ServiceImportLabelDerivedService]
+	if name == "" {
+		t.Fatalf("ServiceImport has no derived service label, want one")
+	}
+	serviceList := &corev1.ServiceList{}
+	if err := fakeClient.List(ctx, serviceList, client.InNamespace(fleetSystemNS)); err != nil {
+		t.Fatalf("Service List() = %v, want no error", err)
+	}
+	if len(serviceList.Items) != 1 || serviceList.Items[0].Name != name {
+		t.Errorf("Service List() = %v, want a single derived service %s", serviceList.Items, name)
+	}
+}
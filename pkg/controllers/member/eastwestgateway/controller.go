@@ -0,0 +1,306 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package eastwestgateway
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+)
+
+const (
+	// gatewayPortNameFormat is the name format of the ports of the gateway Service, which is gw-{gatewayPort}.
+	gatewayPortNameFormat = "gw-%d"
+)
+
+// Reconciler reconciles the ConfigMap of the gateway; all the events are mapped to the ConfigMap, as the ports are
+// assigned to all the exported Services of the member cluster.
+type Reconciler struct {
+	Client client.Client
+	// The Service and the ConfigMap of the gateway are in the fleet system namespace.
+	FleetSystemNamespace string
+	// ServiceName is the name of the LoadBalancer Service exposing the gateway; its ports within the port range are
+	// managed by the controller.
+	ServiceName string
+	// ConfigMapName is the name of the ConfigMap keeping the assigned ports and the Envoy configuration.
+	ConfigMapName string
+	// MinPort and MaxPort are the range of the ports of the gateway assigned to the Service ports.
+	MinPort int32
+	MaxPort int32
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
+}
+
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceexports,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch
+
+// Reconcile assigns the ports of the gateway to the ports of the exported Services, and updates the ConfigMap and
+// the Service of the gateway accordingly.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	configMapKRef := klog.KRef(req.Namespace, req.Name)
+	startTime := time.Now()
+	klog.V(2).InfoS("Reconciliation starts", "configMap", configMapKRef)
+	defer func() {
+		latency := time.Since(startTime).Milliseconds()
+		klog.V(2).InfoS("Reconciliation ends", "configMap", configMapKRef, "latency", latency)
+	}()
+
+	exported, err := r.listExportedServicePorts(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	configMap := &corev1.ConfigMap{}
+	isNew := false
+	if err := r.Client.Get(ctx, req.NamespacedName, configMap); err != nil {
+		if !errors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to get configMap", "configMap", configMapKRef)
+			return ctrl.Result{}, err
+		}
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: req.Namespace, Name: req.Name},
+		}
+		isNew = true
+	}
+
+	mappings, unassigned := assignPorts(readPortMappings(configMap), exported, r.MinPort, r.MaxPort)
+	if unassigned > 0 {
+		// Retrying will not help until more exported Service ports are released; the ports left are assigned once
+		// they are.
+		klog.ErrorS(fmt.Errorf("port range %d-%d is exhausted", r.MinPort, r.MaxPort),
+			"Failed to assign gateway ports to the exported service ports", "configMap", configMapKRef, "numberOfUnassignedPorts", unassigned)
+	}
+	data := map[string]string{
+		PortsKey:     marshal(mappings),
+		ListenersKey: renderListeners(mappings),
+		ClustersKey:  renderClusters(mappings),
+	}
+	switch {
+	case isNew:
+		configMap.Data = data
+		klog.V(2).InfoS("Creating configMap of the gateway", "configMap", configMapKRef, "numberOfPorts", len(mappings))
+		if err := r.Client.Create(ctx, configMap); err != nil {
+			klog.ErrorS(err, "Failed to create configMap", "configMap", configMapKRef)
+			return ctrl.Result{}, err
+		}
+	case !equality.Semantic.DeepEqual(configMap.Data, data):
+		configMap.Data = data
+		klog.V(2).InfoS("Updating configMap of the gateway", "configMap", configMapKRef, "numberOfPorts", len(mappings))
+		if err := r.Client.Update(ctx, configMap); err != nil {
+			klog.ErrorS(err, "Failed to update configMap", "configMap", configMapKRef)
+			return ctrl.Result{}, err
+		}
+	default:
+		klog.V(4).InfoS("Ports of the gateway are not changed", "configMap", configMapKRef)
+	}
+	return ctrl.Result{}, r.updateGatewayService(ctx, mappings)
+}
+
+// listExportedServicePorts returns the TCP ports of the exported Services which can be routed through the gateway,
+// i.e. the ports selected by the valid ServiceExports with no conflict, of the Services with a ClusterIP; the
+// gateway ports are not assigned yet.
+func (r *Reconciler) listExportedServicePorts(ctx context.Context) ([]PortMapping, error) {
+	svcExportList := &fleetnetv1alpha1.ServiceExportList{}
+	if err := r.Client.List(ctx, svcExportList); err != nil {
+		klog.ErrorS(err, "Failed to list serviceExports")
+		return nil, err
+	}
+	var res []PortMapping
+	for i := range svcExportList.Items {
+		svcExport := &svcExportList.Items[i]
+		if !isServiceExportValidWithNoConflict(svcExport) {
+			continue
+		}
+		svc := &corev1.Service{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Namespace: svcExport.Namespace, Name: svcExport.Name}, svc); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			klog.ErrorS(err, "Failed to get service", "service", klog.KObj(svcExport))
+			return nil, err
+		}
+		// Headless Services have no ClusterIP to forward the connections to.
+		if svc.Spec.ClusterIP == "" || svc.Spec.ClusterIP == corev1.ClusterIPNone {
+			continue
+		}
+		for _, svcPort := range svc.Spec.Ports {
+			if svcPort.Protocol != corev1.ProtocolTCP || !svcExport.Spec.SelectsPort(svcPort) {
+				continue
+			}
+			res = append(res, PortMapping{
+				Namespace: svc.Namespace,
+				Service:   svc.Name,
+				PortName:  svcPort.Name,
+				ClusterIP: svc.Spec.ClusterIP,
+				Port:      svcPort.Port,
+			})
+		}
+	}
+	return res, nil
+}
+
+// assignPorts assigns the gateway ports to the exported Service ports, keeping the ports already assigned so that
+// the exported endpoints stay stable; it returns the mappings sorted by the gateway port, and the number of the
+// Service ports which cannot be assigned as the range is exhausted.
+func assignPorts(current, exported []PortMapping, minPort, maxPort int32) ([]PortMapping, int) {
+	assigned := make(map[portKey]int32, len(current))
+	inUse := make(map[int32]bool, len(current))
+	for _, m := range current {
+		if m.GatewayPort < minPort || m.GatewayPort > maxPort || inUse[m.GatewayPort] {
+			continue
+		}
+		assigned[m.key()] = m.GatewayPort
+		inUse[m.GatewayPort] = true
+	}
+	// Release the ports of the Service ports which are no longer exported.
+	exportedKeys := make(map[portKey]bool, len(exported))
+	for _, m := range exported {
+		exportedKeys[m.key()] = true
+	}
+	for key, port := range assigned {
+		if !exportedKeys[key] {
+			delete(assigned, key)
+			delete(inUse, port)
+		}
+	}
+
+	// Assign the lowest free ports to the new Service ports in a stable order.
+	sort.Slice(exported, func(i, j int) bool {
+		return exported[i].clusterName() < exported[j].clusterName()
+	})
+	res := make([]PortMapping, 0, len(exported))
+	unassigned := 0
+	next := minPort
+	for _, m := range exported {
+		port, ok := assigned[m.key()]
+		if !ok {
+			for next <= maxPort && inUse[next] {
+				next++
+			}
+			if next > maxPort {
+				unassigned++
+				continue
+			}
+			port = next
+			inUse[port] = true
+		}
+		m.GatewayPort = port
+		res = append(res, m)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].GatewayPort < res[j].GatewayPort
+	})
+	return res, unassigned
+}
+
+// updateGatewayService sets the ports of the gateway Service within the port range to the assigned ports; the
+// other ports, e.g. the status port of Envoy, are left as they are.
+func (r *Reconciler) updateGatewayService(ctx context.Context, mappings []PortMapping) error {
+	key := types.NamespacedName{Namespace: r.FleetSystemNamespace, Name: r.ServiceName}
+	svc := &corev1.Service{}
+	if err := r.Client.Get(ctx, key, svc); err != nil {
+		if errors.IsNotFound(err) {
+			// The controller will be re-triggered when the Service is created.
+			klog.V(2).InfoS("Gateway service is not found", "service", key)
+			return nil
+		}
+		klog.ErrorS(err, "Failed to get gateway service", "service", key)
+		return err
+	}
+
+	ports := make([]corev1.ServicePort, 0, len(svc.Spec.Ports)+len(mappings))
+	for _, port := range svc.Spec.Ports {
+		if port.Port < r.MinPort || port.Port > r.MaxPort {
+			ports = append(ports, port)
+		}
+	}
+	for _, m := range mappings {
+		ports = append(ports, corev1.ServicePort{
+			Name:       fmt.Sprintf(gatewayPortNameFormat, m.GatewayPort),
+			Protocol:   corev1.ProtocolTCP,
+			Port:       m.GatewayPort,
+			TargetPort: intstr.FromInt32(m.GatewayPort),
+		})
+	}
+	if equalServicePorts(svc.Spec.Ports, ports) {
+		return nil
+	}
+	// Patch the ports only, as the Service is cached without the configuration last applied by kubectl.
+	patch := client.MergeFromWithOptions(svc.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	svc.Spec.Ports = ports
+	klog.V(2).InfoS("Updating ports of the gateway service", "service", key, "numberOfPorts", len(ports))
+	if err := r.Client.Patch(ctx, svc, patch); err != nil {
+		klog.ErrorS(err, "Failed to update gateway service", "service", key)
+		return err
+	}
+	return nil
+}
+
+// equalServicePorts compares the ports ignoring the node ports allocated by the API server.
+func equalServicePorts(current, desired []corev1.ServicePort) bool {
+	if len(current) != len(desired) {
+		return false
+	}
+	for i := range current {
+		c, d := current[i], desired[i]
+		if c.Name != d.Name || c.Protocol != d.Protocol || c.Port != d.Port || c.TargetPort != d.TargetPort {
+			return false
+		}
+	}
+	return true
+}
+
+// isServiceExportValidWithNoConflict returns if a ServiceExport is valid, in no conflict with the other service
+// exports, and not being deleted, i.e. its Service is exported.
+func isServiceExportValidWithNoConflict(svcExport *fleetnetv1alpha1.ServiceExport) bool {
+	validCond := meta.FindStatusCondition(svcExport.Status.Conditions, string(fleetnetv1alpha1.ServiceExportValid))
+	conflictCond := meta.FindStatusCondition(svcExport.Status.Conditions, string(fleetnetv1alpha1.ServiceExportConflict))
+	return validCond != nil && validCond.Status == metav1.ConditionTrue &&
+		conflictCond != nil && conflictCond.Status == metav1.ConditionFalse &&
+		svcExport.DeletionTimestamp == nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	key := types.NamespacedName{Namespace: r.FleetSystemNamespace, Name: r.ConfigMapName}
+	enqueueConfigMap := handler.EnqueueRequestsFromMapFunc(func(_ context.Context, _ client.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: key}}
+	})
+	enqueueOwnConfigMap := handler.EnqueueRequestsFromMapFunc(func(_ context.Context, object client.Object) []reconcile.Request {
+		if object.GetNamespace() != key.Namespace || object.GetName() != key.Name {
+			return []reconcile.Request{}
+		}
+		return []reconcile.Request{{NamespacedName: key}}
+	})
+	return ctrl.NewControllerManagedBy(mgr).Named("eastwestgateway").
+		Watches(&fleetnetv1alpha1.ServiceExport{}, enqueueConfigMap).
+		// The Services are watched for the changes of their ports and ClusterIPs, as well as the gateway Service.
+		Watches(&corev1.Service{}, enqueueConfigMap).
+		// The ConfigMap is watched so that the configuration is restored if it is modified or deleted.
+		Watches(&corev1.ConfigMap{}, enqueueOwnConfigMap).
+		WithOptions(r.ControllerOptions).
+		Complete(metrics.InstrumentReconciler("eastwestgateway", r))
+}
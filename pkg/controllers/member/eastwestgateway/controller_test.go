@@ -0,0 +1,181 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package eastwestgateway
+
+import (
+	"context"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+const (
+	testNamespace        = "work"
+	fleetSystemNamespace = "fleet-system"
+	gatewayServiceName   = "east-west-gateway"
+	gatewayConfigMapName = "east-west-gateway-config"
+)
+
+func TestMain(m *testing.M) {
+	// Add custom APIs to the runtime scheme
+	if err := fleetnetv1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		log.Fatalf("failed to add custom APIs to the runtime scheme: %v", err)
+	}
+	os.Exit(m.Run())
+}
+
+func gatewayConfigMap(mappings ...PortMapping) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: fleetSystemNamespace, Name: gatewayConfigMapName},
+		Data: map[string]string{
+			PortsKey:     marshal(mappings),
+			ListenersKey: renderListeners(mappings),
+			ClustersKey:  renderClusters(mappings),
+		},
+	}
+}
+
+func exportedService(name, clusterIP string, ports ...corev1.ServicePort) []client.Object {
+	return []client.Object{
+		&fleetnetv1alpha1.ServiceExport{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: name},
+			Status: fleetnetv1alpha1.ServiceExportStatus{
+				Conditions: []metav1.Condition{
+					{Type: string(fleetnetv1alpha1.ServiceExportValid), Status: metav1.ConditionTrue},
+					{Type: string(fleetnetv1alpha1.ServiceExportConflict), Status: metav1.ConditionFalse},
+				},
+			},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: name},
+			Spec:       corev1.ServiceSpec{ClusterIP: clusterIP, Ports: ports},
+		},
+	}
+}
+
+func gatewayService(ports ...corev1.ServicePort) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: fleetSystemNamespace, Name: gatewayServiceName},
+		Spec: corev1.ServiceSpec{
+			Type:  corev1.ServiceTypeLoadBalancer,
+			Ports: ports,
+		},
+	}
+}
+
+// TestReconcile tests the Reconcile method.
+func TestReconcile(t *testing.T) {
+	statusPort := corev1.ServicePort{Name: "status", Protocol: corev1.ProtocolTCP, Port: 15021, TargetPort: intstr.FromString("status")}
+	webPort := corev1.ServicePort{Name: "web", Protocol: corev1.ProtocolTCP, Port: 80}
+	dnsPort := corev1.ServicePort{Name: "dns", Protocol: corev1.ProtocolUDP, Port: 53}
+	testCases := []struct {
+		name         string
+		objs         []client.Object
+		wantMappings []PortMapping
+		wantPorts    []corev1.ServicePort
+	}{
+		{
+			name:         "no exported services",
+			objs:         []client.Object{gatewayService(statusPort)},
+			wantMappings: []PortMapping{},
+			wantPorts:    []corev1.ServicePort{statusPort},
+		},
+		{
+			name: "new exported services",
+			objs: append(append(
+				exportedService("app", "10.0.0.10", webPort, dnsPort),
+				exportedService("db", corev1.ClusterIPNone, corev1.ServicePort{Name: "sql", Protocol: corev1.ProtocolTCP, Port: 5432})...),
+				gatewayService(statusPort)),
+			wantMappings: []PortMapping{
+				{Namespace: testNamespace, Service: "app", PortName: "web", ClusterIP: "10.0.0.10", Port: 80, GatewayPort: 15100},
+			},
+			wantPorts: []corev1.ServicePort{
+				statusPort,
+				{Name: "gw-15100", Protocol: corev1.ProtocolTCP, Port: 15100, TargetPort: intstr.FromInt32(15100)},
+			},
+		},
+		{
+			name: "assigned ports are kept",
+			objs: append(append(
+				exportedService("app", "10.0.0.20", webPort),
+				exportedService("api", "10.0.0.11", webPort)...),
+				gatewayService(statusPort),
+				gatewayConfigMap(
+					PortMapping{Namespace: testNamespace, Service: "app", PortName: "web", ClusterIP: "10.0.0.10", Port: 80, GatewayPort: 15101},
+					PortMapping{Namespace: testNamespace, Service: "gone", PortName: "web", ClusterIP: "10.0.0.12", Port: 80, GatewayPort: 15100},
+				)),
+			wantMappings: []PortMapping{
+				{Namespace: testNamespace, Service: "api", PortName: "web", ClusterIP: "10.0.0.11", Port: 80, GatewayPort: 15100},
+				{Namespace: testNamespace, Service: "app", PortName: "web", ClusterIP: "10.0.0.20", Port: 80, GatewayPort: 15101},
+			},
+			wantPorts: []corev1.ServicePort{
+				statusPort,
+				{Name: "gw-15100", Protocol: corev1.ProtocolTCP, Port: 15100, TargetPort: intstr.FromInt32(15100)},
+				{Name: "gw-15101", Protocol: corev1.ProtocolTCP, Port: 15101, TargetPort: intstr.FromInt32(15101)},
+			},
+		},
+		{
+			name: "port range exhausted",
+			objs: append(append(
+				exportedService("app", "10.0.0.10", webPort),
+				exportedService("api", "10.0.0.11", webPort)...),
+				exportedService("web", "10.0.0.12", webPort)...),
+			wantMappings: []PortMapping{
+				{Namespace: testNamespace, Service: "api", PortName: "web", ClusterIP: "10.0.0.11", Port: 80, GatewayPort: 15100},
+				{Namespace: testNamespace, Service: "app", PortName: "web", ClusterIP: "10.0.0.10", Port: 80, GatewayPort: 15101},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(tc.objs...).Build()
+			r := &Reconciler{
+				Client:               fakeClient,
+				FleetSystemNamespace: fleetSystemNamespace,
+				ServiceName:          gatewayServiceName,
+				ConfigMapName:        gatewayConfigMapName,
+				MinPort:              15100,
+				MaxPort:              15101,
+			}
+			key := types.NamespacedName{Namespace: fleetSystemNamespace, Name: gatewayConfigMapName}
+			if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+
+			configMap := &corev1.ConfigMap{}
+			if err := fakeClient.Get(context.Background(), key, configMap); err != nil {
+				t.Fatalf("configMap Get() = %v, want no error", err)
+			}
+			want := gatewayConfigMap(tc.wantMappings...).Data
+			if diff := cmp.Diff(want, configMap.Data); diff != "" {
+				t.Errorf("configMap data mismatch (-want, +got):\n%s", diff)
+			}
+
+			if tc.wantPorts == nil {
+				return
+			}
+			svc := &corev1.Service{}
+			if err := fakeClient.Get(context.Background(), types.NamespacedName{Namespace: fleetSystemNamespace, Name: gatewayServiceName}, svc); err != nil {
+				t.Fatalf("service Get() = %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.wantPorts, svc.Spec.Ports); diff != "" {
+				t.Errorf("service ports mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,317 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package eastwestgateway features the east-west gateway controller deployed in member cluster, for the member
+// clusters whose Pods are not routable from the other member clusters.
+//
+// Each TCP port of the exported Services is assigned a port of the gateway, an Envoy proxy exposed by a
+// LoadBalancer Service, which forwards the connections on the port to the ClusterIP of the exported Service; the
+// EndpointSlice controller then exports the address of the gateway with the assigned ports along with the
+// addresses of the Pods, and the hub distributes the former to the importing clusters which cannot reach the Pods,
+// per the ClusterNetworkTopology of the fleet, so that they reach the Service through the gateway.
+//
+// The assigned ports are kept in a ConfigMap along with the listeners and clusters of Envoy, which is expected to be
+// mounted into Envoy and served with its filesystem based dynamic configuration, e.g.
+//
+//	dynamic_resources:
+//	  lds_config:
+//	    path_config_source:
+//	      path: /etc/envoy/dynamic/lds.json
+//	      watched_directory:
+//	        path: /etc/envoy/dynamic
+//	  cds_config:
+//	    path_config_source:
+//	      path: /etc/envoy/dynamic/cds.json
+//	      watched_directory:
+//	        path: /etc/envoy/dynamic
+package eastwestgateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// PortsKey is the key of the assigned ports in the ConfigMap.
+	PortsKey = "ports.json"
+	// ListenersKey is the key of the Envoy listeners in the ConfigMap.
+	ListenersKey = "lds.json"
+	// ClustersKey is the key of the Envoy clusters in the ConfigMap.
+	ClustersKey = "cds.json"
+)
+
+// PortMapping is a port of an exported Service assigned to a port of the gateway.
+type PortMapping struct {
+	// Namespace and Service are the namespace and the name of the exported Service.
+	Namespace string `json:"namespace"`
+	Service   string `json:"service"`
+	// PortName is the name of the Service port, which is also the name of the port in the EndpointSlices.
+	PortName string `json:"portName,omitempty"`
+	// ClusterIP and Port are the address of the Service port the gateway forwards the connections to.
+	ClusterIP string `json:"clusterIP"`
+	Port      int32  `json:"port"`
+	// GatewayPort is the port of the gateway assigned to the Service port.
+	GatewayPort int32 `json:"gatewayPort"`
+}
+
+// clusterName returns the name of the Envoy cluster of the Service port.
+func (m *PortMapping) clusterName() string {
+	return fmt.Sprintf("%s/%s/%s", m.Namespace, m.Service, m.PortName)
+}
+
+type portKey struct {
+	namespace string
+	service   string
+	portName  string
+}
+
+func (m *PortMapping) key() portKey {
+	return portKey{namespace: m.Namespace, service: m.Service, portName: m.PortName}
+}
+
+// Gateway is the address of the gateway and the ports assigned to the exported Services.
+type Gateway struct {
+	// Address is the address of the gateway reachable from the other member clusters, i.e. the ingress IP of its
+	// LoadBalancer Service; it is empty if the load balancer has not been provisioned yet.
+	Address string
+	ports   map[portKey]int32
+	// services are the exported Services with at least one port assigned.
+	services map[types.NamespacedName]bool
+}
+
+// AddressType returns the address type of the gateway address.
+func (g *Gateway) AddressType() discoveryv1.AddressType {
+	if ip := net.ParseIP(g.Address); ip != nil && ip.To4() == nil {
+		return discoveryv1.AddressTypeIPv6
+	}
+	return discoveryv1.AddressTypeIPv4
+}
+
+// Port returns the port of the gateway assigned to the port of an exported Service.
+func (g *Gateway) Port(namespace, service, portName string) (int32, bool) {
+	port, ok := g.ports[portKey{namespace: namespace, service: service, portName: portName}]
+	return port, ok
+}
+
+// Routes returns if any port of the exported Service is routed through the gateway.
+func (g *Gateway) Routes(namespace, service string) bool {
+	return g.services[types.NamespacedName{Namespace: namespace, Name: service}]
+}
+
+// Services returns the exported Services with at least one port routed through the gateway.
+func (g *Gateway) Services() []types.NamespacedName {
+	res := make([]types.NamespacedName, 0, len(g.services))
+	for svc := range g.services {
+		res = append(res, svc)
+	}
+	return res
+}
+
+// NewGateway returns a gateway at the address with the ports assigned.
+func NewGateway(address string, mappings []PortMapping) *Gateway {
+	g := &Gateway{
+		Address:  address,
+		ports:    make(map[portKey]int32, len(mappings)),
+		services: make(map[types.NamespacedName]bool, len(mappings)),
+	}
+	for i := range mappings {
+		g.ports[mappings[i].key()] = mappings[i].GatewayPort
+		g.services[types.NamespacedName{Namespace: mappings[i].Namespace, Name: mappings[i].Service}] = true
+	}
+	return g
+}
+
+// Reader reads the gateway from its Service and ConfigMap.
+type Reader struct {
+	Client client.Reader
+	// Namespace is the namespace of the Service and the ConfigMap of the gateway.
+	Namespace     string
+	ServiceName   string
+	ConfigMapName string
+}
+
+// Read returns the gateway; no ports are assigned if the ConfigMap has not been created yet.
+func (r *Reader) Read(ctx context.Context) (*Gateway, error) {
+	svc := &corev1.Service{}
+	address := ""
+	switch err := r.Client.Get(ctx, types.NamespacedName{Namespace: r.Namespace, Name: r.ServiceName}, svc); {
+	case errors.IsNotFound(err):
+	case err != nil:
+		return nil, err
+	default:
+		address = loadBalancerAddress(svc)
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: r.Namespace, Name: r.ConfigMapName}, configMap); err != nil {
+		if errors.IsNotFound(err) {
+			return NewGateway(address, nil), nil
+		}
+		return nil, err
+	}
+	return NewGateway(address, readPortMappings(configMap)), nil
+}
+
+// loadBalancerAddress returns the first ingress IP of the LoadBalancer Service.
+func loadBalancerAddress(svc *corev1.Service) string {
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		if ingress.IP != "" {
+			return ingress.IP
+		}
+	}
+	return ""
+}
+
+// readPortMappings reads the assigned ports from the ConfigMap; an invalid value is treated as no ports assigned.
+func readPortMappings(configMap *corev1.ConfigMap) []PortMapping {
+	var mappings []PortMapping
+	if err := json.Unmarshal([]byte(configMap.Data[PortsKey]), &mappings); err != nil {
+		return nil
+	}
+	return mappings
+}
+
+// ParsePortRange parses a port range in the format of <min>-<max>, e.g. 15100-15999.
+func ParsePortRange(s string) (int32, int32, error) {
+	minStr, maxStr, ok := strings.Cut(s, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("port range %q is not in the format of <min>-<max>", s)
+	}
+	minPort, err := strconv.ParseInt(strings.TrimSpace(minStr), 10, 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid min port of port range %q: %w", s, err)
+	}
+	maxPort, err := strconv.ParseInt(strings.TrimSpace(maxStr), 10, 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid max port of port range %q: %w", s, err)
+	}
+	if minPort < 1 || maxPort > 65535 || minPort > maxPort {
+		return 0, 0, fmt.Errorf("port range %q is not within 1-65535", s)
+	}
+	return int32(minPort), int32(maxPort), nil
+}
+
+// The Envoy configuration is rendered as JSON, which is valid YAML as well; only the fields in use are modeled.
+type resources struct {
+	Resources []interface{} `json:"resources"`
+}
+
+type socketAddress struct {
+	Address   string `json:"address"`
+	PortValue int32  `json:"port_value"`
+}
+
+type address struct {
+	SocketAddress socketAddress `json:"socket_address"`
+}
+
+type typedConfig struct {
+	Type       string `json:"@type"`
+	StatPrefix string `json:"stat_prefix"`
+	Cluster    string `json:"cluster"`
+}
+
+type filter struct {
+	Name        string      `json:"name"`
+	TypedConfig typedConfig `json:"typed_config"`
+}
+
+type filterChain struct {
+	Filters []filter `json:"filters"`
+}
+
+type listener struct {
+	Type         string        `json:"@type"`
+	Name         string        `json:"name"`
+	Address      address       `json:"address"`
+	FilterChains []filterChain `json:"filter_chains"`
+}
+
+type lbEndpoint struct {
+	Endpoint struct {
+		Address address `json:"address"`
+	} `json:"endpoint"`
+}
+
+type localityLbEndpoints struct {
+	LbEndpoints []lbEndpoint `json:"lb_endpoints"`
+}
+
+type loadAssignment struct {
+	ClusterName string                `json:"cluster_name"`
+	Endpoints   []localityLbEndpoints `json:"endpoints"`
+}
+
+type cluster struct {
+	Type           string         `json:"@type"`
+	Name           string         `json:"name"`
+	DiscoveryType  string         `json:"type"`
+	ConnectTimeout string         `json:"connect_timeout"`
+	LoadAssignment loadAssignment `json:"load_assignment"`
+}
+
+const (
+	listenerType = "type.googleapis.com/envoy.config.listener.v3.Listener"
+	clusterType  = "type.googleapis.com/envoy.config.cluster.v3.Cluster"
+	tcpProxyType = "type.googleapis.com/envoy.extensions.filters.network.tcp_proxy.v3.TcpProxy"
+	tcpProxyName = "envoy.filters.network.tcp_proxy"
+
+	connectTimeout = "5s"
+)
+
+// renderListeners renders a TCP proxy listener on each assigned gateway port.
+func renderListeners(mappings []PortMapping) string {
+	res := resources{Resources: make([]interface{}, 0, len(mappings))}
+	for i := range mappings {
+		m := &mappings[i]
+		res.Resources = append(res.Resources, listener{
+			Type:    listenerType,
+			Name:    fmt.Sprintf("port-%d", m.GatewayPort),
+			Address: address{SocketAddress: socketAddress{Address: "0.0.0.0", PortValue: m.GatewayPort}},
+			FilterChains: []filterChain{{Filters: []filter{{
+				Name:        tcpProxyName,
+				TypedConfig: typedConfig{Type: tcpProxyType, StatPrefix: m.clusterName(), Cluster: m.clusterName()},
+			}}}},
+		})
+	}
+	return marshal(res)
+}
+
+// renderClusters renders a static cluster of the ClusterIP of each assigned Service port.
+func renderClusters(mappings []PortMapping) string {
+	res := resources{Resources: make([]interface{}, 0, len(mappings))}
+	for i := range mappings {
+		m := &mappings[i]
+		endpoint := lbEndpoint{}
+		endpoint.Endpoint.Address = address{SocketAddress: socketAddress{Address: m.ClusterIP, PortValue: m.Port}}
+		res.Resources = append(res.Resources, cluster{
+			Type:           clusterType,
+			Name:           m.clusterName(),
+			DiscoveryType:  "STATIC",
+			ConnectTimeout: connectTimeout,
+			LoadAssignment: loadAssignment{
+				ClusterName: m.clusterName(),
+				Endpoints:   []localityLbEndpoints{{LbEndpoints: []lbEndpoint{endpoint}}},
+			},
+		})
+	}
+	return marshal(res)
+}
+
+func marshal(v interface{}) string {
+	// The values are plain structs and never fail to be marshaled.
+	data, _ := json.MarshalIndent(v, "", "  ")
+	return string(data)
+}
@@ -0,0 +1,241 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package eastwestgateway
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestParsePortRange tests the ParsePortRange function.
+func TestParsePortRange(t *testing.T) {
+	testCases := []struct {
+		name    string
+		s       string
+		wantMin int32
+		wantMax int32
+		wantErr bool
+	}{
+		{
+			name:    "valid range",
+			s:       "15100-15999",
+			wantMin: 15100,
+			wantMax: 15999,
+		},
+		{
+			name:    "single port",
+			s:       "15100-15100",
+			wantMin: 15100,
+			wantMax: 15100,
+		},
+		{
+			name:    "no separator",
+			s:       "15100",
+			wantErr: true,
+		},
+		{
+			name:    "invalid port",
+			s:       "15100-abc",
+			wantErr: true,
+		},
+		{
+			name:    "min greater than max",
+			s:       "15999-15100",
+			wantErr: true,
+		},
+		{
+			name:    "out of range",
+			s:       "0-70000",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotMin, gotMax, err := ParsePortRange(tc.s)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("ParsePortRange(%q) = %v, want error %v", tc.s, err, tc.wantErr)
+			}
+			if gotMin != tc.wantMin || gotMax != tc.wantMax {
+				t.Errorf("ParsePortRange(%q) = %d, %d, want %d, %d", tc.s, gotMin, gotMax, tc.wantMin, tc.wantMax)
+			}
+		})
+	}
+}
+
+// TestRead tests the Reader.Read method.
+func TestRead(t *testing.T) {
+	gatewayService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: fleetSystemNamespace, Name: gatewayServiceName},
+		Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{
+			Ingress: []corev1.LoadBalancerIngress{{Hostname: "gateway.contoso.com"}, {IP: "20.0.0.1"}},
+		}},
+	}
+	testCases := []struct {
+		name            string
+		objs            []client.Object
+		wantAddress     string
+		wantAddressType discoveryv1.AddressType
+		wantServices    []types.NamespacedName
+		wantPort        int32
+		wantPortFound   bool
+	}{
+		{
+			name:            "no gateway",
+			wantAddressType: discoveryv1.AddressTypeIPv4,
+			wantServices:    []types.NamespacedName{},
+		},
+		{
+			name: "gateway with ports assigned",
+			objs: []client.Object{
+				gatewayService,
+				gatewayConfigMap(PortMapping{Namespace: testNamespace, Service: "app", PortName: "web", ClusterIP: "10.0.0.10", Port: 80, GatewayPort: 15100}),
+			},
+			wantAddress:     "20.0.0.1",
+			wantAddressType: discoveryv1.AddressTypeIPv4,
+			wantServices:    []types.NamespacedName{{Namespace: testNamespace, Name: "app"}},
+			wantPort:        15100,
+			wantPortFound:   true,
+		},
+		{
+			name: "IPv6 gateway without configMap",
+			objs: []client.Object{
+				&corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{Namespace: fleetSystemNamespace, Name: gatewayServiceName},
+					Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{
+						Ingress: []corev1.LoadBalancerIngress{{IP: "2001:db8::1"}},
+					}},
+				},
+			},
+			wantAddress:     "2001:db8::1",
+			wantAddressType: discoveryv1.AddressTypeIPv6,
+			wantServices:    []types.NamespacedName{},
+		},
+		{
+			name: "invalid configMap",
+			objs: []client.Object{
+				gatewayService,
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: fleetSystemNamespace, Name: gatewayConfigMapName},
+					Data:       map[string]string{PortsKey: "invalid"},
+				},
+			},
+			wantAddress:     "20.0.0.1",
+			wantAddressType: discoveryv1.AddressTypeIPv4,
+			wantServices:    []types.NamespacedName{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &Reader{
+				Client:        fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(tc.objs...).Build(),
+				Namespace:     fleetSystemNamespace,
+				ServiceName:   gatewayServiceName,
+				ConfigMapName: gatewayConfigMapName,
+			}
+			got, err := r.Read(context.Background())
+			if err != nil {
+				t.Fatalf("Read() = %v, want no error", err)
+			}
+			if got.Address != tc.wantAddress {
+				t.Errorf("Read() address = %q, want %q", got.Address, tc.wantAddress)
+			}
+			if got.AddressType() != tc.wantAddressType {
+				t.Errorf("Read() address type = %q, want %q", got.AddressType(), tc.wantAddressType)
+			}
+			if diff := cmp.Diff(tc.wantServices, got.Services()); diff != "" {
+				t.Errorf("Read() services mismatch (-want, +got):\n%s", diff)
+			}
+			port, found := got.Port(testNamespace, "app", "web")
+			if port != tc.wantPort || found != tc.wantPortFound {
+				t.Errorf("Port() = %d, %t, want %d, %t", port, found, tc.wantPort, tc.wantPortFound)
+			}
+		})
+	}
+}
+
+// TestRender tests the renderListeners and renderClusters functions.
+func TestRender(t *testing.T) {
+	mappings := []PortMapping{
+		{Namespace: testNamespace, Service: "app", PortName: "web", ClusterIP: "10.0.0.10", Port: 80, GatewayPort: 15100},
+	}
+	wantListeners := `{
+  "resources": [
+    {
+      "@type": "type.googleapis.com/envoy.config.listener.v3.Listener",
+      "name": "port-15100",
+      "address": {
+        "socket_address": {
+          "address": "0.0.0.0",
+          "port_value": 15100
+        }
+      },
+      "filter_chains": [
+        {
+          "filters": [
+            {
+              "name": "envoy.filters.network.tcp_proxy",
+              "typed_config": {
+                "@type": "type.googleapis.com/envoy.extensions.filters.network.tcp_proxy.v3.TcpProxy",
+                "stat_prefix": "work/app/web",
+                "cluster": "work/app/web"
+              }
+            }
+          ]
+        }
+      ]
+    }
+  ]
+}`
+	if diff := cmp.Diff(wantListeners, renderListeners(mappings)); diff != "" {
+		t.Errorf("renderListeners() mismatch (-want, +got):\n%s", diff)
+	}
+	wantClusters := `{
+  "resources": [
+    {
+      "@type": "type.googleapis.com/envoy.config.cluster.v3.Cluster",
+      "name": "work/app/web",
+      "type": "STATIC",
+      "connect_timeout": "5s",
+      "load_assignment": {
+        "cluster_name": "work/app/web",
+        "endpoints": [
+          {
+            "lb_endpoints": [
+              {
+                "endpoint": {
+                  "address": {
+                    "socket_address": {
+                      "address": "10.0.0.10",
+                      "port_value": 80
+                    }
+                  }
+                }
+              }
+            ]
+          }
+        ]
+      }
+    }
+  ]
+}`
+	if diff := cmp.Diff(wantClusters, renderClusters(mappings)); diff != "" {
+		t.Errorf("renderClusters() mismatch (-want, +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(`{
+  "resources": []
+}`, renderListeners(nil)); diff != "" {
+		t.Errorf("renderListeners(nil) mismatch (-want, +got):\n%s", diff)
+	}
+}
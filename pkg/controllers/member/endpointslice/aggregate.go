@@ -0,0 +1,492 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package endpointslice
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/exportdrain"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/pause"
+	"go.goms.io/fleet-networking/pkg/common/tracing"
+	"go.goms.io/fleet-networking/pkg/common/uniquename"
+	"go.goms.io/fleet-networking/pkg/controllers/member/eastwestgateway"
+	"go.goms.io/fleet-networking/pkg/controllers/member/loadbalancerexport"
+)
+
+// maxAggregatedEndpoints is the maximum number of endpoints an aggregated EndpointSliceExport carries; it is the
+// maximum number of endpoints of an EndpointSlice, so that each aggregated EndpointSliceExport is still imported
+// as one EndpointSlice.
+const maxAggregatedEndpoints = 1000
+
+// endpointGroup is the endpoints of the EndpointSlices of a Service which share an address type and the exported
+// ports; each group is exported with one or more aggregated EndpointSliceExports.
+type endpointGroup struct {
+	key         string
+	addressType discoveryv1.AddressType
+	ports       []discoveryv1.EndpointPort
+	endpoints   []fleetnetv1alpha1.Endpoint
+	gateway     *fleetnetv1alpha1.GatewayEndpoints
+	// endpointSlice is one of the EndpointSlices of the group; the Service and the address type of the group are
+	// read from it.
+	endpointSlice *discoveryv1.EndpointSlice
+}
+
+// reconcileService exports the endpoints of all the EndpointSlices of a Service with as few EndpointSliceExports as
+// possible, i.e. one for each address type and set of exported ports, split every maxAggregatedEndpoints endpoints,
+// instead of one for each EndpointSlice; the EndpointSliceExports exported otherwise for the Service are withdrawn.
+func (r *Reconciler) reconcileService(ctx context.Context, svcKey types.NamespacedName) (ctrl.Result, error) {
+	logger := klog.FromContext(ctx).WithValues("service", klog.KRef(svcKey.Namespace, svcKey.Name))
+	ctx = klog.NewContext(ctx, logger)
+	startTime := time.Now()
+	logger.V(2).Info("Reconciliation starts")
+	defer func() {
+		latency := time.Since(startTime).Milliseconds()
+		logger.V(2).Info("Reconciliation ends", "latency", latency)
+	}()
+
+	// Unexport the endpoints of the Service if it is not, or is no longer, exported with no conflicts, or if it is
+	// being deleted; the endpoints of a deleted ServiceExport stay exported while they are drained.
+	svcExport := &fleetnetv1alpha1.ServiceExport{}
+	err := r.MemberClient.Get(ctx, svcKey, svcExport)
+	switch {
+	case errors.IsNotFound(err):
+		logger.V(4).Info("Service is not exported; its endpoints should be unexported")
+		return ctrl.Result{}, r.unexportService(ctx, svcKey)
+	case err != nil:
+		logger.Error(err, "Failed to get the service export")
+		return ctrl.Result{}, err
+	case pause.IsPaused(svcExport):
+		logger.V(4).Info("Reconciliation of the service export is paused; its endpoints are left as they are")
+		return ctrl.Result{}, nil
+	case !isServiceExportValidWithNoConflict(svcExport) && !isServiceExportDraining(svcExport, startTime):
+		logger.V(4).Info("Service export is invalid or in conflict; its endpoints should be unexported")
+		return ctrl.Result{}, r.unexportService(ctx, svcKey)
+	case r.ExportOnDemand && !isServiceExportImported(svcExport):
+		logger.V(4).Info("Service is not imported by other member clusters; its endpoints should be unexported")
+		return ctrl.Result{}, r.unexportService(ctx, svcKey)
+	}
+	svc := &corev1.Service{}
+	err = r.MemberClient.Get(ctx, svcKey, svc)
+	switch {
+	case errors.IsNotFound(err) || (err == nil && svc.DeletionTimestamp != nil):
+		logger.V(4).Info("Service is gone or being deleted; its endpoints should be unexported")
+		return ctrl.Result{}, r.unexportService(ctx, svcKey)
+	case err != nil:
+		logger.Error(err, "Failed to get the service")
+		return ctrl.Result{}, err
+	}
+
+	groups, err := r.groupEndpoints(ctx, svcExport, svc)
+	if err != nil {
+		logger.Error(err, "Failed to group the endpoints of the service")
+		return ctrl.Result{}, err
+	}
+
+	endpointSliceExportList, err := r.listServiceEndpointSliceExports(ctx, svcKey)
+	if err != nil {
+		logger.Error(err, "Failed to list the endpoint slice exports of the service")
+		return ctrl.Result{}, err
+	}
+
+	// Withhold the newly ready endpoints until they complete their warmup; the endpoints already advertised are read
+	// from the existing aggregated EndpointSliceExports of the same address type.
+	warmup, err := extractEndpointWarmup(svcExport)
+	if err != nil {
+		logger.Error(err, "Ignoring the endpoint warmup period", "serviceExport", klog.KObj(svcExport))
+	}
+	nextWarmedUp := r.withholdAggregatedWarmingUpEndpoints(svcKey, groups, endpointSliceExportList.Items, warmup)
+
+	desired := map[string]bool{}
+	changed := false
+	exportedEndpoints := 0
+	indexes := map[discoveryv1.AddressType]int{}
+	for _, group := range groups {
+		var privateLinkEndpoints *fleetnetv1alpha1.PrivateLinkEndpoints
+		if _, ok := svcExport.Annotations[objectmeta.ServiceExportAnnotationPrivateLinkServiceID]; ok {
+			privateLinkEndpoints = exposeThroughPrivateLink(svcExport, svc, group.ports)
+		}
+		for _, chunk := range chunkEndpoints(group.endpoints, maxAggregatedEndpoints) {
+			name := uniquename.FleetScopedDeterministicName(r.MemberClusterID, svc.Namespace,
+				fmt.Sprintf("%s-%s-%d", svc.Name, strings.ToLower(string(group.addressType)), indexes[group.addressType]))
+			indexes[group.addressType]++
+			desired[name] = true
+
+			spec := fleetnetv1alpha1.EndpointSliceExportSpec{
+				AddressType: group.addressType,
+				Endpoints:   chunk,
+				Ports:       group.ports,
+				Gateway:     group.gateway,
+				PrivateLink: privateLinkEndpoints,
+			}
+			op, err := r.createOrUpdateAggregatedExport(ctx, svc, name, &spec, startTime)
+			if err != nil {
+				logger.Error(err, "Failed to create/update aggregated endpoint slice export",
+					"endpointSliceExport", klog.KRef(r.HubNamespace, name), "op", op)
+				return ctrl.Result{}, err
+			}
+			changed = changed || op != controllerutil.OperationResultNone
+			exportedEndpoints += len(chunk)
+		}
+	}
+
+	// Withdraw the EndpointSliceExports of the Service which are no longer needed, i.e. the aggregated ones left over
+	// after the endpoints shrink, and the ones exported for each EndpointSlice before the exports were aggregated.
+	for idx := range endpointSliceExportList.Items {
+		endpointSliceExport := &endpointSliceExportList.Items[idx]
+		if desired[endpointSliceExport.Name] {
+			continue
+		}
+		logger.V(2).Info("Delete the endpoint slice export no longer needed", "endpointSliceExport", klog.KObj(endpointSliceExport))
+		if err := r.HubClient.Delete(ctx, endpointSliceExport); err != nil && !errors.IsNotFound(err) {
+			logger.Error(err, "Failed to delete endpoint slice export", "endpointSliceExport", klog.KObj(endpointSliceExport))
+			return ctrl.Result{}, err
+		}
+		changed = true
+	}
+	if changed {
+		r.Recorder.Eventf(svcExport, corev1.EventTypeNormal, "EndpointsPropagated",
+			"Propagated %d endpoints of Service %s to the fleet", exportedEndpoints, svc.Name)
+	}
+
+	// Periodically re-scan exported Services, for the same reasons as exported EndpointSlices.
+	requeueAfter := endpointSliceResyncInterval
+	if nextWarmedUp > 0 && nextWarmedUp < requeueAfter {
+		requeueAfter = nextWarmedUp
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// groupEndpoints extracts the endpoints to export from the EndpointSlices of an exported Service and groups them by
+// address type and exported ports; the endpoints present in more than one EndpointSlice, e.g. while an endpoint is
+// moved from one EndpointSlice to another, are exported once. The groups are sorted by their keys, and the
+// endpoints of each group by their addresses, so that the exports stay the same until the endpoints change.
+func (r *Reconciler) groupEndpoints(ctx context.Context, svcExport *fleetnetv1alpha1.ServiceExport,
+	svc *corev1.Service) ([]*endpointGroup, error) {
+	endpointSliceList := &discoveryv1.EndpointSliceList{}
+	if err := r.MemberClient.List(ctx, endpointSliceList,
+		client.InNamespace(svc.Namespace), client.MatchingLabels{discoveryv1.LabelServiceName: svc.Name}); err != nil {
+		return nil, err
+	}
+
+	draining := isServiceExportDraining(svcExport, time.Now())
+	groupsByKey := map[string]*endpointGroup{}
+	for idx := range endpointSliceList.Items {
+		endpointSlice := &endpointSliceList.Items[idx]
+		if isEndpointSlicePermanentlyUnexportable(endpointSlice) || endpointSlice.DeletionTimestamp != nil {
+			continue
+		}
+		endpoints, err := r.extractSelectedEndpoints(ctx, endpointSlice, svcExport)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.setEndpointRegions(ctx, endpoints); err != nil {
+			return nil, err
+		}
+		if draining {
+			endpoints = exportdrain.Drain(endpoints)
+		}
+		ports, err := r.extractSelectedPorts(ctx, endpointSlice, svcExport)
+		if err != nil {
+			return nil, err
+		}
+		key := fmt.Sprintf("%s/%s", endpointSlice.AddressType, portsKey(ports))
+		group, ok := groupsByKey[key]
+		if !ok {
+			group = &endpointGroup{key: key, addressType: endpointSlice.AddressType, ports: ports, endpointSlice: endpointSlice}
+			groupsByKey[key] = group
+		}
+		group.endpoints = append(group.endpoints, endpoints...)
+	}
+
+	if len(groupsByKey) == 0 {
+		return []*endpointGroup{}, nil
+	}
+	loadBalancerMode := loadbalancerexport.IsLoadBalancerMode(svcExport)
+	var lbSvc *corev1.Service
+	if loadBalancerMode {
+		var err error
+		if lbSvc, err = r.getLoadBalancerService(ctx, types.NamespacedName{Namespace: svc.Namespace, Name: svc.Name}); err != nil {
+			return nil, err
+		}
+	}
+	var gateway *eastwestgateway.Gateway
+	// The internal load balancer is reached by the other member clusters directly, instead of through the gateway.
+	if r.EastWestGateway != nil && !loadBalancerMode {
+		var err error
+		if gateway, err = r.EastWestGateway.Read(ctx); err != nil {
+			return nil, err
+		}
+	}
+	groups := make([]*endpointGroup, 0, len(groupsByKey))
+	for _, group := range groupsByKey {
+		group.endpoints = dedupEndpoints(group.endpoints)
+		if loadBalancerMode {
+			group.endpoints, group.ports = exportThroughLoadBalancer(lbSvc, group.endpointSlice, group.endpoints, group.ports)
+		}
+		if gateway != nil {
+			group.gateway = routeThroughGateway(gateway, group.endpointSlice, group.endpoints, group.ports)
+		}
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].key < groups[j].key })
+	return groups, nil
+}
+
+// withholdAggregatedWarmingUpEndpoints withholds the endpoints of each group still warming up, tracking the warmup
+// of the endpoints of each address type of a Service as a whole, and returns how long until the next of them
+// completes its warmup (0 if none).
+func (r *Reconciler) withholdAggregatedWarmingUpEndpoints(svcKey types.NamespacedName, groups []*endpointGroup,
+	endpointSliceExports []fleetnetv1alpha1.EndpointSliceExport, warmup time.Duration) time.Duration {
+	var nextWarmedUp time.Duration
+	for _, addressType := range []discoveryv1.AddressType{discoveryv1.AddressTypeIPv4, discoveryv1.AddressTypeIPv6} {
+		serving := []fleetnetv1alpha1.Endpoint{}
+		for _, group := range groups {
+			if group.addressType == addressType {
+				serving = append(serving, group.endpoints...)
+			}
+		}
+		advertised := []fleetnetv1alpha1.Endpoint{}
+		for idx := range endpointSliceExports {
+			if endpointSliceExports[idx].IsAggregated() && endpointSliceExports[idx].Spec.AddressType == addressType {
+				advertised = append(advertised, endpointSliceExports[idx].Spec.Endpoints...)
+			}
+		}
+		kept, next := r.withholdWarmingUpEndpoints(aggregatedWarmupKey(svcKey, addressType), serving, advertised, warmup)
+		if next > 0 && (nextWarmedUp == 0 || next < nextWarmedUp) {
+			nextWarmedUp = next
+		}
+
+		isKept := make(map[string]bool, len(kept))
+		for i := range kept {
+			isKept[endpointKey(&kept[i])] = true
+		}
+		for _, group := range groups {
+			if group.addressType != addressType {
+				continue
+			}
+			endpoints := []fleetnetv1alpha1.Endpoint{}
+			for i := range group.endpoints {
+				if isKept[endpointKey(&group.endpoints[i])] {
+					endpoints = append(endpoints, group.endpoints[i])
+				}
+			}
+			group.endpoints = endpoints
+		}
+	}
+	return nextWarmedUp
+}
+
+// createOrUpdateAggregatedExport creates or updates an aggregated EndpointSliceExport of a Service with the given
+// spec; the reference to the Service is bumped to a new generation, exported at the given time, whenever the spec
+// changes, as the importing side tells the versions of an EndpointSliceExport apart by the generation.
+func (r *Reconciler) createOrUpdateAggregatedExport(ctx context.Context, svc *corev1.Service, name string,
+	spec *fleetnetv1alpha1.EndpointSliceExportSpec, exportedSince time.Time) (controllerutil.OperationResult, error) {
+	endpointSliceExport := &fleetnetv1alpha1.EndpointSliceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: r.HubNamespace,
+			Name:      name,
+		},
+	}
+	return r.createOrUpdateFunc()(ctx, r.HubClient, endpointSliceExport, func() error {
+		// The EndpointSliceExport is about to be created if it has no resource version yet.
+		if endpointSliceExport.ResourceVersion == "" {
+			svcTypeMeta := metav1.TypeMeta{APIVersion: "v1", Kind: "Service"}
+			endpointSliceExport.Spec.EndpointSliceReference = fleetnetv1alpha1.FromMetaObjects(r.MemberClusterID,
+				svcTypeMeta, svc.ObjectMeta, metav1.NewTime(exportedSince))
+			endpointSliceExport.Spec.EndpointSliceReference.Generation = 0
+		}
+		// Return an error if the name is taken by an EndpointSliceExport of another object.
+		if !endpointSliceExport.IsAggregated() || endpointSliceExport.Spec.EndpointSliceReference.UID != svc.UID {
+			return errors.NewAlreadyExists(
+				schema.GroupResource{Group: fleetnetv1alpha1.GroupVersion.Group, Resource: "EndpointSliceExport"},
+				name,
+			)
+		}
+
+		if endpointSliceExport.Labels == nil {
+			endpointSliceExport.Labels = map[string]string{}
+		}
+		endpointSliceExport.Labels[objectmeta.EndpointSliceExportLabelOwnerServiceNamespace] = svc.Namespace
+		endpointSliceExport.Labels[objectmeta.EndpointSliceExportLabelOwnerServiceName] = svc.Name
+
+		oldSpec := endpointSliceExport.Spec.DeepCopy()
+		endpointSliceExport.Spec.AddressType = spec.AddressType
+		endpointSliceExport.Spec.Endpoints = spec.Endpoints
+		endpointSliceExport.Spec.Ports = spec.Ports
+		endpointSliceExport.Spec.Gateway = spec.Gateway
+		endpointSliceExport.Spec.PrivateLink = spec.PrivateLink
+		endpointSliceExport.Spec.OwnerServiceReference = fleetnetv1alpha1.OwnerServiceReference{
+			Namespace:      svc.Namespace,
+			Name:           svc.Name,
+			NamespacedName: fmt.Sprintf("%s/%s", svc.Namespace, svc.Name),
+		}
+		if !equality.Semantic.DeepEqual(oldSpec, &endpointSliceExport.Spec) {
+			ref := &endpointSliceExport.Spec.EndpointSliceReference
+			ref.ResourceVersion = svc.ResourceVersion
+			ref.Generation++
+			ref.ExportedSince = metav1.NewTime(exportedSince)
+			// Only a new version of the export carries the correlation ID of this reconciliation.
+			endpointSliceExport.Annotations = tracing.SetCorrelationID(ctx, endpointSliceExport.Annotations)
+		}
+		return nil
+	})
+}
+
+// unexportService withdraws all the EndpointSliceExports of a Service from the hub cluster.
+func (r *Reconciler) unexportService(ctx context.Context, svcKey types.NamespacedName) error {
+	for _, addressType := range []discoveryv1.AddressType{discoveryv1.AddressTypeIPv4, discoveryv1.AddressTypeIPv6} {
+		r.forgetWarmingUpEndpoints(aggregatedWarmupKey(svcKey, addressType))
+	}
+	endpointSliceExportList, err := r.listServiceEndpointSliceExports(ctx, svcKey)
+	if err != nil {
+		return err
+	}
+	for idx := range endpointSliceExportList.Items {
+		if err := r.HubClient.Delete(ctx, &endpointSliceExportList.Items[idx]); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// listServiceEndpointSliceExports lists the EndpointSliceExports derived from a Service, aggregated or not.
+func (r *Reconciler) listServiceEndpointSliceExports(ctx context.Context,
+	svcKey types.NamespacedName) (*fleetnetv1alpha1.EndpointSliceExportList, error) {
+	endpointSliceExportList := &fleetnetv1alpha1.EndpointSliceExportList{}
+	err := r.HubClient.List(ctx, endpointSliceExportList,
+		client.InNamespace(r.HubNamespace),
+		client.MatchingLabels{
+			objectmeta.EndpointSliceExportLabelOwnerServiceNamespace: svcKey.Namespace,
+			objectmeta.EndpointSliceExportLabelOwnerServiceName:      svcKey.Name,
+		})
+	return endpointSliceExportList, err
+}
+
+// setupAggregatedWithManager sets up the EndpointSlice controller with a controller manager, reconciling the
+// Services owning the EndpointSlices rather than the EndpointSlices themselves.
+func (r *Reconciler) setupAggregatedWithManager(mgr ctrl.Manager) error {
+	// A load balancer service created for an exported Service enqueues the exported Service.
+	svcEventHandlers := handler.EnqueueRequestsFromMapFunc(func(_ context.Context, o client.Object) []reconcile.Request {
+		svcName := o.GetName()
+		if name, ok := o.GetLabels()[objectmeta.ServiceLabelLoadBalancerFor]; ok {
+			svcName = name
+		}
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: o.GetNamespace(), Name: svcName}}}
+	})
+	endpointSliceEventHandlers := r.debouncedEventHandler(func(o client.Object) (reconcile.Request, bool) {
+		svcName, ok := o.GetLabels()[discoveryv1.LabelServiceName]
+		return reconcile.Request{NamespacedName: types.NamespacedName{Namespace: o.GetNamespace(), Name: svcName}}, ok
+	})
+
+	// The controller keeps the name it has when it reconciles EndpointSlices, so that its metrics carry on.
+	builder := ctrl.NewControllerManagedBy(mgr).
+		Named("endpointslice").
+		Watches(&discoveryv1.EndpointSlice{}, endpointSliceEventHandlers).
+		Watches(&fleetnetv1alpha1.ServiceExport{}, svcEventHandlers).
+		Watches(&corev1.Service{}, svcEventHandlers)
+	if r.EastWestGateway != nil {
+		gatewayEventHandlers := handler.EnqueueRequestsFromMapFunc(r.enqueueGatewayRoutedServices)
+		builder = builder.
+			Watches(&corev1.ConfigMap{}, gatewayEventHandlers).
+			Watches(&corev1.Service{}, gatewayEventHandlers)
+	}
+	if r.ResyncEvents != nil {
+		builder = builder.WatchesRawSource(source.Channel(r.ResyncEvents, svcEventHandlers))
+	}
+	opts := r.ControllerOptions
+	opts.NewQueue = r.NewQueue
+	return builder.WithOptions(opts).Complete(metrics.InstrumentReconciler("endpointslice", r))
+}
+
+// enqueueGatewayRoutedServices enqueues the Services routed through the east-west gateway, when the ConfigMap or
+// the Service of the gateway changes.
+func (r *Reconciler) enqueueGatewayRoutedServices(ctx context.Context, o client.Object) []reconcile.Request {
+	gw := r.EastWestGateway
+	if o.GetNamespace() != gw.Namespace || (o.GetName() != gw.ConfigMapName && o.GetName() != gw.ServiceName) {
+		return []reconcile.Request{}
+	}
+	gateway, err := gw.Read(ctx)
+	if err != nil {
+		klog.ErrorS(err, "Failed to read the east-west gateway")
+		return []reconcile.Request{}
+	}
+	reqs := []reconcile.Request{}
+	for _, svc := range gateway.Services() {
+		reqs = append(reqs, reconcile.Request{NamespacedName: svc})
+	}
+	return reqs
+}
+
+// aggregatedWarmupKey returns the key under which the warmup of the endpoints of an address type of a Service is
+// tracked; the slash keeps it apart from the keys of EndpointSlices.
+func aggregatedWarmupKey(svcKey types.NamespacedName, addressType discoveryv1.AddressType) types.NamespacedName {
+	return types.NamespacedName{Namespace: svcKey.Namespace, Name: svcKey.Name + "/" + string(addressType)}
+}
+
+// portsKey returns the key which identifies a set of ports regardless of their order.
+func portsKey(ports []discoveryv1.EndpointPort) string {
+	keys := make([]string, 0, len(ports))
+	for _, port := range ports {
+		keys = append(keys, fmt.Sprintf("%s:%s:%d:%s", ptr.Deref(port.Name, ""), ptr.Deref(port.Protocol, corev1.ProtocolTCP),
+			ptr.Deref(port.Port, 0), ptr.Deref(port.AppProtocol, "")))
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+// dedupEndpoints returns the endpoints sorted by their keys, with one endpoint kept for each key; a ready endpoint
+// is preferred over a duplicate which is not.
+func dedupEndpoints(endpoints []fleetnetv1alpha1.Endpoint) []fleetnetv1alpha1.Endpoint {
+	byKey := make(map[string]int, len(endpoints))
+	res := make([]fleetnetv1alpha1.Endpoint, 0, len(endpoints))
+	for i := range endpoints {
+		key := endpointKey(&endpoints[i])
+		if j, ok := byKey[key]; ok {
+			if !res[j].IsReady() && endpoints[i].IsReady() {
+				res[j] = endpoints[i]
+			}
+			continue
+		}
+		byKey[key] = len(res)
+		res = append(res, endpoints[i])
+	}
+	sort.Slice(res, func(i, j int) bool { return endpointKey(&res[i]) < endpointKey(&res[j]) })
+	return res
+}
+
+// chunkEndpoints splits endpoints into chunks of at most size endpoints; there is always at least one chunk, so that
+// a Service with no endpoints to export still has its ports exported.
+func chunkEndpoints(endpoints []fleetnetv1alpha1.Endpoint, size int) [][]fleetnetv1alpha1.Endpoint {
+	chunks := [][]fleetnetv1alpha1.Endpoint{}
+	for len(endpoints) > size {
+		chunks = append(chunks, endpoints[:size])
+		endpoints = endpoints[size:]
+	}
+	return append(chunks, endpoints)
+}
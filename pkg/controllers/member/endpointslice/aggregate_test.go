@@ -0,0 +1,242 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package endpointslice
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+const aggregatedEndpointSliceExportName = "bravelion-work-app-ipv4-0"
+
+// serviceEndpointSlice returns an IPv4 EndpointSlice of the Service with the given endpoint addresses.
+func serviceEndpointSlice(name string, addresses ...string) *discoveryv1.EndpointSlice {
+	endpointSlice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: memberUserNS,
+			Name:      name,
+			Labels: map[string]string{
+				discoveryv1.LabelServiceName: svcName,
+			},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Ports: []discoveryv1.EndpointPort{
+			{Name: ptr.To("http"), Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To[int32](8080)},
+		},
+	}
+	for _, address := range addresses {
+		endpointSlice.Endpoints = append(endpointSlice.Endpoints, discoveryv1.Endpoint{Addresses: []string{address}})
+	}
+	return endpointSlice
+}
+
+// TestReconcileService tests that the endpoints of all the EndpointSlices of a Service are exported with one
+// aggregated EndpointSliceExport, which is bumped to a new generation only when the endpoints change.
+func TestReconcileService(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: memberUserNS,
+			Name:      svcName,
+			UID:       "svc-uid",
+		},
+	}
+	svcExport := &fleetnetv1alpha1.ServiceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: memberUserNS,
+			Name:      svcName,
+		},
+		Status: fleetnetv1alpha1.ServiceExportStatus{
+			Conditions: []metav1.Condition{
+				serviceExportValidCondition(memberUserNS, svcName),
+				serviceExportNoConflictCondition(memberUserNS, svcName),
+			},
+		},
+	}
+	// An EndpointSliceExport exported for an EndpointSlice before the exports were aggregated.
+	legacyEndpointSliceExport := &fleetnetv1alpha1.EndpointSliceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: hubNSForMember,
+			Name:      endpointSliceUniqueName,
+			Labels: map[string]string{
+				objectmeta.EndpointSliceExportLabelOwnerServiceNamespace: memberUserNS,
+				objectmeta.EndpointSliceExportLabelOwnerServiceName:      svcName,
+			},
+		},
+	}
+
+	fakeMemberClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(svc, svcExport,
+			serviceEndpointSlice("app-1", "3.4.5.6", "1.2.3.4"),
+			// An endpoint which is moving from one EndpointSlice to another is exported once.
+			serviceEndpointSlice("app-2", "1.2.3.4", "2.3.4.5")).
+		Build()
+	fakeHubClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(legacyEndpointSliceExport).
+		Build()
+	reconciler := &Reconciler{
+		Recorder:         record.NewFakeRecorder(10),
+		MemberClusterID:  memberClusterID,
+		MemberClient:     fakeMemberClient,
+		HubClient:        fakeHubClient,
+		HubNamespace:     hubNSForMember,
+		AggregateExports: true,
+	}
+	ctx := context.Background()
+
+	reconcileAndGet := func() *fleetnetv1alpha1.EndpointSliceExport {
+		if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: svcKey}); err != nil {
+			t.Fatalf("Reconcile() = %v, want no error", err)
+		}
+		endpointSliceExportList := &fleetnetv1alpha1.EndpointSliceExportList{}
+		if err := fakeHubClient.List(ctx, endpointSliceExportList); err != nil {
+			t.Fatalf("endpointSliceExport List() = %v, want no error", err)
+		}
+		if len(endpointSliceExportList.Items) != 1 || endpointSliceExportList.Items[0].Name != aggregatedEndpointSliceExportName {
+			t.Fatalf("got endpointSliceExports %+v, want only %s", endpointSliceExportList.Items, aggregatedEndpointSliceExportName)
+		}
+		return &endpointSliceExportList.Items[0]
+	}
+
+	got := reconcileAndGet()
+	wantEndpoints := []fleetnetv1alpha1.Endpoint{
+		{Addresses: []string{"1.2.3.4"}},
+		{Addresses: []string{"2.3.4.5"}},
+		{Addresses: []string{"3.4.5.6"}},
+	}
+	if diff := cmp.Diff(wantEndpoints, got.Spec.Endpoints); diff != "" {
+		t.Errorf("exported endpoints mismatch (-want, +got):\n%s", diff)
+	}
+	if !got.IsAggregated() || got.Spec.EndpointSliceReference.UID != svc.UID || got.Spec.EndpointSliceReference.Generation != 1 {
+		t.Errorf("endpointSliceReference = %+v, want generation 1 of the service", got.Spec.EndpointSliceReference)
+	}
+
+	// Nothing changes.
+	if got := reconcileAndGet(); got.Spec.EndpointSliceReference.Generation != 1 {
+		t.Errorf("endpointSliceReference generation = %d, want 1", got.Spec.EndpointSliceReference.Generation)
+	}
+
+	// An endpoint is removed.
+	if err := fakeMemberClient.Delete(ctx, serviceEndpointSlice("app-2")); err != nil {
+		t.Fatalf("endpointSlice Delete() = %v, want no error", err)
+	}
+	got = reconcileAndGet()
+	wantEndpoints = []fleetnetv1alpha1.Endpoint{
+		{Addresses: []string{"1.2.3.4"}},
+		{Addresses: []string{"3.4.5.6"}},
+	}
+	if diff := cmp.Diff(wantEndpoints, got.Spec.Endpoints); diff != "" {
+		t.Errorf("exported endpoints mismatch (-want, +got):\n%s", diff)
+	}
+	if got.Spec.EndpointSliceReference.Generation != 2 {
+		t.Errorf("endpointSliceReference generation = %d, want 2", got.Spec.EndpointSliceReference.Generation)
+	}
+}
+
+// TestReconcileService_Unexport tests that all the EndpointSliceExports of a Service are withdrawn when the Service
+// is no longer exported.
+func TestReconcileService_Unexport(t *testing.T) {
+	endpointSliceExportFor := func(svcName string) *fleetnetv1alpha1.EndpointSliceExport {
+		return &fleetnetv1alpha1.EndpointSliceExport{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: hubNSForMember,
+				Name:      "bravelion-work-" + svcName + "-ipv4-0",
+				Labels: map[string]string{
+					objectmeta.EndpointSliceExportLabelOwnerServiceNamespace: memberUserNS,
+					objectmeta.EndpointSliceExportLabelOwnerServiceName:      svcName,
+				},
+			},
+		}
+	}
+	fakeMemberClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(serviceEndpointSlice("app-1", "1.2.3.4")).
+		Build()
+	fakeHubClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(endpointSliceExportFor(svcName), endpointSliceExportFor("db")).
+		Build()
+	reconciler := &Reconciler{
+		Recorder:         record.NewFakeRecorder(10),
+		MemberClusterID:  memberClusterID,
+		MemberClient:     fakeMemberClient,
+		HubClient:        fakeHubClient,
+		HubNamespace:     hubNSForMember,
+		AggregateExports: true,
+	}
+	ctx := context.Background()
+
+	if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: svcKey}); err != nil {
+		t.Fatalf("Reconcile() = %v, want no error", err)
+	}
+
+	endpointSliceExportList := &fleetnetv1alpha1.EndpointSliceExportList{}
+	if err := fakeHubClient.List(ctx, endpointSliceExportList, client.InNamespace(hubNSForMember)); err != nil {
+		t.Fatalf("endpointSliceExport List() = %v, want no error", err)
+	}
+	gotNames := []string{}
+	for _, endpointSliceExport := range endpointSliceExportList.Items {
+		gotNames = append(gotNames, endpointSliceExport.Name)
+	}
+	if diff := cmp.Diff([]string{"bravelion-work-db-ipv4-0"}, gotNames); diff != "" {
+		t.Errorf("endpointSliceExports mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+// TestChunkEndpoints tests the chunkEndpoints function.
+func TestChunkEndpoints(t *testing.T) {
+	endpoints := func(n int) []fleetnetv1alpha1.Endpoint {
+		return make([]fleetnetv1alpha1.Endpoint, n)
+	}
+	testCases := []struct {
+		name           string
+		endpoints      []fleetnetv1alpha1.Endpoint
+		wantChunkSizes []int
+	}{
+		{
+			name:           "no endpoints",
+			endpoints:      endpoints(0),
+			wantChunkSizes: []int{0},
+		},
+		{
+			name:           "one full chunk",
+			endpoints:      endpoints(3),
+			wantChunkSizes: []int{3},
+		},
+		{
+			name:           "several chunks",
+			endpoints:      endpoints(7),
+			wantChunkSizes: []int{3, 3, 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotChunkSizes := []int{}
+			for _, chunk := range chunkEndpoints(tc.endpoints, 3) {
+				gotChunkSizes = append(gotChunkSizes, len(chunk))
+			}
+			if diff := cmp.Diff(tc.wantChunkSizes, gotChunkSizes); diff != "" {
+				t.Errorf("chunkEndpoints() chunk sizes mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
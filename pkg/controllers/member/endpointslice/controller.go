@@ -11,25 +11,41 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"sync"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/apply"
+	"go.goms.io/fleet-networking/pkg/common/exportdrain"
+	"go.goms.io/fleet-networking/pkg/common/fairqueue"
 	"go.goms.io/fleet-networking/pkg/common/metrics"
 	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/pause"
+	"go.goms.io/fleet-networking/pkg/common/tracing"
 	"go.goms.io/fleet-networking/pkg/common/uniquename"
+	"go.goms.io/fleet-networking/pkg/controllers/member/eastwestgateway"
+	"go.goms.io/fleet-networking/pkg/controllers/member/loadbalancerexport"
 )
 
 // skipOrUnexportEndpointSliceOp describes the op the controller should take on an EndpointSlice, specifically
@@ -45,6 +61,15 @@ const (
 	continueReconcileOp skipOrUnexportEndpointSliceOp = 2
 )
 
+const (
+	// ControllerName is the name of the Reconciler.
+	ControllerName = "endpointslice-controller"
+
+	// endpointSliceResyncInterval is the interval at which an exported EndpointSlice is re-reconciled, so that
+	// its EndpointSliceExport in the hub cluster is re-created if it has been deleted out-of-band.
+	endpointSliceResyncInterval = time.Minute * 5
+)
+
 // Reconciler reconciles the export of an EndpointSlice.
 type Reconciler struct {
 	// The ID of the member cluster.
@@ -53,19 +78,67 @@ type Reconciler struct {
 	HubClient       client.Client
 	// The namespace reserved for the current member cluster in the hub cluster.
 	HubNamespace string
+	// NewQueue, if set, constructs the work queue of the controller, e.g. a queue that shares the reconcile
+	// throughput fairly among namespaces; the default work queue is used otherwise.
+	NewQueue fairqueue.NewQueueFunc
+	// ResyncEvents, if set, enqueue the ServiceExports whose EndpointSlices are to be re-published to the hub
+	// cluster at once, e.g. after the hub cluster has lost its state.
+	ResyncEvents <-chan event.GenericEvent
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
+	// EastWestGateway, if set, reads the east-west gateway of the member cluster; the Services with ports assigned
+	// to the gateway are exported with the address of the gateway along with the addresses of their endpoints, and
+	// the hub decides which of them each importing cluster gets.
+	EastWestGateway *eastwestgateway.Reader
+	// Recorder records the Events telling the users when the endpoints of their exported Services are propagated to
+	// the fleet.
+	Recorder record.EventRecorder
+	// DebounceWindow, if positive, is the wait time for the controller to export the endpoints of an EndpointSlice
+	// after it changes; the changes within the window, e.g. while a Deployment rolls out or scales, are exported to
+	// the hub cluster with one write.
+	DebounceWindow time.Duration
+	// AggregateExports, if set, exports the endpoints of all the EndpointSlices of an exported Service with as few
+	// EndpointSliceExports as possible, instead of one for each EndpointSlice, which keeps the number of objects in
+	// the hub cluster down for the Services with many EndpointSlices.
+	AggregateExports bool
+	// ExportOnDemand, if set, only exports the endpoints of the Services which the hub reports, with the Imported
+	// condition of their ServiceExports, to be imported by other member clusters; the endpoints of the Services no
+	// other cluster imports are withdrawn from the hub cluster.
+	ExportOnDemand bool
+	// ServerSideApply, if set, writes the EndpointSliceExports with server-side applies, so that the writes under
+	// heavy endpoint churn never conflict with each other.
+	ServerSideApply bool
+
+	// warmupMu guards readySince.
+	warmupMu sync.Mutex
+	// readySince tracks, for each EndpointSlice (or each address type of a Service, when the exports are
+	// aggregated), the time since which each of its endpoints still warming up has been ready; it is kept in memory only, and the endpoints already advertised stay advertised across restarts.
+	readySince map[types.NamespacedName]map[string]time.Time
 }
 
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=endpointsliceexports,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile exports an EndpointSlice.
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if r.AggregateExports {
+		// The requests are keyed by the Services instead.
+		return r.reconcileService(ctx, req.NamespacedName)
+	}
+
 	endpointSliceRef := klog.KRef(req.Namespace, req.Name)
+	logger := klog.FromContext(ctx).WithValues("endpointSlice", endpointSliceRef)
+	ctx = klog.NewContext(ctx, logger)
 	startTime := time.Now()
-	klog.V(2).InfoS("Reconciliation starts", "endpointSlice", endpointSliceRef)
+	logger.V(2).Info("Reconciliation starts")
 	defer func() {
 		latency := time.Since(startTime).Milliseconds()
-		klog.V(2).InfoS("Reconciliation ends", "endpointSlice", endpointSliceRef, "latency", latency)
+		logger.V(2).Info("Reconciliation ends", "latency", latency)
 	}()
 
 	// Retrieve the EndpointSlice object.
@@ -78,10 +151,11 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		// hub cluster, and it is up to another controller, EndpointSliceExport controller, to pick up the leftover
 		// and clean it out.
 		if errors.IsNotFound(err) {
-			klog.V(4).InfoS("Ignoring NotFound endpointSlice", "endpointSlice", endpointSliceRef)
+			logger.V(4).Info("Ignoring NotFound endpointSlice")
+			r.forgetWarmingUpEndpoints(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
-		klog.ErrorS(err, "Failed to get endpoint slice", "endpointSlice", endpointSliceRef)
+		logger.Error(err, "Failed to get endpoint slice")
 		return ctrl.Result{}, err
 	}
 
@@ -89,22 +163,22 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	skipOrUnexportOp, err := r.shouldSkipOrUnexportEndpointSlice(ctx, &endpointSlice)
 	if err != nil {
 		// An unexpected error occurs.
-		klog.ErrorS(err,
-			"Failed to determine whether an endpoint slice should be skipped for reconciliation or unexported",
-			"endpointSlice", endpointSliceRef)
+		logger.Error(err,
+			"Failed to determine whether an endpoint slice should be skipped for reconciliation or unexported")
 		return ctrl.Result{}, err
 	}
 
 	switch skipOrUnexportOp {
 	case shouldSkipEndpointSliceOp:
 		// Skip reconciling the EndpointSlice.
-		klog.V(4).InfoS("Endpoint slice should be skipped for reconciliation", "endpointSlice", endpointSliceRef)
+		logger.V(4).Info("Endpoint slice should be skipped for reconciliation")
 		return ctrl.Result{}, nil
 	case shouldUnexportEndpointSliceOp:
 		// Unexport the EndpointSlice.
-		klog.V(4).InfoS("Endpoint slice should be unexported", "endpointSlice", endpointSliceRef)
+		logger.V(4).Info("Endpoint slice should be unexported")
+		r.forgetWarmingUpEndpoints(req.NamespacedName)
 		if err := r.unexportEndpointSlice(ctx, &endpointSlice); err != nil {
-			klog.ErrorS(err, "Failed to unexport the endpoint slice", "endpointSlice", endpointSliceRef)
+			logger.Error(err, "Failed to unexport the endpoint slice")
 			return ctrl.Result{}, err
 		}
 		return ctrl.Result{}, nil
@@ -114,13 +188,12 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	// to user tampering with the annotation, assign a new unique name.
 	fleetUniqueName, ok := endpointSlice.Annotations[objectmeta.ExportedObjectAnnotationUniqueName]
 	if !ok || !isUniqueNameValid(fleetUniqueName) {
-		klog.V(2).InfoS("The endpoint slice does not have a unique name assigned or the one assigned is not valid; a new one will be assigned",
-			"endpointSlice", endpointSliceRef)
+		logger.V(2).Info("The endpoint slice does not have a unique name assigned or the one assigned is not valid; a new one will be assigned")
 		var err error
 		// Unique name annotation must be added before an EndpointSlice is exported.
 		fleetUniqueName, err = r.assignUniqueNameAsAnnotation(ctx, &endpointSlice)
 		if err != nil {
-			klog.ErrorS(err, "Failed to assign unique name as an annotation", "endpointSlice", endpointSliceRef)
+			logger.Error(err, "Failed to assign unique name as an annotation")
 			return ctrl.Result{}, err
 		}
 	}
@@ -131,22 +204,82 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	// Note that the two values are not tamperproof.
 	exportedSince, err := r.collectAndVerifyLastSeenGenerationAndTimestamp(ctx, &endpointSlice, startTime)
 	if err != nil {
-		klog.Warning("Failed to annotate last seen generation and timestamp", "endpointSlice", endpointSliceRef)
+		logger.Error(err, "Failed to annotate last seen generation and timestamp")
 	}
 
 	// Create an EndpointSliceExport in the hub cluster if the EndpointSlice has never been exported; otherwise
 	// update the corresponding EndpointSliceExport.
-	extractedEndpoints := extractEndpointsFromEndpointSlice(&endpointSlice)
+	svcExport := &fleetnetv1alpha1.ServiceExport{}
+	svcExportKey := types.NamespacedName{Namespace: endpointSlice.Namespace, Name: endpointSlice.Labels[discoveryv1.LabelServiceName]}
+	logger = logger.WithValues("service", klog.KRef(svcExportKey.Namespace, svcExportKey.Name))
+	ctx = klog.NewContext(ctx, logger)
+	if err := r.MemberClient.Get(ctx, svcExportKey, svcExport); err != nil {
+		logger.Error(err, "Failed to get the service export")
+		return ctrl.Result{}, err
+	}
+	extractedEndpoints, err := r.extractSelectedEndpoints(ctx, &endpointSlice, svcExport)
+	if err != nil {
+		logger.Error(err, "Failed to extract the endpoints selected for export")
+		return ctrl.Result{}, err
+	}
+	if err := r.setEndpointRegions(ctx, extractedEndpoints); err != nil {
+		logger.Error(err, "Failed to look up the regions of the endpoints")
+		return ctrl.Result{}, err
+	}
+	if isServiceExportDraining(svcExport, startTime) {
+		// The importing clusters stop opening new connections to the endpoints, while the in-flight ones complete.
+		extractedEndpoints = exportdrain.Drain(extractedEndpoints)
+	}
+	extractedPorts, err := r.extractSelectedPorts(ctx, &endpointSlice, svcExport)
+	if err != nil {
+		logger.Error(err, "Failed to extract the ports selected for export")
+		return ctrl.Result{}, err
+	}
+	loadBalancerMode := loadbalancerexport.IsLoadBalancerMode(svcExport)
+	if loadBalancerMode {
+		lbSvc, err := r.getLoadBalancerService(ctx, svcExportKey)
+		if err != nil {
+			logger.Error(err, "Failed to get the load balancer service", "service", svcExportKey)
+			return ctrl.Result{}, err
+		}
+		extractedEndpoints, extractedPorts = exportThroughLoadBalancer(lbSvc, &endpointSlice, extractedEndpoints, extractedPorts)
+	}
+	var gatewayEndpoints *fleetnetv1alpha1.GatewayEndpoints
+	// The internal load balancer is reached by the other member clusters directly, instead of through the gateway.
+	if r.EastWestGateway != nil && !loadBalancerMode {
+		gateway, err := r.EastWestGateway.Read(ctx)
+		if err != nil {
+			logger.Error(err, "Failed to read the east-west gateway")
+			return ctrl.Result{}, err
+		}
+		gatewayEndpoints = routeThroughGateway(gateway, &endpointSlice, extractedEndpoints, extractedPorts)
+	}
+	var privateLinkEndpoints *fleetnetv1alpha1.PrivateLinkEndpoints
+	if _, ok := svcExport.Annotations[objectmeta.ServiceExportAnnotationPrivateLinkServiceID]; ok {
+		svc := &corev1.Service{}
+		if err := r.MemberClient.Get(ctx, svcExportKey, svc); err != nil {
+			logger.Error(err, "Failed to get the service", "service", svcExportKey)
+			return ctrl.Result{}, err
+		}
+		privateLinkEndpoints = exposeThroughPrivateLink(svcExport, svc, extractedPorts)
+	}
+	warmup, err := extractEndpointWarmup(svcExport)
+	if err != nil {
+		// The warmup period is specified by the user and retrying will not help; advertise the endpoints as
+		// soon as they are ready, as if no warmup period were specified.
+		logger.Error(err, "Ignoring the endpoint warmup period", "serviceExport", klog.KObj(svcExport))
+	}
+	var nextWarmedUp time.Duration
 	endpointSliceExport := fleetnetv1alpha1.EndpointSliceExport{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: r.HubNamespace,
 			Name:      fleetUniqueName,
 		},
 	}
-	klog.V(2).InfoS("Endpoint slice will be exported",
-		"endpointSlice", endpointSliceRef,
+	logger.V(2).Info("Endpoint slice will be exported",
 		"endpointSliceExport", klog.KObj(&endpointSliceExport))
-	createOrUpdateOp, err := controllerutil.CreateOrUpdate(ctx, r.HubClient, &endpointSliceExport, func() error {
+	createOrUpdateOp, err := r.createOrUpdateFunc()(ctx, r.HubClient, &endpointSliceExport, func() error {
+		oldSpec := endpointSliceExport.Spec.DeepCopy()
 		// Set up an EndpointSliceReference and only when an EndpointSliceExport is first created; this is because
 		// most fields in EndpointSliceReference should be immutable after creation.
 		if endpointSliceExport.CreationTimestamp.IsZero() {
@@ -165,9 +298,23 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			)
 		}
 
-		endpointSliceExport.Spec.AddressType = discoveryv1.AddressTypeIPv4
-		endpointSliceExport.Spec.Endpoints = extractedEndpoints
-		endpointSliceExport.Spec.Ports = endpointSlice.Ports
+		// Label the EndpointSliceExport with its owner Service so that the ServiceExport controller can find
+		// all the EndpointSliceExports derived from a Service and clean up the ones whose source EndpointSlice
+		// is gone.
+		if endpointSliceExport.Labels == nil {
+			endpointSliceExport.Labels = map[string]string{}
+		}
+		endpointSliceExport.Labels[objectmeta.EndpointSliceExportLabelOwnerServiceNamespace] = endpointSlice.Namespace
+		endpointSliceExport.Labels[objectmeta.EndpointSliceExportLabelOwnerServiceName] = endpointSlice.Labels[discoveryv1.LabelServiceName]
+
+		endpointSliceExport.Spec.AddressType = endpointSlice.AddressType
+		// Withhold the newly ready endpoints until they complete their warmup; the endpoints already advertised
+		// are read from the existing EndpointSliceExport.
+		endpointSliceExport.Spec.Endpoints, nextWarmedUp = r.withholdWarmingUpEndpoints(req.NamespacedName,
+			extractedEndpoints, endpointSliceExport.Spec.Endpoints, warmup)
+		endpointSliceExport.Spec.Ports = extractedPorts
+		endpointSliceExport.Spec.Gateway = gatewayEndpoints
+		endpointSliceExport.Spec.PrivateLink = privateLinkEndpoints
 		endpointSliceExport.Spec.OwnerServiceReference = fleetnetv1alpha1.OwnerServiceReference{
 			// The owner Service is guaranteed to reside in the same namespace as the EndpointSlice to export.
 			Namespace:      endpointSlice.Namespace,
@@ -176,38 +323,112 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		}
 
 		endpointSliceExport.Spec.EndpointSliceReference.UpdateFromMetaObject(endpointSlice.ObjectMeta, metav1.NewTime(exportedSince))
+		// Only a new version of the export carries the correlation ID of this reconciliation.
+		if !equality.Semantic.DeepEqual(oldSpec, &endpointSliceExport.Spec) {
+			endpointSliceExport.Annotations = tracing.SetCorrelationID(ctx, endpointSliceExport.Annotations)
+		}
 		return nil
 	})
 	switch {
 	case errors.IsAlreadyExists(err):
 		// Remove the unique name annotation; a new one will be assigned in future reciliation attempts.
-		klog.V(2).InfoS("The unique name assigned to the endpoint slice has been used; it will be removed", "endpointSlice", endpointSliceRef)
+		logger.V(2).Info("The unique name assigned to the endpoint slice has been used; it will be removed")
 		delete(endpointSlice.Annotations, objectmeta.ExportedObjectAnnotationUniqueName)
 		if err := r.MemberClient.Update(ctx, &endpointSlice); err != nil {
-			klog.ErrorS(err, "Failed to remove endpointslice unique name annotation", "endpointSlice", endpointSliceRef)
+			logger.Error(err, "Failed to remove endpointslice unique name annotation")
 			return ctrl.Result{}, err
 		}
 		return ctrl.Result{}, nil
 	case err != nil:
-		klog.ErrorS(err,
+		logger.Error(err,
 			"Failed to create/update endpointslice export",
-			"endpointSlice", endpointSliceRef,
 			"endpointSliceExport", klog.KObj(&endpointSliceExport),
 			"op", createOrUpdateOp)
 		return ctrl.Result{}, err
 	}
+	if createOrUpdateOp != controllerutil.OperationResultNone {
+		r.Recorder.Eventf(svcExport, corev1.EventTypeNormal, "EndpointsPropagated",
+			"Propagated %d endpoints of EndpointSlice %s to the fleet", len(endpointSliceExport.Spec.Endpoints), endpointSlice.Name)
+	}
 
-	return ctrl.Result{}, nil
+	// Periodically re-scan exported EndpointSlices; the controller is not notified when the EndpointSliceExport
+	// is deleted from the hub cluster out-of-band, and the CreateOrUpdate call above is what restores it.
+	// Re-scan earlier if an endpoint is due to complete its warmup before then.
+	requeueAfter := endpointSliceResyncInterval
+	if nextWarmedUp > 0 && nextWarmedUp < requeueAfter {
+		requeueAfter = nextWarmedUp
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// withholdWarmingUpEndpoints returns the serving endpoints of an EndpointSlice to advertise, i.e. the ones already
+// advertised and the ones which have completed their warmup, and how long until the next endpoint still warming up
+// completes its warmup (0 if none).
+func (r *Reconciler) withholdWarmingUpEndpoints(key types.NamespacedName,
+	serving, advertised []fleetnetv1alpha1.Endpoint, warmup time.Duration) ([]fleetnetv1alpha1.Endpoint, time.Duration) {
+	if warmup <= 0 {
+		r.forgetWarmingUpEndpoints(key)
+		return serving, 0
+	}
+
+	r.warmupMu.Lock()
+	defer r.warmupMu.Unlock()
+	kept, warmingUp, nextWarmedUp := filterWarmedUpEndpoints(serving, advertised, r.readySince[key], warmup, time.Now())
+	if len(warmingUp) == 0 {
+		delete(r.readySince, key)
+		return kept, 0
+	}
+	if r.readySince == nil {
+		r.readySince = map[types.NamespacedName]map[string]time.Time{}
+	}
+	r.readySince[key] = warmingUp
+	return kept, nextWarmedUp
+}
+
+// forgetWarmingUpEndpoints drops the warmup progress of the endpoints of an EndpointSlice.
+func (r *Reconciler) forgetWarmingUpEndpoints(key types.NamespacedName) {
+	r.warmupMu.Lock()
+	defer r.warmupMu.Unlock()
+	delete(r.readySince, key)
+}
+
+// getLoadBalancerService returns the internal LoadBalancer Service an exported Service is exported through in the
+// LoadBalancer connectivity mode, i.e. the Service itself if it is an internal LoadBalancer Service, or the one
+// created for it by the loadbalancerexport controller; an empty Service is returned if the latter is not created
+// yet.
+func (r *Reconciler) getLoadBalancerService(ctx context.Context, svcKey types.NamespacedName) (*corev1.Service, error) {
+	svc := &corev1.Service{}
+	if err := r.MemberClient.Get(ctx, svcKey, svc); err != nil {
+		return nil, err
+	}
+	if loadbalancerexport.IsInternalLoadBalancer(svc) {
+		return svc, nil
+	}
+	lbSvc := &corev1.Service{}
+	lbSvcKey := types.NamespacedName{Namespace: svcKey.Namespace, Name: loadbalancerexport.ServiceName(svcKey.Name)}
+	if err := r.MemberClient.Get(ctx, lbSvcKey, lbSvc); err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+	return lbSvc, nil
 }
 
 // SetupWithManager sets up the EndpointSlice controller with a controller manager.
 func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
-	// Enqueue EndpointSlices for processing when a ServiceExport changes.
+	if r.AggregateExports {
+		return r.setupAggregatedWithManager(mgr)
+	}
+
+	// Enqueue EndpointSlices for processing when a ServiceExport or a Service changes; a load balancer service
+	// created for an exported Service enqueues the EndpointSlices of the exported Service.
 	eventHandlers := handler.EnqueueRequestsFromMapFunc(func(_ context.Context, o client.Object) []reconcile.Request {
+		svcName := o.GetName()
+		if name, ok := o.GetLabels()[objectmeta.ServiceLabelLoadBalancerFor]; ok {
+			svcName = name
+		}
 		endpointSliceList := &discoveryv1.EndpointSliceList{}
 		listOpts := client.ListOptions{
 			LabelSelector: labels.SelectorFromSet(labels.Set{
-				discoveryv1.LabelServiceName: o.GetName(),
+				discoveryv1.LabelServiceName: svcName,
 			}),
 			Namespace: o.GetNamespace(),
 		}
@@ -227,11 +448,91 @@ func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) err
 		return reqs
 	})
 
-	// EndpointSlice controller watches over EndpointSlice and ServiceExport objects.
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&discoveryv1.EndpointSlice{}).
+	// EndpointSlice controller watches over EndpointSlice, ServiceExport, and Service objects.
+	builder := ctrl.NewControllerManagedBy(mgr).
+		Named("endpointslice").
+		Watches(&discoveryv1.EndpointSlice{}, r.debouncedEventHandler(func(o client.Object) (reconcile.Request, bool) {
+			return reconcile.Request{NamespacedName: client.ObjectKeyFromObject(o)}, true
+		})).
 		Watches(&fleetnetv1alpha1.ServiceExport{}, eventHandlers).
-		Complete(r)
+		Watches(&corev1.Service{}, eventHandlers)
+	if r.EastWestGateway != nil {
+		// Enqueue the EndpointSlices of the Services routed through the gateway when its address or its ports
+		// change.
+		gatewayEventHandlers := handler.EnqueueRequestsFromMapFunc(r.enqueueGatewayRoutedEndpointSlices)
+		builder = builder.
+			Watches(&corev1.ConfigMap{}, gatewayEventHandlers).
+			Watches(&corev1.Service{}, gatewayEventHandlers)
+	}
+	if r.ResyncEvents != nil {
+		builder = builder.WatchesRawSource(source.Channel(r.ResyncEvents, eventHandlers))
+	}
+	opts := r.ControllerOptions
+	opts.NewQueue = r.NewQueue
+	return builder.WithOptions(opts).Complete(metrics.InstrumentReconciler("endpointslice", r))
+}
+
+// debouncedEventHandler returns the event handler which enqueues the request an object maps to after the debounce
+// window, if any; the work queue de-duplicates the requests added during the window, so that a burst of changes is
+// handled only once.
+func (r *Reconciler) debouncedEventHandler(toRequest func(o client.Object) (reconcile.Request, bool)) handler.EventHandler {
+	enqueue := func(q workqueue.TypedRateLimitingInterface[reconcile.Request], o client.Object) {
+		req, ok := toRequest(o)
+		if !ok {
+			return
+		}
+		if r.DebounceWindow <= 0 {
+			q.Add(req)
+			return
+		}
+		q.AddAfter(req, r.DebounceWindow)
+	}
+	return handler.Funcs{
+		CreateFunc: func(_ context.Context, e event.CreateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(q, e.Object)
+		},
+		UpdateFunc: func(_ context.Context, e event.UpdateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(q, e.ObjectNew)
+		},
+		DeleteFunc: func(_ context.Context, e event.DeleteEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(q, e.Object)
+		},
+		GenericFunc: func(_ context.Context, e event.GenericEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(q, e.Object)
+		},
+	}
+}
+
+// enqueueGatewayRoutedEndpointSlices enqueues the EndpointSlices of the Services routed through the east-west
+// gateway, when the ConfigMap or the Service of the gateway changes.
+func (r *Reconciler) enqueueGatewayRoutedEndpointSlices(ctx context.Context, o client.Object) []reconcile.Request {
+	gw := r.EastWestGateway
+	if o.GetNamespace() != gw.Namespace || (o.GetName() != gw.ConfigMapName && o.GetName() != gw.ServiceName) {
+		return []reconcile.Request{}
+	}
+	gateway, err := gw.Read(ctx)
+	if err != nil {
+		klog.ErrorS(err, "Failed to read the east-west gateway")
+		return []reconcile.Request{}
+	}
+	reqs := []reconcile.Request{}
+	for _, svc := range gateway.Services() {
+		endpointSliceList := &discoveryv1.EndpointSliceList{}
+		listOpts := client.ListOptions{
+			LabelSelector: labels.SelectorFromSet(labels.Set{discoveryv1.LabelServiceName: svc.Name}),
+			Namespace:     svc.Namespace,
+		}
+		if err := r.MemberClient.List(ctx, endpointSliceList, &listOpts); err != nil {
+			klog.ErrorS(err, "Failed to list endpoint slices in use by a service", "service", svc)
+			continue
+		}
+		for _, endpointSlice := range endpointSliceList.Items {
+			reqs = append(reqs, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: endpointSlice.Namespace, Name: endpointSlice.Name},
+			})
+		}
+	}
+	return reqs
 }
 
 // shouldSkipOrUnexportEndpointSlice returns the op the controller should take on an EndpointSlice, specifically
@@ -239,16 +540,19 @@ func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) err
 //
 // The controller can only export an EndpointSlice if
 // * the EndpointSlice is in use by a Service that has been successfully exported (valid with no conflicts); and
+// * the Service is not being deleted; and
 // * the EndpointSlice has not been deleted.
 //
 // If an EndpointSlice has been exported before, but
 // * its owner Service has not been, or is no longer, exported; or
+// * its owner Service is being deleted; or
 // * the EndpointSlice itself has been deleted
 // the EndpointSlice should be unexported.
 //
 // EndpointSlices that are
 // * not exportable; or
-// * not owned by a successfully exported Service
+// * not owned by a successfully exported Service; or
+// * owned by a Service whose ServiceExport is paused
 // should never be reconciled with this controller.
 func (r *Reconciler) shouldSkipOrUnexportEndpointSlice(ctx context.Context,
 	endpointSlice *discoveryv1.EndpointSlice) (skipOrUnexportEndpointSliceOp, error) {
@@ -292,8 +596,15 @@ func (r *Reconciler) shouldSkipOrUnexportEndpointSlice(ctx context.Context,
 		return continueReconcileOp, err
 	}
 
-	// Check if the ServiceExport is valid with no conflicts.
-	if !isServiceExportValidWithNoConflict(svcExport) {
+	// Leave the EndpointSlice, exported or not, as it is while the reconciliation of the ServiceExport is paused.
+	if pause.IsPaused(svcExport) {
+		return shouldSkipEndpointSliceOp, nil
+	}
+
+	// Check if the ServiceExport is valid with no conflicts; the EndpointSlices exported before the ServiceExport is
+	// deleted stay exported while their endpoints are drained.
+	draining := isServiceExportDraining(svcExport, time.Now())
+	if !isServiceExportValidWithNoConflict(svcExport) && !(draining && hasUniqueNameAnnotation) {
 		if hasUniqueNameAnnotation {
 			// The Service using the EndpointSlice is not valid for export or has conflicts with other exported
 			// Services, but the EndpointSlice has a unique name annotation present (i.e. it might have been
@@ -306,6 +617,39 @@ func (r *Reconciler) shouldSkipOrUnexportEndpointSlice(ctx context.Context,
 		return shouldSkipEndpointSliceOp, nil
 	}
 
+	// Check if the Service is in demand, if only the endpoints of the imported Services are exported.
+	if r.ExportOnDemand && !isServiceExportImported(svcExport) {
+		if hasUniqueNameAnnotation {
+			// No other member cluster imports the Service using the EndpointSlice, but the EndpointSlice has a
+			// unique name annotation present (i.e. it might have been exported before); the EndpointSlice should be
+			// unexported.
+			return shouldUnexportEndpointSliceOp, nil
+		}
+		// No other member cluster imports the Service using the EndpointSlice, and the EndpointSlice has not been
+		// exported before; the EndpointSlice should be skipped until the Service is imported.
+		return shouldSkipEndpointSliceOp, nil
+	}
+
+	// Check if the Service using the EndpointSlice is being deleted; its endpoints may linger for a short while
+	// after the Service is marked for deletion, and exporting them would only cause a flap on the fleet, as
+	// the ServiceExport controller is about to withdraw the Service as well.
+	svc := &corev1.Service{}
+	err = r.MemberClient.Get(ctx, types.NamespacedName{Namespace: endpointSlice.Namespace, Name: svcName}, svc)
+	switch {
+	case err != nil && !errors.IsNotFound(err):
+		// An unexpected error has occurred.
+		return continueReconcileOp, err
+	case err == nil && svc.DeletionTimestamp != nil:
+		if hasUniqueNameAnnotation {
+			// The Service using the EndpointSlice is being deleted, and the EndpointSlice has a unique name
+			// annotation present (i.e. it might have been exported); the EndpointSlice should be unexported.
+			return shouldUnexportEndpointSliceOp, nil
+		}
+		// The Service using the EndpointSlice is being deleted, and the EndpointSlice has no unique name
+		// annotation present (i.e. it has not been exported before); the EndpointSlice should be skipped.
+		return shouldSkipEndpointSliceOp, nil
+	}
+
 	if endpointSlice.DeletionTimestamp != nil {
 		if hasUniqueNameAnnotation {
 			// The Service using the EndpointSlice is exported with no conflicts, and the EndpointSlice has a unique
@@ -324,6 +668,119 @@ func (r *Reconciler) shouldSkipOrUnexportEndpointSlice(ctx context.Context,
 	return continueReconcileOp, nil
 }
 
+// extractSelectedEndpoints extracts the endpoints to export from an EndpointSlice; if the ServiceExport of the
+// owner Service has an endpoint selector, only the endpoints backed by Pods whose labels match the selector
+// are kept.
+//
+// Pod labels are read as metadata only, so that the Pods are cached with minimal footprint; note that a change
+// of Pod labels alone does not trigger a reconciliation, and is picked up at the next resync.
+func (r *Reconciler) extractSelectedEndpoints(ctx context.Context, endpointSlice *discoveryv1.EndpointSlice,
+	svcExport *fleetnetv1alpha1.ServiceExport) ([]fleetnetv1alpha1.Endpoint, error) {
+	if svcExport.Spec.EndpointSelector == nil {
+		return extractEndpointsFromEndpointSlice(endpointSlice), nil
+	}
+
+	selected := endpointSlice.DeepCopy()
+	selected.Endpoints = []discoveryv1.Endpoint{}
+	selector, err := metav1.LabelSelectorAsSelector(svcExport.Spec.EndpointSelector)
+	if err != nil {
+		// The selector is specified by the user and retrying will not help; export no endpoints rather than
+		// exposing more of the Service than intended.
+		klog.ErrorS(err, "Invalid endpoint selector; no endpoints will be exported",
+			"serviceExport", klog.KObj(svcExport), "endpointSlice", klog.KObj(endpointSlice))
+		return extractEndpointsFromEndpointSlice(selected), nil
+	}
+	for _, endpoint := range endpointSlice.Endpoints {
+		if endpoint.TargetRef == nil || endpoint.TargetRef.Kind != "Pod" {
+			continue
+		}
+		podNamespace := endpoint.TargetRef.Namespace
+		if podNamespace == "" {
+			podNamespace = endpointSlice.Namespace
+		}
+		pod := &metav1.PartialObjectMetadata{}
+		pod.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Pod"))
+		err := r.MemberClient.Get(ctx, types.NamespacedName{Namespace: podNamespace, Name: endpoint.TargetRef.Name}, pod)
+		switch {
+		case errors.IsNotFound(err):
+			// The Pod is gone; its endpoint will soon be removed from the EndpointSlice as well.
+			continue
+		case err != nil:
+			return nil, err
+		}
+		if selector.Matches(labels.Set(pod.Labels)) {
+			selected.Endpoints = append(selected.Endpoints, endpoint)
+		}
+	}
+	return extractEndpointsFromEndpointSlice(selected), nil
+}
+
+// setEndpointRegions sets the region of each endpoint to the region labeled on the Node hosting it, so that the
+// importing clusters can tell the endpoints in their own region apart; EndpointSlices carry the zones of the
+// endpoints only.
+//
+// Node labels are read as metadata only, as with Pod labels.
+func (r *Reconciler) setEndpointRegions(ctx context.Context, endpoints []fleetnetv1alpha1.Endpoint) error {
+	regions := map[string]string{}
+	for i := range endpoints {
+		nodeName := ptr.Deref(endpoints[i].NodeName, "")
+		if nodeName == "" {
+			continue
+		}
+		region, ok := regions[nodeName]
+		if !ok {
+			node := &metav1.PartialObjectMetadata{}
+			node.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Node"))
+			err := r.MemberClient.Get(ctx, types.NamespacedName{Name: nodeName}, node)
+			switch {
+			case errors.IsNotFound(err):
+				// The Node is gone; its endpoints will soon be removed from the EndpointSlice as well.
+			case err != nil:
+				return err
+			default:
+				region = node.Labels[corev1.LabelTopologyRegion]
+			}
+			regions[nodeName] = region
+		}
+		if region != "" {
+			endpoints[i].Region = ptr.To(region)
+		}
+	}
+	return nil
+}
+
+// extractSelectedPorts extracts the ports to export from an EndpointSlice; if the ServiceExport of the owner
+// Service selects the ports to export, only the ports of the EndpointSlice named after the selected Service ports
+// are kept, as the port numbers in an EndpointSlice are the target ports rather than the Service ports.
+func (r *Reconciler) extractSelectedPorts(ctx context.Context, endpointSlice *discoveryv1.EndpointSlice,
+	svcExport *fleetnetv1alpha1.ServiceExport) ([]discoveryv1.EndpointPort, error) {
+	if len(svcExport.Spec.Ports) == 0 {
+		return endpointSlice.Ports, nil
+	}
+
+	svc := &corev1.Service{}
+	if err := r.MemberClient.Get(ctx, types.NamespacedName{Namespace: svcExport.Namespace, Name: svcExport.Name}, svc); err != nil {
+		return nil, err
+	}
+	selectedNames := make(map[string]bool, len(svc.Spec.Ports))
+	for _, svcPort := range svc.Spec.Ports {
+		if svcExport.Spec.SelectsPort(svcPort) {
+			selectedNames[svcPort.Name] = true
+		}
+	}
+	ports := []discoveryv1.EndpointPort{}
+	for _, port := range endpointSlice.Ports {
+		name := ""
+		if port.Name != nil {
+			name = *port.Name
+		}
+		if selectedNames[name] {
+			ports = append(ports, port)
+		}
+	}
+	return ports, nil
+}
+
 // unexportEndpointSlice unexports an EndpointSlice by deleting its corresponding EndpointSliceExport.
 func (r *Reconciler) unexportEndpointSlice(ctx context.Context, endpointSlice *discoveryv1.EndpointSlice) error {
 	// Remove the EndpointSliceExport.
@@ -439,3 +896,17 @@ func (r *Reconciler) annotateLastSeenGenerationAndTimestamp(ctx context.Context,
 	endpointSlice.Annotations[metrics.MetricsAnnotationLastSeenTimestamp] = startTime.Format(metrics.MetricsLastSeenTimestampFormat)
 	return r.MemberClient.Update(ctx, endpointSlice)
 }
+
+// createOrUpdateFunc returns the function which writes the EndpointSliceExports to the hub cluster.
+func (r *Reconciler) createOrUpdateFunc() apply.CreateOrUpdateFunc {
+	if !r.ServerSideApply {
+		return controllerutil.CreateOrUpdate
+	}
+	return apply.CreateOrApply(apply.Owned{
+		Labels: []string{
+			objectmeta.EndpointSliceExportLabelOwnerServiceNamespace,
+			objectmeta.EndpointSliceExportLabelOwnerServiceName,
+		},
+		Annotations: []string{objectmeta.ExportedObjectAnnotationCorrelationID},
+	})
+}
@@ -28,7 +28,7 @@ import (
 const (
 	ipv4Addr             = "1.2.3.4"
 	altIPv4Addr          = "2.3.4.5"
-	ipv6Addr             = "2001:db8:1::ab9:C0A8:102"
+	fqdnAddr             = "app.example.com"
 	altEndpointSliceName = "app-endpointslice-2"
 
 	eventuallyTimeout    = time.Second * 10
@@ -128,7 +128,7 @@ func notYetFulfilledServiceExport() *fleetnetv1alpha1.ServiceExport {
 }
 
 var _ = Describe("endpointslice controller (skip endpointslice)", Serial, Ordered, func() {
-	Context("IPv6 endpointSlice", func() {
+	Context("FQDN endpointSlice", func() {
 		var (
 			endpointSlice *discoveryv1.EndpointSlice
 			svcExport     *fleetnetv1alpha1.ServiceExport
@@ -143,10 +143,10 @@ var _ = Describe("endpointslice controller (skip endpointslice)", Serial, Ordere
 						discoveryv1.LabelServiceName: svcName,
 					},
 				},
-				AddressType: discoveryv1.AddressTypeIPv6,
+				AddressType: discoveryv1.AddressTypeFQDN,
 				Endpoints: []discoveryv1.Endpoint{
 					{
-						Addresses: []string{ipv6Addr},
+						Addresses: []string{fqdnAddr},
 					},
 				},
 				Ports: []discoveryv1.EndpointPort{
@@ -178,7 +178,7 @@ var _ = Describe("endpointslice controller (skip endpointslice)", Serial, Ordere
 			Eventually(serviceExportIsAbsentActual, eventuallyTimeout, eventuallyInterval).Should(BeNil())
 		})
 
-		It("should not export ipv6 endpointslice", func() {
+		It("should not export fqdn endpointslice", func() {
 			// Wait until the state stablizes to run consistently check; this helps make the test less flaky.
 			Eventually(endpointSliceUniqueNameIsNotAssignedActual, eventuallyTimeout, eventuallyInterval).Should(BeNil())
 			Consistently(endpointSliceUniqueNameIsNotAssignedActual, consistentlyDuration, consistentlyInterval).Should(BeNil())
@@ -15,17 +15,28 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
 	discoveryv1 "k8s.io/api/discovery/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 	"go.goms.io/fleet-networking/pkg/common/metrics"
 	"go.goms.io/fleet-networking/pkg/common/objectmeta"
 	"go.goms.io/fleet-networking/pkg/common/uniquename"
+	"go.goms.io/fleet-networking/pkg/controllers/member/eastwestgateway"
+	"go.goms.io/fleet-networking/pkg/controllers/member/loadbalancerexport"
 )
 
 const (
@@ -122,7 +133,7 @@ func TestIsEndpointSlicePermanentlyUnexportable(t *testing.T) {
 			want: false,
 		},
 		{
-			name: "should not be exportable (IPv6 endpointslice)",
+			name: "should be exportable (IPv6 endpointslice)",
 			endpointSlice: &discoveryv1.EndpointSlice{
 				ObjectMeta: metav1.ObjectMeta{
 					Namespace: memberUserNS,
@@ -130,6 +141,17 @@ func TestIsEndpointSlicePermanentlyUnexportable(t *testing.T) {
 				},
 				AddressType: discoveryv1.AddressTypeIPv6,
 			},
+			want: false,
+		},
+		{
+			name: "should not be exportable (FQDN endpointslice)",
+			endpointSlice: &discoveryv1.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: memberUserNS,
+					Name:      endpointSliceName,
+				},
+				AddressType: discoveryv1.AddressTypeFQDN,
+			},
 			want: true,
 		},
 	}
@@ -150,6 +172,13 @@ func TestExtractEndpointsFromEndpointSlice(t *testing.T) {
 	readyAddress := "1.2.3.4"
 	unknownStateAddress := "2.3.4.5"
 	notReadyAddress := "3.4.5.6"
+	terminatingAddress := "4.5.6.7"
+	terminatedAddress := "5.6.7.8"
+	terminatingConditions := discoveryv1.EndpointConditions{
+		Ready:       &isNotReady,
+		Serving:     &isReady,
+		Terminating: &isReady,
+	}
 
 	testCases := []struct {
 		name              string
@@ -157,7 +186,7 @@ func TestExtractEndpointsFromEndpointSlice(t *testing.T) {
 		expectedEndpoints []fleetnetv1alpha1.Endpoint
 	}{
 		{
-			name: "should extract ready endpoints only",
+			name: "should extract ready endpoints with their conditions",
 			endpointSlice: &discoveryv1.EndpointSlice{
 				ObjectMeta: metav1.ObjectMeta{
 					Namespace: memberUserNS,
@@ -185,12 +214,94 @@ func TestExtractEndpointsFromEndpointSlice(t *testing.T) {
 			expectedEndpoints: []fleetnetv1alpha1.Endpoint{
 				{
 					Addresses: []string{readyAddress},
+					Conditions: discoveryv1.EndpointConditions{
+						Ready: &isReady,
+					},
 				},
 				{
 					Addresses: []string{unknownStateAddress},
 				},
 			},
 		},
+		{
+			name: "should extract terminating endpoints which are still serving",
+			endpointSlice: &discoveryv1.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: memberUserNS,
+					Name:      endpointSliceName,
+				},
+				Endpoints: []discoveryv1.Endpoint{
+					{
+						Addresses:  []string{terminatingAddress},
+						Conditions: terminatingConditions,
+					},
+					{
+						Addresses: []string{terminatedAddress},
+						Conditions: discoveryv1.EndpointConditions{
+							Ready:       &isNotReady,
+							Serving:     &isNotReady,
+							Terminating: &isReady,
+						},
+					},
+				},
+			},
+			expectedEndpoints: []fleetnetv1alpha1.Endpoint{
+				{
+					Addresses:  []string{terminatingAddress},
+					Conditions: terminatingConditions,
+				},
+			},
+		},
+		{
+			name: "should keep the topology of endpoints",
+			endpointSlice: &discoveryv1.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: memberUserNS,
+					Name:      endpointSliceName,
+				},
+				Endpoints: []discoveryv1.Endpoint{
+					{
+						Addresses: []string{readyAddress},
+						NodeName:  ptr.To("node-1"),
+						Zone:      ptr.To("zone-1"),
+						Hints: &discoveryv1.EndpointHints{
+							ForZones: []discoveryv1.ForZone{{Name: "zone-1"}},
+						},
+					},
+				},
+			},
+			expectedEndpoints: []fleetnetv1alpha1.Endpoint{
+				{
+					Addresses: []string{readyAddress},
+					NodeName:  ptr.To("node-1"),
+					Zone:      ptr.To("zone-1"),
+					Hints: &discoveryv1.EndpointHints{
+						ForZones: []discoveryv1.ForZone{{Name: "zone-1"}},
+					},
+				},
+			},
+		},
+		{
+			name: "should keep the hostnames of endpoints",
+			endpointSlice: &discoveryv1.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: memberUserNS,
+					Name:      endpointSliceName,
+				},
+				Endpoints: []discoveryv1.Endpoint{
+					{
+						Addresses: []string{readyAddress},
+						Hostname:  ptr.To("web-0"),
+					},
+				},
+			},
+			expectedEndpoints: []fleetnetv1alpha1.Endpoint{
+				{
+					Addresses: []string{readyAddress},
+					Hostname:  ptr.To("web-0"),
+				},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -269,6 +380,7 @@ func TestUnexportLinkedEndpointSlice(t *testing.T) {
 			}
 			fakeHubClient := fakeHubClientBuilder.Build()
 			reconciler := &Reconciler{
+				Recorder:     record.NewFakeRecorder(10),
 				MemberClient: fakeMemberClient,
 				HubClient:    fakeHubClient,
 				HubNamespace: hubNSForMember,
@@ -349,6 +461,7 @@ func TestUnexportUnlinkedEndpointSlice(t *testing.T) {
 				WithScheme(scheme.Scheme).
 				Build()
 			reconciler := &Reconciler{
+				Recorder:     record.NewFakeRecorder(10),
 				MemberClient: fakeMemberClient,
 				HubClient:    fakeHubClient,
 				HubNamespace: hubNSForMember,
@@ -401,6 +514,7 @@ func TestAssignUniqueNameAsAnnotation(t *testing.T) {
 				Build()
 			fakeHubClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
 			reconciler := &Reconciler{
+				Recorder:        record.NewFakeRecorder(10),
 				MemberClusterID: memberClusterID,
 				MemberClient:    fakeMemberClient,
 				HubClient:       fakeHubClient,
@@ -440,7 +554,7 @@ func TestShouldSkipOrUnexportEndpointSlice_NoServiceExport(t *testing.T) {
 					Namespace: memberUserNS,
 					Name:      endpointSliceName,
 				},
-				AddressType: discoveryv1.AddressTypeIPv6,
+				AddressType: discoveryv1.AddressTypeFQDN,
 			},
 			want: shouldSkipEndpointSliceOp,
 		},
@@ -511,6 +625,7 @@ func TestShouldSkipOrUnexportEndpointSlice_NoServiceExport(t *testing.T) {
 				Build()
 			fakeHubClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
 			reconciler := &Reconciler{
+				Recorder:     record.NewFakeRecorder(10),
 				MemberClient: fakeMemberClient,
 				HubClient:    fakeHubClient,
 				HubNamespace: hubNSForMember,
@@ -725,6 +840,7 @@ func TestShouldSkipOrUnexportEndpointSlice_InvalidOrConflictedServiceExport(t *t
 				Build()
 			fakeHubClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
 			reconciler := &Reconciler{
+				Recorder:     record.NewFakeRecorder(10),
 				MemberClient: fakeMemberClient,
 				HubClient:    fakeHubClient,
 				HubNamespace: hubNSForMember,
@@ -849,6 +965,264 @@ func TestShouldSkipOrUnexportEndpointSlice_ExportedService(t *testing.T) {
 				Build()
 			fakeHubClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
 			reconciler := &Reconciler{
+				Recorder:     record.NewFakeRecorder(10),
+				MemberClient: fakeMemberClient,
+				HubClient:    fakeHubClient,
+				HubNamespace: hubNSForMember,
+			}
+
+			op, err := reconciler.shouldSkipOrUnexportEndpointSlice(ctx, tc.endpointSlice)
+			if err != nil {
+				t.Fatalf("shouldSkipOrUnexportEndpointSlice(%+v), got %v, want no error", tc.endpointSlice, err)
+			}
+			if op != tc.want {
+				t.Fatalf("shouldSkipOrUnexportEndpointSlice(%+v) = %d, want %d", tc.endpointSlice, op, tc.want)
+			}
+		})
+	}
+}
+
+// TestShouldSkipOrUnexportEndpointSlice_ExportOnDemand tests the *Reconciler.shouldSkipOrUnexportEndpointSlice method
+// when only the endpoints of the Services imported by other member clusters are exported.
+func TestShouldSkipOrUnexportEndpointSlice_ExportOnDemand(t *testing.T) {
+	importedCondition := func(status metav1.ConditionStatus) metav1.Condition {
+		return metav1.Condition{
+			Type:   string(fleetnetv1alpha1.ServiceExportImported),
+			Status: status,
+			Reason: "Test",
+		}
+	}
+	testCases := []struct {
+		name                    string
+		importedConds           []metav1.Condition
+		hasUniqueNameAnnotation bool
+		want                    skipOrUnexportEndpointSliceOp
+	}{
+		{
+			name:          "should export endpoint slice (imported)",
+			importedConds: []metav1.Condition{importedCondition(metav1.ConditionTrue)},
+			want:          continueReconcileOp,
+		},
+		{
+			name:          "should skip endpoint slice (not imported)",
+			importedConds: []metav1.Condition{importedCondition(metav1.ConditionFalse)},
+			want:          shouldSkipEndpointSliceOp,
+		},
+		{
+			name:                    "should unexport endpoint slice (no longer imported)",
+			importedConds:           []metav1.Condition{importedCondition(metav1.ConditionFalse)},
+			hasUniqueNameAnnotation: true,
+			want:                    shouldUnexportEndpointSliceOp,
+		},
+		{
+			name:                    "should unexport endpoint slice (no demand reported)",
+			hasUniqueNameAnnotation: true,
+			want:                    shouldUnexportEndpointSliceOp,
+		},
+	}
+
+	ctx := context.Background()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svcExport := &fleetnetv1alpha1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: memberUserNS,
+					Name:      svcName,
+				},
+				Status: fleetnetv1alpha1.ServiceExportStatus{
+					Conditions: append([]metav1.Condition{
+						serviceExportValidCondition(memberUserNS, svcName),
+						serviceExportNoConflictCondition(memberUserNS, svcName),
+					}, tc.importedConds...),
+				},
+			}
+			endpointSlice := &discoveryv1.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: memberUserNS,
+					Name:      endpointSliceName,
+					Labels: map[string]string{
+						discoveryv1.LabelServiceName: svcName,
+					},
+				},
+				AddressType: discoveryv1.AddressTypeIPv4,
+			}
+			if tc.hasUniqueNameAnnotation {
+				endpointSlice.Annotations = map[string]string{
+					objectmeta.ExportedObjectAnnotationUniqueName: endpointSliceUniqueName,
+				}
+			}
+			fakeMemberClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(endpointSlice, svcExport).
+				Build()
+			reconciler := &Reconciler{
+				Recorder:       record.NewFakeRecorder(10),
+				MemberClient:   fakeMemberClient,
+				HubClient:      fake.NewClientBuilder().WithScheme(scheme.Scheme).Build(),
+				HubNamespace:   hubNSForMember,
+				ExportOnDemand: true,
+			}
+
+			op, err := reconciler.shouldSkipOrUnexportEndpointSlice(ctx, endpointSlice)
+			if err != nil {
+				t.Fatalf("shouldSkipOrUnexportEndpointSlice(%+v), got %v, want no error", endpointSlice, err)
+			}
+			if op != tc.want {
+				t.Fatalf("shouldSkipOrUnexportEndpointSlice(%+v) = %d, want %d", endpointSlice, op, tc.want)
+			}
+		})
+	}
+}
+
+// TestShouldSkipOrUnexportEndpointSlice_PausedServiceExport tests the
+// *Reconciler.shouldSkipOrUnexportEndpointSlice method with a ServiceExport whose reconciliation is paused.
+func TestShouldSkipOrUnexportEndpointSlice_PausedServiceExport(t *testing.T) {
+	testCases := []struct {
+		name                    string
+		svcExportConds          []metav1.Condition
+		hasUniqueNameAnnotation bool
+	}{
+		{
+			name: "should skip endpoint slice (valid export, not exported before)",
+			svcExportConds: []metav1.Condition{
+				serviceExportValidCondition(memberUserNS, svcName),
+				serviceExportNoConflictCondition(memberUserNS, svcName),
+			},
+		},
+		{
+			name:                    "should skip endpoint slice (invalid export, exported before)",
+			hasUniqueNameAnnotation: true,
+		},
+	}
+
+	ctx := context.Background()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svcExport := &fleetnetv1alpha1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   memberUserNS,
+					Name:        svcName,
+					Annotations: map[string]string{objectmeta.ObjectAnnotationPaused: "true"},
+				},
+				Status: fleetnetv1alpha1.ServiceExportStatus{
+					Conditions: tc.svcExportConds,
+				},
+			}
+			endpointSlice := &discoveryv1.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: memberUserNS,
+					Name:      endpointSliceName,
+					Labels: map[string]string{
+						discoveryv1.LabelServiceName: svcName,
+					},
+				},
+				AddressType: discoveryv1.AddressTypeIPv4,
+			}
+			if tc.hasUniqueNameAnnotation {
+				endpointSlice.Annotations = map[string]string{
+					objectmeta.ExportedObjectAnnotationUniqueName: endpointSliceUniqueName,
+				}
+			}
+			fakeMemberClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(endpointSlice, svcExport).
+				Build()
+			reconciler := &Reconciler{
+				Recorder:     record.NewFakeRecorder(10),
+				MemberClient: fakeMemberClient,
+				HubClient:    fake.NewClientBuilder().WithScheme(scheme.Scheme).Build(),
+				HubNamespace: hubNSForMember,
+			}
+
+			op, err := reconciler.shouldSkipOrUnexportEndpointSlice(ctx, endpointSlice)
+			if err != nil {
+				t.Fatalf("shouldSkipOrUnexportEndpointSlice(%+v), got %v, want no error", endpointSlice, err)
+			}
+			if op != shouldSkipEndpointSliceOp {
+				t.Fatalf("shouldSkipOrUnexportEndpointSlice(%+v) = %d, want %d", endpointSlice, op, shouldSkipEndpointSliceOp)
+			}
+		})
+	}
+}
+
+// TestShouldSkipOrUnexportEndpointSlice_TerminatingService tests the *Reconciler.shouldSkipOrUnexportEndpointSlice
+// method with a Service that is being deleted.
+func TestShouldSkipOrUnexportEndpointSlice_TerminatingService(t *testing.T) {
+	deletionTimestamp := metav1.Now()
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         memberUserNS,
+			Name:              svcName,
+			DeletionTimestamp: &deletionTimestamp,
+			// Note that fake client will reject object that is deleted (has the deletion
+			// timestamp) but does not have finalizers.
+			Finalizers: []string{
+				customDeletionBlockerFinalizer,
+			},
+		},
+	}
+	svcExport := &fleetnetv1alpha1.ServiceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: memberUserNS,
+			Name:      svcName,
+		},
+		Status: fleetnetv1alpha1.ServiceExportStatus{
+			Conditions: []metav1.Condition{
+				serviceExportValidCondition(memberUserNS, svcName),
+				serviceExportNoConflictCondition(memberUserNS, svcName),
+			},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		endpointSlice *discoveryv1.EndpointSlice
+		want          skipOrUnexportEndpointSliceOp
+	}{
+		{
+			name: "should unexport endpoint slice (exported)",
+			endpointSlice: &discoveryv1.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: memberUserNS,
+					Name:      endpointSliceName,
+					Labels: map[string]string{
+						discoveryv1.LabelServiceName: svcName,
+					},
+					Annotations: map[string]string{
+						objectmeta.ExportedObjectAnnotationUniqueName: endpointSliceUniqueName,
+					},
+				},
+				AddressType: discoveryv1.AddressTypeIPv4,
+			},
+			want: shouldUnexportEndpointSliceOp,
+		},
+		{
+			name: "should skip endpoint slice (not exported)",
+			endpointSlice: &discoveryv1.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: memberUserNS,
+					Name:      endpointSliceName,
+					Labels: map[string]string{
+						discoveryv1.LabelServiceName: svcName,
+					},
+				},
+				AddressType: discoveryv1.AddressTypeIPv4,
+			},
+			want: shouldSkipEndpointSliceOp,
+		},
+	}
+
+	ctx := context.Background()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeMemberClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(tc.endpointSlice, svcExport, svc).
+				WithStatusSubresource(tc.endpointSlice, svcExport).
+				Build()
+			fakeHubClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+			reconciler := &Reconciler{
+				Recorder:     record.NewFakeRecorder(10),
 				MemberClient: fakeMemberClient,
 				HubClient:    fakeHubClient,
 				HubNamespace: hubNSForMember,
@@ -861,6 +1235,18 @@ func TestShouldSkipOrUnexportEndpointSlice_ExportedService(t *testing.T) {
 			if op != tc.want {
 				t.Fatalf("shouldSkipOrUnexportEndpointSlice(%+v) = %d, want %d", tc.endpointSlice, op, tc.want)
 			}
+
+			// Reconcile the EndpointSlice and verify that no endpoints are exported.
+			if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: endpointSliceKey}); err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+			endpointSliceExportList := &fleetnetv1alpha1.EndpointSliceExportList{}
+			if err := fakeHubClient.List(ctx, endpointSliceExportList); err != nil {
+				t.Fatalf("endpointSliceExport List() = %v, want no error", err)
+			}
+			if len(endpointSliceExportList.Items) != 0 {
+				t.Errorf("got %d endpointSliceExports, want none", len(endpointSliceExportList.Items))
+			}
 		})
 	}
 }
@@ -1091,6 +1477,7 @@ func TestAnnotateLastSeenGenerationAndTimestamp(t *testing.T) {
 				Build()
 			fakeHubClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
 			reconciler := &Reconciler{
+				Recorder:     record.NewFakeRecorder(10),
 				MemberClient: fakeMemberClient,
 				HubClient:    fakeHubClient,
 				HubNamespace: hubNSForMember,
@@ -1253,6 +1640,7 @@ func TestCollectAndVerifyLastSeenGenerationAndTimestamp(t *testing.T) {
 				Build()
 			fakeHubClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
 			reconciler := &Reconciler{
+				Recorder:     record.NewFakeRecorder(10),
 				MemberClient: fakeMemberClient,
 				HubClient:    fakeHubClient,
 				HubNamespace: hubNSForMember,
@@ -1275,3 +1663,969 @@ func TestCollectAndVerifyLastSeenGenerationAndTimestamp(t *testing.T) {
 		})
 	}
 }
+
+// TestReconcile_EndpointSelector tests the *Reconciler.Reconcile method with ServiceExports that select only part
+// of the endpoints of a Service for export.
+func TestReconcile_EndpointSelector(t *testing.T) {
+	podRef := func(name string) *corev1.ObjectReference {
+		return &corev1.ObjectReference{Kind: "Pod", Namespace: memberUserNS, Name: name}
+	}
+	pod := func(name, tier string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: memberUserNS,
+				Name:      name,
+				Labels:    map[string]string{"tier": tier},
+			},
+		}
+	}
+	endpointSlice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: memberUserNS,
+			Name:      endpointSliceName,
+			Labels: map[string]string{
+				discoveryv1.LabelServiceName: svcName,
+			},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints: []discoveryv1.Endpoint{
+			{
+				Addresses: []string{"1.2.3.4"},
+				TargetRef: podRef("frontend-1"),
+			},
+			{
+				Addresses: []string{"2.3.4.5"},
+				TargetRef: podRef("backend-1"),
+			},
+			{
+				Addresses: []string{"3.4.5.6"},
+				TargetRef: podRef("frontend-2"),
+			},
+			{
+				// An external endpoint that is not backed by a Pod.
+				Addresses: []string{"4.5.6.7"},
+			},
+			{
+				// An endpoint whose Pod is gone.
+				Addresses: []string{"5.6.7.8"},
+				TargetRef: podRef("frontend-3"),
+			},
+		},
+	}
+
+	testCases := []struct {
+		name             string
+		endpointSelector *metav1.LabelSelector
+		wantEndpoints    []fleetnetv1alpha1.Endpoint
+	}{
+		{
+			name: "no endpoint selector",
+			wantEndpoints: []fleetnetv1alpha1.Endpoint{
+				{Addresses: []string{"1.2.3.4"}},
+				{Addresses: []string{"2.3.4.5"}},
+				{Addresses: []string{"3.4.5.6"}},
+				{Addresses: []string{"4.5.6.7"}},
+				{Addresses: []string{"5.6.7.8"}},
+			},
+		},
+		{
+			name: "endpoint selector matching some pods",
+			endpointSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"tier": "frontend"},
+			},
+			wantEndpoints: []fleetnetv1alpha1.Endpoint{
+				{Addresses: []string{"1.2.3.4"}},
+				{Addresses: []string{"3.4.5.6"}},
+			},
+		},
+		{
+			name: "endpoint selector matching no pods",
+			endpointSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"tier": "canary"},
+			},
+			wantEndpoints: []fleetnetv1alpha1.Endpoint{},
+		},
+		{
+			name: "invalid endpoint selector",
+			endpointSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "tier", Operator: "Unknown"},
+				},
+			},
+			wantEndpoints: []fleetnetv1alpha1.Endpoint{},
+		},
+	}
+
+	ctx := context.Background()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svcExport := &fleetnetv1alpha1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: memberUserNS,
+					Name:      svcName,
+				},
+				Spec: fleetnetv1alpha1.ServiceExportSpec{
+					EndpointSelector: tc.endpointSelector,
+				},
+				Status: fleetnetv1alpha1.ServiceExportStatus{
+					Conditions: []metav1.Condition{
+						serviceExportValidCondition(memberUserNS, svcName),
+						serviceExportNoConflictCondition(memberUserNS, svcName),
+					},
+				},
+			}
+			fakeMemberClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(endpointSlice.DeepCopy(), svcExport,
+					pod("frontend-1", "frontend"), pod("backend-1", "backend"), pod("frontend-2", "frontend")).
+				Build()
+			fakeHubClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+			reconciler := &Reconciler{
+				Recorder:        record.NewFakeRecorder(10),
+				MemberClusterID: memberClusterID,
+				MemberClient:    fakeMemberClient,
+				HubClient:       fakeHubClient,
+				HubNamespace:    hubNSForMember,
+			}
+
+			if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: endpointSliceKey}); err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+
+			endpointSliceExportList := &fleetnetv1alpha1.EndpointSliceExportList{}
+			if err := fakeHubClient.List(ctx, endpointSliceExportList); err != nil {
+				t.Fatalf("endpointSliceExport List() = %v, want no error", err)
+			}
+			if len(endpointSliceExportList.Items) != 1 {
+				t.Fatalf("got %d endpointSliceExports, want 1", len(endpointSliceExportList.Items))
+			}
+			if diff := cmp.Diff(tc.wantEndpoints, endpointSliceExportList.Items[0].Spec.Endpoints); diff != "" {
+				t.Errorf("exported endpoints mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestReconcile_LoadBalancerMode tests that the endpoints are exported through the internal load balancer of the
+// Service in the LoadBalancer connectivity mode.
+func TestReconcile_LoadBalancerMode(t *testing.T) {
+	endpointSlice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: memberUserNS,
+			Name:      endpointSliceName,
+			Labels: map[string]string{
+				discoveryv1.LabelServiceName: svcName,
+			},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints:   []discoveryv1.Endpoint{{Addresses: []string{"1.2.3.4"}}, {Addresses: []string{"2.3.4.5"}}},
+		Ports:       []discoveryv1.EndpointPort{{Name: ptr.To("web"), Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To(int32(8080))}},
+	}
+	svcExport := &fleetnetv1alpha1.ServiceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   memberUserNS,
+			Name:        svcName,
+			Annotations: map[string]string{objectmeta.ServiceExportAnnotationConnectivityMode: loadbalancerexport.ConnectivityModeLoadBalancer},
+		},
+		Status: fleetnetv1alpha1.ServiceExportStatus{
+			Conditions: []metav1.Condition{
+				serviceExportValidCondition(memberUserNS, svcName),
+				serviceExportNoConflictCondition(memberUserNS, svcName),
+			},
+		},
+	}
+	svc := func(name string, svcType corev1.ServiceType, ingressIP string) *corev1.Service {
+		res := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: memberUserNS, Name: name},
+			Spec: corev1.ServiceSpec{
+				Type:  svcType,
+				Ports: []corev1.ServicePort{{Name: "web", Protocol: corev1.ProtocolTCP, Port: 80, TargetPort: intstr.FromInt(8080)}},
+			},
+		}
+		if svcType == corev1.ServiceTypeLoadBalancer {
+			res.Annotations = map[string]string{objectmeta.ServiceAnnotationAzureLoadBalancerInternal: "true"}
+		}
+		if ingressIP != "" {
+			res.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: ingressIP}}
+		}
+		return res
+	}
+	lbEndpoints := []fleetnetv1alpha1.Endpoint{{
+		Addresses:  []string{"10.1.0.10"},
+		Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true), Serving: ptr.To(true)},
+	}}
+	lbPorts := []discoveryv1.EndpointPort{{Name: ptr.To("web"), Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To(int32(80))}}
+
+	testCases := []struct {
+		name          string
+		svcs          []client.Object
+		wantEndpoints []fleetnetv1alpha1.Endpoint
+		wantPorts     []discoveryv1.EndpointPort
+	}{
+		{
+			name: "load balancer service created for the service",
+			svcs: []client.Object{
+				svc(svcName, corev1.ServiceTypeClusterIP, ""),
+				svc(loadbalancerexport.ServiceName(svcName), corev1.ServiceTypeLoadBalancer, "10.1.0.10"),
+			},
+			wantEndpoints: lbEndpoints,
+			wantPorts:     lbPorts,
+		},
+		{
+			name:          "internal load balancer service",
+			svcs:          []client.Object{svc(svcName, corev1.ServiceTypeLoadBalancer, "10.1.0.10")},
+			wantEndpoints: lbEndpoints,
+			wantPorts:     lbPorts,
+		},
+		{
+			name:          "load balancer service not created yet",
+			svcs:          []client.Object{svc(svcName, corev1.ServiceTypeClusterIP, "")},
+			wantEndpoints: []fleetnetv1alpha1.Endpoint{},
+			wantPorts:     []discoveryv1.EndpointPort{},
+		},
+	}
+
+	ctx := context.Background()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeMemberClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(endpointSlice.DeepCopy(), svcExport.DeepCopy()).
+				WithObjects(tc.svcs...).
+				Build()
+			fakeHubClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+			reconciler := &Reconciler{
+				Recorder:        record.NewFakeRecorder(10),
+				MemberClusterID: memberClusterID,
+				MemberClient:    fakeMemberClient,
+				HubClient:       fakeHubClient,
+				HubNamespace:    hubNSForMember,
+			}
+
+			if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: endpointSliceKey}); err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+
+			endpointSliceExportList := &fleetnetv1alpha1.EndpointSliceExportList{}
+			if err := fakeHubClient.List(ctx, endpointSliceExportList); err != nil {
+				t.Fatalf("endpointSliceExport List() = %v, want no error", err)
+			}
+			if len(endpointSliceExportList.Items) != 1 {
+				t.Fatalf("got %d endpointSliceExports, want 1", len(endpointSliceExportList.Items))
+			}
+			if diff := cmp.Diff(tc.wantEndpoints, endpointSliceExportList.Items[0].Spec.Endpoints); diff != "" {
+				t.Errorf("exported endpoints mismatch (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantPorts, endpointSliceExportList.Items[0].Spec.Ports); diff != "" {
+				t.Errorf("exported ports mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestExtractSelectedPorts tests the Reconciler.extractSelectedPorts method.
+func TestExtractSelectedPorts(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: memberUserNS,
+			Name:      svcName,
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{Name: "web", Protocol: corev1.ProtocolTCP, Port: 80, TargetPort: intstr.FromInt(8080)},
+				{Name: "admin", Protocol: corev1.ProtocolTCP, Port: 9000, TargetPort: intstr.FromInt(9090)},
+			},
+		},
+	}
+	endpointSlice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: memberUserNS,
+			Name:      endpointSliceName,
+		},
+		Ports: []discoveryv1.EndpointPort{
+			{Name: ptr.To("web"), Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To(int32(8080))},
+			{Name: ptr.To("admin"), Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To(int32(9090))},
+		},
+	}
+	testCases := []struct {
+		name  string
+		ports []intstr.IntOrString
+		want  []discoveryv1.EndpointPort
+	}{
+		{
+			name: "all ports are exported",
+			want: endpointSlice.Ports,
+		},
+		{
+			name:  "port selected by name",
+			ports: []intstr.IntOrString{intstr.FromString("web")},
+			want:  []discoveryv1.EndpointPort{endpointSlice.Ports[0]},
+		},
+		{
+			name:  "port selected by the service port number",
+			ports: []intstr.IntOrString{intstr.FromInt32(9000)},
+			want:  []discoveryv1.EndpointPort{endpointSlice.Ports[1]},
+		},
+		{
+			name:  "target port number selects no ports",
+			ports: []intstr.IntOrString{intstr.FromInt32(8080)},
+			want:  []discoveryv1.EndpointPort{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svcExport := &fleetnetv1alpha1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: memberUserNS,
+					Name:      svcName,
+				},
+				Spec: fleetnetv1alpha1.ServiceExportSpec{Ports: tc.ports},
+			}
+			r := &Reconciler{MemberClient: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(svc).Build()}
+			got, err := r.extractSelectedPorts(context.Background(), endpointSlice, svcExport)
+			if err != nil {
+				t.Fatalf("extractSelectedPorts() = %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("extractSelectedPorts() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestRouteThroughGateway tests the routeThroughGateway function.
+func TestRouteThroughGateway(t *testing.T) {
+	endpointSlice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: memberUserNS,
+			Name:      endpointSliceName,
+			Labels:    map[string]string{discoveryv1.LabelServiceName: svcName},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+	}
+	ports := []discoveryv1.EndpointPort{
+		{Name: ptr.To("web"), Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To(int32(8080))},
+		{Name: ptr.To("admin"), Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To(int32(9090))},
+	}
+	readyEndpoint := fleetnetv1alpha1.Endpoint{Addresses: []string{"1.2.3.4"}, NodeName: ptr.To("node-1")}
+	terminatingEndpoint := fleetnetv1alpha1.Endpoint{
+		Addresses:  []string{"1.2.3.5"},
+		Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(false), Serving: ptr.To(true), Terminating: ptr.To(true)},
+	}
+	mappings := []eastwestgateway.PortMapping{
+		{Namespace: memberUserNS, Service: svcName, PortName: "web", ClusterIP: "10.0.0.10", Port: 80, GatewayPort: 15100},
+	}
+	routedPorts := []discoveryv1.EndpointPort{
+		{Name: ptr.To("web"), Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To(int32(15100))},
+	}
+	testCases := []struct {
+		name      string
+		gateway   *eastwestgateway.Gateway
+		endpoints []fleetnetv1alpha1.Endpoint
+		want      *fleetnetv1alpha1.GatewayEndpoints
+	}{
+		{
+			name: "service not routed through the gateway",
+			gateway: eastwestgateway.NewGateway("20.0.0.1", []eastwestgateway.PortMapping{
+				{Namespace: memberUserNS, Service: "other", PortName: "web", ClusterIP: "10.0.0.11", Port: 80, GatewayPort: 15100},
+			}),
+			endpoints: []fleetnetv1alpha1.Endpoint{readyEndpoint},
+		},
+		{
+			name:      "ready endpoints",
+			gateway:   eastwestgateway.NewGateway("20.0.0.1", mappings),
+			endpoints: []fleetnetv1alpha1.Endpoint{readyEndpoint, terminatingEndpoint},
+			want: &fleetnetv1alpha1.GatewayEndpoints{
+				Endpoints: []fleetnetv1alpha1.Endpoint{{
+					Addresses:  []string{"20.0.0.1"},
+					Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true), Serving: ptr.To(true)},
+				}},
+				Ports: routedPorts,
+			},
+		},
+		{
+			name:      "terminating endpoints only",
+			gateway:   eastwestgateway.NewGateway("20.0.0.1", mappings),
+			endpoints: []fleetnetv1alpha1.Endpoint{terminatingEndpoint},
+			want: &fleetnetv1alpha1.GatewayEndpoints{
+				Endpoints: []fleetnetv1alpha1.Endpoint{{
+					Addresses:  []string{"20.0.0.1"},
+					Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(false), Serving: ptr.To(true), Terminating: ptr.To(true)},
+				}},
+				Ports: routedPorts,
+			},
+		},
+		{
+			name:      "no endpoints",
+			gateway:   eastwestgateway.NewGateway("20.0.0.1", mappings),
+			endpoints: []fleetnetv1alpha1.Endpoint{},
+			want:      &fleetnetv1alpha1.GatewayEndpoints{Endpoints: []fleetnetv1alpha1.Endpoint{}, Ports: routedPorts},
+		},
+		{
+			name:      "gateway without address",
+			gateway:   eastwestgateway.NewGateway("", mappings),
+			endpoints: []fleetnetv1alpha1.Endpoint{readyEndpoint},
+			want:      &fleetnetv1alpha1.GatewayEndpoints{Endpoints: []fleetnetv1alpha1.Endpoint{}, Ports: routedPorts},
+		},
+		{
+			name:      "gateway address of a different family",
+			gateway:   eastwestgateway.NewGateway("2001:db8::1", mappings),
+			endpoints: []fleetnetv1alpha1.Endpoint{readyEndpoint},
+			want:      &fleetnetv1alpha1.GatewayEndpoints{Endpoints: []fleetnetv1alpha1.Endpoint{}, Ports: routedPorts},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := routeThroughGateway(tc.gateway, endpointSlice, tc.endpoints, ports)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("routeThroughGateway() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestExposeThroughPrivateLink tests the exposeThroughPrivateLink function.
+func TestExposeThroughPrivateLink(t *testing.T) {
+	serviceID := "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/privateLinkServices/fleet-work-app"
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: memberUserNS, Name: svcName},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{Name: "web", Protocol: corev1.ProtocolTCP, Port: 80, TargetPort: intstr.FromInt(8080)},
+				{Name: "dns", Protocol: corev1.ProtocolUDP, Port: 53, TargetPort: intstr.FromInt(5353)},
+			},
+		},
+	}
+	ports := []discoveryv1.EndpointPort{
+		{Name: ptr.To("web"), Port: ptr.To(int32(8080))},
+		{Name: ptr.To("dns"), Protocol: ptr.To(corev1.ProtocolUDP), Port: ptr.To(int32(5353))},
+		{Name: ptr.To("admin"), Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To(int32(9090))},
+	}
+	testCases := []struct {
+		name      string
+		svcExport *fleetnetv1alpha1.ServiceExport
+		want      *fleetnetv1alpha1.PrivateLinkEndpoints
+	}{
+		{
+			name: "service export not annotated",
+			svcExport: &fleetnetv1alpha1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{Namespace: memberUserNS, Name: svcName},
+			},
+		},
+		{
+			name: "service export annotated",
+			svcExport: &fleetnetv1alpha1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   memberUserNS,
+					Name:        svcName,
+					Annotations: map[string]string{objectmeta.ServiceExportAnnotationPrivateLinkServiceID: serviceID},
+				},
+			},
+			want: &fleetnetv1alpha1.PrivateLinkEndpoints{
+				ServiceID: serviceID,
+				Ports: []discoveryv1.EndpointPort{
+					{Name: ptr.To("web"), Port: ptr.To(int32(80))},
+					{Name: ptr.To("dns"), Protocol: ptr.To(corev1.ProtocolUDP), Port: ptr.To(int32(53))},
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := exposeThroughPrivateLink(tc.svcExport, svc, ports)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("exposeThroughPrivateLink() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestExportThroughLoadBalancer tests the exportThroughLoadBalancer function.
+func TestExportThroughLoadBalancer(t *testing.T) {
+	endpointSlice := &discoveryv1.EndpointSlice{
+		ObjectMeta:  metav1.ObjectMeta{Namespace: memberUserNS, Name: endpointSliceName},
+		AddressType: discoveryv1.AddressTypeIPv4,
+	}
+	ports := []discoveryv1.EndpointPort{
+		{Name: ptr.To("web"), Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To(int32(8080))},
+		{Name: ptr.To("admin"), Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To(int32(9090))},
+	}
+	lbPorts := []discoveryv1.EndpointPort{
+		{Name: ptr.To("web"), Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To(int32(80))},
+	}
+	lbSvc := func(ips ...string) *corev1.Service {
+		svc := &corev1.Service{
+			Spec: corev1.ServiceSpec{
+				Type:  corev1.ServiceTypeLoadBalancer,
+				Ports: []corev1.ServicePort{{Name: "web", Protocol: corev1.ProtocolTCP, Port: 80, TargetPort: intstr.FromInt(8080)}},
+			},
+		}
+		for _, ip := range ips {
+			svc.Status.LoadBalancer.Ingress = append(svc.Status.LoadBalancer.Ingress, corev1.LoadBalancerIngress{IP: ip})
+		}
+		return svc
+	}
+	readyEndpoint := fleetnetv1alpha1.Endpoint{Addresses: []string{"1.2.3.4"}, NodeName: ptr.To("node-1")}
+	terminatingEndpoint := fleetnetv1alpha1.Endpoint{
+		Addresses:  []string{"1.2.3.5"},
+		Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(false), Serving: ptr.To(true), Terminating: ptr.To(true)},
+	}
+	testCases := []struct {
+		name          string
+		lbSvc         *corev1.Service
+		endpoints     []fleetnetv1alpha1.Endpoint
+		wantEndpoints []fleetnetv1alpha1.Endpoint
+	}{
+		{
+			name:      "ready endpoints",
+			lbSvc:     lbSvc("fd00::10", "10.1.0.10"),
+			endpoints: []fleetnetv1alpha1.Endpoint{readyEndpoint, terminatingEndpoint},
+			wantEndpoints: []fleetnetv1alpha1.Endpoint{{
+				Addresses:  []string{"10.1.0.10"},
+				Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true), Serving: ptr.To(true)},
+			}},
+		},
+		{
+			name:      "terminating endpoints only",
+			lbSvc:     lbSvc("10.1.0.10"),
+			endpoints: []fleetnetv1alpha1.Endpoint{terminatingEndpoint},
+			wantEndpoints: []fleetnetv1alpha1.Endpoint{{
+				Addresses:  []string{"10.1.0.10"},
+				Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(false), Serving: ptr.To(true), Terminating: ptr.To(true)},
+			}},
+		},
+		{
+			name:          "no endpoints",
+			lbSvc:         lbSvc("10.1.0.10"),
+			endpoints:     []fleetnetv1alpha1.Endpoint{},
+			wantEndpoints: []fleetnetv1alpha1.Endpoint{},
+		},
+		{
+			name:          "load balancer without ingress IP",
+			lbSvc:         lbSvc(),
+			endpoints:     []fleetnetv1alpha1.Endpoint{readyEndpoint},
+			wantEndpoints: []fleetnetv1alpha1.Endpoint{},
+		},
+		{
+			name:          "load balancer ingress IP of a different family",
+			lbSvc:         lbSvc("fd00::10"),
+			endpoints:     []fleetnetv1alpha1.Endpoint{readyEndpoint},
+			wantEndpoints: []fleetnetv1alpha1.Endpoint{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotEndpoints, gotPorts := exportThroughLoadBalancer(tc.lbSvc, endpointSlice, tc.endpoints, ports)
+			if diff := cmp.Diff(tc.wantEndpoints, gotEndpoints); diff != "" {
+				t.Errorf("exportThroughLoadBalancer() endpoints mismatch (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(lbPorts, gotPorts); diff != "" {
+				t.Errorf("exportThroughLoadBalancer() ports mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestSetEndpointRegions tests the setEndpointRegions method.
+func TestSetEndpointRegions(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "node-1",
+			Labels: map[string]string{corev1.LabelTopologyRegion: "eastus"},
+		},
+	}
+	fakeMemberClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(node).
+		Build()
+	r := &Reconciler{MemberClient: fakeMemberClient}
+
+	endpoints := []fleetnetv1alpha1.Endpoint{
+		{Addresses: []string{"1.2.3.4"}, NodeName: ptr.To("node-1")},
+		{Addresses: []string{"2.3.4.5"}, NodeName: ptr.To("node-1")},
+		{Addresses: []string{"3.4.5.6"}, NodeName: ptr.To("node-gone")},
+		{Addresses: []string{"4.5.6.7"}},
+	}
+	if err := r.setEndpointRegions(context.Background(), endpoints); err != nil {
+		t.Fatalf("setEndpointRegions() = %v, want no error", err)
+	}
+	want := []fleetnetv1alpha1.Endpoint{
+		{Addresses: []string{"1.2.3.4"}, NodeName: ptr.To("node-1"), Region: ptr.To("eastus")},
+		{Addresses: []string{"2.3.4.5"}, NodeName: ptr.To("node-1"), Region: ptr.To("eastus")},
+		{Addresses: []string{"3.4.5.6"}, NodeName: ptr.To("node-gone")},
+		{Addresses: []string{"4.5.6.7"}},
+	}
+	if diff := cmp.Diff(want, endpoints); diff != "" {
+		t.Errorf("setEndpointRegions() mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+// TestFilterWarmedUpEndpoints tests the filterWarmedUpEndpoints function.
+func TestFilterWarmedUpEndpoints(t *testing.T) {
+	now := time.Now()
+	warmup := time.Second * 30
+	terminating := discoveryv1.EndpointConditions{
+		Ready:       ptr.To(false),
+		Serving:     ptr.To(true),
+		Terminating: ptr.To(true),
+	}
+	testCases := []struct {
+		name             string
+		serving          []fleetnetv1alpha1.Endpoint
+		advertised       []fleetnetv1alpha1.Endpoint
+		readySince       map[string]time.Time
+		wantKept         []fleetnetv1alpha1.Endpoint
+		wantWarmingUp    map[string]time.Time
+		wantNextWarmedUp time.Duration
+	}{
+		{
+			name:             "newly ready endpoint starts its warmup",
+			serving:          []fleetnetv1alpha1.Endpoint{{Addresses: []string{"1.2.3.4"}}},
+			wantKept:         []fleetnetv1alpha1.Endpoint{},
+			wantWarmingUp:    map[string]time.Time{"1.2.3.4": now},
+			wantNextWarmedUp: warmup,
+		},
+		{
+			name:             "endpoint still warming up",
+			serving:          []fleetnetv1alpha1.Endpoint{{Addresses: []string{"1.2.3.4"}}},
+			readySince:       map[string]time.Time{"1.2.3.4": now.Add(-time.Second * 20)},
+			wantKept:         []fleetnetv1alpha1.Endpoint{},
+			wantWarmingUp:    map[string]time.Time{"1.2.3.4": now.Add(-time.Second * 20)},
+			wantNextWarmedUp: time.Second * 10,
+		},
+		{
+			name:          "endpoint completed its warmup",
+			serving:       []fleetnetv1alpha1.Endpoint{{Addresses: []string{"1.2.3.4"}}},
+			readySince:    map[string]time.Time{"1.2.3.4": now.Add(-time.Second * 30)},
+			wantKept:      []fleetnetv1alpha1.Endpoint{{Addresses: []string{"1.2.3.4"}}},
+			wantWarmingUp: map[string]time.Time{},
+		},
+		{
+			name:          "advertised endpoint stays advertised",
+			serving:       []fleetnetv1alpha1.Endpoint{{Addresses: []string{"1.2.3.4"}}},
+			advertised:    []fleetnetv1alpha1.Endpoint{{Addresses: []string{"1.2.3.4"}}},
+			wantKept:      []fleetnetv1alpha1.Endpoint{{Addresses: []string{"1.2.3.4"}}},
+			wantWarmingUp: map[string]time.Time{},
+		},
+		{
+			name: "endpoint no longer ready drops out of warmup",
+			serving: []fleetnetv1alpha1.Endpoint{
+				{Addresses: []string{"1.2.3.4"}},
+				{Addresses: []string{"2.3.4.5"}},
+			},
+			advertised: []fleetnetv1alpha1.Endpoint{
+				{Addresses: []string{"1.2.3.4"}},
+				{Addresses: []string{"3.4.5.6"}},
+			},
+			readySince: map[string]time.Time{
+				"2.3.4.5": now.Add(-time.Second * 5),
+				"4.5.6.7": now.Add(-time.Second * 25),
+			},
+			wantKept:         []fleetnetv1alpha1.Endpoint{{Addresses: []string{"1.2.3.4"}}},
+			wantWarmingUp:    map[string]time.Time{"2.3.4.5": now.Add(-time.Second * 5)},
+			wantNextWarmedUp: time.Second * 25,
+		},
+		{
+			name: "terminating endpoint stays advertised but never starts its warmup",
+			serving: []fleetnetv1alpha1.Endpoint{
+				{Addresses: []string{"1.2.3.4"}, Conditions: terminating},
+				{Addresses: []string{"2.3.4.5"}, Conditions: terminating},
+			},
+			advertised:    []fleetnetv1alpha1.Endpoint{{Addresses: []string{"1.2.3.4"}}},
+			wantKept:      []fleetnetv1alpha1.Endpoint{{Addresses: []string{"1.2.3.4"}, Conditions: terminating}},
+			wantWarmingUp: map[string]time.Time{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			kept, warmingUp, nextWarmedUp := filterWarmedUpEndpoints(tc.serving, tc.advertised, tc.readySince, warmup, now)
+			if diff := cmp.Diff(tc.wantKept, kept); diff != "" {
+				t.Errorf("filterWarmedUpEndpoints() kept endpoints mismatch (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantWarmingUp, warmingUp); diff != "" {
+				t.Errorf("filterWarmedUpEndpoints() warming up endpoints mismatch (-want, +got):\n%s", diff)
+			}
+			if nextWarmedUp != tc.wantNextWarmedUp {
+				t.Errorf("filterWarmedUpEndpoints() next warmed up = %v, want %v", nextWarmedUp, tc.wantNextWarmedUp)
+			}
+		})
+	}
+}
+
+// TestReconcile_EndpointWarmup tests that the controller delays advertising newly ready endpoints until they
+// complete their warmup, and restarts the warmup of an endpoint that becomes not ready in the middle of it.
+func TestReconcile_EndpointWarmup(t *testing.T) {
+	ctx := context.Background()
+	warmup := time.Second * 30
+	endpoint := func(addr string, ready bool) discoveryv1.Endpoint {
+		return discoveryv1.Endpoint{
+			Addresses:  []string{addr},
+			Conditions: discoveryv1.EndpointConditions{Ready: &ready},
+		}
+	}
+	endpointSlice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: memberUserNS,
+			Name:      endpointSliceName,
+			Labels: map[string]string{
+				discoveryv1.LabelServiceName: svcName,
+			},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints:   []discoveryv1.Endpoint{endpoint("1.2.3.4", true), endpoint("2.3.4.5", true)},
+	}
+	svcExport := &fleetnetv1alpha1.ServiceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: memberUserNS,
+			Name:      svcName,
+			Annotations: map[string]string{
+				objectmeta.ServiceExportAnnotationEndpointWarmup: warmup.String(),
+			},
+		},
+		Status: fleetnetv1alpha1.ServiceExportStatus{
+			Conditions: []metav1.Condition{
+				serviceExportValidCondition(memberUserNS, svcName),
+				serviceExportNoConflictCondition(memberUserNS, svcName),
+			},
+		},
+	}
+	fakeMemberClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(endpointSlice, svcExport).Build()
+	fakeHubClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	reconciler := &Reconciler{
+		Recorder:        record.NewFakeRecorder(10),
+		MemberClusterID: memberClusterID,
+		MemberClient:    fakeMemberClient,
+		HubClient:       fakeHubClient,
+		HubNamespace:    hubNSForMember,
+	}
+
+	// elapse moves the ready-since times of the endpoints warming up back by d, as if d had passed.
+	elapse := func(d time.Duration) {
+		for addr, since := range reconciler.readySince[endpointSliceKey] {
+			reconciler.readySince[endpointSliceKey][addr] = since.Add(-d)
+		}
+	}
+	setEndpoints := func(endpoints ...discoveryv1.Endpoint) {
+		slice := &discoveryv1.EndpointSlice{}
+		if err := fakeMemberClient.Get(ctx, endpointSliceKey, slice); err != nil {
+			t.Fatalf("endpointSlice Get() = %v, want no error", err)
+		}
+		slice.Endpoints = endpoints
+		if err := fakeMemberClient.Update(ctx, slice); err != nil {
+			t.Fatalf("endpointSlice Update() = %v, want no error", err)
+		}
+	}
+	reconcileAndCheck := func(step string, wantAddrs []string, wantRequeueAfter time.Duration) {
+		res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: endpointSliceKey})
+		if err != nil {
+			t.Fatalf("%s: Reconcile() = %v, want no error", step, err)
+		}
+		// Allow some slack for the time passed during the reconciliation.
+		if res.RequeueAfter > wantRequeueAfter || res.RequeueAfter < wantRequeueAfter-time.Second {
+			t.Errorf("%s: Reconcile() requeueAfter = %v, want %v", step, res.RequeueAfter, wantRequeueAfter)
+		}
+		endpointSliceExportList := &fleetnetv1alpha1.EndpointSliceExportList{}
+		if err := fakeHubClient.List(ctx, endpointSliceExportList); err != nil {
+			t.Fatalf("%s: endpointSliceExport List() = %v, want no error", step, err)
+		}
+		if len(endpointSliceExportList.Items) != 1 {
+			t.Fatalf("%s: got %d endpointSliceExports, want 1", step, len(endpointSliceExportList.Items))
+		}
+		gotAddrs := []string{}
+		for _, endpoint := range endpointSliceExportList.Items[0].Spec.Endpoints {
+			gotAddrs = append(gotAddrs, endpoint.Addresses...)
+		}
+		if diff := cmp.Diff(wantAddrs, gotAddrs); diff != "" {
+			t.Errorf("%s: advertised endpoints mismatch (-want, +got):\n%s", step, diff)
+		}
+	}
+
+	reconcileAndCheck("newly ready endpoints", []string{}, warmup)
+	elapse(warmup)
+	reconcileAndCheck("endpoints warmed up", []string{"1.2.3.4", "2.3.4.5"}, endpointSliceResyncInterval)
+
+	setEndpoints(endpoint("1.2.3.4", true), endpoint("2.3.4.5", true), endpoint("3.4.5.6", true))
+	reconcileAndCheck("another endpoint becomes ready", []string{"1.2.3.4", "2.3.4.5"}, warmup)
+	elapse(time.Second * 20)
+	reconcileAndCheck("endpoint still warming up", []string{"1.2.3.4", "2.3.4.5"}, time.Second*10)
+
+	setEndpoints(endpoint("1.2.3.4", true), endpoint("2.3.4.5", true), endpoint("3.4.5.6", false))
+	reconcileAndCheck("endpoint not ready during warmup", []string{"1.2.3.4", "2.3.4.5"}, endpointSliceResyncInterval)
+
+	setEndpoints(endpoint("1.2.3.4", true), endpoint("2.3.4.5", true), endpoint("3.4.5.6", true))
+	reconcileAndCheck("endpoint ready again restarts warmup", []string{"1.2.3.4", "2.3.4.5"}, warmup)
+	elapse(time.Second * 20)
+	reconcileAndCheck("restarted warmup not completed", []string{"1.2.3.4", "2.3.4.5"}, time.Second*10)
+	elapse(time.Second * 10)
+	reconcileAndCheck("restarted warmup completed", []string{"1.2.3.4", "2.3.4.5", "3.4.5.6"}, endpointSliceResyncInterval)
+}
+
+// TestReconcile_Draining tests that the endpoints of a deleted ServiceExport are exported as terminating while they
+// are drained, and unexported once the drain period ends.
+func TestReconcile_Draining(t *testing.T) {
+	drainPeriod := time.Minute
+	testCases := []struct {
+		name          string
+		deletedFor    time.Duration
+		wantEndpoints []fleetnetv1alpha1.Endpoint
+		wantExported  bool
+	}{
+		{
+			name:         "endpoints are drained",
+			deletedFor:   time.Second * 10,
+			wantExported: true,
+			wantEndpoints: []fleetnetv1alpha1.Endpoint{
+				{
+					Addresses:  []string{"1.2.3.4"},
+					Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(false), Serving: ptr.To(true), Terminating: ptr.To(true)},
+				},
+			},
+		},
+		{
+			name:       "drain period has ended",
+			deletedFor: drainPeriod * 2,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			endpointSlice := &discoveryv1.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: memberUserNS,
+					Name:      endpointSliceName,
+					Labels: map[string]string{
+						discoveryv1.LabelServiceName: svcName,
+					},
+					Annotations: map[string]string{
+						objectmeta.ExportedObjectAnnotationUniqueName: endpointSliceUniqueName,
+					},
+				},
+				AddressType: discoveryv1.AddressTypeIPv4,
+				Endpoints: []discoveryv1.Endpoint{
+					{
+						Addresses:  []string{"1.2.3.4"},
+						Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true)},
+					},
+				},
+			}
+			svcExport := &fleetnetv1alpha1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:         memberUserNS,
+					Name:              svcName,
+					DeletionTimestamp: &metav1.Time{Time: time.Now().Add(-tc.deletedFor)},
+					Finalizers:        []string{objectmeta.ServiceExportCleanupFinalizer},
+					Annotations: map[string]string{
+						objectmeta.ServiceExportAnnotationDrainPeriod: drainPeriod.String(),
+					},
+				},
+				Status: fleetnetv1alpha1.ServiceExportStatus{
+					Conditions: []metav1.Condition{
+						serviceExportValidCondition(memberUserNS, svcName),
+						serviceExportNoConflictCondition(memberUserNS, svcName),
+					},
+				},
+			}
+			endpointSliceExport := &fleetnetv1alpha1.EndpointSliceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: hubNSForMember,
+					Name:      endpointSliceUniqueName,
+				},
+				Spec: fleetnetv1alpha1.EndpointSliceExportSpec{
+					AddressType: discoveryv1.AddressTypeIPv4,
+					EndpointSliceReference: fleetnetv1alpha1.FromMetaObjects(memberClusterID,
+						endpointSlice.TypeMeta, endpointSlice.ObjectMeta, metav1.Now()),
+				},
+			}
+			fakeMemberClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(endpointSlice, svcExport).Build()
+			fakeHubClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(endpointSliceExport).Build()
+			reconciler := &Reconciler{
+				Recorder:        record.NewFakeRecorder(10),
+				MemberClusterID: memberClusterID,
+				MemberClient:    fakeMemberClient,
+				HubClient:       fakeHubClient,
+				HubNamespace:    hubNSForMember,
+			}
+
+			if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: endpointSliceKey}); err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+
+			got := &fleetnetv1alpha1.EndpointSliceExport{}
+			err := fakeHubClient.Get(ctx, endpointSliceExportKey, got)
+			if !tc.wantExported {
+				if !errors.IsNotFound(err) {
+					t.Fatalf("endpointSliceExport Get() = %v, want not found", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("endpointSliceExport Get() = %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.wantEndpoints, got.Spec.Endpoints); diff != "" {
+				t.Errorf("exported endpoints mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestDebouncedEventHandler tests that the changes of an EndpointSlice within the debounce window are coalesced
+// into one request, which is handed out after the window.
+func TestDebouncedEventHandler(t *testing.T) {
+	endpointSlice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: memberUserNS,
+			Name:      endpointSliceName,
+		},
+	}
+	toRequest := func(o client.Object) (reconcile.Request, bool) {
+		return reconcile.Request{NamespacedName: client.ObjectKeyFromObject(o)}, true
+	}
+
+	testCases := []struct {
+		name           string
+		debounceWindow time.Duration
+		wantQueued     int
+	}{
+		{
+			name:       "no debounce window",
+			wantQueued: 1,
+		},
+		{
+			name:           "debounce window",
+			debounceWindow: 100 * time.Millisecond,
+			wantQueued:     0,
+		},
+	}
+
+	ctx := context.Background()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reconciler := &Reconciler{DebounceWindow: tc.debounceWindow}
+			eventHandler := reconciler.debouncedEventHandler(toRequest)
+			q := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+			defer q.ShutDown()
+
+			start := time.Now()
+			eventHandler.Create(ctx, event.CreateEvent{Object: endpointSlice}, q)
+			for i := 0; i < 3; i++ {
+				eventHandler.Update(ctx, event.UpdateEvent{ObjectOld: endpointSlice, ObjectNew: endpointSlice}, q)
+			}
+			if got := q.Len(); got != tc.wantQueued {
+				t.Errorf("queue length = %d right after the events, want %d", got, tc.wantQueued)
+			}
+
+			req, _ := q.Get()
+			if elapsed := time.Since(start); elapsed < tc.debounceWindow {
+				t.Errorf("request handed out after %v, want at least %v", elapsed, tc.debounceWindow)
+			}
+			if req.NamespacedName != endpointSliceKey {
+				t.Errorf("request = %v, want %v", req.NamespacedName, endpointSliceKey)
+			}
+			q.Done(req)
+			if got := q.Len(); got != 0 {
+				t.Errorf("queue length = %d after the request is handled, want 0", got)
+			}
+		})
+	}
+}
@@ -104,6 +104,7 @@ var _ = BeforeSuite(func() {
 		MemberClient:    memberClient,
 		HubClient:       hubClient,
 		HubNamespace:    hubNSForMember,
+		Recorder:        ctrlMgr.GetEventRecorderFor(ControllerName),
 	}).SetupWithManager(ctx, ctrlMgr)
 	Expect(err).NotTo(HaveOccurred())
 
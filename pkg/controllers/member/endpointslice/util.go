@@ -6,18 +6,29 @@ Licensed under the MIT license.
 package endpointslice
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
 	discoveryv1 "k8s.io/api/discovery/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/ptr"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/exportdrain"
+	"go.goms.io/fleet-networking/pkg/common/ipfamily"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/controllers/member/eastwestgateway"
 )
 
 // isEndpointSlicePermanentlyUnexportable returns if an EndpointSlice is permanently unexportable.
 func isEndpointSlicePermanentlyUnexportable(endpointSlice *discoveryv1.EndpointSlice) bool {
-	// At this moment only IPv4 endpointslices can be exported; note that AddressType is an immutable field.
-	return endpointSlice.AddressType != discoveryv1.AddressTypeIPv4
+	// Only IPv4 and IPv6 endpointslices can be exported, as the endpoints are imported as IP addresses in other
+	// member clusters; note that AddressType is an immutable field.
+	return endpointSlice.AddressType != discoveryv1.AddressTypeIPv4 && endpointSlice.AddressType != discoveryv1.AddressTypeIPv6
 }
 
 // isServiceExportValidWithNoConflict returns if a ServiceExport
@@ -25,11 +36,31 @@ func isEndpointSlicePermanentlyUnexportable(endpointSlice *discoveryv1.EndpointS
 // * is in no conflict with other service exports; and
 // * has not been deleted
 func isServiceExportValidWithNoConflict(svcExport *fleetnetv1alpha1.ServiceExport) bool {
+	return hasValidWithNoConflictConditions(svcExport) && svcExport.DeletionTimestamp == nil
+}
+
+// isServiceExportDraining returns if a ServiceExport
+// * is valid; and
+// * is in no conflict with other service exports; and
+// * has been deleted, but its endpoints are still drained at the given time
+func isServiceExportDraining(svcExport *fleetnetv1alpha1.ServiceExport, now time.Time) bool {
+	return hasValidWithNoConflictConditions(svcExport) && exportdrain.Remaining(svcExport, now) > 0
+}
+
+// hasValidWithNoConflictConditions returns if the conditions of a ServiceExport report it as valid and in no conflict
+// with other service exports.
+func hasValidWithNoConflictConditions(svcExport *fleetnetv1alpha1.ServiceExport) bool {
 	validCond := meta.FindStatusCondition(svcExport.Status.Conditions, string(fleetnetv1alpha1.ServiceExportValid))
 	conflictCond := meta.FindStatusCondition(svcExport.Status.Conditions, string(fleetnetv1alpha1.ServiceExportConflict))
 	isValid := (validCond != nil && validCond.Status == metav1.ConditionTrue)
 	hasNoConflict := (conflictCond != nil && conflictCond.Status == metav1.ConditionFalse)
-	return (isValid && hasNoConflict && svcExport.DeletionTimestamp == nil)
+	return isValid && hasNoConflict
+}
+
+// isServiceExportImported returns if the hub reports that other member clusters import the exported Service.
+func isServiceExportImported(svcExport *fleetnetv1alpha1.ServiceExport) bool {
+	importedCond := meta.FindStatusCondition(svcExport.Status.Conditions, string(fleetnetv1alpha1.ServiceExportImported))
+	return importedCond != nil && importedCond.Status == metav1.ConditionTrue
 }
 
 // isUniqueNameValid returns if an assigned unique name is a valid DNS subdomain name.
@@ -46,20 +77,222 @@ func isEndpointSliceExportLinkedWithEndpointSlice(endpointSliceExport *fleetnetv
 	return (endpointSliceExport.Spec.EndpointSliceReference.UID == endpointSlice.UID)
 }
 
-// extractEndpointsFromEndpointSlice extracts endpoints from an EndpointSlice.
+// extractEndpointsFromEndpointSlice extracts the serving endpoints from an EndpointSlice, along with their conditions
+// and topology; a terminating endpoint which is still serving is exported, so that the importing clusters may fall
+// back to it when no ready endpoints are left, as kube-proxy does in the exporting cluster.
 func extractEndpointsFromEndpointSlice(endpointSlice *discoveryv1.EndpointSlice) []fleetnetv1alpha1.Endpoint {
 	extractedEndpoints := []fleetnetv1alpha1.Endpoint{}
 	for _, endpoint := range endpointSlice.Endpoints {
-		// Only ready endpoints can be exported; EndpointSlice API dictates that consumers should interpret
-		// unknown ready state, represented by a nil value, as true ready state.
-		// TO-DO (chenyu1): In newer API versions the EndpointConditions API (V1) introduces a serving state, which
-		// allows a backend to serve traffic even if it is already terminating (EndpointSliceTerminationCondition
-		// feature gate).
-		if endpoint.Conditions.Ready == nil || *(endpoint.Conditions.Ready) {
-			extractedEndpoints = append(extractedEndpoints, fleetnetv1alpha1.Endpoint{
-				Addresses: endpoint.Addresses,
-			})
+		extracted := fleetnetv1alpha1.Endpoint{
+			Addresses:  endpoint.Addresses,
+			Zone:       endpoint.Zone,
+			Hostname:   endpoint.Hostname,
+			Conditions: endpoint.Conditions,
+			NodeName:   endpoint.NodeName,
+			Hints:      endpoint.Hints,
 		}
+		if !extracted.IsReady() && !extracted.IsServing() {
+			continue
+		}
+		extractedEndpoints = append(extractedEndpoints, extracted)
 	}
 	return extractedEndpoints
 }
+
+// extractEndpointWarmup extracts the endpoint warmup period from the annotation of a ServiceExport; it returns 0 if
+// the annotation is absent, and an error if the value is not a positive duration.
+func extractEndpointWarmup(svcExport *fleetnetv1alpha1.ServiceExport) (time.Duration, error) {
+	val, ok := svcExport.Annotations[objectmeta.ServiceExportAnnotationEndpointWarmup]
+	if !ok {
+		return 0, nil
+	}
+	warmup, err := time.ParseDuration(val)
+	if err != nil || warmup <= 0 {
+		return 0, fmt.Errorf("the value of annotation %s must be a positive duration, got %q", objectmeta.ServiceExportAnnotationEndpointWarmup, val)
+	}
+	return warmup, nil
+}
+
+// endpointKey returns the key which identifies an endpoint among the endpoints of an EndpointSlice.
+func endpointKey(endpoint *fleetnetv1alpha1.Endpoint) string {
+	return strings.Join(endpoint.Addresses, ",")
+}
+
+// filterWarmedUpEndpoints keeps the serving endpoints which are either already advertised or have completed their
+// warmup, i.e. have stayed ready for the warmup period since the time recorded in readySince; a terminating endpoint
+// is kept only if it is already advertised.
+//
+// It returns the endpoints to advertise, the ready-since times of the endpoints still warming up (an endpoint seen
+// for the first time starts its warmup now), and how long until the next of them completes its warmup (0 if none).
+// An endpoint that is no longer ready drops out of the returned times, so that its warmup restarts from scratch
+// once it becomes ready again.
+func filterWarmedUpEndpoints(serving, advertised []fleetnetv1alpha1.Endpoint, readySince map[string]time.Time,
+	warmup time.Duration, now time.Time) ([]fleetnetv1alpha1.Endpoint, map[string]time.Time, time.Duration) {
+	isAdvertised := make(map[string]bool, len(advertised))
+	for i := range advertised {
+		isAdvertised[endpointKey(&advertised[i])] = true
+	}
+
+	kept := []fleetnetv1alpha1.Endpoint{}
+	warmingUp := map[string]time.Time{}
+	var nextWarmedUp time.Duration
+	for i := range serving {
+		key := endpointKey(&serving[i])
+		if isAdvertised[key] {
+			kept = append(kept, serving[i])
+			continue
+		}
+		if !serving[i].IsReady() {
+			continue
+		}
+		since, ok := readySince[key]
+		if !ok {
+			since = now
+		}
+		remaining := warmup - now.Sub(since)
+		if remaining <= 0 {
+			kept = append(kept, serving[i])
+			continue
+		}
+		warmingUp[key] = since
+		if nextWarmedUp == 0 || remaining < nextWarmedUp {
+			nextWarmedUp = remaining
+		}
+	}
+	return kept, warmingUp, nextWarmedUp
+}
+
+// routeThroughGateway returns the endpoints and the ports of the east-west gateway an EndpointSlice is exported
+// through, if its Service is routed through the gateway: a single endpoint at the address of the gateway, with the
+// ports of the gateway assigned to the Service ports; the ports not assigned are dropped. It returns nil if no port
+// of the Service is assigned.
+//
+// No endpoints of the gateway are returned if the gateway has no address yet, or its address is of a different
+// family from the EndpointSlice, as the Service cannot be reached through the gateway then.
+func routeThroughGateway(gateway *eastwestgateway.Gateway, endpointSlice *discoveryv1.EndpointSlice,
+	endpoints []fleetnetv1alpha1.Endpoint, ports []discoveryv1.EndpointPort) *fleetnetv1alpha1.GatewayEndpoints {
+	svcName := endpointSlice.Labels[discoveryv1.LabelServiceName]
+	if !gateway.Routes(endpointSlice.Namespace, svcName) {
+		return nil
+	}
+
+	res := &fleetnetv1alpha1.GatewayEndpoints{
+		Endpoints: []fleetnetv1alpha1.Endpoint{},
+		Ports:     []discoveryv1.EndpointPort{},
+	}
+	for _, port := range ports {
+		gatewayPort, ok := gateway.Port(endpointSlice.Namespace, svcName, ptr.Deref(port.Name, ""))
+		if !ok {
+			continue
+		}
+		port.Port = ptr.To(gatewayPort)
+		res.Ports = append(res.Ports, port)
+	}
+	if gateway.Address == "" || gateway.AddressType() != endpointSlice.AddressType {
+		return res
+	}
+
+	// The gateway forwards the connections to the ClusterIP of the Service, which is ready as long as any of
+	// the endpoints is; it keeps serving while only the terminating endpoints are left.
+	ready, serving := false, false
+	for i := range endpoints {
+		ready = ready || endpoints[i].IsReady()
+		serving = serving || endpoints[i].IsServing()
+	}
+	switch {
+	case ready:
+		res.Endpoints = []fleetnetv1alpha1.Endpoint{{
+			Addresses:  []string{gateway.Address},
+			Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true), Serving: ptr.To(true)},
+		}}
+	case serving:
+		res.Endpoints = []fleetnetv1alpha1.Endpoint{{
+			Addresses:  []string{gateway.Address},
+			Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(false), Serving: ptr.To(true), Terminating: ptr.To(true)},
+		}}
+	}
+	return res
+}
+
+// exposeThroughPrivateLink returns the Private Link Service an EndpointSlice is exported through, if the
+// ServiceExport of its Service is annotated with one: the ports of the load balancer, i.e. the Service ports, of the
+// exported ports; the ports the Service does not expose are dropped. It returns nil if the ServiceExport is not
+// annotated.
+func exposeThroughPrivateLink(svcExport *fleetnetv1alpha1.ServiceExport, svc *corev1.Service,
+	ports []discoveryv1.EndpointPort) *fleetnetv1alpha1.PrivateLinkEndpoints {
+	id := svcExport.Annotations[objectmeta.ServiceExportAnnotationPrivateLinkServiceID]
+	if id == "" {
+		return nil
+	}
+
+	res := &fleetnetv1alpha1.PrivateLinkEndpoints{
+		ServiceID: id,
+		Ports:     []discoveryv1.EndpointPort{},
+	}
+	for _, port := range ports {
+		for _, svcPort := range svc.Spec.Ports {
+			if svcPort.Name != ptr.Deref(port.Name, "") || svcPort.Protocol != ptr.Deref(port.Protocol, corev1.ProtocolTCP) {
+				continue
+			}
+			port.Port = ptr.To(svcPort.Port)
+			res.Ports = append(res.Ports, port)
+			break
+		}
+	}
+	return res
+}
+
+// exportThroughLoadBalancer returns the endpoints and the ports an EndpointSlice is exported with in the
+// LoadBalancer connectivity mode: a single endpoint at the ingress IP of the internal load balancer of the Service,
+// of the same family as the EndpointSlice, with the load balancer ports, i.e. the Service ports, of the exported
+// ports; the ports the load balancer does not expose are dropped.
+//
+// No endpoints are returned if the load balancer has no ingress IP of the family yet, as the Service cannot be
+// reached through the load balancer then.
+func exportThroughLoadBalancer(lbSvc *corev1.Service, endpointSlice *discoveryv1.EndpointSlice,
+	endpoints []fleetnetv1alpha1.Endpoint, ports []discoveryv1.EndpointPort) ([]fleetnetv1alpha1.Endpoint, []discoveryv1.EndpointPort) {
+	lbPorts := []discoveryv1.EndpointPort{}
+	for _, port := range ports {
+		for _, svcPort := range lbSvc.Spec.Ports {
+			if svcPort.Name != ptr.Deref(port.Name, "") || svcPort.Protocol != ptr.Deref(port.Protocol, corev1.ProtocolTCP) {
+				continue
+			}
+			port.Port = ptr.To(svcPort.Port)
+			lbPorts = append(lbPorts, port)
+			break
+		}
+	}
+
+	addressFamily, _ := ipfamily.OfAddressType(endpointSlice.AddressType)
+	var ip string
+	for _, ingress := range lbSvc.Status.LoadBalancer.Ingress {
+		if family, ok := ipfamily.OfAddress(ingress.IP); ok && family == addressFamily {
+			ip = ingress.IP
+			break
+		}
+	}
+	if ip == "" {
+		return []fleetnetv1alpha1.Endpoint{}, lbPorts
+	}
+
+	// The load balancer forwards the connections to the endpoints of the Service, which is ready as long as any of
+	// the endpoints is; it keeps serving while only the terminating endpoints are left.
+	ready, serving := false, false
+	for i := range endpoints {
+		ready = ready || endpoints[i].IsReady()
+		serving = serving || endpoints[i].IsServing()
+	}
+	switch {
+	case ready:
+		return []fleetnetv1alpha1.Endpoint{{
+			Addresses:  []string{ip},
+			Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true), Serving: ptr.To(true)},
+		}}, lbPorts
+	case serving:
+		return []fleetnetv1alpha1.Endpoint{{
+			Addresses:  []string{ip},
+			Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(false), Serving: ptr.To(true), Terminating: ptr.To(true)},
+		}}, lbPorts
+	}
+	return []fleetnetv1alpha1.Endpoint{}, lbPorts
+}
@@ -11,14 +11,17 @@ import (
 	"context"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	discoveryv1 "k8s.io/api/discovery/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
 	"go.goms.io/fleet-networking/pkg/common/objectmeta"
 )
 
@@ -29,20 +32,30 @@ const (
 type Reconciler struct {
 	MemberClient client.Client
 	HubClient    client.Client
+
+	// AggregateEndpointSliceExports tells if the EndpointSlice controller aggregates the EndpointSliceExports of each
+	// Service; the aggregated EndpointSliceExports are left over otherwise.
+	AggregateEndpointSliceExports bool
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
 }
 
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=endpointsliceexports,verbs=get;list;watch;delete
 //+kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch
 
 // Reconcile verifies if an EndpointSliceExport in the hub cluster matches with a exported EndpointSlice from
 // the current member cluster, and will clean up EndpointSliceExports that fail to match.
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	endpointSliceExportRef := klog.KRef(req.Namespace, req.Name)
+	logger := klog.FromContext(ctx).WithValues("endpointSliceExport", endpointSliceExportRef)
+	ctx = klog.NewContext(ctx, logger)
 	startTime := time.Now()
-	klog.V(2).InfoS("Reconciliation starts", "endpointSliceExport", endpointSliceExportRef)
+	logger.V(2).Info("Reconciliation starts")
 	defer func() {
 		latency := time.Since(startTime).Seconds()
-		klog.V(2).InfoS("Reconciliation ends", "endpointSliceExport", endpointSliceExportRef, "latency", latency)
+		logger.V(2).Info("Reconciliation ends", "latency", latency)
 	}()
 
 	// Retrieve the EndpointSliceExport object.
@@ -52,13 +65,17 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		// EndpointSliceExport is deleted before the controller gets a chance to reconcile it;
 		// this requires no action to take on this controller's end.
 		if errors.IsNotFound(err) {
-			klog.V(4).InfoS("Ignoring NotFound endpointSliceExport", "endpointSliceExport", endpointSliceExportRef)
+			logger.V(4).Info("Ignoring NotFound endpointSliceExport")
 			return ctrl.Result{}, nil
 		}
-		klog.ErrorS(err, "Failed to get endpointSliceExport", "endpointSliceExport", endpointSliceExportRef)
+		logger.Error(err, "Failed to get endpointSliceExport")
 		return ctrl.Result{}, err
 	}
 
+	if endpointSliceExport.IsAggregated() {
+		return r.reconcileAggregated(ctx, endpointSliceExport)
+	}
+
 	// Check if the EndpointSliceExport refers to an existing EndpointSlice.
 	endpointSlice := &discoveryv1.EndpointSlice{}
 	endpointSliceKey := types.NamespacedName{
@@ -70,15 +87,13 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	switch {
 	case errors.IsNotFound(err):
 		// The matching EndpointSlice is not found; the EndpointSliceExport should be deleted.
-		klog.V(2).InfoS("Referred endpointSlice is not found; delete the endpointSliceExport",
-			"endpointSliceExport", endpointSliceExportRef,
+		logger.V(2).Info("Referred endpointSlice is not found; delete the endpointSliceExport",
 			"endpointSlice", endpointSliceRef,
 		)
 		return r.deleteEndpointSliceExport(ctx, endpointSliceExport)
 	case err != nil:
 		// An unexpected error has occurred.
-		klog.ErrorS(err, "Failed to get endpointSlice",
-			"endpointSliceExport", endpointSliceExportRef,
+		logger.Error(err, "Failed to get endpointSlice",
 			"endpointSlice", endpointSliceRef)
 		return ctrl.Result{}, err
 	}
@@ -98,13 +113,42 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	return ctrl.Result{RequeueAfter: endpointSliceExportRetryInterval}, nil
 }
 
+// reconcileAggregated verifies if an aggregated EndpointSliceExport, which references a Service instead of an
+// EndpointSlice, matches with an existing Service while the exports are aggregated, and cleans it up otherwise.
+func (r *Reconciler) reconcileAggregated(ctx context.Context, endpointSliceExport *fleetnetv1alpha1.EndpointSliceExport) (ctrl.Result, error) {
+	logger := klog.FromContext(ctx)
+	if !r.AggregateEndpointSliceExports {
+		logger.V(2).Info("Endpoint slice exports are no longer aggregated; delete the aggregated endpointSliceExport")
+		return r.deleteEndpointSliceExport(ctx, endpointSliceExport)
+	}
+
+	svc := &corev1.Service{}
+	svcKey := types.NamespacedName{
+		Namespace: endpointSliceExport.Spec.EndpointSliceReference.Namespace,
+		Name:      endpointSliceExport.Spec.EndpointSliceReference.Name,
+	}
+	svcRef := klog.KRef(svcKey.Namespace, svcKey.Name)
+	err := r.MemberClient.Get(ctx, svcKey, svc)
+	switch {
+	case errors.IsNotFound(err) || (err == nil && svc.UID != endpointSliceExport.Spec.EndpointSliceReference.UID):
+		// The Service is gone, or has been re-created with the same name.
+		logger.V(2).Info("Referred service is not found; delete the endpointSliceExport", "service", svcRef)
+		return r.deleteEndpointSliceExport(ctx, endpointSliceExport)
+	case err != nil:
+		logger.Error(err, "Failed to get service", "service", svcRef)
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: endpointSliceExportRetryInterval}, nil
+}
+
 // SetupWithManager builds a controller with Reconciler and sets it up with a controller manager.
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		// The EndpointSliceExport controller watches over EndpointSliceExport objects.
 		// TO-DO (chenyu1): use predicates to filter out some events.
 		For(&fleetnetv1alpha1.EndpointSliceExport{}).
-		Complete(r)
+		WithOptions(r.ControllerOptions).
+		Complete(metrics.InstrumentReconciler("endpointsliceexport", r))
 }
 
 // deleteEndpointSliceExport deletes an EndpointSliceExport from the hub cluster.
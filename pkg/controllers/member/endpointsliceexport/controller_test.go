@@ -11,11 +11,13 @@ import (
 	"os"
 	"testing"
 
+	corev1 "k8s.io/api/core/v1"
 	discoveryv1 "k8s.io/api/discovery/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
@@ -163,3 +165,90 @@ func TestDeleteEndpointSliceExport(t *testing.T) {
 		})
 	}
 }
+
+// TestReconcileAggregated tests the *Reconciler.reconcileAggregated method.
+func TestReconcileAggregated(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: memberUserNS,
+			Name:      "app",
+			UID:       "svc-uid",
+		},
+	}
+	testCases := []struct {
+		name       string
+		aggregate  bool
+		svcUID     types.UID
+		svc        *corev1.Service
+		wantExists bool
+	}{
+		{
+			name:       "should keep endpoint slice export of the service",
+			aggregate:  true,
+			svcUID:     "svc-uid",
+			svc:        svc,
+			wantExists: true,
+		},
+		{
+			name:      "should delete endpoint slice export of a re-created service",
+			aggregate: true,
+			svcUID:    "old-svc-uid",
+			svc:       svc,
+		},
+		{
+			name:      "should delete endpoint slice export of a deleted service",
+			aggregate: true,
+			svcUID:    "svc-uid",
+		},
+		{
+			name:   "should delete endpoint slice export when the exports are no longer aggregated",
+			svcUID: "svc-uid",
+			svc:    svc,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			endpointSliceExport := &fleetnetv1alpha1.EndpointSliceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: hubNSForMember,
+					Name:      endpointSliceExportName,
+				},
+				Spec: fleetnetv1alpha1.EndpointSliceExportSpec{
+					EndpointSliceReference: fleetnetv1alpha1.ExportedObjectReference{
+						Kind:      "Service",
+						Namespace: memberUserNS,
+						Name:      "app",
+						UID:       tc.svcUID,
+					},
+				},
+			}
+			memberClientBuilder := fake.NewClientBuilder().WithScheme(scheme.Scheme)
+			if tc.svc != nil {
+				memberClientBuilder = memberClientBuilder.WithObjects(tc.svc)
+			}
+			fakeHubClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(endpointSliceExport).
+				Build()
+			reconciler := &Reconciler{
+				MemberClient:                  memberClientBuilder.Build(),
+				HubClient:                     fakeHubClient,
+				AggregateEndpointSliceExports: tc.aggregate,
+			}
+			ctx := context.Background()
+
+			if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: endpointSliceExportKey}); err != nil {
+				t.Fatalf("Reconcile(), got %v, want no error", err)
+			}
+
+			err := fakeHubClient.Get(ctx, endpointSliceExportKey, &fleetnetv1alpha1.EndpointSliceExport{})
+			switch {
+			case tc.wantExists && err != nil:
+				t.Errorf("endpoint slice export Get(%+v), got %v, want no error", endpointSliceExportKey, err)
+			case !tc.wantExists && !errors.IsNotFound(err):
+				t.Errorf("endpoint slice export Get(%+v), got %v, want not found error", endpointSliceExportKey, err)
+			}
+		})
+	}
+}
@@ -10,24 +10,38 @@ package endpointsliceimport
 import (
 	"context"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	corev1 "k8s.io/api/core/v1"
 	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/importpolicy"
+	"go.goms.io/fleet-networking/pkg/common/ipfamily"
 	"go.goms.io/fleet-networking/pkg/common/metrics"
 	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/tracing"
+	"go.goms.io/fleet-networking/pkg/controllers/member/tunnel"
 )
 
 const (
@@ -67,12 +81,35 @@ var (
 			"is_first_import",
 		},
 	)
+
+	// endpointSliceHubImportDuration is a Prometheus histogram metric bundle that measures the time it takes for
+	// Fleet networking controllers to import an EndpointSlice distributed by the hub cluster into its destination
+	// cluster. The stopwatch starts when the hub cluster distributes a generation of the EndpointSlice (as an
+	// EndpointSliceImport), and stops when the EndpointSlice is successfully imported.
+	endpointSliceHubImportDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metrics.MetricsNamespace,
+			Subsystem: metrics.MetricsSubsystem,
+			Name:      "endpointslice_hub_import_duration_milliseconds",
+			Help:      "The duration of an endpointslice import from the hub cluster",
+			Buckets:   metrics.ExportDurationMillisecondsBuckets,
+		},
+		[]string{
+			// The ID of the origin cluster, which exports the Service and the EndpointSlice.
+			"origin_cluster_id",
+			// The ID of the destination cluster, which imports the Service and the EndpointSlice.
+			"destination_cluster_id",
+		},
+	)
 )
 
 func init() {
 	// Register endpointSliceExportImportDuration (endpointslice_export_import_duration_milliseconds) metric
 	// with the controller runtime global metrics registry.
 	ctrlmetrics.Registry.MustRegister(endpointSliceExportImportDuration)
+	// Register endpointSliceHubImportDuration (endpointslice_hub_import_duration_milliseconds) metric
+	// with the controller runtime global metrics registry.
+	ctrlmetrics.Registry.MustRegister(endpointSliceHubImportDuration)
 }
 
 // Reconciler reconciles an EndpointSliceImport.
@@ -82,21 +119,46 @@ type Reconciler struct {
 	HubClient       client.Client
 	// The namespace reserved for fleet resources in the member cluster.
 	FleetSystemNamespace string
+	// SupportedIPFamilies are the IP families the member cluster supports; endpoints with addresses of other
+	// IP families are not imported. An empty list means all IP families are supported.
+	SupportedIPFamilies []corev1.IPFamily
+	// PreferSameRegion, if set, hints the imported endpoints in the region of the member cluster for all its zones,
+	// so that the endpoints in other regions are only consumed when no endpoints are left in the region.
+	PreferSameRegion bool
+	// EnforceImportPolicies, if set, leaves the EndpointSlices exported from the clusters denied by the ImportPolicies
+	// of the member cluster unimported; the ImportPolicy CRD must be installed in the member cluster.
+	EnforceImportPolicies bool
+	// HubName is the name of the additional hub cluster the controller imports the EndpointSlices from, or empty for
+	// the primary hub cluster; the EndpointSlices imported from an additional hub cluster are prefixed with its name,
+	// so that they never collide with the ones of another hub cluster.
+	HubName string
+	// Tunnel, if set, reads the tunnel addresses of the other member clusters; the EndpointSlices exported through
+	// the east-west gateway of a member cluster with a tunnel peer are imported at its tunnel address once it is
+	// routed from the pod network, and through the gateway as without the tunnel until then.
+	Tunnel *tunnel.Reader
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
 }
 
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=endpointsliceimports,verbs=get;list;watch;update;patch
 //+kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;create;update;patch;delete
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=multiclusterservices,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports,verbs=get
 //+kubebuilder:rbac:groups="",resources=services,verbs=get;list
+//+kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=importpolicies,verbs=get;list;watch
 
 // Reconcile imports an EndpointSlice from hub cluster.
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	endpointSliceImportRef := klog.KRef(req.Namespace, req.Name)
+	logger := klog.FromContext(ctx).WithValues("endpointSliceImport", endpointSliceImportRef)
+	ctx = klog.NewContext(ctx, logger)
 	startTime := time.Now()
-	klog.V(2).InfoS("Reconciliation starts", "endpointSliceImport", endpointSliceImportRef)
+	logger.V(2).Info("Reconciliation starts")
 	defer func() {
 		latency := time.Since(startTime).Milliseconds()
-		klog.V(2).InfoS("Reconciliation ends", "endpointSliceImport", endpointSliceImportRef, "latency", latency)
+		logger.V(2).Info("Reconciliation ends", "latency", latency)
 	}()
 
 	// Retrieve the EndpointSliceImport.
@@ -106,30 +168,68 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		// EndpointSliceImport is deleted before the controller gets a chance to reconcile it, which
 		// requires no action to take on this controller's end.
 		if errors.IsNotFound(err) {
-			klog.V(4).InfoS("Ignoring NotFound endpointSliceImport", "endpointSliceImport", endpointSliceImportRef)
+			logger.V(4).Info("Ignoring NotFound endpointSliceImport")
 			return ctrl.Result{}, nil
 		}
-		klog.ErrorS(err, "Failed to get endpoint slice import", "endpointSliceImport", endpointSliceImportRef)
+		logger.Error(err, "Failed to get endpoint slice import")
 		return ctrl.Result{}, err
 	}
 
+	// Continue the trace of the export, so that the import of the EndpointSlice completes its journey.
+	ctx, span := tracing.Start(ctx, "endpointsliceimport.Import", endpointSliceImport.Annotations)
+	defer span.End()
+
 	// Check if the EndpointSliceImport has been deleted and needs cleanup (unimport EndpointSlice).
 	// An EndpointSliceImport needs cleanup when it has the EndpointSliceImport cleanup finalizer added;
 	// the absence of this finalizer guarantees that the EndpointSliceImport has never been imported.
-	endpointSliceRef := klog.KRef(r.FleetSystemNamespace, req.Name)
+	endpointSliceRef := klog.KRef(r.FleetSystemNamespace, r.endpointSliceName(req.Name))
 	if endpointSliceImport.DeletionTimestamp != nil {
-		klog.V(2).InfoS("EndpointSliceImport is deleted; unimport EndpointSlice",
-			"endpointSliceImport", endpointSliceImportRef,
+		logger.V(2).Info("EndpointSliceImport is deleted; unimport EndpointSlice",
 			"endpointSlice", endpointSliceRef)
 		if err := r.unimportEndpointSlice(ctx, endpointSliceImport); err != nil {
-			klog.ErrorS(err, "Failed to unimport EndpointSlice",
-				"endpointSliceImport", endpointSliceImportRef,
+			logger.Error(err, "Failed to unimport EndpointSlice",
 				"endpointSlice", endpointSliceRef)
 			return ctrl.Result{}, err
 		}
 		return ctrl.Result{}, nil
 	}
 
+	// Skip the EndpointSlice if its IP family is not supported by the member cluster; an EndpointSlice imported
+	// earlier, e.g. before the supported IP families are changed, is unimported.
+	if !isAddressTypeSupported(endpointSliceImport.Spec.AddressType, r.SupportedIPFamilies) {
+		logger.V(2).Info("EndpointSlice is of an unsupported IP family; it will not be imported",
+			"addressType", endpointSliceImport.Spec.AddressType,
+			"supportedIPFamilies", r.SupportedIPFamilies)
+		if err := r.unimportEndpointSlice(ctx, endpointSliceImport); err != nil {
+			logger.Error(err, "Failed to unimport EndpointSlice",
+				"endpointSlice", endpointSliceRef)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// Skip the EndpointSlice if its origin cluster is denied by the import policies; an EndpointSlice imported
+	// earlier is unimported. The controller does not watch the ImportPolicies, so that changes of the policies are
+	// picked up by the periodic resyncs.
+	if r.EnforceImportPolicies {
+		originClusterID := endpointSliceImport.Spec.EndpointSliceReference.ClusterID
+		deniedReason, err := importpolicy.CheckCluster(ctx, r.MemberClient, originClusterID)
+		if err != nil {
+			logger.Error(err, "Failed to evaluate the import policies")
+			return ctrl.Result{}, err
+		}
+		if deniedReason != "" {
+			logger.V(2).Info("EndpointSlice is exported from a denied cluster; it will not be imported",
+				"originClusterID", originClusterID, "reason", deniedReason)
+			if err := r.unimportEndpointSlice(ctx, endpointSliceImport); err != nil {
+				logger.Error(err, "Failed to unimport EndpointSlice",
+					"endpointSlice", endpointSliceRef)
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{}, nil
+		}
+	}
+
 	// Import the EndpointSlice, or update an imported EndpointSlice.
 
 	// Inquire the corresponding MCS to find out which Service the imported EndpointSlice should associate with.
@@ -145,20 +245,9 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	switch {
 	case err != nil:
 		// An unexpected error occurs.
-		klog.ErrorS(err, "Failed to list MCS",
-			"serviceImport", klog.KRef(ownerSvcNS, ownerSvcName),
-			"endpointSliceImport", endpointSliceImportRef)
+		logger.Error(err, "Failed to list MCS",
+			"serviceImport", klog.KRef(ownerSvcNS, ownerSvcName))
 		return ctrl.Result{}, err
-	case len(multiClusterSvcList.Items) == 0:
-		// No matching MCS is found; typically this will never happen as the hub cluster will only distribute
-		// EndpointSlices to member clusters that have requested them with an MCS. It could be that the controller
-		// sees an in-between state where a Service is imported and then immediately unimported, and the hub cluster
-		// does not get to retract distributed EndpointSlices in time. In this case the controller will skip
-		// importing the EndpointSlice.
-		klog.V(2).InfoS("No matching MCS is found; EndpointSlice will not be imported",
-			"serviceImport", klog.KRef(ownerSvcNS, ownerSvcName),
-			"endpointSliceImport", endpointSliceImportRef)
-		return ctrl.Result{}, nil
 	}
 
 	// Scan all matching MCSes: inspect each MCS for the derived Service label; if one is present, it signals
@@ -168,6 +257,26 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	// one member cluster or from multiple clusters from the fleet, attempt to import the same Service, it is
 	// guaranteed that only one will succeed.
 	derivedSvcName := scanForDerivedServiceName(multiClusterSvcList)
+	if len(multiClusterSvcList.Items) == 0 {
+		// No matching MCS is found; the Service may be imported with a ServiceImport alone, whose derived Service
+		// is created by the derivedservice controller.
+		svcImport := &fleetnetv1alpha1.ServiceImport{}
+		err := r.MemberClient.Get(ctx, types.NamespacedName{Namespace: ownerSvcNS, Name: ownerSvcName}, svcImport)
+		switch {
+		case errors.IsNotFound(err) || (err == nil && svcImport.DeletionTimestamp != nil):
+			// It could be that the controller sees an in-between state where a Service is imported and then
+			// immediately unimported, and the hub cluster does not get to retract distributed EndpointSlices in
+			// time. In this case the controller will skip importing the EndpointSlice.
+			logger.V(2).Info("No matching MCS or serviceImport is found; EndpointSlice will not be imported",
+				"serviceImport", klog.KRef(ownerSvcNS, ownerSvcName))
+			return ctrl.Result{}, nil
+		case err != nil:
+			logger.Error(err, "Failed to get serviceImport",
+				"serviceImport", klog.KRef(ownerSvcNS, ownerSvcName))
+			return ctrl.Result{}, err
+		}
+		derivedSvcName = svcImport.Labels[objectmeta.ServiceImportLabelDerivedService]
+	}
 
 	// Verify if the found derived Service label points to a Service that the controller can associate the
 	// EndpointSlice with. In most cases this check will always pass as the hub cluster will only distribute
@@ -179,18 +288,16 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	// * a connectivity issue has kept the member cluster out of sync with the hub cluster, with the member cluster
 	//   not knowing that a Service has been successfully claimed by itself; or
 	// * the controller for processing MCSes lags, and has not created the derived Service in time.
-	isValid, err := r.isDerivedServiceValid(ctx, derivedSvcName)
+	derivedSvc, err := r.getDerivedService(ctx, derivedSvcName)
 	switch {
 	case err != nil:
-		klog.ErrorS(err, "Failed to check if derived Service is valid",
-			"derivedServiceName", derivedSvcName,
-			"endpointSliceImport", endpointSliceImportRef)
+		logger.Error(err, "Failed to check if derived Service is valid",
+			"derivedServiceName", derivedSvcName)
 		return ctrl.Result{}, err
-	case !isValid:
+	case derivedSvc == nil:
 		// Retry importing the EndpointSlice at a later time if no valid derived Service can be found.
-		klog.V(2).InfoS("No valid derived Service; will retry importing EndpointSlice later",
-			"derivedServiceName", derivedSvcName,
-			"endpointSliceImport", endpointSliceImportRef)
+		logger.V(2).Info("No valid derived Service; will retry importing EndpointSlice later",
+			"derivedServiceName", derivedSvcName)
 		return ctrl.Result{RequeueAfter: endpointSliceImportRetryInterval}, nil
 	}
 
@@ -206,49 +313,126 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 
 	// Add the cleanup finalizer (if one has not been added earlier); this must happen before
 	// the EndpointSlice is imported.
-	klog.V(2).InfoS("Add cleanup finalizer to EndpointSliceImport", "endpointSliceImport", endpointSliceImportRef)
+	logger.V(2).Info("Add cleanup finalizer to EndpointSliceImport")
 	if err := r.addEndpointSliceImportCleanupFinalizer(ctx, endpointSliceImport); err != nil {
-		klog.ErrorS(err, "Failed to add cleanup finalizer to EndpointSliceImport", "endpointSliceImport", endpointSliceImportRef)
+		logger.Error(err, "Failed to add cleanup finalizer to EndpointSliceImport")
 		return ctrl.Result{}, err
 	}
 
+	var topology *localTopology
+	if r.PreferSameRegion {
+		if topology, err = r.getLocalTopology(ctx); err != nil {
+			logger.Error(err, "Failed to get the topology of the member cluster")
+			return ctrl.Result{}, err
+		}
+	}
+
+	share, err := r.getEndpointShare(ctx, endpointSliceImport)
+	if err != nil {
+		logger.Error(err, "Failed to get the share of the endpoints to import")
+		return ctrl.Result{}, err
+	}
+
+	tunnelAddress, requeueAfter := "", time.Duration(0)
+	if r.Tunnel != nil {
+		addresses, err := r.Tunnel.Addresses(ctx)
+		if err != nil {
+			logger.Error(err, "Failed to get the tunnel addresses of the member clusters")
+			return ctrl.Result{}, err
+		}
+		tunnelAddress = addresses[endpointSliceImport.Spec.EndpointSliceReference.ClusterID]
+		if tunnelAddress != "" && !r.Tunnel.Routed(ctx, tunnelAddress) {
+			// The tunnel is not up yet, or the tunnel network is not routed to the east-west gateway; the address is
+			// probed again after the probe interval.
+			logger.V(2).Info("Tunnel address is not routed from the pod network yet, importing through the gateway",
+				"clusterID", endpointSliceImport.Spec.EndpointSliceReference.ClusterID, "tunnelAddress", tunnelAddress)
+			tunnelAddress = ""
+			requeueAfter = r.Tunnel.ProbeInterval()
+		}
+	}
+
 	// Associate the EndpointSlice with the Service.
-	klog.V(2).InfoS("Import the EndpointSlice", "endpointSlice", endpointSliceRef)
+	logger.V(2).Info("Import the EndpointSlice", "endpointSlice", endpointSliceRef)
 	endpointSlice := &discoveryv1.EndpointSlice{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: r.FleetSystemNamespace,
-			Name:      endpointSliceImport.Name,
+			Name:      r.endpointSliceName(endpointSliceImport.Name),
 		},
 	}
 	if op, err := controllerutil.CreateOrUpdate(ctx, r.MemberClient, endpointSlice, func() error {
-		formatEndpointSliceFromImport(endpointSlice, derivedSvcName, endpointSliceImport)
+		formatEndpointSliceFromImport(endpointSlice, derivedSvc, endpointSliceImport, r.SupportedIPFamilies, topology, tunnelAddress)
+		if share != nil {
+			endpointSlice.Endpoints = selectWeightedEndpoints(endpointSlice.Endpoints, *share)
+		}
 		return nil
 	}); err != nil {
-		klog.ErrorS(err, "Failed to create/update EndpointSlice",
+		logger.Error(err, "Failed to create/update EndpointSlice",
 			"endpointSlice", endpointSliceRef,
-			"op", op,
-			"endpointSliceImport", endpointSliceImportRef)
+			"op", op)
 		return ctrl.Result{}, err
 	}
 
 	// Observe a data point for the EndpointSliceExportImportDuration metric.
 	if err := r.observeMetrics(ctx, endpointSliceImport, time.Now()); err != nil {
-		klog.Warning("Failed to observe metrics", "error", err, "endpointSliceImport", endpointSliceImportRef)
+		klog.Warning("Failed to observe metrics", "error", err)
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// getEndpointShare returns the share of the endpoints to import from the cluster exporting the EndpointSlice, as
+// weighted by the cluster weights annotation of the ServiceImport; it returns nil if all the endpoints are imported.
+//
+// The controller only watches EndpointSliceImports in the hub cluster, so that changes of the weights, or of the
+// numbers of ready endpoints in the ServiceImport status, are picked up by the periodic resyncs.
+func (r *Reconciler) getEndpointShare(ctx context.Context, endpointSliceImport *fleetnetv1alpha1.EndpointSliceImport) (*endpointShare, error) {
+	svcImportKey := types.NamespacedName{
+		Namespace: endpointSliceImport.Spec.OwnerServiceReference.Namespace,
+		Name:      endpointSliceImport.Spec.OwnerServiceReference.Name,
+	}
+	svcImport := &fleetnetv1alpha1.ServiceImport{}
+	if err := r.MemberClient.Get(ctx, svcImportKey, svcImport); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	val, ok := svcImport.Annotations[objectmeta.ServiceImportAnnotationClusterWeights]
+	if !ok {
+		return nil, nil
+	}
+	weights, err := parseClusterWeights(val)
+	if err != nil {
+		// An invalid annotation is ignored, as retrying cannot fix it.
+		klog.ErrorS(err, "Ignoring invalid cluster weights of serviceImport", "serviceImport", klog.KObj(svcImport))
+		return nil, nil
+	}
+	share, ok := clusterEndpointShares(weights, svcImport.Status.Clusters)[endpointSliceImport.Spec.EndpointSliceReference.ClusterID]
+	if !ok {
+		return nil, nil
+	}
+	return &share, nil
 }
 
 // SetupWithManager builds a controller with Reconciler and sets it up with a controller manager.
 func (r *Reconciler) SetupWithManager(ctx context.Context, memberCtrlMgr, hubCtrlMgr ctrl.Manager) error {
+	// Each hub cluster has a controller of its own; the index is shared with the controller of the primary hub cluster.
+	if r.HubName != "" {
+		return ctrl.NewControllerManagedBy(hubCtrlMgr).
+			Named("endpointsliceimport-" + r.HubName).
+			For(&fleetnetv1alpha1.EndpointSliceImport{}).
+			WithOptions(r.ControllerOptions).
+			Complete(metrics.InstrumentReconciler("endpointsliceimport-"+r.HubName, r))
+	}
+
 	// Set up an index for efficient MCS lookup **on the controller manager for member cluster controllers**.
 	indexerFunc := func(o client.Object) []string {
 		multiClusterSvc, ok := o.(*fleetnetv1alpha1.MultiClusterService)
 		if !ok {
 			return []string{}
 		}
-		return []string{multiClusterSvc.Spec.ServiceImport.Name}
+		return []string{multiClusterSvc.ServiceImportName()}
 	}
 	if err := memberCtrlMgr.GetFieldIndexer().IndexField(ctx,
 		&fleetnetv1alpha1.MultiClusterService{},
@@ -259,10 +443,60 @@ func (r *Reconciler) SetupWithManager(ctx context.Context, memberCtrlMgr, hubCtr
 	}
 
 	// The controller itself is managed by the controller manager for hub cluster controllers.
-	return ctrl.NewControllerManagedBy(hubCtrlMgr).
+	b := ctrl.NewControllerManagedBy(hubCtrlMgr).
 		// The EndpointSliceImport controller watches over EndpointSliceImport objects.
-		For(&fleetnetv1alpha1.EndpointSliceImport{}).
-		Complete(r)
+		For(&fleetnetv1alpha1.EndpointSliceImport{})
+	if r.Tunnel != nil {
+		// All the EndpointSlices are imported again when the tunnel peers distributed by the hub cluster change.
+		b = b.Watches(&fleetnetv1alpha1.InternalMemberNetworkStatus{},
+			handler.EnqueueRequestsFromMapFunc(r.enqueueEndpointSliceImports),
+			builder.WithPredicates(tunnelPeersChanged()))
+	}
+	return b.WithOptions(r.ControllerOptions).
+		Complete(metrics.InstrumentReconciler("endpointsliceimport", r))
+}
+
+// enqueueEndpointSliceImports enqueues all the EndpointSliceImports of the member cluster.
+func (r *Reconciler) enqueueEndpointSliceImports(ctx context.Context, o client.Object) []reconcile.Request {
+	if o.GetName() != r.MemberClusterID {
+		return []reconcile.Request{}
+	}
+	endpointSliceImportList := &fleetnetv1alpha1.EndpointSliceImportList{}
+	if err := r.HubClient.List(ctx, endpointSliceImportList, client.InNamespace(o.GetNamespace())); err != nil {
+		klog.ErrorS(err, "Failed to list endpointSliceImports", "namespace", o.GetNamespace())
+		return []reconcile.Request{}
+	}
+	requests := make([]reconcile.Request, 0, len(endpointSliceImportList.Items))
+	for i := range endpointSliceImportList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&endpointSliceImportList.Items[i])})
+	}
+	return requests
+}
+
+// tunnelPeersChanged filters the updates of an InternalMemberNetworkStatus to the ones changing its tunnel peers, as
+// it is updated with every heartbeat.
+func tunnelPeersChanged() predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldStatus, ok := e.ObjectOld.(*fleetnetv1alpha1.InternalMemberNetworkStatus)
+			if !ok {
+				return false
+			}
+			newStatus, ok := e.ObjectNew.(*fleetnetv1alpha1.InternalMemberNetworkStatus)
+			if !ok {
+				return false
+			}
+			return !equality.Semantic.DeepEqual(oldStatus.Status.TunnelPeers, newStatus.Status.TunnelPeers)
+		},
+	}
+}
+
+// endpointSliceName returns the name of the EndpointSlice imported from the EndpointSliceImport of the given name.
+func (r *Reconciler) endpointSliceName(endpointSliceImportName string) string {
+	if r.HubName == "" {
+		return endpointSliceImportName
+	}
+	return r.HubName + "-" + endpointSliceImportName
 }
 
 // unimportEndpointSlice unimports an EndpointSlice.
@@ -277,7 +511,7 @@ func (r *Reconciler) unimportEndpointSlice(ctx context.Context, endpointSliceImp
 	endpointSlice := &discoveryv1.EndpointSlice{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: r.FleetSystemNamespace,
-			Name:      endpointSliceImport.Name,
+			Name:      r.endpointSliceName(endpointSliceImport.Name),
 		},
 	}
 	if err := r.MemberClient.Delete(ctx, endpointSlice); err != nil && !errors.IsNotFound(err) {
@@ -302,11 +536,11 @@ func (r *Reconciler) addEndpointSliceImportCleanupFinalizer(ctx context.Context,
 	return nil
 }
 
-// isDerivedServiceValid returns if a derived Service is valid for EndpointSlice association.
-func (r *Reconciler) isDerivedServiceValid(ctx context.Context, derivedSvcName string) (bool, error) {
+// getDerivedService returns the derived Service if it is valid for EndpointSlice association, or nil otherwise.
+func (r *Reconciler) getDerivedService(ctx context.Context, derivedSvcName string) (*corev1.Service, error) {
 	// Check if the given name is a valid Service name; this helps guard against user tampering the label.
 	if errs := validation.IsDNS1035Label(derivedSvcName); len(errs) != 0 {
-		return false, nil
+		return nil, nil
 	}
 
 	// Check if the derived Service has been created and has not been marked for deletion.
@@ -315,9 +549,12 @@ func (r *Reconciler) isDerivedServiceValid(ctx context.Context, derivedSvcName s
 	derivedSvc := &corev1.Service{}
 	derivedSvcKey := types.NamespacedName{Namespace: r.FleetSystemNamespace, Name: derivedSvcName}
 	if err := r.MemberClient.Get(ctx, derivedSvcKey, derivedSvc); err != nil {
-		return false, client.IgnoreNotFound(err)
+		return nil, client.IgnoreNotFound(err)
+	}
+	if derivedSvc.DeletionTimestamp != nil {
+		return nil, nil
 	}
-	return derivedSvc.DeletionTimestamp == nil, nil
+	return derivedSvc, nil
 }
 
 // scanForDerivedServiceName scans a list of MCSes and returns the first found derived Service label in the list.
@@ -337,24 +574,289 @@ func scanForDerivedServiceName(multiClusterSvcList *fleetnetv1alpha1.MultiCluste
 	return derivedSvcName
 }
 
-// formatEndpointSliceFromImport formats an EndpointSlice using an EndpointSliceImport.
-func formatEndpointSliceFromImport(endpointSlice *discoveryv1.EndpointSlice, derivedSvcName string, endpointSliceImport *fleetnetv1alpha1.EndpointSliceImport) {
+// formatEndpointSliceFromImport formats an EndpointSlice using an EndpointSliceImport, keeping the conditions and the
+// zones of the imported endpoints; addresses of IP families that are not supported by the member cluster are
+// filtered out, along with endpoints that are left with no addresses.
+// The ports are normalized against the ports published by the derived Service, and the topology hints are set per
+// the topology of the member cluster, which is nil unless the endpoints in the same region are preferred. The tunnel
+// address is the one of the exporting cluster, or empty if no tunnel is established to it.
+func formatEndpointSliceFromImport(endpointSlice *discoveryv1.EndpointSlice, derivedSvc *corev1.Service, endpointSliceImport *fleetnetv1alpha1.EndpointSliceImport, supportedIPFamilies []corev1.IPFamily, topology *localTopology, tunnelAddress string) {
 	endpointSlice.AddressType = endpointSliceImport.Spec.AddressType
 	endpointSlice.Labels = map[string]string{
-		discoveryv1.LabelServiceName: derivedSvcName,
-		discoveryv1.LabelManagedBy:   controllerID,
+		discoveryv1.LabelServiceName:               derivedSvc.Name,
+		discoveryv1.LabelManagedBy:                 controllerID,
+		objectmeta.EndpointSliceLabelSourceCluster: endpointSliceImport.Spec.EndpointSliceReference.ClusterID,
 	}
-	endpointSlice.Ports = endpointSliceImport.Spec.Ports
+	importedEndpoints, importedPorts := selectImportedEndpoints(endpointSliceImport, tunnelAddress)
+	endpointSlice.Ports = normalizeEndpointPorts(importedPorts, derivedSvc.Spec.Ports)
 
 	endpoints := []discoveryv1.Endpoint{}
-	for _, importedEndpoint := range endpointSliceImport.Spec.Endpoints {
+	for _, importedEndpoint := range importedEndpoints {
+		addresses := filterAddressesByIPFamily(importedEndpoint.Addresses, supportedIPFamilies)
+		if len(addresses) == 0 {
+			continue
+		}
+		// The conditions are kept so that kube-proxy falls back to the terminating endpoints which are still serving
+		// only when no ready endpoints are left. The name of the Node is not kept, as it refers to a Node of the
+		// exporting cluster, which may share its name with an unrelated Node of the member cluster.
 		endpoints = append(endpoints, discoveryv1.Endpoint{
-			Addresses: importedEndpoint.Addresses,
+			Addresses:  addresses,
+			Conditions: importedEndpoint.Conditions,
+			Hostname:   importedHostname(importedEndpoint.Hostname, endpointSliceImport.Spec.EndpointSliceReference.ClusterID),
+			Zone:       importedEndpoint.Zone,
+			Hints:      importedHints(&importedEndpoint, topology),
 		})
 	}
 	endpointSlice.Endpoints = endpoints
 }
 
+// selectImportedEndpoints returns the endpoints and the ports to import from an EndpointSliceImport; the IP of the
+// Private Endpoint of the member cluster is imported if the EndpointSlice is exported through a Private Link Service
+// the member cluster is connected to. Otherwise the ones of the east-west gateway are imported if the EndpointSlice
+// is exported through a gateway, unless the hub has marked the exporting cluster as directly reachable; if a tunnel
+// is established to the exporting cluster, the gateway is reached at the tunnel address whatever the reachability.
+func selectImportedEndpoints(endpointSliceImport *fleetnetv1alpha1.EndpointSliceImport, tunnelAddress string) ([]fleetnetv1alpha1.Endpoint, []discoveryv1.EndpointPort) {
+	if privateLink := endpointSliceImport.Spec.PrivateLink; privateLink != nil {
+		if endpoints, ok := privateEndpointEndpoints(endpointSliceImport); ok {
+			return endpoints, privateLink.Ports
+		}
+	}
+	gateway := endpointSliceImport.Spec.Gateway
+	if gateway != nil && tunnelAddress != "" {
+		return tunnelEndpoints(endpointSliceImport.Spec.AddressType, gateway, tunnelAddress), gateway.Ports
+	}
+	reachability := fleetnetv1alpha1.ClusterReachability(endpointSliceImport.Annotations[objectmeta.EndpointSliceImportAnnotationReachability])
+	if gateway == nil || reachability == fleetnetv1alpha1.ClusterReachabilityDirect {
+		return endpointSliceImport.Spec.Endpoints, endpointSliceImport.Spec.Ports
+	}
+	return gateway.Endpoints, gateway.Ports
+}
+
+// privateEndpointEndpoints returns the endpoint at the IP of the Private Endpoint annotated on an EndpointSliceImport,
+// which is ready or serving as long as any of the exported endpoints is, as the load balancer behind the Private Link
+// Service forwards the connections to them. It returns false if no IP of the address type of the EndpointSliceImport
+// is annotated.
+func privateEndpointEndpoints(endpointSliceImport *fleetnetv1alpha1.EndpointSliceImport) ([]fleetnetv1alpha1.Endpoint, bool) {
+	ip := endpointSliceImport.Annotations[objectmeta.EndpointSliceImportAnnotationPrivateEndpointIP]
+	family, ok := ipfamily.OfAddress(ip)
+	if !ok {
+		return nil, false
+	}
+	if addressFamily, ok := ipfamily.OfAddressType(endpointSliceImport.Spec.AddressType); !ok || addressFamily != family {
+		return nil, false
+	}
+
+	ready, serving := false, false
+	for i := range endpointSliceImport.Spec.Endpoints {
+		ready = ready || endpointSliceImport.Spec.Endpoints[i].IsReady()
+		serving = serving || endpointSliceImport.Spec.Endpoints[i].IsServing()
+	}
+	switch {
+	case ready:
+		return []fleetnetv1alpha1.Endpoint{{
+			Addresses:  []string{ip},
+			Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true), Serving: ptr.To(true)},
+		}}, true
+	case serving:
+		return []fleetnetv1alpha1.Endpoint{{
+			Addresses:  []string{ip},
+			Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(false), Serving: ptr.To(true), Terminating: ptr.To(true)},
+		}}, true
+	}
+	return []fleetnetv1alpha1.Endpoint{}, true
+}
+
+// tunnelEndpoints returns the endpoint at the tunnel address of the exporting cluster, which is ready or serving as
+// long as any of the endpoints of its gateway is. No endpoint is imported if the tunnel address is not of the address
+// type of the EndpointSlice, so that the connections never bypass the tunnel.
+func tunnelEndpoints(addressType discoveryv1.AddressType, gateway *fleetnetv1alpha1.GatewayEndpoints, tunnelAddress string) []fleetnetv1alpha1.Endpoint {
+	family, ok := ipfamily.OfAddress(tunnelAddress)
+	if !ok {
+		return []fleetnetv1alpha1.Endpoint{}
+	}
+	if addressFamily, ok := ipfamily.OfAddressType(addressType); !ok || addressFamily != family {
+		return []fleetnetv1alpha1.Endpoint{}
+	}
+
+	ready, serving := false, false
+	for i := range gateway.Endpoints {
+		ready = ready || gateway.Endpoints[i].IsReady()
+		serving = serving || gateway.Endpoints[i].IsServing()
+	}
+	switch {
+	case ready:
+		return []fleetnetv1alpha1.Endpoint{{
+			Addresses:  []string{tunnelAddress},
+			Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true), Serving: ptr.To(true)},
+		}}
+	case serving:
+		return []fleetnetv1alpha1.Endpoint{{
+			Addresses:  []string{tunnelAddress},
+			Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(false), Serving: ptr.To(true), Terminating: ptr.To(true)},
+		}}
+	}
+	return []fleetnetv1alpha1.Endpoint{}
+}
+
+// localTopology is the region and the zones of the Nodes of the member cluster.
+type localTopology struct {
+	region string
+	zones  []string
+}
+
+// getLocalTopology returns the topology of the member cluster, as labeled on its Nodes; Node labels are read as
+// metadata only.
+func (r *Reconciler) getLocalTopology(ctx context.Context) (*localTopology, error) {
+	nodeList := &metav1.PartialObjectMetadataList{}
+	nodeList.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("NodeList"))
+	if err := r.MemberClient.List(ctx, nodeList); err != nil {
+		return nil, err
+	}
+	topology := &localTopology{}
+	for i := range nodeList.Items {
+		labels := nodeList.Items[i].Labels
+		if topology.region == "" {
+			topology.region = labels[corev1.LabelTopologyRegion]
+		}
+		if zone := labels[corev1.LabelTopologyZone]; zone != "" && !slices.Contains(topology.zones, zone) {
+			topology.zones = append(topology.zones, zone)
+		}
+	}
+	slices.Sort(topology.zones)
+	return topology, nil
+}
+
+// importedHints returns the topology hints of an imported endpoint, which replace the ones computed for the zones of
+// the exporting cluster.
+//
+// An endpoint is hinted for its own zone, so that the Nodes of the member cluster in the same zone consume the
+// endpoints in their zone only; kube-proxy falls back to all the endpoints for the Nodes in a zone that no endpoint
+// is hinted for, so the traffic leaves the zone only when it has to. If the endpoints in the same region are
+// preferred, they are hinted for all the zones of the member cluster instead. An endpoint of unknown zone has no
+// hints, which makes kube-proxy ignore the hints of the Service altogether.
+func importedHints(endpoint *fleetnetv1alpha1.Endpoint, topology *localTopology) *discoveryv1.EndpointHints {
+	if topology != nil && topology.region != "" && len(topology.zones) > 0 && ptr.Deref(endpoint.Region, "") == topology.region {
+		hints := &discoveryv1.EndpointHints{}
+		for _, zone := range topology.zones {
+			hints.ForZones = append(hints.ForZones, discoveryv1.ForZone{Name: zone})
+		}
+		return hints
+	}
+	if ptr.Deref(endpoint.Zone, "") == "" {
+		return nil
+	}
+	return &discoveryv1.EndpointHints{ForZones: []discoveryv1.ForZone{{Name: *endpoint.Zone}}}
+}
+
+// importedHostname returns the hostname of an imported endpoint, which is the hostname reported by the exporting
+// cluster suffixed with the ID of the cluster, as the Pods of a StatefulSet deployed in multiple clusters share
+// their hostnames; e.g. Pod web-0 from cluster member-1 is addressable as web-0-member-1.<derived Service>.
+// No hostname is set if the suffixed hostname is not a valid DNS label.
+func importedHostname(hostname *string, clusterID string) *string {
+	if hostname == nil || *hostname == "" {
+		return nil
+	}
+	imported := fmt.Sprintf("%s-%s", *hostname, clusterID)
+	if errs := validation.IsDNS1123Label(imported); len(errs) != 0 {
+		return nil
+	}
+	return &imported
+}
+
+// normalizeEndpointPorts maps the ports of the endpoints imported from a cluster to the canonical ports published
+// by the derived Service, as the same named port may be served on different numbers in different clusters.
+//
+// A named port is matched by its name; an unnamed port falls back to be matched by its number, against the target
+// port (or the port, if the target port is not a number) of a published port, and at last to the unnamed published
+// port, if any. The matched port takes the name of the published port and keeps its own number, which is where
+// the endpoints actually listen; ports that match no published port are dropped.
+func normalizeEndpointPorts(ports []discoveryv1.EndpointPort, svcPorts []corev1.ServicePort) []discoveryv1.EndpointPort {
+	if len(svcPorts) == 0 {
+		return ports
+	}
+	normalized := make([]discoveryv1.EndpointPort, 0, len(ports))
+	for i := range ports {
+		svcPort := matchServicePort(&ports[i], svcPorts)
+		if svcPort == nil {
+			continue
+		}
+		port := *ports[i].DeepCopy()
+		port.Name = ptr.To(svcPort.Name)
+		normalized = append(normalized, port)
+	}
+	return normalized
+}
+
+// matchServicePort returns the published port which the given endpoint port serves, or nil if there is none.
+func matchServicePort(port *discoveryv1.EndpointPort, svcPorts []corev1.ServicePort) *corev1.ServicePort {
+	protocol := corev1.ProtocolTCP
+	if port.Protocol != nil {
+		protocol = *port.Protocol
+	}
+	var candidates []*corev1.ServicePort
+	for i := range svcPorts {
+		svcProtocol := svcPorts[i].Protocol
+		if svcProtocol == "" {
+			svcProtocol = corev1.ProtocolTCP
+		}
+		if svcProtocol == protocol {
+			candidates = append(candidates, &svcPorts[i])
+		}
+	}
+
+	if port.Name != nil && *port.Name != "" {
+		for _, svcPort := range candidates {
+			if svcPort.Name == *port.Name {
+				return svcPort
+			}
+		}
+		return nil
+	}
+	if port.Port != nil {
+		for _, svcPort := range candidates {
+			number := int32(svcPort.TargetPort.IntValue())
+			if svcPort.TargetPort.Type == intstr.String || number == 0 {
+				number = svcPort.Port
+			}
+			if number == *port.Port {
+				return svcPort
+			}
+		}
+	}
+	for _, svcPort := range candidates {
+		if svcPort.Name == "" {
+			return svcPort
+		}
+	}
+	return nil
+}
+
+// isAddressTypeSupported returns if the addresses of an EndpointSlice are of an IP family supported by the member
+// cluster; all IP families are considered supported if none is specified.
+func isAddressTypeSupported(addressType discoveryv1.AddressType, supportedIPFamilies []corev1.IPFamily) bool {
+	if len(supportedIPFamilies) == 0 {
+		return true
+	}
+	family, ok := ipfamily.OfAddressType(addressType)
+	return ok && ipfamily.Contains(supportedIPFamilies, family)
+}
+
+// filterAddressesByIPFamily returns the addresses that belong to one of the supported IP families; addresses that
+// are not IP addresses (e.g. FQDNs) are always kept.
+func filterAddressesByIPFamily(addresses []string, supportedIPFamilies []corev1.IPFamily) []string {
+	if len(supportedIPFamilies) == 0 {
+		return addresses
+	}
+	filtered := make([]string, 0, len(addresses))
+	for _, addr := range addresses {
+		if family, ok := ipfamily.OfAddress(addr); ok && !ipfamily.Contains(supportedIPFamilies, family) {
+			continue
+		}
+		filtered = append(filtered, addr)
+	}
+	return filtered
+}
+
 // Observe data points for metrics.
 func (r *Reconciler) observeMetrics(ctx context.Context, endpointSliceImport *fleetnetv1alpha1.EndpointSliceImport, startTime time.Time) error {
 	// Check if a metric data point has been observed for the current generation of the object; this helps guard
@@ -388,6 +890,8 @@ func (r *Reconciler) observeMetrics(ctx context.Context, endpointSliceImport *fl
 		return err
 	}
 
+	r.observeHubImportDuration(endpointSliceImport, startTime)
+
 	// Skip the observation if the exportedSince field is empty in the object reference.
 	// Note that in most cases this branch should never run as the Fleet networking controllers will always assign a
 	// timestamp for each exported object.
@@ -428,3 +932,27 @@ func (r *Reconciler) observeMetrics(ctx context.Context, endpointSliceImport *fl
 		"isFirstImport", isFirstImport)
 	return nil
 }
+
+// observeHubImportDuration observes a data point for the hub to import duration metric, if the hub cluster has
+// stamped the time it distributes the EndpointSlice.
+func (r *Reconciler) observeHubImportDuration(endpointSliceImport *fleetnetv1alpha1.EndpointSliceImport, startTime time.Time) {
+	distributedTimestampData, ok := endpointSliceImport.Annotations[metrics.MetricsAnnotationDistributedTimestamp]
+	if !ok {
+		// The EndpointSliceImport is distributed by a hub controller which does not stamp the timestamp.
+		return
+	}
+	distributedTimestamp, err := time.Parse(metrics.MetricsLastSeenTimestampFormat, distributedTimestampData)
+	if err != nil {
+		klog.V(4).InfoS("distributed timestamp is invalid; endpointSlice hub/import duration data point is not collected",
+			"endpointSliceImport", klog.KObj(endpointSliceImport), "distributedTimestamp", distributedTimestampData)
+		return
+	}
+	timeSpent := metrics.PropagationDurationMilliseconds(distributedTimestamp, startTime)
+	endpointSliceHubImportDuration.
+		WithLabelValues(endpointSliceImport.Spec.EndpointSliceReference.ClusterID, r.MemberClusterID).
+		Observe(float64(timeSpent))
+	klog.V(2).InfoS("endpointSliceHubImportDurationMilliseconds",
+		"value", timeSpent,
+		"originClusterID", endpointSliceImport.Spec.EndpointSliceReference.ClusterID,
+		"destinationClusterID", r.MemberClusterID)
+}
@@ -18,9 +18,14 @@ import (
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	corev1 "k8s.io/api/core/v1"
 	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
@@ -135,8 +140,9 @@ func importedIPv4EndpointSlice() *discoveryv1.EndpointSlice {
 			Namespace: fleetSystemNS,
 			Name:      endpointSliceImportName,
 			Labels: map[string]string{
-				discoveryv1.LabelServiceName: derivedSvcName,
-				discoveryv1.LabelManagedBy:   controllerID,
+				discoveryv1.LabelServiceName:               derivedSvcName,
+				discoveryv1.LabelManagedBy:                 controllerID,
+				objectmeta.EndpointSliceLabelSourceCluster: hubNSForMember,
 			},
 		},
 		AddressType: discoveryv1.AddressTypeIPv4,
@@ -237,9 +243,88 @@ func TestScanForDerivedServiceName(t *testing.T) {
 
 // TestFormatEndpointSliceFromImport tests the formatEndpointSliceFromImport function.
 func TestFormatEndpointSliceFromImport(t *testing.T) {
+	ipv6EndpointSliceImport := ipv4EndpointSliceImport()
+	ipv6EndpointSliceImport.Spec.AddressType = discoveryv1.AddressTypeIPv6
+	ipv6EndpointSliceImport.Spec.Endpoints = []fleetnetv1alpha1.Endpoint{
+		{
+			Addresses: []string{"fd00::1"},
+		},
+	}
+	ipv6EndpointSlice := importedIPv4EndpointSlice()
+	ipv6EndpointSlice.AddressType = discoveryv1.AddressTypeIPv6
+	ipv6EndpointSlice.Endpoints = []discoveryv1.Endpoint{
+		{
+			Addresses: []string{"fd00::1"},
+		},
+	}
+	ipv6EndpointSliceWithNoEndpoints := importedIPv4EndpointSlice()
+	ipv6EndpointSliceWithNoEndpoints.AddressType = discoveryv1.AddressTypeIPv6
+	ipv6EndpointSliceWithNoEndpoints.Endpoints = []discoveryv1.Endpoint{}
+	terminatingConditions := discoveryv1.EndpointConditions{
+		Ready:       ptr.To(false),
+		Serving:     ptr.To(true),
+		Terminating: ptr.To(true),
+	}
+	endpointSliceImportWithConditions := ipv4EndpointSliceImport()
+	endpointSliceImportWithConditions.Spec.Endpoints = []fleetnetv1alpha1.Endpoint{
+		{
+			Addresses:  []string{"1.2.3.4"},
+			Conditions: terminatingConditions,
+			NodeName:   ptr.To("node-1"),
+			Zone:       ptr.To("eastus-1"),
+			Region:     ptr.To("eastus"),
+			Hints: &discoveryv1.EndpointHints{
+				ForZones: []discoveryv1.ForZone{{Name: "eastus-2"}},
+			},
+		},
+	}
+	endpointSliceWithConditions := importedIPv4EndpointSlice()
+	endpointSliceWithConditions.Endpoints = []discoveryv1.Endpoint{
+		{
+			Addresses:  []string{"1.2.3.4"},
+			Conditions: terminatingConditions,
+			Zone:       ptr.To("eastus-1"),
+			Hints: &discoveryv1.EndpointHints{
+				ForZones: []discoveryv1.ForZone{{Name: "eastus-1"}},
+			},
+		},
+	}
+	endpointSliceImportInRegions := ipv4EndpointSliceImport()
+	endpointSliceImportInRegions.Spec.Endpoints = []fleetnetv1alpha1.Endpoint{
+		{
+			Addresses: []string{"1.2.3.4"},
+			Zone:      ptr.To("eastus-1"),
+			Region:    ptr.To("eastus"),
+		},
+		{
+			Addresses: []string{"2.3.4.5"},
+			Zone:      ptr.To("westus-1"),
+			Region:    ptr.To("westus"),
+		},
+	}
+	endpointSliceInRegions := importedIPv4EndpointSlice()
+	endpointSliceInRegions.Endpoints = []discoveryv1.Endpoint{
+		{
+			Addresses: []string{"1.2.3.4"},
+			Zone:      ptr.To("eastus-1"),
+			Hints: &discoveryv1.EndpointHints{
+				ForZones: []discoveryv1.ForZone{{Name: "eastus-1"}, {Name: "eastus-2"}},
+			},
+		},
+		{
+			Addresses: []string{"2.3.4.5"},
+			Zone:      ptr.To("westus-1"),
+			Hints: &discoveryv1.EndpointHints{
+				ForZones: []discoveryv1.ForZone{{Name: "westus-1"}},
+			},
+		},
+	}
+
 	testCases := []struct {
 		name                string
 		endpointSliceImport *fleetnetv1alpha1.EndpointSliceImport
+		supportedIPFamilies []corev1.IPFamily
+		topology            *localTopology
 		want                *discoveryv1.EndpointSlice
 	}{
 		{
@@ -247,6 +332,35 @@ func TestFormatEndpointSliceFromImport(t *testing.T) {
 			endpointSliceImport: ipv4EndpointSliceImport(),
 			want:                importedIPv4EndpointSlice(),
 		},
+		{
+			name:                "should import ipv4 endpoints into a single-stack ipv4 cluster",
+			endpointSliceImport: ipv4EndpointSliceImport(),
+			supportedIPFamilies: []corev1.IPFamily{corev1.IPv4Protocol},
+			want:                importedIPv4EndpointSlice(),
+		},
+		{
+			name:                "should import ipv6 endpoints into a dual-stack cluster",
+			endpointSliceImport: ipv6EndpointSliceImport,
+			supportedIPFamilies: []corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv6Protocol},
+			want:                ipv6EndpointSlice,
+		},
+		{
+			name:                "should filter out ipv6 endpoints of a dual-stack export in a single-stack ipv4 cluster",
+			endpointSliceImport: ipv6EndpointSliceImport,
+			supportedIPFamilies: []corev1.IPFamily{corev1.IPv4Protocol},
+			want:                ipv6EndpointSliceWithNoEndpoints,
+		},
+		{
+			name:                "should keep the conditions and zones of endpoints, but not their node names",
+			endpointSliceImport: endpointSliceImportWithConditions,
+			want:                endpointSliceWithConditions,
+		},
+		{
+			name:                "should hint endpoints in the same region for all the zones of the member cluster",
+			endpointSliceImport: endpointSliceImportInRegions,
+			topology:            &localTopology{region: "eastus", zones: []string{"eastus-1", "eastus-2"}},
+			want:                endpointSliceInRegions,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -258,7 +372,13 @@ func TestFormatEndpointSliceFromImport(t *testing.T) {
 				},
 			}
 
-			formatEndpointSliceFromImport(endpointSlice, derivedSvcName, tc.endpointSliceImport)
+			derivedSvc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: fleetSystemNS,
+					Name:      derivedSvcName,
+				},
+			}
+			formatEndpointSliceFromImport(endpointSlice, derivedSvc, tc.endpointSliceImport, tc.supportedIPFamilies, tc.topology, "")
 			if diff := cmp.Diff(endpointSlice, tc.want); diff != "" {
 				t.Fatalf("formatEndpointSliceImport(), got diff %s", diff)
 			}
@@ -266,8 +386,629 @@ func TestFormatEndpointSliceFromImport(t *testing.T) {
 	}
 }
 
-// TestIsDerivedServiceValid tests the isDerivedServiceValid function.
-func TestIsDerivedServiceValid(t *testing.T) {
+// TestSelectImportedEndpoints tests the selectImportedEndpoints function.
+func TestSelectImportedEndpoints(t *testing.T) {
+	podEndpoints := []fleetnetv1alpha1.Endpoint{{Addresses: []string{"10.0.0.1"}}}
+	podPorts := []discoveryv1.EndpointPort{{Name: &httpPortName, Port: &httpPort, Protocol: &httpPortProtocol}}
+	gatewayPort := int32(15100)
+	gateway := &fleetnetv1alpha1.GatewayEndpoints{
+		Endpoints: []fleetnetv1alpha1.Endpoint{{Addresses: []string{"20.0.0.1"}}},
+		Ports:     []discoveryv1.EndpointPort{{Name: &httpPortName, Port: &gatewayPort, Protocol: &httpPortProtocol}},
+	}
+	endpointSliceImport := func(gateway *fleetnetv1alpha1.GatewayEndpoints, reachability fleetnetv1alpha1.ClusterReachability) *fleetnetv1alpha1.EndpointSliceImport {
+		res := &fleetnetv1alpha1.EndpointSliceImport{
+			Spec: fleetnetv1alpha1.EndpointSliceExportSpec{
+				Endpoints: podEndpoints,
+				Ports:     podPorts,
+				Gateway:   gateway,
+			},
+		}
+		if reachability != "" {
+			res.Annotations = map[string]string{objectmeta.EndpointSliceImportAnnotationReachability: string(reachability)}
+		}
+		return res
+	}
+	lbPort := int32(80)
+	privateLink := &fleetnetv1alpha1.PrivateLinkEndpoints{
+		ServiceID: "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/privateLinkServices/fleet-work-app",
+		Ports:     []discoveryv1.EndpointPort{{Name: &httpPortName, Port: &lbPort, Protocol: &httpPortProtocol}},
+	}
+	privateLinkEndpointSliceImport := func(endpoints []fleetnetv1alpha1.Endpoint, privateEndpointIP string) *fleetnetv1alpha1.EndpointSliceImport {
+		res := endpointSliceImport(gateway, fleetnetv1alpha1.ClusterReachabilityGateway)
+		res.Spec.AddressType = discoveryv1.AddressTypeIPv4
+		res.Spec.Endpoints = endpoints
+		res.Spec.PrivateLink = privateLink
+		if privateEndpointIP != "" {
+			res.Annotations[objectmeta.EndpointSliceImportAnnotationPrivateEndpointIP] = privateEndpointIP
+		}
+		return res
+	}
+	terminatingEndpoints := []fleetnetv1alpha1.Endpoint{{
+		Addresses:  []string{"10.0.0.2"},
+		Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(false), Serving: ptr.To(true), Terminating: ptr.To(true)},
+	}}
+
+	tunnelEndpointSliceImport := func(gateway *fleetnetv1alpha1.GatewayEndpoints, reachability fleetnetv1alpha1.ClusterReachability) *fleetnetv1alpha1.EndpointSliceImport {
+		res := endpointSliceImport(gateway, reachability)
+		res.Spec.AddressType = discoveryv1.AddressTypeIPv4
+		return res
+	}
+	terminatingGateway := &fleetnetv1alpha1.GatewayEndpoints{
+		Endpoints: terminatingEndpoints,
+		Ports:     gateway.Ports,
+	}
+
+	testCases := []struct {
+		name                string
+		endpointSliceImport *fleetnetv1alpha1.EndpointSliceImport
+		tunnelAddress       string
+		wantEndpoints       []fleetnetv1alpha1.Endpoint
+		wantPorts           []discoveryv1.EndpointPort
+	}{
+		{
+			name:                "not exported through a gateway",
+			endpointSliceImport: endpointSliceImport(nil, ""),
+			wantEndpoints:       podEndpoints,
+			wantPorts:           podPorts,
+		},
+		{
+			name:                "exported through a gateway without reachability",
+			endpointSliceImport: endpointSliceImport(gateway, ""),
+			wantEndpoints:       gateway.Endpoints,
+			wantPorts:           gateway.Ports,
+		},
+		{
+			name:                "reached through the gateway",
+			endpointSliceImport: endpointSliceImport(gateway, fleetnetv1alpha1.ClusterReachabilityGateway),
+			wantEndpoints:       gateway.Endpoints,
+			wantPorts:           gateway.Ports,
+		},
+		{
+			name:                "reached directly",
+			endpointSliceImport: endpointSliceImport(gateway, fleetnetv1alpha1.ClusterReachabilityDirect),
+			wantEndpoints:       podEndpoints,
+			wantPorts:           podPorts,
+		},
+		{
+			name:                "exposed through private link without a private endpoint",
+			endpointSliceImport: privateLinkEndpointSliceImport(podEndpoints, ""),
+			wantEndpoints:       gateway.Endpoints,
+			wantPorts:           gateway.Ports,
+		},
+		{
+			name:                "reached through the private endpoint",
+			endpointSliceImport: privateLinkEndpointSliceImport(podEndpoints, "10.2.0.4"),
+			wantEndpoints: []fleetnetv1alpha1.Endpoint{{
+				Addresses:  []string{"10.2.0.4"},
+				Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true), Serving: ptr.To(true)},
+			}},
+			wantPorts: privateLink.Ports,
+		},
+		{
+			name:                "reached through the private endpoint, terminating endpoints only",
+			endpointSliceImport: privateLinkEndpointSliceImport(terminatingEndpoints, "10.2.0.4"),
+			wantEndpoints: []fleetnetv1alpha1.Endpoint{{
+				Addresses:  []string{"10.2.0.4"},
+				Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(false), Serving: ptr.To(true), Terminating: ptr.To(true)},
+			}},
+			wantPorts: privateLink.Ports,
+		},
+		{
+			name:                "reached through the private endpoint, no endpoints",
+			endpointSliceImport: privateLinkEndpointSliceImport([]fleetnetv1alpha1.Endpoint{}, "10.2.0.4"),
+			wantEndpoints:       []fleetnetv1alpha1.Endpoint{},
+			wantPorts:           privateLink.Ports,
+		},
+		{
+			name:                "private endpoint address of a different family",
+			endpointSliceImport: privateLinkEndpointSliceImport(podEndpoints, "fd00::4"),
+			wantEndpoints:       gateway.Endpoints,
+			wantPorts:           gateway.Ports,
+		},
+		{
+			name:                "reached through the tunnel",
+			endpointSliceImport: tunnelEndpointSliceImport(gateway, fleetnetv1alpha1.ClusterReachabilityGateway),
+			tunnelAddress:       "100.96.0.2",
+			wantEndpoints: []fleetnetv1alpha1.Endpoint{{
+				Addresses:  []string{"100.96.0.2"},
+				Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true), Serving: ptr.To(true)},
+			}},
+			wantPorts: gateway.Ports,
+		},
+		{
+			name:                "reached through the tunnel though directly reachable",
+			endpointSliceImport: tunnelEndpointSliceImport(gateway, fleetnetv1alpha1.ClusterReachabilityDirect),
+			tunnelAddress:       "100.96.0.2",
+			wantEndpoints: []fleetnetv1alpha1.Endpoint{{
+				Addresses:  []string{"100.96.0.2"},
+				Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true), Serving: ptr.To(true)},
+			}},
+			wantPorts: gateway.Ports,
+		},
+		{
+			name:                "reached through the tunnel, terminating gateway endpoints only",
+			endpointSliceImport: tunnelEndpointSliceImport(terminatingGateway, ""),
+			tunnelAddress:       "100.96.0.2",
+			wantEndpoints: []fleetnetv1alpha1.Endpoint{{
+				Addresses:  []string{"100.96.0.2"},
+				Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(false), Serving: ptr.To(true), Terminating: ptr.To(true)},
+			}},
+			wantPorts: gateway.Ports,
+		},
+		{
+			name:                "tunnel address of a different family",
+			endpointSliceImport: tunnelEndpointSliceImport(gateway, fleetnetv1alpha1.ClusterReachabilityDirect),
+			tunnelAddress:       "fd00::2",
+			wantEndpoints:       []fleetnetv1alpha1.Endpoint{},
+			wantPorts:           gateway.Ports,
+		},
+		{
+			name:                "tunnel to a cluster not exporting through a gateway",
+			endpointSliceImport: tunnelEndpointSliceImport(nil, ""),
+			tunnelAddress:       "100.96.0.2",
+			wantEndpoints:       podEndpoints,
+			wantPorts:           podPorts,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotEndpoints, gotPorts := selectImportedEndpoints(tc.endpointSliceImport, tc.tunnelAddress)
+			if diff := cmp.Diff(tc.wantEndpoints, gotEndpoints); diff != "" {
+				t.Errorf("selectImportedEndpoints() endpoints mismatch (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantPorts, gotPorts); diff != "" {
+				t.Errorf("selectImportedEndpoints() ports mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestGetLocalTopology tests the getLocalTopology method.
+func TestGetLocalTopology(t *testing.T) {
+	node := func(name, region, zone string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Labels: map[string]string{
+					corev1.LabelTopologyRegion: region,
+					corev1.LabelTopologyZone:   zone,
+				},
+			},
+		}
+	}
+	fakeMemberClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(node("node-1", "eastus", "eastus-2"), node("node-2", "eastus", "eastus-1"), node("node-3", "eastus", "eastus-2")).
+		Build()
+	r := &Reconciler{MemberClient: fakeMemberClient}
+
+	got, err := r.getLocalTopology(context.Background())
+	if err != nil {
+		t.Fatalf("getLocalTopology() = %v, want no error", err)
+	}
+	want := &localTopology{region: "eastus", zones: []string{"eastus-1", "eastus-2"}}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(localTopology{})); diff != "" {
+		t.Errorf("getLocalTopology() mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+// TestNormalizeEndpointPorts tests the normalizeEndpointPorts function.
+func TestNormalizeEndpointPorts(t *testing.T) {
+	// The published ports route to named target ports, which the exporting clusters serve on different numbers.
+	publishedPorts := []corev1.ServicePort{
+		{
+			Name:       httpPortName,
+			Protocol:   corev1.ProtocolTCP,
+			Port:       80,
+			TargetPort: intstr.FromString("web"),
+		},
+		{
+			Name:       "metrics",
+			Protocol:   corev1.ProtocolTCP,
+			Port:       9090,
+			TargetPort: intstr.FromInt32(9091),
+		},
+		{
+			Name:     "dns",
+			Protocol: corev1.ProtocolUDP,
+			Port:     53,
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		ports    []discoveryv1.EndpointPort
+		svcPorts []corev1.ServicePort
+		want     []discoveryv1.EndpointPort
+	}{
+		{
+			name: "named port served on 8080 by one cluster",
+			ports: []discoveryv1.EndpointPort{
+				{Name: ptr.To(httpPortName), Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To(int32(8080))},
+			},
+			svcPorts: publishedPorts,
+			want: []discoveryv1.EndpointPort{
+				{Name: ptr.To(httpPortName), Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To(int32(8080))},
+			},
+		},
+		{
+			name: "same named port served on 8081 by another cluster",
+			ports: []discoveryv1.EndpointPort{
+				{Name: ptr.To(httpPortName), Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To(int32(8081))},
+			},
+			svcPorts: publishedPorts,
+			want: []discoveryv1.EndpointPort{
+				{Name: ptr.To(httpPortName), Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To(int32(8081))},
+			},
+		},
+		{
+			name: "unnamed ports are matched by number",
+			ports: []discoveryv1.EndpointPort{
+				{Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To(int32(9091))},
+				{Name: ptr.To(""), Protocol: ptr.To(corev1.ProtocolUDP), Port: ptr.To(int32(53))},
+			},
+			svcPorts: publishedPorts,
+			want: []discoveryv1.EndpointPort{
+				{Name: ptr.To("metrics"), Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To(int32(9091))},
+				{Name: ptr.To("dns"), Protocol: ptr.To(corev1.ProtocolUDP), Port: ptr.To(int32(53))},
+			},
+		},
+		{
+			name: "ports matching no published port are dropped",
+			ports: []discoveryv1.EndpointPort{
+				{Name: ptr.To("admin"), Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To(int32(8443))},
+				{Name: ptr.To(httpPortName), Protocol: ptr.To(corev1.ProtocolUDP), Port: ptr.To(int32(8080))},
+				{Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To(int32(53))},
+			},
+			svcPorts: publishedPorts,
+			want:     []discoveryv1.EndpointPort{},
+		},
+		{
+			name: "unnamed port falls back to the unnamed published port",
+			ports: []discoveryv1.EndpointPort{
+				{Port: ptr.To(int32(8080))},
+			},
+			svcPorts: []corev1.ServicePort{
+				{Port: 80, TargetPort: intstr.FromString("web")},
+			},
+			want: []discoveryv1.EndpointPort{
+				{Name: ptr.To(""), Port: ptr.To(int32(8080))},
+			},
+		},
+		{
+			name: "no published ports",
+			ports: []discoveryv1.EndpointPort{
+				{Name: ptr.To(httpPortName), Port: ptr.To(int32(8080))},
+			},
+			want: []discoveryv1.EndpointPort{
+				{Name: ptr.To(httpPortName), Port: ptr.To(int32(8080))},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := normalizeEndpointPorts(tc.ports, tc.svcPorts)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("normalizeEndpointPorts() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestFilterAddressesByIPFamily tests the filterAddressesByIPFamily function.
+func TestFilterAddressesByIPFamily(t *testing.T) {
+	testCases := []struct {
+		name                string
+		addresses           []string
+		supportedIPFamilies []corev1.IPFamily
+		want                []string
+	}{
+		{
+			name:      "should keep all addresses when no IP family is specified",
+			addresses: []string{"1.2.3.4", "fd00::1"},
+			want:      []string{"1.2.3.4", "fd00::1"},
+		},
+		{
+			name:                "should drop ipv6 addresses in a single-stack ipv4 cluster",
+			addresses:           []string{"1.2.3.4", "fd00::1"},
+			supportedIPFamilies: []corev1.IPFamily{corev1.IPv4Protocol},
+			want:                []string{"1.2.3.4"},
+		},
+		{
+			name:                "should drop ipv4 addresses in a single-stack ipv6 cluster",
+			addresses:           []string{"1.2.3.4", "fd00::1"},
+			supportedIPFamilies: []corev1.IPFamily{corev1.IPv6Protocol},
+			want:                []string{"fd00::1"},
+		},
+		{
+			name:                "should keep non-IP addresses",
+			addresses:           []string{"example.com"},
+			supportedIPFamilies: []corev1.IPFamily{corev1.IPv4Protocol},
+			want:                []string{"example.com"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := filterAddressesByIPFamily(tc.addresses, tc.supportedIPFamilies)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("filterAddressesByIPFamily() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestIsAddressTypeSupported tests the isAddressTypeSupported function.
+func TestIsAddressTypeSupported(t *testing.T) {
+	testCases := []struct {
+		name                string
+		addressType         discoveryv1.AddressType
+		supportedIPFamilies []corev1.IPFamily
+		want                bool
+	}{
+		{
+			name:        "should support all address types when no IP family is specified",
+			addressType: discoveryv1.AddressTypeIPv6,
+			want:        true,
+		},
+		{
+			name:                "should support ipv4 in a single-stack ipv4 cluster",
+			addressType:         discoveryv1.AddressTypeIPv4,
+			supportedIPFamilies: []corev1.IPFamily{corev1.IPv4Protocol},
+			want:                true,
+		},
+		{
+			name:                "should not support ipv6 in a single-stack ipv4 cluster",
+			addressType:         discoveryv1.AddressTypeIPv6,
+			supportedIPFamilies: []corev1.IPFamily{corev1.IPv4Protocol},
+		},
+		{
+			name:                "should support ipv6 in a dual-stack cluster",
+			addressType:         discoveryv1.AddressTypeIPv6,
+			supportedIPFamilies: []corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv6Protocol},
+			want:                true,
+		},
+		{
+			name:                "should not support fqdn",
+			addressType:         discoveryv1.AddressTypeFQDN,
+			supportedIPFamilies: []corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv6Protocol},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isAddressTypeSupported(tc.addressType, tc.supportedIPFamilies); got != tc.want {
+				t.Errorf("isAddressTypeSupported(%s, %v) = %t, want %t", tc.addressType, tc.supportedIPFamilies, got, tc.want)
+			}
+		})
+	}
+}
+
+// TestReconcile_UnsupportedAddressType tests the *Reconciler.Reconcile method with an EndpointSliceImport of an
+// IP family the member cluster does not support.
+func TestReconcile_UnsupportedAddressType(t *testing.T) {
+	ctx := context.Background()
+	endpointSliceImport := &fleetnetv1alpha1.EndpointSliceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  hubNSForMember,
+			Name:       endpointSliceImportName,
+			Finalizers: []string{endpointSliceImportCleanupFinalizer},
+		},
+		Spec: fleetnetv1alpha1.EndpointSliceExportSpec{
+			AddressType: discoveryv1.AddressTypeIPv6,
+			Endpoints: []fleetnetv1alpha1.Endpoint{
+				{Addresses: []string{"fd00::1"}},
+			},
+			OwnerServiceReference: fleetnetv1alpha1.OwnerServiceReference{
+				Namespace: memberUserNS,
+				Name:      svcName,
+			},
+		},
+	}
+	importedEndpointSlice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: fleetSystemNS,
+			Name:      endpointSliceImportName,
+		},
+		AddressType: discoveryv1.AddressTypeIPv6,
+	}
+	fakeMemberClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(importedEndpointSlice).
+		Build()
+	fakeHubClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(endpointSliceImport).
+		Build()
+	reconciler := Reconciler{
+		MemberClient:         fakeMemberClient,
+		HubClient:            fakeHubClient,
+		FleetSystemNamespace: fleetSystemNS,
+		SupportedIPFamilies:  []corev1.IPFamily{corev1.IPv4Protocol},
+	}
+
+	if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: endpointSliceImportKey}); err != nil {
+		t.Fatalf("Reconcile(%v) = %v, want no error", endpointSliceImportKey, err)
+	}
+
+	endpointSlice := &discoveryv1.EndpointSlice{}
+	endpointSliceKey := types.NamespacedName{Namespace: fleetSystemNS, Name: endpointSliceImportName}
+	if err := fakeMemberClient.Get(ctx, endpointSliceKey, endpointSlice); !errors.IsNotFound(err) {
+		t.Errorf("endpointSlice Get(%v) = %v, want not found error", endpointSliceKey, err)
+	}
+	updatedEndpointSliceImport := &fleetnetv1alpha1.EndpointSliceImport{}
+	if err := fakeHubClient.Get(ctx, endpointSliceImportKey, updatedEndpointSliceImport); err != nil {
+		t.Fatalf("endpointSliceImport Get(%v) = %v, want no error", endpointSliceImportKey, err)
+	}
+	if len(updatedEndpointSliceImport.Finalizers) != 0 {
+		t.Errorf("endpointSliceImport finalizers = %v, want none", updatedEndpointSliceImport.Finalizers)
+	}
+}
+
+// TestReconcile_AdditionalHub tests the *Reconciler.Reconcile method of an additional hub cluster, which unimports
+// the EndpointSlice prefixed with the name of the hub cluster and leaves the one of the primary hub cluster alone.
+func TestReconcile_AdditionalHub(t *testing.T) {
+	ctx := context.Background()
+	endpointSliceImport := &fleetnetv1alpha1.EndpointSliceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  hubNSForMember,
+			Name:       endpointSliceImportName,
+			Finalizers: []string{endpointSliceImportCleanupFinalizer},
+		},
+		Spec: fleetnetv1alpha1.EndpointSliceExportSpec{
+			AddressType: discoveryv1.AddressTypeIPv6,
+			OwnerServiceReference: fleetnetv1alpha1.OwnerServiceReference{
+				Namespace: memberUserNS,
+				Name:      svcName,
+			},
+		},
+	}
+	primaryEndpointSlice := &discoveryv1.EndpointSlice{
+		ObjectMeta:  metav1.ObjectMeta{Namespace: fleetSystemNS, Name: endpointSliceImportName},
+		AddressType: discoveryv1.AddressTypeIPv6,
+	}
+	additionalEndpointSlice := &discoveryv1.EndpointSlice{
+		ObjectMeta:  metav1.ObjectMeta{Namespace: fleetSystemNS, Name: "west-" + endpointSliceImportName},
+		AddressType: discoveryv1.AddressTypeIPv6,
+	}
+	fakeMemberClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(primaryEndpointSlice, additionalEndpointSlice).
+		Build()
+	fakeHubClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(endpointSliceImport).
+		Build()
+	reconciler := Reconciler{
+		MemberClient:         fakeMemberClient,
+		HubClient:            fakeHubClient,
+		FleetSystemNamespace: fleetSystemNS,
+		SupportedIPFamilies:  []corev1.IPFamily{corev1.IPv4Protocol},
+		HubName:              "west",
+	}
+
+	if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: endpointSliceImportKey}); err != nil {
+		t.Fatalf("Reconcile(%v) = %v, want no error", endpointSliceImportKey, err)
+	}
+
+	additionalKey := types.NamespacedName{Namespace: fleetSystemNS, Name: additionalEndpointSlice.Name}
+	if err := fakeMemberClient.Get(ctx, additionalKey, &discoveryv1.EndpointSlice{}); !errors.IsNotFound(err) {
+		t.Errorf("endpointSlice Get(%v) = %v, want not found error", additionalKey, err)
+	}
+	primaryKey := types.NamespacedName{Namespace: fleetSystemNS, Name: primaryEndpointSlice.Name}
+	if err := fakeMemberClient.Get(ctx, primaryKey, &discoveryv1.EndpointSlice{}); err != nil {
+		t.Errorf("endpointSlice Get(%v) = %v, want no error", primaryKey, err)
+	}
+}
+
+// TestReconcile_DeniedOriginCluster tests the *Reconciler.Reconcile method with an EndpointSliceImport exported from
+// a cluster denied by an ImportPolicy.
+func TestReconcile_DeniedOriginCluster(t *testing.T) {
+	ctx := context.Background()
+	endpointSliceImport := &fleetnetv1alpha1.EndpointSliceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  hubNSForMember,
+			Name:       endpointSliceImportName,
+			Finalizers: []string{endpointSliceImportCleanupFinalizer},
+		},
+		Spec: fleetnetv1alpha1.EndpointSliceExportSpec{
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Endpoints: []fleetnetv1alpha1.Endpoint{
+				{Addresses: []string{"10.0.0.1"}},
+			},
+			EndpointSliceReference: fleetnetv1alpha1.ExportedObjectReference{
+				ClusterID: "member-2",
+			},
+			OwnerServiceReference: fleetnetv1alpha1.OwnerServiceReference{
+				Namespace: memberUserNS,
+				Name:      svcName,
+			},
+		},
+	}
+	importedEndpointSlice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: fleetSystemNS,
+			Name:      endpointSliceImportName,
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+	}
+	importPolicy := &fleetnetv1alpha1.ImportPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "no-member-2"},
+		Spec: fleetnetv1alpha1.ImportPolicySpec{
+			DeniedClusters: []string{"member-2"},
+		},
+	}
+	fakeMemberClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(importedEndpointSlice, importPolicy).
+		Build()
+	fakeHubClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(endpointSliceImport).
+		Build()
+	reconciler := Reconciler{
+		MemberClient:          fakeMemberClient,
+		HubClient:             fakeHubClient,
+		FleetSystemNamespace:  fleetSystemNS,
+		EnforceImportPolicies: true,
+	}
+
+	if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: endpointSliceImportKey}); err != nil {
+		t.Fatalf("Reconcile(%v) = %v, want no error", endpointSliceImportKey, err)
+	}
+
+	endpointSlice := &discoveryv1.EndpointSlice{}
+	endpointSliceKey := types.NamespacedName{Namespace: fleetSystemNS, Name: endpointSliceImportName}
+	if err := fakeMemberClient.Get(ctx, endpointSliceKey, endpointSlice); !errors.IsNotFound(err) {
+		t.Errorf("endpointSlice Get(%v) = %v, want not found error", endpointSliceKey, err)
+	}
+	updatedEndpointSliceImport := &fleetnetv1alpha1.EndpointSliceImport{}
+	if err := fakeHubClient.Get(ctx, endpointSliceImportKey, updatedEndpointSliceImport); err != nil {
+		t.Fatalf("endpointSliceImport Get(%v) = %v, want no error", endpointSliceImportKey, err)
+	}
+	if len(updatedEndpointSliceImport.Finalizers) != 0 {
+		t.Errorf("endpointSliceImport finalizers = %v, want none", updatedEndpointSliceImport.Finalizers)
+	}
+}
+
+// TestImportedHostname tests the importedHostname function.
+func TestImportedHostname(t *testing.T) {
+	testCases := []struct {
+		name     string
+		hostname *string
+		want     *string
+	}{
+		{
+			name:     "should suffix the hostname with the cluster ID",
+			hostname: ptr.To("web-0"),
+			want:     ptr.To("web-0-member-1"),
+		},
+		{
+			name: "should set no hostname for endpoints without one",
+		},
+		{
+			name:     "should set no hostname for empty hostnames",
+			hostname: ptr.To(""),
+		},
+		{
+			name:     "should set no hostname if the suffixed hostname is too long",
+			hostname: ptr.To(strings.Repeat("a", 60)),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := importedHostname(tc.hostname, "member-1")
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("importedHostname() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestGetDerivedService tests the getDerivedService function.
+func TestGetDerivedService(t *testing.T) {
 	deletionTimestamp := metav1.Now()
 
 	testCases := []struct {
@@ -332,8 +1073,15 @@ func TestIsDerivedServiceValid(t *testing.T) {
 				FleetSystemNamespace: fleetSystemNS,
 			}
 
-			if got, err := reconciler.isDerivedServiceValid(ctx, tc.derivedSvcName); got != tc.want || err != nil {
-				t.Fatalf("isDerivedServiceValid(%+v) = %t, %v, want %t, no error", tc.derivedSvcName, got, err, tc.want)
+			got, err := reconciler.getDerivedService(ctx, tc.derivedSvcName)
+			if err != nil {
+				t.Fatalf("getDerivedService(%+v) = %v, want no error", tc.derivedSvcName, err)
+			}
+			if (got != nil) != tc.want {
+				t.Fatalf("getDerivedService(%+v) = %v, want a Service %t", tc.derivedSvcName, got, tc.want)
+			}
+			if got != nil && (got.Namespace != fleetSystemNS || got.Name != tc.derivedSvcName) {
+				t.Errorf("getDerivedService(%+v) = %s/%s, want %s/%s", tc.derivedSvcName, got.Namespace, got.Name, fleetSystemNS, tc.derivedSvcName)
 			}
 		})
 	}
@@ -720,3 +1468,172 @@ func TestObserveMetrics(t *testing.T) {
 		})
 	}
 }
+
+// TestObserveHubImportDuration tests the Reconciler.observeHubImportDuration function.
+func TestObserveHubImportDuration(t *testing.T) {
+	metricMetadata := `
+		# HELP fleet_networking_endpointslice_hub_import_duration_milliseconds The duration of an endpointslice import from the hub cluster
+		# TYPE fleet_networking_endpointslice_hub_import_duration_milliseconds histogram
+	`
+	startTime := time.Now().Round(time.Second)
+
+	testCases := []struct {
+		name            string
+		annotations     map[string]string
+		wantMetricCount int
+		wantHistogram   string
+	}{
+		{
+			name:            "should not observe data point (no distributed timestamp)",
+			wantMetricCount: 0,
+		},
+		{
+			name: "should not observe data point (invalid distributed timestamp)",
+			annotations: map[string]string{
+				metrics.MetricsAnnotationDistributedTimestamp: "yesterday",
+			},
+			wantMetricCount: 0,
+		},
+		{
+			name: "should observe a data point",
+			annotations: map[string]string{
+				metrics.MetricsAnnotationDistributedTimestamp: startTime.Add(-time.Second * 2).Format(metrics.MetricsLastSeenTimestampFormat),
+			},
+			wantMetricCount: 1,
+			wantHistogram: fmt.Sprintf(`
+				fleet_networking_endpointslice_hub_import_duration_milliseconds_bucket{destination_cluster_id="%[1]s",origin_cluster_id="%[1]s",le="1000"} 0
+				fleet_networking_endpointslice_hub_import_duration_milliseconds_bucket{destination_cluster_id="%[1]s",origin_cluster_id="%[1]s",le="2500"} 1
+				fleet_networking_endpointslice_hub_import_duration_milliseconds_bucket{destination_cluster_id="%[1]s",origin_cluster_id="%[1]s",le="5000"} 1
+				fleet_networking_endpointslice_hub_import_duration_milliseconds_bucket{destination_cluster_id="%[1]s",origin_cluster_id="%[1]s",le="10000"} 1
+				fleet_networking_endpointslice_hub_import_duration_milliseconds_bucket{destination_cluster_id="%[1]s",origin_cluster_id="%[1]s",le="25000"} 1
+				fleet_networking_endpointslice_hub_import_duration_milliseconds_bucket{destination_cluster_id="%[1]s",origin_cluster_id="%[1]s",le="50000"} 1
+				fleet_networking_endpointslice_hub_import_duration_milliseconds_bucket{destination_cluster_id="%[1]s",origin_cluster_id="%[1]s",le="+Inf"} 1
+				fleet_networking_endpointslice_hub_import_duration_milliseconds_sum{destination_cluster_id="%[1]s",origin_cluster_id="%[1]s"} 2000
+				fleet_networking_endpointslice_hub_import_duration_milliseconds_count{destination_cluster_id="%[1]s",origin_cluster_id="%[1]s"} 1
+			`, memberClusterID),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			endpointSliceImport := &fleetnetv1alpha1.EndpointSliceImport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   hubNSForMember,
+					Name:        endpointSliceImportName,
+					Annotations: tc.annotations,
+				},
+				Spec: fleetnetv1alpha1.EndpointSliceExportSpec{
+					EndpointSliceReference: fleetnetv1alpha1.ExportedObjectReference{
+						ClusterID: memberClusterID,
+					},
+				},
+			}
+			reconciler := Reconciler{MemberClusterID: memberClusterID}
+			reconciler.observeHubImportDuration(endpointSliceImport, startTime)
+
+			if c := testutil.CollectAndCount(endpointSliceHubImportDuration); c != tc.wantMetricCount {
+				t.Fatalf("metric counts, got %d, want %d", c, tc.wantMetricCount)
+			}
+			if tc.wantHistogram != "" {
+				if err := testutil.CollectAndCompare(endpointSliceHubImportDuration, strings.NewReader(metricMetadata+tc.wantHistogram)); err != nil {
+					t.Errorf("%s", err)
+				}
+			}
+		})
+	}
+}
+
+// TestReconcile_ServiceImportWithoutMCS tests that an EndpointSlice is imported for the derived Service of a
+// ServiceImport when no MCS imports the Service.
+func TestReconcile_ServiceImportWithoutMCS(t *testing.T) {
+	derivedSvc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: fleetSystemNS,
+			Name:      derivedSvcName,
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{Name: httpPortName, Protocol: httpPortProtocol, Port: httpPort, AppProtocol: &httpPortAppProtocol},
+				{Name: tcpPortName, Protocol: tcpPortProtocol, Port: tcpPort, AppProtocol: &tcpPortAppProtocol},
+			},
+		},
+	}
+	testCases := []struct {
+		name              string
+		serviceImport     *fleetnetv1alpha1.ServiceImport
+		derivedSvc        *corev1.Service
+		want              ctrl.Result
+		wantEndpointSlice bool
+	}{
+		{
+			name: "serviceImport with derived service",
+			serviceImport: &fleetnetv1alpha1.ServiceImport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: memberUserNS,
+					Name:      svcName,
+					Labels:    map[string]string{objectmeta.ServiceImportLabelDerivedService: derivedSvcName},
+				},
+			},
+			derivedSvc:        derivedSvc,
+			wantEndpointSlice: true,
+		},
+		{
+			name: "serviceImport whose derived service is not created yet",
+			serviceImport: &fleetnetv1alpha1.ServiceImport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: memberUserNS,
+					Name:      svcName,
+				},
+			},
+			want: ctrl.Result{RequeueAfter: endpointSliceImportRetryInterval},
+		},
+		{
+			name: "no serviceImport",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			fakeMemberClientBuilder := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithIndex(&fleetnetv1alpha1.MultiClusterService{}, mcsServiceImportRefFieldKey, func(o client.Object) []string {
+					return []string{o.(*fleetnetv1alpha1.MultiClusterService).Spec.ServiceImport.Name}
+				})
+			if tc.serviceImport != nil {
+				fakeMemberClientBuilder = fakeMemberClientBuilder.WithObjects(tc.serviceImport)
+			}
+			if tc.derivedSvc != nil {
+				fakeMemberClientBuilder = fakeMemberClientBuilder.WithObjects(tc.derivedSvc)
+			}
+			fakeMemberClient := fakeMemberClientBuilder.Build()
+			fakeHubClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(ipv4EndpointSliceImport()).
+				Build()
+			reconciler := Reconciler{
+				MemberClusterID:      memberClusterID,
+				MemberClient:         fakeMemberClient,
+				HubClient:            fakeHubClient,
+				FleetSystemNamespace: fleetSystemNS,
+			}
+
+			got, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: endpointSliceImportKey})
+			if err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+			if !cmp.Equal(got, tc.want) {
+				t.Errorf("Reconcile() = %+v, want %+v", got, tc.want)
+			}
+			endpointSlice := &discoveryv1.EndpointSlice{}
+			err = fakeMemberClient.Get(ctx, types.NamespacedName{Namespace: fleetSystemNS, Name: endpointSliceImportName}, endpointSlice)
+			switch {
+			case tc.wantEndpointSlice && err != nil:
+				t.Fatalf("EndpointSlice Get() = %v, want no error", err)
+			case tc.wantEndpointSlice && endpointSlice.Labels[discoveryv1.LabelServiceName] != derivedSvcName:
+				t.Errorf("EndpointSlice service name label = %q, want %q", endpointSlice.Labels[discoveryv1.LabelServiceName], derivedSvcName)
+			case !tc.wantEndpointSlice && !errors.IsNotFound(err):
+				t.Errorf("EndpointSlice Get() = %v, want NotFound", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package endpointsliceimport
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+// endpointShare is the share of the endpoints of a cluster to import, i.e. keep out of total; the share is applied
+// to every EndpointSlice imported from the cluster.
+type endpointShare struct {
+	keep  int64
+	total int64
+}
+
+// parseClusterWeights parses the cluster weights annotation of a ServiceImport, which is a comma-separated list of
+// <cluster>=<weight> pairs, e.g. "member-1=90,member-2=10"; the weights must be non-negative integers.
+func parseClusterWeights(val string) (map[string]int64, error) {
+	weights := map[string]int64{}
+	for _, item := range strings.Split(val, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		cluster, weightStr, ok := strings.Cut(item, "=")
+		cluster = strings.TrimSpace(cluster)
+		if !ok || cluster == "" {
+			return nil, fmt.Errorf("the value of annotation %s must be a list of <cluster>=<weight> pairs, got %q", objectmeta.ServiceImportAnnotationClusterWeights, item)
+		}
+		weight, err := strconv.ParseInt(strings.TrimSpace(weightStr), 10, 64)
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("the weight of cluster %s must be a non-negative integer, got %q", cluster, weightStr)
+		}
+		weights[cluster] = weight
+	}
+	return weights, nil
+}
+
+// clusterEndpointShares returns the share of the endpoints to import from each cluster backing the ServiceImport, so
+// that the numbers of the imported endpoints, which kube-proxy load-balances the traffic evenly across, are in the
+// ratio of the weights of the clusters. The clusters which are not weighted have a weight of 0, i.e. none of their
+// endpoints are imported.
+//
+// The cluster with the fewest ready endpoints for its weight has all its endpoints imported, and the others are
+// scaled down to match; a cluster with a positive weight keeps at least one endpoint, so that the ratio is only as
+// precise as the numbers of the endpoints allow. It returns nil, i.e. all the endpoints are imported, if no cluster
+// with ready endpoints has a positive weight.
+func clusterEndpointShares(weights map[string]int64, clusters []fleetnetv1alpha1.ServiceImportClusterStatus) map[string]endpointShare {
+	var minCluster *fleetnetv1alpha1.ServiceImportClusterStatus
+	for i := range clusters {
+		c := &clusters[i]
+		if weights[c.Cluster] == 0 || c.ReadyEndpoints == 0 {
+			continue
+		}
+		// Compare ReadyEndpoints/weight of the clusters without dividing.
+		if minCluster == nil || int64(c.ReadyEndpoints)*weights[minCluster.Cluster] < int64(minCluster.ReadyEndpoints)*weights[c.Cluster] {
+			minCluster = c
+		}
+	}
+	if minCluster == nil {
+		return nil
+	}
+
+	shares := make(map[string]endpointShare, len(clusters))
+	for _, c := range clusters {
+		total := int64(c.ReadyEndpoints)
+		if weights[c.Cluster] == 0 || total == 0 {
+			shares[c.Cluster] = endpointShare{keep: 0, total: total}
+			continue
+		}
+		keep := weights[c.Cluster] * int64(minCluster.ReadyEndpoints) / weights[minCluster.Cluster]
+		if keep < 1 {
+			keep = 1
+		}
+		if keep > total {
+			keep = total
+		}
+		shares[c.Cluster] = endpointShare{keep: keep, total: total}
+	}
+	return shares
+}
+
+// selectWeightedEndpoints keeps the share of the endpoints of an imported EndpointSlice, rounded up; the ready
+// endpoints are kept first, and the endpoints are ordered by their addresses so that the same endpoints are kept
+// across reconciliations.
+func selectWeightedEndpoints(endpoints []discoveryv1.Endpoint, share endpointShare) []discoveryv1.Endpoint {
+	if share.total == 0 || share.keep >= share.total {
+		return endpoints
+	}
+	keep := (int64(len(endpoints))*share.keep + share.total - 1) / share.total
+	if keep >= int64(len(endpoints)) {
+		return endpoints
+	}
+	sorted := make([]discoveryv1.Endpoint, len(endpoints))
+	copy(sorted, endpoints)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		iReady, jReady := isReady(&sorted[i]), isReady(&sorted[j])
+		if iReady != jReady {
+			return iReady
+		}
+		return strings.Join(sorted[i].Addresses, ",") < strings.Join(sorted[j].Addresses, ",")
+	})
+	return sorted[:keep]
+}
+
+func isReady(endpoint *discoveryv1.Endpoint) bool {
+	return endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready
+}
@@ -0,0 +1,278 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package endpointsliceimport
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+// TestParseClusterWeights tests the parseClusterWeights function.
+func TestParseClusterWeights(t *testing.T) {
+	testCases := []struct {
+		name    string
+		val     string
+		want    map[string]int64
+		wantErr bool
+	}{
+		{
+			name: "weights",
+			val:  "member-1=90, member-2 = 10,member-3=0",
+			want: map[string]int64{"member-1": 90, "member-2": 10, "member-3": 0},
+		},
+		{
+			name: "empty",
+			val:  "",
+			want: map[string]int64{},
+		},
+		{
+			name:    "missing weight",
+			val:     "member-1",
+			wantErr: true,
+		},
+		{
+			name:    "missing cluster",
+			val:     "=10",
+			wantErr: true,
+		},
+		{
+			name:    "negative weight",
+			val:     "member-1=-1",
+			wantErr: true,
+		},
+		{
+			name:    "invalid weight",
+			val:     "member-1=half",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseClusterWeights(tc.val)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parseClusterWeights() = %v, want error %t", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("parseClusterWeights() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestClusterEndpointShares tests the clusterEndpointShares function.
+func TestClusterEndpointShares(t *testing.T) {
+	testCases := []struct {
+		name     string
+		weights  map[string]int64
+		clusters []fleetnetv1alpha1.ServiceImportClusterStatus
+		want     map[string]endpointShare
+	}{
+		{
+			name:    "scale down to the cluster with the fewest endpoints for its weight",
+			weights: map[string]int64{"member-1": 90, "member-2": 10},
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
+				{Cluster: "member-1", ReadyEndpoints: 9},
+				{Cluster: "member-2", ReadyEndpoints: 10},
+			},
+			want: map[string]endpointShare{
+				"member-1": {keep: 9, total: 9},
+				"member-2": {keep: 1, total: 10},
+			},
+		},
+		{
+			name:    "even weights",
+			weights: map[string]int64{"member-1": 1, "member-2": 1},
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
+				{Cluster: "member-1", ReadyEndpoints: 3},
+				{Cluster: "member-2", ReadyEndpoints: 6},
+			},
+			want: map[string]endpointShare{
+				"member-1": {keep: 3, total: 3},
+				"member-2": {keep: 3, total: 6},
+			},
+		},
+		{
+			name:    "weighted cluster keeps at least one endpoint",
+			weights: map[string]int64{"member-1": 99, "member-2": 1},
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
+				{Cluster: "member-1", ReadyEndpoints: 4},
+				{Cluster: "member-2", ReadyEndpoints: 4},
+			},
+			want: map[string]endpointShare{
+				"member-1": {keep: 4, total: 4},
+				"member-2": {keep: 1, total: 4},
+			},
+		},
+		{
+			name:    "clusters not weighted",
+			weights: map[string]int64{"member-1": 100},
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
+				{Cluster: "member-1", ReadyEndpoints: 2},
+				{Cluster: "member-2", ReadyEndpoints: 4},
+			},
+			want: map[string]endpointShare{
+				"member-1": {keep: 2, total: 2},
+				"member-2": {keep: 0, total: 4},
+			},
+		},
+		{
+			name:    "weighted clusters have no ready endpoints",
+			weights: map[string]int64{"member-1": 100},
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
+				{Cluster: "member-1"},
+				{Cluster: "member-2", ReadyEndpoints: 4},
+			},
+		},
+		{
+			name:    "zero weights",
+			weights: map[string]int64{"member-1": 0, "member-2": 0},
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
+				{Cluster: "member-1", ReadyEndpoints: 2},
+				{Cluster: "member-2", ReadyEndpoints: 4},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := clusterEndpointShares(tc.weights, tc.clusters)
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(endpointShare{})); diff != "" {
+				t.Errorf("clusterEndpointShares() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestSelectWeightedEndpoints tests the selectWeightedEndpoints function.
+func TestSelectWeightedEndpoints(t *testing.T) {
+	endpoints := []discoveryv1.Endpoint{
+		{Addresses: []string{"10.0.0.3"}},
+		{Addresses: []string{"10.0.0.1"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(false), Serving: ptr.To(true)}},
+		{Addresses: []string{"10.0.0.2"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true)}},
+		{Addresses: []string{"10.0.0.4"}},
+	}
+	testCases := []struct {
+		name  string
+		share endpointShare
+		want  []discoveryv1.Endpoint
+	}{
+		{
+			name:  "keep all",
+			share: endpointShare{keep: 4, total: 4},
+			want:  endpoints,
+		},
+		{
+			name:  "keep half, ready endpoints first",
+			share: endpointShare{keep: 2, total: 4},
+			want: []discoveryv1.Endpoint{
+				{Addresses: []string{"10.0.0.2"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true)}},
+				{Addresses: []string{"10.0.0.3"}},
+			},
+		},
+		{
+			name:  "round up",
+			share: endpointShare{keep: 1, total: 8},
+			want: []discoveryv1.Endpoint{
+				{Addresses: []string{"10.0.0.2"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true)}},
+			},
+		},
+		{
+			name:  "keep none",
+			share: endpointShare{keep: 0, total: 4},
+			want:  []discoveryv1.Endpoint{},
+		},
+		{
+			name:  "no ready endpoints in status",
+			share: endpointShare{keep: 0, total: 0},
+			want:  endpoints,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := selectWeightedEndpoints(endpoints, tc.share)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("selectWeightedEndpoints() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestGetEndpointShare tests the Reconciler.getEndpointShare method.
+func TestGetEndpointShare(t *testing.T) {
+	serviceImport := func(weights string) *fleetnetv1alpha1.ServiceImport {
+		svcImport := &fleetnetv1alpha1.ServiceImport{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: memberUserNS,
+				Name:      svcName,
+			},
+			Status: fleetnetv1alpha1.ServiceImportStatus{
+				Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
+					{Cluster: hubNSForMember, ReadyEndpoints: 4},
+					{Cluster: "member-2", ReadyEndpoints: 2},
+				},
+			},
+		}
+		if weights != "" {
+			svcImport.Annotations = map[string]string{objectmeta.ServiceImportAnnotationClusterWeights: weights}
+		}
+		return svcImport
+	}
+	testCases := []struct {
+		name          string
+		serviceImport *fleetnetv1alpha1.ServiceImport
+		want          *endpointShare
+	}{
+		{
+			name:          "weighted serviceImport",
+			serviceImport: serviceImport(hubNSForMember + "=50,member-2=50"),
+			want:          &endpointShare{keep: 2, total: 4},
+		},
+		{
+			name:          "cluster not weighted",
+			serviceImport: serviceImport("member-2=100"),
+			want:          &endpointShare{keep: 0, total: 4},
+		},
+		{
+			name:          "serviceImport without weights",
+			serviceImport: serviceImport(""),
+		},
+		{
+			name:          "invalid weights",
+			serviceImport: serviceImport(hubNSForMember + "=half"),
+		},
+		{
+			name: "no serviceImport",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeMemberClientBuilder := fake.NewClientBuilder().WithScheme(scheme.Scheme)
+			if tc.serviceImport != nil {
+				fakeMemberClientBuilder = fakeMemberClientBuilder.WithObjects(tc.serviceImport)
+			}
+			reconciler := Reconciler{
+				MemberClient:         fakeMemberClientBuilder.Build(),
+				FleetSystemNamespace: fleetSystemNS,
+			}
+			got, err := reconciler.getEndpointShare(context.Background(), ipv4EndpointSliceImport())
+			if err != nil {
+				t.Fatalf("getEndpointShare() = %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(endpointShare{})); diff != "" {
+				t.Errorf("getEndpointShare() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
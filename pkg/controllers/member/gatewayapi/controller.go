@@ -0,0 +1,268 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package gatewayapi
+
+import (
+	"context"
+	"time"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/uniquename"
+	"go.goms.io/fleet-networking/pkg/common/unstructuredkind"
+)
+
+const (
+	// controllerID helps identify that the published EndpointSlices are managed by this controller.
+	controllerID = "gatewayapi-controller.networking.fleet.azure.com"
+
+	// routeBackendServiceImportFieldKey is the field index of the routes by the ServiceImports, in the form of
+	// <namespace>/<name>, referenced by their backendRefs.
+	routeBackendServiceImportFieldKey = ".spec.rules.backendRefs.serviceImport"
+)
+
+// Reconciler reconciles a ServiceImport object referenced as a backend by the Gateway API routes.
+type Reconciler struct {
+	Client client.Client
+	Scheme *runtime.Scheme
+	// The imported EndpointSlices are in the fleet system namespace, along with the derived Services.
+	FleetSystemNamespace string
+	// RouteGVKs are the route kinds whose ServiceImport backends are resolved; their CRDs must be installed in the
+	// member cluster.
+	RouteGVKs []schema.GroupVersionKind
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
+}
+
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes;tcproutes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports,verbs=get;list;watch
+//+kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile publishes the imported endpoints of the ServiceImport into its namespace, as EndpointSlices labeled with
+// its name, if it is referenced as a backend by any route, or deletes the published EndpointSlices otherwise.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	serviceImportKRef := klog.KRef(req.Namespace, req.Name)
+	startTime := time.Now()
+	klog.V(2).InfoS("Reconciliation starts", "serviceImport", serviceImportKRef)
+	defer func() {
+		latency := time.Since(startTime).Milliseconds()
+		klog.V(2).InfoS("Reconciliation ends", "serviceImport", serviceImportKRef, "latency", latency)
+	}()
+
+	serviceImport := &fleetnetv1alpha1.ServiceImport{}
+	if err := r.Client.Get(ctx, req.NamespacedName, serviceImport); err != nil {
+		if errors.IsNotFound(err) {
+			// The published EndpointSlices are owned by the serviceImport and garbage collected along with it.
+			klog.V(4).InfoS("Ignoring NotFound serviceImport", "serviceImport", serviceImportKRef)
+			return ctrl.Result{}, nil
+		}
+		klog.ErrorS(err, "Failed to get serviceImport", "serviceImport", serviceImportKRef)
+		return ctrl.Result{}, err
+	}
+	if serviceImport.DeletionTimestamp != nil {
+		klog.V(4).InfoS("ServiceImport is being deleted", "serviceImport", serviceImportKRef)
+		return ctrl.Result{}, nil
+	}
+
+	referenced, err := r.isReferencedByRoutes(ctx, req.NamespacedName)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	// The derived Service of a serviceImport imported by a MultiClusterService is exposed by the load balancer of
+	// the MultiClusterService instead, and is not labeled on the serviceImport.
+	derivedSvcName := serviceImport.Labels[objectmeta.ServiceImportLabelDerivedService]
+	if !referenced || derivedSvcName == "" {
+		klog.V(4).InfoS("ServiceImport is not referenced by any route or has no derived service", "serviceImport", serviceImportKRef)
+		return ctrl.Result{}, r.deleteStaleEndpointSlices(ctx, serviceImport, nil)
+	}
+
+	importedSliceList := &discoveryv1.EndpointSliceList{}
+	if err := r.Client.List(ctx, importedSliceList, client.InNamespace(r.FleetSystemNamespace), client.MatchingLabels{
+		discoveryv1.LabelServiceName: derivedSvcName,
+	}); err != nil {
+		klog.ErrorS(err, "Failed to list imported endpointSlices", "serviceImport", serviceImportKRef, "derivedService", klog.KRef(r.FleetSystemNamespace, derivedSvcName))
+		return ctrl.Result{}, err
+	}
+
+	desired := make(map[string]bool, len(importedSliceList.Items))
+	for i := range importedSliceList.Items {
+		importedSlice := &importedSliceList.Items[i]
+		if importedSlice.DeletionTimestamp != nil {
+			continue
+		}
+		endpointSlice := &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: serviceImport.Namespace,
+				Name:      uniquename.ClusterScopedDeterministicName(serviceImport.Name, importedSlice.Name),
+			},
+		}
+		desired[endpointSlice.Name] = true
+		endpointSliceKObj := klog.KObj(endpointSlice)
+		if op, err := controllerutil.CreateOrUpdate(ctx, r.Client, endpointSlice, func() error {
+			formatPublishedEndpointSlice(endpointSlice, serviceImport, importedSlice)
+			return controllerutil.SetControllerReference(serviceImport, endpointSlice, r.Scheme)
+		}); err != nil {
+			klog.ErrorS(err, "Failed to create or update published endpointSlice", "serviceImport", serviceImportKRef, "endpointSlice", endpointSliceKObj, "op", op)
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{}, r.deleteStaleEndpointSlices(ctx, serviceImport, desired)
+}
+
+// formatPublishedEndpointSlice formats the EndpointSlice published for the ServiceImport out of an imported
+// EndpointSlice; it is not labeled with a Service name, so that it is left alone by kube-proxy.
+func formatPublishedEndpointSlice(endpointSlice *discoveryv1.EndpointSlice, serviceImport *fleetnetv1alpha1.ServiceImport, importedSlice *discoveryv1.EndpointSlice) {
+	endpointSlice.Labels = map[string]string{
+		objectmeta.EndpointSliceLabelServiceImportName: serviceImport.Name,
+		discoveryv1.LabelManagedBy:                     controllerID,
+	}
+	if cluster, ok := importedSlice.Labels[objectmeta.EndpointSliceLabelSourceCluster]; ok {
+		endpointSlice.Labels[objectmeta.EndpointSliceLabelSourceCluster] = cluster
+	}
+	endpointSlice.AddressType = importedSlice.AddressType
+	endpointSlice.Endpoints = importedSlice.Endpoints
+	endpointSlice.Ports = importedSlice.Ports
+}
+
+// isReferencedByRoutes returns true if the ServiceImport is referenced as a backend by a route of any kind.
+func (r *Reconciler) isReferencedByRoutes(ctx context.Context, serviceImportKey types.NamespacedName) (bool, error) {
+	for _, gvk := range r.RouteGVKs {
+		routeList := &unstructured.UnstructuredList{}
+		routeList.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := r.Client.List(ctx, routeList, client.MatchingFields{routeBackendServiceImportFieldKey: serviceImportKey.String()}); err != nil {
+			klog.ErrorS(err, "Failed to list routes", "kind", gvk.Kind, "serviceImport", serviceImportKey)
+			return false, err
+		}
+		for i := range routeList.Items {
+			if routeList.Items[i].GetDeletionTimestamp() == nil {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// deleteStaleEndpointSlices deletes the EndpointSlices published for the ServiceImport which are not desired.
+func (r *Reconciler) deleteStaleEndpointSlices(ctx context.Context, serviceImport *fleetnetv1alpha1.ServiceImport, desired map[string]bool) error {
+	endpointSliceList := &discoveryv1.EndpointSliceList{}
+	if err := r.Client.List(ctx, endpointSliceList, client.InNamespace(serviceImport.Namespace), client.MatchingLabels{
+		objectmeta.EndpointSliceLabelServiceImportName: serviceImport.Name,
+		discoveryv1.LabelManagedBy:                     controllerID,
+	}); err != nil {
+		klog.ErrorS(err, "Failed to list published endpointSlices", "serviceImport", klog.KObj(serviceImport))
+		return err
+	}
+	for i := range endpointSliceList.Items {
+		endpointSlice := &endpointSliceList.Items[i]
+		if desired[endpointSlice.Name] {
+			continue
+		}
+		klog.V(2).InfoS("Deleting stale published endpointSlice", "endpointSlice", klog.KObj(endpointSlice), "serviceImport", klog.KObj(serviceImport))
+		if err := r.Client.Delete(ctx, endpointSlice); err != nil && !errors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to delete stale published endpointSlice", "endpointSlice", klog.KObj(endpointSlice), "serviceImport", klog.KObj(serviceImport))
+			return err
+		}
+	}
+	return nil
+}
+
+// routeBackendServiceImportIndexerFunc indexes a route by the ServiceImports referenced by its backendRefs.
+func routeBackendServiceImportIndexerFunc(o client.Object) []string {
+	route, ok := o.(*unstructured.Unstructured)
+	if !ok {
+		return []string{}
+	}
+	keys := []string{}
+	for _, key := range backendServiceImports(route) {
+		keys = append(keys, key.String())
+	}
+	return keys
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
+	b := ctrl.NewControllerManagedBy(mgr).
+		Named("gatewayapi").
+		For(&fleetnetv1alpha1.ServiceImport{}).
+		Owns(&discoveryv1.EndpointSlice{}).
+		Watches(
+			&discoveryv1.EndpointSlice{},
+			handler.EnqueueRequestsFromMapFunc(r.importedEndpointSliceEventHandler()),
+		)
+	for _, gvk := range r.RouteGVKs {
+		if err := mgr.GetFieldIndexer().IndexField(ctx, unstructuredkind.New(gvk), routeBackendServiceImportFieldKey, routeBackendServiceImportIndexerFunc); err != nil {
+			klog.ErrorS(err, "Failed to create index", "kind", gvk.Kind, "field", routeBackendServiceImportFieldKey)
+			return err
+		}
+		b = b.Watches(unstructuredkind.New(gvk), routeEventHandler())
+	}
+	return b.WithOptions(r.ControllerOptions).Complete(metrics.InstrumentReconciler("gatewayapi", r))
+}
+
+// importedEndpointSliceEventHandler maps an imported EndpointSlice to the ServiceImports of its derived Service.
+func (r *Reconciler) importedEndpointSliceEventHandler() handler.MapFunc {
+	return func(ctx context.Context, object client.Object) []reconcile.Request {
+		derivedSvcName := object.GetLabels()[discoveryv1.LabelServiceName]
+		if object.GetNamespace() != r.FleetSystemNamespace || derivedSvcName == "" {
+			return []reconcile.Request{}
+		}
+		serviceImportList := &fleetnetv1alpha1.ServiceImportList{}
+		if err := r.Client.List(ctx, serviceImportList, client.MatchingLabels{objectmeta.ServiceImportLabelDerivedService: derivedSvcName}); err != nil {
+			klog.ErrorS(err, "Failed to list serviceImports of the imported endpointSlice", "endpointSlice", klog.KObj(object))
+			return []reconcile.Request{}
+		}
+		requests := make([]reconcile.Request, 0, len(serviceImportList.Items))
+		for i := range serviceImportList.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&serviceImportList.Items[i])})
+		}
+		return requests
+	}
+}
+
+// routeEventHandler enqueues the ServiceImports referenced as backends by a route; on updates, the ServiceImports
+// referenced by the old route are enqueued as well, so that the ones no longer referenced are cleaned up.
+func routeEventHandler() handler.EventHandler {
+	enqueueBackends := func(q workqueue.TypedRateLimitingInterface[reconcile.Request], o client.Object) {
+		route, ok := o.(*unstructured.Unstructured)
+		if !ok {
+			return
+		}
+		for _, key := range backendServiceImports(route) {
+			q.Add(reconcile.Request{NamespacedName: key})
+		}
+	}
+	return handler.Funcs{
+		CreateFunc: func(_ context.Context, e event.CreateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueueBackends(q, e.Object)
+		},
+		UpdateFunc: func(_ context.Context, e event.UpdateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueueBackends(q, e.ObjectOld)
+			enqueueBackends(q, e.ObjectNew)
+		},
+		DeleteFunc: func(_ context.Context, e event.DeleteEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueueBackends(q, e.Object)
+		},
+	}
+}
@@ -0,0 +1,201 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package gatewayapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/unstructuredkind"
+)
+
+const (
+	fleetSystemNamespace = "fleet-system"
+	testServiceImport    = "app"
+	derivedServiceName   = "work-app-xyz"
+	importedSliceName    = "member-2-work-app-abcde"
+)
+
+func serviceImport() *fleetnetv1alpha1.ServiceImport {
+	return &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testServiceImport,
+			UID:       "serviceimport-uid",
+			Labels:    map[string]string{objectmeta.ServiceImportLabelDerivedService: derivedServiceName},
+		},
+	}
+}
+
+func importedEndpointSlice() *discoveryv1.EndpointSlice {
+	return &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: fleetSystemNamespace,
+			Name:      importedSliceName,
+			Labels: map[string]string{
+				discoveryv1.LabelServiceName:               derivedServiceName,
+				discoveryv1.LabelManagedBy:                 "endpointsliceimport-controller.networking.fleet.azure.com",
+				objectmeta.EndpointSliceLabelSourceCluster: "member-2",
+			},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints: []discoveryv1.Endpoint{
+			{
+				Addresses:  []string{"10.1.0.1"},
+				Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true)},
+			},
+		},
+		Ports: []discoveryv1.EndpointPort{
+			{
+				Name:     ptr.To("http"),
+				Protocol: ptr.To(corev1.ProtocolTCP),
+				Port:     ptr.To[int32](8080),
+			},
+		},
+	}
+}
+
+func publishedEndpointSlice(name string) *discoveryv1.EndpointSlice {
+	return &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      name,
+			Labels: map[string]string{
+				objectmeta.EndpointSliceLabelServiceImportName: testServiceImport,
+				discoveryv1.LabelManagedBy:                     controllerID,
+				objectmeta.EndpointSliceLabelSourceCluster:     "member-2",
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion:         fleetnetv1alpha1.GroupVersion.String(),
+					Kind:               "ServiceImport",
+					Name:               testServiceImport,
+					UID:                "serviceimport-uid",
+					Controller:         ptr.To(true),
+					BlockOwnerDeletion: ptr.To(true),
+				},
+			},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints:   importedEndpointSlice().Endpoints,
+		Ports:       importedEndpointSlice().Ports,
+	}
+}
+
+func serviceImportRoute(gvk schema.GroupVersionKind, group string) client.Object {
+	return route(gvk, []interface{}{backendRef(group, "ServiceImport", "", testServiceImport)})
+}
+
+// TestReconcile tests the Reconciler.Reconcile method.
+func TestReconcile(t *testing.T) {
+	wantPublished := publishedEndpointSlice("app-" + importedSliceName)
+	stalePublished := publishedEndpointSlice("app-stale")
+
+	testCases := []struct {
+		name       string
+		objs       []client.Object
+		wantSlices []discoveryv1.EndpointSlice
+	}{
+		{
+			name: "publish imported endpoints of serviceImport referenced by httpRoute",
+			objs: []client.Object{
+				serviceImport(),
+				importedEndpointSlice(),
+				serviceImportRoute(HTTPRouteGVK, "networking.fleet.azure.com"),
+				stalePublished,
+			},
+			wantSlices: []discoveryv1.EndpointSlice{*wantPublished},
+		},
+		{
+			name: "publish imported endpoints of upstream serviceImport referenced by tcpRoute",
+			objs: []client.Object{
+				serviceImport(),
+				importedEndpointSlice(),
+				serviceImportRoute(TCPRouteGVK, "multicluster.x-k8s.io"),
+			},
+			wantSlices: []discoveryv1.EndpointSlice{*wantPublished},
+		},
+		{
+			name: "serviceImport not referenced by any route",
+			objs: []client.Object{
+				serviceImport(),
+				importedEndpointSlice(),
+				route(HTTPRouteGVK, []interface{}{backendRef("", "Service", "", testServiceImport)}),
+				stalePublished,
+			},
+		},
+		{
+			name: "serviceImport without derived service",
+			objs: []client.Object{
+				&fleetnetv1alpha1.ServiceImport{
+					ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testServiceImport},
+				},
+				serviceImportRoute(HTTPRouteGVK, "networking.fleet.azure.com"),
+				stalePublished,
+			},
+		},
+		{
+			name: "serviceImport not found",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := clientgoscheme.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			AddToScheme(scheme)
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(tc.objs...).
+				WithIndex(unstructuredkind.New(HTTPRouteGVK), routeBackendServiceImportFieldKey, routeBackendServiceImportIndexerFunc).
+				WithIndex(unstructuredkind.New(TCPRouteGVK), routeBackendServiceImportFieldKey, routeBackendServiceImportIndexerFunc).
+				Build()
+			r := &Reconciler{
+				Client:               fakeClient,
+				Scheme:               scheme,
+				FleetSystemNamespace: fleetSystemNamespace,
+				RouteGVKs:            []schema.GroupVersionKind{HTTPRouteGVK, TCPRouteGVK},
+			}
+			key := types.NamespacedName{Namespace: testNamespace, Name: testServiceImport}
+			if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+
+			endpointSliceList := &discoveryv1.EndpointSliceList{}
+			if err := fakeClient.List(context.Background(), endpointSliceList, client.InNamespace(testNamespace)); err != nil {
+				t.Fatalf("EndpointSlice List() got error %v, want no error", err)
+			}
+			options := []cmp.Option{
+				cmpopts.IgnoreFields(metav1.ObjectMeta{}, "ResourceVersion"),
+				cmpopts.IgnoreFields(metav1.TypeMeta{}, "Kind", "APIVersion"),
+				cmpopts.EquateEmpty(),
+			}
+			if diff := cmp.Diff(tc.wantSlices, endpointSliceList.Items, options...); diff != "" {
+				t.Errorf("published endpointSlices mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
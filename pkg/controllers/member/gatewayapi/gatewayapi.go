@@ -0,0 +1,101 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package gatewayapi features the multi-cluster backends of the Gateway API (gateway.networking.k8s.io), so that the
+// HTTPRoutes and TCPRoutes in a member cluster can route the traffic to the services in the other member clusters by
+// referencing their ServiceImports, of either the fleet-networking or the upstream Multi-Cluster Services API group,
+// as backends.
+//
+// Gateway API implementations resolve a ServiceImport backend, per the Multi-Cluster Services API, to the
+// EndpointSlices in the namespace of the ServiceImport labeled with its name; the imported EndpointSlices are kept in
+// the fleet system namespace instead, along with the derived Service. The gatewayapi controller therefore publishes
+// the imported endpoints of each ServiceImport referenced by a route into the namespace of the ServiceImport.
+// The routes are handled as unstructured objects, so that the controller does not depend on the upstream module.
+package gatewayapi
+
+import (
+	"fmt"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/unstructuredkind"
+	"go.goms.io/fleet-networking/pkg/controllers/member/mcsapi"
+)
+
+const (
+	// GroupName is the group of the Gateway API.
+	GroupName = "gateway.networking.k8s.io"
+
+	serviceImportKind = "ServiceImport"
+)
+
+var (
+	// HTTPRouteGVK is the group version kind of the HTTPRoute.
+	HTTPRouteGVK = schema.GroupVersionKind{Group: GroupName, Version: "v1", Kind: "HTTPRoute"}
+	// TCPRouteGVK is the group version kind of the TCPRoute, which is part of the experimental channel.
+	TCPRouteGVK = schema.GroupVersionKind{Group: GroupName, Version: "v1alpha2", Kind: "TCPRoute"}
+
+	// routeGVKs are the route kinds whose ServiceImport backends are resolved.
+	routeGVKs = []schema.GroupVersionKind{HTTPRouteGVK, TCPRouteGVK}
+
+	// serviceImportGroups are the groups of the ServiceImports which can be referenced as backends; an upstream
+	// ServiceImport is translated into the fleet-networking ServiceImport of the same namespace and name.
+	serviceImportGroups = []string{fleetnetv1alpha1.GroupVersion.Group, mcsapi.GroupVersion.Group}
+)
+
+// RouteGVKForKind returns the group version kind of the route kind, e.g. HTTPRoute.
+func RouteGVKForKind(kind string) (schema.GroupVersionKind, error) {
+	for _, gvk := range routeGVKs {
+		if gvk.Kind == kind {
+			return gvk, nil
+		}
+	}
+	return schema.GroupVersionKind{}, fmt.Errorf("unsupported Gateway API route kind %q", kind)
+}
+
+// AddToScheme registers the route kinds, and their lists, as unstructured objects in the scheme.
+func AddToScheme(scheme *runtime.Scheme) {
+	unstructuredkind.AddToScheme(scheme, routeGVKs...)
+}
+
+// backendServiceImports returns the ServiceImports referenced by the backendRefs of the rules of the route, without
+// duplicates; a backendRef without a namespace refers to the namespace of the route.
+func backendServiceImports(route *unstructured.Unstructured) []types.NamespacedName {
+	rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+	var serviceImports []types.NamespacedName
+	for _, rule := range rules {
+		ruleObj, ok := rule.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		backendRefs, _, _ := unstructured.NestedSlice(ruleObj, "backendRefs")
+		for _, backendRef := range backendRefs {
+			refObj, ok := backendRef.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			group, _, _ := unstructured.NestedString(refObj, "group")
+			kind, _, _ := unstructured.NestedString(refObj, "kind")
+			name, _, _ := unstructured.NestedString(refObj, "name")
+			if kind != serviceImportKind || !slices.Contains(serviceImportGroups, group) || name == "" {
+				continue
+			}
+			namespace, _, _ := unstructured.NestedString(refObj, "namespace")
+			if namespace == "" {
+				namespace = route.GetNamespace()
+			}
+			key := types.NamespacedName{Namespace: namespace, Name: name}
+			if !slices.Contains(serviceImports, key) {
+				serviceImports = append(serviceImports, key)
+			}
+		}
+	}
+	return serviceImports
+}
@@ -0,0 +1,122 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package gatewayapi
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	"go.goms.io/fleet-networking/pkg/common/unstructuredkind"
+)
+
+const (
+	testNamespace = "work"
+	testRouteName = "app-route"
+)
+
+// backendRef returns a backendRef of a route in its unstructured form.
+func backendRef(group, kind, namespace, name string) map[string]interface{} {
+	ref := map[string]interface{}{"name": name, "port": int64(80)}
+	if group != "" {
+		ref["group"] = group
+	}
+	if kind != "" {
+		ref["kind"] = kind
+	}
+	if namespace != "" {
+		ref["namespace"] = namespace
+	}
+	return ref
+}
+
+// route returns a route of the given kind with a rule of each list of backendRefs.
+func route(gvk schema.GroupVersionKind, rules ...[]interface{}) *unstructured.Unstructured {
+	obj := unstructuredkind.New(gvk)
+	obj.SetNamespace(testNamespace)
+	obj.SetName(testRouteName)
+	ruleList := make([]interface{}, 0, len(rules))
+	for _, backendRefs := range rules {
+		ruleList = append(ruleList, map[string]interface{}{"backendRefs": backendRefs})
+	}
+	obj.Object["spec"] = map[string]interface{}{"rules": ruleList}
+	return obj
+}
+
+// TestBackendServiceImports tests the backendServiceImports function.
+func TestBackendServiceImports(t *testing.T) {
+	testCases := []struct {
+		name  string
+		route *unstructured.Unstructured
+		want  []types.NamespacedName
+	}{
+		{
+			name: "serviceImports of both groups",
+			route: route(HTTPRouteGVK,
+				[]interface{}{
+					backendRef("networking.fleet.azure.com", "ServiceImport", "", "app"),
+					backendRef("multicluster.x-k8s.io", "ServiceImport", "other", "db"),
+				},
+				[]interface{}{
+					backendRef("networking.fleet.azure.com", "ServiceImport", "", "app"),
+				},
+			),
+			want: []types.NamespacedName{
+				{Namespace: testNamespace, Name: "app"},
+				{Namespace: "other", Name: "db"},
+			},
+		},
+		{
+			name: "services and other kinds are skipped",
+			route: route(TCPRouteGVK,
+				[]interface{}{
+					backendRef("", "", "", "app"),
+					backendRef("", "Service", "", "db"),
+					backendRef("example.com", "ServiceImport", "", "cache"),
+				},
+			),
+		},
+		{
+			name:  "route without rules",
+			route: unstructuredkind.New(HTTPRouteGVK),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := backendServiceImports(tc.route)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("backendServiceImports() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestRouteGVKForKind tests the RouteGVKForKind function.
+func TestRouteGVKForKind(t *testing.T) {
+	testCases := []struct {
+		kind    string
+		want    schema.GroupVersionKind
+		wantErr bool
+	}{
+		{kind: "HTTPRoute", want: HTTPRouteGVK},
+		{kind: "TCPRoute", want: TCPRouteGVK},
+		{kind: "GRPCRoute", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.kind, func(t *testing.T) {
+			got, err := RouteGVKForKind(tc.kind)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("RouteGVKForKind() = %v, want error %t", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("RouteGVKForKind() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
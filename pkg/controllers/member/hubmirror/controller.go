@@ -0,0 +1,164 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package hubmirror features the hubmirror controllers deployed in member cluster, which export the services of a
+// member cluster joining more than one hub cluster to its additional hub clusters.
+//
+// The exports of the member cluster are made to its primary hub cluster as usual; the controllers mirror the
+// InternalServiceExports and EndpointSliceExports the agent keeps in the primary hub cluster to the namespace
+// reserved for the member cluster in an additional hub cluster, so that each hub cluster resolves the exports on its
+// own, while the ServiceExports report the status of the exports from the primary hub cluster only.
+package hubmirror
+
+import (
+	"context"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+)
+
+// Reconciler mirrors the exports of one kind from the primary hub cluster to an additional hub cluster.
+type Reconciler struct {
+	// PrimaryHubClient reads the exports in the primary hub cluster.
+	PrimaryHubClient client.Client
+	// PrimaryHubNamespace is the namespace reserved for the member cluster in the primary hub cluster.
+	PrimaryHubNamespace string
+	// HubClient writes the mirrors in the additional hub cluster.
+	HubClient client.Client
+	// HubNamespace is the namespace reserved for the member cluster in the additional hub cluster.
+	HubNamespace string
+	// HubName is the name of the additional hub cluster.
+	HubName string
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
+
+	// kind is the kind of the mirrored exports.
+	kind string
+	// newObject returns an empty export of the kind.
+	newObject func() client.Object
+	// mirrorSpec copies the spec of an export of the kind to its mirror.
+	mirrorSpec func(export, mirror client.Object)
+}
+
+// NewInternalServiceExportReconciler returns a Reconciler mirroring the InternalServiceExports.
+func NewInternalServiceExportReconciler(r Reconciler) *Reconciler {
+	r.kind = "internalserviceexport"
+	r.newObject = func() client.Object { return &fleetnetv1alpha1.InternalServiceExport{} }
+	r.mirrorSpec = func(export, mirror client.Object) {
+		export.(*fleetnetv1alpha1.InternalServiceExport).Spec.DeepCopyInto(&mirror.(*fleetnetv1alpha1.InternalServiceExport).Spec)
+	}
+	return &r
+}
+
+// NewEndpointSliceExportReconciler returns a Reconciler mirroring the EndpointSliceExports.
+func NewEndpointSliceExportReconciler(r Reconciler) *Reconciler {
+	r.kind = "endpointsliceexport"
+	r.newObject = func() client.Object { return &fleetnetv1alpha1.EndpointSliceExport{} }
+	r.mirrorSpec = func(export, mirror client.Object) {
+		export.(*fleetnetv1alpha1.EndpointSliceExport).Spec.DeepCopyInto(&mirror.(*fleetnetv1alpha1.EndpointSliceExport).Spec)
+	}
+	return &r
+}
+
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalserviceexports,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=endpointsliceexports,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile mirrors an export of the primary hub cluster to the additional hub cluster, or deletes its mirror if the
+// export is gone.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	mirrorRef := klog.KRef(req.Namespace, req.Name)
+	logger := klog.FromContext(ctx).WithValues("kind", r.kind, "mirror", mirrorRef, "hub", r.HubName)
+	ctx = klog.NewContext(ctx, logger)
+	startTime := time.Now()
+	logger.V(2).Info("Reconciliation starts")
+	defer func() {
+		latency := time.Since(startTime).Milliseconds()
+		logger.V(2).Info("Reconciliation ends", "latency", latency)
+	}()
+
+	export := r.newObject()
+	exportKey := types.NamespacedName{Namespace: r.PrimaryHubNamespace, Name: req.Name}
+	if err := r.PrimaryHubClient.Get(ctx, exportKey, export); err != nil {
+		if !apierrors.IsNotFound(err) {
+			logger.Error(err, "Failed to get the export in the primary hub cluster", "export", klog.KRef(exportKey.Namespace, exportKey.Name))
+			return ctrl.Result{}, err
+		}
+		export = nil
+	}
+
+	mirror := r.newObject()
+	mirror.SetNamespace(r.HubNamespace)
+	mirror.SetName(req.Name)
+	if export == nil || export.GetDeletionTimestamp() != nil {
+		logger.V(2).Info("The export is withdrawn from the primary hub cluster; delete its mirror")
+		if err := r.HubClient.Delete(ctx, mirror); err != nil && !apierrors.IsNotFound(err) {
+			logger.Error(err, "Failed to delete the mirror")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	op, err := controllerutil.CreateOrUpdate(ctx, r.HubClient, mirror, func() error {
+		r.mirrorSpec(export, mirror)
+		// The labels and annotations set by the agent, e.g. the service of the export, are mirrored along with the
+		// spec; the ones set by the additional hub cluster are kept.
+		mirror.SetLabels(mergeStringMap(mirror.GetLabels(), export.GetLabels()))
+		mirror.SetAnnotations(mergeStringMap(mirror.GetAnnotations(), export.GetAnnotations()))
+		return nil
+	})
+	if err != nil {
+		logger.Error(err, "Failed to mirror the export", "op", op)
+		return ctrl.Result{}, err
+	}
+	logger.V(4).Info("Mirrored the export", "op", op)
+	return ctrl.Result{}, nil
+}
+
+// mergeStringMap sets the entries of src in dst, and returns dst.
+func mergeStringMap(dst, src map[string]string) map[string]string {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[string]string, len(src))
+	}
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
+
+// SetupWithManager sets up the controller with the Manager of the additional hub cluster; the exports are watched in
+// the primary hub cluster through the given cluster, and their mirrors are keyed by the same names.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager, primary cluster.Cluster) error {
+	name := "hubmirror-" + r.kind + "-" + r.HubName
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		// Restore the mirrors changed or deleted out-of-band, and delete the ones left over as the agent restarts.
+		For(r.newObject()).
+		WatchesRawSource(source.Kind(primary.GetCache(), r.newObject(),
+			handler.TypedEnqueueRequestsFromMapFunc(func(_ context.Context, o client.Object) []reconcile.Request {
+				if o.GetNamespace() != r.PrimaryHubNamespace {
+					return nil
+				}
+				return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: r.HubNamespace, Name: o.GetName()}}}
+			}))).
+		WithOptions(r.ControllerOptions).
+		Complete(metrics.InstrumentReconciler(name, r))
+}
@@ -0,0 +1,181 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package hubmirror
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+const (
+	primaryHubNS = "fleet-member-bravelion"
+	hubNS        = "fleet-member-bravelion-eu"
+	hubName      = "eu"
+	exportName   = "work-app"
+	hubLabelKey  = "hub.example.com/team"
+)
+
+func testScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	return scheme
+}
+
+func internalServiceExport(namespace string, port int32) *fleetnetv1alpha1.InternalServiceExport {
+	return &fleetnetv1alpha1.InternalServiceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      exportName,
+			Labels:    map[string]string{"networking.fleet.azure.com/service": "app"},
+		},
+		Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+			Ports: []fleetnetv1alpha1.ServicePort{
+				{Name: "http", Protocol: corev1.ProtocolTCP, Port: port},
+			},
+			ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+				ClusterID:       "bravelion",
+				Kind:            "Service",
+				Namespace:       "work",
+				Name:            "app",
+				ResourceVersion: "1",
+				Generation:      1,
+				UID:             "1",
+			},
+		},
+	}
+}
+
+func TestReconcile_InternalServiceExport(t *testing.T) {
+	deletingExport := internalServiceExport(primaryHubNS, 80)
+	deletingExport.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	deletingExport.Finalizers = []string{"networking.fleet.azure.com/internal-svc-export-cleanup"}
+	staleMirror := internalServiceExport(hubNS, 8080)
+	staleMirror.Labels[hubLabelKey] = "web"
+
+	testCases := []struct {
+		name       string
+		primaryObj []client.Object
+		hubObj     []client.Object
+		want       *fleetnetv1alpha1.InternalServiceExport
+	}{
+		{
+			name:       "create the mirror",
+			primaryObj: []client.Object{internalServiceExport(primaryHubNS, 80)},
+			want:       internalServiceExport(hubNS, 80),
+		},
+		{
+			name:       "update the mirror and keep the labels set by the hub",
+			primaryObj: []client.Object{internalServiceExport(primaryHubNS, 80)},
+			hubObj:     []client.Object{staleMirror},
+			want: func() *fleetnetv1alpha1.InternalServiceExport {
+				want := internalServiceExport(hubNS, 80)
+				want.Labels[hubLabelKey] = "web"
+				return want
+			}(),
+		},
+		{
+			name:   "delete the mirror of a withdrawn export",
+			hubObj: []client.Object{internalServiceExport(hubNS, 80)},
+		},
+		{
+			name:       "delete the mirror of a deleting export",
+			primaryObj: []client.Object{deletingExport},
+			hubObj:     []client.Object{internalServiceExport(hubNS, 80)},
+		},
+		{
+			name: "no mirror of a withdrawn export",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			scheme := testScheme(t)
+			primaryClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.primaryObj...).Build()
+			hubClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.hubObj...).Build()
+			r := NewInternalServiceExportReconciler(Reconciler{
+				PrimaryHubClient:    primaryClient,
+				PrimaryHubNamespace: primaryHubNS,
+				HubClient:           hubClient,
+				HubNamespace:        hubNS,
+				HubName:             hubName,
+			})
+
+			key := types.NamespacedName{Namespace: hubNS, Name: exportName}
+			if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+
+			got := &fleetnetv1alpha1.InternalServiceExport{}
+			err := hubClient.Get(ctx, key, got)
+			if tc.want == nil {
+				if !apierrors.IsNotFound(err) {
+					t.Fatalf("InternalServiceExport Get() = %v, want not found", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("InternalServiceExport Get() = %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.want, got, cmpopts.IgnoreFields(metav1.ObjectMeta{}, "ResourceVersion")); diff != "" {
+				t.Errorf("InternalServiceExport mirror mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReconcile_EndpointSliceExport(t *testing.T) {
+	ctx := context.Background()
+	scheme := testScheme(t)
+	export := &fleetnetv1alpha1.EndpointSliceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: primaryHubNS,
+			Name:      exportName,
+		},
+		Spec: fleetnetv1alpha1.EndpointSliceExportSpec{
+			AddressType: "IPv4",
+			Endpoints: []fleetnetv1alpha1.Endpoint{
+				{Addresses: []string{"1.2.3.4"}},
+			},
+		},
+	}
+	primaryClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(export).Build()
+	hubClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	r := NewEndpointSliceExportReconciler(Reconciler{
+		PrimaryHubClient:    primaryClient,
+		PrimaryHubNamespace: primaryHubNS,
+		HubClient:           hubClient,
+		HubNamespace:        hubNS,
+		HubName:             hubName,
+	})
+
+	key := types.NamespacedName{Namespace: hubNS, Name: exportName}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile() = %v, want no error", err)
+	}
+	got := &fleetnetv1alpha1.EndpointSliceExport{}
+	if err := hubClient.Get(ctx, key, got); err != nil {
+		t.Fatalf("EndpointSliceExport Get() = %v, want no error", err)
+	}
+	if diff := cmp.Diff(export.Spec, got.Spec); diff != "" {
+		t.Errorf("EndpointSliceExport mirror spec mismatch (-want, +got):\n%s", diff)
+	}
+}
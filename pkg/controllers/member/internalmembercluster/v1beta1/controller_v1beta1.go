@@ -18,11 +18,13 @@ import (
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 
 	clusterv1beta1 "go.goms.io/fleet/apis/cluster/v1beta1"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 	"go.goms.io/fleet-networking/pkg/common/apiretry"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
 )
 
 const (
@@ -38,16 +40,21 @@ type Reconciler struct {
 	MemberClient client.Client
 	HubClient    client.Client
 	AgentType    clusterv1beta1.AgentType
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
 }
 
 //+kubebuilder:rbac:groups=cluster.kubernetes-fleet.io,resources=internalmemberclusters,verbs=get;list;watch
 //+kubebuilder:rbac:groups=cluster.kubernetes-fleet.io,resources=internalmemberclusters/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=multiclusterservices,verbs=get;list;delete
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceexports,verbs=get;list;delete
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports,verbs=get;list;delete
 
 // Reconcile handles join/leave for the member cluster controllers and updates its heartbeats.
 // For the MCS controller, it needs to delete created MCS related in the member clusters.
-// For the ServiceExportImport controllers, it needs to delete created serviceExported related in the member clusters.
+// For the ServiceExportImport controllers, it needs to delete created serviceExported related in the member clusters,
+// and to withdraw the serviceImports so that the fleet stops distributing endpoints to the member clusters.
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	imcKRef := klog.KRef(req.Namespace, req.Name)
 	startTime := time.Now()
@@ -82,6 +89,9 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			if err := r.cleanupServiceExportRelatedResources(ctx); err != nil {
 				return ctrl.Result{}, err
 			}
+			if err := r.cleanupServiceImportRelatedResources(ctx); err != nil {
+				return ctrl.Result{}, err
+			}
 		}
 
 		// Update the agent status.
@@ -223,9 +233,52 @@ func (r *Reconciler) cleanupServiceExportRelatedResources(ctx context.Context) e
 	return nil
 }
 
+// cleanupServiceImportRelatedResources deletes the serviceImports which are not owned by a MCS, whose finalizers
+// withdraw their internalServiceImports from the hub cluster before the imported endpoints are removed; the
+// serviceImports owned by a MCS are deleted with the MCS by the MCS agent.
+func (r *Reconciler) cleanupServiceImportRelatedResources(ctx context.Context) error {
+	list := &fleetnetv1alpha1.ServiceImportList{}
+	if err := r.MemberClient.List(ctx, list); err != nil {
+		klog.ErrorS(err, "Failed to list service import")
+		return err
+	}
+	var deleted []types.NamespacedName
+	for i := range list.Items {
+		if list.Items[i].ObjectMeta.DeletionTimestamp != nil {
+			continue
+		}
+		if owner := metav1.GetControllerOf(&list.Items[i]); owner != nil && owner.Kind == "MultiClusterService" {
+			continue
+		}
+		deleteFunc := func() error {
+			return r.MemberClient.Delete(ctx, &list.Items[i])
+		}
+		if err := apiretry.Do(deleteFunc); err != nil && !apierrors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to delete service import", "serviceImport", klog.KObj(&list.Items[i]))
+			return err
+		}
+		deleted = append(deleted, types.NamespacedName{Namespace: list.Items[i].GetNamespace(), Name: list.Items[i].GetName()})
+	}
+
+	for _, name := range deleted {
+		svcImport := fleetnetv1alpha1.ServiceImport{}
+		getFunc := func() error {
+			return r.MemberClient.Get(ctx, name, &svcImport)
+		}
+		if err := apiretry.WaitUntilObjectDeleted(ctx, getFunc); err != nil {
+			klog.ErrorS(err, "The service import has not been deleted in time", "serviceImport", name)
+			return err
+		}
+	}
+
+	klog.V(2).InfoS("Cleanup of service import related resources has been completed", "objectCounter", len(deleted))
+	return nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&clusterv1beta1.InternalMemberCluster{}).
-		Complete(r)
+		WithOptions(r.ControllerOptions).
+		Complete(metrics.InstrumentReconciler("internalmembercluster", r))
 }
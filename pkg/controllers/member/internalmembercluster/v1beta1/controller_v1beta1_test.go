@@ -16,6 +16,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	clusterv1beta1 "go.goms.io/fleet/apis/cluster/v1beta1"
@@ -284,3 +285,57 @@ func TestCleanupServiceExportRelatedResources(t *testing.T) {
 		}
 	}
 }
+
+// TestCleanupServiceImportRelatedResources tests the cleanupServiceImportRelatedResources method.
+func TestCleanupServiceImportRelatedResources(t *testing.T) {
+	svcImports := []fleetnetv1alpha1.ServiceImport{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      svcExportName1,
+				Namespace: workNamespaceName,
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      svcExportName2,
+				Namespace: workNamespaceName,
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion: fleetnetv1alpha1.GroupVersion.String(),
+						Kind:       "MultiClusterService",
+						Name:       mcsName1,
+						Controller: ptr.To(true),
+					},
+				},
+			},
+		},
+	}
+
+	fakeHubClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	fakeMemberClientBuilder := fake.NewClientBuilder().WithScheme(scheme.Scheme)
+	for idx := range svcImports {
+		svcImport := svcImports[idx]
+		fakeMemberClientBuilder.WithObjects(&svcImport)
+	}
+	fakeMemberClient := fakeMemberClientBuilder.Build()
+	reconciler := &Reconciler{
+		MemberClient: fakeMemberClient,
+		HubClient:    fakeHubClient,
+		AgentType:    serviceExportImportAgentType,
+	}
+
+	ctx := context.Background()
+	if err := reconciler.cleanupServiceImportRelatedResources(ctx); err != nil {
+		t.Fatalf("cleanupServiceImportRelatedResources() = %v, want no error", err)
+	}
+
+	key := types.NamespacedName{Namespace: workNamespaceName, Name: svcExportName1}
+	if err := fakeMemberClient.Get(ctx, key, &fleetnetv1alpha1.ServiceImport{}); !errors.IsNotFound(err) {
+		t.Errorf("serviceImport %s still exists", key)
+	}
+	// The serviceImports owned by a MCS are left to the MCS agent.
+	key = types.NamespacedName{Namespace: workNamespaceName, Name: svcExportName2}
+	if err := fakeMemberClient.Get(ctx, key, &fleetnetv1alpha1.ServiceImport{}); err != nil {
+		t.Errorf("serviceImport %s Get() = %v, want no error", key, err)
+	}
+}
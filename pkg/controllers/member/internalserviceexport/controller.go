@@ -22,6 +22,7 @@ import (
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
@@ -33,6 +34,15 @@ const (
 	ControllerName = "internalserviceexport-controller"
 )
 
+// reportedHubConditionTypes are the types of the conditions which the hub adds to the InternalServiceExports and
+// which are reported back as they are to the ServiceExports.
+var reportedHubConditionTypes = []fleetnetv1alpha1.ServiceExportConditionType{
+	fleetnetv1alpha1.ServiceExportImported,
+	fleetnetv1alpha1.ServiceExportPendingApproval,
+	fleetnetv1alpha1.ServiceExportQuotaExceeded,
+	fleetnetv1alpha1.ServiceExportTenantConflict,
+}
+
 var (
 	// svcExportDuration is a Prometheus histogram metric bundle that measures that time it takes for
 	// Fleet networking controllers to export a valid Service with no conflicts. That is, the
@@ -71,6 +81,9 @@ type Reconciler struct {
 	MemberClient    client.Client
 	HubClient       client.Client
 	Recorder        record.EventRecorder
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
 }
 
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalserviceexports,verbs=get;list;watch;create;update;patch;delete
@@ -137,6 +150,17 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return ctrl.Result{}, err
 	}
 
+	// Report back whether the Service is imported by other member clusters, if the hub tracks the demand, whether
+	// the export is pending approval, if the hub requires the exports to be approved, whether the export is over
+	// quota, if the hub enforces export quotas, and whether the Service is owned by another tenant, if the hub
+	// isolates the tenants.
+	for _, condType := range reportedHubConditionTypes {
+		if err := r.reportBackHubCondition(ctx, &svcExport, &internalSvcExport, condType); err != nil {
+			klog.ErrorS(err, "Failed to report back hub condition", "serviceExport", svcExportRef, "conditionType", condType)
+			return ctrl.Result{}, err
+		}
+	}
+
 	// Observe a data point for the svcExportDuration metric.
 	// Note that an observation happens only when there is a conflict resolution result to report back.
 	if reported {
@@ -152,7 +176,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 // SetupWithManager builds a controller with InternalSvcExportReconciler and sets it up with a
 // (multi-namespaced) controller manager.
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).For(&fleetnetv1alpha1.InternalServiceExport{}).Complete(r)
+	return ctrl.NewControllerManagedBy(mgr).For(&fleetnetv1alpha1.InternalServiceExport{}).WithOptions(r.ControllerOptions).Complete(metrics.InstrumentReconciler("internalserviceexport", r))
 }
 
 // reportBackConflictCond reports the ServiceExportConflict condition added to the InternalServiceExport object in the
@@ -191,6 +215,28 @@ func (r *Reconciler) reportBackConflictCondition(ctx context.Context,
 	return true, r.MemberClient.Status().Update(ctx, svcExport)
 }
 
+// reportBackHubCondition reports the condition of the given type added to the InternalServiceExport object in the
+// hub cluster back to the ServiceExport object in the member cluster; the condition is removed from the
+// ServiceExport if the hub no longer reports it.
+func (r *Reconciler) reportBackHubCondition(ctx context.Context,
+	svcExport *fleetnetv1alpha1.ServiceExport,
+	internalSvcExport *fleetnetv1alpha1.InternalServiceExport,
+	condType fleetnetv1alpha1.ServiceExportConditionType) error {
+	internalSvcExportCond := meta.FindStatusCondition(internalSvcExport.Status.Conditions, string(condType))
+	svcExportCond := meta.FindStatusCondition(svcExport.Status.Conditions, string(condType))
+	if reflect.DeepEqual(internalSvcExportCond, svcExportCond) {
+		return nil
+	}
+
+	if internalSvcExportCond == nil {
+		meta.RemoveStatusCondition(&svcExport.Status.Conditions, string(condType))
+	} else {
+		meta.SetStatusCondition(&svcExport.Status.Conditions, *internalSvcExportCond)
+	}
+	klog.V(2).InfoS("Reporting back hub condition", "serviceExport", klog.KObj(svcExport), "conditionType", condType, "condition", internalSvcExportCond)
+	return r.MemberClient.Status().Update(ctx, svcExport)
+}
+
 // Observe data points for metrics.
 func (r *Reconciler) observeMetrics(ctx context.Context,
 	internalSvcExport *fleetnetv1alpha1.InternalServiceExport,
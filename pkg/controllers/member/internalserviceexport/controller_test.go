@@ -216,6 +216,100 @@ func TestReportBackConflictCondition(t *testing.T) {
 	}
 }
 
+// TestReportBackHubCondition tests the Reconciler.reportBackHubCondition function.
+func TestReportBackHubCondition(t *testing.T) {
+	importedCond := metav1.Condition{
+		Type:   string(fleetnetv1alpha1.ServiceExportImported),
+		Status: metav1.ConditionTrue,
+		Reason: "ImportedByOtherClusters",
+	}
+	pendingApprovalCond := metav1.Condition{
+		Type:   string(fleetnetv1alpha1.ServiceExportPendingApproval),
+		Status: metav1.ConditionTrue,
+		Reason: "AwaitingApproval",
+	}
+	testCases := []struct {
+		name          string
+		condType      fleetnetv1alpha1.ServiceExportConditionType
+		svcExportCond []metav1.Condition
+		internalCond  []metav1.Condition
+		wantConds     []metav1.Condition
+	}{
+		{
+			name:     "no demand reported",
+			condType: fleetnetv1alpha1.ServiceExportImported,
+		},
+		{
+			name:         "should report back imported cond",
+			condType:     fleetnetv1alpha1.ServiceExportImported,
+			internalCond: []metav1.Condition{importedCond},
+			wantConds:    []metav1.Condition{importedCond},
+		},
+		{
+			name:          "should remove imported cond no longer reported",
+			condType:      fleetnetv1alpha1.ServiceExportImported,
+			svcExportCond: []metav1.Condition{importedCond},
+		},
+		{
+			name:         "should report back pending approval cond only",
+			condType:     fleetnetv1alpha1.ServiceExportPendingApproval,
+			internalCond: []metav1.Condition{importedCond, pendingApprovalCond},
+			wantConds:    []metav1.Condition{pendingApprovalCond},
+		},
+		{
+			name:          "should remove pending approval cond once approved",
+			condType:      fleetnetv1alpha1.ServiceExportPendingApproval,
+			svcExportCond: []metav1.Condition{pendingApprovalCond},
+		},
+	}
+
+	ctx := context.Background()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svcExport := &fleetnetv1alpha1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: memberUserNS,
+					Name:      svcName,
+				},
+				Status: fleetnetv1alpha1.ServiceExportStatus{
+					Conditions: tc.svcExportCond,
+				},
+			}
+			internalSvcExport := &fleetnetv1alpha1.InternalServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: hubNSForMember,
+					Name:      internalSvcExportName,
+				},
+				Status: fleetnetv1alpha1.InternalServiceExportStatus{
+					Conditions: tc.internalCond,
+				},
+			}
+			fakeMemberClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(svcExport).
+				WithStatusSubresource(svcExport).
+				Build()
+			reconciler := Reconciler{
+				MemberClient: fakeMemberClient,
+				HubClient:    fake.NewClientBuilder().Build(),
+				Recorder:     record.NewFakeRecorder(10),
+			}
+
+			if err := reconciler.reportBackHubCondition(ctx, svcExport, internalSvcExport, tc.condType); err != nil {
+				t.Fatalf("reportBackHubCondition() = %v, want no error", err)
+			}
+
+			updatedSvcExport := &fleetnetv1alpha1.ServiceExport{}
+			if err := fakeMemberClient.Get(ctx, svcExportKey, updatedSvcExport); err != nil {
+				t.Fatalf("failed to get updated svc export: %v", err)
+			}
+			if !cmp.Equal(updatedSvcExport.Status.Conditions, tc.wantConds, ignoredCondFields, cmpopts.EquateEmpty()) {
+				t.Fatalf("conds are not correctly updated, got %+v, want %+v", updatedSvcExport.Status.Conditions, tc.wantConds)
+			}
+		})
+	}
+}
+
 // TestObserveMetrics tests the Reconciler.observeMetrics function.
 func TestObserveMetrics(t *testing.T) {
 	metricMetadata := `
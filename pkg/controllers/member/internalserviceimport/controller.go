@@ -11,34 +11,57 @@ import (
 	"context"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/hubconfig"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/pause"
+)
+
+const (
+	// ControllerName is the name of the Reconciler.
+	ControllerName = "internalserviceimport-controller"
 )
 
 // Reconciler reconciles a InternalServiceImport object.
 type Reconciler struct {
 	MemberClient client.Client
 	HubClient    client.Client
+	// Recorder records the Events telling the users when a service starts and stops being imported from the fleet.
+	Recorder record.EventRecorder
+	// HubName is the name of the additional hub cluster the controller imports the services from, or empty for the
+	// primary hub cluster; the status of the ServiceImports annotated with another hub cluster is left as it is.
+	HubName string
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
 }
 
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalserviceimports,verbs=get;list;watch;delete
-//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports,verbs=get;list
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports,verbs=get;list;update
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile reports back ServiceImport status from the fleet to a member cluster.
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	internalSvcImportKRef := klog.KRef(req.Namespace, req.Name)
+	logger := klog.FromContext(ctx).WithValues("internalServiceImport", internalSvcImportKRef)
+	ctx = klog.NewContext(ctx, logger)
 	startTime := time.Now()
-	klog.V(2).InfoS("Reconciliation starts", "internalServiceImport", internalSvcImportKRef)
+	logger.V(2).Info("Reconciliation starts")
 	defer func() {
 		latency := time.Since(startTime).Milliseconds()
-		klog.V(2).InfoS("Reconciliation ends", "internalServiceImport", internalSvcImportKRef, "latency", latency)
+		logger.V(2).Info("Reconciliation ends", "latency", latency)
 	}()
 
 	// Retrieve the InternalServiceImport object.
@@ -46,10 +69,10 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	if err := r.HubClient.Get(ctx, req.NamespacedName, &internalSvcImport); err != nil {
 		// Skip the reconciliation if the InternalServiceImport does not exist.
 		if errors.IsNotFound(err) {
-			klog.V(4).InfoS("Ignoring NotFound internalServiceImport", "internalServiceImport", internalSvcImportKRef)
+			logger.V(4).Info("Ignoring NotFound internalServiceImport")
 			return ctrl.Result{}, nil
 		}
-		klog.ErrorS(err, "Failed to get internal svc import", "internalServiceImport", internalSvcImportKRef)
+		logger.Error(err, "Failed to get internal svc import")
 		return ctrl.Result{}, err
 	}
 	// Check if the service import exists in the member cluster.
@@ -63,42 +86,132 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		// finalizer, that a InternalServiceImport should be deleted. In some corner cases,
 		// however, e.g. the user chooses to remove the finalizer explicitly, a InternalServiceImport can be left over
 		// in the hub cluster, and it is up to this controller to remove it.
-		klog.V(2).InfoS("serviceImport does not exist; deleting the internalServiceImport",
+		logger.V(2).Info("serviceImport does not exist; deleting the internalServiceImport",
 			"serviceImport", svcImportKRef,
-			"internalServiceImport", internalSvcImportKRef,
 		)
 		if err := r.HubClient.Delete(ctx, &internalSvcImport); err != nil {
-			klog.ErrorS(err, "Failed to delete internalServiceImport", "internalServiceImport", internalSvcImportKRef)
+			logger.Error(err, "Failed to delete internalServiceImport")
 			return ctrl.Result{}, err
 		}
 		return ctrl.Result{}, nil
 	case err != nil:
 		// An unexpected error occurs.
-		klog.ErrorS(err, "Failed to get serviceImport", "serviceImport", svcImportKRef)
+		logger.Error(err, "Failed to get serviceImport", "serviceImport", svcImportKRef)
 		return ctrl.Result{}, err
 	}
 
+	// The ServiceImport controller withdraws the import from the hub cluster once the ServiceImport is moved away
+	// from it; the status is left to the hub cluster the ServiceImport is imported from in the meantime.
+	if hub := hubconfig.ImportingHub(&serviceImport); hub != r.HubName {
+		logger.V(4).Info("The service import is imported from another hub cluster", "serviceImport", svcImportKRef, "hub", hub)
+		return ctrl.Result{}, nil
+	}
+
+	// Leave the service import as it is while its reconciliation is paused; the ServiceImport controller updates the
+	// internalServiceImport as the reconciliation resumes, which brings the service import up to date.
+	if pause.IsPaused(&serviceImport) {
+		logger.V(2).Info("Reconciliation of the service import is paused", "serviceImport", svcImportKRef)
+		return ctrl.Result{}, nil
+	}
+
+	// The ClusterSetIP is allocated in the member cluster rather than in the fleet, and is kept as it is.
+	desiredStatus := internalSvcImport.Status.DeepCopy()
+	desiredStatus.IPs = serviceImport.Status.IPs
+
+	// Apply the labels and annotations exported from the member clusters to the service import.
+	if syncExportedMetadata(&serviceImport, desiredStatus) {
+		logger.V(2).Info("Updating the exported labels and annotations of the service import", "serviceImport", svcImportKRef)
+		if err := r.MemberClient.Update(ctx, &serviceImport); err != nil {
+			logger.Error(err, "Failed to update the exported labels and annotations of the service import", "serviceImport", svcImportKRef)
+			return ctrl.Result{}, err
+		}
+	}
+
 	// no status change
-	if equality.Semantic.DeepEqual(internalSvcImport.Status, serviceImport.Status) {
+	if equality.Semantic.DeepEqual(*desiredStatus, serviceImport.Status) {
 		return ctrl.Result{}, nil
 	}
 
 	// report back import status
-	klog.V(2).InfoS("Report back service import status from fleet", "internalServiceImport", internalSvcImportKRef)
+	logger.V(2).Info("Report back service import status from fleet")
 	oldStatus := serviceImport.Status.DeepCopy()
-	serviceImport.Status = internalSvcImport.Status
+	serviceImport.Status = *desiredStatus
 
-	klog.V(2).InfoS("Updating the service import status", "serviceImport", svcImportKRef, "status", serviceImport.Status, "oldStatus", oldStatus)
+	logger.V(2).Info("Updating the service import status", "serviceImport", svcImportKRef, "status", serviceImport.Status, "oldStatus", oldStatus)
 	if err := r.MemberClient.Status().Update(ctx, &serviceImport); err != nil {
-		klog.ErrorS(err, "Failed to update service import status", "serviceImport", svcImportKRef, "status", serviceImport.Status, "oldStatus", oldStatus)
+		logger.Error(err, "Failed to update service import status", "serviceImport", svcImportKRef, "status", serviceImport.Status, "oldStatus", oldStatus)
 		return ctrl.Result{}, err
 	}
+	switch {
+	case len(oldStatus.Clusters) == 0 && len(serviceImport.Status.Clusters) != 0:
+		r.Recorder.Eventf(&serviceImport, corev1.EventTypeNormal, "ServiceImported",
+			"Service %s is imported from clusters %v", serviceImport.Name, clusterNames(serviceImport.Status.Clusters))
+	case len(oldStatus.Clusters) != 0 && len(serviceImport.Status.Clusters) == 0:
+		r.Recorder.Eventf(&serviceImport, corev1.EventTypeWarning, "ImportWithdrawn",
+			"Service %s is no longer exported from any cluster and its import is withdrawn", serviceImport.Name)
+	}
 	return ctrl.Result{}, nil
 }
 
+// clusterNames returns the names of the clusters the service is imported from.
+func clusterNames(clusters []fleetnetv1alpha1.ServiceImportClusterStatus) []string {
+	names := make([]string, 0, len(clusters))
+	for _, c := range clusters {
+		names = append(names, c.Cluster)
+	}
+	return names
+}
+
+// syncExportedMetadata sets the labels and annotations exported from the member clusters on the service import, and
+// removes the ones which were exported before but are no longer; the keys reserved by fleet networking are never
+// touched. It returns true if the labels or annotations of the service import are changed.
+func syncExportedMetadata(serviceImport *fleetnetv1alpha1.ServiceImport, desiredStatus *fleetnetv1alpha1.ServiceImportStatus) bool {
+	labels, labelsChanged := syncMetadataMap(serviceImport.Labels, serviceImport.Status.ExportedLabels, desiredStatus.ExportedLabels)
+	annotations, annotationsChanged := syncMetadataMap(serviceImport.Annotations, serviceImport.Status.ExportedAnnotations, desiredStatus.ExportedAnnotations)
+	serviceImport.Labels = labels
+	serviceImport.Annotations = annotations
+	return labelsChanged || annotationsChanged
+}
+
+// syncMetadataMap applies the desired exported entries to the metadata map, removing the previously exported ones
+// which are no longer desired.
+func syncMetadataMap(current, previous, desired map[string]string) (map[string]string, bool) {
+	changed := false
+	for key := range previous {
+		if _, ok := desired[key]; ok || objectmeta.IsReservedKey(key) {
+			continue
+		}
+		if _, ok := current[key]; ok {
+			delete(current, key)
+			changed = true
+		}
+	}
+	for key, val := range desired {
+		if objectmeta.IsReservedKey(key) {
+			continue
+		}
+		if cur, ok := current[key]; ok && cur == val {
+			continue
+		}
+		if current == nil {
+			current = make(map[string]string, len(desired))
+		}
+		current[key] = val
+		changed = true
+	}
+	return current, changed
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// Each hub cluster has a controller of its own.
+	name := "internalserviceimport"
+	if r.HubName != "" {
+		name += "-" + r.HubName
+	}
 	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
 		For(&fleetnetv1alpha1.InternalServiceImport{}).
-		Complete(r)
+		WithOptions(r.ControllerOptions).
+		Complete(metrics.InstrumentReconciler(name, r))
 }
@@ -0,0 +1,265 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package internalserviceimport
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+// TestReconcile_ClusterSetIP tests that the status reported back from the fleet keeps the ClusterSetIP allocated in
+// the member cluster.
+func TestReconcile_ClusterSetIP(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	internalSvcImport := &fleetnetv1alpha1.InternalServiceImport{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "fleet-member-member-1", Name: "work-app"},
+		Spec: fleetnetv1alpha1.InternalServiceImportSpec{
+			ServiceImportReference: fleetnetv1alpha1.ExportedObjectReference{Namespace: "work", Name: "app"},
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Type:     fleetnetv1alpha1.ClusterSetIP,
+			Ports:    []fleetnetv1alpha1.ServicePort{{Port: 80}},
+			Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-2"}},
+		},
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "work", Name: "app"},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			IPs:  []string{"10.255.0.1"},
+			Type: fleetnetv1alpha1.ClusterSetIP,
+		},
+	}
+	hubClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(internalSvcImport).Build()
+	memberClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(serviceImport).WithStatusSubresource(serviceImport).Build()
+	r := &Reconciler{HubClient: hubClient, MemberClient: memberClient, Recorder: record.NewFakeRecorder(10)}
+
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "fleet-member-member-1", Name: "work-app"}}); err != nil {
+		t.Fatalf("Reconcile() = %v, want no error", err)
+	}
+	got := &fleetnetv1alpha1.ServiceImport{}
+	if err := memberClient.Get(ctx, types.NamespacedName{Namespace: "work", Name: "app"}, got); err != nil {
+		t.Fatalf("ServiceImport Get() = %v, want no error", err)
+	}
+	want := fleetnetv1alpha1.ServiceImportStatus{
+		IPs:      []string{"10.255.0.1"},
+		Type:     fleetnetv1alpha1.ClusterSetIP,
+		Ports:    []fleetnetv1alpha1.ServicePort{{Port: 80}},
+		Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-2"}},
+	}
+	if diff := cmp.Diff(want, got.Status); diff != "" {
+		t.Errorf("ServiceImport status mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+// TestReconcile_AdditionalHub tests that the status is only reported back from the hub cluster the ServiceImport is
+// imported from.
+func TestReconcile_AdditionalHub(t *testing.T) {
+	testCases := []struct {
+		name      string
+		hubName   string
+		annotated string
+		wantPorts []fleetnetv1alpha1.ServicePort
+	}{
+		{
+			name:      "primary hub reports the service import not annotated",
+			wantPorts: []fleetnetv1alpha1.ServicePort{{Port: 80}},
+		},
+		{
+			name:      "primary hub leaves the service import annotated with an additional hub",
+			annotated: "west",
+		},
+		{
+			name:      "additional hub reports the service import annotated with it",
+			hubName:   "west",
+			annotated: "west",
+			wantPorts: []fleetnetv1alpha1.ServicePort{{Port: 80}},
+		},
+		{
+			name:    "additional hub leaves the service import not annotated",
+			hubName: "west",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			internalSvcImport := &fleetnetv1alpha1.InternalServiceImport{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "fleet-member-member-1", Name: "work-app"},
+				Spec: fleetnetv1alpha1.InternalServiceImportSpec{
+					ServiceImportReference: fleetnetv1alpha1.ExportedObjectReference{Namespace: "work", Name: "app"},
+				},
+				Status: fleetnetv1alpha1.ServiceImportStatus{
+					Ports: []fleetnetv1alpha1.ServicePort{{Port: 80}},
+				},
+			}
+			serviceImport := &fleetnetv1alpha1.ServiceImport{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "work", Name: "app"},
+			}
+			if tc.annotated != "" {
+				serviceImport.Annotations = map[string]string{objectmeta.ServiceImportAnnotationHub: tc.annotated}
+			}
+			hubClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(internalSvcImport).Build()
+			memberClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(serviceImport).WithStatusSubresource(serviceImport).Build()
+			r := &Reconciler{HubClient: hubClient, MemberClient: memberClient, Recorder: record.NewFakeRecorder(10), HubName: tc.hubName}
+
+			if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "fleet-member-member-1", Name: "work-app"}}); err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+			got := &fleetnetv1alpha1.ServiceImport{}
+			if err := memberClient.Get(ctx, types.NamespacedName{Namespace: "work", Name: "app"}, got); err != nil {
+				t.Fatalf("ServiceImport Get() = %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.wantPorts, got.Status.Ports); diff != "" {
+				t.Errorf("ServiceImport ports mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestReconcile_ExportedMetadata tests that the labels and annotations exported from the member clusters are applied
+// to the ServiceImport, and the ones no longer exported are removed.
+func TestReconcile_ExportedMetadata(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	internalSvcImport := &fleetnetv1alpha1.InternalServiceImport{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "fleet-member-member-1", Name: "work-app"},
+		Spec: fleetnetv1alpha1.InternalServiceImportSpec{
+			ServiceImportReference: fleetnetv1alpha1.ExportedObjectReference{Namespace: "work", Name: "app"},
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Type:                fleetnetv1alpha1.ClusterSetIP,
+			ExportedLabels:      map[string]string{"team": "payments"},
+			ExportedAnnotations: map[string]string{"example.com/owner": "alice"},
+		},
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "work",
+			Name:      "app",
+			Labels: map[string]string{
+				"team":    "billing",
+				"tier":    "backend",
+				"managed": "by-user",
+				"networking.fleet.azure.com/derived-service": "work-app-1x2yz",
+			},
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Type:           fleetnetv1alpha1.ClusterSetIP,
+			ExportedLabels: map[string]string{"team": "billing", "tier": "backend"},
+		},
+	}
+	hubClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(internalSvcImport).Build()
+	memberClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(serviceImport).WithStatusSubresource(serviceImport).Build()
+	r := &Reconciler{HubClient: hubClient, MemberClient: memberClient, Recorder: record.NewFakeRecorder(10)}
+
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "fleet-member-member-1", Name: "work-app"}}); err != nil {
+		t.Fatalf("Reconcile() = %v, want no error", err)
+	}
+	got := &fleetnetv1alpha1.ServiceImport{}
+	if err := memberClient.Get(ctx, types.NamespacedName{Namespace: "work", Name: "app"}, got); err != nil {
+		t.Fatalf("ServiceImport Get() = %v, want no error", err)
+	}
+	wantLabels := map[string]string{
+		"team":    "payments",
+		"managed": "by-user",
+		"networking.fleet.azure.com/derived-service": "work-app-1x2yz",
+	}
+	if diff := cmp.Diff(wantLabels, got.Labels); diff != "" {
+		t.Errorf("ServiceImport labels mismatch (-want, +got):\n%s", diff)
+	}
+	wantAnnotations := map[string]string{"example.com/owner": "alice"}
+	if diff := cmp.Diff(wantAnnotations, got.Annotations); diff != "" {
+		t.Errorf("ServiceImport annotations mismatch (-want, +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(internalSvcImport.Status.ExportedLabels, got.Status.ExportedLabels); diff != "" {
+		t.Errorf("ServiceImport exported labels mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+// TestReconcile_Events tests that Events are recorded on the ServiceImport when the service starts and stops being
+// imported from the fleet.
+func TestReconcile_Events(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+
+	testCases := []struct {
+		name        string
+		oldClusters []fleetnetv1alpha1.ServiceImportClusterStatus
+		newClusters []fleetnetv1alpha1.ServiceImportClusterStatus
+		wantEvents  []string
+	}{
+		{
+			name:        "service imported",
+			newClusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-2"}, {Cluster: "member-3"}},
+			wantEvents:  []string{"Normal ServiceImported Service app is imported from clusters [member-2 member-3]"},
+		},
+		{
+			name:        "import withdrawn",
+			oldClusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-2"}},
+			wantEvents:  []string{"Warning ImportWithdrawn Service app is no longer exported from any cluster and its import is withdrawn"},
+		},
+		{
+			name:        "exporting clusters changed",
+			oldClusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-2"}},
+			newClusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-3"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			internalSvcImport := &fleetnetv1alpha1.InternalServiceImport{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "fleet-member-member-1", Name: "work-app"},
+				Spec: fleetnetv1alpha1.InternalServiceImportSpec{
+					ServiceImportReference: fleetnetv1alpha1.ExportedObjectReference{Namespace: "work", Name: "app"},
+				},
+				Status: fleetnetv1alpha1.ServiceImportStatus{Clusters: tc.newClusters},
+			}
+			serviceImport := &fleetnetv1alpha1.ServiceImport{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "work", Name: "app"},
+				Status:     fleetnetv1alpha1.ServiceImportStatus{Clusters: tc.oldClusters},
+			}
+			hubClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(internalSvcImport).Build()
+			memberClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(serviceImport).WithStatusSubresource(serviceImport).Build()
+			recorder := record.NewFakeRecorder(10)
+			r := &Reconciler{HubClient: hubClient, MemberClient: memberClient, Recorder: recorder}
+
+			if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "fleet-member-member-1", Name: "work-app"}}); err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+			var gotEvents []string
+			for len(recorder.Events) > 0 {
+				gotEvents = append(gotEvents, <-recorder.Events)
+			}
+			if diff := cmp.Diff(tc.wantEvents, gotEvents); diff != "" {
+				t.Errorf("Events mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
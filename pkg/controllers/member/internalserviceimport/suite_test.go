@@ -123,6 +123,7 @@ var _ = BeforeSuite(func() {
 	err = (&Reconciler{
 		MemberClient: memberClient,
 		HubClient:    hubClient,
+		Recorder:     mgr.GetEventRecorderFor(ControllerName),
 	}).SetupWithManager(mgr)
 	Expect(err).ToNot(HaveOccurred())
 
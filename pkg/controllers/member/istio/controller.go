@@ -0,0 +1,382 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package istio
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/uniquename"
+	"go.goms.io/fleet-networking/pkg/common/unstructuredkind"
+)
+
+// Reconciler reconciles the ServiceEntry and the WorkloadEntries of a ServiceImport.
+type Reconciler struct {
+	Client client.Client
+	Scheme *runtime.Scheme
+	// The imported EndpointSlices are in the fleet system namespace, along with the derived Services.
+	FleetSystemNamespace string
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
+}
+
+// workload is a WorkloadEntry of an imported endpoint address.
+type workload struct {
+	address string
+	cluster string
+	// ports maps the names of the ports of the ServiceEntry to the ports of the endpoint.
+	ports map[string]int64
+}
+
+//+kubebuilder:rbac:groups=networking.istio.io,resources=serviceentries;workloadentries,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports,verbs=get;list;watch
+//+kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch
+
+// Reconcile translates the ServiceImport, and its imported endpoints, into a ServiceEntry and its WorkloadEntries, or
+// deletes them if the ServiceImport has no derived Service or no port which Istio can route.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	serviceImportKRef := klog.KRef(req.Namespace, req.Name)
+	startTime := time.Now()
+	klog.V(2).InfoS("Reconciliation starts", "serviceImport", serviceImportKRef)
+	defer func() {
+		latency := time.Since(startTime).Milliseconds()
+		klog.V(2).InfoS("Reconciliation ends", "serviceImport", serviceImportKRef, "latency", latency)
+	}()
+
+	serviceImport := &fleetnetv1alpha1.ServiceImport{}
+	if err := r.Client.Get(ctx, req.NamespacedName, serviceImport); err != nil {
+		if errors.IsNotFound(err) {
+			// The Istio objects are owned by the serviceImport and garbage collected along with it.
+			klog.V(4).InfoS("Ignoring NotFound serviceImport", "serviceImport", serviceImportKRef)
+			return ctrl.Result{}, nil
+		}
+		klog.ErrorS(err, "Failed to get serviceImport", "serviceImport", serviceImportKRef)
+		return ctrl.Result{}, err
+	}
+	if serviceImport.DeletionTimestamp != nil {
+		klog.V(4).InfoS("ServiceImport is being deleted", "serviceImport", serviceImportKRef)
+		return ctrl.Result{}, nil
+	}
+
+	ports := serviceEntryPorts(serviceImport)
+	derivedSvcName := serviceImport.Labels[objectmeta.ServiceImportLabelDerivedService]
+	if derivedSvcName == "" || len(ports) == 0 {
+		klog.V(4).InfoS("ServiceImport has no derived service or no port routable by Istio", "serviceImport", serviceImportKRef)
+		if err := r.deleteServiceEntry(ctx, serviceImport); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, r.deleteStaleWorkloadEntries(ctx, serviceImport, nil)
+	}
+
+	importedSliceList := &discoveryv1.EndpointSliceList{}
+	if err := r.Client.List(ctx, importedSliceList, client.InNamespace(r.FleetSystemNamespace), client.MatchingLabels{
+		discoveryv1.LabelServiceName: derivedSvcName,
+	}); err != nil {
+		klog.ErrorS(err, "Failed to list imported endpointSlices", "serviceImport", serviceImportKRef, "derivedService", klog.KRef(r.FleetSystemNamespace, derivedSvcName))
+		return ctrl.Result{}, err
+	}
+
+	serviceEntry := unstructuredkind.New(ServiceEntryGVK)
+	serviceEntry.SetNamespace(serviceImport.Namespace)
+	serviceEntry.SetName(serviceImport.Name)
+	serviceEntryKObj := klog.KObj(serviceEntry)
+	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(serviceEntry), serviceEntry); err != nil && !errors.IsNotFound(err) {
+		klog.ErrorS(err, "Failed to get serviceEntry", "serviceImport", serviceImportKRef, "serviceEntry", serviceEntryKObj)
+		return ctrl.Result{}, err
+	}
+	if serviceEntry.GetResourceVersion() != "" && !isManaged(serviceEntry) {
+		// Retrying will not help until the ServiceEntry of the user is removed.
+		klog.V(2).InfoS("Skipping the serviceImport, as a serviceEntry of the same name is not managed by the controller", "serviceImport", serviceImportKRef, "serviceEntry", serviceEntryKObj)
+		return ctrl.Result{}, nil
+	}
+	if op, err := controllerutil.CreateOrUpdate(ctx, r.Client, serviceEntry, func() error {
+		formatServiceEntry(serviceEntry, serviceImport, ports)
+		return controllerutil.SetControllerReference(serviceImport, serviceEntry, r.Scheme)
+	}); err != nil {
+		klog.ErrorS(err, "Failed to create or update serviceEntry", "serviceImport", serviceImportKRef, "serviceEntry", serviceEntryKObj, "op", op)
+		return ctrl.Result{}, err
+	}
+
+	workloads := importedWorkloads(serviceImport, importedSliceList.Items)
+	desired := make(map[string]bool, len(workloads))
+	for i := range workloads {
+		workloadEntry := unstructuredkind.New(WorkloadEntryGVK)
+		workloadEntry.SetNamespace(serviceImport.Namespace)
+		workloadEntry.SetName(workloadEntryName(serviceImport.Name, workloads[i].address))
+		desired[workloadEntry.GetName()] = true
+		workloadEntryKObj := klog.KObj(workloadEntry)
+		if op, err := controllerutil.CreateOrUpdate(ctx, r.Client, workloadEntry, func() error {
+			formatWorkloadEntry(workloadEntry, serviceImport, &workloads[i])
+			return controllerutil.SetControllerReference(serviceImport, workloadEntry, r.Scheme)
+		}); err != nil {
+			klog.ErrorS(err, "Failed to create or update workloadEntry", "serviceImport", serviceImportKRef, "workloadEntry", workloadEntryKObj, "op", op)
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{}, r.deleteStaleWorkloadEntries(ctx, serviceImport, desired)
+}
+
+// serviceEntryPorts returns the ports of the ServiceEntry of a ServiceImport, i.e. its TCP ports.
+func serviceEntryPorts(serviceImport *fleetnetv1alpha1.ServiceImport) []interface{} {
+	ports := []interface{}{}
+	for i := range serviceImport.Status.Ports {
+		port := &serviceImport.Status.Ports[i]
+		protocol, ok := istioProtocol(port)
+		if !ok {
+			continue
+		}
+		ports = append(ports, map[string]interface{}{
+			"number":   int64(port.Port),
+			"name":     portName(port),
+			"protocol": protocol,
+		})
+	}
+	return ports
+}
+
+// workloadLabels are the labels the ServiceEntry of a ServiceImport selects its WorkloadEntries with.
+func workloadLabels(serviceImport *fleetnetv1alpha1.ServiceImport) map[string]string {
+	return map[string]string{
+		objectmeta.EndpointSliceLabelServiceImportName: serviceImport.Name,
+		objectmeta.LabelManagedBy:                      objectmeta.MemberNetControllerManagerName,
+	}
+}
+
+// isManaged returns true if an Istio object is managed by the controller.
+func isManaged(obj client.Object) bool {
+	return obj.GetLabels()[objectmeta.LabelManagedBy] == objectmeta.MemberNetControllerManagerName
+}
+
+// formatServiceEntry formats the ServiceEntry of a ServiceImport; the endpoints are the WorkloadEntries selected by
+// their labels, at their addresses.
+func formatServiceEntry(serviceEntry *unstructured.Unstructured, serviceImport *fleetnetv1alpha1.ServiceImport, ports []interface{}) {
+	serviceEntry.SetLabels(workloadLabels(serviceImport))
+	selector := map[string]interface{}{}
+	for k, v := range workloadLabels(serviceImport) {
+		selector[k] = v
+	}
+	serviceEntry.Object["spec"] = map[string]interface{}{
+		"hosts":            []interface{}{fmt.Sprintf(hostFormat, serviceImport.Name, serviceImport.Namespace)},
+		"location":         "MESH_INTERNAL",
+		"resolution":       "STATIC",
+		"ports":            ports,
+		"workloadSelector": map[string]interface{}{"labels": selector},
+	}
+}
+
+// formatWorkloadEntry formats the WorkloadEntry of an imported endpoint address of a ServiceImport.
+func formatWorkloadEntry(workloadEntry *unstructured.Unstructured, serviceImport *fleetnetv1alpha1.ServiceImport, w *workload) {
+	labels := workloadLabels(serviceImport)
+	if w.cluster != "" {
+		labels[objectmeta.EndpointSliceLabelSourceCluster] = w.cluster
+	}
+	workloadEntry.SetLabels(labels)
+	specLabels := map[string]interface{}{}
+	for k, v := range labels {
+		specLabels[k] = v
+	}
+	ports := map[string]interface{}{}
+	for name, port := range w.ports {
+		ports[name] = port
+	}
+	workloadEntry.Object["spec"] = map[string]interface{}{
+		"address": w.address,
+		"labels":  specLabels,
+		"ports":   ports,
+	}
+}
+
+// importedWorkloads returns the workloads of the imported endpoints of a ServiceImport, sorted by address; the ready
+// endpoints are translated, or the serving ones if no endpoint is ready, as Istio does not tell the conditions of
+// the WorkloadEntries. The endpoints of the same address, e.g. an east-west gateway exporting several EndpointSlices
+// of the service, are merged.
+func importedWorkloads(serviceImport *fleetnetv1alpha1.ServiceImport, importedSlices []discoveryv1.EndpointSlice) []workload {
+	collect := func(include func(*discoveryv1.Endpoint) bool) []workload {
+		byAddress := map[string]*workload{}
+		for i := range importedSlices {
+			importedSlice := &importedSlices[i]
+			if importedSlice.DeletionTimestamp != nil {
+				continue
+			}
+			ports := workloadPorts(serviceImport, importedSlice.Ports)
+			if len(ports) == 0 {
+				continue
+			}
+			for j := range importedSlice.Endpoints {
+				endpoint := &importedSlice.Endpoints[j]
+				if !include(endpoint) {
+					continue
+				}
+				for _, address := range endpoint.Addresses {
+					w, ok := byAddress[address]
+					if !ok {
+						w = &workload{
+							address: address,
+							cluster: importedSlice.Labels[objectmeta.EndpointSliceLabelSourceCluster],
+							ports:   map[string]int64{},
+						}
+						byAddress[address] = w
+					}
+					for name, port := range ports {
+						w.ports[name] = port
+					}
+				}
+			}
+		}
+		res := make([]workload, 0, len(byAddress))
+		for _, w := range byAddress {
+			res = append(res, *w)
+		}
+		sort.Slice(res, func(i, j int) bool {
+			return res[i].address < res[j].address
+		})
+		return res
+	}
+	if res := collect(isReady); len(res) > 0 {
+		return res
+	}
+	return collect(isServing)
+}
+
+// workloadPorts maps the names of the ports of the ServiceEntry of a ServiceImport to the ports of an imported
+// EndpointSlice, which are named after the ports of the ServiceImport.
+func workloadPorts(serviceImport *fleetnetv1alpha1.ServiceImport, endpointPorts []discoveryv1.EndpointPort) map[string]int64 {
+	res := map[string]int64{}
+	for i := range serviceImport.Status.Ports {
+		port := &serviceImport.Status.Ports[i]
+		if _, ok := istioProtocol(port); !ok {
+			continue
+		}
+		for _, endpointPort := range endpointPorts {
+			name := ""
+			if endpointPort.Name != nil {
+				name = *endpointPort.Name
+			}
+			if name == port.Name && endpointPort.Port != nil {
+				res[portName(port)] = int64(*endpointPort.Port)
+				break
+			}
+		}
+	}
+	return res
+}
+
+func isReady(endpoint *discoveryv1.Endpoint) bool {
+	return endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready
+}
+
+func isServing(endpoint *discoveryv1.Endpoint) bool {
+	if endpoint.Conditions.Serving == nil {
+		return isReady(endpoint)
+	}
+	return *endpoint.Conditions.Serving
+}
+
+// workloadEntryName returns the name of the WorkloadEntry of an imported endpoint address of a ServiceImport; the
+// colons of IPv6 addresses are not allowed in names.
+func workloadEntryName(serviceImportName, address string) string {
+	return uniquename.ClusterScopedDeterministicName(serviceImportName, strings.ReplaceAll(address, ":", "-"))
+}
+
+// deleteServiceEntry deletes the ServiceEntry of the ServiceImport, if it is managed by the controller.
+func (r *Reconciler) deleteServiceEntry(ctx context.Context, serviceImport *fleetnetv1alpha1.ServiceImport) error {
+	serviceEntry := unstructuredkind.New(ServiceEntryGVK)
+	key := types.NamespacedName{Namespace: serviceImport.Namespace, Name: serviceImport.Name}
+	if err := r.Client.Get(ctx, key, serviceEntry); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		klog.ErrorS(err, "Failed to get serviceEntry", "serviceEntry", key)
+		return err
+	}
+	if !isManaged(serviceEntry) {
+		return nil
+	}
+	klog.V(2).InfoS("Deleting serviceEntry", "serviceEntry", key, "serviceImport", klog.KObj(serviceImport))
+	if err := r.Client.Delete(ctx, serviceEntry); err != nil && !errors.IsNotFound(err) {
+		klog.ErrorS(err, "Failed to delete serviceEntry", "serviceEntry", key)
+		return err
+	}
+	return nil
+}
+
+// deleteStaleWorkloadEntries deletes the WorkloadEntries of the ServiceImport which are not desired.
+func (r *Reconciler) deleteStaleWorkloadEntries(ctx context.Context, serviceImport *fleetnetv1alpha1.ServiceImport, desired map[string]bool) error {
+	workloadEntryList := &unstructured.UnstructuredList{}
+	workloadEntryList.SetGroupVersionKind(WorkloadEntryGVK.GroupVersion().WithKind(WorkloadEntryGVK.Kind + "List"))
+	if err := r.Client.List(ctx, workloadEntryList, client.InNamespace(serviceImport.Namespace), client.MatchingLabels(workloadLabels(serviceImport))); err != nil {
+		klog.ErrorS(err, "Failed to list workloadEntries", "serviceImport", klog.KObj(serviceImport))
+		return err
+	}
+	for i := range workloadEntryList.Items {
+		workloadEntry := &workloadEntryList.Items[i]
+		if desired[workloadEntry.GetName()] {
+			continue
+		}
+		klog.V(2).InfoS("Deleting stale workloadEntry", "workloadEntry", klog.KObj(workloadEntry), "serviceImport", klog.KObj(serviceImport))
+		if err := r.Client.Delete(ctx, workloadEntry); err != nil && !errors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to delete stale workloadEntry", "workloadEntry", klog.KObj(workloadEntry), "serviceImport", klog.KObj(serviceImport))
+			return err
+		}
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("istio").
+		For(&fleetnetv1alpha1.ServiceImport{}).
+		// The Istio objects are watched so that they are restored if they are modified or deleted.
+		Owns(unstructuredkind.New(ServiceEntryGVK)).
+		Owns(unstructuredkind.New(WorkloadEntryGVK)).
+		Watches(
+			&discoveryv1.EndpointSlice{},
+			handler.EnqueueRequestsFromMapFunc(r.importedEndpointSliceEventHandler()),
+		).
+		WithOptions(r.ControllerOptions).
+		Complete(metrics.InstrumentReconciler("istio", r))
+}
+
+// importedEndpointSliceEventHandler maps an imported EndpointSlice to the ServiceImports of its derived Service.
+func (r *Reconciler) importedEndpointSliceEventHandler() handler.MapFunc {
+	return func(ctx context.Context, object client.Object) []reconcile.Request {
+		derivedSvcName := object.GetLabels()[discoveryv1.LabelServiceName]
+		if object.GetNamespace() != r.FleetSystemNamespace || derivedSvcName == "" {
+			return []reconcile.Request{}
+		}
+		serviceImportList := &fleetnetv1alpha1.ServiceImportList{}
+		if err := r.Client.List(ctx, serviceImportList, client.MatchingLabels{objectmeta.ServiceImportLabelDerivedService: derivedSvcName}); err != nil {
+			klog.ErrorS(err, "Failed to list serviceImports of the imported endpointSlice", "endpointSlice", klog.KObj(object))
+			return []reconcile.Request{}
+		}
+		requests := make([]reconcile.Request, 0, len(serviceImportList.Items))
+		for i := range serviceImportList.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&serviceImportList.Items[i])})
+		}
+		return requests
+	}
+}
@@ -0,0 +1,242 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package istio
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/unstructuredkind"
+)
+
+const (
+	testNamespace        = "work"
+	fleetSystemNamespace = "fleet-system"
+	testServiceImport    = "app"
+	derivedServiceName   = "work-app-xyz"
+)
+
+func serviceImport() *fleetnetv1alpha1.ServiceImport {
+	return &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testServiceImport,
+			UID:       "serviceimport-uid",
+			Labels:    map[string]string{objectmeta.ServiceImportLabelDerivedService: derivedServiceName},
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Ports: []fleetnetv1alpha1.ServicePort{
+				{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80, AppProtocol: ptr.To("http")},
+				{Name: "dns", Protocol: corev1.ProtocolUDP, Port: 53},
+			},
+		},
+	}
+}
+
+// importedEndpointSlice returns an EndpointSlice imported from the given cluster; the gateway of member-2 exports
+// the http port at port 15443.
+func importedEndpointSlice(name, cluster string, port int32, endpoints ...discoveryv1.Endpoint) *discoveryv1.EndpointSlice {
+	return &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: fleetSystemNamespace,
+			Name:      name,
+			Labels: map[string]string{
+				discoveryv1.LabelServiceName:               derivedServiceName,
+				objectmeta.EndpointSliceLabelSourceCluster: cluster,
+			},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints:   endpoints,
+		Ports: []discoveryv1.EndpointPort{
+			{Name: ptr.To("http"), Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To(port)},
+			{Name: ptr.To("dns"), Protocol: ptr.To(corev1.ProtocolUDP), Port: ptr.To[int32](53)},
+		},
+	}
+}
+
+func endpoint(address string, ready bool) discoveryv1.Endpoint {
+	return discoveryv1.Endpoint{
+		Addresses:  []string{address},
+		Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(ready), Serving: ptr.To(true)},
+	}
+}
+
+func workloadEntry(name string, spec map[string]interface{}) *unstructured.Unstructured {
+	obj := unstructuredkind.New(WorkloadEntryGVK)
+	obj.SetNamespace(testNamespace)
+	obj.SetName(name)
+	obj.SetLabels(workloadLabels(serviceImport()))
+	obj.Object["spec"] = spec
+	return obj
+}
+
+// TestReconcile tests the Reconciler.Reconcile method.
+func TestReconcile(t *testing.T) {
+	wantServiceEntrySpec := map[string]interface{}{
+		"hosts":      []interface{}{"app.work.svc.clusterset.local"},
+		"location":   "MESH_INTERNAL",
+		"resolution": "STATIC",
+		"ports": []interface{}{
+			map[string]interface{}{"number": int64(80), "name": "http", "protocol": "HTTP"},
+		},
+		"workloadSelector": map[string]interface{}{
+			"labels": map[string]interface{}{
+				objectmeta.EndpointSliceLabelServiceImportName: testServiceImport,
+				objectmeta.LabelManagedBy:                      objectmeta.MemberNetControllerManagerName,
+			},
+		},
+	}
+	workloadSpec := func(address, cluster string, port int64) map[string]interface{} {
+		return map[string]interface{}{
+			"address": address,
+			"labels": map[string]interface{}{
+				objectmeta.EndpointSliceLabelServiceImportName: testServiceImport,
+				objectmeta.LabelManagedBy:                      objectmeta.MemberNetControllerManagerName,
+				objectmeta.EndpointSliceLabelSourceCluster:     cluster,
+			},
+			"ports": map[string]interface{}{"http": port},
+		}
+	}
+	staleWorkloadEntry := workloadEntry("app-stale", workloadSpec("10.9.0.1", "member-9", 80))
+	userServiceEntry := unstructuredkind.New(ServiceEntryGVK)
+	userServiceEntry.SetNamespace(testNamespace)
+	userServiceEntry.SetName(testServiceImport)
+	userServiceEntry.Object["spec"] = map[string]interface{}{"hosts": []interface{}{"app.example.com"}}
+
+	testCases := []struct {
+		name                 string
+		objs                 []client.Object
+		wantServiceEntrySpec map[string]interface{}
+		wantWorkloadEntries  map[string]map[string]interface{}
+	}{
+		{
+			name: "ready endpoints of both clusters",
+			objs: []client.Object{
+				serviceImport(),
+				importedEndpointSlice("member-1-app", "member-1", 8080, endpoint("10.1.0.1", true), endpoint("10.1.0.2", false)),
+				// The endpoints behind the east-west gateway of member-2 are imported at the address of the gateway.
+				importedEndpointSlice("member-2-app", "member-2", 15443, endpoint("20.0.0.2", true)),
+				staleWorkloadEntry,
+			},
+			wantServiceEntrySpec: wantServiceEntrySpec,
+			wantWorkloadEntries: map[string]map[string]interface{}{
+				workloadEntryName(testServiceImport, "10.1.0.1"): workloadSpec("10.1.0.1", "member-1", 8080),
+				workloadEntryName(testServiceImport, "20.0.0.2"): workloadSpec("20.0.0.2", "member-2", 15443),
+			},
+		},
+		{
+			name: "serving endpoints when none is ready",
+			objs: []client.Object{
+				serviceImport(),
+				importedEndpointSlice("member-1-app", "member-1", 8080, endpoint("10.1.0.2", false)),
+			},
+			wantServiceEntrySpec: wantServiceEntrySpec,
+			wantWorkloadEntries: map[string]map[string]interface{}{
+				workloadEntryName(testServiceImport, "10.1.0.2"): workloadSpec("10.1.0.2", "member-1", 8080),
+			},
+		},
+		{
+			name: "serviceImport without derived service",
+			objs: []client.Object{
+				&fleetnetv1alpha1.ServiceImport{
+					ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testServiceImport},
+					Status:     serviceImport().Status,
+				},
+				staleWorkloadEntry,
+			},
+		},
+		{
+			name: "serviceImport with UDP ports only",
+			objs: []client.Object{
+				&fleetnetv1alpha1.ServiceImport{
+					ObjectMeta: serviceImport().ObjectMeta,
+					Status: fleetnetv1alpha1.ServiceImportStatus{
+						Ports: []fleetnetv1alpha1.ServicePort{{Name: "dns", Protocol: corev1.ProtocolUDP, Port: 53}},
+					},
+				},
+				staleWorkloadEntry,
+			},
+		},
+		{
+			name: "serviceEntry of the user kept",
+			objs: []client.Object{
+				serviceImport(),
+				importedEndpointSlice("member-1-app", "member-1", 8080, endpoint("10.1.0.1", true)),
+				userServiceEntry,
+			},
+			wantServiceEntrySpec: userServiceEntry.Object["spec"].(map[string]interface{}),
+		},
+		{
+			name: "serviceImport not found",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := clientgoscheme.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			AddToScheme(scheme)
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.objs...).Build()
+			r := &Reconciler{
+				Client:               fakeClient,
+				Scheme:               scheme,
+				FleetSystemNamespace: fleetSystemNamespace,
+			}
+			ctx := context.Background()
+			key := types.NamespacedName{Namespace: testNamespace, Name: testServiceImport}
+			if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+
+			var gotServiceEntrySpec map[string]interface{}
+			serviceEntry := unstructuredkind.New(ServiceEntryGVK)
+			if err := fakeClient.Get(ctx, key, serviceEntry); err == nil {
+				gotServiceEntrySpec = serviceEntry.Object["spec"].(map[string]interface{})
+			}
+			if diff := cmp.Diff(tc.wantServiceEntrySpec, gotServiceEntrySpec); diff != "" {
+				t.Errorf("serviceEntry spec mismatch (-want, +got):\n%s", diff)
+			}
+
+			workloadEntryList := &unstructured.UnstructuredList{}
+			workloadEntryList.SetGroupVersionKind(WorkloadEntryGVK.GroupVersion().WithKind(WorkloadEntryGVK.Kind + "List"))
+			if err := fakeClient.List(ctx, workloadEntryList, client.InNamespace(testNamespace)); err != nil {
+				t.Fatalf("WorkloadEntry List() = %v, want no error", err)
+			}
+			var gotWorkloadEntries map[string]map[string]interface{}
+			for _, item := range workloadEntryList.Items {
+				if gotWorkloadEntries == nil {
+					gotWorkloadEntries = map[string]map[string]interface{}{}
+				}
+				gotWorkloadEntries[item.GetName()] = item.Object["spec"].(map[string]interface{})
+				if owner := metav1.GetControllerOf(&item); owner == nil || owner.UID != "serviceimport-uid" {
+					t.Errorf("workloadEntry %s controller = %v, want the serviceImport", item.GetName(), owner)
+				}
+			}
+			if diff := cmp.Diff(tc.wantWorkloadEntries, gotWorkloadEntries); diff != "" {
+				t.Errorf("workloadEntries mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package istio features the translation of the ServiceImports into Istio ServiceEntries and WorkloadEntries
+// (networking.istio.io), so that the meshes spanning the fleet can route to the fleet services without running
+// Istio's own multi-cluster discovery.
+//
+// Each ServiceImport with a derived Service is translated into a ServiceEntry of the same namespace and name, for the
+// <service>.<namespace>.svc.clusterset.local host, which selects the WorkloadEntries of the imported endpoints of the
+// ServiceImport. The imported endpoints are the ones the endpointsliceimport controller imports, so that the services
+// exported through the east-west gateway of a member cluster are reached at the address of the gateway, or at the
+// tunnel address of the member cluster, with the service ports mapped to the ports of the gateway.
+// The Istio objects are handled as unstructured objects, so that the controller does not depend on the Istio module.
+package istio
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/unstructuredkind"
+)
+
+const (
+	// GroupName is the group of the Istio networking API.
+	GroupName = "networking.istio.io"
+
+	// hostFormat is the format of the host of a ServiceEntry, which is the clusterset.local name of its ServiceImport.
+	hostFormat = "%s.%s.svc.clusterset.local"
+)
+
+var (
+	// ServiceEntryGVK is the group version kind of the ServiceEntry.
+	ServiceEntryGVK = schema.GroupVersionKind{Group: GroupName, Version: "v1beta1", Kind: "ServiceEntry"}
+	// WorkloadEntryGVK is the group version kind of the WorkloadEntry.
+	WorkloadEntryGVK = schema.GroupVersionKind{Group: GroupName, Version: "v1beta1", Kind: "WorkloadEntry"}
+
+	gvks = []schema.GroupVersionKind{ServiceEntryGVK, WorkloadEntryGVK}
+)
+
+// AddToScheme registers the ServiceEntry and the WorkloadEntry, and their lists, as unstructured objects in the scheme.
+func AddToScheme(scheme *runtime.Scheme) {
+	unstructuredkind.AddToScheme(scheme, gvks...)
+}
+
+// portName returns the name of a port of a ServiceImport in the ServiceEntry, which requires the ports to be named; an
+// unnamed port, which is the only port of its ServiceImport, is named after its protocol and number.
+func portName(port *fleetnetv1alpha1.ServicePort) string {
+	if port.Name != "" {
+		return port.Name
+	}
+	protocol := port.Protocol
+	if protocol == "" {
+		protocol = corev1.ProtocolTCP
+	}
+	return fmt.Sprintf("%s-%d", strings.ToLower(string(protocol)), port.Port)
+}
+
+// istioProtocol returns the Istio protocol of a port of a ServiceImport per its application protocol, or false if
+// the port cannot be routed by Istio, i.e. it is not a TCP port.
+func istioProtocol(port *fleetnetv1alpha1.ServicePort) (string, bool) {
+	if port.Protocol != "" && port.Protocol != corev1.ProtocolTCP {
+		return "", false
+	}
+	if port.AppProtocol == nil {
+		return "TCP", true
+	}
+	switch strings.ToLower(*port.AppProtocol) {
+	case "http":
+		return "HTTP", true
+	case "http2", "kubernetes.io/h2c":
+		return "HTTP2", true
+	case "grpc":
+		return "GRPC", true
+	case "https":
+		return "HTTPS", true
+	case "tls":
+		return "TLS", true
+	default:
+		return "TCP", true
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package istio
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+// TestPortName tests the portName function.
+func TestPortName(t *testing.T) {
+	testCases := []struct {
+		name string
+		port fleetnetv1alpha1.ServicePort
+		want string
+	}{
+		{
+			name: "named port",
+			port: fleetnetv1alpha1.ServicePort{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80},
+			want: "http",
+		},
+		{
+			name: "unnamed port",
+			port: fleetnetv1alpha1.ServicePort{Protocol: corev1.ProtocolTCP, Port: 80},
+			want: "tcp-80",
+		},
+		{
+			name: "unnamed port without protocol",
+			port: fleetnetv1alpha1.ServicePort{Port: 6379},
+			want: "tcp-6379",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := portName(&tc.port); got != tc.want {
+				t.Errorf("portName() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestIstioProtocol tests the istioProtocol function.
+func TestIstioProtocol(t *testing.T) {
+	testCases := []struct {
+		name   string
+		port   fleetnetv1alpha1.ServicePort
+		want   string
+		wantOK bool
+	}{
+		{
+			name:   "TCP port",
+			port:   fleetnetv1alpha1.ServicePort{Protocol: corev1.ProtocolTCP, Port: 6379},
+			want:   "TCP",
+			wantOK: true,
+		},
+		{
+			name:   "HTTP port",
+			port:   fleetnetv1alpha1.ServicePort{Protocol: corev1.ProtocolTCP, Port: 80, AppProtocol: ptr.To("http")},
+			want:   "HTTP",
+			wantOK: true,
+		},
+		{
+			name:   "h2c port",
+			port:   fleetnetv1alpha1.ServicePort{Protocol: corev1.ProtocolTCP, Port: 80, AppProtocol: ptr.To("kubernetes.io/h2c")},
+			want:   "HTTP2",
+			wantOK: true,
+		},
+		{
+			name:   "gRPC port",
+			port:   fleetnetv1alpha1.ServicePort{Protocol: corev1.ProtocolTCP, Port: 9000, AppProtocol: ptr.To("GRPC")},
+			want:   "GRPC",
+			wantOK: true,
+		},
+		{
+			name:   "unknown application protocol",
+			port:   fleetnetv1alpha1.ServicePort{Protocol: corev1.ProtocolTCP, Port: 5432, AppProtocol: ptr.To("postgresql")},
+			want:   "TCP",
+			wantOK: true,
+		},
+		{
+			name: "UDP port",
+			port: fleetnetv1alpha1.ServicePort{Protocol: corev1.ProtocolUDP, Port: 53},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := istioProtocol(&tc.port)
+			if got != tc.want || ok != tc.wantOK {
+				t.Errorf("istioProtocol() = %q, %v, want %q, %v", got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package loadbalancerexport features the loadbalancerexport controller, which creates an internal LoadBalancer
+// Service for each Service exported in the LoadBalancer connectivity mode, unless the Service is an internal
+// LoadBalancer Service itself, so that the IP of its load balancer can be exported instead of the pod IPs.
+package loadbalancerexport
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+const (
+	// ConnectivityModeLoadBalancer is the value of the connectivity mode annotation of the ServiceExports whose
+	// Services are exported through their internal load balancers.
+	ConnectivityModeLoadBalancer = "LoadBalancer"
+
+	loadBalancerServiceNamePrefix = "fleet-ilb-"
+)
+
+// IsLoadBalancerMode returns true if the Service of the ServiceExport is exported through its internal load
+// balancer.
+func IsLoadBalancerMode(svcExport *fleetnetv1alpha1.ServiceExport) bool {
+	return svcExport.Annotations[objectmeta.ServiceExportAnnotationConnectivityMode] == ConnectivityModeLoadBalancer
+}
+
+// IsInternalLoadBalancer returns true if the Service is an internal LoadBalancer Service of cloud-provider-azure.
+func IsInternalLoadBalancer(svc *corev1.Service) bool {
+	return svc.Spec.Type == corev1.ServiceTypeLoadBalancer &&
+		svc.Annotations[objectmeta.ServiceAnnotationAzureLoadBalancerInternal] == "true"
+}
+
+// ServiceName returns the name of the internal LoadBalancer Service created for the exported Service with the given
+// name; the name is derived from a hash, as the name of the exported Service may take up the length of a DNS label.
+func ServiceName(svcName string) string {
+	return loadBalancerServiceNamePrefix + fmt.Sprintf("%x", sha256.Sum256([]byte(svcName)))[:16]
+}
+
+// Reconciler reconciles the internal LoadBalancer Service of a ServiceExport.
+type Reconciler struct {
+	Client client.Client
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
+}
+
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceexports,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile creates or updates the internal LoadBalancer Service of the ServiceExport if its Service is exported
+// through an internal load balancer but is not an internal LoadBalancer Service itself, and deletes it otherwise.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	svcExportRef := klog.KRef(req.Namespace, req.Name)
+	startTime := time.Now()
+	klog.V(2).InfoS("Reconciliation starts", "serviceExport", svcExportRef)
+	defer func() {
+		latency := time.Since(startTime).Milliseconds()
+		klog.V(2).InfoS("Reconciliation ends", "serviceExport", svcExportRef, "latency", latency)
+	}()
+
+	svcExport := &fleetnetv1alpha1.ServiceExport{}
+	if err := r.Client.Get(ctx, req.NamespacedName, svcExport); err != nil {
+		if errors.IsNotFound(err) {
+			klog.V(4).InfoS("ServiceExport is not found; deleting its load balancer service", "serviceExport", svcExportRef)
+			return ctrl.Result{}, r.deleteLoadBalancerService(ctx, req.NamespacedName)
+		}
+		klog.ErrorS(err, "Failed to get serviceExport", "serviceExport", svcExportRef)
+		return ctrl.Result{}, err
+	}
+	if svcExport.DeletionTimestamp != nil || !IsLoadBalancerMode(svcExport) {
+		return ctrl.Result{}, r.deleteLoadBalancerService(ctx, req.NamespacedName)
+	}
+
+	svc := &corev1.Service{}
+	if err := r.Client.Get(ctx, req.NamespacedName, svc); err != nil {
+		if errors.IsNotFound(err) {
+			klog.V(4).InfoS("Service is not found; deleting its load balancer service", "service", svcExportRef)
+			return ctrl.Result{}, r.deleteLoadBalancerService(ctx, req.NamespacedName)
+		}
+		klog.ErrorS(err, "Failed to get service", "service", svcExportRef)
+		return ctrl.Result{}, err
+	}
+	if IsInternalLoadBalancer(svc) || len(svc.Spec.Selector) == 0 {
+		// The load balancer of an internal LoadBalancer Service is exported as is; a Service without a selector,
+		// e.g. a headless Service of manually managed endpoints, cannot be fronted by another Service.
+		klog.V(4).InfoS("Service needs no load balancer service", "service", svcExportRef, "type", svc.Spec.Type)
+		return ctrl.Result{}, r.deleteLoadBalancerService(ctx, req.NamespacedName)
+	}
+
+	lbSvc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: svc.Namespace,
+			Name:      ServiceName(svc.Name),
+		},
+	}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, lbSvc, func() error {
+		formatLoadBalancerService(svc, lbSvc)
+		// The load balancer service is garbage collected along with the ServiceExport.
+		return controllerutil.SetControllerReference(svcExport, lbSvc, r.Client.Scheme())
+	})
+	if err != nil {
+		klog.ErrorS(err, "Failed to create or update load balancer service", "service", svcExportRef, "loadBalancerService", klog.KObj(lbSvc), "op", op)
+		return ctrl.Result{}, err
+	}
+	klog.V(2).InfoS("Load balancer service is created or updated", "service", svcExportRef, "loadBalancerService", klog.KObj(lbSvc), "op", op)
+	return ctrl.Result{}, nil
+}
+
+// formatLoadBalancerService formats the internal LoadBalancer Service fronting the same pods as the Service, on the
+// same ports; the node ports allocated to the load balancer service are kept.
+func formatLoadBalancerService(svc, lbSvc *corev1.Service) {
+	if lbSvc.Labels == nil {
+		lbSvc.Labels = map[string]string{}
+	}
+	lbSvc.Labels[objectmeta.ServiceLabelLoadBalancerFor] = svc.Name
+	if lbSvc.Annotations == nil {
+		lbSvc.Annotations = map[string]string{}
+	}
+	lbSvc.Annotations[objectmeta.ServiceAnnotationAzureLoadBalancerInternal] = "true"
+
+	nodePorts := map[string]int32{}
+	for _, port := range lbSvc.Spec.Ports {
+		nodePorts[port.Name] = port.NodePort
+	}
+	ports := make([]corev1.ServicePort, 0, len(svc.Spec.Ports))
+	for _, port := range svc.Spec.Ports {
+		ports = append(ports, corev1.ServicePort{
+			Name:        port.Name,
+			Protocol:    port.Protocol,
+			AppProtocol: port.AppProtocol,
+			Port:        port.Port,
+			TargetPort:  port.TargetPort,
+			NodePort:    nodePorts[port.Name],
+		})
+	}
+	lbSvc.Spec.Type = corev1.ServiceTypeLoadBalancer
+	lbSvc.Spec.Selector = svc.Spec.Selector
+	lbSvc.Spec.Ports = ports
+	lbSvc.Spec.IPFamilyPolicy = svc.Spec.IPFamilyPolicy
+	lbSvc.Spec.IPFamilies = svc.Spec.IPFamilies
+}
+
+// deleteLoadBalancerService deletes the internal LoadBalancer Service created for the exported Service with the
+// given key, if any.
+func (r *Reconciler) deleteLoadBalancerService(ctx context.Context, svcKey types.NamespacedName) error {
+	lbSvc := &corev1.Service{}
+	lbSvcKey := types.NamespacedName{Namespace: svcKey.Namespace, Name: ServiceName(svcKey.Name)}
+	if err := r.Client.Get(ctx, lbSvcKey, lbSvc); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		klog.ErrorS(err, "Failed to get load balancer service", "loadBalancerService", lbSvcKey)
+		return err
+	}
+	if lbSvc.Labels[objectmeta.ServiceLabelLoadBalancerFor] != svcKey.Name {
+		// The Service is not created by the controller.
+		return nil
+	}
+	klog.V(2).InfoS("Deleting load balancer service", "loadBalancerService", lbSvcKey, "service", svcKey)
+	if err := r.Client.Delete(ctx, lbSvc); err != nil && !errors.IsNotFound(err) {
+		klog.ErrorS(err, "Failed to delete load balancer service", "loadBalancerService", lbSvcKey)
+		return err
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("loadbalancerexport").
+		For(&fleetnetv1alpha1.ServiceExport{}).
+		Watches(&corev1.Service{}, handler.EnqueueRequestsFromMapFunc(serviceEventHandler)).
+		WithOptions(r.ControllerOptions).
+		Complete(metrics.InstrumentReconciler("loadbalancerexport", r))
+}
+
+// serviceEventHandler enqueues the ServiceExport of a Service, or of the exported Service a load balancer service
+// is created for.
+func serviceEventHandler(_ context.Context, o client.Object) []reconcile.Request {
+	name := o.GetName()
+	if svcName, ok := o.GetLabels()[objectmeta.ServiceLabelLoadBalancerFor]; ok {
+		name = svcName
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: o.GetNamespace(), Name: name}}}
+}
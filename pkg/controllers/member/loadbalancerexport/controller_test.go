@@ -0,0 +1,182 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package loadbalancerexport
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+const (
+	testNamespace = "work"
+	testName      = "app"
+)
+
+var svcKey = types.NamespacedName{Namespace: testNamespace, Name: testName}
+
+func scheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	return scheme
+}
+
+func serviceExportForTest(mode string) *fleetnetv1alpha1.ServiceExport {
+	svcExport := &fleetnetv1alpha1.ServiceExport{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, UID: "svc-export-uid"},
+	}
+	if mode != "" {
+		svcExport.Annotations = map[string]string{objectmeta.ServiceExportAnnotationConnectivityMode: mode}
+	}
+	return svcExport
+}
+
+func serviceForTest(svcType corev1.ServiceType, annotations map[string]string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName, Annotations: annotations},
+		Spec: corev1.ServiceSpec{
+			Type:     svcType,
+			Selector: map[string]string{"app": testName},
+			Ports: []corev1.ServicePort{
+				{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80, TargetPort: intstr.FromString("web")},
+			},
+		},
+	}
+}
+
+func loadBalancerServiceForTest(nodePort int32) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   testNamespace,
+			Name:        ServiceName(testName),
+			Labels:      map[string]string{objectmeta.ServiceLabelLoadBalancerFor: testName},
+			Annotations: map[string]string{objectmeta.ServiceAnnotationAzureLoadBalancerInternal: "true"},
+		},
+		Spec: corev1.ServiceSpec{
+			Type:     corev1.ServiceTypeLoadBalancer,
+			Selector: map[string]string{"app": testName},
+			Ports: []corev1.ServicePort{
+				{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80, TargetPort: intstr.FromString("web"), NodePort: nodePort},
+			},
+		},
+	}
+}
+
+// TestReconcile tests that the load balancer service follows the ServiceExport and its Service.
+func TestReconcile(t *testing.T) {
+	testCases := []struct {
+		name   string
+		objs   []client.Object
+		want   *corev1.Service
+		wantOK bool
+	}{
+		{
+			name:   "service exported through its load balancer",
+			objs:   []client.Object{serviceExportForTest(ConnectivityModeLoadBalancer), serviceForTest(corev1.ServiceTypeClusterIP, nil)},
+			want:   loadBalancerServiceForTest(0),
+			wantOK: true,
+		},
+		{
+			name: "node port of the load balancer service kept",
+			objs: []client.Object{
+				serviceExportForTest(ConnectivityModeLoadBalancer),
+				serviceForTest(corev1.ServiceTypeClusterIP, nil),
+				loadBalancerServiceForTest(30080),
+			},
+			want:   loadBalancerServiceForTest(30080),
+			wantOK: true,
+		},
+		{
+			name: "service exported through its pod IPs",
+			objs: []client.Object{
+				serviceExportForTest(""),
+				serviceForTest(corev1.ServiceTypeClusterIP, nil),
+				loadBalancerServiceForTest(30080),
+			},
+		},
+		{
+			name: "internal load balancer service",
+			objs: []client.Object{
+				serviceExportForTest(ConnectivityModeLoadBalancer),
+				serviceForTest(corev1.ServiceTypeLoadBalancer, map[string]string{objectmeta.ServiceAnnotationAzureLoadBalancerInternal: "true"}),
+				loadBalancerServiceForTest(30080),
+			},
+		},
+		{
+			name: "service not found",
+			objs: []client.Object{serviceExportForTest(ConnectivityModeLoadBalancer), loadBalancerServiceForTest(30080)},
+		},
+		{
+			name: "serviceExport not found",
+			objs: []client.Object{serviceForTest(corev1.ServiceTypeClusterIP, nil), loadBalancerServiceForTest(30080)},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme(t)).WithObjects(tc.objs...).Build()
+			r := &Reconciler{Client: fakeClient}
+			if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: svcKey}); err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+
+			got := &corev1.Service{}
+			err := fakeClient.Get(context.Background(), types.NamespacedName{Namespace: testNamespace, Name: ServiceName(testName)}, got)
+			if !tc.wantOK {
+				if !errors.IsNotFound(err) {
+					t.Fatalf("load balancer service Get() = %v, want not found", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("load balancer service Get() = %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.want.Spec, got.Spec); diff != "" {
+				t.Errorf("load balancer service spec mismatch (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.Labels, got.Labels); diff != "" {
+				t.Errorf("load balancer service labels mismatch (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.Annotations, got.Annotations); diff != "" {
+				t.Errorf("load balancer service annotations mismatch (-want, +got):\n%s", diff)
+			}
+			if owner := metav1.GetControllerOf(got); owner == nil || owner.UID != "svc-export-uid" {
+				t.Errorf("load balancer service controller = %v, want the serviceExport", owner)
+			}
+		})
+	}
+}
+
+// TestServiceName tests the ServiceName function.
+func TestServiceName(t *testing.T) {
+	name := ServiceName(testName)
+	if name != ServiceName(testName) {
+		t.Errorf("ServiceName() = %s, want a stable name", name)
+	}
+	if name == ServiceName("other") {
+		t.Errorf("ServiceName() = %s for different services, want distinct names", name)
+	}
+	if got, want := len(name), len("fleet-ilb-")+16; got != want {
+		t.Errorf("ServiceName() length = %d, want %d", got, want)
+	}
+}
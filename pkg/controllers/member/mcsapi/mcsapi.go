@@ -0,0 +1,62 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package mcsapi features the compatibility layer for the upstream Multi-Cluster Services API (KEP-1645, API group
+// multicluster.x-k8s.io), so that workloads written against the upstream API work on fleet-networking without
+// rewriting their manifests.
+//
+// The layer is a translation shim in the member cluster: each upstream ServiceExport or ServiceImport is
+// translated into a fleet-networking object of the same namespace and name, which is owned by the upstream object
+// and then handled by the regular member and hub controllers; the outcome is mirrored back into the upstream object.
+// The upstream objects are handled as unstructured objects, so that the layer does not depend on the upstream module.
+package mcsapi
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"go.goms.io/fleet-networking/pkg/common/unstructuredkind"
+)
+
+// GroupVersion is the group version of the upstream Multi-Cluster Services API.
+var GroupVersion = schema.GroupVersion{Group: "multicluster.x-k8s.io", Version: "v1alpha1"}
+
+var (
+	// ServiceExportGVK is the group version kind of the upstream ServiceExport.
+	ServiceExportGVK = GroupVersion.WithKind("ServiceExport")
+	// ServiceImportGVK is the group version kind of the upstream ServiceImport.
+	ServiceImportGVK = GroupVersion.WithKind("ServiceImport")
+)
+
+// AddToScheme registers the upstream kinds, and their lists, as unstructured objects in the scheme.
+func AddToScheme(scheme *runtime.Scheme) {
+	unstructuredkind.AddToScheme(scheme, ServiceExportGVK, ServiceImportGVK)
+}
+
+// toUnstructuredConditions converts the conditions into their unstructured form.
+func toUnstructuredConditions(conds []metav1.Condition) ([]interface{}, error) {
+	res := make([]interface{}, 0, len(conds))
+	for i := range conds {
+		cond, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&conds[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert condition %s: %w", conds[i].Type, err)
+		}
+		res = append(res, cond)
+	}
+	return res, nil
+}
+
+// setStatusField sets the field of the status of the unstructured object; a null status, which is what an object
+// without status is read back as after an update, is replaced.
+func setStatusField(obj *unstructured.Unstructured, value []interface{}, field string) error {
+	if _, ok := obj.Object["status"].(map[string]interface{}); !ok {
+		delete(obj.Object, "status")
+	}
+	return unstructured.SetNestedSlice(obj.Object, value, "status", field)
+}
@@ -0,0 +1,237 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package mcsapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/unstructuredkind"
+)
+
+const (
+	testNamespace = "work"
+	testName      = "app"
+)
+
+var key = types.NamespacedName{Namespace: testNamespace, Name: testName}
+
+func upstreamObject(gvk schema.GroupVersionKind) *unstructured.Unstructured {
+	obj := unstructuredkind.New(gvk)
+	obj.SetNamespace(testNamespace)
+	obj.SetName(testName)
+	obj.SetUID("upstream-uid")
+	return obj
+}
+
+func ownerReferences(gvk schema.GroupVersionKind) []metav1.OwnerReference {
+	return []metav1.OwnerReference{
+		{
+			APIVersion:         gvk.GroupVersion().String(),
+			Kind:               gvk.Kind,
+			Name:               testName,
+			UID:                "upstream-uid",
+			Controller:         ptrTo(true),
+			BlockOwnerDeletion: ptrTo(true),
+		},
+	}
+}
+
+func ptrTo[T any](v T) *T {
+	return &v
+}
+
+func fakeClient(t *testing.T, objects ...client.Object) (client.Client, *runtime.Scheme) {
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	AddToScheme(scheme)
+	return fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objects...).
+		WithStatusSubresource(unstructuredkind.New(ServiceExportGVK), unstructuredkind.New(ServiceImportGVK)).
+		Build(), scheme
+}
+
+// TestServiceExportReconciler_Reconcile tests the Reconcile method of the ServiceExportReconciler.
+func TestServiceExportReconciler_Reconcile(t *testing.T) {
+	valid := metav1.Condition{
+		Type:               string(fleetnetv1alpha1.ServiceExportValid),
+		Status:             metav1.ConditionTrue,
+		Reason:             "ServiceIsValid",
+		LastTransitionTime: metav1.Date(2024, 1, 1, 0, 0, 0, 0, metav1.Now().Location()),
+	}
+	testCases := []struct {
+		name           string
+		svcExport      *fleetnetv1alpha1.ServiceExport
+		wantOwnerRefs  []metav1.OwnerReference
+		wantConditions []interface{}
+	}{
+		{
+			name:          "serviceExport is created",
+			wantOwnerRefs: ownerReferences(ServiceExportGVK),
+		},
+		{
+			name: "conditions are mirrored",
+			svcExport: &fleetnetv1alpha1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:       testNamespace,
+					Name:            testName,
+					OwnerReferences: ownerReferences(ServiceExportGVK),
+				},
+				Status: fleetnetv1alpha1.ServiceExportStatus{Conditions: []metav1.Condition{valid}},
+			},
+			wantOwnerRefs: ownerReferences(ServiceExportGVK),
+			wantConditions: []interface{}{
+				map[string]interface{}{
+					"type":               "Valid",
+					"status":             "True",
+					"reason":             "ServiceIsValid",
+					"message":            "",
+					"lastTransitionTime": valid.LastTransitionTime.UTC().Format("2006-01-02T15:04:05Z"),
+				},
+			},
+		},
+		{
+			name: "serviceExport not created from the upstream one is left alone",
+			svcExport: &fleetnetv1alpha1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testName},
+				Status:     fleetnetv1alpha1.ServiceExportStatus{Conditions: []metav1.Condition{valid}},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			objects := []client.Object{upstreamObject(ServiceExportGVK)}
+			if tc.svcExport != nil {
+				objects = append(objects, tc.svcExport)
+			}
+			fakeClient, scheme := fakeClient(t, objects...)
+			r := &ServiceExportReconciler{Client: fakeClient, Scheme: scheme}
+			if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("Reconcile() got error %v, want no error", err)
+			}
+
+			svcExport := &fleetnetv1alpha1.ServiceExport{}
+			if err := fakeClient.Get(ctx, key, svcExport); err != nil {
+				t.Fatalf("ServiceExport Get() got error %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.wantOwnerRefs, svcExport.OwnerReferences); diff != "" {
+				t.Errorf("ServiceExport ownerReferences mismatch (-want, +got):\n%s", diff)
+			}
+			upstream := unstructuredkind.New(ServiceExportGVK)
+			if err := fakeClient.Get(ctx, key, upstream); err != nil {
+				t.Fatalf("upstream ServiceExport Get() got error %v, want no error", err)
+			}
+			gotConditions, _, _ := unstructured.NestedSlice(upstream.Object, "status", "conditions")
+			if diff := cmp.Diff(tc.wantConditions, gotConditions); diff != "" {
+				t.Errorf("upstream ServiceExport conditions mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestServiceImportReconciler_Reconcile tests the Reconcile method of the ServiceImportReconciler.
+func TestServiceImportReconciler_Reconcile(t *testing.T) {
+	unresolvedSpec := map[string]interface{}{
+		"type":  "ClusterSetIP",
+		"ports": []interface{}{map[string]interface{}{"port": int64(8080)}},
+	}
+	testCases := []struct {
+		name         string
+		svcImport    *fleetnetv1alpha1.ServiceImport
+		wantSpec     map[string]interface{}
+		wantClusters []interface{}
+	}{
+		{
+			name:     "serviceImport is created",
+			wantSpec: unresolvedSpec,
+		},
+		{
+			name: "spec is kept until the service is resolved",
+			svcImport: &fleetnetv1alpha1.ServiceImport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:       testNamespace,
+					Name:            testName,
+					OwnerReferences: ownerReferences(ServiceImportGVK),
+				},
+			},
+			wantSpec: unresolvedSpec,
+		},
+		{
+			name: "status is mirrored",
+			svcImport: &fleetnetv1alpha1.ServiceImport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:       testNamespace,
+					Name:            testName,
+					OwnerReferences: ownerReferences(ServiceImportGVK),
+				},
+				Status: fleetnetv1alpha1.ServiceImportStatus{
+					Type:     fleetnetv1alpha1.ClusterSetIP,
+					Ports:    []fleetnetv1alpha1.ServicePort{{Name: "http", Protocol: "TCP", Port: 80}},
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-1"}, {Cluster: "member-2"}},
+				},
+			},
+			wantSpec: map[string]interface{}{
+				"type":  "ClusterSetIP",
+				"ports": []interface{}{map[string]interface{}{"name": "http", "protocol": "TCP", "port": int64(80)}},
+			},
+			wantClusters: []interface{}{
+				map[string]interface{}{"cluster": "member-1"},
+				map[string]interface{}{"cluster": "member-2"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			upstream := upstreamObject(ServiceImportGVK)
+			upstream.Object["spec"] = unresolvedSpec
+			objects := []client.Object{upstream}
+			if tc.svcImport != nil {
+				objects = append(objects, tc.svcImport)
+			}
+			fakeClient, scheme := fakeClient(t, objects...)
+			r := &ServiceImportReconciler{Client: fakeClient, Scheme: scheme}
+			if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("Reconcile() got error %v, want no error", err)
+			}
+
+			svcImport := &fleetnetv1alpha1.ServiceImport{}
+			if err := fakeClient.Get(ctx, key, svcImport); err != nil {
+				t.Fatalf("ServiceImport Get() got error %v, want no error", err)
+			}
+			if diff := cmp.Diff(ownerReferences(ServiceImportGVK), svcImport.OwnerReferences); diff != "" {
+				t.Errorf("ServiceImport ownerReferences mismatch (-want, +got):\n%s", diff)
+			}
+			got := unstructuredkind.New(ServiceImportGVK)
+			if err := fakeClient.Get(ctx, key, got); err != nil {
+				t.Fatalf("upstream ServiceImport Get() got error %v, want no error", err)
+			}
+			gotSpec, _, _ := unstructured.NestedMap(got.Object, "spec")
+			if diff := cmp.Diff(tc.wantSpec, gotSpec); diff != "" {
+				t.Errorf("upstream ServiceImport spec mismatch (-want, +got):\n%s", diff)
+			}
+			gotClusters, _, _ := unstructured.NestedSlice(got.Object, "status", "clusters")
+			if diff := cmp.Diff(tc.wantClusters, gotClusters); diff != "" {
+				t.Errorf("upstream ServiceImport clusters mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
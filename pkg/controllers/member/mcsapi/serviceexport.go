@@ -0,0 +1,123 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package mcsapi
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+	"go.goms.io/fleet-networking/pkg/common/unstructuredkind"
+)
+
+// ServiceExportReconciler translates upstream ServiceExports into fleet-networking ServiceExports.
+type ServiceExportReconciler struct {
+	Client client.Client
+	Scheme *runtime.Scheme
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
+}
+
+//+kubebuilder:rbac:groups=multicluster.x-k8s.io,resources=serviceexports,verbs=get;list;watch
+//+kubebuilder:rbac:groups=multicluster.x-k8s.io,resources=serviceexports/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=multicluster.x-k8s.io,resources=serviceexports/finalizers,verbs=update
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceexports,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile creates a fleet-networking ServiceExport out of an upstream ServiceExport, and mirrors the conditions of
+// the former into the status of the latter; the fleet-networking ServiceExport is garbage collected along with its
+// upstream owner.
+func (r *ServiceExportReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	upstreamRef := klog.KRef(req.Namespace, req.Name)
+	startTime := time.Now()
+	klog.V(2).InfoS("Reconciliation starts", "upstreamServiceExport", upstreamRef)
+	defer func() {
+		latency := time.Since(startTime).Milliseconds()
+		klog.V(2).InfoS("Reconciliation ends", "upstreamServiceExport", upstreamRef, "latency", latency)
+	}()
+
+	upstream := unstructuredkind.New(ServiceExportGVK)
+	if err := r.Client.Get(ctx, req.NamespacedName, upstream); err != nil {
+		if errors.IsNotFound(err) {
+			klog.V(4).InfoS("Ignoring NotFound upstream serviceExport", "upstreamServiceExport", upstreamRef)
+			return ctrl.Result{}, nil
+		}
+		klog.ErrorS(err, "Failed to get upstream serviceExport", "upstreamServiceExport", upstreamRef)
+		return ctrl.Result{}, err
+	}
+	if upstream.GetDeletionTimestamp() != nil {
+		klog.V(4).InfoS("Upstream serviceExport is being deleted", "upstreamServiceExport", upstreamRef)
+		return ctrl.Result{}, nil
+	}
+
+	svcExport := &fleetnetv1alpha1.ServiceExport{}
+	if err := r.Client.Get(ctx, req.NamespacedName, svcExport); err != nil {
+		if !errors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to get serviceExport", "serviceExport", upstreamRef)
+			return ctrl.Result{}, err
+		}
+		svcExport = &fleetnetv1alpha1.ServiceExport{
+			ObjectMeta: metav1.ObjectMeta{Namespace: req.Namespace, Name: req.Name},
+		}
+		if err := controllerutil.SetControllerReference(upstream, svcExport, r.Scheme); err != nil {
+			klog.ErrorS(err, "Failed to set the controller reference", "serviceExport", upstreamRef)
+			return ctrl.Result{}, err
+		}
+		klog.V(2).InfoS("Creating serviceExport for the upstream serviceExport", "serviceExport", upstreamRef)
+		if err := r.Client.Create(ctx, svcExport); err != nil {
+			klog.ErrorS(err, "Failed to create serviceExport", "serviceExport", upstreamRef)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+	if !metav1.IsControlledBy(svcExport, upstream) {
+		// The service is exported with the fleet-networking API as well; the existing ServiceExport is left alone.
+		klog.V(2).InfoS("Skipping the upstream serviceExport as the serviceExport is not created from it", "upstreamServiceExport", upstreamRef)
+		return ctrl.Result{}, nil
+	}
+
+	conds, err := toUnstructuredConditions(svcExport.Status.Conditions)
+	if err != nil {
+		klog.ErrorS(err, "Failed to convert the conditions of serviceExport", "serviceExport", upstreamRef)
+		return ctrl.Result{}, err
+	}
+	oldConds, _, _ := unstructured.NestedSlice(upstream.Object, "status", "conditions")
+	if equality.Semantic.DeepEqual(oldConds, conds) || (len(oldConds) == 0 && len(conds) == 0) {
+		return ctrl.Result{}, nil
+	}
+	if err := setStatusField(upstream, conds, "conditions"); err != nil {
+		klog.ErrorS(err, "Failed to set the conditions of upstream serviceExport", "upstreamServiceExport", upstreamRef)
+		return ctrl.Result{}, err
+	}
+	klog.V(2).InfoS("Updating the conditions of upstream serviceExport", "upstreamServiceExport", upstreamRef)
+	if err := r.Client.Status().Update(ctx, upstream); err != nil {
+		klog.ErrorS(err, "Failed to update the status of upstream serviceExport", "upstreamServiceExport", upstreamRef)
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ServiceExportReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("mcsapi-serviceexport").
+		For(unstructuredkind.New(ServiceExportGVK)).
+		Owns(&fleetnetv1alpha1.ServiceExport{}).
+		WithOptions(r.ControllerOptions).
+		Complete(metrics.InstrumentReconciler("mcsapi-serviceexport", r))
+}
@@ -0,0 +1,165 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package mcsapi
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+	"go.goms.io/fleet-networking/pkg/common/unstructuredkind"
+)
+
+// ServiceImportReconciler translates upstream ServiceImports into fleet-networking ServiceImports.
+type ServiceImportReconciler struct {
+	Client client.Client
+	Scheme *runtime.Scheme
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
+}
+
+//+kubebuilder:rbac:groups=multicluster.x-k8s.io,resources=serviceimports,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=multicluster.x-k8s.io,resources=serviceimports/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=multicluster.x-k8s.io,resources=serviceimports/finalizers,verbs=update
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile creates a fleet-networking ServiceImport out of an upstream ServiceImport, and mirrors the status of
+// the former into the latter: the upstream API keeps the resolved ports, type and IPs in its spec, and the
+// contributing clusters in its status.
+func (r *ServiceImportReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	upstreamRef := klog.KRef(req.Namespace, req.Name)
+	startTime := time.Now()
+	klog.V(2).InfoS("Reconciliation starts", "upstreamServiceImport", upstreamRef)
+	defer func() {
+		latency := time.Since(startTime).Milliseconds()
+		klog.V(2).InfoS("Reconciliation ends", "upstreamServiceImport", upstreamRef, "latency", latency)
+	}()
+
+	upstream := unstructuredkind.New(ServiceImportGVK)
+	if err := r.Client.Get(ctx, req.NamespacedName, upstream); err != nil {
+		if errors.IsNotFound(err) {
+			klog.V(4).InfoS("Ignoring NotFound upstream serviceImport", "upstreamServiceImport", upstreamRef)
+			return ctrl.Result{}, nil
+		}
+		klog.ErrorS(err, "Failed to get upstream serviceImport", "upstreamServiceImport", upstreamRef)
+		return ctrl.Result{}, err
+	}
+	if upstream.GetDeletionTimestamp() != nil {
+		klog.V(4).InfoS("Upstream serviceImport is being deleted", "upstreamServiceImport", upstreamRef)
+		return ctrl.Result{}, nil
+	}
+
+	svcImport := &fleetnetv1alpha1.ServiceImport{}
+	if err := r.Client.Get(ctx, req.NamespacedName, svcImport); err != nil {
+		if !errors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to get serviceImport", "serviceImport", upstreamRef)
+			return ctrl.Result{}, err
+		}
+		svcImport = &fleetnetv1alpha1.ServiceImport{
+			ObjectMeta: metav1.ObjectMeta{Namespace: req.Namespace, Name: req.Name},
+		}
+		if err := controllerutil.SetControllerReference(upstream, svcImport, r.Scheme); err != nil {
+			klog.ErrorS(err, "Failed to set the controller reference", "serviceImport", upstreamRef)
+			return ctrl.Result{}, err
+		}
+		klog.V(2).InfoS("Creating serviceImport for the upstream serviceImport", "serviceImport", upstreamRef)
+		if err := r.Client.Create(ctx, svcImport); err != nil {
+			klog.ErrorS(err, "Failed to create serviceImport", "serviceImport", upstreamRef)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+	if !metav1.IsControlledBy(svcImport, upstream) {
+		// The service is imported with the fleet-networking API as well; the existing ServiceImport is left alone.
+		klog.V(2).InfoS("Skipping the upstream serviceImport as the serviceImport is not created from it", "upstreamServiceImport", upstreamRef)
+		return ctrl.Result{}, nil
+	}
+
+	spec, clusters, err := translateServiceImportStatus(&svcImport.Status)
+	if err != nil {
+		klog.ErrorS(err, "Failed to translate the status of serviceImport", "serviceImport", upstreamRef)
+		return ctrl.Result{}, err
+	}
+	// The spec is left as it is until the service is resolved, as the upstream API requires its ports and type.
+	if spec != nil {
+		oldSpec, _, _ := unstructured.NestedMap(upstream.Object, "spec")
+		if !equality.Semantic.DeepEqual(oldSpec, spec) {
+			upstream.Object["spec"] = spec
+			klog.V(2).InfoS("Updating the spec of upstream serviceImport", "upstreamServiceImport", upstreamRef)
+			if err := r.Client.Update(ctx, upstream); err != nil {
+				klog.ErrorS(err, "Failed to update upstream serviceImport", "upstreamServiceImport", upstreamRef)
+				return ctrl.Result{}, err
+			}
+		}
+	}
+	oldClusters, _, _ := unstructured.NestedSlice(upstream.Object, "status", "clusters")
+	if equality.Semantic.DeepEqual(oldClusters, clusters) || (len(oldClusters) == 0 && len(clusters) == 0) {
+		return ctrl.Result{}, nil
+	}
+	if err := setStatusField(upstream, clusters, "clusters"); err != nil {
+		klog.ErrorS(err, "Failed to set the clusters of upstream serviceImport", "upstreamServiceImport", upstreamRef)
+		return ctrl.Result{}, err
+	}
+	klog.V(2).InfoS("Updating the clusters of upstream serviceImport", "upstreamServiceImport", upstreamRef)
+	if err := r.Client.Status().Update(ctx, upstream); err != nil {
+		klog.ErrorS(err, "Failed to update the status of upstream serviceImport", "upstreamServiceImport", upstreamRef)
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// translateServiceImportStatus splits the status of a fleet-networking ServiceImport into the spec and the
+// clusters of an upstream ServiceImport; the spec is nil if the service is not resolved yet. Both APIs share the
+// same field names, so no field is renamed; the target ports, which the upstream API does not have, are dropped.
+func translateServiceImportStatus(status *fleetnetv1alpha1.ServiceImportStatus) (map[string]interface{}, []interface{}, error) {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(status)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The clusters of the upstream API carry nothing but the cluster names; the fleet-networking specific fields
+	// would be pruned by the upstream CRD.
+	clusters := make([]interface{}, 0, len(status.Clusters))
+	for _, c := range status.Clusters {
+		clusters = append(clusters, map[string]interface{}{"cluster": c.Cluster})
+	}
+	delete(obj, "clusters")
+	ports, _, _ := unstructured.NestedSlice(obj, "ports")
+	for _, port := range ports {
+		if port, ok := port.(map[string]interface{}); ok {
+			delete(port, "targetPort")
+		}
+	}
+	if ports != nil {
+		obj["ports"] = ports
+	}
+	if len(status.Ports) == 0 || status.Type == "" {
+		return nil, clusters, nil
+	}
+	return obj, clusters, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ServiceImportReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("mcsapi-serviceimport").
+		For(unstructuredkind.New(ServiceImportGVK)).
+		Owns(&fleetnetv1alpha1.ServiceImport{}).
+		WithOptions(r.ControllerOptions).
+		Complete(metrics.InstrumentReconciler("mcsapi-serviceimport", r))
+}
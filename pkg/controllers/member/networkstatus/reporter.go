@@ -0,0 +1,220 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package networkstatus features the network status reporter deployed in member cluster, which reports the
+// heartbeats, version, exports, imports and errors of the networking agent of the member cluster to the hub cluster
+// in an InternalMemberNetworkStatus.
+package networkstatus
+
+import (
+	"context"
+	"runtime/debug"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/rebuild"
+)
+
+const (
+	// jitterFactor is the maximum jitter of the period between two heartbeats, so that the member clusters do not
+	// report in lockstep.
+	jitterFactor = 0.1
+)
+
+// Reporter reports the state of the networking agent of a member cluster to the hub cluster periodically.
+type Reporter struct {
+	MemberClusterID string
+	MemberClient    client.Reader
+	HubClient       client.Client
+	// the namespace reserved for the current member cluster in the hub cluster.
+	HubNamespace string
+	// Period is how often the state is reported.
+	Period time.Duration
+	// AgentVersion is the version of the networking agent; the version of the running binary is used if it is not
+	// set.
+	AgentVersion string
+	// Resync, if set, re-publishes all the exports of the member cluster to the hub cluster; it is called when a
+	// resync is requested in the InternalMemberNetworkStatus, or when the InternalMemberNetworkStatus reported
+	// before is gone, as the hub cluster has lost its state.
+	Resync func(ctx context.Context) error
+	// Tunnel, if set, returns the tunnel peer of the member cluster, which the hub cluster distributes to the other
+	// member clusters running the tunnel agent.
+	Tunnel func(ctx context.Context) (*fleetnetv1alpha1.TunnelPeer, error)
+
+	// reported is whether the state has been reported since the agent started.
+	reported bool
+}
+
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalmembernetworkstatuses,verbs=get;list;watch;create;update
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalmembernetworkstatuses/status,verbs=get;update
+
+// SetupWithManager sets up the Reporter with the hub controller manager, which hosts the client of the hub cluster.
+func (r *Reporter) SetupWithManager(mgr ctrl.Manager) error {
+	if r.AgentVersion == "" {
+		r.AgentVersion = agentVersion()
+	}
+	return mgr.Add(r)
+}
+
+// Start implements manager.Runnable; it reports the state every period until the context is cancelled.
+func (r *Reporter) Start(ctx context.Context) error {
+	klog.V(2).InfoS("Starting the network status reporter", "memberClusterID", r.MemberClusterID, "period", r.Period)
+	wait.JitterUntilWithContext(ctx, func(ctx context.Context) {
+		if err := r.Report(ctx); err != nil {
+			klog.ErrorS(err, "Failed to report the network status", "memberClusterID", r.MemberClusterID)
+		}
+	}, r.Period, jitterFactor, true)
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable; only the leader reports the state.
+func (r *Reporter) NeedLeaderElection() bool {
+	return true
+}
+
+// Report creates or updates the InternalMemberNetworkStatus of the member cluster with its current state.
+//
+// The errors reported are the ones since the previous report; they are dropped if the report fails.
+func (r *Reporter) Report(ctx context.Context) error {
+	status, err := r.collect(ctx)
+	if err != nil {
+		return err
+	}
+	status.Errors = metrics.TakeReconcileErrors()
+
+	networkStatus := &fleetnetv1alpha1.InternalMemberNetworkStatus{
+		ObjectMeta: metav1.ObjectMeta{Namespace: r.HubNamespace, Name: r.MemberClusterID},
+	}
+	networkStatusRef := klog.KObj(networkStatus)
+	// The hub cluster updates the conditions of the same object, which may conflict with the report.
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		op, err := controllerutil.CreateOrUpdate(ctx, r.HubClient, networkStatus, func() error {
+			if networkStatus.Labels == nil {
+				networkStatus.Labels = map[string]string{}
+			}
+			networkStatus.Labels[objectmeta.LabelManagedBy] = objectmeta.MemberNetControllerManagerName
+			return nil
+		})
+		if err != nil {
+			klog.ErrorS(err, "Failed to create or update internalMemberNetworkStatus", "internalMemberNetworkStatus", networkStatusRef, "op", op)
+			return err
+		}
+
+		resyncToken, resync := rebuild.ResyncRequest(networkStatus)
+		if op == controllerutil.OperationResultCreated {
+			// The status reported before is gone with the rest of the hub state; the exports are re-published
+			// without waiting for the request of the fleet operator.
+			resync = r.reported
+		}
+
+		if resync && r.Resync != nil {
+			klog.V(2).InfoS("Re-publishing the exports to the hub cluster", "internalMemberNetworkStatus", networkStatusRef, "token", resyncToken)
+			// The controllers re-publishing the exports may not be running yet, e.g. while another agent leads
+			// them; the resync is retried at the next report.
+			resyncCtx, cancel := context.WithTimeout(ctx, r.Period)
+			err := r.Resync(resyncCtx)
+			cancel()
+			if err != nil {
+				klog.ErrorS(err, "Failed to resync the exports", "internalMemberNetworkStatus", networkStatusRef)
+				return err
+			}
+			if resyncToken != "" {
+				// Acknowledge the request, so that the exports are re-published only once per request.
+				networkStatus.Annotations[objectmeta.NetworkStatusAnnotationResynced] = resyncToken
+				if err := r.HubClient.Update(ctx, networkStatus); err != nil {
+					klog.ErrorS(err, "Failed to acknowledge the resync request", "internalMemberNetworkStatus", networkStatusRef)
+					return err
+				}
+			}
+		}
+
+		now := metav1.Now()
+		status.LastHeartbeatTime = &now
+		// The conditions and the tunnel peers are set by the hub cluster.
+		status.Conditions = networkStatus.Status.Conditions
+		status.TunnelPeers = networkStatus.Status.TunnelPeers
+		networkStatus.Status = *status.DeepCopy()
+		klog.V(4).InfoS("Reporting the network status", "internalMemberNetworkStatus", networkStatusRef, "status", networkStatus.Status)
+		if err := r.HubClient.Status().Update(ctx, networkStatus); err != nil {
+			klog.ErrorS(err, "Failed to update internalMemberNetworkStatus status", "internalMemberNetworkStatus", networkStatusRef)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	r.reported = true
+	return nil
+}
+
+// collect counts the exports and imports of the member cluster, along with its tunnel peer.
+func (r *Reporter) collect(ctx context.Context) (*fleetnetv1alpha1.InternalMemberNetworkStatusStatus, error) {
+	serviceExportList := &fleetnetv1alpha1.ServiceExportList{}
+	if err := r.MemberClient.List(ctx, serviceExportList); err != nil {
+		klog.ErrorS(err, "Failed to list serviceExports")
+		return nil, err
+	}
+	serviceImportList := &fleetnetv1alpha1.ServiceImportList{}
+	if err := r.MemberClient.List(ctx, serviceImportList); err != nil {
+		klog.ErrorS(err, "Failed to list serviceImports")
+		return nil, err
+	}
+	endpointSliceExportList := &fleetnetv1alpha1.EndpointSliceExportList{}
+	if err := r.HubClient.List(ctx, endpointSliceExportList, client.InNamespace(r.HubNamespace)); err != nil {
+		klog.ErrorS(err, "Failed to list endpointSliceExports", "hubNamespace", r.HubNamespace)
+		return nil, err
+	}
+	endpointSliceImportList := &fleetnetv1alpha1.EndpointSliceImportList{}
+	if err := r.HubClient.List(ctx, endpointSliceImportList, client.InNamespace(r.HubNamespace)); err != nil {
+		klog.ErrorS(err, "Failed to list endpointSliceImports", "hubNamespace", r.HubNamespace)
+		return nil, err
+	}
+	var tunnel *fleetnetv1alpha1.TunnelPeer
+	if r.Tunnel != nil {
+		var err error
+		if tunnel, err = r.Tunnel(ctx); err != nil {
+			klog.ErrorS(err, "Failed to get the tunnel peer")
+			return nil, err
+		}
+	}
+	return &fleetnetv1alpha1.InternalMemberNetworkStatusStatus{
+		ClusterID:            r.MemberClusterID,
+		AgentVersion:         r.AgentVersion,
+		ServiceExports:       int32(len(serviceExportList.Items)),
+		ServiceImports:       int32(len(serviceImportList.Items)),
+		EndpointSliceExports: int32(len(endpointSliceExportList.Items)),
+		EndpointSliceImports: int32(len(endpointSliceImportList.Items)),
+		Tunnel:               tunnel,
+	}, nil
+}
+
+// agentVersion returns the version of the running binary, or its VCS revision when it is not built from a
+// released module.
+func agentVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	if info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			return setting.Value
+		}
+	}
+	return info.Main.Version
+}
@@ -0,0 +1,263 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package networkstatus
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+const (
+	memberClusterID  = "bravelion"
+	hubNSForMember   = "fleet-member-bravelion"
+	testAgentVersion = "v0.3.0"
+)
+
+func TestMain(m *testing.M) {
+	// Add custom APIs to the runtime scheme
+	if err := fleetnetv1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		log.Fatalf("failed to add custom APIs to the runtime scheme: %v", err)
+	}
+	os.Exit(m.Run())
+}
+
+// TestReport tests the Report method.
+func TestReport(t *testing.T) {
+	healthyCondition := metav1.Condition{
+		Type:               string(fleetnetv1alpha1.InternalMemberNetworkStatusConditionAgentHealthy),
+		Status:             metav1.ConditionTrue,
+		Reason:             "HeartbeatReceived",
+		LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second)),
+	}
+	tunnel := &fleetnetv1alpha1.TunnelPeer{ClusterID: memberClusterID, PublicKey: "key-1", Address: "100.96.0.1"}
+	peers := []fleetnetv1alpha1.TunnelPeer{
+		{ClusterID: "highflyingcat", PublicKey: "key-2", Endpoint: "1.2.3.4:51820", Address: "100.96.0.2"},
+	}
+	testCases := []struct {
+		name          string
+		networkStatus *fleetnetv1alpha1.InternalMemberNetworkStatus
+		tunnel        *fleetnetv1alpha1.TunnelPeer
+		want          fleetnetv1alpha1.InternalMemberNetworkStatusStatus
+	}{
+		{
+			name: "first report",
+			want: fleetnetv1alpha1.InternalMemberNetworkStatusStatus{
+				ClusterID:            memberClusterID,
+				AgentVersion:         testAgentVersion,
+				ServiceExports:       2,
+				ServiceImports:       1,
+				EndpointSliceExports: 1,
+				Errors: []fleetnetv1alpha1.ControllerErrorSummary{
+					{Controller: "serviceexport-controller", Count: 1, LastError: "test error"},
+				},
+			},
+		},
+		{
+			name: "conditions are kept",
+			networkStatus: &fleetnetv1alpha1.InternalMemberNetworkStatus{
+				ObjectMeta: metav1.ObjectMeta{Namespace: hubNSForMember, Name: memberClusterID},
+				Status: fleetnetv1alpha1.InternalMemberNetworkStatusStatus{
+					ClusterID:      memberClusterID,
+					AgentVersion:   "v0.2.0",
+					ServiceExports: 5,
+					Conditions:     []metav1.Condition{healthyCondition},
+				},
+			},
+			want: fleetnetv1alpha1.InternalMemberNetworkStatusStatus{
+				ClusterID:            memberClusterID,
+				AgentVersion:         testAgentVersion,
+				ServiceExports:       2,
+				ServiceImports:       1,
+				EndpointSliceExports: 1,
+				Errors: []fleetnetv1alpha1.ControllerErrorSummary{
+					{Controller: "serviceexport-controller", Count: 1, LastError: "test error"},
+				},
+				Conditions: []metav1.Condition{healthyCondition},
+			},
+		},
+		{
+			name: "tunnel peers are kept",
+			networkStatus: &fleetnetv1alpha1.InternalMemberNetworkStatus{
+				ObjectMeta: metav1.ObjectMeta{Namespace: hubNSForMember, Name: memberClusterID},
+				Status: fleetnetv1alpha1.InternalMemberNetworkStatusStatus{
+					ClusterID:   memberClusterID,
+					TunnelPeers: peers,
+				},
+			},
+			tunnel: tunnel,
+			want: fleetnetv1alpha1.InternalMemberNetworkStatusStatus{
+				ClusterID:            memberClusterID,
+				AgentVersion:         testAgentVersion,
+				ServiceExports:       2,
+				ServiceImports:       1,
+				EndpointSliceExports: 1,
+				Errors: []fleetnetv1alpha1.ControllerErrorSummary{
+					{Controller: "serviceexport-controller", Count: 1, LastError: "test error"},
+				},
+				Tunnel:      tunnel,
+				TunnelPeers: peers,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			memberClient := fake.NewClientBuilder().WithObjects(
+				&fleetnetv1alpha1.ServiceExport{ObjectMeta: metav1.ObjectMeta{Namespace: "work", Name: "app"}},
+				&fleetnetv1alpha1.ServiceExport{ObjectMeta: metav1.ObjectMeta{Namespace: "work", Name: "db"}},
+				&fleetnetv1alpha1.ServiceImport{ObjectMeta: metav1.ObjectMeta{Namespace: "work", Name: "app"}},
+			).Build()
+			hubObjs := []client.Object{
+				&fleetnetv1alpha1.EndpointSliceExport{ObjectMeta: metav1.ObjectMeta{Namespace: hubNSForMember, Name: "app-1"}},
+				// An EndpointSliceExport of another member cluster.
+				&fleetnetv1alpha1.EndpointSliceExport{ObjectMeta: metav1.ObjectMeta{Namespace: "fleet-member-highflyingcat", Name: "app-1"}},
+			}
+			if tc.networkStatus != nil {
+				hubObjs = append(hubObjs, tc.networkStatus)
+			}
+			hubClient := fake.NewClientBuilder().
+				WithObjects(hubObjs...).
+				WithStatusSubresource(&fleetnetv1alpha1.InternalMemberNetworkStatus{}).
+				Build()
+			r := &Reporter{
+				MemberClusterID: memberClusterID,
+				MemberClient:    memberClient,
+				HubClient:       hubClient,
+				HubNamespace:    hubNSForMember,
+				Period:          time.Minute,
+				AgentVersion:    testAgentVersion,
+			}
+			if tc.tunnel != nil {
+				r.Tunnel = func(context.Context) (*fleetnetv1alpha1.TunnelPeer, error) {
+					return tc.tunnel, nil
+				}
+			}
+
+			ctx := context.Background()
+			metrics.TakeReconcileErrors()
+			metrics.ObserveReconcile("serviceexport-controller", time.Now(), ctrl.Result{}, fmt.Errorf("test error"))
+			startTime := time.Now().Truncate(time.Second)
+			if err := r.Report(ctx); err != nil {
+				t.Fatalf("Report() = %v, want no error", err)
+			}
+
+			got := &fleetnetv1alpha1.InternalMemberNetworkStatus{}
+			if err := hubClient.Get(ctx, types.NamespacedName{Namespace: hubNSForMember, Name: memberClusterID}, got); err != nil {
+				t.Fatalf("InternalMemberNetworkStatus Get() = %v, want no error", err)
+			}
+			options := []cmp.Option{
+				cmpopts.IgnoreFields(fleetnetv1alpha1.InternalMemberNetworkStatusStatus{}, "LastHeartbeatTime"),
+				cmpopts.IgnoreFields(fleetnetv1alpha1.ControllerErrorSummary{}, "LastErrorTime"),
+			}
+			if diff := cmp.Diff(tc.want, got.Status, options...); diff != "" {
+				t.Errorf("InternalMemberNetworkStatus status mismatch (-want, +got):\n%s", diff)
+			}
+			if got.Status.LastHeartbeatTime == nil || got.Status.LastHeartbeatTime.Time.Before(startTime) {
+				t.Errorf("InternalMemberNetworkStatus lastHeartbeatTime = %v, want no earlier than %v", got.Status.LastHeartbeatTime, startTime)
+			}
+		})
+	}
+}
+
+// TestReport_Resync tests that the exports are re-published when a resync is requested, or when the status
+// reported before is gone.
+func TestReport_Resync(t *testing.T) {
+	networkStatus := func(annotations map[string]string) *fleetnetv1alpha1.InternalMemberNetworkStatus {
+		return &fleetnetv1alpha1.InternalMemberNetworkStatus{
+			ObjectMeta: metav1.ObjectMeta{Namespace: hubNSForMember, Name: memberClusterID, Annotations: annotations},
+		}
+	}
+	testCases := []struct {
+		name          string
+		networkStatus *fleetnetv1alpha1.InternalMemberNetworkStatus
+		reported      bool
+		wantResync    bool
+		wantResynced  string
+	}{
+		{
+			name: "first report",
+		},
+		{
+			name:     "status is kept",
+			reported: true,
+			networkStatus: networkStatus(map[string]string{
+				objectmeta.NetworkStatusAnnotationResyncRequested: "2024-01-01T00:00:00Z",
+				objectmeta.NetworkStatusAnnotationResynced:        "2024-01-01T00:00:00Z",
+			}),
+			wantResynced: "2024-01-01T00:00:00Z",
+		},
+		{
+			name:     "resync requested",
+			reported: true,
+			networkStatus: networkStatus(map[string]string{
+				objectmeta.NetworkStatusAnnotationResyncRequested: "2024-02-01T00:00:00Z",
+				objectmeta.NetworkStatusAnnotationResynced:        "2024-01-01T00:00:00Z",
+			}),
+			wantResync:   true,
+			wantResynced: "2024-02-01T00:00:00Z",
+		},
+		{
+			name:       "status is gone",
+			reported:   true,
+			wantResync: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			memberClient := fake.NewClientBuilder().Build()
+			hubBuilder := fake.NewClientBuilder().WithStatusSubresource(&fleetnetv1alpha1.InternalMemberNetworkStatus{})
+			if tc.networkStatus != nil {
+				hubBuilder = hubBuilder.WithObjects(tc.networkStatus)
+			}
+			hubClient := hubBuilder.Build()
+			var gotResync bool
+			r := &Reporter{
+				MemberClusterID: memberClusterID,
+				MemberClient:    memberClient,
+				HubClient:       hubClient,
+				HubNamespace:    hubNSForMember,
+				Period:          time.Minute,
+				AgentVersion:    testAgentVersion,
+				Resync: func(context.Context) error {
+					gotResync = true
+					return nil
+				},
+				reported: tc.reported,
+			}
+
+			ctx := context.Background()
+			if err := r.Report(ctx); err != nil {
+				t.Fatalf("Report() = %v, want no error", err)
+			}
+			if gotResync != tc.wantResync {
+				t.Errorf("Report() resynced = %v, want %v", gotResync, tc.wantResync)
+			}
+			got := &fleetnetv1alpha1.InternalMemberNetworkStatus{}
+			if err := hubClient.Get(ctx, types.NamespacedName{Namespace: hubNSForMember, Name: memberClusterID}, got); err != nil {
+				t.Fatalf("InternalMemberNetworkStatus Get() = %v, want no error", err)
+			}
+			if gotResynced := got.Annotations[objectmeta.NetworkStatusAnnotationResynced]; gotResynced != tc.wantResynced {
+				t.Errorf("InternalMemberNetworkStatus resynced annotation = %q, want %q", gotResynced, tc.wantResynced)
+			}
+		})
+	}
+}
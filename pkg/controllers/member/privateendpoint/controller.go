@@ -0,0 +1,286 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package privateendpoint features the privateendpoint controller deployed in member cluster, which connects the
+// member cluster to the Azure Private Link Services the imported EndpointSlices are exported through with Private
+// Endpoints in its virtual network, and annotates the EndpointSliceImports with the IPs of the Private Endpoints, so
+// that they are imported instead of the endpoints of the exporting clusters.
+package privateendpoint
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/azureerrors"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+const (
+	// tagMemberCluster is the tag of the Private Endpoints created by the Reconciler, whose value is the ID of the
+	// member cluster.
+	tagMemberCluster = "fleet-networking-member-cluster"
+	// tagPrivateLinkService is the tag of the Private Endpoints created by the Reconciler, whose value is the
+	// resource ID of the Private Link Service the Private Endpoint connects to.
+	tagPrivateLinkService = "fleet-networking-private-link-service"
+
+	// connectionStatusApproved is the status of the Private Link Service connections approved by the providers.
+	connectionStatusApproved = "Approved"
+
+	// pendingRequeueInterval is how often the Private Endpoints whose connections are not approved yet are checked.
+	pendingRequeueInterval = time.Minute
+
+	hashLength = 16
+	// reconcileKeyName is the name of the only key the Reconciler reconciles, as all the Private Endpoints of the
+	// member cluster are reconciled at once.
+	reconcileKeyName = "private-endpoints"
+)
+
+// Reconciler reconciles the Azure Private Endpoints connected to the Private Link Services of the EndpointSliceImports.
+type Reconciler struct {
+	HubClient    client.Client
+	HubNamespace string
+	// MemberClusterID is the ID of the member cluster; the Private Endpoints created by the member cluster are tagged
+	// with it, so that the member clusters sharing the same resource group do not delete the ones of each other.
+	MemberClusterID string
+
+	PrivateEndpointsClient *armnetwork.PrivateEndpointsClient
+	InterfacesClient       *armnetwork.InterfacesClient
+	// ResourceGroupName is the resource group of the Private Endpoints.
+	ResourceGroupName string
+	// Location is the Azure region of the Private Endpoints, i.e. the one of the member cluster.
+	Location string
+	// SubnetID is the resource ID of the subnet the IPs of the Private Endpoints are allocated from, which must be
+	// reachable from the Pods of the member cluster.
+	SubnetID string
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
+}
+
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=endpointsliceimports,verbs=get;list;watch;update;patch
+
+// Reconcile creates a Private Endpoint for each Private Link Service the EndpointSliceImports are exported through,
+// deletes the stale Private Endpoints created by the member cluster, and annotates the EndpointSliceImports with the
+// IPs of the Private Endpoints whose connections are approved; the others are checked again periodically.
+func (r *Reconciler) Reconcile(ctx context.Context, _ ctrl.Request) (ctrl.Result, error) {
+	rgKRef := klog.KRef("", r.ResourceGroupName)
+	startTime := time.Now()
+	klog.V(2).InfoS("Reconciliation starts", "resourceGroup", rgKRef)
+	defer func() {
+		latency := time.Since(startTime).Milliseconds()
+		klog.V(2).InfoS("Reconciliation ends", "resourceGroup", rgKRef, "latency", latency)
+	}()
+
+	endpointSliceImportList := &fleetnetv1alpha1.EndpointSliceImportList{}
+	if err := r.HubClient.List(ctx, endpointSliceImportList, client.InNamespace(r.HubNamespace)); err != nil {
+		klog.ErrorS(err, "Failed to list endpoint slice imports", "namespace", r.HubNamespace)
+		return ctrl.Result{}, err
+	}
+	// The desired Private Endpoints are keyed by their names, which are derived from the Private Link Services.
+	desired := make(map[string]string)
+	for i := range endpointSliceImportList.Items {
+		endpointSliceImport := &endpointSliceImportList.Items[i]
+		if endpointSliceImport.DeletionTimestamp != nil || endpointSliceImport.Spec.PrivateLink == nil {
+			continue
+		}
+		serviceID := endpointSliceImport.Spec.PrivateLink.ServiceID
+		desired[privateEndpointName(serviceID)] = serviceID
+	}
+
+	owned := make(map[string]*armnetwork.PrivateEndpoint)
+	pager := r.PrivateEndpointsClient.NewListPager(r.ResourceGroupName, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			klog.ErrorS(err, "Failed to list private endpoints", "resourceGroup", rgKRef)
+			return ctrl.Result{}, err
+		}
+		for _, pe := range page.Value {
+			if pe == nil || pe.Name == nil || ptr.Deref(pe.Tags[tagMemberCluster], "") != r.MemberClusterID {
+				continue
+			}
+			owned[*pe.Name] = pe
+		}
+	}
+
+	// The IPs of the Private Endpoints are keyed by the Private Link Services they connect to.
+	ips := make(map[string]string)
+	pending := false
+	for name, serviceID := range desired {
+		pe, ok := owned[name]
+		if !ok || !strings.EqualFold(connectedPrivateLinkService(pe), serviceID) {
+			klog.V(2).InfoS("Writing private endpoint", "resourceGroup", rgKRef, "privateEndpoint", name, "privateLinkService", serviceID)
+			poller, err := r.PrivateEndpointsClient.BeginCreateOrUpdate(ctx, r.ResourceGroupName, name, r.privateEndpointOf(name, serviceID), nil)
+			if err != nil {
+				klog.ErrorS(err, "Failed to write private endpoint", "resourceGroup", rgKRef, "privateEndpoint", name)
+				return ctrl.Result{}, err
+			}
+			resp, err := poller.PollUntilDone(ctx, nil)
+			if err != nil {
+				klog.ErrorS(err, "Failed to write private endpoint", "resourceGroup", rgKRef, "privateEndpoint", name)
+				return ctrl.Result{}, err
+			}
+			pe = &resp.PrivateEndpoint
+		}
+		ip, err := r.privateEndpointIP(ctx, pe)
+		if err != nil {
+			klog.ErrorS(err, "Failed to get the IP of private endpoint", "resourceGroup", rgKRef, "privateEndpoint", name)
+			return ctrl.Result{}, err
+		}
+		if ip == "" {
+			klog.V(2).InfoS("Private endpoint is not connected yet", "resourceGroup", rgKRef, "privateEndpoint", name)
+			pending = true
+			continue
+		}
+		ips[strings.ToLower(serviceID)] = ip
+	}
+	for name := range owned {
+		if _, ok := desired[name]; ok {
+			continue
+		}
+		klog.V(2).InfoS("Deleting stale private endpoint", "resourceGroup", rgKRef, "privateEndpoint", name)
+		poller, err := r.PrivateEndpointsClient.BeginDelete(ctx, r.ResourceGroupName, name, nil)
+		if err == nil {
+			_, err = poller.PollUntilDone(ctx, nil)
+		}
+		if err != nil && !azureerrors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to delete private endpoint", "resourceGroup", rgKRef, "privateEndpoint", name)
+			return ctrl.Result{}, err
+		}
+	}
+
+	for i := range endpointSliceImportList.Items {
+		endpointSliceImport := &endpointSliceImportList.Items[i]
+		ip := ""
+		if endpointSliceImport.Spec.PrivateLink != nil {
+			ip = ips[strings.ToLower(endpointSliceImport.Spec.PrivateLink.ServiceID)]
+		}
+		if endpointSliceImport.Annotations[objectmeta.EndpointSliceImportAnnotationPrivateEndpointIP] == ip {
+			continue
+		}
+		if ip == "" {
+			delete(endpointSliceImport.Annotations, objectmeta.EndpointSliceImportAnnotationPrivateEndpointIP)
+		} else {
+			if endpointSliceImport.Annotations == nil {
+				endpointSliceImport.Annotations = map[string]string{}
+			}
+			endpointSliceImport.Annotations[objectmeta.EndpointSliceImportAnnotationPrivateEndpointIP] = ip
+		}
+		klog.V(2).InfoS("Annotating endpoint slice import with the IP of its private endpoint", "endpointSliceImport", klog.KObj(endpointSliceImport), "ip", ip)
+		if err := r.HubClient.Update(ctx, endpointSliceImport); err != nil {
+			klog.ErrorS(err, "Failed to annotate endpoint slice import with the IP of its private endpoint", "endpointSliceImport", klog.KObj(endpointSliceImport))
+			return ctrl.Result{}, err
+		}
+	}
+	if pending {
+		return ctrl.Result{RequeueAfter: pendingRequeueInterval}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// privateEndpointOf returns the desired Private Endpoint connected to a Private Link Service.
+func (r *Reconciler) privateEndpointOf(name, serviceID string) armnetwork.PrivateEndpoint {
+	return armnetwork.PrivateEndpoint{
+		Location: ptr.To(r.Location),
+		Tags: map[string]*string{
+			tagMemberCluster:      ptr.To(r.MemberClusterID),
+			tagPrivateLinkService: ptr.To(serviceID),
+		},
+		Properties: &armnetwork.PrivateEndpointProperties{
+			Subnet: &armnetwork.Subnet{ID: ptr.To(r.SubnetID)},
+			PrivateLinkServiceConnections: []*armnetwork.PrivateLinkServiceConnection{{
+				Name: ptr.To(name),
+				Properties: &armnetwork.PrivateLinkServiceConnectionProperties{
+					PrivateLinkServiceID: ptr.To(serviceID),
+					RequestMessage:       ptr.To("Requested by fleet member cluster " + r.MemberClusterID),
+				},
+			}},
+		},
+	}
+}
+
+// privateEndpointIP returns the private IP of the network interface of a Private Endpoint, or an empty string if
+// its connection is not approved yet.
+func (r *Reconciler) privateEndpointIP(ctx context.Context, pe *armnetwork.PrivateEndpoint) (string, error) {
+	if pe.Properties == nil || len(pe.Properties.PrivateLinkServiceConnections) == 0 || len(pe.Properties.NetworkInterfaces) == 0 {
+		return "", nil
+	}
+	connection := pe.Properties.PrivateLinkServiceConnections[0]
+	if connection == nil || connection.Properties == nil || connection.Properties.PrivateLinkServiceConnectionState == nil ||
+		ptr.Deref(connection.Properties.PrivateLinkServiceConnectionState.Status, "") != connectionStatusApproved {
+		return "", nil
+	}
+	nic := pe.Properties.NetworkInterfaces[0]
+	if nic == nil || nic.ID == nil {
+		return "", nil
+	}
+	nicID, err := arm.ParseResourceID(*nic.ID)
+	if err != nil {
+		return "", err
+	}
+	resp, err := r.InterfacesClient.Get(ctx, nicID.ResourceGroupName, nicID.Name, nil)
+	if err != nil {
+		return "", err
+	}
+	if resp.Properties == nil {
+		return "", nil
+	}
+	for _, ipConfig := range resp.Properties.IPConfigurations {
+		if ipConfig != nil && ipConfig.Properties != nil && ipConfig.Properties.PrivateIPAddress != nil {
+			return *ipConfig.Properties.PrivateIPAddress, nil
+		}
+	}
+	return "", nil
+}
+
+// connectedPrivateLinkService returns the resource ID of the Private Link Service a Private Endpoint connects to.
+func connectedPrivateLinkService(pe *armnetwork.PrivateEndpoint) string {
+	if pe.Properties == nil || len(pe.Properties.PrivateLinkServiceConnections) == 0 {
+		return ""
+	}
+	connection := pe.Properties.PrivateLinkServiceConnections[0]
+	if connection == nil || connection.Properties == nil {
+		return ""
+	}
+	return ptr.Deref(connection.Properties.PrivateLinkServiceID, "")
+}
+
+// privateEndpointName returns the name of the Private Endpoint connected to a Private Link Service, in the format
+// of fleet-[HASH] where the hash is of the case-insensitive resource ID of the Private Link Service.
+func privateEndpointName(serviceID string) string {
+	hash := sha256.Sum256([]byte(strings.ToLower(serviceID)))
+	return "fleet-" + hex.EncodeToString(hash[:])[:hashLength]
+}
+
+// SetupWithManager sets up the controller with the controller manager for hub cluster controllers.
+func (r *Reconciler) SetupWithManager(hubCtrlMgr ctrl.Manager) error {
+	key := types.NamespacedName{Namespace: r.HubNamespace, Name: reconcileKeyName}
+	enqueue := handler.EnqueueRequestsFromMapFunc(func(_ context.Context, o client.Object) []reconcile.Request {
+		if o.GetNamespace() != r.HubNamespace {
+			return []reconcile.Request{}
+		}
+		return []reconcile.Request{{NamespacedName: key}}
+	})
+	return ctrl.NewControllerManagedBy(hubCtrlMgr).Named("privateendpoint").
+		Watches(&fleetnetv1alpha1.EndpointSliceImport{}, enqueue).
+		WithOptions(r.ControllerOptions).
+		Complete(metrics.InstrumentReconciler("privateendpoint", r))
+}
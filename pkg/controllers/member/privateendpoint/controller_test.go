@@ -0,0 +1,261 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package privateendpoint
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azcorefake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	networkfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4/fake"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+const (
+	hubNSForMember      = "bravelion"
+	testResourceGroup   = "rg"
+	testMemberClusterID = "member-1"
+	testSubnetID        = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet/subnets/pe"
+	appServiceID        = "/subscriptions/other-sub/resourceGroups/rg/providers/Microsoft.Network/privateLinkServices/fleet-work-app"
+	dbServiceID         = "/subscriptions/other-sub/resourceGroups/rg/providers/Microsoft.Network/privateLinkServices/fleet-work-db"
+	goneServiceID       = "/subscriptions/other-sub/resourceGroups/rg/providers/Microsoft.Network/privateLinkServices/fleet-work-gone"
+)
+
+func TestMain(m *testing.M) {
+	// Add custom APIs to the runtime scheme
+	if err := fleetnetv1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		log.Fatalf("failed to add custom APIs to the runtime scheme: %v", err)
+	}
+	os.Exit(m.Run())
+}
+
+// fakeNetwork is an in-memory Azure network, which records the Private Endpoints written and deleted; the
+// connections to the Private Link Services are approved unless they are pending.
+type fakeNetwork struct {
+	privateEndpoints map[string]armnetwork.PrivateEndpoint
+	pending          map[string]bool
+	written          []string
+	deleted          []string
+}
+
+func nicID(name string) string {
+	return "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/networkInterfaces/" + name + ".nic"
+}
+
+func (f *fakeNetwork) newClientFactory(t *testing.T) *armnetwork.ClientFactory {
+	server := networkfake.ServerFactory{
+		PrivateEndpointsServer: networkfake.PrivateEndpointsServer{
+			BeginCreateOrUpdate: func(_ context.Context, _ string, name string, parameters armnetwork.PrivateEndpoint, _ *armnetwork.PrivateEndpointsClientBeginCreateOrUpdateOptions) (resp azcorefake.PollerResponder[armnetwork.PrivateEndpointsClientCreateOrUpdateResponse], errResp azcorefake.ErrorResponder) {
+				parameters.Name = ptr.To(name)
+				parameters.Properties.NetworkInterfaces = []*armnetwork.Interface{{ID: ptr.To(nicID(name))}}
+				status := "Approved"
+				if f.pending[name] {
+					status = "Pending"
+				}
+				parameters.Properties.PrivateLinkServiceConnections[0].Properties.PrivateLinkServiceConnectionState = &armnetwork.PrivateLinkServiceConnectionState{
+					Status: ptr.To(status),
+				}
+				f.privateEndpoints[name] = parameters
+				f.written = append(f.written, name)
+				resp.SetTerminalResponse(http.StatusOK, armnetwork.PrivateEndpointsClientCreateOrUpdateResponse{PrivateEndpoint: parameters}, nil)
+				return resp, errResp
+			},
+			BeginDelete: func(_ context.Context, _ string, name string, _ *armnetwork.PrivateEndpointsClientBeginDeleteOptions) (resp azcorefake.PollerResponder[armnetwork.PrivateEndpointsClientDeleteResponse], errResp azcorefake.ErrorResponder) {
+				delete(f.privateEndpoints, name)
+				f.deleted = append(f.deleted, name)
+				resp.SetTerminalResponse(http.StatusOK, armnetwork.PrivateEndpointsClientDeleteResponse{}, nil)
+				return resp, errResp
+			},
+			NewListPager: func(_ string, _ *armnetwork.PrivateEndpointsClientListOptions) (resp azcorefake.PagerResponder[armnetwork.PrivateEndpointsClientListResponse]) {
+				page := armnetwork.PrivateEndpointsClientListResponse{}
+				for name, pe := range f.privateEndpoints {
+					pe := pe
+					pe.Name = ptr.To(name)
+					page.Value = append(page.Value, &pe)
+				}
+				resp.AddPage(http.StatusOK, page, nil)
+				return resp
+			},
+		},
+		InterfacesServer: networkfake.InterfacesServer{
+			Get: func(_ context.Context, _ string, name string, _ *armnetwork.InterfacesClientGetOptions) (resp azcorefake.Responder[armnetwork.InterfacesClientGetResponse], errResp azcorefake.ErrorResponder) {
+				ip := map[string]string{
+					privateEndpointName(appServiceID) + ".nic": "10.2.0.4",
+					privateEndpointName(dbServiceID) + ".nic":  "10.2.0.5",
+				}[name]
+				resp.SetResponse(http.StatusOK, armnetwork.InterfacesClientGetResponse{Interface: armnetwork.Interface{
+					Properties: &armnetwork.InterfacePropertiesFormat{
+						IPConfigurations: []*armnetwork.InterfaceIPConfiguration{{
+							Properties: &armnetwork.InterfaceIPConfigurationPropertiesFormat{PrivateIPAddress: ptr.To(ip)},
+						}},
+					},
+				}}, nil)
+				return resp, errResp
+			},
+		},
+	}
+	clientFactory, err := armnetwork.NewClientFactory("sub", &azcorefake.TokenCredential{}, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: networkfake.NewServerFactoryTransport(&server),
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewClientFactory() = %v, want no error", err)
+	}
+	return clientFactory
+}
+
+func privateLinkEndpointSliceImport(name, serviceID string, annotations map[string]string) *fleetnetv1alpha1.EndpointSliceImport {
+	res := &fleetnetv1alpha1.EndpointSliceImport{
+		ObjectMeta: metav1.ObjectMeta{Namespace: hubNSForMember, Name: name, Annotations: annotations},
+	}
+	if serviceID != "" {
+		res.Spec.PrivateLink = &fleetnetv1alpha1.PrivateLinkEndpoints{ServiceID: serviceID}
+	}
+	return res
+}
+
+// TestReconcile tests the Reconcile method.
+func TestReconcile(t *testing.T) {
+	r := &Reconciler{
+		HubNamespace:      hubNSForMember,
+		MemberClusterID:   testMemberClusterID,
+		ResourceGroupName: testResourceGroup,
+		Location:          "westus",
+		SubnetID:          testSubnetID,
+	}
+	appName := privateEndpointName(appServiceID)
+	dbName := privateEndpointName(dbServiceID)
+	goneName := privateEndpointName(goneServiceID)
+	connected := func(name, serviceID string) armnetwork.PrivateEndpoint {
+		pe := r.privateEndpointOf(name, serviceID)
+		pe.Properties.NetworkInterfaces = []*armnetwork.Interface{{ID: ptr.To(nicID(name))}}
+		pe.Properties.PrivateLinkServiceConnections[0].Properties.PrivateLinkServiceConnectionState = &armnetwork.PrivateLinkServiceConnectionState{
+			Status: ptr.To("Approved"),
+		}
+		return pe
+	}
+	others := connected(goneName, goneServiceID)
+	others.Tags[tagMemberCluster] = ptr.To("member-2")
+
+	testCases := []struct {
+		name             string
+		objs             []client.Object
+		privateEndpoints map[string]armnetwork.PrivateEndpoint
+		pending          map[string]bool
+		wantWritten      []string
+		wantDeleted      []string
+		wantIPs          map[string]string
+		wantRequeueAfter time.Duration
+	}{
+		{
+			name: "new private endpoints",
+			objs: []client.Object{
+				privateLinkEndpointSliceImport("app-1", appServiceID, nil),
+				privateLinkEndpointSliceImport("app-2", appServiceID, nil),
+				privateLinkEndpointSliceImport("db", dbServiceID, nil),
+				privateLinkEndpointSliceImport("web", "", nil),
+			},
+			privateEndpoints: map[string]armnetwork.PrivateEndpoint{},
+			wantWritten:      []string{appName, dbName},
+			wantIPs:          map[string]string{"app-1": "10.2.0.4", "app-2": "10.2.0.4", "db": "10.2.0.5"},
+		},
+		{
+			name: "connection pending approval",
+			objs: []client.Object{
+				privateLinkEndpointSliceImport("app", appServiceID, nil),
+				privateLinkEndpointSliceImport("db", dbServiceID, nil),
+			},
+			privateEndpoints: map[string]armnetwork.PrivateEndpoint{},
+			pending:          map[string]bool{dbName: true},
+			wantWritten:      []string{appName, dbName},
+			wantIPs:          map[string]string{"app": "10.2.0.4"},
+			wantRequeueAfter: pendingRequeueInterval,
+		},
+		{
+			name: "existing private endpoint kept and stale one deleted",
+			objs: []client.Object{
+				privateLinkEndpointSliceImport("app", appServiceID, nil),
+			},
+			privateEndpoints: map[string]armnetwork.PrivateEndpoint{
+				appName:  connected(appName, appServiceID),
+				goneName: connected(goneName, goneServiceID),
+				"others": others,
+			},
+			wantDeleted: []string{goneName},
+			wantIPs:     map[string]string{"app": "10.2.0.4"},
+		},
+		{
+			name: "private endpoint withdrawn",
+			objs: []client.Object{
+				privateLinkEndpointSliceImport("app", "", map[string]string{objectmeta.EndpointSliceImportAnnotationPrivateEndpointIP: "10.2.0.4"}),
+			},
+			privateEndpoints: map[string]armnetwork.PrivateEndpoint{
+				appName: connected(appName, appServiceID),
+			},
+			wantDeleted: []string{appName},
+			wantIPs:     map[string]string{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			network := &fakeNetwork{privateEndpoints: tc.privateEndpoints, pending: tc.pending}
+			clientFactory := network.newClientFactory(t)
+			fakeHubClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(tc.objs...).Build()
+			reconciler := *r
+			reconciler.HubClient = fakeHubClient
+			reconciler.PrivateEndpointsClient = clientFactory.NewPrivateEndpointsClient()
+			reconciler.InterfacesClient = clientFactory.NewInterfacesClient()
+			res, err := reconciler.Reconcile(context.Background(), ctrl.Request{})
+			if err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+			if res.RequeueAfter != tc.wantRequeueAfter {
+				t.Errorf("Reconcile() requeueAfter = %v, want %v", res.RequeueAfter, tc.wantRequeueAfter)
+			}
+
+			sort.Strings(network.written)
+			sort.Strings(tc.wantWritten)
+			if diff := cmp.Diff(tc.wantWritten, network.written); diff != "" {
+				t.Errorf("written private endpoints mismatch (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantDeleted, network.deleted); diff != "" {
+				t.Errorf("deleted private endpoints mismatch (-want, +got):\n%s", diff)
+			}
+
+			endpointSliceImportList := &fleetnetv1alpha1.EndpointSliceImportList{}
+			if err := fakeHubClient.List(context.Background(), endpointSliceImportList); err != nil {
+				t.Fatalf("endpointSliceImport List() = %v, want no error", err)
+			}
+			got := map[string]string{}
+			for _, endpointSliceImport := range endpointSliceImportList.Items {
+				if ip, ok := endpointSliceImport.Annotations[objectmeta.EndpointSliceImportAnnotationPrivateEndpointIP]; ok {
+					got[endpointSliceImport.Name] = ip
+				}
+			}
+			if diff := cmp.Diff(tc.wantIPs, got); diff != "" {
+				t.Errorf("private endpoint IP annotations mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
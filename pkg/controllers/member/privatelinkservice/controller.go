@@ -0,0 +1,371 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package privatelinkservice features the privatelinkservice controller deployed in member cluster, which exposes
+// the exported internal LoadBalancer Services annotated for private-link exposure through Azure Private Link
+// Services, so that the member clusters in the virtual networks not peered with the one of the member cluster can
+// reach them through their Private Endpoints.
+package privatelinkservice
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/azureerrors"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+const (
+	// tagMemberCluster is the tag of the Private Link Services created by the Reconciler, whose value is the ID of
+	// the member cluster.
+	tagMemberCluster = "fleet-networking-member-cluster"
+	// tagService is the tag of the Private Link Services created by the Reconciler, whose value is the namespaced
+	// name of the exposed Service.
+	tagService = "fleet-networking-service"
+
+	// maxNameLength is the maximum length of the name of an Azure Private Link Service.
+	maxNameLength = 80
+	hashLength    = 10
+
+	// reconcileKeyName is the name of the only key the Reconciler reconciles, as all the Private Link Services of
+	// the member cluster are reconciled at once.
+	reconcileKeyName = "private-link-services"
+)
+
+// Reconciler reconciles the Azure Private Link Services of the exported Services annotated for private-link exposure.
+type Reconciler struct {
+	Client client.Client
+	// MemberClusterID is the ID of the member cluster; the Private Link Services created by the member cluster are
+	// tagged with it, so that the member clusters sharing the same resource group do not delete the ones of each other.
+	MemberClusterID string
+
+	PrivateLinkServicesClient *armnetwork.PrivateLinkServicesClient
+	LoadBalancersClient       *armnetwork.LoadBalancersClient
+	// ResourceGroupName is the resource group of the Private Link Services, and of the load balancers of the Services
+	// without the load balancer resource group annotation.
+	ResourceGroupName string
+	// Location is the Azure region of the Private Link Services, i.e. the one of the member cluster.
+	Location string
+	// NATSubnetID is the resource ID of the subnet the NAT IPs of the Private Link Services are allocated from.
+	NATSubnetID string
+	// AllowedSubscriptions are the subscriptions of the member clusters allowed to see, and auto-approved to connect
+	// to, the Private Link Services.
+	AllowedSubscriptions []string
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
+}
+
+// exposedService is an exported Service to expose through a Private Link Service.
+type exposedService struct {
+	svcExport *fleetnetv1alpha1.ServiceExport
+	frontend  string
+}
+
+//+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceexports,verbs=get;list;watch;update;patch
+
+// Reconcile creates or updates a Private Link Service for each exported Service annotated for private-link
+// exposure, deletes the stale Private Link Services created by the member cluster, and annotates the ServiceExports
+// with the IDs of their Private Link Services.
+func (r *Reconciler) Reconcile(ctx context.Context, _ ctrl.Request) (ctrl.Result, error) {
+	rgKRef := klog.KRef("", r.ResourceGroupName)
+	startTime := time.Now()
+	klog.V(2).InfoS("Reconciliation starts", "resourceGroup", rgKRef)
+	defer func() {
+		latency := time.Since(startTime).Milliseconds()
+		klog.V(2).InfoS("Reconciliation ends", "resourceGroup", rgKRef, "latency", latency)
+	}()
+
+	svcExportList := &fleetnetv1alpha1.ServiceExportList{}
+	if err := r.Client.List(ctx, svcExportList); err != nil {
+		klog.ErrorS(err, "Failed to list service exports")
+		return ctrl.Result{}, err
+	}
+	exposed, err := r.exposedServices(ctx, svcExportList.Items)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	owned := make(map[string]*armnetwork.PrivateLinkService)
+	pager := r.PrivateLinkServicesClient.NewListPager(r.ResourceGroupName, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			klog.ErrorS(err, "Failed to list private link services", "resourceGroup", rgKRef)
+			return ctrl.Result{}, err
+		}
+		for _, pls := range page.Value {
+			if pls == nil || pls.Name == nil || ptr.Deref(pls.Tags[tagMemberCluster], "") != r.MemberClusterID {
+				continue
+			}
+			owned[*pls.Name] = pls
+		}
+	}
+
+	ids := make(map[types.NamespacedName]string, len(exposed))
+	for name, svc := range exposed {
+		svcKey := types.NamespacedName{Namespace: svc.svcExport.Namespace, Name: svc.svcExport.Name}
+		desired := r.privateLinkServiceOf(svcKey, svc.frontend)
+		if current, ok := owned[name]; ok && current.ID != nil && equalPrivateLinkServices(current, &desired) {
+			ids[svcKey] = *current.ID
+			continue
+		}
+		klog.V(2).InfoS("Writing private link service", "resourceGroup", rgKRef, "privateLinkService", name, "service", svcKey)
+		poller, err := r.PrivateLinkServicesClient.BeginCreateOrUpdate(ctx, r.ResourceGroupName, name, desired, nil)
+		if err != nil {
+			klog.ErrorS(err, "Failed to write private link service", "resourceGroup", rgKRef, "privateLinkService", name)
+			return ctrl.Result{}, err
+		}
+		resp, err := poller.PollUntilDone(ctx, nil)
+		if err != nil {
+			klog.ErrorS(err, "Failed to write private link service", "resourceGroup", rgKRef, "privateLinkService", name)
+			return ctrl.Result{}, err
+		}
+		ids[svcKey] = ptr.Deref(resp.ID, "")
+	}
+	for name := range owned {
+		if _, ok := exposed[name]; ok {
+			continue
+		}
+		klog.V(2).InfoS("Deleting stale private link service", "resourceGroup", rgKRef, "privateLinkService", name)
+		poller, err := r.PrivateLinkServicesClient.BeginDelete(ctx, r.ResourceGroupName, name, nil)
+		if err == nil {
+			_, err = poller.PollUntilDone(ctx, nil)
+		}
+		if err != nil && !azureerrors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to delete private link service", "resourceGroup", rgKRef, "privateLinkService", name)
+			return ctrl.Result{}, err
+		}
+	}
+
+	for i := range svcExportList.Items {
+		svcExport := &svcExportList.Items[i]
+		id := ids[types.NamespacedName{Namespace: svcExport.Namespace, Name: svcExport.Name}]
+		if svcExport.Annotations[objectmeta.ServiceExportAnnotationPrivateLinkServiceID] == id {
+			continue
+		}
+		if id == "" {
+			delete(svcExport.Annotations, objectmeta.ServiceExportAnnotationPrivateLinkServiceID)
+		} else {
+			if svcExport.Annotations == nil {
+				svcExport.Annotations = map[string]string{}
+			}
+			svcExport.Annotations[objectmeta.ServiceExportAnnotationPrivateLinkServiceID] = id
+		}
+		klog.V(2).InfoS("Annotating service export with its private link service", "serviceExport", klog.KObj(svcExport), "privateLinkServiceID", id)
+		if err := r.Client.Update(ctx, svcExport); err != nil {
+			klog.ErrorS(err, "Failed to annotate service export with its private link service", "serviceExport", klog.KObj(svcExport))
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// exposedServices returns the exported Services to expose keyed by the names of their Private Link Services; the
+// Services whose internal load balancer frontends are not found yet are skipped until they are provisioned.
+func (r *Reconciler) exposedServices(ctx context.Context, svcExports []fleetnetv1alpha1.ServiceExport) (map[string]exposedService, error) {
+	// The frontends of the load balancers are listed once per resource group, keyed by their private IPs.
+	frontends := make(map[string]map[string]string)
+	res := make(map[string]exposedService)
+	for i := range svcExports {
+		svcExport := &svcExports[i]
+		if !isServiceExportValidWithNoConflict(svcExport) {
+			continue
+		}
+		svc := &corev1.Service{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Namespace: svcExport.Namespace, Name: svcExport.Name}, svc); err != nil {
+			if client.IgnoreNotFound(err) == nil {
+				continue
+			}
+			klog.ErrorS(err, "Failed to get service", "service", klog.KObj(svcExport))
+			return nil, err
+		}
+		ip := internalLoadBalancerIP(svc)
+		if ip == "" {
+			continue
+		}
+
+		rg := r.ResourceGroupName
+		if v := svc.Annotations[objectmeta.ServiceAnnotationLoadBalancerResourceGroup]; v != "" {
+			rg = v
+		}
+		if _, ok := frontends[rg]; !ok {
+			rgFrontends, err := r.listFrontends(ctx, rg)
+			if err != nil {
+				return nil, err
+			}
+			frontends[rg] = rgFrontends
+		}
+		frontend, ok := frontends[rg][ip]
+		if !ok {
+			klog.V(2).InfoS("Load balancer frontend of service is not found", "service", klog.KObj(svc), "resourceGroup", rg, "ip", ip)
+			continue
+		}
+		res[privateLinkServiceName(svc.Namespace, svc.Name)] = exposedService{svcExport: svcExport, frontend: frontend}
+	}
+	return res, nil
+}
+
+// listFrontends returns the IDs of the frontend IP configurations of the load balancers in a resource group keyed
+// by their private IPs.
+func (r *Reconciler) listFrontends(ctx context.Context, resourceGroup string) (map[string]string, error) {
+	res := make(map[string]string)
+	pager := r.LoadBalancersClient.NewListPager(resourceGroup, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			klog.ErrorS(err, "Failed to list load balancers", "resourceGroup", resourceGroup)
+			return nil, err
+		}
+		for _, lb := range page.Value {
+			if lb == nil || lb.Properties == nil {
+				continue
+			}
+			for _, frontend := range lb.Properties.FrontendIPConfigurations {
+				if frontend == nil || frontend.ID == nil || frontend.Properties == nil || frontend.Properties.PrivateIPAddress == nil {
+					continue
+				}
+				res[*frontend.Properties.PrivateIPAddress] = *frontend.ID
+			}
+		}
+	}
+	return res, nil
+}
+
+// privateLinkServiceOf returns the desired Private Link Service of a Service.
+func (r *Reconciler) privateLinkServiceOf(svcKey types.NamespacedName, frontend string) armnetwork.PrivateLinkService {
+	subscriptions := make([]*string, 0, len(r.AllowedSubscriptions))
+	for _, subscription := range r.AllowedSubscriptions {
+		subscriptions = append(subscriptions, ptr.To(subscription))
+	}
+	return armnetwork.PrivateLinkService{
+		Location: ptr.To(r.Location),
+		Tags: map[string]*string{
+			tagMemberCluster: ptr.To(r.MemberClusterID),
+			tagService:       ptr.To(svcKey.String()),
+		},
+		Properties: &armnetwork.PrivateLinkServiceProperties{
+			LoadBalancerFrontendIPConfigurations: []*armnetwork.FrontendIPConfiguration{{ID: ptr.To(frontend)}},
+			IPConfigurations: []*armnetwork.PrivateLinkServiceIPConfiguration{{
+				Name: ptr.To("nat"),
+				Properties: &armnetwork.PrivateLinkServiceIPConfigurationProperties{
+					Primary:                   ptr.To(true),
+					PrivateIPAllocationMethod: ptr.To(armnetwork.IPAllocationMethodDynamic),
+					Subnet:                    &armnetwork.Subnet{ID: ptr.To(r.NATSubnetID)},
+				},
+			}},
+			Visibility:   &armnetwork.PrivateLinkServicePropertiesVisibility{Subscriptions: subscriptions},
+			AutoApproval: &armnetwork.PrivateLinkServicePropertiesAutoApproval{Subscriptions: subscriptions},
+		},
+	}
+}
+
+// equalPrivateLinkServices returns true if the Private Link Services have the same frontend, NAT subnet and allowed
+// subscriptions, regardless of the order of the subscriptions.
+func equalPrivateLinkServices(current, desired *armnetwork.PrivateLinkService) bool {
+	return slices.Equal(privateLinkServiceDataOf(current), privateLinkServiceDataOf(desired))
+}
+
+func privateLinkServiceDataOf(pls *armnetwork.PrivateLinkService) []string {
+	if pls.Properties == nil {
+		return nil
+	}
+	var data []string
+	for _, frontend := range pls.Properties.LoadBalancerFrontendIPConfigurations {
+		if frontend != nil {
+			data = append(data, "frontend "+strings.ToLower(ptr.Deref(frontend.ID, "")))
+		}
+	}
+	for _, ipConfig := range pls.Properties.IPConfigurations {
+		if ipConfig != nil && ipConfig.Properties != nil && ipConfig.Properties.Subnet != nil {
+			data = append(data, "subnet "+strings.ToLower(ptr.Deref(ipConfig.Properties.Subnet.ID, "")))
+		}
+	}
+	if pls.Properties.Visibility != nil {
+		for _, subscription := range pls.Properties.Visibility.Subscriptions {
+			data = append(data, "visibility "+ptr.Deref(subscription, ""))
+		}
+	}
+	if pls.Properties.AutoApproval != nil {
+		for _, subscription := range pls.Properties.AutoApproval.Subscriptions {
+			data = append(data, "autoApproval "+ptr.Deref(subscription, ""))
+		}
+	}
+	slices.Sort(data)
+	return data
+}
+
+// privateLinkServiceName returns the name of the Private Link Service of a Service, in the format of
+// fleet-[NAMESPACE]-[NAME]; if it exceeds the maximum length, it is truncated and suffixed with a hash of the
+// namespace and name.
+func privateLinkServiceName(namespace, name string) string {
+	res := fmt.Sprintf("fleet-%s-%s", namespace, name)
+	if len(res) <= maxNameLength {
+		return res
+	}
+	hash := sha256.Sum256([]byte(namespace + "/" + name))
+	// The name of a Private Link Service must end with an alphanumeric character or an underscore.
+	prefix := strings.TrimRight(res[:maxNameLength-1-hashLength], "-.")
+	return fmt.Sprintf("%s-%s", prefix, hex.EncodeToString(hash[:])[:hashLength])
+}
+
+// internalLoadBalancerIP returns the private IP of an internal LoadBalancer Service annotated for private-link
+// exposure, or an empty string if the Service is not to expose or its IP is not assigned yet.
+func internalLoadBalancerIP(svc *corev1.Service) string {
+	if svc.DeletionTimestamp != nil || svc.Spec.Type != corev1.ServiceTypeLoadBalancer ||
+		svc.Annotations[objectmeta.ServiceAnnotationPrivateLinkExposure] != "true" ||
+		svc.Annotations[objectmeta.ServiceAnnotationAzureLoadBalancerInternal] != "true" {
+		return ""
+	}
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		if ingress.IP != "" {
+			return ingress.IP
+		}
+	}
+	return ""
+}
+
+// isServiceExportValidWithNoConflict returns if a ServiceExport is valid, in no conflict with the other service
+// exports, and not being deleted, i.e. its Service is exported.
+func isServiceExportValidWithNoConflict(svcExport *fleetnetv1alpha1.ServiceExport) bool {
+	validCond := meta.FindStatusCondition(svcExport.Status.Conditions, string(fleetnetv1alpha1.ServiceExportValid))
+	conflictCond := meta.FindStatusCondition(svcExport.Status.Conditions, string(fleetnetv1alpha1.ServiceExportConflict))
+	return validCond != nil && validCond.Status == metav1.ConditionTrue &&
+		conflictCond != nil && conflictCond.Status == metav1.ConditionFalse &&
+		svcExport.DeletionTimestamp == nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	enqueue := handler.EnqueueRequestsFromMapFunc(func(_ context.Context, _ client.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: reconcileKeyName}}}
+	})
+	return ctrl.NewControllerManagedBy(mgr).Named("privatelinkservice").
+		Watches(&fleetnetv1alpha1.ServiceExport{}, enqueue).
+		Watches(&corev1.Service{}, enqueue).
+		WithOptions(r.ControllerOptions).
+		Complete(metrics.InstrumentReconciler("privatelinkservice", r))
+}
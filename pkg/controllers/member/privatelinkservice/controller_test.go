@@ -0,0 +1,326 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package privatelinkservice
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azcorefake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	networkfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4/fake"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+const (
+	testNamespace       = "work"
+	testResourceGroup   = "rg"
+	testLBResourceGroup = "lb-rg"
+	testMemberClusterID = "member-1"
+	testSubnetID        = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet/subnets/pls"
+	testFrontendID      = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/loadBalancers/kubernetes-internal/frontendIPConfigurations/app"
+	testLBFrontendID    = "/subscriptions/sub/resourceGroups/lb-rg/providers/Microsoft.Network/loadBalancers/kubernetes-internal/frontendIPConfigurations/db"
+)
+
+func TestMain(m *testing.M) {
+	// Add custom APIs to the runtime scheme
+	if err := fleetnetv1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		log.Fatalf("failed to add custom APIs to the runtime scheme: %v", err)
+	}
+	os.Exit(m.Run())
+}
+
+// fakeNetwork is an in-memory Azure network, which records the Private Link Services written and deleted.
+type fakeNetwork struct {
+	frontends           map[string]map[string]string
+	privateLinkServices map[string]armnetwork.PrivateLinkService
+	written             []string
+	deleted             []string
+}
+
+func privateLinkServiceID(name string) string {
+	return "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/privateLinkServices/" + name
+}
+
+func (f *fakeNetwork) newClientFactory(t *testing.T) *armnetwork.ClientFactory {
+	server := networkfake.ServerFactory{
+		LoadBalancersServer: networkfake.LoadBalancersServer{
+			NewListPager: func(resourceGroupName string, _ *armnetwork.LoadBalancersClientListOptions) (resp azcorefake.PagerResponder[armnetwork.LoadBalancersClientListResponse]) {
+				lb := &armnetwork.LoadBalancer{Properties: &armnetwork.LoadBalancerPropertiesFormat{}}
+				for ip, id := range f.frontends[resourceGroupName] {
+					lb.Properties.FrontendIPConfigurations = append(lb.Properties.FrontendIPConfigurations, &armnetwork.FrontendIPConfiguration{
+						ID:         ptr.To(id),
+						Properties: &armnetwork.FrontendIPConfigurationPropertiesFormat{PrivateIPAddress: ptr.To(ip)},
+					})
+				}
+				resp.AddPage(http.StatusOK, armnetwork.LoadBalancersClientListResponse{
+					LoadBalancerListResult: armnetwork.LoadBalancerListResult{Value: []*armnetwork.LoadBalancer{lb}},
+				}, nil)
+				return resp
+			},
+		},
+		PrivateLinkServicesServer: networkfake.PrivateLinkServicesServer{
+			BeginCreateOrUpdate: func(_ context.Context, _ string, name string, parameters armnetwork.PrivateLinkService, _ *armnetwork.PrivateLinkServicesClientBeginCreateOrUpdateOptions) (resp azcorefake.PollerResponder[armnetwork.PrivateLinkServicesClientCreateOrUpdateResponse], errResp azcorefake.ErrorResponder) {
+				parameters.ID = ptr.To(privateLinkServiceID(name))
+				parameters.Name = ptr.To(name)
+				f.privateLinkServices[name] = parameters
+				f.written = append(f.written, name)
+				resp.SetTerminalResponse(http.StatusOK, armnetwork.PrivateLinkServicesClientCreateOrUpdateResponse{PrivateLinkService: parameters}, nil)
+				return resp, errResp
+			},
+			BeginDelete: func(_ context.Context, _ string, name string, _ *armnetwork.PrivateLinkServicesClientBeginDeleteOptions) (resp azcorefake.PollerResponder[armnetwork.PrivateLinkServicesClientDeleteResponse], errResp azcorefake.ErrorResponder) {
+				delete(f.privateLinkServices, name)
+				f.deleted = append(f.deleted, name)
+				resp.SetTerminalResponse(http.StatusOK, armnetwork.PrivateLinkServicesClientDeleteResponse{}, nil)
+				return resp, errResp
+			},
+			NewListPager: func(_ string, _ *armnetwork.PrivateLinkServicesClientListOptions) (resp azcorefake.PagerResponder[armnetwork.PrivateLinkServicesClientListResponse]) {
+				page := armnetwork.PrivateLinkServicesClientListResponse{}
+				for name, pls := range f.privateLinkServices {
+					pls := pls
+					pls.ID = ptr.To(privateLinkServiceID(name))
+					pls.Name = ptr.To(name)
+					page.Value = append(page.Value, &pls)
+				}
+				resp.AddPage(http.StatusOK, page, nil)
+				return resp
+			},
+		},
+	}
+	clientFactory, err := armnetwork.NewClientFactory("sub", &azcorefake.TokenCredential{}, &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: networkfake.NewServerFactoryTransport(&server),
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewClientFactory() = %v, want no error", err)
+	}
+	return clientFactory
+}
+
+func exportedService(name, ip string, annotations map[string]string) []client.Object {
+	return []client.Object{
+		&fleetnetv1alpha1.ServiceExport{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: name},
+			Status: fleetnetv1alpha1.ServiceExportStatus{
+				Conditions: []metav1.Condition{
+					{Type: string(fleetnetv1alpha1.ServiceExportValid), Status: metav1.ConditionTrue},
+					{Type: string(fleetnetv1alpha1.ServiceExportConflict), Status: metav1.ConditionFalse},
+				},
+			},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: name, Annotations: annotations},
+			Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+			Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{
+				Ingress: []corev1.LoadBalancerIngress{{IP: ip}},
+			}},
+		},
+	}
+}
+
+func exposureAnnotations(extra ...string) map[string]string {
+	res := map[string]string{
+		objectmeta.ServiceAnnotationPrivateLinkExposure:       "true",
+		objectmeta.ServiceAnnotationAzureLoadBalancerInternal: "true",
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		res[extra[i]] = extra[i+1]
+	}
+	return res
+}
+
+// TestReconcile tests the Reconcile method.
+func TestReconcile(t *testing.T) {
+	r := &Reconciler{
+		MemberClusterID:      testMemberClusterID,
+		ResourceGroupName:    testResourceGroup,
+		Location:             "eastus",
+		NATSubnetID:          testSubnetID,
+		AllowedSubscriptions: []string{"sub", "other-sub"},
+	}
+	appKey := types.NamespacedName{Namespace: testNamespace, Name: "app"}
+	stale := r.privateLinkServiceOf(types.NamespacedName{Namespace: testNamespace, Name: "gone"}, testFrontendID)
+	others := r.privateLinkServiceOf(types.NamespacedName{Namespace: testNamespace, Name: "other"}, testFrontendID)
+	others.Tags[tagMemberCluster] = ptr.To("member-2")
+
+	testCases := []struct {
+		name                    string
+		objs                    []client.Object
+		privateLinkServices     map[string]armnetwork.PrivateLinkService
+		wantWritten             []string
+		wantDeleted             []string
+		wantServiceExportAnnots map[string]string
+	}{
+		{
+			name: "new private link services",
+			objs: append(append(append(
+				exportedService("app", "10.0.0.10", exposureAnnotations()),
+				exportedService("db", "10.1.0.10", exposureAnnotations(objectmeta.ServiceAnnotationLoadBalancerResourceGroup, testLBResourceGroup))...),
+				exportedService("web", "10.0.0.11", map[string]string{objectmeta.ServiceAnnotationAzureLoadBalancerInternal: "true"})...),
+				exportedService("pending", "10.0.0.12", exposureAnnotations())...),
+			privateLinkServices: map[string]armnetwork.PrivateLinkService{},
+			wantWritten:         []string{"fleet-work-app", "fleet-work-db"},
+			wantServiceExportAnnots: map[string]string{
+				"app": privateLinkServiceID("fleet-work-app"),
+				"db":  privateLinkServiceID("fleet-work-db"),
+			},
+		},
+		{
+			name: "up-to-date private link service kept and stale one deleted",
+			objs: exportedService("app", "10.0.0.10", exposureAnnotations()),
+			privateLinkServices: map[string]armnetwork.PrivateLinkService{
+				"fleet-work-app":   r.privateLinkServiceOf(appKey, testFrontendID),
+				"fleet-work-gone":  stale,
+				"fleet-work-other": others,
+			},
+			wantDeleted: []string{"fleet-work-gone"},
+			wantServiceExportAnnots: map[string]string{
+				"app": privateLinkServiceID("fleet-work-app"),
+			},
+		},
+		{
+			name: "private link service withdrawn",
+			objs: []client.Object{
+				&fleetnetv1alpha1.ServiceExport{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace:   testNamespace,
+						Name:        "app",
+						Annotations: map[string]string{objectmeta.ServiceExportAnnotationPrivateLinkServiceID: privateLinkServiceID("fleet-work-app")},
+					},
+				},
+			},
+			privateLinkServices: map[string]armnetwork.PrivateLinkService{
+				"fleet-work-app": r.privateLinkServiceOf(appKey, testFrontendID),
+			},
+			wantDeleted:             []string{"fleet-work-app"},
+			wantServiceExportAnnots: map[string]string{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			network := &fakeNetwork{
+				frontends: map[string]map[string]string{
+					testResourceGroup:   {"10.0.0.10": testFrontendID, "10.0.0.11": testFrontendID},
+					testLBResourceGroup: {"10.1.0.10": testLBFrontendID},
+				},
+				privateLinkServices: tc.privateLinkServices,
+			}
+			clientFactory := network.newClientFactory(t)
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(tc.objs...).Build()
+			reconciler := *r
+			reconciler.Client = fakeClient
+			reconciler.PrivateLinkServicesClient = clientFactory.NewPrivateLinkServicesClient()
+			reconciler.LoadBalancersClient = clientFactory.NewLoadBalancersClient()
+			if _, err := reconciler.Reconcile(context.Background(), ctrl.Request{}); err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+
+			sort.Strings(network.written)
+			if diff := cmp.Diff(tc.wantWritten, network.written); diff != "" {
+				t.Errorf("written private link services mismatch (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantDeleted, network.deleted); diff != "" {
+				t.Errorf("deleted private link services mismatch (-want, +got):\n%s", diff)
+			}
+			if pls, ok := network.privateLinkServices["fleet-work-db"]; ok {
+				frontend := *pls.Properties.LoadBalancerFrontendIPConfigurations[0].ID
+				if frontend != testLBFrontendID {
+					t.Errorf("private link service frontend = %q, want %q", frontend, testLBFrontendID)
+				}
+			}
+
+			svcExportList := &fleetnetv1alpha1.ServiceExportList{}
+			if err := fakeClient.List(context.Background(), svcExportList); err != nil {
+				t.Fatalf("serviceExport List() = %v, want no error", err)
+			}
+			got := map[string]string{}
+			for _, svcExport := range svcExportList.Items {
+				if id, ok := svcExport.Annotations[objectmeta.ServiceExportAnnotationPrivateLinkServiceID]; ok {
+					got[svcExport.Name] = id
+				}
+			}
+			if diff := cmp.Diff(tc.wantServiceExportAnnots, got); diff != "" {
+				t.Errorf("service export annotations mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestEqualPrivateLinkServices tests the equalPrivateLinkServices function.
+func TestEqualPrivateLinkServices(t *testing.T) {
+	r := &Reconciler{NATSubnetID: testSubnetID, AllowedSubscriptions: []string{"sub", "other-sub"}}
+	svcKey := types.NamespacedName{Namespace: testNamespace, Name: "app"}
+	desired := r.privateLinkServiceOf(svcKey, testFrontendID)
+
+	reordered := r.privateLinkServiceOf(svcKey, strings.ToUpper(testFrontendID))
+	reordered.Properties.Visibility.Subscriptions = []*string{ptr.To("other-sub"), ptr.To("sub")}
+	otherFrontend := r.privateLinkServiceOf(svcKey, testLBFrontendID)
+	fewerSubscriptions := (&Reconciler{NATSubnetID: testSubnetID, AllowedSubscriptions: []string{"sub"}}).privateLinkServiceOf(svcKey, testFrontendID)
+
+	testCases := []struct {
+		name    string
+		current armnetwork.PrivateLinkService
+		want    bool
+	}{
+		{
+			name:    "same private link service in different order and case",
+			current: reordered,
+			want:    true,
+		},
+		{
+			name:    "different frontend",
+			current: otherFrontend,
+		},
+		{
+			name:    "different subscriptions",
+			current: fewerSubscriptions,
+		},
+		{
+			name:    "no properties",
+			current: armnetwork.PrivateLinkService{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := equalPrivateLinkServices(&tc.current, &desired); got != tc.want {
+				t.Errorf("equalPrivateLinkServices() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestPrivateLinkServiceName tests the privateLinkServiceName function.
+func TestPrivateLinkServiceName(t *testing.T) {
+	if got, want := privateLinkServiceName(testNamespace, "app"), "fleet-work-app"; got != want {
+		t.Errorf("privateLinkServiceName() = %q, want %q", got, want)
+	}
+	long := privateLinkServiceName(testNamespace, strings.Repeat("a", 100))
+	if len(long) != maxNameLength {
+		t.Errorf("privateLinkServiceName() = %q of length %d, want length %d", long, len(long), maxNameLength)
+	}
+	if other := privateLinkServiceName(testNamespace, strings.Repeat("a", 101)); other == long {
+		t.Errorf("privateLinkServiceName() = %q for different names, want distinct names", other)
+	}
+}
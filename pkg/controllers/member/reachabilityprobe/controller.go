@@ -0,0 +1,193 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package reachabilityprobe features the reachability probe controller deployed in member cluster, which probes
+// whether the Pods of the other member clusters exporting Services through their east-west gateways are reachable
+// from the member cluster, and reports the results to the hub cluster for the ClusterNetworkTopology of the fleet.
+package reachabilityprobe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+const (
+	// maxProbedEndpoints is the maximum number of endpoints probed for an EndpointSliceImport; the exporting cluster
+	// is reachable if any of them accepts the connection.
+	maxProbedEndpoints = 3
+)
+
+// DialFunc dials a TCP address, e.g. (&net.Dialer{}).DialContext.
+type DialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// Reconciler probes the endpoints of the EndpointSliceImports exported through an east-west gateway.
+type Reconciler struct {
+	MemberClusterID string
+	HubClient       client.Client
+	// Dial dials the endpoints; the default dialer is used if it is not set.
+	Dial DialFunc
+	// Interval is how often the endpoints are probed.
+	Interval time.Duration
+	// Timeout is how long a probe waits for a connection to be established.
+	Timeout time.Duration
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
+}
+
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=endpointsliceimports,verbs=get;list;watch;update;patch
+
+// Reconcile probes whether the Pods of the exporting cluster of an EndpointSliceImport are reachable, and
+// annotates the result on the EndpointSliceImport.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	endpointSliceImportRef := klog.KRef(req.Namespace, req.Name)
+	startTime := time.Now()
+	klog.V(2).InfoS("Reconciliation starts", "endpointSliceImport", endpointSliceImportRef)
+	defer func() {
+		latency := time.Since(startTime).Milliseconds()
+		klog.V(2).InfoS("Reconciliation ends", "endpointSliceImport", endpointSliceImportRef, "latency", latency)
+	}()
+
+	endpointSliceImport := &fleetnetv1alpha1.EndpointSliceImport{}
+	if err := r.HubClient.Get(ctx, req.NamespacedName, endpointSliceImport); err != nil {
+		if errors.IsNotFound(err) {
+			klog.V(4).InfoS("Ignoring NotFound endpointSliceImport", "endpointSliceImport", endpointSliceImportRef)
+			return ctrl.Result{}, nil
+		}
+		klog.ErrorS(err, "Failed to get endpoint slice import", "endpointSliceImport", endpointSliceImportRef)
+		return ctrl.Result{}, err
+	}
+	if endpointSliceImport.DeletionTimestamp != nil || endpointSliceImport.Spec.Gateway == nil {
+		return ctrl.Result{}, nil
+	}
+
+	addresses := probeAddresses(&endpointSliceImport.Spec)
+	if len(addresses) == 0 {
+		// There is nothing to probe until the exporting cluster has ready endpoints.
+		klog.V(4).InfoS("No endpoints to probe", "endpointSliceImport", endpointSliceImportRef)
+		return ctrl.Result{RequeueAfter: r.Interval}, nil
+	}
+
+	// The result is reported only when the reachability changes, so that the hub is not updated at every probe.
+	reachability := r.probe(ctx, addresses)
+	if last := lastProbeResult(endpointSliceImport); last != nil && last.SourceCluster == r.MemberClusterID && last.Reachability == reachability {
+		klog.V(4).InfoS("Reachability is not changed", "endpointSliceImport", endpointSliceImportRef, "reachability", reachability)
+		return ctrl.Result{RequeueAfter: r.Interval}, nil
+	}
+
+	result := fleetnetv1alpha1.ReachabilityProbeResult{
+		SourceCluster: r.MemberClusterID,
+		Reachability:  reachability,
+		ProbeTime:     metav1.Now(),
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		klog.ErrorS(err, "Failed to marshal the reachability probe result", "endpointSliceImport", endpointSliceImportRef)
+		return ctrl.Result{}, err
+	}
+	if endpointSliceImport.Annotations == nil {
+		endpointSliceImport.Annotations = map[string]string{}
+	}
+	endpointSliceImport.Annotations[objectmeta.EndpointSliceImportAnnotationReachabilityProbe] = string(data)
+	klog.V(2).InfoS("Reporting the reachability of the exporting cluster", "endpointSliceImport", endpointSliceImportRef,
+		"clusterID", endpointSliceImport.Spec.EndpointSliceReference.ClusterID, "reachability", reachability)
+	if err := r.HubClient.Update(ctx, endpointSliceImport); err != nil {
+		klog.ErrorS(err, "Failed to annotate the reachability probe result", "endpointSliceImport", endpointSliceImportRef)
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: r.Interval}, nil
+}
+
+// probe dials the addresses and returns Direct if any of them accepts the connection, and Gateway otherwise.
+func (r *Reconciler) probe(ctx context.Context, addresses []string) fleetnetv1alpha1.ClusterReachability {
+	dial := r.Dial
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	for _, address := range addresses {
+		probeCtx, cancel := context.WithTimeout(ctx, r.Timeout)
+		conn, err := dial(probeCtx, "tcp", address)
+		cancel()
+		if err != nil {
+			klog.V(4).InfoS("Endpoint is not reachable", "address", address, "err", err)
+			continue
+		}
+		_ = conn.Close()
+		return fleetnetv1alpha1.ClusterReachabilityDirect
+	}
+	return fleetnetv1alpha1.ClusterReachabilityGateway
+}
+
+// probeAddresses returns the addresses to probe of the ready endpoints exported, i.e. not the ones of the
+// gateway, on the first TCP port.
+func probeAddresses(spec *fleetnetv1alpha1.EndpointSliceExportSpec) []string {
+	var port *int32
+	for i := range spec.Ports {
+		if spec.Ports[i].Port != nil && ptr.Deref(spec.Ports[i].Protocol, corev1.ProtocolTCP) == corev1.ProtocolTCP {
+			port = spec.Ports[i].Port
+			break
+		}
+	}
+	if port == nil {
+		return nil
+	}
+	var res []string
+	for i := range spec.Endpoints {
+		if !spec.Endpoints[i].IsReady() || len(spec.Endpoints[i].Addresses) == 0 {
+			continue
+		}
+		res = append(res, net.JoinHostPort(spec.Endpoints[i].Addresses[0], strconv.Itoa(int(*port))))
+		if len(res) == maxProbedEndpoints {
+			break
+		}
+	}
+	return res
+}
+
+// lastProbeResult returns the probe result annotated on the EndpointSliceImport; it returns nil if there is none
+// or it is invalid.
+func lastProbeResult(endpointSliceImport *fleetnetv1alpha1.EndpointSliceImport) *fleetnetv1alpha1.ReachabilityProbeResult {
+	data, ok := endpointSliceImport.Annotations[objectmeta.EndpointSliceImportAnnotationReachabilityProbe]
+	if !ok {
+		return nil
+	}
+	result := &fleetnetv1alpha1.ReachabilityProbeResult{}
+	if err := json.Unmarshal([]byte(data), result); err != nil {
+		return nil
+	}
+	return result
+}
+
+// SetupWithManager sets up the controller with the controller manager for hub cluster controllers.
+func (r *Reconciler) SetupWithManager(hubCtrlMgr ctrl.Manager) error {
+	if r.Interval <= 0 || r.Timeout <= 0 {
+		return fmt.Errorf("the probe interval %v and timeout %v must be positive", r.Interval, r.Timeout)
+	}
+	return ctrl.NewControllerManagedBy(hubCtrlMgr).Named("reachabilityprobe").
+		// The annotation written by the controller itself does not change the generation, so that the results
+		// do not trigger new probes; the endpoints are re-probed periodically instead.
+		For(&fleetnetv1alpha1.EndpointSliceImport{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		WithOptions(r.ControllerOptions).
+		Complete(metrics.InstrumentReconciler("reachabilityprobe", r))
+}
@@ -0,0 +1,242 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package reachabilityprobe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+const (
+	memberClusterID         = "bravelion"
+	hubNSForMember          = "bravelion"
+	exportingClusterID      = "highflyingcat"
+	endpointSliceImportName = "highflyingcat-work-app-endpointslice-1a2bc"
+	probeInterval           = time.Minute
+)
+
+var (
+	endpointSliceImportKey = types.NamespacedName{Namespace: hubNSForMember, Name: endpointSliceImportName}
+)
+
+func TestMain(m *testing.M) {
+	// Add custom APIs to the runtime scheme
+	if err := fleetnetv1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		log.Fatalf("failed to add custom APIs to the runtime scheme: %v", err)
+	}
+	os.Exit(m.Run())
+}
+
+// fakeDial accepts the connections to the reachable addresses only, and records the dialed addresses.
+type fakeDial struct {
+	reachable map[string]bool
+	dialed    []string
+}
+
+func (d *fakeDial) dial(_ context.Context, _, address string) (net.Conn, error) {
+	d.dialed = append(d.dialed, address)
+	if !d.reachable[address] {
+		return nil, fmt.Errorf("dial tcp %s: i/o timeout", address)
+	}
+	conn, peer := net.Pipe()
+	_ = peer.Close()
+	return conn, nil
+}
+
+func gatewayEndpointSliceImport(lastResult *fleetnetv1alpha1.ReachabilityProbeResult) *fleetnetv1alpha1.EndpointSliceImport {
+	res := &fleetnetv1alpha1.EndpointSliceImport{
+		ObjectMeta: metav1.ObjectMeta{Namespace: hubNSForMember, Name: endpointSliceImportName},
+		Spec: fleetnetv1alpha1.EndpointSliceExportSpec{
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Endpoints: []fleetnetv1alpha1.Endpoint{
+				{Addresses: []string{"10.0.0.1"}},
+				{Addresses: []string{"10.0.0.2"}},
+			},
+			Ports: []discoveryv1.EndpointPort{
+				{Name: ptr.To("dns"), Protocol: ptr.To(corev1.ProtocolUDP), Port: ptr.To(int32(53))},
+				{Name: ptr.To("web"), Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To(int32(80))},
+			},
+			Gateway: &fleetnetv1alpha1.GatewayEndpoints{
+				Endpoints: []fleetnetv1alpha1.Endpoint{{Addresses: []string{"20.0.0.1"}}},
+				Ports:     []discoveryv1.EndpointPort{{Name: ptr.To("web"), Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To(int32(15100))}},
+			},
+			EndpointSliceReference: fleetnetv1alpha1.ExportedObjectReference{ClusterID: exportingClusterID},
+		},
+	}
+	if lastResult != nil {
+		data, _ := json.Marshal(lastResult)
+		res.Annotations = map[string]string{objectmeta.EndpointSliceImportAnnotationReachabilityProbe: string(data)}
+	}
+	return res
+}
+
+// TestProbeAddresses tests the probeAddresses function.
+func TestProbeAddresses(t *testing.T) {
+	notReady := gatewayEndpointSliceImport(nil)
+	notReady.Spec.Endpoints = []fleetnetv1alpha1.Endpoint{
+		{Addresses: []string{"10.0.0.1"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(false)}},
+		{Addresses: []string{"10.0.0.2"}},
+	}
+	tooMany := gatewayEndpointSliceImport(nil)
+	tooMany.Spec.Endpoints = []fleetnetv1alpha1.Endpoint{
+		{Addresses: []string{"10.0.0.1"}},
+		{Addresses: []string{"10.0.0.2"}},
+		{Addresses: []string{"10.0.0.3"}},
+		{Addresses: []string{"10.0.0.4"}},
+	}
+	udpOnly := gatewayEndpointSliceImport(nil)
+	udpOnly.Spec.Ports = udpOnly.Spec.Ports[:1]
+
+	testCases := []struct {
+		name                string
+		endpointSliceImport *fleetnetv1alpha1.EndpointSliceImport
+		want                []string
+	}{
+		{
+			name:                "ready endpoints on the first TCP port",
+			endpointSliceImport: gatewayEndpointSliceImport(nil),
+			want:                []string{"10.0.0.1:80", "10.0.0.2:80"},
+		},
+		{
+			name:                "endpoints not ready",
+			endpointSliceImport: notReady,
+			want:                []string{"10.0.0.2:80"},
+		},
+		{
+			name:                "too many endpoints",
+			endpointSliceImport: tooMany,
+			want:                []string{"10.0.0.1:80", "10.0.0.2:80", "10.0.0.3:80"},
+		},
+		{
+			name:                "no TCP ports",
+			endpointSliceImport: udpOnly,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := probeAddresses(&tc.endpointSliceImport.Spec)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("probeAddresses() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestReconcile tests the Reconcile method.
+func TestReconcile(t *testing.T) {
+	lastProbeTime := metav1.NewTime(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	notExportedThroughGateway := gatewayEndpointSliceImport(nil)
+	notExportedThroughGateway.Spec.Gateway = nil
+
+	testCases := []struct {
+		name                string
+		endpointSliceImport *fleetnetv1alpha1.EndpointSliceImport
+		reachable           map[string]bool
+		wantDialed          []string
+		wantReachability    fleetnetv1alpha1.ClusterReachability
+		// wantProbeTime is the probe time of the result when it is kept; the time of the new results is not compared.
+		wantProbeTime *metav1.Time
+	}{
+		{
+			name:                "not exported through a gateway",
+			endpointSliceImport: notExportedThroughGateway,
+		},
+		{
+			name:                "reachable directly",
+			endpointSliceImport: gatewayEndpointSliceImport(nil),
+			reachable:           map[string]bool{"10.0.0.2:80": true},
+			wantDialed:          []string{"10.0.0.1:80", "10.0.0.2:80"},
+			wantReachability:    fleetnetv1alpha1.ClusterReachabilityDirect,
+		},
+		{
+			name:                "reachable through the gateway only",
+			endpointSliceImport: gatewayEndpointSliceImport(nil),
+			wantDialed:          []string{"10.0.0.1:80", "10.0.0.2:80"},
+			wantReachability:    fleetnetv1alpha1.ClusterReachabilityGateway,
+		},
+		{
+			name: "reachability not changed",
+			endpointSliceImport: gatewayEndpointSliceImport(&fleetnetv1alpha1.ReachabilityProbeResult{
+				SourceCluster: memberClusterID, Reachability: fleetnetv1alpha1.ClusterReachabilityDirect, ProbeTime: lastProbeTime,
+			}),
+			reachable:        map[string]bool{"10.0.0.1:80": true},
+			wantDialed:       []string{"10.0.0.1:80"},
+			wantReachability: fleetnetv1alpha1.ClusterReachabilityDirect,
+			wantProbeTime:    &lastProbeTime,
+		},
+		{
+			name: "reachability changed",
+			endpointSliceImport: gatewayEndpointSliceImport(&fleetnetv1alpha1.ReachabilityProbeResult{
+				SourceCluster: memberClusterID, Reachability: fleetnetv1alpha1.ClusterReachabilityDirect, ProbeTime: lastProbeTime,
+			}),
+			wantDialed:       []string{"10.0.0.1:80", "10.0.0.2:80"},
+			wantReachability: fleetnetv1alpha1.ClusterReachabilityGateway,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeHubClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(tc.endpointSliceImport).Build()
+			dial := &fakeDial{reachable: tc.reachable}
+			r := &Reconciler{
+				MemberClusterID: memberClusterID,
+				HubClient:       fakeHubClient,
+				Dial:            dial.dial,
+				Interval:        probeInterval,
+				Timeout:         time.Second,
+			}
+			res, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: endpointSliceImportKey})
+			if err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.wantDialed, dial.dialed); diff != "" {
+				t.Errorf("dialed addresses mismatch (-want, +got):\n%s", diff)
+			}
+
+			endpointSliceImport := &fleetnetv1alpha1.EndpointSliceImport{}
+			if err := fakeHubClient.Get(context.Background(), endpointSliceImportKey, endpointSliceImport); err != nil {
+				t.Fatalf("endpointSliceImport Get() = %v, want no error", err)
+			}
+			got := lastProbeResult(endpointSliceImport)
+			if tc.wantReachability == "" {
+				if got != nil {
+					t.Errorf("lastProbeResult() = %+v, want nil", got)
+				}
+				return
+			}
+			if res.RequeueAfter != probeInterval {
+				t.Errorf("Reconcile() requeueAfter = %v, want %v", res.RequeueAfter, probeInterval)
+			}
+			if got == nil {
+				t.Fatalf("lastProbeResult() = nil, want reachability %q", tc.wantReachability)
+			}
+			if got.SourceCluster != memberClusterID || got.Reachability != tc.wantReachability {
+				t.Errorf("lastProbeResult() = %+v, want source cluster %q and reachability %q", got, memberClusterID, tc.wantReachability)
+			}
+			if tc.wantProbeTime != nil && !got.ProbeTime.Equal(tc.wantProbeTime) {
+				t.Errorf("lastProbeResult() probe time = %v, want %v", got.ProbeTime, tc.wantProbeTime)
+			}
+		})
+	}
+}
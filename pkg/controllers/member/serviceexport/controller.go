@@ -16,38 +16,56 @@ import (
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/cloud-provider-azure/pkg/azclient/publicipaddressclient"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"go.goms.io/fleet/pkg/utils/controller"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/apply"
 	"go.goms.io/fleet-networking/pkg/common/condition"
+	"go.goms.io/fleet-networking/pkg/common/exportdrain"
+	"go.goms.io/fleet-networking/pkg/common/fairqueue"
 	"go.goms.io/fleet-networking/pkg/common/metrics"
+	"go.goms.io/fleet-networking/pkg/common/namespacesameness"
 	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/pause"
+	"go.goms.io/fleet-networking/pkg/common/tracing"
 )
 
 const (
 	svcExportValidCondReason                 = "ServiceIsValid"
 	svcExportInvalidNotFoundCondReason       = "ServiceNotFound"
 	svcExportInvalidIneligibleCondReason     = "ServiceIneligible"
+	svcExportInvalidNoPortsSelectedReason    = "NoPortsSelected"
+	svcExportInvalidNamespaceNotAllowed      = "NamespaceNotAllowed"
 	svcExportPendingConflictResolutionReason = "ServicePendingConflictResolution"
 
-	// svcExportCleanupFinalizer is the finalizer ServiceExport controllers adds to mark that
-	// a ServiceExport can only be deleted after its corresponding Service has been unexported from the hub cluster.
-	svcExportCleanupFinalizer = "networking.fleet.azure.com/svc-export-cleanup"
-
 	// ControllerName is the name of the Reconciler.
 	ControllerName = "serviceexport-controller"
+
+	// svcExportResyncInterval is the interval at which an exported Service is re-reconciled, so that the objects
+	// derived from it in the hub cluster (e.g. the InternalServiceExport) are re-created if they have been deleted
+	// out-of-band.
+	svcExportResyncInterval = time.Minute * 5
 )
 
 // Reconciler reconciles the export of a Service.
@@ -63,23 +81,68 @@ type Reconciler struct {
 	AzurePublicIPAddressClient publicipaddressclient.Interface
 
 	EnableTrafficManagerFeature bool
+
+	// IgnoreSystemManagedUpdates, if set, filters out the ServiceExport update events that change only the
+	// system managed fields, which prevents the controller from re-processing its own status writes.
+	IgnoreSystemManagedUpdates bool
+
+	// CleanupFinalizer is the finalizer added to ServiceExports to unexport their Services before they are
+	// deleted; objectmeta.ServiceExportCleanupFinalizer is used if empty.
+	CleanupFinalizer string
+
+	// HealthCheckAnnotationKeys are the keys of the Service annotations which configure the health checks of the
+	// endpoints, e.g. the health-check path and port; these annotations are exported along with the Service.
+	HealthCheckAnnotationKeys []string
+
+	// RejectHeadlessServices, if set, refuses to export the headless Services, i.e. the HeadlessServiceExport
+	// feature is disabled.
+	RejectHeadlessServices bool
+
+	// EnforceNamespaceSameness, if set, refuses to export the Services in the namespaces excluded from
+	// multi-cluster networking by the NamespaceSamenessPolicies of the member cluster.
+	EnforceNamespaceSameness bool
+
+	// AggregateEndpointSliceExports tells if the EndpointSlice controller aggregates the EndpointSliceExports of each
+	// Service; the aggregated EndpointSliceExports are stale otherwise.
+	AggregateEndpointSliceExports bool
+
+	// ServerSideApply, if set, writes the InternalServiceExports with server-side applies, so that the writes never
+	// conflict with the status the hub cluster sets.
+	ServerSideApply bool
+
+	// NewQueue, if set, constructs the work queue of the controller, e.g. a queue that shares the reconcile
+	// throughput fairly among namespaces; the default work queue is used otherwise.
+	NewQueue fairqueue.NewQueueFunc
+
+	// ResyncEvents, if set, enqueue the ServiceExports whose Services are to be re-published to the hub cluster at
+	// once, e.g. after the hub cluster has lost its state.
+	ResyncEvents <-chan event.GenericEvent
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions ctrlcontroller.Options
 }
 
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceexports,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceexports/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceexports/finalizers,verbs=update
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalserviceexports,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=endpointsliceexports,verbs=get;list;watch;delete
+//+kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch
 //+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=namespacesamenesspolicies,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
 
 // Reconcile exports a Service.
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	svcRef := klog.KRef(req.Namespace, req.Name)
+	logger := klog.FromContext(ctx).WithValues("service", svcRef)
+	ctx = klog.NewContext(ctx, logger)
 	startTime := time.Now()
-	klog.V(2).InfoS("Reconciliation starts", "service", svcRef)
+	logger.V(2).Info("Reconciliation starts")
 	defer func() {
 		latency := time.Since(startTime).Milliseconds()
-		klog.V(2).InfoS("Reconciliation ends", "service", svcRef, "latency", latency)
+		logger.V(2).Info("Reconciliation ends", "latency", latency)
 	}()
 
 	// Retrieve the ServiceExport object.
@@ -90,24 +153,47 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			// changes in a Service that has not been exported yet, or when a ServiceExport is deleted before the
 			// corresponding Service is exported to the fleet (and a cleanup finalizer is added). Either case requires
 			// no action on this controller's end.
-			klog.V(4).InfoS("Service export is not found", "service", svcRef)
+			logger.V(4).Info("Service export is not found")
 			return ctrl.Result{}, nil
 		}
 		// An error has occurred when getting the ServiceExport.
-		klog.ErrorS(err, "Failed to get service export", "service", svcRef)
+		logger.Error(err, "Failed to get service export")
 		return ctrl.Result{}, err
 	}
 
+	// Leave the ServiceExport, and the Service as it has been exported, as it is while its reconciliation is
+	// paused; the Paused condition is removed as soon as the reconciliation resumes.
+	paused := pause.IsPaused(&svcExport)
+	if pause.SetCondition(&svcExport.Status.Conditions, string(fleetnetv1alpha1.ServiceExportPaused), paused, svcExport.Generation) {
+		if err := r.MemberClient.Status().Update(ctx, &svcExport); err != nil {
+			logger.Error(err, "Failed to update the paused condition of the service export")
+			return ctrl.Result{}, err
+		}
+	}
+	if paused {
+		logger.V(2).Info("Reconciliation of the service export is paused")
+		return ctrl.Result{}, nil
+	}
+
 	// Check if the ServiceExport has been deleted and needs cleanup (unexporting Service).
 	// A ServiceExport needs cleanup when it has the ServiceExport cleanup finalizer added; the absence of this
 	// finalizer guarantees that the corresponding Service has never been exported to the fleet, thus no action
 	// is needed.
 	if svcExport.DeletionTimestamp != nil {
-		if controllerutil.ContainsFinalizer(&svcExport, svcExportCleanupFinalizer) {
-			klog.V(4).InfoS("Service export is deleted; unexport the service", "service", svcRef)
+		if r.hasCleanupFinalizer(&svcExport) {
+			// Keep the Service exported while its endpoints are drained; the EndpointSlice controller exports them
+			// as terminating in the meantime.
+			if _, err := exportdrain.Period(&svcExport); err != nil {
+				logger.Error(err, "Ignoring the drain period")
+			}
+			if remaining := exportdrain.Remaining(&svcExport, startTime); remaining > 0 {
+				logger.V(4).Info("Service export is deleted; drain the endpoints before unexporting the service", "remaining", remaining)
+				return ctrl.Result{RequeueAfter: remaining}, nil
+			}
+			logger.V(4).Info("Service export is deleted; unexport the service")
 			res, err := r.unexportService(ctx, &svcExport)
 			if err != nil {
-				klog.ErrorS(err, "Failed to unexport the service", "service", svcRef)
+				logger.Error(err, "Failed to unexport the service")
 			}
 			return res, err
 		}
@@ -128,60 +214,111 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		r.Recorder.Eventf(&svcExport, corev1.EventTypeWarning, "ServiceNotFound", "Service %s is not found or in the deleting state", svc.Name)
 
 		// Unexport the Service if the ServiceExport has the cleanup finalizer added.
-		klog.V(4).InfoS("Service is deleted; unexport the service", "service", svcRef)
-		if controllerutil.ContainsFinalizer(&svcExport, svcExportCleanupFinalizer) {
+		logger.V(4).Info("Service is deleted; unexport the service")
+		if r.hasCleanupFinalizer(&svcExport) {
 			if _, err = r.unexportService(ctx, &svcExport); err != nil {
-				klog.ErrorS(err, "Failed to unexport the service", "service", svcRef)
+				logger.Error(err, "Failed to unexport the service")
 				return ctrl.Result{}, err
 			}
 		}
 		// Mark the ServiceExport as invalid.
-		klog.V(4).InfoS("Mark service export as invalid (service not found)", "service", svcRef)
+		logger.V(4).Info("Mark service export as invalid (service not found)")
 		if err := r.markServiceExportAsInvalidNotFound(ctx, &svcExport); err != nil {
-			klog.ErrorS(err, "Failed to mark service export as invalid (service not found)", "service", svcRef)
+			logger.Error(err, "Failed to mark service export as invalid (service not found)")
 			return ctrl.Result{}, err
 		}
 		return ctrl.Result{}, nil
 	// An unexpected error occurs when retrieving the Service.
 	case err != nil:
-		klog.ErrorS(err, "Failed to get the service", "service", svcRef)
+		logger.Error(err, "Failed to get the service")
 		return ctrl.Result{}, err
 	}
 
 	// Check if the Service is eligible for export.
-	if !isServiceEligibleForExport(&svc) {
+	if !isServiceEligibleForExport(&svc) || (r.RejectHeadlessServices && isHeadlessService(&svc)) {
 		r.Recorder.Eventf(&svcExport, corev1.EventTypeWarning, "ServiceNotEligible", "Service %s is not eligible for exporting and please check service spec", svc.Name)
 
 		// Unexport ineligible Service if the ServiceExport has the cleanup finalizer added.
-		if controllerutil.ContainsFinalizer(&svcExport, svcExportCleanupFinalizer) {
-			klog.V(4).InfoS("Service is ineligible; unexport the service", "service", svcRef)
+		if r.hasCleanupFinalizer(&svcExport) {
+			logger.V(4).Info("Service is ineligible; unexport the service")
 			if _, err = r.unexportService(ctx, &svcExport); err != nil {
-				klog.ErrorS(err, "Failed to unexport the service", "service", svcRef)
+				logger.Error(err, "Failed to unexport the service")
 				return ctrl.Result{}, err
 			}
 		}
 		// Mark the ServiceExport as invalid.
-		klog.V(4).InfoS("Mark service export as invalid (service ineligible)", "service", svcRef)
+		logger.V(4).Info("Mark service export as invalid (service ineligible)")
 		err := r.markServiceExportAsInvalidSvcIneligible(ctx, &svcExport, &svc)
 		if err != nil {
-			klog.ErrorS(err, "Failed to mark service export as invalid (service ineligible)", "service", svcRef)
+			logger.Error(err, "Failed to mark service export as invalid (service ineligible)")
+		}
+		return ctrl.Result{}, err
+	}
+
+	// Check if the namespace of the Service participates in multi-cluster networking.
+	if r.EnforceNamespaceSameness {
+		reason, err := namespacesameness.Check(ctx, r.MemberClient, svc.Namespace)
+		if err != nil {
+			logger.Error(err, "Failed to evaluate the namespace sameness policies")
+			return ctrl.Result{}, err
+		}
+		if reason != "" {
+			r.Recorder.Eventf(&svcExport, corev1.EventTypeWarning, "NamespaceNotAllowed", "Service %s cannot be exported: %s", svc.Name, reason)
+
+			// Unexport the Service if the ServiceExport has the cleanup finalizer added.
+			if r.hasCleanupFinalizer(&svcExport) {
+				logger.V(4).Info("Namespace is not allowed; unexport the service")
+				if _, err = r.unexportService(ctx, &svcExport); err != nil {
+					logger.Error(err, "Failed to unexport the service")
+					return ctrl.Result{}, err
+				}
+			}
+			// Mark the ServiceExport as invalid.
+			logger.V(4).Info("Mark service export as invalid (namespace not allowed)")
+			err := r.markServiceExportAsInvalidNamespaceNotAllowed(ctx, &svcExport, &svc, reason)
+			if err != nil {
+				logger.Error(err, "Failed to mark service export as invalid (namespace not allowed)")
+			}
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Check if any port of the Service is selected for export; a Service with no ports at all, e.g. a headless
+	// Service, can still be exported.
+	svcExportPorts := extractServicePorts(&svc, &svcExport.Spec)
+	if len(svcExportPorts) == 0 && len(svc.Spec.Ports) != 0 {
+		r.Recorder.Eventf(&svcExport, corev1.EventTypeWarning, "NoPortsSelected", "None of the ports of service %s is selected for export", svc.Name)
+
+		// Unexport the Service if the ServiceExport has the cleanup finalizer added.
+		if r.hasCleanupFinalizer(&svcExport) {
+			logger.V(4).Info("No ports are selected; unexport the service")
+			if _, err = r.unexportService(ctx, &svcExport); err != nil {
+				logger.Error(err, "Failed to unexport the service")
+				return ctrl.Result{}, err
+			}
+		}
+		// Mark the ServiceExport as invalid.
+		logger.V(4).Info("Mark service export as invalid (no ports selected)")
+		err := r.markServiceExportAsInvalidNoPortsSelected(ctx, &svcExport, &svc)
+		if err != nil {
+			logger.Error(err, "Failed to mark service export as invalid (no ports selected)")
 		}
 		return ctrl.Result{}, err
 	}
 
 	// Add the cleanup finalizer to the ServiceExport; this must happen before the Service is actually exported.
-	if !controllerutil.ContainsFinalizer(&svcExport, svcExportCleanupFinalizer) {
-		klog.V(4).InfoS("Add cleanup finalizer to service export", "service", svcRef)
+	if !r.hasCleanupFinalizer(&svcExport) {
+		logger.V(4).Info("Add cleanup finalizer to service export")
 		if err := r.addServiceExportCleanupFinalizer(ctx, &svcExport); err != nil {
-			klog.ErrorS(err, "Failed to add cleanup finalizer to svc export", "service", svcRef)
+			logger.Error(err, "Failed to add cleanup finalizer to svc export")
 			return ctrl.Result{}, err
 		}
 	}
 
 	// Mark the ServiceExport as valid.
-	klog.V(4).InfoS("Mark service export as valid", "service", svcRef)
+	logger.V(4).Info("Mark service export as valid")
 	if err := r.markServiceExportAsValid(ctx, &svcExport, &svc); err != nil {
-		klog.ErrorS(err, "Failed to mark service export as valid", "service", svcRef)
+		logger.Error(err, "Failed to mark service export as valid")
 		return ctrl.Result{}, err
 	}
 
@@ -191,7 +328,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	// Note that the two values are not tamperproof.
 	exportedSince, err := r.collectAndVerifyLastSeenResourceVersionAndTimestamp(ctx, &svc, &svcExport, startTime)
 	if err != nil {
-		klog.Warning("Failed to annotate last seen generation and timestamp", "serviceExport", svcRef)
+		logger.Error(err, "Failed to annotate last seen generation and timestamp")
 	}
 
 	// Export the Service or update the exported Service.
@@ -203,11 +340,40 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			Name:      formatInternalServiceExportName(&svcExport),
 		},
 	}
-	svcExportPorts := extractServicePorts(&svc)
-	klog.V(2).InfoS("Export the service or update the exported service",
-		"service", svcExport,
+	dnsTTL, err := extractDNSTTL(&svcExport)
+	if err != nil {
+		// An invalid TTL hint does not block the export; the hub cluster falls back to the default TTL.
+		logger.V(2).Info("Ignoring the invalid DNS TTL hint", "error", err)
+		r.Recorder.Eventf(&svcExport, corev1.EventTypeWarning, "InvalidDNSTTL", "Ignoring the DNS TTL hint: %v", err)
+	}
+	canaryPercent, err := extractCanaryPercent(&svcExport)
+	if err != nil {
+		// An invalid canary percentage does not block the export; the cluster is simply not treated as a canary.
+		logger.V(2).Info("Ignoring the invalid canary percentage", "error", err)
+		r.Recorder.Eventf(&svcExport, corev1.EventTypeWarning, "InvalidCanaryPercent", "Ignoring the canary percentage: %v", err)
+	}
+	sessionAffinity, sessionAffinityTimeout, err := extractSessionAffinity(&svc)
+	if err != nil {
+		// An invalid timeout does not block the export; the hub cluster falls back to the Kubernetes default.
+		logger.V(2).Info("Ignoring the invalid session affinity timeout", "error", err)
+		r.Recorder.Eventf(&svcExport, corev1.EventTypeWarning, "InvalidSessionAffinityTimeout", "Ignoring the session affinity timeout: %v", err)
+	}
+	healthCheckAnnotations, err := extractHealthCheckAnnotations(&svc, r.HealthCheckAnnotationKeys)
+	if err != nil {
+		// Invalid health-check annotations do not block the export; they are simply not exported.
+		logger.V(2).Info("Ignoring the invalid health-check annotations", "error", err)
+		r.Recorder.Eventf(&svcExport, corev1.EventTypeWarning, "InvalidHealthCheckAnnotation", "Ignoring the health-check annotations: %v", err)
+	}
+	exportedLabels, exportedAnnotations, err := extractExportedMetadata(&svcExport)
+	if err != nil {
+		// Invalid exported labels or annotations do not block the export; they are simply not exported.
+		logger.V(2).Info("Ignoring the invalid exported labels or annotations", "error", err)
+		r.Recorder.Eventf(&svcExport, corev1.EventTypeWarning, "InvalidExportedMetadata", "Ignoring the exported labels or annotations: %v", err)
+	}
+	logger.V(2).Info("Export the service or update the exported service",
 		"internalServiceExport", klog.KObj(&internalSvcExport))
-	createOrUpdateOp, err := controllerutil.CreateOrUpdate(ctx, r.HubClient, &internalSvcExport, func() error {
+	createOrUpdateOp, err := r.createOrUpdateFunc()(ctx, r.HubClient, &internalSvcExport, func() error {
+		oldSpec := internalSvcExport.Spec.DeepCopy()
 		if internalSvcExport.CreationTimestamp.IsZero() {
 			// Set the ServiceReference only when the InternalServiceExport is created; most of the fields in
 			// an ExportedObjectReference should be immutable.
@@ -219,8 +385,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		// Service from the one that is being reconciled. This usually happens when a service is deleted and
 		// re-created immediately.
 		if internalSvcExport.Spec.ServiceReference.UID != svc.UID {
-			klog.V(4).InfoS("Failed to create/update internalServiceExport, UIDs mismatch",
-				"service", svcRef,
+			logger.V(4).Info("Failed to create/update internalServiceExport, UIDs mismatch",
 				"internalServiceExport", klog.KObj(&internalSvcExport),
 				"newUID", svc.UID,
 				"oldUID", internalSvcExport.Spec.ServiceReference.UID)
@@ -232,16 +397,41 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			)
 		}
 
+		// Label the InternalServiceExport with the namespace and name of the exported Service, so that the
+		// source of an export can be identified even if its name has been truncated.
+		if internalSvcExport.Labels == nil {
+			internalSvcExport.Labels = map[string]string{}
+		}
+		internalSvcExport.Labels[objectmeta.InternalServiceExportLabelServiceNamespace] = svc.Namespace
+		internalSvcExport.Labels[objectmeta.InternalServiceExportLabelServiceName] = svc.Name
+
 		internalSvcExport.Spec.Ports = svcExportPorts
+		internalSvcExport.Spec.Headless = isHeadlessService(&svc)
+		internalSvcExport.Spec.AllocateLoadBalancerNodePorts, internalSvcExport.Spec.LoadBalancerIP = extractLoadBalancerMetadata(&svc)
+		internalSvcExport.Spec.LoadBalancerIngress = extractLoadBalancerIngress(&svc)
+		internalSvcExport.Spec.IPFamilyPolicy, internalSvcExport.Spec.IPFamilies = extractIPFamilyMetadata(&svc)
+		internalSvcExport.Spec.DNSTTLSeconds = dnsTTL
+		internalSvcExport.Spec.CanaryPercent = canaryPercent
+		internalSvcExport.Spec.SessionAffinity = sessionAffinity
+		internalSvcExport.Spec.SessionAffinityTimeoutSeconds = sessionAffinityTimeout
+		internalSvcExport.Spec.HealthCheckAnnotations = healthCheckAnnotations
+		internalSvcExport.Spec.ExportedLabels = exportedLabels
+		internalSvcExport.Spec.ExportedAnnotations = exportedAnnotations
+		internalSvcExport.Spec.AllowedConsumers = svcExport.Spec.AllowedConsumers.DeepCopy()
 		internalSvcExport.Spec.ServiceReference.UpdateFromMetaObject(svc.ObjectMeta, metav1.NewTime(exportedSince))
 
 		if r.EnableTrafficManagerFeature {
-			klog.V(2).InfoS("Collecting Traffic Manager related information", "service", svcRef)
+			logger.V(2).Info("Collecting Traffic Manager related information")
 			if err := r.setAzureRelatedInformation(ctx, &svc, &internalSvcExport); err != nil {
-				klog.ErrorS(err, "Failed to populate the Azure information for the Traffic Manager feature", "service", svcRef)
+				logger.Error(err, "Failed to populate the Azure information for the Traffic Manager feature")
 				return err
 			}
 		}
+		// Only a new version of the export carries the correlation ID of this reconciliation; the exports left
+		// unchanged keep the trace which last changed them.
+		if !equality.Semantic.DeepEqual(oldSpec, &internalSvcExport.Spec) {
+			internalSvcExport.Annotations = tracing.SetCorrelationID(ctx, internalSvcExport.Annotations)
+		}
 		return nil
 	})
 	statusErr := &apierrors.StatusError{}
@@ -258,7 +448,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		// fast enough; the out-of-date cache will return that an object does not exist when read, even though the object is
 		// already present in the persistent store, and any subsequent create call would fail.
 		if _, err := r.unexportService(ctx, &svcExport); err != nil {
-			klog.ErrorS(err, "Failed to unexport the service", "service", svcRef)
+			logger.Error(err, "Failed to unexport the service")
 			return ctrl.Result{}, err
 		}
 		// Unexporting a Service removes the cleanup finalizer from the ServiceExport, which in normal cases
@@ -266,16 +456,87 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		// the new reconciliation attempt explicitly.
 		return ctrl.Result{Requeue: true}, nil
 	case err != nil:
-		klog.ErrorS(err, "Failed to create/update InternalServiceExport",
+		logger.Error(err, "Failed to create/update InternalServiceExport",
 			"internalServiceExport", klog.KObj(&internalSvcExport),
-			"service", svcRef,
 			"op", createOrUpdateOp)
 		return ctrl.Result{}, err
 	}
-	return ctrl.Result{}, nil
+
+	// Remove EndpointSliceExports derived from the Service whose source EndpointSlices no longer exist; normally
+	// the EndpointSlice controller withdraws an EndpointSlice from the hub cluster when it is deleted, but the
+	// deletion event might be missed.
+	if err := r.removeStaleEndpointSliceExports(ctx, &svc); err != nil {
+		logger.Error(err, "Failed to remove stale endpoint slice exports")
+		return ctrl.Result{}, err
+	}
+
+	// Periodically re-scan exported Services; the controller is not notified when the InternalServiceExport is
+	// deleted from the hub cluster out-of-band, and the CreateOrUpdate call above is what restores it. Services
+	// that are being unexported never reach this point, so the resync does not interfere with their cleanup.
+	return ctrl.Result{RequeueAfter: svcExportResyncInterval}, nil
+}
+
+// removeStaleEndpointSliceExports deletes the EndpointSliceExports derived from a Service that no longer
+// match an existing EndpointSlice in the member cluster, or the Service itself if they are aggregated.
+func (r *Reconciler) removeStaleEndpointSliceExports(ctx context.Context, svc *corev1.Service) error {
+	logger := klog.FromContext(ctx)
+	endpointSliceExportList := &fleetnetv1alpha1.EndpointSliceExportList{}
+	listOpts := []client.ListOption{
+		client.InNamespace(r.HubNamespace),
+		client.MatchingLabels{
+			objectmeta.EndpointSliceExportLabelOwnerServiceNamespace: svc.Namespace,
+			objectmeta.EndpointSliceExportLabelOwnerServiceName:      svc.Name,
+		},
+	}
+	if err := r.HubClient.List(ctx, endpointSliceExportList, listOpts...); err != nil {
+		return err
+	}
+
+	for idx := range endpointSliceExportList.Items {
+		endpointSliceExport := &endpointSliceExportList.Items[idx]
+		if endpointSliceExport.IsAggregated() {
+			// An aggregated EndpointSliceExport references the Service itself, and is maintained by the EndpointSlice
+			// controller as long as the exports are aggregated.
+			if r.AggregateEndpointSliceExports && endpointSliceExport.Spec.EndpointSliceReference.UID == svc.UID {
+				continue
+			}
+			logger.V(2).Info("Endpoint slice exports are no longer aggregated; delete the aggregated endpoint slice export",
+				"endpointSliceExport", klog.KObj(endpointSliceExport))
+			if err := r.HubClient.Delete(ctx, endpointSliceExport); err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+			continue
+		}
+		endpointSliceKey := types.NamespacedName{
+			Namespace: endpointSliceExport.Spec.EndpointSliceReference.Namespace,
+			Name:      endpointSliceExport.Spec.EndpointSliceReference.Name,
+		}
+		endpointSlice := &discoveryv1.EndpointSlice{}
+		err := r.MemberClient.Get(ctx, endpointSliceKey, endpointSlice)
+		switch {
+		case apierrors.IsNotFound(err):
+			// The source EndpointSlice has been deleted; the EndpointSliceExport should be deleted as well.
+		case err != nil:
+			return err
+		case endpointSlice.Annotations[objectmeta.ExportedObjectAnnotationUniqueName] != endpointSliceExport.Name:
+			// The EndpointSlice has been re-created with the same name (or its unique name has been re-assigned),
+			// and is no longer exported as this EndpointSliceExport.
+		default:
+			continue
+		}
+
+		logger.V(2).Info("Source endpoint slice is gone; delete the endpoint slice export",
+			"endpointSlice", klog.KRef(endpointSliceKey.Namespace, endpointSliceKey.Name),
+			"endpointSliceExport", klog.KObj(endpointSliceExport))
+		if err := r.HubClient.Delete(ctx, endpointSliceExport); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
 }
 
 func (r *Reconciler) setAzureRelatedInformation(ctx context.Context, service *corev1.Service, export *fleetnetv1alpha1.InternalServiceExport) error {
+	logger := klog.FromContext(ctx)
 	export.Spec.Type = service.Spec.Type
 	if service.Spec.Type != corev1.ServiceTypeLoadBalancer {
 		return nil
@@ -288,16 +549,15 @@ func (r *Reconciler) setAzureRelatedInformation(ctx context.Context, service *co
 		return nil
 	}
 
-	serviceKObj := klog.KObj(service)
 	if len(service.Status.LoadBalancer.Ingress) == 0 {
 		// Assuming once the service status is updated, the controller will be triggered again.
-		klog.V(2).InfoS("The load balancer IP is not assigned yet", "service", serviceKObj)
+		logger.V(2).Info("The load balancer IP is not assigned yet")
 		return nil
 	}
 
 	if service.Status.LoadBalancer.Ingress[0].IP == "" {
 		err := errors.New("the service ingress is not nil but with empty IP")
-		klog.ErrorS(controller.NewUnexpectedBehaviorError(err), "Failed to get the load balancer IP from service", "service", serviceKObj, "status", service.Status)
+		logger.Error(controller.NewUnexpectedBehaviorError(err), "Failed to get the load balancer IP from service", "status", service.Status)
 		return nil
 	}
 
@@ -306,7 +566,7 @@ func (r *Reconciler) setAzureRelatedInformation(ctx context.Context, service *co
 		return err
 	}
 	if pip == nil {
-		klog.V(2).InfoS("The public IP is in the progressing", "service", serviceKObj, "ip", service.Status.LoadBalancer.Ingress[0].IP)
+		logger.V(2).Info("The public IP is in the progressing", "ip", service.Status.LoadBalancer.Ingress[0].IP)
 		// Assuming once the service status is updated, the controller will be triggered again in instead of retrying here
 		// to avoid sending Azure requests.
 		return nil
@@ -320,7 +580,7 @@ func (r *Reconciler) setAzureRelatedInformation(ctx context.Context, service *co
 	// No matter if the customer bring your own IP or not, the cloud provider will reconcile the DNS label based on the
 	// DNS annotation.
 	dnsName, found := service.Annotations[objectmeta.ServiceAnnotationAzureDNSLabelName]
-	klog.V(2).InfoS("Finding whether the DNS is assigned", "service", serviceKObj, "dnsName", dnsName, "isSetOnService", found, "isConfiguredOnPIP", export.Spec.IsDNSLabelConfigured)
+	logger.V(2).Info("Finding whether the DNS is assigned", "dnsName", dnsName, "isSetOnService", found, "isConfiguredOnPIP", export.Spec.IsDNSLabelConfigured)
 	// If the annotation is not set, the cloud provider won't reconcile the DNS label and return the current status.
 	if !found {
 		// cloud provider won't delete DNS label on pip if the annotation is not set.
@@ -332,7 +592,7 @@ func (r *Reconciler) setAzureRelatedInformation(ctx context.Context, service *co
 	}
 	if !export.Spec.IsDNSLabelConfigured {
 		err = fmt.Errorf("in the process of adding DNS to the public ip address %s", *pip.ID)
-		klog.ErrorS(err, "Requeue the request to see if the DNS is ready or not", "service", serviceKObj)
+		logger.Error(err, "Requeue the request to see if the DNS is ready or not")
 		return err
 	}
 	return nil
@@ -342,15 +602,15 @@ func (r *Reconciler) setAzureRelatedInformation(ctx context.Context, service *co
 // Note: we don't support "service.beta.kubernetes.io/azure-pip-prefix-id" annotation, and public ip cannot be found in
 // this case.
 func (r *Reconciler) lookupPublicIPResourceIDByLoadBalancerIP(ctx context.Context, service *corev1.Service) (*armnetwork.PublicIPAddress, error) {
+	logger := klog.FromContext(ctx)
 	// The customer can specify the resource group for the public IP address in the service annotation.
 	rg := strings.TrimSpace(service.Annotations[objectmeta.ServiceAnnotationLoadBalancerResourceGroup])
 	if len(rg) == 0 {
 		rg = r.ResourceGroupName
 	}
-	serviceKObj := klog.KObj(service)
 	pips, err := r.AzurePublicIPAddressClient.List(ctx, rg)
 	if err != nil {
-		klog.ErrorS(err, "Failed to list Azure public IP addresses", "service", serviceKObj, "resourceGroup", rg)
+		logger.Error(err, "Failed to list Azure public IP addresses", "resourceGroup", rg)
 		return nil, err
 	}
 	for _, pip := range pips {
@@ -359,18 +619,70 @@ func (r *Reconciler) lookupPublicIPResourceIDByLoadBalancerIP(ctx context.Contex
 			return pip, nil
 		}
 	}
-	klog.V(2).InfoS("The public IP address resource ID cannot be found in the public IP lists", "service", serviceKObj, "ip", service.Status.LoadBalancer.Ingress[0].IP, "resourceGroup", rg)
+	logger.V(2).Info("The public IP address resource ID cannot be found in the public IP lists", "ip", service.Status.LoadBalancer.Ingress[0].IP, "resourceGroup", rg)
 	return nil, nil
 }
 
 // SetupWithManager builds a controller with Reconciler and sets it up with a controller manager.
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	var svcExportOpts []builder.ForOption
+	if r.IgnoreSystemManagedUpdates {
+		svcExportOpts = append(svcExportOpts, builder.WithPredicates(ignoreSystemManagedUpdatesPredicate()))
+	}
+	b := ctrl.NewControllerManagedBy(mgr).
 		// The ServiceExport controller watches over ServiceExport objects.
-		For(&fleetnetv1alpha1.ServiceExport{}).
+		For(&fleetnetv1alpha1.ServiceExport{}, svcExportOpts...).
 		// The ServiceExport controller watches over Service objects.
-		Watches(&corev1.Service{}, &handler.EnqueueRequestForObject{}).
-		Complete(r)
+		Watches(&corev1.Service{}, &handler.EnqueueRequestForObject{})
+	if r.EnforceNamespaceSameness {
+		// The ServiceExports are re-evaluated as the policies, or the labels of their namespaces, change.
+		b = b.Watches(&fleetnetv1alpha1.NamespaceSamenessPolicy{}, handler.EnqueueRequestsFromMapFunc(r.serviceExportsInNamespace)).
+			Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.serviceExportsInNamespace),
+				builder.WithPredicates(predicate.LabelChangedPredicate{}))
+	}
+	if r.ResyncEvents != nil {
+		b = b.WatchesRawSource(source.Channel(r.ResyncEvents, &handler.EnqueueRequestForObject{}))
+	}
+	opts := r.ControllerOptions
+	opts.NewQueue = r.NewQueue
+	return b.WithOptions(opts).Complete(metrics.InstrumentReconciler("serviceexport", r))
+}
+
+// serviceExportsInNamespace enqueues the ServiceExports in the namespace, or all the ServiceExports for a
+// NamespaceSamenessPolicy.
+func (r *Reconciler) serviceExportsInNamespace(ctx context.Context, obj client.Object) []reconcile.Request {
+	logger := klog.FromContext(ctx)
+	var listOpts []client.ListOption
+	if _, ok := obj.(*corev1.Namespace); ok {
+		listOpts = append(listOpts, client.InNamespace(obj.GetName()))
+	}
+	svcExportList := &fleetnetv1alpha1.ServiceExportList{}
+	if err := r.MemberClient.List(ctx, svcExportList, listOpts...); err != nil {
+		logger.Error(err, "Failed to list service exports")
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(svcExportList.Items))
+	for i := range svcExportList.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: svcExportList.Items[i].Namespace, Name: svcExportList.Items[i].Name},
+		})
+	}
+	return requests
+}
+
+// ignoreSystemManagedUpdatesPredicate returns a predicate that drops the ServiceExport update events which
+// change only the system managed fields; all the other events pass through.
+func ignoreSystemManagedUpdatesPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldSvcExport, oldOK := e.ObjectOld.(*fleetnetv1alpha1.ServiceExport)
+			newSvcExport, newOK := e.ObjectNew.(*fleetnetv1alpha1.ServiceExport)
+			if !oldOK || !newOK {
+				return true
+			}
+			return !isSystemManagedUpdate(oldSvcExport, newSvcExport)
+		},
+	}
 }
 
 // unexportService unexports a Service, specifically, it deletes the corresponding InternalServiceExport from the
@@ -379,6 +691,7 @@ func (r *Reconciler) unexportService(ctx context.Context, svcExport *fleetnetv1a
 	// Get the unique name assigned when the Service is exported. it is guaranteed that Services are
 	// always exported using the name format `ORIGINAL_NAMESPACE-ORIGINAL_NAME`; for example, a Service
 	// from namespace `default`` with the name `store`` will be exported with the name `default-store`.
+	// Overly long names are truncated and suffixed with a hash, which is stable for the same Service.
 	internalSvcExportName := formatInternalServiceExportName(svcExport)
 	internalSvcExport := &fleetnetv1alpha1.InternalServiceExport{
 		ObjectMeta: metav1.ObjectMeta{
@@ -407,7 +720,10 @@ func (r *Reconciler) unexportService(ctx context.Context, svcExport *fleetnetv1a
 
 // removeServiceExportCleanupFinalizer removes the cleanup finalizer from a ServiceExport.
 func (r *Reconciler) removeServiceExportCleanupFinalizer(ctx context.Context, svcExport *fleetnetv1alpha1.ServiceExport) error {
-	controllerutil.RemoveFinalizer(svcExport, svcExportCleanupFinalizer)
+	// Remove the default finalizer as well, which the ServiceExport may have been given before the finalizer
+	// name was configured.
+	controllerutil.RemoveFinalizer(svcExport, r.cleanupFinalizer())
+	controllerutil.RemoveFinalizer(svcExport, objectmeta.ServiceExportCleanupFinalizer)
 	return r.MemberClient.Update(ctx, svcExport)
 }
 
@@ -449,12 +765,68 @@ func (r *Reconciler) markServiceExportAsInvalidSvcIneligible(ctx context.Context
 	return r.MemberClient.Status().Update(ctx, svcExport)
 }
 
+// markServiceExportAsInvalidNoPortsSelected marks a ServiceExport as invalid as none of the ports of the Service
+// is selected for export.
+func (r *Reconciler) markServiceExportAsInvalidNoPortsSelected(ctx context.Context, svcExport *fleetnetv1alpha1.ServiceExport, svc *corev1.Service) error {
+	validCond := meta.FindStatusCondition(svcExport.Status.Conditions, string(fleetnetv1alpha1.ServiceExportValid))
+	expectedValidCond := &metav1.Condition{
+		Type:               string(fleetnetv1alpha1.ServiceExportValid),
+		Status:             metav1.ConditionFalse,
+		Reason:             svcExportInvalidNoPortsSelectedReason,
+		ObservedGeneration: svc.Generation,
+		Message:            fmt.Sprintf("none of the ports of service %s/%s is selected for export", svcExport.Namespace, svcExport.Name),
+	}
+	if condition.EqualCondition(validCond, expectedValidCond) {
+		// A stable state has been reached; no further action is needed.
+		return nil
+	}
+
+	meta.SetStatusCondition(&svcExport.Status.Conditions, *expectedValidCond)
+	return r.MemberClient.Status().Update(ctx, svcExport)
+}
+
+// markServiceExportAsInvalidNamespaceNotAllowed marks a ServiceExport as invalid as the namespace of its Service is
+// excluded from multi-cluster networking.
+func (r *Reconciler) markServiceExportAsInvalidNamespaceNotAllowed(ctx context.Context, svcExport *fleetnetv1alpha1.ServiceExport,
+	svc *corev1.Service, reason string) error {
+	validCond := meta.FindStatusCondition(svcExport.Status.Conditions, string(fleetnetv1alpha1.ServiceExportValid))
+	expectedValidCond := &metav1.Condition{
+		Type:               string(fleetnetv1alpha1.ServiceExportValid),
+		Status:             metav1.ConditionFalse,
+		Reason:             svcExportInvalidNamespaceNotAllowed,
+		ObservedGeneration: svc.Generation,
+		Message:            fmt.Sprintf("service %s/%s cannot be exported: %s", svcExport.Namespace, svcExport.Name, reason),
+	}
+	if condition.EqualCondition(validCond, expectedValidCond) {
+		// A stable state has been reached; no further action is needed.
+		return nil
+	}
+
+	meta.SetStatusCondition(&svcExport.Status.Conditions, *expectedValidCond)
+	return r.MemberClient.Status().Update(ctx, svcExport)
+}
+
 // addServiceExportCleanupFinalizer adds the cleanup finalizer to a ServiceExport.
 func (r *Reconciler) addServiceExportCleanupFinalizer(ctx context.Context, svcExport *fleetnetv1alpha1.ServiceExport) error {
-	controllerutil.AddFinalizer(svcExport, svcExportCleanupFinalizer)
+	controllerutil.AddFinalizer(svcExport, r.cleanupFinalizer())
 	return r.MemberClient.Update(ctx, svcExport)
 }
 
+// cleanupFinalizer returns the name of the cleanup finalizer of ServiceExports.
+func (r *Reconciler) cleanupFinalizer() string {
+	if r.CleanupFinalizer != "" {
+		return r.CleanupFinalizer
+	}
+	return objectmeta.ServiceExportCleanupFinalizer
+}
+
+// hasCleanupFinalizer returns true if a ServiceExport has the cleanup finalizer, or the default one which the
+// ServiceExport may have been given before the finalizer name was configured.
+func (r *Reconciler) hasCleanupFinalizer(svcExport *fleetnetv1alpha1.ServiceExport) bool {
+	return controllerutil.ContainsFinalizer(svcExport, r.cleanupFinalizer()) ||
+		controllerutil.ContainsFinalizer(svcExport, objectmeta.ServiceExportCleanupFinalizer)
+}
+
 // markServiceExportAsValid marks a ServiceExport as valid; if no conflict condition has been added, the
 // ServiceExport will be marked as pending conflict resolution as well.
 func (r *Reconciler) markServiceExportAsValid(ctx context.Context, svcExport *fleetnetv1alpha1.ServiceExport, svc *corev1.Service) error {
@@ -519,3 +891,17 @@ func (r *Reconciler) annotateLastSeenResourceVersionAndTimestamp(ctx context.Con
 	svcExport.Annotations[metrics.MetricsAnnotationLastSeenTimestamp] = startTime.Format(metrics.MetricsLastSeenTimestampFormat)
 	return r.MemberClient.Update(ctx, svcExport)
 }
+
+// createOrUpdateFunc returns the function which writes the InternalServiceExports to the hub cluster.
+func (r *Reconciler) createOrUpdateFunc() apply.CreateOrUpdateFunc {
+	if !r.ServerSideApply {
+		return controllerutil.CreateOrUpdate
+	}
+	return apply.CreateOrApply(apply.Owned{
+		Labels: []string{
+			objectmeta.InternalServiceExportLabelServiceNamespace,
+			objectmeta.InternalServiceExportLabelServiceName,
+		},
+		Annotations: []string{objectmeta.ExportedObjectAnnotationCorrelationID},
+	})
+}
@@ -24,6 +24,7 @@ import (
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 	"go.goms.io/fleet-networking/pkg/common/metrics"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
 )
 
 const (
@@ -225,8 +226,8 @@ var (
 			return fmt.Errorf("serviceExport Get(%+v), got %w, want no error", svcOrSvcExportKey, err)
 		}
 
-		if !cmp.Equal(svcExport.Finalizers, []string{svcExportCleanupFinalizer}) {
-			return fmt.Errorf("serviceExport finalizers, got %v, want %v", svcExport.Finalizers, []string{svcExportCleanupFinalizer})
+		if !cmp.Equal(svcExport.Finalizers, []string{objectmeta.ServiceExportCleanupFinalizer}) {
+			return fmt.Errorf("serviceExport finalizers, got %v, want %v", svcExport.Finalizers, []string{objectmeta.ServiceExportCleanupFinalizer})
 		}
 
 		expectedValidCond := serviceExportValidCondition(memberUserNS, svcName)
@@ -311,6 +312,13 @@ func serviceIsExportedToHubActual(serviceType corev1.ServiceType, isPublicAzureL
 		if diff := cmp.Diff(internalSvcExport.Spec, expectedInternalSvcExportSpec, ignoredRefFields); diff != "" {
 			return fmt.Errorf("internalServiceExport spec (-got, +want): %s", diff)
 		}
+		expectedLabels := map[string]string{
+			objectmeta.InternalServiceExportLabelServiceNamespace: svc.Namespace,
+			objectmeta.InternalServiceExportLabelServiceName:      svc.Name,
+		}
+		if diff := cmp.Diff(internalSvcExport.Labels, expectedLabels); diff != "" {
+			return fmt.Errorf("internalServiceExport labels (-got, +want): %s", diff)
+		}
 		return nil
 	}
 }
@@ -546,7 +554,7 @@ var _ = Describe("serviceexport controller", func() {
 		})
 	})
 
-	Context("export ineligible service (headless)", func() {
+	Context("export headless service", func() {
 		var svcExport = &fleetnetv1alpha1.ServiceExport{}
 		var svc = &corev1.Service{}
 
@@ -567,9 +575,17 @@ var _ = Describe("serviceexport controller", func() {
 			Eventually(serviceIsAbsentActual, eventuallyTimeout, eventuallyInterval).Should(Succeed())
 		})
 
-		It("should mark the service export as invalid (ineligible) + should not export headless service", func() {
-			Eventually(serviceIsInvalidForExportIneligibleActual, eventuallyTimeout, eventuallyInterval).Should(Succeed())
-			Consistently(serviceIsNotExportedActual, consistentlyDuration, consistentlyInterval).Should(Succeed())
+		It("should export headless service", func() {
+			Eventually(func() error {
+				internalSvcExport := &fleetnetv1alpha1.InternalServiceExport{}
+				if err := hubClient.Get(ctx, internalSvcExportKey, internalSvcExport); err != nil {
+					return fmt.Errorf("internalServiceExport Get(%+v), got %w, want no error", internalSvcExportKey, err)
+				}
+				if !internalSvcExport.Spec.Headless {
+					return fmt.Errorf("internalServiceExport headless, got false, want true")
+				}
+				return nil
+			}, eventuallyTimeout, eventuallyInterval).Should(Succeed())
 		})
 	})
 
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -18,7 +19,9 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -26,11 +29,14 @@ import (
 	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 	"go.goms.io/fleet-networking/pkg/common/metrics"
 	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/pause"
 )
 
 const (
@@ -152,7 +158,7 @@ func TestIsServiceEligibleForExport(t *testing.T) {
 			want: false,
 		},
 		{
-			name: "should not export headless Service",
+			name: "should export headless Service",
 			svc: &corev1.Service{
 				ObjectMeta: metav1.ObjectMeta{
 					Namespace: memberUserNS,
@@ -168,7 +174,7 @@ func TestIsServiceEligibleForExport(t *testing.T) {
 					},
 				},
 			},
-			want: false,
+			want: true,
 		},
 	}
 
@@ -181,6 +187,37 @@ func TestIsServiceEligibleForExport(t *testing.T) {
 	}
 }
 
+// TestIsHeadlessService tests the isHeadlessService function.
+func TestIsHeadlessService(t *testing.T) {
+	testCases := []struct {
+		name      string
+		clusterIP string
+		want      bool
+	}{
+		{
+			name:      "headless Service",
+			clusterIP: corev1.ClusterIPNone,
+			want:      true,
+		},
+		{
+			name:      "Service with a cluster IP",
+			clusterIP: "10.0.0.1",
+		},
+		{
+			name: "Service with no cluster IP allocated yet",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &corev1.Service{Spec: corev1.ServiceSpec{ClusterIP: tc.clusterIP}}
+			if got := isHeadlessService(svc); got != tc.want {
+				t.Errorf("isHeadlessService() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
 // TestFormatInternalServiceExportName tests the formatInternalServiceExportName function.
 func TestFormatInternalServiceExportName(t *testing.T) {
 	testCases := []struct {
@@ -198,6 +235,16 @@ func TestFormatInternalServiceExportName(t *testing.T) {
 			},
 			want: "work-app",
 		},
+		{
+			name: "should return truncated name with hash suffix",
+			svcExport: &fleetnetv1alpha1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: strings.Repeat("n", 63),
+					Name:      strings.Repeat("s", 253),
+				},
+			},
+			want: strings.Repeat("n", 63) + "-" + strings.Repeat("s", 178) + "-" + "636fe74790",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -209,11 +256,65 @@ func TestFormatInternalServiceExportName(t *testing.T) {
 
 // TestExtractServicePorts tests the extractServicePorts function.
 func TestExtractServicePorts(t *testing.T) {
+	multiPortSvc := &corev1.Service{
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "web",
+					Protocol:   corev1.ProtocolTCP,
+					Port:       80,
+					TargetPort: intstr.FromInt(8080),
+				},
+				{
+					Name:       "admin",
+					Protocol:   corev1.ProtocolTCP,
+					Port:       9000,
+					TargetPort: intstr.FromInt(9000),
+				},
+				{
+					Name:       "debug",
+					Protocol:   corev1.ProtocolTCP,
+					Port:       6060,
+					TargetPort: intstr.FromInt(6060),
+				},
+			},
+		},
+	}
 	testCases := []struct {
-		name string
-		svc  *corev1.Service
-		want []fleetnetv1alpha1.ServicePort
+		name          string
+		svc           *corev1.Service
+		svcExportSpec fleetnetv1alpha1.ServiceExportSpec
+		want          []fleetnetv1alpha1.ServicePort
 	}{
+		{
+			name: "should extract ports selected by name or number",
+			svc:  multiPortSvc,
+			svcExportSpec: fleetnetv1alpha1.ServiceExportSpec{
+				Ports: []intstr.IntOrString{intstr.FromString("web"), intstr.FromInt32(6060)},
+			},
+			want: []fleetnetv1alpha1.ServicePort{
+				{
+					Name:       "web",
+					Protocol:   corev1.ProtocolTCP,
+					Port:       80,
+					TargetPort: intstr.FromInt(8080),
+				},
+				{
+					Name:       "debug",
+					Protocol:   corev1.ProtocolTCP,
+					Port:       6060,
+					TargetPort: intstr.FromInt(6060),
+				},
+			},
+		},
+		{
+			name: "should extract no ports if none is selected",
+			svc:  multiPortSvc,
+			svcExportSpec: fleetnetv1alpha1.ServiceExportSpec{
+				Ports: []intstr.IntOrString{intstr.FromString("metrics"), intstr.FromInt32(8080)},
+			},
+			want: []fleetnetv1alpha1.ServicePort{},
+		},
 		{
 			name: "should extract ports",
 			svc: &corev1.Service{
@@ -241,7 +342,7 @@ func TestExtractServicePorts(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			svcExportPorts := extractServicePorts(tc.svc)
+			svcExportPorts := extractServicePorts(tc.svc, &tc.svcExportSpec)
 			if !cmp.Equal(svcExportPorts, tc.want) {
 				t.Fatalf("extractServicePorts(%+v) = %v, want %v", tc.svc, svcExportPorts, tc.want)
 			}
@@ -249,6 +350,216 @@ func TestExtractServicePorts(t *testing.T) {
 	}
 }
 
+// TestExtractLoadBalancerMetadata tests the extractLoadBalancerMetadata function.
+func TestExtractLoadBalancerMetadata(t *testing.T) {
+	testCases := []struct {
+		name                              string
+		svc                               *corev1.Service
+		wantAllocateLoadBalancerNodePorts *bool
+		wantLoadBalancerIP                string
+	}{
+		{
+			name: "should extract load balancer metadata from load balancer svc",
+			svc: &corev1.Service{
+				Spec: corev1.ServiceSpec{
+					Type:                          corev1.ServiceTypeLoadBalancer,
+					AllocateLoadBalancerNodePorts: ptr.To(false),
+					LoadBalancerIP:                "10.0.0.1",
+				},
+			},
+			wantAllocateLoadBalancerNodePorts: ptr.To(false),
+			wantLoadBalancerIP:                "10.0.0.1",
+		},
+		{
+			name: "should leave unset load balancer metadata as is",
+			svc: &corev1.Service{
+				Spec: corev1.ServiceSpec{
+					Type: corev1.ServiceTypeLoadBalancer,
+				},
+			},
+		},
+		{
+			name: "should omit load balancer metadata from cluster IP svc",
+			svc: &corev1.Service{
+				Spec: corev1.ServiceSpec{
+					Type:                          corev1.ServiceTypeClusterIP,
+					AllocateLoadBalancerNodePorts: ptr.To(true),
+					LoadBalancerIP:                "10.0.0.1",
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotAllocateLoadBalancerNodePorts, gotLoadBalancerIP := extractLoadBalancerMetadata(tc.svc)
+			if !cmp.Equal(gotAllocateLoadBalancerNodePorts, tc.wantAllocateLoadBalancerNodePorts) || gotLoadBalancerIP != tc.wantLoadBalancerIP {
+				t.Fatalf("extractLoadBalancerMetadata(%+v) = %v, %q, want %v, %q", tc.svc,
+					gotAllocateLoadBalancerNodePorts, gotLoadBalancerIP, tc.wantAllocateLoadBalancerNodePorts, tc.wantLoadBalancerIP)
+			}
+		})
+	}
+}
+
+// TestExtractLoadBalancerIngress tests the extractLoadBalancerIngress function.
+func TestExtractLoadBalancerIngress(t *testing.T) {
+	testCases := []struct {
+		name string
+		svc  *corev1.Service
+		want []string
+	}{
+		{
+			name: "should extract the IPs and hostnames of the load balancer ingress",
+			svc: &corev1.Service{
+				Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+				Status: corev1.ServiceStatus{
+					LoadBalancer: corev1.LoadBalancerStatus{
+						Ingress: []corev1.LoadBalancerIngress{
+							{IP: "20.0.0.1", Hostname: "app.example.com"},
+							{Hostname: "app.westus.cloudapp.azure.com"},
+							{},
+						},
+					},
+				},
+			},
+			want: []string{"20.0.0.1", "app.westus.cloudapp.azure.com"},
+		},
+		{
+			name: "should return nothing if the load balancer is not provisioned",
+			svc: &corev1.Service{
+				Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+			},
+		},
+		{
+			name: "should omit the load balancer ingress of cluster IP svc",
+			svc: &corev1.Service{
+				Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP},
+				Status: corev1.ServiceStatus{
+					LoadBalancer: corev1.LoadBalancerStatus{
+						Ingress: []corev1.LoadBalancerIngress{{IP: "20.0.0.1"}},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, extractLoadBalancerIngress(tc.svc)); diff != "" {
+				t.Errorf("extractLoadBalancerIngress() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestExtractIPFamilyMetadata tests the extractIPFamilyMetadata function.
+func TestExtractIPFamilyMetadata(t *testing.T) {
+	testCases := []struct {
+		name               string
+		svc                *corev1.Service
+		wantIPFamilyPolicy *corev1.IPFamilyPolicy
+		wantIPFamilies     []corev1.IPFamily
+	}{
+		{
+			name: "should extract ip family metadata from dual-stack svc",
+			svc: &corev1.Service{
+				Spec: corev1.ServiceSpec{
+					IPFamilyPolicy: ptr.To(corev1.IPFamilyPolicyPreferDualStack),
+					IPFamilies:     []corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv6Protocol},
+				},
+			},
+			wantIPFamilyPolicy: ptr.To(corev1.IPFamilyPolicyPreferDualStack),
+			wantIPFamilies:     []corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv6Protocol},
+		},
+		{
+			name: "should leave unset ip family metadata as is",
+			svc:  &corev1.Service{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotIPFamilyPolicy, gotIPFamilies := extractIPFamilyMetadata(tc.svc)
+			if !cmp.Equal(gotIPFamilyPolicy, tc.wantIPFamilyPolicy) || !cmp.Equal(gotIPFamilies, tc.wantIPFamilies) {
+				t.Fatalf("extractIPFamilyMetadata(%+v) = %v, %v, want %v, %v", tc.svc,
+					gotIPFamilyPolicy, gotIPFamilies, tc.wantIPFamilyPolicy, tc.wantIPFamilies)
+			}
+		})
+	}
+}
+
+// TestExtractSessionAffinity tests the extractSessionAffinity function.
+func TestExtractSessionAffinity(t *testing.T) {
+	clientIPSvc := func(timeout *int32) *corev1.Service {
+		return &corev1.Service{
+			Spec: corev1.ServiceSpec{
+				SessionAffinity: corev1.ServiceAffinityClientIP,
+				SessionAffinityConfig: &corev1.SessionAffinityConfig{
+					ClientIP: &corev1.ClientIPConfig{TimeoutSeconds: timeout},
+				},
+			},
+		}
+	}
+	testCases := []struct {
+		name         string
+		svc          *corev1.Service
+		wantAffinity corev1.ServiceAffinity
+		wantTimeout  *int32
+		wantErr      bool
+	}{
+		{
+			name: "should extract no timeout from svc without session affinity",
+			svc: &corev1.Service{
+				Spec: corev1.ServiceSpec{
+					SessionAffinity: corev1.ServiceAffinityNone,
+				},
+			},
+			wantAffinity: corev1.ServiceAffinityNone,
+		},
+		{
+			name:         "should extract timeout from svc with client ip session affinity",
+			svc:          clientIPSvc(ptr.To(int32(300))),
+			wantAffinity: corev1.ServiceAffinityClientIP,
+			wantTimeout:  ptr.To(int32(300)),
+		},
+		{
+			name:         "should accept the maximum timeout",
+			svc:          clientIPSvc(ptr.To(int32(86400))),
+			wantAffinity: corev1.ServiceAffinityClientIP,
+			wantTimeout:  ptr.To(int32(86400)),
+		},
+		{
+			name:         "should leave unset timeout as is",
+			svc:          clientIPSvc(nil),
+			wantAffinity: corev1.ServiceAffinityClientIP,
+		},
+		{
+			name:         "should reject timeout above the maximum",
+			svc:          clientIPSvc(ptr.To(int32(86401))),
+			wantAffinity: corev1.ServiceAffinityClientIP,
+			wantErr:      true,
+		},
+		{
+			name:         "should reject non-positive timeout",
+			svc:          clientIPSvc(ptr.To(int32(0))),
+			wantAffinity: corev1.ServiceAffinityClientIP,
+			wantErr:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotAffinity, gotTimeout, err := extractSessionAffinity(tc.svc)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("extractSessionAffinity() error = %v, want error %t", err, tc.wantErr)
+			}
+			if gotAffinity != tc.wantAffinity || !cmp.Equal(gotTimeout, tc.wantTimeout) {
+				t.Fatalf("extractSessionAffinity() = %q, %v, want %q, %v", gotAffinity, gotTimeout, tc.wantAffinity, tc.wantTimeout)
+			}
+		})
+	}
+}
+
 // TestMarkServiceExportAsInvalidNotFound tests the *Reconciler.markServiceExportAsInvalidNotFound method.
 func TestMarkServiceExportAsInvalidNotFound(t *testing.T) {
 	testCases := []struct {
@@ -406,6 +717,61 @@ func TestMarkServiceExportAsInvalidIneligible(t *testing.T) {
 	}
 }
 
+// TestMarkServiceExportAsInvalidNamespaceNotAllowed tests the *Reconciler.markServiceExportAsInvalidNamespaceNotAllowed method.
+func TestMarkServiceExportAsInvalidNamespaceNotAllowed(t *testing.T) {
+	reason := "namespace work is denied by namespace sameness policy sameness"
+	wantCond := metav1.Condition{
+		Type:    string(fleetnetv1alpha1.ServiceExportValid),
+		Status:  metav1.ConditionFalse,
+		Reason:  svcExportInvalidNamespaceNotAllowed,
+		Message: fmt.Sprintf("service %s/%s cannot be exported: %s", memberUserNS, svcName, reason),
+	}
+	svcExport := &fleetnetv1alpha1.ServiceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: memberUserNS,
+			Name:      svcName,
+		},
+		Status: fleetnetv1alpha1.ServiceExportStatus{
+			Conditions: []metav1.Condition{
+				serviceExportValidCondition(memberUserNS, svcName),
+			},
+		},
+	}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: memberUserNS,
+			Name:      svcName,
+		},
+	}
+
+	ctx := context.Background()
+	fakeMemberClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(svcExport).
+		WithStatusSubresource(svcExport).
+		Build()
+	reconciler := Reconciler{
+		MemberClient: fakeMemberClient,
+		HubClient:    fake.NewClientBuilder().Build(),
+		HubNamespace: hubNSForMember,
+		Recorder:     record.NewFakeRecorder(10),
+	}
+
+	if err := reconciler.markServiceExportAsInvalidNamespaceNotAllowed(ctx, svcExport, svc, reason); err != nil {
+		t.Fatalf("failed to mark svc export: %v", err)
+	}
+
+	var updatedSvcExport = &fleetnetv1alpha1.ServiceExport{}
+	svcExportKey := types.NamespacedName{Namespace: svcExport.Namespace, Name: svcExport.Name}
+	if err := fakeMemberClient.Get(ctx, svcExportKey, updatedSvcExport); err != nil {
+		t.Fatalf("svc export Get(%+v), got %v, want no error", svcExportKey, err)
+	}
+	wantConds := []metav1.Condition{wantCond}
+	if diff := cmp.Diff(wantConds, updatedSvcExport.Status.Conditions, ignoredCondFields); diff != "" {
+		t.Fatalf("svc export conditions mismatch (-want, +got):\n%s", diff)
+	}
+}
+
 // TestMarkServiceExportAsValid tests the *Reconciler.markServiceExportAsValid method.
 func TestMarkServiceExportAsValid(t *testing.T) {
 	testCases := []struct {
@@ -580,7 +946,7 @@ func TestRemoveServiceExportCleanupFinalizer(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{
 					Namespace:  memberUserNS,
 					Name:       svcName,
-					Finalizers: []string{svcExportCleanupFinalizer},
+					Finalizers: []string{objectmeta.ServiceExportCleanupFinalizer},
 				},
 			},
 			want: nil,
@@ -635,7 +1001,7 @@ func TestAddServiceExportCleanupFinalizer(t *testing.T) {
 					Name:      svcName,
 				},
 			},
-			want: []string{svcExportCleanupFinalizer},
+			want: []string{objectmeta.ServiceExportCleanupFinalizer},
 		},
 	}
 
@@ -687,7 +1053,7 @@ func TestUnexportService(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{
 					Namespace:  memberUserNS,
 					Name:       svcName,
-					Finalizers: []string{svcExportCleanupFinalizer},
+					Finalizers: []string{objectmeta.ServiceExportCleanupFinalizer},
 				},
 			},
 			internalSvcExport: &fleetnetv1alpha1.InternalServiceExport{
@@ -703,7 +1069,7 @@ func TestUnexportService(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{
 					Namespace:  memberUserNS,
 					Name:       svcName,
-					Finalizers: []string{svcExportCleanupFinalizer},
+					Finalizers: []string{objectmeta.ServiceExportCleanupFinalizer},
 				},
 			},
 		},
@@ -1456,26 +1822,1009 @@ func TestSetAzureRelatedInformation(t *testing.T) {
 	}
 }
 
-type fakePublicIPAddressClient struct {
-	ListResponse []*armnetwork.PublicIPAddress
-	ListError    error
-}
+// TestReconcile_RestoreInternalServiceExport tests that the reconciler restores an InternalServiceExport deleted
+// out-of-band, and that it leaves alone the InternalServiceExport of a Service that is being unexported.
+func TestReconcile_RestoreInternalServiceExport(t *testing.T) {
+	internalSvcExportKey := types.NamespacedName{Namespace: hubNSForMember, Name: fmt.Sprintf("%s-%s", memberUserNS, svcName)}
+	svcExportKey := types.NamespacedName{Namespace: memberUserNS, Name: svcName}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: memberUserNS,
+			Name:      svcName,
+			UID:       "svc-uid",
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{
+					Protocol:   corev1.ProtocolTCP,
+					Port:       80,
+					TargetPort: intstr.FromInt(8080),
+				},
+			},
+		},
+	}
 
-func (c *fakePublicIPAddressClient) Get(_ context.Context, _ string, _ string, _ *string) (*armnetwork.PublicIPAddress, error) {
-	return nil, nil
-}
+	testCases := []struct {
+		name                  string
+		svcExport             *fleetnetv1alpha1.ServiceExport
+		wantRes               ctrl.Result
+		wantInternalSvcExport bool
+	}{
+		{
+			name: "should restore the internal svc export of an exported svc",
+			svcExport: &fleetnetv1alpha1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:  memberUserNS,
+					Name:       svcName,
+					Finalizers: []string{objectmeta.ServiceExportCleanupFinalizer},
+				},
+				Status: fleetnetv1alpha1.ServiceExportStatus{
+					Conditions: []metav1.Condition{
+						serviceExportValidCondition(memberUserNS, svcName),
+						serviceExportNoConflictCondition(memberUserNS, svcName),
+					},
+				},
+			},
+			wantRes:               ctrl.Result{RequeueAfter: svcExportResyncInterval},
+			wantInternalSvcExport: true,
+		},
+		{
+			name: "should not restore the internal svc export of a svc being unexported",
+			svcExport: &fleetnetv1alpha1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:         memberUserNS,
+					Name:              svcName,
+					Finalizers:        []string{objectmeta.ServiceExportCleanupFinalizer},
+					DeletionTimestamp: &metav1.Time{Time: time.Now()},
+				},
+			},
+			wantRes: ctrl.Result{},
+		},
+	}
 
-func (c *fakePublicIPAddressClient) CreateOrUpdate(_ context.Context, _ string, _ string, _ armnetwork.PublicIPAddress) (*armnetwork.PublicIPAddress, error) {
-	return nil, nil
-}
+	ctx := context.Background()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeMemberClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(svc, tc.svcExport).
+				WithStatusSubresource(tc.svcExport).
+				Build()
+			// The internal svc export has been deleted from the hub cluster out-of-band.
+			fakeHubClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+			reconciler := Reconciler{
+				MemberClusterID: "member-1",
+				MemberClient:    fakeMemberClient,
+				HubClient:       fakeHubClient,
+				HubNamespace:    hubNSForMember,
+				Recorder:        record.NewFakeRecorder(10),
+			}
 
-func (c *fakePublicIPAddressClient) Delete(_ context.Context, _ string, _ string) error {
-	return nil
-}
+			res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: svcExportKey})
+			if err != nil || !cmp.Equal(res, tc.wantRes) {
+				t.Fatalf("Reconcile() = %+v, %v, want %+v, nil", res, err, tc.wantRes)
+			}
 
-func (c *fakePublicIPAddressClient) List(_ context.Context, rg string) ([]*armnetwork.PublicIPAddress, error) {
-	if rg == validResourceGroup {
-		return c.ListResponse, c.ListError
+			internalSvcExport := &fleetnetv1alpha1.InternalServiceExport{}
+			err = fakeHubClient.Get(ctx, internalSvcExportKey, internalSvcExport)
+			switch {
+			case tc.wantInternalSvcExport && err != nil:
+				t.Fatalf("internalSvcExport Get(%+v), got %v, want no error", internalSvcExportKey, err)
+			case tc.wantInternalSvcExport && internalSvcExport.Spec.ServiceReference.UID != svc.UID:
+				t.Fatalf("internalSvcExport service reference UID, got %s, want %s", internalSvcExport.Spec.ServiceReference.UID, svc.UID)
+			case !tc.wantInternalSvcExport && !apierrors.IsNotFound(err):
+				t.Fatalf("internalSvcExport Get(%+v), got %v, want not found error", internalSvcExportKey, err)
+			}
+		})
+	}
+}
+
+// TestReconcile_Draining tests that a deleted svc export keeps its svc exported until the drain period ends.
+func TestReconcile_Draining(t *testing.T) {
+	internalSvcExportKey := types.NamespacedName{Namespace: hubNSForMember, Name: fmt.Sprintf("%s-%s", memberUserNS, svcName)}
+	svcExportKey := types.NamespacedName{Namespace: memberUserNS, Name: svcName}
+	drainPeriod := time.Minute
+
+	testCases := []struct {
+		name                  string
+		deletedFor            time.Duration
+		wantRequeue           bool
+		wantInternalSvcExport bool
+	}{
+		{
+			name:                  "should keep the svc exported while it is drained",
+			deletedFor:            time.Second * 10,
+			wantRequeue:           true,
+			wantInternalSvcExport: true,
+		},
+		{
+			name:       "should unexport the svc once the drain period ends",
+			deletedFor: drainPeriod * 2,
+		},
+	}
+
+	ctx := context.Background()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svcExport := &fleetnetv1alpha1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:         memberUserNS,
+					Name:              svcName,
+					Finalizers:        []string{objectmeta.ServiceExportCleanupFinalizer},
+					DeletionTimestamp: &metav1.Time{Time: time.Now().Add(-tc.deletedFor)},
+					Annotations: map[string]string{
+						objectmeta.ServiceExportAnnotationDrainPeriod: drainPeriod.String(),
+					},
+				},
+			}
+			internalSvcExport := &fleetnetv1alpha1.InternalServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: internalSvcExportKey.Namespace,
+					Name:      internalSvcExportKey.Name,
+				},
+			}
+			fakeMemberClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(svcExport).Build()
+			fakeHubClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(internalSvcExport).Build()
+			reconciler := Reconciler{
+				MemberClusterID: "member-1",
+				MemberClient:    fakeMemberClient,
+				HubClient:       fakeHubClient,
+				HubNamespace:    hubNSForMember,
+				Recorder:        record.NewFakeRecorder(10),
+			}
+
+			res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: svcExportKey})
+			if err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+			if gotRequeue := res.RequeueAfter > 0 && res.RequeueAfter <= drainPeriod-tc.deletedFor; gotRequeue != tc.wantRequeue {
+				t.Errorf("Reconcile() = %+v, want a requeue before the drain period ends %t", res, tc.wantRequeue)
+			}
+
+			err = fakeHubClient.Get(ctx, internalSvcExportKey, &fleetnetv1alpha1.InternalServiceExport{})
+			switch {
+			case tc.wantInternalSvcExport && err != nil:
+				t.Fatalf("internalSvcExport Get(%+v), got %v, want no error", internalSvcExportKey, err)
+			case !tc.wantInternalSvcExport && !apierrors.IsNotFound(err):
+				t.Fatalf("internalSvcExport Get(%+v), got %v, want not found error", internalSvcExportKey, err)
+			}
+		})
+	}
+}
+
+// TestReconcile_Paused tests that a deleted ServiceExport stays exported while its reconciliation is paused.
+func TestReconcile_Paused(t *testing.T) {
+	internalSvcExportKey := types.NamespacedName{Namespace: hubNSForMember, Name: fmt.Sprintf("%s-%s", memberUserNS, svcName)}
+	svcExportKey := types.NamespacedName{Namespace: memberUserNS, Name: svcName}
+	pausedCond := metav1.Condition{
+		Type:   string(fleetnetv1alpha1.ServiceExportPaused),
+		Status: metav1.ConditionTrue,
+		Reason: pause.ReasonReconciliationPaused,
+	}
+
+	testCases := []struct {
+		name                  string
+		paused                bool
+		conditions            []metav1.Condition
+		wantInternalSvcExport bool
+	}{
+		{
+			name:                  "should keep the svc exported and report the pause",
+			paused:                true,
+			wantInternalSvcExport: true,
+		},
+		{
+			name:       "should unexport the svc once resumed",
+			conditions: []metav1.Condition{pausedCond},
+		},
+	}
+
+	ctx := context.Background()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svcExport := &fleetnetv1alpha1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:         memberUserNS,
+					Name:              svcName,
+					Finalizers:        []string{objectmeta.ServiceExportCleanupFinalizer},
+					DeletionTimestamp: &metav1.Time{Time: time.Now()},
+				},
+				Status: fleetnetv1alpha1.ServiceExportStatus{Conditions: tc.conditions},
+			}
+			if tc.paused {
+				svcExport.Annotations = map[string]string{objectmeta.ObjectAnnotationPaused: "true"}
+			}
+			internalSvcExport := &fleetnetv1alpha1.InternalServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: internalSvcExportKey.Namespace,
+					Name:      internalSvcExportKey.Name,
+				},
+			}
+			fakeMemberClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(svcExport).
+				WithStatusSubresource(svcExport).
+				Build()
+			fakeHubClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(internalSvcExport).Build()
+			reconciler := Reconciler{
+				MemberClusterID: "member-1",
+				MemberClient:    fakeMemberClient,
+				HubClient:       fakeHubClient,
+				HubNamespace:    hubNSForMember,
+				Recorder:        record.NewFakeRecorder(10),
+			}
+
+			if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: svcExportKey}); err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+
+			err := fakeHubClient.Get(ctx, internalSvcExportKey, &fleetnetv1alpha1.InternalServiceExport{})
+			switch {
+			case tc.wantInternalSvcExport && err != nil:
+				t.Fatalf("internalSvcExport Get(%+v), got %v, want no error", internalSvcExportKey, err)
+			case !tc.wantInternalSvcExport && !apierrors.IsNotFound(err):
+				t.Fatalf("internalSvcExport Get(%+v), got %v, want not found error", internalSvcExportKey, err)
+			}
+			if !tc.paused {
+				return
+			}
+			got := &fleetnetv1alpha1.ServiceExport{}
+			if err := fakeMemberClient.Get(ctx, svcExportKey, got); err != nil {
+				t.Fatalf("svcExport Get(%+v), got %v, want no error", svcExportKey, err)
+			}
+			if !meta.IsStatusConditionTrue(got.Status.Conditions, string(fleetnetv1alpha1.ServiceExportPaused)) {
+				t.Errorf("svcExport conditions = %+v, want the paused condition", got.Status.Conditions)
+			}
+		})
+	}
+}
+
+// TestRemoveStaleEndpointSliceExports tests the *Reconciler.removeStaleEndpointSliceExports method.
+func TestRemoveStaleEndpointSliceExports(t *testing.T) {
+	endpointSliceExportFor := func(name, svcName, endpointSliceName string) *fleetnetv1alpha1.EndpointSliceExport {
+		return &fleetnetv1alpha1.EndpointSliceExport{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: hubNSForMember,
+				Name:      name,
+				Labels: map[string]string{
+					objectmeta.EndpointSliceExportLabelOwnerServiceNamespace: memberUserNS,
+					objectmeta.EndpointSliceExportLabelOwnerServiceName:      svcName,
+				},
+			},
+			Spec: fleetnetv1alpha1.EndpointSliceExportSpec{
+				EndpointSliceReference: fleetnetv1alpha1.ExportedObjectReference{
+					Namespace: memberUserNS,
+					Name:      endpointSliceName,
+				},
+			},
+		}
+	}
+	endpointSliceFor := func(name, uniqueName string) *discoveryv1.EndpointSlice {
+		return &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: memberUserNS,
+				Name:      name,
+				Labels: map[string]string{
+					discoveryv1.LabelServiceName: svcName,
+				},
+				Annotations: map[string]string{
+					objectmeta.ExportedObjectAnnotationUniqueName: uniqueName,
+				},
+			},
+			AddressType: discoveryv1.AddressTypeIPv4,
+		}
+	}
+
+	aggregatedEndpointSliceExportFor := func(name string, svcUID types.UID) *fleetnetv1alpha1.EndpointSliceExport {
+		endpointSliceExport := endpointSliceExportFor(name, svcName, svcName)
+		endpointSliceExport.Spec.EndpointSliceReference.Kind = "Service"
+		endpointSliceExport.Spec.EndpointSliceReference.UID = svcUID
+		return endpointSliceExport
+	}
+
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: memberUserNS,
+			Name:      svcName,
+			UID:       "svc-uid",
+		},
+	}
+	testCases := []struct {
+		name                         string
+		aggregate                    bool
+		endpointSlices               []client.Object
+		endpointSliceExports         []client.Object
+		wantEndpointSliceExportNames []string
+	}{
+		{
+			name:      "should keep aggregated endpoint slice exports of the service",
+			aggregate: true,
+			endpointSliceExports: []client.Object{
+				aggregatedEndpointSliceExportFor("bravelion-work-app-ipv4-0", "svc-uid"),
+			},
+			wantEndpointSliceExportNames: []string{"bravelion-work-app-ipv4-0"},
+		},
+		{
+			name:      "should remove aggregated endpoint slice exports of a re-created service",
+			aggregate: true,
+			endpointSliceExports: []client.Object{
+				aggregatedEndpointSliceExportFor("bravelion-work-app-ipv4-0", "old-svc-uid"),
+			},
+			wantEndpointSliceExportNames: []string{},
+		},
+		{
+			name: "should remove aggregated endpoint slice exports when the exports are no longer aggregated",
+			endpointSliceExports: []client.Object{
+				aggregatedEndpointSliceExportFor("bravelion-work-app-ipv4-0", "svc-uid"),
+			},
+			wantEndpointSliceExportNames: []string{},
+		},
+		{
+			name: "should remove endpoint slice exports whose source endpoint slices are deleted",
+			endpointSlices: []client.Object{
+				endpointSliceFor("app-1", "bravelion-work-app-1"),
+			},
+			endpointSliceExports: []client.Object{
+				endpointSliceExportFor("bravelion-work-app-1", svcName, "app-1"),
+				endpointSliceExportFor("bravelion-work-app-2", svcName, "app-2"),
+			},
+			wantEndpointSliceExportNames: []string{"bravelion-work-app-1"},
+		},
+		{
+			name: "should remove endpoint slice exports whose source endpoint slices are exported under a different name",
+			endpointSlices: []client.Object{
+				endpointSliceFor("app-1", "bravelion-work-app-1-new"),
+			},
+			endpointSliceExports: []client.Object{
+				endpointSliceExportFor("bravelion-work-app-1", svcName, "app-1"),
+			},
+			wantEndpointSliceExportNames: []string{},
+		},
+		{
+			name: "should not remove endpoint slice exports from other services",
+			endpointSliceExports: []client.Object{
+				endpointSliceExportFor("bravelion-work-db-1", "db", "db-1"),
+			},
+			wantEndpointSliceExportNames: []string{"bravelion-work-db-1"},
+		},
+	}
+
+	ctx := context.Background()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeMemberClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(tc.endpointSlices...).
+				Build()
+			fakeHubClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(tc.endpointSliceExports...).
+				Build()
+			reconciler := Reconciler{
+				MemberClient:                  fakeMemberClient,
+				HubClient:                     fakeHubClient,
+				HubNamespace:                  hubNSForMember,
+				AggregateEndpointSliceExports: tc.aggregate,
+			}
+
+			if err := reconciler.removeStaleEndpointSliceExports(ctx, svc); err != nil {
+				t.Fatalf("removeStaleEndpointSliceExports() = %v, want no error", err)
+			}
+
+			endpointSliceExportList := &fleetnetv1alpha1.EndpointSliceExportList{}
+			if err := fakeHubClient.List(ctx, endpointSliceExportList, client.InNamespace(hubNSForMember)); err != nil {
+				t.Fatalf("endpointSliceExport List(), got %v, want no error", err)
+			}
+			gotNames := []string{}
+			for _, endpointSliceExport := range endpointSliceExportList.Items {
+				gotNames = append(gotNames, endpointSliceExport.Name)
+			}
+			if diff := cmp.Diff(tc.wantEndpointSliceExportNames, gotNames, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+				t.Errorf("endpointSliceExports mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+type fakePublicIPAddressClient struct {
+	ListResponse []*armnetwork.PublicIPAddress
+	ListError    error
+}
+
+func (c *fakePublicIPAddressClient) Get(_ context.Context, _ string, _ string, _ *string) (*armnetwork.PublicIPAddress, error) {
+	return nil, nil
+}
+
+func (c *fakePublicIPAddressClient) CreateOrUpdate(_ context.Context, _ string, _ string, _ armnetwork.PublicIPAddress) (*armnetwork.PublicIPAddress, error) {
+	return nil, nil
+}
+
+func (c *fakePublicIPAddressClient) Delete(_ context.Context, _ string, _ string) error {
+	return nil
+}
+
+func (c *fakePublicIPAddressClient) List(_ context.Context, rg string) ([]*armnetwork.PublicIPAddress, error) {
+	if rg == validResourceGroup {
+		return c.ListResponse, c.ListError
+	}
+	return nil, errors.New("invalid resource group")
+}
+
+// TestExtractDNSTTL tests the extractDNSTTL function.
+func TestExtractDNSTTL(t *testing.T) {
+	testCases := []struct {
+		name        string
+		annotations map[string]string
+		want        *int64
+		wantErr     bool
+	}{
+		{
+			name: "no annotation",
+		},
+		{
+			name:        "valid ttl",
+			annotations: map[string]string{objectmeta.ServiceExportAnnotationDNSTTL: "60"},
+			want:        ptr.To(int64(60)),
+		},
+		{
+			name:        "zero ttl",
+			annotations: map[string]string{objectmeta.ServiceExportAnnotationDNSTTL: "0"},
+			wantErr:     true,
+		},
+		{
+			name:        "negative ttl",
+			annotations: map[string]string{objectmeta.ServiceExportAnnotationDNSTTL: "-5"},
+			wantErr:     true,
+		},
+		{
+			name:        "non-integer ttl",
+			annotations: map[string]string{objectmeta.ServiceExportAnnotationDNSTTL: "30s"},
+			wantErr:     true,
+		},
+		{
+			name:        "empty ttl",
+			annotations: map[string]string{objectmeta.ServiceExportAnnotationDNSTTL: ""},
+			wantErr:     true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svcExport := &fleetnetv1alpha1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   memberUserNS,
+					Name:        svcName,
+					Annotations: tc.annotations,
+				},
+			}
+			got, err := extractDNSTTL(svcExport)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("extractDNSTTL() got error %v, want error %t", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("extractDNSTTL() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestIgnoreSystemManagedUpdatesPredicate tests the ignoreSystemManagedUpdatesPredicate function.
+func TestIgnoreSystemManagedUpdatesPredicate(t *testing.T) {
+	earlier := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	validCond := serviceExportValidCondition(memberUserNS, svcName)
+	validCond.LastTransitionTime = earlier
+
+	testCases := []struct {
+		name   string
+		mutate func(svcExport *fleetnetv1alpha1.ServiceExport)
+		want   bool
+	}{
+		{
+			name: "self write refreshing the condition timestamp only",
+			mutate: func(svcExport *fleetnetv1alpha1.ServiceExport) {
+				svcExport.Status.Conditions[0].LastTransitionTime = metav1.Now()
+				svcExport.ManagedFields = []metav1.ManagedFieldsEntry{{Manager: ControllerName}}
+			},
+			want: false,
+		},
+		{
+			name: "condition status transition",
+			mutate: func(svcExport *fleetnetv1alpha1.ServiceExport) {
+				svcExport.Status.Conditions[0] = serviceExportInvalidNotFoundCondition(memberUserNS, svcName)
+			},
+			want: true,
+		},
+		{
+			name: "condition reason change",
+			mutate: func(svcExport *fleetnetv1alpha1.ServiceExport) {
+				svcExport.Status.Conditions[0].Reason = svcExportInvalidIneligibleCondReason
+			},
+			want: true,
+		},
+		{
+			name: "new condition",
+			mutate: func(svcExport *fleetnetv1alpha1.ServiceExport) {
+				svcExport.Status.Conditions = append(svcExport.Status.Conditions, serviceExportNoConflictCondition(memberUserNS, svcName))
+			},
+			want: true,
+		},
+		{
+			name: "metadata change",
+			mutate: func(svcExport *fleetnetv1alpha1.ServiceExport) {
+				svcExport.Finalizers = nil
+				svcExport.Annotations = map[string]string{"foo": "bar"}
+			},
+			want: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			svcExport := &fleetnetv1alpha1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:  memberUserNS,
+					Name:       svcName,
+					Finalizers: []string{objectmeta.ServiceExportCleanupFinalizer},
+				},
+				Status: fleetnetv1alpha1.ServiceExportStatus{
+					Conditions: []metav1.Condition{validCond},
+				},
+			}
+			fakeMemberClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(svcExport).
+				WithStatusSubresource(svcExport).
+				Build()
+
+			oldSvcExport := &fleetnetv1alpha1.ServiceExport{}
+			if err := fakeMemberClient.Get(ctx, types.NamespacedName{Namespace: memberUserNS, Name: svcName}, oldSvcExport); err != nil {
+				t.Fatalf("serviceExport Get() = %v, want no error", err)
+			}
+			// Write the change through the client so that the resource version gets bumped as it would be.
+			wantSvcExport := oldSvcExport.DeepCopy()
+			tc.mutate(wantSvcExport)
+			newSvcExport := wantSvcExport.DeepCopy()
+			if err := fakeMemberClient.Update(ctx, newSvcExport); err != nil {
+				t.Fatalf("serviceExport Update() = %v, want no error", err)
+			}
+			newSvcExport.Status = wantSvcExport.Status
+			if err := fakeMemberClient.Status().Update(ctx, newSvcExport); err != nil {
+				t.Fatalf("serviceExport Status().Update() = %v, want no error", err)
+			}
+			if newSvcExport.ResourceVersion == oldSvcExport.ResourceVersion {
+				t.Fatalf("serviceExport resource version is not bumped")
+			}
+
+			got := ignoreSystemManagedUpdatesPredicate().Update(event.UpdateEvent{ObjectOld: oldSvcExport, ObjectNew: newSvcExport})
+			if got != tc.want {
+				t.Errorf("ignoreSystemManagedUpdatesPredicate().Update() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExtractCanaryPercent(t *testing.T) {
+	testCases := []struct {
+		name        string
+		annotations map[string]string
+		want        *int32
+		wantErr     bool
+	}{
+		{
+			name: "no annotation",
+		},
+		{
+			name:        "valid percentage",
+			annotations: map[string]string{objectmeta.ServiceExportAnnotationCanaryPercent: "25"},
+			want:        ptr.To(int32(25)),
+		},
+		{
+			name:        "zero percentage",
+			annotations: map[string]string{objectmeta.ServiceExportAnnotationCanaryPercent: "0"},
+			want:        ptr.To(int32(0)),
+		},
+		{
+			name:        "negative percentage is clamped",
+			annotations: map[string]string{objectmeta.ServiceExportAnnotationCanaryPercent: "-10"},
+			want:        ptr.To(int32(0)),
+		},
+		{
+			name:        "percentage over 100 is clamped",
+			annotations: map[string]string{objectmeta.ServiceExportAnnotationCanaryPercent: "150"},
+			want:        ptr.To(int32(100)),
+		},
+		{
+			name:        "non-integer percentage",
+			annotations: map[string]string{objectmeta.ServiceExportAnnotationCanaryPercent: "12.5"},
+			wantErr:     true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svcExport := &fleetnetv1alpha1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   memberUserNS,
+					Name:        svcName,
+					Annotations: tc.annotations,
+				},
+			}
+			got, err := extractCanaryPercent(svcExport)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("extractCanaryPercent() got error %v, want error %t", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("extractCanaryPercent() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestExtractHealthCheckAnnotations tests the extractHealthCheckAnnotations function.
+func TestExtractHealthCheckAnnotations(t *testing.T) {
+	keys := []string{objectmeta.ServiceAnnotationHealthCheckPath, objectmeta.ServiceAnnotationHealthCheckPort}
+	testCases := []struct {
+		name        string
+		annotations map[string]string
+		want        map[string]string
+		wantErr     bool
+	}{
+		{
+			name: "no annotations",
+		},
+		{
+			name: "health-check annotations are extracted",
+			annotations: map[string]string{
+				objectmeta.ServiceAnnotationHealthCheckPath: "/healthz",
+				objectmeta.ServiceAnnotationHealthCheckPort: "8080",
+				"example.com/unrelated":                     "value",
+			},
+			want: map[string]string{
+				objectmeta.ServiceAnnotationHealthCheckPath: "/healthz",
+				objectmeta.ServiceAnnotationHealthCheckPort: "8080",
+			},
+		},
+		{
+			name:        "escaped path",
+			annotations: map[string]string{objectmeta.ServiceAnnotationHealthCheckPath: "/health%20check/ready"},
+			want:        map[string]string{objectmeta.ServiceAnnotationHealthCheckPath: "/health%20check/ready"},
+		},
+		{
+			name: "relative path is dropped",
+			annotations: map[string]string{
+				objectmeta.ServiceAnnotationHealthCheckPath: "healthz",
+				objectmeta.ServiceAnnotationHealthCheckPort: "8080",
+			},
+			want:    map[string]string{objectmeta.ServiceAnnotationHealthCheckPort: "8080"},
+			wantErr: true,
+		},
+		{
+			name:        "path with a query is dropped",
+			annotations: map[string]string{objectmeta.ServiceAnnotationHealthCheckPath: "/healthz?verbose=1"},
+			wantErr:     true,
+		},
+		{
+			name:        "URL is dropped",
+			annotations: map[string]string{objectmeta.ServiceAnnotationHealthCheckPath: "http://example.com/healthz"},
+			wantErr:     true,
+		},
+		{
+			name:        "path with unescaped spaces is dropped",
+			annotations: map[string]string{objectmeta.ServiceAnnotationHealthCheckPath: "/health check"},
+			wantErr:     true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   memberUserNS,
+					Name:        svcName,
+					Annotations: tc.annotations,
+				},
+			}
+			got, err := extractHealthCheckAnnotations(svc, keys)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("extractHealthCheckAnnotations() got error %v, want error %t", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("extractHealthCheckAnnotations() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestReconcile_HealthCheckAnnotations tests that the configured health-check annotations of a Service are exported
+// to the hub cluster, and the invalid ones are reported.
+func TestReconcile_HealthCheckAnnotations(t *testing.T) {
+	internalSvcExportKey := types.NamespacedName{Namespace: hubNSForMember, Name: fmt.Sprintf("%s-%s", memberUserNS, svcName)}
+	svcExportKey := types.NamespacedName{Namespace: memberUserNS, Name: svcName}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: memberUserNS,
+			Name:      svcName,
+			UID:       "svc-uid",
+			Annotations: map[string]string{
+				objectmeta.ServiceAnnotationHealthCheckPath: "healthz",
+				objectmeta.ServiceAnnotationHealthCheckPort: "8080",
+				"example.com/unrelated":                     "value",
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{
+					Protocol:   corev1.ProtocolTCP,
+					Port:       80,
+					TargetPort: intstr.FromInt(8080),
+				},
+			},
+		},
+	}
+	svcExport := &fleetnetv1alpha1.ServiceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  memberUserNS,
+			Name:       svcName,
+			Finalizers: []string{objectmeta.ServiceExportCleanupFinalizer},
+		},
+	}
+
+	ctx := context.Background()
+	fakeMemberClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(svc, svcExport).
+		WithStatusSubresource(svcExport).
+		Build()
+	fakeHubClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	recorder := record.NewFakeRecorder(10)
+	reconciler := Reconciler{
+		MemberClusterID:           "member-1",
+		MemberClient:              fakeMemberClient,
+		HubClient:                 fakeHubClient,
+		HubNamespace:              hubNSForMember,
+		Recorder:                  recorder,
+		HealthCheckAnnotationKeys: []string{objectmeta.ServiceAnnotationHealthCheckPath, objectmeta.ServiceAnnotationHealthCheckPort},
+	}
+
+	if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: svcExportKey}); err != nil {
+		t.Fatalf("Reconcile() = %v, want no error", err)
+	}
+	internalSvcExport := &fleetnetv1alpha1.InternalServiceExport{}
+	if err := fakeHubClient.Get(ctx, internalSvcExportKey, internalSvcExport); err != nil {
+		t.Fatalf("internalSvcExport Get(%+v), got %v, want no error", internalSvcExportKey, err)
+	}
+	// The health-check path is dropped as it is not a well-formed URL path.
+	want := map[string]string{objectmeta.ServiceAnnotationHealthCheckPort: "8080"}
+	if diff := cmp.Diff(want, internalSvcExport.Spec.HealthCheckAnnotations); diff != "" {
+		t.Errorf("internalSvcExport health-check annotations mismatch (-want, +got):\n%s", diff)
+	}
+	var events []string
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	if !strings.Contains(strings.Join(events, "\n"), "InvalidHealthCheckAnnotation") {
+		t.Errorf("events = %q, want an InvalidHealthCheckAnnotation event", events)
+	}
+
+	// The fixed health-check path is exported.
+	svc.Annotations[objectmeta.ServiceAnnotationHealthCheckPath] = "/healthz"
+	if err := fakeMemberClient.Update(ctx, svc); err != nil {
+		t.Fatalf("Service Update() = %v, want no error", err)
+	}
+	if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: svcExportKey}); err != nil {
+		t.Fatalf("Reconcile() = %v, want no error", err)
+	}
+	if err := fakeHubClient.Get(ctx, internalSvcExportKey, internalSvcExport); err != nil {
+		t.Fatalf("internalSvcExport Get(%+v), got %v, want no error", internalSvcExportKey, err)
+	}
+	want = map[string]string{
+		objectmeta.ServiceAnnotationHealthCheckPath: "/healthz",
+		objectmeta.ServiceAnnotationHealthCheckPort: "8080",
+	}
+	if diff := cmp.Diff(want, internalSvcExport.Spec.HealthCheckAnnotations); diff != "" {
+		t.Errorf("internalSvcExport health-check annotations mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+// TestReconcile_PortSelection tests that only the selected ports are exported, and that the export is withdrawn
+// once none of the ports is selected.
+func TestReconcile_PortSelection(t *testing.T) {
+	internalSvcExportKey := types.NamespacedName{Namespace: hubNSForMember, Name: fmt.Sprintf("%s-%s", memberUserNS, svcName)}
+	svcExportKey := types.NamespacedName{Namespace: memberUserNS, Name: svcName}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: memberUserNS,
+			Name:      svcName,
+			UID:       "svc-uid",
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "web",
+					Protocol:   corev1.ProtocolTCP,
+					Port:       80,
+					TargetPort: intstr.FromInt(8080),
+				},
+				{
+					Name:       "admin",
+					Protocol:   corev1.ProtocolTCP,
+					Port:       9000,
+					TargetPort: intstr.FromInt(9000),
+				},
+			},
+		},
+	}
+	svcExport := &fleetnetv1alpha1.ServiceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  memberUserNS,
+			Name:       svcName,
+			Finalizers: []string{objectmeta.ServiceExportCleanupFinalizer},
+		},
+		Spec: fleetnetv1alpha1.ServiceExportSpec{
+			Ports: []intstr.IntOrString{intstr.FromString("web")},
+		},
+	}
+
+	ctx := context.Background()
+	fakeMemberClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(svc, svcExport).
+		WithStatusSubresource(svcExport).
+		Build()
+	fakeHubClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	reconciler := Reconciler{
+		MemberClusterID: "member-1",
+		MemberClient:    fakeMemberClient,
+		HubClient:       fakeHubClient,
+		HubNamespace:    hubNSForMember,
+		Recorder:        record.NewFakeRecorder(10),
+	}
+
+	if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: svcExportKey}); err != nil {
+		t.Fatalf("Reconcile() = %v, want no error", err)
+	}
+	internalSvcExport := &fleetnetv1alpha1.InternalServiceExport{}
+	if err := fakeHubClient.Get(ctx, internalSvcExportKey, internalSvcExport); err != nil {
+		t.Fatalf("internalSvcExport Get(%+v), got %v, want no error", internalSvcExportKey, err)
+	}
+	wantPorts := []fleetnetv1alpha1.ServicePort{
+		{
+			Name:       "web",
+			Protocol:   corev1.ProtocolTCP,
+			Port:       80,
+			TargetPort: intstr.FromInt(8080),
+		},
+	}
+	if diff := cmp.Diff(wantPorts, internalSvcExport.Spec.Ports); diff != "" {
+		t.Errorf("internalSvcExport ports mismatch (-want, +got):\n%s", diff)
+	}
+
+	// The export is withdrawn and marked as invalid once none of the ports is selected.
+	if err := fakeMemberClient.Get(ctx, svcExportKey, svcExport); err != nil {
+		t.Fatalf("serviceExport Get() = %v, want no error", err)
+	}
+	svcExport.Spec.Ports = []intstr.IntOrString{intstr.FromString("metrics")}
+	if err := fakeMemberClient.Update(ctx, svcExport); err != nil {
+		t.Fatalf("serviceExport Update() = %v, want no error", err)
+	}
+	if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: svcExportKey}); err != nil {
+		t.Fatalf("Reconcile() = %v, want no error", err)
+	}
+	if err := fakeHubClient.Get(ctx, internalSvcExportKey, internalSvcExport); !apierrors.IsNotFound(err) {
+		t.Errorf("internalSvcExport Get(%+v), got %v, want not found error", internalSvcExportKey, err)
+	}
+	if err := fakeMemberClient.Get(ctx, svcExportKey, svcExport); err != nil {
+		t.Fatalf("serviceExport Get() = %v, want no error", err)
+	}
+	validCond := meta.FindStatusCondition(svcExport.Status.Conditions, string(fleetnetv1alpha1.ServiceExportValid))
+	if validCond == nil || validCond.Status != metav1.ConditionFalse || validCond.Reason != svcExportInvalidNoPortsSelectedReason {
+		t.Errorf("serviceExport valid condition = %v, want false with reason %s", validCond, svcExportInvalidNoPortsSelectedReason)
+	}
+}
+
+// TestReconcile_RejectHeadlessServices tests that a headless Service is not exported once the HeadlessServiceExport
+// feature is disabled.
+func TestReconcile_RejectHeadlessServices(t *testing.T) {
+	internalSvcExportKey := types.NamespacedName{Namespace: hubNSForMember, Name: fmt.Sprintf("%s-%s", memberUserNS, svcName)}
+	svcExportKey := types.NamespacedName{Namespace: memberUserNS, Name: svcName}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: memberUserNS,
+			Name:      svcName,
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+		},
+	}
+	svcExport := &fleetnetv1alpha1.ServiceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: memberUserNS,
+			Name:      svcName,
+		},
+	}
+
+	ctx := context.Background()
+	fakeMemberClient := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(svc, svcExport).
+		WithStatusSubresource(svcExport).
+		Build()
+	fakeHubClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	reconciler := Reconciler{
+		MemberClusterID:        "member-1",
+		MemberClient:           fakeMemberClient,
+		HubClient:              fakeHubClient,
+		HubNamespace:           hubNSForMember,
+		Recorder:               record.NewFakeRecorder(10),
+		RejectHeadlessServices: true,
+	}
+
+	if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: svcExportKey}); err != nil {
+		t.Fatalf("Reconcile() = %v, want no error", err)
+	}
+	internalSvcExport := &fleetnetv1alpha1.InternalServiceExport{}
+	if err := fakeHubClient.Get(ctx, internalSvcExportKey, internalSvcExport); !apierrors.IsNotFound(err) {
+		t.Errorf("internalSvcExport Get(%+v), got %v, want not found error", internalSvcExportKey, err)
+	}
+	if err := fakeMemberClient.Get(ctx, svcExportKey, svcExport); err != nil {
+		t.Fatalf("serviceExport Get() = %v, want no error", err)
+	}
+	validCond := meta.FindStatusCondition(svcExport.Status.Conditions, string(fleetnetv1alpha1.ServiceExportValid))
+	if validCond == nil || validCond.Status != metav1.ConditionFalse || validCond.Reason != svcExportInvalidIneligibleCondReason {
+		t.Errorf("serviceExport valid condition = %v, want false with reason %s", validCond, svcExportInvalidIneligibleCondReason)
+	}
+}
+
+// TestExtractExportedMetadata tests the extractExportedMetadata function.
+func TestExtractExportedMetadata(t *testing.T) {
+	testCases := []struct {
+		name            string
+		spec            fleetnetv1alpha1.ServiceExportSpec
+		wantLabels      map[string]string
+		wantAnnotations map[string]string
+		wantErr         string
+	}{
+		{
+			name: "no exported metadata",
+		},
+		{
+			name: "valid exported metadata",
+			spec: fleetnetv1alpha1.ServiceExportSpec{
+				ExportedLabels:      map[string]string{"team": "payments"},
+				ExportedAnnotations: map[string]string{"example.com/owner": "alice <alice@example.com>"},
+			},
+			wantLabels:      map[string]string{"team": "payments"},
+			wantAnnotations: map[string]string{"example.com/owner": "alice <alice@example.com>"},
+		},
+		{
+			name: "reserved and invalid metadata are dropped",
+			spec: fleetnetv1alpha1.ServiceExportSpec{
+				ExportedLabels: map[string]string{
+					"team": "payments",
+					"networking.fleet.azure.com/derived-service": "app",
+					"tier": "back end",
+				},
+				ExportedAnnotations: map[string]string{"bad key": "value"},
+			},
+			wantLabels: map[string]string{"team": "payments"},
+			wantErr: `the exported annotation bad key, label networking.fleet.azure.com/derived-service="app", ` +
+				`label tier="back end" are reserved or invalid`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svcExport := &fleetnetv1alpha1.ServiceExport{Spec: tc.spec}
+			gotLabels, gotAnnotations, err := extractExportedMetadata(svcExport)
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("extractExportedMetadata() got error %v, want no error", err)
+			}
+			if tc.wantErr != "" && (err == nil || err.Error() != tc.wantErr) {
+				t.Fatalf("extractExportedMetadata() got error %v, want error %q", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.wantLabels, gotLabels); diff != "" {
+				t.Errorf("extractExportedMetadata() labels mismatch (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantAnnotations, gotAnnotations); diff != "" {
+				t.Errorf("extractExportedMetadata() annotations mismatch (-want, +got):\n%s", diff)
+			}
+		})
 	}
-	return nil, errors.New("invalid resource group")
 }
@@ -7,30 +7,47 @@ package serviceexport
 
 import (
 	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/uniquename"
 )
 
+// maxSessionAffinityTimeoutSeconds is the maximum client IP based session affinity timeout allowed by Kubernetes.
+const maxSessionAffinityTimeoutSeconds int32 = 86400
+
 // formatInternalServiceExportName returns the unique name assigned to an exported Service.
 func formatInternalServiceExportName(svcExport *fleetnetv1alpha1.ServiceExport) string {
-	return fmt.Sprintf("%s-%s", svcExport.Namespace, svcExport.Name)
+	return uniquename.ClusterScopedDeterministicName(svcExport.Namespace, svcExport.Name)
 }
 
-// isServiceEligibleForExport returns if a Service is eligible for export; at this stage, headless Services
-// and Services of the ExternalName type cannot be exported.
+// isServiceEligibleForExport returns if a Service is eligible for export; at this stage, Services of the
+// ExternalName type cannot be exported.
 func isServiceEligibleForExport(svc *corev1.Service) bool {
-	if svc.Spec.Type == corev1.ServiceTypeExternalName || svc.Spec.ClusterIP == "None" {
-		return false
-	}
-	return true
+	return svc.Spec.Type != corev1.ServiceTypeExternalName
 }
 
-// extractServicePorts extracts ports in use from Service.
-func extractServicePorts(svc *corev1.Service) []fleetnetv1alpha1.ServicePort {
+// isHeadlessService returns if a Service is headless, i.e. it has no cluster IP allocated.
+func isHeadlessService(svc *corev1.Service) bool {
+	return svc.Spec.ClusterIP == corev1.ClusterIPNone
+}
+
+// extractServicePorts extracts the ports in use from Service which are selected for export.
+func extractServicePorts(svc *corev1.Service, svcExportSpec *fleetnetv1alpha1.ServiceExportSpec) []fleetnetv1alpha1.ServicePort {
 	svcExportPorts := []fleetnetv1alpha1.ServicePort{}
 	for _, svcPort := range svc.Spec.Ports {
+		if !svcExportSpec.SelectsPort(svcPort) {
+			continue
+		}
 		svcExportPorts = append(svcExportPorts, fleetnetv1alpha1.ServicePort{
 			Name:        svcPort.Name,
 			Protocol:    svcPort.Protocol,
@@ -42,3 +59,185 @@ func extractServicePorts(svc *corev1.Service) []fleetnetv1alpha1.ServicePort {
 
 	return svcExportPorts
 }
+
+// extractLoadBalancerMetadata extracts the load balancer related settings from a Service; these settings are
+// informational only and are left unset for Services not of the LoadBalancer type.
+func extractLoadBalancerMetadata(svc *corev1.Service) (allocateLoadBalancerNodePorts *bool, loadBalancerIP string) {
+	if svc.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		return nil, ""
+	}
+	if svc.Spec.AllocateLoadBalancerNodePorts != nil {
+		allocate := *svc.Spec.AllocateLoadBalancerNodePorts
+		allocateLoadBalancerNodePorts = &allocate
+	}
+	return allocateLoadBalancerNodePorts, svc.Spec.LoadBalancerIP
+}
+
+// extractLoadBalancerIngress extracts the IP addresses, or the hostnames if there are no IP addresses, of the load
+// balancer ingress points of a Service.
+func extractLoadBalancerIngress(svc *corev1.Service) []string {
+	if svc.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		return nil
+	}
+	var ingress []string
+	for _, i := range svc.Status.LoadBalancer.Ingress {
+		switch {
+		case i.IP != "":
+			ingress = append(ingress, i.IP)
+		case i.Hostname != "":
+			ingress = append(ingress, i.Hostname)
+		}
+	}
+	return ingress
+}
+
+// extractIPFamilyMetadata extracts the IP family settings from a Service, so that consuming clusters can tell
+// single-stack exports apart from dual-stack ones.
+func extractIPFamilyMetadata(svc *corev1.Service) (*corev1.IPFamilyPolicy, []corev1.IPFamily) {
+	var ipFamilyPolicy *corev1.IPFamilyPolicy
+	if svc.Spec.IPFamilyPolicy != nil {
+		policy := *svc.Spec.IPFamilyPolicy
+		ipFamilyPolicy = &policy
+	}
+	var ipFamilies []corev1.IPFamily
+	if len(svc.Spec.IPFamilies) > 0 {
+		ipFamilies = append([]corev1.IPFamily{}, svc.Spec.IPFamilies...)
+	}
+	return ipFamilyPolicy, ipFamilies
+}
+
+// extractDNSTTL extracts the DNS TTL hint from the annotation of a ServiceExport; it returns nil if the
+// annotation is absent, and an error if the value is not a positive integer.
+func extractDNSTTL(svcExport *fleetnetv1alpha1.ServiceExport) (*int64, error) {
+	val, ok := svcExport.Annotations[objectmeta.ServiceExportAnnotationDNSTTL]
+	if !ok {
+		return nil, nil
+	}
+	ttl, err := strconv.ParseInt(val, 10, 64)
+	if err != nil || ttl <= 0 {
+		return nil, fmt.Errorf("the value of annotation %s must be a positive integer, got %q", objectmeta.ServiceExportAnnotationDNSTTL, val)
+	}
+	return &ttl, nil
+}
+
+// extractCanaryPercent extracts the canary percentage from the annotation of a ServiceExport, clamped to the
+// range [0, 100]; it returns nil if the annotation is absent, and an error if the value is not an integer.
+func extractCanaryPercent(svcExport *fleetnetv1alpha1.ServiceExport) (*int32, error) {
+	val, ok := svcExport.Annotations[objectmeta.ServiceExportAnnotationCanaryPercent]
+	if !ok {
+		return nil, nil
+	}
+	percent, err := strconv.ParseInt(val, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("the value of annotation %s must be an integer, got %q", objectmeta.ServiceExportAnnotationCanaryPercent, val)
+	}
+	clamped := int32(min(max(percent, 0), 100))
+	return &clamped, nil
+}
+
+// extractSessionAffinity extracts the session affinity settings from a Service; the timeout is only returned for
+// client IP based session affinity. It returns an error, along with a nil timeout, if the timeout is out of the
+// range allowed by Kubernetes.
+func extractSessionAffinity(svc *corev1.Service) (corev1.ServiceAffinity, *int32, error) {
+	if svc.Spec.SessionAffinity != corev1.ServiceAffinityClientIP {
+		return svc.Spec.SessionAffinity, nil, nil
+	}
+	cfg := svc.Spec.SessionAffinityConfig
+	if cfg == nil || cfg.ClientIP == nil || cfg.ClientIP.TimeoutSeconds == nil {
+		return corev1.ServiceAffinityClientIP, nil, nil
+	}
+	timeout := *cfg.ClientIP.TimeoutSeconds
+	if timeout <= 0 || timeout > maxSessionAffinityTimeoutSeconds {
+		return corev1.ServiceAffinityClientIP, nil, fmt.Errorf("the session affinity timeout must be in the range (0, %d], got %d",
+			maxSessionAffinityTimeoutSeconds, timeout)
+	}
+	return corev1.ServiceAffinityClientIP, &timeout, nil
+}
+
+// extractHealthCheckAnnotations extracts the annotations with the given keys from a Service. The annotations whose
+// keys end with "path" are only extracted if their values are well-formed URL paths; it returns an error listing
+// the dropped annotations, along with the others.
+func extractHealthCheckAnnotations(svc *corev1.Service, keys []string) (map[string]string, error) {
+	var annotations map[string]string
+	var invalid []string
+	for _, key := range keys {
+		val, ok := svc.Annotations[key]
+		if !ok {
+			continue
+		}
+		if strings.HasSuffix(strings.ToLower(key), "path") && !isURLPath(val) {
+			invalid = append(invalid, fmt.Sprintf("%s=%q", key, val))
+			continue
+		}
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[key] = val
+	}
+	if len(invalid) > 0 {
+		return annotations, fmt.Errorf("the values of annotations %s must be well-formed URL paths", strings.Join(invalid, ", "))
+	}
+	return annotations, nil
+}
+
+// extractExportedMetadata extracts the labels and annotations a ServiceExport declares to merge onto the
+// ServiceImport. The reserved keys, invalid keys, and invalid label values are dropped; it returns an error listing
+// the dropped entries, along with the others.
+func extractExportedMetadata(svcExport *fleetnetv1alpha1.ServiceExport) (exportedLabels, exportedAnnotations map[string]string, err error) {
+	var invalid []string
+	for key, val := range svcExport.Spec.ExportedLabels {
+		if objectmeta.IsReservedKey(key) || len(validation.IsQualifiedName(key)) != 0 || len(validation.IsValidLabelValue(val)) != 0 {
+			invalid = append(invalid, fmt.Sprintf("label %s=%q", key, val))
+			continue
+		}
+		if exportedLabels == nil {
+			exportedLabels = make(map[string]string)
+		}
+		exportedLabels[key] = val
+	}
+	for key, val := range svcExport.Spec.ExportedAnnotations {
+		if objectmeta.IsReservedKey(key) || len(validation.IsQualifiedName(key)) != 0 {
+			invalid = append(invalid, fmt.Sprintf("annotation %s", key))
+			continue
+		}
+		if exportedAnnotations == nil {
+			exportedAnnotations = make(map[string]string)
+		}
+		exportedAnnotations[key] = val
+	}
+	if len(invalid) > 0 {
+		sort.Strings(invalid)
+		return exportedLabels, exportedAnnotations, fmt.Errorf("the exported %s are reserved or invalid", strings.Join(invalid, ", "))
+	}
+	return exportedLabels, exportedAnnotations, nil
+}
+
+// isURLPath returns if a string is a well-formed, properly escaped absolute URL path, without a scheme, host,
+// query or fragment.
+func isURLPath(s string) bool {
+	if !strings.HasPrefix(s, "/") {
+		return false
+	}
+	u, err := url.Parse(s)
+	return err == nil && u.EscapedPath() == s
+}
+
+// isSystemManagedUpdate returns if an update to a ServiceExport changes only the fields that the system manages,
+// specifically managedFields, resourceVersion, and the last transition time of the status conditions; such
+// updates are often caused by the controller's own status writes and need no further reconciliation.
+//
+// Any other change, e.g. a condition switching its status or reason, is considered a genuine one.
+func isSystemManagedUpdate(oldSvcExport, newSvcExport *fleetnetv1alpha1.ServiceExport) bool {
+	return equality.Semantic.DeepEqual(stripSystemManagedFields(oldSvcExport), stripSystemManagedFields(newSvcExport))
+}
+
+// stripSystemManagedFields returns a copy of a ServiceExport with the system managed fields cleared.
+func stripSystemManagedFields(svcExport *fleetnetv1alpha1.ServiceExport) *fleetnetv1alpha1.ServiceExport {
+	stripped := svcExport.DeepCopy()
+	stripped.ResourceVersion = ""
+	stripped.ManagedFields = nil
+	for i := range stripped.Status.Conditions {
+		stripped.Status.Conditions[i].LastTransitionTime = metav1.Time{}
+	}
+	return stripped
+}
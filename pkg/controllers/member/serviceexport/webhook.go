@@ -0,0 +1,96 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package serviceexport
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+// maxExportNameLength is the maximum length of the name of the hub objects of an export, in the format of
+// [NAMESPACE]-[NAME]; longer names are truncated and hashed in the hub, which makes the hub objects hard to trace
+// back to their Services, and are rejected at admission time instead.
+const maxExportNameLength = validation.DNS1123LabelMaxLength
+
+// Validator validates ServiceExports at admission time, so that exports which can never succeed are rejected
+// upfront rather than reported later in their conditions.
+type Validator struct {
+	// Reader reads the Services being exported.
+	Reader client.Reader
+	// ReservedNamespaces are the namespaces whose Services cannot be exported, e.g. kube-system.
+	ReservedNamespaces []string
+}
+
+//+kubebuilder:webhook:path=/validate-networking-fleet-azure-com-v1alpha1-serviceexport,mutating=false,failurePolicy=fail,sideEffects=None,groups=networking.fleet.azure.com,resources=serviceexports,verbs=create,versions=v1alpha1,name=vserviceexport.networking.fleet.azure.com,admissionReviewVersions=v1
+
+var _ admission.CustomValidator = &Validator{}
+
+// ValidateCreate implements admission.CustomValidator.
+func (v *Validator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	svcExport, ok := obj.(*fleetnetv1alpha1.ServiceExport)
+	if !ok {
+		return nil, fmt.Errorf("expected a ServiceExport, got %T", obj)
+	}
+	return v.validate(ctx, svcExport)
+}
+
+// ValidateUpdate implements admission.CustomValidator; the namespace and the name of a ServiceExport are
+// immutable, and existing ServiceExports must remain updatable, e.g. to have their finalizers removed.
+func (v *Validator) ValidateUpdate(_ context.Context, _, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// ValidateDelete implements admission.CustomValidator; ServiceExports can always be deleted.
+func (v *Validator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate returns an error if the ServiceExport exports a Service which cannot be exported.
+func (v *Validator) validate(ctx context.Context, svcExport *fleetnetv1alpha1.ServiceExport) (admission.Warnings, error) {
+	for _, ns := range v.ReservedNamespaces {
+		if svcExport.Namespace == ns {
+			return nil, fmt.Errorf("services in the reserved namespace %s cannot be exported", ns)
+		}
+	}
+	if name := svcExport.Namespace + "-" + svcExport.Name; len(name) > maxExportNameLength {
+		return nil, fmt.Errorf("the namespace and the name of the service are too long: %q must be no more than %d characters", name, maxExportNameLength)
+	}
+
+	// The Service may be created after the ServiceExport, in which case it is validated by the serviceexport
+	// controller instead.
+	svc := &corev1.Service{}
+	if err := v.Reader.Get(ctx, types.NamespacedName{Namespace: svcExport.Namespace, Name: svcExport.Name}, svc); err != nil {
+		if errors.IsNotFound(err) {
+			return admission.Warnings{fmt.Sprintf("service %s/%s does not exist yet", svcExport.Namespace, svcExport.Name)}, nil
+		}
+		klog.ErrorS(err, "Failed to get service", "service", klog.KObj(svcExport))
+		return nil, fmt.Errorf("failed to get service %s/%s: %w", svcExport.Namespace, svcExport.Name, err)
+	}
+	if !isServiceEligibleForExport(svc) {
+		return nil, fmt.Errorf("services of the %s type cannot be exported", svc.Spec.Type)
+	}
+	return nil, nil
+}
+
+// SetupWebhookWithManager registers the validating webhook of ServiceExports with the manager.
+func (v *Validator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&fleetnetv1alpha1.ServiceExport{}).
+		WithValidator(v).
+		Complete()
+}
@@ -0,0 +1,103 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package serviceexport
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+// TestValidator_ValidateCreate tests the Validator.ValidateCreate method.
+func TestValidator_ValidateCreate(t *testing.T) {
+	svcExport := func(namespace, name string) *fleetnetv1alpha1.ServiceExport {
+		return &fleetnetv1alpha1.ServiceExport{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	}
+	svc := func(namespace, name string, svcType corev1.ServiceType) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec:       corev1.ServiceSpec{Type: svcType},
+		}
+	}
+	longName := strings.Repeat("a", 60)
+
+	testCases := []struct {
+		name         string
+		svcExport    *fleetnetv1alpha1.ServiceExport
+		svc          *corev1.Service
+		wantWarnings admission.Warnings
+		wantErr      string
+	}{
+		{
+			name:      "service of the ClusterIP type",
+			svcExport: svcExport(memberUserNS, svcName),
+			svc:       svc(memberUserNS, svcName, corev1.ServiceTypeClusterIP),
+		},
+		{
+			name:      "service of the ExternalName type",
+			svcExport: svcExport(memberUserNS, svcName),
+			svc:       svc(memberUserNS, svcName, corev1.ServiceTypeExternalName),
+			wantErr:   "services of the ExternalName type cannot be exported",
+		},
+		{
+			name:         "service does not exist yet",
+			svcExport:    svcExport(memberUserNS, svcName),
+			wantWarnings: admission.Warnings{"service work/app does not exist yet"},
+		},
+		{
+			name:      "service in kube-system",
+			svcExport: svcExport("kube-system", svcName),
+			svc:       svc("kube-system", svcName, corev1.ServiceTypeClusterIP),
+			wantErr:   "services in the reserved namespace kube-system cannot be exported",
+		},
+		{
+			name:      "service in fleet-system",
+			svcExport: svcExport("fleet-system", svcName),
+			wantErr:   "services in the reserved namespace fleet-system cannot be exported",
+		},
+		{
+			name:      "namespace and name too long",
+			svcExport: svcExport(memberUserNS, longName),
+			svc:       svc(memberUserNS, longName, corev1.ServiceTypeClusterIP),
+			wantErr:   "must be no more than 63 characters",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := corev1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			builder := fake.NewClientBuilder().WithScheme(scheme)
+			if tc.svc != nil {
+				builder = builder.WithObjects(tc.svc)
+			}
+			v := &Validator{
+				Reader:             builder.Build(),
+				ReservedNamespaces: []string{"kube-system", "fleet-system"},
+			}
+			gotWarnings, err := v.ValidateCreate(context.Background(), tc.svcExport)
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("ValidateCreate() got error %v, want no error", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Fatalf("ValidateCreate() got error %v, want error containing %q", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.wantWarnings, gotWarnings); diff != "" {
+				t.Errorf("ValidateCreate() warnings mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
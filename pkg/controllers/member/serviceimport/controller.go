@@ -13,18 +13,31 @@ import (
 	"time"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/hubconfig"
+	"go.goms.io/fleet-networking/pkg/common/importpolicy"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/pkg/common/pause"
 )
 
 const (
-	ServiceImportFinalizer = "networking.fleet.azure.com/serviceimport-cleanup"
+	// importDeniedReason is the reason of the Denied condition of the ServiceImports denied by an ImportPolicy.
+	importDeniedReason = "ImportPolicyDenied"
+
+	// ServiceImportFinalizer is the default finalizer added to ServiceImports to delete their
+	// InternalServiceImports before they are deleted.
+	ServiceImportFinalizer = objectmeta.ServiceImportCleanupFinalizer
 )
 
 // Reconciler reconciles a InternalServceImport object.
@@ -34,35 +47,76 @@ type Reconciler struct {
 	// The namespace reserved for the current member cluster in the hub cluster.
 	HubNamespace string
 
+	// HubName is the name of the additional hub cluster the controller imports the services from, or empty for the
+	// primary hub cluster; only the ServiceImports annotated with it are imported.
+	HubName string
+
 	HubClient    client.Client
 	MemberClient client.Client
+
+	// Finalizer is the finalizer added to ServiceImports to delete their InternalServiceImports before they are
+	// deleted; ServiceImportFinalizer is used if empty.
+	Finalizer string
+
+	// EnforceImportPolicies makes the controller leave the ServiceImports denied by the ImportPolicies of the member
+	// cluster unimported; the ImportPolicy CRD must be installed in the member cluster.
+	EnforceImportPolicies bool
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
 }
 
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports,verbs=get;list;watch;update;patch
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports/finalizers,verbs=get;update
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalserviceimports,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=importpolicies,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
 
 // Reconcile in member cluster creates hub cluster internal service import out of member cluster service import.
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	// fetch serviceimport in member cluster
 	serviceImport := &fleetnetv1alpha1.ServiceImport{}
 	serviceImportRef := klog.KRef(req.Namespace, req.Name)
+	logger := klog.FromContext(ctx).WithValues("serviceImport", serviceImportRef)
+	ctx = klog.NewContext(ctx, logger)
 	startTime := time.Now()
-	klog.V(2).InfoS("Reconciliation starts", "serviceImport", serviceImportRef)
+	logger.V(2).Info("Reconciliation starts")
 	defer func() {
 		latency := time.Since(startTime).Milliseconds()
-		klog.V(2).InfoS("Reconciliation ends", "serviceImport", serviceImportRef, "latency", latency)
+		logger.V(2).Info("Reconciliation ends", "latency", latency)
 	}()
 
 	if err := r.MemberClient.Get(ctx, req.NamespacedName, serviceImport); err != nil {
 		if errors.IsNotFound(err) {
-			klog.V(4).InfoS("Ignoring NotFound serviceImport", "serviceImport", serviceImportRef)
+			logger.V(4).Info("Ignoring NotFound serviceImport")
 			return ctrl.Result{}, nil
 		}
-		klog.ErrorS(err, "Failed to get serviceImport", "serviceImport", serviceImportRef)
+		logger.Error(err, "Failed to get serviceImport")
 		return reconcile.Result{}, err
 	}
 
+	if hub := hubconfig.ImportingHub(serviceImport); hub != r.HubName {
+		// The ServiceImport is imported from another hub cluster; the import from this one is withdrawn in case
+		// the ServiceImport is moved away from it.
+		logger.V(4).Info("ServiceImport is imported from another hub cluster", "hub", hub)
+		return ctrl.Result{}, r.withdrawFromHub(ctx, serviceImport)
+	}
+
+	// Leave the ServiceImport, and the Service as it has been imported, as it is while its reconciliation is
+	// paused; the Paused condition is removed as soon as the reconciliation resumes.
+	paused := pause.IsPaused(serviceImport)
+	if pause.SetCondition(&serviceImport.Status.Conditions, string(fleetnetv1alpha1.ServiceImportPaused), paused, serviceImport.Generation) {
+		if err := r.MemberClient.Status().Update(ctx, serviceImport); err != nil {
+			logger.Error(err, "Failed to update the paused condition of serviceImport")
+			return ctrl.Result{}, err
+		}
+	}
+	if paused {
+		logger.V(2).Info("Reconciliation of serviceImport is paused")
+		return ctrl.Result{}, nil
+	}
+
 	internalServiceImportName := formatInternalServiceImportName(serviceImport)
 	internalServiceImport := &fleetnetv1alpha1.InternalServiceImport{
 		ObjectMeta: metav1.ObjectMeta{
@@ -72,39 +126,49 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	}
 	internalServiceImportRef := klog.KObj(internalServiceImport)
 
+	finalizer := r.finalizer()
 	// Examine DeletionTimestamp to determine if service import is under deletion.
 	if serviceImport.ObjectMeta.DeletionTimestamp != nil {
-		// When finalizer is not found, we can return early as the cleanup work should have been done.
-		if !controllerutil.ContainsFinalizer(serviceImport, ServiceImportFinalizer) {
-			return ctrl.Result{}, nil
-		}
-
 		// Delete service import dependency when the finalizer is expected then remove the finalizer from service import.
-		if err := r.HubClient.Delete(ctx, internalServiceImport); err != nil {
-			klog.ErrorS(err, "Failed to delete internalserviceimport as required by serviceimport finalizer", "InternalServiceImport", internalServiceImportRef, "ServiceImport", serviceImportRef, "finalizer", ServiceImportFinalizer)
-			if !errors.IsNotFound(err) {
-				return ctrl.Result{}, err
-			}
+		// Stop reconciliation as the item is being deleted
+		return ctrl.Result{}, r.withdrawFromHub(ctx, serviceImport)
+	}
+
+	// Withdraw the import of a ServiceImport denied by the ImportPolicies, so that the hub cluster no longer
+	// distributes its endpoints to the member cluster; the Denied condition is removed as soon as it is allowed.
+	deniedReason := ""
+	if r.EnforceImportPolicies {
+		var err error
+		if deniedReason, err = importpolicy.Check(ctx, r.MemberClient, serviceImport); err != nil {
+			logger.Error(err, "Failed to evaluate the import policies")
+			return ctrl.Result{}, err
 		}
-		controllerutil.RemoveFinalizer(serviceImport, ServiceImportFinalizer)
-		if err := r.MemberClient.Update(ctx, serviceImport); err != nil {
-			klog.ErrorS(err, "Failed to remove serviceimport finalizer", "ServiceImport", serviceImportRef, "finalizer", ServiceImportFinalizer)
+	}
+	if setDeniedCondition(serviceImport, deniedReason) {
+		if err := r.MemberClient.Status().Update(ctx, serviceImport); err != nil {
+			logger.Error(err, "Failed to update the denied condition of serviceImport")
+			return ctrl.Result{}, err
+		}
+	}
+	if deniedReason != "" {
+		logger.V(2).Info("ServiceImport is denied by the import policies", "reason", deniedReason)
+		if err := r.HubClient.Delete(ctx, internalServiceImport); client.IgnoreNotFound(err) != nil {
+			logger.Error(err, "Failed to delete the internalServiceImport of the denied serviceImport", "InternalServiceImport", internalServiceImportRef)
 			return ctrl.Result{}, err
 		}
-		// Stop reconciliation as the item is being deleted
 		return ctrl.Result{}, nil
 	}
 
 	// Add finalizer when it's in service import when not being deleted
-	if !controllerutil.ContainsFinalizer(serviceImport, ServiceImportFinalizer) {
-		controllerutil.AddFinalizer(serviceImport, ServiceImportFinalizer)
+	if !controllerutil.ContainsFinalizer(serviceImport, finalizer) {
+		controllerutil.AddFinalizer(serviceImport, finalizer)
 		if err := r.MemberClient.Update(ctx, serviceImport); err != nil {
-			klog.ErrorS(err, "Failed to add serviceimport finalizer", "ServiceImport", serviceImportRef, "finalizer", ServiceImportFinalizer)
+			logger.Error(err, "Failed to add serviceimport finalizer", "finalizer", finalizer)
 			return ctrl.Result{}, err
 		}
 	}
 
-	klog.V(2).InfoS("Create or update internal service import", "InternalServiceImport", internalServiceImportRef)
+	logger.V(2).Info("Create or update internal service import", "InternalServiceImport", internalServiceImportRef)
 	if op, err := controllerutil.CreateOrUpdate(ctx, r.HubClient, internalServiceImport, func() error {
 		if internalServiceImport.CreationTimestamp.IsZero() {
 			// Set the ServiceReference only when the InternalServiceImport is created; most of the fields in
@@ -117,7 +181,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		internalServiceImport.Spec.ServiceImportReference.UpdateFromMetaObject(serviceImport.ObjectMeta, serviceImport.CreationTimestamp)
 		return nil
 	}); err != nil {
-		klog.ErrorS(err, "Failed to create or update InternalServiceImport from ServiceImport", "InternalServiceImport", internalServiceImportRef, "ServiceImport", serviceImportRef, "op", op)
+		logger.Error(err, "Failed to create or update InternalServiceImport from ServiceImport", "InternalServiceImport", internalServiceImportRef, "op", op)
 		return ctrl.Result{}, err
 	}
 
@@ -125,9 +189,94 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 }
 
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	// Each hub cluster has a controller of its own.
+	name := "serviceimport"
+	if r.HubName != "" {
+		name += "-" + r.HubName
+	}
+	builder := ctrl.NewControllerManagedBy(mgr).
+		Named(name).
 		For(&fleetnetv1alpha1.ServiceImport{}).
-		Complete(r)
+		WithOptions(r.ControllerOptions)
+	if r.EnforceImportPolicies {
+		// Re-evaluate all the ServiceImports as soon as an ImportPolicy changes.
+		builder = builder.Watches(&fleetnetv1alpha1.ImportPolicy{}, handler.EnqueueRequestsFromMapFunc(r.allServiceImports))
+	}
+	return builder.Complete(metrics.InstrumentReconciler(name, r))
+}
+
+// allServiceImports returns the requests of all the ServiceImports of the member cluster.
+func (r *Reconciler) allServiceImports(ctx context.Context, _ client.Object) []reconcile.Request {
+	svcImportList := &fleetnetv1alpha1.ServiceImportList{}
+	if err := r.MemberClient.List(ctx, svcImportList); err != nil {
+		klog.FromContext(ctx).Error(err, "Failed to list serviceImports")
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(svcImportList.Items))
+	for i := range svcImportList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&svcImportList.Items[i])})
+	}
+	return requests
+}
+
+// setDeniedCondition sets the Denied condition of the ServiceImport if it is denied for the given reason, or removes
+// it if the reason is empty; it returns true if the conditions are changed.
+func setDeniedCondition(serviceImport *fleetnetv1alpha1.ServiceImport, reason string) bool {
+	conditionType := string(fleetnetv1alpha1.ServiceImportDenied)
+	if reason == "" {
+		return meta.RemoveStatusCondition(&serviceImport.Status.Conditions, conditionType)
+	}
+	return meta.SetStatusCondition(&serviceImport.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: serviceImport.Generation,
+		Reason:             importDeniedReason,
+		Message:            reason,
+	})
+}
+
+// withdrawFromHub deletes the InternalServiceImport of the ServiceImport from the hub cluster and removes the
+// finalizer of the controller from the ServiceImport, if it has been imported from the hub cluster.
+func (r *Reconciler) withdrawFromHub(ctx context.Context, serviceImport *fleetnetv1alpha1.ServiceImport) error {
+	logger := klog.FromContext(ctx)
+	finalizer := r.finalizer()
+	// When finalizer is not found, we can return early as the cleanup work should have been done; the default
+	// finalizer counts as well for the primary hub cluster, as the service import may have been given it before the
+	// finalizer name was configured.
+	legacyFinalizer := r.HubName == "" && controllerutil.ContainsFinalizer(serviceImport, ServiceImportFinalizer)
+	if !controllerutil.ContainsFinalizer(serviceImport, finalizer) && !legacyFinalizer {
+		return nil
+	}
+
+	internalServiceImport := &fleetnetv1alpha1.InternalServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      formatInternalServiceImportName(serviceImport),
+			Namespace: r.HubNamespace,
+		},
+	}
+	if err := r.HubClient.Delete(ctx, internalServiceImport); err != nil {
+		logger.Error(err, "Failed to delete internalserviceimport as required by serviceimport finalizer", "InternalServiceImport", klog.KObj(internalServiceImport), "finalizer", finalizer)
+		if !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	controllerutil.RemoveFinalizer(serviceImport, finalizer)
+	if r.HubName == "" {
+		controllerutil.RemoveFinalizer(serviceImport, ServiceImportFinalizer)
+	}
+	if err := r.MemberClient.Update(ctx, serviceImport); err != nil {
+		logger.Error(err, "Failed to remove serviceimport finalizer", "finalizer", finalizer)
+		return err
+	}
+	return nil
+}
+
+// finalizer returns the name of the finalizer added to ServiceImports.
+func (r *Reconciler) finalizer() string {
+	if r.Finalizer != "" {
+		return r.Finalizer
+	}
+	return ServiceImportFinalizer
 }
 
 // formatInternalServiceImportName returns the unique name assigned to an service import
@@ -0,0 +1,343 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package serviceimport
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+)
+
+const (
+	testMemberClusterID = "member-1"
+	testHubNamespace    = "fleet-member-member-1"
+	testSvcNamespace    = "work"
+	testServiceName     = "app"
+	customFinalizer     = "example.com/serviceimport-cleanup"
+)
+
+// TestReconcile_Finalizer tests that the configured finalizer is added to a ServiceImport and removed from it
+// through a create/delete lifecycle.
+func TestReconcile_Finalizer(t *testing.T) {
+	testCases := []struct {
+		name           string
+		finalizer      string
+		existing       []string
+		wantFinalizers []string
+	}{
+		{
+			name:           "default finalizer",
+			wantFinalizers: []string{ServiceImportFinalizer},
+		},
+		{
+			name:           "custom finalizer",
+			finalizer:      customFinalizer,
+			wantFinalizers: []string{customFinalizer},
+		},
+		{
+			name:           "custom finalizer with the default one added before the change",
+			finalizer:      customFinalizer,
+			existing:       []string{ServiceImportFinalizer},
+			wantFinalizers: []string{ServiceImportFinalizer, customFinalizer},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			serviceImport := &fleetnetv1alpha1.ServiceImport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:  testSvcNamespace,
+					Name:       testServiceName,
+					Finalizers: tc.existing,
+				},
+			}
+			memberClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(serviceImport).Build()
+			hubClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+			r := &Reconciler{
+				MemberClusterID: testMemberClusterID,
+				HubNamespace:    testHubNamespace,
+				MemberClient:    memberClient,
+				HubClient:       hubClient,
+				Finalizer:       tc.finalizer,
+			}
+			key := types.NamespacedName{Namespace: testSvcNamespace, Name: testServiceName}
+			internalSvcImportKey := types.NamespacedName{Namespace: testHubNamespace, Name: testSvcNamespace + "-" + testServiceName}
+
+			// Create: the finalizer is added before the import is created in the hub cluster.
+			if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+			got := &fleetnetv1alpha1.ServiceImport{}
+			if err := memberClient.Get(ctx, key, got); err != nil {
+				t.Fatalf("ServiceImport Get() = %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.wantFinalizers, got.Finalizers); diff != "" {
+				t.Errorf("ServiceImport finalizers mismatch (-want, +got):\n%s", diff)
+			}
+			if err := hubClient.Get(ctx, internalSvcImportKey, &fleetnetv1alpha1.InternalServiceImport{}); err != nil {
+				t.Fatalf("InternalServiceImport Get() = %v, want no error", err)
+			}
+
+			// Delete: the import is withdrawn and all the finalizers are removed, so that the deletion completes.
+			if err := memberClient.Delete(ctx, got); err != nil {
+				t.Fatalf("ServiceImport Delete() = %v, want no error", err)
+			}
+			if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+			if err := memberClient.Get(ctx, key, got); !errors.IsNotFound(err) {
+				t.Errorf("ServiceImport Get() = %v, want NotFound", err)
+			}
+			if err := hubClient.Get(ctx, internalSvcImportKey, &fleetnetv1alpha1.InternalServiceImport{}); !errors.IsNotFound(err) {
+				t.Errorf("InternalServiceImport Get() = %v, want NotFound", err)
+			}
+		})
+	}
+}
+
+// TestReconcile_Paused tests that a ServiceImport is not imported while its reconciliation is paused, and is
+// imported as soon as it resumes.
+func TestReconcile_Paused(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   testSvcNamespace,
+			Name:        testServiceName,
+			Annotations: map[string]string{objectmeta.ObjectAnnotationPaused: "true"},
+		},
+	}
+	memberClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(serviceImport).
+		WithStatusSubresource(serviceImport).
+		Build()
+	hubClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	r := &Reconciler{
+		MemberClusterID: testMemberClusterID,
+		HubNamespace:    testHubNamespace,
+		MemberClient:    memberClient,
+		HubClient:       hubClient,
+	}
+	key := types.NamespacedName{Namespace: testSvcNamespace, Name: testServiceName}
+	internalSvcImportKey := types.NamespacedName{Namespace: testHubNamespace, Name: testSvcNamespace + "-" + testServiceName}
+
+	// Paused: the pause is reported and the import is not created in the hub cluster.
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile() = %v, want no error", err)
+	}
+	got := &fleetnetv1alpha1.ServiceImport{}
+	if err := memberClient.Get(ctx, key, got); err != nil {
+		t.Fatalf("ServiceImport Get() = %v, want no error", err)
+	}
+	if !meta.IsStatusConditionTrue(got.Status.Conditions, string(fleetnetv1alpha1.ServiceImportPaused)) {
+		t.Errorf("ServiceImport conditions = %+v, want the paused condition", got.Status.Conditions)
+	}
+	if err := hubClient.Get(ctx, internalSvcImportKey, &fleetnetv1alpha1.InternalServiceImport{}); !errors.IsNotFound(err) {
+		t.Errorf("InternalServiceImport Get() = %v, want NotFound", err)
+	}
+
+	// Resumed: the pause is no longer reported and the import is created in the hub cluster.
+	delete(got.Annotations, objectmeta.ObjectAnnotationPaused)
+	if err := memberClient.Update(ctx, got); err != nil {
+		t.Fatalf("ServiceImport Update() = %v, want no error", err)
+	}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile() = %v, want no error", err)
+	}
+	if err := memberClient.Get(ctx, key, got); err != nil {
+		t.Fatalf("ServiceImport Get() = %v, want no error", err)
+	}
+	if cond := meta.FindStatusCondition(got.Status.Conditions, string(fleetnetv1alpha1.ServiceImportPaused)); cond != nil {
+		t.Errorf("ServiceImport paused condition = %+v, want nil", cond)
+	}
+	if err := hubClient.Get(ctx, internalSvcImportKey, &fleetnetv1alpha1.InternalServiceImport{}); err != nil {
+		t.Errorf("InternalServiceImport Get() = %v, want no error", err)
+	}
+}
+
+// TestReconcile_AdditionalHub tests that a ServiceImport is only imported from the hub cluster it is annotated with,
+// and that its import is withdrawn from the primary hub cluster once it is moved to an additional one.
+func TestReconcile_AdditionalHub(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testSvcNamespace,
+			Name:      testServiceName,
+		},
+	}
+	memberClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(serviceImport).Build()
+	primaryHubClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	additionalHubClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	primary := &Reconciler{
+		MemberClusterID: testMemberClusterID,
+		HubNamespace:    testHubNamespace,
+		MemberClient:    memberClient,
+		HubClient:       primaryHubClient,
+	}
+	additionalFinalizer := ServiceImportFinalizer + "-west"
+	additional := &Reconciler{
+		MemberClusterID: testMemberClusterID,
+		HubNamespace:    testHubNamespace,
+		HubName:         "west",
+		MemberClient:    memberClient,
+		HubClient:       additionalHubClient,
+		Finalizer:       additionalFinalizer,
+	}
+	key := types.NamespacedName{Namespace: testSvcNamespace, Name: testServiceName}
+	internalSvcImportKey := types.NamespacedName{Namespace: testHubNamespace, Name: testSvcNamespace + "-" + testServiceName}
+
+	reconcileAll := func() {
+		for _, r := range []*Reconciler{primary, additional} {
+			if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+		}
+	}
+
+	// Not annotated: the service is imported from the primary hub cluster only.
+	reconcileAll()
+	got := &fleetnetv1alpha1.ServiceImport{}
+	if err := memberClient.Get(ctx, key, got); err != nil {
+		t.Fatalf("ServiceImport Get() = %v, want no error", err)
+	}
+	if diff := cmp.Diff([]string{ServiceImportFinalizer}, got.Finalizers); diff != "" {
+		t.Errorf("ServiceImport finalizers mismatch (-want, +got):\n%s", diff)
+	}
+	if err := primaryHubClient.Get(ctx, internalSvcImportKey, &fleetnetv1alpha1.InternalServiceImport{}); err != nil {
+		t.Errorf("InternalServiceImport Get() from the primary hub = %v, want no error", err)
+	}
+	if err := additionalHubClient.Get(ctx, internalSvcImportKey, &fleetnetv1alpha1.InternalServiceImport{}); !errors.IsNotFound(err) {
+		t.Errorf("InternalServiceImport Get() from the additional hub = %v, want NotFound", err)
+	}
+
+	// Annotated: the service is moved to the additional hub cluster.
+	got.Annotations = map[string]string{objectmeta.ServiceImportAnnotationHub: "west"}
+	if err := memberClient.Update(ctx, got); err != nil {
+		t.Fatalf("ServiceImport Update() = %v, want no error", err)
+	}
+	reconcileAll()
+	if err := memberClient.Get(ctx, key, got); err != nil {
+		t.Fatalf("ServiceImport Get() = %v, want no error", err)
+	}
+	if diff := cmp.Diff([]string{additionalFinalizer}, got.Finalizers); diff != "" {
+		t.Errorf("ServiceImport finalizers mismatch (-want, +got):\n%s", diff)
+	}
+	if err := primaryHubClient.Get(ctx, internalSvcImportKey, &fleetnetv1alpha1.InternalServiceImport{}); !errors.IsNotFound(err) {
+		t.Errorf("InternalServiceImport Get() from the primary hub = %v, want NotFound", err)
+	}
+	if err := additionalHubClient.Get(ctx, internalSvcImportKey, &fleetnetv1alpha1.InternalServiceImport{}); err != nil {
+		t.Errorf("InternalServiceImport Get() from the additional hub = %v, want no error", err)
+	}
+}
+
+// TestReconcile_ImportPolicy tests that the import of a ServiceImport is withdrawn once it is denied by an
+// ImportPolicy, and is restored once the policy is deleted.
+func TestReconcile_ImportPolicy(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testSvcNamespace,
+			Name:      testServiceName,
+		},
+	}
+	memberClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(serviceImport).
+		WithStatusSubresource(serviceImport).
+		Build()
+	hubClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	r := &Reconciler{
+		MemberClusterID:       testMemberClusterID,
+		HubNamespace:          testHubNamespace,
+		MemberClient:          memberClient,
+		HubClient:             hubClient,
+		EnforceImportPolicies: true,
+	}
+	key := types.NamespacedName{Namespace: testSvcNamespace, Name: testServiceName}
+	internalSvcImportKey := types.NamespacedName{Namespace: testHubNamespace, Name: testSvcNamespace + "-" + testServiceName}
+
+	// Allowed: the import is created in the hub cluster.
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile() = %v, want no error", err)
+	}
+	if err := hubClient.Get(ctx, internalSvcImportKey, &fleetnetv1alpha1.InternalServiceImport{}); err != nil {
+		t.Fatalf("InternalServiceImport Get() = %v, want no error", err)
+	}
+
+	// Denied: the denial is reported and the import is withdrawn from the hub cluster.
+	policy := &fleetnetv1alpha1.ImportPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "no-work"},
+		Spec: fleetnetv1alpha1.ImportPolicySpec{
+			DeniedNamespaces: &metav1.LabelSelector{MatchLabels: map[string]string{corev1.LabelMetadataName: testSvcNamespace}},
+		},
+	}
+	if err := memberClient.Create(ctx, policy); err != nil {
+		t.Fatalf("ImportPolicy Create() = %v, want no error", err)
+	}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile() = %v, want no error", err)
+	}
+	got := &fleetnetv1alpha1.ServiceImport{}
+	if err := memberClient.Get(ctx, key, got); err != nil {
+		t.Fatalf("ServiceImport Get() = %v, want no error", err)
+	}
+	cond := meta.FindStatusCondition(got.Status.Conditions, string(fleetnetv1alpha1.ServiceImportDenied))
+	if cond == nil || cond.Status != metav1.ConditionTrue || cond.Message != "namespace work is denied by import policy no-work" {
+		t.Errorf("ServiceImport denied condition = %+v, want true with the reason", cond)
+	}
+	if err := hubClient.Get(ctx, internalSvcImportKey, &fleetnetv1alpha1.InternalServiceImport{}); !errors.IsNotFound(err) {
+		t.Errorf("InternalServiceImport Get() = %v, want NotFound", err)
+	}
+
+	// Allowed again: the denial is no longer reported and the import is restored in the hub cluster.
+	if err := memberClient.Delete(ctx, policy); err != nil {
+		t.Fatalf("ImportPolicy Delete() = %v, want no error", err)
+	}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile() = %v, want no error", err)
+	}
+	if err := memberClient.Get(ctx, key, got); err != nil {
+		t.Fatalf("ServiceImport Get() = %v, want no error", err)
+	}
+	if cond := meta.FindStatusCondition(got.Status.Conditions, string(fleetnetv1alpha1.ServiceImportDenied)); cond != nil {
+		t.Errorf("ServiceImport denied condition = %+v, want nil", cond)
+	}
+	if err := hubClient.Get(ctx, internalSvcImportKey, &fleetnetv1alpha1.InternalServiceImport{}); err != nil {
+		t.Errorf("InternalServiceImport Get() = %v, want no error", err)
+	}
+}
@@ -0,0 +1,264 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package tunnel
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+)
+
+const (
+	// persistentKeepalive is the interval in seconds of the keepalives to the peers, which keep the tunnels open
+	// through the NATs and the load balancers in between.
+	persistentKeepalive = 25
+
+	// wireGuardKeyLength is the length in bytes of a WireGuard key.
+	wireGuardKeyLength = 32
+)
+
+// Reconciler reconciles the Secret of the tunnel agent, which keeps its key and its configuration; it watches the
+// InternalMemberNetworkStatus of the member cluster in the hub cluster for the tunnel peers of the other member
+// clusters.
+type Reconciler struct {
+	MemberClusterID string
+	MemberClient    client.Client
+	HubClient       client.Reader
+	// HubNamespace is the namespace reserved for the member cluster in the hub cluster.
+	HubNamespace string
+	// The Secret of the tunnel agent is in the fleet system namespace.
+	FleetSystemNamespace string
+	SecretName           string
+	// Address is the IP address of the tunnel agent within the tunnel network.
+	Address string
+	// Port is the UDP port of the tunnel agent.
+	Port int32
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
+}
+
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update
+
+// Reconcile generates the key of the tunnel agent if it has none, and renders its configuration with the tunnel
+// peers of the other member clusters.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	secretKey := types.NamespacedName{Namespace: r.FleetSystemNamespace, Name: r.SecretName}
+	secretKRef := klog.KRef(secretKey.Namespace, secretKey.Name)
+	startTime := time.Now()
+	klog.V(2).InfoS("Reconciliation starts", "secret", secretKRef)
+	defer func() {
+		latency := time.Since(startTime).Milliseconds()
+		klog.V(2).InfoS("Reconciliation ends", "secret", secretKRef, "latency", latency)
+	}()
+
+	var peers []fleetnetv1alpha1.TunnelPeer
+	networkStatus := &fleetnetv1alpha1.InternalMemberNetworkStatus{}
+	if err := r.HubClient.Get(ctx, req.NamespacedName, networkStatus); err != nil {
+		if !errors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to get internalMemberNetworkStatus", "internalMemberNetworkStatus", req.NamespacedName)
+			return ctrl.Result{}, err
+		}
+		// The key is generated all the same, so that the network status reporter publishes the tunnel peer of the
+		// member cluster as soon as it reports.
+	} else {
+		peers = networkStatus.Status.TunnelPeers
+	}
+
+	secret := &corev1.Secret{}
+	isNew := false
+	if err := r.MemberClient.Get(ctx, secretKey, secret); err != nil {
+		if !errors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to get secret", "secret", secretKRef)
+			return ctrl.Result{}, err
+		}
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: secretKey.Namespace, Name: secretKey.Name},
+		}
+		isNew = true
+	}
+	privateKey := string(secret.Data[PrivateKeyKey])
+	if _, err := publicKey(privateKey); err != nil {
+		// The key is missing or corrupted; a new one is published to the peers with the next report.
+		klog.V(2).InfoS("Generating the key of the tunnel agent", "secret", secretKRef)
+		if privateKey, err = generatePrivateKey(); err != nil {
+			klog.ErrorS(err, "Failed to generate the key of the tunnel agent", "secret", secretKRef)
+			return ctrl.Result{}, err
+		}
+	}
+	config, err := renderConfig(privateKey, r.Address, r.Port, peers)
+	if err != nil {
+		// Retrying will not help until the flags are fixed.
+		klog.ErrorS(err, "Failed to render the configuration of the tunnel agent", "secret", secretKRef)
+		return ctrl.Result{}, nil
+	}
+	data := map[string][]byte{
+		PrivateKeyKey: []byte(privateKey),
+		ConfigKey:     []byte(config),
+	}
+	switch {
+	case isNew:
+		secret.Data = data
+		klog.V(2).InfoS("Creating secret of the tunnel agent", "secret", secretKRef, "numberOfPeers", len(peers))
+		if err := r.MemberClient.Create(ctx, secret); err != nil {
+			klog.ErrorS(err, "Failed to create secret", "secret", secretKRef)
+			return ctrl.Result{}, err
+		}
+	case string(secret.Data[PrivateKeyKey]) != privateKey || string(secret.Data[ConfigKey]) != config:
+		secret.Data = data
+		klog.V(2).InfoS("Updating secret of the tunnel agent", "secret", secretKRef, "numberOfPeers", len(peers))
+		if err := r.MemberClient.Update(ctx, secret); err != nil {
+			klog.ErrorS(err, "Failed to update secret", "secret", secretKRef)
+			return ctrl.Result{}, err
+		}
+	default:
+		klog.V(4).InfoS("Configuration of the tunnel agent is not changed", "secret", secretKRef)
+	}
+	return ctrl.Result{}, nil
+}
+
+// renderConfig renders the wg-quick configuration of the tunnel agent.
+//
+// The connections from the member cluster leave the tunnel from the address of the tunnel agent, as the peers only
+// accept the packets from the tunnel addresses; the ones to the member cluster reach the east-west gateway running
+// along with the tunnel agent at its tunnel address. Forwarding is left to the security context of the gateway, as
+// the tunnel agent cannot write the sysctls.
+func renderConfig(privateKey, address string, port int32, peers []fleetnetv1alpha1.TunnelPeer) (string, error) {
+	prefix, ok := hostPrefix(address)
+	if !ok {
+		return "", fmt.Errorf("invalid tunnel address %q", address)
+	}
+	iptables := "iptables"
+	if net.ParseIP(address).To4() == nil {
+		iptables = "ip6tables"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "[Interface]\n")
+	fmt.Fprintf(&b, "Address = %s\n", prefix)
+	fmt.Fprintf(&b, "ListenPort = %d\n", port)
+	fmt.Fprintf(&b, "PrivateKey = %s\n", privateKey)
+	fmt.Fprintf(&b, "PostUp = %s -t nat -A POSTROUTING -o %%i -j MASQUERADE\n", iptables)
+	fmt.Fprintf(&b, "PostDown = %s -t nat -D POSTROUTING -o %%i -j MASQUERADE\n", iptables)
+	for _, peer := range peers {
+		peerPrefix, ok := hostPrefix(peer.Address)
+		if !ok {
+			klog.V(2).InfoS("Skipping invalid tunnel peer", "clusterID", peer.ClusterID, "address", peer.Address)
+			continue
+		}
+		if err := validatePeer(&peer); err != nil {
+			klog.V(2).InfoS("Skipping invalid tunnel peer", "clusterID", peer.ClusterID, "err", err)
+			continue
+		}
+		fmt.Fprintf(&b, "\n# %s\n", peer.ClusterID)
+		fmt.Fprintf(&b, "[Peer]\n")
+		fmt.Fprintf(&b, "PublicKey = %s\n", peer.PublicKey)
+		fmt.Fprintf(&b, "AllowedIPs = %s\n", peerPrefix)
+		if peer.Endpoint != "" {
+			fmt.Fprintf(&b, "Endpoint = %s\n", peer.Endpoint)
+		}
+		fmt.Fprintf(&b, "PersistentKeepalive = %d\n", persistentKeepalive)
+	}
+	return b.String(), nil
+}
+
+// validatePeer checks the fields of a tunnel peer reported by another member cluster before they are written to the
+// configuration, which also runs commands as root on the tunnel agent; a value breaking out of its line could
+// otherwise add commands of its own.
+func validatePeer(peer *fleetnetv1alpha1.TunnelPeer) error {
+	fields := []struct{ name, value string }{
+		{"clusterID", peer.ClusterID},
+		{"publicKey", peer.PublicKey},
+		{"endpoint", peer.Endpoint},
+	}
+	for _, field := range fields {
+		if strings.IndexFunc(field.value, unicode.IsControl) >= 0 {
+			return fmt.Errorf("%s %q has control characters", field.name, field.value)
+		}
+	}
+	key, err := base64.StdEncoding.DecodeString(peer.PublicKey)
+	if err != nil {
+		return fmt.Errorf("invalid public key %q: %w", peer.PublicKey, err)
+	}
+	if len(key) != wireGuardKeyLength {
+		return fmt.Errorf("invalid public key %q: got %d bytes, want %d", peer.PublicKey, len(key), wireGuardKeyLength)
+	}
+	// A peer with no endpoint yet is reached once it has connected to the member cluster.
+	if peer.Endpoint == "" {
+		return nil
+	}
+	host, port, err := net.SplitHostPort(peer.Endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid endpoint %q: %w", peer.Endpoint, err)
+	}
+	if n, err := strconv.ParseUint(port, 10, 16); err != nil || n == 0 {
+		return fmt.Errorf("invalid endpoint %q: invalid port %q", peer.Endpoint, port)
+	}
+	if net.ParseIP(host) == nil {
+		if errs := validation.IsDNS1123Subdomain(host); len(errs) != 0 {
+			return fmt.Errorf("invalid endpoint %q: invalid host: %s", peer.Endpoint, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+// hostPrefix returns the single-address prefix of an IP address.
+func hostPrefix(address string) (string, bool) {
+	ip := net.ParseIP(address)
+	switch {
+	case ip == nil:
+		return "", false
+	case ip.To4() != nil:
+		return ip.String() + "/32", true
+	default:
+		return ip.String() + "/128", true
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager of the hub cluster; the Secret is watched in the member
+// cluster through the given cluster.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager, member cluster.Cluster) error {
+	key := types.NamespacedName{Namespace: r.HubNamespace, Name: r.MemberClusterID}
+	isOwnNetworkStatus := predicate.NewPredicateFuncs(func(o client.Object) bool {
+		return o.GetNamespace() == key.Namespace && o.GetName() == key.Name
+	})
+	return ctrl.NewControllerManagedBy(mgr).Named("tunnel").
+		For(&fleetnetv1alpha1.InternalMemberNetworkStatus{}, builder.WithPredicates(isOwnNetworkStatus)).
+		// The Secret is watched so that the key and the configuration are restored if they are modified or deleted.
+		WatchesRawSource(source.Kind(member.GetCache(), &corev1.Secret{},
+			handler.TypedEnqueueRequestsFromMapFunc(func(_ context.Context, o *corev1.Secret) []reconcile.Request {
+				if o.Namespace != r.FleetSystemNamespace || o.Name != r.SecretName {
+					return nil
+				}
+				return []reconcile.Request{{NamespacedName: key}}
+			}))).
+		WithOptions(r.ControllerOptions).
+		Complete(metrics.InstrumentReconciler("tunnel", r))
+}
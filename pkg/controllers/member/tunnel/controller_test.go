@@ -0,0 +1,221 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package tunnel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+const (
+	testPeerKey2 = "1HNeOiZeFu7gP1lxi5tdAwGcB9i2xR+Q2jpmbuwTqzU="
+	testPeerKey3 = "TgdAhWK+24tgzgXB3s/jrRa3IjCWfeAfZAt+Rym0n84="
+	testPeerKey4 = "SyJ3d9TdH8Ycb4hPSGQdArTRIdP9Moywi1Ux/Kzav4o="
+)
+
+var (
+	networkStatusKey = types.NamespacedName{Namespace: hubNSForMember, Name: memberClusterID}
+	secretKey        = types.NamespacedName{Namespace: fleetSystemNamespace, Name: tunnelSecretName}
+)
+
+// TestRenderConfig tests the renderConfig function.
+func TestRenderConfig(t *testing.T) {
+	testCases := []struct {
+		name    string
+		address string
+		peers   []fleetnetv1alpha1.TunnelPeer
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "no peers",
+			address: "100.96.0.1",
+			want: `[Interface]
+Address = 100.96.0.1/32
+ListenPort = 51820
+PrivateKey = yAnz5TF+lXXJte14tji3zlMNq+hd2rYUIgJBgB3fBmk=
+PostUp = iptables -t nat -A POSTROUTING -o %i -j MASQUERADE
+PostDown = iptables -t nat -D POSTROUTING -o %i -j MASQUERADE
+`,
+		},
+		{
+			name:    "peers",
+			address: "100.96.0.1",
+			peers: []fleetnetv1alpha1.TunnelPeer{
+				{ClusterID: "highflyingcat", PublicKey: testPeerKey3, Endpoint: "20.0.0.3:51820", Address: "100.96.0.3"},
+				// A peer whose load balancer address is not assigned yet.
+				{ClusterID: "smartfish", PublicKey: testPeerKey2, Address: "100.96.0.2"},
+				{ClusterID: "quickfox", PublicKey: testPeerKey4, Address: "invalid"},
+				// Peers whose reported fields are invalid, some of them trying to add commands to the configuration.
+				{ClusterID: "tinybird", PublicKey: "not-a-key", Address: "100.96.0.5"},
+				{ClusterID: "tinybird", PublicKey: "c2hvcnQ=", Address: "100.96.0.5"},
+				{ClusterID: "tinybird", PublicKey: testPeerKey4 + "\nPostUp = touch /pwned", Address: "100.96.0.5"},
+				{ClusterID: "tinybird", PublicKey: testPeerKey4, Endpoint: "20.0.0.5:51820\nPostUp = touch /pwned", Address: "100.96.0.5"},
+				{ClusterID: "tinybird\nPostUp = touch /pwned", PublicKey: testPeerKey4, Address: "100.96.0.5"},
+				{ClusterID: "tinybird", PublicKey: testPeerKey4, Endpoint: "20.0.0.5", Address: "100.96.0.5"},
+				{ClusterID: "tinybird", PublicKey: testPeerKey4, Endpoint: "20.0.0.5:70000", Address: "100.96.0.5"},
+				{ClusterID: "tinybird", PublicKey: testPeerKey4, Endpoint: "bad host:51820", Address: "100.96.0.5"},
+				// A peer reachable at the hostname of its load balancer.
+				{ClusterID: "wiseowl", PublicKey: testPeerKey4, Endpoint: "wiseowl.example.com:51820", Address: "100.96.0.6"},
+			},
+			want: `[Interface]
+Address = 100.96.0.1/32
+ListenPort = 51820
+PrivateKey = yAnz5TF+lXXJte14tji3zlMNq+hd2rYUIgJBgB3fBmk=
+PostUp = iptables -t nat -A POSTROUTING -o %i -j MASQUERADE
+PostDown = iptables -t nat -D POSTROUTING -o %i -j MASQUERADE
+
+# highflyingcat
+[Peer]
+PublicKey = TgdAhWK+24tgzgXB3s/jrRa3IjCWfeAfZAt+Rym0n84=
+AllowedIPs = 100.96.0.3/32
+Endpoint = 20.0.0.3:51820
+PersistentKeepalive = 25
+
+# smartfish
+[Peer]
+PublicKey = 1HNeOiZeFu7gP1lxi5tdAwGcB9i2xR+Q2jpmbuwTqzU=
+AllowedIPs = 100.96.0.2/32
+PersistentKeepalive = 25
+
+# wiseowl
+[Peer]
+PublicKey = SyJ3d9TdH8Ycb4hPSGQdArTRIdP9Moywi1Ux/Kzav4o=
+AllowedIPs = 100.96.0.6/32
+Endpoint = wiseowl.example.com:51820
+PersistentKeepalive = 25
+`,
+		},
+		{
+			name:    "IPv6",
+			address: "fd00:100::1",
+			want: `[Interface]
+Address = fd00:100::1/128
+ListenPort = 51820
+PrivateKey = yAnz5TF+lXXJte14tji3zlMNq+hd2rYUIgJBgB3fBmk=
+PostUp = ip6tables -t nat -A POSTROUTING -o %i -j MASQUERADE
+PostDown = ip6tables -t nat -D POSTROUTING -o %i -j MASQUERADE
+`,
+		},
+		{
+			name:    "invalid address",
+			address: "100.96.0.1/16",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := renderConfig(testPrivateKey, tc.address, DefaultPort, tc.peers)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("renderConfig() = %v, want error %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("renderConfig() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestReconcile tests that the key is generated once and the configuration follows the tunnel peers.
+func TestReconcile(t *testing.T) {
+	peers := []fleetnetv1alpha1.TunnelPeer{
+		{ClusterID: "smartfish", PublicKey: testPeerKey2, Endpoint: "20.0.0.2:51820", Address: "100.96.0.2"},
+	}
+	testCases := []struct {
+		name           string
+		networkStatus  *fleetnetv1alpha1.InternalMemberNetworkStatus
+		secret         *corev1.Secret
+		wantPrivateKey string
+		wantPeers      []fleetnetv1alpha1.TunnelPeer
+	}{
+		{
+			name: "key generated before the first report",
+		},
+		{
+			name: "key generated with the peers",
+			networkStatus: &fleetnetv1alpha1.InternalMemberNetworkStatus{
+				ObjectMeta: metav1.ObjectMeta{Namespace: hubNSForMember, Name: memberClusterID},
+				Status:     fleetnetv1alpha1.InternalMemberNetworkStatusStatus{TunnelPeers: peers},
+			},
+			wantPeers: peers,
+		},
+		{
+			name: "key kept",
+			networkStatus: &fleetnetv1alpha1.InternalMemberNetworkStatus{
+				ObjectMeta: metav1.ObjectMeta{Namespace: hubNSForMember, Name: memberClusterID},
+				Status:     fleetnetv1alpha1.InternalMemberNetworkStatusStatus{TunnelPeers: peers},
+			},
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: fleetSystemNamespace, Name: tunnelSecretName},
+				Data:       map[string][]byte{PrivateKeyKey: []byte(testPrivateKey)},
+			},
+			wantPrivateKey: testPrivateKey,
+			wantPeers:      peers,
+		},
+		{
+			name: "corrupted key replaced",
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: fleetSystemNamespace, Name: tunnelSecretName},
+				Data:       map[string][]byte{PrivateKeyKey: []byte("not-a-key"), ConfigKey: []byte("edited")},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var hubObjs, memberObjs []client.Object
+			if tc.networkStatus != nil {
+				hubObjs = append(hubObjs, tc.networkStatus)
+			}
+			if tc.secret != nil {
+				memberObjs = append(memberObjs, tc.secret)
+			}
+			memberClient := fake.NewClientBuilder().WithObjects(memberObjs...).Build()
+			r := &Reconciler{
+				MemberClusterID:      memberClusterID,
+				MemberClient:         memberClient,
+				HubClient:            fake.NewClientBuilder().WithObjects(hubObjs...).Build(),
+				HubNamespace:         hubNSForMember,
+				FleetSystemNamespace: fleetSystemNamespace,
+				SecretName:           tunnelSecretName,
+				Address:              tunnelAddress,
+				Port:                 DefaultPort,
+			}
+			ctx := context.Background()
+			if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: networkStatusKey}); err != nil {
+				t.Fatalf("Reconcile() = %v, want no error", err)
+			}
+
+			secret := &corev1.Secret{}
+			if err := memberClient.Get(ctx, secretKey, secret); err != nil {
+				t.Fatalf("Secret Get() = %v, want no error", err)
+			}
+			privateKey := string(secret.Data[PrivateKeyKey])
+			if _, err := publicKey(privateKey); err != nil {
+				t.Fatalf("private key %q is invalid: %v", privateKey, err)
+			}
+			if tc.wantPrivateKey != "" && privateKey != tc.wantPrivateKey {
+				t.Errorf("private key = %q, want %q", privateKey, tc.wantPrivateKey)
+			}
+			wantConfig, err := renderConfig(privateKey, tunnelAddress, DefaultPort, tc.wantPeers)
+			if err != nil {
+				t.Fatalf("renderConfig() = %v, want no error", err)
+			}
+			if diff := cmp.Diff(wantConfig, string(secret.Data[ConfigKey])); diff != "" {
+				t.Errorf("configuration mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package tunnel features the controller of the WireGuard tunnels between the member clusters, for the fleets which
+// require the traffic between the member clusters to be encrypted in transit without running a service mesh.
+//
+// The tunnel agent is a WireGuard container running along with the east-west gateway of each member cluster. The
+// controller generates the key of the agent, and renders its configuration from the tunnel peers of the other member
+// clusters, which the hub cluster distributes through the InternalMemberNetworkStatus of the member cluster; the
+// network status reporter publishes the tunnel peer of the member cluster in return. The EndpointSlices exported
+// through the east-west gateway of a member cluster with a tunnel peer are then imported at its tunnel address, so
+// that the connections to them go through the tunnel, as soon as its gateway answers there from the pod network.
+package tunnel
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+const (
+	// PrivateKeyKey is the key of the Secret of the tunnel agent keeping its base64-encoded WireGuard private key.
+	PrivateKeyKey = "privatekey"
+	// ConfigKey is the key of the Secret of the tunnel agent keeping its wg-quick configuration.
+	ConfigKey = "wg0.conf"
+
+	// DefaultPort is the default UDP port of the tunnel agent.
+	DefaultPort = 51820
+
+	// gatewayStatusPort is the TCP port of the status listener of the east-west gateway, which the gateways of the
+	// other member clusters are probed at through the tunnel.
+	gatewayStatusPort = "15021"
+	// probeTimeout is how long a probe waits for a connection to be established.
+	probeTimeout = 3 * time.Second
+	// probeInterval is how long the result of a probe is kept.
+	probeInterval = 30 * time.Second
+)
+
+// generatePrivateKey returns a new base64-encoded WireGuard private key.
+func generatePrivateKey() (string, error) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key.Bytes()), nil
+}
+
+// publicKey returns the base64-encoded WireGuard public key of a base64-encoded private key.
+func publicKey(privateKey string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(privateKey)
+	if err != nil {
+		return "", fmt.Errorf("invalid tunnel private key: %w", err)
+	}
+	key, err := ecdh.X25519().NewPrivateKey(data)
+	if err != nil {
+		return "", fmt.Errorf("invalid tunnel private key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(key.PublicKey().Bytes()), nil
+}
+
+// Agent is the tunnel agent of the member cluster, which the network status reporter publishes as the tunnel peer
+// of the member cluster.
+type Agent struct {
+	MemberClusterID string
+	Client          client.Reader
+	// Namespace is the namespace of the Secret and the Service of the tunnel agent.
+	Namespace string
+	// SecretName is the name of the Secret keeping the key and the configuration of the tunnel agent.
+	SecretName string
+	// ServiceName is the name of the LoadBalancer Service the tunnel agent is reachable at from the other member
+	// clusters.
+	ServiceName string
+	// Address is the IP address of the tunnel agent within the tunnel network.
+	Address string
+	// Port is the UDP port of the tunnel agent.
+	Port int32
+}
+
+// Peer returns the tunnel peer of the member cluster, or nil if the key of the tunnel agent is not generated yet;
+// the endpoint of the peer is left empty until the address of the Service is assigned.
+func (a *Agent) Peer(ctx context.Context) (*fleetnetv1alpha1.TunnelPeer, error) {
+	secret := &corev1.Secret{}
+	if err := a.Client.Get(ctx, types.NamespacedName{Namespace: a.Namespace, Name: a.SecretName}, secret); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	privateKey, ok := secret.Data[PrivateKeyKey]
+	if !ok {
+		return nil, nil
+	}
+	key, err := publicKey(string(privateKey))
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := ""
+	svc := &corev1.Service{}
+	switch err := a.Client.Get(ctx, types.NamespacedName{Namespace: a.Namespace, Name: a.ServiceName}, svc); {
+	case errors.IsNotFound(err):
+	case err != nil:
+		return nil, err
+	default:
+		if address := loadBalancerAddress(svc); address != "" {
+			endpoint = net.JoinHostPort(address, strconv.Itoa(int(a.Port)))
+		}
+	}
+	return &fleetnetv1alpha1.TunnelPeer{
+		ClusterID: a.MemberClusterID,
+		PublicKey: key,
+		Endpoint:  endpoint,
+		Address:   a.Address,
+	}, nil
+}
+
+// loadBalancerAddress returns the first IP or hostname of the load balancer of a Service, or empty if none is
+// assigned.
+func loadBalancerAddress(svc *corev1.Service) string {
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		if ingress.IP != "" {
+			return ingress.IP
+		}
+		if ingress.Hostname != "" {
+			return ingress.Hostname
+		}
+	}
+	return ""
+}
+
+// Reader reads the tunnel addresses of the other member clusters, as distributed by the hub cluster, and probes
+// whether they are routed from the pod network of the member cluster.
+type Reader struct {
+	HubClient client.Reader
+	// HubNamespace is the namespace reserved for the member cluster in the hub cluster.
+	HubNamespace    string
+	MemberClusterID string
+	// Dial dials the gateways of the other member clusters at their tunnel addresses; the default dialer is used if
+	// it is not set.
+	Dial func(ctx context.Context, network, address string) (net.Conn, error)
+
+	mu     sync.Mutex
+	probes map[string]probeResult
+}
+
+// probeResult is the result of the last probe of a tunnel address.
+type probeResult struct {
+	routed bool
+	time   time.Time
+}
+
+// Addresses returns the tunnel addresses of the other member clusters keyed by cluster; it is empty until the hub
+// cluster distributes the tunnel peers.
+func (r *Reader) Addresses(ctx context.Context) (map[string]string, error) {
+	networkStatus := &fleetnetv1alpha1.InternalMemberNetworkStatus{}
+	if err := r.HubClient.Get(ctx, types.NamespacedName{Namespace: r.HubNamespace, Name: r.MemberClusterID}, networkStatus); err != nil {
+		if errors.IsNotFound(err) {
+			return map[string]string{}, nil
+		}
+		return nil, err
+	}
+	res := make(map[string]string, len(networkStatus.Status.TunnelPeers))
+	for _, peer := range networkStatus.Status.TunnelPeers {
+		res[peer.ClusterID] = peer.Address
+	}
+	return res, nil
+}
+
+// Routed returns whether the east-west gateway of another member cluster answers at its tunnel address from the pod
+// network, i.e. whether the tunnel to it is up and the tunnel network is routed to the local east-west gateway; the
+// tunnel agent routes the tunnel network within the gateway only, and the pods reach it only once the member cluster
+// routes the tunnel network to the gateway. The result is kept for the probe interval, which the caller checks again
+// after if the address is not routed.
+func (r *Reader) Routed(ctx context.Context, address string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if last, ok := r.probes[address]; ok && time.Since(last.time) < probeInterval {
+		return last.routed
+	}
+
+	dial := r.Dial
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	probeCtx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	conn, err := dial(probeCtx, "tcp", net.JoinHostPort(address, gatewayStatusPort))
+	if err == nil {
+		_ = conn.Close()
+	}
+	if r.probes == nil {
+		r.probes = map[string]probeResult{}
+	}
+	r.probes[address] = probeResult{routed: err == nil, time: time.Now()}
+	return err == nil
+}
+
+// ProbeInterval returns how long the result of a probe of a tunnel address is kept.
+func (r *Reader) ProbeInterval() time.Duration {
+	return probeInterval
+}
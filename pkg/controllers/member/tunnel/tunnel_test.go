@@ -0,0 +1,208 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package tunnel
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+)
+
+const (
+	memberClusterID      = "bravelion"
+	hubNSForMember       = "fleet-member-bravelion"
+	fleetSystemNamespace = "fleet-system"
+	tunnelSecretName     = "east-west-gateway-tunnel"
+	tunnelServiceName    = "east-west-gateway-tunnel"
+	tunnelAddress        = "100.96.0.1"
+
+	// The key pair of the WireGuard documentation, as generated by wg genkey and wg pubkey.
+	testPrivateKey = "yAnz5TF+lXXJte14tji3zlMNq+hd2rYUIgJBgB3fBmk="
+	testPublicKey  = "HIgo9xNzJMWLKASShiTqIybxZ0U3wGLiUeJ1PKf8ykw="
+)
+
+func TestMain(m *testing.M) {
+	// Add custom APIs to the runtime scheme
+	if err := fleetnetv1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		log.Fatalf("failed to add custom APIs to the runtime scheme: %v", err)
+	}
+	os.Exit(m.Run())
+}
+
+// TestPublicKey tests that the public keys are derived as by wg pubkey, including from the generated private keys.
+func TestPublicKey(t *testing.T) {
+	got, err := publicKey(testPrivateKey)
+	if err != nil || got != testPublicKey {
+		t.Errorf("publicKey() = %q, %v, want %q, nil", got, err, testPublicKey)
+	}
+
+	privateKey, err := generatePrivateKey()
+	if err != nil {
+		t.Fatalf("generatePrivateKey() = %v, want no error", err)
+	}
+	if _, err := publicKey(privateKey); err != nil {
+		t.Errorf("publicKey() of the generated key = %v, want no error", err)
+	}
+
+	if _, err := publicKey("not-a-key"); err == nil {
+		t.Errorf("publicKey() of an invalid key = nil, want error")
+	}
+}
+
+// TestAgentPeer tests the Peer method of the Agent.
+func TestAgentPeer(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: fleetSystemNamespace, Name: tunnelSecretName},
+		Data:       map[string][]byte{PrivateKeyKey: []byte(testPrivateKey)},
+	}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: fleetSystemNamespace, Name: tunnelServiceName},
+		Status: corev1.ServiceStatus{
+			LoadBalancer: corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{{IP: "20.0.0.1"}}},
+		},
+	}
+	testCases := []struct {
+		name    string
+		objects []client.Object
+		want    *fleetnetv1alpha1.TunnelPeer
+	}{
+		{
+			name: "key not generated yet",
+		},
+		{
+			name:    "load balancer address not assigned yet",
+			objects: []client.Object{secret},
+			want: &fleetnetv1alpha1.TunnelPeer{
+				ClusterID: memberClusterID,
+				PublicKey: testPublicKey,
+				Address:   tunnelAddress,
+			},
+		},
+		{
+			name:    "reachable at the load balancer",
+			objects: []client.Object{secret, svc},
+			want: &fleetnetv1alpha1.TunnelPeer{
+				ClusterID: memberClusterID,
+				PublicKey: testPublicKey,
+				Endpoint:  "20.0.0.1:51820",
+				Address:   tunnelAddress,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := &Agent{
+				MemberClusterID: memberClusterID,
+				Client:          fake.NewClientBuilder().WithObjects(tc.objects...).Build(),
+				Namespace:       fleetSystemNamespace,
+				SecretName:      tunnelSecretName,
+				ServiceName:     tunnelServiceName,
+				Address:         tunnelAddress,
+				Port:            DefaultPort,
+			}
+			got, err := a.Peer(context.Background())
+			if err != nil {
+				t.Fatalf("Peer() = %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Peer() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestReaderAddresses tests the Addresses method of the Reader.
+func TestReaderAddresses(t *testing.T) {
+	networkStatus := &fleetnetv1alpha1.InternalMemberNetworkStatus{
+		ObjectMeta: metav1.ObjectMeta{Namespace: hubNSForMember, Name: memberClusterID},
+		Status: fleetnetv1alpha1.InternalMemberNetworkStatusStatus{
+			TunnelPeers: []fleetnetv1alpha1.TunnelPeer{
+				{ClusterID: "smartfish", PublicKey: "key-2", Address: "100.96.0.2"},
+				{ClusterID: "highflyingcat", PublicKey: "key-3", Address: "100.96.0.3"},
+			},
+		},
+	}
+	testCases := []struct {
+		name    string
+		objects []client.Object
+		want    map[string]string
+	}{
+		{
+			name: "network status not reported yet",
+			want: map[string]string{},
+		},
+		{
+			name:    "tunnel peers distributed",
+			objects: []client.Object{networkStatus},
+			want:    map[string]string{"smartfish": "100.96.0.2", "highflyingcat": "100.96.0.3"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &Reader{
+				HubClient:       fake.NewClientBuilder().WithObjects(tc.objects...).Build(),
+				HubNamespace:    hubNSForMember,
+				MemberClusterID: memberClusterID,
+			}
+			got, err := r.Addresses(context.Background())
+			if err != nil {
+				t.Fatalf("Addresses() = %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Addresses() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestReaderRouted tests that the gateways are probed at their tunnel addresses, and that the results are kept for
+// the probe interval.
+func TestReaderRouted(t *testing.T) {
+	var dialed []string
+	r := &Reader{
+		Dial: func(_ context.Context, _, address string) (net.Conn, error) {
+			dialed = append(dialed, address)
+			if address != "100.96.0.2:15021" {
+				return nil, errors.New("no route to host")
+			}
+			client, server := net.Pipe()
+			_ = server.Close()
+			return client, nil
+		},
+	}
+	if !r.Routed(context.Background(), "100.96.0.2") {
+		t.Errorf("Routed(100.96.0.2) = false, want true")
+	}
+	if r.Routed(context.Background(), "100.96.0.3") {
+		t.Errorf("Routed(100.96.0.3) = true, want false")
+	}
+	if r.Routed(context.Background(), "100.96.0.3") {
+		t.Errorf("Routed(100.96.0.3) probed again = true, want false")
+	}
+	if want := []string{"100.96.0.2:15021", "100.96.0.3:15021"}; !cmp.Equal(dialed, want) {
+		t.Errorf("Routed() dialed %v, want %v", dialed, want)
+	}
+
+	// The address is probed again once the result has expired.
+	r.probes["100.96.0.3"] = probeResult{time: time.Now().Add(-probeInterval)}
+	r.Routed(context.Background(), "100.96.0.3")
+	if len(dialed) != 3 {
+		t.Errorf("Routed() dialed %d times after the result expired, want 3", len(dialed))
+	}
+}
@@ -10,7 +10,9 @@ package multiclusterservice
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -24,12 +26,16 @@ import (
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 	"go.goms.io/fleet-networking/pkg/common/condition"
+	"go.goms.io/fleet-networking/pkg/common/fairqueue"
+	"go.goms.io/fleet-networking/pkg/common/ipfamily"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
 	"go.goms.io/fleet-networking/pkg/common/objectmeta"
 )
 
@@ -42,8 +48,13 @@ const (
 	serviceLabelMCSName      = "networking.fleet.azure.com/multi-cluster-service-name"
 	serviceLabelMCSNamespace = "networking.fleet.azure.com/multi-cluster-service-namespace"
 
+	// topologyModeAuto enables topology aware routing of a Service.
+	topologyModeAuto = "Auto"
+
 	conditionReasonUnknownServiceImport = "UnknownServiceImport"
 	conditionReasonFoundServiceImport   = "FoundServiceImport"
+	conditionReasonIPFamilyMismatch     = "UnsupportedIPFamilies"
+	conditionReasonIPFamilyMatch        = "SupportedIPFamilies"
 
 	mcsRetryInterval = time.Second * 5
 
@@ -63,6 +74,15 @@ type Reconciler struct {
 	Scheme               *runtime.Scheme
 	FleetSystemNamespace string // reserved fleet namespace
 	Recorder             record.EventRecorder
+	// SupportedIPFamilies are the IP families the member cluster supports. If set, the controller reports
+	// exporting clusters whose Services use other IP families via the IPFamilyMismatch condition.
+	SupportedIPFamilies []corev1.IPFamily
+	// NewQueue, if set, constructs the work queue of the controller, e.g. a queue that shares the reconcile
+	// throughput fairly among namespaces; the default work queue is used otherwise.
+	NewQueue fairqueue.NewQueueFunc
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
 }
 
 //+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
@@ -191,7 +211,7 @@ func (r *Reconciler) serviceImportFromLabel(mcs *fleetnetv1alpha1.MultiClusterSe
 func (r *Reconciler) handleUpdate(ctx context.Context, mcs *fleetnetv1alpha1.MultiClusterService) (ctrl.Result, error) {
 	mcsKObj := klog.KObj(mcs)
 	currentServiceImportName := r.serviceImportFromLabel(mcs)
-	desiredServiceImportName := types.NamespacedName{Namespace: mcs.Namespace, Name: mcs.Spec.ServiceImport.Name}
+	desiredServiceImportName := types.NamespacedName{Namespace: mcs.Namespace, Name: mcs.ServiceImportName()}
 	if currentServiceImportName != nil && currentServiceImportName.Name != desiredServiceImportName.Name {
 		if err := r.deleteServiceImport(ctx, currentServiceImportName); err != nil {
 			klog.ErrorS(err, "Failed to remove service import of mcs", "multiClusterService", mcsKObj, "serviceImport", klog.KRef(currentServiceImportName.Namespace, currentServiceImportName.Name))
@@ -255,6 +275,10 @@ func (r *Reconciler) handleUpdate(ctx context.Context, mcs *fleetnetv1alpha1.Mul
 		return ctrl.Result{}, err
 	}
 
+	if err := r.deleteStaleDerivedService(ctx, mcs, serviceImport, serviceName); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	service := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: serviceName.Namespace,
@@ -358,7 +382,17 @@ func (r *Reconciler) ensureDerivedService(mcs *fleetnetv1alpha1.MultiClusterServ
 		svcPorts[i] = importPort.ToServicePort()
 	}
 	service.Spec.Ports = svcPorts
-	service.Spec.Type = corev1.ServiceTypeLoadBalancer
+	if serviceImport.Status.Type == fleetnetv1alpha1.Headless {
+		// A headless service is imported as a headless service, whose DNS records resolve to the imported endpoints.
+		service.Spec.Type = corev1.ServiceTypeClusterIP
+		service.Spec.ClusterIP = corev1.ClusterIPNone
+	} else {
+		service.Spec.Type = corev1.ServiceTypeLoadBalancer
+		if clusterSetIP := clusterSetIPOf(serviceImport); clusterSetIP != "" {
+			// The ClusterSetIP allocated in the member cluster is the stable virtual IP of the imported service.
+			service.Spec.ClusterIP = clusterSetIP
+		}
+	}
 
 	if service.GetLabels() == nil { // in case labels map is nil and causes the panic
 		service.Labels = map[string]string{}
@@ -366,7 +400,107 @@ func (r *Reconciler) ensureDerivedService(mcs *fleetnetv1alpha1.MultiClusterServ
 
 	service.Labels[serviceLabelMCSName] = mcs.Name
 	service.Labels[serviceLabelMCSNamespace] = mcs.Namespace
-	configureInternalLoadBalancer(mcs, service)
+	if service.GetAnnotations() == nil {
+		service.Annotations = map[string]string{}
+	}
+	// The imported EndpointSlices are hinted for the zones of the member cluster; kube-proxy honors the hints of a
+	// Service only if topology aware routing is enabled for it.
+	service.Annotations[corev1.AnnotationTopologyMode] = topologyModeAuto
+	if service.Spec.Type == corev1.ServiceTypeLoadBalancer {
+		configureInternalLoadBalancer(mcs, service)
+		configureDNSLabelName(mcs, service)
+	}
+	configureTrafficPolicy(mcs, serviceImport, service)
+	return nil
+}
+
+// configureDNSLabelName assigns the DNS label of the mcs to the public IP address of the load balancer; the DNS label
+// of an internal load balancer is removed, as it has no public IP address.
+func configureDNSLabelName(mcs *fleetnetv1alpha1.MultiClusterService, service *corev1.Service) {
+	if mcs.Spec.DNSLabelName == "" || service.Annotations[serviceAnnotationInternalLoadBalancer] == "true" {
+		delete(service.Annotations, objectmeta.ServiceAnnotationAzureDNSLabelName)
+		return
+	}
+	if service.GetAnnotations() == nil { // in case annotation map is nil
+		service.Annotations = map[string]string{}
+	}
+	service.Annotations[objectmeta.ServiceAnnotationAzureDNSLabelName] = mcs.Spec.DNSLabelName
+}
+
+// configureTrafficPolicy applies the traffic policy of the mcs to the derived service; the session affinity of the
+// exported services, as resolved on the serviceImport, applies unless the traffic policy sets one. The fields left
+// unset are reset to their defaults only if they have been set before, so that the derived service is not updated
+// over and over against the defaults applied by the API server.
+func configureTrafficPolicy(mcs *fleetnetv1alpha1.MultiClusterService, serviceImport *fleetnetv1alpha1.ServiceImport, service *corev1.Service) {
+	policy := mcs.Spec.TrafficPolicy
+	if policy == nil {
+		policy = &fleetnetv1alpha1.MultiClusterServiceTrafficPolicy{}
+	}
+
+	importedAffinity := serviceImport.Status.SessionAffinity
+	sessionAffinity := policy.SessionAffinity
+	if sessionAffinity == "" {
+		sessionAffinity = importedAffinity
+	}
+	if sessionAffinity == "" {
+		sessionAffinity = corev1.ServiceAffinityNone
+	}
+	if service.Spec.SessionAffinity != "" || sessionAffinity != corev1.ServiceAffinityNone {
+		service.Spec.SessionAffinity = sessionAffinity
+	}
+	switch {
+	case sessionAffinity == corev1.ServiceAffinityNone:
+		// The session affinity config is only allowed with the ClientIP session affinity.
+		service.Spec.SessionAffinityConfig = nil
+	case importedAffinity == corev1.ServiceAffinityClientIP && serviceImport.Status.SessionAffinityConfig != nil:
+		// The client IP based session affinity keeps the timeout the exported services agree on.
+		service.Spec.SessionAffinityConfig = serviceImport.Status.SessionAffinityConfig.DeepCopy()
+	}
+
+	if service.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		return
+	}
+	externalTrafficPolicy := policy.ExternalTrafficPolicy
+	if externalTrafficPolicy == "" {
+		externalTrafficPolicy = corev1.ServiceExternalTrafficPolicyCluster
+	}
+	if service.Spec.ExternalTrafficPolicy != "" || externalTrafficPolicy != corev1.ServiceExternalTrafficPolicyCluster {
+		service.Spec.ExternalTrafficPolicy = externalTrafficPolicy
+	}
+	service.Spec.LoadBalancerSourceRanges = policy.LoadBalancerSourceRanges
+}
+
+// clusterSetIPOf returns the ClusterSetIP allocated to the serviceImport, or an empty string if none is allocated.
+func clusterSetIPOf(serviceImport *fleetnetv1alpha1.ServiceImport) string {
+	if serviceImport.Status.Type != fleetnetv1alpha1.ClusterSetIP || len(serviceImport.Status.IPs) == 0 {
+		return ""
+	}
+	return serviceImport.Status.IPs[0]
+}
+
+// deleteStaleDerivedService deletes the derived service if it is headless while the serviceImport is not, or
+// vice versa, or if its cluster IP is not the ClusterSetIP allocated to the serviceImport; the cluster IP of a
+// service is immutable, so the derived service has to be recreated.
+func (r *Reconciler) deleteStaleDerivedService(ctx context.Context, mcs *fleetnetv1alpha1.MultiClusterService, serviceImport *fleetnetv1alpha1.ServiceImport, serviceName *types.NamespacedName) error {
+	service := &corev1.Service{}
+	if err := r.Client.Get(ctx, *serviceName, service); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		klog.ErrorS(err, "Failed to get derived service of mcs", "multiClusterService", klog.KObj(mcs), "service", serviceName)
+		return err
+	}
+	isHeadlessService := service.Spec.ClusterIP == corev1.ClusterIPNone
+	clusterSetIP := clusterSetIPOf(serviceImport)
+	if isHeadlessService == (serviceImport.Status.Type == fleetnetv1alpha1.Headless) &&
+		(clusterSetIP == "" || service.Spec.ClusterIP == clusterSetIP) {
+		return nil
+	}
+	klog.V(2).InfoS("Deleting derived service of mcs to recreate it as the serviceImport type or clusterSetIP has changed", "multiClusterService", klog.KObj(mcs), "service", serviceName, "serviceImportType", serviceImport.Status.Type, "clusterIP", service.Spec.ClusterIP, "clusterSetIP", clusterSetIP)
+	if err := r.Client.Delete(ctx, service); err != nil && !errors.IsNotFound(err) {
+		klog.ErrorS(err, "Failed to delete derived service of mcs", "multiClusterService", klog.KObj(mcs), "service", serviceName)
+		return err
+	}
 	return nil
 }
 
@@ -398,14 +532,24 @@ func (r *Reconciler) updateMultiClusterServiceStatus(ctx context.Context, mcs *f
 		}
 	}
 
+	currentIPFamilyCond := meta.FindStatusCondition(mcs.Status.Conditions, string(fleetnetv1alpha1.MultiClusterServiceIPFamilyMismatch))
+	desiredIPFamilyCond := ipFamilyMismatchCondition(mcs, serviceImport, r.SupportedIPFamilies)
+
 	mcsKObj := klog.KObj(mcs)
 	if equality.Semantic.DeepEqual(mcs.Status.LoadBalancer, service.Status.LoadBalancer) &&
-		condition.EqualCondition(currentCond, desiredCond) {
+		condition.EqualCondition(currentCond, desiredCond) &&
+		condition.EqualCondition(currentIPFamilyCond, desiredIPFamilyCond) &&
+		(currentIPFamilyCond == nil || currentIPFamilyCond.Message == desiredIPFamilyCond.Message) {
 		klog.V(4).InfoS("Status is in the desired state and skipping updating status", "multiClusterService", mcsKObj)
 		return nil
 	}
 	mcs.Status.LoadBalancer = service.Status.LoadBalancer
 	meta.SetStatusCondition(&mcs.Status.Conditions, *desiredCond)
+	if desiredIPFamilyCond != nil {
+		meta.SetStatusCondition(&mcs.Status.Conditions, *desiredIPFamilyCond)
+	} else {
+		meta.RemoveStatusCondition(&mcs.Status.Conditions, string(fleetnetv1alpha1.MultiClusterServiceIPFamilyMismatch))
+	}
 
 	klog.V(2).InfoS("Updating mcs status", "multiClusterService", mcsKObj)
 	if err := r.Status().Update(ctx, mcs); err != nil {
@@ -415,8 +559,43 @@ func (r *Reconciler) updateMultiClusterServiceStatus(ctx context.Context, mcs *f
 	return nil
 }
 
+// ipFamilyMismatchCondition returns the desired IPFamilyMismatch condition of a multi-cluster service, based on
+// the IP families of the Services exported from each cluster; it returns nil if the supported IP families of the
+// member cluster are not specified.
+func ipFamilyMismatchCondition(mcs *fleetnetv1alpha1.MultiClusterService, serviceImport *fleetnetv1alpha1.ServiceImport, supportedIPFamilies []corev1.IPFamily) *metav1.Condition {
+	if len(supportedIPFamilies) == 0 {
+		return nil
+	}
+	var mismatches []string
+	for _, c := range serviceImport.Status.Clusters {
+		if unsupported := ipfamily.Unsupported(c.IPFamilies, supportedIPFamilies); len(unsupported) > 0 {
+			mismatches = append(mismatches, fmt.Sprintf("%s (%v)", c.Cluster, unsupported))
+		}
+	}
+	if len(mismatches) == 0 {
+		return &metav1.Condition{
+			Type:               string(fleetnetv1alpha1.MultiClusterServiceIPFamilyMismatch),
+			Status:             metav1.ConditionFalse,
+			Reason:             conditionReasonIPFamilyMatch,
+			ObservedGeneration: mcs.GetGeneration(),
+			Message:            fmt.Sprintf("all exporting clusters use IP families supported by this cluster %v", supportedIPFamilies),
+		}
+	}
+	sort.Strings(mismatches)
+	return &metav1.Condition{
+		Type:               string(fleetnetv1alpha1.MultiClusterServiceIPFamilyMismatch),
+		Status:             metav1.ConditionTrue,
+		Reason:             conditionReasonIPFamilyMismatch,
+		ObservedGeneration: mcs.GetGeneration(),
+		Message: fmt.Sprintf("endpoints of IP families not supported by this cluster %v are not imported from clusters: %s",
+			supportedIPFamilies, strings.Join(mismatches, ", ")),
+	}
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	opts := r.ControllerOptions
+	opts.NewQueue = r.NewQueue
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&fleetnetv1alpha1.MultiClusterService{}).
 		Owns(&fleetnetv1alpha1.ServiceImport{}).
@@ -428,7 +607,8 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 			&corev1.Service{},
 			handler.EnqueueRequestsFromMapFunc(r.serviceEventHandler()),
 		).
-		Complete(r)
+		WithOptions(opts).
+		Complete(metrics.InstrumentReconciler("multiclusterservice", r))
 }
 
 func (r *Reconciler) serviceEventHandler() handler.MapFunc {
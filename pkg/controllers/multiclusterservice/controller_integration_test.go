@@ -87,7 +87,7 @@ var _ = Describe("Test MultiClusterService Controller", func() {
 			By("By updating service import status")
 			createdServiceImport.Status = fleetnetv1alpha1.ServiceImportStatus{
 				Type: fleetnetv1alpha1.ClusterSetIP,
-				Clusters: []fleetnetv1alpha1.ClusterStatus{
+				Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 					{
 						Cluster: "member1",
 					},
@@ -607,7 +607,7 @@ func TestHandleUpdate(t *testing.T) {
 				},
 				Status: fleetnetv1alpha1.ServiceImportStatus{
 					Ports: importServicePorts,
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 						{Cluster: "member1"},
 					},
 				},
@@ -621,7 +621,7 @@ func TestHandleUpdate(t *testing.T) {
 				},
 				Status: fleetnetv1alpha1.ServiceImportStatus{
 					Ports: importServicePorts,
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 						{Cluster: "member1"},
 					},
 				},
@@ -631,6 +631,9 @@ func TestHandleUpdate(t *testing.T) {
 					Name:      derivedServiceName,
 					Namespace: systemNamespace,
 					Labels:    serviceLabel,
+					Annotations: map[string]string{
+						corev1.AnnotationTopologyMode: topologyModeAuto,
+					},
 				},
 				Spec: corev1.ServiceSpec{
 					Ports: servicePorts,
@@ -673,7 +676,7 @@ func TestHandleUpdate(t *testing.T) {
 				},
 				Status: fleetnetv1alpha1.ServiceImportStatus{
 					Ports: importServicePorts,
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 						{Cluster: "member1"},
 					},
 				},
@@ -687,7 +690,7 @@ func TestHandleUpdate(t *testing.T) {
 				},
 				Status: fleetnetv1alpha1.ServiceImportStatus{
 					Ports: importServicePorts,
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 						{Cluster: "member1"},
 					},
 				},
@@ -697,6 +700,9 @@ func TestHandleUpdate(t *testing.T) {
 					Name:      derivedServiceName,
 					Namespace: systemNamespace,
 					Labels:    serviceLabel,
+					Annotations: map[string]string{
+						corev1.AnnotationTopologyMode: topologyModeAuto,
+					},
 				},
 				Spec: corev1.ServiceSpec{
 					Ports: servicePorts,
@@ -757,7 +763,7 @@ func TestHandleUpdate(t *testing.T) {
 				},
 				Status: fleetnetv1alpha1.ServiceImportStatus{
 					Ports: importServicePorts,
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 						{Cluster: "member1"},
 					},
 				},
@@ -792,7 +798,7 @@ func TestHandleUpdate(t *testing.T) {
 				},
 				Status: fleetnetv1alpha1.ServiceImportStatus{
 					Ports: importServicePorts,
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 						{Cluster: "member1"},
 					},
 				},
@@ -803,6 +809,9 @@ func TestHandleUpdate(t *testing.T) {
 					Name:      derivedServiceName,
 					Namespace: systemNamespace,
 					Labels:    serviceLabel,
+					Annotations: map[string]string{
+						corev1.AnnotationTopologyMode: topologyModeAuto,
+					},
 				},
 				Spec: corev1.ServiceSpec{
 					Ports: servicePorts,
@@ -853,7 +862,7 @@ func TestHandleUpdate(t *testing.T) {
 				},
 				Status: fleetnetv1alpha1.ServiceImportStatus{
 					Ports: importServicePorts,
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 						{Cluster: "member1"},
 					},
 				},
@@ -875,7 +884,7 @@ func TestHandleUpdate(t *testing.T) {
 				},
 				Status: fleetnetv1alpha1.ServiceImportStatus{
 					Ports: importServicePorts,
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 						{Cluster: "member1"},
 					},
 				},
@@ -918,7 +927,7 @@ func TestHandleUpdate(t *testing.T) {
 				},
 				Status: fleetnetv1alpha1.ServiceImportStatus{
 					Ports: importServicePorts,
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 						{Cluster: "member1"},
 					},
 				},
@@ -932,7 +941,7 @@ func TestHandleUpdate(t *testing.T) {
 				},
 				Status: fleetnetv1alpha1.ServiceImportStatus{
 					Ports: importServicePorts,
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 						{Cluster: "member1"},
 					},
 				},
@@ -944,6 +953,7 @@ func TestHandleUpdate(t *testing.T) {
 					Labels:    serviceLabel,
 					Annotations: map[string]string{
 						serviceAnnotationInternalLoadBalancer: "true",
+						corev1.AnnotationTopologyMode:         topologyModeAuto,
 					},
 				},
 				Spec: corev1.ServiceSpec{
@@ -1055,6 +1065,153 @@ func TestHandleUpdate(t *testing.T) {
 	}
 }
 
+// TestHandleUpdate_ServiceImportType tests that the derived service follows the type and the ClusterSetIP of the
+// serviceImport, and that it is recreated when either changes.
+func TestHandleUpdate_ServiceImportType(t *testing.T) {
+	derivedService := func(clusterIP string, serviceType corev1.ServiceType) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: systemNamespace,
+				Name:      derivedServiceName,
+			},
+			Spec: corev1.ServiceSpec{
+				Type:      serviceType,
+				ClusterIP: clusterIP,
+			},
+		}
+	}
+	tests := []struct {
+		name              string
+		serviceImportType fleetnetv1alpha1.ServiceImportType
+		serviceImportIPs  []string
+		service           *corev1.Service
+		wantType          corev1.ServiceType
+		wantClusterIP     string
+	}{
+		{
+			name:              "headless serviceImport and no derived service",
+			serviceImportType: fleetnetv1alpha1.Headless,
+			wantType:          corev1.ServiceTypeClusterIP,
+			wantClusterIP:     corev1.ClusterIPNone,
+		},
+		{
+			name:              "headless serviceImport and derived service of the load balancer type",
+			serviceImportType: fleetnetv1alpha1.Headless,
+			service:           derivedService("10.0.0.1", corev1.ServiceTypeLoadBalancer),
+			wantType:          corev1.ServiceTypeClusterIP,
+			wantClusterIP:     corev1.ClusterIPNone,
+		},
+		{
+			name:              "serviceImport with cluster set IP and headless derived service",
+			serviceImportType: fleetnetv1alpha1.ClusterSetIP,
+			service:           derivedService(corev1.ClusterIPNone, corev1.ServiceTypeClusterIP),
+			wantType:          corev1.ServiceTypeLoadBalancer,
+		},
+		{
+			name:              "serviceImport with cluster set IP and derived service of the load balancer type",
+			serviceImportType: fleetnetv1alpha1.ClusterSetIP,
+			service:           derivedService("10.0.0.1", corev1.ServiceTypeLoadBalancer),
+			wantType:          corev1.ServiceTypeLoadBalancer,
+			wantClusterIP:     "10.0.0.1",
+		},
+		{
+			name:              "serviceImport with clusterSetIP and no derived service",
+			serviceImportType: fleetnetv1alpha1.ClusterSetIP,
+			serviceImportIPs:  []string{"10.255.0.1"},
+			wantType:          corev1.ServiceTypeLoadBalancer,
+			wantClusterIP:     "10.255.0.1",
+		},
+		{
+			name:              "serviceImport with clusterSetIP and derived service with another cluster IP",
+			serviceImportType: fleetnetv1alpha1.ClusterSetIP,
+			serviceImportIPs:  []string{"10.255.0.1"},
+			service:           derivedService("10.0.0.1", corev1.ServiceTypeLoadBalancer),
+			wantType:          corev1.ServiceTypeLoadBalancer,
+			wantClusterIP:     "10.255.0.1",
+		},
+		{
+			name:              "serviceImport with clusterSetIP and derived service with the clusterSetIP",
+			serviceImportType: fleetnetv1alpha1.ClusterSetIP,
+			serviceImportIPs:  []string{"10.255.0.1"},
+			service:           derivedService("10.255.0.1", corev1.ServiceTypeLoadBalancer),
+			wantType:          corev1.ServiceTypeLoadBalancer,
+			wantClusterIP:     "10.255.0.1",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			mcsObj := multiClusterServiceForTest()
+			mcsObj.Labels = map[string]string{
+				multiClusterServiceLabelServiceImport:             testServiceName,
+				objectmeta.MultiClusterServiceLabelDerivedService: derivedServiceName,
+			}
+			serviceImport := &fleetnetv1alpha1.ServiceImport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: testNamespace,
+					Name:      testServiceName,
+				},
+				Status: fleetnetv1alpha1.ServiceImportStatus{
+					Type:     tc.serviceImportType,
+					IPs:      tc.serviceImportIPs,
+					Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{{Cluster: "member-1"}},
+				},
+			}
+			objects := []client.Object{mcsObj, serviceImport}
+			if tc.service != nil {
+				objects = append(objects, tc.service)
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(multiClusterServiceScheme(t)).
+				WithObjects(objects...).
+				WithStatusSubresource(objects...).
+				Build()
+
+			r := multiClusterServiceReconciler(fakeClient)
+			if _, err := r.handleUpdate(ctx, mcsObj); err != nil {
+				t.Fatalf("failed to handle update: %v", err)
+			}
+			service := corev1.Service{}
+			name := types.NamespacedName{Namespace: systemNamespace, Name: derivedServiceName}
+			if err := fakeClient.Get(ctx, name, &service); err != nil {
+				t.Fatalf("Service Get() got error %v, want no error", err)
+			}
+			if service.Spec.Type != tc.wantType {
+				t.Errorf("Service type = %q, want %q", service.Spec.Type, tc.wantType)
+			}
+			if service.Spec.ClusterIP != tc.wantClusterIP {
+				t.Errorf("Service cluster IP = %q, want %q", service.Spec.ClusterIP, tc.wantClusterIP)
+			}
+		})
+	}
+}
+
+func TestHandleUpdate_DefaultServiceImportName(t *testing.T) {
+	ctx := context.Background()
+	mcsObj := multiClusterServiceForTest()
+	mcsObj.Spec = fleetnetv1alpha1.MultiClusterServiceSpec{
+		DNSLabelName: "my-app",
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(multiClusterServiceScheme(t)).
+		WithObjects(mcsObj).
+		WithStatusSubresource(mcsObj).
+		Build()
+
+	r := multiClusterServiceReconciler(fakeClient)
+	if _, err := r.handleUpdate(ctx, mcsObj); err != nil {
+		t.Fatalf("failed to handle update: %v", err)
+	}
+	serviceImport := fleetnetv1alpha1.ServiceImport{}
+	name := types.NamespacedName{Namespace: testNamespace, Name: testName}
+	if err := fakeClient.Get(ctx, name, &serviceImport); err != nil {
+		t.Fatalf("ServiceImport Get(%v) got error %v, want no error", name, err)
+	}
+	if got := mcsObj.Labels[multiClusterServiceLabelServiceImport]; got != testName {
+		t.Errorf("mcs service import label = %q, want %q", got, testName)
+	}
+}
+
 func TestConfigureInternalLoadBalancer(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -1111,3 +1268,256 @@ func TestConfigureInternalLoadBalancer(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigureDNSLabelName(t *testing.T) {
+	tests := []struct {
+		name         string
+		dnsLabelName string
+		annotations  map[string]string
+		want         map[string]string
+	}{
+		{
+			name: "dns label name is not set",
+		},
+		{
+			name:         "dns label name is set",
+			dnsLabelName: "my-app",
+			want: map[string]string{
+				objectmeta.ServiceAnnotationAzureDNSLabelName: "my-app",
+			},
+		},
+		{
+			name: "dns label name is removed",
+			annotations: map[string]string{
+				objectmeta.ServiceAnnotationAzureDNSLabelName: "my-app",
+			},
+			want: map[string]string{},
+		},
+		{
+			name:         "dns label name is ignored for internal load balancer",
+			dnsLabelName: "my-app",
+			annotations: map[string]string{
+				serviceAnnotationInternalLoadBalancer:         "true",
+				objectmeta.ServiceAnnotationAzureDNSLabelName: "my-app",
+			},
+			want: map[string]string{
+				serviceAnnotationInternalLoadBalancer: "true",
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mcs := &fleetnetv1alpha1.MultiClusterService{
+				Spec: fleetnetv1alpha1.MultiClusterServiceSpec{DNSLabelName: tc.dnsLabelName},
+			}
+			service := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: tc.annotations,
+				},
+			}
+			configureDNSLabelName(mcs, service)
+			if diff := cmp.Diff(tc.want, service.GetAnnotations()); diff != "" {
+				t.Errorf("configureDNSLabelName() service annotations mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConfigureTrafficPolicy(t *testing.T) {
+	timeout := int32(600)
+	clientIPConfig := &corev1.SessionAffinityConfig{ClientIP: &corev1.ClientIPConfig{TimeoutSeconds: &timeout}}
+	tests := []struct {
+		name          string
+		trafficPolicy *fleetnetv1alpha1.MultiClusterServiceTrafficPolicy
+		imported      corev1.ServiceAffinity
+		serviceSpec   corev1.ServiceSpec
+		want          corev1.ServiceSpec
+	}{
+		{
+			name:        "traffic policy is not set on a new service",
+			serviceSpec: corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+			want:        corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+		},
+		{
+			name: "traffic policy is not set on a service with the defaults",
+			serviceSpec: corev1.ServiceSpec{
+				Type:                  corev1.ServiceTypeLoadBalancer,
+				ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyCluster,
+				SessionAffinity:       corev1.ServiceAffinityNone,
+			},
+			want: corev1.ServiceSpec{
+				Type:                  corev1.ServiceTypeLoadBalancer,
+				ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyCluster,
+				SessionAffinity:       corev1.ServiceAffinityNone,
+			},
+		},
+		{
+			name: "traffic policy is set",
+			trafficPolicy: &fleetnetv1alpha1.MultiClusterServiceTrafficPolicy{
+				ExternalTrafficPolicy:    corev1.ServiceExternalTrafficPolicyLocal,
+				LoadBalancerSourceRanges: []string{"10.0.0.0/8"},
+				SessionAffinity:          corev1.ServiceAffinityClientIP,
+			},
+			serviceSpec: corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+			want: corev1.ServiceSpec{
+				Type:                     corev1.ServiceTypeLoadBalancer,
+				ExternalTrafficPolicy:    corev1.ServiceExternalTrafficPolicyLocal,
+				LoadBalancerSourceRanges: []string{"10.0.0.0/8"},
+				SessionAffinity:          corev1.ServiceAffinityClientIP,
+			},
+		},
+		{
+			name: "traffic policy is removed",
+			serviceSpec: corev1.ServiceSpec{
+				Type:                     corev1.ServiceTypeLoadBalancer,
+				ExternalTrafficPolicy:    corev1.ServiceExternalTrafficPolicyLocal,
+				LoadBalancerSourceRanges: []string{"10.0.0.0/8"},
+				SessionAffinity:          corev1.ServiceAffinityClientIP,
+				SessionAffinityConfig:    clientIPConfig,
+			},
+			want: corev1.ServiceSpec{
+				Type:                  corev1.ServiceTypeLoadBalancer,
+				ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyCluster,
+				SessionAffinity:       corev1.ServiceAffinityNone,
+			},
+		},
+		{
+			name:        "session affinity of the exported services applies",
+			imported:    corev1.ServiceAffinityClientIP,
+			serviceSpec: corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+			want: corev1.ServiceSpec{
+				Type:                  corev1.ServiceTypeLoadBalancer,
+				SessionAffinity:       corev1.ServiceAffinityClientIP,
+				SessionAffinityConfig: clientIPConfig,
+			},
+		},
+		{
+			name: "session affinity of the traffic policy overrides the exported services",
+			trafficPolicy: &fleetnetv1alpha1.MultiClusterServiceTrafficPolicy{
+				SessionAffinity: corev1.ServiceAffinityNone,
+			},
+			imported: corev1.ServiceAffinityClientIP,
+			serviceSpec: corev1.ServiceSpec{
+				Type:                  corev1.ServiceTypeLoadBalancer,
+				SessionAffinity:       corev1.ServiceAffinityClientIP,
+				SessionAffinityConfig: clientIPConfig,
+			},
+			want: corev1.ServiceSpec{
+				Type:            corev1.ServiceTypeLoadBalancer,
+				SessionAffinity: corev1.ServiceAffinityNone,
+			},
+		},
+		{
+			name: "only the session affinity applies to a headless service",
+			trafficPolicy: &fleetnetv1alpha1.MultiClusterServiceTrafficPolicy{
+				ExternalTrafficPolicy:    corev1.ServiceExternalTrafficPolicyLocal,
+				LoadBalancerSourceRanges: []string{"10.0.0.0/8"},
+				SessionAffinity:          corev1.ServiceAffinityClientIP,
+			},
+			serviceSpec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, ClusterIP: corev1.ClusterIPNone},
+			want: corev1.ServiceSpec{
+				Type:            corev1.ServiceTypeClusterIP,
+				ClusterIP:       corev1.ClusterIPNone,
+				SessionAffinity: corev1.ServiceAffinityClientIP,
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mcs := &fleetnetv1alpha1.MultiClusterService{
+				Spec: fleetnetv1alpha1.MultiClusterServiceSpec{TrafficPolicy: tc.trafficPolicy},
+			}
+			serviceImport := &fleetnetv1alpha1.ServiceImport{}
+			if tc.imported == corev1.ServiceAffinityClientIP {
+				serviceImport.Status.SessionAffinity = tc.imported
+				serviceImport.Status.SessionAffinityConfig = clientIPConfig
+			}
+			service := &corev1.Service{Spec: tc.serviceSpec}
+			configureTrafficPolicy(mcs, serviceImport, service)
+			if diff := cmp.Diff(tc.want, service.Spec); diff != "" {
+				t.Errorf("configureTrafficPolicy() service spec mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIPFamilyMismatchCondition(t *testing.T) {
+	dualStack := []corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv6Protocol}
+	ipv4Only := []corev1.IPFamily{corev1.IPv4Protocol}
+	mcs := &fleetnetv1alpha1.MultiClusterService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       testName,
+			Namespace:  testNamespace,
+			Generation: 2,
+		},
+	}
+
+	tests := []struct {
+		name                string
+		clusters            []fleetnetv1alpha1.ServiceImportClusterStatus
+		supportedIPFamilies []corev1.IPFamily
+		want                *metav1.Condition
+	}{
+		{
+			name: "supported IP families are not specified",
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
+				{Cluster: "member-1", IPFamilies: dualStack},
+			},
+		},
+		{
+			name: "single-stack cluster consumes single-stack exports",
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
+				{Cluster: "member-1", IPFamilies: ipv4Only},
+				{Cluster: "member-2"},
+			},
+			supportedIPFamilies: ipv4Only,
+			want: &metav1.Condition{
+				Type:               string(fleetnetv1alpha1.MultiClusterServiceIPFamilyMismatch),
+				Status:             metav1.ConditionFalse,
+				Reason:             conditionReasonIPFamilyMatch,
+				ObservedGeneration: 2,
+				Message:            "all exporting clusters use IP families supported by this cluster [IPv4]",
+			},
+		},
+		{
+			name: "single-stack cluster consumes a dual-stack export",
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
+				{Cluster: "member-2", IPFamilies: dualStack},
+				{Cluster: "member-1", IPFamilies: ipv4Only},
+			},
+			supportedIPFamilies: ipv4Only,
+			want: &metav1.Condition{
+				Type:               string(fleetnetv1alpha1.MultiClusterServiceIPFamilyMismatch),
+				Status:             metav1.ConditionTrue,
+				Reason:             conditionReasonIPFamilyMismatch,
+				ObservedGeneration: 2,
+				Message:            "endpoints of IP families not supported by this cluster [IPv4] are not imported from clusters: member-2 ([IPv6])",
+			},
+		},
+		{
+			name: "dual-stack cluster consumes a dual-stack export",
+			clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
+				{Cluster: "member-1", IPFamilies: dualStack},
+			},
+			supportedIPFamilies: dualStack,
+			want: &metav1.Condition{
+				Type:               string(fleetnetv1alpha1.MultiClusterServiceIPFamilyMismatch),
+				Status:             metav1.ConditionFalse,
+				Reason:             conditionReasonIPFamilyMatch,
+				ObservedGeneration: 2,
+				Message:            "all exporting clusters use IP families supported by this cluster [IPv4 IPv6]",
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			serviceImport := &fleetnetv1alpha1.ServiceImport{
+				Status: fleetnetv1alpha1.ServiceImportStatus{Clusters: tc.clusters},
+			}
+			got := ipFamilyMismatchCondition(mcs, serviceImport, tc.supportedIPFamilies)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("ipFamilyMismatchCondition() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package storageversionmigration features the storage version migration controller, which rewrites the objects
+// of a CRD that are still stored in an older API version into the current storage version, so that the older
+// version can be retired once the storage version of the CRD changes.
+package storageversionmigration
+
+import (
+	"context"
+	"time"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+)
+
+// Reconciler migrates the objects of the CRDs to their storage versions.
+type Reconciler struct {
+	Client client.Client
+	// CRDNames are the names of the CRDs whose objects are migrated, e.g. "serviceexports.networking.fleet.azure.com".
+	CRDNames []string
+
+	// ControllerOptions tune the concurrency and the rate limiting of the controller.
+	ControllerOptions controller.Options
+}
+
+//+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch
+//+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions/status,verbs=get;update;patch
+
+// Reconcile rewrites all the objects of the CRD if any of them may be stored in a version other than the storage
+// version, i.e. the stored versions of the CRD include other versions; the stored versions are reset to the
+// storage version once all the objects are rewritten.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	crdRef := klog.KRef("", req.Name)
+	startTime := time.Now()
+	klog.V(2).InfoS("Reconciliation starts", "customResourceDefinition", crdRef)
+	defer func() {
+		latency := time.Since(startTime).Milliseconds()
+		klog.V(2).InfoS("Reconciliation ends", "customResourceDefinition", crdRef, "latency", latency)
+	}()
+
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := r.Client.Get(ctx, req.NamespacedName, crd); err != nil {
+		if errors.IsNotFound(err) {
+			klog.V(4).InfoS("Ignoring NotFound customResourceDefinition", "customResourceDefinition", crdRef)
+			return ctrl.Result{}, nil
+		}
+		klog.ErrorS(err, "Failed to get customResourceDefinition", "customResourceDefinition", crdRef)
+		return ctrl.Result{}, err
+	}
+
+	storageVersion := ""
+	for _, v := range crd.Spec.Versions {
+		if v.Storage {
+			storageVersion = v.Name
+		}
+	}
+	if storageVersion == "" || !hasStaleStoredVersions(crd.Status.StoredVersions, storageVersion) {
+		return ctrl.Result{}, nil
+	}
+
+	klog.V(2).InfoS("Migrating objects to the storage version", "customResourceDefinition", crdRef, "storageVersion", storageVersion, "storedVersions", crd.Status.StoredVersions)
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(schema.GroupVersionKind{Group: crd.Spec.Group, Version: storageVersion, Kind: crd.Spec.Names.ListKind})
+	if err := r.Client.List(ctx, list); err != nil {
+		klog.ErrorS(err, "Failed to list objects", "customResourceDefinition", crdRef)
+		return ctrl.Result{}, err
+	}
+	for i := range list.Items {
+		obj := &list.Items[i]
+		// An update, even one which changes nothing, makes the API server store the object in the storage version.
+		// An object which is updated or deleted in the meantime needs no migration.
+		if err := r.Client.Update(ctx, obj); err != nil && !errors.IsConflict(err) && !errors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to migrate object", "customResourceDefinition", crdRef, "object", klog.KObj(obj))
+			return ctrl.Result{}, err
+		}
+	}
+
+	crd.Status.StoredVersions = []string{storageVersion}
+	if err := r.Client.Status().Update(ctx, crd); err != nil {
+		klog.ErrorS(err, "Failed to update the stored versions of customResourceDefinition", "customResourceDefinition", crdRef)
+		return ctrl.Result{}, err
+	}
+	klog.V(2).InfoS("Migrated objects to the storage version", "customResourceDefinition", crdRef, "storageVersion", storageVersion, "count", len(list.Items))
+	return ctrl.Result{}, nil
+}
+
+// hasStaleStoredVersions returns true if the stored versions include any version other than the storage version.
+func hasStaleStoredVersions(storedVersions []string, storageVersion string) bool {
+	for _, v := range storedVersions {
+		if v != storageVersion {
+			return true
+		}
+	}
+	return false
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	names := make(map[string]bool, len(r.CRDNames))
+	for _, name := range r.CRDNames {
+		names[name] = true
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("storageversionmigration").
+		For(&apiextensionsv1.CustomResourceDefinition{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			return names[obj.GetName()]
+		}))).
+		WithOptions(r.ControllerOptions).
+		Complete(metrics.InstrumentReconciler("storageversionmigration", r))
+}
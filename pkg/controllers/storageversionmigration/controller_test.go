@@ -0,0 +1,99 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package storageversionmigration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
+	fleetnetv1beta1 "go.goms.io/fleet-networking/api/v1beta1"
+)
+
+const crdName = "serviceexports.networking.fleet.azure.com"
+
+// TestReconcile tests the Reconcile method.
+func TestReconcile(t *testing.T) {
+	testCases := []struct {
+		name               string
+		storedVersions     []string
+		wantStoredVersions []string
+		wantMigrated       bool
+	}{
+		{
+			name:               "objects are migrated",
+			storedVersions:     []string{"v1alpha1", "v1beta1"},
+			wantStoredVersions: []string{"v1beta1"},
+			wantMigrated:       true,
+		},
+		{
+			name:               "objects are stored in the storage version",
+			storedVersions:     []string{"v1beta1"},
+			wantStoredVersions: []string{"v1beta1"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			scheme := runtime.NewScheme()
+			for _, addToScheme := range []func(*runtime.Scheme) error{apiextensionsv1.AddToScheme, fleetnetv1alpha1.AddToScheme, fleetnetv1beta1.AddToScheme} {
+				if err := addToScheme(scheme); err != nil {
+					t.Fatalf("failed to add scheme: %v", err)
+				}
+			}
+			crd := &apiextensionsv1.CustomResourceDefinition{
+				ObjectMeta: metav1.ObjectMeta{Name: crdName},
+				Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+					Group: fleetnetv1beta1.GroupVersion.Group,
+					Names: apiextensionsv1.CustomResourceDefinitionNames{Kind: "ServiceExport", ListKind: "ServiceExportList"},
+					Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+						{Name: "v1alpha1", Served: true},
+						{Name: "v1beta1", Served: true, Storage: true},
+					},
+				},
+				Status: apiextensionsv1.CustomResourceDefinitionStatus{StoredVersions: tc.storedVersions},
+			}
+			svcExport := &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "work", Name: "app"},
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(crd, svcExport).
+				WithStatusSubresource(crd).
+				Build()
+			if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: "work", Name: "app"}, svcExport); err != nil {
+				t.Fatalf("ServiceExport Get() got error %v, want no error", err)
+			}
+			oldResourceVersion := svcExport.ResourceVersion
+
+			r := &Reconciler{Client: fakeClient, CRDNames: []string{crdName}}
+			if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: crdName}}); err != nil {
+				t.Fatalf("Reconcile() got error %v, want no error", err)
+			}
+
+			if err := fakeClient.Get(ctx, types.NamespacedName{Name: crdName}, crd); err != nil {
+				t.Fatalf("CustomResourceDefinition Get() got error %v, want no error", err)
+			}
+			if diff := cmp.Diff(tc.wantStoredVersions, crd.Status.StoredVersions); diff != "" {
+				t.Errorf("CustomResourceDefinition storedVersions mismatch (-want, +got):\n%s", diff)
+			}
+			if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: "work", Name: "app"}, svcExport); err != nil {
+				t.Fatalf("ServiceExport Get() got error %v, want no error", err)
+			}
+			if gotMigrated := svcExport.ResourceVersion != oldResourceVersion; gotMigrated != tc.wantMigrated {
+				t.Errorf("ServiceExport migrated = %t, want %t", gotMigrated, tc.wantMigrated)
+			}
+		})
+	}
+}
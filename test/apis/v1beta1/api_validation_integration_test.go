@@ -132,6 +132,20 @@ var _ = Describe("Test networking v1alpha1 API validation", func() {
 			Expect(errors.As(err, &statusErr)).To(BeTrue(), fmt.Sprintf("Create API call produced error %s. Error type wanted is %s.", reflect.TypeOf(err), reflect.TypeOf(&k8serrors.StatusError{})))
 			Expect(statusErr.Status().Message).Should(ContainSubstring("metadata.name max length is 63"))
 		})
+
+		It("should deny creating API with an expected status code range whose min is greater than max", func() {
+			// Create the API.
+			spec := trafficManagerProfileSpec.DeepCopy()
+			spec.MonitorConfig.ExpectedStatusCodeRanges = []fleetnetv1beta1.MonitorConfigStatusCodeRange{{Min: 299, Max: 200}}
+			trafficManagerProfile := &fleetnetv1beta1.TrafficManagerProfile{
+				ObjectMeta: objectMetaWithNameValid,
+				Spec:       *spec,
+			}
+			By("expecting denial of CREATE API with invalid expected status code range")
+			var err = hubClient.Create(ctx, trafficManagerProfile)
+			Expect(errors.As(err, &statusErr)).To(BeTrue(), fmt.Sprintf("Create API call produced error %s. Error type wanted is %s.", reflect.TypeOf(err), reflect.TypeOf(&k8serrors.StatusError{})))
+			Expect(statusErr.Status().Message).Should(ContainSubstring("min must not be greater than max"))
+		})
 	})
 
 	Context("Test TrafficManagerProfile API validation - valid cases", func() {
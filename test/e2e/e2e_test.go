@@ -69,19 +69,10 @@ func TestE2E(t *testing.T) {
 
 var _ = BeforeSuite(func() {
 	var err error
-	// hub cluster setup
-	hubCluster, err = framework.NewCluster(hubClusterName, scheme)
-	Expect(err).Should(Succeed(), "Failed to initialize hubCluster")
-
-	//member cluster setup
-	memberClusters = make([]*framework.Cluster, 0, len(memberClusterNames))
-	for _, m := range memberClusterNames {
-		cluster, err := framework.NewCluster(m, scheme)
-		Expect(err).Should(Succeed(), "Failed to initialize memberCluster %s", m)
-		memberClusters = append(memberClusters, cluster)
-	}
-
-	fleet = framework.NewFleet(memberClusters, memberClusters[0], hubCluster)
+	fleet, err = framework.NewFleetFromClusterNames(hubClusterName, memberClusterNames, scheme)
+	Expect(err).Should(Succeed(), "Failed to initialize fleet")
+	hubCluster = fleet.HubCluster()
+	memberClusters = fleet.MemberClusters()
 
 	testNamespace = framework.UniqueTestNamespace()
 	createTestNamespace(context.Background())
@@ -111,31 +102,23 @@ func initAzureClients() {
 }
 
 func createTestNamespace(ctx context.Context) {
-	ns := corev1.Namespace{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: testNamespace,
-		},
-	}
-	Expect(hubCluster.Client().Create(ctx, &ns)).Should(Succeed(), "Failed to create namespace %s cluster %s", testNamespace, hubClusterName)
-
-	for _, m := range memberClusters {
-		ns = corev1.Namespace{
+	Expect(fleet.ForEachCluster(func(c *framework.Cluster) error {
+		ns := corev1.Namespace{
 			ObjectMeta: metav1.ObjectMeta{
 				Name: testNamespace,
 			},
-		} // reset ns object
-		Expect(m.Client().Create(ctx, &ns)).Should(Succeed(), "Failed to create namespace %s cluster %s", testNamespace, m.Name())
-	}
+		}
+		return c.Client().Create(ctx, &ns)
+	})).Should(Succeed(), "Failed to create namespace %s", testNamespace)
 }
 
 var _ = AfterSuite(func() {
-	ns := corev1.Namespace{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: testNamespace,
-		},
-	}
-	Expect(hubCluster.Client().Delete(ctx, &ns)).Should(Succeed(), "Failed to delete namespace %s cluster %s", testNamespace, hubClusterName)
-	for _, m := range memberClusters {
-		Expect(m.Client().Delete(ctx, &ns)).Should(Succeed(), "Failed to delete namespace %s cluster %s", testNamespace, m.Name())
-	}
+	Expect(fleet.ForEachCluster(func(c *framework.Cluster) error {
+		ns := corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: testNamespace,
+			},
+		}
+		return c.Client().Delete(ctx, &ns)
+	})).Should(Succeed(), "Failed to delete namespace %s", testNamespace)
 })
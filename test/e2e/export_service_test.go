@@ -91,7 +91,7 @@ var _ = Describe("Test exporting service", func() {
 			err := hubCluster.Client().Get(ctx, svcImportKey, svcImportObj)
 			Expect(err).Should(BeNil(), "Failed to get service import")
 			wantedSvcImportStatus := fleetnetv1alpha1.ServiceImportStatus{
-				Clusters: []fleetnetv1alpha1.ClusterStatus{
+				Clusters: []fleetnetv1alpha1.ServiceImportClusterStatus{
 					{
 						Cluster: memberClusters[0].Name(),
 					},
@@ -108,7 +108,13 @@ var _ = Describe("Test exporting service", func() {
 					},
 				},
 			}
-			Expect(cmp.Diff(wantedSvcImportStatus, svcImportObj.Status)).Should(BeEmpty(), "Validate service import status mismatch (-want, +got):")
+			// The fields derived from the cluster environment (e.g. IP families, endpoint distribution) are not validated.
+			svcImportStatusCmpOptions := []cmp.Option{
+				cmpopts.IgnoreFields(fleetnetv1alpha1.ServiceImportStatus{},
+					"SessionAffinity", "EndpointDistribution", "DNSTTLSeconds", "ClusterExportSummaries", "Conditions"),
+				cmpopts.IgnoreFields(fleetnetv1alpha1.ServiceImportClusterStatus{}, "IPFamilyPolicy", "IPFamilies"),
+			}
+			Expect(cmp.Diff(wantedSvcImportStatus, svcImportObj.Status, svcImportStatusCmpOptions...)).Should(BeEmpty(), "Validate service import status mismatch (-want, +got):")
 
 			By("Validating multi-cluster service request distribution")
 			requestURL := fmt.Sprintf("http://%s:%d", mcsLBAddr, svcDef.Spec.Ports[0].Port)
@@ -508,7 +514,7 @@ var _ = Describe("Test exporting service", func() {
 			Expect(memberClusterTwo.Client().Delete(ctx, &svcExportDef)).Should(Succeed(), "Failed to delete service export %s in cluster %s", svcExportKey, memberClusterTwo.Name())
 		})
 
-		It("should reject exporting a headless service", func() {
+		It("should export a headless service", func() {
 			By("Creating a headless service")
 			memberCluster := wm.Fleet.MemberClusters()[0]
 			svcDef := wm.Service()
@@ -524,7 +530,7 @@ var _ = Describe("Test exporting service", func() {
 			svcExportKey := types.NamespacedName{Namespace: svcExportDef.Namespace, Name: svcExportDef.Name}
 			Expect(memberCluster.Client().Create(ctx, &svcExportDef)).Should(Succeed(), "Failed to create service export %s in cluster %s", svcExportKey, memberCluster.Name())
 
-			By("Validating exporting the headless service should be ineligible")
+			By("Validating the headless service is exported")
 			svcExportObj := &fleetnetv1alpha1.ServiceExport{}
 			Eventually(func() string {
 				if err := memberCluster.Client().Get(ctx, svcExportKey, svcExportObj); err != nil {
@@ -533,7 +539,12 @@ var _ = Describe("Test exporting service", func() {
 				wantedSvcExportConditions := []metav1.Condition{
 					{
 						Type:   string(fleetnetv1alpha1.ServiceExportValid),
-						Reason: "ServiceIneligible",
+						Reason: "ServiceIsValid",
+						Status: metav1.ConditionTrue,
+					},
+					{
+						Type:   string(fleetnetv1alpha1.ServiceExportConflict),
+						Reason: "NoConflictFound",
 						Status: metav1.ConditionFalse,
 					},
 				}
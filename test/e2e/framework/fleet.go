@@ -5,6 +5,14 @@ Licensed under the MIT license.
 
 package framework
 
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
 // Fleet is a collection of clusters for e2e tests.
 type Fleet struct {
 	memberClusters   []*Cluster
@@ -27,9 +35,77 @@ func (c *Fleet) HubCluster() *Cluster {
 	return c.hubCluster
 }
 
+// Hub is a shorthand for HubCluster.
+func (c *Fleet) Hub() *Cluster {
+	return c.hubCluster
+}
+
 // Clusters returns all clusters including both member and hub.
 func (c *Fleet) Clusters() []*Cluster {
-	return append(c.memberClusters, c.hubCluster)
+	clusters := make([]*Cluster, 0, len(c.memberClusters)+1)
+	clusters = append(clusters, c.memberClusters...)
+	return append(clusters, c.hubCluster)
+}
+
+// ForEachMember runs fn against every member cluster one by one.
+// It does not stop at the first failure; errors from all clusters are collected and returned together.
+func (c *Fleet) ForEachMember(fn func(*Cluster) error) error {
+	return forEach(c.memberClusters, fn)
+}
+
+// ForEachCluster runs fn against every cluster, including the hub, one by one.
+// It does not stop at the first failure; errors from all clusters are collected and returned together.
+func (c *Fleet) ForEachCluster(fn func(*Cluster) error) error {
+	return forEach(c.Clusters(), fn)
+}
+
+// ForEachMemberInParallel runs fn against every member cluster concurrently, with at most maxConcurrency
+// invocations in flight; a non-positive maxConcurrency means no limit.
+// It waits for all invocations to finish and returns the errors from all clusters together.
+func (c *Fleet) ForEachMemberInParallel(maxConcurrency int, fn func(*Cluster) error) error {
+	return forEachInParallel(c.memberClusters, maxConcurrency, fn)
+}
+
+// ForEachClusterInParallel runs fn against every cluster, including the hub, concurrently, with at most
+// maxConcurrency invocations in flight; a non-positive maxConcurrency means no limit.
+// It waits for all invocations to finish and returns the errors from all clusters together.
+func (c *Fleet) ForEachClusterInParallel(maxConcurrency int, fn func(*Cluster) error) error {
+	return forEachInParallel(c.Clusters(), maxConcurrency, fn)
+}
+
+func forEach(clusters []*Cluster, fn func(*Cluster) error) error {
+	var errs []error
+	for _, cluster := range clusters {
+		if err := fn(cluster); err != nil {
+			errs = append(errs, fmt.Errorf("cluster %s: %w", cluster.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func forEachInParallel(clusters []*Cluster, maxConcurrency int, fn func(*Cluster) error) error {
+	if maxConcurrency <= 0 || maxConcurrency > len(clusters) {
+		maxConcurrency = len(clusters)
+	}
+	// errs is indexed by the cluster position so that the aggregated error has a stable order.
+	errs := make([]error, len(clusters))
+	sem := make(chan struct{}, maxConcurrency)
+	var wg sync.WaitGroup
+	for i, cluster := range clusters {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, cluster *Cluster) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := fn(cluster); err != nil {
+				errs[i] = fmt.Errorf("cluster %s: %w", cluster.Name(), err)
+			}
+		}(i, cluster)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
 }
 
 // NewFleet returns a collection of clusters for e2e tests.
@@ -40,3 +116,25 @@ func NewFleet(memberClusters []*Cluster, mcsMemberCluster, hubCluster *Cluster)
 		hubCluster:       hubCluster,
 	}
 }
+
+// NewFleetFromClusterNames initializes the hub cluster and all the member clusters by their names, which are
+// used to look up the kubeconfig contexts, and returns them as a fleet.
+// The first member cluster is the one on which MCS will be hosted.
+func NewFleetFromClusterNames(hubClusterName string, memberClusterNames []string, scheme *runtime.Scheme) (*Fleet, error) {
+	if len(memberClusterNames) == 0 {
+		return nil, fmt.Errorf("at least one member cluster is required")
+	}
+	hubCluster, err := NewCluster(hubClusterName, scheme)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize hub cluster %s: %w", hubClusterName, err)
+	}
+	memberClusters := make([]*Cluster, 0, len(memberClusterNames))
+	for _, name := range memberClusterNames {
+		cluster, err := NewCluster(name, scheme)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize member cluster %s: %w", name, err)
+		}
+		memberClusters = append(memberClusters, cluster)
+	}
+	return NewFleet(memberClusters, memberClusters[0], hubCluster), nil
+}
@@ -0,0 +1,209 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package framework
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func newFakeFleet(memberNames ...string) *Fleet {
+	members := make([]*Cluster, 0, len(memberNames))
+	for _, name := range memberNames {
+		members = append(members, &Cluster{name: name})
+	}
+	return NewFleet(members, members[0], &Cluster{name: "hub"})
+}
+
+func TestFleetClusters(t *testing.T) {
+	fleet := newFakeFleet("member-1", "member-2")
+
+	var got []string
+	for _, c := range fleet.Clusters() {
+		got = append(got, c.Name())
+	}
+	want := []string{"member-1", "member-2", "hub"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Clusters() mismatch (-want, +got):\n%s", diff)
+	}
+	if len(fleet.MemberClusters()) != 2 {
+		t.Errorf("Clusters() modified the member clusters, got %d members, want 2", len(fleet.MemberClusters()))
+	}
+	if fleet.Hub() != fleet.HubCluster() {
+		t.Errorf("Hub() = %v, want %v", fleet.Hub(), fleet.HubCluster())
+	}
+}
+
+func TestForEachMember(t *testing.T) {
+	errFake := errors.New("fake error")
+	testCases := []struct {
+		name         string
+		failOn       map[string]bool
+		wantVisited  []string
+		wantErrParts []string
+	}{
+		{
+			name:        "all succeed",
+			wantVisited: []string{"member-1", "member-2", "member-3"},
+		},
+		{
+			name:         "failures are collected and do not stop the iteration",
+			failOn:       map[string]bool{"member-1": true, "member-3": true},
+			wantVisited:  []string{"member-1", "member-2", "member-3"},
+			wantErrParts: []string{"cluster member-1: fake error", "cluster member-3: fake error"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fleet := newFakeFleet("member-1", "member-2", "member-3")
+			var visited []string
+			err := fleet.ForEachMember(func(c *Cluster) error {
+				visited = append(visited, c.Name())
+				if tc.failOn[c.Name()] {
+					return errFake
+				}
+				return nil
+			})
+			if diff := cmp.Diff(tc.wantVisited, visited); diff != "" {
+				t.Errorf("ForEachMember() visited mismatch (-want, +got):\n%s", diff)
+			}
+			checkAggregatedError(t, err, errFake, tc.wantErrParts)
+		})
+	}
+}
+
+func TestForEachCluster(t *testing.T) {
+	fleet := newFakeFleet("member-1", "member-2")
+	var visited []string
+	if err := fleet.ForEachCluster(func(c *Cluster) error {
+		visited = append(visited, c.Name())
+		return nil
+	}); err != nil {
+		t.Fatalf("ForEachCluster() = %v, want nil", err)
+	}
+	want := []string{"member-1", "member-2", "hub"}
+	if diff := cmp.Diff(want, visited); diff != "" {
+		t.Errorf("ForEachCluster() visited mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestForEachMemberInParallel(t *testing.T) {
+	errFake := errors.New("fake error")
+	memberNames := make([]string, 0, 8)
+	for i := 1; i <= 8; i++ {
+		memberNames = append(memberNames, fmt.Sprintf("member-%d", i))
+	}
+	testCases := []struct {
+		name           string
+		maxConcurrency int
+		wantMaxInUse   int
+		failOn         map[string]bool
+		wantErrParts   []string
+	}{
+		{
+			name:           "bounded concurrency",
+			maxConcurrency: 3,
+			wantMaxInUse:   3,
+		},
+		{
+			name:           "unbounded concurrency",
+			maxConcurrency: 0,
+			wantMaxInUse:   len(memberNames),
+		},
+		{
+			name:           "failures are collected from all clusters",
+			maxConcurrency: 2,
+			wantMaxInUse:   2,
+			failOn:         map[string]bool{"member-2": true, "member-7": true},
+			wantErrParts:   []string{"cluster member-2: fake error", "cluster member-7: fake error"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fleet := newFakeFleet(memberNames...)
+			var inUse, maxInUse int32
+			var mu sync.Mutex
+			var visited []string
+			err := fleet.ForEachMemberInParallel(tc.maxConcurrency, func(c *Cluster) error {
+				n := atomic.AddInt32(&inUse, 1)
+				defer atomic.AddInt32(&inUse, -1)
+				for {
+					old := atomic.LoadInt32(&maxInUse)
+					if n <= old || atomic.CompareAndSwapInt32(&maxInUse, old, n) {
+						break
+					}
+				}
+				// Hold the slot for a while so that the concurrent invocations overlap.
+				time.Sleep(50 * time.Millisecond)
+
+				mu.Lock()
+				visited = append(visited, c.Name())
+				mu.Unlock()
+				if tc.failOn[c.Name()] {
+					return errFake
+				}
+				return nil
+			})
+
+			sort.Strings(visited)
+			wantVisited := append([]string{}, memberNames...)
+			sort.Strings(wantVisited)
+			if diff := cmp.Diff(wantVisited, visited); diff != "" {
+				t.Errorf("ForEachMemberInParallel() visited mismatch (-want, +got):\n%s", diff)
+			}
+			if got := int(atomic.LoadInt32(&maxInUse)); got > tc.wantMaxInUse {
+				t.Errorf("ForEachMemberInParallel() ran %d invocations concurrently, want at most %d", got, tc.wantMaxInUse)
+			}
+			checkAggregatedError(t, err, errFake, tc.wantErrParts)
+		})
+	}
+}
+
+func TestForEachClusterInParallel(t *testing.T) {
+	fleet := newFakeFleet("member-1", "member-2")
+	var mu sync.Mutex
+	var visited []string
+	if err := fleet.ForEachClusterInParallel(1, func(c *Cluster) error {
+		mu.Lock()
+		defer mu.Unlock()
+		visited = append(visited, c.Name())
+		return nil
+	}); err != nil {
+		t.Fatalf("ForEachClusterInParallel() = %v, want nil", err)
+	}
+	want := []string{"member-1", "member-2", "hub"}
+	if diff := cmp.Diff(want, visited); diff != "" {
+		t.Errorf("ForEachClusterInParallel() visited mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func checkAggregatedError(t *testing.T, err, wantIs error, wantErrParts []string) {
+	t.Helper()
+	if len(wantErrParts) == 0 {
+		if err != nil {
+			t.Errorf("got error %v, want nil", err)
+		}
+		return
+	}
+	if err == nil {
+		t.Fatalf("got nil error, want errors %v", wantErrParts)
+	}
+	if !errors.Is(err, wantIs) {
+		t.Errorf("got error %v, want it to wrap %v", err, wantIs)
+	}
+	gotErrParts := strings.Split(err.Error(), "\n")
+	if diff := cmp.Diff(wantErrParts, gotErrParts); diff != "" {
+		t.Errorf("aggregated error mismatch (-want, +got):\n%s", diff)
+	}
+}